@@ -243,6 +243,10 @@ func main() {
 		if err != nil {
 			log.Fatal(err.Error())
 		}
+		armAMI, err := FindImage(svc, EKSResourceAccountID(region), fmt.Sprintf("amazon-eks-arm64-node-%s-v*", k8sVersion))
+		if err != nil {
+			log.Fatal(err.Error())
+		}
 
 		if k8sVersionMap[k8sVersion][region] == nil {
 			k8sVersionMap[k8sVersion][region] = map[string]string{}
@@ -250,6 +254,7 @@ func main() {
 		k8sVersionMap[k8sVersion][region] = map[string]string{
 			"cpu":         cpuAMI,
 			"accelerated": acceleratedAMI,
+			"arm":         armAMI,
 		}
 		fmt.Println(" ✓")
 	}
@@ -25,10 +25,13 @@ dev_images=(
   "async-gateway"
   "enqueuer"
   "dequeuer"
+  "downloader"
+  "model-manager"
 )
 
 non_dev_images=(
   "cluster-autoscaler"
+  "karpenter"
   "operator"
   "controller-manager"
   "istio-proxy"
@@ -43,6 +46,7 @@ non_dev_images=(
   "prometheus-node-exporter"
   "kube-rbac-proxy"
   "grafana"
+  "alertmanager"
   "event-exporter"
   "metrics-server"
   "inferentia"
@@ -50,6 +54,25 @@ non_dev_images=(
   "kubexit"
 )
 
+# images which are built for both amd64 and arm64 (i.e. the images which run on the worker nodes), since node groups may use ARM (graviton) instances;
+# these are built and pushed together with `docker buildx` when pushing, since multi-arch images can't be loaded into the local docker daemon
+multi_arch_images=(
+  "proxy"
+  "async-gateway"
+  "enqueuer"
+  "dequeuer"
+  "downloader"
+  "model-manager"
+  "kubexit"
+  "fluent-bit"
+  "prometheus-node-exporter"
+)
+
+# images which are also built for windows/amd64 (i.e. the sidecars of the apis which can run on windows node groups)
+windows_images=(
+  "proxy"
+)
+
 all_images=(
   "${dev_images[@]}"
   "${non_dev_images[@]}"
@@ -17,10 +17,23 @@
 
 set -euo pipefail
 
+ROOT="$(cd "$(dirname "${BASH_SOURCE[0]}")"/.. >/dev/null && pwd)"
+
+source $ROOT/build/images.sh
+
 CORTEX_VERSION=master
 
 host=$1
 image=$2
 
 echo "$DOCKER_PASSWORD" | docker login -u "$DOCKER_USERNAME" --password-stdin
-docker push $host/cortexlabs/${image}:${CORTEX_VERSION}
+
+if [[ " ${multi_arch_images[*]} " == *" $image "* ]]; then
+  platforms="linux/amd64,linux/arm64"
+  if [[ " ${windows_images[*]} " == *" $image "* ]]; then
+    platforms="$platforms,windows/amd64"
+  fi
+  docker buildx build "$ROOT" -f $ROOT/images/$image/Dockerfile --platform $platforms -t $host/cortexlabs/${image}:${CORTEX_VERSION} --push
+else
+  docker push $host/cortexlabs/${image}:${CORTEX_VERSION}
+fi
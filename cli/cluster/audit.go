@@ -0,0 +1,47 @@
+/*
+Copyright 2021 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/lib/json"
+	s "github.com/cortexlabs/cortex/pkg/lib/strings"
+	"github.com/cortexlabs/cortex/pkg/operator/schema"
+)
+
+func GetAuditEvents(operatorConfig OperatorConfig, apiName string, since string, limit int) (schema.AuditResponse, error) {
+	params := map[string]string{
+		"since": since,
+		"limit": s.Int(limit),
+	}
+	if apiName != "" {
+		params["apiName"] = apiName
+	}
+
+	httpRes, err := HTTPGet(operatorConfig, "/audit", params)
+	if err != nil {
+		return schema.AuditResponse{}, err
+	}
+
+	var auditRes schema.AuditResponse
+	err = json.Unmarshal(httpRes, &auditRes)
+	if err != nil {
+		return schema.AuditResponse{}, errors.Wrap(err, "/audit", string(httpRes))
+	}
+
+	return auditRes, nil
+}
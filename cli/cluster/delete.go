@@ -80,14 +80,22 @@ func StopJob(operatorConfig OperatorConfig, kind userconfig.Kind, apiName string
 		"jobID":   jobID,
 	}
 
-	var endpointComponent string
-	if kind == userconfig.BatchAPIKind {
-		endpointComponent = "batch"
-	} else {
-		endpointComponent = "tasks"
+	httpRes, err := HTTPDelete(operatorConfig, path.Join("/"+jobEndpointComponent(kind), apiName), params)
+	if err != nil {
+		return schema.DeleteResponse{}, err
 	}
 
-	httpRes, err := HTTPDelete(operatorConfig, path.Join("/"+endpointComponent, apiName), params)
+	var deleteRes schema.DeleteResponse
+	err = json.Unmarshal(httpRes, &deleteRes)
+	if err != nil {
+		return schema.DeleteResponse{}, errors.Wrap(err, string(httpRes))
+	}
+
+	return deleteRes, nil
+}
+
+func CancelAsyncWorkload(operatorConfig OperatorConfig, apiName string, requestID string) (schema.DeleteResponse, error) {
+	httpRes, err := HTTPDelete(operatorConfig, path.Join("/async", apiName, requestID))
 	if err != nil {
 		return schema.DeleteResponse{}, err
 	}
@@ -100,3 +108,30 @@ func StopJob(operatorConfig OperatorConfig, kind userconfig.Kind, apiName string
 
 	return deleteRes, nil
 }
+
+func RetryJob(operatorConfig OperatorConfig, kind userconfig.Kind, apiName string, jobID string) (schema.RetryJobResponse, error) {
+	params := map[string]string{
+		"apiName": apiName,
+		"jobID":   jobID,
+	}
+
+	httpRes, err := HTTPPostNoBody(operatorConfig, path.Join("/"+jobEndpointComponent(kind), apiName, "retry"), params)
+	if err != nil {
+		return schema.RetryJobResponse{}, err
+	}
+
+	var retryRes schema.RetryJobResponse
+	err = json.Unmarshal(httpRes, &retryRes)
+	if err != nil {
+		return schema.RetryJobResponse{}, errors.Wrap(err, string(httpRes))
+	}
+
+	return retryRes, nil
+}
+
+func jobEndpointComponent(kind userconfig.Kind) string {
+	if kind == userconfig.BatchAPIKind {
+		return "batch"
+	}
+	return "tasks"
+}
@@ -25,9 +25,10 @@ import (
 	"github.com/cortexlabs/cortex/pkg/operator/schema"
 )
 
-func Deploy(operatorConfig OperatorConfig, configPath string, deploymentBytesMap map[string][]byte, force bool) ([]schema.DeployResult, error) {
+func Deploy(operatorConfig OperatorConfig, configPath string, deploymentBytesMap map[string][]byte, force bool, project bool) ([]schema.DeployResult, error) {
 	params := map[string]string{
 		"force":          s.Bool(force),
+		"project":        s.Bool(project),
 		"configFileName": filepath.Base(configPath),
 	}
 	uploadInput := &HTTPUploadInput{
@@ -46,3 +47,24 @@ func Deploy(operatorConfig OperatorConfig, configPath string, deploymentBytesMap
 
 	return deployResults, nil
 }
+
+func DeployDiff(operatorConfig OperatorConfig, configPath string, deploymentBytesMap map[string][]byte) ([]schema.DeployDiffResult, error) {
+	params := map[string]string{
+		"configFileName": filepath.Base(configPath),
+	}
+	uploadInput := &HTTPUploadInput{
+		Bytes: deploymentBytesMap,
+	}
+
+	response, err := HTTPUpload(operatorConfig, "/deploy/diff", uploadInput, params)
+	if err != nil {
+		return nil, err
+	}
+
+	var diffResults []schema.DeployDiffResult
+	if err := json.Unmarshal(response, &diffResults); err != nil {
+		return nil, errors.Wrap(err, "/deploy/diff", string(response))
+	}
+
+	return diffResults, nil
+}
@@ -41,6 +41,9 @@ const (
 	ErrResponseUnknown               = "cli.response_unknown"
 	ErrOperatorResponseUnknown       = "cli.operator_response_unknown"
 	ErrOperatorStreamResponseUnknown = "cli.operator_stream_response_unknown"
+	ErrOIDCSessionNotFound           = "cli.oidc_session_not_found"
+	ErrExecFailed                    = "cli.exec_failed"
+	ErrPortForwardListen             = "cli.port_forward_listen"
 )
 
 func ErrorFailedToConnectOperator(originalError error, envName string, operatorURL string) error {
@@ -68,6 +71,7 @@ func ErrorFailedToConnectOperator(originalError error, envName string, operatorU
 	return errors.WithStack(&errors.Error{
 		Kind:    ErrFailedToConnectOperator,
 		Message: msg,
+		Cause:   originalError,
 	})
 }
 
@@ -93,14 +97,37 @@ func ErrorResponseUnknown(body string, statusCode int) error {
 
 func ErrorOperatorResponseUnknown(body string, statusCode int) error {
 	return errors.WithStack(&errors.Error{
-		Kind:    ErrOperatorResponseUnknown,
-		Message: fmt.Sprintf("unexpected response from operator (status code %d): %s", statusCode, body),
+		Kind:     ErrOperatorResponseUnknown,
+		Message:  fmt.Sprintf("unexpected response from operator (status code %d): %s", statusCode, body),
+		Metadata: OperatorErrorMetadata{StatusCode: statusCode},
 	})
 }
 
 func ErrorOperatorStreamResponseUnknown(body string, statusCode int) error {
 	return errors.WithStack(&errors.Error{
-		Kind:    ErrOperatorStreamResponseUnknown,
-		Message: fmt.Sprintf("unexpected response from operator (status code %d): %s", statusCode, body),
+		Kind:     ErrOperatorStreamResponseUnknown,
+		Message:  fmt.Sprintf("unexpected response from operator (status code %d): %s", statusCode, body),
+		Metadata: OperatorErrorMetadata{StatusCode: statusCode},
+	})
+}
+
+func ErrorOIDCSessionNotFound(envName string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrOIDCSessionNotFound,
+		Message: fmt.Sprintf("you are not logged in to the %s environment; run `cortex env configure %s --auth oidc` to log in", envName, envName),
+	})
+}
+
+func ErrorExecFailed(reason string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrExecFailed,
+		Message: fmt.Sprintf("unable to run the command: %s", reason),
+	})
+}
+
+func ErrorPortForwardListen(localPort int, err error) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrPortForwardListen,
+		Message: fmt.Sprintf("unable to listen on local port %d: %s", localPort, errors.Message(err)),
 	})
 }
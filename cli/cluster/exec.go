@@ -0,0 +1,194 @@
+/*
+Copyright 2021 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"sync"
+
+	"github.com/cortexlabs/cortex/cli/lib/routines"
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/lib/json"
+	s "github.com/cortexlabs/cortex/pkg/lib/strings"
+	"github.com/gorilla/websocket"
+)
+
+type TerminalSize struct {
+	Width  uint16 `json:"width"`
+	Height uint16 `json:"height"`
+}
+
+// Exec runs command in a replica of the api, and returns the command's exit code;
+// stdin is forwarded until it is closed, and the terminal sizes which are sent on sizes are forwarded until sizes is closed
+func Exec(operatorConfig OperatorConfig, apiName string, replica string, container string, command []string, tty bool, stdin io.Reader, stdout io.Writer, stderr io.Writer, sizes <-chan TerminalSize) (int, error) {
+	commandBytes, err := json.Marshal(command)
+	if err != nil {
+		return 0, err
+	}
+
+	connection, err := openOperatorSocket(operatorConfig, "/exec/"+apiName, map[string]string{
+		"command":   string(commandBytes),
+		"tty":       s.Bool(tty),
+		"replica":   replica,
+		"container": container,
+	})
+	if err != nil {
+		return 0, err
+	}
+	defer connection.Close()
+
+	// the socket doesn't support concurrent writes
+	writeMutex := &sync.Mutex{}
+	writeMessage := func(messageType int, message []byte) error {
+		writeMutex.Lock()
+		defer writeMutex.Unlock()
+		return connection.WriteMessage(messageType, message)
+	}
+
+	if stdin != nil {
+		routines.RunWithPanicHandler(func() {
+			buf := make([]byte, 32*1024)
+			for {
+				n, err := stdin.Read(buf)
+				if n > 0 {
+					if err := writeMessage(websocket.BinaryMessage, buf[:n]); err != nil {
+						return
+					}
+				}
+				if err != nil {
+					writeMessage(websocket.BinaryMessage, []byte{}) // closes the command's stdin
+					return
+				}
+			}
+		}, false)
+	}
+
+	if sizes != nil {
+		routines.RunWithPanicHandler(func() {
+			for size := range sizes {
+				message, err := json.Marshal(size)
+				if err != nil {
+					continue
+				}
+				if err := writeMessage(websocket.TextMessage, message); err != nil {
+					return
+				}
+			}
+		}, false)
+	}
+
+	for {
+		messageType, message, err := connection.ReadMessage()
+		if err != nil {
+			if closeErr, ok := err.(*websocket.CloseError); ok {
+				if closeErr.Code == websocket.CloseNormalClosure {
+					exitCode, ok := s.ParseInt(closeErr.Text)
+					if !ok {
+						return 0, nil
+					}
+					return exitCode, nil
+				}
+				return 0, ErrorExecFailed(closeErr.Text)
+			}
+			return 0, ErrorOperatorSocketRead(err)
+		}
+
+		if messageType == websocket.BinaryMessage {
+			stdout.Write(message)
+		} else {
+			stderr.Write(message)
+		}
+	}
+}
+
+// PortForward listens on localPort (on 127.0.0.1), and forwards each connection which it accepts to remotePort of a replica of the api;
+// it returns once the listener fails, or once stop is closed
+func PortForward(operatorConfig OperatorConfig, apiName string, replica string, localPort int, remotePort int, stop <-chan struct{}) error {
+	listener, err := net.Listen("tcp", fmt.Sprintf("127.0.0.1:%d", localPort))
+	if err != nil {
+		return ErrorPortForwardListen(localPort, err)
+	}
+
+	routines.RunWithPanicHandler(func() {
+		<-stop
+		listener.Close()
+	}, false)
+
+	for {
+		localConn, err := listener.Accept()
+		if err != nil {
+			select {
+			case <-stop:
+				return nil
+			default:
+			}
+			return ErrorPortForwardListen(localPort, err)
+		}
+
+		routines.RunWithPanicHandler(func() {
+			forwardConnection(operatorConfig, apiName, replica, remotePort, localConn)
+		}, false)
+	}
+}
+
+func forwardConnection(operatorConfig OperatorConfig, apiName string, replica string, remotePort int, localConn net.Conn) {
+	defer localConn.Close()
+
+	connection, err := openOperatorSocket(operatorConfig, "/portforward/"+apiName, map[string]string{
+		"port":    s.Int(remotePort),
+		"replica": replica,
+	})
+	if err != nil {
+		errors.PrintError(err)
+		return
+	}
+	defer connection.Close()
+
+	done := make(chan struct{}, 2)
+	routines.RunWithPanicHandler(func() {
+		defer func() { done <- struct{}{} }()
+		buf := make([]byte, 32*1024)
+		for {
+			n, err := localConn.Read(buf)
+			if n > 0 {
+				if err := connection.WriteMessage(websocket.BinaryMessage, buf[:n]); err != nil {
+					return
+				}
+			}
+			if err != nil {
+				return
+			}
+		}
+	}, false)
+	routines.RunWithPanicHandler(func() {
+		defer func() { done <- struct{}{} }()
+		for {
+			_, message, err := connection.ReadMessage()
+			if err != nil {
+				return
+			}
+			if _, err := localConn.Write(message); err != nil {
+				return
+			}
+		}
+	}, false)
+	<-done
+
+	connection.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+}
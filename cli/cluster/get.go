@@ -37,6 +37,39 @@ func GetAPIs(operatorConfig OperatorConfig) ([]schema.APIResponse, error) {
 	return apisRes, nil
 }
 
+// GetAPIsBySelector returns the apis whose labels match the label selector (e.g. team=nlp)
+func GetAPIsBySelector(operatorConfig OperatorConfig, selector string) ([]schema.APIResponse, error) {
+	httpRes, err := HTTPGet(operatorConfig, "/get", map[string]string{"selector": selector})
+	if err != nil {
+		return nil, err
+	}
+
+	var apisRes []schema.APIResponse
+	if err = json.Unmarshal(httpRes, &apisRes); err != nil {
+		return nil, errors.Wrap(err, "/get", string(httpRes))
+	}
+	return apisRes, nil
+}
+
+// GetAPINames only requests the names of the apis from the operator, which keeps the response small on clusters with many apis
+func GetAPINames(operatorConfig OperatorConfig) ([]string, error) {
+	httpRes, err := HTTPGet(operatorConfig, "/get", map[string]string{"fields": "spec.name"})
+	if err != nil {
+		return nil, err
+	}
+
+	var apisRes []schema.APIResponse
+	if err = json.Unmarshal(httpRes, &apisRes); err != nil {
+		return nil, errors.Wrap(err, "/get", string(httpRes))
+	}
+
+	apiNames := make([]string, 0, len(apisRes))
+	for _, api := range apisRes {
+		apiNames = append(apiNames, api.Spec.Name)
+	}
+	return apiNames, nil
+}
+
 func GetAPI(operatorConfig OperatorConfig, apiName string) ([]schema.APIResponse, error) {
 	httpRes, err := HTTPGet(operatorConfig, "/get/"+apiName)
 	if err != nil {
@@ -80,6 +113,21 @@ func GetBatchJob(operatorConfig OperatorConfig, apiName string, jobID string) (s
 	return jobRes, nil
 }
 
+func GetBatchJobResults(operatorConfig OperatorConfig, apiName string, jobID string) (schema.BatchJobResultsResponse, error) {
+	endpoint := path.Join("/batch", apiName, "results")
+	httpRes, err := HTTPGet(operatorConfig, endpoint, map[string]string{"jobID": jobID})
+	if err != nil {
+		return schema.BatchJobResultsResponse{}, err
+	}
+
+	var resultsRes schema.BatchJobResultsResponse
+	if err = json.Unmarshal(httpRes, &resultsRes); err != nil {
+		return schema.BatchJobResultsResponse{}, errors.Wrap(err, endpoint, string(httpRes))
+	}
+
+	return resultsRes, nil
+}
+
 func GetTaskJob(operatorConfig OperatorConfig, apiName string, jobID string) (schema.TaskJobResponse, error) {
 	endpoint := path.Join("/tasks", apiName)
 	httpRes, err := HTTPGet(operatorConfig, endpoint, map[string]string{"jobID": jobID})
@@ -94,3 +142,18 @@ func GetTaskJob(operatorConfig OperatorConfig, apiName string, jobID string) (sc
 
 	return jobRes, nil
 }
+
+// GetRecommendations returns right-sizing recommendations for the compute requests of the api's containers, based on their usage over the window (e.g. 7d)
+func GetRecommendations(operatorConfig OperatorConfig, apiName string, window string) (schema.RecommendationsResponse, error) {
+	httpRes, err := HTTPGet(operatorConfig, "/recommendations/"+apiName, map[string]string{"window": window})
+	if err != nil {
+		return schema.RecommendationsResponse{}, err
+	}
+
+	var recommendationsRes schema.RecommendationsResponse
+	if err = json.Unmarshal(httpRes, &recommendationsRes); err != nil {
+		return schema.RecommendationsResponse{}, errors.Wrap(err, "/recommendations/"+apiName, string(httpRes))
+	}
+
+	return recommendationsRes, nil
+}
@@ -0,0 +1,62 @@
+/*
+Copyright 2021 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/lib/json"
+	s "github.com/cortexlabs/cortex/pkg/lib/strings"
+	"github.com/cortexlabs/cortex/pkg/operator/schema"
+)
+
+func GetHistory(operatorConfig OperatorConfig, apiName string) (schema.HistoryResponse, error) {
+	httpRes, err := HTTPGet(operatorConfig, "/history/"+apiName)
+	if err != nil {
+		return schema.HistoryResponse{}, err
+	}
+
+	var historyRes schema.HistoryResponse
+	err = json.Unmarshal(httpRes, &historyRes)
+	if err != nil {
+		return schema.HistoryResponse{}, errors.Wrap(err, "/history", string(httpRes))
+	}
+
+	return historyRes, nil
+}
+
+// Rollback redeploys the given revision of the api, or the api's previous revision if revision is 0
+func Rollback(operatorConfig OperatorConfig, apiName string, revision int, force bool) (schema.RollbackResponse, error) {
+	params := map[string]string{
+		"force": s.Bool(force),
+	}
+	if revision > 0 {
+		params["revision"] = s.Int(revision)
+	}
+
+	httpRes, err := HTTPPostNoBody(operatorConfig, "/rollback/"+apiName, params)
+	if err != nil {
+		return schema.RollbackResponse{}, err
+	}
+
+	var rollbackRes schema.RollbackResponse
+	err = json.Unmarshal(httpRes, &rollbackRes)
+	if err != nil {
+		return schema.RollbackResponse{}, errors.Wrap(err, "/rollback", string(httpRes))
+	}
+
+	return rollbackRes, nil
+}
@@ -18,6 +18,7 @@ package cluster
 
 import (
 	"bytes"
+	"compress/gzip"
 	"crypto/tls"
 	"io"
 	"io/ioutil"
@@ -32,6 +33,7 @@ import (
 	"github.com/cortexlabs/cortex/pkg/lib/files"
 	"github.com/cortexlabs/cortex/pkg/lib/json"
 	"github.com/cortexlabs/cortex/pkg/operator/schema"
+	"github.com/klauspost/compress/zstd"
 )
 
 type OperatorClient struct {
@@ -43,6 +45,10 @@ type OperatorConfig struct {
 	ClientID         string
 	EnvName          string
 	OperatorEndpoint string
+	OperatorToken    string
+	OIDCSessionPath  string // set if the environment authenticates with OIDC
+	ResponseCacheDir string // if set, GET responses are cached here and requested conditionally (with If-None-Match)
+	AWSCredentials   aws.CredentialsConfig
 }
 
 func HTTPGet(operatorConfig OperatorConfig, endpoint string, qParams ...map[string]string) ([]byte, error) {
@@ -87,6 +93,11 @@ func HTTPDelete(operatorConfig OperatorConfig, endpoint string, qParams ...map[s
 	return makeOperatorRequest(operatorConfig, req)
 }
 
+// OperatorErrorMetadata is attached to the errors which are returned by the operator
+type OperatorErrorMetadata struct {
+	StatusCode int
+}
+
 type HTTPUploadInput struct {
 	FilePaths map[string]string
 	Bytes     map[string][]byte
@@ -178,16 +189,10 @@ func makeOperatorRequest(operatorConfig OperatorConfig, request *http.Request) (
 	}
 
 	request.Header.Set("CortexAPIVersion", consts.CortexVersion)
-	awsClient, err := aws.New()
-	if err != nil {
-		return nil, err
-	}
-
-	authHeader, err := awsClient.IdentityRequestAsHeader()
-	if err != nil {
+	request.Header.Set("Accept-Encoding", "zstd, gzip")
+	if err := setAuthHeader(operatorConfig, request.Header); err != nil {
 		return nil, err
 	}
-	request.Header.Set(consts.AuthHeader, authHeader)
 
 	timeout := 600 * time.Second
 	if request.URL.Path == "/info" {
@@ -201,14 +206,32 @@ func makeOperatorRequest(operatorConfig OperatorConfig, request *http.Request) (
 		},
 	}
 
+	var cached *cachedResponse
+	if request.Method == http.MethodGet && operatorConfig.ResponseCacheDir != "" {
+		cached = readCachedResponse(operatorConfig, request)
+		if cached != nil {
+			request.Header.Set("If-None-Match", cached.ETag)
+		}
+	}
+
 	response, err := client.Do(request)
 	if err != nil {
 		return nil, ErrorFailedToConnectOperator(err, operatorConfig.EnvName, operatorConfig.OperatorEndpoint)
 	}
 	defer response.Body.Close()
 
+	if response.StatusCode == http.StatusNotModified && cached != nil {
+		return cached.Body, nil
+	}
+
+	body, err := decompressedBody(response)
+	if err != nil {
+		return nil, errors.Wrap(err, _errStrRead)
+	}
+	defer body.Close()
+
 	if response.StatusCode != 200 {
-		bodyBytes, err := ioutil.ReadAll(response.Body)
+		bodyBytes, err := ioutil.ReadAll(body)
 		if err != nil {
 			return nil, errors.Wrap(err, _errStrRead)
 		}
@@ -222,13 +245,74 @@ func makeOperatorRequest(operatorConfig OperatorConfig, request *http.Request) (
 		return nil, errors.WithStack(&errors.Error{
 			Kind:        output.Kind,
 			Message:     output.Message,
+			Metadata:    OperatorErrorMetadata{StatusCode: response.StatusCode},
 			NoTelemetry: true,
 		})
 	}
 
-	bodyBytes, err := ioutil.ReadAll(response.Body)
+	bodyBytes, err := ioutil.ReadAll(body)
 	if err != nil {
 		return nil, errors.Wrap(err, _errStrRead)
 	}
+
+	if request.Method == http.MethodGet && operatorConfig.ResponseCacheDir != "" {
+		if etag := response.Header.Get("ETag"); etag != "" {
+			writeCachedResponse(operatorConfig, request, etag, bodyBytes)
+		}
+	}
+
 	return bodyBytes, nil
 }
+
+// decompressedBody decodes the response body according to its Content-Encoding; since Accept-Encoding is set explicitly
+// (to request zstd), the transport doesn't decompress gzip-encoded responses transparently
+func decompressedBody(response *http.Response) (io.ReadCloser, error) {
+	switch response.Header.Get("Content-Encoding") {
+	case "zstd":
+		decoder, err := zstd.NewReader(response.Body, zstd.WithDecoderConcurrency(1))
+		if err != nil {
+			return nil, err
+		}
+		return decoder.IOReadCloser(), nil
+	case "gzip":
+		reader, err := gzip.NewReader(response.Body)
+		if err == io.EOF {
+			return http.NoBody, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		return reader, nil
+	}
+	return ioutil.NopCloser(response.Body), nil
+}
+
+// setAuthHeader authenticates with the operator token or OIDC session if one is configured, and with the caller's AWS credentials otherwise
+func setAuthHeader(operatorConfig OperatorConfig, header http.Header) error {
+	if operatorConfig.OperatorToken != "" {
+		header.Set(consts.OperatorTokenHeader, operatorConfig.OperatorToken)
+		return nil
+	}
+
+	if operatorConfig.OIDCSessionPath != "" {
+		idToken, err := getOIDCIDToken(operatorConfig)
+		if err != nil {
+			return err
+		}
+		header.Set("Authorization", "Bearer "+idToken)
+		return nil
+	}
+
+	awsClient, err := aws.NewWithCredentials(operatorConfig.AWSCredentials)
+	if err != nil {
+		return err
+	}
+
+	authHeader, err := awsClient.IdentityRequestAsHeader()
+	if err != nil {
+		return err
+	}
+	header.Set(consts.AuthHeader, authHeader)
+
+	return nil
+}
@@ -19,6 +19,7 @@ package cluster
 import (
 	"crypto/tls"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"net/http"
 	"os"
@@ -27,7 +28,6 @@ import (
 
 	"github.com/cortexlabs/cortex/cli/lib/routines"
 	"github.com/cortexlabs/cortex/pkg/consts"
-	"github.com/cortexlabs/cortex/pkg/lib/aws"
 	"github.com/cortexlabs/cortex/pkg/lib/errors"
 	"github.com/cortexlabs/cortex/pkg/lib/exit"
 	"github.com/cortexlabs/cortex/pkg/lib/json"
@@ -63,6 +63,21 @@ func GetJobLogs(operatorConfig OperatorConfig, apiName string, jobID string) (sc
 	return logResponse, nil
 }
 
+// GetLogLines returns the log lines of a job or of a request (the query params are jobID or requestID, and optionally since)
+func GetLogLines(operatorConfig OperatorConfig, apiName string, queryParams map[string]string) (schema.LogLinesResponse, error) {
+	httpRes, err := HTTPGet(operatorConfig, "/logs/"+apiName+"/lines", queryParams)
+	if err != nil {
+		return schema.LogLinesResponse{}, err
+	}
+
+	var logLinesResponse schema.LogLinesResponse
+	if err = json.Unmarshal(httpRes, &logLinesResponse); err != nil {
+		return schema.LogLinesResponse{}, errors.Wrap(err, "/logs/"+apiName+"/lines", string(httpRes))
+	}
+
+	return logLinesResponse, nil
+}
+
 func StreamLogs(operatorConfig OperatorConfig, apiName string) error {
 	return streamLogs(operatorConfig, "/streamlogs/"+apiName)
 }
@@ -71,14 +86,68 @@ func StreamJobLogs(operatorConfig OperatorConfig, apiName string, jobID string)
 	return streamLogs(operatorConfig, "/streamlogs/"+apiName, map[string]string{"jobID": jobID})
 }
 
+// StreamLogsTo writes the api's logs to out until stop is closed (or the operator closes the connection)
+func StreamLogsTo(operatorConfig OperatorConfig, apiName string, out io.Writer, stop <-chan struct{}) error {
+	return streamLogsTo(operatorConfig, "/streamlogs/"+apiName, out, stop)
+}
+
+// StreamJobLogsTo writes the job's logs to out until stop is closed (or the operator closes the connection)
+func StreamJobLogsTo(operatorConfig OperatorConfig, apiName string, jobID string, out io.Writer, stop <-chan struct{}) error {
+	return streamLogsTo(operatorConfig, "/streamlogs/"+apiName, out, stop, map[string]string{"jobID": jobID})
+}
+
 func streamLogs(operatorConfig OperatorConfig, path string, qParams ...map[string]string) error {
 	interrupt := make(chan os.Signal, 1)
 	signal.Notify(interrupt, os.Interrupt)
 
-	req, err := operatorRequest(operatorConfig, "GET", path, nil, qParams...)
+	connection, err := openOperatorSocket(operatorConfig, path, qParams...)
+	if err != nil {
+		return err
+	}
+	defer connection.Close()
+
+	done := make(chan struct{})
+	handleConnection(connection, done)
+	closeConnection(connection, done, interrupt)
+	return nil
+}
+
+func streamLogsTo(operatorConfig OperatorConfig, path string, out io.Writer, stop <-chan struct{}, qParams ...map[string]string) error {
+	connection, err := openOperatorSocket(operatorConfig, path, qParams...)
 	if err != nil {
 		return err
 	}
+	defer connection.Close()
+
+	readErr := make(chan error, 1)
+	routines.RunWithPanicHandler(func() {
+		for {
+			_, message, err := connection.ReadMessage()
+			if err != nil {
+				readErr <- err
+				return
+			}
+			out.Write(message)
+		}
+	}, false)
+
+	select {
+	case err := <-readErr:
+		if websocket.IsCloseError(err, websocket.CloseNormalClosure) {
+			return nil
+		}
+		return ErrorOperatorSocketRead(err)
+	case <-stop:
+		connection.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+		return nil
+	}
+}
+
+func openOperatorSocket(operatorConfig OperatorConfig, path string, qParams ...map[string]string) (*websocket.Conn, error) {
+	req, err := operatorRequest(operatorConfig, "GET", path, nil, qParams...)
+	if err != nil {
+		return nil, err
+	}
 
 	values := req.URL.Query()
 	if operatorConfig.Telemetry {
@@ -91,16 +160,9 @@ func streamLogs(operatorConfig OperatorConfig, path string, qParams ...map[strin
 
 	header := http.Header{}
 	header.Set("CortexAPIVersion", consts.CortexVersion)
-	awsClient, err := aws.New()
-	if err != nil {
-		return err
-	}
-
-	authHeader, err := awsClient.IdentityRequestAsHeader()
-	if err != nil {
-		return err
+	if err := setAuthHeader(operatorConfig, header); err != nil {
+		return nil, err
 	}
-	header.Set(consts.AuthHeader, authHeader)
 
 	var dialer = websocket.Dialer{
 		TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
@@ -108,32 +170,29 @@ func streamLogs(operatorConfig OperatorConfig, path string, qParams ...map[strin
 
 	connection, response, err := dialer.Dial(wsURL, header)
 	if err != nil && response == nil {
-		return ErrorFailedToConnectOperator(err, operatorConfig.EnvName, strings.Replace(operatorConfig.OperatorEndpoint, "http", "ws", 1))
+		return nil, ErrorFailedToConnectOperator(err, operatorConfig.EnvName, strings.Replace(operatorConfig.OperatorEndpoint, "http", "ws", 1))
 	}
 	defer response.Body.Close()
 
 	if err != nil {
 		bodyBytes, err := ioutil.ReadAll(response.Body)
 		if err != nil || bodyBytes == nil || string(bodyBytes) == "" {
-			return ErrorFailedToConnectOperator(err, operatorConfig.EnvName, strings.Replace(operatorConfig.OperatorEndpoint, "http", "ws", 1))
+			return nil, ErrorFailedToConnectOperator(err, operatorConfig.EnvName, strings.Replace(operatorConfig.OperatorEndpoint, "http", "ws", 1))
 		}
 		var output schema.ErrorResponse
 		err = json.Unmarshal(bodyBytes, &output)
 		if err != nil || output.Message == "" {
-			return ErrorOperatorStreamResponseUnknown(string(bodyBytes), response.StatusCode)
+			return nil, ErrorOperatorStreamResponseUnknown(string(bodyBytes), response.StatusCode)
 		}
-		return errors.WithStack(&errors.Error{
+		return nil, errors.WithStack(&errors.Error{
 			Kind:        output.Kind,
 			Message:     output.Message,
+			Metadata:    OperatorErrorMetadata{StatusCode: response.StatusCode},
 			NoTelemetry: true,
 		})
 	}
-	defer connection.Close()
 
-	done := make(chan struct{})
-	handleConnection(connection, done)
-	closeConnection(connection, done, interrupt)
-	return nil
+	return connection, nil
 }
 
 func handleConnection(connection *websocket.Conn, done chan struct{}) {
@@ -0,0 +1,198 @@
+/*
+Copyright 2021 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"crypto/tls"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/lib/files"
+	"github.com/cortexlabs/cortex/pkg/lib/json"
+	"github.com/cortexlabs/cortex/pkg/lib/oidc"
+	"github.com/cortexlabs/cortex/pkg/lib/urls"
+	"github.com/cortexlabs/cortex/pkg/operator/schema"
+)
+
+// id tokens are refreshed if they expire within this period
+const _oidcRefreshMargin = time.Minute
+
+// OIDCSession contains an environment's OIDC tokens, and the provider settings which are needed to refresh them
+type OIDCSession struct {
+	IssuerURL    string   `json:"issuer_url"`
+	ClientID     string   `json:"client_id"`
+	Scopes       []string `json:"scopes"`
+	IDToken      string   `json:"id_token"`
+	RefreshToken string   `json:"refresh_token,omitempty"`
+}
+
+func (session *OIDCSession) client() (*oidc.Client, error) {
+	provider, err := oidc.Discover(session.IssuerURL)
+	if err != nil {
+		return nil, err
+	}
+
+	return &oidc.Client{
+		Provider: provider,
+		ClientID: session.ClientID,
+		Scopes:   session.Scopes,
+	}, nil
+}
+
+// GetOIDCConfig returns the cluster's OIDC settings (the request is not authenticated)
+func GetOIDCConfig(operatorEndpoint string) (schema.OIDCConfigResponse, error) {
+	client := http.Client{
+		Timeout: 10 * time.Second,
+		Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+		},
+	}
+
+	response, err := client.Get(urls.Join(operatorEndpoint, "/oidc"))
+	if err != nil {
+		return schema.OIDCConfigResponse{}, errors.Wrap(err, _errStrCantMakeRequest)
+	}
+	defer response.Body.Close()
+
+	bodyBytes, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return schema.OIDCConfigResponse{}, errors.Wrap(err, _errStrRead)
+	}
+
+	if response.StatusCode != http.StatusOK {
+		var output schema.ErrorResponse
+		if err := json.Unmarshal(bodyBytes, &output); err != nil || output.Message == "" {
+			return schema.OIDCConfigResponse{}, ErrorOperatorResponseUnknown(string(bodyBytes), response.StatusCode)
+		}
+		return schema.OIDCConfigResponse{}, errors.WithStack(&errors.Error{
+			Kind:        output.Kind,
+			Message:     output.Message,
+			NoTelemetry: true,
+		})
+	}
+
+	var oidcConfig schema.OIDCConfigResponse
+	if err := json.Unmarshal(bodyBytes, &oidcConfig); err != nil {
+		return schema.OIDCConfigResponse{}, errors.Wrap(err, "/oidc", string(bodyBytes))
+	}
+
+	return oidcConfig, nil
+}
+
+// LoginOIDC runs the authorization code flow (with PKCE); promptUser is called with the url which the user must visit
+func LoginOIDC(oidcConfig schema.OIDCConfigResponse, promptUser func(url string)) (*OIDCSession, error) {
+	session := OIDCSession{
+		IssuerURL: oidcConfig.IssuerURL,
+		ClientID:  oidcConfig.ClientID,
+		Scopes:    oidcConfig.Scopes,
+	}
+
+	client, err := session.client()
+	if err != nil {
+		return nil, err
+	}
+
+	request, err := client.StartAuthorization()
+	if err != nil {
+		return nil, err
+	}
+
+	promptUser(request.URL)
+
+	token, err := client.WaitForToken(request)
+	if err != nil {
+		return nil, err
+	}
+
+	session.IDToken = token.IDToken
+	session.RefreshToken = token.RefreshToken
+	return &session, nil
+}
+
+// ReadOIDCSession returns nil if the session doesn't exist (i.e. the user hasn't logged in)
+func ReadOIDCSession(path string) (*OIDCSession, error) {
+	if !files.IsFile(path) {
+		return nil, nil
+	}
+
+	sessionBytes, err := files.ReadFileBytes(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var session OIDCSession
+	if err := json.Unmarshal(sessionBytes, &session); err != nil {
+		return nil, errors.Wrap(err, path)
+	}
+
+	return &session, nil
+}
+
+// WriteOIDCSession writes the session with permissions which only allow the current user to read it, since it contains credentials
+func WriteOIDCSession(session *OIDCSession, path string) error {
+	sessionBytes, err := json.Marshal(session)
+	if err != nil {
+		return err
+	}
+
+	if err := ioutil.WriteFile(path, sessionBytes, 0600); err != nil {
+		return errors.Wrap(err, "unable to write file", path)
+	}
+
+	return nil
+}
+
+// getOIDCIDToken returns a valid id token, refreshing (and saving) the session if the current id token is about to expire
+func getOIDCIDToken(operatorConfig OperatorConfig) (string, error) {
+	sessionPath := operatorConfig.OIDCSessionPath
+
+	session, err := ReadOIDCSession(sessionPath)
+	if err != nil {
+		return "", err
+	}
+	if session == nil {
+		return "", ErrorOIDCSessionNotFound(operatorConfig.EnvName)
+	}
+
+	if expiresAt, ok := oidc.UnverifiedExpiresAt(session.IDToken); ok && time.Until(expiresAt) > _oidcRefreshMargin {
+		return session.IDToken, nil
+	}
+
+	if session.RefreshToken == "" {
+		return "", oidc.ErrorTokenExpired()
+	}
+
+	client, err := session.client()
+	if err != nil {
+		return "", err
+	}
+
+	token, err := client.Refresh(session.RefreshToken)
+	if err != nil {
+		return "", err
+	}
+
+	session.IDToken = token.IDToken
+	session.RefreshToken = token.RefreshToken
+	if err := WriteOIDCSession(session, sessionPath); err != nil {
+		return "", err
+	}
+
+	return session.IDToken, nil
+}
@@ -0,0 +1,66 @@
+/*
+Copyright 2021 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"net/http"
+	"path/filepath"
+
+	"github.com/cortexlabs/cortex/pkg/lib/files"
+	"github.com/cortexlabs/cortex/pkg/lib/hash"
+	"github.com/cortexlabs/cortex/pkg/lib/json"
+)
+
+// cachedResponse is a GET response from the operator; if the operator's response hasn't changed since (i.e. it has the same etag), the operator responds with 304 Not Modified and the cached body is used
+type cachedResponse struct {
+	ETag string `json:"etag"`
+	Body []byte `json:"body"`
+}
+
+func cachedResponsePath(operatorConfig OperatorConfig, request *http.Request) string {
+	return filepath.Join(operatorConfig.ResponseCacheDir, hash.Strings(operatorConfig.EnvName, request.URL.String())+".json")
+}
+
+// readCachedResponse returns nil if the response isn't cached (or the cache can't be read)
+func readCachedResponse(operatorConfig OperatorConfig, request *http.Request) *cachedResponse {
+	path := cachedResponsePath(operatorConfig, request)
+	if !files.IsFile(path) {
+		return nil
+	}
+
+	cachedBytes, err := files.ReadFileBytes(path)
+	if err != nil {
+		return nil
+	}
+
+	var cached cachedResponse
+	if err := json.Unmarshal(cachedBytes, &cached); err != nil || cached.ETag == "" {
+		return nil
+	}
+	return &cached
+}
+
+// writeCachedResponse ignores errors, since the cache is only an optimization
+func writeCachedResponse(operatorConfig OperatorConfig, request *http.Request, etag string, body []byte) {
+	cachedBytes, err := json.Marshal(cachedResponse{ETag: etag, Body: body})
+	if err != nil {
+		return
+	}
+
+	// the responses can contain e.g. the cluster configuration
+	files.WritePrivateFile(cachedBytes, cachedResponsePath(operatorConfig, request))
+}
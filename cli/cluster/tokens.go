@@ -0,0 +1,75 @@
+/*
+Copyright 2021 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/lib/json"
+	"github.com/cortexlabs/cortex/pkg/operator/schema"
+)
+
+func CreateOperatorToken(operatorConfig OperatorConfig, role string, apiPrefix string, description string, ttl string) (schema.CreateOperatorTokenResponse, error) {
+	params := map[string]string{
+		"role":        role,
+		"apiPrefix":   apiPrefix,
+		"description": description,
+		"ttl":         ttl,
+	}
+
+	httpRes, err := HTTPPostNoBody(operatorConfig, "/tokens", params)
+	if err != nil {
+		return schema.CreateOperatorTokenResponse{}, err
+	}
+
+	var createRes schema.CreateOperatorTokenResponse
+	err = json.Unmarshal(httpRes, &createRes)
+	if err != nil {
+		return schema.CreateOperatorTokenResponse{}, errors.Wrap(err, "/tokens", string(httpRes))
+	}
+
+	return createRes, nil
+}
+
+func ListOperatorTokens(operatorConfig OperatorConfig) (schema.OperatorTokensResponse, error) {
+	httpRes, err := HTTPGet(operatorConfig, "/tokens")
+	if err != nil {
+		return schema.OperatorTokensResponse{}, err
+	}
+
+	var listRes schema.OperatorTokensResponse
+	err = json.Unmarshal(httpRes, &listRes)
+	if err != nil {
+		return schema.OperatorTokensResponse{}, errors.Wrap(err, "/tokens", string(httpRes))
+	}
+
+	return listRes, nil
+}
+
+func RevokeOperatorToken(operatorConfig OperatorConfig, tokenID string) (schema.RevokeOperatorTokenResponse, error) {
+	httpRes, err := HTTPDelete(operatorConfig, "/tokens/"+tokenID)
+	if err != nil {
+		return schema.RevokeOperatorTokenResponse{}, err
+	}
+
+	var revokeRes schema.RevokeOperatorTokenResponse
+	err = json.Unmarshal(httpRes, &revokeRes)
+	if err != nil {
+		return schema.RevokeOperatorTokenResponse{}, errors.Wrap(err, "/tokens", string(httpRes))
+	}
+
+	return revokeRes, nil
+}
@@ -0,0 +1,160 @@
+/*
+Copyright 2021 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/cortexlabs/cortex/cli/cluster"
+	"github.com/cortexlabs/cortex/cli/types/flags"
+	"github.com/cortexlabs/cortex/pkg/lib/console"
+	"github.com/cortexlabs/cortex/pkg/lib/exit"
+	libjson "github.com/cortexlabs/cortex/pkg/lib/json"
+	s "github.com/cortexlabs/cortex/pkg/lib/strings"
+	"github.com/cortexlabs/cortex/pkg/lib/table"
+	"github.com/cortexlabs/cortex/pkg/lib/telemetry"
+	"github.com/cortexlabs/cortex/pkg/operator/schema"
+	"github.com/spf13/cobra"
+)
+
+const (
+	_titleTime      = "time"
+	_titleAction    = "action"
+	_titleAPI       = "api"
+	_titlePrincipal = "principal"
+	_titleClientID  = "client id"
+	_titleSourceIP  = "source ip"
+	_titleChanges   = "changes"
+)
+
+var (
+	_flagAuditEnv   string
+	_flagAuditSince string
+	_flagAuditLimit int
+)
+
+func auditInit() {
+	_auditListCmd.Flags().SortFlags = false
+	_auditListCmd.Flags().StringVarP(&_flagAuditEnv, "env", "e", "", "environment to use")
+	_auditListCmd.Flags().StringVar(&_flagAuditSince, "since", "168h", "only show events which occurred within this duration (e.g. 24h)")
+	_auditListCmd.Flags().IntVar(&_flagAuditLimit, "limit", 100, "maximum number of events to show")
+	_auditListCmd.Flags().VarP(&_flagOutput, "output", "o", fmt.Sprintf("output format: one of %s", strings.Join(flags.UserOutputTypeStrings(), "|")))
+	_auditCmd.AddCommand(_auditListCmd)
+}
+
+var _auditCmd = &cobra.Command{
+	Use:   "audit",
+	Short: "inspect the audit log of control-plane actions (contains subcommands)",
+}
+
+var _auditListCmd = &cobra.Command{
+	Use:               "list [API_NAME]",
+	Short:             "list deploy, update, delete, refresh, and rollback actions, most recent first",
+	Args:              cobra.RangeArgs(0, 1),
+	ValidArgsFunction: completeAPINameArg,
+	Run: func(cmd *cobra.Command, args []string) {
+		envName, err := getEnvFromFlag(_flagAuditEnv)
+		if err != nil {
+			telemetry.Event("cli.audit.list")
+			exit.Error(err)
+		}
+
+		env, err := ReadOrConfigureEnv(envName)
+		if err != nil {
+			telemetry.Event("cli.audit.list")
+			exit.Error(err)
+		}
+		telemetry.Event("cli.audit.list", map[string]interface{}{"env_name": env.Name})
+
+		err = printEnvIfNotSpecified(env.Name, cmd)
+		if err != nil {
+			exit.Error(err)
+		}
+
+		var apiName string
+		if len(args) == 1 {
+			apiName = args[0]
+		}
+
+		auditResponse, err := cluster.GetAuditEvents(MustGetOperatorConfig(env.Name), apiName, _flagAuditSince, _flagAuditLimit)
+		if err != nil {
+			exit.Error(err)
+		}
+
+		if _flagOutput == flags.JSONOutputType {
+			bytes, err := libjson.Marshal(auditResponse)
+			if err != nil {
+				exit.Error(err)
+			}
+			fmt.Print(string(bytes))
+			return
+		}
+
+		if len(auditResponse.Events) == 0 {
+			fmt.Println(console.Bold("no audit events found in the past " + _flagAuditSince))
+			return
+		}
+
+		t := auditEventsTable(auditResponse.Events)
+		fmt.Print(t.MustFormat())
+	},
+}
+
+func auditEventsTable(events []schema.AuditEvent) table.Table {
+	rows := make([][]interface{}, 0, len(events))
+	for _, event := range events {
+		changes := "-"
+		if event.Error != "" {
+			changes = "error: " + event.Error
+		} else if len(event.Diffs) > 0 {
+			changes = s.Int(len(event.Diffs)) + " " + s.PluralS("field", len(event.Diffs))
+		} else if event.Message != "" {
+			changes = event.Message
+		}
+
+		rows = append(rows, []interface{}{
+			event.Time.Local().Format("2006-01-02 15:04:05 MST"),
+			event.Action,
+			event.APIName,
+			event.Principal,
+			valueOrDash(event.ClientID),
+			valueOrDash(event.SourceIP),
+			changes,
+		})
+	}
+
+	return table.Table{
+		Headers: []table.Header{
+			{Title: _titleTime},
+			{Title: _titleAction},
+			{Title: _titleAPI},
+			{Title: _titlePrincipal, MaxWidth: 64},
+			{Title: _titleClientID},
+			{Title: _titleSourceIP},
+			{Title: _titleChanges, MaxWidth: 64},
+		},
+		Rows: rows,
+	}
+}
+
+func valueOrDash(val string) string {
+	if val == "" {
+		return "-"
+	}
+	return val
+}
@@ -0,0 +1,235 @@
+/*
+Copyright 2021 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/cortexlabs/cortex/cli/cluster"
+	"github.com/cortexlabs/cortex/cli/types/cliconfig"
+	"github.com/cortexlabs/cortex/cli/types/flags"
+	"github.com/cortexlabs/cortex/pkg/lib/console"
+	"github.com/cortexlabs/cortex/pkg/lib/exit"
+	libjson "github.com/cortexlabs/cortex/pkg/lib/json"
+	"github.com/cortexlabs/cortex/pkg/lib/print"
+	"github.com/cortexlabs/cortex/pkg/lib/table"
+	"github.com/cortexlabs/cortex/pkg/lib/telemetry"
+	libtime "github.com/cortexlabs/cortex/pkg/lib/time"
+	"github.com/cortexlabs/cortex/pkg/operator/schema"
+	"github.com/cortexlabs/cortex/pkg/types/rbac"
+	"github.com/spf13/cobra"
+)
+
+const (
+	_titleTokenID     = "id"
+	_titleRole        = "role"
+	_titleAPIPrefix   = "api prefix"
+	_titleDescription = "description"
+	_titleCreatedBy   = "created by"
+	_titleCreated     = "created"
+	_titleExpires     = "expires"
+)
+
+var (
+	_flagAuthEnv              string
+	_flagAuthTokenRole        string
+	_flagAuthTokenAPIPrefix   string
+	_flagAuthTokenDescription string
+	_flagAuthTokenTTL         string
+)
+
+func authInit() {
+	_authTokenCreateCmd.Flags().SortFlags = false
+	_authTokenCreateCmd.Flags().StringVarP(&_flagAuthEnv, "env", "e", "", "environment to use")
+	_authTokenCreateCmd.Flags().StringVar(&_flagAuthTokenRole, "role", "", fmt.Sprintf("the token's role: one of %s", strings.Join(rbac.RoleStrings(), "|")))
+	_authTokenCreateCmd.MarkFlagRequired("role")
+	_authTokenCreateCmd.Flags().StringVar(&_flagAuthTokenAPIPrefix, "api-prefix", "", "limit the token to apis whose names start with this prefix")
+	_authTokenCreateCmd.Flags().StringVar(&_flagAuthTokenDescription, "description", "", "a description of the token (e.g. the team which will use it)")
+	_authTokenCreateCmd.Flags().StringVar(&_flagAuthTokenTTL, "ttl", "", "how long the token is valid for (e.g. 720h); by default the token does not expire")
+	_authTokenCreateCmd.Flags().VarP(&_flagOutput, "output", "o", fmt.Sprintf("output format: one of %s", strings.Join(flags.UserOutputTypeStrings(), "|")))
+	_authTokenCmd.AddCommand(_authTokenCreateCmd)
+
+	_authTokenListCmd.Flags().SortFlags = false
+	_authTokenListCmd.Flags().StringVarP(&_flagAuthEnv, "env", "e", "", "environment to use")
+	_authTokenListCmd.Flags().VarP(&_flagOutput, "output", "o", fmt.Sprintf("output format: one of %s", strings.Join(flags.UserOutputTypeStrings(), "|")))
+	_authTokenCmd.AddCommand(_authTokenListCmd)
+
+	_authTokenRevokeCmd.Flags().SortFlags = false
+	_authTokenRevokeCmd.Flags().StringVarP(&_flagAuthEnv, "env", "e", "", "environment to use")
+	_authTokenRevokeCmd.Flags().VarP(&_flagOutput, "output", "o", fmt.Sprintf("output format: one of %s", strings.Join(flags.UserOutputTypeStrings(), "|")))
+	_authTokenCmd.AddCommand(_authTokenRevokeCmd)
+
+	_authCmd.AddCommand(_authTokenCmd)
+}
+
+var _authCmd = &cobra.Command{
+	Use:   "auth",
+	Short: "manage access to the cluster (contains subcommands)",
+}
+
+var _authTokenCmd = &cobra.Command{
+	Use:   "token",
+	Short: "manage operator tokens, which grant scoped access to the cluster (contains subcommands)",
+}
+
+var _authTokenCreateCmd = &cobra.Command{
+	Use:   "create",
+	Short: "create an operator token (requires cluster admin access)",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		env := mustGetAuthEnv(cmd, "cli.auth.token.create")
+
+		if rbac.RoleFromString(_flagAuthTokenRole) == rbac.UnknownRole {
+			exit.Error(ErrorInvalidRole(_flagAuthTokenRole))
+		}
+
+		createResponse, err := cluster.CreateOperatorToken(MustGetOperatorConfig(env.Name), _flagAuthTokenRole, _flagAuthTokenAPIPrefix, _flagAuthTokenDescription, _flagAuthTokenTTL)
+		if err != nil {
+			exit.Error(err)
+		}
+
+		if _flagOutput == flags.JSONOutputType {
+			bytes, err := libjson.Marshal(createResponse)
+			if err != nil {
+				exit.Error(err)
+			}
+			fmt.Print(string(bytes))
+			return
+		}
+
+		print.BoldFirstLine(fmt.Sprintf("created operator token %s (role: %s)", createResponse.OperatorToken.ID, createResponse.OperatorToken.Role))
+		fmt.Println("\n" + createResponse.Token + "\n")
+		fmt.Println("this token will not be shown again; it can be used by running `cortex env configure --operator-token <token>`, or by setting the CORTEX_OPERATOR_TOKEN environment variable")
+	},
+}
+
+var _authTokenListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "list operator tokens (requires cluster admin access)",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		env := mustGetAuthEnv(cmd, "cli.auth.token.list")
+
+		listResponse, err := cluster.ListOperatorTokens(MustGetOperatorConfig(env.Name))
+		if err != nil {
+			exit.Error(err)
+		}
+
+		if _flagOutput == flags.JSONOutputType {
+			bytes, err := libjson.Marshal(listResponse)
+			if err != nil {
+				exit.Error(err)
+			}
+			fmt.Print(string(bytes))
+			return
+		}
+
+		if len(listResponse.Tokens) == 0 {
+			fmt.Println(console.Bold("no operator tokens have been created"))
+			return
+		}
+
+		t := operatorTokensTable(listResponse.Tokens)
+		fmt.Print(t.MustFormat())
+	},
+}
+
+var _authTokenRevokeCmd = &cobra.Command{
+	Use:   "revoke TOKEN_ID",
+	Short: "revoke an operator token (requires cluster admin access)",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		env := mustGetAuthEnv(cmd, "cli.auth.token.revoke")
+
+		revokeResponse, err := cluster.RevokeOperatorToken(MustGetOperatorConfig(env.Name), args[0])
+		if err != nil {
+			exit.Error(err)
+		}
+
+		if _flagOutput == flags.JSONOutputType {
+			bytes, err := libjson.Marshal(revokeResponse)
+			if err != nil {
+				exit.Error(err)
+			}
+			fmt.Print(string(bytes))
+			return
+		}
+
+		print.BoldFirstLine(revokeResponse.Message)
+	},
+}
+
+func mustGetAuthEnv(cmd *cobra.Command, eventName string) cliconfig.Environment {
+	envName, err := getEnvFromFlag(_flagAuthEnv)
+	if err != nil {
+		telemetry.Event(eventName)
+		exit.Error(err)
+	}
+
+	env, err := ReadOrConfigureEnv(envName)
+	if err != nil {
+		telemetry.Event(eventName)
+		exit.Error(err)
+	}
+	telemetry.Event(eventName, map[string]interface{}{"env_name": env.Name})
+
+	err = printEnvIfNotSpecified(env.Name, cmd)
+	if err != nil {
+		exit.Error(err)
+	}
+
+	return env
+}
+
+func operatorTokensTable(tokens []schema.OperatorToken) table.Table {
+	rows := make([][]interface{}, 0, len(tokens))
+	for _, token := range tokens {
+		expires := "never"
+		if token.ExpiresAt != nil {
+			if token.IsExpired() {
+				expires = "expired"
+			} else {
+				expires = token.ExpiresAt.Local().Format("2006-01-02 15:04:05 MST")
+			}
+		}
+
+		createdAt := token.CreatedAt
+		rows = append(rows, []interface{}{
+			token.ID,
+			token.Role,
+			valueOrDash(token.APIPrefix),
+			valueOrDash(token.Description),
+			valueOrDash(token.CreatedBy),
+			libtime.SinceStr(&createdAt) + " ago",
+			expires,
+		})
+	}
+
+	return table.Table{
+		Headers: []table.Header{
+			{Title: _titleTokenID},
+			{Title: _titleRole},
+			{Title: _titleAPIPrefix},
+			{Title: _titleDescription, MaxWidth: 40},
+			{Title: _titleCreatedBy, MaxWidth: 64},
+			{Title: _titleCreated},
+			{Title: _titleExpires},
+		},
+		Rows: rows,
+	}
+}
@@ -0,0 +1,422 @@
+/*
+Copyright 2021 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/cortexlabs/cortex/pkg/lib/aws"
+	"github.com/cortexlabs/cortex/pkg/lib/docker"
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/lib/exit"
+	"github.com/cortexlabs/cortex/pkg/lib/files"
+	"github.com/cortexlabs/cortex/pkg/lib/regex"
+	"github.com/cortexlabs/cortex/pkg/lib/telemetry"
+	"github.com/cortexlabs/cortex/pkg/types/spec"
+	"github.com/docker/docker/builder/dockerignore"
+	"github.com/docker/docker/pkg/fileutils"
+	"github.com/spf13/cobra"
+)
+
+const (
+	_buildTagLength = 12
+
+	// BuildKit is required for the syntax directive and the pip cache mount
+	_buildDockerfileTemplate = `# syntax=docker/dockerfile:1
+FROM %s
+WORKDIR /app
+%sCOPY . .
+ENTRYPOINT ["python", "%s"]
+`
+	_buildRequirementsTemplate = `COPY requirements.txt ./
+RUN --mount=type=cache,target=/root/.cache/pip pip install -r requirements.txt
+`
+)
+
+var (
+	_flagBuildRepository string
+	_flagBuildEntrypoint string
+	_flagBuildBaseImage  string
+	_flagBuildSkipPush   bool
+	_flagBuildAPI        string
+	_flagBuildContainer  string
+	_flagBuildConfig     string
+)
+
+func buildInit() {
+	_buildCmd.Flags().SortFlags = false
+	_buildCmd.Flags().StringVarP(&_flagBuildRepository, "repository", "r", "", "repository to push the image to, e.g. <account_id>.dkr.ecr.<region>.amazonaws.com/my-api or docker.io/my-org/my-api")
+	_buildCmd.MarkFlagRequired("repository")
+	_buildCmd.Flags().StringVar(&_flagBuildEntrypoint, "entrypoint", "", "python file (relative to the build context) which starts your server; a Dockerfile is generated if the build context doesn't contain one")
+	_buildCmd.Flags().StringVar(&_flagBuildBaseImage, "base-image", "python:3.8-slim", "base image of the generated Dockerfile")
+	_buildCmd.Flags().BoolVar(&_flagBuildSkipPush, "skip-push", false, "build the image without pushing it")
+	_buildCmd.Flags().StringVar(&_flagBuildAPI, "api", "", "name of the api whose container image should be updated in the api configuration file")
+	_buildCmd.Flags().StringVar(&_flagBuildContainer, "container", "", "name of the container whose image should be updated (only required if the api has multiple containers)")
+	_buildCmd.Flags().StringVarP(&_flagBuildConfig, "config", "c", "cortex.yaml", "api configuration file to update")
+}
+
+var _buildCmd = &cobra.Command{
+	Use:   "build [CONTEXT_DIR]",
+	Short: "build and push an image for an api",
+	Args:  cobra.RangeArgs(0, 1),
+	Run: func(cmd *cobra.Command, args []string) {
+		telemetry.Event("cli.build")
+
+		contextDir := _cwd
+		if len(args) == 1 {
+			contextDir = files.RelToAbsPath(args[0], _cwd)
+		}
+		contextDir = strings.TrimSuffix(contextDir, "/")
+		if err := files.CheckDir(contextDir); err != nil {
+			exit.Error(err)
+		}
+
+		if _, err := exec.LookPath("docker"); err != nil {
+			exit.Error(ErrorDockerNotInstalled())
+		}
+		if _, err := docker.GetDockerClient(); err != nil {
+			exit.Error(err)
+		}
+
+		// each build gets its own directory for the generated Dockerfile, so that concurrent builds don't overwrite each other's
+		buildDir, err := ioutil.TempDir("", "cortex-build-")
+		if err != nil {
+			exit.Error(errors.WithStack(err))
+		}
+
+		image, err := buildImage(contextDir, buildDir)
+		os.RemoveAll(buildDir)
+		if err != nil {
+			exit.Error(err)
+		}
+
+		if !_flagBuildSkipPush {
+			if err := loginToECRIfNecessary(image); err != nil {
+				exit.Error(err)
+			}
+
+			fmt.Printf("￮ pushing %s\n\n", image)
+			if err := runDocker(nil, "push", image); err != nil {
+				exit.Error(err)
+			}
+			fmt.Println()
+		}
+
+		if _flagBuildAPI == "" {
+			fmt.Printf("built %s; set it as the image of your api's container, or run `cortex build` with the --api flag to update your api configuration automatically\n", image)
+			return
+		}
+
+		configPath := files.RelToAbsPath(_flagBuildConfig, _cwd)
+		if err := files.CheckFile(configPath); err != nil {
+			exit.Error(err)
+		}
+		containerName, err := updateContainerImageInConfig(configPath, _flagBuildAPI, _flagBuildContainer, image)
+		if err != nil {
+			exit.Error(err)
+		}
+
+		fmt.Printf("built %s and set it as the image of the %s container of the %s api in %s\n", image, containerName, _flagBuildAPI, _flagBuildConfig)
+	},
+}
+
+func buildImage(contextDir string, buildDir string) (string, error) {
+	dockerfilePath, dockerfileBytes, err := getBuildDockerfile(contextDir, _flagBuildEntrypoint, _flagBuildBaseImage, buildDir)
+	if err != nil {
+		return "", err
+	}
+
+	tag, err := buildContentHash(contextDir, dockerfileBytes)
+	if err != nil {
+		return "", err
+	}
+	image := _flagBuildRepository + ":" + tag
+
+	fmt.Printf("￮ building %s\n\n", image)
+	if err := runDocker([]string{"DOCKER_BUILDKIT=1"}, "build", "--file", dockerfilePath, "--tag", image, contextDir); err != nil {
+		return "", err
+	}
+	fmt.Println()
+
+	return image, nil
+}
+
+// getBuildDockerfile returns the build context's Dockerfile, or generates one in buildDir (outside of the build context, so that it isn't copied into the image)
+func getBuildDockerfile(contextDir string, entrypoint string, baseImage string, buildDir string) (string, []byte, error) {
+	dockerfilePath := filepath.Join(contextDir, "Dockerfile")
+	if files.IsFile(dockerfilePath) {
+		if entrypoint != "" {
+			return "", nil, ErrorBuildEntrypointWithDockerfile(dockerfilePath)
+		}
+		dockerfileBytes, err := files.ReadFileBytes(dockerfilePath)
+		if err != nil {
+			return "", nil, err
+		}
+		return dockerfilePath, dockerfileBytes, nil
+	}
+
+	if entrypoint == "" {
+		return "", nil, ErrorBuildEntrypointRequired(contextDir)
+	}
+
+	entrypointPath := files.RelToAbsPath(entrypoint, contextDir+"/")
+	if err := files.CheckFile(entrypointPath); err != nil {
+		return "", nil, err
+	}
+	relEntrypointPath := strings.TrimPrefix(entrypointPath, contextDir+"/")
+	if relEntrypointPath == entrypointPath {
+		return "", nil, ErrorBuildEntrypointNotInContext(entrypoint, contextDir)
+	}
+
+	requirements := ""
+	if files.IsFile(filepath.Join(contextDir, "requirements.txt")) {
+		requirements = _buildRequirementsTemplate
+	}
+	dockerfileBytes := []byte(fmt.Sprintf(_buildDockerfileTemplate, baseImage, requirements, relEntrypointPath))
+
+	dockerfilePath = filepath.Join(buildDir, "Dockerfile")
+	if err := files.WriteFile(dockerfileBytes, dockerfilePath); err != nil {
+		return "", nil, err
+	}
+
+	return dockerfilePath, dockerfileBytes, nil
+}
+
+// buildContentHash hashes the Dockerfile and the files which docker sends as the build context (i.e. all files which aren't excluded by .dockerignore),
+// including their relative paths and permissions, so that the image tag only changes when the image's contents change
+func buildContentHash(contextDir string, dockerfileBytes []byte) (string, error) {
+	excludes, err := readDockerignore(contextDir)
+	if err != nil {
+		return "", err
+	}
+	patternMatcher, err := fileutils.NewPatternMatcher(excludes)
+	if err != nil {
+		return "", errors.Wrap(err, filepath.Join(contextDir, ".dockerignore"))
+	}
+
+	sha := sha256.New()
+	sha.Write(dockerfileBytes)
+
+	// filepath.Walk() visits the files in lexical order, and doesn't follow symbolic links (which are copied as links)
+	err = filepath.Walk(contextDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		relPath, err := filepath.Rel(contextDir, path)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		if relPath == "." {
+			return nil
+		}
+
+		excluded, err := patternMatcher.Matches(relPath)
+		if err != nil {
+			return errors.Wrap(err, relPath)
+		}
+		if excluded {
+			// files in an excluded directory can still be included by an exception pattern (e.g. "!dir/file")
+			if info.IsDir() && !patternMatcher.Exclusions() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		fmt.Fprintf(sha, "%s\x00%s\x00", filepath.ToSlash(relPath), info.Mode().String())
+
+		switch {
+		case info.Mode()&os.ModeSymlink != 0:
+			target, err := os.Readlink(path)
+			if err != nil {
+				return errors.Wrap(err, relPath)
+			}
+			io.WriteString(sha, target)
+		case info.Mode().IsRegular():
+			f, err := files.Open(path)
+			if err != nil {
+				return err
+			}
+			_, err = io.Copy(sha, f)
+			f.Close()
+			if err != nil {
+				return errors.Wrap(err, relPath)
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(sha.Sum(nil))[:_buildTagLength], nil
+}
+
+func readDockerignore(contextDir string) ([]string, error) {
+	dockerignorePath := filepath.Join(contextDir, ".dockerignore")
+	if !files.IsFile(dockerignorePath) {
+		return nil, nil
+	}
+
+	f, err := files.Open(dockerignorePath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	excludes, err := dockerignore.ReadAll(f)
+	if err != nil {
+		return nil, errors.Wrap(err, dockerignorePath)
+	}
+	return excludes, nil
+}
+
+// images in other registries are pushed with the credentials of `docker login`
+func loginToECRIfNecessary(image string) error {
+	registry := docker.ExtractImageRegistry(image)
+	if !regex.IsValidECRURL(registry) {
+		return nil
+	}
+
+	awsClient, err := newAWSClient(aws.GetRegionFromECRURL(registry), aws.CredentialsConfig{}, false)
+	if err != nil {
+		return err
+	}
+	ecrAuthConfig, err := awsClient.GetECRAuthConfigForRegistry(registry)
+	if err != nil {
+		return err
+	}
+
+	cmd := exec.Command("docker", "login", "--username", ecrAuthConfig.Username, "--password-stdin", registry)
+	cmd.Stdin = strings.NewReader(ecrAuthConfig.AccessToken)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return errors.Wrap(errors.Append(err, "\n\n"+strings.TrimSpace(string(out))), "docker login "+registry)
+	}
+	return nil
+}
+
+// updateContainerImageInConfig replaces the image of the api's container in place, so that the rest of the file (including comments) is preserved
+func updateContainerImageInConfig(configPath string, apiName string, containerName string, image string) (string, error) {
+	configBytes, err := files.ReadFileBytes(configPath)
+	if err != nil {
+		return "", err
+	}
+
+	updatedConfigBytes, containerName, err := setContainerImage(configBytes, configPath, apiName, containerName, image)
+	if err != nil {
+		return "", err
+	}
+
+	if err := files.WriteFile(updatedConfigBytes, configPath); err != nil {
+		return "", err
+	}
+	return containerName, nil
+}
+
+func setContainerImage(configBytes []byte, configPath string, apiName string, containerName string, image string) ([]byte, string, error) {
+	apiConfigs, err := spec.ExtractAPIConfigs(configBytes, configPath)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var oldImage string
+	var numContainersWithOldImage int
+	apiFound := false
+	for _, apiConfig := range apiConfigs {
+		if apiConfig.Name != apiName {
+			continue
+		}
+		apiFound = true
+
+		if apiConfig.Pod == nil || len(apiConfig.Pod.Containers) == 0 {
+			return nil, "", ErrorBuildAPIHasNoContainers(apiName)
+		}
+
+		var containerNames []string
+		for _, container := range apiConfig.Pod.Containers {
+			containerNames = append(containerNames, container.Name)
+		}
+		if containerName == "" {
+			if len(apiConfig.Pod.Containers) > 1 {
+				return nil, "", ErrorBuildContainerNameMustBeProvided(apiName, containerNames)
+			}
+			containerName = apiConfig.Pod.Containers[0].Name
+		}
+
+		for _, container := range apiConfig.Pod.Containers {
+			if container.Name == containerName {
+				oldImage = container.Image
+			}
+		}
+		if oldImage == "" {
+			return nil, "", ErrorBuildContainerNotFound(containerName, apiName, containerNames)
+		}
+	}
+	if !apiFound {
+		return nil, "", ErrorAPINotFoundInConfig(apiName)
+	}
+
+	for _, apiConfig := range apiConfigs {
+		if apiConfig.Pod == nil {
+			continue
+		}
+		for _, container := range apiConfig.Pod.Containers {
+			if container.Image == oldImage {
+				numContainersWithOldImage++
+			}
+		}
+		for _, container := range apiConfig.Pod.InitContainers {
+			if container.Image == oldImage {
+				numContainersWithOldImage++
+			}
+		}
+	}
+	if numContainersWithOldImage > 1 {
+		return nil, "", ErrorBuildImageNotUniqueInConfig(oldImage, configPath)
+	}
+
+	imageRegex := regexp.MustCompile(`(?m)^(\s*(?:-\s*)?image:\s*["']?)` + regexp.QuoteMeta(oldImage) + `(["']?[ \t]*(?:#.*)?)$`)
+	matches := imageRegex.FindAllIndex(configBytes, -1)
+	if len(matches) == 0 {
+		return nil, "", ErrorBuildImageNotFoundInConfig(oldImage, configPath)
+	}
+	if len(matches) > 1 {
+		return nil, "", ErrorBuildImageNotUniqueInConfig(oldImage, configPath)
+	}
+
+	return imageRegex.ReplaceAll(configBytes, []byte("${1}"+image+"${2}")), containerName, nil
+}
+
+func runDocker(env []string, args ...string) error {
+	cmd := exec.Command("docker", args...)
+	cmd.Env = append(os.Environ(), env...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return errors.Wrap(err, "docker "+args[0])
+	}
+	return nil
+}
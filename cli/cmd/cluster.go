@@ -32,9 +32,9 @@ import (
 	"github.com/cortexlabs/cortex/cli/types/cliconfig"
 	"github.com/cortexlabs/cortex/cli/types/flags"
 	"github.com/cortexlabs/cortex/pkg/consts"
+	"github.com/cortexlabs/cortex/pkg/lib/archive"
 	"github.com/cortexlabs/cortex/pkg/lib/aws"
 	"github.com/cortexlabs/cortex/pkg/lib/console"
-	"github.com/cortexlabs/cortex/pkg/lib/docker"
 	"github.com/cortexlabs/cortex/pkg/lib/errors"
 	"github.com/cortexlabs/cortex/pkg/lib/exit"
 	"github.com/cortexlabs/cortex/pkg/lib/files"
@@ -42,6 +42,7 @@ import (
 	libmath "github.com/cortexlabs/cortex/pkg/lib/math"
 	"github.com/cortexlabs/cortex/pkg/lib/pointer"
 	"github.com/cortexlabs/cortex/pkg/lib/prompt"
+	"github.com/cortexlabs/cortex/pkg/lib/slices"
 	s "github.com/cortexlabs/cortex/pkg/lib/strings"
 	"github.com/cortexlabs/cortex/pkg/lib/table"
 	"github.com/cortexlabs/cortex/pkg/lib/telemetry"
@@ -55,6 +56,7 @@ import (
 
 var (
 	_flagClusterUpEnv                string
+	_flagClusterInstallKubeconfig    string
 	_flagClusterInfoEnv              string
 	_flagClusterScaleNodeGroup       string
 	_flagClusterScaleMinInstances    int64
@@ -63,18 +65,51 @@ var (
 	_flagClusterName                 string
 	_flagClusterRegion               string
 	_flagClusterInfoDebug            bool
+	_flagClusterInfoDebugRedact      bool
+	_flagClusterInfoDebugCollectors  []string
+	_flagClusterInfoPricingCoverage  string
+	_flagClusterInfoCostExplorer     bool
 	_flagClusterDisallowPrompt       bool
 	_flagClusterDownKeepAWSResources bool
+	_flagClusterDownReport           string
+	_flagClusterGCOlderThan          string
+	_flagClusterGCDryRun             bool
+	_flagClusterGCIncludeRetained    bool
+	_flagClusterValidateReport       string
+	_flagClusterSnapshotS3Path       string
+	_flagClusterSnapshotSaveConfig   string
+	_flagClusterSnapshotForce        bool
+	_flagClusterAWSEnv               string
+	_flagClusterAWSRoleARN           string
+	_flagClusterAWSExternalID        string
+	_flagClusterAWSMFASerial         string
+	_flagClusterRunRemote            bool
 )
 
+const _containerKubeconfigPath = "/root/.kube/config"
+
+var _debugCollectors = []string{"manifests", "events", "logs", "metrics", "aws"}
+
 var _eksctlPrefixRegex = regexp.MustCompile(`^.*[0-9]{4}-[0-9]{2}-[0-9]{2} [0-9]{2}:[0-9]{2}:[0-9]{2} \[.+] {2}`)
 
 func clusterInit() {
 	_clusterUpCmd.Flags().SortFlags = false
 	_clusterUpCmd.Flags().StringVarP(&_flagClusterUpEnv, "configure-env", "e", "", "name of environment to configure (default: the name of your cluster)")
 	_clusterUpCmd.Flags().BoolVarP(&_flagClusterDisallowPrompt, "yes", "y", false, "skip prompts")
+	_clusterUpCmd.Flags().VarP(&_flagOutput, "output", "o", fmt.Sprintf("output format: one of %s (json prints the progress as one event per line, and everything else to stderr)", strings.Join(flags.UserOutputTypeStrings(), "|")))
+	addClusterAWSCredentialsFlags(_clusterUpCmd)
+	addClusterRunRemoteFlag(_clusterUpCmd)
 	_clusterCmd.AddCommand(_clusterUpCmd)
 
+	_clusterInstallCmd.Flags().SortFlags = false
+	_clusterInstallCmd.Flags().StringVar(&_flagClusterInstallKubeconfig, "kubeconfig", "", "path to a kubeconfig file with admin access to the existing cluster")
+	_clusterInstallCmd.MarkFlagRequired("kubeconfig")
+	_clusterInstallCmd.Flags().StringVarP(&_flagClusterUpEnv, "configure-env", "e", "", "name of environment to configure (default: the name of your cluster)")
+	_clusterInstallCmd.Flags().BoolVarP(&_flagClusterDisallowPrompt, "yes", "y", false, "skip prompts")
+	addClusterAWSCredentialsFlags(_clusterInstallCmd)
+	addClusterRunRemoteFlag(_clusterInstallCmd)
+	_clusterCmd.AddCommand(_clusterInstallCmd)
+
 	_clusterInfoCmd.Flags().SortFlags = false
 	addClusterConfigFlag(_clusterInfoCmd)
 	addClusterNameFlag(_clusterInfoCmd)
@@ -82,7 +117,13 @@ func clusterInit() {
 	_clusterInfoCmd.Flags().VarP(&_flagOutput, "output", "o", fmt.Sprintf("output format: one of %s", strings.Join(flags.UserOutputTypeStrings(), "|")))
 	_clusterInfoCmd.Flags().StringVarP(&_flagClusterInfoEnv, "configure-env", "e", "", "name of environment to configure")
 	_clusterInfoCmd.Flags().BoolVarP(&_flagClusterInfoDebug, "debug", "d", false, "save the current cluster state to a file")
+	_clusterInfoCmd.Flags().BoolVar(&_flagClusterInfoDebugRedact, "debug-redact", false, "redact environment variable values and configmap data from the debug file (requires --debug)")
+	_clusterInfoCmd.Flags().StringSliceVar(&_flagClusterInfoDebugCollectors, "debug-collectors", _debugCollectors, fmt.Sprintf("comma-separated list of data to include in the debug file: %s (requires --debug)", strings.Join(_debugCollectors, "|")))
+	_clusterInfoCmd.Flags().StringVar(&_flagClusterInfoPricingCoverage, "pricing-coverage", "", "path to a file describing your reserved instances and savings plan, to show the cluster's effective cost")
+	_clusterInfoCmd.Flags().BoolVar(&_flagClusterInfoCostExplorer, "cost-explorer", false, fmt.Sprintf("show the cluster's effective cost based on the instance prices which were paid over the past %d days according to aws cost explorer (cost explorer charges for each request)", _costExplorerDays))
 	_clusterInfoCmd.Flags().BoolVarP(&_flagClusterDisallowPrompt, "yes", "y", false, "skip prompts")
+	addClusterAWSCredentialsFlags(_clusterInfoCmd)
+	addClusterRunRemoteFlag(_clusterInfoCmd)
 	_clusterCmd.AddCommand(_clusterInfoCmd)
 
 	_clusterScaleCmd.Flags().SortFlags = false
@@ -90,6 +131,8 @@ func clusterInit() {
 	addClusterRegionFlag(_clusterScaleCmd)
 	addClusterScaleFlags(_clusterScaleCmd)
 	_clusterScaleCmd.Flags().BoolVarP(&_flagClusterDisallowPrompt, "yes", "y", false, "skip prompts")
+	addClusterAWSCredentialsFlags(_clusterScaleCmd)
+	addClusterRunRemoteFlag(_clusterScaleCmd)
 	_clusterCmd.AddCommand(_clusterScaleCmd)
 
 	_clusterDownCmd.Flags().SortFlags = false
@@ -97,14 +140,78 @@ func clusterInit() {
 	addClusterNameFlag(_clusterDownCmd)
 	addClusterRegionFlag(_clusterDownCmd)
 	_clusterDownCmd.Flags().BoolVarP(&_flagClusterDisallowPrompt, "yes", "y", false, "skip prompts")
-	_clusterDownCmd.Flags().BoolVar(&_flagClusterDownKeepAWSResources, "keep-aws-resources", false, "skip deletion of resources that cortex provisioned on aws (bucket contents, ebs volumes, log group)")
+	_clusterDownCmd.Flags().BoolVar(&_flagClusterDownKeepAWSResources, "keep-aws-resources", false, "skip deletion of resources that cortex provisioned on aws (bucket contents, ebs volumes, efs file system, log group)")
+	_clusterDownCmd.Flags().StringVar(&_flagClusterDownReport, "report", "", "path to save a json report of the resources which were and weren't deleted")
+	addClusterAWSCredentialsFlags(_clusterDownCmd)
+	addClusterRunRemoteFlag(_clusterDownCmd)
 	_clusterCmd.AddCommand(_clusterDownCmd)
 
+	_clusterGCCmd.Flags().SortFlags = false
+	addClusterRegionFlag(_clusterGCCmd)
+	_clusterGCCmd.MarkFlagRequired("region")
+	_clusterGCCmd.Flags().StringVar(&_flagClusterGCOlderThan, "older-than", "1h", "only delete resources which were created at least this long ago (e.g. 30d or 12h)")
+	_clusterGCCmd.Flags().BoolVar(&_flagClusterGCDryRun, "dry-run", false, "list the orphaned resources without deleting them")
+	_clusterGCCmd.Flags().BoolVar(&_flagClusterGCIncludeRetained, "include-retained", false, "also delete the resources which hold data (s3 buckets, ebs volumes, efs file systems, and log groups), e.g. those kept by `cortex cluster down --keep-aws-resources`")
+	_clusterGCCmd.Flags().BoolVarP(&_flagClusterDisallowPrompt, "yes", "y", false, "skip prompts")
+	addClusterAWSCredentialsFlags(_clusterGCCmd)
+	_clusterCmd.AddCommand(_clusterGCCmd)
+
+	_clusterValidateCmd.Flags().SortFlags = false
+	_clusterValidateCmd.Flags().StringVar(&_flagClusterValidateReport, "report", "", "path to save a json report of the checks")
+	addClusterAWSCredentialsFlags(_clusterValidateCmd)
+	_clusterCmd.AddCommand(_clusterValidateCmd)
+
+	_clusterSnapshotCreateCmd.Flags().SortFlags = false
+	addClusterConfigFlag(_clusterSnapshotCreateCmd)
+	addClusterNameFlag(_clusterSnapshotCreateCmd)
+	addClusterRegionFlag(_clusterSnapshotCreateCmd)
+	addClusterSnapshotS3PathFlag(_clusterSnapshotCreateCmd)
+	addClusterAWSCredentialsFlags(_clusterSnapshotCreateCmd)
+	_clusterSnapshotCmd.AddCommand(_clusterSnapshotCreateCmd)
+
+	_clusterSnapshotListCmd.Flags().SortFlags = false
+	addClusterConfigFlag(_clusterSnapshotListCmd)
+	addClusterNameFlag(_clusterSnapshotListCmd)
+	addClusterRegionFlag(_clusterSnapshotListCmd)
+	addClusterSnapshotS3PathFlag(_clusterSnapshotListCmd)
+	_clusterSnapshotListCmd.Flags().VarP(&_flagOutput, "output", "o", fmt.Sprintf("output format: one of %s", strings.Join(flags.UserOutputTypeStrings(), "|")))
+	addClusterAWSCredentialsFlags(_clusterSnapshotListCmd)
+	_clusterSnapshotCmd.AddCommand(_clusterSnapshotListCmd)
+
+	_clusterSnapshotRestoreCmd.Flags().SortFlags = false
+	addClusterConfigFlag(_clusterSnapshotRestoreCmd)
+	addClusterNameFlag(_clusterSnapshotRestoreCmd)
+	addClusterRegionFlag(_clusterSnapshotRestoreCmd)
+	addClusterSnapshotS3PathFlag(_clusterSnapshotRestoreCmd)
+	_clusterSnapshotRestoreCmd.Flags().BoolVarP(&_flagClusterSnapshotForce, "force", "f", false, "override the in-progress api updates")
+	_clusterSnapshotRestoreCmd.Flags().StringVar(&_flagClusterSnapshotSaveConfig, "save-cluster-config", "", "path to save the snapshot's cluster configuration (e.g. to create a new cluster with the same configuration)")
+	_clusterSnapshotRestoreCmd.Flags().BoolVarP(&_flagClusterDisallowPrompt, "yes", "y", false, "skip prompts")
+	addClusterAWSCredentialsFlags(_clusterSnapshotRestoreCmd)
+	_clusterSnapshotCmd.AddCommand(_clusterSnapshotRestoreCmd)
+
+	_clusterCmd.AddCommand(_clusterSnapshotCmd)
+
 	_clusterExportCmd.Flags().SortFlags = false
 	addClusterConfigFlag(_clusterExportCmd)
 	addClusterNameFlag(_clusterExportCmd)
 	addClusterRegionFlag(_clusterExportCmd)
+	addClusterAWSCredentialsFlags(_clusterExportCmd)
 	_clusterCmd.AddCommand(_clusterExportCmd)
+
+	_clusterNodeShellCmd.Flags().SortFlags = false
+	addClusterConfigFlag(_clusterNodeShellCmd)
+	addClusterNameFlag(_clusterNodeShellCmd)
+	addClusterRegionFlag(_clusterNodeShellCmd)
+	addClusterAWSCredentialsFlags(_clusterNodeShellCmd)
+	_clusterCmd.AddCommand(_clusterNodeShellCmd)
+
+	_clusterKubeconfigCmd.Flags().SortFlags = false
+	addClusterConfigFlag(_clusterKubeconfigCmd)
+	addClusterNameFlag(_clusterKubeconfigCmd)
+	addClusterRegionFlag(_clusterKubeconfigCmd)
+	addClusterKubeconfigFlags(_clusterKubeconfigCmd)
+	addClusterAWSCredentialsFlags(_clusterKubeconfigCmd)
+	_clusterCmd.AddCommand(_clusterKubeconfigCmd)
 }
 
 func addClusterConfigFlag(cmd *cobra.Command) {
@@ -120,6 +227,21 @@ func addClusterRegionFlag(cmd *cobra.Command) {
 	cmd.Flags().StringVarP(&_flagClusterRegion, "region", "r", "", "aws region of the cluster")
 }
 
+func addClusterAWSCredentialsFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&_flagClusterAWSEnv, "aws-env", "", "use the aws profile/role bound to this environment (default: the profile/role bound to the cluster's environment, if any)")
+	cmd.Flags().StringVar(&_flagClusterAWSRoleARN, "aws-role-arn", "", "assume this aws role (overrides assume_role.role_arn in the cluster configuration)")
+	cmd.Flags().StringVar(&_flagClusterAWSExternalID, "aws-external-id", "", "external id to pass when assuming the aws role")
+	cmd.Flags().StringVar(&_flagClusterAWSMFASerial, "aws-mfa-serial", "", "mfa device to authenticate with when assuming the aws role (the token code is prompted for)")
+}
+
+func addClusterSnapshotS3PathFlag(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&_flagClusterSnapshotS3Path, "s3-path", "", "s3 path under which the snapshots are stored (default: s3://<cluster bucket>/snapshots)")
+}
+
+func addClusterRunRemoteFlag(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&_flagClusterRunRemote, "run-remote", false, "run the cluster manager in aws codebuild instead of a local docker container")
+}
+
 func addClusterScaleFlags(cmd *cobra.Command) {
 	cmd.Flags().StringVar(&_flagClusterScaleNodeGroup, "node-group", "", "name of the node group to scale")
 	cmd.MarkFlagRequired("node-group")
@@ -139,9 +261,14 @@ var _clusterUpCmd = &cobra.Command{
 	Run: func(cmd *cobra.Command, args []string) {
 		telemetry.EventNotify("cli.cluster.up")
 
+		// stdout is reserved for the progress events (and errors) in json mode
+		if _flagOutput == flags.JSONOutputType {
+			os.Stdout = os.Stderr
+		}
+
 		clusterConfigFile := args[0]
 
-		if _, err := docker.GetDockerClient(); err != nil {
+		if err := assertManagerCanRun(); err != nil {
 			exit.Error(err)
 		}
 
@@ -167,63 +294,118 @@ var _clusterUpCmd = &cobra.Command{
 			}
 		}
 
-		awsClient, err := newAWSClient(accessConfig.Region, true)
+		awsCredentials, err := getClusterAWSCredentials(envName)
 		if err != nil {
 			exit.Error(err)
 		}
 
-		clusterConfig, err := getInstallClusterConfig(awsClient, clusterConfigFile, _flagClusterDisallowPrompt)
+		awsClient, err := newAWSClient(accessConfig.Region, withClusterAssumeRole(awsCredentials, accessConfig), true)
 		if err != nil {
 			exit.Error(err)
 		}
 
-		clusterState, err := clusterstate.GetClusterState(awsClient, accessConfig)
+		clusterConfig, err := getInstallClusterConfig(awsClient, clusterConfigFile, _flagClusterDisallowPrompt)
 		if err != nil {
 			exit.Error(err)
 		}
 
-		err = clusterstate.AssertClusterStatus(accessConfig.ClusterName, accessConfig.Region, clusterState.Status, clusterstate.StatusNotFound, clusterstate.StatusDeleteComplete)
+		clusterState, err := clusterstate.GetClusterState(awsClient, accessConfig)
 		if err != nil {
 			exit.Error(err)
 		}
 
+		// a previous `cluster up` which failed after the control plane was created can be resumed
+		var upState *clusterUpState
+		if clusterState.Status == clusterstate.StatusNodeGroupCreateFailed || clusterState.Status == clusterstate.StatusCreateComplete || clusterState.Status == clusterstate.StatusUpdateComplete {
+			upState, err = readClusterUpState(awsClient, clusterConfig.Bucket)
+			if err != nil {
+				exit.Error(err)
+			}
+		}
+		resumePhase := clusterUpResumePhase(upState, clusterState.Status)
+
+		if resumePhase == "" {
+			err = clusterstate.AssertClusterStatus(accessConfig.ClusterName, accessConfig.Region, clusterState.Status, clusterstate.StatusNotFound, clusterstate.StatusDeleteComplete)
+			if err != nil {
+				exit.Error(err)
+			}
+		} else {
+			// the cluster's data in the bucket is stored under the uid which was assigned when the cluster was first created
+			clusterConfig.ClusterUID = upState.ClusterUID
+			fmt.Printf("a previous attempt to create the cluster named \"%s\" in %s didn't complete; resuming from the %s phase\n\n", clusterConfig.ClusterName, clusterConfig.Region, clusterPhaseTitle(resumePhase))
+		}
+
 		err = createS3BucketIfNotFound(awsClient, clusterConfig.Bucket, clusterConfig.Tags)
 		if err != nil {
 			exit.Error(err)
 		}
 
-		err = setLifecycleRulesOnClusterUp(awsClient, clusterConfig.Bucket, clusterConfig.ClusterUID)
-		if err != nil {
-			exit.Error(err)
+		// the lifecycle rules were applied when the cluster was first created (applying them again would expire the cluster's data)
+		if resumePhase == "" {
+			err = setLifecycleRulesOnClusterUp(awsClient, clusterConfig.Bucket, clusterConfig.ClusterUID)
+			if err != nil {
+				exit.Error(err)
+			}
 		}
 
-		err = createLogGroupIfNotFound(awsClient, clusterConfig.ClusterName, clusterConfig.Tags)
+		err = createLogGroupIfNotFound(awsClient, clusterConfig.ClusterName, clusterConfig.Tags, clusterConfig.LogRetentionDays())
 		if err != nil {
 			exit.Error(err)
 		}
 
+		if clusterConfig.EFS != nil {
+			clusterConfig.EFSFileSystemID, err = createEFSFileSystemIfNotFound(awsClient, clusterConfig.ClusterName, clusterConfig.Region, clusterConfig.EFS.PerformanceMode, clusterConfig.Tags)
+			if err != nil {
+				exit.Error(err)
+			}
+		}
+
 		accountID, _, err := awsClient.GetCachedAccountID()
 		if err != nil {
 			exit.Error(err)
 		}
 
-		err = clusterconfig.CreateDefaultPolicy(awsClient, clusterconfig.CortexPolicyTemplateArgs{
-			ClusterName: clusterConfig.ClusterName,
-			LogGroup:    clusterConfig.ClusterName,
-			Bucket:      clusterConfig.Bucket,
-			Region:      clusterConfig.Region,
-			AccountID:   accountID,
-		})
-		if err != nil {
-			exit.Error(err)
+		// the completed phases are recorded in the bucket so that a failed `cluster up` can be resumed (this is best-effort)
+		progress := newClusterProgress(_clusterUpPhases, _flagOutput)
+		progress.onUpdate = func(completed []string, failed string) {
+			writeClusterUpState(awsClient, clusterConfig.Bucket, clusterUpState{
+				ClusterUID:      clusterConfig.ClusterUID,
+				CompletedPhases: completed,
+				FailedPhase:     failed,
+			})
 		}
 
-		out, exitCode, err := runManagerWithClusterConfig("/root/install.sh", clusterConfig, awsClient, nil, nil, nil)
+		managerEnvs := []string{"CORTEX_PROGRESS_EVENTS=true"}
+		if resumePhase == "" {
+			writeClusterUpState(awsClient, clusterConfig.Bucket, clusterUpState{ClusterUID: clusterConfig.ClusterUID, CompletedPhases: []string{}})
+			progress.StartPhase(_phaseIAM)
+
+			err = createCortexPolicy(awsClient, clusterConfig, accountID)
+			if err != nil {
+				progress.Fail()
+				exit.Error(err)
+			}
+		} else {
+			progress.ResumeFromPhase(resumePhase)
+			managerEnvs = append(managerEnvs, "CORTEX_RESUME_PHASE="+resumePhase)
+		}
+
+		_managerOutput = progress
+		out, exitCode, err := runManagerWithClusterConfig("/root/install.sh", clusterConfig, awsClient, nil, nil, nil, managerEnvs)
+		_managerOutput = nil
 		if err != nil {
+			progress.Fail()
 			exit.Error(err)
 		}
 		if exitCode == nil || *exitCode != 0 {
+			failedPhase := progress.Fail()
 			out = filterEKSCTLOutput(out)
+
+			retryHelpStr := "please run `cortex cluster down` to delete the cluster before trying to create this cluster again"
+			if failedPhase != "" && clusterPhaseIndex(failedPhase) >= clusterPhaseIndex(_phaseNodeGroups) {
+				retryHelpStr = fmt.Sprintf("once the issue has been resolved (e.g. by changing the instance types in your cluster configuration or by requesting a quota increase), run `cortex cluster up` again to resume from the %s phase; alternatively, run `cortex cluster down` to delete the cluster", clusterPhaseTitle(failedPhase))
+			}
+
 			eksCluster, err := awsClient.EKSClusterOrNil(clusterConfig.ClusterName)
 			if err != nil {
 				helpStr := "\ndebugging tips (may or may not apply to this error):"
@@ -243,14 +425,14 @@ var _clusterUpCmd = &cobra.Command{
 			if err != nil {
 				helpStr := "\ndebugging tips (may or may not apply to this error):"
 				helpStr += fmt.Sprintf("\n* if your cluster was unable to provision instances, additional error information may be found in the activity history of your cluster's autoscaling groups (select each autoscaling group and click the \"Activity\" or \"Activity History\" tab): https://console.aws.amazon.com/ec2/autoscaling/home?region=%s#AutoScalingGroups:", clusterConfig.Region)
-				helpStr += "\n* please run `cortex cluster down` to delete the cluster before trying to create this cluster again"
+				helpStr += "\n* " + retryHelpStr
 				fmt.Println(helpStr)
 				exit.Error(ErrorClusterUp(out + helpStr))
 			}
 
 			// no autoscaling groups were created
 			if len(asgs) == 0 {
-				helpStr := "\nplease run `cortex cluster down` to delete the cluster before trying to create this cluster again"
+				helpStr := "\n" + retryHelpStr
 				fmt.Println(helpStr)
 				exit.Error(ErrorClusterUp(out + helpStr))
 			}
@@ -260,7 +442,7 @@ var _clusterUpCmd = &cobra.Command{
 				if err != nil {
 					helpStr := "\ndebugging tips (may or may not apply to this error):"
 					helpStr += fmt.Sprintf("\n* if your cluster was unable to provision instances, additional error information may be found in the activity history of your cluster's autoscaling groups (select each autoscaling group and click the \"Activity\" or \"Activity History\" tab): https://console.aws.amazon.com/ec2/autoscaling/home?region=%s#AutoScalingGroups:", clusterConfig.Region)
-					helpStr += "\n* please run `cortex cluster down` to delete the cluster before trying to create this cluster again"
+					helpStr += "\n* " + retryHelpStr
 					fmt.Println(helpStr)
 					exit.Error(ErrorClusterUp(out + helpStr))
 				}
@@ -278,17 +460,162 @@ var _clusterUpCmd = &cobra.Command{
 					helpStr := "\nyour cluster was unable to provision EC2 instances; here is one of the encountered errors:"
 					helpStr += fmt.Sprintf("\n\n> status: %s\n> description: %s", status, description)
 					helpStr += fmt.Sprintf("\n\nadditional error information might be found in the activity history of your cluster's autoscaling groups (select each autoscaling group and click the \"Activity\" or \"Activity History\" tab): https://console.aws.amazon.com/ec2/autoscaling/home?region=%s#AutoScalingGroups:", clusterConfig.Region)
-					helpStr += "\n\nplease run `cortex cluster down` to delete the cluster before trying to create this cluster again"
+					helpStr += "\n\n" + retryHelpStr
 					fmt.Println(helpStr)
 					exit.Error(ErrorClusterUp(out + helpStr))
 				}
 			}
 
 			// No failed asg activities
-			helpStr := "\nplease run `cortex cluster down` to delete the cluster before trying to create this cluster again"
+			helpStr := "\n" + retryHelpStr
 			fmt.Println(helpStr)
 			exit.Error(ErrorClusterUp(out + helpStr))
 		}
+		progress.Complete()
+		deleteClusterUpState(awsClient, clusterConfig.Bucket)
+
+		loadBalancer, err := getLoadBalancer(clusterConfig.ClusterName, OperatorLoadBalancer, awsClient)
+		if err != nil {
+			exit.Error(errors.Append(err, fmt.Sprintf("\n\nyou can attempt to resolve this issue and configure your cli environment by running `cortex cluster info --configure-env %s`", envName)))
+		}
+
+		newEnvironment := cliconfig.Environment{
+			Name:             envName,
+			OperatorEndpoint: "https://" + *loadBalancer.DNSName,
+			AWSProfile:       awsCredentials.Profile,
+			AWSRoleARN:       awsCredentials.RoleARN,
+		}
+
+		err = addEnvToCLIConfig(newEnvironment, true)
+		if err != nil {
+			exit.Error(errors.Append(err, fmt.Sprintf("\n\nyou can attempt to resolve this issue and configure your cli environment by running `cortex cluster info --configure-env %s`", envName)))
+		}
+
+		if envExists {
+			fmt.Printf(console.Bold("\nthe environment named \"%s\" has been updated to point to this cluster (and was set as the default environment)\n"), envName)
+		} else {
+			fmt.Printf(console.Bold("\nan environment named \"%s\" has been configured to point to this cluster (and was set as the default environment)\n"), envName)
+		}
+	},
+}
+
+var _clusterInstallCmd = &cobra.Command{
+	Use:   "install CLUSTER_CONFIG_FILE",
+	Short: "install cortex onto an existing kubernetes cluster on aws",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		telemetry.EventNotify("cli.cluster.install")
+
+		clusterConfigFile := args[0]
+
+		if err := assertManagerCanRun(); err != nil {
+			exit.Error(err)
+		}
+
+		kubeconfigPath := files.UserRelToAbsPath(_flagClusterInstallKubeconfig)
+		if err := files.CheckFile(kubeconfigPath); err != nil {
+			exit.Error(err)
+		}
+
+		accessConfig, err := getNewClusterAccessConfig(clusterConfigFile)
+		if err != nil {
+			exit.Error(err)
+		}
+
+		envName := _flagClusterUpEnv
+		if envName == "" {
+			envName = accessConfig.ClusterName
+		}
+
+		envExists, err := isEnvConfigured(envName)
+		if err != nil {
+			exit.Error(err)
+		}
+		if envExists {
+			if _flagClusterDisallowPrompt {
+				fmt.Printf("found an existing environment named \"%s\", which will be overwritten to connect to this cluster once cortex is installed\n\n", envName)
+			} else {
+				prompt.YesOrExit(fmt.Sprintf("found an existing environment named \"%s\"; would you like to overwrite it to connect to this cluster once cortex is installed?", envName), "", "you can specify a different environment name to be configured to connect to this cluster by specifying the --configure-env flag (e.g. `cortex cluster install --configure-env prod`); or you can list your environments with `cortex env list` and delete an environment with `cortex env delete ENV_NAME`")
+			}
+		}
+
+		awsCredentials, err := getClusterAWSCredentials(envName)
+		if err != nil {
+			exit.Error(err)
+		}
+
+		awsClient, err := newAWSClient(accessConfig.Region, withClusterAssumeRole(awsCredentials, accessConfig), true)
+		if err != nil {
+			exit.Error(err)
+		}
+
+		clusterConfig, err := getInstallClusterConfig(awsClient, clusterConfigFile, _flagClusterDisallowPrompt)
+		if err != nil {
+			exit.Error(err)
+		}
+
+		if clusterConfig.APILoadBalancerType == clusterconfig.ALBLoadBalancerType {
+			exit.Error(ErrorALBNotSupportedOnInstall())
+		}
+		if len(clusterConfig.NATGatewayElasticIPs) > 0 {
+			exit.Error(ErrorNATElasticIPsNotSupportedOnInstall())
+		}
+
+		err = createS3BucketIfNotFound(awsClient, clusterConfig.Bucket, clusterConfig.Tags)
+		if err != nil {
+			exit.Error(err)
+		}
+
+		err = setLifecycleRulesOnClusterUp(awsClient, clusterConfig.Bucket, clusterConfig.ClusterUID)
+		if err != nil {
+			exit.Error(err)
+		}
+
+		err = createLogGroupIfNotFound(awsClient, clusterConfig.ClusterName, clusterConfig.Tags, clusterConfig.LogRetentionDays())
+		if err != nil {
+			exit.Error(err)
+		}
+
+		if clusterConfig.EFS != nil {
+			clusterConfig.EFSFileSystemID, err = createEFSFileSystemIfNotFound(awsClient, clusterConfig.ClusterName, clusterConfig.Region, clusterConfig.EFS.PerformanceMode, clusterConfig.Tags)
+			if err != nil {
+				exit.Error(err)
+			}
+		}
+
+		accountID, _, err := awsClient.GetCachedAccountID()
+		if err != nil {
+			exit.Error(err)
+		}
+
+		err = createCortexPolicy(awsClient, clusterConfig, accountID)
+		if err != nil {
+			exit.Error(err)
+		}
+
+		copyToPaths := []dockerCopyToPath{
+			{
+				input: &archive.Input{
+					Files: []archive.FileInput{
+						{
+							Source: kubeconfigPath,
+							Dest:   _containerKubeconfigPath,
+						},
+					},
+				},
+				containerPath: "/",
+			},
+		}
+
+		out, exitCode, err := runManagerWithClusterConfig("/root/install.sh --install", clusterConfig, awsClient, nil, copyToPaths, nil, []string{"KUBECONFIG=" + _containerKubeconfigPath})
+		if err != nil {
+			exit.Error(err)
+		}
+		if exitCode == nil || *exitCode != 0 {
+			helpStr := "\nonce the issue has been resolved, you can run `cortex cluster install` again (installation is idempotent)"
+			fmt.Println(helpStr)
+			exit.Error(ErrorClusterInstall(out + helpStr))
+		}
 
 		loadBalancer, err := getLoadBalancer(clusterConfig.ClusterName, OperatorLoadBalancer, awsClient)
 		if err != nil {
@@ -298,6 +625,8 @@ var _clusterUpCmd = &cobra.Command{
 		newEnvironment := cliconfig.Environment{
 			Name:             envName,
 			OperatorEndpoint: "https://" + *loadBalancer.DNSName,
+			AWSProfile:       awsCredentials.Profile,
+			AWSRoleARN:       awsCredentials.RoleARN,
 		}
 
 		err = addEnvToCLIConfig(newEnvironment, true)
@@ -331,7 +660,7 @@ var _clusterScaleCmd = &cobra.Command{
 			exit.Error(ErrorSpecifyAtLeastOneFlag("--min-instances", "--max-instances"))
 		}
 
-		if _, err := docker.GetDockerClient(); err != nil {
+		if err := assertManagerCanRun(); err != nil {
 			exit.Error(err)
 		}
 
@@ -340,7 +669,12 @@ var _clusterScaleCmd = &cobra.Command{
 			exit.Error(err)
 		}
 
-		awsClient, err := newAWSClient(accessConfig.Region, true)
+		awsCredentials, err := getClusterAWSCredentials(accessConfig.ClusterName)
+		if err != nil {
+			exit.Error(err)
+		}
+
+		awsClient, err := newAWSClient(accessConfig.Region, withClusterAssumeRole(awsCredentials, accessConfig), true)
 		if err != nil {
 			exit.Error(err)
 		}
@@ -356,12 +690,28 @@ var _clusterScaleCmd = &cobra.Command{
 		}
 
 		clusterConfig := refreshCachedClusterConfig(*awsClient, accessConfig, true)
+
+		// the node groups are pointers, so the previous max instances must be read before the node group is updated
+		var prevMaxInstances int64
+		for _, ng := range clusterConfig.NodeGroups {
+			if ng != nil && ng.Name == _flagClusterScaleNodeGroup {
+				prevMaxInstances = ng.MaxInstances
+			}
+		}
+
 		clusterConfig, ngIndex, err := updateNodeGroupScale(clusterConfig, _flagClusterScaleNodeGroup, scaleMinIntances, scaleMaxInstances, _flagClusterDisallowPrompt)
 		if err != nil {
 			exit.Error(err)
 		}
 
-		out, exitCode, err := runManagerWithClusterConfig("/root/install.sh --update", &clusterConfig, awsClient, nil, nil, []string{
+		// fail fast if the account's quotas can't fit the increased max instances, rather than waiting for the autoscaling group to fail to launch them
+		if clusterConfig.NodeGroups[ngIndex].MaxInstances > prevMaxInstances {
+			if err := verifyScaleInstanceQuota(awsClient, clusterConfig); err != nil {
+				exit.Error(err)
+			}
+		}
+
+		out, exitCode, err := runManagerWithClusterConfig("/root/install.sh --update", &clusterConfig, awsClient, nil, nil, nil, []string{
 			"CORTEX_SCALING_NODEGROUP=" + _flagClusterScaleNodeGroup,
 			"CORTEX_SCALING_MIN_INSTANCES=" + s.Int64(clusterConfig.NodeGroups[ngIndex].MinInstances),
 			"CORTEX_SCALING_MAX_INSTANCES=" + s.Int64(clusterConfig.NodeGroups[ngIndex].MaxInstances),
@@ -385,7 +735,7 @@ var _clusterInfoCmd = &cobra.Command{
 	Run: func(cmd *cobra.Command, args []string) {
 		telemetry.Event("cli.cluster.info")
 
-		if _, err := docker.GetDockerClient(); err != nil {
+		if err := assertManagerCanRun(); err != nil {
 			exit.Error(err)
 		}
 
@@ -394,18 +744,51 @@ var _clusterInfoCmd = &cobra.Command{
 			exit.Error(err)
 		}
 
-		awsClient, err := newAWSClient(accessConfig.Region, _flagOutput == flags.PrettyOutputType)
+		awsCredentialsEnvName := _flagClusterInfoEnv
+		if awsCredentialsEnvName == "" {
+			awsCredentialsEnvName = accessConfig.ClusterName
+		}
+		awsCredentials, err := getClusterAWSCredentials(awsCredentialsEnvName)
 		if err != nil {
 			exit.Error(err)
 		}
 
+		awsClient, err := newAWSClient(accessConfig.Region, withClusterAssumeRole(awsCredentials, accessConfig), _flagOutput == flags.PrettyOutputType)
+		if err != nil {
+			exit.Error(err)
+		}
+
+		if !_flagClusterInfoDebug {
+			if wasFlagProvided(cmd, "debug-redact") {
+				exit.Error(ErrorFlagRequiresDebug("--debug-redact"))
+			}
+			if wasFlagProvided(cmd, "debug-collectors") {
+				exit.Error(ErrorFlagRequiresDebug("--debug-collectors"))
+			}
+		}
+
+		if _flagClusterInfoPricingCoverage != "" && _flagClusterInfoCostExplorer {
+			exit.Error(ErrorMutuallyExclusiveFlags("--pricing-coverage", "--cost-explorer"))
+		}
+		if _flagClusterInfoPricingCoverage != "" {
+			_flagClusterInfoPricingCoverage = files.RelToAbsPath(_flagClusterInfoPricingCoverage, _cwd)
+			if err := files.CheckFile(_flagClusterInfoPricingCoverage); err != nil {
+				exit.Error(err)
+			}
+		}
+
 		if _flagClusterInfoDebug {
 			if _flagOutput != flags.PrettyOutputType {
 				exit.Error(ErrorJSONOutputNotSupportedWithFlag("--debug"))
 			}
-			cmdDebug(awsClient, accessConfig)
+			for _, collector := range _flagClusterInfoDebugCollectors {
+				if !slices.HasString(_debugCollectors, collector) {
+					exit.Error(ErrorInvalidDebugCollector(collector, _debugCollectors))
+				}
+			}
+			cmdDebug(awsClient, accessConfig, _flagClusterInfoDebugCollectors, _flagClusterInfoDebugRedact)
 		} else {
-			cmdInfo(awsClient, accessConfig, _flagOutput, _flagClusterDisallowPrompt)
+			cmdInfo(awsClient, awsCredentials, accessConfig, _flagOutput, _flagClusterDisallowPrompt)
 		}
 	},
 }
@@ -417,7 +800,7 @@ var _clusterDownCmd = &cobra.Command{
 	Run: func(cmd *cobra.Command, args []string) {
 		telemetry.Event("cli.cluster.down")
 
-		if _, err := docker.GetDockerClient(); err != nil {
+		if err := assertManagerCanRun(); err != nil {
 			exit.Error(err)
 		}
 
@@ -426,8 +809,13 @@ var _clusterDownCmd = &cobra.Command{
 			exit.Error(err)
 		}
 
+		awsCredentials, err := getClusterAWSCredentials(accessConfig.ClusterName)
+		if err != nil {
+			exit.Error(err)
+		}
+
 		// Check AWS access
-		awsClient, err := newAWSClient(accessConfig.Region, true)
+		awsClient, err := newAWSClient(accessConfig.Region, withClusterAssumeRole(awsCredentials, accessConfig), true)
 		if err != nil {
 			exit.Error(err)
 		}
@@ -462,16 +850,7 @@ var _clusterDownCmd = &cobra.Command{
 			case clusterstate.StatusNotFound:
 				fmt.Println("cluster doesn't exist ✓")
 			case clusterstate.StatusDeleteComplete:
-				awsClient.DeleteQueuesWithPrefix(clusterconfig.SQSNamePrefix(accessConfig.ClusterName))
-				awsClient.DeletePolicy(clusterconfig.DefaultPolicyARN(accountID, accessConfig.ClusterName, accessConfig.Region))
-				if !_flagClusterDownKeepAWSResources {
-					volumes, err := listPVCVolumesForCluster(awsClient, accessConfig.ClusterName)
-					if err == nil {
-						for _, volume := range volumes {
-							awsClient.DeleteVolume(*volume.VolumeId)
-						}
-					}
-				}
+				// the remaining resources are cleaned up below
 				fmt.Println("already deleted ✓")
 			default:
 				fmt.Println("✓")
@@ -482,147 +861,21 @@ var _clusterDownCmd = &cobra.Command{
 		// updating CLI env is best-effort, so ignore errors
 		loadBalancer, _ := getLoadBalancer(accessConfig.ClusterName, OperatorLoadBalancer, awsClient)
 
-		fmt.Print("￮ deleting sqs queues ... ")
-		numDeleted, err := awsClient.DeleteQueuesWithPrefix(clusterconfig.SQSNamePrefix(accessConfig.ClusterName))
-		if err != nil {
-			errorsList = append(errorsList, err)
-			fmt.Print("failed ✗")
-			fmt.Printf("\n\nfailed to delete all sqs queues; please delete queues starting with the name %s via the cloudwatch console: https://%s.console.aws.amazon.com/sqs/v2/home\n", clusterconfig.SQSNamePrefix(accessConfig.ClusterName), accessConfig.Region)
-			errors.PrintError(err)
-			fmt.Println()
-		} else if numDeleted == 0 {
-			fmt.Println("no sqs queues exist ✓")
-		} else {
-			fmt.Println("✓")
-		}
-
-		clusterDoesntExist := !clusterExists
-		if clusterExists {
-			fmt.Print("￮ spinning down the cluster ...")
-			out, exitCode, err := runManagerAccessCommand("/root/uninstall.sh", *accessConfig, awsClient, nil, nil)
-			if err != nil {
-				errorsList = append(errorsList, err)
-				fmt.Println()
-				errors.PrintError(err)
-			} else if exitCode == nil || *exitCode != 0 {
-				template := "\nNote: if this error cannot be resolved, please ensure that all CloudFormation stacks for this cluster eventually become fully deleted (%s)."
-				template += " If the stack deletion process has failed, please delete the stacks directly from the AWS console (this may require manually deleting particular AWS resources that are blocking the stack deletion)."
-				template += " In addition to deleting the stacks manually from the AWS console, also make sure to empty and remove the %s bucket"
-				helpStr := fmt.Sprintf(template, clusterstate.CloudFormationURL(accessConfig.ClusterName, accessConfig.Region), bucketName)
-				fmt.Println(helpStr)
-				errorsList = append(errorsList, ErrorClusterDown(filterEKSCTLOutput(out)+helpStr))
-			} else {
-				clusterDoesntExist = true
-			}
-			fmt.Println()
-		}
-
-		// set lifecycle policy to clean the bucket
-		var bucketExists bool
-		if !_flagClusterDownKeepAWSResources {
-			fmt.Printf("￮ setting lifecycle policy to empty the %s bucket ... ", bucketName)
-			bucketExists, err := awsClient.DoesBucketExist(bucketName)
-			if err != nil {
-				errorsList = append(errorsList, err)
-				fmt.Print("failed ✗")
-				fmt.Printf("\n\nfailed to set lifecycle policy to empty the %s bucket; you can remove the bucket manually via the s3 console: https://s3.console.aws.amazon.com/s3/management/%s\n", bucketName, bucketName)
-				errors.PrintError(err)
-				fmt.Println()
-			} else if !bucketExists {
-				fmt.Println("bucket doesn't exist ✗")
-			} else {
-				err = setLifecycleRulesOnClusterDown(awsClient, bucketName)
-				if err != nil {
-					errorsList = append(errorsList, err)
-					fmt.Print("failed ✗")
-					fmt.Printf("\n\nfailed to set lifecycle policy to empty the %s bucket; you can remove the bucket manually via the s3 console: https://s3.console.aws.amazon.com/s3/management/%s\n", bucketName, bucketName)
-					errors.PrintError(err)
-					fmt.Println()
-				} else {
-					fmt.Println("✓")
-				}
-			}
-		}
-
-		// delete policy after spinning down the cluster (which deletes the roles) because policies can't be deleted if they are attached to roles
-		if clusterDoesntExist {
-			policyARN := clusterconfig.DefaultPolicyARN(accountID, accessConfig.ClusterName, accessConfig.Region)
-			fmt.Printf("￮ deleting auto-generated iam policy %s ... ", policyARN)
-			if policy, err := awsClient.GetPolicyOrNil(policyARN); err != nil {
-				errorsList = append(errorsList, err)
-				fmt.Print("failed ✗")
-				fmt.Printf("\n\nfailed to delete auto-generated cortex policy %s; please delete the policy via the iam console: https://console.aws.amazon.com/iam/home#/policies\n", policyARN)
-				errors.PrintError(err)
-				fmt.Println()
-			} else if policy == nil {
-				fmt.Println("policy doesn't exist ✓")
-			} else {
-				err = awsClient.DeletePolicy(policyARN)
-				if err != nil {
-					errorsList = append(errorsList, err)
-					fmt.Print("failed ✗")
-					fmt.Printf("\n\nfailed to delete auto-generated cortex policy %s; please delete the policy via the iam console: https://console.aws.amazon.com/iam/home#/policies\n", policyARN)
-					errors.PrintError(err)
-					fmt.Println()
-				} else {
-					fmt.Println("✓")
-				}
-			}
-		}
+		downState := &clusterDownState{clusterDeleted: !clusterExists}
+		cleanupResults := runCleanupTasks(clusterDownCleanupTasks(awsClient, *accessConfig, accountID, bucketName, clusterExists, downState))
+		errorsList = append(errorsList, cleanupErrors(cleanupResults)...)
+		fmt.Printf("\ncleanup summary: %s\n", cleanupSummary(cleanupResults))
 
-		if !_flagClusterDownKeepAWSResources {
-			fmt.Print("￮ deleting ebs volumes ... ")
-			volumes, err := listPVCVolumesForCluster(awsClient, accessConfig.ClusterName)
-			if err != nil {
-				errorsList = append(errorsList, err)
-				fmt.Println("\n\nfailed to list volumes for deletion; please delete any volumes associated with your cluster via the ec2 console: https://console.aws.amazon.com/ec2/v2/home?#Volumes")
-				errors.PrintError(err)
-				fmt.Println()
-			} else {
-				var failedToDeleteVolumes []string
-				var lastErr error
-				for _, volume := range volumes {
-					err := awsClient.DeleteVolume(*volume.VolumeId)
-					if err != nil {
-						failedToDeleteVolumes = append(failedToDeleteVolumes, *volume.VolumeId)
-						lastErr = err
-					}
-				}
-				if len(volumes) == 0 {
-					fmt.Println("no ebs volumes exist ✓")
-				} else if lastErr != nil {
-					errorsList = append(errorsList, lastErr)
-					fmt.Printf("\n\nfailed to delete %s %s; please delete %s via the ec2 console: https://console.aws.amazon.com/ec2/v2/home?#Volumes\n", s.PluralS("volume", len(failedToDeleteVolumes)), s.UserStrsAnd(failedToDeleteVolumes), s.PluralCustom("it", "them", len(failedToDeleteVolumes)))
-					errors.PrintError(lastErr)
-					fmt.Println()
-				} else {
-					fmt.Println("✓")
-				}
+		if _flagClusterDownReport != "" {
+			report := cleanupReport{
+				ClusterName: accessConfig.ClusterName,
+				Region:      accessConfig.Region,
+				Resources:   cleanupResults,
 			}
-
-			fmt.Printf("￮ deleting log group %s ... ", accessConfig.ClusterName)
-			logGroupExists, err := awsClient.DoesLogGroupExist(accessConfig.ClusterName)
-			if err != nil {
+			if err := writeCleanupReport(report, _flagClusterDownReport); err != nil {
 				errorsList = append(errorsList, err)
-				fmt.Print("failed ✗")
-				fmt.Printf("\n\nfailed to list log group for deletion; please delete the log group associated with your cluster via the ec2 console: https://%s.console.aws.amazon.com/cloudwatch/home?#logsV2:log-groups\n", accessConfig.Region)
-				errors.PrintError(err)
-				fmt.Println()
 			} else {
-				if !logGroupExists {
-					fmt.Println("log group doesn't exist ✓")
-				} else {
-					err = awsClient.DeleteLogGroup(accessConfig.ClusterName)
-					if err != nil {
-						errorsList = append(errorsList, err)
-						fmt.Print("failed ✗")
-						fmt.Printf("\n\nfailed to delete log group %s; please delete the log group associated with your cluster via the ec2 console: https://%s.console.aws.amazon.com/cloudwatch/home?#logsV2:log-groups\n", accessConfig.ClusterName, accessConfig.Region)
-						errors.PrintError(err)
-						fmt.Println()
-					} else {
-						fmt.Println("✓")
-					}
-				}
+				fmt.Printf("saved the cleanup report to %s\n", _flagClusterDownReport)
 			}
 		}
 
@@ -634,7 +887,7 @@ var _clusterDownCmd = &cobra.Command{
 			exit.Error(errors.ListOfErrors(ErrClusterDown, false, errorsList...))
 		}
 		fmt.Printf("\nplease check CloudFormation to ensure that all resources for the %s cluster eventually become successfully deleted: %s\n", accessConfig.ClusterName, clusterstate.CloudFormationURL(accessConfig.ClusterName, accessConfig.Region))
-		if !_flagClusterDownKeepAWSResources && bucketExists {
+		if !_flagClusterDownKeepAWSResources && downState.bucketExists {
 			fmt.Printf("\na lifecycle rule has been applied to the cluster's %s bucket to empty its contents within the next 24 hours; you can delete the %s bucket via the s3 console once it has been emptied (or you can empty and delete it now): https://s3.console.aws.amazon.com/s3/management/%s\n", bucketName, bucketName, bucketName)
 		}
 		fmt.Println()
@@ -664,6 +917,180 @@ var _clusterDownCmd = &cobra.Command{
 	},
 }
 
+type clusterDownState struct {
+	clusterDeleted bool
+	bucketExists   bool
+}
+
+// the cluster is spun down before the resources which are used by its nodes (or attached to its roles) are deleted
+func clusterDownCleanupTasks(awsClient *aws.Client, accessConfig clusterconfig.AccessConfig, accountID string, bucketName string, clusterExists bool, state *clusterDownState) []cleanupTask {
+	keepAWSResourcesMsg := "--keep-aws-resources was specified"
+
+	tasks := []cleanupTask{
+		{
+			resource:    "sqs_queues",
+			description: "deleting sqs queues",
+			run: func() cleanupResult {
+				queuePrefix := clusterconfig.SQSNamePrefix(accessConfig.ClusterName)
+				helpStr := fmt.Sprintf("failed to delete all sqs queues; please delete queues starting with the name %s via the sqs console: https://%s.console.aws.amazon.com/sqs/v2/home", queuePrefix, accessConfig.Region)
+				queueURLs, err := awsClient.ListQueuesByQueueNamePrefix(queuePrefix)
+				if err != nil {
+					return cleanupFailed(err, helpStr)
+				}
+				if len(queueURLs) == 0 {
+					return cleanupNotFound("no sqs queues exist")
+				}
+				return cleanupInParallel(queueURLs, awsClient.DeleteQueue, helpStr)
+			},
+		},
+	}
+
+	if clusterExists {
+		tasks = append(tasks, cleanupTask{
+			resource:    "cluster",
+			description: "spinning down the cluster",
+			after:       []string{"sqs_queues"},
+			exclusive:   true,
+			run: func() cleanupResult {
+				out, exitCode, err := runManagerAccessCommand("/root/uninstall.sh", accessConfig, awsClient, nil, nil)
+				if err != nil {
+					return cleanupFailed(err, "")
+				}
+				if exitCode == nil || *exitCode != 0 {
+					template := "\nNote: if this error cannot be resolved, please ensure that all CloudFormation stacks for this cluster eventually become fully deleted (%s)."
+					template += " If the stack deletion process has failed, please delete the stacks directly from the AWS console (this may require manually deleting particular AWS resources that are blocking the stack deletion)."
+					template += " In addition to deleting the stacks manually from the AWS console, also make sure to empty and remove the %s bucket"
+					helpStr := fmt.Sprintf(template, clusterstate.CloudFormationURL(accessConfig.ClusterName, accessConfig.Region), bucketName)
+					return cleanupFailed(ErrorClusterDown(filterEKSCTLOutput(out)+helpStr), "")
+				}
+				state.clusterDeleted = true
+				return cleanupDeleted(accessConfig.ClusterName)
+			},
+		})
+	}
+
+	tasks = append(tasks,
+		cleanupTask{
+			resource:    "s3_bucket",
+			description: fmt.Sprintf("setting lifecycle policy to empty the %s bucket", bucketName),
+			run: func() cleanupResult {
+				if _flagClusterDownKeepAWSResources {
+					return cleanupSkipped(keepAWSResourcesMsg)
+				}
+				helpStr := fmt.Sprintf("failed to set lifecycle policy to empty the %s bucket; you can remove the bucket manually via the s3 console: https://s3.console.aws.amazon.com/s3/management/%s", bucketName, bucketName)
+				bucketExists, err := awsClient.DoesBucketExist(bucketName)
+				if err != nil {
+					return cleanupFailed(err, helpStr)
+				}
+				if !bucketExists {
+					return cleanupNotFound("bucket doesn't exist")
+				}
+				if err := setLifecycleRulesOnClusterDown(awsClient, bucketName); err != nil {
+					return cleanupFailed(err, helpStr)
+				}
+				state.bucketExists = true
+				return cleanupScheduled(fmt.Sprintf("the contents of the %s bucket will be deleted within 24 hours", bucketName))
+			},
+		},
+		cleanupTask{
+			// delete policy after spinning down the cluster (which deletes the roles) because policies can't be deleted if they are attached to roles
+			resource:    "iam_policy",
+			description: "deleting auto-generated iam policy",
+			after:       []string{"cluster"},
+			run: func() cleanupResult {
+				if !state.clusterDeleted {
+					return cleanupSkipped("the cluster was not spun down")
+				}
+				policyARN := clusterconfig.DefaultPolicyARN(accountID, accessConfig.ClusterName, accessConfig.Region)
+				helpStr := fmt.Sprintf("failed to delete auto-generated cortex policy %s; please delete the policy via the iam console: https://console.aws.amazon.com/iam/home#/policies", policyARN)
+				policy, err := awsClient.GetPolicyOrNil(policyARN)
+				if err != nil {
+					return cleanupFailed(err, helpStr)
+				}
+				if policy == nil {
+					return cleanupNotFound("policy doesn't exist")
+				}
+				if err := awsClient.DeletePolicy(policyARN); err != nil {
+					return cleanupFailed(err, helpStr)
+				}
+				return cleanupDeleted(policyARN)
+			},
+		},
+		cleanupTask{
+			resource:    "ebs_volumes",
+			description: "deleting ebs volumes",
+			after:       []string{"cluster"},
+			run: func() cleanupResult {
+				if _flagClusterDownKeepAWSResources {
+					return cleanupSkipped(keepAWSResourcesMsg)
+				}
+				helpStr := "failed to delete all volumes; please delete any volumes associated with your cluster via the ec2 console: https://console.aws.amazon.com/ec2/v2/home?#Volumes"
+				volumes, err := listPVCVolumesForCluster(awsClient, accessConfig.ClusterName)
+				if err != nil {
+					return cleanupFailed(err, helpStr)
+				}
+				if len(volumes) == 0 {
+					return cleanupNotFound("no ebs volumes exist")
+				}
+				volumeIDs := make([]string, len(volumes))
+				for i := range volumes {
+					volumeIDs[i] = *volumes[i].VolumeId
+				}
+				return cleanupInParallel(volumeIDs, awsClient.DeleteVolume, helpStr)
+			},
+		},
+		cleanupTask{
+			resource:    "log_group",
+			description: fmt.Sprintf("deleting log group %s", accessConfig.ClusterName),
+			after:       []string{"cluster"},
+			run: func() cleanupResult {
+				if _flagClusterDownKeepAWSResources {
+					return cleanupSkipped(keepAWSResourcesMsg)
+				}
+				helpStr := fmt.Sprintf("failed to delete log group %s; please delete the log group associated with your cluster via the cloudwatch console: https://%s.console.aws.amazon.com/cloudwatch/home?#logsV2:log-groups", accessConfig.ClusterName, accessConfig.Region)
+				logGroupExists, err := awsClient.DoesLogGroupExist(accessConfig.ClusterName)
+				if err != nil {
+					return cleanupFailed(err, helpStr)
+				}
+				if !logGroupExists {
+					return cleanupNotFound("log group doesn't exist")
+				}
+				if err := awsClient.DeleteLogGroup(accessConfig.ClusterName); err != nil {
+					return cleanupFailed(err, helpStr)
+				}
+				return cleanupDeleted(accessConfig.ClusterName)
+			},
+		},
+		cleanupTask{
+			// the file system's mount targets are deleted when spinning down the cluster
+			resource:    "efs_file_system",
+			description: "deleting efs file system",
+			after:       []string{"cluster"},
+			run: func() cleanupResult {
+				if _flagClusterDownKeepAWSResources {
+					return cleanupSkipped(keepAWSResourcesMsg)
+				}
+				if !state.clusterDeleted {
+					return cleanupSkipped("the cluster was not spun down")
+				}
+				fileSystem, err := awsClient.EFSFileSystemOrNil(clusterconfig.EFSCreationToken(accessConfig.ClusterName, accessConfig.Region))
+				if err != nil {
+					return cleanupFailed(err, fmt.Sprintf("failed to list efs file systems for deletion; please delete the file system associated with your cluster via the efs console: https://%s.console.aws.amazon.com/efs/home#/file-systems", accessConfig.Region))
+				}
+				if fileSystem == nil {
+					return cleanupNotFound("efs file system doesn't exist")
+				}
+				if err := awsClient.DeleteEFSFileSystem(*fileSystem.FileSystemId); err != nil {
+					return cleanupFailed(err, fmt.Sprintf("failed to delete efs file system %s; please delete the file system via the efs console: https://%s.console.aws.amazon.com/efs/home#/file-systems", *fileSystem.FileSystemId, accessConfig.Region))
+				}
+				return cleanupDeleted(*fileSystem.FileSystemId)
+			},
+		},
+	)
+
+	return tasks
+}
+
 var _clusterExportCmd = &cobra.Command{
 	Use:   "export",
 	Short: "download the configurations for all APIs",
@@ -676,8 +1103,13 @@ var _clusterExportCmd = &cobra.Command{
 			exit.Error(err)
 		}
 
+		awsCredentials, err := getClusterAWSCredentials(accessConfig.ClusterName)
+		if err != nil {
+			exit.Error(err)
+		}
+
 		// Check AWS access
-		awsClient, err := newAWSClient(accessConfig.Region, true)
+		awsClient, err := newAWSClient(accessConfig.Region, withClusterAssumeRole(awsCredentials, accessConfig), true)
 		if err != nil {
 			exit.Error(err)
 		}
@@ -739,7 +1171,7 @@ var _clusterExportCmd = &cobra.Command{
 	},
 }
 
-func cmdInfo(awsClient *aws.Client, accessConfig *clusterconfig.AccessConfig, outputType flags.OutputType, disallowPrompt bool) {
+func cmdInfo(awsClient *aws.Client, awsCredentials aws.CredentialsConfig, accessConfig *clusterconfig.AccessConfig, outputType flags.OutputType, disallowPrompt bool) {
 	if outputType == flags.PrettyOutputType {
 		if err := printInfoClusterState(awsClient, accessConfig); err != nil {
 			exit.Error(err)
@@ -760,6 +1192,22 @@ func cmdInfo(awsClient *aws.Client, accessConfig *clusterconfig.AccessConfig, ou
 	operatorEndpoint := s.EnsurePrefix(*operatorLoadBalancer.DNSName, "https://")
 	apiEndpoint := *apiLoadBalancer.DNSName
 
+	var apiGatewayEndpoint string
+	if clusterConfig.APIGateway != nil {
+		apiGateway, err := awsClient.GetAPIGatewayByTag(clusterconfig.ClusterNameTag, accessConfig.ClusterName)
+		if err != nil {
+			exit.Error(err)
+		}
+		if apiGateway != nil && apiGateway.ApiEndpoint != nil {
+			apiGatewayEndpoint = *apiGateway.ApiEndpoint
+		}
+	}
+
+	egressIPs, err := getEgressIPs(awsClient, accessConfig.ClusterName, clusterConfig)
+	if err != nil {
+		exit.Error(err)
+	}
+
 	if outputType == flags.JSONOutputType {
 		infoResponse, err := getInfoOperatorResponse(operatorEndpoint)
 		if err != nil {
@@ -767,13 +1215,29 @@ func cmdInfo(awsClient *aws.Client, accessConfig *clusterconfig.AccessConfig, ou
 		}
 		infoResponse.ClusterConfig.Config = clusterConfig
 
-		jsonBytes, err := libjson.Marshal(map[string]interface{}{
-			"cluster_config":    infoResponse.ClusterConfig.Config,
-			"cluster_metadata":  infoResponse.ClusterConfig.OperatorMetadata,
-			"node_infos":        infoResponse.NodeInfos,
-			"endpoint_operator": operatorEndpoint,
-			"endpoint_api":      apiEndpoint,
-		})
+		effectiveInstanceCosts, err := getEffectiveInstanceCosts(awsClient, infoResponse, clusterConfig)
+		if err != nil {
+			exit.Error(err)
+		}
+
+		infoJSON := map[string]interface{}{
+			"cluster_config":           infoResponse.ClusterConfig.Config,
+			"cluster_metadata":         infoResponse.ClusterConfig.OperatorMetadata,
+			"node_infos":               infoResponse.NodeInfos,
+			"quota_usages":             infoResponse.QuotaUsages,
+			"budget_usage":             infoResponse.BudgetUsage,
+			"effective_instance_costs": effectiveInstanceCosts,
+			"endpoint_operator":        operatorEndpoint,
+			"endpoint_api":             apiEndpoint,
+		}
+		if apiGatewayEndpoint != "" {
+			infoJSON["endpoint_api_gateway"] = apiGatewayEndpoint
+		}
+		if len(egressIPs) > 0 {
+			infoJSON["egress_ips"] = egressIPs
+		}
+
+		jsonBytes, err := libjson.Marshal(infoJSON)
 		if err != nil {
 			exit.Error(err)
 		}
@@ -784,20 +1248,46 @@ func cmdInfo(awsClient *aws.Client, accessConfig *clusterconfig.AccessConfig, ou
 		fmt.Println(console.Bold("endpoints:"))
 		fmt.Println("operator:         ", operatorEndpoint)
 		fmt.Println("api load balancer:", apiEndpoint)
+		if apiGatewayEndpoint != "" {
+			fmt.Println("api gateway:      ", apiGatewayEndpoint)
+		}
 		fmt.Println()
 
-		if err := printInfoOperatorResponse(clusterConfig, operatorEndpoint); err != nil {
+		if len(egressIPs) > 0 {
+			fmt.Println(console.Bold("egress ips:"), strings.Join(egressIPs, ", "))
+			fmt.Println()
+		}
+
+		if err := printInfoOperatorResponse(awsClient, clusterConfig, operatorEndpoint); err != nil {
 			exit.Error(err)
 		}
 	}
 
 	if _flagClusterInfoEnv != "" {
-		if err := updateCLIEnv(_flagClusterInfoEnv, operatorEndpoint, disallowPrompt, outputType == flags.PrettyOutputType); err != nil {
+		if err := updateCLIEnv(_flagClusterInfoEnv, operatorEndpoint, awsCredentials, disallowPrompt, outputType == flags.PrettyOutputType); err != nil {
 			exit.Error(err)
 		}
 	}
 }
 
+// outbound traffic from the nodes in private subnets exits through the nat gateways of the cluster's vpc
+// (nodes in public subnets send requests from their own public ip addresses, which change as nodes are replaced)
+func getEgressIPs(awsClient *aws.Client, clusterName string, clusterConfig clusterconfig.Config) ([]string, error) {
+	if clusterConfig.SubnetVisibility != clusterconfig.PrivateSubnetVisibility {
+		return nil, nil
+	}
+
+	eksCluster, err := awsClient.EKSClusterOrNil(clusterName)
+	if err != nil {
+		return nil, err
+	}
+	if eksCluster == nil || eksCluster.ResourcesVpcConfig == nil || eksCluster.ResourcesVpcConfig.VpcId == nil {
+		return nil, nil
+	}
+
+	return awsClient.ListNATGatewayPublicIPs(*eksCluster.ResourcesVpcConfig.VpcId)
+}
+
 func printInfoClusterState(awsClient *aws.Client, accessConfig *clusterconfig.AccessConfig) error {
 	clusterState, err := clusterstate.GetClusterState(awsClient, accessConfig)
 	if err != nil {
@@ -805,7 +1295,7 @@ func printInfoClusterState(awsClient *aws.Client, accessConfig *clusterconfig.Ac
 	}
 
 	fmt.Println(clusterState.TableString())
-	if clusterState.Status == clusterstate.StatusCreateFailed || clusterState.Status == clusterstate.StatusDeleteFailed {
+	if clusterState.Status == clusterstate.StatusCreateFailed || clusterState.Status == clusterstate.StatusNodeGroupCreateFailed || clusterState.Status == clusterstate.StatusDeleteFailed {
 		fmt.Println(fmt.Sprintf("more information can be found in your AWS console: %s", clusterstate.CloudFormationURL(accessConfig.ClusterName, accessConfig.Region)))
 		fmt.Println()
 	}
@@ -818,7 +1308,7 @@ func printInfoClusterState(awsClient *aws.Client, accessConfig *clusterconfig.Ac
 	return nil
 }
 
-func printInfoOperatorResponse(clusterConfig clusterconfig.Config, operatorEndpoint string) error {
+func printInfoOperatorResponse(awsClient *aws.Client, clusterConfig clusterconfig.Config, operatorEndpoint string) error {
 	fmt.Print("fetching cluster status ...\n\n")
 
 	yamlBytes, err := yaml.Marshal(clusterConfig)
@@ -838,8 +1328,15 @@ func printInfoOperatorResponse(clusterConfig clusterconfig.Config, operatorEndpo
 	fmt.Println(fmt.Sprintf("cluster version: %s", infoResponse.ClusterConfig.APIVersion))
 	fmt.Print(yamlString)
 
-	printInfoPricing(infoResponse, clusterConfig)
+	effectiveInstanceCosts, err := getEffectiveInstanceCosts(awsClient, infoResponse, clusterConfig)
+	if err != nil {
+		return err
+	}
+
+	printInfoPricing(infoResponse, clusterConfig, effectiveInstanceCosts)
 	printInfoNodes(infoResponse)
+	printInfoQuotas(infoResponse)
+	printInfoBudget(infoResponse)
 
 	return nil
 }
@@ -853,7 +1350,7 @@ func getInfoOperatorResponse(operatorEndpoint string) (*schema.InfoResponse, err
 	return cluster.Info(operatorConfig)
 }
 
-func printInfoPricing(infoResponse *schema.InfoResponse, clusterConfig clusterconfig.Config) {
+func printInfoPricing(infoResponse *schema.InfoResponse, clusterConfig clusterconfig.Config, effectiveInstanceCosts []*instanceTypeCost) {
 	eksPrice := aws.EKSPrices[clusterConfig.Region]
 	operatorInstancePrice := aws.InstanceMetadatas[clusterConfig.Region]["t3.medium"].Price
 	operatorEBSPrice := aws.EBSMetadatas[clusterConfig.Region]["gp3"].PriceGB * 20 / 30 / 24
@@ -871,13 +1368,10 @@ func printInfoPricing(infoResponse *schema.InfoResponse, clusterConfig clusterco
 
 	var totalNodeGroupsPrice float64
 	for _, ng := range clusterConfig.NodeGroups {
-		var ngNamePrefix string
-		if ng.Spot {
-			ngNamePrefix = "cx-ws-"
-		} else {
-			ngNamePrefix = "cx-wd-"
+		var nodesInfo []schema.NodeInfo
+		for _, eksName := range ng.EKSNames() {
+			nodesInfo = append(nodesInfo, infoResponse.GetNodesWithNodeGroupName(eksName)...)
 		}
-		nodesInfo := infoResponse.GetNodesWithNodeGroupName(ngNamePrefix + ng.Name)
 		numInstances := len(nodesInfo)
 
 		ebsPrice := aws.EBSMetadatas[clusterConfig.Region][ng.InstanceVolumeType.String()].PriceGB * float64(ng.InstanceVolumeSize) / 30 / 24
@@ -895,7 +1389,7 @@ func printInfoPricing(infoResponse *schema.InfoResponse, clusterConfig clusterco
 			totalInstancePrice += nodeInfo.Price
 		}
 
-		rows = append(rows, []interface{}{fmt.Sprintf("nodegroup %s: %d (out of %d) %s", ng.Name, numInstances, ng.MaxInstances, s.PluralS("instance", numInstances)), s.DollarsAndTenthsOfCents(totalInstancePrice+totalEBSPrice) + " total"})
+		rows = append(rows, []interface{}{fmt.Sprintf("nodegroup %s (%s, %s): %d (out of %d) %s", ng.Name, nodeGroupProvisioningStr(clusterConfig, ng), ng.AMIType(), numInstances, ng.MaxInstances, s.PluralS("instance", numInstances)), s.DollarsAndTenthsOfCents(totalInstancePrice+totalEBSPrice) + " total"})
 
 		totalNodeGroupsPrice += totalEBSPrice + totalInstancePrice
 	}
@@ -911,7 +1405,10 @@ func printInfoPricing(infoResponse *schema.InfoResponse, clusterConfig clusterco
 
 	operatorPrice := 2*(operatorInstancePrice+operatorEBSPrice) + metricsEBSPrice
 	rows = append(rows, []interface{}{"2 t3.medium instances (cortex system)", s.DollarsAndTenthsOfCents(operatorPrice)})
-	rows = append(rows, []interface{}{"2 network load balancers", s.DollarsMaxPrecision(nlbPrice*2) + " total"})
+	rows = append(rows, []interface{}{loadBalancersStr(clusterConfig), s.DollarsMaxPrecision(nlbPrice*2) + " total"})
+	if clusterConfig.APIGateway != nil {
+		rows = append(rows, []interface{}{"1 api gateway (http api)", "billed per request"})
+	}
 
 	if clusterConfig.NATGateway == clusterconfig.SingleNATGateway {
 		rows = append(rows, []interface{}{"1 nat gateway", s.DollarsMaxPrecision(natUnitPrice)})
@@ -925,9 +1422,34 @@ func printInfoPricing(infoResponse *schema.InfoResponse, clusterConfig clusterco
 		Rows:    rows,
 	}
 	t.MustPrint(&table.Opts{Sort: pointer.Bool(false)})
+
+	printInfoEffectivePricing(effectiveInstanceCosts, totalPrice)
+}
+
+// the hourly price of an application load balancer is the same as that of a network load balancer (both are also billed by usage)
+func loadBalancersStr(clusterConfig clusterconfig.Config) string {
+	if clusterConfig.APILoadBalancerType == clusterconfig.ALBLoadBalancerType {
+		return "1 network load balancer, 1 application load balancer"
+	}
+	return "2 network load balancers"
+}
+
+// nodeGroupProvisioningStr describes how the node group's instances are provisioned
+func nodeGroupProvisioningStr(clusterConfig clusterconfig.Config, ng *clusterconfig.NodeGroup) string {
+	if clusterConfig.UsesKarpenter() {
+		return "karpenter"
+	}
+	if ng.Managed {
+		return "managed"
+	}
+	return "self-managed"
 }
 
 func printInfoNodes(infoResponse *schema.InfoResponse) {
+	fmt.Print(infoNodesStr(infoResponse))
+}
+
+func infoNodesStr(infoResponse *schema.InfoResponse) string {
 	numAPIInstances := len(infoResponse.NodeInfos)
 
 	var totalReplicas int
@@ -950,10 +1472,10 @@ func printInfoNodes(infoResponse *schema.InfoResponse) {
 		pendingReplicasStr = fmt.Sprintf(", and %d unscheduled %s", infoResponse.NumPendingReplicas, s.PluralS("replica", infoResponse.NumPendingReplicas))
 	}
 
-	fmt.Printf(console.Bold("\nyour cluster has %d API %s running across %d %s%s\n"), totalReplicas, s.PluralS("replica", totalReplicas), numAPIInstances, s.PluralS("instance", numAPIInstances), pendingReplicasStr)
+	out := fmt.Sprintf(console.Bold("\nyour cluster has %d API %s running across %d %s%s\n"), totalReplicas, s.PluralS("replica", totalReplicas), numAPIInstances, s.PluralS("instance", numAPIInstances), pendingReplicasStr)
 
 	if len(infoResponse.NodeInfos) == 0 {
-		return
+		return out
 	}
 
 	headers := []table.Header{
@@ -965,6 +1487,7 @@ func printInfoNodes(infoResponse *schema.InfoResponse) {
 		{Title: "memory (requested / total allocatable)"},
 		{Title: "GPU (requested / total allocatable)", Hidden: !doesClusterHaveGPUs},
 		{Title: "Inf (requested / total allocatable)", Hidden: !doesClusterHaveInfs},
+		{Title: "NeuronCores (requested / total allocatable)", Hidden: !doesClusterHaveInfs},
 	}
 
 	var rows [][]interface{}
@@ -976,20 +1499,115 @@ func printInfoNodes(infoResponse *schema.InfoResponse) {
 
 		cpuStr := nodeInfo.ComputeUserRequested.CPU.MilliString() + " / " + nodeInfo.ComputeUserCapacity.CPU.MilliString()
 		memStr := nodeInfo.ComputeUserRequested.Mem.String() + " / " + nodeInfo.ComputeUserCapacity.Mem.String()
-		gpuStr := s.Int64(nodeInfo.ComputeUserRequested.GPU) + " / " + s.Int64(nodeInfo.ComputeUserCapacity.GPU)
+		gpuStr := s.Round(nodeInfo.ComputeUserRequested.GPU, 2, 0) + " / " + s.Round(nodeInfo.ComputeUserCapacity.GPU, 2, 0)
 		infStr := s.Int64(nodeInfo.ComputeUserRequested.Inf) + " / " + s.Int64(nodeInfo.ComputeUserCapacity.Inf)
-		rows = append(rows, []interface{}{nodeInfo.InstanceType, lifecycle, nodeInfo.NumReplicas, nodeInfo.NumAsyncGatewayReplicas, cpuStr, memStr, gpuStr, infStr})
+		neuronCoresPerInf, _ := aws.NeuronCoresPerInf(nodeInfo.InstanceType)
+		neuronCoresStr := s.Int64(nodeInfo.ComputeUserRequested.Inf*neuronCoresPerInf) + " / " + s.Int64(nodeInfo.ComputeUserCapacity.Inf*neuronCoresPerInf)
+		rows = append(rows, []interface{}{nodeInfo.InstanceType, lifecycle, nodeInfo.NumReplicas, nodeInfo.NumAsyncGatewayReplicas, cpuStr, memStr, gpuStr, infStr, neuronCoresStr})
 	}
 
 	t := table.Table{
 		Headers: headers,
 		Rows:    rows,
 	}
-	fmt.Println()
-	t.MustPrint(&table.Opts{Sort: pointer.Bool(false)})
+	return out + "\n" + t.MustFormat(&table.Opts{Sort: pointer.Bool(false)})
 }
 
-func updateCLIEnv(envName string, operatorEndpoint string, disallowPrompt bool, printToStdout bool) error {
+func printInfoQuotas(infoResponse *schema.InfoResponse) {
+	fmt.Print(infoQuotasStr(infoResponse))
+}
+
+func infoQuotasStr(infoResponse *schema.InfoResponse) string {
+	if len(infoResponse.QuotaUsages) == 0 {
+		return ""
+	}
+
+	headers := []table.Header{
+		{Title: "team"},
+		{Title: "api prefix"},
+		{Title: "apis"},
+		{Title: "CPU (requested / quota)"},
+		{Title: "memory (requested / quota)"},
+		{Title: "GPU (requested / quota)"},
+		{Title: "replicas (requested / quota)"},
+	}
+
+	var rows [][]interface{}
+	for _, usage := range infoResponse.QuotaUsages {
+		cpuQuotaStr, memQuotaStr, gpuQuotaStr, replicasQuotaStr := "unlimited", "unlimited", "unlimited", "unlimited"
+		if usage.Quota.CPU != nil {
+			cpuQuotaStr = usage.Quota.CPU.MilliString()
+		}
+		if usage.Quota.Mem != nil {
+			memQuotaStr = usage.Quota.Mem.String()
+		}
+		if usage.Quota.GPU != nil {
+			gpuQuotaStr = s.Int64(*usage.Quota.GPU)
+		}
+		if usage.Quota.Replicas != nil {
+			replicasQuotaStr = s.Int64(*usage.Quota.Replicas)
+		}
+
+		rows = append(rows, []interface{}{
+			usage.Quota.Team,
+			usage.Quota.APIPrefix,
+			usage.NumAPIs,
+			usage.Used.CPU.MilliString() + " / " + cpuQuotaStr,
+			usage.Used.Mem.String() + " / " + memQuotaStr,
+			s.Round(usage.Used.GPU, 2, 0) + " / " + gpuQuotaStr,
+			s.Int64(usage.Replicas) + " / " + replicasQuotaStr,
+		})
+	}
+
+	t := table.Table{
+		Headers: headers,
+		Rows:    rows,
+	}
+	return console.Bold("\nteam quotas (requested at the apis' max replicas):") + "\n\n" + t.MustFormat(&table.Opts{Sort: pointer.Bool(false)})
+}
+
+func printInfoBudget(infoResponse *schema.InfoResponse) {
+	fmt.Print(infoBudgetStr(infoResponse))
+}
+
+func infoBudgetStr(infoResponse *schema.InfoResponse) string {
+	if infoResponse.BudgetUsage == nil {
+		return ""
+	}
+	usage := infoResponse.BudgetUsage
+
+	clusterBudgetStr, apiBudgetStr := "unlimited", "unlimited"
+	if usage.Budget.MaxClusterCostPerHour != nil {
+		clusterBudgetStr = s.DollarsAndCents(*usage.Budget.MaxClusterCostPerHour)
+	}
+	if usage.Budget.MaxAPICostPerHour != nil {
+		apiBudgetStr = s.DollarsAndCents(*usage.Budget.MaxAPICostPerHour)
+	}
+
+	out := console.Bold("\nbudget (projected cost per hour at the apis' max replicas):") + "\n\n"
+	out += fmt.Sprintf("cluster: %s / %s\n", s.DollarsAndCents(usage.ClusterCostPerHour), clusterBudgetStr)
+	if len(usage.APICostsPerHour) == 0 {
+		return out
+	}
+
+	headers := []table.Header{
+		{Title: "api"},
+		{Title: "cost per hour (projected / budget)"},
+	}
+
+	var rows [][]interface{}
+	for apiName, apiCost := range usage.APICostsPerHour {
+		rows = append(rows, []interface{}{apiName, s.DollarsAndTenthsOfCents(apiCost) + " / " + apiBudgetStr})
+	}
+
+	t := table.Table{
+		Headers: headers,
+		Rows:    rows,
+	}
+	return out + "\n" + t.MustFormat()
+}
+
+func updateCLIEnv(envName string, operatorEndpoint string, awsCredentials aws.CredentialsConfig, disallowPrompt bool, printToStdout bool) error {
 	prevEnv, err := readEnv(envName)
 	if err != nil {
 		return err
@@ -998,6 +1616,8 @@ func updateCLIEnv(envName string, operatorEndpoint string, disallowPrompt bool,
 	newEnvironment := cliconfig.Environment{
 		Name:             envName,
 		OperatorEndpoint: operatorEndpoint,
+		AWSProfile:       awsCredentials.Profile,
+		AWSRoleARN:       awsCredentials.RoleARN,
 	}
 
 	shouldWriteEnv := false
@@ -1007,7 +1627,7 @@ func updateCLIEnv(envName string, operatorEndpoint string, disallowPrompt bool,
 		if printToStdout {
 			fmt.Println()
 		}
-	} else if prevEnv.OperatorEndpoint != operatorEndpoint {
+	} else if prevEnv.OperatorEndpoint != operatorEndpoint || prevEnv.AWSProfile != awsCredentials.Profile || prevEnv.AWSRoleARN != awsCredentials.RoleARN {
 		envWasUpdated = true
 		if printToStdout {
 			if disallowPrompt {
@@ -1039,7 +1659,7 @@ func updateCLIEnv(envName string, operatorEndpoint string, disallowPrompt bool,
 	return nil
 }
 
-func cmdDebug(awsClient *aws.Client, accessConfig *clusterconfig.AccessConfig) {
+func cmdDebug(awsClient *aws.Client, accessConfig *clusterconfig.AccessConfig, collectors []string, redact bool) {
 	// note: if modifying this string, also change it in files.IgnoreCortexDebug()
 	debugFileName := fmt.Sprintf("cortex-debug-%s.tgz", time.Now().UTC().Format("2006-01-02-15-04-05"))
 
@@ -1051,7 +1671,12 @@ func cmdDebug(awsClient *aws.Client, accessConfig *clusterconfig.AccessConfig) {
 		},
 	}
 
-	out, exitCode, err := runManagerAccessCommand("/root/debug.sh "+containerDebugPath, *accessConfig, awsClient, nil, copyFromPaths)
+	debugCmd := fmt.Sprintf("/root/debug.sh %s --collectors=%s", containerDebugPath, strings.Join(collectors, ","))
+	if redact {
+		debugCmd += " --redact"
+	}
+
+	out, exitCode, err := runManagerAccessCommand(debugCmd, *accessConfig, awsClient, nil, copyFromPaths)
 	if err != nil {
 		exit.Error(err)
 	}
@@ -1126,6 +1751,12 @@ func updateNodeGroupScale(clusterConfig clusterconfig.Config, targetNg string, d
 			if minReplicas > maxReplicas {
 				return clusterconfig.Config{}, 0, ErrorMinInstancesGreaterThanMaxInstances(minReplicas, maxReplicas)
 			}
+			if clusterConfig.UsesKarpenter() && minReplicas > 0 {
+				return clusterconfig.Config{}, 0, clusterconfig.ErrorMinInstancesNotSupportedByKarpenter()
+			}
+			if ng.Managed && minReplicas == 0 {
+				return clusterconfig.Config{}, 0, clusterconfig.ErrorManagedNodeGroupMinInstances()
+			}
 
 			if ng.MinInstances == minReplicas && ng.MaxInstances == maxReplicas {
 				fmt.Printf("the %s nodegroup in the %s cluster in %s already has min instances set to %d and max instances set to %d\n", ng.Name, clusterName, region, minReplicas, maxReplicas)
@@ -1157,6 +1788,40 @@ func updateNodeGroupScale(clusterConfig clusterconfig.Config, targetNg string, d
 	return clusterconfig.Config{}, 0, ErrorNodeGroupNotFound(targetNg, clusterName, region, availableNodeGroups)
 }
 
+func verifyScaleInstanceQuota(awsClient *aws.Client, clusterConfig clusterconfig.Config) error {
+	fmt.Print("￮ checking your instance quotas ... ")
+
+	err := awsClient.VerifyInstanceQuota(clusterConfig.InstanceTypeRequests())
+	if err != nil {
+		// skip aws errors, since some regions (e.g. eu-north-1) do not support this API
+		if aws.IsAWSError(err) {
+			fmt.Println("skipped (unable to retrieve the instance quotas)")
+			return nil
+		}
+		fmt.Print("failed ✗\n\n")
+		return errors.Append(err, "\n\nnote: the quota applies to all of the node groups in your cluster (scaled to their max instances), so you can also reduce the max instances of other node groups with `cortex cluster scale`")
+	}
+
+	fmt.Println("✓")
+	return nil
+}
+
+func createCortexPolicy(awsClient *aws.Client, clusterConfig *clusterconfig.Config, accountID string) error {
+	policyArgs := clusterconfig.CortexPolicyArgs{
+		ClusterName: clusterConfig.ClusterName,
+		LogGroup:    clusterConfig.ClusterName,
+		Bucket:      clusterConfig.Bucket,
+		Region:      clusterConfig.Region,
+		QueuePrefix: clusterConfig.SQSNamePrefix(),
+		AccountID:   accountID,
+		Karpenter:   clusterConfig.UsesKarpenter(),
+	}
+
+	fmt.Print("￮ " + clusterconfig.CortexPolicySummary(policyArgs) + "\n")
+
+	return clusterconfig.CreateDefaultPolicy(awsClient, policyArgs)
+}
+
 func createS3BucketIfNotFound(awsClient *aws.Client, bucket string, tags map[string]string) error {
 	bucketFound, err := awsClient.DoesBucketExist(bucket)
 	if err != nil {
@@ -1196,11 +1861,19 @@ func setLifecycleRulesOnClusterUp(awsClient *aws.Client, bucket, newClusterUID s
 		return err
 	}
 
-	clusterUIDs, err := awsClient.ListS3TopLevelDirs(bucket)
+	topLevelDirs, err := awsClient.ListS3TopLevelDirs(bucket)
 	if err != nil {
 		return err
 	}
 
+	// snapshots are kept across clusters
+	var clusterUIDs []string
+	for _, dir := range topLevelDirs {
+		if dir != _snapshotsDir {
+			clusterUIDs = append(clusterUIDs, dir)
+		}
+	}
+
 	if len(clusterUIDs)+1 > consts.MaxBucketLifecycleRules {
 		return ErrorClusterUIDsLimitInBucket(bucket)
 	}
@@ -1255,7 +1928,30 @@ func setLifecycleRulesOnClusterDown(awsClient *aws.Client, bucket string) error
 	})
 }
 
-func createLogGroupIfNotFound(awsClient *aws.Client, logGroup string, tags map[string]string) error {
+func createEFSFileSystemIfNotFound(awsClient *aws.Client, clusterName string, region string, performanceMode string, tags map[string]string) (string, error) {
+	creationToken := clusterconfig.EFSCreationToken(clusterName, region)
+
+	fileSystem, err := awsClient.EFSFileSystemOrNil(creationToken)
+	if err != nil {
+		return "", err
+	}
+	if fileSystem != nil {
+		fmt.Println("￮ using existing efs file system: " + *fileSystem.FileSystemId + " ✓")
+		return *fileSystem.FileSystemId, nil
+	}
+
+	fmt.Print("￮ creating a new efs file system")
+	fileSystemID, err := awsClient.CreateEFSFileSystem(creationToken, performanceMode, tags)
+	if err != nil {
+		fmt.Print("\n\n")
+		return "", err
+	}
+	fmt.Println(": " + fileSystemID + " ✓")
+
+	return fileSystemID, nil
+}
+
+func createLogGroupIfNotFound(awsClient *aws.Client, logGroup string, tags map[string]string, retentionDays *int64) error {
 	logGroupFound, err := awsClient.DoesLogGroupExist(logGroup)
 	if err != nil {
 		return err
@@ -1267,6 +1963,12 @@ func createLogGroupIfNotFound(awsClient *aws.Client, logGroup string, tags map[s
 			fmt.Print("\n\n")
 			return err
 		}
+		if retentionDays != nil {
+			if err := awsClient.PutLogGroupRetention(logGroup, *retentionDays); err != nil {
+				fmt.Print("\n\n")
+				return err
+			}
+		}
 		fmt.Println(" ✓")
 		return nil
 	}
@@ -1280,6 +1982,13 @@ func createLogGroupIfNotFound(awsClient *aws.Client, logGroup string, tags map[s
 		return err
 	}
 
+	if retentionDays != nil {
+		if err := awsClient.PutLogGroupRetention(logGroup, *retentionDays); err != nil {
+			fmt.Print("\n\n")
+			return err
+		}
+	}
+
 	fmt.Println(" ✓")
 
 	return nil
@@ -1321,5 +2030,5 @@ func listPVCVolumesForCluster(awsClient *aws.Client, clusterName string) ([]ec2.
 }
 
 func filterEKSCTLOutput(out string) string {
-	return strings.Join(s.RemoveDuplicates(strings.Split(out, "\n"), _eksctlPrefixRegex), "\n")
+	return strings.Join(s.RemoveDuplicates(strings.Split(removePhaseMarkers(out), "\n"), _eksctlPrefixRegex), "\n")
 }
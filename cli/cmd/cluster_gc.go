@@ -0,0 +1,415 @@
+/*
+Copyright 2021 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/cortexlabs/cortex/pkg/lib/aws"
+	"github.com/cortexlabs/cortex/pkg/lib/console"
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/lib/exit"
+	"github.com/cortexlabs/cortex/pkg/lib/hash"
+	"github.com/cortexlabs/cortex/pkg/lib/pointer"
+	"github.com/cortexlabs/cortex/pkg/lib/print"
+	"github.com/cortexlabs/cortex/pkg/lib/prompt"
+	"github.com/cortexlabs/cortex/pkg/lib/sets/strset"
+	s "github.com/cortexlabs/cortex/pkg/lib/strings"
+	"github.com/cortexlabs/cortex/pkg/lib/table"
+	"github.com/cortexlabs/cortex/pkg/lib/telemetry"
+	libtime "github.com/cortexlabs/cortex/pkg/lib/time"
+	"github.com/cortexlabs/cortex/pkg/types/clusterconfig"
+	"github.com/spf13/cobra"
+)
+
+const (
+	_orphanLoadBalancer = "load balancer"
+	_orphanEBSVolume    = "ebs volume"
+	_orphanSQSQueue     = "sqs queue"
+	_orphanIAMPolicy    = "iam policy"
+	_orphanS3Bucket     = "s3 bucket"
+	_orphanLogGroup     = "log group"
+	_orphanEFS          = "efs file system"
+)
+
+// the order in which the kinds of resources are listed
+var _orphanKinds = []string{_orphanLoadBalancer, _orphanEBSVolume, _orphanEFS, _orphanSQSQueue, _orphanLogGroup, _orphanS3Bucket, _orphanIAMPolicy}
+
+// the kinds of resources which hold data, and may have been kept on purpose (e.g. with `cortex cluster down --keep-aws-resources`);
+// they are only deleted when --include-retained is specified
+var _retainedOrphanKinds = strset.New(_orphanEBSVolume, _orphanEFS, _orphanLogGroup, _orphanS3Bucket)
+
+type orphanedResource struct {
+	kind        string
+	id          string
+	clusterName string // empty if unknown (e.g. sqs queues only contain a hash of the cluster name)
+	created     *time.Time
+}
+
+var _clusterGCCmd = &cobra.Command{
+	Use:   "gc",
+	Short: "delete resources which were created by cortex clusters that no longer exist",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		telemetry.Event("cli.cluster.gc")
+
+		olderThan, err := libtime.ParseDuration(_flagClusterGCOlderThan)
+		if err != nil || olderThan < 0 {
+			exit.Error(ErrorInvalidOlderThan(_flagClusterGCOlderThan))
+		}
+
+		awsCredentials, err := getClusterAWSCredentials("")
+		if err != nil {
+			exit.Error(err)
+		}
+
+		awsClient, err := newAWSClient(_flagClusterRegion, withClusterAssumeRole(awsCredentials, &clusterconfig.AccessConfig{Region: _flagClusterRegion}), true)
+		if err != nil {
+			exit.Error(err)
+		}
+		warnIfNotAdmin(awsClient)
+
+		fmt.Printf("￮ scanning %s for resources of cortex clusters which no longer exist ...\n", _flagClusterRegion)
+		orphans, err := findOrphanedResources(awsClient, olderThan)
+		if err != nil {
+			exit.Error(err)
+		}
+
+		var retained []orphanedResource
+		if !_flagClusterGCIncludeRetained {
+			orphans, retained = splitRetainedOrphans(orphans)
+		}
+		if len(retained) > 0 {
+			fmt.Printf("\nskipping %s which may hold data that was kept on purpose (%s); use --include-retained to delete them as well\n", s.PluralCustom("1 resource", fmt.Sprintf("%d resources", len(retained)), len(retained)), retainedOrphansSummary(retained))
+		}
+
+		if len(orphans) == 0 {
+			print.BoldFirstLine(fmt.Sprintf("\nno orphaned resources were found in %s", _flagClusterRegion))
+			return
+		}
+
+		t := orphanedResourcesTable(orphans)
+
+		fmt.Println()
+		if _flagClusterGCDryRun {
+			fmt.Println(console.Bold(fmt.Sprintf("the following %s would be deleted (dry run):", s.PluralS("resource", len(orphans)))) + "\n")
+			fmt.Print(t.MustFormat())
+			return
+		}
+
+		fmt.Print(t.MustFormat() + "\n")
+		if !_flagClusterDisallowPrompt {
+			prompt.YesOrExit(fmt.Sprintf("are you sure you want to delete %s?", s.PluralCustom("this resource", fmt.Sprintf("these %d resources", len(orphans)), len(orphans))), "", "")
+		}
+		fmt.Println()
+
+		results := runCleanupTasks(orphanCleanupTasks(awsClient, orphans))
+		fmt.Printf("\ncleanup summary: %s\n", cleanupSummary(results))
+
+		if errs := cleanupErrors(results); len(errs) > 0 {
+			exit.Error(errors.ListOfErrors(ErrClusterGC, false, errs...))
+		}
+	},
+}
+
+func orphanedResourcesTable(orphans []orphanedResource) table.Table {
+	t := table.Table{
+		Headers: []table.Header{
+			{Title: "type"},
+			{Title: "resource"},
+			{Title: "cluster"},
+			{Title: "age"},
+		},
+	}
+
+	t.Rows = make([][]interface{}, len(orphans))
+	for i, orphan := range orphans {
+		clusterName := orphan.clusterName
+		if clusterName == "" {
+			clusterName = "-"
+		}
+		t.Rows[i] = []interface{}{orphan.kind, orphan.id, clusterName, libtime.SinceStr(orphan.created)}
+	}
+
+	return t
+}
+
+// a resource is orphaned if it was created by a cortex cluster whose eks cluster doesn't exist;
+// resources which were created less than olderThan ago are excluded, since they may belong to a cluster which is being created
+func findOrphanedResources(awsClient *aws.Client, olderThan time.Duration) ([]orphanedResource, error) {
+	region := awsClient.Region
+
+	accountID, _, err := awsClient.GetCachedAccountID()
+	if err != nil {
+		return nil, err
+	}
+
+	eksClusterNames, err := awsClient.ListEKSClusterNames()
+	if err != nil {
+		return nil, err
+	}
+	liveClusters := strset.New(eksClusterNames...)
+	liveQueuePrefixes := strset.New()
+	for clusterName := range liveClusters {
+		liveQueuePrefixes.Add(clusterconfig.SQSNamePrefix(clusterName))
+	}
+
+	var orphans []orphanedResource
+	// names of clusters which no longer exist, based on the resources that they left behind
+	deadClusters := strset.New()
+
+	addOrphan := func(orphan orphanedResource) {
+		if orphan.clusterName != "" {
+			deadClusters.Add(orphan.clusterName)
+		}
+		if orphan.created != nil && time.Since(*orphan.created) < olderThan {
+			return
+		}
+		orphans = append(orphans, orphan)
+	}
+
+	warn := func(kind string, err error) {
+		fmt.Printf("warning: unable to check for orphaned %s: %s\n", orphanKindPlural(kind, 2), errors.Message(err))
+	}
+
+	loadBalancers, clusterNamesByARN, err := awsClient.ListLoadBalancersWithTagKey(clusterconfig.ClusterNameTag)
+	if err != nil {
+		warn(_orphanLoadBalancer, err)
+	}
+	for _, loadBalancer := range loadBalancers {
+		clusterName := clusterNamesByARN[*loadBalancer.LoadBalancerArn]
+		if liveClusters.Has(clusterName) {
+			continue
+		}
+		addOrphan(orphanedResource{kind: _orphanLoadBalancer, id: *loadBalancer.LoadBalancerArn, clusterName: clusterName, created: loadBalancer.CreatedTime})
+	}
+
+	bucketSuffix := "-" + hash.String(accountID + region)[:8] // see clusterconfig.BucketName()
+	buckets, err := awsClient.ListBuckets()
+	if err != nil {
+		warn(_orphanS3Bucket, err)
+	}
+	for _, bucket := range buckets {
+		if bucket.Name == nil || !strings.HasSuffix(*bucket.Name, bucketSuffix) {
+			continue
+		}
+		clusterName := strings.TrimSuffix(*bucket.Name, bucketSuffix)
+		if clusterName == "" || liveClusters.Has(clusterName) {
+			continue
+		}
+		addOrphan(orphanedResource{kind: _orphanS3Bucket, id: *bucket.Name, clusterName: clusterName, created: bucket.CreationDate})
+	}
+
+	// see clusterconfig.DefaultPolicyName()
+	policies, err := awsClient.ListPoliciesWithPrefix("cortex-")
+	if err != nil {
+		warn(_orphanIAMPolicy, err)
+	}
+	for _, policy := range policies {
+		if !strings.HasSuffix(*policy.PolicyName, "-"+region) {
+			continue
+		}
+		clusterName := strings.TrimSuffix(strings.TrimPrefix(*policy.PolicyName, "cortex-"), "-"+region)
+		// policies which are attached to roles may still be in use
+		if clusterName == "" || liveClusters.Has(clusterName) || (policy.AttachmentCount != nil && *policy.AttachmentCount > 0) {
+			continue
+		}
+		addOrphan(orphanedResource{kind: _orphanIAMPolicy, id: *policy.Arn, clusterName: clusterName, created: policy.CreateDate})
+	}
+
+	// see clusterconfig.EFSCreationToken()
+	fileSystems, err := awsClient.ListEFSFileSystems()
+	if err != nil {
+		warn(_orphanEFS, err)
+	}
+	for _, fileSystem := range fileSystems {
+		if fileSystem.CreationToken == nil || !strings.HasPrefix(*fileSystem.CreationToken, "cortex-") || !strings.HasSuffix(*fileSystem.CreationToken, "-"+region) {
+			continue
+		}
+		clusterName := strings.TrimSuffix(strings.TrimPrefix(*fileSystem.CreationToken, "cortex-"), "-"+region)
+		// file systems with mount targets can't be deleted, and may still be in use
+		if clusterName == "" || liveClusters.Has(clusterName) || (fileSystem.NumberOfMountTargets != nil && *fileSystem.NumberOfMountTargets > 0) {
+			continue
+		}
+		addOrphan(orphanedResource{kind: _orphanEFS, id: *fileSystem.FileSystemId, clusterName: clusterName, created: fileSystem.CreationTime})
+	}
+
+	queueURLs, err := awsClient.ListQueuesByQueueNamePrefix("cx" + clusterconfig.SQSQueueDelimiter)
+	if err != nil {
+		warn(_orphanSQSQueue, err)
+	}
+	for _, queueURL := range queueURLs {
+		queueName := queueURL[strings.LastIndex(queueURL, "/")+1:]
+		isLive := false
+		for prefix := range liveQueuePrefixes {
+			if strings.HasPrefix(queueName, prefix) {
+				isLive = true
+				break
+			}
+		}
+		if isLive {
+			continue
+		}
+
+		orphan := orphanedResource{kind: _orphanSQSQueue, id: queueURL}
+		if attributes, err := awsClient.GetAllQueueAttributes(queueURL); err == nil {
+			if createdTimestamp, ok := attributes["CreatedTimestamp"]; ok {
+				var seconds int64
+				if _, err := fmt.Sscan(createdTimestamp, &seconds); err == nil {
+					orphan.created = pointer.Time(time.Unix(seconds, 0))
+				}
+			}
+		}
+		addOrphan(orphan)
+	}
+
+	// log groups and volumes are found based on the names of the clusters which left other resources behind
+	for clusterName := range deadClusters {
+		logGroup, err := awsClient.LogGroupOrNil(clusterName)
+		if err != nil {
+			warn(_orphanLogGroup, err)
+			continue
+		}
+		if logGroup == nil {
+			continue
+		}
+		tags, err := awsClient.GetLogGroupTags(clusterName)
+		if err != nil {
+			warn(_orphanLogGroup, err)
+			continue
+		}
+		if tags[clusterconfig.ClusterNameTag] != clusterName {
+			continue
+		}
+		var created *time.Time
+		if logGroup.CreationTime != nil {
+			created = pointer.Time(time.Unix(0, *logGroup.CreationTime*int64(time.Millisecond)))
+		}
+		addOrphan(orphanedResource{kind: _orphanLogGroup, id: clusterName, clusterName: clusterName, created: created})
+	}
+
+	for clusterName := range deadClusters {
+		volumes, err := listPVCVolumesForCluster(awsClient, clusterName)
+		if err != nil {
+			warn(_orphanEBSVolume, err)
+			break
+		}
+		for _, volume := range volumes {
+			if volume.State == nil || *volume.State != "available" {
+				continue
+			}
+			addOrphan(orphanedResource{kind: _orphanEBSVolume, id: *volume.VolumeId, clusterName: clusterName, created: volume.CreateTime})
+		}
+	}
+
+	kindOrder := map[string]int{}
+	for i, kind := range _orphanKinds {
+		kindOrder[kind] = i
+	}
+	sort.Slice(orphans, func(i, j int) bool {
+		if kindOrder[orphans[i].kind] != kindOrder[orphans[j].kind] {
+			return kindOrder[orphans[i].kind] < kindOrder[orphans[j].kind]
+		}
+		return orphans[i].id < orphans[j].id
+	})
+
+	return orphans, nil
+}
+
+func splitRetainedOrphans(orphans []orphanedResource) ([]orphanedResource, []orphanedResource) {
+	var deletable, retained []orphanedResource
+	for _, orphan := range orphans {
+		if _retainedOrphanKinds.Has(orphan.kind) {
+			retained = append(retained, orphan)
+		} else {
+			deletable = append(deletable, orphan)
+		}
+	}
+	return deletable, retained
+}
+
+// e.g. "2 s3 buckets, 1 log group"
+func retainedOrphansSummary(retained []orphanedResource) string {
+	counts := map[string]int{}
+	for _, orphan := range retained {
+		counts[orphan.kind]++
+	}
+	var parts []string
+	for _, kind := range _orphanKinds {
+		if counts[kind] > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", counts[kind], orphanKindPlural(kind, counts[kind])))
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+func orphanKindPlural(kind string, count int) string {
+	if strings.HasSuffix(kind, "policy") {
+		return s.PluralCustom(kind, strings.TrimSuffix(kind, "y")+"ies", count)
+	}
+	return s.PluralS(kind, count)
+}
+
+// load balancers are deleted before the other resources, since the volumes and file systems may still be detaching from a cluster which was just deleted
+func orphanCleanupTasks(awsClient *aws.Client, orphans []orphanedResource) []cleanupTask {
+	idsByKind := map[string][]string{}
+	for _, orphan := range orphans {
+		idsByKind[orphan.kind] = append(idsByKind[orphan.kind], orphan.id)
+	}
+
+	deleteFns := map[string]func(string) error{
+		_orphanLoadBalancer: awsClient.DeleteLoadBalancer,
+		_orphanEBSVolume:    awsClient.DeleteVolume,
+		_orphanEFS:          awsClient.DeleteEFSFileSystem,
+		_orphanSQSQueue:     awsClient.DeleteQueue,
+		_orphanLogGroup:     awsClient.DeleteLogGroup,
+		_orphanIAMPolicy:    awsClient.DeletePolicy,
+		_orphanS3Bucket: func(bucket string) error {
+			if err := awsClient.DeleteS3Prefix(bucket, "", false); err != nil {
+				return err
+			}
+			return awsClient.DeleteBucket(bucket)
+		},
+	}
+
+	var tasks []cleanupTask
+	for _, kind := range _orphanKinds {
+		ids := idsByKind[kind]
+		if len(ids) == 0 {
+			continue
+		}
+
+		task := cleanupTask{
+			resource:    strings.ReplaceAll(kind, " ", "_"),
+			description: fmt.Sprintf("deleting %d %s", len(ids), orphanKindPlural(kind, len(ids))),
+			run: func(ids []string, deleteFn func(string) error, kind string) func() cleanupResult {
+				return func() cleanupResult {
+					return cleanupInParallel(ids, deleteFn, fmt.Sprintf("failed to delete all orphaned %s; you can delete them via the aws console, or run `cortex cluster gc` again", orphanKindPlural(kind, 2)))
+				}
+			}(ids, deleteFns[kind], kind),
+		}
+		if kind != _orphanLoadBalancer {
+			task.after = []string{strings.ReplaceAll(_orphanLoadBalancer, " ", "_")}
+		}
+		tasks = append(tasks, task)
+	}
+
+	return tasks
+}
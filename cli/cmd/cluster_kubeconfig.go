@@ -0,0 +1,179 @@
+/*
+Copyright 2021 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"encoding/base64"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/cortexlabs/cortex/pkg/lib/aws"
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/lib/exit"
+	"github.com/cortexlabs/cortex/pkg/lib/files"
+	"github.com/cortexlabs/cortex/pkg/lib/telemetry"
+	"github.com/cortexlabs/cortex/pkg/types/clusterstate"
+	"github.com/spf13/cobra"
+	kclientcmd "k8s.io/client-go/tools/clientcmd"
+	kclientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+const _awsIAMAuthenticator = "aws-iam-authenticator"
+
+var (
+	_flagClusterKubeconfigPath    string
+	_flagClusterKubeconfigContext string
+	_flagClusterKubeconfigPrint   bool
+)
+
+func addClusterKubeconfigFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&_flagClusterKubeconfigPath, "kubeconfig", "", "path to the kubeconfig file to update (default: the first file in $KUBECONFIG, or ~/.kube/config)")
+	cmd.Flags().StringVar(&_flagClusterKubeconfigContext, "context", "", "name of the kubeconfig context (default: cortex-<cluster_name>-<region>)")
+	cmd.Flags().BoolVar(&_flagClusterKubeconfigPrint, "print", false, "print the kubeconfig instead of updating the kubeconfig file")
+}
+
+var _clusterKubeconfigCmd = &cobra.Command{
+	Use:   "kubeconfig",
+	Short: "add the cluster to your kubeconfig, so that it can be accessed with kubectl (authenticated with aws-iam-authenticator)",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		telemetry.Event("cli.cluster.kubeconfig")
+
+		accessConfig, err := getClusterAccessConfigWithCache()
+		if err != nil {
+			exit.Error(err)
+		}
+
+		awsCredentials, err := getClusterAWSCredentials(accessConfig.ClusterName)
+		if err != nil {
+			exit.Error(err)
+		}
+		credentialsConfig := withClusterAssumeRole(awsCredentials, accessConfig)
+
+		awsClient, err := newAWSClient(accessConfig.Region, credentialsConfig, !_flagClusterKubeconfigPrint)
+		if err != nil {
+			exit.Error(err)
+		}
+
+		eksCluster, err := awsClient.EKSClusterOrNil(accessConfig.ClusterName)
+		if err != nil {
+			exit.Error(err)
+		}
+		if eksCluster == nil {
+			exit.Error(clusterstate.ErrorClusterDoesNotExist(accessConfig.ClusterName, accessConfig.Region))
+		}
+
+		contextName := _flagClusterKubeconfigContext
+		if contextName == "" {
+			contextName = fmt.Sprintf("cortex-%s-%s", accessConfig.ClusterName, accessConfig.Region)
+		}
+
+		kubeconfig := kclientcmdapi.NewConfig()
+		if err := addClusterToKubeconfig(kubeconfig, contextName, accessConfig.ClusterName, awsClient.Region, *eksCluster.Endpoint, *eksCluster.CertificateAuthority.Data, credentialsConfig); err != nil {
+			exit.Error(err)
+		}
+
+		if _flagClusterKubeconfigPrint {
+			kubeconfigBytes, err := kclientcmd.Write(*kubeconfig)
+			if err != nil {
+				exit.Error(errors.WithStack(err))
+			}
+			fmt.Print(string(kubeconfigBytes))
+			return
+		}
+
+		kubeconfigPath := _flagClusterKubeconfigPath
+		if kubeconfigPath == "" {
+			kubeconfigPath = kclientcmd.NewDefaultClientConfigLoadingRules().GetDefaultFilename()
+		}
+		kubeconfigPath = files.UserRelToAbsPath(kubeconfigPath)
+
+		// the cluster's entries are merged into the existing kubeconfig (replacing the entries with the same name)
+		if files.IsFile(kubeconfigPath) {
+			kubeconfig, err = kclientcmd.LoadFromFile(kubeconfigPath)
+			if err != nil {
+				exit.Error(errors.Wrap(errors.WithStack(err), kubeconfigPath))
+			}
+			if err := addClusterToKubeconfig(kubeconfig, contextName, accessConfig.ClusterName, awsClient.Region, *eksCluster.Endpoint, *eksCluster.CertificateAuthority.Data, credentialsConfig); err != nil {
+				exit.Error(err)
+			}
+		} else if _, err := files.CreateDirIfMissing(filepath.Dir(kubeconfigPath)); err != nil {
+			exit.Error(err)
+		}
+
+		if err := kclientcmd.WriteToFile(*kubeconfig, kubeconfigPath); err != nil {
+			exit.Error(errors.Wrap(errors.WithStack(err), kubeconfigPath))
+		}
+
+		fmt.Printf("added context %s to %s and set it as the current context\n", contextName, kubeconfigPath)
+		if _, err := exec.LookPath(_awsIAMAuthenticator); err != nil {
+			fmt.Printf("\nnote: %s must be installed to use the context (see https://docs.aws.amazon.com/eks/latest/userguide/install-aws-iam-authenticator.html)\n", _awsIAMAuthenticator)
+		}
+		if len(credentialsConfig.SourceRoleARNs) > 0 || credentialsConfig.MFASerial != "" || credentialsConfig.ExternalID != "" {
+			fmt.Printf("\nnote: %s can't chain roles, use mfa, or pass an external id, so %s is assumed directly with your aws credentials; configure an aws profile which assumes the role if that isn't allowed\n", _awsIAMAuthenticator, credentialsConfig.RoleARN)
+		}
+		fmt.Printf("\nkubectl can only access the cluster if your iam identity is mapped to a kubernetes group (the cluster's creator is an admin); see https://docs.cortex.dev/clusters/advanced/kubectl\n")
+	},
+}
+
+// addClusterToKubeconfig adds (or replaces) the cluster, user, and context entries for the cluster, and sets the context as the current context;
+// the user's token is generated by aws-iam-authenticator with the same aws profile and role as the cli
+func addClusterToKubeconfig(
+	kubeconfig *kclientcmdapi.Config,
+	contextName string,
+	clusterName string,
+	region string,
+	endpoint string,
+	certificateAuthorityData string,
+	credentialsConfig aws.CredentialsConfig,
+) error {
+	caBytes, err := base64.StdEncoding.DecodeString(certificateAuthorityData)
+	if err != nil {
+		return errors.Wrap(errors.WithStack(err), "cluster certificate authority")
+	}
+
+	execArgs := []string{"token", "-i", clusterName}
+	if credentialsConfig.RoleARN != "" {
+		execArgs = append(execArgs, "-r", credentialsConfig.RoleARN)
+	}
+	execEnv := []kclientcmdapi.ExecEnvVar{{Name: "AWS_REGION", Value: region}}
+	if credentialsConfig.Profile != "" {
+		execEnv = append(execEnv, kclientcmdapi.ExecEnvVar{Name: "AWS_PROFILE", Value: credentialsConfig.Profile})
+	}
+
+	kubeconfig.Clusters[contextName] = &kclientcmdapi.Cluster{
+		Server:                   endpoint,
+		CertificateAuthorityData: caBytes,
+	}
+	kubeconfig.AuthInfos[contextName] = &kclientcmdapi.AuthInfo{
+		Exec: &kclientcmdapi.ExecConfig{
+			APIVersion: "client.authentication.k8s.io/v1alpha1",
+			Command:    _awsIAMAuthenticator,
+			Args:       execArgs,
+			Env:        execEnv,
+		},
+	}
+	kubeconfig.Contexts[contextName] = &kclientcmdapi.Context{
+		Cluster:   contextName,
+		AuthInfo:  contextName,
+		Namespace: "default",
+	}
+	kubeconfig.CurrentContext = contextName
+
+	return nil
+}
@@ -0,0 +1,136 @@
+/*
+Copyright 2021 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+
+	awssdk "github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/cortexlabs/cortex/pkg/lib/aws"
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/lib/exit"
+	libjson "github.com/cortexlabs/cortex/pkg/lib/json"
+	"github.com/cortexlabs/cortex/pkg/lib/telemetry"
+	"github.com/cortexlabs/cortex/pkg/types/clusterconfig"
+	"github.com/spf13/cobra"
+)
+
+const _sessionManagerPlugin = "session-manager-plugin"
+
+var _clusterNodeShellCmd = &cobra.Command{
+	Use:   "node-shell INSTANCE_ID",
+	Short: "open a shell on one of the cluster's instances via aws systems manager session manager (requires ssm_access in the cluster configuration)",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		telemetry.Event("cli.cluster.node-shell")
+
+		instanceID := args[0]
+
+		if _, err := exec.LookPath(_sessionManagerPlugin); err != nil {
+			exit.Error(ErrorSessionManagerPluginNotInstalled())
+		}
+
+		accessConfig, err := getClusterAccessConfigWithCache()
+		if err != nil {
+			exit.Error(err)
+		}
+
+		awsCredentials, err := getClusterAWSCredentials(accessConfig.ClusterName)
+		if err != nil {
+			exit.Error(err)
+		}
+
+		awsClient, err := newAWSClient(accessConfig.Region, withClusterAssumeRole(awsCredentials, accessConfig), true)
+		if err != nil {
+			exit.Error(err)
+		}
+
+		instance, err := awsClient.DescribeInstance(instanceID)
+		if err != nil {
+			exit.Error(err)
+		}
+		if instance == nil || !isClusterInstance(instance, accessConfig.ClusterName) {
+			exit.Error(ErrorInstanceNotInCluster(instanceID, accessConfig.ClusterName, accessConfig.Region))
+		}
+
+		isManaged, err := awsClient.IsSSMManagedInstance(instanceID)
+		if err != nil {
+			exit.Error(err)
+		}
+		if !isManaged {
+			exit.Error(ErrorInstanceNotManagedBySSM(instanceID))
+		}
+
+		if err := startNodeShell(awsClient, instanceID); err != nil {
+			exit.Error(err)
+		}
+	},
+}
+
+// the instances of the cluster's node groups are tagged with the cluster's tags by eksctl, and with the kubernetes cluster tag by eksctl and karpenter
+func isClusterInstance(instance *ec2.Instance, clusterName string) bool {
+	for _, tag := range instance.Tags {
+		key, value := awssdk.StringValue(tag.Key), awssdk.StringValue(tag.Value)
+		if key == clusterconfig.ClusterNameTag && value == clusterName {
+			return true
+		}
+		if key == "kubernetes.io/cluster/"+clusterName {
+			return true
+		}
+	}
+	return false
+}
+
+// startNodeShell starts a session and connects to it with the session manager plugin (like `aws ssm start-session` does)
+func startNodeShell(awsClient *aws.Client, instanceID string) error {
+	session, err := awsClient.StartSSMSession(instanceID)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		// the plugin terminates the session when the shell exits, so this only cleans up sessions which were interrupted
+		_ = awsClient.TerminateSSMSession(awssdk.StringValue(session.SessionId))
+	}()
+
+	sessionJSON, err := libjson.MarshalJSONStr(session)
+	if err != nil {
+		return err
+	}
+	parametersJSON, err := libjson.MarshalJSONStr(map[string]string{"Target": instanceID})
+	if err != nil {
+		return err
+	}
+
+	pluginCmd := exec.Command(_sessionManagerPlugin, sessionJSON, awsClient.Region, "StartSession", "", parametersJSON, awsClient.SSMEndpoint())
+	pluginCmd.Stdin = os.Stdin
+	pluginCmd.Stdout = os.Stdout
+	pluginCmd.Stderr = os.Stderr
+
+	// ctrl+c is sent to the remote shell by the plugin, so it shouldn't exit the cli
+	signal.Ignore(os.Interrupt)
+	defer signal.Reset(os.Interrupt)
+
+	fmt.Printf("starting a session on %s (%s) ...\n", instanceID, awssdk.StringValue(session.SessionId))
+	if err := pluginCmd.Run(); err != nil {
+		return errors.Wrap(errors.WithStack(err), _sessionManagerPlugin)
+	}
+	return nil
+}
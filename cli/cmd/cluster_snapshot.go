@@ -0,0 +1,459 @@
+/*
+Copyright 2021 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/cortexlabs/cortex/cli/cluster"
+	"github.com/cortexlabs/cortex/cli/types/cliconfig"
+	"github.com/cortexlabs/cortex/cli/types/flags"
+	"github.com/cortexlabs/cortex/pkg/consts"
+	"github.com/cortexlabs/cortex/pkg/lib/aws"
+	"github.com/cortexlabs/cortex/pkg/lib/console"
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/lib/exit"
+	"github.com/cortexlabs/cortex/pkg/lib/files"
+	libjson "github.com/cortexlabs/cortex/pkg/lib/json"
+	"github.com/cortexlabs/cortex/pkg/lib/print"
+	"github.com/cortexlabs/cortex/pkg/lib/prompt"
+	s "github.com/cortexlabs/cortex/pkg/lib/strings"
+	"github.com/cortexlabs/cortex/pkg/lib/table"
+	"github.com/cortexlabs/cortex/pkg/lib/telemetry"
+	libtime "github.com/cortexlabs/cortex/pkg/lib/time"
+	"github.com/cortexlabs/cortex/pkg/types/clusterconfig"
+	"github.com/cortexlabs/cortex/pkg/types/clusterstate"
+	"github.com/cortexlabs/yaml"
+	"github.com/spf13/cobra"
+)
+
+// the default directory (in the cluster's bucket) in which snapshots are stored; it's not expired by the lifecycle rules which are applied during `cluster up`
+const _snapshotsDir = "snapshots"
+
+// snapshot ids are the (utc) time at which the snapshot was created, so that they sort chronologically
+const _snapshotIDFormat = "20060102-150405"
+
+type clusterSnapshot struct {
+	ID            string                  `json:"id"`
+	CreatedAt     time.Time               `json:"created_at"`
+	CortexVersion string                  `json:"cortex_version"`
+	ClusterName   string                  `json:"cluster_name"`
+	Region        string                  `json:"region"`
+	APIs          []interface{}           `json:"apis"` // the submitted configurations of the apis (including traffic splitters), in the order in which they were listed
+	Environments  []cliconfig.Environment `json:"environments"`
+	ClusterConfig clusterconfig.Config    `json:"cluster_config"`
+}
+
+type snapshotTarget struct {
+	accessConfig   *clusterconfig.AccessConfig
+	awsClient      *aws.Client
+	operatorConfig cluster.OperatorConfig
+}
+
+var _clusterSnapshotCmd = &cobra.Command{
+	Use:   "snapshot",
+	Short: "create, list, and restore snapshots of a cluster's apis and configuration (contains subcommands)",
+}
+
+var _clusterSnapshotCreateCmd = &cobra.Command{
+	Use:   "create",
+	Short: "save the configurations of the cluster's apis and traffic splitters, the cli environments which connect to it, and its cluster configuration",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		telemetry.Event("cli.cluster.snapshot.create")
+
+		target, err := getSnapshotTarget()
+		if err != nil {
+			exit.Error(err)
+		}
+
+		fmt.Print("￮ retrieving the apis ... ")
+		apisResponse, err := cluster.GetAPIs(target.operatorConfig)
+		if err != nil {
+			fmt.Print("failed ✗\n\n")
+			exit.Error(err)
+		}
+		var apis []interface{}
+		for _, apiResponse := range apisResponse {
+			if apiResponse.Spec.API == nil || apiResponse.Spec.API.SubmittedAPISpec == nil {
+				continue
+			}
+			apis = append(apis, apiResponse.Spec.API.SubmittedAPISpec)
+		}
+		fmt.Printf("%d %s ✓\n", len(apis), s.PluralS("api", len(apis)))
+
+		fmt.Print("￮ retrieving the cluster configuration ... ")
+		infoResponse, err := cluster.Info(target.operatorConfig)
+		if err != nil {
+			fmt.Print("failed ✗\n\n")
+			exit.Error(err)
+		}
+		fmt.Println("✓")
+
+		fmt.Print("￮ retrieving the cli environments ... ")
+		environments, err := snapshotEnvironments(target.operatorConfig.OperatorEndpoint)
+		if err != nil {
+			fmt.Print("failed ✗\n\n")
+			exit.Error(err)
+		}
+		fmt.Printf("%d %s ✓\n", len(environments), s.PluralS("environment", len(environments)))
+
+		now := time.Now().UTC()
+		snapshot := clusterSnapshot{
+			ID:            now.Format(_snapshotIDFormat),
+			CreatedAt:     now,
+			CortexVersion: consts.CortexVersion,
+			ClusterName:   target.accessConfig.ClusterName,
+			Region:        target.accessConfig.Region,
+			APIs:          apis,
+			Environments:  environments,
+			ClusterConfig: infoResponse.ClusterConfig.Config,
+		}
+
+		bucket, prefix, err := snapshotsLocation(target.awsClient, target.accessConfig)
+		if err != nil {
+			exit.Error(err)
+		}
+		key := snapshotKey(prefix, snapshot.ID)
+
+		fmt.Print("￮ saving the snapshot ... ")
+		if err := target.awsClient.UploadJSONToS3(snapshot, bucket, key); err != nil {
+			fmt.Print("failed ✗\n\n")
+			exit.Error(err)
+		}
+		fmt.Println("✓")
+
+		fmt.Printf("\ncreated snapshot %s (%s); you can restore it with `cortex cluster snapshot restore %s`\n", console.Bold(snapshot.ID), aws.S3Path(bucket, key), snapshot.ID)
+	},
+}
+
+var _clusterSnapshotListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "list the snapshots of a cluster",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		telemetry.Event("cli.cluster.snapshot.list")
+
+		accessConfig, err := getClusterAccessConfigWithCache()
+		if err != nil {
+			exit.Error(err)
+		}
+
+		awsCredentials, err := getClusterAWSCredentials(accessConfig.ClusterName)
+		if err != nil {
+			exit.Error(err)
+		}
+
+		awsClient, err := newAWSClient(accessConfig.Region, withClusterAssumeRole(awsCredentials, accessConfig), _flagOutput == flags.PrettyOutputType)
+		if err != nil {
+			exit.Error(err)
+		}
+
+		bucket, prefix, err := snapshotsLocation(awsClient, accessConfig)
+		if err != nil {
+			exit.Error(err)
+		}
+
+		snapshots, err := listSnapshots(awsClient, bucket, prefix)
+		if err != nil {
+			exit.Error(err)
+		}
+
+		if _flagOutput == flags.JSONOutputType {
+			bytes, err := libjson.Marshal(snapshots)
+			if err != nil {
+				exit.Error(err)
+			}
+			fmt.Println(string(bytes))
+			return
+		}
+
+		if len(snapshots) == 0 {
+			fmt.Printf("no snapshots found in %s\n", aws.S3Path(bucket, prefix))
+			return
+		}
+
+		rows := make([][]interface{}, 0, len(snapshots))
+		for _, snapshot := range snapshots {
+			createdAt := snapshot.CreatedAt
+			rows = append(rows, []interface{}{snapshot.ID, snapshot.ClusterName, snapshot.Region, len(snapshot.APIs), snapshot.CortexVersion, libtime.SinceStr(&createdAt)})
+		}
+		t := table.Table{
+			Headers: []table.Header{
+				{Title: "id"},
+				{Title: "cluster"},
+				{Title: "region"},
+				{Title: "apis"},
+				{Title: "cortex version"},
+				{Title: "age"},
+			},
+			Rows: rows,
+		}
+		fmt.Print(t.MustFormat())
+	},
+}
+
+var _clusterSnapshotRestoreCmd = &cobra.Command{
+	Use:   "restore SNAPSHOT_ID",
+	Short: "deploy the apis and traffic splitters of a snapshot, and configure its cli environments to connect to the cluster",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		telemetry.Event("cli.cluster.snapshot.restore")
+
+		snapshotID := args[0]
+
+		target, err := getSnapshotTarget()
+		if err != nil {
+			exit.Error(err)
+		}
+
+		bucket, prefix, err := snapshotsLocation(target.awsClient, target.accessConfig)
+		if err != nil {
+			exit.Error(err)
+		}
+
+		snapshot, err := readSnapshot(target.awsClient, bucket, prefix, snapshotID)
+		if err != nil {
+			exit.Error(err)
+		}
+
+		if snapshot.CortexVersion != consts.CortexVersion {
+			fmt.Printf("warning: snapshot %s was created with cortex %s, but your cli is version %s; the configurations of its apis may need to be updated\n\n", snapshot.ID, snapshot.CortexVersion, consts.CortexVersion)
+		}
+
+		if !_flagClusterDisallowPrompt {
+			promptMsg := fmt.Sprintf("%d %s from snapshot %s (created from the cluster named \"%s\" in %s) will be deployed to the cluster named \"%s\" in %s, and existing apis with the same names will be updated; would you like to continue?", len(snapshot.APIs), s.PluralS("api", len(snapshot.APIs)), snapshot.ID, snapshot.ClusterName, snapshot.Region, target.accessConfig.ClusterName, target.accessConfig.Region)
+			prompt.YesOrExit(promptMsg, "", "")
+		}
+
+		if _flagClusterSnapshotSaveConfig != "" {
+			if err := saveSnapshotClusterConfig(snapshot.ClusterConfig, _flagClusterSnapshotSaveConfig); err != nil {
+				exit.Error(err)
+			}
+			fmt.Printf("saved the snapshot's cluster configuration to %s\n\n", _flagClusterSnapshotSaveConfig)
+		}
+
+		if len(snapshot.APIs) > 0 {
+			apisBytes, err := yaml.Marshal(snapshot.APIs)
+			if err != nil {
+				exit.Error(errors.WithStack(err))
+			}
+
+			// all of the apis are deployed together, so that traffic splitters and workflows can reference the apis which they depend on
+			configFileName := fmt.Sprintf("snapshot-%s.yaml", snapshot.ID)
+			deployResults, err := cluster.Deploy(target.operatorConfig, configFileName, map[string][]byte{configFileName: apisBytes}, _flagClusterSnapshotForce, false)
+			if err != nil {
+				exit.Error(err)
+			}
+
+			message := mergeResultMessages(deployResults)
+			if didAnyResultsError(deployResults) {
+				print.StderrBoldFirstBlock(message)
+				exit.Error(nil)
+			}
+			print.BoldFirstBlock(message)
+		} else {
+			fmt.Println("the snapshot doesn't contain any apis")
+		}
+
+		for _, env := range snapshot.Environments {
+			if err := restoreSnapshotEnvironment(env, target.operatorConfig.OperatorEndpoint); err != nil {
+				exit.Error(err)
+			}
+		}
+	},
+}
+
+// returns the access config, aws client, and operator config of the cluster which is selected by the --config/--name/--region flags
+func getSnapshotTarget() (*snapshotTarget, error) {
+	accessConfig, err := getClusterAccessConfigWithCache()
+	if err != nil {
+		return nil, err
+	}
+
+	awsCredentials, err := getClusterAWSCredentials(accessConfig.ClusterName)
+	if err != nil {
+		return nil, err
+	}
+	awsCredentials = withClusterAssumeRole(awsCredentials, accessConfig)
+
+	awsClient, err := newAWSClient(accessConfig.Region, awsCredentials, true)
+	if err != nil {
+		return nil, err
+	}
+	warnIfNotAdmin(awsClient)
+
+	clusterState, err := clusterstate.GetClusterState(awsClient, accessConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	err = clusterstate.AssertClusterStatus(accessConfig.ClusterName, accessConfig.Region, clusterState.Status, clusterstate.StatusCreateComplete, clusterstate.StatusUpdateComplete, clusterstate.StatusUpdateRollbackComplete)
+	if err != nil {
+		return nil, err
+	}
+
+	loadBalancer, err := getLoadBalancer(accessConfig.ClusterName, OperatorLoadBalancer, awsClient)
+	if err != nil {
+		return nil, err
+	}
+
+	return &snapshotTarget{
+		accessConfig: accessConfig,
+		awsClient:    awsClient,
+		operatorConfig: cluster.OperatorConfig{
+			Telemetry:        isTelemetryEnabled(),
+			ClientID:         clientID(),
+			OperatorEndpoint: "https://" + *loadBalancer.DNSName,
+			AWSCredentials:   awsCredentials,
+		},
+	}, nil
+}
+
+// returns the bucket and key prefix under which snapshots are stored
+func snapshotsLocation(awsClient *aws.Client, accessConfig *clusterconfig.AccessConfig) (string, string, error) {
+	if _flagClusterSnapshotS3Path != "" {
+		bucket, key, err := aws.SplitS3Path(_flagClusterSnapshotS3Path)
+		if err != nil {
+			return "", "", err
+		}
+		return bucket, strings.Trim(key, "/"), nil
+	}
+
+	accountID, _, err := awsClient.GetCachedAccountID()
+	if err != nil {
+		return "", "", err
+	}
+	return clusterconfig.BucketName(accountID, accessConfig.ClusterName, accessConfig.Region), _snapshotsDir, nil
+}
+
+func snapshotKey(prefix string, snapshotID string) string {
+	return filepath.Join(prefix, snapshotID+".json")
+}
+
+// returns the cli environments which connect to the cluster (without their operator tokens, which are secret)
+func snapshotEnvironments(operatorEndpoint string) ([]cliconfig.Environment, error) {
+	envNames, _, err := getEnvNamesByOperatorEndpoint(operatorEndpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	environments := []cliconfig.Environment{}
+	for _, envName := range envNames {
+		env, err := readEnv(envName)
+		if err != nil {
+			return nil, err
+		}
+		if env == nil {
+			continue
+		}
+		env.OperatorToken = ""
+		environments = append(environments, *env)
+	}
+
+	return environments, nil
+}
+
+func readSnapshot(awsClient *aws.Client, bucket string, prefix string, snapshotID string) (*clusterSnapshot, error) {
+	key := snapshotKey(prefix, snapshotID)
+
+	exists, err := awsClient.IsS3File(bucket, key)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, ErrorSnapshotNotFound(snapshotID, aws.S3Path(bucket, prefix))
+	}
+
+	var snapshot clusterSnapshot
+	if err := awsClient.ReadJSONFromS3(&snapshot, bucket, key); err != nil {
+		return nil, err
+	}
+	return &snapshot, nil
+}
+
+// returns the snapshots sorted from newest to oldest
+func listSnapshots(awsClient *aws.Client, bucket string, prefix string) ([]clusterSnapshot, error) {
+	objects, err := awsClient.ListS3Dir(bucket, prefix, false, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshots := []clusterSnapshot{}
+	for _, key := range aws.ConvertS3ObjectsToKeys(objects...) {
+		if filepath.Dir(key) != filepath.Clean(prefix) || !strings.HasSuffix(key, ".json") {
+			continue
+		}
+
+		var snapshot clusterSnapshot
+		if err := awsClient.ReadJSONFromS3(&snapshot, bucket, key); err != nil {
+			return nil, err
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+
+	sort.Slice(snapshots, func(i, j int) bool {
+		return snapshots[i].CreatedAt.After(snapshots[j].CreatedAt)
+	})
+
+	return snapshots, nil
+}
+
+// configures the environment to connect to the cluster, prompting before an environment which connects to a different cluster is overwritten
+func restoreSnapshotEnvironment(env cliconfig.Environment, operatorEndpoint string) error {
+	prevEnv, err := readEnv(env.Name)
+	if err != nil {
+		return err
+	}
+
+	env.OperatorEndpoint = operatorEndpoint
+	if prevEnv != nil {
+		if prevEnv.OperatorEndpoint == operatorEndpoint {
+			return nil
+		}
+		if !_flagClusterDisallowPrompt && !prompt.YesOrNo(fmt.Sprintf("\nfound an existing environment named \"%s\"; would you like to overwrite it to connect to this cluster?", env.Name), "", "") {
+			return nil
+		}
+		// the operator token of the existing environment may still be valid (e.g. if it's restored onto the same cluster)
+		env.OperatorToken = prevEnv.OperatorToken
+	}
+
+	if err := addEnvToCLIConfig(env, false); err != nil {
+		return err
+	}
+	fmt.Printf(console.Bold("the environment named \"%s\" has been configured to connect to this cluster\n"), env.Name)
+	return nil
+}
+
+// saves the cluster configuration so that it can be used to create a new cluster (the fields which are set by `cortex cluster up` are cleared)
+func saveSnapshotClusterConfig(clusterConfig clusterconfig.Config, path string) error {
+	clusterConfig.ClusterUID = ""
+	clusterConfig.Bucket = ""
+	clusterConfig.CortexPolicyARN = ""
+	clusterConfig.AccountID = ""
+	clusterConfig.EFSFileSystemID = ""
+
+	yamlBytes, err := yaml.Marshal(clusterConfig)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	return files.WriteFile(yamlBytes, path)
+}
@@ -0,0 +1,384 @@
+/*
+Copyright 2021 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/cortexlabs/cortex/pkg/consts"
+	"github.com/cortexlabs/cortex/pkg/lib/aws"
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/lib/exit"
+	libjson "github.com/cortexlabs/cortex/pkg/lib/json"
+	libmath "github.com/cortexlabs/cortex/pkg/lib/math"
+	"github.com/cortexlabs/cortex/pkg/lib/sets/strset"
+	s "github.com/cortexlabs/cortex/pkg/lib/strings"
+	"github.com/cortexlabs/cortex/pkg/lib/telemetry"
+	"github.com/cortexlabs/cortex/pkg/types/clusterconfig"
+	"github.com/spf13/cobra"
+)
+
+const (
+	_validationStatusPassed  = "passed"
+	_validationStatusFailed  = "failed"
+	_validationStatusSkipped = "skipped"
+)
+
+type validationCheck struct {
+	Check   string `json:"check"`
+	Status  string `json:"status"`
+	Message string `json:"message,omitempty"`
+}
+
+type validationReport struct {
+	ClusterName string            `json:"cluster_name"`
+	Region      string            `json:"region"`
+	Checks      []validationCheck `json:"checks"`
+}
+
+// a set of actions which the cluster's creator must be allowed to perform on a resource (a subset of the minimum iam policy in docs/clusters/management/auth.md)
+type iamPermissions struct {
+	resource string
+	actions  []string
+}
+
+var _clusterValidateCmd = &cobra.Command{
+	Use:   "validate CLUSTER_CONFIG_FILE",
+	Short: "check a cluster configuration and your aws account's quotas and permissions without creating a cluster",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		telemetry.Event("cli.cluster.validate")
+
+		clusterConfigFile := args[0]
+
+		accessConfig, err := getNewClusterAccessConfig(clusterConfigFile)
+		if err != nil {
+			exit.Error(err)
+		}
+
+		awsCredentials, err := getClusterAWSCredentials(accessConfig.ClusterName)
+		if err != nil {
+			exit.Error(err)
+		}
+
+		awsClient, err := newAWSClient(accessConfig.Region, withClusterAssumeRole(awsCredentials, accessConfig), true)
+		if err != nil {
+			exit.Error(err)
+		}
+
+		checks := runClusterValidationChecks(awsClient, accessConfig, clusterConfigFile)
+		fmt.Printf("\nvalidation summary: %s\n", validationSummary(checks))
+
+		if _flagClusterValidateReport != "" {
+			report := validationReport{
+				ClusterName: accessConfig.ClusterName,
+				Region:      accessConfig.Region,
+				Checks:      checks,
+			}
+			if err := libjson.WriteJSON(report, _flagClusterValidateReport); err != nil {
+				exit.Error(err)
+			}
+			fmt.Printf("saved the validation report to %s\n", _flagClusterValidateReport)
+		}
+
+		var numFailed int
+		for _, check := range checks {
+			if check.Status == _validationStatusFailed {
+				numFailed++
+			}
+		}
+		if numFailed > 0 {
+			exit.Error(ErrorClusterValidate(numFailed))
+		}
+	},
+}
+
+// the checks which depend on the cluster configuration are skipped if it can't be parsed; the iam check only depends on the cluster's name and region
+func runClusterValidationChecks(awsClient *aws.Client, accessConfig *clusterconfig.AccessConfig, clusterConfigFile string) []validationCheck {
+	var checks []validationCheck
+
+	clusterConfig := &clusterconfig.Config{}
+	isParsed := false
+	configErr := readUserClusterConfigFile(clusterConfig, clusterConfigFile)
+	if configErr == nil {
+		isParsed = true
+		configErr = clusterConfig.Validate(awsClient)
+		if configErr != nil {
+			configErr = errors.Wrap(configErr, clusterConfigFile)
+		}
+	}
+	checks = append(checks, printValidationCheck("cluster configuration", func() validationCheck {
+		if configErr != nil {
+			return validationFailed(errors.Message(configErr))
+		}
+		return validationPassed("")
+	}))
+
+	// the availability zones are determined while validating the cluster configuration, unless they are specified explicitly
+	zones := strset.FromSlice(clusterConfig.AvailabilityZones)
+	for _, subnet := range clusterConfig.Subnets {
+		zones.Add(subnet.AvailabilityZone)
+	}
+
+	checks = append(checks, printValidationCheck("instance type availability", func() validationCheck {
+		if !isParsed {
+			return validationSkipped("the cluster configuration could not be parsed")
+		}
+		if len(zones) == 0 {
+			return validationSkipped("the availability zones could not be determined")
+		}
+		return checkInstanceTypeAvailability(awsClient, clusterConfig, zones)
+	}))
+
+	checks = append(checks, printValidationCheck("instance quotas", func() validationCheck {
+		if !isParsed {
+			return validationSkipped("the cluster configuration could not be parsed")
+		}
+		return checkInstanceQuotas(awsClient, clusterConfig)
+	}))
+
+	checks = append(checks, printValidationCheck("network quotas", func() validationCheck {
+		if !isParsed {
+			return validationSkipped("the cluster configuration could not be parsed")
+		}
+		if len(zones) == 0 {
+			return validationSkipped("the availability zones could not be determined")
+		}
+		return checkNetworkQuotas(awsClient, clusterConfig, zones)
+	}))
+
+	checks = append(checks, printValidationCheck("iam permissions", func() validationCheck {
+		return checkIAMPermissions(awsClient, accessConfig)
+	}))
+
+	return checks
+}
+
+func printValidationCheck(name string, run func() validationCheck) validationCheck {
+	fmt.Printf("￮ %s ... ", name)
+
+	check := run()
+	check.Check = name
+
+	switch check.Status {
+	case _validationStatusPassed:
+		if check.Message != "" {
+			fmt.Println(check.Message + " ✓")
+		} else {
+			fmt.Println("✓")
+		}
+	case _validationStatusSkipped:
+		fmt.Println("skipped (" + check.Message + ")")
+	case _validationStatusFailed:
+		fmt.Print("failed ✗\n\n")
+		fmt.Print(check.Message + "\n\n")
+	}
+
+	return check
+}
+
+func validationPassed(message string) validationCheck {
+	return validationCheck{Status: _validationStatusPassed, Message: message}
+}
+
+func validationFailed(message string) validationCheck {
+	return validationCheck{Status: _validationStatusFailed, Message: message}
+}
+
+func validationSkipped(message string) validationCheck {
+	return validationCheck{Status: _validationStatusSkipped, Message: message}
+}
+
+func validationSummary(checks []validationCheck) string {
+	counts := map[string]int{}
+	for _, check := range checks {
+		counts[check.Status]++
+	}
+
+	var parts []string
+	for _, status := range []string{_validationStatusPassed, _validationStatusFailed, _validationStatusSkipped} {
+		if counts[status] > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", counts[status], status))
+		}
+	}
+	return s.StrsAnd(parts)
+}
+
+// unlike the availability zone validation of the cluster configuration, this also checks the instance types of the spot instance distributions
+func checkInstanceTypeAvailability(awsClient *aws.Client, clusterConfig *clusterconfig.Config, zones strset.Set) validationCheck {
+	instanceTypes := strset.New()
+	for _, ng := range clusterConfig.NodeGroups {
+		instanceTypes.Add(ng.InstanceType)
+		if ng.Spot && ng.SpotConfig != nil {
+			instanceTypes.Add(ng.SpotConfig.InstanceDistribution...)
+		}
+	}
+
+	var unavailable []string
+	for _, instanceType := range instanceTypes.SliceSorted() {
+		supportedZones, err := awsClient.ListSupportedAvailabilityZones(instanceType)
+		if err != nil {
+			return validationSkipped("unable to list the availability zones which offer " + instanceType + ": " + errors.MessageFirstLine(err))
+		}
+		missingZones := strset.Difference(zones, supportedZones)
+		if len(missingZones) > 0 {
+			unavailable = append(unavailable, fmt.Sprintf("%s is not offered in %s", instanceType, s.StrsAnd(missingZones.SliceSorted())))
+		}
+	}
+
+	if len(unavailable) > 0 {
+		return validationFailed(strings.Join(unavailable, "\n") + fmt.Sprintf("\n\nyou can specify availability zones which offer these instance types via the %s field, or choose different instance types", clusterconfig.AvailabilityZonesKey))
+	}
+	return validationPassed(fmt.Sprintf("%s offered in %s", s.PluralCustom("the instance type is", "all instance types are", len(instanceTypes)), s.StrsAnd(zones.SliceSorted())))
+}
+
+func checkInstanceQuotas(awsClient *aws.Client, clusterConfig *clusterconfig.Config) validationCheck {
+	if err := awsClient.VerifyInstanceQuota(clusterConfig.InstanceTypeRequests()); err != nil {
+		// some regions (e.g. eu-north-1) do not support the service quotas api
+		if aws.IsAWSError(err) {
+			return validationSkipped("unable to retrieve the instance quotas: " + errors.MessageFirstLine(err))
+		}
+		return validationFailed(errors.Message(err))
+	}
+	return validationPassed("")
+}
+
+func checkNetworkQuotas(awsClient *aws.Client, clusterConfig *clusterconfig.Config, zones strset.Set) validationCheck {
+	var requiredVPCs int
+	if len(clusterConfig.Subnets) == 0 {
+		requiredVPCs = 1
+	}
+	longestCIDRWhiteList := libmath.MaxInt(len(clusterConfig.APILoadBalancerCIDRWhiteList), len(clusterConfig.OperatorLoadBalancerCIDRWhiteList))
+
+	err := awsClient.VerifyNetworkQuotas(1, clusterConfig.NATGateway != clusterconfig.NoneNATGateway, clusterConfig.NATGateway == clusterconfig.HighlyAvailableNATGateway, requiredVPCs, zones, len(clusterConfig.NodeGroups), longestCIDRWhiteList)
+	if err != nil {
+		// some regions (e.g. eu-north-1) do not support the service quotas api
+		if aws.IsAWSError(err) {
+			return validationSkipped("unable to retrieve the network quotas: " + errors.MessageFirstLine(err))
+		}
+		return validationFailed(errors.Message(err))
+	}
+	return validationPassed("")
+}
+
+func checkIAMPermissions(awsClient *aws.Client, accessConfig *clusterconfig.AccessConfig) validationCheck {
+	accountID, _, err := awsClient.GetCachedAccountID()
+	if err != nil {
+		return validationSkipped(errors.MessageFirstLine(err))
+	}
+
+	principalARN, err := awsClient.GetPrincipalARN()
+	if err != nil {
+		return validationSkipped("unable to determine the arn of your iam user or role: " + errors.MessageFirstLine(err))
+	}
+
+	// the policy simulator doesn't support the root user, which has full access
+	if strings.HasSuffix(principalARN, ":root") {
+		return validationPassed("the root user has full access")
+	}
+
+	var deniedActions []string
+	for _, permissions := range clusterIAMPermissions(accountID, accessConfig.ClusterName, accessConfig.Region) {
+		denied, err := awsClient.ListDeniedActions(principalARN, permissions.resource, permissions.actions)
+		if err != nil {
+			if aws.IsErrCode(err, "AccessDenied") {
+				return validationSkipped(fmt.Sprintf("%s is not allowed to run the iam policy simulator (iam:SimulatePrincipalPolicy)", principalARN))
+			}
+			return validationSkipped("unable to run the iam policy simulator: " + errors.MessageFirstLine(err))
+		}
+		for _, action := range denied {
+			deniedActions = append(deniedActions, fmt.Sprintf("%s (on %s)", action, permissions.resource))
+		}
+	}
+
+	if len(deniedActions) > 0 {
+		return validationFailed(fmt.Sprintf("%s is not allowed to perform the following %s:\n  %s\n\nsee https://docs.cortex.dev/v/%s/clusters/management/auth#minimum-iam-policy for the minimum iam policy required to create a cluster", principalARN, s.PluralS("action", len(deniedActions)), strings.Join(deniedActions, "\n  "), consts.CortexVersionMinor))
+	}
+	return validationPassed("")
+}
+
+func clusterIAMPermissions(accountID string, clusterName string, region string) []iamPermissions {
+	partition := aws.PartitionFromRegion(region)
+	bucket := clusterconfig.BucketName(accountID, clusterName, region)
+
+	return []iamPermissions{
+		{
+			resource: "*",
+			actions: []string{
+				"cloudformation:CreateStack",
+				"cloudformation:DescribeStacks",
+				"ec2:RunInstances",
+				"ec2:CreateVpc",
+				"ec2:AllocateAddress",
+				"ec2:CreateNatGateway",
+				"ec2:CreateSecurityGroup",
+				"ec2:DescribeAvailabilityZones",
+				"eks:CreateCluster",
+				"eks:DescribeCluster",
+				"autoscaling:CreateAutoScalingGroup",
+				"elasticloadbalancing:DescribeLoadBalancers",
+				"elasticfilesystem:CreateFileSystem",
+				"servicequotas:ListServiceQuotas",
+				"ecr:GetAuthorizationToken",
+				"iam:GetPolicy",
+				"sts:GetCallerIdentity",
+			},
+		},
+		{
+			resource: fmt.Sprintf("arn:%s:iam::%s:role/eksctl-%s-*", partition, accountID, clusterName),
+			actions: []string{
+				"iam:CreateRole",
+				"iam:GetRole",
+				"iam:PassRole",
+				"iam:AttachRolePolicy",
+				"iam:PutRolePolicy",
+				"iam:DeleteRole",
+			},
+		},
+		{
+			resource: clusterconfig.DefaultPolicyARN(accountID, clusterName, region),
+			actions: []string{
+				"iam:CreatePolicy",
+				"iam:DeletePolicy",
+			},
+		},
+		{
+			resource: fmt.Sprintf("arn:%s:s3:::%s", partition, bucket),
+			actions: []string{
+				"s3:CreateBucket",
+				"s3:ListBucket",
+				"s3:PutLifecycleConfiguration",
+			},
+		},
+		{
+			resource: fmt.Sprintf("arn:%s:s3:::%s/*", partition, bucket),
+			actions: []string{
+				"s3:GetObject",
+				"s3:PutObject",
+			},
+		},
+		{
+			resource: fmt.Sprintf("arn:%s:logs:%s:%s:log-group:%s", partition, region, accountID, clusterName),
+			actions: []string{
+				"logs:CreateLogGroup",
+				"logs:TagLogGroup",
+				"logs:PutRetentionPolicy",
+			},
+		},
+	}
+}
@@ -73,10 +73,16 @@ to enable cortex shell completion:
             create a _cortex file in your fpath, for example:
                 cortex completion zsh > /usr/local/share/zsh/site-functions/_cortex
 
+    fish:
+        add this to ~/.config/fish/config.fish:
+            cortex completion fish | source
+
+api names, job ids, environment names, and node group names are completed dynamically (api names and job ids are fetched from the operator of the environment which is selected with --env, or the default environment)
+
 Note: this will also add the "cx" alias for cortex for convenience
 `,
 	Args:      cobra.ExactArgs(1),
-	ValidArgs: []string{"bash", "zsh"},
+	ValidArgs: []string{"bash", "zsh", "fish"},
 	Run: func(cmd *cobra.Command, args []string) {
 		switch args[0] {
 		case "bash":
@@ -94,6 +100,10 @@ Note: this will also add the "cx" alias for cortex for convenience
 			// https://github.com/asdf-vm/asdf/issues/266
 			fmt.Println("if compquote '' 2>/dev/null; then _cortex; else compdef _cortex cortex; fi")
 
+		case "fish":
+			_rootCmd.GenFishCompletion(os.Stdout, true)
+			fmt.Print("\nalias cx='cortex'\ncomplete -c cx -w cortex\n")
+
 		default:
 			fmt.Println()
 			exit.Error(ErrorShellCompletionNotSupported(args[0]))
@@ -0,0 +1,642 @@
+/*
+Copyright 2021 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cortexlabs/cortex/cli/cluster"
+	"github.com/cortexlabs/cortex/cli/lib/routines"
+	"github.com/cortexlabs/cortex/cli/types/cliconfig"
+	"github.com/cortexlabs/cortex/pkg/lib/console"
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/lib/exit"
+	libmath "github.com/cortexlabs/cortex/pkg/lib/math"
+	"github.com/cortexlabs/cortex/pkg/lib/pointer"
+	s "github.com/cortexlabs/cortex/pkg/lib/strings"
+	"github.com/cortexlabs/cortex/pkg/lib/table"
+	"github.com/cortexlabs/cortex/pkg/lib/telemetry"
+	libtime "github.com/cortexlabs/cortex/pkg/lib/time"
+	"github.com/cortexlabs/cortex/pkg/operator/schema"
+	"github.com/cortexlabs/cortex/pkg/types/userconfig"
+	"github.com/spf13/cobra"
+)
+
+const (
+	_dashboardRefreshInterval = 5 * time.Second
+	_dashboardMaxLogLines     = 5000
+)
+
+const (
+	_keyUp      = "up"
+	_keyDown    = "down"
+	_keyEnter   = "enter"
+	_keyBack    = "back"
+	_keyTab     = "tab"
+	_keyLogs    = "logs"
+	_keyRefresh = "refresh"
+	_keyQuit    = "quit"
+)
+
+type dashboardView int
+
+const (
+	_dashboardAPIsView dashboardView = iota
+	_dashboardClusterView
+	_dashboardAPIView
+	_dashboardJobView
+	_dashboardLogsView
+)
+
+var (
+	_flagDashboardEnv string
+)
+
+func dashboardInit() {
+	_dashboardCmd.Flags().SortFlags = false
+	_dashboardCmd.Flags().StringVarP(&_flagDashboardEnv, "env", "e", "", "environment to use")
+}
+
+var _dashboardCmd = &cobra.Command{
+	Use:   "dashboard",
+	Short: "open an interactive terminal dashboard for the cluster, apis, jobs, and logs",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		envName, err := getEnvFromFlag(_flagDashboardEnv)
+		if err != nil {
+			telemetry.Event("cli.dashboard")
+			exit.Error(err)
+		}
+
+		env, err := ReadOrConfigureEnv(envName)
+		if err != nil {
+			telemetry.Event("cli.dashboard")
+			exit.Error(err)
+		}
+		telemetry.Event("cli.dashboard", map[string]interface{}{"env_name": env.Name})
+
+		if height, width := getTerminalSize(); height <= 0 || width <= 0 {
+			exit.Error(ErrorDashboardRequiresTerminal())
+		}
+
+		d := &dashboard{
+			env:            env,
+			operatorConfig: MustGetOperatorConfig(env.Name),
+			logUpdates:     make(chan struct{}, 1),
+		}
+
+		if err := d.run(); err != nil {
+			exit.Error(err)
+		}
+	},
+}
+
+type dashboard struct {
+	env            cliconfig.Environment
+	operatorConfig cluster.OperatorConfig
+
+	view      dashboardView
+	prevView  dashboardView // the view to return to when leaving the logs view
+	body      string        // the rendered content of the current view (except the logs view)
+	scroll    int
+	err       error  // the error from the most recent refresh
+	notice    string // a message which is shown until the next key press
+	updatedAt string
+
+	apis    []schema.APIResponse
+	apiName string
+	apiKind userconfig.Kind
+	jobIDs  []string
+	jobID   string
+
+	logs       *dashboardLogBuffer
+	logUpdates chan struct{}
+	stopLogs   chan struct{}
+}
+
+func (d *dashboard) run() error {
+	restoreTerminal, err := enterRawMode()
+	if err != nil {
+		return err
+	}
+	defer restoreTerminal()
+
+	keys := make(chan string)
+	routines.RunWithPanicHandler(func() {
+		readKeys(keys)
+	}, false)
+
+	ticker := time.NewTicker(_dashboardRefreshInterval)
+	defer ticker.Stop()
+
+	d.refresh()
+	d.draw()
+
+	for {
+		select {
+		case key := <-keys:
+			d.notice = ""
+			if key == _keyQuit {
+				d.stopLogStream()
+				return nil
+			}
+			d.handleKey(key)
+		case <-ticker.C:
+			d.refresh()
+		case <-d.logUpdates:
+		}
+		d.draw()
+	}
+}
+
+func (d *dashboard) handleKey(key string) {
+	switch key {
+	case _keyUp, _keyDown:
+		d.move(key)
+	case _keyEnter:
+		d.enter()
+	case _keyBack:
+		d.back()
+	case _keyTab:
+		switch d.view {
+		case _dashboardAPIsView:
+			d.setView(_dashboardClusterView)
+		case _dashboardClusterView:
+			d.setView(_dashboardAPIsView)
+		}
+	case _keyLogs:
+		d.openLogs()
+	case _keyRefresh:
+		d.refresh()
+	}
+}
+
+func (d *dashboard) setView(view dashboardView) {
+	d.view = view
+	d.scroll = 0
+	d.body = ""
+	d.err = nil
+	d.refresh()
+}
+
+func (d *dashboard) move(key string) {
+	delta := 1
+	if key == _keyUp {
+		delta = -1
+	}
+
+	switch {
+	case d.view == _dashboardAPIsView && len(d.apis) > 0:
+		i := libmath.MinInt(libmath.MaxInt(d.apiIndex()+delta, 0), len(d.apis)-1)
+		d.apiName = d.apis[i].Spec.Name
+		d.apiKind = d.apis[i].Spec.Kind
+		d.body = d.apisBody()
+	case d.view == _dashboardAPIView && len(d.jobIDs) > 0:
+		i := libmath.MinInt(libmath.MaxInt(indexOf(d.jobIDs, d.jobID)+delta, 0), len(d.jobIDs)-1)
+		d.jobID = d.jobIDs[i]
+	case d.view != _dashboardLogsView:
+		d.scroll = libmath.MaxInt(d.scroll+delta, 0)
+	}
+}
+
+func (d *dashboard) enter() {
+	switch {
+	case d.view == _dashboardAPIsView && d.apiName != "":
+		d.jobIDs = nil
+		d.jobID = ""
+		d.setView(_dashboardAPIView)
+	case d.view == _dashboardAPIView && d.jobID != "":
+		d.setView(_dashboardJobView)
+	}
+}
+
+func (d *dashboard) back() {
+	switch d.view {
+	case _dashboardLogsView:
+		d.stopLogStream()
+		d.setView(d.prevView)
+	case _dashboardJobView:
+		d.setView(_dashboardAPIView)
+	case _dashboardAPIView, _dashboardClusterView:
+		d.setView(_dashboardAPIsView)
+	}
+}
+
+func (d *dashboard) openLogs() {
+	if d.apiName == "" || (d.view != _dashboardAPIsView && d.view != _dashboardAPIView && d.view != _dashboardJobView) {
+		return
+	}
+
+	jobID := ""
+	switch d.apiKind {
+	case userconfig.RealtimeAPIKind, userconfig.AsyncAPIKind:
+	case userconfig.BatchAPIKind, userconfig.TaskAPIKind:
+		if d.view == _dashboardAPIsView || d.jobID == "" {
+			d.notice = "select a job to view its logs"
+			return
+		}
+		jobID = d.jobID
+	default:
+		d.notice = fmt.Sprintf("logs are not available for %s apis", d.apiKind.String())
+		return
+	}
+
+	d.prevView = d.view
+	d.view = _dashboardLogsView
+	d.err = nil
+	d.logs = &dashboardLogBuffer{updates: d.logUpdates}
+	d.stopLogs = make(chan struct{})
+
+	operatorConfig, apiName, logs, stop := d.operatorConfig, d.apiName, d.logs, d.stopLogs
+	routines.RunWithPanicHandler(func() {
+		var err error
+		if jobID == "" {
+			err = cluster.StreamLogsTo(operatorConfig, apiName, logs, stop)
+		} else {
+			err = cluster.StreamJobLogsTo(operatorConfig, apiName, jobID, logs, stop)
+		}
+		if err != nil {
+			logs.Write([]byte("\n" + errors.Message(err) + "\n"))
+		}
+	}, false)
+}
+
+func (d *dashboard) stopLogStream() {
+	if d.stopLogs != nil {
+		close(d.stopLogs)
+		d.stopLogs = nil
+	}
+}
+
+func (d *dashboard) refresh() {
+	var err error
+
+	switch d.view {
+	case _dashboardAPIsView:
+		var apis []schema.APIResponse
+		apis, err = cluster.GetAPIs(d.operatorConfig)
+		if err == nil {
+			sort.Slice(apis, func(i, j int) bool {
+				return apis[i].Spec.Name < apis[j].Spec.Name
+			})
+			d.apis = apis
+			if len(apis) > 0 && d.apiIndex() == -1 {
+				d.apiName = apis[0].Spec.Name
+				d.apiKind = apis[0].Spec.Kind
+			}
+			d.body = d.apisBody()
+		}
+	case _dashboardClusterView:
+		var infoResponse *schema.InfoResponse
+		infoResponse, err = cluster.Info(d.operatorConfig)
+		if err == nil {
+			d.body = fmt.Sprintf("cluster version: %s\n", infoResponse.ClusterConfig.APIVersion) + infoNodesStr(infoResponse) + infoQuotasStr(infoResponse) + infoBudgetStr(infoResponse)
+		}
+	case _dashboardAPIView:
+		var apisRes []schema.APIResponse
+		apisRes, err = cluster.GetAPI(d.operatorConfig, d.apiName)
+		if err == nil && len(apisRes) == 0 {
+			err = errors.ErrorUnexpected(fmt.Sprintf("unable to find API %s", d.apiName))
+		}
+		if err == nil {
+			d.jobIDs = dashboardJobIDs(apisRes[0])
+			if len(d.jobIDs) > 0 && indexOf(d.jobIDs, d.jobID) == -1 {
+				d.jobID = d.jobIDs[0]
+			}
+			d.body, err = apiTable(apisRes[0], d.env)
+		}
+	case _dashboardJobView:
+		if d.apiKind == userconfig.BatchAPIKind {
+			d.body, err = getBatchJob(d.env, d.apiName, d.jobID)
+		} else {
+			d.body, err = getTaskJob(d.env, d.apiName, d.jobID)
+		}
+	case _dashboardLogsView:
+		return
+	}
+
+	d.err = err
+	if err == nil {
+		d.updatedAt = libtime.LocalHourNow()
+	}
+}
+
+func (d *dashboard) apiIndex() int {
+	for i, api := range d.apis {
+		if api.Spec.Name == d.apiName {
+			return i
+		}
+	}
+	return -1
+}
+
+func (d *dashboard) apisBody() string {
+	if len(d.apis) == 0 {
+		return console.Bold("no apis are deployed") + "\n"
+	}
+
+	rows := make([][]interface{}, 0, len(d.apis))
+	hasProjects := false
+	for _, api := range d.apis {
+		project := "-"
+		if api.Spec.Project != nil {
+			project = *api.Spec.Project
+			hasProjects = true
+		}
+		lastUpdated := time.Unix(api.Spec.LastUpdated, 0)
+		rows = append(rows, []interface{}{
+			api.Spec.Name,
+			api.Spec.Kind.String(),
+			project,
+			dashboardAPIStatus(api),
+			libtime.SinceStr(&lastUpdated),
+		})
+	}
+
+	t := table.Table{
+		Headers: []table.Header{
+			{Title: "api"},
+			{Title: "kind"},
+			{Title: "project", Hidden: !hasProjects},
+			{Title: _titleStatus},
+			{Title: _titleLastupdated},
+		},
+		Rows: rows,
+	}
+
+	return t.MustFormat(&table.Opts{Sort: pointer.Bool(false)})
+}
+
+func dashboardAPIStatus(api schema.APIResponse) string {
+	if api.Status != nil {
+		return fmt.Sprintf("%s (%d/%d ready)", api.Status.Message(), api.Status.Updated.Ready, api.Status.Requested)
+	}
+	if numJobs := len(api.BatchJobStatuses) + len(api.TaskJobStatuses); numJobs > 0 {
+		return fmt.Sprintf("%d %s", numJobs, s.PluralS("job", numJobs))
+	}
+	return "-"
+}
+
+// the job ids in the order in which they are listed by batchAPITable() and taskAPITable()
+func dashboardJobIDs(api schema.APIResponse) []string {
+	var jobIDs []string
+	for _, job := range api.BatchJobStatuses {
+		jobIDs = append(jobIDs, job.ID)
+	}
+	for _, job := range api.TaskJobStatuses {
+		jobIDs = append(jobIDs, job.ID)
+	}
+	sort.Strings(jobIDs)
+	return jobIDs
+}
+
+func (d *dashboard) draw() {
+	height, _ := getTerminalSize()
+	bodyHeight := libmath.MaxInt(height-4, 1)
+
+	var lines []string
+	if d.view == _dashboardLogsView {
+		lines = d.logs.tail(bodyHeight)
+	} else {
+		lines, d.scroll = d.bodyWindow(bodyHeight)
+	}
+
+	out := "\033[H" // move the cursor to the top left
+	out += "\033[2K" + d.titleLine() + "\r\n\033[2K\r\n"
+	for _, line := range lines {
+		out += "\033[2K" + line + "\r\n"
+	}
+	for i := len(lines); i < bodyHeight; i++ {
+		out += "\033[2K\r\n"
+	}
+	out += "\033[2K\r\n\033[2K" + d.footerLine()
+	out += "\033[J" // clear the rest of the screen
+
+	fmt.Print(out)
+}
+
+// returns the lines of the body which fit on the screen (with the selected row marked) and the updated scroll offset
+func (d *dashboard) bodyWindow(bodyHeight int) ([]string, int) {
+	selected := ""
+	switch d.view {
+	case _dashboardAPIsView:
+		selected = d.apiName
+	case _dashboardAPIView:
+		selected = d.jobID
+	}
+
+	lines := strings.Split(strings.TrimRight(d.body, "\n"), "\n")
+	selectedLine := -1
+	for i, line := range lines {
+		if selected != "" && selectedLine == -1 && strings.HasPrefix(line, selected+" ") {
+			selectedLine = i
+			lines[i] = "> " + line
+		} else {
+			lines[i] = "  " + line
+		}
+	}
+
+	scroll := d.scroll
+	if selectedLine != -1 {
+		if selectedLine < scroll {
+			scroll = selectedLine
+		}
+		if selectedLine >= scroll+bodyHeight {
+			scroll = selectedLine - bodyHeight + 1
+		}
+	}
+	scroll = libmath.MinInt(scroll, libmath.MaxInt(len(lines)-bodyHeight, 0))
+
+	return lines[scroll:libmath.MinInt(scroll+bodyHeight, len(lines))], scroll
+}
+
+func (d *dashboard) titleLine() string {
+	var location string
+	switch d.view {
+	case _dashboardAPIsView:
+		location = "apis"
+	case _dashboardClusterView:
+		location = "cluster"
+	case _dashboardAPIView:
+		location = "apis > " + d.apiName
+	case _dashboardJobView:
+		location = "apis > " + d.apiName + " > " + d.jobID
+	case _dashboardLogsView:
+		location = "logs > " + d.apiName
+		if d.prevView != _dashboardAPIsView && d.jobID != "" && (d.apiKind == userconfig.BatchAPIKind || d.apiKind == userconfig.TaskAPIKind) {
+			location += " > " + d.jobID
+		}
+	}
+
+	title := console.Bold("cortex dashboard") + "  env: " + d.env.Name + "  " + location
+	if d.updatedAt != "" && d.view != _dashboardLogsView {
+		title += "  (updated " + d.updatedAt + ")"
+	}
+	return title
+}
+
+func (d *dashboard) footerLine() string {
+	if d.err != nil {
+		return console.Bold("error: ") + strings.Split(errors.Message(d.err), "\n")[0]
+	}
+	if d.notice != "" {
+		return d.notice
+	}
+
+	switch d.view {
+	case _dashboardAPIsView:
+		return "j/k: select  enter: details  l: logs  tab: cluster  r: refresh  q: quit"
+	case _dashboardClusterView:
+		return "j/k: scroll  tab: apis  r: refresh  q: quit"
+	case _dashboardAPIView:
+		if len(d.jobIDs) > 0 {
+			return "j/k: select job  enter: job details  l: job logs  esc: back  r: refresh  q: quit"
+		}
+		return "j/k: scroll  l: logs  esc: back  r: refresh  q: quit"
+	case _dashboardJobView:
+		return "j/k: scroll  l: logs  esc: back  r: refresh  q: quit"
+	default:
+		return "esc: back  q: quit"
+	}
+}
+
+type dashboardLogBuffer struct {
+	mu      sync.Mutex
+	lines   []string
+	partial string // the last line, if it hasn't been terminated yet
+	updates chan<- struct{}
+}
+
+func (b *dashboardLogBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	lines := strings.Split(b.partial+strings.ReplaceAll(string(p), "\r", ""), "\n")
+	b.partial = lines[len(lines)-1]
+	b.lines = append(b.lines, lines[:len(lines)-1]...)
+	if len(b.lines) > _dashboardMaxLogLines {
+		b.lines = b.lines[len(b.lines)-_dashboardMaxLogLines:]
+	}
+	b.mu.Unlock()
+
+	select {
+	case b.updates <- struct{}{}:
+	default:
+	}
+
+	return len(p), nil
+}
+
+func (b *dashboardLogBuffer) tail(n int) []string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	lines := b.lines
+	if b.partial != "" {
+		lines = append(lines[:len(lines):len(lines)], b.partial)
+	}
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return lines
+}
+
+func indexOf(strs []string, str string) int {
+	for i, elem := range strs {
+		if elem == str {
+			return i
+		}
+	}
+	return -1
+}
+
+// switches the terminal to raw mode and the alternate screen, and returns a function which restores it
+func enterRawMode() (func(), error) {
+	state, err := stty("-g")
+	if err != nil {
+		return nil, ErrorDashboardRequiresTerminal()
+	}
+	if _, err := stty("raw", "-echo"); err != nil {
+		return nil, ErrorDashboardRequiresTerminal()
+	}
+
+	fmt.Print("\033[?1049h\033[?25l\033[?7l") // use the alternate screen, hide the cursor, and disable line wrapping
+
+	return func() {
+		fmt.Print("\033[?7h\033[?25h\033[?1049l")
+		stty(strings.TrimSpace(state))
+	}, nil
+}
+
+func stty(args ...string) (string, error) {
+	cmd := exec.Command("stty", args...)
+	cmd.Stdin = os.Stdin
+	out, err := cmd.Output()
+	return string(out), err
+}
+
+// reads key presses from stdin (which must be in raw mode) until stdin is closed
+func readKeys(keys chan<- string) {
+	buf := make([]byte, 16)
+	for {
+		n, err := os.Stdin.Read(buf)
+		if err != nil {
+			keys <- _keyQuit
+			return
+		}
+
+		input := string(buf[:n])
+		switch {
+		case input == "\033":
+			keys <- _keyBack
+			continue
+		case strings.HasPrefix(input, "\033[A"):
+			keys <- _keyUp
+			continue
+		case strings.HasPrefix(input, "\033[B"):
+			keys <- _keyDown
+			continue
+		case strings.HasPrefix(input, "\033"):
+			continue
+		}
+
+		for _, c := range input {
+			switch c {
+			case 'q', 3: // 3 is ctrl-c
+				keys <- _keyQuit
+			case 'k':
+				keys <- _keyUp
+			case 'j':
+				keys <- _keyDown
+			case '\r', '\n':
+				keys <- _keyEnter
+			case 127, 'b': // 127 is backspace
+				keys <- _keyBack
+			case '\t':
+				keys <- _keyTab
+			case 'l':
+				keys <- _keyLogs
+			case 'r':
+				keys <- _keyRefresh
+			}
+		}
+	}
+}
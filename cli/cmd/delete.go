@@ -19,14 +19,22 @@ package cmd
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/cortexlabs/cortex/cli/cluster"
 	"github.com/cortexlabs/cortex/cli/types/flags"
+	"github.com/cortexlabs/cortex/pkg/lib/console"
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
 	"github.com/cortexlabs/cortex/pkg/lib/exit"
 	libjson "github.com/cortexlabs/cortex/pkg/lib/json"
 	"github.com/cortexlabs/cortex/pkg/lib/print"
+	"github.com/cortexlabs/cortex/pkg/lib/prompt"
+	s "github.com/cortexlabs/cortex/pkg/lib/strings"
+	"github.com/cortexlabs/cortex/pkg/lib/table"
 	"github.com/cortexlabs/cortex/pkg/lib/telemetry"
+	libtime "github.com/cortexlabs/cortex/pkg/lib/time"
 	"github.com/cortexlabs/cortex/pkg/operator/schema"
+	"github.com/cortexlabs/cortex/pkg/types/userconfig"
 	"github.com/spf13/cobra"
 )
 
@@ -34,6 +42,9 @@ var (
 	_flagDeleteEnv       string
 	_flagDeleteKeepCache bool
 	_flagDeleteForce     bool
+	_flagDeleteSelector  string
+	_flagDeleteAll       bool
+	_flagDeleteDryRun    bool
 )
 
 func deleteInit() {
@@ -42,14 +53,29 @@ func deleteInit() {
 
 	_deleteCmd.Flags().BoolVarP(&_flagDeleteForce, "force", "f", false, "delete the api without confirmation")
 	_deleteCmd.Flags().BoolVarP(&_flagDeleteKeepCache, "keep-cache", "c", false, "keep cached data for the api")
+	_deleteCmd.Flags().StringVarP(&_flagDeleteSelector, "selector", "l", "", "delete all of the apis whose labels match a label selector (e.g. team=nlp,stage!=dev)")
+	_deleteCmd.Flags().BoolVar(&_flagDeleteAll, "all", false, "delete all of the apis in the environment")
+	_deleteCmd.Flags().BoolVar(&_flagDeleteDryRun, "dry-run", false, "list the apis which would be deleted by --all or --selector without deleting them")
 	_deleteCmd.Flags().VarP(&_flagOutput, "output", "o", fmt.Sprintf("output format: one of %s", strings.Join(flags.UserOutputTypeStrings(), "|")))
 }
 
 var _deleteCmd = &cobra.Command{
-	Use:   "delete API_NAME [JOB_ID]",
-	Short: "delete an api or stop a job",
-	Args:  cobra.RangeArgs(1, 2),
+	Use:               "delete [API_NAME] [JOB_ID|REQUEST_ID]",
+	Short:             "delete an api, stop a job, or cancel an async request",
+	Args:              cobra.RangeArgs(0, 2),
+	ValidArgsFunction: completeAPINameAndJobIDArgs,
 	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) > 0 {
+			if _flagDeleteSelector != "" {
+				exit.Error(ErrorBulkDeleteWithAPIName("--selector"))
+			}
+			if _flagDeleteAll {
+				exit.Error(ErrorBulkDeleteWithAPIName("--all"))
+			}
+		} else if _flagDeleteSelector == "" && !_flagDeleteAll {
+			exit.Error(ErrorDeleteAPINameRequired())
+		}
+
 		envName, err := getEnvFromFlag(_flagDeleteEnv)
 		if err != nil {
 			telemetry.Event("cli.delete")
@@ -68,6 +94,16 @@ var _deleteCmd = &cobra.Command{
 			exit.Error(err)
 		}
 
+		if _flagDeleteSelector != "" || _flagDeleteAll {
+			operatorConfig := MustGetOperatorConfig(env.Name)
+			apisRes, err := getSelectedAPIs(operatorConfig, _flagDeleteSelector, "")
+			if err != nil {
+				exit.Error(err)
+			}
+			deleteAPIs(operatorConfig, apisRes, noAPIsDeployedMessage(_flagDeleteSelector, ""), _flagDeleteKeepCache, _flagDeleteForce, _flagDeleteDryRun)
+			return
+		}
+
 		var deleteResponse schema.DeleteResponse
 		if len(args) == 2 {
 			apisRes, err := cluster.GetAPI(MustGetOperatorConfig(env.Name), args[0])
@@ -75,7 +111,11 @@ var _deleteCmd = &cobra.Command{
 				exit.Error(err)
 			}
 
-			deleteResponse, err = cluster.StopJob(MustGetOperatorConfig(env.Name), apisRes[0].Spec.Kind, args[0], args[1])
+			if apisRes[0].Spec.Kind == userconfig.AsyncAPIKind {
+				deleteResponse, err = cluster.CancelAsyncWorkload(MustGetOperatorConfig(env.Name), args[0], args[1])
+			} else {
+				deleteResponse, err = cluster.StopJob(MustGetOperatorConfig(env.Name), apisRes[0].Spec.Kind, args[0], args[1])
+			}
 			if err != nil {
 				exit.Error(err)
 			}
@@ -98,3 +138,90 @@ var _deleteCmd = &cobra.Command{
 		print.BoldFirstLine(deleteResponse.Message)
 	},
 }
+
+// deleteAPIs deletes the apis, after confirming the list of apis with the user (unless force is set); if dryRun is set, the apis are only listed
+func deleteAPIs(operatorConfig cluster.OperatorConfig, apis []schema.APIResponse, noAPIsMessage string, keepCache bool, force bool, dryRun bool) {
+	apiNames := make([]string, len(apis))
+	for i := range apis {
+		apiNames[i] = apis[i].Spec.Name
+	}
+
+	if dryRun {
+		if _flagOutput == flags.JSONOutputType {
+			bytes, err := libjson.Marshal(apiNames)
+			if err != nil {
+				exit.Error(err)
+			}
+			fmt.Print(string(bytes))
+			return
+		}
+		if len(apis) == 0 {
+			print.BoldFirstLine(noAPIsMessage)
+			return
+		}
+		fmt.Println(console.Bold(fmt.Sprintf("the following %s would be deleted (dry run):", s.PluralS("api", len(apis)))) + "\n")
+		t := apisToDeleteTable(apis)
+		fmt.Print(t.MustFormat())
+		return
+	}
+
+	deleteResponses := []schema.DeleteResponse{}
+	printDeleteResponses := func() {
+		if _flagOutput == flags.JSONOutputType {
+			bytes, err := libjson.Marshal(deleteResponses)
+			if err != nil {
+				exit.Error(err)
+			}
+			fmt.Print(string(bytes))
+			return
+		}
+		for _, deleteResponse := range deleteResponses {
+			print.BoldFirstLine(deleteResponse.Message)
+		}
+	}
+
+	if len(apis) == 0 {
+		if _flagOutput == flags.JSONOutputType {
+			printDeleteResponses()
+		} else {
+			print.BoldFirstLine(noAPIsMessage)
+		}
+		return
+	}
+
+	if !force {
+		t := apisToDeleteTable(apis)
+		fmt.Print(t.MustFormat() + "\n")
+		prompt.YesOrExit(fmt.Sprintf("are you sure you want to delete %s?", s.PluralCustom("this api", fmt.Sprintf("these %d apis", len(apis)), len(apis))), "", "")
+	}
+
+	for _, apiName := range apiNames {
+		// the deletion was already confirmed, so the per-api prompt is skipped
+		deleteResponse, err := cluster.Delete(operatorConfig, apiName, keepCache, true)
+		if err != nil {
+			printDeleteResponses()
+			exit.Error(errors.Wrap(err, apiName))
+		}
+		deleteResponses = append(deleteResponses, deleteResponse)
+	}
+
+	printDeleteResponses()
+}
+
+func apisToDeleteTable(apis []schema.APIResponse) table.Table {
+	t := table.Table{
+		Headers: []table.Header{
+			{Title: "api"},
+			{Title: "kind"},
+			{Title: _titleLastupdated},
+		},
+	}
+
+	t.Rows = make([][]interface{}, len(apis))
+	for i, api := range apis {
+		lastUpdated := time.Unix(api.Spec.LastUpdated, 0)
+		t.Rows[i] = []interface{}{api.Spec.Name, api.Spec.Kind.String(), libtime.SinceStr(&lastUpdated)}
+	}
+
+	return t
+}
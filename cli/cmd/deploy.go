@@ -22,15 +22,24 @@ import (
 
 	"github.com/cortexlabs/cortex/cli/cluster"
 	"github.com/cortexlabs/cortex/cli/types/flags"
+	"github.com/cortexlabs/cortex/pkg/lib/console"
 	"github.com/cortexlabs/cortex/pkg/lib/exit"
 	"github.com/cortexlabs/cortex/pkg/lib/files"
 	libjson "github.com/cortexlabs/cortex/pkg/lib/json"
+	"github.com/cortexlabs/cortex/pkg/lib/pointer"
 	"github.com/cortexlabs/cortex/pkg/lib/print"
+	"github.com/cortexlabs/cortex/pkg/lib/prompt"
+	s "github.com/cortexlabs/cortex/pkg/lib/strings"
+	"github.com/cortexlabs/cortex/pkg/lib/table"
 	"github.com/cortexlabs/cortex/pkg/lib/telemetry"
 	"github.com/cortexlabs/cortex/pkg/operator/schema"
+	"github.com/cortexlabs/cortex/pkg/types/spec"
 	"github.com/spf13/cobra"
 )
 
+// the status code of `cortex deploy --diff-only` when there are changes (like `terraform plan -detailed-exitcode`), so that it can be told apart from errors
+const _diffOnlyChangesExitCode = 2
+
 var (
 	_warningFileBytes    = 1024 * 1024 * 10
 	_warningProjectBytes = 1024 * 1024 * 10
@@ -42,6 +51,10 @@ var (
 	_flagDeployEnv            string
 	_flagDeployForce          bool
 	_flagDeployDisallowPrompt bool
+	_flagDeployDiff           bool
+	_flagDeployDiffOnly       bool
+	_flagDeployValues         []string
+	_flagDeployProject        bool
 )
 
 func deployInit() {
@@ -49,6 +62,10 @@ func deployInit() {
 	_deployCmd.Flags().StringVarP(&_flagDeployEnv, "env", "e", "", "environment to use")
 	_deployCmd.Flags().BoolVarP(&_flagDeployForce, "force", "f", false, "override the in-progress api update")
 	_deployCmd.Flags().BoolVarP(&_flagDeployDisallowPrompt, "yes", "y", false, "skip prompts")
+	_deployCmd.Flags().StringSliceVar(&_flagDeployValues, "values", nil, "values file(s) with variables and api overlays to apply to the config file (can be specified multiple times; later files take precedence)")
+	_deployCmd.Flags().BoolVar(&_flagDeployProject, "project", false, "if an api fails to be submitted, roll back the other apis in the config file (failures during rollout are not rolled back)")
+	_deployCmd.Flags().BoolVar(&_flagDeployDiff, "diff", false, "show the changes to the deployed apis before applying them")
+	_deployCmd.Flags().BoolVar(&_flagDeployDiffOnly, "diff-only", false, "show the changes to the deployed apis without applying them (exits with status code 2 if there are changes, 0 if there are no changes, and another non-zero code on errors)")
 	_deployCmd.Flags().VarP(&_flagOutput, "output", "o", fmt.Sprintf("output format: one of %s", strings.Join(flags.UserOutputTypeStrings(), "|")))
 }
 
@@ -85,12 +102,53 @@ var _deployCmd = &cobra.Command{
 			exit.Error(ErrorDeployFromTopLevelDir("root"))
 		}
 
-		deploymentBytes, err := getDeploymentBytes(configPath)
+		deploymentBytes, err := getDeploymentBytes(configPath, _flagDeployValues)
 		if err != nil {
 			exit.Error(err)
 		}
 
-		deployResults, err := cluster.Deploy(MustGetOperatorConfig(env.Name), configPath, deploymentBytes, _flagDeployForce)
+		if _flagDeployDiff || _flagDeployDiffOnly {
+			diffResults, err := cluster.DeployDiff(MustGetOperatorConfig(env.Name), configPath, deploymentBytes)
+			if err != nil {
+				exit.Error(err)
+			}
+
+			if _flagDeployDiffOnly {
+				switch _flagOutput {
+				case flags.JSONOutputType:
+					bytes, err := libjson.Marshal(diffResults)
+					if err != nil {
+						exit.Error(err)
+					}
+					fmt.Print(string(bytes))
+				case flags.PrettyOutputType:
+					fmt.Print(deployDiffStr(diffResults))
+				}
+
+				if didAnyDiffResultsError(diffResults) {
+					exit.Error(nil)
+				}
+				if didAnyDiffResultsChange(diffResults) {
+					exit.Code(_diffOnlyChangesExitCode)
+				}
+				exit.Ok()
+			}
+
+			// the diff is not printed when the output is json, so that only the deploy results are written to stdout
+			if _flagOutput == flags.PrettyOutputType {
+				fmt.Print(deployDiffStr(diffResults) + "\n")
+			}
+
+			if didAnyDiffResultsError(diffResults) {
+				exit.Error(nil)
+			}
+
+			if didAnyDiffResultsChange(diffResults) && !_flagDeployDisallowPrompt && _flagOutput == flags.PrettyOutputType {
+				prompt.YesOrExit("would you like to apply these changes?", "", "")
+			}
+		}
+
+		deployResults, err := cluster.Deploy(MustGetOperatorConfig(env.Name), configPath, deploymentBytes, _flagDeployForce, _flagDeployProject)
 		if err != nil {
 			exit.Error(err)
 		}
@@ -136,12 +194,31 @@ func getConfigPath(args []string) string {
 	return files.RelToAbsPath(configPath, _cwd)
 }
 
-func getDeploymentBytes(configPath string) (map[string][]byte, error) {
+func getDeploymentBytes(configPath string, valuesPaths []string) (map[string][]byte, error) {
 	configBytes, err := files.ReadFileBytes(configPath)
 	if err != nil {
 		return nil, err
 	}
 
+	var valuesList []*spec.Values
+	for _, valuesPath := range valuesPaths {
+		valuesPath = files.RelToAbsPath(valuesPath, _cwd)
+		valuesBytes, err := files.ReadFileBytes(valuesPath)
+		if err != nil {
+			return nil, err
+		}
+		values, err := spec.ReadValuesBytes(valuesBytes, files.PathRelativeToCWD(valuesPath))
+		if err != nil {
+			return nil, err
+		}
+		valuesList = append(valuesList, values)
+	}
+
+	configBytes, err = spec.ApplyValues(configBytes, files.PathRelativeToCWD(configPath), valuesList...)
+	if err != nil {
+		return nil, err
+	}
+
 	uploadBytes := map[string][]byte{
 		"config": configBytes,
 	}
@@ -152,6 +229,7 @@ func getDeploymentBytes(configPath string) (map[string][]byte, error) {
 func mergeResultMessages(results []schema.DeployResult) string {
 	var okMessages []string
 	var errMessages []string
+	var warnings []string
 
 	for _, result := range results {
 		if result.Error != "" {
@@ -159,12 +237,18 @@ func mergeResultMessages(results []schema.DeployResult) string {
 		} else {
 			okMessages = append(okMessages, result.Message)
 		}
+		for _, warning := range result.Warnings {
+			warnings = append(warnings, "warning: "+warning)
+		}
 	}
 
 	output := ""
 
 	if len(okMessages) > 0 {
 		output += strings.Join(okMessages, "\n")
+		if len(warnings) > 0 {
+			output += "\n\n" + strings.Join(warnings, "\n")
+		}
 		if len(errMessages) > 0 {
 			output += "\n\n"
 		}
@@ -177,6 +261,67 @@ func mergeResultMessages(results []schema.DeployResult) string {
 	return output
 }
 
+func deployDiffStr(results []schema.DeployDiffResult) string {
+	var items []string
+
+	for _, result := range results {
+		title := console.Bold(result.Resource.UserString())
+
+		if result.Error != "" {
+			items = append(items, title+": error\n"+result.Error+"\n")
+			continue
+		}
+		if result.IsNew {
+			items = append(items, title+": will be created\n")
+			continue
+		}
+		if len(result.Diffs) == 0 {
+			items = append(items, title+": no changes\n")
+			continue
+		}
+
+		t := table.Table{
+			Headers: []table.Header{
+				{Title: "field"},
+				{Title: "deployed", MaxWidth: 60},
+				{Title: "submitted", MaxWidth: 60},
+			},
+		}
+		for _, diff := range result.Diffs {
+			t.Rows = append(t.Rows, []interface{}{diff.Field, diffValueStr(diff.Old), diffValueStr(diff.New)})
+		}
+
+		items = append(items, fmt.Sprintf("%s: %d %s\n\n%s", title, len(result.Diffs), s.PluralS("change", len(result.Diffs)), t.MustFormat(&table.Opts{Sort: pointer.Bool(false)})))
+	}
+
+	return strings.Join(items, "\n")
+}
+
+func diffValueStr(val *string) string {
+	if val == nil {
+		return "<not set>"
+	}
+	return *val
+}
+
+func didAnyDiffResultsChange(results []schema.DeployDiffResult) bool {
+	for _, result := range results {
+		if result.IsNew || len(result.Diffs) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func didAnyDiffResultsError(results []schema.DeployDiffResult) bool {
+	for _, result := range results {
+		if result.Error != "" {
+			return true
+		}
+	}
+	return false
+}
+
 func didAllResultsError(results []schema.DeployResult) bool {
 	for _, result := range results {
 		if result.Error == "" {
@@ -0,0 +1,296 @@
+/*
+Copyright 2021 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	awssdk "github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/cortexlabs/cortex/cli/types/cliconfig"
+	"github.com/cortexlabs/cortex/pkg/lib/archive"
+	"github.com/cortexlabs/cortex/pkg/lib/aws"
+	"github.com/cortexlabs/cortex/pkg/lib/console"
+	"github.com/cortexlabs/cortex/pkg/lib/docker"
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/lib/exit"
+	"github.com/cortexlabs/cortex/pkg/lib/files"
+	"github.com/cortexlabs/cortex/pkg/lib/prompt"
+	"github.com/cortexlabs/cortex/pkg/lib/slices"
+	"github.com/cortexlabs/cortex/pkg/lib/telemetry"
+	"github.com/cortexlabs/cortex/pkg/types/clusterconfig"
+	"github.com/docker/docker/api/types/container"
+	"github.com/spf13/cobra"
+)
+
+const (
+	_devClusterName     = "cortex-dev"
+	_devClusterRegion   = "us-east-1"
+	_devEnvName         = "dev"
+	_devOperatorPort    = 8888
+	_devAPIsPort        = 8889
+	_devAccessKeyID     = "cortex-dev"
+	_devSecretAccessKey = "cortex-dev-secret"
+)
+
+// the node is labeled like the nodes of the "dev" node group in an eks cluster
+var _devKindConfig = fmt.Sprintf(`kind: Cluster
+apiVersion: kind.x-k8s.io/v1alpha4
+nodes:
+  - role: control-plane
+    kubeadmConfigPatches:
+      - |
+        kind: InitConfiguration
+        nodeRegistration:
+          kubeletExtraArgs:
+            node-labels: "workload=true,alpha.eksctl.io/nodegroup-name=dev"
+    extraPortMappings:
+      - containerPort: 30443
+        hostPort: %d
+      - containerPort: 30080
+        hostPort: %d
+`, _devOperatorPort, _devAPIsPort)
+
+var _devClusterConfig = fmt.Sprintf(`cluster_name: %s
+region: %s
+node_groups:
+  - name: dev
+    instance_type: m5.xlarge
+    min_instances: 1
+    max_instances: 1
+`, _devClusterName, _devClusterRegion)
+
+func devInit() {
+	_devUpCmd.Flags().SortFlags = false
+	_devUpCmd.Flags().BoolVarP(&_flagClusterDisallowPrompt, "yes", "y", false, "skip prompts")
+	_devCmd.AddCommand(_devUpCmd)
+
+	_devDownCmd.Flags().SortFlags = false
+	_devDownCmd.Flags().BoolVarP(&_flagClusterDisallowPrompt, "yes", "y", false, "skip prompts")
+	_devCmd.AddCommand(_devDownCmd)
+}
+
+var _devCmd = &cobra.Command{
+	Use:   "dev",
+	Short: "manage a local development cluster (contains subcommands)",
+}
+
+var _devUpCmd = &cobra.Command{
+	Use:   "up",
+	Short: "spin up a local development cluster with kind",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		telemetry.Event("cli.dev.up")
+
+		if _, err := docker.GetDockerClient(); err != nil {
+			exit.Error(err)
+		}
+
+		if _, err := exec.LookPath("kind"); err != nil {
+			exit.Error(ErrorKindNotInstalled())
+		}
+
+		envExists, err := isEnvConfigured(_devEnvName)
+		if err != nil {
+			exit.Error(err)
+		}
+		if envExists && !_flagClusterDisallowPrompt {
+			prompt.YesOrExit(fmt.Sprintf("found an existing environment named \"%s\"; would you like to overwrite it to connect to the local development cluster?", _devEnvName), "", "")
+		}
+
+		devDir := filepath.Join(_localDir, "dev")
+		if err := files.CreateDir(devDir); err != nil {
+			exit.Error(err)
+		}
+
+		clusterExists, err := doesDevClusterExist()
+		if err != nil {
+			exit.Error(err)
+		}
+
+		if !clusterExists {
+			kindConfigPath := filepath.Join(devDir, "kind.yaml")
+			if err := files.WriteFile([]byte(_devKindConfig), kindConfigPath); err != nil {
+				exit.Error(err)
+			}
+
+			fmt.Print("￮ creating the kind cluster\n\n")
+			if err := runKind("create", "cluster", "--name", _devClusterName, "--config", kindConfigPath); err != nil {
+				exit.Error(err)
+			}
+			fmt.Println()
+		}
+
+		// the internal kubeconfig points to the control plane's address on the "kind" docker network, which the manager container joins
+		kubeconfig, err := exec.Command("kind", "get", "kubeconfig", "--internal", "--name", _devClusterName).Output()
+		if err != nil {
+			exit.Error(errors.Wrap(err, "kind get kubeconfig"))
+		}
+		kubeconfigPath := filepath.Join(devDir, "kubeconfig")
+		if err := files.WriteFile(kubeconfig, kubeconfigPath); err != nil {
+			exit.Error(err)
+		}
+
+		clusterConfig, err := getDevClusterConfig(devDir)
+		if err != nil {
+			exit.Error(err)
+		}
+
+		awsClient, err := newDevAWSClient()
+		if err != nil {
+			exit.Error(err)
+		}
+
+		copyToPaths := []dockerCopyToPath{
+			{
+				input: &archive.Input{
+					Files: []archive.FileInput{
+						{
+							Source: kubeconfigPath,
+							Dest:   _containerKubeconfigPath,
+						},
+					},
+				},
+				containerPath: "/",
+			},
+		}
+
+		out, exitCode, err := runManagerWithClusterConfig("/root/install.sh --dev", clusterConfig, awsClient, &container.HostConfig{NetworkMode: "kind"}, copyToPaths, nil, []string{
+			"KUBECONFIG=" + _containerKubeconfigPath,
+			"CORTEX_DEV_ACCESS_KEY_ID=" + _devAccessKeyID,
+			"CORTEX_DEV_SECRET_ACCESS_KEY=" + _devSecretAccessKey,
+		})
+		if err != nil {
+			exit.Error(err)
+		}
+		if exitCode == nil || *exitCode != 0 {
+			exit.Error(ErrorDevClusterUp(out))
+		}
+
+		err = addEnvToCLIConfig(cliconfig.Environment{
+			Name:             _devEnvName,
+			OperatorEndpoint: fmt.Sprintf("https://localhost:%d", _devOperatorPort),
+		}, true)
+		if err != nil {
+			exit.Error(err)
+		}
+
+		fmt.Printf("\noperator: https://localhost:%d\n", _devOperatorPort)
+		fmt.Printf("apis:     http://localhost:%d\n", _devAPIsPort)
+		fmt.Printf(console.Bold("\nan environment named \"%s\" has been configured to point to the local development cluster (and was set as the default environment)\n"), _devEnvName)
+	},
+}
+
+var _devDownCmd = &cobra.Command{
+	Use:   "down",
+	Short: "delete the local development cluster",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		telemetry.Event("cli.dev.down")
+
+		if _, err := exec.LookPath("kind"); err != nil {
+			exit.Error(ErrorKindNotInstalled())
+		}
+
+		clusterExists, err := doesDevClusterExist()
+		if err != nil {
+			exit.Error(err)
+		}
+		if !clusterExists {
+			exit.Error(ErrorDevClusterNotFound())
+		}
+
+		if !_flagClusterDisallowPrompt {
+			prompt.YesOrExit("your local development cluster (including all of its apis and the contents of its minio bucket) will be deleted; would you like to continue?", "", "")
+		}
+
+		if err := runKind("delete", "cluster", "--name", _devClusterName); err != nil {
+			exit.Error(err)
+		}
+
+		envExists, err := isEnvConfigured(_devEnvName)
+		if err != nil {
+			exit.Error(err)
+		}
+		if envExists {
+			if err := removeEnvFromCLIConfig(_devEnvName); err != nil {
+				exit.Error(err)
+			}
+		}
+
+		fmt.Println("\n✓ the local development cluster has been deleted")
+	},
+}
+
+func doesDevClusterExist() (bool, error) {
+	out, err := exec.Command("kind", "get", "clusters").Output()
+	if err != nil {
+		return false, errors.WithStack(err)
+	}
+	return slices.HasString(strings.Fields(string(out)), _devClusterName), nil
+}
+
+func runKind(args ...string) error {
+	cmd := exec.Command("kind", args...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return errors.Wrap(err, "kind "+strings.Join(args, " "))
+	}
+	return nil
+}
+
+func getDevClusterConfig(devDir string) (*clusterconfig.Config, error) {
+	clusterConfigPath := filepath.Join(devDir, "cluster.yaml")
+	if err := files.WriteFile([]byte(_devClusterConfig), clusterConfigPath); err != nil {
+		return nil, err
+	}
+
+	clusterConfig := &clusterconfig.Config{}
+	if err := readUserClusterConfigFile(clusterConfig, clusterConfigPath); err != nil {
+		return nil, err
+	}
+
+	// these are normally set during validation, which requires aws
+	clusterConfig.Bucket = _devClusterName
+	clusterConfig.ClusterUID = "dev"
+
+	var err error
+	clusterConfig.Telemetry, err = readTelemetryConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	return clusterConfig, nil
+}
+
+// the manager authenticates to minio with these credentials (they are not aws credentials)
+func newDevAWSClient() (*aws.Client, error) {
+	sess, err := session.NewSession(&awssdk.Config{
+		Credentials: credentials.NewStaticCredentials(_devAccessKeyID, _devSecretAccessKey, ""),
+		Region:      awssdk.String(_devClusterRegion),
+	})
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return aws.NewForSession(sess)
+}
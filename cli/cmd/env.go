@@ -31,11 +31,19 @@ import (
 
 var (
 	_flagEnvOperatorEndpoint string
+	_flagEnvOperatorToken    string
+	_flagEnvAuth             string
+	_flagEnvAWSProfile       string
+	_flagEnvAWSRoleARN       string
 )
 
 func envInit() {
 	_envConfigureCmd.Flags().SortFlags = false
 	_envConfigureCmd.Flags().StringVarP(&_flagEnvOperatorEndpoint, "operator-endpoint", "o", "", "set the operator endpoint without prompting")
+	_envConfigureCmd.Flags().StringVar(&_flagEnvAuth, "auth", "", fmt.Sprintf("how to authenticate with the operator: one of %s (oidc logs in with the cluster's OIDC provider)", strings.Join(cliconfig.AuthTypes, "|")))
+	_envConfigureCmd.Flags().StringVar(&_flagEnvOperatorToken, "operator-token", "", "authenticate with an operator token (created by a cluster admin with `cortex auth token create`) instead of AWS credentials")
+	_envConfigureCmd.Flags().StringVar(&_flagEnvAWSProfile, "aws-profile", "", "use this AWS profile (from your AWS config/credentials files) for this environment instead of the default AWS credentials")
+	_envConfigureCmd.Flags().StringVar(&_flagEnvAWSRoleARN, "aws-role-arn", "", "assume this AWS role (with the profile's or the default AWS credentials) for this environment")
 	_envCmd.AddCommand(_envConfigureCmd)
 
 	_envListCmd.Flags().SortFlags = false
@@ -58,9 +66,10 @@ var _envCmd = &cobra.Command{
 }
 
 var _envConfigureCmd = &cobra.Command{
-	Use:   "configure [ENVIRONMENT_NAME]",
-	Short: "configure an environment",
-	Args:  cobra.RangeArgs(0, 1),
+	Use:               "configure [ENVIRONMENT_NAME]",
+	Short:             "configure an environment",
+	Args:              cobra.RangeArgs(0, 1),
+	ValidArgsFunction: completeEnvNameArg,
 	Run: func(cmd *cobra.Command, args []string) {
 		telemetry.Event("cli.env.configure")
 
@@ -77,6 +86,10 @@ var _envConfigureCmd = &cobra.Command{
 			}
 			fieldsToSkipPrompt.OperatorEndpoint = operatorEndpoint
 		}
+		fieldsToSkipPrompt.OperatorToken = _flagEnvOperatorToken
+		fieldsToSkipPrompt.Auth = _flagEnvAuth
+		fieldsToSkipPrompt.AWSProfile = _flagEnvAWSProfile
+		fieldsToSkipPrompt.AWSRoleARN = _flagEnvAWSRoleARN
 
 		if _, err := configureEnv(envName, fieldsToSkipPrompt); err != nil {
 			exit.Error(err)
@@ -125,9 +138,10 @@ var _envListCmd = &cobra.Command{
 }
 
 var _envDefaultCmd = &cobra.Command{
-	Use:   "default [ENVIRONMENT_NAME]",
-	Short: "set the default environment",
-	Args:  cobra.RangeArgs(0, 1),
+	Use:               "default [ENVIRONMENT_NAME]",
+	Short:             "set the default environment",
+	Args:              cobra.RangeArgs(0, 1),
+	ValidArgsFunction: completeEnvNameArg,
 	Run: func(cmd *cobra.Command, args []string) {
 		telemetry.Event("cli.env.default")
 
@@ -160,9 +174,10 @@ var _envDefaultCmd = &cobra.Command{
 }
 
 var _envRenameCmd = &cobra.Command{
-	Use:   "rename EXISTING_NAME NEW_NAME",
-	Short: "rename an environment",
-	Args:  cobra.ExactArgs(2),
+	Use:               "rename EXISTING_NAME NEW_NAME",
+	Short:             "rename an environment",
+	Args:              cobra.ExactArgs(2),
+	ValidArgsFunction: completeEnvNameArg,
 	Run: func(cmd *cobra.Command, args []string) {
 		telemetry.Event("cli.env.rename")
 
@@ -178,9 +193,10 @@ var _envRenameCmd = &cobra.Command{
 }
 
 var _envDeleteCmd = &cobra.Command{
-	Use:   "delete [ENVIRONMENT_NAME]",
-	Short: "delete an environment configuration",
-	Args:  cobra.RangeArgs(0, 1),
+	Use:               "delete [ENVIRONMENT_NAME]",
+	Short:             "delete an environment configuration",
+	Args:              cobra.RangeArgs(0, 1),
+	ValidArgsFunction: completeEnvNameArg,
 	Run: func(cmd *cobra.Command, args []string) {
 		telemetry.Event("cli.env.delete")
 
@@ -26,6 +26,8 @@ import (
 	s "github.com/cortexlabs/cortex/pkg/lib/strings"
 	"github.com/cortexlabs/cortex/pkg/lib/urls"
 	"github.com/cortexlabs/cortex/pkg/types/clusterconfig"
+	"github.com/cortexlabs/cortex/pkg/types/rbac"
+	"github.com/cortexlabs/cortex/pkg/types/userconfig"
 )
 
 const (
@@ -52,16 +54,26 @@ const (
 	ErrMissingAWSCredentials               = "cli.missing_aws_credentials"
 	ErrCredentialsInClusterConfig          = "cli.credentials_in_cluster_config"
 	ErrClusterUp                           = "cli.cluster_up"
+	ErrClusterInstall                      = "cli.cluster_install"
+	ErrKindNotInstalled                    = "cli.kind_not_installed"
+	ErrDevClusterUp                        = "cli.dev_cluster_up"
+	ErrDevClusterNotFound                  = "cli.dev_cluster_not_found"
 	ErrClusterScale                        = "cli.cluster_scale"
 	ErrClusterDebug                        = "cli.cluster_debug"
 	ErrClusterRefresh                      = "cli.cluster_refresh"
 	ErrClusterDown                         = "cli.cluster_down"
+	ErrClusterGC                           = "cli.cluster_gc"
+	ErrClusterValidate                     = "cli.cluster_validate"
+	ErrSnapshotNotFound                    = "cli.snapshot_not_found"
 	ErrSpecifyAtLeastOneFlag               = "cli.specify_at_least_one_flag"
 	ErrMinInstancesLowerThan               = "cli.min_instances_lower_than"
 	ErrMaxInstancesLowerThan               = "cli.max_instances_lower_than"
 	ErrMinInstancesGreaterThanMaxInstances = "cli.min_instances_greater_than_max_instances"
 	ErrNodeGroupNotFound                   = "cli.nodegroup_not_found"
 	ErrJSONOutputNotSupportedWithFlag      = "cli.json_output_not_supported_with_flag"
+	ErrFlagRequiresDebug                   = "cli.flag_requires_debug"
+	ErrMutuallyExclusiveFlags              = "cli.mutually_exclusive_flags"
+	ErrInvalidDebugCollector               = "cli.invalid_debug_collector"
 	ErrClusterAccessConfigRequired         = "cli.cluster_access_config_or_prompts_required"
 	ErrShellCompletionNotSupported         = "cli.shell_completion_not_supported"
 	ErrNoTerminalWidth                     = "cli.no_terminal_width"
@@ -69,6 +81,36 @@ const (
 	ErrAPINameMustBeProvided               = "cli.api_name_must_be_provided"
 	ErrAPINotFoundInConfig                 = "cli.api_not_found_in_config"
 	ErrClusterUIDsLimitInBucket            = "cli.cluster_uids_limit_in_bucket"
+	ErrInvalidRole                         = "cli.invalid_role"
+	ErrDockerNotInstalled                  = "cli.docker_not_installed"
+	ErrBuildEntrypointRequired             = "cli.build_entrypoint_required"
+	ErrBuildEntrypointWithDockerfile       = "cli.build_entrypoint_with_dockerfile"
+	ErrBuildEntrypointNotInContext         = "cli.build_entrypoint_not_in_context"
+	ErrBuildAPIHasNoContainers             = "cli.build_api_has_no_containers"
+	ErrBuildContainerNameMustBeProvided    = "cli.build_container_name_must_be_provided"
+	ErrBuildContainerNotFound              = "cli.build_container_not_found"
+	ErrBuildImageNotFoundInConfig          = "cli.build_image_not_found_in_config"
+	ErrBuildImageNotUniqueInConfig         = "cli.build_image_not_unique_in_config"
+	ErrInvalidRevision                     = "cli.invalid_revision"
+	ErrHistoryRequiresAPIName              = "cli.history_requires_api_name"
+	ErrRecommendationsRequireAPIName       = "cli.recommendations_require_api_name"
+	ErrAPIIsNotJobAPI                      = "cli.api_is_not_job_api"
+	ErrAPIIsNotBatchAPI                    = "cli.api_is_not_batch_api"
+	ErrInvalidSelector                     = "cli.invalid_selector"
+	ErrBulkDeleteWithAPIName               = "cli.bulk_delete_with_api_name"
+	ErrDeleteAPINameRequired               = "cli.delete_api_name_required"
+	ErrInvalidOlderThan                    = "cli.invalid_older_than"
+	ErrDashboardRequiresTerminal           = "cli.dashboard_requires_terminal"
+	ErrInvalidManagerMode                  = "cli.invalid_manager_mode"
+	ErrManagerScriptsNotFound              = "cli.manager_scripts_not_found"
+	ErrRemoteManagerBucketNotFound         = "cli.remote_manager_bucket_not_found"
+	ErrRemoteManagerBuildFailed            = "cli.remote_manager_build_failed"
+	ErrInvalidPortMapping                  = "cli.invalid_port_mapping"
+	ErrSessionManagerPluginNotInstalled    = "cli.session_manager_plugin_not_installed"
+	ErrInstanceNotInCluster                = "cli.instance_not_in_cluster"
+	ErrInstanceNotManagedBySSM             = "cli.instance_not_managed_by_ssm"
+	ErrALBNotSupportedOnInstall            = "cli.alb_not_supported_on_install"
+	ErrNATElasticIPsNotSupportedOnInstall  = "cli.nat_elastic_ips_not_supported_on_install"
 )
 
 func ErrorInvalidProvider(providerStr, cliConfigPath string) error {
@@ -162,6 +204,36 @@ func ErrorClusterUp(out string) error {
 	})
 }
 
+func ErrorClusterInstall(out string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrClusterInstall,
+		Message: out,
+		NoPrint: true,
+	})
+}
+
+func ErrorKindNotInstalled() error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrKindNotInstalled,
+		Message: "kind must be installed to run a local development cluster (see https://kind.sigs.k8s.io/docs/user/quick-start/#installation)",
+	})
+}
+
+func ErrorDevClusterUp(out string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrDevClusterUp,
+		Message: out,
+		NoPrint: true,
+	})
+}
+
+func ErrorDevClusterNotFound() error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrDevClusterNotFound,
+		Message: "there is no local development cluster running; you can create one with `cortex dev up`",
+	})
+}
+
 func ErrorClusterScale(out string) error {
 	return errors.WithStack(&errors.Error{
 		Kind:    ErrClusterScale,
@@ -194,6 +266,21 @@ func ErrorClusterDown(out string) error {
 	})
 }
 
+func ErrorClusterValidate(numFailed int) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrClusterValidate,
+		Message: fmt.Sprintf("%s failed", s.PluralCustom("1 check", fmt.Sprintf("%d checks", numFailed), numFailed)),
+		NoPrint: true,
+	})
+}
+
+func ErrorSnapshotNotFound(snapshotID string, s3Path string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrSnapshotNotFound,
+		Message: fmt.Sprintf("there is no snapshot with id \"%s\" in %s; run `cortex cluster snapshot list` to list the available snapshots", snapshotID, s3Path),
+	})
+}
+
 func ErrorSpecifyAtLeastOneFlag(flagsToSpecify ...string) error {
 	return errors.WithStack(&errors.Error{
 		Kind:    ErrSpecifyAtLeastOneFlag,
@@ -236,6 +323,27 @@ func ErrorJSONOutputNotSupportedWithFlag(flag string) error {
 	})
 }
 
+func ErrorFlagRequiresDebug(flag string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrFlagRequiresDebug,
+		Message: fmt.Sprintf("flag %s can only be used with the --debug flag", flag),
+	})
+}
+
+func ErrorMutuallyExclusiveFlags(flag1 string, flag2 string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrMutuallyExclusiveFlags,
+		Message: fmt.Sprintf("flags %s and %s cannot be used together", flag1, flag2),
+	})
+}
+
+func ErrorInvalidDebugCollector(collector string, validCollectors []string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrInvalidDebugCollector,
+		Message: fmt.Sprintf("invalid debug collector \"%s\"; valid collectors are %s", collector, s.UserStrsOr(validCollectors)),
+	})
+}
+
 func ErrorClusterAccessConfigRequired(cliFlagsOnly bool) error {
 	message := ""
 	if cliFlagsOnly {
@@ -290,3 +398,221 @@ func ErrorClusterUIDsLimitInBucket(bucket string) error {
 		Message: fmt.Sprintf("detected too many top level folders in %s bucket; please empty your bucket and try again", bucket),
 	})
 }
+
+func ErrorInvalidRole(role string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrInvalidRole,
+		Message: fmt.Sprintf("%s is not a valid role; valid roles are %s", s.UserStr(role), s.UserStrsOr(rbac.RoleStrings())),
+	})
+}
+
+func ErrorDockerNotInstalled() error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrDockerNotInstalled,
+		Message: "the docker cli must be installed to build images (see https://docs.docker.com/get-docker)",
+	})
+}
+
+func ErrorBuildEntrypointRequired(contextDir string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrBuildEntrypointRequired,
+		Message: fmt.Sprintf("%s does not contain a Dockerfile; please specify the python file which starts your server with the --entrypoint flag", contextDir),
+	})
+}
+
+func ErrorBuildEntrypointWithDockerfile(dockerfilePath string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrBuildEntrypointWithDockerfile,
+		Message: fmt.Sprintf("the --entrypoint flag cannot be used when the build context contains a Dockerfile (%s); either set the entrypoint in the Dockerfile, or remove the Dockerfile", dockerfilePath),
+	})
+}
+
+func ErrorBuildEntrypointNotInContext(entrypoint string, contextDir string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrBuildEntrypointNotInContext,
+		Message: fmt.Sprintf("the entrypoint (%s) must be in the build context (%s)", entrypoint, contextDir),
+	})
+}
+
+func ErrorBuildAPIHasNoContainers(apiName string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrBuildAPIHasNoContainers,
+		Message: fmt.Sprintf("api %s does not have any containers", apiName),
+	})
+}
+
+func ErrorBuildContainerNameMustBeProvided(apiName string, containerNames []string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrBuildContainerNameMustBeProvided,
+		Message: fmt.Sprintf("api %s has multiple containers (%s); please specify which container's image to update with the --container flag", apiName, s.StrsAnd(containerNames)),
+	})
+}
+
+func ErrorBuildContainerNotFound(containerName string, apiName string, containerNames []string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrBuildContainerNotFound,
+		Message: fmt.Sprintf("container %s was not found in api %s (its containers are %s)", s.UserStr(containerName), apiName, s.StrsAnd(containerNames)),
+	})
+}
+
+func ErrorBuildImageNotFoundInConfig(image string, configPath string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrBuildImageNotFoundInConfig,
+		Message: fmt.Sprintf("unable to find the line which sets the image to %s in %s; please update the image manually", image, configPath),
+	})
+}
+
+func ErrorBuildImageNotUniqueInConfig(image string, configPath string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrBuildImageNotUniqueInConfig,
+		Message: fmt.Sprintf("the image %s is used by multiple containers in %s, so it is ambiguous which one to update; please update the image manually", image, configPath),
+	})
+}
+
+func ErrorInvalidRevision(revision int) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrInvalidRevision,
+		Message: fmt.Sprintf("invalid revision %d; revisions are positive integers (run `cortex get API_NAME --history` to see an api's revisions)", revision),
+	})
+}
+
+func ErrorHistoryRequiresAPIName() error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrHistoryRequiresAPIName,
+		Message: "the --history flag can only be used when getting a single api (e.g. `cortex get API_NAME --history`)",
+	})
+}
+
+func ErrorRecommendationsRequireAPIName() error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrRecommendationsRequireAPIName,
+		Message: "the --recommendations flag can only be used when getting a single api (e.g. `cortex get API_NAME --recommendations`)",
+	})
+}
+
+func ErrorAPIIsNotJobAPI(apiName string, kind userconfig.Kind) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrAPIIsNotJobAPI,
+		Message: fmt.Sprintf("%s is a %s, but jobs can only be managed for a %s or %s", apiName, kind.String(), userconfig.BatchAPIKind.String(), userconfig.TaskAPIKind.String()),
+	})
+}
+
+func ErrorAPIIsNotBatchAPI(apiName string, kind userconfig.Kind) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrAPIIsNotBatchAPI,
+		Message: fmt.Sprintf("%s is a %s, but job results are only available for a %s", apiName, kind.String(), userconfig.BatchAPIKind.String()),
+	})
+}
+
+func ErrorInvalidSelector(selector string, err error) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrInvalidSelector,
+		Message: fmt.Sprintf("invalid label selector %s (e.g. team=nlp,stage!=dev): %s", s.UserStr(selector), errors.Message(err)),
+	})
+}
+
+func ErrorBulkDeleteWithAPIName(flag string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrBulkDeleteWithAPIName,
+		Message: fmt.Sprintf("an api name and the %s flag can't both be specified (`cortex delete API_NAME` deletes a single api, and `cortex delete %s` deletes multiple apis)", flag, flag),
+	})
+}
+
+func ErrorDeleteAPINameRequired() error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrDeleteAPINameRequired,
+		Message: "an api name, the --selector flag, or the --all flag must be specified (e.g. `cortex delete API_NAME`, `cortex delete --selector team=nlp`, or `cortex delete --all`)",
+	})
+}
+
+func ErrorInvalidOlderThan(olderThan string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrInvalidOlderThan,
+		Message: fmt.Sprintf("invalid value for --older-than (%s); expected a positive duration (e.g. 30d or 12h)", olderThan),
+	})
+}
+
+func ErrorDashboardRequiresTerminal() error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrDashboardRequiresTerminal,
+		Message: "`cortex dashboard` must be run in an interactive terminal",
+	})
+}
+
+func ErrorInvalidManagerMode(mode string, validModes []string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrInvalidManagerMode,
+		Message: fmt.Sprintf("invalid value for the %s environment variable (%s); valid values are %s", _managerModeEnvVar, s.UserStr(mode), s.UserStrsOr(validModes)),
+	})
+}
+
+func ErrorManagerScriptsNotFound(path string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrManagerScriptsNotFound,
+		Message: fmt.Sprintf("%s is set to %s, but the cluster manager's scripts were not found (%s does not exist); %s=%s is only supported when running inside of the manager image (%s)", _managerModeEnvVar, _managerModeLocal, path, _managerModeEnvVar, _managerModeLocal, consts.DefaultRegistry()+"/manager:"+consts.CortexVersion),
+	})
+}
+
+func ErrorRemoteManagerBucketNotFound(bucket string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrRemoteManagerBucketNotFound,
+		Message: fmt.Sprintf("unable to run the cluster manager remotely because the cluster's s3 bucket (%s) does not exist; please run this command without --run-remote", bucket),
+	})
+}
+
+func ErrorRemoteManagerBuildFailed(buildID string, status string, reason string, logsURL string) error {
+	msg := fmt.Sprintf("the codebuild build which runs the cluster manager (%s) did not complete successfully (status: %s)", buildID, strings.ToLower(status))
+	if reason != "" {
+		msg += ": " + reason
+	}
+	if logsURL != "" {
+		msg += fmt.Sprintf("\n\nthe build's logs can be found here: %s", logsURL)
+	}
+
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrRemoteManagerBuildFailed,
+		Message: msg,
+	})
+}
+
+func ErrorInvalidPortMapping(mapping string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrInvalidPortMapping,
+		Message: fmt.Sprintf("invalid port mapping \"%s\"; specify LOCAL_PORT:REMOTE_PORT (e.g. 8888:8080), or PORT to use the same port locally and remotely", mapping),
+	})
+}
+
+func ErrorSessionManagerPluginNotInstalled() error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrSessionManagerPluginNotInstalled,
+		Message: "the session manager plugin for the aws cli must be installed to open shells on the cluster's instances (see https://docs.aws.amazon.com/systems-manager/latest/userguide/session-manager-working-with-install-plugin.html)",
+	})
+}
+
+func ErrorInstanceNotInCluster(instanceID string, clusterName string, region string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrInstanceNotInCluster,
+		Message: fmt.Sprintf("instance %s is not one of the instances of your cluster named \"%s\" in %s", instanceID, clusterName, region),
+	})
+}
+
+func ErrorInstanceNotManagedBySSM(instanceID string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrInstanceNotManagedBySSM,
+		Message: fmt.Sprintf("instance %s is not registered with aws systems manager; sessions can only be started on the instances of clusters which were created with `ssm_access: true` in their cluster configuration (if it is set, the instance may still be starting)", instanceID),
+	})
+}
+
+func ErrorALBNotSupportedOnInstall() error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrALBNotSupportedOnInstall,
+		Message: fmt.Sprintf("`%s: %s` is not supported by `cortex cluster install`, since the application load balancer's targets are the cortex system node group which is created by `cortex cluster up`", clusterconfig.APILoadBalancerTypeKey, clusterconfig.ALBLoadBalancerType.String()),
+	})
+}
+
+func ErrorNATElasticIPsNotSupportedOnInstall() error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrNATElasticIPsNotSupportedOnInstall,
+		Message: fmt.Sprintf("%s is not supported by `cortex cluster install`, since the nat gateways of an existing cluster's vpc aren't managed by cortex", clusterconfig.NATGatewayElasticIPsKey),
+	})
+}
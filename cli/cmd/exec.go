@@ -0,0 +1,120 @@
+/*
+Copyright 2021 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"os"
+	"strings"
+	"time"
+
+	"github.com/cortexlabs/cortex/cli/cluster"
+	"github.com/cortexlabs/cortex/cli/lib/routines"
+	"github.com/cortexlabs/cortex/pkg/lib/exit"
+	"github.com/cortexlabs/cortex/pkg/lib/telemetry"
+	"github.com/spf13/cobra"
+)
+
+const _execTerminalSizeInterval = 500 * time.Millisecond
+
+var (
+	_flagExecEnv       string
+	_flagExecReplica   string
+	_flagExecContainer string
+	_flagExecTTY       bool
+)
+
+func execInit() {
+	_execCmd.Flags().SortFlags = false
+	_execCmd.Flags().StringVarP(&_flagExecEnv, "env", "e", "", "environment to use")
+	_execCmd.Flags().StringVar(&_flagExecReplica, "replica", "", "name of the replica to run the command in (defaults to the first running replica)")
+	_execCmd.Flags().StringVarP(&_flagExecContainer, "container", "c", "", "name of the container to run the command in (defaults to the api's first container)")
+	_execCmd.Flags().BoolVarP(&_flagExecTTY, "tty", "t", false, "allocate a terminal for the command (defaults to true if stdin is a terminal)")
+}
+
+var _execCmd = &cobra.Command{
+	Use:               "exec API_NAME [-- COMMAND [ARGS...]]",
+	Short:             "run a command in a replica of an api (defaults to sh)",
+	Args:              cobra.MinimumNArgs(1),
+	ValidArgsFunction: completeAPINameArg,
+	Run: func(cmd *cobra.Command, args []string) {
+		envName, err := getEnvFromFlag(_flagExecEnv)
+		if err != nil {
+			telemetry.Event("cli.exec")
+			exit.Error(err)
+		}
+
+		env, err := ReadOrConfigureEnv(envName)
+		if err != nil {
+			telemetry.Event("cli.exec")
+			exit.Error(err)
+		}
+		telemetry.Event("cli.exec", map[string]interface{}{"env_name": env.Name})
+
+		err = printEnvIfNotSpecified(env.Name, cmd)
+		if err != nil {
+			exit.Error(err)
+		}
+
+		apiName := args[0]
+		command := args[1:]
+		if len(command) == 0 {
+			command = []string{"sh"}
+		}
+
+		// stdin is a terminal if its state can be read
+		terminalState, err := stty("-g")
+		isTerminal := err == nil
+		tty := isTerminal
+		if cmd.Flags().Changed("tty") {
+			tty = _flagExecTTY
+		}
+
+		var sizes chan cluster.TerminalSize
+		if tty && isTerminal {
+			if _, err := stty("raw", "-echo"); err != nil {
+				exit.Error(err)
+			}
+			sizes = make(chan cluster.TerminalSize, 1)
+			routines.RunWithPanicHandler(func() {
+				watchTerminalSize(sizes)
+			}, false)
+		}
+
+		exitCode, err := cluster.Exec(MustGetOperatorConfig(env.Name), apiName, _flagExecReplica, _flagExecContainer, command, tty, os.Stdin, os.Stdout, os.Stderr, sizes)
+
+		if sizes != nil {
+			stty(strings.TrimSpace(terminalState))
+		}
+		if err != nil {
+			exit.Error(err)
+		}
+		exit.Code(exitCode)
+	},
+}
+
+// sends the terminal's size when the command starts, and each time that it changes
+func watchTerminalSize(sizes chan<- cluster.TerminalSize) {
+	var prevHeight, prevWidth int
+	for {
+		height, width := getTerminalSize()
+		if height > 0 && width > 0 && (height != prevHeight || width != prevWidth) {
+			sizes <- cluster.TerminalSize{Width: uint16(width), Height: uint16(height)}
+			prevHeight, prevWidth = height, width
+		}
+		time.Sleep(_execTerminalSizeInterval)
+	}
+}
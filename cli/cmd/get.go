@@ -18,6 +18,7 @@ package cmd
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 	"time"
 
@@ -28,6 +29,7 @@ import (
 	"github.com/cortexlabs/cortex/pkg/lib/errors"
 	"github.com/cortexlabs/cortex/pkg/lib/exit"
 	libjson "github.com/cortexlabs/cortex/pkg/lib/json"
+	"github.com/cortexlabs/cortex/pkg/lib/maps"
 	"github.com/cortexlabs/cortex/pkg/lib/pointer"
 	s "github.com/cortexlabs/cortex/pkg/lib/strings"
 	"github.com/cortexlabs/cortex/pkg/lib/table"
@@ -36,6 +38,7 @@ import (
 	"github.com/cortexlabs/cortex/pkg/operator/schema"
 	"github.com/cortexlabs/cortex/pkg/types/userconfig"
 	"github.com/spf13/cobra"
+	klabels "k8s.io/apimachinery/pkg/labels"
 )
 
 const (
@@ -51,25 +54,40 @@ const (
 	_title2XX         = "2XX"
 	_title4XX         = "4XX"
 	_title5XX         = "5XX"
+	_titleRevision    = "revision"
+	_titleDeployedBy  = "deployed by"
+	_titleNotes       = "notes"
+	_titleModels      = "model versions"
 )
 
 var (
-	_flagGetEnv string
-	_flagWatch  bool
+	_flagGetEnv      string
+	_flagWatch       bool
+	_flagGetHistory  bool
+	_flagGetRecs     bool
+	_flagGetWindow   string
+	_flagGetProject  string
+	_flagGetSelector string
 )
 
 func getInit() {
 	_getCmd.Flags().SortFlags = false
 	_getCmd.Flags().StringVarP(&_flagGetEnv, "env", "e", "", "environment to use")
 	_getCmd.Flags().BoolVarP(&_flagWatch, "watch", "w", false, "re-run the command every 2 seconds")
+	_getCmd.Flags().BoolVar(&_flagGetHistory, "history", false, "show the deployed revisions of an api (which can be redeployed with `cortex rollback`)")
+	_getCmd.Flags().BoolVar(&_flagGetRecs, "recommendations", false, "show right-sizing recommendations for the compute requests of an api's containers, based on their usage")
+	_getCmd.Flags().StringVar(&_flagGetWindow, "window", "7d", "the duration of usage on which --recommendations are based (up to 14d)")
+	_getCmd.Flags().StringVar(&_flagGetProject, "project", "", "only list the apis which belong to a project")
+	_getCmd.Flags().StringVarP(&_flagGetSelector, "selector", "l", "", "only list the apis whose labels match a label selector (e.g. team=nlp,stage!=dev)")
 	_getCmd.Flags().VarP(&_flagOutput, "output", "o", fmt.Sprintf("output format: one of %s", strings.Join(flags.UserOutputTypeStrings(), "|")))
 	addVerboseFlag(_getCmd)
 }
 
 var _getCmd = &cobra.Command{
-	Use:   "get [API_NAME] [JOB_ID]",
-	Short: "get information about apis or jobs",
-	Args:  cobra.RangeArgs(0, 2),
+	Use:               "get [API_NAME] [JOB_ID]",
+	Short:             "get information about apis or jobs",
+	Args:              cobra.RangeArgs(0, 2),
+	ValidArgsFunction: completeAPINameAndJobIDArgs,
 	Run: func(cmd *cobra.Command, args []string) {
 		var envName string
 		if wasFlagProvided(cmd, "env") {
@@ -94,6 +112,25 @@ var _getCmd = &cobra.Command{
 			telemetry.Event("cli.get")
 		}
 
+		if _flagGetHistory && len(args) != 1 {
+			exit.Error(ErrorHistoryRequiresAPIName())
+		}
+
+		if _flagGetRecs {
+			if len(args) != 1 {
+				exit.Error(ErrorRecommendationsRequireAPIName())
+			}
+			if _flagGetHistory {
+				exit.Error(ErrorMutuallyExclusiveFlags("--history", "--recommendations"))
+			}
+		}
+
+		if _flagGetSelector != "" {
+			if _, err := klabels.Parse(_flagGetSelector); err != nil {
+				exit.Error(ErrorInvalidSelector(_flagGetSelector, err))
+			}
+		}
+
 		rerun(func() (string, error) {
 			if len(args) == 1 {
 				env, err := ReadOrConfigureEnv(envName)
@@ -105,7 +142,15 @@ var _getCmd = &cobra.Command{
 				if err != nil {
 					return "", err
 				}
-				apiTable, err := getAPI(env, args[0])
+
+				var apiTable string
+				if _flagGetHistory {
+					apiTable, err = getAPIHistory(env, args[0])
+				} else if _flagGetRecs {
+					apiTable, err = getAPIRecommendations(env, args[0])
+				} else {
+					apiTable, err = getAPI(env, args[0])
+				}
 				if err != nil {
 					return "", err
 				}
@@ -204,6 +249,8 @@ func getAPIsInAllEnvironments() (string, error) {
 	var allTaskAPIEnvs []string
 	var allTrafficSplitters []schema.APIResponse
 	var allTrafficSplitterEnvs []string
+	var allWorkflows []schema.APIResponse
+	var allWorkflowEnvs []string
 
 	type getAPIsOutput struct {
 		EnvName string               `json:"env_name"`
@@ -216,7 +263,7 @@ func getAPIsInAllEnvironments() (string, error) {
 	errorsMap := map[string]error{}
 	// get apis from both environments
 	for _, env := range cliConfig.Environments {
-		apisRes, err := cluster.GetAPIs(MustGetOperatorConfig(env.Name))
+		apisRes, err := getSelectedAPIs(MustGetOperatorConfig(env.Name), _flagGetSelector, _flagGetProject)
 
 		apisOutput := getAPIsOutput{
 			EnvName: env.Name,
@@ -241,6 +288,9 @@ func getAPIsInAllEnvironments() (string, error) {
 				case userconfig.TrafficSplitterKind:
 					allTrafficSplitterEnvs = append(allTrafficSplitterEnvs, env.Name)
 					allTrafficSplitters = append(allTrafficSplitters, api)
+				case userconfig.WorkflowKind:
+					allWorkflowEnvs = append(allWorkflowEnvs, env.Name)
+					allWorkflows = append(allWorkflows, api)
 				}
 			}
 		} else {
@@ -262,11 +312,11 @@ func getAPIsInAllEnvironments() (string, error) {
 
 	out := ""
 
-	if len(allRealtimeAPIs) == 0 && len(allAsyncAPIs) == 0 && len(allBatchAPIs) == 0 && len(allTrafficSplitters) == 0 && len(allTaskAPIs) == 0 {
+	if len(allRealtimeAPIs) == 0 && len(allAsyncAPIs) == 0 && len(allBatchAPIs) == 0 && len(allTrafficSplitters) == 0 && len(allTaskAPIs) == 0 && len(allWorkflows) == 0 {
 		// check if any environments errorred
 		if len(errorsMap) != len(cliConfig.Environments) {
 			if len(errorsMap) == 0 {
-				return console.Bold("no apis are deployed"), nil
+				return console.Bold(noAPIsDeployedMessage(_flagGetSelector, _flagGetProject)), nil
 			}
 
 			var successfulEnvs []string
@@ -322,6 +372,16 @@ func getAPIsInAllEnvironments() (string, error) {
 
 			out += t.MustFormat()
 		}
+
+		if len(allWorkflows) > 0 {
+			t := workflowsTable(allWorkflows, allWorkflowEnvs)
+
+			if len(allBatchAPIs) > 0 || len(allTaskAPIs) > 0 || len(allRealtimeAPIs) > 0 || len(allAsyncAPIs) > 0 || len(allTrafficSplitters) > 0 {
+				out += "\n"
+			}
+
+			out += t.MustFormat()
+		}
 	}
 
 	if len(errorsMap) == 1 {
@@ -335,8 +395,58 @@ func getAPIsInAllEnvironments() (string, error) {
 	return out, nil
 }
 
+// getSelectedAPIs returns the apis whose labels match the label selector and which belong to the project (either can be empty)
+func getSelectedAPIs(operatorConfig cluster.OperatorConfig, selectorStr string, project string) ([]schema.APIResponse, error) {
+	if selectorStr == "" && project == "" {
+		return cluster.GetAPIs(operatorConfig)
+	}
+
+	selector := klabels.Everything()
+	var apisRes []schema.APIResponse
+	var err error
+	if selectorStr != "" {
+		selector, err = klabels.Parse(selectorStr)
+		if err != nil {
+			return nil, ErrorInvalidSelector(selectorStr, err)
+		}
+		apisRes, err = cluster.GetAPIsBySelector(operatorConfig, selectorStr)
+	} else {
+		apisRes, err = cluster.GetAPIs(operatorConfig)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// the selector is also checked here, since operators which don't support selectors return all of the apis
+	selected := []schema.APIResponse{}
+	for _, api := range apisRes {
+		if project != "" && (api.Spec.Project == nil || *api.Spec.Project != project) {
+			continue
+		}
+		if !selector.Matches(klabels.Set(api.Spec.Labels)) {
+			continue
+		}
+		selected = append(selected, api)
+	}
+	return selected, nil
+}
+
+func noAPIsDeployedMessage(selector string, project string) string {
+	var filters []string
+	if project != "" {
+		filters = append(filters, "in the "+project+" project")
+	}
+	if selector != "" {
+		filters = append(filters, "matching "+selector)
+	}
+	if len(filters) == 0 {
+		return "no apis are deployed"
+	}
+	return "no apis are deployed " + strings.Join(filters, " ")
+}
+
 func getAPIsByEnv(env cliconfig.Environment) (string, error) {
-	apisRes, err := cluster.GetAPIs(MustGetOperatorConfig(env.Name))
+	apisRes, err := getSelectedAPIs(MustGetOperatorConfig(env.Name), _flagGetSelector, _flagGetProject)
 	if err != nil {
 		return "", err
 	}
@@ -354,6 +464,7 @@ func getAPIsByEnv(env cliconfig.Environment) (string, error) {
 	var allBatchAPIs []schema.APIResponse
 	var allTaskAPIs []schema.APIResponse
 	var allTrafficSplitters []schema.APIResponse
+	var allWorkflows []schema.APIResponse
 
 	for _, api := range apisRes {
 		switch api.Spec.Kind {
@@ -367,11 +478,13 @@ func getAPIsByEnv(env cliconfig.Environment) (string, error) {
 			allAsyncAPIs = append(allAsyncAPIs, api)
 		case userconfig.TrafficSplitterKind:
 			allTrafficSplitters = append(allTrafficSplitters, api)
+		case userconfig.WorkflowKind:
+			allWorkflows = append(allWorkflows, api)
 		}
 	}
 
-	if len(allRealtimeAPIs) == 0 && len(allBatchAPIs) == 0 && len(allTaskAPIs) == 0 && len(allTrafficSplitters) == 0 {
-		return console.Bold("no apis are deployed"), nil
+	if len(allRealtimeAPIs) == 0 && len(allAsyncAPIs) == 0 && len(allBatchAPIs) == 0 && len(allTaskAPIs) == 0 && len(allTrafficSplitters) == 0 && len(allWorkflows) == 0 {
+		return console.Bold(noAPIsDeployedMessage(_flagGetSelector, _flagGetProject)), nil
 	}
 
 	out := ""
@@ -452,6 +565,22 @@ func getAPIsByEnv(env cliconfig.Environment) (string, error) {
 		out += t.MustFormat()
 	}
 
+	if len(allWorkflows) > 0 {
+		envNames := []string{}
+		for range allWorkflows {
+			envNames = append(envNames, env.Name)
+		}
+
+		t := workflowsTable(allWorkflows, envNames)
+		t.FindHeaderByTitle(_titleEnvironment).Hidden = true
+
+		if len(allBatchAPIs) > 0 || len(allTaskAPIs) > 0 || len(allRealtimeAPIs) > 0 || len(allAsyncAPIs) > 0 || len(allTrafficSplitters) > 0 {
+			out += "\n"
+		}
+
+		out += t.MustFormat()
+	}
+
 	return out, nil
 }
 
@@ -473,8 +602,10 @@ func getAPI(env cliconfig.Environment, apiName string) (string, error) {
 		exit.Error(errors.ErrorUnexpected(fmt.Sprintf("unable to find API %s", apiName)))
 	}
 
-	apiRes := apisRes[0]
+	return apiTable(apisRes[0], env)
+}
 
+func apiTable(apiRes schema.APIResponse, env cliconfig.Environment) (string, error) {
 	switch apiRes.Spec.Kind {
 	case userconfig.RealtimeAPIKind:
 		return realtimeAPITable(apiRes, env)
@@ -486,11 +617,147 @@ func getAPI(env cliconfig.Environment, apiName string) (string, error) {
 		return batchAPITable(apiRes), nil
 	case userconfig.TaskAPIKind:
 		return taskAPITable(apiRes), nil
+	case userconfig.WorkflowKind:
+		return workflowTable(apiRes), nil
 	default:
 		return "", errors.ErrorUnexpected(fmt.Sprintf("encountered unexpected kind %s for api %s", apiRes.Spec.Kind, apiRes.Spec.Name))
 	}
 }
 
+func getAPIHistory(env cliconfig.Environment, apiName string) (string, error) {
+	historyRes, err := cluster.GetHistory(MustGetOperatorConfig(env.Name), apiName)
+	if err != nil {
+		return "", err
+	}
+
+	if _flagOutput == flags.JSONOutputType {
+		bytes, err := libjson.Marshal(historyRes)
+		if err != nil {
+			return "", err
+		}
+		return string(bytes), nil
+	}
+
+	if len(historyRes.Revisions) == 0 {
+		return console.Bold(fmt.Sprintf("no revisions of %s have been recorded", apiName)) + "\n", nil
+	}
+
+	return deploymentRevisionsTable(historyRes.Revisions), nil
+}
+
+func getAPIRecommendations(env cliconfig.Environment, apiName string) (string, error) {
+	recommendationsRes, err := cluster.GetRecommendations(MustGetOperatorConfig(env.Name), apiName, _flagGetWindow)
+	if err != nil {
+		return "", err
+	}
+
+	if _flagOutput == flags.JSONOutputType {
+		bytes, err := libjson.Marshal(recommendationsRes)
+		if err != nil {
+			return "", err
+		}
+		return string(bytes), nil
+	}
+
+	t := table.Table{
+		Headers: []table.Header{
+			{Title: "container"},
+			{Title: "resource"},
+			{Title: "requested"},
+			{Title: "usage (p95)"},
+			{Title: "usage (max)"},
+			{Title: "recommended"},
+		},
+	}
+
+	var suggestions []string
+	for _, container := range recommendationsRes.Containers {
+		for _, resource := range []struct {
+			name           string
+			recommendation schema.ResourceRecommendation
+		}{{"cpu", container.CPU}, {"mem", container.Mem}} {
+			recommended := "-"
+			if resource.recommendation.Recommended != nil {
+				recommended = *resource.recommendation.Recommended
+			}
+			t.Rows = append(t.Rows, []interface{}{
+				container.Container,
+				resource.name,
+				strPtrStr(resource.recommendation.Requested),
+				strPtrStr(resource.recommendation.UsageP95),
+				strPtrStr(resource.recommendation.UsageMax),
+				recommended,
+			})
+		}
+		suggestions = append(suggestions, container.Suggestions...)
+	}
+
+	out := console.Bold(fmt.Sprintf("usage over the last %s", recommendationsRes.Window)) + "\n\n"
+	out += t.MustFormat(&table.Opts{Sort: pointer.Bool(false)})
+
+	if len(suggestions) == 0 {
+		out += "\n" + "the compute requests of all of the api's containers are appropriate for their usage\n"
+	} else {
+		out += titleStr("suggestions")
+		for _, suggestion := range suggestions {
+			out += "- " + suggestion + "\n"
+		}
+	}
+
+	return out, nil
+}
+
+func strPtrStr(str *string) string {
+	if str == nil {
+		return "-"
+	}
+	return *str
+}
+
+func deploymentRevisionsTable(revisions []schema.DeploymentRevision) string {
+	t := table.Table{
+		Headers: []table.Header{
+			{Title: _titleRevision},
+			{Title: _titleTime},
+			{Title: _titleDeployedBy, MaxWidth: 64},
+			{Title: _titleClientID},
+			{Title: _titleModels, MaxWidth: 64, Hidden: true},
+			{Title: _titleNotes},
+		},
+	}
+
+	t.Rows = make([][]interface{}, len(revisions))
+	for i, revision := range revisions {
+		notes := "-"
+		if revision.RollbackOf != nil {
+			notes = fmt.Sprintf("rollback to revision %d", *revision.RollbackOf)
+		}
+
+		modelVersions := "-"
+		if len(revision.ModelVersions) > 0 {
+			t.FindHeaderByTitle(_titleModels).Hidden = false
+			modelNames := maps.StrMapKeysString(revision.ModelVersions)
+			sort.Strings(modelNames)
+			var versions []string
+			for _, modelName := range modelNames {
+				versions = append(versions, modelName+": "+revision.ModelVersions[modelName])
+			}
+			modelVersions = strings.Join(versions, ", ")
+		}
+
+		t.Rows[i] = []interface{}{
+			revision.Revision,
+			revision.Time.Local().Format("2006-01-02 15:04:05 MST"),
+			revision.Principal,
+			valueOrDash(revision.ClientID),
+			modelVersions,
+			notes,
+		}
+	}
+
+	return t.MustFormat(&table.Opts{Sort: pointer.Bool(false)})
+}
+
 func apiHistoryTable(apiVersions []schema.APIVersion) string {
 	t := table.Table{
 		Headers: []table.Header{
@@ -508,6 +775,156 @@ func apiHistoryTable(apiVersions []schema.APIVersion) string {
 	return t.MustFormat(&table.Opts{Sort: pointer.Bool(false)})
 }
 
+// replicaFailuresStr describes why the api's replicas are failing, or returns an empty string if none of them are failing
+func replicaFailuresStr(failures []schema.ReplicaFailure) string {
+	if len(failures) == 0 {
+		return ""
+	}
+
+	out := titleStr("failing replicas")
+	for i, failure := range failures {
+		if i > 0 {
+			out += "\n"
+		}
+
+		details := []string{failure.Reason}
+		if failure.ExitCode != nil {
+			details = append(details, fmt.Sprintf("exit code %d", *failure.ExitCode))
+		}
+		details = append(details, fmt.Sprintf("%d %s", failure.Restarts, s.PluralS("restart", failure.Restarts)))
+		if failure.FinishedAt != nil {
+			details = append(details, "terminated "+libtime.SinceStr(failure.FinishedAt)+" ago")
+		}
+
+		out += fmt.Sprintf("%s (container %s): %s\n", failure.Replica, failure.Container, strings.Join(details, ", "))
+		if failure.Message != "" {
+			out += "  " + failure.Message + "\n"
+		}
+		if len(failure.LogLines) > 0 {
+			out += console.Bold(fmt.Sprintf("  last %d log lines:", len(failure.LogLines))) + "\n"
+			for _, line := range failure.LogLines {
+				out += "    " + line + "\n"
+			}
+		}
+	}
+
+	return out
+}
+
+// apiEventsTable returns the api's events in chronological order, or an empty string if there are no events
+func apiEventsTable(events []schema.APIEvent) string {
+	if len(events) == 0 {
+		return ""
+	}
+
+	t := table.Table{
+		Headers: []table.Header{
+			{Title: "last seen"},
+			{Title: "type"},
+			{Title: "reason"},
+			{Title: "object"},
+			{Title: "count"},
+			{Title: "message", MaxWidth: 100},
+		},
+	}
+
+	t.Rows = make([][]interface{}, len(events))
+	for i, event := range events {
+		eventTime := event.Time
+		t.Rows[i] = []interface{}{libtime.SinceStr(&eventTime), event.Type, event.Reason, event.Object, event.Count, event.Message}
+	}
+
+	return titleStr("events") + t.MustFormat(&table.Opts{Sort: pointer.Bool(false)})
+}
+
+// modelVersionsStr lists the model registry versions which the api's models were resolved to and the artifact versions of its watched
+// s3 paths, or returns an empty string if the api doesn't have any versioned models
+func modelVersionsStr(api *userconfig.API) string {
+	var lines []string
+
+	if api.Pod != nil && api.Pod.ModelCache != nil {
+		modelCache := api.Pod.ModelCache
+		if line := modelVersionStr(userconfig.ModelCacheKey, modelCache.ModelRegistry, modelCache.Path, modelCache.ArtifactVersion); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	if api.Models != nil {
+		for _, modelPath := range api.Models.Paths {
+			if line := modelVersionStr(modelPath.Name, modelPath.ModelRegistry, modelPath.Path, modelPath.ArtifactVersion); line != "" {
+				lines = append(lines, line)
+			}
+		}
+	}
+
+	if len(lines) == 0 {
+		return ""
+	}
+	return titleStr("model versions") + strings.Join(lines, "\n") + "\n"
+}
+
+func modelVersionStr(modelName string, modelRegistry *userconfig.ModelRegistry, path string, artifactVersion string) string {
+	if modelRegistry != nil {
+		return fmt.Sprintf("%s: %s model %s, version %s (%s)", modelName, modelRegistry.Provider, modelRegistry.Name, modelRegistry.ResolvedVersion, path)
+	}
+	if artifactVersion != "" {
+		return fmt.Sprintf("%s: %s (artifact version %s)", modelName, path, artifactVersion)
+	}
+	return ""
+}
+
+// integrityStr lists the image digests which the api's containers are pinned to, and the verification status of its model artifacts
+func integrityStr(api *userconfig.API, verification *schema.ArtifactVerification) string {
+	var lines []string
+
+	if api.Pod != nil {
+		for _, initContainer := range api.Pod.InitContainers {
+			if initContainer.ImageDigest != nil {
+				lines = append(lines, fmt.Sprintf("%s: %s@%s", initContainer.Name, initContainer.Image, *initContainer.ImageDigest))
+			}
+		}
+		for _, container := range api.Pod.Containers {
+			if container.ImageDigest != nil {
+				lines = append(lines, fmt.Sprintf("%s: %s@%s", container.Name, container.Image, *container.ImageDigest))
+			}
+		}
+
+		if api.Pod.ModelCache != nil && api.Pod.ModelCache.Integrity != nil {
+			lines = append(lines, userconfig.ModelCacheKey+": "+artifactVerificationStr(verification))
+		}
+	}
+
+	if api.Models != nil {
+		for _, modelPath := range api.Models.Paths {
+			if modelPath.Integrity != nil {
+				lines = append(lines, modelPath.Name+": verified when the model is loaded")
+			}
+		}
+	}
+
+	if len(lines) == 0 {
+		return ""
+	}
+	return titleStr("integrity") + strings.Join(lines, "\n") + "\n"
+}
+
+func artifactVerificationStr(verification *schema.ArtifactVerification) string {
+	if verification == nil || verification.VerifiedReplicas+verification.FailedReplicas == 0 {
+		return "verified before each replica starts"
+	}
+
+	var out string
+	if verification.VerifiedReplicas > 0 {
+		out = fmt.Sprintf("verified by %d %s (sha256:%s)", verification.VerifiedReplicas, s.PluralS("replica", verification.VerifiedReplicas), verification.Checksum)
+	}
+	if verification.FailedReplicas > 0 {
+		if out != "" {
+			out += "; "
+		}
+		out += fmt.Sprintf("failed on %d %s: %s", verification.FailedReplicas, s.PluralS("replica", verification.FailedReplicas), verification.Failure)
+	}
+	return out
+}
+
 func titleStr(title string) string {
 	return "\n" + console.Bold(title) + "\n"
 }
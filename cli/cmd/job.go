@@ -0,0 +1,180 @@
+/*
+Copyright 2021 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/cortexlabs/cortex/cli/cluster"
+	"github.com/cortexlabs/cortex/cli/types/cliconfig"
+	"github.com/cortexlabs/cortex/cli/types/flags"
+	"github.com/cortexlabs/cortex/pkg/lib/exit"
+	libjson "github.com/cortexlabs/cortex/pkg/lib/json"
+	"github.com/cortexlabs/cortex/pkg/lib/print"
+	"github.com/cortexlabs/cortex/pkg/lib/telemetry"
+	"github.com/cortexlabs/cortex/pkg/types/userconfig"
+	"github.com/spf13/cobra"
+)
+
+var (
+	_flagJobEnv string
+)
+
+func jobInit() {
+	_jobStopCmd.Flags().SortFlags = false
+	_jobStopCmd.Flags().StringVarP(&_flagJobEnv, "env", "e", "", "environment to use")
+	_jobStopCmd.Flags().VarP(&_flagOutput, "output", "o", fmt.Sprintf("output format: one of %s", strings.Join(flags.UserOutputTypeStrings(), "|")))
+	_jobCmd.AddCommand(_jobStopCmd)
+
+	_jobRetryCmd.Flags().SortFlags = false
+	_jobRetryCmd.Flags().StringVarP(&_flagJobEnv, "env", "e", "", "environment to use")
+	_jobRetryCmd.Flags().VarP(&_flagOutput, "output", "o", fmt.Sprintf("output format: one of %s", strings.Join(flags.UserOutputTypeStrings(), "|")))
+	_jobCmd.AddCommand(_jobRetryCmd)
+
+	_jobResultsCmd.Flags().SortFlags = false
+	_jobResultsCmd.Flags().StringVarP(&_flagJobEnv, "env", "e", "", "environment to use")
+	_jobResultsCmd.Flags().VarP(&_flagOutput, "output", "o", fmt.Sprintf("output format: one of %s", strings.Join(flags.UserOutputTypeStrings(), "|")))
+	_jobCmd.AddCommand(_jobResultsCmd)
+}
+
+var _jobCmd = &cobra.Command{
+	Use:   "job",
+	Short: "manage batch and task jobs (contains subcommands)",
+}
+
+var _jobStopCmd = &cobra.Command{
+	Use:               "stop API_NAME JOB_ID",
+	Short:             "stop a running job (its queue is deleted and its workers are terminated)",
+	Args:              cobra.ExactArgs(2),
+	ValidArgsFunction: completeAPINameAndJobIDArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		env := mustGetJobEnv(cmd, "cli.job.stop")
+
+		kind, err := getJobAPIKind(env.Name, args[0])
+		if err != nil {
+			exit.Error(err)
+		}
+
+		stopResponse, err := cluster.StopJob(MustGetOperatorConfig(env.Name), kind, args[0], args[1])
+		if err != nil {
+			exit.Error(err)
+		}
+
+		if _flagOutput == flags.JSONOutputType {
+			bytes, err := libjson.Marshal(stopResponse)
+			if err != nil {
+				exit.Error(err)
+			}
+			fmt.Print(string(bytes))
+			return
+		}
+
+		print.BoldFirstLine(stopResponse.Message)
+	},
+}
+
+var _jobRetryCmd = &cobra.Command{
+	Use:               "retry API_NAME JOB_ID",
+	Short:             "resubmit a failed job with its original submission",
+	Args:              cobra.ExactArgs(2),
+	ValidArgsFunction: completeAPINameAndJobIDArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		env := mustGetJobEnv(cmd, "cli.job.retry")
+
+		kind, err := getJobAPIKind(env.Name, args[0])
+		if err != nil {
+			exit.Error(err)
+		}
+
+		retryResponse, err := cluster.RetryJob(MustGetOperatorConfig(env.Name), kind, args[0], args[1])
+		if err != nil {
+			exit.Error(err)
+		}
+
+		if _flagOutput == flags.JSONOutputType {
+			bytes, err := libjson.Marshal(retryResponse)
+			if err != nil {
+				exit.Error(err)
+			}
+			fmt.Print(string(bytes))
+			return
+		}
+
+		print.BoldFirstLine(retryResponse.Message)
+	},
+}
+
+var _jobResultsCmd = &cobra.Command{
+	Use:               "results API_NAME JOB_ID",
+	Short:             "summarize the results of a completed batch job",
+	Args:              cobra.ExactArgs(2),
+	ValidArgsFunction: completeAPINameAndJobIDArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		env := mustGetJobEnv(cmd, "cli.job.results")
+
+		kind, err := getJobAPIKind(env.Name, args[0])
+		if err != nil {
+			exit.Error(err)
+		}
+		if kind != userconfig.BatchAPIKind {
+			exit.Error(ErrorAPIIsNotBatchAPI(args[0], kind))
+		}
+
+		out, err := getBatchJobResults(env, args[0], args[1])
+		if err != nil {
+			exit.Error(err)
+		}
+		fmt.Print(out)
+	},
+}
+
+func mustGetJobEnv(cmd *cobra.Command, telemetryEvent string) cliconfig.Environment {
+	envName, err := getEnvFromFlag(_flagJobEnv)
+	if err != nil {
+		telemetry.Event(telemetryEvent)
+		exit.Error(err)
+	}
+
+	env, err := ReadOrConfigureEnv(envName)
+	if err != nil {
+		telemetry.Event(telemetryEvent)
+		exit.Error(err)
+	}
+	telemetry.Event(telemetryEvent, map[string]interface{}{"env_name": env.Name})
+
+	err = printEnvIfNotSpecified(env.Name, cmd)
+	if err != nil {
+		exit.Error(err)
+	}
+
+	return env
+}
+
+func getJobAPIKind(envName string, apiName string) (userconfig.Kind, error) {
+	apisRes, err := cluster.GetAPI(MustGetOperatorConfig(envName), apiName)
+	if err != nil {
+		return userconfig.UnknownKind, err
+	}
+
+	kind := apisRes[0].Spec.Kind
+	if kind != userconfig.BatchAPIKind && kind != userconfig.TaskAPIKind {
+		return userconfig.UnknownKind, ErrorAPIIsNotJobAPI(apiName, kind)
+	}
+
+	return kind, nil
+}
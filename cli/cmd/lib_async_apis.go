@@ -46,8 +46,15 @@ func asyncAPITable(asyncAPI schema.APIResponse, env cliconfig.Environment) (stri
 
 	out += "\n" + console.Bold("endpoint: ") + asyncAPI.Endpoint + "\n"
 
+	out += modelVersionsStr(asyncAPI.Spec.API)
+	out += integrityStr(asyncAPI.Spec.API, asyncAPI.Verification)
+
 	out += "\n" + apiHistoryTable(asyncAPI.APIVersions)
 
+	out += replicaFailuresStr(asyncAPI.ReplicaFailures)
+
+	out += apiEventsTable(asyncAPI.Events)
+
 	if !_flagVerbose {
 		return out, nil
 	}
@@ -18,7 +18,9 @@ package cmd
 
 import (
 	"fmt"
+	"strings"
 
+	"github.com/cortexlabs/cortex/cli/types/cliconfig"
 	"github.com/cortexlabs/cortex/pkg/consts"
 	"github.com/cortexlabs/cortex/pkg/lib/aws"
 
@@ -26,12 +28,12 @@ import (
 	"github.com/cortexlabs/cortex/pkg/types/clusterconfig"
 )
 
-func newAWSClient(region string, printToStdout bool) (*aws.Client, error) {
+func newAWSClient(region string, credentialsConfig aws.CredentialsConfig, printToStdout bool) (*aws.Client, error) {
 	if err := clusterconfig.ValidateRegion(region); err != nil {
 		return nil, err
 	}
 
-	awsClient, err := aws.NewForRegion(region)
+	awsClient, err := aws.NewForRegionWithCredentials(region, credentialsConfig)
 	if err != nil {
 		return nil, err
 	}
@@ -41,12 +43,79 @@ func newAWSClient(region string, printToStdout bool) (*aws.Client, error) {
 	}
 
 	if printToStdout {
-		fmt.Println("using aws credentials with access key " + *awsClient.AccessKeyID() + "\n")
+		if credentialsConfig.RoleARN != "" {
+			fmt.Println("using aws role " + credentialsConfig.RoleARN + " (with access key " + *awsClient.AccessKeyID() + ")\n")
+		} else {
+			fmt.Println("using aws credentials with access key " + *awsClient.AccessKeyID() + "\n")
+		}
 	}
 
 	return awsClient, nil
 }
 
+// getClusterAWSCredentials returns the aws credentials bound to the environment specified with --aws-env;
+// if the flag isn't set, the credentials bound to fallbackEnvName are used (if that environment exists)
+func getClusterAWSCredentials(fallbackEnvName string) (aws.CredentialsConfig, error) {
+	if _flagClusterAWSEnv != "" {
+		env, err := readEnv(_flagClusterAWSEnv)
+		if err != nil {
+			return aws.CredentialsConfig{}, err
+		}
+		if env == nil {
+			return aws.CredentialsConfig{}, cliconfig.ErrorEnvironmentNotConfigured(_flagClusterAWSEnv)
+		}
+		return env.AWSCredentialsConfig(), nil
+	}
+
+	if fallbackEnvName != "" {
+		env, err := readEnv(fallbackEnvName)
+		if err != nil {
+			return aws.CredentialsConfig{}, err
+		}
+		if env != nil {
+			return env.AWSCredentialsConfig(), nil
+		}
+	}
+
+	return aws.CredentialsConfig{}, nil
+}
+
+// withClusterAssumeRole applies the cluster configuration's assume_role section and the --aws-role-arn, --aws-external-id,
+// and --aws-mfa-serial flags (which take precedence) to the credentials bound to the environment
+func withClusterAssumeRole(credentialsConfig aws.CredentialsConfig, accessConfig *clusterconfig.AccessConfig) aws.CredentialsConfig {
+	if accessConfig.AssumeRole != nil {
+		assumeRoleConfig := accessConfig.AssumeRole.CredentialsConfig()
+		assumeRoleConfig.Profile = credentialsConfig.Profile
+		credentialsConfig = assumeRoleConfig
+	}
+
+	if _flagClusterAWSRoleARN != "" {
+		credentialsConfig.RoleARN = _flagClusterAWSRoleARN
+	}
+	if _flagClusterAWSExternalID != "" {
+		credentialsConfig.ExternalID = _flagClusterAWSExternalID
+	}
+	if _flagClusterAWSMFASerial != "" {
+		credentialsConfig.MFASerial = _flagClusterAWSMFASerial
+	}
+
+	if credentialsConfig.MFASerial != "" {
+		credentialsConfig.MFATokenProvider = promptMFAToken(credentialsConfig.MFASerial)
+	}
+
+	return credentialsConfig
+}
+
+func promptMFAToken(mfaSerial string) func() (string, error) {
+	return func() (string, error) {
+		tokenCode := prompt.Prompt(&prompt.Options{
+			Prompt:     fmt.Sprintf("mfa token code for %s", mfaSerial),
+			HideTyping: true,
+		})
+		return strings.TrimSpace(tokenCode), nil
+	}
+}
+
 func promptIfNotAdmin(awsClient *aws.Client, disallowPrompt bool) {
 	accessKeyMsg := ""
 	if accessKey := awsClient.AccessKeyID(); accessKey != nil {
@@ -136,8 +136,15 @@ func batchAPITable(batchAPI schema.APIResponse) string {
 
 	out += "\n" + console.Bold("endpoint: ") + batchAPI.Endpoint + "\n"
 
+	out += modelVersionsStr(batchAPI.Spec.API)
+	out += integrityStr(batchAPI.Spec.API, batchAPI.Verification)
+
 	out += "\n" + apiHistoryTable(batchAPI.APIVersions)
 
+	out += replicaFailuresStr(batchAPI.ReplicaFailures)
+
+	out += apiEventsTable(batchAPI.Events)
+
 	if !_flagVerbose {
 		return out
 	}
@@ -264,3 +271,89 @@ func getBatchJob(env cliconfig.Environment, apiName string, jobID string) (strin
 
 	return out, nil
 }
+
+func getBatchJobResults(env cliconfig.Environment, apiName string, jobID string) (string, error) {
+	resp, err := cluster.GetBatchJobResults(MustGetOperatorConfig(env.Name), apiName, jobID)
+	if err != nil {
+		return "", err
+	}
+
+	if _flagOutput == flags.JSONOutputType {
+		bytes, err := libjson.Marshal(resp)
+		if err != nil {
+			return "", err
+		}
+		return string(bytes), nil
+	}
+
+	results := resp.JobResults
+
+	out := ""
+
+	jobIntroTable := table.KeyValuePairs{}
+	jobIntroTable.Add("job id", results.ID)
+	jobIntroTable.Add("status", results.Status.Message())
+	out += jobIntroTable.String(&table.KeyValuePairOpts{BoldKeys: pointer.Bool(true)})
+
+	jobTimingTable := table.KeyValuePairs{}
+	jobTimingTable.Add("start time", results.StartTime.Format(_timeFormat))
+	if results.EndTime != nil {
+		jobTimingTable.Add("end time", results.EndTime.Format(_timeFormat))
+	} else {
+		jobTimingTable.Add("end time", "-")
+	}
+	if duration := results.Duration(); duration != nil {
+		jobTimingTable.Add("duration", duration.Truncate(time.Second).String())
+	} else {
+		jobTimingTable.Add("duration", "-")
+	}
+	out += "\n" + jobTimingTable.String(&table.KeyValuePairOpts{BoldKeys: pointer.Bool(true)})
+
+	t := table.Table{
+		Headers: []table.Header{
+			{Title: "succeeded"},
+			{Title: "failed attempts"},
+		},
+		Rows: [][]interface{}{{results.Succeeded, results.Failed}},
+	}
+	out += titleStr("batch stats") + t.MustFormat(&table.Opts{BoldHeader: pointer.Bool(false)})
+
+	if len(results.Workers) > 0 {
+		t := table.Table{
+			Headers: []table.Header{
+				{Title: "worker"},
+				{Title: "succeeded"},
+				{Title: "failed attempts"},
+				{Title: "avg time per batch"},
+			},
+		}
+		for _, worker := range results.Workers {
+			avgTimePerBatch := "-"
+			if worker.Succeeded > 0 {
+				avgTimePerBatch = (time.Duration(worker.ProcessingTime/float64(worker.Succeeded)*1000000000) * time.Nanosecond).Truncate(time.Millisecond).String()
+			}
+			t.Rows = append(t.Rows, []interface{}{worker.Worker, worker.Succeeded, worker.Failed, avgTimePerBatch})
+		}
+		out += titleStr("worker stats") + t.MustFormat(&table.Opts{BoldHeader: pointer.Bool(false)})
+	}
+
+	if len(results.ErrorSamples) > 0 {
+		t := table.Table{
+			Headers: []table.Header{
+				{Title: "batch id"},
+				{Title: "worker"},
+				{Title: "error", MaxWidth: 80},
+			},
+		}
+		for _, failedBatch := range results.ErrorSamples {
+			t.Rows = append(t.Rows, []interface{}{failedBatch.BatchID, failedBatch.Worker, failedBatch.Error})
+		}
+		out += titleStr("error samples") + t.MustFormat(&table.Opts{BoldHeader: pointer.Bool(false)})
+	}
+
+	if results.Failed > 0 {
+		out += "\n" + console.Bold("failed batch payloads: ") + results.FailedBatchesPath + "\n"
+	}
+
+	return out, nil
+}
@@ -0,0 +1,281 @@
+/*
+Copyright 2021 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	libjson "github.com/cortexlabs/cortex/pkg/lib/json"
+	"github.com/cortexlabs/cortex/pkg/lib/parallel"
+	s "github.com/cortexlabs/cortex/pkg/lib/strings"
+)
+
+const (
+	_cleanupStatusDeleted   = "deleted"
+	_cleanupStatusScheduled = "scheduled" // e.g. a lifecycle rule will delete the resource
+	_cleanupStatusNotFound  = "not_found"
+	_cleanupStatusSkipped   = "skipped"
+	_cleanupStatusFailed    = "failed"
+)
+
+type cleanupTask struct {
+	resource    string   // identifies the task in the report and in other tasks' dependencies
+	description string   // e.g. "deleting sqs queues"
+	after       []string // resources which must be cleaned up (successfully or not) before this task runs
+	exclusive   bool     // exclusive tasks print their own output, so they are run while no other tasks are running
+	run         func() cleanupResult
+}
+
+type cleanupResult struct {
+	Resource string   `json:"resource"`
+	Status   string   `json:"status"`
+	Deleted  []string `json:"deleted,omitempty"`
+	Failed   []string `json:"failed,omitempty"`
+	Message  string   `json:"message,omitempty"`
+	Error    string   `json:"error,omitempty"`
+
+	err error
+}
+
+type cleanupReport struct {
+	ClusterName string          `json:"cluster_name"`
+	Region      string          `json:"region"`
+	Resources   []cleanupResult `json:"resources"`
+}
+
+func cleanupDeleted(deleted ...string) cleanupResult {
+	return cleanupResult{Status: _cleanupStatusDeleted, Deleted: deleted}
+}
+
+func cleanupScheduled(message string) cleanupResult {
+	return cleanupResult{Status: _cleanupStatusScheduled, Message: message}
+}
+
+func cleanupNotFound(message string) cleanupResult {
+	return cleanupResult{Status: _cleanupStatusNotFound, Message: message}
+}
+
+func cleanupSkipped(message string) cleanupResult {
+	return cleanupResult{Status: _cleanupStatusSkipped, Message: message}
+}
+
+// message should explain how to delete the resource manually
+func cleanupFailed(err error, message string) cleanupResult {
+	return cleanupResult{Status: _cleanupStatusFailed, Message: message, Error: errors.Message(err), err: err}
+}
+
+// runs the tasks in dependency order; tasks whose dependencies have completed run in parallel, and their progress is printed as they complete
+func runCleanupTasks(tasks []cleanupTask) []cleanupResult {
+	results := make([]cleanupResult, len(tasks))
+
+	taskResources := map[string]bool{}
+	for _, task := range tasks {
+		taskResources[task.resource] = true
+	}
+
+	completed := map[string]bool{}
+	started := make([]bool, len(tasks))
+	var printMux sync.Mutex
+
+	for len(completed) < len(tasks) {
+		var readyIndices []int
+		for i, task := range tasks {
+			if started[i] {
+				continue
+			}
+			ready := true
+			for _, dependency := range task.after {
+				if taskResources[dependency] && !completed[dependency] {
+					ready = false
+					break
+				}
+			}
+			if ready {
+				readyIndices = append(readyIndices, i)
+			}
+		}
+
+		if len(readyIndices) == 0 {
+			// only possible if the dependencies contain a cycle
+			for i, task := range tasks {
+				if !started[i] {
+					results[i] = cleanupFailed(errors.ErrorUnexpected("cleanup task dependencies contain a cycle", task.resource), "")
+					results[i].Resource = task.resource
+					completed[task.resource] = true
+				}
+			}
+			break
+		}
+
+		var parallelIndices []int
+		for _, i := range readyIndices {
+			if !tasks[i].exclusive {
+				parallelIndices = append(parallelIndices, i)
+			}
+		}
+
+		if len(parallelIndices) == 0 {
+			i := readyIndices[0]
+			started[i] = true
+			fmt.Printf("￮ %s ...", tasks[i].description)
+			results[i] = runCleanupTask(tasks[i])
+			fmt.Println()
+			if results[i].Status == _cleanupStatusFailed {
+				printCleanupFailure(results[i])
+			}
+			completed[tasks[i].resource] = true
+			continue
+		}
+
+		var wg sync.WaitGroup
+		for _, i := range parallelIndices {
+			started[i] = true
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				result := runCleanupTask(tasks[i])
+
+				printMux.Lock()
+				defer printMux.Unlock()
+				results[i] = result
+				printCleanupResult(tasks[i], result)
+			}(i)
+		}
+		wg.Wait()
+
+		for _, i := range parallelIndices {
+			completed[tasks[i].resource] = true
+		}
+	}
+
+	return results
+}
+
+func runCleanupTask(task cleanupTask) (result cleanupResult) {
+	defer func() {
+		if r := recover(); r != nil {
+			result = cleanupFailed(errors.CastRecoverError(r), "")
+		}
+		result.Resource = task.resource
+	}()
+	return task.run()
+}
+
+func printCleanupResult(task cleanupTask, result cleanupResult) {
+	fmt.Printf("￮ %s ... ", task.description)
+
+	switch result.Status {
+	case _cleanupStatusDeleted:
+		if len(result.Deleted) > 1 {
+			fmt.Printf("deleted %d ✓\n", len(result.Deleted))
+		} else {
+			fmt.Println("✓")
+		}
+	case _cleanupStatusScheduled:
+		fmt.Println("✓")
+	case _cleanupStatusNotFound:
+		fmt.Println(result.Message + " ✓")
+	case _cleanupStatusSkipped:
+		fmt.Println("skipped (" + result.Message + ")")
+	case _cleanupStatusFailed:
+		fmt.Print("failed ✗\n")
+		printCleanupFailure(result)
+	}
+}
+
+func printCleanupFailure(result cleanupResult) {
+	if result.Message != "" {
+		fmt.Printf("\n%s\n", result.Message)
+	}
+	if result.err != nil {
+		errors.PrintError(result.err)
+	}
+	fmt.Println()
+}
+
+func cleanupErrors(results []cleanupResult) []error {
+	var errs []error
+	for _, result := range results {
+		if result.Status == _cleanupStatusFailed && result.err != nil {
+			errs = append(errs, result.err)
+		}
+	}
+	return errs
+}
+
+func cleanupSummary(results []cleanupResult) string {
+	counts := map[string]int{}
+	for _, result := range results {
+		counts[result.Status]++
+	}
+
+	var parts []string
+	for _, status := range []string{_cleanupStatusDeleted, _cleanupStatusScheduled, _cleanupStatusNotFound, _cleanupStatusSkipped, _cleanupStatusFailed} {
+		if counts[status] > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", counts[status], strings.ReplaceAll(status, "_", " ")))
+		}
+	}
+	return s.StrsAnd(parts)
+}
+
+func writeCleanupReport(report cleanupReport, path string) error {
+	return libjson.WriteJSON(report, path)
+}
+
+// deletes the resources concurrently; message should explain how to delete the resources manually
+func cleanupInParallel(ids []string, deleteFn func(id string) error, message string) cleanupResult {
+	if len(ids) == 0 {
+		return cleanupDeleted()
+	}
+
+	var mux sync.Mutex
+	var deleted, failed []string
+	var lastErr error
+
+	fns := make([]func() error, len(ids))
+	for i := range ids {
+		id := ids[i]
+		fns[i] = func() error {
+			err := deleteFn(id)
+			mux.Lock()
+			defer mux.Unlock()
+			if err != nil {
+				failed = append(failed, id)
+				lastErr = err
+			} else {
+				deleted = append(deleted, id)
+			}
+			return nil
+		}
+	}
+	parallel.Run(fns[0], fns[1:]...)
+
+	sort.Strings(deleted)
+	sort.Strings(failed)
+
+	if lastErr != nil {
+		result := cleanupFailed(lastErr, message)
+		result.Deleted = deleted
+		result.Failed = failed
+		return result
+	}
+	return cleanupDeleted(deleted...)
+}
@@ -91,6 +91,19 @@ var _cliConfigValidation = &cr.StructValidation{
 								Validator: cliconfig.CortexEndpointValidator,
 							},
 						},
+						{
+							StructField: "OperatorToken",
+							StringValidation: &cr.StringValidation{
+								AllowEmpty: true,
+							},
+						},
+						{
+							StructField: "Auth",
+							StringValidation: &cr.StringValidation{
+								Default:       cliconfig.AWSAuth,
+								AllowedValues: cliconfig.AuthTypes,
+							},
+						},
 					},
 				},
 			},
@@ -406,6 +419,10 @@ func getEnvConfigDefaults(envName string) cliconfig.Environment {
 		defaults.OperatorEndpoint = os.Getenv("CORTEX_OPERATOR_ENDPOINT")
 	}
 
+	if defaults.OperatorToken == "" && os.Getenv("CORTEX_OPERATOR_TOKEN") != "" {
+		defaults.OperatorToken = os.Getenv("CORTEX_OPERATOR_TOKEN")
+	}
+
 	return defaults
 }
 
@@ -414,10 +431,29 @@ func configureEnv(envName string, fieldsToSkipPrompt cliconfig.Environment) (cli
 	env := cliconfig.Environment{
 		Name:             envName,
 		OperatorEndpoint: fieldsToSkipPrompt.OperatorEndpoint,
+		OperatorToken:    fieldsToSkipPrompt.OperatorToken,
+		Auth:             fieldsToSkipPrompt.Auth,
+		AWSProfile:       fieldsToSkipPrompt.AWSProfile,
+		AWSRoleARN:       fieldsToSkipPrompt.AWSRoleARN,
 	}
 
 	defaults := getEnvConfigDefaults(env.Name)
 
+	// the operator token isn't prompted for, since most users authenticate with their AWS credentials
+	if env.OperatorToken == "" {
+		env.OperatorToken = defaults.OperatorToken
+	}
+	if env.Auth == "" {
+		env.Auth = defaults.Auth
+	}
+	// the aws credentials aren't prompted for either, since most users use their default aws credentials
+	if env.AWSProfile == "" {
+		env.AWSProfile = defaults.AWSProfile
+	}
+	if env.AWSRoleARN == "" {
+		env.AWSRoleARN = defaults.AWSRoleARN
+	}
+
 	err := promptEnv(&env, defaults)
 	if err != nil {
 		return cliconfig.Environment{}, err
@@ -427,6 +463,12 @@ func configureEnv(envName string, fieldsToSkipPrompt cliconfig.Environment) (cli
 		return cliconfig.Environment{}, err
 	}
 
+	if env.Auth == cliconfig.OIDCAuth {
+		if err := loginOIDC(env); err != nil {
+			return cliconfig.Environment{}, err
+		}
+	}
+
 	if err := addEnvToCLIConfig(env, false); err != nil {
 		return cliconfig.Environment{}, err
 	}
@@ -437,14 +479,22 @@ func configureEnv(envName string, fieldsToSkipPrompt cliconfig.Environment) (cli
 }
 
 func MustGetOperatorConfig(envName string) cluster.OperatorConfig {
+	operatorConfig, err := getOperatorConfig(envName)
+	if err != nil {
+		exit.Error(err)
+	}
+	return operatorConfig
+}
+
+func getOperatorConfig(envName string) (cluster.OperatorConfig, error) {
 	clientID := clientID()
 	env, err := readEnv(envName)
 	if err != nil {
-		exit.Error(err)
+		return cluster.OperatorConfig{}, err
 	}
 
 	if env == nil {
-		exit.Error(ErrorEnvironmentNotFound(envName))
+		return cluster.OperatorConfig{}, ErrorEnvironmentNotFound(envName)
 	}
 
 	operatorConfig := cluster.OperatorConfig{
@@ -454,11 +504,24 @@ func MustGetOperatorConfig(envName string) cluster.OperatorConfig {
 	}
 
 	if env.OperatorEndpoint == "" {
-		exit.Error(ErrorFieldNotFoundInEnvironment(cliconfig.OperatorEndpointKey, env.Name))
+		return cluster.OperatorConfig{}, ErrorFieldNotFoundInEnvironment(cliconfig.OperatorEndpointKey, env.Name)
 	}
 	operatorConfig.OperatorEndpoint = env.OperatorEndpoint
 
-	return operatorConfig
+	operatorConfig.OperatorToken = env.OperatorToken
+	if os.Getenv("CORTEX_OPERATOR_TOKEN") != "" {
+		operatorConfig.OperatorToken = os.Getenv("CORTEX_OPERATOR_TOKEN")
+	}
+
+	if env.Auth == cliconfig.OIDCAuth {
+		operatorConfig.OIDCSessionPath = oidcSessionPath(env.Name)
+	}
+
+	operatorConfig.AWSCredentials = env.AWSCredentialsConfig()
+
+	operatorConfig.ResponseCacheDir = _responseCacheDir
+
+	return operatorConfig, nil
 }
 
 func listConfiguredEnvs() ([]*cliconfig.Environment, error) {
@@ -554,6 +617,10 @@ func removeEnvFromCLIConfig(envName string) error {
 		return cliconfig.ErrorEnvironmentNotConfigured(envName)
 	}
 
+	if err := os.RemoveAll(oidcSessionPath(envName)); err != nil {
+		return errors.WithStack(err)
+	}
+
 	cliConfig.Environments = updatedEnvs
 
 	if prevDefault != nil && envName == *prevDefault {
@@ -630,7 +697,8 @@ func writeCLIConfig(cliConfig cliconfig.CLIConfig) error {
 	if err != nil {
 		return errors.WithStack(err)
 	}
-	if err := files.WriteFile(cliConfigBytes, _cliConfigPath); err != nil {
+	// the cli config may contain operator tokens
+	if err := files.WritePrivateFile(cliConfigBytes, _cliConfigPath); err != nil {
 		return err
 	}
 
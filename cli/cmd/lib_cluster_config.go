@@ -98,6 +98,7 @@ func getClusterAccessConfigWithCache() (*clusterconfig.AccessConfig, error) {
 		cr.ParseYAMLFile(cachedAccessConfig, clusterconfig.AccessValidation, cachedPaths[0])
 		accessConfig.ClusterName = cachedAccessConfig.ClusterName
 		accessConfig.Region = cachedAccessConfig.Region
+		accessConfig.AssumeRole = cachedAccessConfig.AssumeRole
 	}
 
 	if _flagClusterConfig != "" {
@@ -212,7 +213,10 @@ func confirmInstallClusterConfig(clusterConfig *clusterconfig.Config, awsClient
 
 	operatorPrice := 2*(operatorInstancePrice+operatorEBSPrice) + metricsEBSPrice
 	rows = append(rows, []interface{}{"2 t3.medium instances (cortex system)", s.DollarsAndTenthsOfCents(operatorPrice)})
-	rows = append(rows, []interface{}{"2 network load balancers", s.DollarsMaxPrecision(nlbPrice) + " each"})
+	rows = append(rows, []interface{}{loadBalancersStr(*clusterConfig), s.DollarsMaxPrecision(nlbPrice) + " each"})
+	if clusterConfig.APIGateway != nil {
+		rows = append(rows, []interface{}{"1 api gateway (http api)", "billed per request"})
+	}
 
 	if clusterConfig.NATGateway == clusterconfig.SingleNATGateway {
 		rows = append(rows, []interface{}{"1 nat gateway", s.DollarsMaxPrecision(natUnitPrice)})
@@ -0,0 +1,320 @@
+/*
+Copyright 2021 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cortexlabs/cortex/cli/types/flags"
+	"github.com/cortexlabs/cortex/pkg/lib/console"
+	libjson "github.com/cortexlabs/cortex/pkg/lib/json"
+	libtime "github.com/cortexlabs/cortex/pkg/lib/time"
+)
+
+// the manager prints this prefix followed by the name of a phase when the phase starts (see start_phase() in manager/install.sh)
+const _managerPhasePrefix = "::phase::"
+
+const (
+	_phaseIAM          = "iam"
+	_phaseVPC          = "vpc"
+	_phaseControlPlane = "control_plane"
+	_phaseNodeGroups   = "nodegroups"
+	_phaseAddons       = "addons"
+	_phaseOperator     = "operator"
+)
+
+type clusterPhase struct {
+	name  string
+	title string
+}
+
+var _clusterUpPhases = []clusterPhase{
+	{name: _phaseIAM, title: "iam"},
+	{name: _phaseVPC, title: "vpc"},
+	{name: _phaseControlPlane, title: "control plane"},
+	{name: _phaseNodeGroups, title: "node groups"},
+	{name: _phaseAddons, title: "add-ons"},
+	{name: _phaseOperator, title: "operator"},
+}
+
+// returns -1 if there is no phase with the given name
+func clusterPhaseIndex(name string) int {
+	for i, phase := range _clusterUpPhases {
+		if phase.name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+func clusterPhaseTitle(name string) string {
+	if idx := clusterPhaseIndex(name); idx >= 0 {
+		return _clusterUpPhases[idx].title
+	}
+	return name
+}
+
+// the process's stdout, which is reserved for json lines when json output is requested for commands which redirect their other output to stderr (e.g. `cluster up`)
+var _jsonStdout = os.Stdout
+
+type progressEvent struct {
+	Time            time.Time `json:"time"`
+	Event           string    `json:"event"`
+	Phase           string    `json:"phase,omitempty"`
+	DurationSeconds *int64    `json:"duration_seconds,omitempty"`
+	ElapsedSeconds  int64     `json:"elapsed_seconds"`
+	Line            *string   `json:"line,omitempty"`
+}
+
+// clusterProgress renders the phases of a cluster operation as they start and complete; the manager's output is written through it,
+// so that the phase markers which the manager prints can be replaced with the rendered phases (or json events)
+type clusterProgress struct {
+	phases     []clusterPhase
+	outputType flags.OutputType
+
+	start      time.Time
+	phaseIdx   int // -1 until the first phase starts
+	phaseStart time.Time
+	completed  []string
+
+	// called (if set) whenever a phase completes or fails, with the names of the completed phases and the name of the failed phase (if any)
+	onUpdate func(completed []string, failed string)
+
+	pending     []byte // the start of the current line, which is buffered while it may be a phase marker
+	passthrough bool   // whether the current line has been determined to not be a phase marker
+	jsonLine    []byte // the current line, which is buffered until it's complete in json mode
+	lastByte    byte
+
+	mux sync.Mutex
+}
+
+func newClusterProgress(phases []clusterPhase, outputType flags.OutputType) *clusterProgress {
+	return &clusterProgress{
+		phases:     phases,
+		outputType: outputType,
+		start:      time.Now(),
+		phaseIdx:   -1,
+		lastByte:   '\n',
+	}
+}
+
+func (cp *clusterProgress) Write(p []byte) (int, error) {
+	cp.mux.Lock()
+	defer cp.mux.Unlock()
+
+	var output []byte
+	for _, b := range p {
+		if cp.passthrough {
+			output = append(output, b)
+			if b == '\n' {
+				cp.passthrough = false
+			}
+			continue
+		}
+
+		cp.pending = append(cp.pending, b)
+
+		if b == '\n' {
+			line := strings.TrimRight(string(cp.pending), "\r\n")
+			if strings.HasPrefix(line, _managerPhasePrefix) {
+				cp.writeOutput(output)
+				output = nil
+				cp.startPhase(strings.TrimSpace(strings.TrimPrefix(line, _managerPhasePrefix)))
+			} else {
+				output = append(output, cp.pending...)
+			}
+			cp.pending = nil
+			continue
+		}
+
+		if bytes.HasPrefix([]byte(_managerPhasePrefix), cp.pending) || bytes.HasPrefix(cp.pending, []byte(_managerPhasePrefix)) {
+			continue
+		}
+
+		output = append(output, cp.pending...)
+		cp.pending = nil
+		cp.passthrough = true
+	}
+
+	cp.writeOutput(output)
+	return len(p), nil
+}
+
+func (cp *clusterProgress) writeOutput(output []byte) {
+	if len(output) == 0 {
+		return
+	}
+	cp.lastByte = output[len(output)-1]
+
+	if cp.outputType != flags.JSONOutputType {
+		os.Stdout.Write(output)
+		return
+	}
+
+	for _, b := range output {
+		if b == '\n' {
+			cp.flushJSONLine()
+			continue
+		}
+		cp.jsonLine = append(cp.jsonLine, b)
+	}
+}
+
+func (cp *clusterProgress) flushJSONLine() {
+	if cp.jsonLine == nil {
+		return
+	}
+	line := strings.TrimRight(string(cp.jsonLine), "\r")
+	cp.jsonLine = nil
+	cp.printEvent(progressEvent{Event: "output", Line: &line})
+}
+
+// StartPhase completes the current phase and starts the named phase; phases which are reported out of order are ignored
+func (cp *clusterProgress) StartPhase(name string) {
+	cp.mux.Lock()
+	defer cp.mux.Unlock()
+	cp.startPhase(name)
+}
+
+// ResumeFromPhase marks the phases before the named phase as completed (without rendering them) and starts the named phase
+func (cp *clusterProgress) ResumeFromPhase(name string) {
+	cp.mux.Lock()
+	defer cp.mux.Unlock()
+
+	for _, phase := range cp.phases {
+		if phase.name == name {
+			break
+		}
+		cp.completed = append(cp.completed, phase.name)
+	}
+	cp.startPhase(name)
+}
+
+func (cp *clusterProgress) startPhase(name string) {
+	idx := -1
+	for i, phase := range cp.phases {
+		if phase.name == name {
+			idx = i
+		}
+	}
+	if idx <= cp.phaseIdx {
+		return
+	}
+
+	cp.completePhase()
+
+	cp.phaseIdx = idx
+	cp.phaseStart = time.Now()
+
+	if cp.outputType == flags.JSONOutputType {
+		cp.printEvent(progressEvent{Event: "phase_started", Phase: name})
+		return
+	}
+	cp.printLine(console.Bold(fmt.Sprintf("[%d/%d] %s", idx+1, len(cp.phases), cp.phases[idx].title)))
+}
+
+func (cp *clusterProgress) completePhase() {
+	if cp.phaseIdx < 0 {
+		return
+	}
+	now := time.Now()
+
+	cp.completed = append(cp.completed, cp.phases[cp.phaseIdx].name)
+	if cp.onUpdate != nil {
+		cp.onUpdate(cp.completed, "")
+	}
+
+	if cp.outputType == flags.JSONOutputType {
+		cp.flushJSONLine()
+		duration := int64(now.Sub(cp.phaseStart).Seconds())
+		cp.printEvent(progressEvent{Event: "phase_completed", Phase: cp.phases[cp.phaseIdx].name, DurationSeconds: &duration})
+		return
+	}
+	cp.printLine(fmt.Sprintf("[%d/%d] %s ✓ (%s, %s elapsed)", cp.phaseIdx+1, len(cp.phases), cp.phases[cp.phaseIdx].title, libtime.DifferenceStr(&cp.phaseStart, &now), libtime.DifferenceStr(&cp.start, &now)))
+}
+
+// Complete completes the current phase
+func (cp *clusterProgress) Complete() {
+	cp.mux.Lock()
+	defer cp.mux.Unlock()
+
+	cp.completePhase()
+	if cp.outputType == flags.JSONOutputType {
+		cp.printEvent(progressEvent{Event: "completed"})
+	}
+}
+
+// Fail reports that the current phase failed, and returns its name ("" if no phase was started)
+func (cp *clusterProgress) Fail() string {
+	cp.mux.Lock()
+	defer cp.mux.Unlock()
+
+	if cp.phaseIdx < 0 {
+		return ""
+	}
+	now := time.Now()
+
+	if cp.onUpdate != nil {
+		cp.onUpdate(cp.completed, cp.phases[cp.phaseIdx].name)
+	}
+
+	if cp.outputType == flags.JSONOutputType {
+		cp.flushJSONLine()
+		duration := int64(now.Sub(cp.phaseStart).Seconds())
+		cp.printEvent(progressEvent{Event: "phase_failed", Phase: cp.phases[cp.phaseIdx].name, DurationSeconds: &duration})
+		return cp.phases[cp.phaseIdx].name
+	}
+	cp.printLine(fmt.Sprintf("[%d/%d] %s failed ✗ (%s, %s elapsed)", cp.phaseIdx+1, len(cp.phases), cp.phases[cp.phaseIdx].title, libtime.DifferenceStr(&cp.phaseStart, &now), libtime.DifferenceStr(&cp.start, &now)))
+	return cp.phases[cp.phaseIdx].name
+}
+
+// phase lines are surrounded by blank lines, and start on a new line if the manager's output didn't end with one
+func (cp *clusterProgress) printLine(line string) {
+	prefix := "\n"
+	if cp.lastByte != '\n' {
+		prefix = "\n\n"
+	}
+	os.Stdout.WriteString(prefix + line + "\n\n")
+	cp.lastByte = '\n'
+}
+
+func (cp *clusterProgress) printEvent(event progressEvent) {
+	event.Time = time.Now().UTC()
+	event.ElapsedSeconds = int64(event.Time.Sub(cp.start).Seconds())
+
+	eventBytes, err := libjson.Marshal(event)
+	if err != nil {
+		return
+	}
+	_jsonStdout.Write(append(eventBytes, '\n'))
+}
+
+// strips the phase markers from the manager's output (e.g. before it's included in an error message)
+func removePhaseMarkers(out string) string {
+	var lines []string
+	for _, line := range strings.Split(out, "\n") {
+		if !strings.HasPrefix(line, _managerPhasePrefix) {
+			lines = append(lines, line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
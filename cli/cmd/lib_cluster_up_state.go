@@ -0,0 +1,88 @@
+/*
+Copyright 2021 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"github.com/cortexlabs/cortex/pkg/lib/aws"
+	"github.com/cortexlabs/cortex/pkg/lib/slices"
+	"github.com/cortexlabs/cortex/pkg/types/clusterstate"
+)
+
+// the key of the object in the cluster's bucket which tracks the progress of `cluster up`; it's stored outside of the cluster uid's directory
+// so that it isn't expired by the lifecycle rules, and it's deleted once the cluster has been created
+const _clusterUpStateKey = "cluster_up_state.json"
+
+type clusterUpState struct {
+	ClusterUID      string   `json:"cluster_uid"`
+	CompletedPhases []string `json:"completed_phases"`
+	FailedPhase     string   `json:"failed_phase,omitempty"`
+}
+
+// returns nil if there is no record of a previous `cluster up`
+func readClusterUpState(awsClient *aws.Client, bucket string) (*clusterUpState, error) {
+	exists, err := awsClient.IsS3File(bucket, _clusterUpStateKey)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, nil
+	}
+
+	var state clusterUpState
+	if err := awsClient.ReadJSONFromS3(&state, bucket, _clusterUpStateKey); err != nil {
+		return nil, err
+	}
+	if state.ClusterUID == "" {
+		return nil, nil
+	}
+
+	return &state, nil
+}
+
+func writeClusterUpState(awsClient *aws.Client, bucket string, state clusterUpState) error {
+	return awsClient.UploadJSONToS3(state, bucket, _clusterUpStateKey)
+}
+
+func deleteClusterUpState(awsClient *aws.Client, bucket string) error {
+	return awsClient.DeleteS3File(bucket, _clusterUpStateKey)
+}
+
+// returns the phase from which a previous `cluster up` can be resumed, or "" if the cluster must be created from scratch;
+// resuming is only possible once the control plane has been created (otherwise the cluster must be spun down first)
+func clusterUpResumePhase(state *clusterUpState, status clusterstate.Status) string {
+	if state == nil {
+		return ""
+	}
+
+	switch status {
+	case clusterstate.StatusNodeGroupCreateFailed:
+		return _phaseNodeGroups
+	case clusterstate.StatusCreateComplete, clusterstate.StatusUpdateComplete:
+		// all of the stacks were created, so the phases up to and including the control plane are known to have completed
+		pastControlPlane := false
+		for _, phase := range _clusterUpPhases {
+			if phase.name == _phaseNodeGroups {
+				pastControlPlane = true
+			}
+			if pastControlPlane && !slices.HasString(state.CompletedPhases, phase.name) {
+				return phase.name
+			}
+		}
+	}
+
+	return ""
+}
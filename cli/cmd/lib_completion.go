@@ -0,0 +1,155 @@
+/*
+Copyright 2021 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"sort"
+
+	"github.com/cortexlabs/cortex/cli/cluster"
+	"github.com/cortexlabs/cortex/pkg/lib/sets/strset"
+	"github.com/cortexlabs/cortex/pkg/types/clusterconfig"
+	"github.com/spf13/cobra"
+)
+
+// dynamic completions read the cli config, the cached cluster configs, and the operator;
+// errors are ignored, since the shell can't display them (no suggestions are returned instead)
+
+func registerFlagCompletions(cmd *cobra.Command) {
+	if cmd.Flags().Lookup("env") != nil {
+		cmd.RegisterFlagCompletionFunc("env", completeEnvNames)
+	}
+	if cmd.Flags().Lookup("node-group") != nil {
+		cmd.RegisterFlagCompletionFunc("node-group", completeNodeGroupNames)
+	}
+
+	for _, subcommand := range cmd.Commands() {
+		registerFlagCompletions(subcommand)
+	}
+}
+
+func completeEnvNames(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	envNames, err := listConfiguredEnvNames()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return envNames, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completes the first argument with an environment name
+func completeEnvNameArg(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return completeEnvNames(cmd, args, toComplete)
+}
+
+// completes the first argument with the name of an api which is deployed in the environment selected by the --env flag (or the default environment)
+func completeAPINameArg(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	operatorConfig, err := completionOperatorConfig(cmd)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	apiNames, err := cluster.GetAPINames(operatorConfig)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	sort.Strings(apiNames)
+
+	return apiNames, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completes the first argument with an api name, and the second argument with the id of one of the api's jobs
+func completeAPINameAndJobIDArgs(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) == 0 {
+		return completeAPINameArg(cmd, args, toComplete)
+	}
+	if len(args) > 1 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	operatorConfig, err := completionOperatorConfig(cmd)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	apisRes, err := cluster.GetAPI(operatorConfig, args[0])
+	if err != nil || len(apisRes) == 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	var jobIDs []string
+	for _, job := range apisRes[0].BatchJobStatuses {
+		jobIDs = append(jobIDs, job.ID)
+	}
+	for _, job := range apisRes[0].TaskJobStatuses {
+		jobIDs = append(jobIDs, job.ID)
+	}
+	sort.Strings(jobIDs)
+
+	return jobIDs, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completes node group names from the cluster config file which is passed with --config, or otherwise from the cached cluster configs
+// (which are filtered by --name and --region, if they are set)
+func completeNodeGroupNames(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	var clusterConfigs []clusterconfig.Config
+
+	if configPath, _ := cmd.Flags().GetString("config"); configPath != "" {
+		clusterConfig := clusterconfig.Config{}
+		if err := readUserClusterConfigFile(&clusterConfig, configPath); err != nil {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		clusterConfigs = append(clusterConfigs, clusterConfig)
+	} else {
+		clusterName, _ := cmd.Flags().GetString("name")
+		region, _ := cmd.Flags().GetString("region")
+
+		for _, cachedConfigPath := range existingCachedClusterConfigPaths() {
+			clusterConfig := clusterconfig.Config{}
+			if err := readCachedClusterConfigFile(&clusterConfig, cachedConfigPath); err != nil {
+				continue
+			}
+			if (clusterName != "" && clusterConfig.ClusterName != clusterName) || (region != "" && clusterConfig.Region != region) {
+				continue
+			}
+			clusterConfigs = append(clusterConfigs, clusterConfig)
+		}
+	}
+
+	nodeGroupNames := strset.New()
+	for _, clusterConfig := range clusterConfigs {
+		for _, ng := range clusterConfig.NodeGroups {
+			nodeGroupNames.Add(ng.Name)
+		}
+	}
+
+	return nodeGroupNames.SliceSorted(), cobra.ShellCompDirectiveNoFileComp
+}
+
+func completionOperatorConfig(cmd *cobra.Command) (cluster.OperatorConfig, error) {
+	envFlag, _ := cmd.Flags().GetString("env")
+	envName, err := getEnvFromFlag(envFlag)
+	if err != nil {
+		return cluster.OperatorConfig{}, err
+	}
+	return getOperatorConfig(envName)
+}
@@ -0,0 +1,193 @@
+/*
+Copyright 2021 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/cortexlabs/cortex/cli/cluster"
+	"github.com/cortexlabs/cortex/cli/types/flags"
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	libjson "github.com/cortexlabs/cortex/pkg/lib/json"
+	"github.com/cortexlabs/cortex/pkg/lib/sets/strset"
+)
+
+// the exit codes are stable, so that scripts can branch on them
+const (
+	_exitCodeError      = 1 // errors which don't belong to one of the categories below
+	_exitCodeValidation = 3 // invalid configuration files, flags, or arguments (2 is used by `cortex deploy --diff-only` to signal changes)
+	_exitCodeAWS        = 4 // errors returned by aws (e.g. missing permissions or exceeded service quotas)
+	_exitCodeOperator   = 5 // errors returned by the operator, or failures to connect to it
+	_exitCodeTimeout    = 6 // timeouts
+	_exitCodeAuth       = 7 // the client's credentials are missing, or were rejected by the operator
+)
+
+var _exitCodeCategories = map[int]string{
+	_exitCodeError:      "error",
+	_exitCodeValidation: "validation",
+	_exitCodeAWS:        "aws",
+	_exitCodeOperator:   "operator",
+	_exitCodeTimeout:    "timeout",
+	_exitCodeAuth:       "auth",
+}
+
+var _validationErrorKindPrefixes = []string{"configreader.", "spec.", "userconfig.", "clusterconfig.", "cliconfig."}
+
+var _validationErrorKinds = strset.New(
+	ErrInvalidProvider,
+	ErrInvalidLegacyProvider,
+	ErrNoAvailableEnvironment,
+	ErrEnvironmentNotSet,
+	ErrEnvironmentNotFound,
+	ErrFieldNotFoundInEnvironment,
+	ErrInvalidOperatorEndpoint,
+	ErrCortexYAMLNotFound,
+	ErrCredentialsInClusterConfig,
+	ErrSpecifyAtLeastOneFlag,
+	ErrMinInstancesLowerThan,
+	ErrMaxInstancesLowerThan,
+	ErrMinInstancesGreaterThanMaxInstances,
+	ErrNodeGroupNotFound,
+	ErrJSONOutputNotSupportedWithFlag,
+	ErrFlagRequiresDebug,
+	ErrInvalidDebugCollector,
+	ErrShellCompletionNotSupported,
+	ErrDeployFromTopLevelDir,
+	ErrAPINameMustBeProvided,
+	ErrAPINotFoundInConfig,
+	ErrInvalidRole,
+	ErrBuildEntrypointRequired,
+	ErrBuildEntrypointWithDockerfile,
+	ErrBuildEntrypointNotInContext,
+	ErrBuildAPIHasNoContainers,
+	ErrBuildContainerNameMustBeProvided,
+	ErrBuildContainerNotFound,
+	ErrBuildImageNotFoundInConfig,
+	ErrBuildImageNotUniqueInConfig,
+	ErrInvalidRevision,
+	ErrHistoryRequiresAPIName,
+	ErrInvalidSelector,
+	ErrBulkDeleteWithAPIName,
+	ErrDeleteAPINameRequired,
+	ErrInvalidOlderThan,
+)
+
+var _operatorErrorKinds = strset.New(
+	ErrNoOperatorLoadBalancer,
+	cluster.ErrFailedToConnectOperator,
+	cluster.ErrOperatorSocketRead,
+	cluster.ErrOperatorResponseUnknown,
+	cluster.ErrOperatorStreamResponseUnknown,
+)
+
+var _timeoutErrorKinds = strset.New(
+	"clusterstatus.cluster_create_failed_timeout",
+	"operator.hook_timed_out",
+	"oidc.login_timed_out",
+)
+
+var _authErrorKinds = strset.New(
+	ErrMissingAWSCredentials,
+	cluster.ErrOIDCSessionNotFound,
+)
+
+type errorEnvelope struct {
+	Error errorEnvelopeError `json:"error"`
+}
+
+type errorEnvelopeError struct {
+	Kind     string `json:"kind"`
+	Category string `json:"category"`
+	ExitCode int    `json:"exit_code"`
+	Message  string `json:"message"`
+}
+
+// printError prints the error as a JSON envelope (to stdout, even if the command redirected its other output to stderr) if json output was requested, and for the user (to stderr) otherwise
+func printError(err error) {
+	if _flagOutput != flags.JSONOutputType {
+		if !errors.IsNoPrint(err) {
+			errors.PrintErrorForUser(err)
+		}
+		return
+	}
+
+	exitCode := errorExitCode(err)
+	bytes, jsonErr := libjson.Marshal(errorEnvelope{
+		Error: errorEnvelopeError{
+			Kind:     errors.GetKind(err),
+			Category: _exitCodeCategories[exitCode],
+			ExitCode: exitCode,
+			Message:  errors.Message(err),
+		},
+	})
+	if jsonErr != nil {
+		errors.PrintErrorForUser(err)
+		return
+	}
+	fmt.Fprintln(_jsonStdout, string(bytes))
+}
+
+func errorExitCode(err error) int {
+	if err == nil {
+		return _exitCodeError
+	}
+
+	kind := errors.GetKind(err)
+	cause := errors.CauseOrSelf(err)
+
+	var operatorStatusCode int
+	if metadata, ok := errors.GetMetadata(err).(cluster.OperatorErrorMetadata); ok {
+		operatorStatusCode = metadata.StatusCode
+	}
+
+	if netErr, ok := cause.(net.Error); (ok && netErr.Timeout()) || _timeoutErrorKinds.Has(kind) ||
+		operatorStatusCode == http.StatusGatewayTimeout || operatorStatusCode == http.StatusRequestTimeout {
+		return _exitCodeTimeout
+	}
+
+	if _authErrorKinds.Has(kind) || operatorStatusCode == http.StatusUnauthorized || operatorStatusCode == http.StatusForbidden {
+		return _exitCodeAuth
+	}
+
+	// the operator returns validation errors for invalid api configurations
+	if _validationErrorKinds.Has(kind) || hasAnyPrefix(kind, _validationErrorKindPrefixes) {
+		return _exitCodeValidation
+	}
+
+	if _, ok := cause.(awserr.Error); ok || strings.HasPrefix(kind, "aws.") {
+		return _exitCodeAWS
+	}
+
+	if _operatorErrorKinds.Has(kind) || operatorStatusCode != 0 {
+		return _exitCodeOperator
+	}
+
+	return _exitCodeError
+}
+
+func hasAnyPrefix(str string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(str, prefix) {
+			return true
+		}
+	}
+	return false
+}
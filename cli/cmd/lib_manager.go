@@ -52,9 +52,33 @@ type dockerCopyToPath struct {
 	containerPath string
 }
 
-func runManager(containerConfig *container.Config, addNewLineAfterPull bool, copyToPaths []dockerCopyToPath, copyFromPaths []dockerCopyFromPath) (string, *int, error) {
+// awsClient and bucket are only used when the manager is run remotely
+// if set, the manager's output is written to _managerOutput instead of stdout (e.g. so that `cluster up` can render its progress)
+var _managerOutput io.Writer
+
+func managerOutput() io.Writer {
+	if _managerOutput != nil {
+		return _managerOutput
+	}
+	return os.Stdout
+}
+
+func runManager(containerConfig *container.Config, hostConfig *container.HostConfig, addNewLineAfterPull bool, copyToPaths []dockerCopyToPath, copyFromPaths []dockerCopyFromPath, awsClient *aws.Client, bucket string) (string, *int, error) {
 	containerConfig.Env = append(containerConfig.Env, "CORTEX_CLI_VERSION="+consts.CortexVersion)
 
+	mode, err := managerMode()
+	if err != nil {
+		return "", nil, err
+	}
+	if mode == _managerModeLocal {
+		containerConfig.Cmd[0] = _managerVersionCheckScript + " && " + containerConfig.Cmd[0]
+		return runManagerLocally(containerConfig, copyToPaths, copyFromPaths)
+	}
+	if mode == _managerModeRemote {
+		containerConfig.Cmd[0] = _managerVersionCheckScript + " && " + containerConfig.Cmd[0]
+		return runManagerRemotely(containerConfig, copyToPaths, copyFromPaths, awsClient, bucket)
+	}
+
 	// Add a slight delay before running the command to ensure logs don't start until after the container is attached
 	containerConfig.Cmd[0] = "sleep 0.1 && /root/check_cortex_version.sh && " + containerConfig.Cmd[0]
 
@@ -75,7 +99,7 @@ func runManager(containerConfig *container.Config, addNewLineAfterPull bool, cop
 		fmt.Println()
 	}
 
-	containerInfo, err := dockerClient.ContainerCreate(context.Background(), containerConfig, nil, nil, "")
+	containerInfo, err := dockerClient.ContainerCreate(context.Background(), containerConfig, hostConfig, nil, "")
 	if err != nil {
 		return "", nil, docker.WrapDockerError(err)
 	}
@@ -127,7 +151,7 @@ func runManager(containerConfig *container.Config, addNewLineAfterPull bool, cop
 	var outputBuffer bytes.Buffer
 	tee := io.TeeReader(logsOutput.Reader, &outputBuffer)
 
-	_, err = io.Copy(os.Stdout, tee)
+	_, err = io.Copy(managerOutput(), tee)
 	if err != nil && err != io.EOF {
 		return "", nil, errors.WithStack(err)
 	}
@@ -160,7 +184,7 @@ func runManager(containerConfig *container.Config, addNewLineAfterPull bool, cop
 	return output, &info.State.ExitCode, nil
 }
 
-func runManagerWithClusterConfig(entrypoint string, clusterConfig *clusterconfig.Config, awsClient *aws.Client, copyToPaths []dockerCopyToPath, copyFromPaths []dockerCopyFromPath, extraEnvs []string) (string, *int, error) {
+func runManagerWithClusterConfig(entrypoint string, clusterConfig *clusterconfig.Config, awsClient *aws.Client, hostConfig *container.HostConfig, copyToPaths []dockerCopyToPath, copyFromPaths []dockerCopyFromPath, extraEnvs []string) (string, *int, error) {
 	clusterConfigBytes, err := yaml.Marshal(clusterConfig)
 	if err != nil {
 		return "", nil, errors.WithStack(err)
@@ -208,7 +232,7 @@ func runManagerWithClusterConfig(entrypoint string, clusterConfig *clusterconfig
 		containerConfig.Env = append(containerConfig.Env, "AWS_SESSION_TOKEN="+*sessionToken)
 	}
 
-	output, exitCode, err := runManager(containerConfig, false, copyToPaths, copyFromPaths)
+	output, exitCode, err := runManager(containerConfig, hostConfig, false, copyToPaths, copyFromPaths, awsClient, clusterConfig.Bucket)
 	if err != nil {
 		return "", nil, err
 	}
@@ -239,7 +263,12 @@ func runManagerAccessCommand(entrypoint string, accessConfig clusterconfig.Acces
 		containerConfig.Env = append(containerConfig.Env, "AWS_SESSION_TOKEN="+*sessionToken)
 	}
 
-	output, exitCode, err := runManager(containerConfig, true, copyToPaths, copyFromPaths)
+	accountID, _, err := awsClient.GetCachedAccountID()
+	if err != nil {
+		return "", nil, err
+	}
+
+	output, exitCode, err := runManager(containerConfig, nil, true, copyToPaths, copyFromPaths, awsClient, clusterconfig.BucketName(accountID, accessConfig.ClusterName, accessConfig.Region))
 	if err != nil {
 		return "", nil, err
 	}
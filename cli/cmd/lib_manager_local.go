@@ -0,0 +1,141 @@
+/*
+Copyright 2021 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/cortexlabs/cortex/pkg/lib/archive"
+	"github.com/cortexlabs/cortex/pkg/lib/docker"
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/lib/files"
+	"github.com/docker/docker/api/types/container"
+)
+
+const (
+	_managerModeEnvVar = "CORTEX_MANAGER_MODE"
+	_managerModeDocker = "docker"
+	_managerModeLocal  = "local"
+	_managerModeRemote = "remote"
+
+	_managerVersionCheckScript = "/root/check_cortex_version.sh"
+)
+
+// in local mode, the manager's scripts run directly on the host instead of in a docker container,
+// which is useful in CI environments which can run the manager image as the job's image but can't run docker (the host must have the manager's scripts and tools);
+// in remote mode, the manager runs in aws codebuild (see runManagerRemotely())
+func managerMode() (string, error) {
+	if _flagClusterRunRemote {
+		return _managerModeRemote, nil
+	}
+
+	mode := os.Getenv(_managerModeEnvVar)
+	switch mode {
+	case _managerModeRemote:
+		return _managerModeRemote, nil
+	case "", _managerModeDocker:
+		return _managerModeDocker, nil
+	case _managerModeLocal:
+		if !files.IsFile(_managerVersionCheckScript) {
+			return "", ErrorManagerScriptsNotFound(_managerVersionCheckScript)
+		}
+		return _managerModeLocal, nil
+	default:
+		return "", ErrorInvalidManagerMode(mode, []string{_managerModeDocker, _managerModeLocal, _managerModeRemote})
+	}
+}
+
+// returns an error if the manager can't be run in the configured mode (e.g. if docker isn't running)
+func assertManagerCanRun() error {
+	mode, err := managerMode()
+	if err != nil {
+		return err
+	}
+	if mode == _managerModeDocker {
+		if _, err := docker.GetDockerClient(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func runManagerLocally(containerConfig *container.Config, copyToPaths []dockerCopyToPath, copyFromPaths []dockerCopyFromPath) (string, *int, error) {
+	for _, copyPath := range copyToPaths {
+		copyPath.input.AddPrefix = filepath.Join(copyPath.containerPath, copyPath.input.AddPrefix)
+		tarBytes, _, err := archive.TarToMem(copyPath.input)
+		if err != nil {
+			return "", nil, err
+		}
+		if _, err := archive.UntarReaderToDir(bytes.NewReader(tarBytes), "/"); err != nil {
+			return "", nil, err
+		}
+	}
+
+	cmd := exec.Command(containerConfig.Entrypoint[0], append(containerConfig.Entrypoint[1:], containerConfig.Cmd...)...)
+	cmd.Env = append(os.Environ(), containerConfig.Env...)
+	cmd.Stdin = os.Stdin
+
+	var outputBuffer bytes.Buffer
+	// stderr is written to the manager's output if it's set, since the output is combined in the manager's container (which has a tty)
+	var stderr io.Writer = os.Stderr
+	if _managerOutput != nil {
+		stderr = _managerOutput
+	}
+	cmd.Stdout = io.MultiWriter(managerOutput(), &outputBuffer)
+	cmd.Stderr = io.MultiWriter(stderr, &outputBuffer)
+
+	exitCode := 0
+	if err := cmd.Run(); err != nil {
+		exitErr, ok := err.(*exec.ExitError)
+		if !ok {
+			return "", nil, errors.WithStack(err)
+		}
+		exitCode = exitErr.ExitCode()
+	}
+
+	if exitCode == 0 {
+		for _, copyPath := range copyFromPaths {
+			if err := copyLocalPath(copyPath.containerPath, copyPath.localDir); err != nil {
+				return "", nil, err
+			}
+		}
+	}
+
+	return strings.ReplaceAll(outputBuffer.String(), "\r\n", "\n"), &exitCode, nil
+}
+
+// copies the file or directory at src into destDir (preserving its name), like docker.CopyFromContainer()
+func copyLocalPath(src string, destDir string) error {
+	input := &archive.Input{}
+	if files.IsDir(src) {
+		input.Dirs = []archive.DirInput{{Source: src, Dest: filepath.Base(src)}}
+	} else {
+		input.Files = []archive.FileInput{{Source: src, Dest: filepath.Base(src)}}
+	}
+
+	tarBytes, _, err := archive.TarToMem(input)
+	if err != nil {
+		return err
+	}
+	_, err = archive.UntarReaderToDir(bytes.NewReader(tarBytes), destDir)
+	return err
+}
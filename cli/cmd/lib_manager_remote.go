@@ -0,0 +1,438 @@
+/*
+Copyright 2021 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	awssdk "github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/codebuild"
+	"github.com/aws/aws-sdk-go/service/iam"
+	"github.com/cortexlabs/cortex/cli/lib/routines"
+	"github.com/cortexlabs/cortex/pkg/lib/archive"
+	"github.com/cortexlabs/cortex/pkg/lib/aws"
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/lib/exit"
+	"github.com/cortexlabs/cortex/pkg/lib/random"
+	"github.com/docker/docker/api/types/container"
+)
+
+const (
+	_remoteManagerProjectName     = "cortex-manager"
+	_remoteManagerRoleName        = "cortex-remote-manager"
+	_remoteManagerParameterPrefix = "/cortex/remote-manager/"
+	_remoteManagerBucketPrefix    = "remote-manager/"
+	_remoteManagerExitCodePrefix  = "cortex-remote-manager-exit-code: "
+	_remoteManagerTimeoutMinutes  = 240
+)
+
+// the project's buildspec runs the script which is generated for each build (see remoteManagerScript())
+var _remoteManagerBuildspec = strings.TrimSpace(`
+version: 0.2
+env:
+  shell: bash
+phases:
+  build:
+    commands:
+      - echo "$CORTEX_REMOTE_MANAGER_SCRIPT" | base64 -d > /tmp/cortex_remote_manager.sh && bash /tmp/cortex_remote_manager.sh
+`)
+
+// the aws credentials are passed to the build via ssm parameters, so that they aren't visible in the build's configuration
+var _remoteManagerSecretEnvVars = map[string]bool{
+	"AWS_ACCESS_KEY_ID":     true,
+	"AWS_SECRET_ACCESS_KEY": true,
+	"AWS_SESSION_TOKEN":     true,
+}
+
+// runs the manager in an ephemeral aws codebuild build in the cluster's account and region, streaming its logs;
+// files are copied to and from the build via the cluster's bucket
+func runManagerRemotely(containerConfig *container.Config, copyToPaths []dockerCopyToPath, copyFromPaths []dockerCopyFromPath, awsClient *aws.Client, bucket string) (string, *int, error) {
+	if awsClient == nil {
+		return "", nil, errors.ErrorUnexpected("an aws client is required to run the manager remotely")
+	}
+
+	if len(copyToPaths) > 0 || len(copyFromPaths) > 0 {
+		bucketExists, err := awsClient.DoesBucketExist(bucket)
+		if err != nil {
+			return "", nil, err
+		}
+		if !bucketExists {
+			return "", nil, ErrorRemoteManagerBucketNotFound(bucket)
+		}
+	}
+
+	fmt.Print("￮ starting the cluster manager in aws codebuild (this may take a few minutes)")
+	if err := createRemoteManagerProjectIfNotFound(awsClient, containerConfig.Image); err != nil {
+		fmt.Print("\n\n")
+		return "", nil, err
+	}
+
+	runID := random.LowercaseString(12)
+	bucketPrefix := _remoteManagerBucketPrefix + runID + "/"
+	var parameterNames []string
+
+	cleanup := func() {
+		for _, parameterName := range parameterNames {
+			awsClient.DeleteSSMParameter(parameterName)
+		}
+		if len(copyToPaths) > 0 || len(copyFromPaths) > 0 {
+			awsClient.DeleteS3Prefix(bucket, bucketPrefix, true)
+		}
+	}
+	defer cleanup()
+
+	var inputKeys []string
+	for i, copyPath := range copyToPaths {
+		copyPath.input.AddPrefix = filepath.Join(copyPath.containerPath, copyPath.input.AddPrefix)
+		tarBytes, _, err := archive.TarToMem(copyPath.input)
+		if err != nil {
+			fmt.Print("\n\n")
+			return "", nil, err
+		}
+		key := bucketPrefix + fmt.Sprintf("in-%d.tar", i)
+		if err := awsClient.UploadBytesToS3(tarBytes, bucket, key); err != nil {
+			fmt.Print("\n\n")
+			return "", nil, err
+		}
+		inputKeys = append(inputKeys, key)
+	}
+
+	outputKeys := make([]string, len(copyFromPaths))
+	for i := range copyFromPaths {
+		outputKeys[i] = bucketPrefix + fmt.Sprintf("out-%d.tar", i)
+	}
+
+	var envVars []*codebuild.EnvironmentVariable
+	for _, env := range containerConfig.Env {
+		split := strings.SplitN(env, "=", 2)
+		if len(split) != 2 || split[1] == "" {
+			continue
+		}
+		name, value := split[0], split[1]
+
+		if _remoteManagerSecretEnvVars[name] {
+			parameterName := _remoteManagerParameterPrefix + runID + "/" + name
+			parameterNames = append(parameterNames, parameterName)
+			if err := awsClient.PutSSMSecureStringParameter(parameterName, value); err != nil {
+				fmt.Print("\n\n")
+				return "", nil, err
+			}
+			envVars = append(envVars, &codebuild.EnvironmentVariable{
+				Name:  awssdk.String(name),
+				Value: awssdk.String(parameterName),
+				Type:  awssdk.String(codebuild.EnvironmentVariableTypeParameterStore),
+			})
+			continue
+		}
+
+		envVars = append(envVars, &codebuild.EnvironmentVariable{
+			Name:  awssdk.String(name),
+			Value: awssdk.String(value),
+			Type:  awssdk.String(codebuild.EnvironmentVariableTypePlaintext),
+		})
+	}
+
+	script := remoteManagerScript(append(containerConfig.Entrypoint, containerConfig.Cmd...), bucket, inputKeys, copyFromPaths, outputKeys)
+	envVars = append(envVars, &codebuild.EnvironmentVariable{
+		Name:  awssdk.String("CORTEX_REMOTE_MANAGER_SCRIPT"),
+		Value: awssdk.String(base64.StdEncoding.EncodeToString([]byte(script))),
+		Type:  awssdk.String(codebuild.EnvironmentVariableTypePlaintext),
+	})
+
+	startOutput, err := awsClient.CodeBuild().StartBuild(&codebuild.StartBuildInput{
+		ProjectName:                      awssdk.String(_remoteManagerProjectName),
+		ImageOverride:                    awssdk.String(containerConfig.Image),
+		ImagePullCredentialsTypeOverride: awssdk.String(codebuild.ImagePullCredentialsTypeServiceRole),
+		EnvironmentVariablesOverride:     envVars,
+	})
+	if err != nil {
+		fmt.Print("\n\n")
+		return "", nil, errors.Wrap(err, "failed to start codebuild build for project "+_remoteManagerProjectName)
+	}
+	buildID := *startOutput.Build.Id
+	fmt.Println(" ✓")
+
+	// stop the build immediately on ctrl+c
+	c := make(chan os.Signal, 1)
+	signal.Notify(c, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(c)
+
+	routines.RunWithPanicHandler(func() {
+		<-c
+		awsClient.StopCodeBuildBuild(buildID)
+		cleanup()
+		exit.Error(ErrorDockerCtrlC())
+	}, false)
+
+	output, exitCode, err := streamRemoteManagerBuild(awsClient, buildID)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if exitCode == 0 {
+		for i, copyPath := range copyFromPaths {
+			tarReader, err := awsClient.ReadReaderFromS3(bucket, outputKeys[i])
+			if err != nil {
+				return "", nil, err
+			}
+			_, err = archive.UntarReaderToDir(tarReader, copyPath.localDir)
+			tarReader.Close()
+			if err != nil {
+				return "", nil, err
+			}
+		}
+	}
+
+	return output, &exitCode, nil
+}
+
+// prints the build's logs as they are written (excluding codebuild's own logs), and returns the manager's output and exit code once the build completes
+func streamRemoteManagerBuild(awsClient *aws.Client, buildID string) (string, int, error) {
+	var outputBuffer bytes.Buffer
+	var nextToken *string
+	var exitCode *int
+	var build *codebuild.Build
+	pollsAfterCompletion := 0
+
+	for {
+		var err error
+		build, err = awsClient.GetCodeBuildBuild(buildID)
+		if err != nil {
+			return "", 0, err
+		}
+		buildComplete := build.BuildComplete != nil && *build.BuildComplete
+
+		if build.Logs != nil && build.Logs.GroupName != nil && build.Logs.StreamName != nil {
+			events, token, err := awsClient.GetLogEventsAfter(*build.Logs.GroupName, *build.Logs.StreamName, nextToken)
+			if err != nil {
+				return "", 0, err
+			}
+			nextToken = token
+
+			for _, event := range events {
+				if event.Message == nil {
+					continue
+				}
+				for _, line := range strings.Split(strings.TrimSuffix(*event.Message, "\n"), "\n") {
+					line = strings.TrimSuffix(line, "\r")
+					if strings.HasPrefix(line, "[Container] ") {
+						continue
+					}
+					if strings.HasPrefix(line, _remoteManagerExitCodePrefix) {
+						if code, err := strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(line, _remoteManagerExitCodePrefix))); err == nil {
+							exitCode = &code
+						}
+						continue
+					}
+					fmt.Fprintln(managerOutput(), line)
+					outputBuffer.WriteString(line + "\n")
+				}
+			}
+		}
+
+		if exitCode != nil {
+			return outputBuffer.String(), *exitCode, nil
+		}
+
+		// logs can be delivered to cloudwatch after the build completes
+		if buildComplete {
+			if build.BuildStatus != nil && *build.BuildStatus != codebuild.StatusTypeSucceeded {
+				return "", 0, ErrorRemoteManagerBuildFailed(buildID, *build.BuildStatus, remoteManagerBuildFailureMessage(build), remoteManagerBuildLogsLink(build))
+			}
+			pollsAfterCompletion++
+			if pollsAfterCompletion > 15 {
+				return "", 0, ErrorRemoteManagerBuildFailed(buildID, codebuild.StatusTypeSucceeded, "the manager's exit code was not found in the build's logs", remoteManagerBuildLogsLink(build))
+			}
+		}
+
+		time.Sleep(2 * time.Second)
+	}
+}
+
+func remoteManagerBuildFailureMessage(build *codebuild.Build) string {
+	for _, phase := range build.Phases {
+		if phase.PhaseStatus == nil || *phase.PhaseStatus == codebuild.StatusTypeSucceeded {
+			continue
+		}
+		for _, phaseContext := range phase.Contexts {
+			if phaseContext.Message != nil && *phaseContext.Message != "" {
+				return *phaseContext.Message
+			}
+		}
+	}
+	return ""
+}
+
+func remoteManagerBuildLogsLink(build *codebuild.Build) string {
+	if build.Logs != nil && build.Logs.DeepLink != nil {
+		return *build.Logs.DeepLink
+	}
+	return ""
+}
+
+func remoteManagerScript(command []string, bucket string, inputKeys []string, copyFromPaths []dockerCopyFromPath, outputKeys []string) string {
+	lines := []string{
+		"set -o pipefail",
+		"code=0",
+	}
+
+	for _, key := range inputKeys {
+		lines = append(lines, fmt.Sprintf("[ $code -eq 0 ] && { aws s3 cp %s - | tar -x -C / || code=1; }", shellQuote(aws.S3Path(bucket, key))))
+	}
+
+	quotedCommand := make([]string, len(command))
+	for i, arg := range command {
+		quotedCommand[i] = shellQuote(arg)
+	}
+	lines = append(lines, fmt.Sprintf("if [ $code -eq 0 ]; then %s; code=$?; fi", strings.Join(quotedCommand, " ")))
+
+	for i, copyPath := range copyFromPaths {
+		dir, base := filepath.Dir(copyPath.containerPath), filepath.Base(copyPath.containerPath)
+		lines = append(lines, fmt.Sprintf("[ $code -eq 0 ] && { tar -c -C %s %s | aws s3 cp - %s || code=1; }", shellQuote(dir), shellQuote(base), shellQuote(aws.S3Path(bucket, outputKeys[i]))))
+	}
+
+	lines = append(lines, fmt.Sprintf("echo \"%s$code\"", _remoteManagerExitCodePrefix))
+	return strings.Join(lines, "\n") + "\n"
+}
+
+func shellQuote(str string) string {
+	return "'" + strings.ReplaceAll(str, "'", `'"'"'`) + "'"
+}
+
+func createRemoteManagerProjectIfNotFound(awsClient *aws.Client, image string) error {
+	projectExists, err := awsClient.DoesCodeBuildProjectExist(_remoteManagerProjectName)
+	if err != nil {
+		return err
+	}
+	if projectExists {
+		return nil
+	}
+
+	roleARN, err := createRemoteManagerRoleIfNotFound(awsClient)
+	if err != nil {
+		return err
+	}
+
+	input := &codebuild.CreateProjectInput{
+		Name:        awssdk.String(_remoteManagerProjectName),
+		Description: awssdk.String("runs the cortex cluster manager for `cortex cluster` commands which are run with --run-remote"),
+		Source: &codebuild.ProjectSource{
+			Type:      awssdk.String(codebuild.SourceTypeNoSource),
+			Buildspec: awssdk.String(_remoteManagerBuildspec),
+		},
+		Artifacts: &codebuild.ProjectArtifacts{
+			Type: awssdk.String(codebuild.ArtifactsTypeNoArtifacts),
+		},
+		Environment: &codebuild.ProjectEnvironment{
+			Type:                     awssdk.String(codebuild.EnvironmentTypeLinuxContainer),
+			ComputeType:              awssdk.String(codebuild.ComputeTypeBuildGeneral1Small),
+			Image:                    awssdk.String(image),
+			ImagePullCredentialsType: awssdk.String(codebuild.ImagePullCredentialsTypeServiceRole),
+		},
+		ServiceRole:      awssdk.String(roleARN),
+		TimeoutInMinutes: awssdk.Int64(_remoteManagerTimeoutMinutes),
+	}
+
+	// retry since it's possible that it takes some time for a new role to be assumable by codebuild
+	for i := 0; i < 20; i++ {
+		_, err = awsClient.CodeBuild().CreateProject(input)
+		if err == nil || aws.IsErrCode(err, codebuild.ErrCodeResourceAlreadyExistsException) {
+			return nil
+		}
+		if !aws.IsErrCode(err, codebuild.ErrCodeInvalidInputException) {
+			break
+		}
+		time.Sleep(3 * time.Second)
+	}
+
+	return errors.Wrap(err, "failed to create codebuild project "+_remoteManagerProjectName)
+}
+
+// the role only allows codebuild to write the build's logs, read the build's credentials, and pull the manager image;
+// the manager itself uses the credentials of the cli
+func createRemoteManagerRoleIfNotFound(awsClient *aws.Client) (string, error) {
+	accountID, _, err := awsClient.GetCachedAccountID()
+	if err != nil {
+		return "", err
+	}
+	partition := aws.PartitionFromRegion(awsClient.Region)
+
+	var roleARN string
+	getRoleOutput, err := awsClient.IAM().GetRole(&iam.GetRoleInput{
+		RoleName: awssdk.String(_remoteManagerRoleName),
+	})
+	if err == nil {
+		roleARN = *getRoleOutput.Role.Arn
+	} else if aws.IsNoSuchEntityErr(err) {
+		createRoleOutput, err := awsClient.IAM().CreateRole(&iam.CreateRoleInput{
+			RoleName:                 awssdk.String(_remoteManagerRoleName),
+			Description:              awssdk.String("allows codebuild to run the cortex cluster manager"),
+			AssumeRolePolicyDocument: awssdk.String(`{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Principal":{"Service":"codebuild.amazonaws.com"},"Action":"sts:AssumeRole"}]}`),
+		})
+		if err != nil && !aws.IsErrCode(err, iam.ErrCodeEntityAlreadyExistsException) {
+			return "", errors.Wrap(err, "failed to create iam role "+_remoteManagerRoleName)
+		}
+		if err == nil {
+			roleARN = *createRoleOutput.Role.Arn
+		} else {
+			roleARN = fmt.Sprintf("arn:%s:iam::%s:role/%s", partition, accountID, _remoteManagerRoleName)
+		}
+	} else {
+		return "", errors.Wrap(err, "failed to get iam role "+_remoteManagerRoleName)
+	}
+
+	policyDocument := fmt.Sprintf(`{
+  "Version": "2012-10-17",
+  "Statement": [
+    {
+      "Effect": "Allow",
+      "Action": ["logs:CreateLogGroup", "logs:CreateLogStream", "logs:PutLogEvents"],
+      "Resource": "arn:%[1]s:logs:*:%[2]s:log-group:/aws/codebuild/%[3]s*"
+    },
+    {
+      "Effect": "Allow",
+      "Action": "ssm:GetParameters",
+      "Resource": "arn:%[1]s:ssm:*:%[2]s:parameter%[4]s*"
+    },
+    {
+      "Effect": "Allow",
+      "Action": ["ecr:GetAuthorizationToken", "ecr:BatchCheckLayerAvailability", "ecr:GetDownloadUrlForLayer", "ecr:BatchGetImage"],
+      "Resource": "*"
+    }
+  ]
+}`, partition, accountID, _remoteManagerProjectName, _remoteManagerParameterPrefix)
+
+	_, err = awsClient.IAM().PutRolePolicy(&iam.PutRolePolicyInput{
+		RoleName:       awssdk.String(_remoteManagerRoleName),
+		PolicyName:     awssdk.String(_remoteManagerRoleName),
+		PolicyDocument: awssdk.String(policyDocument),
+	})
+	if err != nil {
+		return "", errors.Wrap(err, "failed to update the policy of iam role "+_remoteManagerRoleName)
+	}
+
+	return roleARN, nil
+}
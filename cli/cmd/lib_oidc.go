@@ -0,0 +1,66 @@
+/*
+Copyright 2021 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+
+	"github.com/cortexlabs/cortex/cli/cluster"
+	"github.com/cortexlabs/cortex/cli/types/cliconfig"
+	"github.com/cortexlabs/cortex/pkg/lib/console"
+)
+
+// ~/.cortex/credentials/oidc-<env_name>.json
+func oidcSessionPath(envName string) string {
+	return filepath.Join(_credentialsCacheDir, "oidc-"+envName+".json")
+}
+
+// loginOIDC logs in with the cluster's OIDC provider in the user's browser, and saves the session for the environment
+func loginOIDC(env cliconfig.Environment) error {
+	oidcConfig, err := cluster.GetOIDCConfig(env.OperatorEndpoint)
+	if err != nil {
+		return err
+	}
+
+	session, err := cluster.LoginOIDC(oidcConfig, func(url string) {
+		fmt.Printf("to log in, visit %s\n\n", console.Bold(url))
+		openBrowser(url)
+		fmt.Println("waiting for the login to complete ...")
+	})
+	if err != nil {
+		return err
+	}
+
+	return cluster.WriteOIDCSession(session, oidcSessionPath(env.Name))
+}
+
+// openBrowser makes a best effort attempt to open the url in the user's browser (the url is also printed, in case it can't be opened)
+func openBrowser(url string) {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("open", url)
+	case "windows":
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", url)
+	default:
+		cmd = exec.Command("xdg-open", url)
+	}
+	_ = cmd.Start()
+}
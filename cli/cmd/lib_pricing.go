@@ -0,0 +1,254 @@
+/*
+Copyright 2021 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/cortexlabs/cortex/pkg/lib/aws"
+	cr "github.com/cortexlabs/cortex/pkg/lib/configreader"
+	"github.com/cortexlabs/cortex/pkg/lib/console"
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	libmath "github.com/cortexlabs/cortex/pkg/lib/math"
+	"github.com/cortexlabs/cortex/pkg/lib/pointer"
+	s "github.com/cortexlabs/cortex/pkg/lib/strings"
+	"github.com/cortexlabs/cortex/pkg/lib/table"
+	"github.com/cortexlabs/cortex/pkg/operator/schema"
+	"github.com/cortexlabs/cortex/pkg/types/clusterconfig"
+)
+
+// the number of days of cost explorer data which are averaged to compute the effective instance prices
+const _costExplorerDays = 7
+
+// pricingCoverage describes the reserved instances and savings plan which cover the cluster's on-demand instances
+type pricingCoverage struct {
+	ReservedInstances []*reservedInstanceCoverage `json:"reserved_instances" yaml:"reserved_instances"`
+	SavingsPlan       *savingsPlanCoverage        `json:"savings_plan" yaml:"savings_plan"`
+}
+
+type reservedInstanceCoverage struct {
+	InstanceType string  `json:"instance_type" yaml:"instance_type"`
+	Count        int64   `json:"count" yaml:"count"`
+	Price        float64 `json:"price" yaml:"price"` // effective hourly price of each reserved instance (including the amortized upfront payment)
+}
+
+type savingsPlanCoverage struct {
+	HourlyCommitment float64 `json:"hourly_commitment" yaml:"hourly_commitment"`
+	Discount         float64 `json:"discount" yaml:"discount"` // fraction of the on-demand price which is saved by the savings plan
+}
+
+var _pricingCoverageValidation = &cr.StructValidation{
+	StructFieldValidations: []*cr.StructFieldValidation{
+		{
+			StructField: "ReservedInstances",
+			StructListValidation: &cr.StructListValidation{
+				AllowExplicitNull: true,
+				StructValidation: &cr.StructValidation{
+					StructFieldValidations: []*cr.StructFieldValidation{
+						{
+							StructField: "InstanceType",
+							StringValidation: &cr.StringValidation{
+								Required: true,
+							},
+						},
+						{
+							StructField: "Count",
+							Int64Validation: &cr.Int64Validation{
+								Required:    true,
+								GreaterThan: pointer.Int64(0),
+							},
+						},
+						{
+							StructField: "Price",
+							Float64Validation: &cr.Float64Validation{
+								Required:             true,
+								GreaterThanOrEqualTo: pointer.Float64(0),
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			StructField: "SavingsPlan",
+			StructValidation: &cr.StructValidation{
+				DefaultNil:        true,
+				AllowExplicitNull: true,
+				StructFieldValidations: []*cr.StructFieldValidation{
+					{
+						StructField: "HourlyCommitment",
+						Float64Validation: &cr.Float64Validation{
+							Required:    true,
+							GreaterThan: pointer.Float64(0),
+						},
+					},
+					{
+						StructField: "Discount",
+						Float64Validation: &cr.Float64Validation{
+							Required:    true,
+							GreaterThan: pointer.Float64(0),
+							LessThan:    pointer.Float64(1),
+						},
+					},
+				},
+			},
+		},
+	},
+}
+
+// instanceTypeCost is the hourly cost of the on-demand instances of an instance type which are running in the cluster
+type instanceTypeCost struct {
+	InstanceType  string  `json:"instance_type"`
+	Count         int64   `json:"count"`
+	OnDemandCost  float64 `json:"on_demand_cost"`
+	EffectiveCost float64 `json:"effective_cost"`
+}
+
+func readPricingCoverage(path string) (*pricingCoverage, error) {
+	coverage := &pricingCoverage{}
+	errs := cr.ParseYAMLFile(coverage, _pricingCoverageValidation, path)
+	if errors.HasError(errs) {
+		return nil, errors.FirstError(errs...)
+	}
+	return coverage, nil
+}
+
+// getEffectiveInstanceCosts returns the effective cost of the cluster's on-demand instances (including the cortex system instances), based on the --pricing-coverage file or on cost explorer;
+// nil is returned if neither was requested
+func getEffectiveInstanceCosts(awsClient *aws.Client, infoResponse *schema.InfoResponse, clusterConfig clusterconfig.Config) ([]*instanceTypeCost, error) {
+	if _flagClusterInfoPricingCoverage == "" && !_flagClusterInfoCostExplorer {
+		return nil, nil
+	}
+
+	costs := onDemandInstanceCosts(infoResponse, clusterConfig)
+
+	if _flagClusterInfoPricingCoverage != "" {
+		coverage, err := readPricingCoverage(_flagClusterInfoPricingCoverage)
+		if err != nil {
+			return nil, err
+		}
+		coverage.apply(costs)
+		return costs, nil
+	}
+
+	prices, err := awsClient.GetEffectiveInstancePrices(clusterConfig.Region, _costExplorerDays)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to read the effective instance prices from cost explorer")
+	}
+	for _, cost := range costs {
+		if price, ok := prices[cost.InstanceType]; ok {
+			cost.EffectiveCost = price * float64(cost.Count)
+		}
+	}
+	return costs, nil
+}
+
+// onDemandInstanceCosts returns the on-demand cost of the cluster's on-demand instances by instance type (the effective cost is initialized to the on-demand cost)
+func onDemandInstanceCosts(infoResponse *schema.InfoResponse, clusterConfig clusterconfig.Config) []*instanceTypeCost {
+	costsByType := map[string]*instanceTypeCost{
+		"t3.medium": {
+			InstanceType: "t3.medium",
+			Count:        2, // cortex system instances
+			OnDemandCost: 2 * aws.InstanceMetadatas[clusterConfig.Region]["t3.medium"].Price,
+		},
+	}
+
+	for _, nodeInfo := range infoResponse.NodeInfos {
+		if nodeInfo.IsSpot {
+			continue
+		}
+		cost, ok := costsByType[nodeInfo.InstanceType]
+		if !ok {
+			cost = &instanceTypeCost{InstanceType: nodeInfo.InstanceType}
+			costsByType[nodeInfo.InstanceType] = cost
+		}
+		cost.Count++
+		cost.OnDemandCost += nodeInfo.Price
+	}
+
+	costs := make([]*instanceTypeCost, 0, len(costsByType))
+	for _, cost := range costsByType {
+		cost.EffectiveCost = cost.OnDemandCost
+		costs = append(costs, cost)
+	}
+	sort.Slice(costs, func(i, j int) bool {
+		return costs[i].InstanceType < costs[j].InstanceType
+	})
+	return costs
+}
+
+// apply applies the reserved instances to the instances of their instance type, and then applies the savings plan to the remaining on-demand cost
+// (the savings plan's commitment covers its discounted rate until it is used up, and the rest is charged at the on-demand price)
+func (coverage *pricingCoverage) apply(costs []*instanceTypeCost) {
+	uncoveredCosts := make(map[string]float64, len(costs))
+	var totalUncoveredCost float64
+
+	for _, cost := range costs {
+		var reservedCount int64
+		var reservedCost float64
+		for _, ri := range coverage.ReservedInstances {
+			if ri.InstanceType != cost.InstanceType || reservedCount >= cost.Count {
+				continue
+			}
+			numCovered := libmath.MinInt64(ri.Count, cost.Count-reservedCount)
+			reservedCount += numCovered
+			reservedCost += float64(numCovered) * ri.Price
+		}
+
+		uncoveredCost := cost.OnDemandCost * float64(cost.Count-reservedCount) / float64(cost.Count)
+		uncoveredCosts[cost.InstanceType] = uncoveredCost
+		totalUncoveredCost += uncoveredCost
+		cost.EffectiveCost = reservedCost + uncoveredCost
+	}
+
+	if coverage.SavingsPlan == nil || totalUncoveredCost == 0 {
+		return
+	}
+
+	// the fraction of the uncovered on-demand cost which the savings plan's commitment covers
+	coveredFraction := libmath.MinFloat64(1, coverage.SavingsPlan.HourlyCommitment/(1-coverage.SavingsPlan.Discount)/totalUncoveredCost)
+	for _, cost := range costs {
+		cost.EffectiveCost -= uncoveredCosts[cost.InstanceType] * coveredFraction * coverage.SavingsPlan.Discount
+	}
+}
+
+func printInfoEffectivePricing(costs []*instanceTypeCost, totalPrice float64) {
+	if costs == nil {
+		return
+	}
+
+	var totalOnDemandCost, totalEffectiveCost float64
+	var rows [][]interface{}
+	for _, cost := range costs {
+		totalOnDemandCost += cost.OnDemandCost
+		totalEffectiveCost += cost.EffectiveCost
+		rows = append(rows, []interface{}{fmt.Sprintf("%d %s on-demand %s", cost.Count, cost.InstanceType, s.PluralS("instance", cost.Count)), s.DollarsAndTenthsOfCents(cost.OnDemandCost), s.DollarsAndTenthsOfCents(cost.EffectiveCost)})
+	}
+
+	fmt.Printf(console.Bold("\nwith your reserved instances and savings plans, your cluster effectively costs %s per hour\n\n"), s.DollarsAndCents(totalPrice-totalOnDemandCost+totalEffectiveCost))
+
+	t := table.Table{
+		Headers: []table.Header{
+			{Title: "aws resource"},
+			{Title: "on-demand cost per hour"},
+			{Title: "effective cost per hour"},
+		},
+		Rows: rows,
+	}
+	t.MustPrint(&table.Opts{Sort: pointer.Bool(false)})
+}
@@ -23,6 +23,7 @@ import (
 
 	"github.com/cortexlabs/cortex/cli/types/cliconfig"
 	"github.com/cortexlabs/cortex/pkg/lib/console"
+	"github.com/cortexlabs/cortex/pkg/lib/pointer"
 	s "github.com/cortexlabs/cortex/pkg/lib/strings"
 	"github.com/cortexlabs/cortex/pkg/lib/table"
 	libtime "github.com/cortexlabs/cortex/pkg/lib/time"
@@ -45,8 +46,19 @@ func realtimeAPITable(realtimeAPI schema.APIResponse, env cliconfig.Environment)
 
 	out += "\n" + console.Bold("endpoint: ") + realtimeAPI.Endpoint + "\n"
 
+	out += modelVersionsStr(realtimeAPI.Spec.API)
+	out += integrityStr(realtimeAPI.Spec.API, realtimeAPI.Verification)
+
+	if realtimeAPI.Metrics != nil && realtimeAPI.Metrics.SLO != nil {
+		out += titleStr("slo (window: "+realtimeAPI.Metrics.SLO.Window+")") + sloTable(realtimeAPI.Metrics.SLO)
+	}
+
 	out += "\n" + apiHistoryTable(realtimeAPI.APIVersions)
 
+	out += replicaFailuresStr(realtimeAPI.ReplicaFailures)
+
+	out += apiEventsTable(realtimeAPI.Events)
+
 	if !_flagVerbose {
 		return out, nil
 	}
@@ -109,6 +121,72 @@ func realtimeAPIsTable(realtimeAPIs []schema.APIResponse, envNames []string) tab
 	}
 }
 
+func sloTable(sloStatus *metrics.SLOStatus) string {
+	t := table.Table{
+		Headers: []table.Header{
+			{Title: "objective"},
+			{Title: "target"},
+			{Title: "attained"},
+			{Title: "burn rate (1h)", Hidden: sloStatus.Availability == nil},
+			{Title: "budget remaining", Hidden: sloStatus.Availability == nil},
+		},
+	}
+
+	if sloStatus.Availability != nil {
+		availability := sloStatus.Availability
+		t.Rows = append(t.Rows, []interface{}{
+			"availability",
+			s.Round(availability.Target, 3, 0) + "%",
+			percentagePtrStr(availability.Attained),
+			float64PtrStr(availability.BurnRate),
+			budgetRemainingStr(availability.RemainingBudget),
+		})
+	}
+
+	for _, latency := range sloStatus.Latency {
+		attained := "-"
+		if latency.Attained != nil {
+			attained = s.Round(*latency.Attained, 2, 0) + " ms"
+			if !latency.IsMet() {
+				attained += " (missed)"
+			}
+		}
+		t.Rows = append(t.Rows, []interface{}{
+			"latency p" + s.Round(latency.Percentile, 3, 0),
+			"<= " + s.Round(latency.Threshold, 2, 0) + " ms",
+			attained,
+			"-",
+			"-",
+		})
+	}
+
+	return t.MustFormat(&table.Opts{Sort: pointer.Bool(false)})
+}
+
+func percentagePtrStr(val *float64) string {
+	if val == nil {
+		return "-"
+	}
+	return s.Round(*val, 3, 0) + "%"
+}
+
+func float64PtrStr(val *float64) string {
+	if val == nil {
+		return "-"
+	}
+	return s.Round(*val, 2, 0)
+}
+
+func budgetRemainingStr(remainingBudget *float64) string {
+	if remainingBudget == nil {
+		return "-"
+	}
+	if *remainingBudget < 0 {
+		return "0% (exhausted)"
+	}
+	return s.Round(*remainingBudget*100, 1, 0) + "%"
+}
+
 func latencyStr(metrics *metrics.Metrics) string {
 	if metrics.NetworkStats == nil || metrics.NetworkStats.Latency == nil {
 		return "-"
@@ -116,8 +116,15 @@ func taskAPITable(taskAPI schema.APIResponse) string {
 
 	out += "\n" + console.Bold("endpoint: ") + taskAPI.Endpoint + "\n"
 
+	out += modelVersionsStr(taskAPI.Spec.API)
+	out += integrityStr(taskAPI.Spec.API, taskAPI.Verification)
+
 	out += "\n" + apiHistoryTable(taskAPI.APIVersions)
 
+	out += replicaFailuresStr(taskAPI.ReplicaFailures)
+
+	out += apiEventsTable(taskAPI.Events)
+
 	if !_flagVerbose {
 		return out
 	}
@@ -17,6 +17,7 @@ limitations under the License.
 package cmd
 
 import (
+	"fmt"
 	"strings"
 	"time"
 
@@ -27,12 +28,14 @@ import (
 	"github.com/cortexlabs/cortex/pkg/lib/table"
 	libtime "github.com/cortexlabs/cortex/pkg/lib/time"
 	"github.com/cortexlabs/cortex/pkg/operator/schema"
+	"github.com/cortexlabs/cortex/pkg/types/metrics"
 )
 
 const (
 	_titleTrafficSplitter   = "traffic splitter"
 	_trafficSplitterWeights = "weights"
 	_titleAPIs              = "apis"
+	_titleTraffic           = "traffic"
 )
 
 func trafficSplitterTable(trafficSplitter schema.APIResponse, env cliconfig.Environment) (string, error) {
@@ -65,6 +68,16 @@ func trafficSplitterTable(trafficSplitter schema.APIResponse, env cliconfig.Envi
 func trafficSplitTable(trafficSplitter schema.APIResponse, env cliconfig.Environment) (table.Table, error) {
 	rows := make([][]interface{}, 0, len(trafficSplitter.Spec.APIs))
 
+	// the metrics of the requests which were routed by the traffic splitter (rather than sent to the apis directly)
+	variantMetrics := map[string]*metrics.Metrics{}
+	totalVariantRequests := 0
+	for i := range trafficSplitter.VariantMetrics {
+		variantMetrics[trafficSplitter.VariantMetrics[i].APIName] = &trafficSplitter.VariantMetrics[i]
+		if trafficSplitter.VariantMetrics[i].NetworkStats != nil {
+			totalVariantRequests += trafficSplitter.VariantMetrics[i].NetworkStats.Total
+		}
+	}
+
 	for _, api := range trafficSplitter.Spec.APIs {
 		apisRes, err := cluster.GetAPI(MustGetOperatorConfig(env.Name), api.Name)
 		if err != nil {
@@ -78,16 +91,27 @@ func trafficSplitTable(trafficSplitter schema.APIResponse, env cliconfig.Environ
 		if api.Shadow {
 			apiName += " (shadow)"
 		}
+
+		apiMetrics := apiRes.Metrics
+		trafficStr := "-"
+		if variantMetric, ok := variantMetrics[api.Name]; ok {
+			apiMetrics = variantMetric
+			if totalVariantRequests > 0 && variantMetric.NetworkStats != nil {
+				trafficStr = fmt.Sprintf("%.1f%%", 100*float64(variantMetric.NetworkStats.Total)/float64(totalVariantRequests))
+			}
+		}
+
 		rows = append(rows, []interface{}{
 			env.Name,
 			apiName,
 			api.Weight,
+			trafficStr,
 			apiRes.Status.Message(),
 			apiRes.Status.Requested,
 			libtime.SinceStr(&lastUpdated),
-			latencyStr(apiRes.Metrics),
-			code2XXStr(apiRes.Metrics),
-			code5XXStr(apiRes.Metrics),
+			latencyStr(apiMetrics),
+			code2XXStr(apiMetrics),
+			code5XXStr(apiMetrics),
 		})
 	}
 
@@ -96,6 +120,7 @@ func trafficSplitTable(trafficSplitter schema.APIResponse, env cliconfig.Environ
 			{Title: _titleEnvironment},
 			{Title: _titleAPIs},
 			{Title: _trafficSplitterWeights},
+			{Title: _titleTraffic, Hidden: len(trafficSplitter.VariantMetrics) == 0},
 			{Title: _titleStatus},
 			{Title: _titleRequested},
 			{Title: _titleLastupdated},
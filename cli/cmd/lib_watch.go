@@ -30,22 +30,31 @@ import (
 )
 
 func getTerminalWidth() int {
+	_, width := getTerminalSize()
+	return width
+}
+
+// returns the height and width of the terminal (or 0, 0 if they can't be determined)
+func getTerminalSize() (int, int) {
 	cmd := exec.Command("stty", "size")
 	cmd.Stdin = os.Stdin
 	out, err := cmd.Output()
 	if err != nil {
-		return 0
+		return 0, 0
 	}
 	dimensions := strings.Split(strings.TrimSpace(string(out)), " ")
 	if len(dimensions) != 2 {
-		return 0
+		return 0, 0
 	}
-	widthStr := dimensions[1]
-	width, ok := s.ParseInt(widthStr)
+	height, ok := s.ParseInt(dimensions[0])
 	if !ok {
-		return 0
+		return 0, 0
 	}
-	return width
+	width, ok := s.ParseInt(dimensions[1])
+	if !ok {
+		return 0, 0
+	}
+	return height, width
 }
 
 func watchHeader() string {
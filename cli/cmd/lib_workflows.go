@@ -0,0 +1,153 @@
+/*
+Copyright 2021 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"strings"
+	"time"
+
+	"github.com/cortexlabs/cortex/pkg/lib/console"
+	s "github.com/cortexlabs/cortex/pkg/lib/strings"
+	"github.com/cortexlabs/cortex/pkg/lib/table"
+	libtime "github.com/cortexlabs/cortex/pkg/lib/time"
+	"github.com/cortexlabs/cortex/pkg/operator/schema"
+	"github.com/cortexlabs/cortex/pkg/types/status"
+)
+
+const (
+	_titleWorkflow = "workflow"
+	_titleSteps    = "steps"
+)
+
+func workflowsTable(workflows []schema.APIResponse, envNames []string) table.Table {
+	rows := make([][]interface{}, 0, len(workflows))
+	for i, workflow := range workflows {
+		lastUpdated := time.Unix(workflow.Spec.LastUpdated, 0)
+		var steps []string
+		for _, step := range workflow.Spec.Steps {
+			steps = append(steps, step.Name)
+		}
+		rows = append(rows, []interface{}{
+			envNames[i],
+			workflow.Spec.Name,
+			s.TruncateEllipses(strings.Join(steps, " "), 50),
+			libtime.SinceStr(&lastUpdated),
+		})
+	}
+
+	return table.Table{
+		Headers: []table.Header{
+			{Title: _titleEnvironment},
+			{Title: _titleWorkflow},
+			{Title: _titleSteps},
+			{Title: _titleLastupdated},
+		},
+		Rows: rows,
+	}
+}
+
+func workflowTable(workflow schema.APIResponse) string {
+	out := ""
+
+	if len(workflow.WorkflowRuns) == 0 {
+		out = console.Bold("no submitted runs\n")
+	} else {
+		runRows := make([][]interface{}, 0, len(workflow.WorkflowRuns))
+		for _, run := range workflow.WorkflowRuns {
+			runRows = append(runRows, []interface{}{
+				run.ID,
+				run.Status.Message(),
+				run.StartTime.Format(_timeFormat),
+				workflowDurationStr(&run.StartTime, run.EndTime),
+			})
+		}
+
+		t := table.Table{
+			Headers: []table.Header{
+				{Title: "run id"},
+				{Title: "status"},
+				{Title: "start time"},
+				{Title: "duration"},
+			},
+			Rows: runRows,
+		}
+		out += t.MustFormat()
+
+		// runs are sorted from most to least recent
+		latestRun := workflow.WorkflowRuns[0]
+		out += titleStr("steps of run " + latestRun.ID)
+		stepsTable := workflowStepsTable(latestRun.Steps)
+		out += stepsTable.MustFormat()
+	}
+
+	out += "\n" + console.Bold("endpoint: ") + workflow.Endpoint + "\n"
+
+	out += "\n" + apiHistoryTable(workflow.APIVersions)
+
+	if !_flagVerbose {
+		return out
+	}
+
+	out += titleStr("configuration") + strings.TrimSpace(workflow.Spec.UserStr())
+
+	return out
+}
+
+func workflowStepsTable(steps []status.WorkflowStepStatus) table.Table {
+	rows := make([][]interface{}, 0, len(steps))
+	for _, step := range steps {
+		jobID := "-"
+		if step.JobID != "" {
+			jobID = step.JobID
+		}
+		dependsOn := "-"
+		if len(step.DependsOn) > 0 {
+			dependsOn = strings.Join(step.DependsOn, " ")
+		}
+		rows = append(rows, []interface{}{
+			step.Name,
+			step.API,
+			dependsOn,
+			step.Status.Message(),
+			jobID,
+			workflowDurationStr(step.StartTime, step.EndTime),
+		})
+	}
+
+	return table.Table{
+		Headers: []table.Header{
+			{Title: "step"},
+			{Title: "api"},
+			{Title: "depends on"},
+			{Title: "status"},
+			{Title: "task job id"},
+			{Title: "duration"},
+		},
+		Rows: rows,
+	}
+}
+
+func workflowDurationStr(startTime *time.Time, endTime *time.Time) string {
+	if startTime == nil {
+		return "-"
+	}
+	end := time.Now()
+	if endTime != nil {
+		end = *endTime
+	}
+	return end.Sub(*startTime).Truncate(time.Second).String()
+}
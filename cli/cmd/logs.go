@@ -18,10 +18,15 @@ package cmd
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/cortexlabs/cortex/cli/cluster"
+	"github.com/cortexlabs/cortex/cli/types/flags"
+	"github.com/cortexlabs/cortex/pkg/lib/console"
 	"github.com/cortexlabs/cortex/pkg/lib/exit"
+	libjson "github.com/cortexlabs/cortex/pkg/lib/json"
 	"github.com/cortexlabs/cortex/pkg/lib/telemetry"
+	"github.com/cortexlabs/cortex/pkg/operator/schema"
 	"github.com/spf13/cobra"
 )
 
@@ -29,6 +34,9 @@ var (
 	_flagLogsEnv            string
 	_flagLogsDisallowPrompt bool
 	_flagRandomPod          bool
+	_flagLogsJob            string
+	_flagLogsRequest        string
+	_flagLogsSince          string
 	_logsOutput             = `Navigate to the link below and click "Run Query":
 
 %s
@@ -42,12 +50,17 @@ func logsInit() {
 	_logsCmd.Flags().StringVarP(&_flagLogsEnv, "env", "e", "", "environment to use")
 	_logsCmd.Flags().BoolVarP(&_flagLogsDisallowPrompt, "yes", "y", false, "skip prompts")
 	_logsCmd.Flags().BoolVarP(&_flagRandomPod, "random-pod", "", false, "stream logs from a random pod")
+	_logsCmd.Flags().StringVar(&_flagLogsJob, "job", "", "print the log lines of a job of a BatchAPI or TaskAPI")
+	_logsCmd.Flags().StringVar(&_flagLogsRequest, "request", "", "print the log lines of a request to a RealtimeAPI or AsyncAPI")
+	_logsCmd.Flags().StringVar(&_flagLogsSince, "since", "24h", "only search for the request's log lines within this duration (e.g. 1h)")
+	_logsCmd.Flags().VarP(&_flagOutput, "output", "o", fmt.Sprintf("output format for --job and --request: one of %s", strings.Join(flags.UserOutputTypeStrings(), "|")))
 }
 
 var _logsCmd = &cobra.Command{
-	Use:   "logs API_NAME [JOB_ID]",
-	Short: "get the logs for a workload",
-	Args:  cobra.RangeArgs(1, 2),
+	Use:               "logs API_NAME [JOB_ID]",
+	Short:             "get the logs for a workload",
+	Args:              cobra.RangeArgs(1, 2),
+	ValidArgsFunction: completeAPINameAndJobIDArgs,
 	Run: func(cmd *cobra.Command, args []string) {
 		envName, err := getEnvFromFlag(_flagLogsEnv)
 		if err != nil {
@@ -60,7 +73,7 @@ var _logsCmd = &cobra.Command{
 			telemetry.Event("cli.logs")
 			exit.Error(err)
 		}
-		telemetry.Event("cli.logs", map[string]interface{}{"env_name": env.Name, "random_pod": _flagRandomPod})
+		telemetry.Event("cli.logs", map[string]interface{}{"env_name": env.Name, "random_pod": _flagRandomPod, "job": _flagLogsJob != "", "request": _flagLogsRequest != ""})
 
 		err = printEnvIfNotSpecified(env.Name, cmd)
 		if err != nil {
@@ -70,6 +83,33 @@ var _logsCmd = &cobra.Command{
 		operatorConfig := MustGetOperatorConfig(env.Name)
 		apiName := args[0]
 
+		if _flagLogsJob != "" || _flagLogsRequest != "" {
+			if _flagLogsJob != "" && _flagLogsRequest != "" {
+				exit.Error(ErrorMutuallyExclusiveFlags("--job", "--request"))
+			}
+			if _flagRandomPod {
+				exit.Error(ErrorMutuallyExclusiveFlags("--random-pod", "--job/--request"))
+			}
+			if len(args) == 2 {
+				exit.Error(ErrorMutuallyExclusiveFlags("JOB_ID", "--job/--request"))
+			}
+
+			queryParams := map[string]string{}
+			if _flagLogsJob != "" {
+				queryParams["jobID"] = _flagLogsJob
+			} else {
+				queryParams["requestID"] = _flagLogsRequest
+				queryParams["since"] = _flagLogsSince
+			}
+
+			logLinesResponse, err := cluster.GetLogLines(operatorConfig, apiName, queryParams)
+			if err != nil {
+				exit.Error(err)
+			}
+			printLogLines(logLinesResponse)
+			return
+		}
+
 		if len(args) == 1 {
 			if _flagRandomPod {
 				err := cluster.StreamLogs(operatorConfig, apiName)
@@ -103,3 +143,27 @@ var _logsCmd = &cobra.Command{
 		fmt.Printf(_logsOutput, logResponse.LogURL)
 	},
 }
+
+func printLogLines(logLinesResponse schema.LogLinesResponse) {
+	if _flagOutput == flags.JSONOutputType {
+		bytes, err := libjson.Marshal(logLinesResponse)
+		if err != nil {
+			exit.Error(err)
+		}
+		fmt.Print(string(bytes))
+		return
+	}
+
+	if len(logLinesResponse.Lines) == 0 {
+		fmt.Println(console.Bold("no log lines found") + " (there may be 1-2 minutes of delay for the logs to show up in CloudWatch)")
+		return
+	}
+
+	for _, logLine := range logLinesResponse.Lines {
+		fmt.Println(console.Bold(logLine.Timestamp) + " " + strings.TrimRight(logLine.Message, "\n"))
+	}
+
+	if logLinesResponse.Truncated {
+		fmt.Println("\n" + console.Bold(fmt.Sprintf("only the first %d log lines are shown", len(logLinesResponse.Lines))))
+	}
+}
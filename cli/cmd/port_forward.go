@@ -0,0 +1,110 @@
+/*
+Copyright 2021 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+
+	"github.com/cortexlabs/cortex/cli/cluster"
+	"github.com/cortexlabs/cortex/pkg/lib/exit"
+	s "github.com/cortexlabs/cortex/pkg/lib/strings"
+	"github.com/cortexlabs/cortex/pkg/lib/telemetry"
+	"github.com/spf13/cobra"
+)
+
+var (
+	_flagPortForwardEnv     string
+	_flagPortForwardReplica string
+)
+
+func portForwardInit() {
+	_portForwardCmd.Flags().SortFlags = false
+	_portForwardCmd.Flags().StringVarP(&_flagPortForwardEnv, "env", "e", "", "environment to use")
+	_portForwardCmd.Flags().StringVar(&_flagPortForwardReplica, "replica", "", "name of the replica to forward to (defaults to the first running replica)")
+}
+
+var _portForwardCmd = &cobra.Command{
+	Use:               "port-forward API_NAME [LOCAL_PORT:]REMOTE_PORT",
+	Short:             "forward a local port to a port of a replica of an api",
+	Args:              cobra.ExactArgs(2),
+	ValidArgsFunction: completeAPINameArg,
+	Run: func(cmd *cobra.Command, args []string) {
+		envName, err := getEnvFromFlag(_flagPortForwardEnv)
+		if err != nil {
+			telemetry.Event("cli.port-forward")
+			exit.Error(err)
+		}
+
+		env, err := ReadOrConfigureEnv(envName)
+		if err != nil {
+			telemetry.Event("cli.port-forward")
+			exit.Error(err)
+		}
+		telemetry.Event("cli.port-forward", map[string]interface{}{"env_name": env.Name})
+
+		err = printEnvIfNotSpecified(env.Name, cmd)
+		if err != nil {
+			exit.Error(err)
+		}
+
+		apiName := args[0]
+		localPort, remotePort, err := parsePortMapping(args[1])
+		if err != nil {
+			exit.Error(err)
+		}
+
+		interrupt := make(chan os.Signal, 1)
+		signal.Notify(interrupt, os.Interrupt)
+		stop := make(chan struct{})
+		go func() {
+			<-interrupt
+			close(stop)
+		}()
+
+		fmt.Printf("forwarding 127.0.0.1:%d to port %d of %s (press ctrl+c to stop)\n", localPort, remotePort, apiName)
+
+		err = cluster.PortForward(MustGetOperatorConfig(env.Name), apiName, _flagPortForwardReplica, localPort, remotePort, stop)
+		if err != nil {
+			exit.Error(err)
+		}
+	},
+}
+
+// parses LOCAL_PORT:REMOTE_PORT, or PORT (which is used as both the local and the remote port)
+func parsePortMapping(mapping string) (int, int, error) {
+	split := strings.Split(mapping, ":")
+	if len(split) > 2 {
+		return 0, 0, ErrorInvalidPortMapping(mapping)
+	}
+
+	var ports []int
+	for _, portStr := range split {
+		port, ok := s.ParseInt(portStr)
+		if !ok || port <= 0 || port > 65535 {
+			return 0, 0, ErrorInvalidPortMapping(mapping)
+		}
+		ports = append(ports, port)
+	}
+
+	if len(ports) == 1 {
+		return ports[0], ports[0], nil
+	}
+	return ports[0], ports[1], nil
+}
@@ -0,0 +1,106 @@
+/*
+Copyright 2021 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/cortexlabs/cortex/cli/types/flags"
+	"github.com/cortexlabs/cortex/pkg/lib/exit"
+	"github.com/cortexlabs/cortex/pkg/lib/telemetry"
+	libtime "github.com/cortexlabs/cortex/pkg/lib/time"
+	"github.com/cortexlabs/cortex/pkg/operator/schema"
+	"github.com/spf13/cobra"
+	klabels "k8s.io/apimachinery/pkg/labels"
+)
+
+var (
+	_flagPruneEnv       string
+	_flagPruneOlderThan string
+	_flagPruneSelector  string
+	_flagPruneProject   string
+	_flagPruneDryRun    bool
+	_flagPruneForce     bool
+	_flagPruneKeepCache bool
+)
+
+func pruneInit() {
+	_pruneCmd.Flags().SortFlags = false
+	_pruneCmd.Flags().StringVarP(&_flagPruneEnv, "env", "e", "", "environment to use")
+	_pruneCmd.Flags().StringVar(&_flagPruneOlderThan, "older-than", "", "delete the apis which haven't been updated for this duration (e.g. 30d or 12h)")
+	_pruneCmd.MarkFlagRequired("older-than")
+	_pruneCmd.Flags().StringVarP(&_flagPruneSelector, "selector", "l", "", "only delete the apis whose labels match a label selector (e.g. stage=dev)")
+	_pruneCmd.Flags().StringVar(&_flagPruneProject, "project", "", "only delete the apis which belong to a project")
+	_pruneCmd.Flags().BoolVar(&_flagPruneDryRun, "dry-run", false, "list the apis which would be deleted without deleting them")
+	_pruneCmd.Flags().BoolVarP(&_flagPruneForce, "force", "f", false, "delete the apis without confirmation")
+	_pruneCmd.Flags().BoolVarP(&_flagPruneKeepCache, "keep-cache", "c", false, "keep cached data for the apis")
+	_pruneCmd.Flags().VarP(&_flagOutput, "output", "o", fmt.Sprintf("output format: one of %s", strings.Join(flags.UserOutputTypeStrings(), "|")))
+}
+
+var _pruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "delete the apis which haven't been updated recently",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		envName, err := getEnvFromFlag(_flagPruneEnv)
+		if err != nil {
+			telemetry.Event("cli.prune")
+			exit.Error(err)
+		}
+
+		env, err := ReadOrConfigureEnv(envName)
+		if err != nil {
+			telemetry.Event("cli.prune")
+			exit.Error(err)
+		}
+		telemetry.Event("cli.prune", map[string]interface{}{"env_name": env.Name})
+
+		olderThan, err := libtime.ParseDuration(_flagPruneOlderThan)
+		if err != nil || olderThan <= 0 {
+			exit.Error(ErrorInvalidOlderThan(_flagPruneOlderThan))
+		}
+
+		if _flagPruneSelector != "" {
+			if _, err := klabels.Parse(_flagPruneSelector); err != nil {
+				exit.Error(ErrorInvalidSelector(_flagPruneSelector, err))
+			}
+		}
+
+		err = printEnvIfNotSpecified(env.Name, cmd)
+		if err != nil {
+			exit.Error(err)
+		}
+
+		operatorConfig := MustGetOperatorConfig(env.Name)
+		apisRes, err := getSelectedAPIs(operatorConfig, _flagPruneSelector, _flagPruneProject)
+		if err != nil {
+			exit.Error(err)
+		}
+
+		staleAPIs := []schema.APIResponse{}
+		for _, api := range apisRes {
+			if time.Since(time.Unix(api.Spec.LastUpdated, 0)) > olderThan {
+				staleAPIs = append(staleAPIs, api)
+			}
+		}
+
+		noAPIsMessage := fmt.Sprintf("no apis have gone without an update for %s", _flagPruneOlderThan)
+		deleteAPIs(operatorConfig, staleAPIs, noAPIsMessage, _flagPruneKeepCache, _flagPruneForce, _flagPruneDryRun)
+	},
+}
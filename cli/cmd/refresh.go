@@ -42,9 +42,10 @@ func refreshInit() {
 }
 
 var _refreshCmd = &cobra.Command{
-	Use:   "refresh API_NAME",
-	Short: "restart all replicas for an api (without downtime)",
-	Args:  cobra.ExactArgs(1),
+	Use:               "refresh API_NAME",
+	Short:             "restart all replicas for an api (without downtime)",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeAPINameArg,
 	Run: func(cmd *cobra.Command, args []string) {
 		envName, err := getEnvFromFlag(_flagRefreshEnv)
 		if err != nil {
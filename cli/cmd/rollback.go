@@ -0,0 +1,100 @@
+/*
+Copyright 2021 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/cortexlabs/cortex/cli/cluster"
+	"github.com/cortexlabs/cortex/cli/types/flags"
+	"github.com/cortexlabs/cortex/pkg/lib/exit"
+	libjson "github.com/cortexlabs/cortex/pkg/lib/json"
+	"github.com/cortexlabs/cortex/pkg/lib/print"
+	"github.com/cortexlabs/cortex/pkg/lib/telemetry"
+	"github.com/spf13/cobra"
+)
+
+var (
+	_flagRollbackEnv   string
+	_flagRollbackTo    int
+	_flagRollbackForce bool
+)
+
+func rollbackInit() {
+	_rollbackCmd.Flags().SortFlags = false
+	_rollbackCmd.Flags().StringVarP(&_flagRollbackEnv, "env", "e", "", "environment to use")
+	_rollbackCmd.Flags().IntVar(&_flagRollbackTo, "to", 0, "revision to redeploy (defaults to the revision before the latest one; see `cortex get API_NAME --history`)")
+	_rollbackCmd.Flags().BoolVarP(&_flagRollbackForce, "force", "f", false, "override the in-progress api update")
+	_rollbackCmd.Flags().VarP(&_flagOutput, "output", "o", fmt.Sprintf("output format: one of %s", strings.Join(flags.UserOutputTypeStrings(), "|")))
+}
+
+var _rollbackCmd = &cobra.Command{
+	Use:               "rollback API_NAME",
+	Short:             "redeploy a previous revision of an api",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeAPINameArg,
+	Run: func(cmd *cobra.Command, args []string) {
+		envName, err := getEnvFromFlag(_flagRollbackEnv)
+		if err != nil {
+			telemetry.Event("cli.rollback")
+			exit.Error(err)
+		}
+
+		env, err := ReadOrConfigureEnv(envName)
+		if err != nil {
+			telemetry.Event("cli.rollback")
+			exit.Error(err)
+		}
+		telemetry.Event("cli.rollback", map[string]interface{}{"env_name": env.Name})
+
+		err = printEnvIfNotSpecified(env.Name, cmd)
+		if err != nil {
+			exit.Error(err)
+		}
+
+		if _flagRollbackTo < 0 {
+			exit.Error(ErrorInvalidRevision(_flagRollbackTo))
+		}
+
+		rollbackResponse, err := cluster.Rollback(MustGetOperatorConfig(env.Name), args[0], _flagRollbackTo, _flagRollbackForce)
+		if err != nil {
+			exit.Error(err)
+		}
+
+		result := rollbackResponse.Result
+
+		switch _flagOutput {
+		case flags.JSONOutputType:
+			bytes, err := libjson.Marshal(rollbackResponse)
+			if err != nil {
+				exit.Error(err)
+			}
+			fmt.Print(string(bytes))
+		case flags.PrettyOutputType:
+			if result.Error != "" {
+				print.StderrBoldFirstBlock(result.Error)
+			} else {
+				print.BoldFirstLine(fmt.Sprintf("%s (revision %d)", result.Message, rollbackResponse.Revision))
+			}
+		}
+
+		if result.Error != "" {
+			exit.Error(nil)
+		}
+	},
+}
@@ -41,6 +41,7 @@ var (
 	_flagOutput     = flags.PrettyOutputType
 
 	_credentialsCacheDir string
+	_responseCacheDir    string
 	_localDir            string
 	_cliConfigPath       string
 	_clientIDPath        string
@@ -51,6 +52,8 @@ var (
 )
 
 func init() {
+	exit.SetErrorHandlers(printError, errorExitCode)
+
 	cwd, err := os.Getwd()
 	if err != nil {
 		err := errors.Wrap(err, "unable to determine current working directory")
@@ -86,6 +89,14 @@ func init() {
 		exit.Error(err)
 	}
 
+	// ~/.cortex/responses/
+	_responseCacheDir = filepath.Join(_localDir, "responses")
+	err = os.MkdirAll(_responseCacheDir, os.ModePerm)
+	if err != nil {
+		err := errors.Wrap(err, "unable to write to home directory", _localDir)
+		exit.Error(err)
+	}
+
 	_cliConfigPath = filepath.Join(_localDir, "cli.yaml")
 	_clientIDPath = filepath.Join(_localDir, "client-id.txt")
 	_emailPath = filepath.Join(_localDir, "email.txt")
@@ -109,14 +120,24 @@ func init() {
 		initTelemetry()
 	}
 
+	auditInit()
+	authInit()
+	buildInit()
 	clusterInit()
 	completionInit()
+	dashboardInit()
 	deleteInit()
 	deployInit()
+	devInit()
 	envInit()
+	execInit()
 	getInit()
+	jobInit()
 	logsInit()
+	portForwardInit()
+	pruneInit()
 	refreshInit()
+	rollbackInit()
 	versionInit()
 }
 
@@ -152,21 +173,35 @@ func Execute() {
 
 	cobra.EnableCommandSorting = false
 
+	_rootCmd.AddCommand(_buildCmd)
 	_rootCmd.AddCommand(_deployCmd)
 	_rootCmd.AddCommand(_getCmd)
+	_rootCmd.AddCommand(_dashboardCmd)
 	_rootCmd.AddCommand(_logsCmd)
+	_rootCmd.AddCommand(_execCmd)
+	_rootCmd.AddCommand(_portForwardCmd)
 	_rootCmd.AddCommand(_refreshCmd)
+	_rootCmd.AddCommand(_rollbackCmd)
 	_rootCmd.AddCommand(_deleteCmd)
+	_rootCmd.AddCommand(_pruneCmd)
+	_rootCmd.AddCommand(_jobCmd)
+	_rootCmd.AddCommand(_auditCmd)
 
 	_rootCmd.AddCommand(_clusterCmd)
+	_rootCmd.AddCommand(_devCmd)
 
 	_rootCmd.AddCommand(_envCmd)
+	_rootCmd.AddCommand(_authCmd)
 	_rootCmd.AddCommand(_versionCmd)
 	_rootCmd.AddCommand(_completionCmd)
 
+	registerFlagCompletions(_rootCmd)
 	updateRootUsage()
 
-	_rootCmd.Execute()
+	// cobra prints usage errors (e.g. unknown flags or missing arguments)
+	if err := _rootCmd.Execute(); err != nil {
+		exit.Code(_exitCodeValidation)
+	}
 
 	exit.Ok()
 }
@@ -56,10 +56,15 @@ func (cliConfig *CLIConfig) Validate() error {
 	return nil
 }
 
+// operator tokens are redacted from the user facing config
 func (cliConfig *CLIConfig) ConvertToUserFacingCLIConfig() UserFacingCLIConfig {
-	envs := cliConfig.Environments
-	if envs == nil {
-		envs = []*Environment{}
+	envs := make([]*Environment, 0, len(cliConfig.Environments))
+	for _, env := range cliConfig.Environments {
+		envCopy := *env
+		if envCopy.OperatorToken != "" {
+			envCopy.OperatorToken = RedactedOperatorToken
+		}
+		envs = append(envs, &envCopy)
 	}
 	return UserFacingCLIConfig{
 		DefaultEnvironment: cliConfig.DefaultEnvironment,
@@ -21,4 +21,8 @@ const (
 	DefaultEnvironmentKey = "default_environment"
 	NameKey               = "name"
 	OperatorEndpointKey   = "operator_endpoint"
+	OperatorTokenKey      = "operator_token"
+	AuthKey               = "auth"
+	AWSProfileKey         = "aws_profile"
+	AWSRoleARNKey         = "aws_role_arn"
 )
@@ -20,15 +20,31 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/cortexlabs/cortex/pkg/lib/aws"
 	cr "github.com/cortexlabs/cortex/pkg/lib/configreader"
 	"github.com/cortexlabs/cortex/pkg/lib/console"
 	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/lib/slices"
 	"github.com/cortexlabs/cortex/pkg/lib/urls"
 )
 
+const (
+	AWSAuth  = "aws"
+	OIDCAuth = "oidc"
+)
+
+// RedactedOperatorToken replaces operator tokens in user-facing output
+const RedactedOperatorToken = "********"
+
+var AuthTypes = []string{AWSAuth, OIDCAuth}
+
 type Environment struct {
 	Name             string `json:"name" yaml:"name"`
 	OperatorEndpoint string `json:"operator_endpoint" yaml:"operator_endpoint"`
+	OperatorToken    string `json:"operator_token,omitempty" yaml:"operator_token,omitempty"` // if set, the token is used to authenticate with the operator instead of AWS credentials
+	Auth             string `json:"auth,omitempty" yaml:"auth,omitempty"`                     // how the cli authenticates with the operator (aws or oidc)
+	AWSProfile       string `json:"aws_profile,omitempty" yaml:"aws_profile,omitempty"`       // the aws profile which is used for this environment (instead of the default credentials)
+	AWSRoleARN       string `json:"aws_role_arn,omitempty" yaml:"aws_role_arn,omitempty"`     // an aws role which is assumed for this environment
 }
 
 func (env Environment) String(isDefault bool) string {
@@ -41,10 +57,29 @@ func (env Environment) String(isDefault bool) string {
 	}
 
 	envStr += fmt.Sprintf("\ncortex operator endpoint: %s\n", env.OperatorEndpoint)
+	if env.OperatorToken != "" {
+		envStr += "authentication: operator token\n"
+	} else if env.Auth == OIDCAuth {
+		envStr += "authentication: oidc\n"
+	}
+	if env.AWSProfile != "" {
+		envStr += fmt.Sprintf("aws profile: %s\n", env.AWSProfile)
+	}
+	if env.AWSRoleARN != "" {
+		envStr += fmt.Sprintf("aws role: %s\n", env.AWSRoleARN)
+	}
 
 	return envStr
 }
 
+// AWSCredentialsConfig returns the aws credentials which are bound to the environment
+func (env Environment) AWSCredentialsConfig() aws.CredentialsConfig {
+	return aws.CredentialsConfig{
+		Profile: env.AWSProfile,
+		RoleARN: env.AWSRoleARN,
+	}
+}
+
 func CortexEndpointValidator(val string) (string, error) {
 	urlStr := strings.TrimSpace(val)
 
@@ -72,5 +107,17 @@ func (env *Environment) Validate() error {
 	}
 
 	env.OperatorEndpoint = validOperatorURL
+
+	if env.Auth == "" {
+		env.Auth = AWSAuth
+	}
+	if !slices.HasString(AuthTypes, env.Auth) {
+		return errors.Wrap(cr.ErrorInvalidStr(env.Auth, AuthTypes[0], AuthTypes[1:]...), AuthKey)
+	}
+
+	if env.AWSRoleARN != "" && !strings.HasPrefix(env.AWSRoleARN, "arn:") {
+		return errors.Wrap(ErrorInvalidRoleARN(env.AWSRoleARN), AWSRoleARNKey)
+	}
+
 	return nil
 }
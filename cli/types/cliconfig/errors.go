@@ -27,6 +27,7 @@ const (
 	ErrEnvironmentNotConfigured     = "cliconfig.environment_not_configured"
 	ErrEnvironmentAlreadyConfigured = "cliconfig.environment_already_configured"
 	ErrDuplicateEnvironmentNames    = "cliconfig.duplicate_environment_names"
+	ErrInvalidRoleARN               = "cliconfig.invalid_role_arn"
 )
 
 func ErrorEnvironmentNotConfigured(envName string) error {
@@ -49,3 +50,10 @@ func ErrorDuplicateEnvironmentNames(envName string) error {
 		Message: fmt.Sprintf("duplicate environment names (%s is defined more than once)", s.UserStr(envName)),
 	})
 }
+
+func ErrorInvalidRoleARN(roleARN string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrInvalidRoleARN,
+		Message: fmt.Sprintf("%s is not a valid role arn (e.g. arn:aws:iam::123456789012:role/cortex-admin)", s.UserStr(roleARN)),
+	})
+}
@@ -20,6 +20,7 @@ import (
 	"flag"
 	"net/http"
 	"os"
+	"time"
 
 	gateway "github.com/cortexlabs/cortex/pkg/async-gateway"
 	"github.com/cortexlabs/cortex/pkg/lib/aws"
@@ -33,7 +34,10 @@ import (
 )
 
 const (
-	_defaultPort = "8080"
+	_defaultPort            = "8080"
+	_defaultAdminPort       = "15100"
+	_defaultMaxWriteLatency = 5 * time.Second
+	_defaultRetryAfter      = 10 * time.Second
 )
 
 var (
@@ -58,7 +62,7 @@ func Exit(err error, wrapStrs ...string) {
 	os.Exit(1)
 }
 
-// usage: ./gateway -bucket <bucket> -region <region> -port <port> -queue queue <apiName>
+// usage: ./gateway -cluster-config <path> -port <port> -admin-port <port> -queue <queue> <apiName>
 func main() {
 	log := logging.GetLogger()
 	defer func() {
@@ -68,7 +72,11 @@ func main() {
 	var (
 		clusterConfigPath = flag.String("cluster-config", "", "cluster config path")
 		port              = flag.String("port", _defaultPort, "port on which the gateway server runs on")
+		adminPort         = flag.String("admin-port", _defaultAdminPort, "port on which the metrics are served")
 		queueURL          = flag.String("queue", "", "SQS queue URL")
+		maxWriteLatency   = flag.Duration("max-write-latency", _defaultMaxWriteLatency, "submissions are rejected while the average s3/sqs write latency exceeds this")
+		retryAfter        = flag.Duration("retry-after", _defaultRetryAfter, "how long submissions are rejected for once the max write latency is exceeded")
+		expiration        = flag.Duration("expiration", 0, "how long submitted workloads can wait in the queue before they expire (0 means they don't expire)")
 	)
 	flag.Parse()
 
@@ -118,8 +126,11 @@ func main() {
 	s3Storage := gateway.NewS3(sess, clusterConfig.Bucket)
 	sqsQueue := gateway.NewSQS(*queueURL, sess)
 
-	svc := gateway.NewService(clusterConfig.ClusterUID, apiName, sqsQueue, s3Storage, log)
-	ep := gateway.NewEndpoint(svc, log)
+	metrics := gateway.NewMetrics()
+	shedder := gateway.NewLoadShedder(*maxWriteLatency, *retryAfter)
+
+	svc := gateway.NewService(clusterConfig.ClusterUID, apiName, sqsQueue, s3Storage, metrics, shedder, log)
+	ep := gateway.NewEndpoint(svc, metrics, shedder, *expiration, log)
 
 	router := mux.NewRouter()
 	router.HandleFunc("/", ep.CreateWorkload).Methods("POST")
@@ -143,6 +154,16 @@ func main() {
 		handlers.AllowCredentials(),
 	}
 
+	adminHandler := http.NewServeMux()
+	adminHandler.Handle("/metrics", metrics)
+
+	go func() {
+		log.Info("Serving metrics on port " + *adminPort)
+		if err := http.ListenAndServe(":"+*adminPort, adminHandler); err != nil {
+			Exit(err)
+		}
+	}()
+
 	log.Info("Running on port " + *port)
 	if err = http.ListenAndServe(":"+*port, handlers.CORS(corsOptions...)(router)); err != nil {
 		Exit(err)
@@ -34,7 +34,9 @@ import (
 	"github.com/cortexlabs/cortex/pkg/lib/telemetry"
 	"github.com/cortexlabs/cortex/pkg/probe"
 	"github.com/cortexlabs/cortex/pkg/types/clusterconfig"
+	"github.com/cortexlabs/cortex/pkg/types/spec"
 	"github.com/cortexlabs/cortex/pkg/types/userconfig"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"go.uber.org/zap"
 )
 
@@ -103,6 +105,9 @@ func main() {
 		exit(log, err, "failed to create aws client")
 	}
 
+	dequeuerMetrics := dequeuer.NewMetrics()
+	awsClient.OnRequestComplete(dequeuerMetrics.ObserveAWSRequest)
+
 	_, userID, err := awsClient.CheckCredentials()
 	if err != nil {
 		exit(log, err)
@@ -145,29 +150,53 @@ func main() {
 	adminHandler.Handle("/healthz", dequeuer.HealthcheckHandler(func() bool {
 		return probe.AreProbesHealthy(probes)
 	}))
+	adminHandler.Handle("/metrics", promhttp.Handler())
 
 	var dequeuerConfig dequeuer.SQSDequeuerConfig
 	var messageHandler dequeuer.MessageHandler
+	var batchMessageHandler *dequeuer.BatchMessageHandler
 
 	switch apiKind {
 	case userconfig.BatchAPIKind.String():
 		if jobID == "" {
 			log.Fatal("--job-id is a required option")
 		}
+		log = log.With(logging.JobIDField, jobID)
+		if clusterUID == "" {
+			log.Fatal("--cluster-uid is a required option")
+		}
+
+		worker, err := os.Hostname()
+		if err != nil {
+			exit(log, err, "failed to get hostname")
+		}
 
 		config := dequeuer.BatchMessageHandlerConfig{
-			Region:    clusterConfig.Region,
-			APIName:   apiName,
-			JobID:     jobID,
-			QueueURL:  queueURL,
-			TargetURL: targetURL,
+			Region:        clusterConfig.Region,
+			APIName:       apiName,
+			JobID:         jobID,
+			QueueURL:      queueURL,
+			TargetURL:     targetURL,
+			Bucket:        clusterConfig.Bucket,
+			ResultsPrefix: spec.JobResultsPrefix(clusterUID, userconfig.BatchAPIKind, apiName, jobID),
+			Worker:        worker,
 		}
 
-		messageHandler = dequeuer.NewBatchMessageHandler(config, awsClient, metricsClient, log)
+		batchMessageHandler = dequeuer.NewBatchMessageHandler(config, awsClient, metricsClient, log)
+		messageHandler = batchMessageHandler
+
+		checkpointStore := dequeuer.NewCheckpointStore(
+			awsClient,
+			clusterConfig.Bucket,
+			spec.JobCheckpointsPrefix(clusterUID, userconfig.BatchAPIKind, apiName, jobID),
+		)
+		adminHandler.Handle(dequeuer.CheckpointsPath, dequeuer.CheckpointHandler(checkpointStore, log))
+
 		dequeuerConfig = dequeuer.SQSDequeuerConfig{
 			Region:           clusterConfig.Region,
 			QueueURL:         queueURL,
 			StopIfNoMessages: true,
+			Metrics:          dequeuerMetrics,
 		}
 
 	case userconfig.AsyncAPIKind.String():
@@ -188,10 +217,8 @@ func main() {
 			Region:           clusterConfig.Region,
 			QueueURL:         queueURL,
 			StopIfNoMessages: false,
+			Metrics:          dequeuerMetrics,
 		}
-
-		// report prometheus metrics for async api kinds
-		adminHandler.Handle("/metrics", asyncStatsReporter)
 	default:
 		exit(log, err, fmt.Sprintf("kind %s is not supported", apiKind))
 	}
@@ -233,12 +260,23 @@ func main() {
 		}
 	}()
 
+	flushBatchResults := func() {
+		if batchMessageHandler == nil {
+			return
+		}
+		if err := batchMessageHandler.FlushResults(); err != nil {
+			log.Errorw("failed to upload batch results", "error", err)
+		}
+	}
+
 	select {
 	case err = <-errCh:
+		flushBatchResults()
 		exit(log, err, "error during message dequeueing or error from admin server")
 	case <-sigint:
 		log.Info("Received TERM signal, handling a graceful shutdown...")
 		sqsDequeuer.Shutdown()
+		flushBatchResults()
 		log.Info("Shutdown complete, exiting...")
 	}
 }
@@ -0,0 +1,164 @@
+/*
+Copyright 2021 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/lib/k8s"
+	"github.com/cortexlabs/cortex/pkg/lib/sets/strset"
+	"go.uber.org/zap"
+	kcore "k8s.io/api/core/v1"
+	kmeta "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	_gcPeriod = 5 * time.Minute
+
+	// cached artifacts are kept for a while after they were last used, so that replicas which are rescheduled onto the node
+	// (e.g. during a rolling update which doesn't change the artifacts) don't download them again
+	_gcRetention = 10 * time.Minute
+)
+
+// runGC periodically deletes the cache directories on the node which aren't referenced by any of the node's pods;
+// it runs on every node (see manager/manifests/model-cache-gc.yaml.j2)
+func runGC(log *zap.SugaredLogger, cacheRoot string, hostCacheRoot string, nodeName string, namespace string) {
+	k8sClient, err := k8s.New(namespace, true, nil, nil)
+	if err != nil {
+		exit(log, err, "failed to create kubernetes client")
+	}
+
+	for {
+		if err := collectGarbage(log, k8sClient, cacheRoot, hostCacheRoot, nodeName); err != nil {
+			log.Errorw("failed to delete unused cached artifacts", "error", errors.Message(err))
+		}
+		time.Sleep(_gcPeriod)
+	}
+}
+
+func collectGarbage(log *zap.SugaredLogger, k8sClient *k8s.Client, cacheRoot string, hostCacheRoot string, nodeName string) error {
+	inUse, err := cacheDirsInUse(k8sClient, hostCacheRoot, nodeName)
+	if err != nil {
+		return err
+	}
+
+	entries, err := ioutil.ReadDir(cacheRoot)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	// the candidates are locked, so that a downloader which starts in the meantime waits until they've been deleted
+	var lockFiles []*os.File
+	defer func() {
+		for _, lockFile := range lockFiles {
+			_ = lockFile.Close()
+		}
+	}()
+	var candidates []string
+	for _, entry := range entries {
+		if !entry.IsDir() || inUse.Has(entry.Name()) {
+			continue
+		}
+		lockFile, ok := lockUnusedCacheDir(filepath.Join(cacheRoot, entry.Name()))
+		if !ok {
+			continue
+		}
+		lockFiles = append(lockFiles, lockFile)
+		candidates = append(candidates, entry.Name())
+	}
+
+	if len(candidates) == 0 {
+		return nil
+	}
+
+	// pods which were scheduled onto the node since the first check still need their cache directories
+	inUse, err = cacheDirsInUse(k8sClient, hostCacheRoot, nodeName)
+	if err != nil {
+		return err
+	}
+
+	for _, dirName := range candidates {
+		if inUse.Has(dirName) {
+			continue
+		}
+		if err := os.RemoveAll(filepath.Join(cacheRoot, dirName)); err != nil {
+			log.Errorw("failed to delete unused cached artifacts", "dir", dirName, "error", err.Error())
+			continue
+		}
+		log.Infow("deleted unused cached artifacts", "dir", dirName)
+	}
+
+	return nil
+}
+
+// returns the names of the cache directories which are mounted by the node's pods
+func cacheDirsInUse(k8sClient *k8s.Client, hostCacheRoot string, nodeName string) (strset.Set, error) {
+	pods, err := k8sClient.ListPods(&kmeta.ListOptions{
+		FieldSelector: "spec.nodeName=" + nodeName,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	inUse := strset.New()
+	for _, pod := range pods {
+		if pod.Status.Phase == kcore.PodSucceeded || pod.Status.Phase == kcore.PodFailed {
+			continue
+		}
+		for _, volume := range pod.Spec.Volumes {
+			if volume.HostPath != nil && path.Dir(volume.HostPath.Path) == path.Clean(hostCacheRoot) {
+				inUse.Add(path.Base(volume.HostPath.Path))
+			}
+		}
+	}
+	return inUse, nil
+}
+
+// locks the cache directory if no downloader is using it and it hasn't been used within the retention period;
+// the downloader updates the modification time of the lock file whenever it uses the directory
+func lockUnusedCacheDir(cacheDir string) (*os.File, bool) {
+	// the directory's modification time is checked before the lock file is created, since creating it modifies the directory
+	dirInfo, err := os.Stat(cacheDir)
+	if err != nil || time.Since(dirInfo.ModTime()) < _gcRetention {
+		return nil, false
+	}
+
+	lockFile, err := os.OpenFile(filepath.Join(cacheDir, _lockFileName), os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, false
+	}
+
+	if err := syscall.Flock(int(lockFile.Fd()), syscall.LOCK_EX|syscall.LOCK_NB); err != nil {
+		_ = lockFile.Close()
+		return nil, false
+	}
+
+	// if the lock file was just created, the directory was never used by a downloader
+	lockInfo, err := lockFile.Stat()
+	if err != nil || (lockInfo.Size() > 0 && time.Since(lockInfo.ModTime()) < _gcRetention) {
+		_ = lockFile.Close()
+		return nil, false
+	}
+
+	return lockFile, true
+}
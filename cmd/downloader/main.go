@@ -0,0 +1,180 @@
+/*
+Copyright 2021 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"flag"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	awslib "github.com/cortexlabs/cortex/pkg/lib/aws"
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/lib/files"
+	"github.com/cortexlabs/cortex/pkg/lib/integrity"
+	libjson "github.com/cortexlabs/cortex/pkg/lib/json"
+	"github.com/cortexlabs/cortex/pkg/lib/logging"
+	s "github.com/cortexlabs/cortex/pkg/lib/strings"
+	"go.uber.org/zap"
+)
+
+const (
+	_lockFileName     = ".cortex-lock"
+	_completeFileName = ".cortex-complete"
+
+	// the operator reads the verification status of the replica from the init container's termination message
+	_terminationLogPath = "/dev/termination-log"
+)
+
+func main() {
+	var (
+		region        string
+		s3Path        string
+		cacheDir      string
+		integrityJSON string
+		gc            bool
+		hostCacheDir  string
+		nodeName      string
+		namespace     string
+	)
+	flag.StringVar(&region, "region", "", "cluster region")
+	flag.StringVar(&s3Path, "s3-path", "", "s3 path of the artifacts to cache")
+	flag.StringVar(&cacheDir, "cache-dir", "", "local directory (shared by all replicas on the node) where the artifacts are cached")
+	flag.StringVar(&integrityJSON, "integrity", "", "json object with the expected sha256 checksum and/or signature of the artifacts (the artifacts are not verified if not set)")
+	flag.BoolVar(&gc, "gc", false, "instead of downloading artifacts, periodically delete the cache directories in --cache-dir which aren't used by any of the node's pods")
+	flag.StringVar(&hostCacheDir, "host-cache-dir", "", "path of --cache-dir on the node, which the pods' host path volumes refer to (--gc only)")
+	flag.StringVar(&nodeName, "node-name", "", "name of the node (--gc only)")
+	flag.StringVar(&namespace, "namespace", "default", "namespace of the pods which use the cache (--gc only)")
+
+	flag.Parse()
+
+	log := logging.GetLogger()
+	defer func() {
+		_ = log.Sync()
+	}()
+
+	if gc {
+		switch {
+		case cacheDir == "":
+			log.Fatal("--cache-dir is a required option")
+		case hostCacheDir == "":
+			log.Fatal("--host-cache-dir is a required option")
+		case nodeName == "":
+			log.Fatal("--node-name is a required option")
+		}
+		runGC(log, cacheDir, hostCacheDir, nodeName, namespace)
+		return
+	}
+
+	switch {
+	case region == "":
+		log.Fatal("--region is a required option")
+	case s3Path == "":
+		log.Fatal("--s3-path is a required option")
+	case cacheDir == "":
+		log.Fatal("--cache-dir is a required option")
+	}
+
+	var integritySpec integrity.Spec
+	if integrityJSON != "" {
+		if err := libjson.Unmarshal([]byte(integrityJSON), &integritySpec); err != nil {
+			exit(log, err, "failed to parse --integrity")
+		}
+	}
+
+	bucket, prefix, err := awslib.SplitS3Path(s.EnsureSuffix(s3Path, "/"))
+	if err != nil {
+		exit(log, err)
+	}
+
+	awsClient, err := awslib.NewForRegion(region)
+	if err != nil {
+		exit(log, err, "failed to create aws client")
+	}
+
+	if _, err := files.CreateDirIfMissing(cacheDir); err != nil {
+		exit(log, err)
+	}
+
+	// the cache directory is shared by all replicas on the node; the lock makes the other replicas wait until the first one has finished downloading
+	lockFile, err := os.OpenFile(filepath.Join(cacheDir, _lockFileName), os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		exit(log, errors.WithStack(err), "failed to open lock file")
+	}
+	defer lockFile.Close()
+
+	if err := syscall.Flock(int(lockFile.Fd()), syscall.LOCK_EX); err != nil {
+		exit(log, errors.WithStack(err), "failed to acquire lock on the cache directory")
+	}
+	defer func() {
+		_ = syscall.Flock(int(lockFile.Fd()), syscall.LOCK_UN)
+	}()
+
+	// the time at which the cache directory was last used is recorded in the lock file, so that the garbage collector (see gc.go) keeps it for a while
+	if err := lockFile.Truncate(0); err == nil {
+		_, _ = lockFile.WriteAt([]byte(time.Now().UTC().Format(time.RFC3339)), 0)
+	}
+
+	completeFilePath := filepath.Join(cacheDir, _completeFileName)
+	if files.IsFile(completeFilePath) {
+		log.Infof("%s is already cached on this node", s3Path)
+	} else {
+		log.Infof("downloading %s to %s", s3Path, cacheDir)
+		if err := awsClient.DownloadPrefixFromS3(bucket, prefix, cacheDir, true, nil); err != nil {
+			exit(log, err, "failed to download "+s3Path)
+		}
+
+		if err := files.MakeEmptyFile(completeFilePath); err != nil {
+			exit(log, err)
+		}
+
+		log.Infof("finished downloading %s", s3Path)
+	}
+
+	if integritySpec.IsEmpty() {
+		return
+	}
+
+	checksum, err := integrity.VerifyDir(cacheDir, integritySpec, _lockFileName, _completeFileName)
+	if err != nil {
+		writeTerminationMessage(integrity.FailedMessagePrefix + errors.Message(err))
+		// the cached artifacts are downloaded again by the next replica which starts on the node, in case they were modified on disk
+		_ = os.Remove(completeFilePath)
+		exit(log, err, "failed to verify the artifacts of "+s3Path)
+	}
+
+	writeTerminationMessage(integrity.VerifiedMessagePrefix + checksum)
+	log.Infof("verified the artifacts of %s (sha256:%s)", s3Path, checksum)
+}
+
+func writeTerminationMessage(message string) {
+	_ = ioutil.WriteFile(_terminationLogPath, []byte(message), 0644)
+}
+
+func exit(log *zap.SugaredLogger, err error, wrapStrs ...string) {
+	for _, str := range wrapStrs {
+		err = errors.Wrap(err, str)
+	}
+
+	if !errors.IsNoPrint(err) {
+		log.Error(err)
+	}
+
+	os.Exit(1)
+}
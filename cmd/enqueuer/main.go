@@ -19,45 +19,19 @@ package main
 import (
 	"flag"
 	"os"
-	"strings"
 
 	"github.com/cortexlabs/cortex/pkg/consts"
 	"github.com/cortexlabs/cortex/pkg/enqueuer"
+	"github.com/cortexlabs/cortex/pkg/lib/logging"
 	"go.uber.org/zap"
-	"go.uber.org/zap/zapcore"
 )
 
 func createLogger() (*zap.Logger, error) {
-	logLevelEnv := strings.ToLower(os.Getenv("CORTEX_LOG_LEVEL"))
-	disableJSONLogging := os.Getenv("CORTEX_DISABLE_JSON_LOGGING")
-
-	var logLevelZap zapcore.Level
-	switch logLevelEnv {
-	case "debug":
-		logLevelZap = zapcore.DebugLevel
-	case "warning":
-		logLevelZap = zapcore.WarnLevel
-	case "error":
-		logLevelZap = zapcore.ErrorLevel
-	default:
-		logLevelZap = zapcore.InfoLevel
-	}
-
-	encoderConfig := zap.NewProductionEncoderConfig()
-	encoderConfig.MessageKey = "message"
-
-	encoding := "json"
-	if strings.ToLower(disableJSONLogging) == "true" {
-		encoding = "console"
+	zapConfig, err := logging.ZapConfigFromEnv()
+	if err != nil {
+		return nil, err
 	}
-
-	return zap.Config{
-		Level:            zap.NewAtomicLevelAt(logLevelZap),
-		Encoding:         encoding,
-		EncoderConfig:    encoderConfig,
-		OutputPaths:      []string{"stdout"},
-		ErrorOutputPaths: []string{"stderr"},
-	}.Build()
+	return zapConfig.Build()
 }
 
 func main() {
@@ -106,6 +80,9 @@ func main() {
 		log.Fatal("-jobID is a required option")
 	}
 
+	// the api name and cluster uid are added from the environment
+	log = log.With(zap.String(logging.JobIDField, jobID))
+
 	envConfig := enqueuer.EnvConfig{
 		ClusterUID: clusterUID,
 		Region:     region,
@@ -0,0 +1,190 @@
+/*
+Copyright 2021 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"flag"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/cortexlabs/cortex/pkg/consts"
+	awslib "github.com/cortexlabs/cortex/pkg/lib/aws"
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/lib/integrity"
+	libjson "github.com/cortexlabs/cortex/pkg/lib/json"
+	"github.com/cortexlabs/cortex/pkg/lib/logging"
+	s "github.com/cortexlabs/cortex/pkg/lib/strings"
+	"github.com/cortexlabs/cortex/pkg/modelmanager"
+	"go.uber.org/zap"
+)
+
+const _unloadTimeout = 30 * time.Second
+
+func main() {
+	var (
+		region          string
+		port            int
+		userPort        int
+		modelsJSON      string
+		modelsDir       string
+		maxLoadedModels int
+		unloadPath      string
+		integrityJSON   string
+	)
+	flag.StringVar(&region, "region", "", "cluster region")
+	flag.IntVar(&port, "port", 15101, "port where the model manager server will be exposed")
+	flag.IntVar(&userPort, "user-port", 8080, "port of the user container, which is notified when a model is unloaded")
+	flag.StringVar(&modelsJSON, "models", "", "json object which maps the names of the api's models to their s3 paths")
+	flag.StringVar(&modelsDir, "models-dir", "", "local directory (shared with the user container) where the models are downloaded")
+	flag.IntVar(&maxLoadedModels, "max-loaded-models", 10, "the maximum number of models which are kept on disk when they aren't in use")
+	flag.StringVar(&unloadPath, "unload-path", "", "path on the user container which is called before a model's files are deleted (the user container is not notified if not set)")
+	flag.StringVar(&integrityJSON, "integrity", "", "json object which maps the names of the api's models to their expected sha256 checksums and/or signatures (models which aren't included are not verified)")
+
+	flag.Parse()
+
+	log := logging.GetLogger()
+	defer func() {
+		_ = log.Sync()
+	}()
+
+	switch {
+	case region == "":
+		log.Fatal("--region is a required option")
+	case modelsJSON == "":
+		log.Fatal("--models is a required option")
+	case modelsDir == "":
+		log.Fatal("--models-dir is a required option")
+	}
+
+	var models map[string]string
+	if err := libjson.Unmarshal([]byte(modelsJSON), &models); err != nil {
+		exit(log, err, "failed to parse --models")
+	}
+
+	var modelsIntegrity map[string]integrity.Spec
+	if integrityJSON != "" {
+		if err := libjson.Unmarshal([]byte(integrityJSON), &modelsIntegrity); err != nil {
+			exit(log, err, "failed to parse --integrity")
+		}
+	}
+
+	// the download function receives the model's s3 path
+	integrityByPath := make(map[string]integrity.Spec, len(modelsIntegrity))
+	for name, integritySpec := range modelsIntegrity {
+		integrityByPath[models[name]] = integritySpec
+	}
+
+	awsClient, err := awslib.NewForRegion(region)
+	if err != nil {
+		exit(log, err, "failed to create aws client")
+	}
+
+	download := func(s3Path string, localDir string) error {
+		bucket, prefix, err := awslib.SplitS3Path(s.EnsureSuffix(s3Path, "/"))
+		if err != nil {
+			return err
+		}
+
+		log.Infof("downloading %s to %s", s3Path, localDir)
+		if err := awsClient.DownloadPrefixFromS3(bucket, prefix, localDir, true, nil); err != nil {
+			log.Error(errors.Wrap(err, "failed to download "+s3Path))
+			return err
+		}
+		log.Infof("finished downloading %s", s3Path)
+
+		if integritySpec, ok := integrityByPath[s3Path]; ok && !integritySpec.IsEmpty() {
+			checksum, err := integrity.VerifyDir(localDir, integritySpec)
+			if err != nil {
+				log.Error(errors.Wrap(err, "failed to verify the artifacts of "+s3Path))
+				return err
+			}
+			log.Infof("verified the artifacts of %s (sha256:%s)", s3Path, checksum)
+		}
+		return nil
+	}
+
+	var unload modelmanager.UnloadFunc
+	if unloadPath != "" {
+		unloadURL := "http://127.0.0.1:" + strconv.Itoa(userPort) + unloadPath
+		client := &http.Client{Timeout: _unloadTimeout}
+
+		unload = func(name string, localDir string) error {
+			req, err := http.NewRequest(http.MethodPost, unloadURL, nil)
+			if err != nil {
+				return errors.WithStack(err)
+			}
+			req.Header.Set(consts.ModelHeader, name)
+			req.Header.Set(consts.ModelPathHeader, localDir)
+
+			res, err := client.Do(req)
+			if err != nil {
+				log.Warn(errors.Wrap(err, "failed to notify the user container that model "+name+" was unloaded"))
+				return errors.WithStack(err)
+			}
+			_ = res.Body.Close()
+			return nil
+		}
+	}
+
+	manager := modelmanager.New(modelmanager.Params{
+		Models:          models,
+		ModelsDir:       modelsDir,
+		MaxLoadedModels: maxLoadedModels,
+	}, download, unload)
+
+	server := &http.Server{
+		Addr:    ":" + strconv.Itoa(port),
+		Handler: modelmanager.Handler(manager),
+	}
+
+	errCh := make(chan error)
+	go func() {
+		log.Infof("Starting model manager server on %s", server.Addr)
+		errCh <- server.ListenAndServe()
+	}()
+
+	sigint := make(chan os.Signal, 1)
+	signal.Notify(sigint, os.Interrupt, syscall.SIGTERM)
+
+	select {
+	case err = <-errCh:
+		exit(log, errors.Wrap(err, "failed to start model manager server"))
+	case <-sigint:
+		log.Info("Received TERM signal, handling a graceful shutdown...")
+		if err := server.Shutdown(context.Background()); err != nil {
+			log.Warn("HTTP server Shutdown Error", zap.Error(err))
+		}
+		log.Info("Shutdown complete, exiting...")
+	}
+}
+
+func exit(log *zap.SugaredLogger, err error, wrapStrs ...string) {
+	for _, str := range wrapStrs {
+		err = errors.Wrap(err, str)
+	}
+
+	if !errors.IsNoPrint(err) {
+		log.Error(err)
+	}
+
+	os.Exit(1)
+}
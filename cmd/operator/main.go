@@ -25,12 +25,19 @@ import (
 	"github.com/cortexlabs/cortex/pkg/lib/errors"
 	"github.com/cortexlabs/cortex/pkg/lib/logging"
 	"github.com/cortexlabs/cortex/pkg/lib/telemetry"
+	libtime "github.com/cortexlabs/cortex/pkg/lib/time"
+	"github.com/cortexlabs/cortex/pkg/operator/crds"
 	"github.com/cortexlabs/cortex/pkg/operator/endpoints"
+	"github.com/cortexlabs/cortex/pkg/operator/gitops"
 	"github.com/cortexlabs/cortex/pkg/operator/lib/exit"
+	"github.com/cortexlabs/cortex/pkg/operator/modelwatch"
 	"github.com/cortexlabs/cortex/pkg/operator/operator"
+	"github.com/cortexlabs/cortex/pkg/operator/resources"
 	"github.com/cortexlabs/cortex/pkg/operator/resources/asyncapi"
+	"github.com/cortexlabs/cortex/pkg/operator/resources/job"
 	"github.com/cortexlabs/cortex/pkg/operator/resources/job/taskapi"
 	"github.com/cortexlabs/cortex/pkg/operator/resources/realtimeapi"
+	"github.com/cortexlabs/cortex/pkg/operator/resources/workflow"
 	"github.com/cortexlabs/cortex/pkg/types/userconfig"
 	"github.com/gorilla/handlers"
 	"github.com/gorilla/mux"
@@ -50,6 +57,12 @@ func main() {
 
 	cron.Run(operator.DeleteEvictedPods, operator.ErrorHandler("delete evicted pods"), time.Hour)
 	cron.Run(operator.ClusterTelemetry, operator.ErrorHandler("instance telemetry"), 1*time.Hour)
+	cron.Run(operator.UpdateImagePrePullers, operator.ErrorHandler("update image pre-pullers"), operator.ImagePrePullerCronPeriod)
+	cron.Run(operator.UpdateNetworkPolicies, operator.ErrorHandler("update network policies"), operator.NetworkPolicyCronPeriod)
+	cron.Run(operator.RefreshRegistryCredentials, operator.ErrorHandler("refresh registry credentials"), operator.RegistryCredentialsCronPeriod)
+	cron.Run(operator.DeleteUnusedProjectServiceAccounts, operator.ErrorHandler("delete unused project service accounts"), operator.ProjectServiceAccountsCronPeriod)
+	cron.Run(operator.RunPostDeployHooks, operator.ErrorHandler("run post-deploy hooks"), operator.PostDeployHooksCronPeriod)
+	cron.Run(operator.RotateGatewayCertificate, operator.ErrorHandler("rotate gateway certificate"), operator.CertificateRotationCronPeriod)
 
 	_, err := operator.UpdateMemoryCapacityConfigMap()
 	if err != nil {
@@ -57,6 +70,28 @@ func main() {
 	}
 
 	cron.Run(taskapi.ManageJobResources, operator.ErrorHandler("manage task jobs"), taskapi.ManageJobResourcesCronPeriod)
+	cron.Run(workflow.ManageRuns, operator.ErrorHandler("manage workflow runs"), workflow.ManageRunsCronPeriod)
+	cron.Run(job.ReportTimedOutJobs, operator.ErrorHandler("report timed out jobs"), job.ReportTimedOutJobsCronPeriod)
+	cron.Run(resources.UpdateVerticalAutoscaling, operator.ErrorHandler("update vertical autoscaling"), resources.VerticalAutoscalingCronPeriod)
+	cron.Run(modelwatch.Reconcile, operator.ErrorHandler("watch model artifacts"), modelwatch.CronPeriod)
+
+	if err := operator.ApplyAlertmanagerConfig(); err != nil {
+		exit.Error(errors.Wrap(err, "init"))
+	}
+	if err := operator.ApplyClusterAlertRules(); err != nil {
+		exit.Error(errors.Wrap(err, "init"))
+	}
+	if config.ClusterConfig.UsesKarpenter() {
+		if err := operator.ApplyKarpenterProvisioners(); err != nil {
+			exit.Error(errors.Wrap(err, "init"))
+		}
+	}
+
+	cron.Run(crds.Reconcile, operator.ErrorHandler("reconcile api resources"), crds.ReconcileCronPeriod)
+
+	if config.ClusterConfig.GitOps != nil {
+		cron.Run(gitops.Reconcile, operator.ErrorHandler("gitops reconcile"), libtime.MustParseDuration(config.ClusterConfig.GitOps.SyncPeriod))
+	}
 
 	deployments, err := config.K8s.ListDeploymentsWithLabelKeys("apiName")
 	if err != nil {
@@ -80,6 +115,7 @@ func main() {
 				if err := realtimeapi.UpdateAutoscalerCron(&deployment, api); err != nil {
 					operatorLogger.Fatal(errors.Wrap(err, "init"))
 				}
+				realtimeapi.UpdateHedgingCron(api)
 			case userconfig.AsyncAPIKind.String():
 				if err := asyncapi.UpdateMetricsCron(&deployment); err != nil {
 					operatorLogger.Fatal(errors.Wrap(err, "init"))
@@ -88,6 +124,10 @@ func main() {
 				if err := asyncapi.UpdateAutoscalerCron(&deployment, *api); err != nil {
 					operatorLogger.Fatal(errors.Wrap(err, "init"))
 				}
+
+				if err := asyncapi.UpdateGatewayAutoscalerCron(&deployment, *api); err != nil {
+					operatorLogger.Fatal(errors.Wrap(err, "init"))
+				}
 			}
 		}
 	}
@@ -97,6 +137,7 @@ func main() {
 	routerWithoutAuth := router.NewRoute().Subrouter()
 	routerWithoutAuth.Use(endpoints.PanicMiddleware)
 	routerWithoutAuth.HandleFunc("/verifycortex", endpoints.VerifyCortex).Methods("GET")
+	routerWithoutAuth.HandleFunc("/oidc", endpoints.GetOIDCConfig).Methods("GET")
 
 	routerWithoutAuth.HandleFunc("/batch/{apiName}", endpoints.SubmitBatchJob).Methods("POST")
 	routerWithoutAuth.HandleFunc("/batch/{apiName}", endpoints.GetBatchJob).Methods("GET")
@@ -104,26 +145,50 @@ func main() {
 	routerWithoutAuth.HandleFunc("/tasks/{apiName}", endpoints.SubmitTaskJob).Methods("POST")
 	routerWithoutAuth.HandleFunc("/tasks/{apiName}", endpoints.GetTaskJob).Methods("GET")
 	routerWithoutAuth.HandleFunc("/tasks/{apiName}", endpoints.StopTaskJob).Methods("DELETE")
+	routerWithoutAuth.HandleFunc("/workflows/{apiName}", endpoints.SubmitWorkflowRun).Methods("POST")
+	routerWithoutAuth.HandleFunc("/workflows/{apiName}", endpoints.GetWorkflowRun).Methods("GET")
+	routerWithoutAuth.HandleFunc("/workflows/{apiName}", endpoints.StopWorkflowRun).Methods("DELETE")
 
 	// prometheus metrics
 	routerWithoutAuth.Handle("/metrics", promhttp.Handler()).Methods("GET")
 
+	if config.ClusterConfig.WebConsole {
+		routerWithoutAuth.HandleFunc("/console", endpoints.Console).Methods("GET")
+		routerWithoutAuth.HandleFunc("/console/console.js", endpoints.ConsoleScript).Methods("GET")
+	}
+
 	routerWithAuth := router.NewRoute().Subrouter()
 
 	routerWithAuth.Use(endpoints.PanicMiddleware)
 	routerWithAuth.Use(endpoints.APIVersionCheckMiddleware)
-	routerWithAuth.Use(endpoints.AWSAuthMiddleware)
+	routerWithAuth.Use(endpoints.AuthMiddleware)
 	routerWithAuth.Use(endpoints.ClientIDMiddleware)
 
-	routerWithAuth.HandleFunc("/info", endpoints.Info).Methods("GET")
-	routerWithAuth.HandleFunc("/deploy", endpoints.Deploy).Methods("POST")
-	routerWithAuth.HandleFunc("/refresh/{apiName}", endpoints.Refresh).Methods("POST")
-	routerWithAuth.HandleFunc("/delete/{apiName}", endpoints.Delete).Methods("DELETE")
-	routerWithAuth.HandleFunc("/get", endpoints.GetAPIs).Methods("GET")
-	routerWithAuth.HandleFunc("/get/{apiName}", endpoints.GetAPI).Methods("GET")
-	routerWithAuth.HandleFunc("/get/{apiName}/{apiID}", endpoints.GetAPIByID).Methods("GET")
-	routerWithAuth.HandleFunc("/streamlogs/{apiName}", endpoints.ReadLogs)
-	routerWithAuth.HandleFunc("/logs/{apiName}", endpoints.GetLogURL).Methods("GET")
+	routerWithAuth.HandleFunc("/info", endpoints.ReadAccess(endpoints.Info)).Methods("GET")
+	routerWithAuth.HandleFunc("/deploy", endpoints.DeployAccess(endpoints.Deploy)).Methods("POST")
+	routerWithAuth.HandleFunc("/deploy/diff", endpoints.ReadAccess(endpoints.DeployDiff)).Methods("POST")
+	routerWithAuth.HandleFunc("/refresh/{apiName}", endpoints.DeployAccess(endpoints.Refresh)).Methods("POST")
+	routerWithAuth.HandleFunc("/rollback/{apiName}", endpoints.DeployAccess(endpoints.Rollback)).Methods("POST")
+	routerWithAuth.HandleFunc("/batch/{apiName}/retry", endpoints.DeployAccess(endpoints.RetryBatchJob)).Methods("POST")
+	routerWithAuth.HandleFunc("/batch/{apiName}/results", endpoints.ReadAccess(endpoints.GetBatchJobResults)).Methods("GET")
+	routerWithAuth.HandleFunc("/tasks/{apiName}/retry", endpoints.DeployAccess(endpoints.RetryTaskJob)).Methods("POST")
+	routerWithAuth.HandleFunc("/async/{apiName}/{requestID}", endpoints.DeployAccess(endpoints.CancelAsyncWorkload)).Methods("DELETE")
+	routerWithAuth.HandleFunc("/delete/{apiName}", endpoints.DeployAccess(endpoints.Delete)).Methods("DELETE")
+	routerWithAuth.HandleFunc("/get", endpoints.ReadAccess(endpoints.GetAPIs)).Methods("GET")
+	routerWithAuth.HandleFunc("/get/{apiName}", endpoints.ReadAccess(endpoints.GetAPI)).Methods("GET")
+	routerWithAuth.HandleFunc("/get/{apiName}/{apiID}", endpoints.ReadAccess(endpoints.GetAPIByID)).Methods("GET")
+	routerWithAuth.HandleFunc("/recommendations/{apiName}", endpoints.ReadAccess(endpoints.GetRecommendations)).Methods("GET")
+	routerWithAuth.HandleFunc("/timeseries/{apiName}", endpoints.ReadAccess(endpoints.GetTimeSeries)).Methods("GET")
+	routerWithAuth.HandleFunc("/history/{apiName}", endpoints.ReadAccess(endpoints.GetHistory)).Methods("GET")
+	routerWithAuth.HandleFunc("/streamlogs/{apiName}", endpoints.ReadAccess(endpoints.ReadLogs))
+	routerWithAuth.HandleFunc("/exec/{apiName}", endpoints.DeployAccess(endpoints.Exec))
+	routerWithAuth.HandleFunc("/portforward/{apiName}", endpoints.DeployAccess(endpoints.PortForward))
+	routerWithAuth.HandleFunc("/logs/{apiName}", endpoints.ReadAccess(endpoints.GetLogURL)).Methods("GET")
+	routerWithAuth.HandleFunc("/logs/{apiName}/lines", endpoints.ReadAccess(endpoints.GetLogLines)).Methods("GET")
+	routerWithAuth.HandleFunc("/audit", endpoints.ReadAccess(endpoints.GetAuditEvents)).Methods("GET")
+	routerWithAuth.HandleFunc("/tokens", endpoints.AdminAccess(endpoints.CreateOperatorToken)).Methods("POST")
+	routerWithAuth.HandleFunc("/tokens", endpoints.AdminAccess(endpoints.ListOperatorTokens)).Methods("GET")
+	routerWithAuth.HandleFunc("/tokens/{tokenID}", endpoints.AdminAccess(endpoints.RevokeOperatorToken)).Methods("DELETE")
 
 	operatorLogger.Info("Running on port " + _operatorPortStr)
 
@@ -137,5 +202,5 @@ func main() {
 		handlers.AllowCredentials(),
 	}
 
-	operatorLogger.Fatal(http.ListenAndServe(":"+_operatorPortStr, handlers.CORS(corsOptions...)(router)))
+	operatorLogger.Fatal(http.ListenAndServe(":"+_operatorPortStr, handlers.CORS(corsOptions...)(endpoints.CompressionMiddleware(router))))
 }
@@ -19,11 +19,13 @@ package main
 import (
 	"context"
 	"flag"
+	"fmt"
 	"net"
 	"net/http"
 	"os"
 	"os/signal"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/cortexlabs/cortex/pkg/lib/aws"
@@ -34,6 +36,8 @@ import (
 	"github.com/cortexlabs/cortex/pkg/types/clusterconfig"
 	"github.com/cortexlabs/cortex/pkg/types/userconfig"
 	"go.uber.org/zap"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
 )
 
 const (
@@ -49,14 +53,43 @@ func main() {
 		maxConcurrency    int
 		maxQueueLength    int
 		clusterConfigPath string
+		apiName           string
+		modelManagerPort  int
+
+		upstreamTimeout      time.Duration
+		idleTimeout          time.Duration
+		maxRequestBodySize   int64
+		maxConcurrentStreams int
+
+		fairQueuingHeader        string
+		fairQueuingDefaultWeight int
+		fairQueuingWeights       = weightFlags{}
+
+		payloadLoggingS3Path        string
+		payloadLoggingSampleRate    float64
+		payloadLoggingMaxBodySize   int64
+		payloadLoggingFlushInterval time.Duration
 	)
 
 	flag.IntVar(&port, "port", 8000, "port where the proxy server will be exposed")
-	flag.IntVar(&adminPort, "admin-port", 15000, "port where the admin server (for metrics and probes) will be exposed")
+	flag.IntVar(&adminPort, "admin-port", 15100, "port where the admin server (for metrics and probes) will be exposed")
 	flag.IntVar(&userContainerPort, "user-port", 8080, "port where the proxy will redirect to the traffic to")
 	flag.IntVar(&maxConcurrency, "max-concurrency", 0, "max concurrency allowed for user container")
 	flag.IntVar(&maxQueueLength, "max-queue-length", 0, "max request queue length for user container")
 	flag.StringVar(&clusterConfigPath, "cluster-config", "", "cluster config path")
+	flag.StringVar(&apiName, "api-name", "", "api name")
+	flag.IntVar(&modelManagerPort, "model-manager-port", 0, "port of the model manager sidecar, which loads the models that requests are routed to (model routing is disabled if not set)")
+	flag.DurationVar(&upstreamTimeout, "upstream-timeout", 0, "requests which the user container doesn't finish responding to within this duration are aborted (disabled if not set)")
+	flag.DurationVar(&idleTimeout, "idle-timeout", 0, "requests are aborted if the user container doesn't send any part of its response for this duration (disabled if not set)")
+	flag.Int64Var(&maxRequestBodySize, "max-request-body-size", 0, "max size of request bodies (in bytes); larger requests are rejected (unlimited if not set)")
+	flag.IntVar(&maxConcurrentStreams, "max-concurrent-streams", 0, "max concurrent streams per HTTP/2 connection (HTTP/2 is disabled if not set)")
+	flag.StringVar(&fairQueuingHeader, "fair-queuing-header", "", "request header which identifies clients for fair queuing (fair queuing is disabled if not set)")
+	flag.IntVar(&fairQueuingDefaultWeight, "fair-queuing-default-weight", 1, "fair queuing weight of clients which don't have a weight")
+	flag.Var(fairQueuingWeights, "fair-queuing-weight", "fair queuing weight of a client, formatted as <client>=<weight> (can be specified multiple times)")
+	flag.StringVar(&payloadLoggingS3Path, "payload-logging-s3-path", "", "s3 path where sampled requests and responses will be written (payload logging is disabled if not set)")
+	flag.Float64Var(&payloadLoggingSampleRate, "payload-logging-sample-rate", 0, "fraction of requests which will be logged")
+	flag.Int64Var(&payloadLoggingMaxBodySize, "payload-logging-max-body-size", 0, "max size of logged request and response bodies (in bytes)")
+	flag.DurationVar(&payloadLoggingFlushInterval, "payload-logging-flush-interval", time.Minute, "how often logged requests and responses are written to s3")
 	flag.Parse()
 
 	log := logging.GetLogger()
@@ -105,18 +138,111 @@ func main() {
 
 	target := "http://127.0.0.1:" + strconv.Itoa(userContainerPort)
 	httpProxy := proxy.NewReverseProxy(target, maxQueueLength, maxQueueLength)
+	httpProxy.ErrorHandler = func(w http.ResponseWriter, r *http.Request, err error) {
+		log.Warnw("failed to proxy request to the user container", logging.RequestIDField, r.Header.Get(logging.RequestIDHeader), "error", err)
+		switch {
+		case err == proxy.ErrRequestBodyTooLarge:
+			http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+		case r.Context().Err() != nil:
+			// the request reached the upstream or idle timeout (or the client disconnected)
+			w.WriteHeader(http.StatusGatewayTimeout)
+		default:
+			w.WriteHeader(http.StatusBadGateway)
+		}
+	}
 
 	requestCounterStats := &proxy.RequestStats{}
-	breaker := proxy.NewBreaker(
-		proxy.BreakerParams{
-			QueueDepth:      maxQueueLength,
-			MaxConcurrency:  maxConcurrency,
-			InitialCapacity: maxConcurrency,
-		},
-	)
+
+	var breaker *proxy.Breaker
+	var fairQueue *proxy.FairQueue
+	if fairQueuingHeader != "" {
+		fairQueue = proxy.NewFairQueue(
+			proxy.FairQueueParams{
+				QueueDepth:     maxQueueLength,
+				MaxConcurrency: maxConcurrency,
+				ClientHeader:   fairQueuingHeader,
+				Weights:        fairQueuingWeights,
+				DefaultWeight:  fairQueuingDefaultWeight,
+			},
+		)
+	} else {
+		breaker = proxy.NewBreaker(
+			proxy.BreakerParams{
+				QueueDepth:      maxQueueLength,
+				MaxConcurrency:  maxConcurrency,
+				InitialCapacity: maxConcurrency,
+			},
+		)
+	}
 
 	promStats := proxy.NewPrometheusStatsReporter()
 
+	podName, _ := os.Hostname()
+	timingReporter := proxy.NewTimingReporter(podName)
+
+	var userContainerHandler http.Handler = httpProxy
+	if upstreamTimeout > 0 || idleTimeout > 0 {
+		userContainerHandler = proxy.UpstreamTimeouts(upstreamTimeout, idleTimeout, userContainerHandler)
+	}
+	userContainerHandler = timingReporter.UpstreamHandler(userContainerHandler)
+	if modelManagerPort != 0 {
+		// models are loaded after the request is admitted by the breaker, so that requests which are waiting for a model count towards the api's concurrency
+		userContainerHandler = proxy.NewModelRouter("http://127.0.0.1:" + strconv.Itoa(modelManagerPort)).Handler(userContainerHandler)
+	}
+
+	var handler http.Handler
+	if fairQueue != nil {
+		handler = timingReporter.Handler(fairQueue.Handler(userContainerHandler))
+	} else {
+		handler = timingReporter.Handler(proxy.Handler(breaker, userContainerHandler))
+	}
+	handler = proxy.NewVariantStatsReporter().Handler(handler)
+
+	var payloadLogger *proxy.PayloadLogger
+	if payloadLoggingS3Path != "" {
+		bucket, prefix, err := aws.SplitS3Path(payloadLoggingS3Path)
+		if err != nil {
+			exit(log, err)
+		}
+
+		payloadLogger = proxy.NewPayloadLogger(
+			proxy.PayloadLoggerParams{
+				APIName:     apiName,
+				PodName:     podName,
+				Bucket:      bucket,
+				Prefix:      prefix,
+				SampleRate:  payloadLoggingSampleRate,
+				MaxBodySize: payloadLoggingMaxBodySize,
+			},
+			awsClient.UploadBytesToS3,
+		)
+		handler = payloadLogger.Handler(handler)
+
+		go func() {
+			flushTicker := time.NewTicker(payloadLoggingFlushInterval)
+			defer flushTicker.Stop()
+
+			for range flushTicker.C {
+				flushPayloadLogger(payloadLogger, log)
+			}
+		}()
+	}
+
+	// the client's address is resolved before the request is logged
+	handler = proxy.RealIP(clusterConfig.NumTrustedProxies(), handler)
+
+	if maxRequestBodySize > 0 {
+		// oversized requests are rejected before they are queued
+		handler = proxy.LimitRequestBody(maxRequestBodySize, handler)
+	}
+
+	if maxConcurrentStreams > 0 {
+		// istio forwards requests over HTTP/2 without TLS if the api's service port is named http2
+		handler = h2c.NewHandler(handler, &http2.Server{
+			MaxConcurrentStreams: uint32(maxConcurrentStreams),
+		})
+	}
+
 	go func() {
 		reportTicker := time.NewTicker(_reportInterval)
 		defer reportTicker.Stop()
@@ -133,7 +259,11 @@ func main() {
 				}()
 			case <-requestSamplingTicker.C:
 				go func() {
-					requestCounterStats.Append(breaker.InFlight())
+					if fairQueue != nil {
+						requestCounterStats.Append(fairQueue.InFlight())
+					} else {
+						requestCounterStats.Append(breaker.InFlight())
+					}
 				}()
 			}
 		}
@@ -146,7 +276,7 @@ func main() {
 	servers := map[string]*http.Server{
 		"proxy": {
 			Addr:    ":" + strconv.Itoa(port),
-			Handler: proxy.Handler(breaker, httpProxy),
+			Handler: handler,
 		},
 		"admin": {
 			Addr:    ":" + strconv.Itoa(adminPort),
@@ -180,6 +310,9 @@ func main() {
 				telemetry.Error(errors.Wrap(err, "HTTP server Shutdown Error"))
 			}
 		}
+		if payloadLogger != nil {
+			flushPayloadLogger(payloadLogger, log)
+		}
 		log.Info("Shutdown complete, exiting...")
 		telemetry.Close()
 	}
@@ -202,6 +335,40 @@ func exit(log *zap.SugaredLogger, err error, wrapStrs ...string) {
 	os.Exit(1)
 }
 
+func flushPayloadLogger(payloadLogger *proxy.PayloadLogger, log *zap.SugaredLogger) {
+	dropped, err := payloadLogger.Flush()
+	if err != nil {
+		log.Warn(errors.Wrap(err, "failed to write request payloads to s3"))
+		telemetry.Error(errors.Wrap(err, "failed to write request payloads to s3"))
+	}
+	if dropped > 0 {
+		log.Warnf("dropped %d request payloads", dropped)
+	}
+}
+
+// weightFlags collects the values of a repeated <client>=<weight> flag
+type weightFlags map[string]int
+
+func (w weightFlags) String() string {
+	return fmt.Sprint(map[string]int(w))
+}
+
+func (w weightFlags) Set(value string) error {
+	// the client is split at the last "=", since the weight can't contain one
+	i := strings.LastIndex(value, "=")
+	if i < 0 {
+		return fmt.Errorf("%s is not formatted as <client>=<weight>", value)
+	}
+
+	weight, err := strconv.Atoi(value[i+1:])
+	if err != nil || weight <= 0 {
+		return fmt.Errorf("%s is not a valid weight (it must be a positive integer)", value[i+1:])
+	}
+
+	w[value[:i]] = weight
+	return nil
+}
+
 func readinessTCPHandler(port int, logger *zap.SugaredLogger) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		timeout := time.Duration(1) * time.Second
@@ -40,10 +40,10 @@ for image in "${all_images[@]}"; do
 done
 echo
 
-# pull the images from source registry and push them to ECR
+# pull the images from source registry and push them to ECR (--all copies every architecture of the multi-arch images)
 for image in "${all_images[@]}"; do
     echo "copying $image:$cortex_version from $source_registry to $destination_registry"
-    skopeo copy --src-no-creds "docker://$source_registry/$image:$cortex_version" "docker://$destination_registry/$image:$cortex_version"
+    skopeo copy --all --src-no-creds "docker://$source_registry/$image:$cortex_version" "docker://$destination_registry/$image:$cortex_version"
     echo
 done
 echo "done ✓"
@@ -10,6 +10,7 @@ require (
 	github.com/aws/aws-sdk-go v1.37.23
 	github.com/bmizerany/assert v0.0.0-20160611221934-b7ed37b82869 // indirect
 	github.com/containerd/containerd v1.4.3 // indirect
+	github.com/coreos/go-oidc/v3 v3.2.0
 	github.com/cortexlabs/go-input v0.0.0-20200503032952-8b67a7a7b28d
 	github.com/cortexlabs/yaml v0.0.0-20200511220111-581aea36a2e4
 	github.com/danwakefield/fnmatch v0.0.0-20160403171240-cbb64ac3d964 // indirect
@@ -21,11 +22,13 @@ require (
 	github.com/getsentry/sentry-go v0.10.0
 	github.com/go-logr/logr v0.3.0
 	github.com/go-ole/go-ole v1.2.4 // indirect
+	github.com/gogo/protobuf v1.3.1
 	github.com/gobwas/glob v0.2.3
 	github.com/google/uuid v1.1.2
 	github.com/gorilla/handlers v1.5.1
 	github.com/gorilla/mux v1.8.0
 	github.com/gorilla/websocket v1.4.2
+	github.com/klauspost/compress v1.13.6
 	github.com/mitchellh/go-homedir v1.1.0
 	github.com/morikuni/aec v1.0.0 // indirect
 	github.com/onsi/ginkgo v1.14.1
@@ -48,7 +51,7 @@ require (
 	go.uber.org/atomic v1.6.0
 	go.uber.org/zap v1.15.0
 	golang.org/x/mod v0.4.2 // indirect
-	golang.org/x/net v0.0.0-20201202161906-c7110b5ffcbb // indirect
+	golang.org/x/net v0.0.0-20201202161906-c7110b5ffcbb
 	golang.org/x/oauth2 v0.0.0-20201203001011-0b49973bad19 // indirect
 	golang.org/x/sys v0.0.0-20210420205809-ac73e9fd8988 // indirect
 	golang.org/x/tools v0.1.0 // indirect
@@ -19,8 +19,38 @@ set +e
 CORTEX_VERSION_MINOR=master
 
 debug_out_path="$1"
+shift
 mkdir -p "$(dirname "$debug_out_path")"
 
+collectors="manifests,events,logs,metrics,aws"
+redact="false"
+for arg in "$@"; do
+  case "$arg" in
+    --collectors=*) collectors="${arg#*=}" ;;
+    --redact) redact="true" ;;
+  esac
+done
+
+function should_collect() {
+  [[ ",$collectors," == *",$1,"* ]]
+}
+
+# replaces environment variable values, configmap data, and last-applied configurations (which may contain either) in the output of `kubectl get -o json`
+redact_filter='
+  walk(
+    if type == "object" then
+      (if (.env | type) == "array" then .env |= map(if has("value") then .value = "REDACTED" else . end) else . end)
+      | (if has("kubectl.kubernetes.io/last-applied-configuration") then .["kubectl.kubernetes.io/last-applied-configuration"] = "REDACTED" else . end)
+    else . end
+  )
+  | .items |= map(
+    if .kind == "ConfigMap" then
+      (if .data then .data |= map_values("REDACTED") else . end)
+      | (if .binaryData then .binaryData |= map_values("REDACTED") else . end)
+    else . end
+  )
+'
+
 if ! eksctl utils describe-stacks --cluster=$CORTEX_CLUSTER_NAME --region=$CORTEX_REGION >/dev/null 2>&1; then
   echo "error: there is no cluster named \"$CORTEX_CLUSTER_NAME\" in $CORTEX_REGION; please update your configuration to point to an existing cortex cluster or create a cortex cluster with \`cortex cluster up\`"
   exit 1
@@ -32,54 +62,90 @@ out=$(kubectl get pods 2>&1 || true); if [[ "$out" == *"must be logged in to the
 echo -n "gathering cluster data"
 
 mkdir -p /cortex-debug/k8s
-for resource in pods pods.metrics nodes nodes.metrics daemonsets deployments hpa services virtualservices gateways ingresses configmaps jobs replicasets events; do
-  kubectl describe $resource --all-namespaces > "/cortex-debug/k8s/${resource}" 2>&1
-  kubectl get $resource --all-namespaces > "/cortex-debug/k8s/${resource}-list" 2>&1
+
+if should_collect "manifests"; then
+  for resource in pods nodes daemonsets deployments hpa services virtualservices gateways ingresses configmaps jobs replicasets; do
+    kubectl get $resource --all-namespaces > "/cortex-debug/k8s/${resource}-list" 2>&1
+    if [ "$redact" = "true" ]; then
+      # the output of describe can't be reliably redacted, so the redacted json is saved instead
+      kubectl get $resource --all-namespaces -o json 2>/dev/null | jq "$redact_filter" > "/cortex-debug/k8s/${resource}.json" 2>&1
+    else
+      kubectl describe $resource --all-namespaces > "/cortex-debug/k8s/${resource}" 2>&1
+    fi
+    echo -n "."
+  done
+fi
+
+if should_collect "events"; then
+  kubectl describe events --all-namespaces > "/cortex-debug/k8s/events" 2>&1
+  kubectl get events --all-namespaces > "/cortex-debug/k8s/events-list" 2>&1
   echo -n "."
-done
+fi
 
-mkdir -p /cortex-debug/logs
-kubectl get pods --all-namespaces -o json | jq '.items[] | . as $parent | $parent.spec.containers[]? | "kubectl logs -n \($parent.metadata.namespace) \($parent.metadata.name) \(.name) --timestamps --tail=10000 > /cortex-debug/logs/\($parent.metadata.namespace).\($parent.metadata.name).\(.name) 2>&1; echo -n ."' | xargs -n 1 bash -c
-kubectl get pods --all-namespaces -o json | jq '.items[] | . as $parent | $parent.spec.containers[]? | "kubectl logs -n \($parent.metadata.namespace) \($parent.metadata.name) \(.name) --previous --timestamps --tail=10000 > /cortex-debug/logs/\($parent.metadata.namespace).\($parent.metadata.name).\(.name).previous 2>&1; if [ $? -ne 0 ]; then rm /cortex-debug/logs/\($parent.metadata.namespace).\($parent.metadata.name).\(.name).previous; fi; echo -n ."' | xargs -n 1 bash -c
-echo -n "."
-kubectl get pods --all-namespaces -o json | jq '.items[] | . as $parent | $parent.spec.initContainers[]? | "kubectl logs -n \($parent.metadata.namespace) \($parent.metadata.name) \(.name) --timestamps --tail=10000 > /cortex-debug/logs/\($parent.metadata.namespace).\($parent.metadata.name).init.\(.name) 2>&1; echo -n ."' | xargs -n 1 bash -c
-kubectl get pods --all-namespaces -o json | jq '.items[] | . as $parent | $parent.spec.initContainers[]? | "kubectl logs -n \($parent.metadata.namespace) \($parent.metadata.name) \(.name) --previous --timestamps --tail=10000 > /cortex-debug/logs/\($parent.metadata.namespace).\($parent.metadata.name).init.\(.name).previous 2>&1; if [ $? -ne 0 ]; then rm /cortex-debug/logs/\($parent.metadata.namespace).\($parent.metadata.name).init.\(.name).previous; fi; echo -n ."' | xargs -n 1 bash -c
-echo -n "."
-
-kubectl top pods --all-namespaces --containers=true > "/cortex-debug/k8s/top_pods" 2>&1
-echo -n "."
-kubectl top nodes > "/cortex-debug/k8s/top_nodes" 2>&1
-echo -n "."
-
-mkdir -p /cortex-debug/aws/amis
-
-aws autoscaling describe-auto-scaling-groups --region=$CORTEX_REGION --output json > "/cortex-debug/aws/asgs" 2>&1
-echo -n "."
-aws autoscaling describe-scaling-activities --max-items 1000 --region=$CORTEX_REGION --output json > "/cortex-debug/aws/asg-activities" 2>&1
-echo -n "."
-
-aws ec2 describe-instances --filters Name=tag:cortex.dev/cluster-name,Values=$CORTEX_CLUSTER_NAME --region=$CORTEX_REGION --output json > "/cortex-debug/aws/instances" 2>&1
-echo -n "."
-aws ec2 describe-instance-status --include-all-instances --region=$CORTEX_REGION --output json > "/cortex-debug/aws/instance-statuses" 2>&1
-echo -n "."
-aws ec2 describe-instances --filters Name=tag:cortex.dev/cluster-name,Values=$CORTEX_CLUSTER_NAME --region=$CORTEX_REGION --output json | jq "[.Reservations[].Instances[].ImageId] | unique | .[] | \"aws ec2 describe-images --image-ids \(.) --region=$CORTEX_REGION --output json > /cortex-debug/aws/amis/\(.) 2>&1\"" | xargs -n 1 bash -c
-echo -n "."
-python get_operator_load_balancer_state.py > "/cortex-debug/aws/operator_load_balancer_state" 2>&1
-echo -n "."
-python get_api_load_balancer_state.py > "/cortex-debug/aws/api_load_balancer_state" 2>&1
-echo -n "."
-python get_operator_target_group_status.py > "/cortex-debug/aws/operator_load_balancer_target_group_status" 2>&1
-echo -n "."
-
-mkdir -p /cortex-debug/misc
-operator_endpoint=$(kubectl -n=istio-system get service ingressgateway-operator -o json 2>/dev/null | tr -d '[:space:]' | sed 's/.*{\"hostname\":\"\(.*\)\".*/\1/')
-echo "$operator_endpoint" > /cortex-debug/misc/operator_endpoint
-if [ "$operator_endpoint" == "" ]; then
-  echo "unable to get operator endpoint" > /cortex-debug/misc/operator_curl
-else
-  curl -sv --max-time 5 "${operator_endpoint}/verifycortex" > /cortex-debug/misc/operator_curl 2>&1
+if should_collect "metrics"; then
+  for resource in pods.metrics nodes.metrics; do
+    kubectl describe $resource --all-namespaces > "/cortex-debug/k8s/${resource}" 2>&1
+    kubectl get $resource --all-namespaces > "/cortex-debug/k8s/${resource}-list" 2>&1
+    echo -n "."
+  done
+  kubectl top pods --all-namespaces --containers=true > "/cortex-debug/k8s/top_pods" 2>&1
+  echo -n "."
+  kubectl top nodes > "/cortex-debug/k8s/top_nodes" 2>&1
+  echo -n "."
 fi
-echo -n "."
+
+if should_collect "logs"; then
+  mkdir -p /cortex-debug/logs
+  kubectl get pods --all-namespaces -o json | jq '.items[] | . as $parent | $parent.spec.containers[]? | "kubectl logs -n \($parent.metadata.namespace) \($parent.metadata.name) \(.name) --timestamps --tail=10000 > /cortex-debug/logs/\($parent.metadata.namespace).\($parent.metadata.name).\(.name) 2>&1; echo -n ."' | xargs -n 1 bash -c
+  kubectl get pods --all-namespaces -o json | jq '.items[] | . as $parent | $parent.spec.containers[]? | "kubectl logs -n \($parent.metadata.namespace) \($parent.metadata.name) \(.name) --previous --timestamps --tail=10000 > /cortex-debug/logs/\($parent.metadata.namespace).\($parent.metadata.name).\(.name).previous 2>&1; if [ $? -ne 0 ]; then rm /cortex-debug/logs/\($parent.metadata.namespace).\($parent.metadata.name).\(.name).previous; fi; echo -n ."' | xargs -n 1 bash -c
+  echo -n "."
+  kubectl get pods --all-namespaces -o json | jq '.items[] | . as $parent | $parent.spec.initContainers[]? | "kubectl logs -n \($parent.metadata.namespace) \($parent.metadata.name) \(.name) --timestamps --tail=10000 > /cortex-debug/logs/\($parent.metadata.namespace).\($parent.metadata.name).init.\(.name) 2>&1; echo -n ."' | xargs -n 1 bash -c
+  kubectl get pods --all-namespaces -o json | jq '.items[] | . as $parent | $parent.spec.initContainers[]? | "kubectl logs -n \($parent.metadata.namespace) \($parent.metadata.name) \(.name) --previous --timestamps --tail=10000 > /cortex-debug/logs/\($parent.metadata.namespace).\($parent.metadata.name).init.\(.name).previous 2>&1; if [ $? -ne 0 ]; then rm /cortex-debug/logs/\($parent.metadata.namespace).\($parent.metadata.name).init.\(.name).previous; fi; echo -n ."' | xargs -n 1 bash -c
+  echo -n "."
+fi
+
+if should_collect "aws"; then
+  mkdir -p /cortex-debug/aws/amis
+
+  aws autoscaling describe-auto-scaling-groups --region=$CORTEX_REGION --output json > "/cortex-debug/aws/asgs" 2>&1
+  echo -n "."
+  aws autoscaling describe-scaling-activities --max-items 1000 --region=$CORTEX_REGION --output json > "/cortex-debug/aws/asg-activities" 2>&1
+  echo -n "."
+
+  aws ec2 describe-instances --filters Name=tag:cortex.dev/cluster-name,Values=$CORTEX_CLUSTER_NAME --region=$CORTEX_REGION --output json > "/cortex-debug/aws/instances" 2>&1
+  echo -n "."
+  aws ec2 describe-instance-status --include-all-instances --region=$CORTEX_REGION --output json > "/cortex-debug/aws/instance-statuses" 2>&1
+  echo -n "."
+  aws ec2 describe-instances --filters Name=tag:cortex.dev/cluster-name,Values=$CORTEX_CLUSTER_NAME --region=$CORTEX_REGION --output json | jq "[.Reservations[].Instances[].ImageId] | unique | .[] | \"aws ec2 describe-images --image-ids \(.) --region=$CORTEX_REGION --output json > /cortex-debug/aws/amis/\(.) 2>&1\"" | xargs -n 1 bash -c
+  echo -n "."
+  python get_operator_load_balancer_state.py > "/cortex-debug/aws/operator_load_balancer_state" 2>&1
+  echo -n "."
+  python get_api_load_balancer_state.py > "/cortex-debug/aws/api_load_balancer_state" 2>&1
+  echo -n "."
+  python get_operator_target_group_status.py > "/cortex-debug/aws/operator_load_balancer_target_group_status" 2>&1
+  echo -n "."
+
+  mkdir -p /cortex-debug/misc
+  operator_endpoint=$(kubectl -n=istio-system get service ingressgateway-operator -o json 2>/dev/null | tr -d '[:space:]' | sed 's/.*{\"hostname\":\"\(.*\)\".*/\1/')
+  echo "$operator_endpoint" > /cortex-debug/misc/operator_endpoint
+  if [ "$operator_endpoint" == "" ]; then
+    echo "unable to get operator endpoint" > /cortex-debug/misc/operator_curl
+  else
+    curl -sv --max-time 5 "${operator_endpoint}/verifycortex" > /cortex-debug/misc/operator_curl 2>&1
+  fi
+  echo -n "."
+fi
+
+jq -n \
+  --arg cortex_version "$CORTEX_CLI_VERSION" \
+  --arg cluster_name "$CORTEX_CLUSTER_NAME" \
+  --arg region "$CORTEX_REGION" \
+  --arg created_at "$(date -u +%Y-%m-%dT%H:%M:%SZ)" \
+  --arg collectors "$collectors" \
+  --argjson redacted "$redact" \
+  --argjson files "$(cd /cortex-debug && find . -type f | sed 's|^\./||' | sort | jq -R . | jq -s .)" \
+  '{cortex_version: $cortex_version, cluster_name: $cluster_name, region: $region, created_at: $created_at, collectors: ($collectors | split(",")), redacted: $redacted, files: $files}' \
+  > /cortex-debug/index.json
 
 (cd / && tar -czf cortex-debug.tgz cortex-debug)
 mv /cortex-debug.tgz $debug_out_path
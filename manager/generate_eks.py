@@ -13,6 +13,7 @@
 # limitations under the License.
 
 import json
+import re
 import sys
 
 import yaml
@@ -25,7 +26,7 @@ def default_nodegroup(cluster_config):
     partition = "aws"
     if "us-gov" in cluster_config["region"]:
         partition = "aws-us-gov"
-    return {
+    nodegroup = {
         "iam": {
             "withAddonPolicies": {"autoScaler": True},
             "attachPolicyARNs": [
@@ -38,6 +39,7 @@ def default_nodegroup(cluster_config):
             + cluster_config.get("iam_policy_arns", []),
         },
         "privateNetworking": cluster_config.get("subnet_visibility", "public") != "public",
+        "disableIMDSv1": cluster_config.get("require_imdsv2", False),
         "kubeletExtraConfig": {
             "kubeReserved": {"cpu": "150m", "memory": "300Mi", "ephemeral-storage": "1Gi"},
             "kubeReservedCgroup": "/kube-reserved",
@@ -47,6 +49,13 @@ def default_nodegroup(cluster_config):
         },
     }
 
+    # eksctl installs the SSM agent (if the AMI doesn't include it) and attaches the AmazonSSMManagedInstanceCore policy to the instances' role;
+    # karpenter's instances use the operator node group's instance profile, so they are also accessible
+    if cluster_config.get("ssm_access", False):
+        nodegroup["ssh"] = {"allow": False, "enableSsm": True}
+
+    return nodegroup
+
 
 def merge_override(a, b):
     "merges b into a"
@@ -90,6 +99,9 @@ def apply_clusterconfig(nodegroup, config):
         clusterconfig_settings["volumeIOPS"] = config["instance_volume_iops"]
     if config["instance_volume_type"] == "gp3":
         clusterconfig_settings["volumeThroughput"] = config["instance_volume_throughput"]
+    # eksctl runs the commands in the instances' user data before bootstrapping them (with powershell on windows)
+    if config.get("pre_bootstrap_commands"):
+        clusterconfig_settings["preBootstrapCommands"] = config["pre_bootstrap_commands"]
 
     return merge_override(nodegroup, clusterconfig_settings)
 
@@ -105,14 +117,30 @@ def apply_spot_settings(nodegroup, config):
                 "on_demand_percentage_above_base_capacity"
             ],
             "maxPrice": config["spot_config"]["max_price"],
-            "spotInstancePools": config["spot_config"]["instance_pools"],
+            "spotAllocationStrategy": config["spot_config"]["allocation_strategy"],
         },
         "labels": {"lifecycle": "Ec2Spot"},
     }
 
+    # spot instance pools can only be set for the lowest-price allocation strategy
+    if config["spot_config"].get("instance_pools") is not None:
+        spot_settings["instancesDistribution"]["spotInstancePools"] = config["spot_config"][
+            "instance_pools"
+        ]
+
     return merge_override(nodegroup, spot_settings)
 
 
+def apply_on_demand_backup_settings(nodegroup, config):
+    # the backup starts empty, and is only scaled up by the cluster autoscaler when the spot node group can't be (see the priority expander in cluster-autoscaler.yaml.j2)
+    on_demand_backup_settings = {
+        "minSize": 0,
+        "desiredCapacity": 0,
+    }
+
+    return merge_override(nodegroup, on_demand_backup_settings)
+
+
 def apply_gpu_settings(nodegroup):
     gpu_settings = {
         "tags": {
@@ -134,6 +162,71 @@ def is_gpu(instance_type):
     return instance_type.startswith("g") or instance_type.startswith("p")
 
 
+def apply_gpu_sharing_settings(nodegroup, config):
+    sharing_factor = config["gpu_sharing_factor"]
+    gpu_sharing_settings = {
+        "tags": {
+            "k8s.io/cluster-autoscaler/node-template/label/cortex.dev/gpu-sharing-factor": str(
+                sharing_factor
+            ),
+        },
+        "labels": {"cortex.dev/gpu-sharing-factor": str(sharing_factor)},
+    }
+    # the nvidia device plugin advertises the time-sliced replicas of the GPUs as nvidia.com/gpu.shared,
+    # which the cluster autoscaler can't infer from the instance type when scaling up from zero
+    num_gpus = get_num_gpus(config["instance_type"])
+    if num_gpus is not None:
+        shared_gpus_tag = "k8s.io/cluster-autoscaler/node-template/resources/nvidia.com/gpu.shared"
+        gpu_sharing_settings["tags"][shared_gpus_tag] = str(num_gpus * sharing_factor)
+
+    return merge_override(nodegroup, gpu_sharing_settings)
+
+
+def shares_gpus(config):
+    return config.get("gpu_sharing_factor", 1) > 1
+
+
+def get_num_gpus(instance_type):
+    if instance_type in [
+        "p2.8xlarge",
+        "p3.16xlarge",
+        "p3dn.24xlarge",
+        "p4d.24xlarge",
+        "g4dn.metal",
+        "g5.48xlarge",
+    ]:
+        return 8
+    if instance_type in ["g3.16xlarge", "p3.8xlarge", "g4dn.12xlarge", "g5.12xlarge", "g5.24xlarge"]:
+        return 4
+    if instance_type == "g3.8xlarge":
+        return 2
+    if instance_type == "p2.16xlarge":
+        return 16
+    if instance_type.split(".")[0] in ["g3s", "g3", "p2", "p3", "g4dn", "g5"]:
+        return 1
+    return None
+
+
+def apply_arm_settings(nodegroup):
+    arm_settings = {
+        "tags": {
+            # the cluster autoscaler assumes amd64 nodes unless the node group is tagged otherwise
+            "k8s.io/cluster-autoscaler/node-template/label/kubernetes.io/arch": "arm64",
+        },
+    }
+
+    return merge_override(nodegroup, arm_settings)
+
+
+def is_arm(instance_type):
+    # instance types take the form [family][generation][capabilities].[size] (e.g. m6gd.large), where the "a" family and the "g" capability are ARM (graviton)
+    match = re.match(r"^([a-z]+)([0-9]+)([a-z\-]*)\.", instance_type)
+    if match is None:
+        return False
+    family, _, capabilities = match.groups()
+    return family == "a" or "g" in capabilities
+
+
 def apply_inf_settings(nodegroup, config):
     instance_type = config["instance_type"]
 
@@ -145,32 +238,126 @@ def apply_inf_settings(nodegroup, config):
             "k8s.io/cluster-autoscaler/node-template/resources/aws.amazon.com/neuron": str(
                 num_chips
             ),
-            "k8s.io/cluster-autoscaler/node-template/resources/hugepages-2Mi": hugepages_mem,
         },
         "labels": {"aws.amazon.com/neuron": "true"},
         "taints": {"aws.amazon.com/neuron": "true:NoSchedule"},
     }
+    # only inf1 instances require hugepages for the neuron runtime
+    if hugepages_mem is not None:
+        hugepages_tag = "k8s.io/cluster-autoscaler/node-template/resources/hugepages-2Mi"
+        inf_settings["tags"][hugepages_tag] = hugepages_mem
     return merge_override(nodegroup, inf_settings)
 
 
 def is_inf(instance_type):
-    return instance_type.startswith("inf")
+    # inferentia (inf1/inf2) and trainium (trn1/trn1n) instances both expose their chips as neuron devices
+    return instance_type.startswith("inf") or instance_type.startswith("trn")
 
 
 def get_inf_resources(instance_type):
     num_chips = 0
-    if instance_type in ["inf1.xlarge", "inf1.2xlarge"]:
+    if instance_type in [
+        "inf1.xlarge",
+        "inf1.2xlarge",
+        "inf2.xlarge",
+        "inf2.8xlarge",
+        "trn1.2xlarge",
+    ]:
         num_chips = 1
     elif instance_type == "inf1.6xlarge":
         num_chips = 4
-    elif instance_type == "inf1.24xlarge":
+    elif instance_type == "inf2.24xlarge":
+        num_chips = 6
+    elif instance_type == "inf2.48xlarge":
+        num_chips = 12
+    elif instance_type in ["inf1.24xlarge", "trn1.32xlarge", "trn1n.32xlarge"]:
         num_chips = 16
 
+    if not instance_type.startswith("inf1."):
+        return num_chips, None
+
     return num_chips, f"{128 * num_chips}Mi"
 
 
-def get_all_worker_nodegroups(ami_map: dict, cluster_config: dict) -> list:
+def apply_bottlerocket_settings(nodegroup):
+    # bottlerocket doesn't run the EKS bootstrap script, so the kubelet is configured with bottlerocket's settings instead
+    # (see https://github.com/bottlerocket-os/bottlerocket#kubernetes-settings); eksctl selects the bottlerocket AMI
+    kubelet_config = nodegroup.pop("kubeletExtraConfig")
+    nodegroup.pop("ami", None)
+    bottlerocket_settings = {
+        "amiFamily": "Bottlerocket",
+        "bottlerocket": {
+            "settings": {
+                "kubernetes": {
+                    "kube-reserved": kubelet_config["kubeReserved"],
+                    "system-reserved": kubelet_config["systemReserved"],
+                    "eviction-hard": kubelet_config["evictionHard"],
+                    "registry-qps": kubelet_config["registryPullQPS"],
+                },
+            },
+        },
+    }
+
+    return merge_override(nodegroup, bottlerocket_settings)
+
+
+def is_bottlerocket(config):
+    return config.get("ami_family", "al2") == "bottlerocket"
+
+
+def apply_windows_settings(nodegroup):
+    # eksctl selects the windows AMI, and doesn't support configuring the kubelet of windows nodes;
+    # the taint keeps the cluster's linux workloads (and non-windows apis) off of the windows instances
+    nodegroup.pop("kubeletExtraConfig")
+    nodegroup.pop("ami", None)
+    windows_settings = {
+        "amiFamily": "WindowsServer2019CoreContainer",
+        "taints": {"os": "windows:NoSchedule"},
+        "tags": {
+            # the cluster autoscaler assumes linux nodes unless the node group is tagged otherwise
+            "k8s.io/cluster-autoscaler/node-template/label/kubernetes.io/os": "windows",
+            "k8s.io/cluster-autoscaler/node-template/taint/os": "windows:NoSchedule",
+        },
+    }
+
+    return merge_override(nodegroup, windows_settings)
+
+
+def is_windows(config):
+    return config.get("ami_family", "al2") == "windows"
+
+
+def to_managed_nodegroup(nodegroup):
+    # eksctl doesn't support custom AMIs (without a bootstrap command), the kubelet config, or suspending the auto scaling group's processes
+    # for managed node groups (EKS selects the AMI based on the instance type)
+    for key in ["ami", "kubeletExtraConfig", "asgSuspendProcesses"]:
+        nodegroup.pop(key, None)
+
+    # the taints of managed node groups are a list
+    taints = []
+    for key, value_and_effect in nodegroup.get("taints", {}).items():
+        value, effect = value_and_effect.split(":")
+        taints.append({"key": key, "value": value, "effect": effect})
+    nodegroup["taints"] = taints
+
+    # managed node groups launch spot instances with the capacity-optimized allocation strategy
+    if "instancesDistribution" in nodegroup:
+        instances_distribution = nodegroup.pop("instancesDistribution")
+        nodegroup.pop("instanceType")
+        nodegroup["instanceTypes"] = instances_distribution["instanceTypes"]
+        nodegroup["spot"] = True
+
+    return nodegroup
+
+
+def is_managed(config):
+    return config.get("managed", False)
+
+
+# returns the self-managed and the EKS managed worker node groups
+def get_all_worker_nodegroups(ami_map: dict, cluster_config: dict) -> tuple:
     worker_nodegroups = []
+    managed_nodegroups = []
     for ng in cluster_config["node_groups"]:
         worker_nodegroup = default_nodegroup(cluster_config)
         worker_nodegroup["ami"] = get_ami(ami_map, ng["instance_type"])
@@ -183,18 +370,62 @@ def get_all_worker_nodegroups(ami_map: dict, cluster_config: dict) -> list:
 
         if is_gpu(ng["instance_type"]):
             apply_gpu_settings(worker_nodegroup)
+            if shares_gpus(ng):
+                apply_gpu_sharing_settings(worker_nodegroup, ng)
+
+        if is_arm(ng["instance_type"]):
+            apply_arm_settings(worker_nodegroup)
 
         if is_inf(ng["instance_type"]):
             apply_inf_settings(worker_nodegroup, ng)
 
-        worker_nodegroups.append(worker_nodegroup)
+        if is_bottlerocket(ng):
+            apply_bottlerocket_settings(worker_nodegroup)
+
+        if is_windows(ng):
+            apply_windows_settings(worker_nodegroup)
+
+        if is_managed(ng):
+            managed_nodegroups.append(to_managed_nodegroup(worker_nodegroup))
+        else:
+            worker_nodegroups.append(worker_nodegroup)
+
+        if ng["spot"] and ng["spot_config"].get("on_demand_backup", False):
+            backup_nodegroup = default_nodegroup(cluster_config)
+            backup_nodegroup["ami"] = get_ami(ami_map, ng["instance_type"])
+
+            apply_worker_settings(backup_nodegroup, ng)
+            apply_clusterconfig(backup_nodegroup, ng)
+            apply_on_demand_backup_settings(backup_nodegroup, ng)
 
-    return worker_nodegroups
+            if is_gpu(ng["instance_type"]):
+                apply_gpu_settings(backup_nodegroup)
+                if shares_gpus(ng):
+                    apply_gpu_sharing_settings(backup_nodegroup, ng)
+
+            if is_arm(ng["instance_type"]):
+                apply_arm_settings(backup_nodegroup)
+
+            if is_inf(ng["instance_type"]):
+                apply_inf_settings(backup_nodegroup, ng)
+
+            if is_bottlerocket(ng):
+                apply_bottlerocket_settings(backup_nodegroup)
+
+            if is_windows(ng):
+                apply_windows_settings(backup_nodegroup)
+
+            worker_nodegroups.append(backup_nodegroup)
+
+    return worker_nodegroups, managed_nodegroups
 
 
 def get_ami(ami_map: dict, instance_type: str) -> str:
     if is_gpu(instance_type) or is_inf(instance_type):
         return ami_map["accelerated"]
+    if is_arm(instance_type):
+        # fall back to eksctl's AMI resolution (which selects the arm64 AMI based on the instance type) for regions which aren't in ami.json yet
+        return ami_map.get("arm", "auto")
     return ami_map["cpu"]
 
 
@@ -222,7 +453,10 @@ def generate_eks(cluster_config_path, ami_json_path):
     }
     operator_nodegroup = merge_override(operator_nodegroup, operator_settings)
 
-    worker_nodegroups = get_all_worker_nodegroups(ami_map, cluster_config)
+    # the worker instances of clusters which use karpenter are launched by karpenter (from the provisioners which the operator generates for the node groups)
+    worker_nodegroups, managed_nodegroups = [], []
+    if cluster_config.get("node_provisioner", "cluster-autoscaler") != "karpenter":
+        worker_nodegroups, managed_nodegroups = get_all_worker_nodegroups(ami_map, cluster_config)
 
     nat_gateway = "Disable"
     if cluster_config["nat_gateway"] == "single":
@@ -249,6 +483,9 @@ def generate_eks(cluster_config_path, ami_json_path):
         ],
     }
 
+    if len(managed_nodegroups) > 0:
+        eks["managedNodeGroups"] = managed_nodegroups
+
     if (
         len(cluster_config.get("availability_zones", [])) > 0
         and len(cluster_config.get("subnets", [])) == 0
@@ -12,6 +12,8 @@
 # See the License for the specific language governing permissions and
 # limitations under the License.
 
+import hashlib
+
 
 def get_operator_load_balancer(cluster_name, client_elbv2):
     return _get_load_balancer("operator", cluster_name, client_elbv2)
@@ -21,7 +23,19 @@ def get_api_load_balancer(cluster_name, client_elbv2):
     return _get_load_balancer("api", cluster_name, client_elbv2)
 
 
+# returns None if the cluster's api load balancer hasn't been created
+def find_api_load_balancer(cluster_name, client_elbv2):
+    return _find_load_balancer("api", cluster_name, client_elbv2)
+
+
 def _get_load_balancer(load_balancer_tag, cluster_name, client_elbv2):
+    load_balancer = _find_load_balancer(load_balancer_tag, cluster_name, client_elbv2)
+    if load_balancer is None:
+        raise Exception(f"unable to find {load_balancer_tag} load balancer")
+    return load_balancer
+
+
+def _find_load_balancer(load_balancer_tag, cluster_name, client_elbv2):
     paginator = client_elbv2.get_paginator("describe_load_balancers")
     for load_balancer_page in paginator.paginate(PaginationConfig={"PageSize": 20}):
         load_balancers = {
@@ -42,4 +56,73 @@ def _get_load_balancer(load_balancer_tag, cluster_name, client_elbv2):
             if foundClusterNameTag and foundLoadBalancerTag:
                 return load_balancers[tag_description["ResourceArn"]]
 
-    raise Exception(f"unable to find {load_balancer_tag} load balancer")
+    return None
+
+
+def get_efs_security_group_name(cluster_name):
+    return f"cortex-{cluster_name}-efs"
+
+
+def get_api_load_balancer_security_group_name(cluster_name):
+    return f"cortex-{cluster_name}-api-load-balancer"
+
+
+# the names of application load balancers and target groups are limited to 32 characters
+def get_api_load_balancer_name(cluster_name, region):
+    suffix = hashlib.sha256(f"{cluster_name}-{region}".encode()).hexdigest()[:16]
+    return f"cx-api-{suffix}"
+
+
+def get_operator_auto_scaling_group_names(cluster_name, client_autoscaling):
+    paginator = client_autoscaling.get_paginator("describe_auto_scaling_groups")
+    auto_scaling_group_names = []
+    for page in paginator.paginate(
+        Filters=[
+            {"Name": "tag:cortex.dev/cluster-name", "Values": [cluster_name]},
+            {"Name": "tag:alpha.eksctl.io/nodegroup-name", "Values": ["cx-operator"]},
+        ]
+    ):
+        for auto_scaling_group in page["AutoScalingGroups"]:
+            auto_scaling_group_names.append(auto_scaling_group["AutoScalingGroupName"])
+    return auto_scaling_group_names
+
+
+def get_api_gateway(cluster_name, client_apigatewayv2):
+    for api in paginate(client_apigatewayv2.get_apis, "Items"):
+        if api.get("Tags", {}).get("cortex.dev/cluster-name") == cluster_name:
+            return api
+    return None
+
+
+def get_vpc_link(cluster_name, client_apigatewayv2):
+    for vpc_link in paginate(client_apigatewayv2.get_vpc_links, "Items"):
+        if vpc_link.get("Tags", {}).get("cortex.dev/cluster-name") == cluster_name:
+            return vpc_link
+    return None
+
+
+# the apigatewayv2 client doesn't provide paginators
+def paginate(method, items_key, **kwargs):
+    next_token = None
+    while True:
+        if next_token is not None:
+            kwargs["NextToken"] = next_token
+        response = method(**kwargs)
+        yield from response[items_key]
+        next_token = response.get("NextToken")
+        if not next_token:
+            return
+
+
+# returns the nat gateways in the vpc which are pending or available (i.e. not deleted or failed)
+def get_nat_gateways(vpc_id, client_ec2):
+    return list(
+        paginate(
+            client_ec2.describe_nat_gateways,
+            "NatGateways",
+            Filter=[
+                {"Name": "vpc-id", "Values": [vpc_id]},
+                {"Name": "state", "Values": ["pending", "available"]},
+            ],
+        )
+    )
@@ -19,6 +19,7 @@ set -eo pipefail
 export CORTEX_VERSION=master
 export CORTEX_VERSION_MINOR=master
 EKSCTL_TIMEOUT=45m
+KARPENTER_VERSION=v0.27.6
 mkdir /workspace
 
 arg1="$1"
@@ -26,29 +27,110 @@ arg1="$1"
 function main() {
   if [ "$arg1" = "--update" ]; then
     cluster_configure
+  elif [ "$arg1" = "--install" ]; then
+    cluster_install
+  elif [ "$arg1" = "--dev" ]; then
+    export CORTEX_DEV_CLUSTER=true
+    cluster_dev
   else
     cluster_up
   fi
 }
 
 function cluster_up() {
-  create_eks
+  # the cli sets CORTEX_RESUME_PHASE when resuming a `cortex cluster up` which failed after the control plane was created
+  if [ "$CORTEX_RESUME_PHASE" = "nodegroups" ]; then
+    resume_nodegroups
+  elif [ "$CORTEX_RESUME_PHASE" != "" ]; then
+    write_kubeconfig
+  else
+    create_eks
+  fi
+
+  install_cortex
+}
 
+# installs cortex onto an existing cluster (the kubeconfig is provided by the cli)
+function cluster_install() {
+  check_kubeconfig
+
+  install_cortex
+}
+
+# installs cortex onto a local kind cluster, using minio and elasticmq in place of s3 and sqs
+function cluster_dev() {
   echo -n "￮ updating cluster configuration "
   setup_configmap
   echo "✓"
 
+  echo -n "￮ starting minio and elasticmq "
+  envsubst < manifests/dev-services.yaml | kubectl apply -f - >/dev/null
+  kubectl -n=default rollout status deployment minio --timeout=5m >/dev/null
+  kubectl -n=default rollout status deployment elasticmq --timeout=5m >/dev/null
+  echo "✓"
+
   echo -n "￮ configuring networking (this might take a few minutes) "
   setup_istio
   python render_template.py $CORTEX_CLUSTER_CONFIG_FILE manifests/apis.yaml.j2 > /workspace/apis.yaml
   kubectl apply -f /workspace/apis.yaml >/dev/null
   echo "✓"
 
-  echo -n "￮ configuring autoscaling "
-  python render_template.py $CORTEX_CLUSTER_CONFIG_FILE manifests/cluster-autoscaler.yaml.j2 > /workspace/cluster-autoscaler.yaml
-  kubectl apply -f /workspace/cluster-autoscaler.yaml >/dev/null
+  echo -n "￮ configuring metrics "
+  setup_prometheus
+  echo "✓"
+
+  restart_operator
+  start_controller_manager
+
+  echo -n "￮ waiting for the operator "
+  kubectl -n=default rollout status deployment operator --timeout=10m >/dev/null
+  echo "✓"
+
+  echo -e "\ncortex is ready!"
+}
+
+function install_cortex() {
+  start_phase addons
+
+  if [ "$CORTEX_EFS_FILE_SYSTEM_ID" != "" ]; then
+    echo -n "￮ configuring efs "
+    python setup_efs.py $CORTEX_CLUSTER_CONFIG_FILE
+    echo "✓"
+  fi
+
+  if [ "$CORTEX_NAT_GATEWAY_ELASTIC_IPS" != "" ]; then
+    echo -n "￮ assigning the elastic ips to the nat gateways (this might take a few minutes) "
+    python setup_nat_gateways.py $CORTEX_CLUSTER_CONFIG_FILE
+    echo "✓"
+  fi
+
+  echo -n "￮ updating cluster configuration "
+  setup_configmap
   echo "✓"
 
+  echo -n "￮ configuring kubernetes access "
+  setup_kubernetes_access
+  echo "✓"
+
+  echo -n "￮ configuring networking (this might take a few minutes) "
+  setup_istio
+  python render_template.py $CORTEX_CLUSTER_CONFIG_FILE manifests/apis.yaml.j2 > /workspace/apis.yaml
+  kubectl apply -f /workspace/apis.yaml >/dev/null
+  echo "✓"
+
+  if [ "$CORTEX_API_LOAD_BALANCER_TYPE" == "alb" ]; then
+    echo -n "￮ configuring the api load balancer (this might take a few minutes) "
+    setup_api_load_balancer
+    echo "✓"
+  fi
+
+  # the node groups of existing clusters are not managed by cortex
+  if [ "$arg1" != "--install" ]; then
+    echo -n "￮ configuring autoscaling "
+    setup_autoscaling
+    echo "✓"
+  fi
+
   echo -n "￮ configuring logging "
   python render_template.py $CORTEX_CLUSTER_CONFIG_FILE manifests/fluent-bit.yaml.j2 | kubectl apply -f - >/dev/null
   envsubst < manifests/event-exporter.yaml | kubectl apply -f - >/dev/null
@@ -61,7 +143,7 @@ function cluster_up() {
   echo "✓"
 
   echo -n "￮ configuring gpu support (for the nodegroups that may require it) "
-  envsubst < manifests/nvidia.yaml | kubectl apply -f - >/dev/null
+  python render_template.py $CORTEX_CLUSTER_CONFIG_FILE manifests/nvidia.yaml.j2 | kubectl apply -f - >/dev/null
   NVIDIA_COM_GPU_VALUE=true envsubst < manifests/prometheus-dcgm-exporter.yaml | kubectl apply -f - >/dev/null
   echo "✓"
 
@@ -69,11 +151,28 @@ function cluster_up() {
   envsubst < manifests/inferentia.yaml | kubectl apply -f - >/dev/null
   echo "✓"
 
+  echo -n "￮ configuring model cache garbage collection "
+  python render_template.py $CORTEX_CLUSTER_CONFIG_FILE manifests/model-cache-gc.yaml.j2 | kubectl apply -f - >/dev/null
+  echo "✓"
+
+  start_phase operator
   restart_operator
   start_controller_manager
 
   validate_cortex
 
+  if [ "$CORTEX_API_LOAD_BALANCER_PROXY_PROTOCOL" == "True" ]; then
+    echo -n "￮ enabling the proxy protocol on the api load balancer "
+    python setup_proxy_protocol.py $CORTEX_CLUSTER_CONFIG_FILE
+    echo "✓"
+  fi
+
+  if [ -n "$CORTEX_API_LOAD_BALANCER_ACCESS_LOGS_S3_PATH" ]; then
+    echo -n "￮ enabling access logs on the api load balancer "
+    python setup_access_logs.py $CORTEX_CLUSTER_CONFIG_FILE
+    echo "✓"
+  fi
+
   echo -e "\ncortex is ready!"
   if [ "$CORTEX_OPERATOR_LOAD_BALANCER_SCHEME" == "internal" ]; then
     echo -e "\nnote: you will need to configure VPC Peering to connect to your cluster: https://docs.cortex.dev/v/${CORTEX_VERSION_MINOR}/"
@@ -85,7 +184,10 @@ function cluster_up() {
 function cluster_configure() {
   check_eks
 
-  resize_nodegroup
+  # the instances of clusters which use karpenter aren't in EKS node groups (the operator updates the karpenter provisioners when it restarts)
+  if [ "$CORTEX_NODE_PROVISIONER" != "karpenter" ]; then
+    resize_nodegroup
+  fi
 
   echo -n "￮ updating cluster configuration "
   setup_configmap
@@ -93,8 +195,7 @@ function cluster_configure() {
 
   # this is necessary since max_instances may have been updated
   echo -n "￮ configuring autoscaling "
-  python render_template.py $CORTEX_CLUSTER_CONFIG_FILE manifests/cluster-autoscaler.yaml.j2 > /workspace/cluster-autoscaler.yaml
-  kubectl apply -f /workspace/cluster-autoscaler.yaml >/dev/null
+  setup_autoscaling
   echo "✓"
 
   restart_operator
@@ -143,12 +244,68 @@ function create_eks() {
 
   echo -e "￮ spinning up the cluster (this will take about 45 minutes) ...\n"
   python generate_eks.py $CORTEX_CLUSTER_CONFIG_FILE manifests/ami.json > /workspace/eks.yaml
+  start_phase vpc
+  if [ "$CORTEX_PROGRESS_EVENTS" = "true" ]; then
+    watch_eks_phases &
+    watcher_pid=$!
+    trap "kill $watcher_pid 2>/dev/null || true" EXIT
+  fi
   eksctl create cluster --timeout=$EKSCTL_TIMEOUT --install-neuron-plugin=false --install-nvidia-plugin=false -f /workspace/eks.yaml
   echo
+  if [ -n "$watcher_pid" ]; then
+    kill $watcher_pid 2>/dev/null || true
+  fi
+
+  install_vpc_controllers
+  write_kubeconfig
+}
+
+# deletes the stacks of the node groups which failed to be created, creates the missing node groups of the existing eks cluster, and configures kubectl
+function resume_nodegroups() {
+  echo -e "￮ creating the node groups of the cluster ...\n"
+  python generate_eks.py $CORTEX_CLUSTER_CONFIG_FILE manifests/ami.json > /workspace/eks.yaml
+  start_phase nodegroups
+
+  failed_stacks=$(aws cloudformation list-stacks --region $CORTEX_REGION --stack-status-filter CREATE_FAILED ROLLBACK_COMPLETE ROLLBACK_FAILED --query "StackSummaries[?starts_with(StackName, 'eksctl-$CORTEX_CLUSTER_NAME-nodegroup-')].StackName" --output text)
+  for stack_name in $failed_stacks; do
+    echo "deleting the stack of the node group which failed to be created ($stack_name)"
+    aws cloudformation delete-stack --region $CORTEX_REGION --stack-name $stack_name
+    aws cloudformation wait stack-delete-complete --region $CORTEX_REGION --stack-name $stack_name
+  done
+
+  # eksctl excludes the node groups which already exist
+  eksctl create nodegroup --timeout=$EKSCTL_TIMEOUT --install-neuron-plugin=false --install-nvidia-plugin=false -f /workspace/eks.yaml
+  echo
 
+  install_vpc_controllers
   write_kubeconfig
 }
 
+# pods on windows nodes are assigned IP addresses by the VPC resource controller and admission webhook (rather than the aws-node daemonset)
+function install_vpc_controllers() {
+  if grep -q "amiFamily: WindowsServer" /workspace/eks.yaml; then
+    eksctl utils install-vpc-controllers --cluster=$CORTEX_CLUSTER_NAME --region=$CORTEX_REGION --approve
+    echo
+  fi
+}
+
+# prints a marker which the cli renders as the start of a phase of `cortex cluster up` (the phases are listed in cli/cmd/lib_cluster_progress.go)
+function start_phase() {
+  if [ "$CORTEX_PROGRESS_EVENTS" = "true" ]; then
+    echo "::phase::$1"
+  fi
+}
+
+# eksctl creates the vpc and the control plane in the same cloudformation stack (the control plane is created once the vpc's resources are ready),
+# so the stacks are polled to determine when the control plane and the node groups start being created
+function watch_eks_phases() {
+  until [ "$(aws cloudformation describe-stack-resource --region $CORTEX_REGION --stack-name eksctl-$CORTEX_CLUSTER_NAME-cluster --logical-resource-id ControlPlane --query StackResourceDetail.ResourceStatus --output text 2>/dev/null || true)" != "" ]; do sleep 10; done
+  start_phase control_plane
+
+  until aws cloudformation describe-stacks --region $CORTEX_REGION --stack-name eksctl-$CORTEX_CLUSTER_NAME-nodegroup-cx-operator >/dev/null 2>&1; do sleep 10; done
+  start_phase nodegroups
+}
+
 # checks that the eks cluster is active and configures kubectl
 function check_eks() {
   set +e
@@ -186,11 +343,42 @@ function check_eks() {
   write_kubeconfig
 }
 
+# checks that the provided kubeconfig can reach the cluster and that the workload nodes are labeled
+function check_kubeconfig() {
+  if [ ! -f "$KUBECONFIG" ]; then
+    echo "error: kubeconfig file not found"
+    exit 1
+  fi
+
+  set +e
+  out=$(kubectl get nodes 2>&1)
+  exit_code=$?
+  set -e
+  if [ $exit_code -ne 0 ]; then
+    echo "error: unable to connect to your cluster using the provided kubeconfig:"
+    echo "$out"
+    exit 1
+  fi
+
+  num_workload_nodes=$(kubectl get nodes -l workload=true -o json | jq -j '.items | length')
+  if [ "$num_workload_nodes" -eq "0" ]; then
+    echo "warning: no nodes in your cluster are labeled with \`workload=true\`; apis will not be scheduled until nodes with this label (and the \`alpha.eksctl.io/nodegroup-name\` label matching a node group in your cluster configuration) are available"
+  fi
+}
+
 function write_kubeconfig() {
   eksctl utils write-kubeconfig --cluster=$CORTEX_CLUSTER_NAME --region=$CORTEX_REGION | (grep -v "saved kubeconfig as" | grep -v "using region" | grep -v "eksctl version" || true)
   out=$(kubectl get pods 2>&1 || true); if [[ "$out" == *"must be logged in to the server"* ]]; then echo "error: your aws iam user does not have access to this cluster; to grant access, see https://docs.cortex.dev/v/${CORTEX_VERSION_MINOR}/"; exit 1; fi
 }
 
+# the application load balancer and api gateway aren't managed by kubernetes, so their endpoints are recorded for the operator
+function setup_api_load_balancer() {
+  python setup_api_load_balancer.py $CORTEX_CLUSTER_CONFIG_FILE > /workspace/api_load_balancer.env
+  kubectl -n=default create configmap 'api-load-balancer' \
+    --from-env-file=/workspace/api_load_balancer.env \
+    -o yaml --dry-run=client | kubectl apply -f - >/dev/null
+}
+
 function setup_configmap() {
   envsubst < manifests/default_cortex_cli_config.yaml > tmp_cli_config.yaml
   kubectl -n=default create configmap 'client-config' \
@@ -202,6 +390,16 @@ function setup_configmap() {
     --from-file='cluster.yaml'=$CORTEX_CLUSTER_CONFIG_FILE \
     -o yaml --dry-run=client | kubectl apply -f - >/dev/null
 
+  dev_env_vars=()
+  if [ "$CORTEX_DEV_CLUSTER" = "true" ]; then
+    dev_env_vars=(
+      --from-literal='CORTEX_S3_ENDPOINT'="http://minio.default:9000"
+      --from-literal='CORTEX_SQS_ENDPOINT'="http://elasticmq.default:9324"
+      --from-literal='AWS_ACCESS_KEY_ID'=$CORTEX_DEV_ACCESS_KEY_ID
+      --from-literal='AWS_SECRET_ACCESS_KEY'=$CORTEX_DEV_SECRET_ACCESS_KEY
+    )
+  fi
+
   kubectl -n=default create configmap 'env-vars' \
     --from-literal='CORTEX_VERSION'=$CORTEX_VERSION \
     --from-literal='CORTEX_REGION'=$CORTEX_REGION \
@@ -212,9 +410,20 @@ function setup_configmap() {
     --from-literal='CORTEX_TELEMETRY_SENTRY_DSN'=$CORTEX_TELEMETRY_SENTRY_DSN \
     --from-literal='CORTEX_TELEMETRY_SEGMENT_WRITE_KEY'=$CORTEX_TELEMETRY_SEGMENT_WRITE_KEY \
     --from-literal='CORTEX_DEV_DEFAULT_IMAGE_REGISTRY'=$CORTEX_DEV_DEFAULT_IMAGE_REGISTRY \
+    "${dev_env_vars[@]}" \
     -o yaml --dry-run=client | kubectl apply -f - >/dev/null
 }
 
+# maps the iam roles and users in the kubernetes_access section to the groups which are bound in manifests/kubernetes-access.yaml;
+# existing mappings of the arns are replaced, so that their roles are updated
+function setup_kubernetes_access() {
+  kubectl apply -f manifests/kubernetes-access.yaml >/dev/null
+  python kubernetes_access.py $CORTEX_CLUSTER_CONFIG_FILE | while read arn role; do
+    eksctl delete iamidentitymapping --cluster=$CORTEX_CLUSTER_NAME --region=$CORTEX_REGION --arn=$arn --all >/dev/null 2>&1 || true
+    eksctl create iamidentitymapping --cluster=$CORTEX_CLUSTER_NAME --region=$CORTEX_REGION --arn=$arn --username=$arn --group=cortex:$role >/dev/null
+  done
+}
+
 function setup_prometheus() {
   envsubst < manifests/prometheus-operator.yaml | kubectl apply -f - >/dev/null
   envsubst < manifests/prometheus-statsd-exporter.yaml | kubectl apply -f - >/dev/null
@@ -255,54 +464,93 @@ function start_controller_manager() {
   echo "✓"
 }
 
+function setup_autoscaling() {
+  if [ "$CORTEX_NODE_PROVISIONER" == "karpenter" ]; then
+    setup_karpenter
+    return
+  fi
+
+  python render_template.py $CORTEX_CLUSTER_CONFIG_FILE manifests/cluster-autoscaler.yaml.j2 > /workspace/cluster-autoscaler.yaml
+  kubectl apply -f /workspace/cluster-autoscaler.yaml >/dev/null
+}
+
+# karpenter runs on the operator nodes, and launches the worker instances with the operator node group's instance profile
+# (the provisioners and node templates are generated from the node groups by the operator)
+function setup_karpenter() {
+  instance_profile=$(aws cloudformation describe-stack-resource --region $CORTEX_REGION --stack-name eksctl-$CORTEX_CLUSTER_NAME-nodegroup-cx-operator --logical-resource-id NodeInstanceProfile --query StackResourceDetail.PhysicalResourceId --output text)
+  cluster_endpoint=$(aws eks describe-cluster --region $CORTEX_REGION --name $CORTEX_CLUSTER_NAME --query cluster.endpoint --output text)
+
+  helm upgrade --install karpenter oci://public.ecr.aws/karpenter/karpenter --version $KARPENTER_VERSION \
+    --namespace karpenter --create-namespace \
+    --set settings.aws.clusterName=$CORTEX_CLUSTER_NAME \
+    --set settings.aws.clusterEndpoint=$cluster_endpoint \
+    --set settings.aws.defaultInstanceProfile=$instance_profile \
+    --set controller.image.repository=${CORTEX_IMAGE_KARPENTER%:*} \
+    --set controller.image.tag=${CORTEX_IMAGE_KARPENTER##*:} \
+    --set controller.image.digest="" \
+    --set-string "nodeSelector.alpha\.eksctl\.io/nodegroup-name=cx-operator" \
+    --wait >/dev/null
+}
+
 function resize_nodegroup() {
   eksctl get nodegroup --cluster=$CORTEX_CLUSTER_NAME --region=$CORTEX_REGION -o json > nodegroups.json
   ng_len=$(cat nodegroups.json | jq -r length)
   config_ng="$CORTEX_SCALING_NODEGROUP"
 
-  has_ng="false"
+  # a spot node group with an on-demand backup has two eks node groups (cx-ws-<name> and cx-wd-<name>)
+  has_spot_ng="false"
+  stack_ngs=()
   for eks_idx in $(seq 0 $(($ng_len-1))); do
     stack_ng=$(cat nodegroups.json | jq -r .[$eks_idx].Name)
-    if [ "$stack_ng" = "cx-operator" ]; then
-      continue
+    if [ "$stack_ng" = "cx-wd-$config_ng" ] || [ "$stack_ng" = "cx-ws-$config_ng" ]; then
+      stack_ngs+=("$eks_idx")
     fi
-    if [[ "$stack_ng" == *"$config_ng" ]]; then
-      has_ng="true"
-      break
+    if [ "$stack_ng" = "cx-ws-$config_ng" ]; then
+      has_spot_ng="true"
     fi
   done
 
-  if [ "$has_ng" == "false" ]; then
+  if [ ${#stack_ngs[@]} -eq 0 ]; then
     echo "error: \"cx-*-$config_ng\" node group couldn't be found"
     exit 1
   fi
 
-  desired=$(cat nodegroups.json | jq -r .[$eks_idx].DesiredCapacity)
-  existing_min=$(cat nodegroups.json | jq -r .[$eks_idx].MinSize)
-  existing_max=$(cat nodegroups.json | jq -r .[$eks_idx].MaxSize)
-  updating_min="$CORTEX_SCALING_MIN_INSTANCES"
-  updating_max="$CORTEX_SCALING_MAX_INSTANCES"
+  for eks_idx in "${stack_ngs[@]}"; do
+    stack_ng=$(cat nodegroups.json | jq -r .[$eks_idx].Name)
+    desired=$(cat nodegroups.json | jq -r .[$eks_idx].DesiredCapacity)
+    existing_min=$(cat nodegroups.json | jq -r .[$eks_idx].MinSize)
+    existing_max=$(cat nodegroups.json | jq -r .[$eks_idx].MaxSize)
+    updating_min="$CORTEX_SCALING_MIN_INSTANCES"
+    updating_max="$CORTEX_SCALING_MAX_INSTANCES"
+    ng_description="nodegroup $config_ng"
+
+    # the on-demand backup is only scaled up by the cluster autoscaler when spot instances can't be provisioned
+    if [ "$has_spot_ng" = "true" ] && [ "$stack_ng" = "cx-wd-$config_ng" ]; then
+      updating_min="0"
+      ng_description="nodegroup $config_ng (on-demand backup)"
+    fi
 
-  if [ "$desired" -lt $updating_min ]; then
-    desired=$updating_min
-  fi
-  if [ "$desired" -gt $updating_max ]; then
-    desired=$updating_max
-  fi
+    if [ "$desired" -lt $updating_min ]; then
+      desired=$updating_min
+    fi
+    if [ "$desired" -gt $updating_max ]; then
+      desired=$updating_max
+    fi
 
-  if [ "$existing_min" != "$updating_min" ] && [ "$existing_max" != "$updating_max" ]; then
-    echo "￮ nodegroup $config_ng: updating min instances to $updating_min and max instances to $updating_max"
-    eksctl scale nodegroup --cluster=$CORTEX_CLUSTER_NAME --region=$CORTEX_REGION $stack_ng --nodes $desired --nodes-min $updating_min --nodes-max $updating_max --timeout "60m"
-    echo
-  elif [ "$existing_min" != "$updating_min" ]; then
-    echo "￮ nodegroup $config_ng: updating min instances to $updating_min"
-    eksctl scale nodegroup --cluster=$CORTEX_CLUSTER_NAME --region=$CORTEX_REGION $stack_ng --nodes $desired --nodes-min $updating_min --timeout "60m"
-    echo
-  elif [ "$existing_max" != "$updating_max" ]; then
-    echo "￮ nodegroup $config_ng: updating max instances to $updating_max"
-    eksctl scale nodegroup --cluster=$CORTEX_CLUSTER_NAME --region=$CORTEX_REGION $stack_ng --nodes $desired --nodes-max $updating_max --timeout "60m"
-    echo
-  fi
+    if [ "$existing_min" != "$updating_min" ] && [ "$existing_max" != "$updating_max" ]; then
+      echo "￮ $ng_description: updating min instances to $updating_min and max instances to $updating_max"
+      eksctl scale nodegroup --cluster=$CORTEX_CLUSTER_NAME --region=$CORTEX_REGION $stack_ng --nodes $desired --nodes-min $updating_min --nodes-max $updating_max --timeout "60m"
+      echo
+    elif [ "$existing_min" != "$updating_min" ]; then
+      echo "￮ $ng_description: updating min instances to $updating_min"
+      eksctl scale nodegroup --cluster=$CORTEX_CLUSTER_NAME --region=$CORTEX_REGION $stack_ng --nodes $desired --nodes-min $updating_min --timeout "60m"
+      echo
+    elif [ "$existing_max" != "$updating_max" ]; then
+      echo "￮ $ng_description: updating max instances to $updating_max"
+      eksctl scale nodegroup --cluster=$CORTEX_CLUSTER_NAME --region=$CORTEX_REGION $stack_ng --nodes $desired --nodes-max $updating_max --timeout "60m"
+      echo
+    fi
+  done
 
   rm nodegroups.json
 }
@@ -318,6 +566,14 @@ function setup_istio() {
 
   python render_template.py $CORTEX_CLUSTER_CONFIG_FILE manifests/istio.yaml.j2 > /workspace/istio.yaml
   output_if_error istio-${ISTIO_VERSION}/bin/istioctl install -f /workspace/istio.yaml
+
+  # the sidecar is only injected into pods which are annotated with sidecar.istio.io/inject=true (i.e. api pods when mtls is enabled)
+  if [ "$CORTEX_MTLS_MODE" != "" ]; then
+    kubectl label namespace default istio-injection=enabled --overwrite >/dev/null
+  else
+    kubectl label namespace default istio-injection- >/dev/null 2>&1 || true
+    kubectl -n=default delete --ignore-not-found=true peerauthentication apis >/dev/null
+  fi
 }
 
 function validate_cortex() {
@@ -451,12 +707,14 @@ function validate_cortex() {
     fi
 
     if [ "$api_load_balancer_endpoint" == "" ]; then
-      out=$(kubectl -n=istio-system get service ingressgateway-apis -o json | tr -d '[:space:]')
-      if [[ $out != *'"loadBalancer":{"ingress":[{"'* ]]; then
-        success_cycles=0
-        continue
+      if [ "$CORTEX_API_LOAD_BALANCER_TYPE" != "alb" ]; then
+        out=$(kubectl -n=istio-system get service ingressgateway-apis -o json | tr -d '[:space:]')
+        if [[ $out != *'"loadBalancer":{"ingress":[{"'* ]]; then
+          success_cycles=0
+          continue
+        fi
       fi
-      api_load_balancer_endpoint=$(kubectl -n=istio-system get service ingressgateway-apis -o json | tr -d '[:space:]' | sed 's/.*{\"hostname\":\"\(.*\)\".*/\1/')
+      api_load_balancer_endpoint=$(get_api_load_balancer_endpoint)
     fi
 
     operator_load_balancer_state="$(python get_operator_load_balancer_state.py)"  # don't cache this result
@@ -505,6 +763,12 @@ function print_endpoints() {
 
   echo "operator:          $operator_endpoint"  # before modifying this, search for this prefix
   echo "api load balancer: $api_load_balancer_endpoint"
+  if [ "$CORTEX_API_LOAD_BALANCER_TYPE" == "alb" ]; then
+    api_gateway_endpoint=$(kubectl -n=default get configmap api-load-balancer -o jsonpath='{.data.api_gateway_endpoint}')
+    if [ "$api_gateway_endpoint" != "" ]; then
+      echo "api gateway:       $api_gateway_endpoint"
+    fi
+  fi
 }
 
 function get_operator_endpoint() {
@@ -512,6 +776,10 @@ function get_operator_endpoint() {
 }
 
 function get_api_load_balancer_endpoint() {
+  if [ "$CORTEX_API_LOAD_BALANCER_TYPE" == "alb" ]; then
+    kubectl -n=default get configmap api-load-balancer -o jsonpath='{.data.load_balancer_endpoint}'
+    return
+  fi
   kubectl -n=istio-system get service ingressgateway-apis -o json | tr -d '[:space:]' | sed 's/.*{\"hostname\":\"\(.*\)\".*/\1/'
 }
 
@@ -0,0 +1,24 @@
+# Copyright 2021 Cortex Labs, Inc.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+import sys
+import yaml
+
+# prints the arn and role of each entry in the cluster configuration's kubernetes_access section (one per line)
+if __name__ == "__main__":
+    with open(sys.argv[1], "r") as f:
+        cluster_config = yaml.safe_load(f)
+
+    for access in cluster_config.get("kubernetes_access") or []:
+        print(f"{access['arn']} {access['role']}")
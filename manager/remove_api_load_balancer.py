@@ -0,0 +1,149 @@
+# Copyright 2021 Cortex Labs, Inc.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+import sys
+import time
+
+import boto3
+import yaml
+
+from helpers import (
+    find_api_load_balancer,
+    get_api_gateway,
+    get_api_load_balancer_name,
+    get_api_load_balancer_security_group_name,
+    get_operator_auto_scaling_group_names,
+    get_vpc_link,
+)
+
+
+# the application load balancer, api gateway, and their security group aren't part of the eksctl stacks,
+# so they must be removed before the cluster's vpc can be deleted
+def remove_api_load_balancer(cluster_config):
+    if cluster_config.get("api_load_balancer_type") != "alb":
+        return
+
+    cluster_name = cluster_config["cluster_name"]
+    region = cluster_config["region"]
+
+    client_ec2 = boto3.client("ec2", region_name=region)
+    client_elbv2 = boto3.client("elbv2", region_name=region)
+    client_autoscaling = boto3.client("autoscaling", region_name=region)
+    client_apigatewayv2 = boto3.client("apigatewayv2", region_name=region)
+
+    # deleting the api also deletes its stage, routes, integrations, and authorizers
+    api = get_api_gateway(cluster_name, client_apigatewayv2)
+    if api is not None:
+        client_apigatewayv2.delete_api(ApiId=api["ApiId"])
+
+    vpc_link = get_vpc_link(cluster_name, client_apigatewayv2)
+    if vpc_link is not None:
+        client_apigatewayv2.delete_vpc_link(VpcLinkId=vpc_link["VpcLinkId"])
+        wait_for_vpc_link_deletion(client_apigatewayv2, vpc_link["VpcLinkId"])
+
+    load_balancer = find_api_load_balancer(cluster_name, client_elbv2)
+    if load_balancer is not None:
+        client_elbv2.delete_load_balancer(LoadBalancerArn=load_balancer["LoadBalancerArn"])
+        client_elbv2.get_waiter("load_balancers_deleted").wait(
+            LoadBalancerArns=[load_balancer["LoadBalancerArn"]]
+        )
+
+    try:
+        name = get_api_load_balancer_name(cluster_name, region)
+        target_groups = client_elbv2.describe_target_groups(Names=[name])["TargetGroups"]
+        target_group_arn = target_groups[0]["TargetGroupArn"]
+        for auto_scaling_group_name in get_operator_auto_scaling_group_names(
+            cluster_name, client_autoscaling
+        ):
+            client_autoscaling.detach_load_balancer_target_groups(
+                AutoScalingGroupName=auto_scaling_group_name, TargetGroupARNs=[target_group_arn]
+            )
+        client_elbv2.delete_target_group(TargetGroupArn=target_group_arn)
+    except client_elbv2.exceptions.TargetGroupNotFoundException:
+        pass
+
+    group_name = get_api_load_balancer_security_group_name(cluster_name)
+    security_groups = client_ec2.describe_security_groups(
+        Filters=[
+            {"Name": "group-name", "Values": [group_name]},
+            {"Name": "tag:cortex.dev/cluster-name", "Values": [cluster_name]},
+        ]
+    )["SecurityGroups"]
+    for security_group in security_groups:
+        revoke_referencing_rules(client_ec2, security_group)
+        delete_security_group(client_ec2, security_group["GroupId"])
+
+
+def wait_for_vpc_link_deletion(client_apigatewayv2, vpc_link_id):
+    while True:
+        try:
+            client_apigatewayv2.get_vpc_link(VpcLinkId=vpc_link_id)
+        except client_apigatewayv2.exceptions.NotFoundException:
+            return
+        time.sleep(5)
+
+
+# the node security group allows traffic from the load balancer's security group
+def revoke_referencing_rules(client_ec2, security_group):
+    referencing_groups = client_ec2.describe_security_groups(
+        Filters=[
+            {"Name": "vpc-id", "Values": [security_group["VpcId"]]},
+            {"Name": "ip-permission.group-id", "Values": [security_group["GroupId"]]},
+        ]
+    )["SecurityGroups"]
+    for referencing_group in referencing_groups:
+        if referencing_group["GroupId"] == security_group["GroupId"]:
+            continue
+        permissions = []
+        for permission in referencing_group["IpPermissions"]:
+            pairs = [
+                pair
+                for pair in permission.get("UserIdGroupPairs", [])
+                if pair["GroupId"] == security_group["GroupId"]
+            ]
+            if len(pairs) == 0:
+                continue
+            revoked_permission = {
+                key: permission[key]
+                for key in ["IpProtocol", "FromPort", "ToPort"]
+                if key in permission
+            }
+            revoked_permission["UserIdGroupPairs"] = [
+                {"GroupId": pair["GroupId"]} for pair in pairs
+            ]
+            permissions.append(revoked_permission)
+        if len(permissions) > 0:
+            client_ec2.revoke_security_group_ingress(
+                GroupId=referencing_group["GroupId"], IpPermissions=permissions
+            )
+
+
+# the network interfaces of the load balancer and vpc link may take a few minutes to be released
+def delete_security_group(client_ec2, security_group_id):
+    for _ in range(60):
+        try:
+            client_ec2.delete_security_group(GroupId=security_group_id)
+            return
+        except client_ec2.exceptions.ClientError as e:
+            if e.response["Error"]["Code"] != "DependencyViolation":
+                raise
+        time.sleep(10)
+
+    client_ec2.delete_security_group(GroupId=security_group_id)
+
+
+if __name__ == "__main__":
+    with open(sys.argv[1], "r") as f:
+        cluster_config = yaml.safe_load(f)
+    remove_api_load_balancer(cluster_config)
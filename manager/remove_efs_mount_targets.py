@@ -0,0 +1,57 @@
+# Copyright 2021 Cortex Labs, Inc.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+import sys
+import time
+
+import boto3
+import yaml
+
+from helpers import get_efs_security_group_name
+
+
+# the mount targets and their security group must be removed before the cluster's vpc can be deleted
+def remove_efs_mount_targets(cluster_config):
+    cluster_name = cluster_config["cluster_name"]
+    region = cluster_config["region"]
+    file_system_id = cluster_config.get("efs_file_system_id")
+    if not file_system_id:
+        return
+
+    client_ec2 = boto3.client("ec2", region_name=region)
+    client_efs = boto3.client("efs", region_name=region)
+
+    mount_targets = client_efs.describe_mount_targets(FileSystemId=file_system_id)["MountTargets"]
+    vpc_ids = set(mount_target["VpcId"] for mount_target in mount_targets)
+    for mount_target in mount_targets:
+        client_efs.delete_mount_target(MountTargetId=mount_target["MountTargetId"])
+
+    while len(client_efs.describe_mount_targets(FileSystemId=file_system_id)["MountTargets"]) > 0:
+        time.sleep(5)
+
+    for vpc_id in vpc_ids:
+        security_groups = client_ec2.describe_security_groups(
+            Filters=[
+                {"Name": "vpc-id", "Values": [vpc_id]},
+                {"Name": "group-name", "Values": [get_efs_security_group_name(cluster_name)]},
+            ]
+        )["SecurityGroups"]
+        for security_group in security_groups:
+            client_ec2.delete_security_group(GroupId=security_group["GroupId"])
+
+
+if __name__ == "__main__":
+    with open(sys.argv[1], "r") as f:
+        cluster_config = yaml.safe_load(f)
+    remove_efs_mount_targets(cluster_config)
@@ -0,0 +1,64 @@
+# Copyright 2021 Cortex Labs, Inc.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+import sys
+import time
+
+import boto3
+import yaml
+
+from helpers import get_nat_gateways
+from setup_nat_gateways import get_allocation_id
+
+
+# the nat gateways which use the elastic ips in nat_gateway_elastic_ips aren't part of the eksctl
+# stacks, so they must be removed before the cluster's vpc can be deleted (the elastic ips are kept)
+def remove_nat_gateways(cluster_config):
+    allocation_ids = cluster_config.get("nat_gateway_elastic_ips")
+    if not allocation_ids:
+        return
+
+    cluster_name = cluster_config["cluster_name"]
+    region = cluster_config["region"]
+
+    client_eks = boto3.client("eks", region_name=region)
+    client_ec2 = boto3.client("ec2", region_name=region)
+
+    vpc_config = client_eks.describe_cluster(name=cluster_name)["cluster"]["resourcesVpcConfig"]
+    vpc_id = vpc_config["vpcId"]
+
+    nat_gateway_ids = [
+        nat_gateway["NatGatewayId"]
+        for nat_gateway in get_nat_gateways(vpc_id, client_ec2)
+        if get_allocation_id(nat_gateway) in allocation_ids
+    ]
+    for nat_gateway_id in nat_gateway_ids:
+        client_ec2.delete_nat_gateway(NatGatewayId=nat_gateway_id)
+
+    # the elastic ips are disassociated once the nat gateways are deleted
+    for _ in range(60):
+        nat_gateways = get_nat_gateways(vpc_id, client_ec2)
+        if not any(nat_gateway["NatGatewayId"] in nat_gateway_ids for nat_gateway in nat_gateways):
+            return
+        time.sleep(10)
+
+    raise Exception(
+        f"timed out waiting for nat gateways {', '.join(nat_gateway_ids)} to be deleted"
+    )
+
+
+if __name__ == "__main__":
+    with open(sys.argv[1], "r") as f:
+        cluster_config = yaml.safe_load(f)
+    remove_nat_gateways(cluster_config)
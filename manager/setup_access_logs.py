@@ -0,0 +1,52 @@
+# Copyright 2021 Cortex Labs, Inc.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+import sys
+
+import boto3
+import yaml
+
+from helpers import get_api_load_balancer
+
+
+# the load balancer writes its access logs to <s3_path>/AWSLogs/<account_id>/elasticloadbalancing/
+# (the bucket's policy must allow the region's elastic load balancing account to write to it)
+def setup_access_logs(cluster_config):
+    s3_path = cluster_config.get("api_load_balancer_access_logs_s3_path")
+    if not s3_path:
+        return
+
+    cluster_name = cluster_config["cluster_name"]
+    region = cluster_config["region"]
+
+    bucket, _, prefix = s3_path[len("s3://") :].partition("/")
+    prefix = prefix.strip("/")
+
+    client_elbv2 = boto3.client("elbv2", region_name=region)
+
+    load_balancer = get_api_load_balancer(cluster_name, client_elbv2)
+    client_elbv2.modify_load_balancer_attributes(
+        LoadBalancerArn=load_balancer["LoadBalancerArn"],
+        Attributes=[
+            {"Key": "access_logs.s3.enabled", "Value": "true"},
+            {"Key": "access_logs.s3.bucket", "Value": bucket},
+            {"Key": "access_logs.s3.prefix", "Value": prefix},
+        ],
+    )
+
+
+if __name__ == "__main__":
+    with open(sys.argv[1], "r") as f:
+        cluster_config = yaml.safe_load(f)
+    setup_access_logs(cluster_config)
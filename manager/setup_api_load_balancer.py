@@ -0,0 +1,474 @@
+# Copyright 2021 Cortex Labs, Inc.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+import re
+import sys
+import time
+
+import boto3
+import yaml
+
+from helpers import (
+    find_api_load_balancer,
+    get_api_gateway,
+    get_api_load_balancer_name,
+    get_api_load_balancer_security_group_name,
+    get_operator_auto_scaling_group_names,
+    get_vpc_link,
+    paginate,
+)
+
+# must match the node ports of the ingressgateway-apis service in manifests/istio.yaml.j2
+HTTP_NODE_PORT = 30080
+STATUS_NODE_PORT = 30021
+
+AUTHORIZER_NAME = "cortex"
+
+
+# creates the application load balancer which routes to the apis' istio gateway on the cortex system nodes,
+# and the api gateway in front of it (if configured); prints the endpoints in the format of an env file
+def setup_api_load_balancer(cluster_config):
+    cluster_name = cluster_config["cluster_name"]
+    region = cluster_config["region"]
+    tags = cluster_config.get("tags", {})
+    scheme = cluster_config.get("api_load_balancer_scheme", "internet-facing")
+    ssl_certificate_arn = cluster_config.get("ssl_certificate_arn")
+
+    client_eks = boto3.client("eks", region_name=region)
+    client_ec2 = boto3.client("ec2", region_name=region)
+    client_elbv2 = boto3.client("elbv2", region_name=region)
+    client_autoscaling = boto3.client("autoscaling", region_name=region)
+
+    vpc_config = client_eks.describe_cluster(name=cluster_name)["cluster"]["resourcesVpcConfig"]
+    vpc_id = vpc_config["vpcId"]
+    subnet_ids = get_load_balancer_subnet_ids(client_ec2, vpc_id, scheme)
+
+    ports = [80]
+    if ssl_certificate_arn:
+        ports.append(443)
+    cidrs = cluster_config.get("api_load_balancer_cidr_white_list") or ["0.0.0.0/0"]
+    security_group_id = get_or_create_security_group(
+        client_ec2, cluster_name, vpc_id, ports, cidrs, tags
+    )
+    allow_node_ports(client_ec2, cluster_name, security_group_id)
+
+    name = get_api_load_balancer_name(cluster_name, region)
+    load_balancer = get_or_create_load_balancer(
+        client_elbv2, cluster_name, name, subnet_ids, security_group_id, scheme, tags
+    )
+    target_group_arn = get_or_create_target_group(client_elbv2, name, vpc_id, tags)
+
+    http_listener_arn = get_or_create_listener(
+        client_elbv2, load_balancer["LoadBalancerArn"], target_group_arn, 80
+    )
+    if ssl_certificate_arn:
+        get_or_create_listener(
+            client_elbv2,
+            load_balancer["LoadBalancerArn"],
+            target_group_arn,
+            443,
+            ssl_certificate_arn,
+        )
+
+    # the apis' istio gateway runs on the cortex system nodes
+    auto_scaling_group_names = get_operator_auto_scaling_group_names(
+        cluster_name, client_autoscaling
+    )
+    if len(auto_scaling_group_names) == 0:
+        raise Exception(f"unable to find the cx-operator autoscaling group of {cluster_name}")
+    for auto_scaling_group_name in auto_scaling_group_names:
+        client_autoscaling.attach_load_balancer_target_groups(
+            AutoScalingGroupName=auto_scaling_group_name, TargetGroupARNs=[target_group_arn]
+        )
+
+    if cluster_config.get("api_load_balancer_waf_web_acl_arn"):
+        client_wafv2 = boto3.client("wafv2", region_name=region)
+        client_wafv2.associate_web_acl(
+            WebACLArn=cluster_config["api_load_balancer_waf_web_acl_arn"],
+            ResourceArn=load_balancer["LoadBalancerArn"],
+        )
+
+    print(f"load_balancer_endpoint={load_balancer['DNSName']}")
+
+    if cluster_config.get("api_gateway") is not None:
+        api_gateway_endpoint = setup_api_gateway(
+            cluster_config, subnet_ids, security_group_id, http_listener_arn
+        )
+        print(f"api_gateway_endpoint={api_gateway_endpoint}")
+
+
+# eksctl (and the vpc requirements for existing subnets) tag the subnets which load balancers can be placed in
+def get_load_balancer_subnet_ids(client_ec2, vpc_id, scheme):
+    role_tag = "kubernetes.io/role/elb"
+    if scheme == "internal":
+        role_tag = "kubernetes.io/role/internal-elb"
+
+    subnets = client_ec2.describe_subnets(
+        Filters=[
+            {"Name": "vpc-id", "Values": [vpc_id]},
+            {"Name": f"tag:{role_tag}", "Values": ["1"]},
+        ]
+    )["Subnets"]
+
+    # application load balancers can only have one subnet per availability zone
+    subnet_ids_by_zone = {}
+    for subnet in subnets:
+        subnet_ids_by_zone.setdefault(subnet["AvailabilityZone"], subnet["SubnetId"])
+
+    if len(subnet_ids_by_zone) < 2:
+        raise Exception(
+            f"application load balancers require subnets in at least two availability zones, but only {len(subnet_ids_by_zone)} of the subnets in {vpc_id} are tagged with {role_tag}=1"
+        )
+
+    return list(subnet_ids_by_zone.values())
+
+
+def get_or_create_security_group(client_ec2, cluster_name, vpc_id, ports, cidrs, tags):
+    group_name = get_api_load_balancer_security_group_name(cluster_name)
+
+    security_groups = client_ec2.describe_security_groups(
+        Filters=[
+            {"Name": "vpc-id", "Values": [vpc_id]},
+            {"Name": "group-name", "Values": [group_name]},
+        ]
+    )["SecurityGroups"]
+    if len(security_groups) > 0:
+        return security_groups[0]["GroupId"]
+
+    security_group_id = client_ec2.create_security_group(
+        GroupName=group_name,
+        Description=f"allows traffic to the {cluster_name} cluster's api load balancer",
+        VpcId=vpc_id,
+        TagSpecifications=[
+            {
+                "ResourceType": "security-group",
+                "Tags": [{"Key": key, "Value": value} for key, value in tags.items()],
+            }
+        ],
+    )["GroupId"]
+
+    # the api gateway's vpc link shares this security group
+    client_ec2.authorize_security_group_ingress(
+        GroupId=security_group_id,
+        IpPermissions=[
+            {
+                "IpProtocol": "tcp",
+                "FromPort": port,
+                "ToPort": port,
+                "IpRanges": [{"CidrIp": cidr} for cidr in cidrs],
+                "UserIdGroupPairs": [{"GroupId": security_group_id}],
+            }
+            for port in ports
+        ],
+    )
+
+    return security_group_id
+
+
+# all of the nodes which eksctl creates are in the cluster's shared node security group
+def get_shared_node_security_group_id(client_ec2, cluster_name):
+    stack_name = f"eksctl-{cluster_name}-cluster"
+    logical_id = "ClusterSharedNodeSecurityGroup"
+    return client_ec2.describe_security_groups(
+        Filters=[
+            {"Name": "tag:aws:cloudformation:stack-name", "Values": [stack_name]},
+            {"Name": "tag:aws:cloudformation:logical-id", "Values": [logical_id]},
+        ]
+    )["SecurityGroups"][0]["GroupId"]
+
+
+def allow_node_ports(client_ec2, cluster_name, security_group_id):
+    node_security_group_id = get_shared_node_security_group_id(client_ec2, cluster_name)
+
+    try:
+        client_ec2.authorize_security_group_ingress(
+            GroupId=node_security_group_id,
+            IpPermissions=[
+                {
+                    "IpProtocol": "tcp",
+                    "FromPort": port,
+                    "ToPort": port,
+                    "UserIdGroupPairs": [{"GroupId": security_group_id}],
+                }
+                for port in [HTTP_NODE_PORT, STATUS_NODE_PORT]
+            ],
+        )
+    except client_ec2.exceptions.ClientError as e:
+        if e.response["Error"]["Code"] != "InvalidPermission.Duplicate":
+            raise
+
+
+def get_or_create_load_balancer(
+    client_elbv2, cluster_name, name, subnet_ids, security_group_id, scheme, tags
+):
+    load_balancer = find_api_load_balancer(cluster_name, client_elbv2)
+    if load_balancer is None:
+        load_balancer = client_elbv2.create_load_balancer(
+            Name=name,
+            Subnets=subnet_ids,
+            SecurityGroups=[security_group_id],
+            Scheme=scheme,
+            Type="application",
+            IpAddressType="ipv4",
+            Tags=load_balancer_tags(tags),
+        )["LoadBalancers"][0]
+
+    # matches the idle timeout of the network load balancers
+    client_elbv2.modify_load_balancer_attributes(
+        LoadBalancerArn=load_balancer["LoadBalancerArn"],
+        Attributes=[{"Key": "idle_timeout.timeout_seconds", "Value": "350"}],
+    )
+
+    return load_balancer
+
+
+def get_or_create_target_group(client_elbv2, name, vpc_id, tags):
+    try:
+        target_groups = client_elbv2.describe_target_groups(Names=[name])["TargetGroups"]
+        return target_groups[0]["TargetGroupArn"]
+    except client_elbv2.exceptions.TargetGroupNotFoundException:
+        pass
+
+    # the gateway's service uses the Local external traffic policy, so only the node which runs the gateway passes the health check
+    return client_elbv2.create_target_group(
+        Name=name,
+        Protocol="HTTP",
+        Port=HTTP_NODE_PORT,
+        VpcId=vpc_id,
+        TargetType="instance",
+        HealthCheckProtocol="HTTP",
+        HealthCheckPort=str(STATUS_NODE_PORT),
+        HealthCheckPath="/healthz/ready",
+        Tags=load_balancer_tags(tags),
+    )["TargetGroups"][0]["TargetGroupArn"]
+
+
+def get_or_create_listener(
+    client_elbv2, load_balancer_arn, target_group_arn, port, ssl_certificate_arn=None
+):
+    for listener in paginate_listeners(client_elbv2, load_balancer_arn):
+        if listener["Port"] == port:
+            return listener["ListenerArn"]
+
+    listener_args = {
+        "LoadBalancerArn": load_balancer_arn,
+        "Protocol": "HTTP",
+        "Port": port,
+        "DefaultActions": [{"Type": "forward", "TargetGroupArn": target_group_arn}],
+    }
+    if ssl_certificate_arn:
+        listener_args["Protocol"] = "HTTPS"
+        listener_args["Certificates"] = [{"CertificateArn": ssl_certificate_arn}]
+
+    return client_elbv2.create_listener(**listener_args)["Listeners"][0]["ListenerArn"]
+
+
+def paginate_listeners(client_elbv2, load_balancer_arn):
+    paginator = client_elbv2.get_paginator("describe_listeners")
+    for listener_page in paginator.paginate(LoadBalancerArn=load_balancer_arn):
+        yield from listener_page["Listeners"]
+
+
+def load_balancer_tags(tags):
+    tags = {**tags, "cortex.dev/load-balancer": "api"}
+    return [{"Key": key, "Value": value} for key, value in tags.items()]
+
+
+# creates an http api which proxies all requests to the load balancer through a vpc link
+def setup_api_gateway(cluster_config, subnet_ids, security_group_id, http_listener_arn):
+    cluster_name = cluster_config["cluster_name"]
+    region = cluster_config["region"]
+    tags = cluster_config.get("tags", {})
+    api_gateway_config = cluster_config["api_gateway"]
+
+    client_apigatewayv2 = boto3.client("apigatewayv2", region_name=region)
+
+    vpc_link_id = get_or_create_vpc_link(
+        client_apigatewayv2, cluster_name, subnet_ids, security_group_id, tags
+    )
+
+    api = get_api_gateway(cluster_name, client_apigatewayv2)
+    if api is None:
+        api = client_apigatewayv2.create_api(
+            Name=f"cortex-{cluster_name}", ProtocolType="HTTP", Tags=tags
+        )
+    api_id = api["ApiId"]
+
+    setup_stage(client_apigatewayv2, api_id, api_gateway_config, tags)
+
+    integration_id = None
+    for integration in paginate(client_apigatewayv2.get_integrations, "Items", ApiId=api_id):
+        if integration.get("ConnectionId") == vpc_link_id:
+            integration_id = integration["IntegrationId"]
+    if integration_id is None:
+        integration_id = client_apigatewayv2.create_integration(
+            ApiId=api_id,
+            IntegrationType="HTTP_PROXY",
+            IntegrationMethod="ANY",
+            IntegrationUri=http_listener_arn,
+            ConnectionType="VPC_LINK",
+            ConnectionId=vpc_link_id,
+            PayloadFormatVersion="1.0",
+        )["IntegrationId"]
+
+    route_args = {
+        "ApiId": api_id,
+        "Target": f"integrations/{integration_id}",
+        "AuthorizationType": "NONE",
+    }
+    authorizer_config = api_gateway_config.get("authorizer")
+    if authorizer_config is not None:
+        authorizer_id = setup_authorizer(
+            client_apigatewayv2, cluster_config, api_id, authorizer_config
+        )
+        route_args["AuthorizerId"] = authorizer_id
+        route_args["AuthorizationType"] = "JWT" if authorizer_config.get("jwt") else "CUSTOM"
+
+    for route in paginate(client_apigatewayv2.get_routes, "Items", ApiId=api_id):
+        if route["RouteKey"] == "$default":
+            client_apigatewayv2.update_route(RouteId=route["RouteId"], **route_args)
+            break
+    else:
+        client_apigatewayv2.create_route(RouteKey="$default", **route_args)
+
+    return api["ApiEndpoint"]
+
+
+def get_or_create_vpc_link(client_apigatewayv2, cluster_name, subnet_ids, security_group_id, tags):
+    vpc_link = get_vpc_link(cluster_name, client_apigatewayv2)
+    if vpc_link is None:
+        vpc_link = client_apigatewayv2.create_vpc_link(
+            Name=f"cortex-{cluster_name}",
+            SubnetIds=subnet_ids,
+            SecurityGroupIds=[security_group_id],
+            Tags=tags,
+        )
+
+    while vpc_link["VpcLinkStatus"] == "PENDING":
+        time.sleep(5)
+        vpc_link = client_apigatewayv2.get_vpc_link(VpcLinkId=vpc_link["VpcLinkId"])
+
+    if vpc_link["VpcLinkStatus"] != "AVAILABLE":
+        raise Exception(
+            f"vpc link {vpc_link['VpcLinkId']} is {vpc_link['VpcLinkStatus']}: {vpc_link.get('VpcLinkStatusMessage', '')}"
+        )
+
+    return vpc_link["VpcLinkId"]
+
+
+def setup_stage(client_apigatewayv2, api_id, api_gateway_config, tags):
+    stages = paginate(client_apigatewayv2.get_stages, "Items", ApiId=api_id)
+    stage_names = [stage["StageName"] for stage in stages]
+    if "$default" not in stage_names:
+        client_apigatewayv2.create_stage(
+            ApiId=api_id, StageName="$default", AutoDeploy=True, Tags=tags
+        )
+
+    route_settings = {}
+    if api_gateway_config.get("throttling_rate_limit") is not None:
+        route_settings["ThrottlingRateLimit"] = api_gateway_config["throttling_rate_limit"]
+    if api_gateway_config.get("throttling_burst_limit") is not None:
+        route_settings["ThrottlingBurstLimit"] = api_gateway_config["throttling_burst_limit"]
+    if len(route_settings) > 0:
+        client_apigatewayv2.update_stage(
+            ApiId=api_id, StageName="$default", DefaultRouteSettings=route_settings
+        )
+
+
+def setup_authorizer(client_apigatewayv2, cluster_config, api_id, authorizer_config):
+    authorizer_args = {"ApiId": api_id, "Name": AUTHORIZER_NAME}
+    if authorizer_config.get("jwt") is not None:
+        jwt_config = authorizer_config["jwt"]
+        authorizer_args["AuthorizerType"] = "JWT"
+        authorizer_args["IdentitySource"] = [jwt_config["identity_source"]]
+        authorizer_args["JwtConfiguration"] = {
+            "Issuer": jwt_config["issuer"],
+            "Audience": jwt_config["audience"],
+        }
+    else:
+        lambda_config = authorizer_config["lambda"]
+        region = cluster_config["region"]
+        partition = boto3.session.Session().get_partition_for_region(region)
+        lambda_path = f"arn:{partition}:apigateway:{region}:lambda:path"
+        function_path = f"functions/{lambda_config['function_arn']}/invocations"
+        authorizer_args["AuthorizerType"] = "REQUEST"
+        authorizer_args["AuthorizerUri"] = f"{lambda_path}/2015-03-31/{function_path}"
+        authorizer_args["AuthorizerPayloadFormatVersion"] = "2.0"
+        authorizer_args["EnableSimpleResponses"] = True
+        authorizer_args["IdentitySource"] = lambda_config["identity_source"]
+        authorizer_args["AuthorizerResultTtlInSeconds"] = duration_seconds(
+            lambda_config["result_ttl"]
+        )
+
+    authorizer_id = None
+    for authorizer in paginate(client_apigatewayv2.get_authorizers, "Items", ApiId=api_id):
+        if authorizer["Name"] == AUTHORIZER_NAME:
+            authorizer_id = authorizer["AuthorizerId"]
+
+    # an authorizer's type can't be updated
+    if authorizer_id is not None:
+        authorizer = client_apigatewayv2.get_authorizer(ApiId=api_id, AuthorizerId=authorizer_id)
+        if authorizer["AuthorizerType"] == authorizer_args["AuthorizerType"]:
+            client_apigatewayv2.update_authorizer(AuthorizerId=authorizer_id, **authorizer_args)
+        else:
+            for route in paginate(client_apigatewayv2.get_routes, "Items", ApiId=api_id):
+                if route.get("AuthorizerId") == authorizer_id:
+                    client_apigatewayv2.update_route(
+                        ApiId=api_id, RouteId=route["RouteId"], AuthorizationType="NONE"
+                    )
+            client_apigatewayv2.delete_authorizer(ApiId=api_id, AuthorizerId=authorizer_id)
+            authorizer_id = None
+
+    if authorizer_id is None:
+        authorizer_id = client_apigatewayv2.create_authorizer(**authorizer_args)["AuthorizerId"]
+
+    if authorizer_config.get("lambda") is not None:
+        lambda_config = authorizer_config["lambda"]
+        allow_authorizer_invocation(cluster_config, api_id, authorizer_id, lambda_config)
+
+    return authorizer_id
+
+
+def allow_authorizer_invocation(cluster_config, api_id, authorizer_id, lambda_config):
+    region = cluster_config["region"]
+    partition = boto3.session.Session().get_partition_for_region(region)
+    account_id = cluster_config["account_id"]
+
+    client_lambda = boto3.client("lambda", region_name=region)
+    source_arn = f"arn:{partition}:execute-api:{region}:{account_id}:{api_id}"
+    try:
+        client_lambda.add_permission(
+            FunctionName=lambda_config["function_arn"],
+            StatementId=f"cortex-{cluster_config['cluster_name']}-{authorizer_id}",
+            Action="lambda:InvokeFunction",
+            Principal="apigateway.amazonaws.com",
+            SourceArn=f"{source_arn}/authorizers/{authorizer_id}",
+        )
+    except client_lambda.exceptions.ResourceConflictException:
+        pass  # the permission already exists
+
+
+# parses a go duration string (e.g. "5m" or "1h30m"), which the cli validates to be a whole number of seconds
+def duration_seconds(duration):
+    units = {"h": 3600, "m": 60, "s": 1, "ms": 1e-3, "us": 1e-6, "µs": 1e-6, "ns": 1e-9}
+    seconds = 0
+    for value, unit in re.findall(r"([0-9.]+)(h|ms|m|s|us|µs|ns)", duration):
+        seconds += float(value) * units[unit]
+    return round(seconds)
+
+
+if __name__ == "__main__":
+    with open(sys.argv[1], "r") as f:
+        cluster_config = yaml.safe_load(f)
+    setup_api_load_balancer(cluster_config)
@@ -0,0 +1,109 @@
+# Copyright 2021 Cortex Labs, Inc.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+import sys
+import time
+
+import boto3
+import yaml
+
+from helpers import get_efs_security_group_name
+
+
+# creates a mount target for the cluster's efs file system in each of the cluster's availability zones
+def setup_efs(cluster_config):
+    cluster_name = cluster_config["cluster_name"]
+    region = cluster_config["region"]
+    file_system_id = cluster_config["efs_file_system_id"]
+
+    client_eks = boto3.client("eks", region_name=region)
+    client_ec2 = boto3.client("ec2", region_name=region)
+    client_efs = boto3.client("efs", region_name=region)
+
+    wait_for_file_system(client_efs, file_system_id)
+
+    vpc_config = client_eks.describe_cluster(name=cluster_name)["cluster"]["resourcesVpcConfig"]
+    vpc_id = vpc_config["vpcId"]
+    vpc_cidr = client_ec2.describe_vpcs(VpcIds=[vpc_id])["Vpcs"][0]["CidrBlock"]
+
+    security_group_id = get_or_create_security_group(
+        client_ec2, cluster_name, vpc_id, vpc_cidr, cluster_config.get("tags", {})
+    )
+
+    mount_targets = client_efs.describe_mount_targets(FileSystemId=file_system_id)["MountTargets"]
+    availability_zones = set(mount_target["AvailabilityZoneName"] for mount_target in mount_targets)
+
+    subnets = client_ec2.describe_subnets(SubnetIds=vpc_config["subnetIds"])["Subnets"]
+    for subnet in subnets:
+        if subnet["AvailabilityZone"] in availability_zones:
+            continue
+        client_efs.create_mount_target(
+            FileSystemId=file_system_id,
+            SubnetId=subnet["SubnetId"],
+            SecurityGroups=[security_group_id],
+        )
+        availability_zones.add(subnet["AvailabilityZone"])
+
+
+def wait_for_file_system(client_efs, file_system_id):
+    while True:
+        file_system = client_efs.describe_file_systems(FileSystemId=file_system_id)["FileSystems"][0]
+        if file_system["LifeCycleState"] == "available":
+            return
+        time.sleep(5)
+
+
+def get_or_create_security_group(client_ec2, cluster_name, vpc_id, vpc_cidr, tags):
+    group_name = get_efs_security_group_name(cluster_name)
+
+    security_groups = client_ec2.describe_security_groups(
+        Filters=[
+            {"Name": "vpc-id", "Values": [vpc_id]},
+            {"Name": "group-name", "Values": [group_name]},
+        ]
+    )["SecurityGroups"]
+    if len(security_groups) > 0:
+        return security_groups[0]["GroupId"]
+
+    security_group_id = client_ec2.create_security_group(
+        GroupName=group_name,
+        Description=f"allows nfs traffic from the {cluster_name} cluster to its efs file system",
+        VpcId=vpc_id,
+        TagSpecifications=[
+            {
+                "ResourceType": "security-group",
+                "Tags": [{"Key": key, "Value": value} for key, value in tags.items()],
+            }
+        ],
+    )["GroupId"]
+
+    client_ec2.authorize_security_group_ingress(
+        GroupId=security_group_id,
+        IpPermissions=[
+            {
+                "IpProtocol": "tcp",
+                "FromPort": 2049,
+                "ToPort": 2049,
+                "IpRanges": [{"CidrIp": vpc_cidr}],
+            }
+        ],
+    )
+
+    return security_group_id
+
+
+if __name__ == "__main__":
+    with open(sys.argv[1], "r") as f:
+        cluster_config = yaml.safe_load(f)
+    setup_efs(cluster_config)
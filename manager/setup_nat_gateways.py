@@ -0,0 +1,116 @@
+# Copyright 2021 Cortex Labs, Inc.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+import sys
+
+import boto3
+import yaml
+
+from helpers import get_nat_gateways
+
+
+# eksctl allocates a new elastic ip for each nat gateway that it creates, so each of eksctl's nat
+# gateways is replaced by a nat gateway in the same public subnet which uses one of the elastic ips
+# in nat_gateway_elastic_ips; the private subnets' routes are pointed at the replacements before
+# eksctl's nat gateways are deleted (eksctl's elastic ips are released along with the vpc stack)
+def setup_nat_gateways(cluster_config):
+    allocation_ids = cluster_config.get("nat_gateway_elastic_ips")
+    if not allocation_ids:
+        return
+
+    cluster_name = cluster_config["cluster_name"]
+    region = cluster_config["region"]
+
+    client_eks = boto3.client("eks", region_name=region)
+    client_ec2 = boto3.client("ec2", region_name=region)
+
+    vpc_config = client_eks.describe_cluster(name=cluster_name)["cluster"]["resourcesVpcConfig"]
+    vpc_id = vpc_config["vpcId"]
+
+    nat_gateways = get_nat_gateways(vpc_id, client_ec2)
+    pinned_nat_gateways = {}  # subnet id -> nat gateway
+    eksctl_nat_gateways = []
+    for nat_gateway in nat_gateways:
+        if get_allocation_id(nat_gateway) in allocation_ids:
+            pinned_nat_gateways[nat_gateway["SubnetId"]] = nat_gateway
+        else:
+            eksctl_nat_gateways.append(nat_gateway)
+
+    pinned_allocation_ids = [get_allocation_id(g) for g in pinned_nat_gateways.values()]
+    unused_allocation_ids = [a for a in allocation_ids if a not in pinned_allocation_ids]
+
+    subnet_zones = get_subnet_zones(client_ec2, [g["SubnetId"] for g in eksctl_nat_gateways])
+    eksctl_nat_gateways.sort(key=lambda nat_gateway: subnet_zones[nat_gateway["SubnetId"]])
+
+    for nat_gateway in eksctl_nat_gateways:
+        subnet_id = nat_gateway["SubnetId"]
+        if subnet_id in pinned_nat_gateways:
+            replacement_id = pinned_nat_gateways[subnet_id]["NatGatewayId"]
+        else:
+            replacement_id = client_ec2.create_nat_gateway(
+                SubnetId=subnet_id,
+                AllocationId=unused_allocation_ids.pop(0),
+                TagSpecifications=[
+                    {
+                        "ResourceType": "natgateway",
+                        "Tags": [
+                            {"Key": key, "Value": value}
+                            for key, value in cluster_config.get("tags", {}).items()
+                        ],
+                    }
+                ],
+            )["NatGateway"]["NatGatewayId"]
+
+        client_ec2.get_waiter("nat_gateway_available").wait(NatGatewayIds=[replacement_id])
+        replace_routes(client_ec2, vpc_id, nat_gateway["NatGatewayId"], replacement_id)
+        client_ec2.delete_nat_gateway(NatGatewayId=nat_gateway["NatGatewayId"])
+
+
+def get_allocation_id(nat_gateway):
+    for address in nat_gateway.get("NatGatewayAddresses", []):
+        if "AllocationId" in address:
+            return address["AllocationId"]
+    return None
+
+
+def get_subnet_zones(client_ec2, subnet_ids):
+    if len(subnet_ids) == 0:
+        return {}
+    subnets = client_ec2.describe_subnets(SubnetIds=subnet_ids)["Subnets"]
+    return {subnet["SubnetId"]: subnet["AvailabilityZone"] for subnet in subnets}
+
+
+def replace_routes(client_ec2, vpc_id, nat_gateway_id, replacement_id):
+    route_tables = client_ec2.describe_route_tables(
+        Filters=[
+            {"Name": "vpc-id", "Values": [vpc_id]},
+            {"Name": "route.nat-gateway-id", "Values": [nat_gateway_id]},
+        ]
+    )["RouteTables"]
+
+    for route_table in route_tables:
+        for route in route_table["Routes"]:
+            if route.get("NatGatewayId") != nat_gateway_id or "DestinationCidrBlock" not in route:
+                continue
+            client_ec2.replace_route(
+                RouteTableId=route_table["RouteTableId"],
+                DestinationCidrBlock=route["DestinationCidrBlock"],
+                NatGatewayId=replacement_id,
+            )
+
+
+if __name__ == "__main__":
+    with open(sys.argv[1], "r") as f:
+        cluster_config = yaml.safe_load(f)
+    setup_nat_gateways(cluster_config)
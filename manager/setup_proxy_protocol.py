@@ -0,0 +1,57 @@
+# Copyright 2021 Cortex Labs, Inc.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+import sys
+
+import boto3
+import yaml
+
+from helpers import get_api_load_balancer
+
+# the istio gateway's status port isn't used by clients, and doesn't expect a proxy protocol header
+_STATUS_PORT = 15021
+
+
+# the in-tree kubernetes load balancer controller doesn't support the proxy protocol for network
+# load balancers, so it's enabled on the api load balancer's target groups once they're created
+def setup_proxy_protocol(cluster_config):
+    if not cluster_config.get("api_load_balancer_proxy_protocol"):
+        return
+
+    cluster_name = cluster_config["cluster_name"]
+    region = cluster_config["region"]
+
+    client_elbv2 = boto3.client("elbv2", region_name=region)
+
+    load_balancer = get_api_load_balancer(cluster_name, client_elbv2)
+    listeners = client_elbv2.describe_listeners(LoadBalancerArn=load_balancer["LoadBalancerArn"])[
+        "Listeners"
+    ]
+
+    for listener in listeners:
+        if listener["Port"] == _STATUS_PORT:
+            continue
+        for action in listener["DefaultActions"]:
+            if "TargetGroupArn" not in action:
+                continue
+            client_elbv2.modify_target_group_attributes(
+                TargetGroupArn=action["TargetGroupArn"],
+                Attributes=[{"Key": "proxy_protocol_v2.enabled", "Value": "true"}],
+            )
+
+
+if __name__ == "__main__":
+    with open(sys.argv[1], "r") as f:
+        cluster_config = yaml.safe_load(f)
+    setup_proxy_protocol(cluster_config)
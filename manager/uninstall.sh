@@ -23,10 +23,46 @@ arg1="$1"
 function main() {
   echo
   aws eks --region $CORTEX_REGION update-kubeconfig --name $CORTEX_CLUSTER_NAME >/dev/null
+  remove_efs_mount_targets
+  remove_api_load_balancer
+  remove_nat_gateways
+  remove_karpenter_instances
   eksctl delete cluster --wait --name=$CORTEX_CLUSTER_NAME --region=$CORTEX_REGION --timeout=$EKSCTL_TIMEOUT
   echo -e "\n✓ done spinning down the cluster"
 }
 
+# the efs file system itself is deleted by the cli (unless --keep-aws-resources is used)
+function remove_efs_mount_targets() {
+  kubectl get configmap cluster-config -o jsonpath='{.data.cluster\.yaml}' > ./cluster.yaml
+
+  python remove_efs_mount_targets.py ./cluster.yaml
+}
+
+# the application load balancer and api gateway (if any) aren't part of the eksctl stacks
+function remove_api_load_balancer() {
+  kubectl get configmap cluster-config -o jsonpath='{.data.cluster\.yaml}' > ./cluster.yaml
+
+  python remove_api_load_balancer.py ./cluster.yaml
+}
+
+# the nat gateways which use the elastic ips in nat_gateway_elastic_ips (if any) aren't part of the eksctl stacks
+function remove_nat_gateways() {
+  kubectl get configmap cluster-config -o jsonpath='{.data.cluster\.yaml}' > ./cluster.yaml
+
+  python remove_nat_gateways.py ./cluster.yaml
+}
+
+# the instances which were launched by karpenter aren't part of the eksctl stacks, so they are terminated before the cluster is deleted
+# (karpenter terminates the instances of a provisioner when it is deleted)
+function remove_karpenter_instances() {
+  if ! kubectl get crd provisioners.karpenter.sh >/dev/null 2>&1; then
+    return
+  fi
+
+  kubectl delete provisioners.karpenter.sh --all >/dev/null
+  kubectl wait --for=delete node --selector=karpenter.sh/provisioner-name --timeout=10m >/dev/null 2>&1 || true
+}
+
 function uninstall_prometheus() {
   kubectl get configmap cluster-config -o jsonpath='{.data.cluster\.yaml}' > ./cluster.yaml
 
@@ -19,9 +19,13 @@ package gateway
 import (
 	"encoding/json"
 	"fmt"
+	"math"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/lib/logging"
 	"github.com/cortexlabs/cortex/pkg/lib/telemetry"
 	"github.com/cortexlabs/cortex/pkg/types/async"
 	"github.com/gorilla/mux"
@@ -30,21 +34,36 @@ import (
 
 // Endpoint wraps an async-gateway Service with HTTP logic
 type Endpoint struct {
-	service Service
-	logger  *zap.SugaredLogger
+	service    Service
+	metrics    *Metrics
+	shedder    *LoadShedder
+	expiration time.Duration // the api's default expiration; 0 if workloads don't expire
+	logger     *zap.SugaredLogger
 }
 
 // NewEndpoint creates and initializes a new Endpoint struct
-func NewEndpoint(svc Service, logger *zap.SugaredLogger) *Endpoint {
+func NewEndpoint(svc Service, metrics *Metrics, shedder *LoadShedder, expiration time.Duration, logger *zap.SugaredLogger) *Endpoint {
 	return &Endpoint{
-		service: svc,
-		logger:  logger,
+		service:    svc,
+		metrics:    metrics,
+		shedder:    shedder,
+		expiration: expiration,
+		logger:     logger,
 	}
 }
 
 // CreateWorkload is a handler for the async-gateway service workload creation route
 func (e *Endpoint) CreateWorkload(w http.ResponseWriter, r *http.Request) {
-	requestID := r.Header.Get("x-request-id")
+	e.metrics.submitted()
+
+	if shedding, retryAfter := e.shedder.Shedding(); shedding {
+		e.metrics.rejectedSubmission(RejectedLoadShedding)
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+		respondPlainText(w, http.StatusServiceUnavailable, "error: the api is temporarily overloaded, please retry later")
+		return
+	}
+
+	requestID := r.Header.Get(logging.RequestIDHeader)
 	if requestID == "" {
 		respondPlainText(w, http.StatusBadRequest, "error: missing x-request-id key in request header")
 		return
@@ -56,14 +75,39 @@ func (e *Endpoint) CreateWorkload(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	metadata := async.MetadataFromHeaders(r.Header)
+	if len(metadata) > async.MaxMetadataKeys {
+		respondPlainText(w, http.StatusBadRequest, fmt.Sprintf("error: at most %d metadata keys can be specified (got %d)", async.MaxMetadataKeys, len(metadata)))
+		return
+	}
+	if metadataSize := async.MetadataSize(metadata); metadataSize > async.MaxMetadataSize {
+		respondPlainText(w, http.StatusBadRequest, fmt.Sprintf("error: metadata keys and values must not exceed %d bytes in total (got %d bytes)", async.MaxMetadataSize, metadataSize))
+		return
+	}
+
+	expiration := e.expiration
+	if expirationStr := r.Header.Get(async.ExpirationHeader); expirationStr != "" {
+		var ok bool
+		if expiration, ok = async.ParseExpiration(expirationStr); !ok {
+			respondPlainText(w, http.StatusBadRequest, fmt.Sprintf("error: invalid %s header: %s (must be a positive duration, e.g. 5m, or number of seconds)", async.ExpirationHeader, expirationStr))
+			return
+		}
+	}
+
+	var expiresAt *time.Time
+	if expiration > 0 {
+		t := time.Now().Add(expiration)
+		expiresAt = &t
+	}
+
 	body := r.Body
 	defer func() {
 		_ = r.Body.Close()
 	}()
 
-	log := e.logger.With(zap.String("id", requestID), zap.String("contentType", contentType))
+	log := e.logger.With(zap.String(logging.RequestIDField, requestID), zap.String("contentType", contentType))
 
-	id, err := e.service.CreateWorkload(requestID, body, contentType)
+	id, err := e.service.CreateWorkload(requestID, body, contentType, metadata, expiresAt)
 	if err != nil {
 		respondPlainText(w, http.StatusInternalServerError, fmt.Sprintf("error: %v", err))
 		logErrorWithTelemetry(log, errors.Wrap(err, "failed to create workload"))
@@ -85,7 +129,7 @@ func (e *Endpoint) GetWorkload(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	log := e.logger.With(zap.String("id", id))
+	log := e.logger.With(zap.String(logging.RequestIDField, id))
 
 	res, err := e.service.GetWorkload(id)
 	if err != nil {
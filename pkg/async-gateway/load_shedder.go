@@ -0,0 +1,77 @@
+/*
+Copyright 2021 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gateway
+
+import (
+	"sync"
+	"time"
+)
+
+// the weight of the latest write in the moving average of the write latency
+const _writeLatencyEWMAWeight = 0.2
+
+// LoadShedder rejects submissions while the gateway's writes to s3 and sqs are slow,
+// so that clients back off instead of piling up requests behind a degraded backend
+type LoadShedder struct {
+	sync.Mutex
+	maxWriteLatency time.Duration
+	retryAfter      time.Duration
+	writeLatency    time.Duration // exponentially weighted moving average
+	sheddingUntil   time.Time
+}
+
+// NewLoadShedder creates a LoadShedder which sheds load for retryAfter once the average write latency exceeds maxWriteLatency
+func NewLoadShedder(maxWriteLatency time.Duration, retryAfter time.Duration) *LoadShedder {
+	return &LoadShedder{
+		maxWriteLatency: maxWriteLatency,
+		retryAfter:      retryAfter,
+	}
+}
+
+// ObserveWriteLatency records the latency of a write to s3 or sqs
+func (l *LoadShedder) ObserveWriteLatency(latency time.Duration) {
+	l.Lock()
+	defer l.Unlock()
+
+	if l.writeLatency == 0 {
+		l.writeLatency = latency
+	} else {
+		l.writeLatency = time.Duration(_writeLatencyEWMAWeight*float64(latency) + (1-_writeLatencyEWMAWeight)*float64(l.writeLatency))
+	}
+
+	if l.writeLatency > l.maxWriteLatency && time.Now().After(l.sheddingUntil) {
+		l.sheddingUntil = time.Now().Add(l.retryAfter)
+	}
+}
+
+// Shedding returns whether submissions should currently be rejected, and if so, how long the client should wait before retrying
+func (l *LoadShedder) Shedding() (bool, time.Duration) {
+	l.Lock()
+	defer l.Unlock()
+
+	remaining := time.Until(l.sheddingUntil)
+	if remaining <= 0 {
+		if !l.sheddingUntil.IsZero() {
+			// start over once the shedding window has passed, since no writes were made to update the average
+			l.sheddingUntil = time.Time{}
+			l.writeLatency = 0
+		}
+		return false, 0
+	}
+
+	return true, remaining
+}
@@ -0,0 +1,87 @@
+/*
+Copyright 2021 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gateway
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// rejection reasons
+const (
+	RejectedLoadShedding = "load_shedding"
+)
+
+// write backends
+const (
+	BackendS3  = "s3"
+	BackendSQS = "sqs"
+)
+
+// Metrics exposes the async-gateway's submission metrics to prometheus
+type Metrics struct {
+	handler      http.Handler
+	submissions  prometheus.Counter
+	rejected     *prometheus.CounterVec
+	writeLatency *prometheus.HistogramVec
+}
+
+// NewMetrics registers the async-gateway's prometheus metrics
+func NewMetrics() *Metrics {
+	submissions := promauto.NewCounter(prometheus.CounterOpts{
+		Name: "cortex_async_gateway_submissions_total",
+		Help: "The number of workloads submitted to an AsyncAPI's gateway (including rejected submissions)",
+	})
+
+	rejected := promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "cortex_async_gateway_rejected_submissions_total",
+		Help: "The number of workload submissions rejected by an AsyncAPI's gateway",
+	}, []string{"reason"})
+
+	writeLatency := promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "cortex_async_gateway_write_latency_seconds",
+		Help:    "Histogram of the latencies of an AsyncAPI's gateway writes to s3 and sqs in seconds",
+		Buckets: []float64{0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30},
+	}, []string{"backend"})
+
+	return &Metrics{
+		handler:      promhttp.Handler(),
+		submissions:  submissions,
+		rejected:     rejected,
+		writeLatency: writeLatency,
+	}
+}
+
+func (m *Metrics) submitted() {
+	m.submissions.Inc()
+}
+
+func (m *Metrics) rejectedSubmission(reason string) {
+	m.rejected.WithLabelValues(reason).Inc()
+}
+
+func (m *Metrics) observeWriteLatency(backend string, latency time.Duration) {
+	m.writeLatency.WithLabelValues(backend).Observe(latency.Seconds())
+}
+
+func (m *Metrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	m.handler.ServeHTTP(w, r)
+}
@@ -17,14 +17,17 @@ limitations under the License.
 package gateway
 
 import (
+	"time"
+
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/session"
 	awssqs "github.com/aws/aws-sdk-go/service/sqs"
+	"github.com/cortexlabs/cortex/pkg/types/async"
 )
 
 // Queue is an interface to abstract communication with event queues
 type Queue interface {
-	SendMessage(message string, uniqueID string) error
+	SendMessage(message string, uniqueID string, expiresAt *time.Time) error
 }
 
 type sqs struct {
@@ -39,13 +42,18 @@ func NewSQS(queueURL string, sess *session.Session) Queue {
 	return &sqs{queueURL: queueURL, client: client}
 }
 
-// SendMessage sends a string
-func (q *sqs) SendMessage(message string, uniqueID string) error {
-	_, err := q.client.SendMessage(&awssqs.SendMessageInput{
+// SendMessage sends a string; if expiresAt is set, it is attached to the message so that the dequeuer can skip it once it has expired
+func (q *sqs) SendMessage(message string, uniqueID string, expiresAt *time.Time) error {
+	input := &awssqs.SendMessageInput{
 		MessageBody:            aws.String(message),
 		MessageDeduplicationId: aws.String(uniqueID),
 		MessageGroupId:         aws.String(uniqueID),
 		QueueUrl:               aws.String(q.queueURL),
-	})
+	}
+	if expiresAt != nil {
+		input.MessageAttributes = async.ExpiresAtAttributes(*expiresAt)
+	}
+
+	_, err := q.client.SendMessage(input)
 	return err
 }
@@ -17,18 +17,21 @@ limitations under the License.
 package gateway
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"io"
 	"strings"
+	"time"
 
+	"github.com/cortexlabs/cortex/pkg/lib/logging"
 	"github.com/cortexlabs/cortex/pkg/types/async"
 	"go.uber.org/zap"
 )
 
 // Service provides an interface to the async-gateway business logic
 type Service interface {
-	CreateWorkload(id string, payload io.Reader, contentType string) (string, error)
+	CreateWorkload(id string, payload io.Reader, contentType string, metadata map[string]string, expiresAt *time.Time) (string, error)
 	GetWorkload(id string) (GetWorkloadResponse, error)
 }
 
@@ -38,38 +41,64 @@ type service struct {
 	storage    Storage
 	clusterUID string
 	apiName    string
+	metrics    *Metrics
+	shedder    *LoadShedder
 }
 
 // NewService creates a new async-gateway service
-func NewService(clusterUID, apiName string, queue Queue, storage Storage, logger *zap.SugaredLogger) Service {
+func NewService(clusterUID, apiName string, queue Queue, storage Storage, metrics *Metrics, shedder *LoadShedder, logger *zap.SugaredLogger) Service {
 	return &service{
 		logger:     logger,
 		queue:      queue,
 		storage:    storage,
 		clusterUID: clusterUID,
 		apiName:    apiName,
+		metrics:    metrics,
+		shedder:    shedder,
 	}
 }
 
-// CreateWorkload enqueues an async workload request and uploads the request payload to S3
-func (s *service) CreateWorkload(id string, payload io.Reader, contentType string) (string, error) {
+// CreateWorkload enqueues an async workload request and uploads the request payload (and metadata, if any) to S3
+func (s *service) CreateWorkload(id string, payload io.Reader, contentType string, metadata map[string]string, expiresAt *time.Time) (string, error) {
 	prefix := async.StoragePath(s.clusterUID, s.apiName)
-	log := s.logger.With(zap.String("id", id), zap.String("contentType", contentType))
+	log := s.logger.With(zap.String(logging.RequestIDField, id), zap.String("contentType", contentType))
 
 	payloadPath := async.PayloadPath(prefix, id)
 	log.Debug("uploading payload", zap.String("path", payloadPath))
-	if err := s.storage.Upload(payloadPath, payload, contentType); err != nil {
+	// the payload upload is not used for load shedding, since its latency depends on the size of the payload
+	if err := s.timeWrite(BackendS3, false, func() error {
+		return s.storage.Upload(payloadPath, payload, contentType)
+	}); err != nil {
 		return "", err
 	}
 
+	if len(metadata) > 0 {
+		metadataBytes, err := json.Marshal(metadata)
+		if err != nil {
+			return "", err
+		}
+
+		metadataPath := async.MetadataPath(prefix, id)
+		log.Debug("uploading metadata", zap.String("path", metadataPath))
+		if err := s.timeWrite(BackendS3, true, func() error {
+			return s.storage.Upload(metadataPath, bytes.NewReader(metadataBytes), "application/json")
+		}); err != nil {
+			return "", err
+		}
+	}
+
 	log.Debug("sending message to queue")
-	if err := s.queue.SendMessage(id, id); err != nil {
+	if err := s.timeWrite(BackendSQS, true, func() error {
+		return s.queue.SendMessage(id, id, expiresAt)
+	}); err != nil {
 		return "", err
 	}
 
 	statusPath := fmt.Sprintf("%s/%s/status/%s", prefix, id, async.StatusInQueue)
 	log.Debug(fmt.Sprintf("setting status to %s", async.StatusInQueue))
-	if err := s.storage.Upload(statusPath, strings.NewReader(""), "text/plain"); err != nil {
+	if err := s.timeWrite(BackendS3, true, func() error {
+		return s.storage.Upload(statusPath, strings.NewReader(""), "text/plain")
+	}); err != nil {
 		return "", err
 	}
 
@@ -78,20 +107,33 @@ func (s *service) CreateWorkload(id string, payload io.Reader, contentType strin
 
 // GetWorkload retrieves the status and result, if available, of a given workload
 func (s *service) GetWorkload(id string) (GetWorkloadResponse, error) {
-	log := s.logger.With(zap.String("id", id))
+	log := s.logger.With(zap.String(logging.RequestIDField, id))
 
 	st, err := s.getStatus(id)
 	if err != nil {
 		return GetWorkloadResponse{}, err
 	}
 
-	if st != async.StatusCompleted {
+	if st == async.StatusNotFound {
 		return GetWorkloadResponse{
 			ID:     id,
 			Status: st,
 		}, nil
 	}
 
+	metadata, err := s.getMetadata(id)
+	if err != nil {
+		return GetWorkloadResponse{}, err
+	}
+
+	if st != async.StatusCompleted {
+		return GetWorkloadResponse{
+			ID:       id,
+			Status:   st,
+			Metadata: metadata,
+		}, nil
+	}
+
 	// attempt to download user result
 	prefix := async.StoragePath(s.clusterUID, s.apiName)
 	resultPath := async.ResultPath(prefix, id)
@@ -115,14 +157,50 @@ func (s *service) GetWorkload(id string) (GetWorkloadResponse, error) {
 	return GetWorkloadResponse{
 		ID:        id,
 		Status:    st,
+		Metadata:  metadata,
 		Result:    &userResponse,
 		Timestamp: &timestamp,
 	}, nil
 }
 
+// getMetadata returns the metadata which was attached to the workload when it was submitted, or nil if there is none
+func (s *service) getMetadata(id string) (map[string]string, error) {
+	prefix := async.StoragePath(s.clusterUID, s.apiName)
+	metadataPath := async.MetadataPath(prefix, id)
+	s.logger.With(zap.String(logging.RequestIDField, id)).Debug("downloading metadata", zap.String("path", metadataPath))
+
+	metadataBuf, err := s.storage.DownloadIfExists(metadataPath)
+	if err != nil {
+		return nil, err
+	}
+	if metadataBuf == nil {
+		return nil, nil
+	}
+
+	var metadata map[string]string
+	if err = json.Unmarshal(metadataBuf, &metadata); err != nil {
+		return nil, err
+	}
+	return metadata, nil
+}
+
+// timeWrite records the latency of a write to s3 or sqs
+func (s *service) timeWrite(backend string, shed bool, write func() error) error {
+	start := time.Now()
+	err := write()
+	latency := time.Since(start)
+
+	s.metrics.observeWriteLatency(backend, latency)
+	if shed {
+		s.shedder.ObserveWriteLatency(latency)
+	}
+
+	return err
+}
+
 func (s *service) getStatus(id string) (async.Status, error) {
 	prefix := async.StoragePath(s.clusterUID, s.apiName)
-	log := s.logger.With(zap.String("id", id))
+	log := s.logger.With(zap.String(logging.RequestIDField, id))
 
 	// download workload status
 	statusPrefixPath := async.StatusPrefixPath(prefix, id)
@@ -136,21 +214,14 @@ func (s *service) getStatus(id string) (async.Status, error) {
 	}
 
 	// determine request status
-	st := async.StatusInQueue
+	statuses := make([]async.Status, 0, len(files))
 	for _, file := range files {
 		fileStatus := async.Status(file)
 		if !fileStatus.Valid() {
-			st = fileStatus
-			return "", fmt.Errorf("invalid workload status: %s", st)
-		}
-		if fileStatus == async.StatusInProgress {
-			st = fileStatus
-		}
-		if fileStatus == async.StatusCompleted || fileStatus == async.StatusFailed {
-			st = fileStatus
-			break
+			return "", fmt.Errorf("invalid workload status: %s", fileStatus)
 		}
+		statuses = append(statuses, fileStatus)
 	}
 
-	return st, nil
+	return async.ResolveStatus(statuses), nil
 }
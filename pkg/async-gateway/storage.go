@@ -26,12 +26,14 @@ import (
 	"github.com/aws/aws-sdk-go/aws/session"
 	awss3 "github.com/aws/aws-sdk-go/service/s3"
 	"github.com/aws/aws-sdk-go/service/s3/s3manager"
+	awslib "github.com/cortexlabs/cortex/pkg/lib/aws"
 )
 
 // Storage is an interface that abstracts cloud storage uploading
 type Storage interface {
 	Upload(key string, payload io.Reader, contentType string) error
 	Download(key string) ([]byte, error)
+	DownloadIfExists(key string) ([]byte, error)
 	List(key string) ([]string, error)
 	GetLastModified(key string) (time.Time, error)
 }
@@ -83,6 +85,18 @@ func (s *s3) Download(key string) ([]byte, error) {
 	return buff.Bytes(), nil
 }
 
+// DownloadIfExists downloads a file from S3 into memory, and returns nil if the file does not exist
+func (s *s3) DownloadIfExists(key string) ([]byte, error) {
+	buf, err := s.Download(key)
+	if err != nil {
+		if awslib.IsNoSuchKeyErr(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return buf, nil
+}
+
 // List lists a set of files from a given S3 path.
 // Works only for one level deep sub-paths.
 func (s *s3) List(key string) ([]string, error) {
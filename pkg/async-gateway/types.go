@@ -32,8 +32,9 @@ type CreateWorkloadResponse struct {
 
 // GetWorkloadResponse represents the workload response that is returned to the user
 type GetWorkloadResponse struct {
-	ID        string        `json:"id"`
-	Status    async.Status  `json:"status"`
-	Result    *UserResponse `json:"result,omitempty"`
-	Timestamp *time.Time    `json:"timestamp,omitempty"`
+	ID        string            `json:"id"`
+	Status    async.Status      `json:"status"`
+	Metadata  map[string]string `json:"metadata,omitempty"`
+	Result    *UserResponse     `json:"result,omitempty"`
+	Timestamp *time.Time        `json:"timestamp,omitempty"`
 }
@@ -33,13 +33,36 @@ var (
 	ProxyListeningPortStr   = "8888"
 	ProxyListeningPortInt32 = int32(8888)
 
-	AdminPortStr   = "15000"
-	AdminPortInt32 = int32(15000)
+	// must not overlap with the ports used by the istio sidecar (15000-15090), which is injected when mtls is enabled
+	AdminPortStr   = "15100"
+	AdminPortInt32 = int32(15100)
+
+	// the model manager sidecar of apis which serve multiple models
+	ModelManagerPortStr   = "15101"
+	ModelManagerPortInt32 = int32(15101)
 
 	StatsDPortStr   = "9125"
 	StatsDPortInt32 = int32(9125)
 
-	AuthHeader = "X-Cortex-Authorization"
+	AuthHeader          = "X-Cortex-Authorization"
+	OperatorTokenHeader = "X-Cortex-Token"
+
+	// set by traffic splitters on the requests which they route (and on their responses), to identify the chosen api
+	TrafficSplitterHeader = "X-Cortex-Traffic-Splitter"
+	VariantHeader         = "X-Cortex-Variant"
+
+	// the model which a request to an api with multiple models is routed to; the proxy adds the local path of the model's files to the request before forwarding it
+	ModelHeader     = "X-Cortex-Model"
+	ModelPathHeader = "X-Cortex-Model-Path"
+
+	// set by the proxy on responses: the time (in milliseconds) which the request spent in the proxy before it was forwarded to the api's container,
+	// the time until the container's response headers were received, and the name of the replica which handled the request
+	QueueTimeHeader    = "X-Cortex-Queue-Time"
+	UpstreamTimeHeader = "X-Cortex-Upstream-Time"
+	ReplicaIDHeader    = "X-Cortex-Replica-ID"
+
+	// set by the operator on list responses which have more results; its value is passed in the "continue" query param to get the next page
+	ContinueHeader = "X-Cortex-Continue"
 
 	DefaultInClusterConfigPath   = "/configs/cluster/cluster.yaml"
 	MaxBucketLifecycleRules      = 100
@@ -48,9 +71,13 @@ var (
 	ReservedContainerPorts = []int32{
 		ProxyListeningPortInt32,
 		AdminPortInt32,
+		ModelManagerPortInt32,
 	}
 	ReservedContainerNames = []string{
 		"dequeuer",
+		"downloader",
+		"kubexit",
+		"model-manager",
 		"proxy",
 	}
 )
@@ -0,0 +1,264 @@
+/*
+Copyright 2021 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"github.com/cortexlabs/cortex/pkg/types/userconfig"
+	kmeta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Finalizer is added to all api resources, so that the operator can delete the api before the resource is removed
+const Finalizer = "finalizers.api.cortex.dev"
+
+// APIStatus defines the observed state of a cortex api resource
+type APIStatus struct {
+	// The generation of the resource which was most recently deployed (or failed to deploy)
+	ObservedGeneration int64 `json:"observed_generation,omitempty"`
+
+	// ID of the deployed api
+	APIID string `json:"api_id,omitempty"`
+
+	// Endpoint of the deployed api
+	Endpoint string `json:"endpoint,omitempty"`
+
+	// Result of the most recent deployment
+	Message string `json:"message,omitempty"`
+
+	// Error of the most recent deployment, if it failed
+	Error string `json:"error,omitempty"`
+}
+
+// Object is implemented by all of the cortex api resources
+// +kubebuilder:object:generate=false
+type Object interface {
+	client.Object
+	APIKind() userconfig.Kind
+	APISpec() *runtime.RawExtension
+	APIStatus() *APIStatus
+}
+
+// ObjectList is implemented by the lists of all of the cortex api resources
+// +kubebuilder:object:generate=false
+type ObjectList interface {
+	client.ObjectList
+	Objects() []Object
+}
+
+// NewObjectLists returns an empty list for each of the cortex api resources
+func NewObjectLists() []ObjectList {
+	return []ObjectList{
+		&RealtimeAPIList{},
+		&AsyncAPIList{},
+		&BatchAPIList{},
+		&TrafficSplitterList{},
+	}
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:JSONPath=".status.api_id",name="API ID",type="string"
+// +kubebuilder:printcolumn:JSONPath=".status.endpoint",name="Endpoint",type="string"
+// +kubebuilder:printcolumn:JSONPath=".status.error",name="Error",type="string",priority=1
+
+// RealtimeAPI is the Schema for the realtimeapis API
+type RealtimeAPI struct {
+	kmeta.TypeMeta   `json:",inline"`
+	kmeta.ObjectMeta `json:"metadata,omitempty"`
+
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// The api configuration (the same fields as in a cortex api configuration file, excluding name and kind)
+	Spec   runtime.RawExtension `json:"spec,omitempty"`
+	Status APIStatus            `json:"status,omitempty"`
+}
+
+func (in *RealtimeAPI) APIKind() userconfig.Kind {
+	return userconfig.RealtimeAPIKind
+}
+
+func (in *RealtimeAPI) APISpec() *runtime.RawExtension {
+	return &in.Spec
+}
+
+func (in *RealtimeAPI) APIStatus() *APIStatus {
+	return &in.Status
+}
+
+// +kubebuilder:object:root=true
+
+// RealtimeAPIList contains a list of RealtimeAPI
+type RealtimeAPIList struct {
+	kmeta.TypeMeta `json:",inline"`
+	kmeta.ListMeta `json:"metadata,omitempty"`
+	Items          []RealtimeAPI `json:"items"`
+}
+
+func (in *RealtimeAPIList) Objects() []Object {
+	objects := make([]Object, len(in.Items))
+	for i := range in.Items {
+		objects[i] = &in.Items[i]
+	}
+	return objects
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:JSONPath=".status.api_id",name="API ID",type="string"
+// +kubebuilder:printcolumn:JSONPath=".status.endpoint",name="Endpoint",type="string"
+// +kubebuilder:printcolumn:JSONPath=".status.error",name="Error",type="string",priority=1
+
+// AsyncAPI is the Schema for the asyncapis API
+type AsyncAPI struct {
+	kmeta.TypeMeta   `json:",inline"`
+	kmeta.ObjectMeta `json:"metadata,omitempty"`
+
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// The api configuration (the same fields as in a cortex api configuration file, excluding name and kind)
+	Spec   runtime.RawExtension `json:"spec,omitempty"`
+	Status APIStatus            `json:"status,omitempty"`
+}
+
+func (in *AsyncAPI) APIKind() userconfig.Kind {
+	return userconfig.AsyncAPIKind
+}
+
+func (in *AsyncAPI) APISpec() *runtime.RawExtension {
+	return &in.Spec
+}
+
+func (in *AsyncAPI) APIStatus() *APIStatus {
+	return &in.Status
+}
+
+// +kubebuilder:object:root=true
+
+// AsyncAPIList contains a list of AsyncAPI
+type AsyncAPIList struct {
+	kmeta.TypeMeta `json:",inline"`
+	kmeta.ListMeta `json:"metadata,omitempty"`
+	Items          []AsyncAPI `json:"items"`
+}
+
+func (in *AsyncAPIList) Objects() []Object {
+	objects := make([]Object, len(in.Items))
+	for i := range in.Items {
+		objects[i] = &in.Items[i]
+	}
+	return objects
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:JSONPath=".status.api_id",name="API ID",type="string"
+// +kubebuilder:printcolumn:JSONPath=".status.endpoint",name="Endpoint",type="string"
+// +kubebuilder:printcolumn:JSONPath=".status.error",name="Error",type="string",priority=1
+
+// BatchAPI is the Schema for the batchapis API
+type BatchAPI struct {
+	kmeta.TypeMeta   `json:",inline"`
+	kmeta.ObjectMeta `json:"metadata,omitempty"`
+
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// The api configuration (the same fields as in a cortex api configuration file, excluding name and kind)
+	Spec   runtime.RawExtension `json:"spec,omitempty"`
+	Status APIStatus            `json:"status,omitempty"`
+}
+
+func (in *BatchAPI) APIKind() userconfig.Kind {
+	return userconfig.BatchAPIKind
+}
+
+func (in *BatchAPI) APISpec() *runtime.RawExtension {
+	return &in.Spec
+}
+
+func (in *BatchAPI) APIStatus() *APIStatus {
+	return &in.Status
+}
+
+// +kubebuilder:object:root=true
+
+// BatchAPIList contains a list of BatchAPI
+type BatchAPIList struct {
+	kmeta.TypeMeta `json:",inline"`
+	kmeta.ListMeta `json:"metadata,omitempty"`
+	Items          []BatchAPI `json:"items"`
+}
+
+func (in *BatchAPIList) Objects() []Object {
+	objects := make([]Object, len(in.Items))
+	for i := range in.Items {
+		objects[i] = &in.Items[i]
+	}
+	return objects
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:JSONPath=".status.api_id",name="API ID",type="string"
+// +kubebuilder:printcolumn:JSONPath=".status.endpoint",name="Endpoint",type="string"
+// +kubebuilder:printcolumn:JSONPath=".status.error",name="Error",type="string",priority=1
+
+// TrafficSplitter is the Schema for the trafficsplitters API
+type TrafficSplitter struct {
+	kmeta.TypeMeta   `json:",inline"`
+	kmeta.ObjectMeta `json:"metadata,omitempty"`
+
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// The api configuration (the same fields as in a cortex api configuration file, excluding name and kind)
+	Spec   runtime.RawExtension `json:"spec,omitempty"`
+	Status APIStatus            `json:"status,omitempty"`
+}
+
+func (in *TrafficSplitter) APIKind() userconfig.Kind {
+	return userconfig.TrafficSplitterKind
+}
+
+func (in *TrafficSplitter) APISpec() *runtime.RawExtension {
+	return &in.Spec
+}
+
+func (in *TrafficSplitter) APIStatus() *APIStatus {
+	return &in.Status
+}
+
+// +kubebuilder:object:root=true
+
+// TrafficSplitterList contains a list of TrafficSplitter
+type TrafficSplitterList struct {
+	kmeta.TypeMeta `json:",inline"`
+	kmeta.ListMeta `json:"metadata,omitempty"`
+	Items          []TrafficSplitter `json:"items"`
+}
+
+func (in *TrafficSplitterList) Objects() []Object {
+	objects := make([]Object, len(in.Items))
+	for i := range in.Items {
+		objects[i] = &in.Items[i]
+	}
+	return objects
+}
+
+func init() {
+	SchemeBuilder.Register(
+		&RealtimeAPI{}, &RealtimeAPIList{},
+		&AsyncAPI{}, &AsyncAPIList{},
+		&BatchAPI{}, &BatchAPIList{},
+		&TrafficSplitter{}, &TrafficSplitterList{},
+	)
+}
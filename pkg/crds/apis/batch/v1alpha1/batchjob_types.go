@@ -58,6 +58,10 @@ type BatchJobSpec struct {
 	// Node groups selector
 	NodeGroups []string `json:"node_groups"`
 
+	// +kubebuilder:validation:Optional
+	// CPU architecture of the nodes which the workers can run on
+	Arch string `json:"arch,omitempty"`
+
 	// +kubebuilder:validation:Optional
 	// +nullable
 	// Readiness probes for the job (container name -> probe)
@@ -29,9 +29,11 @@ import (
 	"github.com/aws/aws-sdk-go/service/sqs"
 	"github.com/cortexlabs/cortex/pkg/consts"
 	batch "github.com/cortexlabs/cortex/pkg/crds/apis/batch/v1alpha1"
+	awslib "github.com/cortexlabs/cortex/pkg/lib/aws"
 	"github.com/cortexlabs/cortex/pkg/lib/errors"
 	libjson "github.com/cortexlabs/cortex/pkg/lib/json"
 	"github.com/cortexlabs/cortex/pkg/lib/k8s"
+	libmath "github.com/cortexlabs/cortex/pkg/lib/math"
 	"github.com/cortexlabs/cortex/pkg/lib/parallel"
 	"github.com/cortexlabs/cortex/pkg/lib/pointer"
 	s "github.com/cortexlabs/cortex/pkg/lib/strings"
@@ -190,7 +192,12 @@ func (r *BatchJobReconciler) checkEnqueuingStatus(ctx context.Context, batchJob
 }
 
 func (r *BatchJobReconciler) enqueuePayload(ctx context.Context, batchJob batch.BatchJob, queueURL string) error {
-	enqueuerJob, err := r.desiredEnqueuerJob(batchJob, queueURL)
+	apiSpec, err := r.getAPISpec(batchJob)
+	if err != nil {
+		return err
+	}
+
+	enqueuerJob, err := r.desiredEnqueuerJob(batchJob, *apiSpec, queueURL)
 	if err != nil {
 		return err
 	}
@@ -258,7 +265,7 @@ func (r *BatchJobReconciler) createWorkerJob(ctx context.Context, batchJob batch
 	return nil
 }
 
-func (r *BatchJobReconciler) desiredEnqueuerJob(batchJob batch.BatchJob, queueURL string) (*kbatch.Job, error) {
+func (r *BatchJobReconciler) desiredEnqueuerJob(batchJob batch.BatchJob, apiSpec spec.API, queueURL string) (*kbatch.Job, error) {
 	job := k8s.Job(
 		&k8s.JobSpec{
 			Name:        batchJob.Spec.APIName + "-" + batchJob.Name + "-enqueuer",
@@ -284,6 +291,7 @@ func (r *BatchJobReconciler) desiredEnqueuerJob(batchJob batch.BatchJob, queueUR
 				Annotations: map[string]string{
 					"traffic.sidecar.istio.io/excludeOutboundIPRanges": "0.0.0.0/0",
 					"cluster-autoscaler.kubernetes.io/safe-to-evict":   "false",
+					"karpenter.sh/do-not-evict":                        "true",
 				},
 				K8sPodSpec: kcore.PodSpec{
 					RestartPolicy: kcore.RestartPolicyNever,
@@ -299,12 +307,13 @@ func (r *BatchJobReconciler) desiredEnqueuerJob(batchJob batch.BatchJob, queueUR
 								"-apiName", batchJob.Spec.APIName,
 								"-jobID", batchJob.Name,
 							},
+							Env:             workloads.LogEnvVars(apiSpec),
 							ImagePullPolicy: kcore.PullAlways,
 						},
 					},
 					NodeSelector:       workloads.NodeSelectors(),
 					Tolerations:        workloads.GenerateResourceTolerations(),
-					Affinity:           workloads.GenerateNodeAffinities(batchJob.Spec.NodeGroups),
+					Affinity:           workloads.GenerateNodeAffinities(batchJob.Spec.NodeGroups, batchJob.Spec.Arch, awslib.OSLinux),
 					ServiceAccountName: workloads.ServiceAccountName,
 				},
 			},
@@ -326,7 +335,7 @@ func (r *BatchJobReconciler) desiredWorkerJob(batchJob batch.BatchJob, apiSpec s
 			Name:        batchJob.Spec.APIName + "-" + batchJob.Name,
 			Namespace:   batchJob.Namespace,
 			Parallelism: batchJob.Spec.Workers,
-			Labels: map[string]string{
+			Labels: workloads.WithUserLabels(apiSpec.API, map[string]string{
 				"apiKind":          userconfig.BatchAPIKind.String(),
 				"apiName":          batchJob.Spec.APIName,
 				"apiID":            batchJob.Spec.APIID,
@@ -335,9 +344,9 @@ func (r *BatchJobReconciler) desiredWorkerJob(batchJob batch.BatchJob, apiSpec s
 				"jobID":            batchJob.Name,
 				"cortex.dev/api":   "true",
 				"cortex.dev/batch": "worker",
-			},
+			}),
 			PodSpec: k8s.PodSpec{
-				Labels: map[string]string{
+				Labels: workloads.WithUserLabels(apiSpec.API, map[string]string{
 					"apiKind":          userconfig.BatchAPIKind.String(),
 					"apiName":          batchJob.Spec.APIName,
 					"apiID":            batchJob.Spec.APIID,
@@ -346,29 +355,34 @@ func (r *BatchJobReconciler) desiredWorkerJob(batchJob batch.BatchJob, apiSpec s
 					"jobID":            batchJob.Name,
 					"cortex.dev/api":   "true",
 					"cortex.dev/batch": "worker",
-				},
+				}),
 				Annotations: map[string]string{
 					"traffic.sidecar.istio.io/excludeOutboundIPRanges": "0.0.0.0/0",
 					"cluster-autoscaler.kubernetes.io/safe-to-evict":   "false",
+					"karpenter.sh/do-not-evict":                        "true",
 				},
 				K8sPodSpec: kcore.PodSpec{
-					InitContainers: []kcore.Container{
-						workloads.KubexitInitContainer(),
-					},
+					InitContainers: append(
+						[]kcore.Container{workloads.KubexitInitContainer()},
+						workloads.UserPodInitContainers(apiSpec)...,
+					),
 					Containers:         containers,
 					Volumes:            volumes,
 					RestartPolicy:      kcore.RestartPolicyNever,
 					NodeSelector:       workloads.NodeSelectors(),
-					Affinity:           workloads.GenerateNodeAffinities(batchJob.Spec.NodeGroups),
+					Affinity:           workloads.GenerateNodeAffinities(batchJob.Spec.NodeGroups, batchJob.Spec.Arch, awslib.OSLinux),
 					Tolerations:        workloads.GenerateResourceTolerations(),
-					ServiceAccountName: workloads.ServiceAccountName,
+					ServiceAccountName: workloads.APIServiceAccountName(apiSpec.API),
+					ImagePullSecrets:   workloads.ImagePullSecrets(apiSpec.API),
 				},
 			},
 		},
 	)
 
 	if batchJob.Spec.Timeout != nil {
-		job.Spec.ActiveDeadlineSeconds = pointer.Int64(int64(batchJob.Spec.Timeout.Seconds()))
+		// the timeout is measured from the job's submission, so the time spent enqueuing is deducted
+		remaining := batchJob.Spec.Timeout.Duration - time.Since(batchJob.CreationTimestamp.Time)
+		job.Spec.ActiveDeadlineSeconds = pointer.Int64(libmath.MaxInt64(int64(remaining.Seconds()), 1))
 	}
 
 	if err := ctrl.SetControllerReference(&batchJob, job, r.Scheme); err != nil {
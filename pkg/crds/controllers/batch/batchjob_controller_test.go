@@ -327,4 +327,84 @@ var _ = Describe("BatchJob controller", func() {
 			}, ttl.Duration.Seconds()*2, interval).Should(BeTrue())
 		})
 	})
+
+	Context("Reconciliation timeout", func() {
+		BeforeEach(func() {
+			// ensures the tests can be ran in rapid succession by avoiding the time limits of SQS queue creation
+			randomJobID = strings.ToLower(random.String(5))
+			randomAPIID = random.Digits(5)
+
+			Expect(uploadTestAPISpec(APIName, randomAPIID)).To(Succeed())
+		})
+
+		AfterEach(func(done Done) {
+			Expect(deleteTestAPISpec(APIName, randomAPIID)).To(Succeed())
+
+			Expect(k8sClient.Delete(
+				context.Background(),
+				&batch.BatchJob{
+					ObjectMeta: kmeta.ObjectMeta{Name: randomJobID, Namespace: BatchJobNamespace},
+				},
+			)).To(Succeed())
+			close(done)
+		})
+
+		It("Should deduct the time spent enqueuing from the worker job's deadline", func() {
+			By("Creating a new BatchJob")
+			jobTimeout := kmeta.Duration{Duration: time.Hour}
+			ctx := context.Background()
+			batchJob := &batch.BatchJob{
+				ObjectMeta: kmeta.ObjectMeta{
+					Name:      randomJobID,
+					Namespace: BatchJobNamespace,
+				},
+				Spec: batch.BatchJobSpec{
+					APIName: APIName,
+					APIID:   randomAPIID,
+					Workers: 1,
+					Timeout: &jobTimeout,
+				},
+			}
+			Expect(k8sClient.Create(ctx, batchJob)).To(Succeed())
+
+			By("Reaching a completed enqueuer status")
+			enqueuerJobLookupKey := ktypes.NamespacedName{
+				Name:      batchJob.Spec.APIName + "-" + batchJob.Name + "-enqueuer",
+				Namespace: batchJob.Namespace,
+			}
+			createdEnqueuerJob := &kbatch.Job{}
+
+			// wait for the enqueuer job to be created
+			Eventually(func() error {
+				return k8sClient.Get(ctx, enqueuerJobLookupKey, createdEnqueuerJob)
+			}, timeout, interval).Should(Succeed())
+
+			// the enqueuer doesn't have a deadline of its own
+			Expect(createdEnqueuerJob.Spec.ActiveDeadlineSeconds).Should(BeNil())
+
+			// simulate the time spent enqueuing
+			enqueuingDuration := 3 * time.Second
+			time.Sleep(enqueuingDuration)
+
+			// Mock the enqueuer status to match the success condition
+			createdEnqueuerJob.Status.Succeeded = 1
+			Expect(k8sClient.Status().Update(ctx, createdEnqueuerJob)).To(Succeed())
+
+			By("Creating the worker job with the remaining time as its deadline")
+			workerJobLookupKey := ktypes.NamespacedName{
+				Name:      batchJob.Spec.APIName + "-" + batchJob.Name,
+				Namespace: BatchJobNamespace,
+			}
+			createdWorkerJob := &kbatch.Job{}
+
+			// Wait for worker job to be created
+			Eventually(func() error {
+				return k8sClient.Get(ctx, workerJobLookupKey, createdWorkerJob)
+			}, timeout, interval).Should(Succeed())
+
+			Expect(createdWorkerJob.Spec.ActiveDeadlineSeconds).ShouldNot(BeNil())
+			Expect(*createdWorkerJob.Spec.ActiveDeadlineSeconds).Should(BeNumerically("<=", int64((jobTimeout.Duration - enqueuingDuration).Seconds())))
+			Expect(*createdWorkerJob.Spec.ActiveDeadlineSeconds).Should(BeNumerically(">", int64((jobTimeout.Duration - enqueuingDuration - timeout).Seconds())))
+		})
+	})
 })
@@ -17,6 +17,7 @@ limitations under the License.
 package dequeuer
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -29,6 +30,7 @@ import (
 	"github.com/aws/aws-sdk-go/service/sqs"
 	awslib "github.com/cortexlabs/cortex/pkg/lib/aws"
 	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/lib/logging"
 	"github.com/cortexlabs/cortex/pkg/lib/telemetry"
 	"github.com/cortexlabs/cortex/pkg/types/async"
 	"go.uber.org/zap"
@@ -37,6 +39,9 @@ import (
 const (
 	// CortexRequestIDHeader is the header containing the workload request id for the user container
 	CortexRequestIDHeader = "X-Cortex-Request-ID"
+
+	// how often to check whether the workload which is being processed has been cancelled
+	_cancellationCheckPeriod = 5 * time.Second
 )
 
 type AsyncMessageHandler struct {
@@ -58,6 +63,7 @@ type AsyncMessageHandlerConfig struct {
 type userPayload struct {
 	Body        io.ReadCloser
 	ContentType string
+	Metadata    map[string]string
 }
 
 func NewAsyncMessageHandler(config AsyncMessageHandlerConfig, awsClient *awslib.Client, eventHandler RequestEventHandler, logger *zap.SugaredLogger) *AsyncMessageHandler {
@@ -81,6 +87,12 @@ func (h *AsyncMessageHandler) Handle(message *sqs.Message) error {
 	}
 
 	requestID := *message.Body
+
+	// workloads which have expired while they were in the queue are not processed
+	if expiresAt := async.ExpiresAt(message); expiresAt != nil && time.Now().After(*expiresAt) {
+		return h.handleExpiredMessage(requestID, *expiresAt)
+	}
+
 	err := h.handleMessage(requestID)
 	if err != nil {
 		return err
@@ -89,9 +101,19 @@ func (h *AsyncMessageHandler) Handle(message *sqs.Message) error {
 }
 
 func (h *AsyncMessageHandler) handleMessage(requestID string) error {
-	h.log.Infow("processing workload", "id", requestID)
+	h.log.Infow("processing workload", logging.RequestIDField, requestID)
+
+	cancelled, err := h.isCancelled(requestID)
+	if err != nil {
+		return errors.Wrap(err, "failed to check whether the workload was cancelled")
+	}
+	if cancelled {
+		h.log.Infow("skipping cancelled workload", logging.RequestIDField, requestID)
+		h.deletePayload(requestID)
+		return nil
+	}
 
-	err := h.updateStatus(requestID, async.StatusInProgress)
+	err = h.updateStatus(requestID, async.StatusInProgress)
 	if err != nil {
 		return errors.Wrap(err, fmt.Sprintf("failed to update status to %s", async.StatusInProgress))
 	}
@@ -100,15 +122,26 @@ func (h *AsyncMessageHandler) handleMessage(requestID string) error {
 	if err != nil {
 		updateStatusErr := h.updateStatus(requestID, async.StatusFailed)
 		if updateStatusErr != nil {
-			h.log.Errorw("failed to update status after failure to get payload", "id", requestID, "error", updateStatusErr)
+			h.log.Errorw("failed to update status after failure to get payload", logging.RequestIDField, requestID, "error", updateStatusErr)
 		}
 		return errors.Wrap(err, "failed to get payload")
 	}
 	defer h.deletePayload(requestID)
 
-	result, err := h.submitRequest(payload, requestID)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	cancelledCh := h.watchCancellation(ctx, cancel, requestID)
+
+	result, err := h.submitRequest(ctx, payload, requestID)
 	if err != nil {
-		h.log.Errorw("failed to submit request to user container", "id", requestID, "error", err)
+		select {
+		case <-cancelledCh:
+			h.log.Infow("aborted cancelled workload", logging.RequestIDField, requestID)
+			return nil
+		default:
+		}
+
+		h.log.Errorw("failed to submit request to user container", logging.RequestIDField, requestID, "error", err)
 		updateStatusErr := h.updateStatus(requestID, async.StatusFailed)
 		if updateStatusErr != nil {
 			return errors.Wrap(updateStatusErr, fmt.Sprintf("failed to update status to %s", async.StatusFailed))
@@ -116,10 +149,19 @@ func (h *AsyncMessageHandler) handleMessage(requestID string) error {
 		return nil
 	}
 
+	// the workload may have been cancelled since the watcher last checked (e.g. while the user container was responding),
+	// in which case its result is discarded so that it isn't reported as both cancelled and completed
+	if cancelled, err := h.isCancelled(requestID); err != nil {
+		h.log.Errorw("failed to check whether the workload was cancelled", logging.RequestIDField, requestID, "error", err)
+	} else if cancelled {
+		h.log.Infow("discarded the result of cancelled workload", logging.RequestIDField, requestID)
+		return nil
+	}
+
 	if err = h.uploadResult(requestID, result); err != nil {
 		updateStatusErr := h.updateStatus(requestID, async.StatusFailed)
 		if updateStatusErr != nil {
-			h.log.Errorw("failed to update status after failure to upload result", "id", requestID, "error", updateStatusErr)
+			h.log.Errorw("failed to update status after failure to upload result", logging.RequestIDField, requestID, "error", updateStatusErr)
 		}
 		return errors.Wrap(err, "failed to upload result to storage")
 	}
@@ -128,7 +170,54 @@ func (h *AsyncMessageHandler) handleMessage(requestID string) error {
 		return errors.Wrap(err, fmt.Sprintf("failed to update status to %s", async.StatusCompleted))
 	}
 
-	h.log.Infow("workload processing complete", "id", requestID)
+	h.log.Infow("workload processing complete", logging.RequestIDField, requestID)
+
+	return nil
+}
+
+// watchCancellation periodically checks whether the workload has been cancelled until ctx is done;
+// if it has, the returned channel is closed and cancel is called to abort the request to the user container
+func (h *AsyncMessageHandler) watchCancellation(ctx context.Context, cancel context.CancelFunc, requestID string) <-chan struct{} {
+	cancelledCh := make(chan struct{})
+
+	go func() {
+		ticker := time.NewTicker(_cancellationCheckPeriod)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				cancelled, err := h.isCancelled(requestID)
+				if err != nil {
+					h.log.Errorw("failed to check whether the workload was cancelled", logging.RequestIDField, requestID, "error", err)
+					continue
+				}
+				if cancelled {
+					close(cancelledCh)
+					cancel()
+					return
+				}
+			}
+		}
+	}()
+
+	return cancelledCh
+}
+
+func (h *AsyncMessageHandler) isCancelled(requestID string) (bool, error) {
+	return h.aws.IsS3File(h.config.Bucket, async.StatusPath(h.storagePath, requestID, async.StatusCancelled))
+}
+
+func (h *AsyncMessageHandler) handleExpiredMessage(requestID string, expiresAt time.Time) error {
+	h.log.Infow("skipping expired workload", logging.RequestIDField, requestID, "expiredAt", expiresAt)
+
+	if err := h.updateStatus(requestID, async.StatusExpired); err != nil {
+		return errors.Wrap(err, fmt.Sprintf("failed to update status to %s", async.StatusExpired))
+	}
+
+	h.deletePayload(requestID)
 
 	return nil
 }
@@ -155,12 +244,32 @@ func (h *AsyncMessageHandler) getPayload(requestID string) (*userPayload, error)
 		contentType = *output.ContentType
 	}
 
+	metadata, err := h.getMetadata(requestID)
+	if err != nil {
+		_ = output.Body.Close()
+		return nil, err
+	}
+
 	return &userPayload{
 		Body:        output.Body,
 		ContentType: contentType,
+		Metadata:    metadata,
 	}, nil
 }
 
+// getMetadata returns the metadata which was attached to the workload when it was submitted, or nil if there is none
+func (h *AsyncMessageHandler) getMetadata(requestID string) (map[string]string, error) {
+	var metadata map[string]string
+	err := h.aws.ReadJSONFromS3(&metadata, h.config.Bucket, async.MetadataPath(h.storagePath, requestID))
+	if err != nil {
+		if awslib.IsNoSuchKeyErr(err) {
+			return nil, nil
+		}
+		return nil, errors.Wrap(err, "failed to get metadata")
+	}
+	return metadata, nil
+}
+
 func (h *AsyncMessageHandler) deletePayload(requestID string) {
 	key := async.PayloadPath(h.storagePath, requestID)
 	err := h.aws.DeleteS3File(h.config.Bucket, key)
@@ -170,14 +279,15 @@ func (h *AsyncMessageHandler) deletePayload(requestID string) {
 	}
 }
 
-func (h *AsyncMessageHandler) submitRequest(payload *userPayload, requestID string) (interface{}, error) {
-	req, err := http.NewRequest(http.MethodPost, h.config.TargetURL, payload.Body)
+func (h *AsyncMessageHandler) submitRequest(ctx context.Context, payload *userPayload, requestID string) (interface{}, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, h.config.TargetURL, payload.Body)
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
 
 	req.Header.Set("Content-Type", payload.ContentType)
 	req.Header.Set(CortexRequestIDHeader, requestID)
+	async.SetMetadataHeaders(req.Header, payload.Metadata)
 
 	startTime := time.Now()
 	response, err := h.httpClient.Do(req)
@@ -195,7 +305,7 @@ func (h *AsyncMessageHandler) submitRequest(payload *userPayload, requestID stri
 	}()
 
 	if response.StatusCode != http.StatusOK {
-		return nil, ErrorUserContainerResponseStatusCode(response.StatusCode)
+		return nil, ErrorUserContainerResponseStatusCode(response.StatusCode, readResponseBodyExcerpt(response))
 	}
 
 	if !strings.HasPrefix(response.Header.Get("Content-Type"), "application/json") {
@@ -21,6 +21,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/s3"
@@ -83,6 +84,188 @@ func TestAsyncMessageHandler_Handle(t *testing.T) {
 	require.Equal(t, 1, requestEventsCount)
 }
 
+func TestAsyncMessageHandler_HandleWithMetadata(t *testing.T) {
+	t.Parallel()
+
+	log := newLogger(t)
+	awsClient := testAWSClient(t)
+
+	requestID := random.String(8)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, requestID, r.Header.Get(CortexRequestIDHeader))
+		require.Equal(t, "acme", r.Header.Get(async.MetadataHeaderPrefix+"customer"))
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte("{}"))
+	}))
+
+	eventHandler := NewRequestEventHandlerFunc(func(event RequestEvent) {})
+
+	asyncHandler := NewAsyncMessageHandler(AsyncMessageHandlerConfig{
+		ClusterUID: "cortex-test",
+		Bucket:     _testBucket,
+		APIName:    "async-test",
+		TargetURL:  server.URL,
+	}, awsClient, eventHandler, log)
+
+	_, err := awsClient.S3().CreateBucket(&s3.CreateBucketInput{
+		Bucket: aws.String(_testBucket),
+	})
+	require.NoError(t, err)
+
+	err = awsClient.UploadStringToS3("{}", asyncHandler.config.Bucket, async.PayloadPath(asyncHandler.storagePath, requestID))
+	require.NoError(t, err)
+
+	err = awsClient.UploadJSONToS3(map[string]string{"customer": "acme"}, asyncHandler.config.Bucket, async.MetadataPath(asyncHandler.storagePath, requestID))
+	require.NoError(t, err)
+
+	err = asyncHandler.Handle(&sqs.Message{
+		Body:      aws.String(requestID),
+		MessageId: aws.String(requestID),
+	})
+	require.NoError(t, err)
+
+	_, err = awsClient.ReadStringFromS3(
+		_testBucket,
+		async.StatusPath(asyncHandler.storagePath, requestID, async.StatusCompleted),
+	)
+	require.NoError(t, err)
+}
+
+func TestAsyncMessageHandler_HandleExpired(t *testing.T) {
+	t.Parallel()
+
+	log := newLogger(t)
+	awsClient := testAWSClient(t)
+
+	requestID := random.String(8)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Fatal("expired workloads should not be sent to the user container")
+	}))
+
+	eventHandler := NewRequestEventHandlerFunc(func(event RequestEvent) {})
+
+	asyncHandler := NewAsyncMessageHandler(AsyncMessageHandlerConfig{
+		ClusterUID: "cortex-test",
+		Bucket:     _testBucket,
+		APIName:    "async-test",
+		TargetURL:  server.URL,
+	}, awsClient, eventHandler, log)
+
+	_, err := awsClient.S3().CreateBucket(&s3.CreateBucketInput{
+		Bucket: aws.String(_testBucket),
+	})
+	require.NoError(t, err)
+
+	err = awsClient.UploadStringToS3("{}", asyncHandler.config.Bucket, async.PayloadPath(asyncHandler.storagePath, requestID))
+	require.NoError(t, err)
+
+	err = asyncHandler.Handle(&sqs.Message{
+		Body:              aws.String(requestID),
+		MessageId:         aws.String(requestID),
+		MessageAttributes: async.ExpiresAtAttributes(time.Now().Add(-time.Minute)),
+	})
+	require.NoError(t, err)
+
+	_, err = awsClient.ReadStringFromS3(
+		_testBucket,
+		async.StatusPath(asyncHandler.storagePath, requestID, async.StatusExpired),
+	)
+	require.NoError(t, err)
+}
+
+func TestAsyncMessageHandler_HandleCancelled(t *testing.T) {
+	t.Parallel()
+
+	log := newLogger(t)
+	awsClient := testAWSClient(t)
+
+	requestID := random.String(8)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Fatal("cancelled workloads should not be sent to the user container")
+	}))
+
+	eventHandler := NewRequestEventHandlerFunc(func(event RequestEvent) {})
+
+	asyncHandler := NewAsyncMessageHandler(AsyncMessageHandlerConfig{
+		ClusterUID: "cortex-test",
+		Bucket:     _testBucket,
+		APIName:    "async-test",
+		TargetURL:  server.URL,
+	}, awsClient, eventHandler, log)
+
+	_, err := awsClient.S3().CreateBucket(&s3.CreateBucketInput{
+		Bucket: aws.String(_testBucket),
+	})
+	require.NoError(t, err)
+
+	err = awsClient.UploadStringToS3("{}", asyncHandler.config.Bucket, async.PayloadPath(asyncHandler.storagePath, requestID))
+	require.NoError(t, err)
+
+	err = awsClient.UploadStringToS3("", asyncHandler.config.Bucket, async.StatusPath(asyncHandler.storagePath, requestID, async.StatusCancelled))
+	require.NoError(t, err)
+
+	err = asyncHandler.Handle(&sqs.Message{
+		Body:      aws.String(requestID),
+		MessageId: aws.String(requestID),
+	})
+	require.NoError(t, err)
+
+	inProgress, err := awsClient.IsS3File(_testBucket, async.StatusPath(asyncHandler.storagePath, requestID, async.StatusInProgress))
+	require.NoError(t, err)
+	require.False(t, inProgress)
+}
+
+func TestAsyncMessageHandler_HandleCancelledWhileProcessing(t *testing.T) {
+	t.Parallel()
+
+	log := newLogger(t)
+	awsClient := testAWSClient(t)
+
+	requestID := random.String(8)
+	var asyncHandler *AsyncMessageHandler
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// the workload is cancelled after the user container has processed it, before the cancellation watcher checks again
+		err := awsClient.UploadStringToS3("", asyncHandler.config.Bucket, async.StatusPath(asyncHandler.storagePath, requestID, async.StatusCancelled))
+		require.NoError(t, err)
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte("{}"))
+	}))
+
+	eventHandler := NewRequestEventHandlerFunc(func(event RequestEvent) {})
+
+	asyncHandler = NewAsyncMessageHandler(AsyncMessageHandlerConfig{
+		ClusterUID: "cortex-test",
+		Bucket:     _testBucket,
+		APIName:    "async-test",
+		TargetURL:  server.URL,
+	}, awsClient, eventHandler, log)
+
+	_, err := awsClient.S3().CreateBucket(&s3.CreateBucketInput{
+		Bucket: aws.String(_testBucket),
+	})
+	require.NoError(t, err)
+
+	err = awsClient.UploadStringToS3("{}", asyncHandler.config.Bucket, async.PayloadPath(asyncHandler.storagePath, requestID))
+	require.NoError(t, err)
+
+	err = asyncHandler.Handle(&sqs.Message{
+		Body:      aws.String(requestID),
+		MessageId: aws.String(requestID),
+	})
+	require.NoError(t, err)
+
+	completed, err := awsClient.IsS3File(_testBucket, async.StatusPath(asyncHandler.storagePath, requestID, async.StatusCompleted))
+	require.NoError(t, err)
+	require.False(t, completed)
+
+	resultExists, err := awsClient.IsS3File(_testBucket, async.ResultPath(asyncHandler.storagePath, requestID))
+	require.NoError(t, err)
+	require.False(t, resultExists)
+}
+
 func TestAsyncMessageHandler_Handle_Errors(t *testing.T) {
 	t.Parallel()
 
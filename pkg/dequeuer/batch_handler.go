@@ -26,6 +26,7 @@ import (
 	"github.com/aws/aws-sdk-go/service/sqs"
 	awslib "github.com/cortexlabs/cortex/pkg/lib/aws"
 	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/lib/logging"
 	"github.com/cortexlabs/cortex/pkg/lib/urls"
 	"github.com/xtgo/uuid"
 	"go.uber.org/zap"
@@ -33,7 +34,9 @@ import (
 
 const (
 	// CortexJobIDHeader is the header containing the job id for the user container
-	CortexJobIDHeader        = "X-Cortex-Job-ID"
+	CortexJobIDHeader = "X-Cortex-Job-ID"
+	// CortexBatchIDHeader is the header containing the batch id (which stays the same when a batch is retried) for the user container
+	CortexBatchIDHeader      = "X-Cortex-Batch-ID"
 	_jobCompleteMessageDelay = 10 * time.Second
 )
 
@@ -45,6 +48,7 @@ type BatchMessageHandler struct {
 	metrics                 statsd.ClientInterface
 	log                     *zap.SugaredLogger
 	httpClient              *http.Client
+	results                 *BatchResultsRecorder
 }
 
 type BatchMessageHandlerConfig struct {
@@ -53,6 +57,11 @@ type BatchMessageHandlerConfig struct {
 	QueueURL  string
 	Region    string
 	TargetURL string
+
+	// where the results of the processed batches are stored (optional)
+	Bucket        string
+	ResultsPrefix string
+	Worker        string
 }
 
 func NewBatchMessageHandler(config BatchMessageHandlerConfig, awsClient *awslib.Client, statsdClient statsd.ClientInterface, log *zap.SugaredLogger) *BatchMessageHandler {
@@ -61,6 +70,11 @@ func NewBatchMessageHandler(config BatchMessageHandlerConfig, awsClient *awslib.
 		"job_id:" + config.JobID,
 	}
 
+	var results *BatchResultsRecorder
+	if config.ResultsPrefix != "" {
+		results = NewBatchResultsRecorder(awsClient, config.Bucket, config.ResultsPrefix, config.Worker)
+	}
+
 	return &BatchMessageHandler{
 		config:                  config,
 		jobCompleteMessageDelay: _jobCompleteMessageDelay,
@@ -69,7 +83,16 @@ func NewBatchMessageHandler(config BatchMessageHandlerConfig, awsClient *awslib.
 		metrics:                 statsdClient,
 		log:                     log,
 		httpClient:              &http.Client{},
+		results:                 results,
+	}
+}
+
+// FlushResults uploads the results of the batches which were processed by this worker
+func (h *BatchMessageHandler) FlushResults() error {
+	if h.results == nil {
+		return nil
 	}
+	return h.results.Flush()
 }
 
 func (h *BatchMessageHandler) Handle(message *sqs.Message) error {
@@ -111,7 +134,7 @@ func (h *BatchMessageHandler) recordTimePerBatch(elapsedTime time.Duration) erro
 	return nil
 }
 
-func (h *BatchMessageHandler) submitRequest(messageBody string, isOnJobComplete bool) error {
+func (h *BatchMessageHandler) submitRequest(messageBody string, batchID string, isOnJobComplete bool) error {
 	targetURL := h.config.TargetURL
 	if isOnJobComplete {
 		targetURL = urls.Join(targetURL, "/on-job-complete")
@@ -124,6 +147,9 @@ func (h *BatchMessageHandler) submitRequest(messageBody string, isOnJobComplete
 
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set(CortexJobIDHeader, h.config.JobID)
+	if batchID != "" {
+		req.Header.Set(CortexBatchIDHeader, batchID)
+	}
 	response, err := h.httpClient.Do(req)
 	if err != nil {
 		return ErrorUserContainerNotReachable(err)
@@ -137,24 +163,29 @@ func (h *BatchMessageHandler) submitRequest(messageBody string, isOnJobComplete
 	}
 
 	if response.StatusCode != http.StatusOK {
-		return ErrorUserContainerResponseStatusCode(response.StatusCode)
+		return ErrorUserContainerResponseStatusCode(response.StatusCode, readResponseBodyExcerpt(response))
 	}
 
 	return nil
 }
 
 func (h *BatchMessageHandler) handleBatch(message *sqs.Message) error {
-	h.log.Infow("processing batch", "id", *message.MessageId)
+	h.log.Infow("processing batch", logging.BatchIDField, *message.MessageId)
 
 	startTime := time.Now()
 
-	err := h.submitRequest(*message.Body, false)
+	err := h.submitRequest(*message.Body, *message.MessageId, false)
 	if err != nil {
-		h.log.Errorw("failed to process batch", "id", *message.MessageId, "error", err)
+		h.log.Errorw("failed to process batch", logging.BatchIDField, *message.MessageId, "error", err)
 		recordFailureErr := h.recordFailure()
 		if recordFailureErr != nil {
 			return errors.Wrap(recordFailureErr, "failed to record failure metric")
 		}
+		if h.results != nil {
+			if recordFailureErr := h.results.RecordFailure(*message.MessageId, *message.Body, err); recordFailureErr != nil {
+				return errors.Wrap(recordFailureErr, "failed to record failed batch")
+			}
+		}
 		return nil
 	}
 
@@ -169,6 +200,12 @@ func (h *BatchMessageHandler) handleBatch(message *sqs.Message) error {
 	if err != nil {
 		return errors.Wrap(err, "failed to record time per batch")
 	}
+
+	if h.results != nil {
+		if err := h.results.RecordSuccess(endTime); err != nil {
+			return errors.Wrap(err, "failed to record batch results")
+		}
+	}
 	return nil
 }
 
@@ -185,7 +222,7 @@ func (h *BatchMessageHandler) onJobComplete(message *sqs.Message) error {
 
 		if totalMessages > 1 {
 			time.Sleep(h.jobCompleteMessageDelay)
-			h.log.Infow("found other messages in queue, requeuing job_complete message", "id", *message.MessageId)
+			h.log.Infow("found other messages in queue, requeuing job_complete message", logging.BatchIDField, *message.MessageId)
 			newMessageID := uuid.NewRandom().String()
 			if _, err = h.aws.SQS().SendMessage(
 				&sqs.SendMessageInput{
@@ -216,8 +253,8 @@ func (h *BatchMessageHandler) onJobComplete(message *sqs.Message) error {
 		}
 
 		if shouldRunOnJobComplete {
-			h.log.Infow("processing job_complete message", "id", *message.MessageId)
-			return h.submitRequest(*message.Body, true)
+			h.log.Infow("processing job_complete message", logging.BatchIDField, *message.MessageId)
+			return h.submitRequest(*message.Body, "", true)
 		}
 		shouldRunOnJobComplete = true
 
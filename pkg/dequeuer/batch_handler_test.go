@@ -23,7 +23,11 @@ import (
 
 	"github.com/DataDog/datadog-go/statsd"
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
 	"github.com/aws/aws-sdk-go/service/sqs"
+	"github.com/cortexlabs/cortex/pkg/lib/random"
+	"github.com/cortexlabs/cortex/pkg/types/spec"
+	"github.com/cortexlabs/cortex/pkg/types/status"
 	"github.com/stretchr/testify/require"
 )
 
@@ -35,6 +39,7 @@ func TestBatchMessageHandler_Handle(t *testing.T) {
 	server := httptest.NewServer(
 		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			callCount++
+			require.Equal(t, "1", r.Header.Get(CortexBatchIDHeader))
 			w.WriteHeader(http.StatusOK)
 		}),
 	)
@@ -104,3 +109,53 @@ func TestBatchMessageHandler_Handle_OnJobComplete(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, callCount, 1)
 }
+
+func TestBatchMessageHandler_Handle_RecordsResults(t *testing.T) {
+	t.Parallel()
+	awsClient := testAWSClient(t)
+
+	_, _ = awsClient.S3().CreateBucket(&s3.CreateBucketInput{
+		Bucket: aws.String(_testBucket),
+	})
+
+	server := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get(CortexBatchIDHeader) == "2" {
+				w.WriteHeader(http.StatusInternalServerError)
+				_, _ = w.Write([]byte("division by zero"))
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+		}),
+	)
+
+	resultsPrefix := "cortex-test/results/" + random.String(8)
+	batchHandler := NewBatchMessageHandler(BatchMessageHandlerConfig{
+		APIName:       "test",
+		JobID:         "12345",
+		Region:        _localStackDefaultRegion,
+		TargetURL:     server.URL,
+		Bucket:        _testBucket,
+		ResultsPrefix: resultsPrefix,
+		Worker:        "worker-1",
+	}, awsClient, &statsd.NoOpClient{}, newLogger(t))
+
+	require.NoError(t, batchHandler.Handle(&sqs.Message{Body: aws.String(`[1, 2]`), MessageId: aws.String("1")}))
+	require.NoError(t, batchHandler.Handle(&sqs.Message{Body: aws.String(`[3, 4]`), MessageId: aws.String("2")}))
+	require.NoError(t, batchHandler.FlushResults())
+
+	var workerResults status.WorkerBatchResults
+	require.NoError(t, awsClient.ReadJSONFromS3(&workerResults, _testBucket, spec.JobWorkerResultsKey(resultsPrefix, "worker-1")))
+	require.Equal(t, "worker-1", workerResults.Worker)
+	require.Equal(t, 1, workerResults.Succeeded)
+	require.Equal(t, 1, workerResults.Failed)
+
+	var failedBatch status.FailedBatch
+	require.NoError(t, awsClient.ReadJSONFromS3(&failedBatch, _testBucket, spec.JobFailedBatchErrorKey(resultsPrefix, "2")))
+	require.Equal(t, "2", failedBatch.BatchID)
+	require.Contains(t, failedBatch.Error, "division by zero")
+
+	payload, err := awsClient.ReadStringFromS3(_testBucket, spec.JobFailedBatchPayloadKey(resultsPrefix, "2"))
+	require.NoError(t, err)
+	require.Equal(t, `[3, 4]`, payload)
+}
@@ -0,0 +1,110 @@
+/*
+Copyright 2021 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dequeuer
+
+import (
+	"sync"
+	"time"
+
+	awslib "github.com/cortexlabs/cortex/pkg/lib/aws"
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/types/spec"
+	"github.com/cortexlabs/cortex/pkg/types/status"
+)
+
+const _resultsFlushPeriod = 10 * time.Second
+
+// BatchResultsRecorder stores the results of the batches processed by a worker in s3, so that the operator
+// can aggregate them once the job has completed; the payloads of failed batches are stored alongside their errors
+type BatchResultsRecorder struct {
+	aws         *awslib.Client
+	bucket      string
+	prefix      string
+	flushPeriod time.Duration
+
+	mu        sync.Mutex
+	results   status.WorkerBatchResults
+	lastFlush time.Time
+}
+
+func NewBatchResultsRecorder(awsClient *awslib.Client, bucket string, prefix string, worker string) *BatchResultsRecorder {
+	return &BatchResultsRecorder{
+		aws:         awsClient,
+		bucket:      bucket,
+		prefix:      prefix,
+		flushPeriod: _resultsFlushPeriod,
+		results: status.WorkerBatchResults{
+			Worker:    worker,
+			StartTime: time.Now(),
+		},
+	}
+}
+
+func (r *BatchResultsRecorder) RecordSuccess(processingTime time.Duration) error {
+	r.mu.Lock()
+	r.results.Succeeded++
+	r.results.ProcessingTime += processingTime.Seconds()
+	r.mu.Unlock()
+
+	return r.flushIfDue()
+}
+
+func (r *BatchResultsRecorder) RecordFailure(batchID string, payload string, batchErr error) error {
+	failedBatch := status.FailedBatch{
+		BatchID:  batchID,
+		Worker:   r.results.Worker,
+		Error:    errors.Message(batchErr),
+		FailedAt: time.Now(),
+	}
+
+	// a retried batch which fails again overwrites its previous error
+	err := errors.FirstError(
+		r.aws.UploadStringToS3(payload, r.bucket, spec.JobFailedBatchPayloadKey(r.prefix, batchID)),
+		r.aws.UploadJSONToS3(&failedBatch, r.bucket, spec.JobFailedBatchErrorKey(r.prefix, batchID)),
+	)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	r.results.Failed++
+	r.mu.Unlock()
+
+	return r.flushIfDue()
+}
+
+func (r *BatchResultsRecorder) flushIfDue() error {
+	r.mu.Lock()
+	due := time.Since(r.lastFlush) >= r.flushPeriod
+	r.mu.Unlock()
+
+	if !due {
+		return nil
+	}
+	return r.Flush()
+}
+
+// Flush uploads the worker's results; it is called periodically while batches are processed, and once the queue is empty
+func (r *BatchResultsRecorder) Flush() error {
+	r.mu.Lock()
+	r.results.LastUpdated = time.Now()
+	r.lastFlush = r.results.LastUpdated
+	results := r.results
+	r.mu.Unlock()
+
+	return r.aws.UploadJSONToS3(&results, r.bucket, spec.JobWorkerResultsKey(r.prefix, results.Worker))
+}
@@ -0,0 +1,130 @@
+/*
+Copyright 2021 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dequeuer
+
+import (
+	"io/ioutil"
+	"net/http"
+	"path"
+	"regexp"
+	"strings"
+
+	awslib "github.com/cortexlabs/cortex/pkg/lib/aws"
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"go.uber.org/zap"
+)
+
+const (
+	// CheckpointsPath is the path (on the admin server) under which the checkpoint api is exposed to the user container
+	CheckpointsPath = "/checkpoints/"
+
+	_maxCheckpointBytes = 64 * 1024
+)
+
+var _batchIDRegex = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+
+// CheckpointStore is an s3-backed key-value store with one value per batch of a job, which allows
+// workers to resume partially processed batches (e.g. after a spot instance interruption)
+type CheckpointStore struct {
+	aws    *awslib.Client
+	bucket string
+	prefix string
+}
+
+func NewCheckpointStore(awsClient *awslib.Client, bucket string, prefix string) *CheckpointStore {
+	return &CheckpointStore{
+		aws:    awsClient,
+		bucket: bucket,
+		prefix: prefix,
+	}
+}
+
+func (c *CheckpointStore) key(batchID string) string {
+	return path.Join(c.prefix, batchID)
+}
+
+// Get returns nil if no checkpoint has been stored for the batch
+func (c *CheckpointStore) Get(batchID string) ([]byte, error) {
+	checkpoint, err := c.aws.ReadBytesFromS3(c.bucket, c.key(batchID))
+	if err != nil {
+		if awslib.IsNoSuchKeyErr(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return checkpoint, nil
+}
+
+func (c *CheckpointStore) Put(batchID string, checkpoint []byte) error {
+	return c.aws.UploadBytesToS3(checkpoint, c.bucket, c.key(batchID))
+}
+
+func (c *CheckpointStore) Delete(batchID string) error {
+	return c.aws.DeleteS3File(c.bucket, c.key(batchID))
+}
+
+// CheckpointHandler serves GET, PUT and DELETE requests on /checkpoints/<batch_id>, where the batch id
+// is the value of the X-Cortex-Batch-ID header which was sent to the user container along with the batch
+func CheckpointHandler(store *CheckpointStore, log *zap.SugaredLogger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		batchID := strings.TrimPrefix(r.URL.Path, CheckpointsPath)
+		if !_batchIDRegex.MatchString(batchID) {
+			http.Error(w, "invalid batch id", http.StatusBadRequest)
+			return
+		}
+
+		switch r.Method {
+		case http.MethodGet:
+			checkpoint, err := store.Get(batchID)
+			if err != nil {
+				log.Errorw("failed to read checkpoint", "batch_id", batchID, "error", err)
+				http.Error(w, errors.Message(err), http.StatusInternalServerError)
+				return
+			}
+			if checkpoint == nil {
+				http.Error(w, "checkpoint not found", http.StatusNotFound)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+			_, _ = w.Write(checkpoint)
+
+		case http.MethodPut:
+			checkpoint, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, _maxCheckpointBytes))
+			if err != nil {
+				http.Error(w, "checkpoint must not exceed 64KiB", http.StatusRequestEntityTooLarge)
+				return
+			}
+			if err := store.Put(batchID, checkpoint); err != nil {
+				log.Errorw("failed to write checkpoint", "batch_id", batchID, "error", err)
+				http.Error(w, errors.Message(err), http.StatusInternalServerError)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+
+		case http.MethodDelete:
+			if err := store.Delete(batchID); err != nil {
+				log.Errorw("failed to delete checkpoint", "batch_id", batchID, "error", err)
+				http.Error(w, errors.Message(err), http.StatusInternalServerError)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	}
+}
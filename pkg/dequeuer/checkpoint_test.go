@@ -0,0 +1,74 @@
+/*
+Copyright 2021 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dequeuer
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/cortexlabs/cortex/pkg/lib/random"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckpointHandler(t *testing.T) {
+	t.Parallel()
+
+	log := newLogger(t)
+	awsClient := testAWSClient(t)
+
+	_, _ = awsClient.S3().CreateBucket(&s3.CreateBucketInput{
+		Bucket: aws.String(_testBucket),
+	})
+
+	store := NewCheckpointStore(awsClient, _testBucket, "cortex-test/checkpoints/"+random.String(8))
+	server := httptest.NewServer(CheckpointHandler(store, log))
+	defer server.Close()
+
+	url := server.URL + CheckpointsPath + "batch-1"
+
+	resp, err := http.Get(url)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	req, err := http.NewRequest(http.MethodPut, url, bytes.NewBufferString(`{"offset": 42}`))
+	require.NoError(t, err)
+	resp, err = http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	resp, err = http.Get(url)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	body, err := ioutil.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.Equal(t, `{"offset": 42}`, string(body))
+
+	req, err = http.NewRequest(http.MethodPut, url, bytes.NewBuffer(make([]byte, _maxCheckpointBytes+1)))
+	require.NoError(t, err)
+	resp, err = http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusRequestEntityTooLarge, resp.StatusCode)
+
+	resp, err = http.Get(server.URL + CheckpointsPath + "..%2Fspec.json")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
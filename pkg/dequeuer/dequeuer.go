@@ -40,6 +40,7 @@ type SQSDequeuerConfig struct {
 	Region           string
 	QueueURL         string
 	StopIfNoMessages bool
+	Metrics          *Metrics // optional
 }
 
 type SQSDequeuer struct {
@@ -152,7 +153,10 @@ func (d *SQSDequeuer) Shutdown() {
 }
 
 func (d *SQSDequeuer) handleMessage(message *sqs.Message, messageHandler MessageHandler, done chan struct{}) error {
+	startTime := time.Now()
+	d.config.Metrics.messageStarted()
 	messageErr := messageHandler.Handle(message) // handle error later
+	d.config.Metrics.messageFinished(time.Since(startTime), messageErr)
 
 	done <- struct{}{}
 	isOnJobComplete := isOnJobCompleteMessage(message)
@@ -29,10 +29,15 @@ const (
 	ErrUserContainerNotReachable              = "dequeuer.user_container_not_reachable"
 )
 
-func ErrorUserContainerResponseStatusCode(statusCode int) error {
+func ErrorUserContainerResponseStatusCode(statusCode int, responseBody string) error {
+	message := fmt.Sprintf("invalid response from user container; got status code %d, expected status code 200", statusCode)
+	if responseBody != "" {
+		message += fmt.Sprintf(" (response body: %s)", responseBody)
+	}
+
 	return &errors.Error{
 		Kind:        ErrUserContainerResponseStatusCode,
-		Message:     fmt.Sprintf("invalid response from user container; got status code %d, expected status code 200", statusCode),
+		Message:     message,
 		NoTelemetry: true,
 	}
 }
@@ -16,7 +16,15 @@ limitations under the License.
 
 package dequeuer
 
-import "net/http"
+import (
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+)
+
+// the maximum number of bytes of an error response from the user container which are included in the error message
+const _maxResponseBodyExcerptBytes = 1024
 
 func HealthcheckHandler(isHealthy func() bool) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
@@ -30,3 +38,12 @@ func HealthcheckHandler(isHealthy func() bool) http.HandlerFunc {
 		_, _ = w.Write([]byte("healthy"))
 	}
 }
+
+// readResponseBodyExcerpt returns the beginning of the response's body (e.g. the traceback returned by the user container)
+func readResponseBodyExcerpt(response *http.Response) string {
+	body, err := ioutil.ReadAll(io.LimitReader(response.Body, _maxResponseBodyExcerptBytes))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(body))
+}
@@ -0,0 +1,96 @@
+/*
+Copyright 2021 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dequeuer
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// Metrics are exposed on the admin server's /metrics endpoint (and scraped by prometheus);
+// all of the methods can be called on a nil *Metrics, in which case nothing is recorded
+type Metrics struct {
+	messagesProcessed prometheus.Counter
+	messagesFailed    prometheus.Counter
+	messagesInFlight  prometheus.Gauge
+	processingLatency prometheus.Histogram
+	awsLatency        *prometheus.HistogramVec
+	awsErrors         *prometheus.CounterVec
+}
+
+func NewMetrics() *Metrics {
+	return &Metrics{
+		messagesProcessed: promauto.NewCounter(prometheus.CounterOpts{
+			Name: "cortex_dequeuer_messages_processed_total",
+			Help: "Number of messages which were processed by the dequeuer (including the failed ones)",
+		}),
+		messagesFailed: promauto.NewCounter(prometheus.CounterOpts{
+			Name: "cortex_dequeuer_messages_failed_total",
+			Help: "Number of messages which failed to be processed by the dequeuer",
+		}),
+		messagesInFlight: promauto.NewGauge(prometheus.GaugeOpts{
+			Name: "cortex_dequeuer_messages_in_flight",
+			Help: "Number of messages which are currently being processed by the dequeuer",
+		}),
+		processingLatency: promauto.NewHistogram(prometheus.HistogramOpts{
+			Name:    "cortex_dequeuer_processing_latency_seconds",
+			Help:    "Time to process a message (from when it was received until it was handled), in seconds",
+			Buckets: []float64{0.01, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60, 120, 300, 600, 1800, 3600},
+		}),
+		awsLatency: promauto.NewHistogramVec(prometheus.HistogramOpts{
+			Name: "cortex_dequeuer_aws_request_latency_seconds",
+			Help: "Latency of the dequeuer's requests to AWS services (e.g. S3 and SQS), in seconds",
+		}, []string{"service", "operation"}),
+		awsErrors: promauto.NewCounterVec(prometheus.CounterOpts{
+			Name: "cortex_dequeuer_aws_request_errors_total",
+			Help: "Number of the dequeuer's requests to AWS services (e.g. S3 and SQS) which failed",
+		}, []string{"service", "operation"}),
+	}
+}
+
+func (m *Metrics) messageStarted() {
+	if m == nil {
+		return
+	}
+	m.messagesInFlight.Inc()
+}
+
+func (m *Metrics) messageFinished(duration time.Duration, err error) {
+	if m == nil {
+		return
+	}
+	m.messagesInFlight.Dec()
+	m.messagesProcessed.Inc()
+	m.processingLatency.Observe(duration.Seconds())
+	if err != nil {
+		m.messagesFailed.Inc()
+	}
+}
+
+// ObserveAWSRequest can be registered with awslib.Client.OnRequestComplete()
+func (m *Metrics) ObserveAWSRequest(service string, operation string, duration time.Duration, err error) {
+	if m == nil {
+		return
+	}
+	labels := prometheus.Labels{"service": service, "operation": operation}
+	m.awsLatency.With(labels).Observe(duration.Seconds())
+	if err != nil {
+		m.awsErrors.With(labels).Inc()
+	}
+}
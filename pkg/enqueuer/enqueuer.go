@@ -152,10 +152,6 @@ func (e *Enqueuer) Enqueue() (int, error) {
 		return 0, errors.Wrap(err, "failed to enqueue job_complete placeholder")
 	}
 
-	if err = e.deleteJobPayload(); err != nil {
-		return 0, err
-	}
-
 	return totalBatches, nil
 }
 
@@ -181,14 +177,6 @@ func (e *Enqueuer) getJobPayload() (JobSubmission, error) {
 	return submission, nil
 }
 
-func (e *Enqueuer) deleteJobPayload() error {
-	key := spec.JobPayloadKey(e.envConfig.ClusterUID, userconfig.BatchAPIKind, e.envConfig.APIName, e.envConfig.JobID)
-	if err := e.aws.DeleteS3File(e.envConfig.Bucket, key); err != nil {
-		return err
-	}
-	return nil
-}
-
 func (e *Enqueuer) enqueueItems(itemList *ItemList) (int, error) {
 	log := e.logger
 
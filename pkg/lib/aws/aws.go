@@ -20,12 +20,27 @@ limitations under the License.
 package aws
 
 import (
+	"os"
+	"time"
+
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/credentials/stscreds"
+	"github.com/aws/aws-sdk-go/aws/request"
 	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/sts"
 	"github.com/cortexlabs/cortex/pkg/lib/errors"
 )
 
+// The S3 and SQS endpoints can be overridden to use compatible services (e.g. MinIO and ElasticMQ in local development clusters)
+const (
+	S3EndpointEnvVar  = "CORTEX_S3_ENDPOINT"
+	SQSEndpointEnvVar = "CORTEX_SQS_ENDPOINT"
+
+	// account ID reported when using local endpoints, since there is no STS endpoint to query
+	LocalAccountID = "000000000000"
+)
+
 type Client struct {
 	Region          string
 	sess            *session.Session
@@ -46,6 +61,14 @@ func NewForSession(sess *session.Session) (*Client, error) {
 	}, nil
 }
 
+// OnRequestComplete registers a function which is called after each request (including its retries) to an AWS service, e.g. to record the requests' latencies;
+// it only applies to the service clients which haven't been created yet, so it should be called right after creating the client
+func (c *Client) OnRequestComplete(fn func(service string, operation string, duration time.Duration, err error)) {
+	c.sess.Handlers.Complete.PushBack(func(r *request.Request) {
+		fn(r.ClientInfo.ServiceName, r.Operation.Name, time.Since(r.Time), r.Error)
+	})
+}
+
 func NewFromClientS3Path(s3Path string, awsClient *Client) (*Client, error) {
 	if !awsClient.IsAnonymous {
 		return NewFromS3Path(s3Path)
@@ -75,29 +98,33 @@ func NewFromS3Bucket(bucket string) (*Client, error) {
 	return NewForRegion(region)
 }
 
-func NewForRegion(region string) (*Client, error) {
-	sess, err := session.NewSessionWithOptions(session.Options{
-		Config: aws.Config{
-			Region: aws.String(region),
-		},
-		SharedConfigState: session.SharedConfigEnable,
-	})
+// CredentialsConfig selects the credentials which are used by a client; if it is empty, the default credential chain is used
+type CredentialsConfig struct {
+	Profile          string                 // a named profile in the shared AWS config and credentials files
+	RoleARN          string                 // a role which is assumed with the profile's (or the default) credentials
+	SourceRoleARNs   []string               // roles which are assumed in order before RoleARN, each with the credentials of the previous one (role chaining)
+	ExternalID       string                 // the external ID which is passed when assuming RoleARN
+	SessionTags      map[string]string      // session tags which are passed when assuming RoleARN
+	MFASerial        string                 // the MFA device which is used to assume the first role in the chain
+	MFATokenProvider func() (string, error) // returns the current MFA token code; required if MFASerial is set
+}
 
-	if err != nil {
-		return nil, errors.WithStack(err)
+// roleChain returns the roles which are assumed (in order) for the credentials config
+func (credentialsConfig CredentialsConfig) roleChain() []string {
+	if credentialsConfig.RoleARN == "" {
+		return nil
 	}
+	return append(append([]string{}, credentialsConfig.SourceRoleARNs...), credentialsConfig.RoleARN)
+}
 
-	if sess.Config.Credentials == nil {
-		return nil, ErrorUnableToFindCredentials()
-	}
+func NewForRegion(region string) (*Client, error) {
+	return NewForRegionWithCredentials(region, CredentialsConfig{})
+}
 
-	creds, err := sess.Config.Credentials.Get()
+func NewForRegionWithCredentials(region string, credentialsConfig CredentialsConfig) (*Client, error) {
+	sess, err := newSession(aws.String(region), credentialsConfig)
 	if err != nil {
-		return nil, ErrorUnableToFindCredentials()
-	}
-
-	if creds.AccessKeyID == "" || creds.SecretAccessKey == "" {
-		return nil, ErrorUnexpectedMissingCredentials(creds.AccessKeyID, creds.SecretAccessKey)
+		return nil, err
 	}
 
 	return &Client{
@@ -107,11 +134,35 @@ func NewForRegion(region string) (*Client, error) {
 }
 
 func New() (*Client, error) {
-	sess := session.Must(session.NewSessionWithOptions(session.Options{
+	return NewWithCredentials(CredentialsConfig{})
+}
+
+func NewWithCredentials(credentialsConfig CredentialsConfig) (*Client, error) {
+	sess, err := newSession(nil, credentialsConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Client{
+		sess:   sess,
+		Region: *sess.Config.Region,
+	}, nil
+}
+
+// newSession uses the region from the shared config if region is nil
+func newSession(region *string, credentialsConfig CredentialsConfig) (*session.Session, error) {
+	sess, err := session.NewSessionWithOptions(session.Options{
+		Config: aws.Config{
+			Region: region,
+		},
+		Profile:           credentialsConfig.Profile,
 		SharedConfigState: session.SharedConfigEnable,
-	}))
+	})
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
 
-	if sess.Config.Region == nil {
+	if sess.Config.Region == nil || *sess.Config.Region == "" {
 		return nil, ErrorRegionNotConfigured()
 	}
 
@@ -119,8 +170,48 @@ func New() (*Client, error) {
 		return nil, ErrorUnableToFindCredentials()
 	}
 
+	roleChain := credentialsConfig.roleChain()
+	for i, roleARN := range roleChain {
+		isFirstRole := i == 0
+		isLastRole := i == len(roleChain)-1
+
+		if isFirstRole && credentialsConfig.MFASerial != "" && credentialsConfig.MFATokenProvider == nil {
+			return nil, ErrorMFATokenProviderRequired(credentialsConfig.MFASerial)
+		}
+
+		roleCredentials := stscreds.NewCredentials(sess, roleARN, func(provider *stscreds.AssumeRoleProvider) {
+			if isFirstRole && credentialsConfig.MFASerial != "" {
+				provider.SerialNumber = aws.String(credentialsConfig.MFASerial)
+				provider.TokenProvider = credentialsConfig.MFATokenProvider
+			}
+			if isLastRole {
+				if credentialsConfig.ExternalID != "" {
+					provider.ExternalID = aws.String(credentialsConfig.ExternalID)
+				}
+				for key, value := range credentialsConfig.SessionTags {
+					provider.Tags = append(provider.Tags, &sts.Tag{
+						Key:   aws.String(key),
+						Value: aws.String(value),
+					})
+				}
+			}
+		})
+
+		// retrieve the credentials of each role in the chain, so that errors are attributed to the correct role
+		if _, err := roleCredentials.Get(); err != nil {
+			return nil, ErrorUnableToAssumeRole(roleARN, err)
+		}
+
+		sess = sess.Copy(&aws.Config{
+			Credentials: roleCredentials,
+		})
+	}
+
 	creds, err := sess.Config.Credentials.Get()
 	if err != nil {
+		if len(roleChain) > 0 {
+			return nil, ErrorUnableToAssumeRole(credentialsConfig.RoleARN, err)
+		}
 		return nil, ErrorUnableToFindCredentials()
 	}
 
@@ -129,10 +220,7 @@ func New() (*Client, error) {
 		return nil, ErrorUnexpectedMissingCredentials(creds.AccessKeyID, creds.SecretAccessKey)
 	}
 
-	return &Client{
-		sess:   sess,
-		Region: *sess.Config.Region,
-	}, nil
+	return sess, nil
 }
 
 func NewAnonymousClientWithRegion(region string) (*Client, error) {
@@ -153,3 +241,21 @@ func NewAnonymousClientWithRegion(region string) (*Client, error) {
 func (c Client) Session() *session.Session {
 	return c.sess
 }
+
+func IsUsingLocalEndpoints() bool {
+	return os.Getenv(S3EndpointEnvVar) != "" || os.Getenv(SQSEndpointEnvVar) != ""
+}
+
+func endpointConfig(envVar string) []*aws.Config {
+	endpoint := os.Getenv(envVar)
+	if endpoint == "" {
+		return nil
+	}
+
+	return []*aws.Config{
+		{
+			Endpoint:         aws.String(endpoint),
+			S3ForcePathStyle: aws.Bool(true),
+		},
+	}
+}
@@ -17,21 +17,28 @@ limitations under the License.
 package aws
 
 import (
+	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/acm"
 	"github.com/aws/aws-sdk-go/service/apigatewayv2"
 	"github.com/aws/aws-sdk-go/service/autoscaling"
 	"github.com/aws/aws-sdk-go/service/cloudformation"
 	"github.com/aws/aws-sdk-go/service/cloudwatch"
 	"github.com/aws/aws-sdk-go/service/cloudwatchlogs"
+	"github.com/aws/aws-sdk-go/service/codebuild"
+	"github.com/aws/aws-sdk-go/service/costexplorer"
 	"github.com/aws/aws-sdk-go/service/ec2"
 	"github.com/aws/aws-sdk-go/service/ecr"
+	"github.com/aws/aws-sdk-go/service/efs"
 	"github.com/aws/aws-sdk-go/service/eks"
 	"github.com/aws/aws-sdk-go/service/elbv2"
 	"github.com/aws/aws-sdk-go/service/iam"
 	"github.com/aws/aws-sdk-go/service/s3"
 	"github.com/aws/aws-sdk-go/service/s3/s3manager"
+	"github.com/aws/aws-sdk-go/service/sagemaker"
+	"github.com/aws/aws-sdk-go/service/secretsmanager"
 	"github.com/aws/aws-sdk-go/service/servicequotas"
 	"github.com/aws/aws-sdk-go/service/sqs"
+	"github.com/aws/aws-sdk-go/service/ssm"
 	"github.com/aws/aws-sdk-go/service/sts"
 )
 
@@ -45,6 +52,7 @@ type clients struct {
 	elbv2          *elbv2.ELBV2
 	eks            *eks.EKS
 	ecr            *ecr.ECR
+	efs            *efs.EFS
 	acm            *acm.ACM
 	autoscaling    *autoscaling.AutoScaling
 	cloudWatchLogs *cloudwatchlogs.CloudWatchLogs
@@ -53,25 +61,30 @@ type clients struct {
 	serviceQuotas  *servicequotas.ServiceQuotas
 	cloudFormation *cloudformation.CloudFormation
 	iam            *iam.IAM
+	secretsManager *secretsmanager.SecretsManager
+	ssm            *ssm.SSM
+	codeBuild      *codebuild.CodeBuild
+	costExplorer   *costexplorer.CostExplorer
+	sageMaker      *sagemaker.SageMaker
 }
 
 func (c *Client) S3() *s3.S3 {
 	if c.clients.s3 == nil {
-		c.clients.s3 = s3.New(c.sess)
+		c.clients.s3 = s3.New(c.sess, endpointConfig(S3EndpointEnvVar)...)
 	}
 	return c.clients.s3
 }
 
 func (c *Client) S3Uploader() *s3manager.Uploader {
 	if c.clients.s3Uploader == nil {
-		c.clients.s3Uploader = s3manager.NewUploader(c.sess)
+		c.clients.s3Uploader = s3manager.NewUploaderWithClient(c.S3())
 	}
 	return c.clients.s3Uploader
 }
 
 func (c *Client) S3Downloader() *s3manager.Downloader {
 	if c.clients.s3Downloader == nil {
-		c.clients.s3Downloader = s3manager.NewDownloader(c.sess)
+		c.clients.s3Downloader = s3manager.NewDownloaderWithClient(c.S3())
 	}
 	return c.clients.s3Downloader
 }
@@ -85,7 +98,7 @@ func (c *Client) STS() *sts.STS {
 
 func (c *Client) SQS() *sqs.SQS {
 	if c.clients.sqs == nil {
-		c.clients.sqs = sqs.New(c.sess)
+		c.clients.sqs = sqs.New(c.sess, endpointConfig(SQSEndpointEnvVar)...)
 	}
 	return c.clients.sqs
 }
@@ -118,6 +131,49 @@ func (c *Client) ECR() *ecr.ECR {
 	return c.clients.ecr
 }
 
+func (c *Client) EFS() *efs.EFS {
+	if c.clients.efs == nil {
+		c.clients.efs = efs.New(c.sess)
+	}
+	return c.clients.efs
+}
+
+func (c *Client) SecretsManager() *secretsmanager.SecretsManager {
+	if c.clients.secretsManager == nil {
+		c.clients.secretsManager = secretsmanager.New(c.sess)
+	}
+	return c.clients.secretsManager
+}
+
+func (c *Client) SSM() *ssm.SSM {
+	if c.clients.ssm == nil {
+		c.clients.ssm = ssm.New(c.sess)
+	}
+	return c.clients.ssm
+}
+
+func (c *Client) CodeBuild() *codebuild.CodeBuild {
+	if c.clients.codeBuild == nil {
+		c.clients.codeBuild = codebuild.New(c.sess)
+	}
+	return c.clients.codeBuild
+}
+
+// Cost Explorer is only served from us-east-1, regardless of the region of the resources
+func (c *Client) CostExplorer() *costexplorer.CostExplorer {
+	if c.clients.costExplorer == nil {
+		c.clients.costExplorer = costexplorer.New(c.sess, aws.NewConfig().WithRegion("us-east-1"))
+	}
+	return c.clients.costExplorer
+}
+
+func (c *Client) SageMaker() *sagemaker.SageMaker {
+	if c.clients.sageMaker == nil {
+		c.clients.sageMaker = sagemaker.New(c.sess)
+	}
+	return c.clients.sageMaker
+}
+
 func (c *Client) CloudFormation() *cloudformation.CloudFormation {
 	if c.clients.cloudFormation == nil {
 		c.clients.cloudFormation = cloudformation.New(c.sess)
@@ -18,6 +18,7 @@ package aws
 
 import (
 	"encoding/json"
+	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/cloudwatch"
@@ -105,6 +106,18 @@ func (c *Client) CreateLogGroup(logGroup string, tags map[string]string) error {
 	return nil
 }
 
+func (c *Client) PutLogGroupRetention(logGroup string, retentionDays int64) error {
+	_, err := c.CloudWatchLogs().PutRetentionPolicy(&cloudwatchlogs.PutRetentionPolicyInput{
+		LogGroupName:    aws.String(logGroup),
+		RetentionInDays: aws.Int64(retentionDays),
+	})
+	if err != nil {
+		return errors.Wrap(err, "setting the retention of log group "+logGroup)
+	}
+
+	return nil
+}
+
 func (c *Client) DeleteLogGroup(logGroup string) error {
 	_, err := c.CloudWatchLogs().DeleteLogGroup(&cloudwatchlogs.DeleteLogGroupInput{
 		LogGroupName: aws.String(logGroup),
@@ -135,6 +148,44 @@ func (c *Client) TagLogGroup(logGroup string, tagMap map[string]string) error {
 }
 
 // NewDashboard creates a new dashboard object with title
+// GetLatestSQSOldestMessageAge returns the most recent ApproximateAgeOfOldestMessage datapoint (in seconds) reported for the queue,
+// or nil if sqs hasn't reported any datapoints in the last 10 minutes
+func (c *Client) GetLatestSQSOldestMessageAge(queueName string) (*float64, error) {
+	now := time.Now()
+	output, err := c.CloudWatch().GetMetricStatistics(&cloudwatch.GetMetricStatisticsInput{
+		Namespace:  aws.String("AWS/SQS"),
+		MetricName: aws.String("ApproximateAgeOfOldestMessage"),
+		Dimensions: []*cloudwatch.Dimension{
+			{
+				Name:  aws.String("QueueName"),
+				Value: aws.String(queueName),
+			},
+		},
+		StartTime:  aws.Time(now.Add(-10 * time.Minute)),
+		EndTime:    aws.Time(now),
+		Period:     aws.Int64(60),
+		Statistics: []*string{aws.String(cloudwatch.StatisticMaximum)},
+	})
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	var latest *cloudwatch.Datapoint
+	for _, datapoint := range output.Datapoints {
+		if datapoint.Timestamp == nil || datapoint.Maximum == nil {
+			continue
+		}
+		if latest == nil || datapoint.Timestamp.After(*latest.Timestamp) {
+			latest = datapoint
+		}
+	}
+	if latest == nil {
+		return nil, nil
+	}
+
+	return latest.Maximum, nil
+}
+
 func (c *Client) NewDashboard(title string) *CloudWatchDashboard {
 	return &CloudWatchDashboard{
 		Start:          "-PT1H",
@@ -352,3 +403,80 @@ func HighestY(dashboard *CloudWatchDashboard) (int, error) {
 
 	return highestY, nil
 }
+
+func (c *Client) GetLogGroupTags(logGroup string) (map[string]string, error) {
+	output, err := c.CloudWatchLogs().ListTagsLogGroup(&cloudwatchlogs.ListTagsLogGroupInput{
+		LogGroupName: aws.String(logGroup),
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "log group "+logGroup)
+	}
+
+	return aws.StringValueMap(output.Tags), nil
+}
+
+// returns nil if the log group doesn't exist
+func (c *Client) LogGroupOrNil(logGroup string) (*cloudwatchlogs.LogGroup, error) {
+	var match *cloudwatchlogs.LogGroup
+	err := c.CloudWatchLogs().DescribeLogGroupsPages(&cloudwatchlogs.DescribeLogGroupsInput{
+		LogGroupNamePrefix: aws.String(logGroup),
+	}, func(output *cloudwatchlogs.DescribeLogGroupsOutput, lastPage bool) bool {
+		for _, group := range output.LogGroups {
+			if group.LogGroupName != nil && *group.LogGroupName == logGroup {
+				match = group
+				return false
+			}
+		}
+		return true
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "log group "+logGroup)
+	}
+
+	return match, nil
+}
+
+// QueryLogs runs a CloudWatch Insights query on the log group, and returns the results (as maps of field names to values) once the query completes;
+// the query is stopped if it doesn't complete within the timeout
+func (c *Client) QueryLogs(logGroup string, query string, startTime time.Time, endTime time.Time, limit int64, timeout time.Duration) ([]map[string]string, error) {
+	startQueryOutput, err := c.CloudWatchLogs().StartQuery(&cloudwatchlogs.StartQueryInput{
+		LogGroupName: aws.String(logGroup),
+		QueryString:  aws.String(query),
+		StartTime:    aws.Int64(startTime.Unix()),
+		EndTime:      aws.Int64(endTime.Unix()),
+		Limit:        aws.Int64(limit),
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "log group "+logGroup)
+	}
+
+	deadline := time.Now().Add(timeout)
+	for {
+		output, err := c.CloudWatchLogs().GetQueryResults(&cloudwatchlogs.GetQueryResultsInput{
+			QueryId: startQueryOutput.QueryId,
+		})
+		if err != nil {
+			return nil, errors.Wrap(err, "log group "+logGroup)
+		}
+
+		switch status := aws.StringValue(output.Status); status {
+		case cloudwatchlogs.QueryStatusComplete:
+			results := make([]map[string]string, len(output.Results))
+			for i, fields := range output.Results {
+				results[i] = map[string]string{}
+				for _, field := range fields {
+					results[i][aws.StringValue(field.Field)] = aws.StringValue(field.Value)
+				}
+			}
+			return results, nil
+		case cloudwatchlogs.QueryStatusScheduled, cloudwatchlogs.QueryStatusRunning:
+			if time.Now().After(deadline) {
+				c.CloudWatchLogs().StopQuery(&cloudwatchlogs.StopQueryInput{QueryId: startQueryOutput.QueryId})
+				return nil, ErrorLogsQueryFailed(logGroup, "Timeout")
+			}
+			time.Sleep(500 * time.Millisecond)
+		default:
+			return nil, ErrorLogsQueryFailed(logGroup, status)
+		}
+	}
+}
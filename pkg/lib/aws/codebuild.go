@@ -0,0 +1,90 @@
+/*
+Copyright 2021 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package aws
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudwatchlogs"
+	"github.com/aws/aws-sdk-go/service/codebuild"
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+)
+
+func (c *Client) DoesCodeBuildProjectExist(projectName string) (bool, error) {
+	output, err := c.CodeBuild().BatchGetProjects(&codebuild.BatchGetProjectsInput{
+		Names: aws.StringSlice([]string{projectName}),
+	})
+	if err != nil {
+		return false, errors.Wrap(err, "codebuild project "+projectName)
+	}
+
+	return len(output.Projects) > 0, nil
+}
+
+func (c *Client) GetCodeBuildBuild(buildID string) (*codebuild.Build, error) {
+	output, err := c.CodeBuild().BatchGetBuilds(&codebuild.BatchGetBuildsInput{
+		Ids: aws.StringSlice([]string{buildID}),
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "codebuild build "+buildID)
+	}
+	if len(output.Builds) == 0 {
+		return nil, errors.ErrorUnexpected("codebuild build was not found", buildID)
+	}
+
+	return output.Builds[0], nil
+}
+
+func (c *Client) StopCodeBuildBuild(buildID string) error {
+	_, err := c.CodeBuild().StopBuild(&codebuild.StopBuildInput{
+		Id: aws.String(buildID),
+	})
+	if err != nil {
+		return errors.Wrap(err, "codebuild build "+buildID)
+	}
+
+	return nil
+}
+
+// returns the events after nextToken (or from the start of the stream if nextToken is nil), and the token to use to continue reading the stream
+func (c *Client) GetLogEventsAfter(logGroup string, logStream string, nextToken *string) ([]*cloudwatchlogs.OutputLogEvent, *string, error) {
+	input := &cloudwatchlogs.GetLogEventsInput{
+		LogGroupName:  aws.String(logGroup),
+		LogStreamName: aws.String(logStream),
+		StartFromHead: aws.Bool(true),
+		NextToken:     nextToken,
+	}
+
+	var events []*cloudwatchlogs.OutputLogEvent
+	for {
+		output, err := c.CloudWatchLogs().GetLogEvents(input)
+		if err != nil {
+			if IsErrCode(err, cloudwatchlogs.ErrCodeResourceNotFoundException) {
+				// the stream hasn't been created yet
+				return nil, nextToken, nil
+			}
+			return nil, nil, errors.Wrap(err, "log stream "+logGroup+"/"+logStream)
+		}
+
+		events = append(events, output.Events...)
+
+		// the end of the stream is reached when the same token is returned
+		if output.NextForwardToken == nil || (input.NextToken != nil && *output.NextForwardToken == *input.NextToken) {
+			return events, output.NextForwardToken, nil
+		}
+		input.NextToken = output.NextForwardToken
+	}
+}
@@ -0,0 +1,115 @@
+/*
+Copyright 2021 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package aws
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/costexplorer"
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+)
+
+// GetEffectiveInstancePrices returns the average hourly price which was paid for the non-spot instances of each instance type in the region over the past number of days,
+// based on their amortized cost (so that reserved instances and savings plans are taken into account); instance types which weren't used are omitted
+func (c *Client) GetEffectiveInstancePrices(region string, days int) (map[string]float64, error) {
+	now := time.Now().UTC()
+	input := &costexplorer.GetCostAndUsageInput{
+		TimePeriod: &costexplorer.DateInterval{
+			Start: aws.String(now.AddDate(0, 0, -days).Format("2006-01-02")),
+			End:   aws.String(now.Format("2006-01-02")),
+		},
+		Granularity: aws.String(costexplorer.GranularityDaily),
+		Metrics:     aws.StringSlice([]string{costexplorer.MetricAmortizedCost, costexplorer.MetricUsageQuantity}),
+		GroupBy: []*costexplorer.GroupDefinition{
+			{
+				Type: aws.String(costexplorer.GroupDefinitionTypeDimension),
+				Key:  aws.String(costexplorer.DimensionInstanceType),
+			},
+		},
+		Filter: &costexplorer.Expression{
+			And: []*costexplorer.Expression{
+				{
+					Dimensions: &costexplorer.DimensionValues{
+						Key:    aws.String(costexplorer.DimensionRegion),
+						Values: aws.StringSlice([]string{region}),
+					},
+				},
+				{
+					Dimensions: &costexplorer.DimensionValues{
+						Key:    aws.String(costexplorer.DimensionUsageTypeGroup),
+						Values: aws.StringSlice([]string{"EC2: Running Hours"}),
+					},
+				},
+				{
+					Not: &costexplorer.Expression{
+						Dimensions: &costexplorer.DimensionValues{
+							Key:    aws.String(costexplorer.DimensionPurchaseType),
+							Values: aws.StringSlice([]string{"Spot Instances"}),
+						},
+					},
+				},
+			},
+		},
+	}
+
+	costs := map[string]float64{}
+	hours := map[string]float64{}
+	for {
+		output, err := c.CostExplorer().GetCostAndUsage(input)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+
+		for _, result := range output.ResultsByTime {
+			for _, group := range result.Groups {
+				if len(group.Keys) == 0 {
+					continue
+				}
+				instanceType := *group.Keys[0]
+				if cost, ok := group.Metrics[costexplorer.MetricAmortizedCost]; ok && cost.Amount != nil {
+					amount, err := strconv.ParseFloat(*cost.Amount, 64)
+					if err != nil {
+						return nil, errors.WithStack(err)
+					}
+					costs[instanceType] += amount
+				}
+				if usage, ok := group.Metrics[costexplorer.MetricUsageQuantity]; ok && usage.Amount != nil {
+					amount, err := strconv.ParseFloat(*usage.Amount, 64)
+					if err != nil {
+						return nil, errors.WithStack(err)
+					}
+					hours[instanceType] += amount
+				}
+			}
+		}
+
+		if output.NextPageToken == nil {
+			break
+		}
+		input.NextPageToken = output.NextPageToken
+	}
+
+	prices := make(map[string]float64, len(hours))
+	for instanceType, numHours := range hours {
+		if numHours > 0 {
+			prices[instanceType] = costs[instanceType] / numHours
+		}
+	}
+	return prices, nil
+}
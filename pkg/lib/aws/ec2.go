@@ -35,6 +35,18 @@ var (
 	_gpuInstanceFamilies = strset.New("g", "p")
 )
 
+// CPU architectures of EC2 instances (named as in the kubernetes.io/arch node label)
+const (
+	ArchAMD64 = "amd64"
+	ArchARM64 = "arm64"
+)
+
+// operating systems of EC2 instances (named as in the kubernetes.io/os node label)
+const (
+	OSLinux   = "linux"
+	OSWindows = "windows"
+)
+
 type ParsedInstanceType struct {
 	Family       string
 	Generation   int
@@ -120,6 +132,27 @@ func IsARMInstance(instanceType string) (bool, error) {
 	return false, nil
 }
 
+// InstanceArch returns the CPU architecture of the instance type (ArchAMD64 or ArchARM64)
+func InstanceArch(instanceType string) (string, error) {
+	isARM, err := IsARMInstance(instanceType)
+	if err != nil {
+		return "", err
+	}
+	if isARM {
+		return ArchARM64, nil
+	}
+	return ArchAMD64, nil
+}
+
+func IsGPUInstance(instanceType string) (bool, error) {
+	parsedType, err := ParseInstanceType(instanceType)
+	if err != nil {
+		return false, err
+	}
+
+	return _gpuInstanceFamilies.Has(parsedType.Family), nil
+}
+
 func IsAMDGPUInstance(instanceType string) (bool, error) {
 	parsedType, err := ParseInstanceType(instanceType)
 	if err != nil {
@@ -137,6 +170,36 @@ func IsAMDGPUInstance(instanceType string) (bool, error) {
 	return false, nil
 }
 
+// NeuronCoresPerInf returns the number of NeuronCores on each Inferentia/Trainium chip of the instance type (0 if the instance type doesn't have any)
+func NeuronCoresPerInf(instanceType string) (int64, error) {
+	parsedType, err := ParseInstanceType(instanceType)
+	if err != nil {
+		return 0, err
+	}
+
+	switch parsedType.Family {
+	case "inf":
+		if parsedType.Generation == 1 {
+			return 4, nil
+		}
+		return 2, nil
+	case "trn":
+		return 2, nil
+	}
+
+	return 0, nil
+}
+
+// RequiresNeuronHugePages returns true if the Neuron runtime requires hugepages on the instance type (only first generation Inferentia instances do)
+func RequiresNeuronHugePages(instanceType string) (bool, error) {
+	parsedType, err := ParseInstanceType(instanceType)
+	if err != nil {
+		return false, err
+	}
+
+	return parsedType.Family == "inf" && parsedType.Generation == 1, nil
+}
+
 func (c *Client) SpotInstancePrice(instanceType string) (float64, error) {
 	result, err := c.EC2().DescribeSpotPriceHistory(&ec2.DescribeSpotPriceHistoryInput{
 		InstanceTypes:       []*string{aws.String(instanceType)},
@@ -338,6 +401,26 @@ func (c *Client) ListElasticIPs() ([]string, error) {
 	return addressesList, nil
 }
 
+// returns nil if the elastic ip doesn't exist
+func (c *Client) DescribeElasticIP(allocationID string) (*ec2.Address, error) {
+	output, err := c.EC2().DescribeAddresses(&ec2.DescribeAddressesInput{
+		AllocationIds: aws.StringSlice([]string{allocationID}),
+	})
+	if err != nil {
+		if IsErrCode(err, "InvalidAllocationID.NotFound") || IsErrCode(err, "InvalidAllocationID.Malformed") {
+			return nil, nil
+		}
+		return nil, errors.Wrap(err, "failed to describe elastic ip", allocationID)
+	}
+
+	for _, address := range output.Addresses {
+		if address != nil {
+			return address, nil
+		}
+	}
+	return nil, nil
+}
+
 func (c *Client) ListInternetGateways() ([]string, error) {
 	gatewaysList := []string{}
 	err := c.EC2().DescribeInternetGatewaysPages(&ec2.DescribeInternetGatewaysInput{}, func(output *ec2.DescribeInternetGatewaysOutput, lastPage bool) bool {
@@ -381,6 +464,44 @@ func (c *Client) DescribeNATGateways() ([]ec2.NatGateway, error) {
 	return gateways, nil
 }
 
+// ListNATGatewayPublicIPs returns the public ip addresses of the available nat gateways in the vpc
+func (c *Client) ListNATGatewayPublicIPs(vpcID string) ([]string, error) {
+	var publicIPs []string
+	err := c.EC2().DescribeNatGatewaysPages(&ec2.DescribeNatGatewaysInput{
+		Filter: []*ec2.Filter{
+			{
+				Name:   aws.String("vpc-id"),
+				Values: []*string{aws.String(vpcID)},
+			},
+			{
+				Name:   aws.String("state"),
+				Values: []*string{aws.String(ec2.NatGatewayStateAvailable)},
+			},
+		},
+	}, func(output *ec2.DescribeNatGatewaysOutput, lastPage bool) bool {
+		if output == nil {
+			return false
+		}
+		for _, gateway := range output.NatGateways {
+			if gateway == nil {
+				continue
+			}
+			for _, address := range gateway.NatGatewayAddresses {
+				if address != nil && address.PublicIp != nil {
+					publicIPs = append(publicIPs, *address.PublicIp)
+				}
+			}
+		}
+
+		return true
+	})
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return publicIPs, nil
+}
+
 func (c *Client) DescribeSubnets() ([]ec2.Subnet, error) {
 	var subnets []ec2.Subnet
 	err := c.EC2().DescribeSubnetsPages(&ec2.DescribeSubnetsInput{}, func(output *ec2.DescribeSubnetsOutput, lastPage bool) bool {
@@ -403,6 +524,28 @@ func (c *Client) DescribeSubnets() ([]ec2.Subnet, error) {
 	return subnets, nil
 }
 
+// returns nil if the instance doesn't exist
+func (c *Client) DescribeInstance(instanceID string) (*ec2.Instance, error) {
+	output, err := c.EC2().DescribeInstances(&ec2.DescribeInstancesInput{
+		InstanceIds: aws.StringSlice([]string{instanceID}),
+	})
+	if err != nil {
+		if IsErrCode(err, "InvalidInstanceID.NotFound") || IsErrCode(err, "InvalidInstanceID.Malformed") {
+			return nil, nil
+		}
+		return nil, errors.Wrap(err, "failed to describe instance", instanceID)
+	}
+
+	for _, reservation := range output.Reservations {
+		for _, instance := range reservation.Instances {
+			if instance != nil {
+				return instance, nil
+			}
+		}
+	}
+	return nil, nil
+}
+
 func (c *Client) DescribeVpcs() ([]ec2.Vpc, error) {
 	var vpcs []ec2.Vpc
 	err := c.EC2().DescribeVpcsPages(&ec2.DescribeVpcsInput{}, func(output *ec2.DescribeVpcsOutput, lastPage bool) bool {
@@ -32,6 +32,8 @@ func TestParseInstanceType(t *testing.T) {
 		{"t3.small", ParsedInstanceType{"t", 3, strset.New(), "small"}},
 		{"g4dn.xlarge", ParsedInstanceType{"g", 4, strset.New("d", "n"), "xlarge"}},
 		{"inf1.24xlarge", ParsedInstanceType{"inf", 1, strset.New(), "24xlarge"}},
+		{"inf2.48xlarge", ParsedInstanceType{"inf", 2, strset.New(), "48xlarge"}},
+		{"trn1n.32xlarge", ParsedInstanceType{"trn", 1, strset.New("n"), "32xlarge"}},
 		{"u-9tb1.metal", ParsedInstanceType{"u-9tb", 1, strset.New(), "metal"}},
 	}
 
@@ -61,3 +63,29 @@ func TestParseInstanceType(t *testing.T) {
 		require.NoError(t, err)
 	}
 }
+
+func TestNeuronCoresPerInf(t *testing.T) {
+	var testcases = []struct {
+		instanceType         string
+		expectedNeuronCores  int64
+		expectedHugePagesReq bool
+	}{
+		{"m5.large", 0, false},
+		{"inf1.xlarge", 4, true},
+		{"inf1.24xlarge", 4, true},
+		{"inf2.xlarge", 2, false},
+		{"inf2.48xlarge", 2, false},
+		{"trn1.2xlarge", 2, false},
+		{"trn1n.32xlarge", 2, false},
+	}
+
+	for _, testcase := range testcases {
+		neuronCores, err := NeuronCoresPerInf(testcase.instanceType)
+		require.NoError(t, err)
+		require.Equal(t, testcase.expectedNeuronCores, neuronCores, fmt.Sprintf("unexpected neuron cores for input: %s", testcase.instanceType))
+
+		requiresHugePages, err := RequiresNeuronHugePages(testcase.instanceType)
+		require.NoError(t, err)
+		require.Equal(t, testcase.expectedHugePagesReq, requiresHugePages, fmt.Sprintf("unexpected hugepages requirement for input: %s", testcase.instanceType))
+	}
+}
@@ -21,6 +21,7 @@ import (
 	"regexp"
 	"strings"
 
+	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/ecr"
 	"github.com/cortexlabs/cortex/pkg/lib/errors"
 	"github.com/cortexlabs/cortex/pkg/lib/regex"
@@ -47,6 +48,27 @@ func (c *Client) GetECRAuthConfig() (ECRAuthConfig, error) {
 	if err != nil {
 		return ECRAuthConfig{}, err
 	}
+	return ecrAuthConfigFromToken(tokenOutput)
+}
+
+// GetECRAuthConfigForRegistry retrieves credentials for an ECR registry (e.g. 123456789012.dkr.ecr.us-west-2.amazonaws.com),
+// which may be in another region, or in another account whose repository policies grant access to the caller
+func (c *Client) GetECRAuthConfigForRegistry(registry string) (ECRAuthConfig, error) {
+	ecrClient := c.ECR()
+	if region := GetRegionFromECRURL(registry); region != "" && region != c.Region {
+		ecrClient = ecr.New(c.sess, aws.NewConfig().WithRegion(region))
+	}
+
+	tokenOutput, err := ecrClient.GetAuthorizationToken(&ecr.GetAuthorizationTokenInput{
+		RegistryIds: aws.StringSlice([]string{GetAccountIDFromECRURL(registry)}),
+	})
+	if err != nil {
+		return ECRAuthConfig{}, errors.Wrap(err, "failed to retrieve ECR auth token", registry)
+	}
+	return ecrAuthConfigFromToken(tokenOutput)
+}
+
+func ecrAuthConfigFromToken(tokenOutput *ecr.GetAuthorizationTokenOutput) (ECRAuthConfig, error) {
 	if len(tokenOutput.AuthorizationData) == 0 {
 		return ECRAuthConfig{}, ErrorECRExtractingCredentials()
 	}
@@ -0,0 +1,98 @@
+/*
+Copyright 2021 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package aws
+
+import (
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/efs"
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+)
+
+func EFSFileSystemDNSName(fileSystemID string, region string) string {
+	return fmt.Sprintf("%s.efs.%s.amazonaws.com", fileSystemID, region)
+}
+
+// Returns the file system which was created with the provided creation token, or nil if no such file system exists
+func (c *Client) EFSFileSystemOrNil(creationToken string) (*efs.FileSystemDescription, error) {
+	output, err := c.EFS().DescribeFileSystems(&efs.DescribeFileSystemsInput{
+		CreationToken: aws.String(creationToken),
+	})
+	if err != nil {
+		if IsErrCode(err, efs.ErrCodeFileSystemNotFound) {
+			return nil, nil
+		}
+		return nil, errors.WithStack(err)
+	}
+
+	if len(output.FileSystems) == 0 {
+		return nil, nil
+	}
+
+	return output.FileSystems[0], nil
+}
+
+// Returns the ID of the file system
+func (c *Client) CreateEFSFileSystem(creationToken string, performanceMode string, tags map[string]string) (string, error) {
+	var efsTags []*efs.Tag
+	for key, value := range tags {
+		efsTags = append(efsTags, &efs.Tag{
+			Key:   aws.String(key),
+			Value: aws.String(value),
+		})
+	}
+
+	fileSystem, err := c.EFS().CreateFileSystem(&efs.CreateFileSystemInput{
+		CreationToken:   aws.String(creationToken),
+		PerformanceMode: aws.String(performanceMode),
+		Encrypted:       aws.Bool(true),
+		Tags:            efsTags,
+	})
+	if err != nil {
+		return "", errors.Wrap(err, "failed to create efs file system", creationToken)
+	}
+
+	return *fileSystem.FileSystemId, nil
+}
+
+func (c *Client) DeleteEFSFileSystem(fileSystemID string) error {
+	_, err := c.EFS().DeleteFileSystem(&efs.DeleteFileSystemInput{
+		FileSystemId: aws.String(fileSystemID),
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to delete efs file system", fileSystemID)
+	}
+
+	return nil
+}
+
+func (c *Client) ListEFSFileSystems() ([]*efs.FileSystemDescription, error) {
+	var fileSystems []*efs.FileSystemDescription
+	input := &efs.DescribeFileSystemsInput{}
+	for {
+		output, err := c.EFS().DescribeFileSystems(input)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		fileSystems = append(fileSystems, output.FileSystems...)
+		if output.NextMarker == nil {
+			return fileSystems, nil
+		}
+		input.Marker = output.NextMarker
+	}
+}
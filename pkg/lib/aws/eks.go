@@ -17,6 +17,7 @@ limitations under the License.
 package aws
 
 import (
+	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/eks"
 	"github.com/cortexlabs/cortex/pkg/lib/errors"
 	"github.com/cortexlabs/cortex/pkg/lib/sets/strset"
@@ -43,3 +44,16 @@ func (c *Client) EKSClusterOrNil(clusterName string) (*eks.Cluster, error) {
 
 	return clusterInfo.Cluster, nil
 }
+
+func (c *Client) ListEKSClusterNames() ([]string, error) {
+	var clusterNames []string
+	err := c.EKS().ListClustersPages(&eks.ListClustersInput{}, func(output *eks.ListClustersOutput, lastPage bool) bool {
+		clusterNames = append(clusterNames, aws.StringValueSlice(output.Clusters)...)
+		return true
+	})
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return clusterNames, nil
+}
@@ -103,3 +103,68 @@ func (c *Client) FindLoadBalancer(tags map[string]string) (*elbv2.LoadBalancer,
 
 	return loadBalancer, nil
 }
+
+// returns the load balancers which have the tag key, and the value of the tag for each load balancer (keyed by ARN)
+func (c *Client) ListLoadBalancersWithTagKey(tagKey string) ([]*elbv2.LoadBalancer, map[string]string, error) {
+	var loadBalancers []*elbv2.LoadBalancer
+	tagValues := map[string]string{}
+	var fnErr error
+
+	params := elbv2.DescribeLoadBalancersInput{
+		PageSize: aws.Int64(20), // 20 is the limit for DescribeTags()
+	}
+	err := c.ELBV2().DescribeLoadBalancersPages(&params,
+		func(page *elbv2.DescribeLoadBalancersOutput, lastPage bool) bool {
+			if len(page.LoadBalancers) == 0 {
+				return true
+			}
+
+			arns := make([]string, len(page.LoadBalancers))
+			pageLoadBalancers := make(map[string]*elbv2.LoadBalancer)
+			for i := range page.LoadBalancers {
+				arn := *page.LoadBalancers[i].LoadBalancerArn
+				arns[i] = arn
+				pageLoadBalancers[arn] = page.LoadBalancers[i]
+			}
+
+			tagsOutput, err := c.ELBV2().DescribeTags(&elbv2.DescribeTagsInput{
+				ResourceArns: aws.StringSlice(arns),
+			})
+			if err != nil {
+				fnErr = errors.WithStack(err)
+				return false
+			}
+
+			for _, tagDescription := range tagsOutput.TagDescriptions {
+				for _, lbTag := range tagDescription.Tags {
+					if lbTag.Key != nil && *lbTag.Key == tagKey && lbTag.Value != nil {
+						loadBalancers = append(loadBalancers, pageLoadBalancers[*tagDescription.ResourceArn])
+						tagValues[*tagDescription.ResourceArn] = *lbTag.Value
+						break
+					}
+				}
+			}
+
+			return true
+		})
+
+	if err != nil {
+		return nil, nil, errors.WithStack(err)
+	}
+	if fnErr != nil {
+		return nil, nil, fnErr
+	}
+
+	return loadBalancers, tagValues, nil
+}
+
+func (c *Client) DeleteLoadBalancer(loadBalancerARN string) error {
+	_, err := c.ELBV2().DeleteLoadBalancer(&elbv2.DeleteLoadBalancerInput{
+		LoadBalancerArn: aws.String(loadBalancerARN),
+	})
+	if err != nil {
+		return errors.Wrap(err, loadBalancerARN)
+	}
+
+	return nil
+}
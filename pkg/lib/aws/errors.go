@@ -43,12 +43,18 @@ const (
 	ErrDashboardHeightOutOfRange    = "aws.dashboard_height_out_of_range"
 	ErrRegionNotConfigured          = "aws.region_not_configured"
 	ErrUnableToFindCredentials      = "aws.unable_to_find_credentials"
+	ErrUnableToAssumeRole           = "aws.unable_to_assume_role"
+	ErrMFATokenProviderRequired     = "aws.mfa_token_provider_required"
 	ErrNATGatewayLimitExceeded      = "aws.nat_gateway_limit_exceeded"
 	ErrEIPLimitExceeded             = "aws.eip_limit_exceeded"
 	ErrInternetGatewayLimitExceeded = "aws.internet_gateway_limit_exceeded"
 	ErrVPCLimitExceeded             = "aws.vpc_limit_exceeded"
 	ErrSecurityGroupRulesExceeded   = "aws.security_group_rules_exceeded"
 	ErrSecurityGroupLimitExceeded   = "aws.security_group_limit_exceeded"
+	ErrUnexpectedIPRangesResponse   = "aws.unexpected_ip_ranges_response"
+	ErrLogsQueryFailed              = "aws.logs_query_failed"
+	ErrModelPackageNotFound         = "aws.model_package_not_found"
+	ErrModelPackageHasNoModelData   = "aws.model_package_has_no_model_data"
 )
 
 func IsAWSError(err error) bool {
@@ -211,6 +217,20 @@ func ErrorUnableToFindCredentials() error {
 	})
 }
 
+func ErrorUnableToAssumeRole(roleARN string, err error) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrUnableToAssumeRole,
+		Message: fmt.Sprintf("unable to assume role %s: %s", roleARN, errors.Message(err)),
+	})
+}
+
+func ErrorMFATokenProviderRequired(mfaSerial string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrMFATokenProviderRequired,
+		Message: fmt.Sprintf("an mfa token is required for mfa device %s, but no way to provide it was configured", mfaSerial),
+	})
+}
+
 func ErrorNATGatewayLimitExceeded(currentLimit, additionalQuotaRequired int, availabilityZones []string, region string) error {
 	url := "https://console.aws.amazon.com/servicequotas/home?#!/services/vpc/quotas"
 	return errors.WithStack(&errors.Error{
@@ -258,3 +278,38 @@ func ErrorSecurityGroupLimitExceeded(currentLimit, additionalQuotaRequired int,
 		Message: fmt.Sprintf("security group limit of %d exceeded in region %s; remove some node groups from your cluster config or increase your quota for security groups by at least %d here: %s (if your request was recently approved, please allow ~30 minutes for AWS to reflect this change)", currentLimit, region, additionalQuotaRequired, url),
 	})
 }
+
+func ErrorUnexpectedIPRangesResponse(statusCode int) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrUnexpectedIPRangesResponse,
+		Message: fmt.Sprintf("unable to download the AWS ip address ranges from %s (status code %d)", _ipRangesURL, statusCode),
+	})
+}
+
+func ErrorLogsQueryFailed(logGroup string, status string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrLogsQueryFailed,
+		Message: fmt.Sprintf("the CloudWatch Insights query of log group %s did not complete (status: %s)", logGroup, status),
+	})
+}
+
+func ErrorModelPackageNotFound(groupName string, version *int64, approvalStatus string) error {
+	var message string
+	if version != nil {
+		message = fmt.Sprintf("version %d of SageMaker model package group %s was not found", *version, groupName)
+	} else {
+		message = fmt.Sprintf("SageMaker model package group %s has no model packages with approval status %s", groupName, approvalStatus)
+	}
+
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrModelPackageNotFound,
+		Message: message,
+	})
+}
+
+func ErrorModelPackageHasNoModelData(modelPackageArn string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrModelPackageHasNoModelData,
+		Message: fmt.Sprintf("SageMaker model package %s does not specify the s3 location of its model data", modelPackageArn),
+	})
+}
@@ -54,11 +54,19 @@ EKS_PRICING_ENDPOINT_TEMPLATE = (
     "https://pricing.us-east-1.amazonaws.com/offers/v1.0/aws/AmazonEKS/current/{}/index.json"
 )
 
+# the number of Inferentia/Trainium chips (i.e. aws.amazon.com/neuron devices) per instance
 inf_per_instance_type = {
     "inf1.xlarge": 1,
     "inf1.2xlarge": 1,
     "inf1.6xlarge": 4,
     "inf1.24xlarge": 16,
+    "inf2.xlarge": 1,
+    "inf2.8xlarge": 1,
+    "inf2.24xlarge": 6,
+    "inf2.48xlarge": 12,
+    "trn1.2xlarge": 1,
+    "trn1.32xlarge": 16,
+    "trn1n.32xlarge": 16,
 }
 
 
@@ -226,3 +226,73 @@ func (c *Client) GetPolicyOrNil(policyARN string) (*iam.Policy, error) {
 	}
 	return nil, nil
 }
+
+// returns the customer managed policies whose names start with prefix
+func (c *Client) ListPoliciesWithPrefix(prefix string) ([]*iam.Policy, error) {
+	var policies []*iam.Policy
+	err := c.IAM().ListPoliciesPages(&iam.ListPoliciesInput{
+		Scope: aws.String(iam.PolicyScopeTypeLocal),
+	}, func(output *iam.ListPoliciesOutput, lastPage bool) bool {
+		for _, policy := range output.Policies {
+			if policy.PolicyName != nil && strings.HasPrefix(*policy.PolicyName, prefix) {
+				policies = append(policies, policy)
+			}
+		}
+		return true
+	})
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return policies, nil
+}
+
+// returns the ARN of the IAM user or role which the client is authenticated as (for assumed roles, the ARN of the role rather than of the session)
+func (c *Client) GetPrincipalARN() (string, error) {
+	identity, err := c.STS().GetCallerIdentity(nil)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+
+	arn := *identity.Arn
+	if !strings.Contains(arn, ":assumed-role/") {
+		return arn, nil
+	}
+
+	// expected to be in form arn:aws:sts::account-id:assumed-role/role-name/role-session-name
+	arnSplit := strings.Split(arn, "/")
+	if len(arnSplit) < 2 {
+		return arn, nil
+	}
+
+	// the role's path isn't included in the assumed role ARN, so the role ARN is retrieved from iam
+	roleOutput, err := c.IAM().GetRole(&iam.GetRoleInput{
+		RoleName: aws.String(arnSplit[1]),
+	})
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	return *roleOutput.Role.Arn, nil
+}
+
+// returns the actions which the iam policy simulator evaluates as denied for the principal on the resource
+func (c *Client) ListDeniedActions(principalARN string, resourceARN string, actions []string) ([]string, error) {
+	var deniedActions []string
+	err := c.IAM().SimulatePrincipalPolicyPages(&iam.SimulatePrincipalPolicyInput{
+		PolicySourceArn: aws.String(principalARN),
+		ActionNames:     aws.StringSlice(actions),
+		ResourceArns:    aws.StringSlice([]string{resourceARN}),
+	}, func(output *iam.SimulatePolicyResponse, lastPage bool) bool {
+		for _, result := range output.EvaluationResults {
+			if result.EvalDecision != nil && *result.EvalDecision != iam.PolicyEvaluationDecisionTypeAllowed {
+				deniedActions = append(deniedActions, *result.EvalActionName)
+			}
+		}
+		return true
+	})
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return deniedActions, nil
+}
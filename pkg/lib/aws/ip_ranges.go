@@ -0,0 +1,89 @@
+/*
+Copyright 2021 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package aws
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/lib/sets/strset"
+)
+
+// https://docs.aws.amazon.com/general/latest/gr/aws-ip-ranges.html
+const _ipRangesURL = "https://ip-ranges.amazonaws.com/ip-ranges.json"
+
+var _ipRangesClient = &http.Client{
+	Timeout: 30 * time.Second,
+}
+
+type ipRanges struct {
+	Prefixes []ipRangesPrefix `json:"prefixes"`
+}
+
+type ipRangesPrefix struct {
+	IPPrefix string `json:"ip_prefix"`
+	Region   string `json:"region"`
+	Service  string `json:"service"`
+}
+
+// GetServiceIPRanges returns the sorted IPv4 CIDR blocks which AWS publishes for its services in the region (e.g. S3 and SQS);
+// the addresses of EC2 instances are excluded, as recommended in https://docs.aws.amazon.com/general/latest/gr/aws-ip-ranges.html
+func GetServiceIPRanges(region string) ([]string, error) {
+	response, err := _ipRangesClient.Get(_ipRangesURL)
+	if err != nil {
+		return nil, errors.Wrap(err, _ipRangesURL)
+	}
+	defer response.Body.Close()
+
+	body, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return nil, errors.Wrap(err, _ipRangesURL)
+	}
+	if response.StatusCode != http.StatusOK {
+		return nil, ErrorUnexpectedIPRangesResponse(response.StatusCode)
+	}
+
+	var ranges ipRanges
+	if err := json.Unmarshal(body, &ranges); err != nil {
+		return nil, errors.Wrap(err, _ipRangesURL)
+	}
+
+	return serviceIPRanges(ranges, region), nil
+}
+
+// every prefix is listed under the AMAZON service, and prefixes which are also used by specific services are listed again under those services
+func serviceIPRanges(ranges ipRanges, region string) []string {
+	amazonPrefixes := strset.New()
+	ec2Prefixes := strset.New()
+	for _, prefix := range ranges.Prefixes {
+		if prefix.Region != region {
+			continue
+		}
+		switch prefix.Service {
+		case "AMAZON":
+			amazonPrefixes.Add(prefix.IPPrefix)
+		case "EC2":
+			ec2Prefixes.Add(prefix.IPPrefix)
+		}
+	}
+
+	amazonPrefixes.Subtract(ec2Prefixes)
+	return amazonPrefixes.SliceSorted()
+}
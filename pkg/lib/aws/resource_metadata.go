@@ -205,6 +205,10 @@ var AllInstanceTypes = strset.New(
 	"inf1.2xlarge",
 	"inf1.6xlarge",
 	"inf1.24xlarge",
+	"inf2.xlarge",
+	"inf2.8xlarge",
+	"inf2.24xlarge",
+	"inf2.48xlarge",
 	"m1.small",
 	"m1.medium",
 	"m1.large",
@@ -427,6 +431,9 @@ var AllInstanceTypes = strset.New(
 	"t4g.large",
 	"t4g.xlarge",
 	"t4g.2xlarge",
+	"trn1.2xlarge",
+	"trn1.32xlarge",
+	"trn1n.32xlarge",
 	"u-12tb1.112xlarge",
 	"u-12tb1.metal",
 	"u-18tb1.metal",
@@ -4625,6 +4632,10 @@ var InstanceTypes = map[string]strset.Set{
 		"inf1.2xlarge",
 		"inf1.6xlarge",
 		"inf1.24xlarge",
+		"inf2.xlarge",
+		"inf2.8xlarge",
+		"inf2.24xlarge",
+		"inf2.48xlarge",
 		"m1.small",
 		"m1.medium",
 		"m1.large",
@@ -4847,6 +4858,9 @@ var InstanceTypes = map[string]strset.Set{
 		"t4g.large",
 		"t4g.xlarge",
 		"t4g.2xlarge",
+		"trn1.2xlarge",
+		"trn1.32xlarge",
+		"trn1n.32xlarge",
 		"u-12tb1.112xlarge",
 		"u-12tb1.metal",
 		"u-18tb1.metal",
@@ -6186,6 +6200,10 @@ var InstanceTypes = map[string]strset.Set{
 		"inf1.2xlarge",
 		"inf1.6xlarge",
 		"inf1.24xlarge",
+		"inf2.xlarge",
+		"inf2.8xlarge",
+		"inf2.24xlarge",
+		"inf2.48xlarge",
 		"m1.small",
 		"m1.medium",
 		"m1.large",
@@ -6408,6 +6426,9 @@ var InstanceTypes = map[string]strset.Set{
 		"t4g.large",
 		"t4g.xlarge",
 		"t4g.2xlarge",
+		"trn1.2xlarge",
+		"trn1.32xlarge",
+		"trn1n.32xlarge",
 		"u-12tb1.112xlarge",
 		"u-12tb1.metal",
 		"u-6tb1.56xlarge",
@@ -10561,6 +10582,10 @@ var InstanceMetadatas = map[string]map[string]InstanceMetadata{
 		"inf1.2xlarge":      {Region: "us-east-1", Type: "inf1.2xlarge", Memory: kresource.MustParse("16384Mi"), CPU: kresource.MustParse("8"), GPU: 0, Inf: 1, Price: 0.584},
 		"inf1.6xlarge":      {Region: "us-east-1", Type: "inf1.6xlarge", Memory: kresource.MustParse("49152Mi"), CPU: kresource.MustParse("24"), GPU: 0, Inf: 4, Price: 1.904},
 		"inf1.24xlarge":     {Region: "us-east-1", Type: "inf1.24xlarge", Memory: kresource.MustParse("196608Mi"), CPU: kresource.MustParse("96"), GPU: 0, Inf: 16, Price: 7.615},
+		"inf2.xlarge":       {Region: "us-east-1", Type: "inf2.xlarge", Memory: kresource.MustParse("16384Mi"), CPU: kresource.MustParse("4"), GPU: 0, Inf: 1, Price: 0.7582},
+		"inf2.8xlarge":      {Region: "us-east-1", Type: "inf2.8xlarge", Memory: kresource.MustParse("131072Mi"), CPU: kresource.MustParse("32"), GPU: 0, Inf: 1, Price: 1.96786},
+		"inf2.24xlarge":     {Region: "us-east-1", Type: "inf2.24xlarge", Memory: kresource.MustParse("393216Mi"), CPU: kresource.MustParse("96"), GPU: 0, Inf: 6, Price: 6.49063},
+		"inf2.48xlarge":     {Region: "us-east-1", Type: "inf2.48xlarge", Memory: kresource.MustParse("786432Mi"), CPU: kresource.MustParse("192"), GPU: 0, Inf: 12, Price: 12.98127},
 		"m1.small":          {Region: "us-east-1", Type: "m1.small", Memory: kresource.MustParse("1740Mi"), CPU: kresource.MustParse("1"), GPU: 0, Inf: 0, Price: 0.044},
 		"m1.medium":         {Region: "us-east-1", Type: "m1.medium", Memory: kresource.MustParse("3840Mi"), CPU: kresource.MustParse("1"), GPU: 0, Inf: 0, Price: 0.087},
 		"m1.large":          {Region: "us-east-1", Type: "m1.large", Memory: kresource.MustParse("7680Mi"), CPU: kresource.MustParse("2"), GPU: 0, Inf: 0, Price: 0.175},
@@ -10782,6 +10807,9 @@ var InstanceMetadatas = map[string]map[string]InstanceMetadata{
 		"t4g.large":         {Region: "us-east-1", Type: "t4g.large", Memory: kresource.MustParse("8192Mi"), CPU: kresource.MustParse("2"), GPU: 0, Inf: 0, Price: 0.0672},
 		"t4g.xlarge":        {Region: "us-east-1", Type: "t4g.xlarge", Memory: kresource.MustParse("16384Mi"), CPU: kresource.MustParse("4"), GPU: 0, Inf: 0, Price: 0.1344},
 		"t4g.2xlarge":       {Region: "us-east-1", Type: "t4g.2xlarge", Memory: kresource.MustParse("32768Mi"), CPU: kresource.MustParse("8"), GPU: 0, Inf: 0, Price: 0.2688},
+		"trn1.2xlarge":      {Region: "us-east-1", Type: "trn1.2xlarge", Memory: kresource.MustParse("32768Mi"), CPU: kresource.MustParse("8"), GPU: 0, Inf: 1, Price: 1.34375},
+		"trn1.32xlarge":     {Region: "us-east-1", Type: "trn1.32xlarge", Memory: kresource.MustParse("524288Mi"), CPU: kresource.MustParse("128"), GPU: 0, Inf: 16, Price: 21.5},
+		"trn1n.32xlarge":    {Region: "us-east-1", Type: "trn1n.32xlarge", Memory: kresource.MustParse("524288Mi"), CPU: kresource.MustParse("128"), GPU: 0, Inf: 16, Price: 24.78},
 		"u-12tb1.112xlarge": {Region: "us-east-1", Type: "u-12tb1.112xlarge", Memory: kresource.MustParse("12582912Mi"), CPU: kresource.MustParse("448"), GPU: 0, Inf: 0, Price: 109.2},
 		"u-6tb1.56xlarge":   {Region: "us-east-1", Type: "u-6tb1.56xlarge", Memory: kresource.MustParse("6291456Mi"), CPU: kresource.MustParse("224"), GPU: 0, Inf: 0, Price: 46.40391},
 		"u-6tb1.112xlarge":  {Region: "us-east-1", Type: "u-6tb1.112xlarge", Memory: kresource.MustParse("6291456Mi"), CPU: kresource.MustParse("448"), GPU: 0, Inf: 0, Price: 54.6},
@@ -12100,6 +12128,10 @@ var InstanceMetadatas = map[string]map[string]InstanceMetadata{
 		"inf1.2xlarge":      {Region: "us-west-2", Type: "inf1.2xlarge", Memory: kresource.MustParse("16384Mi"), CPU: kresource.MustParse("8"), GPU: 0, Inf: 1, Price: 0.584},
 		"inf1.6xlarge":      {Region: "us-west-2", Type: "inf1.6xlarge", Memory: kresource.MustParse("49152Mi"), CPU: kresource.MustParse("24"), GPU: 0, Inf: 4, Price: 1.904},
 		"inf1.24xlarge":     {Region: "us-west-2", Type: "inf1.24xlarge", Memory: kresource.MustParse("196608Mi"), CPU: kresource.MustParse("96"), GPU: 0, Inf: 16, Price: 7.615},
+		"inf2.xlarge":       {Region: "us-west-2", Type: "inf2.xlarge", Memory: kresource.MustParse("16384Mi"), CPU: kresource.MustParse("4"), GPU: 0, Inf: 1, Price: 0.7582},
+		"inf2.8xlarge":      {Region: "us-west-2", Type: "inf2.8xlarge", Memory: kresource.MustParse("131072Mi"), CPU: kresource.MustParse("32"), GPU: 0, Inf: 1, Price: 1.96786},
+		"inf2.24xlarge":     {Region: "us-west-2", Type: "inf2.24xlarge", Memory: kresource.MustParse("393216Mi"), CPU: kresource.MustParse("96"), GPU: 0, Inf: 6, Price: 6.49063},
+		"inf2.48xlarge":     {Region: "us-west-2", Type: "inf2.48xlarge", Memory: kresource.MustParse("786432Mi"), CPU: kresource.MustParse("192"), GPU: 0, Inf: 12, Price: 12.98127},
 		"m1.small":          {Region: "us-west-2", Type: "m1.small", Memory: kresource.MustParse("1740Mi"), CPU: kresource.MustParse("1"), GPU: 0, Inf: 0, Price: 0.044},
 		"m1.medium":         {Region: "us-west-2", Type: "m1.medium", Memory: kresource.MustParse("3840Mi"), CPU: kresource.MustParse("1"), GPU: 0, Inf: 0, Price: 0.087},
 		"m1.large":          {Region: "us-west-2", Type: "m1.large", Memory: kresource.MustParse("7680Mi"), CPU: kresource.MustParse("2"), GPU: 0, Inf: 0, Price: 0.175},
@@ -12321,6 +12353,9 @@ var InstanceMetadatas = map[string]map[string]InstanceMetadata{
 		"t4g.large":         {Region: "us-west-2", Type: "t4g.large", Memory: kresource.MustParse("8192Mi"), CPU: kresource.MustParse("2"), GPU: 0, Inf: 0, Price: 0.0672},
 		"t4g.xlarge":        {Region: "us-west-2", Type: "t4g.xlarge", Memory: kresource.MustParse("16384Mi"), CPU: kresource.MustParse("4"), GPU: 0, Inf: 0, Price: 0.1344},
 		"t4g.2xlarge":       {Region: "us-west-2", Type: "t4g.2xlarge", Memory: kresource.MustParse("32768Mi"), CPU: kresource.MustParse("8"), GPU: 0, Inf: 0, Price: 0.2688},
+		"trn1.2xlarge":      {Region: "us-west-2", Type: "trn1.2xlarge", Memory: kresource.MustParse("32768Mi"), CPU: kresource.MustParse("8"), GPU: 0, Inf: 1, Price: 1.34375},
+		"trn1.32xlarge":     {Region: "us-west-2", Type: "trn1.32xlarge", Memory: kresource.MustParse("524288Mi"), CPU: kresource.MustParse("128"), GPU: 0, Inf: 16, Price: 21.5},
+		"trn1n.32xlarge":    {Region: "us-west-2", Type: "trn1n.32xlarge", Memory: kresource.MustParse("524288Mi"), CPU: kresource.MustParse("128"), GPU: 0, Inf: 16, Price: 24.78},
 		"u-12tb1.112xlarge": {Region: "us-west-2", Type: "u-12tb1.112xlarge", Memory: kresource.MustParse("12582912Mi"), CPU: kresource.MustParse("448"), GPU: 0, Inf: 0, Price: 109.2},
 		"u-6tb1.56xlarge":   {Region: "us-west-2", Type: "u-6tb1.56xlarge", Memory: kresource.MustParse("6291456Mi"), CPU: kresource.MustParse("224"), GPU: 0, Inf: 0, Price: 46.40391},
 		"u-6tb1.112xlarge":  {Region: "us-west-2", Type: "u-6tb1.112xlarge", Memory: kresource.MustParse("6291456Mi"), CPU: kresource.MustParse("448"), GPU: 0, Inf: 0, Price: 54.6},
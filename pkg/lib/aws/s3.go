@@ -33,6 +33,7 @@ import (
 	"github.com/aws/aws-sdk-go/service/s3/s3manager"
 	"github.com/cortexlabs/cortex/pkg/lib/errors"
 	"github.com/cortexlabs/cortex/pkg/lib/files"
+	"github.com/cortexlabs/cortex/pkg/lib/hash"
 	"github.com/cortexlabs/cortex/pkg/lib/json"
 	"github.com/cortexlabs/cortex/pkg/lib/msgpack"
 	"github.com/cortexlabs/cortex/pkg/lib/pointer"
@@ -732,6 +733,23 @@ func (c *Client) ListS3PathPrefix(s3Path string, includeDirObjects bool, maxResu
 	return c.ListS3Prefix(bucket, prefix, includeDirObjects, maxResults, startAfter)
 }
 
+// GetS3PathFingerprint returns a hash of the keys and etags of the objects at the s3 path,
+// which changes whenever an object is added, removed, or modified
+func (c *Client) GetS3PathFingerprint(s3Path string) (string, error) {
+	objects, err := c.ListS3PathPrefix(s3Path, false, nil, nil)
+	if err != nil {
+		return "", err
+	}
+
+	// objects are listed in lexicographical order of their keys
+	fingerprintParts := make([]string, 0, 2*len(objects))
+	for _, object := range objects {
+		fingerprintParts = append(fingerprintParts, aws.StringValue(object.Key), aws.StringValue(object.ETag))
+	}
+
+	return hash.Strings(fingerprintParts...)[:16], nil
+}
+
 func (c *Client) DeleteS3File(bucket string, key string) error {
 	_, err := c.S3().DeleteObject(
 		&s3.DeleteObjectInput{
@@ -960,3 +978,24 @@ func (c *Client) DeleteLifecycleRules(bucket string) error {
 	})
 	return errors.WithStack(err)
 }
+
+func (c *Client) ListBuckets() ([]*s3.Bucket, error) {
+	output, err := c.S3().ListBuckets(&s3.ListBucketsInput{})
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return output.Buckets, nil
+}
+
+// the bucket must be empty
+func (c *Client) DeleteBucket(bucket string) error {
+	_, err := c.S3().DeleteBucket(&s3.DeleteBucketInput{
+		Bucket: aws.String(bucket),
+	})
+	if err != nil {
+		return errors.Wrap(err, S3Path(bucket, ""))
+	}
+
+	return nil
+}
@@ -0,0 +1,94 @@
+/*
+Copyright 2021 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package aws
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/sagemaker"
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+)
+
+// ModelPackage is a version of a model in a SageMaker model package group
+type ModelPackage struct {
+	Arn          string
+	Version      int64
+	ModelDataURL string
+}
+
+// GetModelPackage returns the model package with the given version in the model package group,
+// or the latest version with the approval status (e.g. "Approved") if version is nil
+func (c *Client) GetModelPackage(groupName string, version *int64, approvalStatus string) (*ModelPackage, error) {
+	input := &sagemaker.ListModelPackagesInput{
+		ModelPackageGroupName: aws.String(groupName),
+		ModelPackageType:      aws.String(sagemaker.ModelPackageTypeVersioned),
+	}
+	if version == nil {
+		input.ModelApprovalStatus = aws.String(approvalStatus)
+	}
+
+	var latest *sagemaker.ModelPackageSummary
+	err := c.SageMaker().ListModelPackagesPages(input, func(output *sagemaker.ListModelPackagesOutput, lastPage bool) bool {
+		for _, summary := range output.ModelPackageSummaryList {
+			if summary.ModelPackageVersion == nil {
+				continue
+			}
+			if version != nil {
+				if *summary.ModelPackageVersion == *version {
+					latest = summary
+					return false
+				}
+				continue
+			}
+			if latest == nil || *summary.ModelPackageVersion > *latest.ModelPackageVersion {
+				latest = summary
+			}
+		}
+		return true
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list SageMaker model packages", groupName)
+	}
+	if latest == nil {
+		return nil, ErrorModelPackageNotFound(groupName, version, approvalStatus)
+	}
+
+	output, err := c.SageMaker().DescribeModelPackage(&sagemaker.DescribeModelPackageInput{
+		ModelPackageName: latest.ModelPackageArn,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to describe SageMaker model package", *latest.ModelPackageArn)
+	}
+
+	var modelDataURL string
+	if output.InferenceSpecification != nil {
+		for _, container := range output.InferenceSpecification.Containers {
+			if container.ModelDataUrl != nil && *container.ModelDataUrl != "" {
+				modelDataURL = *container.ModelDataUrl
+				break
+			}
+		}
+	}
+	if modelDataURL == "" {
+		return nil, ErrorModelPackageHasNoModelData(*latest.ModelPackageArn)
+	}
+
+	return &ModelPackage{
+		Arn:          *latest.ModelPackageArn,
+		Version:      *latest.ModelPackageVersion,
+		ModelDataURL: modelDataURL,
+	}, nil
+}
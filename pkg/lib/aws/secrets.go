@@ -0,0 +1,77 @@
+/*
+Copyright 2021 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package aws
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/secretsmanager"
+	"github.com/aws/aws-sdk-go/service/ssm"
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+)
+
+// secretID can be the name or the ARN of the secret; binary secrets are returned as-is
+func (c *Client) GetSecretValue(secretID string) (string, error) {
+	output, err := c.SecretsManager().GetSecretValue(&secretsmanager.GetSecretValueInput{
+		SecretId: aws.String(secretID),
+	})
+	if err != nil {
+		return "", errors.Wrap(err, "failed to get secret from secrets manager", secretID)
+	}
+
+	if output.SecretString != nil {
+		return *output.SecretString, nil
+	}
+	return string(output.SecretBinary), nil
+}
+
+// SecureString parameters are decrypted
+func (c *Client) GetSSMParameterValue(name string) (string, error) {
+	output, err := c.SSM().GetParameter(&ssm.GetParameterInput{
+		Name:           aws.String(name),
+		WithDecryption: aws.Bool(true),
+	})
+	if err != nil {
+		return "", errors.Wrap(err, "failed to get parameter from ssm parameter store", name)
+	}
+
+	return *output.Parameter.Value, nil
+}
+
+func (c *Client) PutSSMSecureStringParameter(name string, value string) error {
+	_, err := c.SSM().PutParameter(&ssm.PutParameterInput{
+		Name:      aws.String(name),
+		Value:     aws.String(value),
+		Type:      aws.String(ssm.ParameterTypeSecureString),
+		Overwrite: aws.Bool(true),
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to put parameter in ssm parameter store", name)
+	}
+
+	return nil
+}
+
+func (c *Client) DeleteSSMParameter(name string) error {
+	_, err := c.SSM().DeleteParameter(&ssm.DeleteParameterInput{
+		Name: aws.String(name),
+	})
+	if err != nil && !IsErrCode(err, ssm.ErrCodeParameterNotFound) {
+		return errors.Wrap(err, "failed to delete parameter from ssm parameter store", name)
+	}
+
+	return nil
+}
@@ -27,7 +27,7 @@ import (
 )
 
 var _standardInstanceFamilies = strset.New("a", "c", "d", "h", "i", "m", "r", "t", "z")
-var _knownInstanceFamilies = strset.Union(_standardInstanceFamilies, strset.New("p", "g", "inf", "x", "f", "mac"))
+var _knownInstanceFamilies = strset.Union(_standardInstanceFamilies, strset.New("p", "g", "inf", "trn", "x", "f", "mac"))
 
 const (
 	_elasticIPsQuotaCode         = "L-0263D0A3"
@@ -64,6 +64,17 @@ func (c *Client) DoesQueueExist(queueName string) (bool, error) {
 	return true, nil
 }
 
+func (c *Client) DeleteQueue(queueURL string) error {
+	_, err := c.SQS().DeleteQueue(&sqs.DeleteQueueInput{
+		QueueUrl: aws.String(queueURL),
+	})
+	if err != nil {
+		return errors.Wrap(err, queueURL)
+	}
+
+	return nil
+}
+
 func (c *Client) DeleteQueuesWithPrefix(queueNamePrefix string) (int, error) {
 	var numDeleted int
 	var deleteError error
@@ -0,0 +1,71 @@
+/*
+Copyright 2021 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package aws
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ssm"
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+)
+
+// IsSSMManagedInstance checks whether the instance's SSM agent is registered with systems manager (i.e. sessions can be started on it)
+func (c *Client) IsSSMManagedInstance(instanceID string) (bool, error) {
+	output, err := c.SSM().DescribeInstanceInformation(&ssm.DescribeInstanceInformationInput{
+		Filters: []*ssm.InstanceInformationStringFilter{
+			{
+				Key:    aws.String("InstanceIds"),
+				Values: aws.StringSlice([]string{instanceID}),
+			},
+		},
+	})
+	if err != nil {
+		return false, errors.Wrap(err, "failed to describe the systems manager information of instance", instanceID)
+	}
+
+	for _, info := range output.InstanceInformationList {
+		if info != nil && aws.StringValue(info.PingStatus) == ssm.PingStatusOnline {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// StartSSMSession starts a session manager session on the instance; the session's stream is opened by the session manager plugin
+func (c *Client) StartSSMSession(instanceID string) (*ssm.StartSessionOutput, error) {
+	output, err := c.SSM().StartSession(&ssm.StartSessionInput{
+		Target: aws.String(instanceID),
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to start a session manager session on instance", instanceID)
+	}
+	return output, nil
+}
+
+func (c *Client) TerminateSSMSession(sessionID string) error {
+	_, err := c.SSM().TerminateSession(&ssm.TerminateSessionInput{
+		SessionId: aws.String(sessionID),
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to terminate session manager session", sessionID)
+	}
+	return nil
+}
+
+// SSMEndpoint is the endpoint of the systems manager api in the client's region
+func (c *Client) SSMEndpoint() string {
+	return c.SSM().Endpoint
+}
@@ -38,6 +38,12 @@ import (
 // Returns account ID, whether the credentials were valid, any other error that occurred
 // Ignores cache, so will re-run on every call to this method
 func (c *Client) CheckCredentials() (string, string, error) {
+	if IsUsingLocalEndpoints() {
+		c.accountID = pointer.String(LocalAccountID)
+		c.hashedAccountID = pointer.String(hash.String(LocalAccountID))
+		return *c.accountID, *c.hashedAccountID, nil
+	}
+
 	response, err := c.STS().GetCallerIdentity(nil)
 	if err != nil {
 		return "", "", ErrorInvalidAWSCredentials(err)
@@ -97,24 +103,24 @@ func (c *Client) IdentityRequestAsHeader() (string, error) {
 	return base64.RawURLEncoding.EncodeToString(jsonSignedRequestArtifacts), nil
 }
 
-// ExecuteIdentityRequestFromHeader executes identity request marshalled from header and returns account id if successful
-func ExecuteIdentityRequestFromHeader(indentityRequestheader string) (string, error) {
+// ExecuteIdentityRequestFromHeader executes identity request marshalled from header and returns the caller's account id and arn if successful
+func ExecuteIdentityRequestFromHeader(indentityRequestheader string) (string, string, error) {
 	jsonObj, err := base64.RawURLEncoding.DecodeString(indentityRequestheader)
 	if err != nil {
-		return "", errors.WithStack(err)
+		return "", "", errors.WithStack(err)
 	}
 
 	signedRequestArtifacts := awsRequest{}
 	err = libjson.Unmarshal(jsonObj, &signedRequestArtifacts)
 	if err != nil {
-		return "", err
+		return "", "", err
 	}
 
 	httpClient := http.Client{}
 
 	url, err := url.Parse(signedRequestArtifacts.URL)
 	if err != nil {
-		return "", errors.WithStack(err)
+		return "", "", errors.WithStack(err)
 	}
 
 	req := http.Request{
@@ -128,14 +134,14 @@ func ExecuteIdentityRequestFromHeader(indentityRequestheader string) (string, er
 
 	resp, err := httpClient.Do(&req)
 	if err != nil {
-		return "", errors.WithStack(err)
+		return "", "", errors.WithStack(err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode >= 400 {
 		awsReq := request.Request{HTTPResponse: resp}
 		query.UnmarshalError(&awsReq)
-		return "", errors.WithStack(awsReq.Error)
+		return "", "", errors.WithStack(awsReq.Error)
 	}
 
 	decoder := xml.NewDecoder(resp.Body)
@@ -143,15 +149,20 @@ func ExecuteIdentityRequestFromHeader(indentityRequestheader string) (string, er
 	result := sts.GetCallerIdentityOutput{}
 	err = xmlutil.UnmarshalXML(&result, decoder, "GetCallerIdentityResult")
 	if err != nil {
-		return "", awserr.NewRequestFailure(
+		return "", "", awserr.NewRequestFailure(
 			awserr.New(request.ErrCodeSerialization, "failed decoding Query response", err),
 			resp.StatusCode,
 			resp.Header.Get("X-Amzn-Requestid"),
 		)
 	}
 	if result.Account == nil {
-		return "", errors.ErrorUnexpected("GetCallerIdentityResult xml parsing failed")
+		return "", "", errors.ErrorUnexpected("GetCallerIdentityResult xml parsing failed")
+	}
+
+	var arn string
+	if result.Arn != nil {
+		arn = *result.Arn
 	}
 
-	return *result.Account, nil
+	return *result.Account, arn, nil
 }
@@ -43,6 +43,11 @@ import (
 	"github.com/docker/docker/pkg/term"
 )
 
+const (
+	DockerHubRegistry    = "docker.io"
+	DockerHubAuthAddress = "https://index.docker.io/v1/"
+)
+
 var NoAuth string
 
 var _cachedClient *Client
@@ -321,6 +326,27 @@ func CheckImageAccessible(dockerClient *Client, dockerImage, authConfig string)
 	return nil
 }
 
+// CheckImageAccessibleForOS checks that the image is accessible and that it is available for the operating system (e.g. "windows"),
+// and returns the image's digest (e.g. sha256:...); images whose platforms aren't reported by the registry are assumed to be available
+func CheckImageAccessibleForOS(dockerClient *Client, dockerImage, authConfig string, imageOS string) (string, error) {
+	inspect, err := dockerClient.DistributionInspect(context.Background(), dockerImage, authConfig)
+	if err != nil {
+		return "", ErrorImageInaccessible(dockerImage, err)
+	}
+	digest := inspect.Descriptor.Digest.String()
+
+	if len(inspect.Platforms) == 0 {
+		return digest, nil
+	}
+	for _, platform := range inspect.Platforms {
+		if platform.OS == imageOS {
+			return digest, nil
+		}
+	}
+
+	return "", ErrorImageNotAvailableForOS(dockerImage, imageOS)
+}
+
 func CheckImageExistsLocally(dockerClient *Client, dockerImage string) error {
 	images, err := dockerClient.ImageList(context.Background(), dockertypes.ImageListOptions{})
 	if err != nil {
@@ -347,3 +373,35 @@ func ExtractImageTag(dockerImage string) string {
 	}
 	return ""
 }
+
+// ExtractImageRegistry returns the registry host of the image (e.g. ghcr.io for ghcr.io/org/image:tag);
+// images without a registry host (e.g. org/image:tag) are pulled from docker hub
+func ExtractImageRegistry(dockerImage string) string {
+	slashIndex := strings.Index(dockerImage, "/")
+	if slashIndex == -1 {
+		return DockerHubRegistry
+	}
+	host := dockerImage[:slashIndex]
+	if !strings.ContainsAny(host, ".:") && host != "localhost" {
+		return DockerHubRegistry
+	}
+	return NormalizeRegistry(host)
+}
+
+// NormalizeRegistry maps the aliases of docker hub to DockerHubRegistry
+func NormalizeRegistry(registry string) string {
+	registry = strings.TrimSuffix(strings.TrimPrefix(strings.TrimPrefix(registry, "https://"), "http://"), "/")
+	switch registry {
+	case "index.docker.io", "registry-1.docker.io", "index.docker.io/v1":
+		return DockerHubRegistry
+	}
+	return registry
+}
+
+// RegistryAuthAddress returns the address which the registry's credentials are stored under in docker config files
+func RegistryAuthAddress(registry string) string {
+	if NormalizeRegistry(registry) == DockerHubRegistry {
+		return DockerHubAuthAddress
+	}
+	return registry
+}
@@ -0,0 +1,38 @@
+/*
+Copyright 2021 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package docker
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestExtractImageRegistry(t *testing.T) {
+	require.Equal(t, DockerHubRegistry, ExtractImageRegistry("ubuntu"))
+	require.Equal(t, DockerHubRegistry, ExtractImageRegistry("org/image:0.1.0"))
+	require.Equal(t, DockerHubRegistry, ExtractImageRegistry("index.docker.io/org/image"))
+	require.Equal(t, "ghcr.io", ExtractImageRegistry("ghcr.io/org/image:latest"))
+	require.Equal(t, "localhost:5000", ExtractImageRegistry("localhost:5000/image"))
+	require.Equal(t, "123456789012.dkr.ecr.us-west-2.amazonaws.com", ExtractImageRegistry("123456789012.dkr.ecr.us-west-2.amazonaws.com/image:latest"))
+}
+
+func TestRegistryAuthAddress(t *testing.T) {
+	require.Equal(t, DockerHubAuthAddress, RegistryAuthAddress("docker.io"))
+	require.Equal(t, DockerHubAuthAddress, RegistryAuthAddress("https://index.docker.io/v1/"))
+	require.Equal(t, "ghcr.io", RegistryAuthAddress("ghcr.io"))
+}
@@ -30,6 +30,7 @@ const (
 	ErrDockerPermissions       = "docker.docker_permissions"
 	ErrImageDoesntExistLocally = "docker.image_doesnt_exist_locally"
 	ErrImageInaccessible       = "docker.image_inaccessible"
+	ErrImageNotAvailableForOS  = "docker.image_not_available_for_os"
 )
 
 func ErrorConnectToDockerDaemon() error {
@@ -82,3 +83,10 @@ func ErrorImageInaccessible(image string, cause error) error {
 		Cause:   cause,
 	})
 }
+
+func ErrorImageNotAvailableForOS(image string, imageOS string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrImageNotAvailableForOS,
+		Message: fmt.Sprintf("%s is not available for %s; build the image for %s (or push a multi-platform image which includes %s)", image, imageOS, imageOS, imageOS),
+	})
+}
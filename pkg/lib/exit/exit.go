@@ -23,11 +23,34 @@ import (
 	"github.com/cortexlabs/cortex/pkg/lib/telemetry"
 )
 
+var (
+	_printError = printErrorForUser
+	_errorCode  = func(err error) int { return 1 }
+)
+
+// SetErrorHandlers overrides how Error() reports errors, and which status code it exits with (e.g. so that the cli can exit with a different code for each category of error)
+func SetErrorHandlers(printError func(err error), errorCode func(err error) int) {
+	_printError = printError
+	_errorCode = errorCode
+}
+
+func printErrorForUser(err error) {
+	if !errors.IsNoPrint(err) {
+		errors.PrintErrorForUser(err)
+	}
+}
+
 func Ok() {
 	telemetry.Close()
 	os.Exit(0)
 }
 
+// Code exits with the given status code (e.g. to signal a result other than success or failure to scripts)
+func Code(code int) {
+	telemetry.Close()
+	os.Exit(code)
+}
+
 func Error(err error, wrapStrs ...string) {
 	for _, str := range wrapStrs {
 		err = errors.Wrap(err, str)
@@ -37,13 +60,13 @@ func Error(err error, wrapStrs ...string) {
 		telemetry.Error(err)
 	}
 
-	if err != nil && !errors.IsNoPrint(err) {
-		errors.PrintErrorForUser(err)
+	if err != nil {
+		_printError(err)
 	}
 
 	telemetry.Close()
 
-	os.Exit(1)
+	os.Exit(_errorCode(err))
 }
 
 func Panic(err error, wrapStrs ...string) {
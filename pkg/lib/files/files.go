@@ -170,6 +170,25 @@ func WriteFile(data []byte, path string) error {
 	return nil
 }
 
+// WritePrivateFile writes the file so that it can only be read by its owner (e.g. because it contains credentials);
+// the permissions of an existing file are also updated, since ioutil.WriteFile() only sets them when the file is created
+func WritePrivateFile(data []byte, path string) error {
+	cleanPath, err := EscapeTilde(path)
+	if err != nil {
+		return err
+	}
+
+	if err := ioutil.WriteFile(cleanPath, data, 0600); err != nil {
+		return errors.Wrap(err, errors.Message(ErrorCreateFile(path)))
+	}
+
+	if err := os.Chmod(cleanPath, 0600); err != nil {
+		return errors.Wrap(err, errors.Message(ErrorCreateFile(path)))
+	}
+
+	return nil
+}
+
 func IsAbsOrTildePrefixed(path string) bool {
 	return strings.HasPrefix(path, "/") || strings.HasPrefix(path, "~/")
 }
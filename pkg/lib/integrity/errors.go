@@ -0,0 +1,50 @@
+/*
+Copyright 2021 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package integrity
+
+import (
+	"fmt"
+
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+)
+
+const (
+	ErrChecksumMismatch = "integrity.checksum_mismatch"
+	ErrInvalidSignature = "integrity.invalid_signature"
+	ErrInvalidPublicKey = "integrity.invalid_public_key"
+)
+
+func ErrorChecksumMismatch(expected string, actual string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrChecksumMismatch,
+		Message: fmt.Sprintf("the artifacts' sha256 checksum is %s, but %s was expected", actual, expected),
+	})
+}
+
+func ErrorInvalidSignature() error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrInvalidSignature,
+		Message: "the artifacts' signature is not valid for the public key",
+	})
+}
+
+func ErrorInvalidPublicKey(reason string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrInvalidPublicKey,
+		Message: fmt.Sprintf("invalid public key: %s", reason),
+	})
+}
@@ -0,0 +1,182 @@
+/*
+Copyright 2021 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package integrity
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+)
+
+// the termination messages of the verifier, which are used to report the verification status of the replicas
+const (
+	VerifiedMessagePrefix = "verified sha256:"
+	FailedMessagePrefix   = "verification failed: "
+)
+
+// Spec is the expected checksum and/or signature of a directory of artifacts
+type Spec struct {
+	SHA256    string `json:"sha256,omitempty"`
+	Signature string `json:"signature,omitempty"`  // base64-encoded signature of the directory's manifest
+	PublicKey string `json:"public_key,omitempty"` // PEM-encoded public key (ECDSA, RSA, or Ed25519)
+}
+
+func (spec Spec) IsEmpty() bool {
+	return spec.SHA256 == "" && spec.Signature == ""
+}
+
+// Manifest lists the sha256 checksum and relative path of each file in the directory, in the format of `sha256sum` (sorted by path);
+// it is the same as the output of `find . -type f -print0 | LC_ALL=C sort -z | xargs -0 sha256sum` when run in the directory.
+// Files whose names are in excludedNames (e.g. lock files) are omitted
+func Manifest(dir string, excludedNames ...string) ([]byte, error) {
+	var paths []string
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		for _, excludedName := range excludedNames {
+			if info.Name() == excludedName {
+				return nil
+			}
+		}
+		relPath, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		paths = append(paths, "./"+filepath.ToSlash(relPath))
+		return nil
+	})
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	sort.Strings(paths)
+
+	var manifest strings.Builder
+	for _, path := range paths {
+		checksum, err := fileSHA256(filepath.Join(dir, path))
+		if err != nil {
+			return nil, err
+		}
+		manifest.WriteString(fmt.Sprintf("%s  %s\n", checksum, path))
+	}
+
+	return []byte(manifest.String()), nil
+}
+
+func fileSHA256(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", errors.WithStack(err)
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// VerifyDir checks the directory against the spec, and returns the directory's checksum (the sha256 checksum of its manifest)
+func VerifyDir(dir string, spec Spec, excludedNames ...string) (string, error) {
+	manifest, err := Manifest(dir, excludedNames...)
+	if err != nil {
+		return "", err
+	}
+
+	checksumBytes := sha256.Sum256(manifest)
+	checksum := hex.EncodeToString(checksumBytes[:])
+
+	if spec.SHA256 != "" && !strings.EqualFold(spec.SHA256, checksum) {
+		return "", ErrorChecksumMismatch(spec.SHA256, checksum)
+	}
+
+	if spec.Signature != "" {
+		if err := VerifySignature(spec.PublicKey, manifest, spec.Signature); err != nil {
+			return "", err
+		}
+	}
+
+	return checksum, nil
+}
+
+// ParsePublicKey parses a PEM-encoded PKIX public key (e.g. the output of `openssl pkey -pubout`)
+func ParsePublicKey(publicKeyPEM string) (crypto.PublicKey, error) {
+	block, _ := pem.Decode([]byte(publicKeyPEM))
+	if block == nil {
+		return nil, ErrorInvalidPublicKey("the key is not PEM-encoded")
+	}
+
+	publicKey, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, ErrorInvalidPublicKey(err.Error())
+	}
+
+	switch publicKey.(type) {
+	case *ecdsa.PublicKey, *rsa.PublicKey, ed25519.PublicKey:
+		return publicKey, nil
+	}
+	return nil, ErrorInvalidPublicKey(fmt.Sprintf("unsupported key type %T (ECDSA, RSA, and Ed25519 keys are supported)", publicKey))
+}
+
+// VerifySignature verifies a base64-encoded signature of the data, e.g. the output of `openssl dgst -sha256 -sign key.pem data | base64`
+// for ECDSA and RSA keys (which sign the data's sha256 digest), or `openssl pkeyutl -sign -rawin -inkey key.pem -in data | base64` for Ed25519 keys
+func VerifySignature(publicKeyPEM string, data []byte, signatureB64 string) error {
+	publicKey, err := ParsePublicKey(publicKeyPEM)
+	if err != nil {
+		return err
+	}
+
+	signature, err := base64.StdEncoding.DecodeString(strings.TrimSpace(signatureB64))
+	if err != nil {
+		return ErrorInvalidSignature()
+	}
+
+	digest := sha256.Sum256(data)
+
+	var valid bool
+	switch key := publicKey.(type) {
+	case *ecdsa.PublicKey:
+		valid = ecdsa.VerifyASN1(key, digest[:], signature)
+	case *rsa.PublicKey:
+		valid = rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], signature) == nil
+	case ed25519.PublicKey:
+		valid = ed25519.Verify(key, data, signature)
+	}
+
+	if !valid {
+		return ErrorInvalidSignature()
+	}
+	return nil
+}
@@ -0,0 +1,131 @@
+/*
+Copyright 2021 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package integrity
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func writeArtifacts(t *testing.T) string {
+	t.Helper()
+
+	dir, err := ioutil.TempDir("", "integrity")
+	require.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "weights"), 0755))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "config.json"), []byte(`{"layers": 2}`), 0644))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "weights", "layer-1.bin"), []byte("abc"), 0644))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, ".cortex-lock"), nil, 0644))
+
+	return dir
+}
+
+func publicKeyPEM(t *testing.T, publicKey crypto.PublicKey) string {
+	t.Helper()
+
+	der, err := x509.MarshalPKIXPublicKey(publicKey)
+	require.NoError(t, err)
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+}
+
+func TestManifest(t *testing.T) {
+	dir := writeArtifacts(t)
+
+	manifest, err := Manifest(dir, ".cortex-lock")
+	require.NoError(t, err)
+
+	// sha256("abc") and sha256(`{"layers": 2}`), in the format of sha256sum
+	configChecksum := sha256.Sum256([]byte(`{"layers": 2}`))
+	expected := hex.EncodeToString(configChecksum[:]) + "  ./config.json\n" +
+		"ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad  ./weights/layer-1.bin\n"
+	require.Equal(t, expected, string(manifest))
+}
+
+func TestVerifyDirChecksum(t *testing.T) {
+	dir := writeArtifacts(t)
+
+	manifest, err := Manifest(dir, ".cortex-lock")
+	require.NoError(t, err)
+	checksumBytes := sha256.Sum256(manifest)
+	checksum := hex.EncodeToString(checksumBytes[:])
+
+	verifiedChecksum, err := VerifyDir(dir, Spec{SHA256: checksum}, ".cortex-lock")
+	require.NoError(t, err)
+	require.Equal(t, checksum, verifiedChecksum)
+
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "weights", "layer-1.bin"), []byte("abd"), 0644))
+	_, err = VerifyDir(dir, Spec{SHA256: checksum}, ".cortex-lock")
+	require.Error(t, err)
+}
+
+func TestVerifySignature(t *testing.T) {
+	data := []byte("ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad  ./model.bin\n")
+	digest := sha256.Sum256(data)
+
+	ecdsaKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	ecdsaSignature, err := ecdsa.SignASN1(rand.Reader, ecdsaKey, digest[:])
+	require.NoError(t, err)
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	rsaSignature, err := rsa.SignPKCS1v15(rand.Reader, rsaKey, crypto.SHA256, digest[:])
+	require.NoError(t, err)
+
+	ed25519PublicKey, ed25519PrivateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	ed25519Signature := ed25519.Sign(ed25519PrivateKey, data)
+
+	for name, tc := range map[string]struct {
+		publicKey crypto.PublicKey
+		signature []byte
+	}{
+		"ecdsa":   {&ecdsaKey.PublicKey, ecdsaSignature},
+		"rsa":     {&rsaKey.PublicKey, rsaSignature},
+		"ed25519": {ed25519PublicKey, ed25519Signature},
+	} {
+		t.Run(name, func(t *testing.T) {
+			publicKey := publicKeyPEM(t, tc.publicKey)
+			signature := base64.StdEncoding.EncodeToString(tc.signature)
+
+			require.NoError(t, VerifySignature(publicKey, data, signature))
+			require.Error(t, VerifySignature(publicKey, append(data, '\n'), signature))
+		})
+	}
+}
+
+func TestParsePublicKey(t *testing.T) {
+	_, err := ParsePublicKey("not a key")
+	require.Error(t, err)
+}
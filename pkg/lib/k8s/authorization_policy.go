@@ -0,0 +1,133 @@
+/*
+Copyright 2021 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package k8s
+
+import (
+	"context"
+
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	istiosecurity "istio.io/api/security/v1beta1"
+	istiotype "istio.io/api/type/v1beta1"
+	istioclientsecurity "istio.io/client-go/pkg/apis/security/v1beta1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	kmeta "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _authorizationPolicyTypeMeta = kmeta.TypeMeta{
+	APIVersion: "security.istio.io/v1beta1",
+	Kind:       "AuthorizationPolicy",
+}
+
+// IPWhiteListPolicySpec denies the requests to the paths (on the pods which match the workload selector)
+// which don't originate from the white-listed ip blocks (authorization policies which apply to gateways must be in istio's namespace)
+type IPWhiteListPolicySpec struct {
+	Name             string
+	WorkloadSelector map[string]string
+	Paths            []string // exact paths, or prefixes which end with "*"
+	IPBlocks         []string // CIDR blocks or ip addresses
+	Labels           map[string]string
+	Annotations      map[string]string
+}
+
+func IPWhiteListPolicy(spec *IPWhiteListPolicySpec) *istioclientsecurity.AuthorizationPolicy {
+	return &istioclientsecurity.AuthorizationPolicy{
+		TypeMeta: _authorizationPolicyTypeMeta,
+		ObjectMeta: kmeta.ObjectMeta{
+			Name:        spec.Name,
+			Labels:      spec.Labels,
+			Annotations: spec.Annotations,
+		},
+		Spec: istiosecurity.AuthorizationPolicy{
+			Selector: &istiotype.WorkloadSelector{
+				MatchLabels: spec.WorkloadSelector,
+			},
+			Action: istiosecurity.AuthorizationPolicy_DENY,
+			Rules: []*istiosecurity.Rule{
+				{
+					From: []*istiosecurity.Rule_From{
+						{
+							Source: &istiosecurity.Source{
+								NotIpBlocks: spec.IPBlocks,
+							},
+						},
+					},
+					To: []*istiosecurity.Rule_To{
+						{
+							Operation: &istiosecurity.Operation{
+								Paths: spec.Paths,
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func (c *Client) CreateAuthorizationPolicy(authorizationPolicy *istioclientsecurity.AuthorizationPolicy) (*istioclientsecurity.AuthorizationPolicy, error) {
+	authorizationPolicy.TypeMeta = _authorizationPolicyTypeMeta
+	authorizationPolicy, err := c.authzPolicyClient.Create(context.Background(), authorizationPolicy, kmeta.CreateOptions{})
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return authorizationPolicy, nil
+}
+
+func (c *Client) UpdateAuthorizationPolicy(existing, updated *istioclientsecurity.AuthorizationPolicy) (*istioclientsecurity.AuthorizationPolicy, error) {
+	updated.TypeMeta = _authorizationPolicyTypeMeta
+	updated.ResourceVersion = existing.ResourceVersion
+
+	authorizationPolicy, err := c.authzPolicyClient.Update(context.Background(), updated, kmeta.UpdateOptions{})
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return authorizationPolicy, nil
+}
+
+func (c *Client) ApplyAuthorizationPolicy(authorizationPolicy *istioclientsecurity.AuthorizationPolicy) (*istioclientsecurity.AuthorizationPolicy, error) {
+	existing, err := c.GetAuthorizationPolicy(authorizationPolicy.Name)
+	if err != nil {
+		return nil, err
+	}
+	if existing == nil {
+		return c.CreateAuthorizationPolicy(authorizationPolicy)
+	}
+	return c.UpdateAuthorizationPolicy(existing, authorizationPolicy)
+}
+
+func (c *Client) GetAuthorizationPolicy(name string) (*istioclientsecurity.AuthorizationPolicy, error) {
+	authorizationPolicy, err := c.authzPolicyClient.Get(context.Background(), name, kmeta.GetOptions{})
+	if err != nil {
+		if kerrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, errors.WithStack(err)
+	}
+	authorizationPolicy.TypeMeta = _authorizationPolicyTypeMeta
+	return authorizationPolicy, nil
+}
+
+func (c *Client) DeleteAuthorizationPolicy(name string) (bool, error) {
+	err := c.authzPolicyClient.Delete(context.Background(), name, _deleteOpts)
+	if err != nil {
+		if kerrors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, errors.WithStack(err)
+	}
+	return true, nil
+}
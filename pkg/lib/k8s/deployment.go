@@ -200,6 +200,23 @@ func DeploymentStartTime(deployment *kapps.Deployment) *time.Time {
 	return &deployment.CreationTimestamp.Time
 }
 
+// IsDeploymentRolledOut returns true once all of the deployment's replicas have been updated and are available,
+// and all of the replicas from previous rollouts have been terminated (similar to `kubectl rollout status`)
+func IsDeploymentRolledOut(deployment *kapps.Deployment) bool {
+	if deployment.Status.ObservedGeneration < deployment.Generation {
+		return false
+	}
+
+	replicas := int32(1)
+	if deployment.Spec.Replicas != nil {
+		replicas = *deployment.Spec.Replicas
+	}
+
+	return deployment.Status.UpdatedReplicas >= replicas &&
+		deployment.Status.Replicas == deployment.Status.UpdatedReplicas &&
+		deployment.Status.AvailableReplicas >= deployment.Status.UpdatedReplicas
+}
+
 func DeploymentStrategiesMatch(s1, s2 kapps.DeploymentStrategy) bool {
 	if s1.Type != s2.Type {
 		return false
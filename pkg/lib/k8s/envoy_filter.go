@@ -0,0 +1,271 @@
+/*
+Copyright 2021 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package k8s
+
+import (
+	"context"
+	"math"
+	"strings"
+
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/gogo/protobuf/types"
+	istionetworking "istio.io/api/networking/v1alpha3"
+	istioclientnetworking "istio.io/client-go/pkg/apis/networking/v1alpha3"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	kmeta "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _envoyFilterTypeMeta = kmeta.TypeMeta{
+	APIVersion: "v1alpha3",
+	Kind:       "EnvoyFilter",
+}
+
+// RouteEnvoyFilterSpec merges a patch into the named routes of the gateway pods which match the workload selector
+// (envoy filters which apply to gateways must be in istio's namespace)
+type RouteEnvoyFilterSpec struct {
+	Name             string
+	WorkloadSelector map[string]string
+	RouteName        string
+	RoutePatch       map[string]interface{} // e.g. {"route": {"hedge_policy": {"hedge_on_per_try_timeout": true}}}
+	Labels           map[string]string
+	Annotations      map[string]string
+}
+
+func RouteEnvoyFilter(spec *RouteEnvoyFilterSpec) *istioclientnetworking.EnvoyFilter {
+	return &istioclientnetworking.EnvoyFilter{
+		TypeMeta: _envoyFilterTypeMeta,
+		ObjectMeta: kmeta.ObjectMeta{
+			Name:        spec.Name,
+			Labels:      spec.Labels,
+			Annotations: spec.Annotations,
+		},
+		Spec: istionetworking.EnvoyFilter{
+			WorkloadSelector: &istionetworking.WorkloadSelector{
+				Labels: spec.WorkloadSelector,
+			},
+			ConfigPatches: []*istionetworking.EnvoyFilter_EnvoyConfigObjectPatch{
+				{
+					ApplyTo: istionetworking.EnvoyFilter_HTTP_ROUTE,
+					Match: &istionetworking.EnvoyFilter_EnvoyConfigObjectMatch{
+						Context: istionetworking.EnvoyFilter_GATEWAY,
+						ObjectTypes: &istionetworking.EnvoyFilter_EnvoyConfigObjectMatch_RouteConfiguration{
+							RouteConfiguration: &istionetworking.EnvoyFilter_RouteConfigurationMatch{
+								Vhost: &istionetworking.EnvoyFilter_RouteConfigurationMatch_VirtualHostMatch{
+									Route: &istionetworking.EnvoyFilter_RouteConfigurationMatch_RouteMatch{
+										Name: spec.RouteName,
+									},
+								},
+							},
+						},
+					},
+					Patch: &istionetworking.EnvoyFilter_Patch{
+						Operation: istionetworking.EnvoyFilter_Patch_MERGE,
+						Value:     structProto(spec.RoutePatch),
+					},
+				},
+			},
+		},
+	}
+}
+
+// AccessLogEnvoyFilterSpec adds an access log to the http connection manager of the gateway pods which match the workload selector,
+// which writes a sample of the requests to the paths to the gateway's stdout as JSON lines
+type AccessLogEnvoyFilterSpec struct {
+	Name             string
+	WorkloadSelector map[string]string
+	Paths            []string               // exact paths, or prefixes which end with "*" (all paths are logged if empty)
+	SampleRate       float64                // the fraction of requests which are logged
+	Fields           map[string]interface{} // the fields of each line, e.g. {"status_code": "%RESPONSE_CODE%"} (see https://www.envoyproxy.io/docs/envoy/latest/configuration/observability/access_log/usage)
+	Labels           map[string]string
+	Annotations      map[string]string
+}
+
+func AccessLogEnvoyFilter(spec *AccessLogEnvoyFilterSpec) *istioclientnetworking.EnvoyFilter {
+	var filters []interface{}
+
+	if len(spec.Paths) > 0 {
+		var pathFilters []interface{}
+		for _, path := range spec.Paths {
+			header := map[string]interface{}{"name": ":path"}
+			if strings.HasSuffix(path, "*") {
+				header["prefix_match"] = strings.TrimSuffix(path, "*")
+			} else {
+				header["exact_match"] = path
+			}
+			pathFilters = append(pathFilters, map[string]interface{}{
+				"header_filter": map[string]interface{}{"header": header},
+			})
+		}
+		filters = append(filters, map[string]interface{}{
+			"or_filter": map[string]interface{}{"filters": pathFilters},
+		})
+	}
+
+	if spec.SampleRate < 1 {
+		// the runtime key isn't set, so the default percentage is always used
+		filters = append(filters, map[string]interface{}{
+			"runtime_filter": map[string]interface{}{
+				"runtime_key": "access_log." + spec.Name,
+				"percent_sampled": map[string]interface{}{
+					"numerator":   int(math.Round(spec.SampleRate * 1000000)),
+					"denominator": "MILLION",
+				},
+			},
+		})
+	}
+
+	accessLog := map[string]interface{}{
+		"name": "envoy.access_loggers.file",
+		"typed_config": map[string]interface{}{
+			"@type":       "type.googleapis.com/envoy.extensions.access_loggers.file.v3.FileAccessLog",
+			"path":        "/dev/stdout",
+			"json_format": spec.Fields,
+		},
+	}
+	if len(filters) > 0 {
+		accessLog["filter"] = map[string]interface{}{
+			"and_filter": map[string]interface{}{"filters": filters},
+		}
+	}
+
+	return &istioclientnetworking.EnvoyFilter{
+		TypeMeta: _envoyFilterTypeMeta,
+		ObjectMeta: kmeta.ObjectMeta{
+			Name:        spec.Name,
+			Labels:      spec.Labels,
+			Annotations: spec.Annotations,
+		},
+		Spec: istionetworking.EnvoyFilter{
+			WorkloadSelector: &istionetworking.WorkloadSelector{
+				Labels: spec.WorkloadSelector,
+			},
+			ConfigPatches: []*istionetworking.EnvoyFilter_EnvoyConfigObjectPatch{
+				{
+					ApplyTo: istionetworking.EnvoyFilter_NETWORK_FILTER,
+					Match: &istionetworking.EnvoyFilter_EnvoyConfigObjectMatch{
+						Context: istionetworking.EnvoyFilter_GATEWAY,
+						ObjectTypes: &istionetworking.EnvoyFilter_EnvoyConfigObjectMatch_Listener{
+							Listener: &istionetworking.EnvoyFilter_ListenerMatch{
+								FilterChain: &istionetworking.EnvoyFilter_ListenerMatch_FilterChainMatch{
+									Filter: &istionetworking.EnvoyFilter_ListenerMatch_FilterMatch{
+										Name: "envoy.filters.network.http_connection_manager",
+									},
+								},
+							},
+						},
+					},
+					// the access logs of the envoy filters which are merged into the same http connection manager are appended to each other
+					Patch: &istionetworking.EnvoyFilter_Patch{
+						Operation: istionetworking.EnvoyFilter_Patch_MERGE,
+						Value: structProto(map[string]interface{}{
+							"typed_config": map[string]interface{}{
+								"@type":      "type.googleapis.com/envoy.extensions.filters.network.http_connection_manager.v3.HttpConnectionManager",
+								"access_log": []interface{}{accessLog},
+							},
+						}),
+					},
+				},
+			},
+		},
+	}
+}
+
+// converts a json-like map (with string, bool, float64, int, list, and map values) to a protobuf struct
+func structProto(fields map[string]interface{}) *types.Struct {
+	protoFields := make(map[string]*types.Value, len(fields))
+	for key, value := range fields {
+		protoFields[key] = valueProto(value)
+	}
+	return &types.Struct{Fields: protoFields}
+}
+
+func valueProto(value interface{}) *types.Value {
+	switch v := value.(type) {
+	case string:
+		return &types.Value{Kind: &types.Value_StringValue{StringValue: v}}
+	case bool:
+		return &types.Value{Kind: &types.Value_BoolValue{BoolValue: v}}
+	case float64:
+		return &types.Value{Kind: &types.Value_NumberValue{NumberValue: v}}
+	case int:
+		return &types.Value{Kind: &types.Value_NumberValue{NumberValue: float64(v)}}
+	case map[string]interface{}:
+		return &types.Value{Kind: &types.Value_StructValue{StructValue: structProto(v)}}
+	case []interface{}:
+		values := make([]*types.Value, len(v))
+		for i, item := range v {
+			values[i] = valueProto(item)
+		}
+		return &types.Value{Kind: &types.Value_ListValue{ListValue: &types.ListValue{Values: values}}}
+	default:
+		return &types.Value{Kind: &types.Value_NullValue{}}
+	}
+}
+
+func (c *Client) CreateEnvoyFilter(envoyFilter *istioclientnetworking.EnvoyFilter) (*istioclientnetworking.EnvoyFilter, error) {
+	envoyFilter.TypeMeta = _envoyFilterTypeMeta
+	envoyFilter, err := c.envoyFilterClient.Create(context.Background(), envoyFilter, kmeta.CreateOptions{})
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return envoyFilter, nil
+}
+
+func (c *Client) UpdateEnvoyFilter(existing, updated *istioclientnetworking.EnvoyFilter) (*istioclientnetworking.EnvoyFilter, error) {
+	updated.TypeMeta = _envoyFilterTypeMeta
+	updated.ResourceVersion = existing.ResourceVersion
+
+	envoyFilter, err := c.envoyFilterClient.Update(context.Background(), updated, kmeta.UpdateOptions{})
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return envoyFilter, nil
+}
+
+func (c *Client) ApplyEnvoyFilter(envoyFilter *istioclientnetworking.EnvoyFilter) (*istioclientnetworking.EnvoyFilter, error) {
+	existing, err := c.GetEnvoyFilter(envoyFilter.Name)
+	if err != nil {
+		return nil, err
+	}
+	if existing == nil {
+		return c.CreateEnvoyFilter(envoyFilter)
+	}
+	return c.UpdateEnvoyFilter(existing, envoyFilter)
+}
+
+func (c *Client) GetEnvoyFilter(name string) (*istioclientnetworking.EnvoyFilter, error) {
+	envoyFilter, err := c.envoyFilterClient.Get(context.Background(), name, kmeta.GetOptions{})
+	if err != nil {
+		if kerrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, errors.WithStack(err)
+	}
+	envoyFilter.TypeMeta = _envoyFilterTypeMeta
+	return envoyFilter, nil
+}
+
+func (c *Client) DeleteEnvoyFilter(name string) (bool, error) {
+	err := c.envoyFilterClient.Delete(context.Background(), name, _deleteOpts)
+	if err != nil {
+		if kerrors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, errors.WithStack(err)
+	}
+	return true, nil
+}
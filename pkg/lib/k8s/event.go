@@ -0,0 +1,51 @@
+/*
+Copyright 2021 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package k8s
+
+import (
+	"context"
+	"time"
+
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	kcore "k8s.io/api/core/v1"
+	kmeta "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func (c *Client) ListEvents(opts *kmeta.ListOptions) ([]kcore.Event, error) {
+	if opts == nil {
+		opts = &kmeta.ListOptions{}
+	}
+	eventList, err := c.eventClient.List(context.Background(), *opts)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return eventList.Items, nil
+}
+
+// EventTime returns the last time that the event occurred (events which are reported by newer components only set the event time)
+func EventTime(event *kcore.Event) time.Time {
+	if !event.LastTimestamp.IsZero() {
+		return event.LastTimestamp.Time
+	}
+	if !event.EventTime.IsZero() {
+		return event.EventTime.Time
+	}
+	if !event.FirstTimestamp.IsZero() {
+		return event.FirstTimestamp.Time
+	}
+	return event.CreationTimestamp.Time
+}
@@ -24,7 +24,9 @@ import (
 	"github.com/cortexlabs/cortex/pkg/lib/errors"
 	"github.com/cortexlabs/cortex/pkg/lib/random"
 	istioclient "istio.io/client-go/pkg/clientset/versioned"
+	istionetworkingclientv1alpha3 "istio.io/client-go/pkg/clientset/versioned/typed/networking/v1alpha3"
 	istionetworkingclient "istio.io/client-go/pkg/clientset/versioned/typed/networking/v1beta1"
+	istiosecurityclient "istio.io/client-go/pkg/clientset/versioned/typed/security/v1beta1"
 	kresource "k8s.io/apimachinery/pkg/api/resource"
 	kmeta "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -35,6 +37,8 @@ import (
 	kclientbatch "k8s.io/client-go/kubernetes/typed/batch/v1"
 	kclientcore "k8s.io/client-go/kubernetes/typed/core/v1"
 	kclientextensions "k8s.io/client-go/kubernetes/typed/extensions/v1beta1"
+	kclientnetworking "k8s.io/client-go/kubernetes/typed/networking/v1"
+	kclientpolicy "k8s.io/client-go/kubernetes/typed/policy/v1beta1"
 	_ "k8s.io/client-go/plugin/pkg/client/auth/gcp"
 	kclientrest "k8s.io/client-go/rest"
 	kclientcmd "k8s.io/client-go/tools/clientcmd"
@@ -60,11 +64,18 @@ type Client struct {
 	serviceClient        kclientcore.ServiceInterface
 	configMapClient      kclientcore.ConfigMapInterface
 	secretClient         kclientcore.SecretInterface
+	eventClient          kclientcore.EventInterface
+	serviceAccountClient kclientcore.ServiceAccountInterface
 	deploymentClient     kclientapps.DeploymentInterface
+	daemonSetClient      kclientapps.DaemonSetInterface
 	jobClient            kclientbatch.JobInterface
 	ingressClient        kclientextensions.IngressInterface
 	hpaClient            kclientautoscaling.HorizontalPodAutoscalerInterface
+	pdbClient            kclientpolicy.PodDisruptionBudgetInterface
+	networkPolicyClient  kclientnetworking.NetworkPolicyInterface
 	virtualServiceClient istionetworkingclient.VirtualServiceInterface
+	envoyFilterClient    istionetworkingclientv1alpha3.EnvoyFilterInterface
+	authzPolicyClient    istiosecurityclient.AuthorizationPolicyInterface
 	Namespace            string
 }
 
@@ -106,16 +117,23 @@ func New(namespace string, inCluster bool, restConfig *kclientrest.Config, schem
 		return nil, errors.Wrap(err, "kubeconfig")
 	}
 	client.virtualServiceClient = istioClient.NetworkingV1beta1().VirtualServices(namespace)
+	client.envoyFilterClient = istioClient.NetworkingV1alpha3().EnvoyFilters(namespace)
+	client.authzPolicyClient = istioClient.SecurityV1beta1().AuthorizationPolicies(namespace)
 
 	client.podClient = client.clientset.CoreV1().Pods(namespace)
 	client.nodeClient = client.clientset.CoreV1().Nodes()
 	client.serviceClient = client.clientset.CoreV1().Services(namespace)
 	client.configMapClient = client.clientset.CoreV1().ConfigMaps(namespace)
 	client.secretClient = client.clientset.CoreV1().Secrets(namespace)
+	client.eventClient = client.clientset.CoreV1().Events(namespace)
+	client.serviceAccountClient = client.clientset.CoreV1().ServiceAccounts(namespace)
 	client.deploymentClient = client.clientset.AppsV1().Deployments(namespace)
+	client.daemonSetClient = client.clientset.AppsV1().DaemonSets(namespace)
 	client.jobClient = client.clientset.BatchV1().Jobs(namespace)
 	client.ingressClient = client.clientset.ExtensionsV1beta1().Ingresses(namespace)
 	client.hpaClient = client.clientset.AutoscalingV2beta2().HorizontalPodAutoscalers(namespace)
+	client.pdbClient = client.clientset.PolicyV1beta1().PodDisruptionBudgets(namespace)
+	client.networkPolicyClient = client.clientset.NetworkingV1().NetworkPolicies(namespace)
 	return client, nil
 }
 
@@ -0,0 +1,135 @@
+/*
+Copyright 2021 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package k8s
+
+import (
+	"context"
+
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	kmeta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kunstructured "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	kschema "k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// the karpenter client-go types aren't vendored, so provisioners and node templates are unstructured (both are cluster-scoped)
+
+var _karpenterProvisionerGVR = kschema.GroupVersionResource{
+	Group:    "karpenter.sh",
+	Version:  "v1alpha5",
+	Resource: "provisioners",
+}
+
+var _karpenterNodeTemplateGVR = kschema.GroupVersionResource{
+	Group:    "karpenter.k8s.aws",
+	Version:  "v1alpha1",
+	Resource: "awsnodetemplates",
+}
+
+// KarpenterProvisioner builds a karpenter Provisioner resource with the given spec
+func KarpenterProvisioner(name string, labels map[string]string, spec map[string]interface{}) *kunstructured.Unstructured {
+	provisioner := &kunstructured.Unstructured{
+		Object: map[string]interface{}{
+			"spec": spec,
+		},
+	}
+	provisioner.SetAPIVersion(_karpenterProvisionerGVR.GroupVersion().String())
+	provisioner.SetKind("Provisioner")
+	provisioner.SetName(name)
+	provisioner.SetLabels(labels)
+	return provisioner
+}
+
+// KarpenterNodeTemplate builds a karpenter AWSNodeTemplate resource with the given spec
+func KarpenterNodeTemplate(name string, labels map[string]string, spec map[string]interface{}) *kunstructured.Unstructured {
+	nodeTemplate := &kunstructured.Unstructured{
+		Object: map[string]interface{}{
+			"spec": spec,
+		},
+	}
+	nodeTemplate.SetAPIVersion(_karpenterNodeTemplateGVR.GroupVersion().String())
+	nodeTemplate.SetKind("AWSNodeTemplate")
+	nodeTemplate.SetName(name)
+	nodeTemplate.SetLabels(labels)
+	return nodeTemplate
+}
+
+func (c *Client) ApplyKarpenterProvisioner(provisioner *kunstructured.Unstructured) (*kunstructured.Unstructured, error) {
+	return c.applyClusterResource(_karpenterProvisionerGVR, provisioner)
+}
+
+func (c *Client) ListKarpenterProvisionersWithLabelKeys(labelKeys ...string) ([]kunstructured.Unstructured, error) {
+	return c.listClusterResourcesWithLabelKeys(_karpenterProvisionerGVR, labelKeys...)
+}
+
+func (c *Client) DeleteKarpenterProvisioner(name string) (bool, error) {
+	return c.deleteClusterResource(_karpenterProvisionerGVR, name)
+}
+
+func (c *Client) ApplyKarpenterNodeTemplate(nodeTemplate *kunstructured.Unstructured) (*kunstructured.Unstructured, error) {
+	return c.applyClusterResource(_karpenterNodeTemplateGVR, nodeTemplate)
+}
+
+func (c *Client) ListKarpenterNodeTemplatesWithLabelKeys(labelKeys ...string) ([]kunstructured.Unstructured, error) {
+	return c.listClusterResourcesWithLabelKeys(_karpenterNodeTemplateGVR, labelKeys...)
+}
+
+func (c *Client) DeleteKarpenterNodeTemplate(name string) (bool, error) {
+	return c.deleteClusterResource(_karpenterNodeTemplateGVR, name)
+}
+
+func (c *Client) applyClusterResource(gvr kschema.GroupVersionResource, obj *kunstructured.Unstructured) (*kunstructured.Unstructured, error) {
+	existing, err := c.dynamicClient.Resource(gvr).Get(context.Background(), obj.GetName(), kmeta.GetOptions{})
+	if err != nil {
+		if !kerrors.IsNotFound(err) {
+			return nil, errors.WithStack(err)
+		}
+		created, err := c.dynamicClient.Resource(gvr).Create(context.Background(), obj, kmeta.CreateOptions{})
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		return created, nil
+	}
+
+	obj.SetResourceVersion(existing.GetResourceVersion())
+	updated, err := c.dynamicClient.Resource(gvr).Update(context.Background(), obj, kmeta.UpdateOptions{})
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return updated, nil
+}
+
+func (c *Client) listClusterResourcesWithLabelKeys(gvr kschema.GroupVersionResource, labelKeys ...string) ([]kunstructured.Unstructured, error) {
+	list, err := c.dynamicClient.Resource(gvr).List(context.Background(), kmeta.ListOptions{
+		LabelSelector: LabelExistsSelector(labelKeys...),
+	})
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return list.Items, nil
+}
+
+func (c *Client) deleteClusterResource(gvr kschema.GroupVersionResource, name string) (bool, error) {
+	err := c.dynamicClient.Resource(gvr).Delete(context.Background(), name, _deleteOpts)
+	if err != nil {
+		if kerrors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, errors.WithStack(err)
+	}
+	return true, nil
+}
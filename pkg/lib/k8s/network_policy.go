@@ -0,0 +1,146 @@
+/*
+Copyright 2021 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package k8s
+
+import (
+	"context"
+
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	knetworking "k8s.io/api/networking/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	kmeta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	klabels "k8s.io/apimachinery/pkg/labels"
+)
+
+var _networkPolicyTypeMeta = kmeta.TypeMeta{
+	APIVersion: "networking.k8s.io/v1",
+	Kind:       "NetworkPolicy",
+}
+
+type NetworkPolicySpec struct {
+	Name        string
+	PodSelector map[string]string
+	Ingress     []knetworking.NetworkPolicyIngressRule // only used if ingress is in PolicyTypes
+	Egress      []knetworking.NetworkPolicyEgressRule  // only used if egress is in PolicyTypes
+	PolicyTypes []knetworking.PolicyType
+	Labels      map[string]string
+	Annotations map[string]string
+}
+
+func NetworkPolicy(spec *NetworkPolicySpec) *knetworking.NetworkPolicy {
+	networkPolicy := &knetworking.NetworkPolicy{
+		TypeMeta: _networkPolicyTypeMeta,
+		ObjectMeta: kmeta.ObjectMeta{
+			Name:        spec.Name,
+			Labels:      spec.Labels,
+			Annotations: spec.Annotations,
+		},
+		Spec: knetworking.NetworkPolicySpec{
+			PodSelector: kmeta.LabelSelector{
+				MatchLabels: spec.PodSelector,
+			},
+			Ingress:     spec.Ingress,
+			Egress:      spec.Egress,
+			PolicyTypes: spec.PolicyTypes,
+		},
+	}
+	return networkPolicy
+}
+
+func (c *Client) CreateNetworkPolicy(networkPolicy *knetworking.NetworkPolicy) (*knetworking.NetworkPolicy, error) {
+	networkPolicy.TypeMeta = _networkPolicyTypeMeta
+	networkPolicy, err := c.networkPolicyClient.Create(context.Background(), networkPolicy, kmeta.CreateOptions{})
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return networkPolicy, nil
+}
+
+func (c *Client) UpdateNetworkPolicy(networkPolicy *knetworking.NetworkPolicy) (*knetworking.NetworkPolicy, error) {
+	networkPolicy.TypeMeta = _networkPolicyTypeMeta
+	networkPolicy, err := c.networkPolicyClient.Update(context.Background(), networkPolicy, kmeta.UpdateOptions{})
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return networkPolicy, nil
+}
+
+func (c *Client) ApplyNetworkPolicy(networkPolicy *knetworking.NetworkPolicy) (*knetworking.NetworkPolicy, error) {
+	existing, err := c.GetNetworkPolicy(networkPolicy.Name)
+	if err != nil {
+		return nil, err
+	}
+	if existing == nil {
+		return c.CreateNetworkPolicy(networkPolicy)
+	}
+	return c.UpdateNetworkPolicy(networkPolicy)
+}
+
+func (c *Client) GetNetworkPolicy(name string) (*knetworking.NetworkPolicy, error) {
+	networkPolicy, err := c.networkPolicyClient.Get(context.Background(), name, kmeta.GetOptions{})
+	if err != nil {
+		if kerrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, errors.WithStack(err)
+	}
+	networkPolicy.TypeMeta = _networkPolicyTypeMeta
+	return networkPolicy, nil
+}
+
+func (c *Client) DeleteNetworkPolicy(name string) (bool, error) {
+	err := c.networkPolicyClient.Delete(context.Background(), name, _deleteOpts)
+	if err != nil {
+		if kerrors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, errors.WithStack(err)
+	}
+	return true, nil
+}
+
+func (c *Client) ListNetworkPolicies(opts *kmeta.ListOptions) ([]knetworking.NetworkPolicy, error) {
+	if opts == nil {
+		opts = &kmeta.ListOptions{}
+	}
+	networkPolicyList, err := c.networkPolicyClient.List(context.Background(), *opts)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	for i := range networkPolicyList.Items {
+		networkPolicyList.Items[i].TypeMeta = _networkPolicyTypeMeta
+	}
+	return networkPolicyList.Items, nil
+}
+
+func (c *Client) ListNetworkPoliciesByLabels(labels map[string]string) ([]knetworking.NetworkPolicy, error) {
+	opts := &kmeta.ListOptions{
+		LabelSelector: klabels.SelectorFromSet(labels).String(),
+	}
+	return c.ListNetworkPolicies(opts)
+}
+
+func (c *Client) ListNetworkPoliciesByLabel(labelKey string, labelValue string) ([]knetworking.NetworkPolicy, error) {
+	return c.ListNetworkPoliciesByLabels(map[string]string{labelKey: labelValue})
+}
+
+func (c *Client) ListNetworkPoliciesWithLabelKeys(labelKeys ...string) ([]knetworking.NetworkPolicy, error) {
+	opts := &kmeta.ListOptions{
+		LabelSelector: LabelExistsSelector(labelKeys...),
+	}
+	return c.ListNetworkPolicies(opts)
+}
@@ -0,0 +1,139 @@
+/*
+Copyright 2021 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package k8s
+
+import (
+	"context"
+
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	kpolicy "k8s.io/api/policy/v1beta1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	kmeta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	klabels "k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+var _pdbTypeMeta = kmeta.TypeMeta{
+	APIVersion: "policy/v1beta1",
+	Kind:       "PodDisruptionBudget",
+}
+
+type PDBSpec struct {
+	Name           string
+	MaxUnavailable string
+	Selector       map[string]string
+	Labels         map[string]string
+	Annotations    map[string]string
+}
+
+func PDB(spec *PDBSpec) *kpolicy.PodDisruptionBudget {
+	maxUnavailable := intstr.Parse(spec.MaxUnavailable)
+
+	pdb := &kpolicy.PodDisruptionBudget{
+		TypeMeta: _pdbTypeMeta,
+		ObjectMeta: kmeta.ObjectMeta{
+			Name:        spec.Name,
+			Labels:      spec.Labels,
+			Annotations: spec.Annotations,
+		},
+		Spec: kpolicy.PodDisruptionBudgetSpec{
+			MaxUnavailable: &maxUnavailable,
+			Selector: &kmeta.LabelSelector{
+				MatchLabels: spec.Selector,
+			},
+		},
+	}
+	return pdb
+}
+
+func (c *Client) CreatePDB(pdb *kpolicy.PodDisruptionBudget) (*kpolicy.PodDisruptionBudget, error) {
+	pdb.TypeMeta = _pdbTypeMeta
+	pdb, err := c.pdbClient.Create(context.Background(), pdb, kmeta.CreateOptions{})
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return pdb, nil
+}
+
+func (c *Client) UpdatePDB(existing *kpolicy.PodDisruptionBudget, updated *kpolicy.PodDisruptionBudget) (*kpolicy.PodDisruptionBudget, error) {
+	updated.TypeMeta = _pdbTypeMeta
+	updated.ResourceVersion = existing.ResourceVersion
+	pdb, err := c.pdbClient.Update(context.Background(), updated, kmeta.UpdateOptions{})
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return pdb, nil
+}
+
+func (c *Client) ApplyPDB(pdb *kpolicy.PodDisruptionBudget) (*kpolicy.PodDisruptionBudget, error) {
+	existing, err := c.GetPDB(pdb.Name)
+	if err != nil {
+		return nil, err
+	}
+	if existing == nil {
+		return c.CreatePDB(pdb)
+	}
+	return c.UpdatePDB(existing, pdb)
+}
+
+func (c *Client) GetPDB(name string) (*kpolicy.PodDisruptionBudget, error) {
+	pdb, err := c.pdbClient.Get(context.Background(), name, kmeta.GetOptions{})
+	if err != nil {
+		if kerrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, errors.WithStack(err)
+	}
+	pdb.TypeMeta = _pdbTypeMeta
+	return pdb, nil
+}
+
+func (c *Client) DeletePDB(name string) (bool, error) {
+	err := c.pdbClient.Delete(context.Background(), name, _deleteOpts)
+	if err != nil {
+		if kerrors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, errors.WithStack(err)
+	}
+	return true, nil
+}
+
+func (c *Client) ListPDBs(opts *kmeta.ListOptions) ([]kpolicy.PodDisruptionBudget, error) {
+	if opts == nil {
+		opts = &kmeta.ListOptions{}
+	}
+	pdbList, err := c.pdbClient.List(context.Background(), *opts)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	for i := range pdbList.Items {
+		pdbList.Items[i].TypeMeta = _pdbTypeMeta
+	}
+	return pdbList.Items, nil
+}
+
+func (c *Client) ListPDBsByLabels(labels map[string]string) ([]kpolicy.PodDisruptionBudget, error) {
+	opts := &kmeta.ListOptions{
+		LabelSelector: klabels.SelectorFromSet(labels).String(),
+	}
+	return c.ListPDBs(opts)
+}
+
+func (c *Client) ListPDBsByLabel(labelKey string, labelValue string) ([]kpolicy.PodDisruptionBudget, error) {
+	return c.ListPDBsByLabels(map[string]string{labelKey: labelValue})
+}
@@ -19,6 +19,7 @@ package k8s
 import (
 	"bytes"
 	"context"
+	"io"
 	"regexp"
 	"time"
 
@@ -284,6 +285,11 @@ func PodStatusFromContainerStatuses(containerStatuses []kcore.ContainerStatus) P
 	}
 }
 
+// IsImagePullError returns whether a waiting container's reason indicates that its image can't be pulled
+func IsImagePullError(reason string) bool {
+	return _imagePullErrorStrings.Has(reason)
+}
+
 func (c *Client) WaitForPodRunning(name string, numSeconds int) error {
 	for true {
 		pod, err := c.GetPod(name)
@@ -335,6 +341,20 @@ func (c *Client) ListPods(opts *kmeta.ListOptions) ([]kcore.Pod, error) {
 	return podList.Items, nil
 }
 
+// GetPodLogs returns the last tailLines lines of a container's logs (or of its previous instance's logs, if previous is true)
+func (c *Client) GetPodLogs(podName string, containerName string, previous bool, tailLines int64) (string, error) {
+	logOpts := &kcore.PodLogOptions{
+		Container: containerName,
+		Previous:  previous,
+		TailLines: &tailLines,
+	}
+	logBytes, err := c.podClient.GetLogs(podName, logOpts).DoRaw(context.Background())
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	return string(logBytes), nil
+}
+
 func (c *Client) ListPodsByLabels(labels map[string]string) ([]kcore.Pod, error) {
 	opts := &kmeta.ListOptions{
 		LabelSelector: klabels.SelectorFromSet(labels).String(),
@@ -427,3 +447,37 @@ func (c *Client) Exec(podName string, containerName string, command []string) (s
 
 	return buf.String(), nil
 }
+
+// ExecStream runs the command in the pod's container, streaming stdin (if not nil) to the command and the command's output to stdout and stderr;
+// if tty is true, stderr is merged into stdout and the terminal's size is read from sizeQueue (if not nil).
+// If the command exits with a non-zero code, the returned error is a k8s.io/client-go/util/exec.CodeExitError
+func (c *Client) ExecStream(podName string, containerName string, command []string, stdin io.Reader, stdout io.Writer, stderr io.Writer, tty bool, sizeQueue kremotecommand.TerminalSizeQueue) error {
+	options := &kcore.PodExecOptions{
+		Container: containerName,
+		Command:   command,
+		Stdin:     stdin != nil,
+		Stdout:    true,
+		Stderr:    !tty,
+		TTY:       tty,
+	}
+
+	req := c.clientset.CoreV1().RESTClient().Post().Namespace(c.Namespace).Resource("pods").Name(podName).SubResource("exec")
+	req.VersionedParams(options, kscheme.ParameterCodec)
+
+	exec, err := kremotecommand.NewSPDYExecutor(c.RestConfig, "POST", req.URL())
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	streamOptions := kremotecommand.StreamOptions{
+		Stdin:             stdin,
+		Stdout:            stdout,
+		Tty:               tty,
+		TerminalSizeQueue: sizeQueue,
+	}
+	if !tty {
+		streamOptions.Stderr = stderr
+	}
+
+	return exec.Stream(streamOptions)
+}
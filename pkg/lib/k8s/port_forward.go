@@ -0,0 +1,82 @@
+/*
+Copyright 2021 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package k8s
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	kcore "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/httpstream"
+	kportforward "k8s.io/client-go/tools/portforward"
+	kspdy "k8s.io/client-go/transport/spdy"
+)
+
+// PodConnection is a connection to a port of a pod
+type PodConnection struct {
+	httpstream.Stream
+	streamConn httpstream.Connection
+}
+
+func (conn *PodConnection) Close() error {
+	conn.Stream.Close()
+	return conn.streamConn.Close()
+}
+
+// DialPod opens a connection to a port of the pod through the kubernetes api server (the same way as `kubectl port-forward`),
+// so the pod doesn't need to be reachable from the caller's network
+func (c *Client) DialPod(podName string, port int32) (*PodConnection, error) {
+	transport, upgrader, err := kspdy.RoundTripperFor(c.RestConfig)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	req := c.clientset.CoreV1().RESTClient().Post().Namespace(c.Namespace).Resource("pods").Name(podName).SubResource("portforward")
+	dialer := kspdy.NewDialer(upgrader, &http.Client{Transport: transport}, "POST", req.URL())
+
+	streamConn, _, err := dialer.Dial(kportforward.PortForwardProtocolV1Name)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	headers := http.Header{}
+	headers.Set(kcore.StreamType, kcore.StreamTypeError)
+	headers.Set(kcore.PortHeader, strconv.Itoa(int(port)))
+	headers.Set(kcore.PortForwardRequestIDHeader, "0")
+
+	// the kubelet requires an error stream for each data stream; it's closed right away since it's only written to by the kubelet
+	// (if the pod isn't listening on the port, the kubelet closes the data stream)
+	errorStream, err := streamConn.CreateStream(headers)
+	if err != nil {
+		streamConn.Close()
+		return nil, errors.WithStack(err)
+	}
+	errorStream.Close()
+
+	headers.Set(kcore.StreamType, kcore.StreamTypeData)
+	dataStream, err := streamConn.CreateStream(headers)
+	if err != nil {
+		streamConn.Close()
+		return nil, errors.WithStack(err)
+	}
+
+	return &PodConnection{
+		Stream:     dataStream,
+		streamConn: streamConn,
+	}, nil
+}
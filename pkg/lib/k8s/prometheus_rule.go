@@ -0,0 +1,155 @@
+/*
+Copyright 2021 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package k8s
+
+import (
+	"context"
+
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	kmeta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kunstructured "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	kschema "k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+var _prometheusRuleGVR = kschema.GroupVersionResource{
+	Group:    "monitoring.coreos.com",
+	Version:  "v1",
+	Resource: "prometheusrules",
+}
+
+type PrometheusRuleSpec struct {
+	Name        string
+	Groups      []PrometheusRuleGroup
+	Labels      map[string]string
+	Annotations map[string]string
+}
+
+type PrometheusRuleGroup struct {
+	Name  string
+	Rules []PrometheusAlertRule
+}
+
+type PrometheusAlertRule struct {
+	Alert       string
+	Expr        string
+	For         string
+	Labels      map[string]string
+	Annotations map[string]string
+}
+
+// PrometheusRule builds a prometheus-operator PrometheusRule resource (the client-go types aren't vendored, so it's unstructured)
+func PrometheusRule(spec *PrometheusRuleSpec) *kunstructured.Unstructured {
+	groups := make([]interface{}, 0, len(spec.Groups))
+	for _, group := range spec.Groups {
+		rules := make([]interface{}, 0, len(group.Rules))
+		for _, rule := range group.Rules {
+			ruleObj := map[string]interface{}{
+				"alert": rule.Alert,
+				"expr":  rule.Expr,
+			}
+			if rule.For != "" {
+				ruleObj["for"] = rule.For
+			}
+			if len(rule.Labels) > 0 {
+				ruleObj["labels"] = stringMapToInterfaceMap(rule.Labels)
+			}
+			if len(rule.Annotations) > 0 {
+				ruleObj["annotations"] = stringMapToInterfaceMap(rule.Annotations)
+			}
+			rules = append(rules, ruleObj)
+		}
+		groups = append(groups, map[string]interface{}{
+			"name":  group.Name,
+			"rules": rules,
+		})
+	}
+
+	prometheusRule := &kunstructured.Unstructured{
+		Object: map[string]interface{}{
+			"spec": map[string]interface{}{
+				"groups": groups,
+			},
+		},
+	}
+	prometheusRule.SetAPIVersion(_prometheusRuleGVR.GroupVersion().String())
+	prometheusRule.SetKind("PrometheusRule")
+	prometheusRule.SetName(spec.Name)
+	prometheusRule.SetLabels(spec.Labels)
+	prometheusRule.SetAnnotations(spec.Annotations)
+
+	return prometheusRule
+}
+
+func (c *Client) CreatePrometheusRule(prometheusRule *kunstructured.Unstructured) (*kunstructured.Unstructured, error) {
+	prometheusRule, err := c.dynamicClient.Resource(_prometheusRuleGVR).Namespace(c.Namespace).Create(context.Background(), prometheusRule, kmeta.CreateOptions{})
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return prometheusRule, nil
+}
+
+func (c *Client) UpdatePrometheusRule(existing, updated *kunstructured.Unstructured) (*kunstructured.Unstructured, error) {
+	updated.SetResourceVersion(existing.GetResourceVersion())
+
+	prometheusRule, err := c.dynamicClient.Resource(_prometheusRuleGVR).Namespace(c.Namespace).Update(context.Background(), updated, kmeta.UpdateOptions{})
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return prometheusRule, nil
+}
+
+func (c *Client) ApplyPrometheusRule(prometheusRule *kunstructured.Unstructured) (*kunstructured.Unstructured, error) {
+	existing, err := c.GetPrometheusRule(prometheusRule.GetName())
+	if err != nil {
+		return nil, err
+	}
+	if existing == nil {
+		return c.CreatePrometheusRule(prometheusRule)
+	}
+	return c.UpdatePrometheusRule(existing, prometheusRule)
+}
+
+func (c *Client) GetPrometheusRule(name string) (*kunstructured.Unstructured, error) {
+	prometheusRule, err := c.dynamicClient.Resource(_prometheusRuleGVR).Namespace(c.Namespace).Get(context.Background(), name, kmeta.GetOptions{})
+	if err != nil {
+		if kerrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, errors.WithStack(err)
+	}
+	return prometheusRule, nil
+}
+
+func (c *Client) DeletePrometheusRule(name string) (bool, error) {
+	err := c.dynamicClient.Resource(_prometheusRuleGVR).Namespace(c.Namespace).Delete(context.Background(), name, _deleteOpts)
+	if err != nil {
+		if kerrors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, errors.WithStack(err)
+	}
+	return true, nil
+}
+
+func stringMapToInterfaceMap(strMap map[string]string) map[string]interface{} {
+	interfaceMap := make(map[string]interface{}, len(strMap))
+	for key, value := range strMap {
+		interfaceMap[key] = value
+	}
+	return interfaceMap
+}
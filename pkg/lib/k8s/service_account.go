@@ -0,0 +1,125 @@
+/*
+Copyright 2021 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package k8s
+
+import (
+	"context"
+
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	kcore "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	kmeta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	klabels "k8s.io/apimachinery/pkg/labels"
+)
+
+var _serviceAccountTypeMeta = kmeta.TypeMeta{
+	APIVersion: "v1",
+	Kind:       "ServiceAccount",
+}
+
+type ServiceAccountSpec struct {
+	Name                         string
+	AutomountServiceAccountToken *bool
+	Labels                       map[string]string
+	Annotations                  map[string]string
+}
+
+func ServiceAccount(spec *ServiceAccountSpec) *kcore.ServiceAccount {
+	serviceAccount := &kcore.ServiceAccount{
+		TypeMeta: _serviceAccountTypeMeta,
+		ObjectMeta: kmeta.ObjectMeta{
+			Name:        spec.Name,
+			Labels:      spec.Labels,
+			Annotations: spec.Annotations,
+		},
+		AutomountServiceAccountToken: spec.AutomountServiceAccountToken,
+	}
+	return serviceAccount
+}
+
+func (c *Client) CreateServiceAccount(serviceAccount *kcore.ServiceAccount) (*kcore.ServiceAccount, error) {
+	serviceAccount.TypeMeta = _serviceAccountTypeMeta
+	serviceAccount, err := c.serviceAccountClient.Create(context.Background(), serviceAccount, kmeta.CreateOptions{})
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return serviceAccount, nil
+}
+
+func (c *Client) UpdateServiceAccount(serviceAccount *kcore.ServiceAccount) (*kcore.ServiceAccount, error) {
+	serviceAccount.TypeMeta = _serviceAccountTypeMeta
+	serviceAccount, err := c.serviceAccountClient.Update(context.Background(), serviceAccount, kmeta.UpdateOptions{})
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return serviceAccount, nil
+}
+
+func (c *Client) GetServiceAccount(name string) (*kcore.ServiceAccount, error) {
+	serviceAccount, err := c.serviceAccountClient.Get(context.Background(), name, kmeta.GetOptions{})
+	if err != nil {
+		if kerrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, errors.WithStack(err)
+	}
+	serviceAccount.TypeMeta = _serviceAccountTypeMeta
+	return serviceAccount, nil
+}
+
+func (c *Client) DeleteServiceAccount(name string) (bool, error) {
+	err := c.serviceAccountClient.Delete(context.Background(), name, _deleteOpts)
+	if err != nil {
+		if kerrors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, errors.WithStack(err)
+	}
+	return true, nil
+}
+
+func (c *Client) ListServiceAccounts(opts *kmeta.ListOptions) ([]kcore.ServiceAccount, error) {
+	if opts == nil {
+		opts = &kmeta.ListOptions{}
+	}
+	serviceAccountList, err := c.serviceAccountClient.List(context.Background(), *opts)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	for i := range serviceAccountList.Items {
+		serviceAccountList.Items[i].TypeMeta = _serviceAccountTypeMeta
+	}
+	return serviceAccountList.Items, nil
+}
+
+func (c *Client) ListServiceAccountsByLabels(labels map[string]string) ([]kcore.ServiceAccount, error) {
+	opts := &kmeta.ListOptions{
+		LabelSelector: klabels.SelectorFromSet(labels).String(),
+	}
+	return c.ListServiceAccounts(opts)
+}
+
+func (c *Client) ListServiceAccountsByLabel(labelKey string, labelValue string) ([]kcore.ServiceAccount, error) {
+	return c.ListServiceAccountsByLabels(map[string]string{labelKey: labelValue})
+}
+
+func (c *Client) ListServiceAccountsWithLabelKeys(labelKeys ...string) ([]kcore.ServiceAccount, error) {
+	opts := &kmeta.ListOptions{
+		LabelSelector: LabelExistsSelector(labelKeys...),
+	}
+	return c.ListServiceAccounts(opts)
+}
@@ -19,10 +19,13 @@ package k8s
 import (
 	"context"
 	"reflect"
+	"strings"
+	"time"
 
 	"github.com/cortexlabs/cortex/pkg/lib/errors"
 	"github.com/cortexlabs/cortex/pkg/lib/sets/strset"
 	"github.com/cortexlabs/cortex/pkg/lib/urls"
+	"github.com/gogo/protobuf/types"
 	istionetworking "istio.io/api/networking/v1beta1"
 	istioclientnetworking "istio.io/client-go/pkg/apis/networking/v1beta1"
 	kerrors "k8s.io/apimachinery/pkg/api/errors"
@@ -41,110 +44,146 @@ type VirtualServiceSpec struct {
 	ExactPath    *string // either this or PrefixPath
 	PrefixPath   *string // either this or ExactPath
 	Destinations []Destination
+	HeaderRoutes []HeaderRoute // matched before the weighted destinations
 	Rewrite      *string
+	RouteName    string   // envoy filters can match the routes by this name
+	Retries      *Retries // if nil, istio's default retry policy is used
 	Labels       map[string]string
 	Annotations  map[string]string
 }
 
+type Retries struct {
+	Attempts      int32
+	PerTryTimeout *time.Duration
+	RetryOn       []string // e.g. connect-failure or 503
+}
+
 type Destination struct {
-	ServiceName string
-	Weight      int32
-	Port        uint32
-	Shadow      bool
+	ServiceName          string
+	Weight               int32
+	Port                 uint32
+	Shadow               bool
+	RequestHeaders       map[string]string // headers which are set on requests routed to this destination (not applied to shadow destinations)
+	ResponseHeaders      map[string]string // headers which are set on responses from this destination (not applied to shadow destinations)
+	AddedResponseHeaders map[string]string // headers which are appended to responses from this destination, e.g. Set-Cookie (not applied to shadow destinations)
+}
+
+// HeaderRoute routes all of the requests whose headers match to a single destination
+type HeaderRoute struct {
+	Headers     map[string]string // header name -> regular expression which the header's value must fully match
+	Destination Destination
+}
+
+type httpRouteSpec struct {
+	headers      map[string]*istionetworking.StringMatch
+	destinations []*istionetworking.HTTPRouteDestination
 }
 
 func VirtualService(spec *VirtualServiceSpec) *istioclientnetworking.VirtualService {
-	destinations := []*istionetworking.HTTPRouteDestination{}
-	var mirror *istionetworking.Destination
-	var mirrorWeight *istionetworking.Percent
+	destinations, mirror, mirrorWeight := routeDestinations(spec.Destinations)
 
-	for _, destination := range spec.Destinations {
-		if destination.Shadow {
-			mirror = &istionetworking.Destination{
-				Host: destination.ServiceName,
-				Port: &istionetworking.PortSelector{
-					Number: destination.Port,
-				},
+	var httpRoutes []*istionetworking.HTTPRoute
+
+	var routes []httpRouteSpec
+	for _, headerRoute := range spec.HeaderRoutes {
+		headerDestinations, _, _ := routeDestinations([]Destination{headerRoute.Destination})
+		headers := map[string]*istionetworking.StringMatch{}
+		for name, regex := range headerRoute.Headers {
+			headers[name] = &istionetworking.StringMatch{
+				MatchType: &istionetworking.StringMatch_Regex{Regex: regex},
 			}
-			mirrorWeight = &istionetworking.Percent{Value: float64(destination.Weight)}
-		} else {
-			destinations = append(destinations, &istionetworking.HTTPRouteDestination{
-				Destination: &istionetworking.Destination{
-					Host: destination.ServiceName,
-					Port: &istionetworking.PortSelector{
-						Number: destination.Port,
-					},
-				},
-				Weight: destination.Weight,
-			})
 		}
+		routes = append(routes, httpRouteSpec{headers: headers, destinations: headerDestinations})
 	}
+	routes = append(routes, httpRouteSpec{destinations: destinations})
 
-	var httpRoutes []*istionetworking.HTTPRoute
+	var retries *istionetworking.HTTPRetry
+	if spec.Retries != nil {
+		retries = &istionetworking.HTTPRetry{
+			Attempts: spec.Retries.Attempts,
+			RetryOn:  strings.Join(spec.Retries.RetryOn, ","),
+		}
+		if spec.Retries.PerTryTimeout != nil {
+			retries.PerTryTimeout = types.DurationProto(*spec.Retries.PerTryTimeout)
+		}
+	}
 
-	if spec.ExactPath != nil {
-		httpRoutes = append(httpRoutes, &istionetworking.HTTPRoute{
-			Match: []*istionetworking.HTTPMatchRequest{
-				{
-					Uri: &istionetworking.StringMatch{
-						MatchType: &istionetworking.StringMatch_Exact{
-							Exact: urls.CanonicalizeEndpoint(*spec.ExactPath),
+	for _, route := range routes {
+		if spec.ExactPath != nil {
+			exactMatch := &istionetworking.HTTPRoute{
+				Match: []*istionetworking.HTTPMatchRequest{
+					{
+						Uri: &istionetworking.StringMatch{
+							MatchType: &istionetworking.StringMatch_Exact{
+								Exact: urls.CanonicalizeEndpoint(*spec.ExactPath),
+							},
 						},
+						Headers: route.headers,
 					},
 				},
-			},
-			Route:            destinations,
-			Mirror:           mirror,
-			MirrorPercentage: mirrorWeight,
-		})
+				Name:             spec.RouteName,
+				Route:            route.destinations,
+				Mirror:           mirror,
+				MirrorPercentage: mirrorWeight,
+				Retries:          retries,
+			}
 
-		if spec.Rewrite != nil {
-			httpRoutes[0].Rewrite = &istionetworking.HTTPRewrite{
-				Uri: urls.CanonicalizeEndpoint(*spec.Rewrite),
+			if spec.Rewrite != nil {
+				exactMatch.Rewrite = &istionetworking.HTTPRewrite{
+					Uri: urls.CanonicalizeEndpoint(*spec.Rewrite),
+				}
 			}
-		}
-	} else {
-		exactMatch := &istionetworking.HTTPRoute{
-			Match: []*istionetworking.HTTPMatchRequest{
-				{
-					Uri: &istionetworking.StringMatch{
-						MatchType: &istionetworking.StringMatch_Exact{
-							Exact: urls.CanonicalizeEndpoint(*spec.PrefixPath),
+
+			httpRoutes = append(httpRoutes, exactMatch)
+		} else {
+			exactMatch := &istionetworking.HTTPRoute{
+				Match: []*istionetworking.HTTPMatchRequest{
+					{
+						Uri: &istionetworking.StringMatch{
+							MatchType: &istionetworking.StringMatch_Exact{
+								Exact: urls.CanonicalizeEndpoint(*spec.PrefixPath),
+							},
 						},
+						Headers: route.headers,
 					},
 				},
-			},
-			Route:            destinations,
-			Mirror:           mirror,
-			MirrorPercentage: mirrorWeight,
-		}
+				Name:             spec.RouteName,
+				Route:            route.destinations,
+				Mirror:           mirror,
+				MirrorPercentage: mirrorWeight,
+				Retries:          retries,
+			}
 
-		prefixMatch := &istionetworking.HTTPRoute{
-			Match: []*istionetworking.HTTPMatchRequest{
-				{
-					Uri: &istionetworking.StringMatch{
-						MatchType: &istionetworking.StringMatch_Prefix{
-							Prefix: urls.CanonicalizeEndpointWithTrailingSlash(*spec.PrefixPath),
+			prefixMatch := &istionetworking.HTTPRoute{
+				Match: []*istionetworking.HTTPMatchRequest{
+					{
+						Uri: &istionetworking.StringMatch{
+							MatchType: &istionetworking.StringMatch_Prefix{
+								Prefix: urls.CanonicalizeEndpointWithTrailingSlash(*spec.PrefixPath),
+							},
 						},
+						Headers: route.headers,
 					},
 				},
-			},
-			Route:            destinations,
-			Mirror:           mirror,
-			MirrorPercentage: mirrorWeight,
-		}
-
-		if spec.Rewrite != nil {
-			exactMatch.Rewrite = &istionetworking.HTTPRewrite{
-				Uri: urls.CanonicalizeEndpoint(*spec.Rewrite),
+				Name:             spec.RouteName,
+				Route:            route.destinations,
+				Mirror:           mirror,
+				MirrorPercentage: mirrorWeight,
+				Retries:          retries,
 			}
 
-			prefixMatch.Rewrite = &istionetworking.HTTPRewrite{
-				Uri: urls.CanonicalizeEndpointWithTrailingSlash(*spec.Rewrite),
+			if spec.Rewrite != nil {
+				exactMatch.Rewrite = &istionetworking.HTTPRewrite{
+					Uri: urls.CanonicalizeEndpoint(*spec.Rewrite),
+				}
+
+				prefixMatch.Rewrite = &istionetworking.HTTPRewrite{
+					Uri: urls.CanonicalizeEndpointWithTrailingSlash(*spec.Rewrite),
+				}
 			}
-		}
 
-		httpRoutes = append(httpRoutes, exactMatch, prefixMatch)
+			httpRoutes = append(httpRoutes, exactMatch, prefixMatch)
+		}
 	}
 
 	virtualService := &istioclientnetworking.VirtualService{
@@ -164,6 +203,53 @@ func VirtualService(spec *VirtualServiceSpec) *istioclientnetworking.VirtualServ
 	return virtualService
 }
 
+// routeDestinations converts the destinations to istio route destinations; the shadow destination (if any) is returned as the mirror
+func routeDestinations(spec []Destination) ([]*istionetworking.HTTPRouteDestination, *istionetworking.Destination, *istionetworking.Percent) {
+	destinations := []*istionetworking.HTTPRouteDestination{}
+	var mirror *istionetworking.Destination
+	var mirrorWeight *istionetworking.Percent
+
+	for _, destination := range spec {
+		if destination.Shadow {
+			mirror = &istionetworking.Destination{
+				Host: destination.ServiceName,
+				Port: &istionetworking.PortSelector{
+					Number: destination.Port,
+				},
+			}
+			mirrorWeight = &istionetworking.Percent{Value: float64(destination.Weight)}
+			continue
+		}
+
+		var headers *istionetworking.Headers
+		if len(destination.RequestHeaders) > 0 || len(destination.ResponseHeaders) > 0 || len(destination.AddedResponseHeaders) > 0 {
+			headers = &istionetworking.Headers{}
+			if len(destination.RequestHeaders) > 0 {
+				headers.Request = &istionetworking.Headers_HeaderOperations{Set: destination.RequestHeaders}
+			}
+			if len(destination.ResponseHeaders) > 0 || len(destination.AddedResponseHeaders) > 0 {
+				headers.Response = &istionetworking.Headers_HeaderOperations{
+					Set: destination.ResponseHeaders,
+					Add: destination.AddedResponseHeaders,
+				}
+			}
+		}
+
+		destinations = append(destinations, &istionetworking.HTTPRouteDestination{
+			Destination: &istionetworking.Destination{
+				Host: destination.ServiceName,
+				Port: &istionetworking.PortSelector{
+					Number: destination.Port,
+				},
+			},
+			Weight:  destination.Weight,
+			Headers: headers,
+		})
+	}
+
+	return destinations, mirror, mirrorWeight
+}
+
 func (c *Client) CreateVirtualService(virtualService *istioclientnetworking.VirtualService) (*istioclientnetworking.VirtualService, error) {
 	virtualService.TypeMeta = _virtualServiceTypeMeta
 	virtualService, err := c.virtualServiceClient.Create(context.Background(), virtualService, kmeta.CreateOptions{})
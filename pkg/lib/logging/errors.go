@@ -24,7 +24,8 @@ import (
 )
 
 const (
-	ErrInvalidLogLevel = "logging.invalid_log_level"
+	ErrInvalidLogLevel  = "logging.invalid_log_level"
+	ErrInvalidLogFormat = "logging.invalid_log_format"
 )
 
 func ErrorInvalidLogLevel(provided string, loglevels []string) error {
@@ -33,3 +34,10 @@ func ErrorInvalidLogLevel(provided string, loglevels []string) error {
 		Message: fmt.Sprintf("invalid log level %s; must be one of %s", provided, s.StrsOr(loglevels)),
 	})
 }
+
+func ErrorInvalidLogFormat(provided string, logFormats []string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrInvalidLogFormat,
+		Message: fmt.Sprintf("invalid log format %s; must be one of %s", provided, s.StrsOr(logFormats)),
+	})
+}
@@ -25,33 +25,81 @@ import (
 	"go.uber.org/zap"
 )
 
+// The environment variables which configure the logs of the cortex containers (e.g. the proxy, gateway, enqueuer, and dequeuer)
+const (
+	LogLevelEnvVar   = "CORTEX_LOG_LEVEL"
+	LogFormatEnvVar  = "CORTEX_LOG_FORMAT"
+	APINameEnvVar    = "CORTEX_API_NAME"
+	ClusterUIDEnvVar = "CORTEX_CLUSTER_UID"
+)
+
+// The standard fields of the cortex containers' structured logs, which correlate the log lines of a request across the containers
+const (
+	RequestIDField  = "request_id"
+	APINameField    = "api_name"
+	ClusterUIDField = "cluster_uid"
+	JobIDField      = "job_id"
+	BatchIDField    = "batch_id"
+)
+
+// RequestIDHeader is set by the istio ingress gateway on each request
+const RequestIDHeader = "X-Request-ID"
+
 var logger *zap.SugaredLogger
 var loggerLock sync.Mutex
 
 func initializeLogger() {
-	logLevel := strings.ToLower(os.Getenv("CORTEX_LOG_LEVEL"))
+	zapConfig, err := ZapConfigFromEnv()
+	if err != nil {
+		panic(err)
+	}
+
+	zapLogger, err := zapConfig.Build()
+	if err != nil {
+		panic(err)
+	}
+
+	logger = zapLogger.Sugar()
+}
+
+// ZapConfigFromEnv configures the level and format of the logs from the environment (defaulting to info level json logs),
+// and adds the api name and cluster uid (if set) to all log lines
+func ZapConfigFromEnv() (zap.Config, error) {
+	logLevel := strings.ToLower(os.Getenv(LogLevelEnvVar))
 	if logLevel == "" {
-		logLevel = "info"
+		logLevel = userconfig.InfoLogLevel.String()
 	}
 
 	cortexLogLevel := userconfig.LogLevelFromString(logLevel)
 	if cortexLogLevel == userconfig.UnknownLogLevel {
-		panic(ErrorInvalidLogLevel(logLevel, userconfig.LogLevelTypes()))
+		return zap.Config{}, ErrorInvalidLogLevel(logLevel, userconfig.LogLevelTypes())
 	}
 
 	zapConfig := DefaultZapConfig(cortexLogLevel)
 
-	disableJSONLogging := strings.ToLower(os.Getenv("CORTEX_DISABLE_JSON_LOGGING"))
-	if disableJSONLogging == "true" {
-		zapConfig.Encoding = "console"
+	logFormat := strings.ToLower(os.Getenv(LogFormatEnvVar))
+	if logFormat == "" {
+		logFormat = userconfig.JSONLogFormat.String()
+		// kept for backwards compatibility
+		if strings.ToLower(os.Getenv("CORTEX_DISABLE_JSON_LOGGING")) == "true" {
+			logFormat = userconfig.ConsoleLogFormat.String()
+		}
 	}
 
-	zapLogger, err := zapConfig.Build()
-	if err != nil {
-		panic(err)
+	cortexLogFormat := userconfig.LogFormatFromString(logFormat)
+	if cortexLogFormat == userconfig.UnknownLogFormat {
+		return zap.Config{}, ErrorInvalidLogFormat(logFormat, userconfig.LogFormatTypes())
 	}
+	zapConfig.Encoding = cortexLogFormat.String()
 
-	logger = zapLogger.Sugar()
+	if apiName := os.Getenv(APINameEnvVar); apiName != "" {
+		zapConfig.InitialFields[APINameField] = apiName
+	}
+	if clusterUID := os.Getenv(ClusterUIDEnvVar); clusterUID != "" {
+		zapConfig.InitialFields[ClusterUIDField] = clusterUID
+	}
+
+	return zapConfig, nil
 }
 
 func GetLogger() *zap.SugaredLogger {
@@ -0,0 +1,47 @@
+/*
+Copyright 2021 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mlflow
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+)
+
+const (
+	ErrUnexpectedResponse = "mlflow.unexpected_response"
+	ErrNoVersionInStage   = "mlflow.no_version_in_stage"
+)
+
+func ErrorUnexpectedResponse(url string, statusCode int, body string) error {
+	msg := fmt.Sprintf("unexpected response from %s (status code %d)", url, statusCode)
+	if strings.TrimSpace(body) != "" {
+		msg += ": " + strings.TrimSpace(body)
+	}
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrUnexpectedResponse,
+		Message: msg,
+	})
+}
+
+func ErrorNoVersionInStage(name string, stage string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrNoVersionInStage,
+		Message: fmt.Sprintf("registered model %s has no versions in stage %s", name, stage),
+	})
+}
@@ -0,0 +1,130 @@
+/*
+Copyright 2021 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mlflow
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+)
+
+var _client = &http.Client{
+	Timeout: 30 * time.Second,
+}
+
+// Client calls the model registry api of an MLflow tracking server (https://mlflow.org/docs/latest/rest-api.html)
+type Client struct {
+	trackingURI string
+	token       string
+}
+
+type ModelVersion struct {
+	Name         string `json:"name"`
+	Version      string `json:"version"`
+	CurrentStage string `json:"current_stage"`
+	Source       string `json:"source"`
+	RunID        string `json:"run_id"`
+}
+
+// the token (if any) is sent as a bearer token, which is how Databricks-hosted tracking servers are authenticated
+func NewClient(trackingURI string, token string) *Client {
+	return &Client{
+		trackingURI: strings.TrimSuffix(trackingURI, "/"),
+		token:       token,
+	}
+}
+
+// GetLatestVersion returns the latest version of the registered model in the stage (e.g. "Production")
+func (c *Client) GetLatestVersion(name string, stage string) (*ModelVersion, error) {
+	var response struct {
+		ModelVersions []ModelVersion `json:"model_versions"`
+	}
+	query := url.Values{"name": {name}, "stages": {stage}}
+	if err := c.getJSON("/api/2.0/mlflow/registered-models/get-latest-versions", query, &response); err != nil {
+		return nil, err
+	}
+
+	if len(response.ModelVersions) == 0 {
+		return nil, ErrorNoVersionInStage(name, stage)
+	}
+
+	return &response.ModelVersions[0], nil
+}
+
+func (c *Client) GetModelVersion(name string, version string) (*ModelVersion, error) {
+	var response struct {
+		ModelVersion ModelVersion `json:"model_version"`
+	}
+	query := url.Values{"name": {name}, "version": {version}}
+	if err := c.getJSON("/api/2.0/mlflow/model-versions/get", query, &response); err != nil {
+		return nil, err
+	}
+
+	return &response.ModelVersion, nil
+}
+
+// GetDownloadURI returns the location of the model version's artifacts (e.g. an s3 path), which the version's source
+// may not be (e.g. if the source is a runs:/ uri)
+func (c *Client) GetDownloadURI(name string, version string) (string, error) {
+	var response struct {
+		ArtifactURI string `json:"artifact_uri"`
+	}
+	query := url.Values{"name": {name}, "version": {version}}
+	if err := c.getJSON("/api/2.0/mlflow/model-versions/get-download-uri", query, &response); err != nil {
+		return "", err
+	}
+
+	return response.ArtifactURI, nil
+}
+
+func (c *Client) getJSON(path string, query url.Values, obj interface{}) error {
+	requestURL := c.trackingURI + path + "?" + query.Encode()
+
+	request, err := http.NewRequest(http.MethodGet, requestURL, nil)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if c.token != "" {
+		request.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	response, err := _client.Do(request)
+	if err != nil {
+		return errors.Wrap(err, "unable to connect to the MLflow tracking server", c.trackingURI)
+	}
+	defer response.Body.Close()
+
+	body, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	if response.StatusCode != http.StatusOK {
+		return ErrorUnexpectedResponse(c.trackingURI+path, response.StatusCode, string(body))
+	}
+
+	if err := json.Unmarshal(body, obj); err != nil {
+		return errors.Wrap(err, c.trackingURI+path)
+	}
+
+	return nil
+}
@@ -0,0 +1,72 @@
+/*
+Copyright 2021 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mlflow
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newTrackingServer(t *testing.T) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+
+		switch r.URL.Path {
+		case "/api/2.0/mlflow/registered-models/get-latest-versions":
+			if r.URL.Query().Get("stages") == "Production" {
+				_, _ = w.Write([]byte(`{"model_versions": [{"name": "iris", "version": "3", "current_stage": "Production", "source": "runs:/abc/model", "run_id": "abc"}]}`))
+			} else {
+				_, _ = w.Write([]byte(`{}`))
+			}
+		case "/api/2.0/mlflow/model-versions/get-download-uri":
+			_, _ = w.Write([]byte(`{"artifact_uri": "s3://bucket/mlflow/1/abc/artifacts/model"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"error_code": "RESOURCE_DOES_NOT_EXIST"}`))
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestGetLatestVersion(t *testing.T) {
+	client := NewClient(newTrackingServer(t).URL+"/", "token")
+
+	modelVersion, err := client.GetLatestVersion("iris", "Production")
+	require.NoError(t, err)
+	require.Equal(t, "3", modelVersion.Version)
+	require.Equal(t, "abc", modelVersion.RunID)
+
+	_, err = client.GetLatestVersion("iris", "Staging")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "has no versions in stage Staging")
+}
+
+func TestGetDownloadURI(t *testing.T) {
+	client := NewClient(newTrackingServer(t).URL, "token")
+
+	artifactURI, err := client.GetDownloadURI("iris", "3")
+	require.NoError(t, err)
+	require.Equal(t, "s3://bucket/mlflow/1/abc/artifacts/model", artifactURI)
+
+	_, err = client.GetModelVersion("iris", "3")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "status code 404")
+}
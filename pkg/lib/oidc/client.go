@@ -0,0 +1,237 @@
+/*
+Copyright 2021 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+)
+
+const _loginTimeout = 5 * time.Minute
+
+type Token struct {
+	IDToken      string `json:"id_token"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int    `json:"expires_in"`
+}
+
+type tokenResponse struct {
+	Token
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+func (response tokenResponse) errorStr() string {
+	if response.ErrorDescription != "" {
+		return response.Error + ": " + response.ErrorDescription
+	}
+	return response.Error
+}
+
+// Client is a public OIDC client (i.e. it has no secret), which authenticates users with the authorization code flow and PKCE (https://tools.ietf.org/html/rfc7636)
+type Client struct {
+	Provider *Provider
+	ClientID string
+	Scopes   []string
+}
+
+// AuthorizationRequest is a login which was started by StartAuthorization(); the provider redirects the user's browser to a server on the loopback interface (https://tools.ietf.org/html/rfc8252#section-7.3)
+type AuthorizationRequest struct {
+	URL string // the url which the user should visit to log in
+
+	listener     net.Listener
+	redirectURI  string
+	state        string
+	codeVerifier string
+}
+
+type authorizationResponse struct {
+	code string
+	err  error
+}
+
+func (c *Client) StartAuthorization() (*AuthorizationRequest, error) {
+	if c.Provider.AuthorizationEndpoint == "" {
+		return nil, ErrorAuthorizationFlowNotSupported(c.Provider.Issuer)
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	state, err := randomString()
+	if err != nil {
+		listener.Close()
+		return nil, err
+	}
+	codeVerifier, err := randomString()
+	if err != nil {
+		listener.Close()
+		return nil, err
+	}
+
+	redirectURI := fmt.Sprintf("http://%s/callback", listener.Addr().String())
+
+	values := url.Values{}
+	values.Set("response_type", "code")
+	values.Set("client_id", c.ClientID)
+	values.Set("redirect_uri", redirectURI)
+	values.Set("scope", strings.Join(c.Scopes, " "))
+	values.Set("state", state)
+	values.Set("code_challenge", codeChallenge(codeVerifier))
+	values.Set("code_challenge_method", "S256")
+
+	authURL := c.Provider.AuthorizationEndpoint
+	if strings.Contains(authURL, "?") {
+		authURL += "&" + values.Encode()
+	} else {
+		authURL += "?" + values.Encode()
+	}
+
+	return &AuthorizationRequest{
+		URL:          authURL,
+		listener:     listener,
+		redirectURI:  redirectURI,
+		state:        state,
+		codeVerifier: codeVerifier,
+	}, nil
+}
+
+// WaitForToken waits for the provider to redirect the user's browser with an authorization code, and exchanges the code for tokens
+func (c *Client) WaitForToken(request *AuthorizationRequest) (*Token, error) {
+	responses := make(chan authorizationResponse, 1)
+
+	server := &http.Server{
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/callback" {
+				http.NotFound(w, r)
+				return
+			}
+
+			query := r.URL.Query()
+			var response authorizationResponse
+			switch {
+			case query.Get("state") != request.state:
+				response.err = ErrorLoginFailed("the state of the response does not match the request")
+			case query.Get("error") != "":
+				response.err = ErrorLoginFailed(tokenResponse{Error: query.Get("error"), ErrorDescription: query.Get("error_description")}.errorStr())
+			case query.Get("code") == "":
+				response.err = ErrorLoginFailed("the response does not include an authorization code")
+			default:
+				response.code = query.Get("code")
+			}
+
+			if response.err != nil {
+				http.Error(w, errors.Message(response.err), http.StatusBadRequest)
+			} else {
+				fmt.Fprintln(w, "you have logged in to cortex; you can close this window")
+			}
+
+			select {
+			case responses <- response:
+			default:
+			}
+		}),
+	}
+
+	go server.Serve(request.listener)
+	defer server.Shutdown(context.Background())
+
+	var response authorizationResponse
+	select {
+	case response = <-responses:
+	case <-time.After(_loginTimeout):
+		return nil, ErrorLoginTimedOut()
+	}
+	if response.err != nil {
+		return nil, response.err
+	}
+
+	values := url.Values{}
+	values.Set("client_id", c.ClientID)
+	values.Set("grant_type", "authorization_code")
+	values.Set("code", response.code)
+	values.Set("redirect_uri", request.redirectURI)
+	values.Set("code_verifier", request.codeVerifier)
+
+	var tokenRes tokenResponse
+	statusCode, err := postForm(c.Provider.TokenEndpoint, values, &tokenRes)
+	if err != nil {
+		return nil, err
+	}
+	if tokenRes.Error != "" {
+		return nil, ErrorLoginFailed(tokenRes.errorStr())
+	}
+	if statusCode != http.StatusOK || tokenRes.IDToken == "" {
+		return nil, ErrorUnexpectedResponse(c.Provider.TokenEndpoint, statusCode, "")
+	}
+
+	return &tokenRes.Token, nil
+}
+
+// Refresh exchanges a refresh token for a new id token; providers may or may not issue a new refresh token
+func (c *Client) Refresh(refreshToken string) (*Token, error) {
+	values := url.Values{}
+	values.Set("client_id", c.ClientID)
+	values.Set("grant_type", "refresh_token")
+	values.Set("refresh_token", refreshToken)
+
+	var response tokenResponse
+	statusCode, err := postForm(c.Provider.TokenEndpoint, values, &response)
+	if err != nil {
+		return nil, err
+	}
+
+	if response.Error != "" {
+		return nil, ErrorRefreshFailed(response.errorStr())
+	}
+	if statusCode != http.StatusOK || response.IDToken == "" {
+		return nil, ErrorUnexpectedResponse(c.Provider.TokenEndpoint, statusCode, "")
+	}
+
+	if response.RefreshToken == "" {
+		response.RefreshToken = refreshToken
+	}
+
+	return &response.Token, nil
+}
+
+// randomString returns 32 random bytes, base64url encoded (i.e. a valid PKCE code verifier)
+func randomString() (string, error) {
+	bytes := make([]byte, 32)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", errors.WithStack(err)
+	}
+	return base64.RawURLEncoding.EncodeToString(bytes), nil
+}
+
+// codeChallenge returns the S256 PKCE challenge of the code verifier
+func codeChallenge(codeVerifier string) string {
+	digest := sha256.Sum256([]byte(codeVerifier))
+	return base64.RawURLEncoding.EncodeToString(digest[:])
+}
@@ -0,0 +1,103 @@
+/*
+Copyright 2021 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package oidc
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+)
+
+const (
+	ErrIssuerMismatch                = "oidc.issuer_mismatch"
+	ErrInvalidDiscoveryDocument      = "oidc.invalid_discovery_document"
+	ErrUnexpectedResponse            = "oidc.unexpected_response"
+	ErrInvalidToken                  = "oidc.invalid_token"
+	ErrTokenExpired                  = "oidc.token_expired"
+	ErrAuthorizationFlowNotSupported = "oidc.authorization_flow_not_supported"
+	ErrLoginTimedOut                 = "oidc.login_timed_out"
+	ErrLoginFailed                   = "oidc.login_failed"
+	ErrRefreshFailed                 = "oidc.refresh_failed"
+)
+
+func ErrorIssuerMismatch(expected string, actual string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrIssuerMismatch,
+		Message: fmt.Sprintf("the OIDC provider's issuer (%s) does not match the configured issuer url (%s)", actual, expected),
+	})
+}
+
+func ErrorInvalidDiscoveryDocument(issuerURL string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrInvalidDiscoveryDocument,
+		Message: fmt.Sprintf("the OIDC discovery document of %s does not specify the jwks_uri and token_endpoint fields", issuerURL),
+	})
+}
+
+func ErrorUnexpectedResponse(url string, statusCode int, body string) error {
+	msg := fmt.Sprintf("unexpected response from %s (status code %d)", url, statusCode)
+	if strings.TrimSpace(body) != "" {
+		msg += ": " + strings.TrimSpace(body)
+	}
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrUnexpectedResponse,
+		Message: msg,
+	})
+}
+
+func ErrorInvalidToken(reason string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrInvalidToken,
+		Message: fmt.Sprintf("invalid OIDC token: %s", reason),
+	})
+}
+
+func ErrorTokenExpired() error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrTokenExpired,
+		Message: "your OIDC token has expired; run `cortex env configure --auth oidc` to log in again",
+	})
+}
+
+func ErrorAuthorizationFlowNotSupported(issuerURL string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrAuthorizationFlowNotSupported,
+		Message: fmt.Sprintf("the OIDC provider %s does not specify an authorization endpoint", issuerURL),
+	})
+}
+
+func ErrorLoginTimedOut() error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrLoginTimedOut,
+		Message: "the login was not completed in time; please try again",
+	})
+}
+
+func ErrorLoginFailed(reason string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrLoginFailed,
+		Message: fmt.Sprintf("login failed (%s)", reason),
+	})
+}
+
+func ErrorRefreshFailed(reason string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrRefreshFailed,
+		Message: fmt.Sprintf("unable to refresh your OIDC token (%s); run `cortex env configure --auth oidc` to log in again", reason),
+	})
+}
@@ -0,0 +1,103 @@
+/*
+Copyright 2021 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package oidc
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+)
+
+var _client = &http.Client{
+	Timeout: 10 * time.Second,
+}
+
+// Provider contains the endpoints which are advertised in the provider's discovery document
+type Provider struct {
+	Issuer                      string `json:"issuer"`
+	AuthorizationEndpoint       string `json:"authorization_endpoint"`
+	TokenEndpoint               string `json:"token_endpoint"`
+	DeviceAuthorizationEndpoint string `json:"device_authorization_endpoint"`
+	JWKSURI                     string `json:"jwks_uri"`
+}
+
+// Discover fetches the provider's discovery document (https://openid.net/specs/openid-connect-discovery-1_0.html)
+func Discover(issuerURL string) (*Provider, error) {
+	issuerURL = strings.TrimSuffix(issuerURL, "/")
+
+	var provider Provider
+	if err := getJSON(issuerURL+"/.well-known/openid-configuration", &provider); err != nil {
+		return nil, errors.Wrap(err, "unable to discover the OIDC provider's configuration")
+	}
+
+	if provider.Issuer != issuerURL {
+		return nil, ErrorIssuerMismatch(issuerURL, provider.Issuer)
+	}
+	if provider.JWKSURI == "" || provider.TokenEndpoint == "" {
+		return nil, ErrorInvalidDiscoveryDocument(issuerURL)
+	}
+
+	return &provider, nil
+}
+
+func getJSON(url string, obj interface{}) error {
+	response, err := _client.Get(url)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer response.Body.Close()
+
+	body, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	if response.StatusCode != http.StatusOK {
+		return ErrorUnexpectedResponse(url, response.StatusCode, string(body))
+	}
+
+	if err := json.Unmarshal(body, obj); err != nil {
+		return errors.Wrap(err, url)
+	}
+
+	return nil
+}
+
+// postForm returns the response's status code, so that callers can handle oauth2 error responses (which have status code 400)
+func postForm(url string, values url.Values, obj interface{}) (int, error) {
+	response, err := _client.PostForm(url, values)
+	if err != nil {
+		return 0, errors.WithStack(err)
+	}
+	defer response.Body.Close()
+
+	body, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return 0, errors.WithStack(err)
+	}
+
+	if err := json.Unmarshal(body, obj); err != nil {
+		return response.StatusCode, ErrorUnexpectedResponse(url, response.StatusCode, string(body))
+	}
+
+	return response.StatusCode, nil
+}
@@ -0,0 +1,122 @@
+/*
+Copyright 2021 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package oidc
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"time"
+
+	gooidc "github.com/coreos/go-oidc/v3/oidc"
+)
+
+// Claims contains the payload of a verified id token
+type Claims map[string]interface{}
+
+// String returns the claim if it is a string, and "" otherwise
+func (claims Claims) String(name string) string {
+	if str, ok := claims[name].(string); ok {
+		return str
+	}
+	return ""
+}
+
+// Strings returns the claim as a list of strings (a single string claim is returned as a list of length 1)
+func (claims Claims) Strings(name string) []string {
+	switch val := claims[name].(type) {
+	case string:
+		return []string{val}
+	case []interface{}:
+		strs := make([]string, 0, len(val))
+		for _, item := range val {
+			if str, ok := item.(string); ok {
+				strs = append(strs, str)
+			}
+		}
+		return strs
+	}
+	return nil
+}
+
+// ExpiresAt returns the token's expiration time (the "exp" claim)
+func (claims Claims) ExpiresAt() (time.Time, bool) {
+	if num, ok := claims["exp"].(float64); ok {
+		return time.Unix(int64(num), 0), true
+	}
+	return time.Time{}, false
+}
+
+// Verifier verifies id tokens which were issued by a provider for a client; the signature and standard claims are checked by go-oidc
+type Verifier struct {
+	verifier *gooidc.IDTokenVerifier
+}
+
+// the provider's signing keys are fetched from its jwks_uri when a token is signed with an unknown key (e.g. after the keys are rotated)
+func NewVerifier(provider *Provider, clientID string) *Verifier {
+	keySet := gooidc.NewRemoteKeySet(context.Background(), provider.JWKSURI)
+	return newVerifier(provider.Issuer, keySet, clientID)
+}
+
+func newVerifier(issuer string, keySet gooidc.KeySet, clientID string) *Verifier {
+	return &Verifier{
+		verifier: gooidc.NewVerifier(issuer, keySet, &gooidc.Config{
+			ClientID:             clientID,
+			SupportedSigningAlgs: []string{gooidc.RS256, gooidc.ES256},
+		}),
+	}
+}
+
+// Verify checks the token's signature, issuer, audience, and expiration, and returns its claims
+func (v *Verifier) Verify(rawToken string) (Claims, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), _client.Timeout)
+	defer cancel()
+
+	idToken, err := v.verifier.Verify(ctx, rawToken)
+	if err != nil {
+		// go-oidc doesn't return a typed error for expired tokens
+		if expiresAt, ok := UnverifiedExpiresAt(rawToken); ok && time.Now().After(expiresAt) {
+			return nil, ErrorTokenExpired()
+		}
+		return nil, ErrorInvalidToken(err.Error())
+	}
+
+	var claims Claims
+	if err := idToken.Claims(&claims); err != nil {
+		return nil, ErrorInvalidToken("unable to decode the token's claims")
+	}
+
+	return claims, nil
+}
+
+// UnverifiedExpiresAt returns the expiration time of a token without verifying it (e.g. so that clients can refresh their tokens before they expire)
+func UnverifiedExpiresAt(rawToken string) (time.Time, bool) {
+	parts := strings.Split(rawToken, ".")
+	if len(parts) != 3 {
+		return time.Time{}, false
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return time.Time{}, false
+	}
+	var claims Claims
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return time.Time{}, false
+	}
+	return claims.ExpiresAt()
+}
@@ -0,0 +1,110 @@
+/*
+Copyright 2021 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package oidc
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"testing"
+	"time"
+
+	gooidc "github.com/coreos/go-oidc/v3/oidc"
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func signRS256(t *testing.T, key *rsa.PrivateKey, keyID string, claims Claims) string {
+	header, err := json.Marshal(map[string]string{"alg": "RS256", "kid": keyID, "typ": "JWT"})
+	require.NoError(t, err)
+	payload, err := json.Marshal(claims)
+	require.NoError(t, err)
+
+	signingInput := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	digest := sha256.Sum256([]byte(signingInput))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	require.NoError(t, err)
+
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func TestVerify(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	keySet := &gooidc.StaticKeySet{PublicKeys: []crypto.PublicKey{&key.PublicKey}}
+	verifier := newVerifier("https://issuer.example.com", keySet, "cortex")
+
+	validClaims := func() Claims {
+		return Claims{
+			"iss":    "https://issuer.example.com",
+			"aud":    []string{"other", "cortex"},
+			"exp":    time.Now().Add(time.Hour).Unix(),
+			"email":  "user@example.com",
+			"groups": []string{"team-a", "team-b"},
+		}
+	}
+
+	claims, err := verifier.Verify(signRS256(t, key, "key1", validClaims()))
+	require.NoError(t, err)
+	require.Equal(t, "user@example.com", claims.String("email"))
+	require.Equal(t, []string{"team-a", "team-b"}, claims.Strings("groups"))
+	require.Equal(t, []string{"user@example.com"}, claims.Strings("email"))
+
+	expired := validClaims()
+	expired["exp"] = time.Now().Add(-time.Hour).Unix()
+	_, err = verifier.Verify(signRS256(t, key, "key1", expired))
+	require.Equal(t, ErrTokenExpired, errors.GetKind(err))
+
+	wrongAudience := validClaims()
+	wrongAudience["aud"] = "other"
+	_, err = verifier.Verify(signRS256(t, key, "key1", wrongAudience))
+	require.Equal(t, ErrInvalidToken, errors.GetKind(err))
+
+	wrongIssuer := validClaims()
+	wrongIssuer["iss"] = "https://attacker.example.com"
+	_, err = verifier.Verify(signRS256(t, key, "key1", wrongIssuer))
+	require.Error(t, err)
+
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	_, err = verifier.Verify(signRS256(t, otherKey, "key1", validClaims()))
+	require.Error(t, err)
+
+	_, err = verifier.Verify("not-a-jwt")
+	require.Error(t, err)
+}
+
+func TestUnverifiedExpiresAt(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	exp := time.Now().Add(time.Hour).Truncate(time.Second)
+	expiresAt, ok := UnverifiedExpiresAt(signRS256(t, key, "key1", Claims{"exp": exp.Unix()}))
+	require.True(t, ok)
+	require.True(t, exp.Equal(expiresAt))
+
+	_, ok = UnverifiedExpiresAt("not-a-jwt")
+	require.False(t, ok)
+}
+
+func TestCodeChallenge(t *testing.T) {
+	// https://tools.ietf.org/html/rfc7636#appendix-B
+	require.Equal(t, "E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM", codeChallenge("dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"))
+}
@@ -150,6 +150,17 @@ func (t *Timer) Print(messages ...string) {
 	t.last = now
 }
 
+// ParseDuration is like time.ParseDuration, and also accepts a number of days (e.g. 30d)
+func ParseDuration(str string) (time.Duration, error) {
+	if strings.HasSuffix(str, "d") {
+		days, err := strconv.ParseFloat(strings.TrimSuffix(str, "d"), 64)
+		if err == nil && days >= 0 {
+			return time.Duration(days * float64(24*time.Hour)), nil
+		}
+	}
+	return time.ParseDuration(str)
+}
+
 func MustParseDuration(str string) time.Duration {
 	d, err := time.ParseDuration(str)
 	if err != nil {
@@ -0,0 +1,69 @@
+/*
+Copyright 2021 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package modelmanager
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+type AcquireResponse struct {
+	Path string `json:"path"`
+}
+
+// Handler serves the model manager's api, which is used by the proxy to acquire and release the models which requests are routed to
+func Handler(manager *ModelManager) http.Handler {
+	router := mux.NewRouter()
+
+	router.HandleFunc("/models", func(w http.ResponseWriter, r *http.Request) {
+		respondJSON(w, http.StatusOK, manager.Statuses())
+	}).Methods("GET")
+
+	router.HandleFunc("/models/{name}/acquire", func(w http.ResponseWriter, r *http.Request) {
+		path, err := manager.Acquire(r.Context(), mux.Vars(r)["name"])
+		if err != nil {
+			if errors.Is(err, ErrModelNotFound) {
+				http.Error(w, err.Error(), http.StatusNotFound)
+			} else {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+			}
+			return
+		}
+		respondJSON(w, http.StatusOK, AcquireResponse{Path: path})
+	}).Methods("POST")
+
+	router.HandleFunc("/models/{name}/release", func(w http.ResponseWriter, r *http.Request) {
+		manager.Release(mux.Vars(r)["name"])
+		w.WriteHeader(http.StatusOK)
+	}).Methods("POST")
+
+	router.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte("healthy"))
+	})
+
+	return router
+}
+
+func respondJSON(w http.ResponseWriter, statusCode int, obj interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	_ = json.NewEncoder(w).Encode(obj)
+}
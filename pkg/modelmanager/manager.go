@@ -0,0 +1,240 @@
+/*
+Copyright 2021 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package modelmanager
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// ErrModelNotFound indicates that the requested model isn't one of the api's models
+var ErrModelNotFound = errors.New("model not found")
+
+// DownloadFunc downloads the model at s3Path into localDir
+type DownloadFunc func(s3Path string, localDir string) error
+
+// UnloadFunc is called before the files of an evicted model are deleted
+type UnloadFunc func(name string, localDir string) error
+
+type Params struct {
+	Models          map[string]string // model name -> s3 path
+	ModelsDir       string
+	MaxLoadedModels int
+}
+
+// ModelManager downloads the api's models when they are acquired, and deletes the least recently used models
+// which aren't in use once more than MaxLoadedModels have been downloaded
+type ModelManager struct {
+	params   Params
+	download DownloadFunc
+	unload   UnloadFunc
+
+	mu          sync.Mutex
+	models      map[string]*model
+	generations map[string]int
+}
+
+type model struct {
+	name     string
+	dir      string
+	loaded   chan struct{} // closed once the download has finished
+	err      error
+	inUse    int
+	lastUsed time.Time
+}
+
+type ModelStatus struct {
+	Name     string    `json:"name"`
+	Path     string    `json:"path"`
+	Loaded   bool      `json:"loaded"`
+	InUse    int       `json:"in_use"`
+	LastUsed time.Time `json:"last_used"`
+}
+
+func New(params Params, download DownloadFunc, unload UnloadFunc) *ModelManager {
+	return &ModelManager{
+		params:      params,
+		download:    download,
+		unload:      unload,
+		models:      map[string]*model{},
+		generations: map[string]int{},
+	}
+}
+
+// Acquire returns the local directory of the model once it has been downloaded; the model isn't deleted until it is released
+func (m *ModelManager) Acquire(ctx context.Context, name string) (string, error) {
+	m.mu.Lock()
+	s3Path, ok := m.params.Models[name]
+	if !ok {
+		m.mu.Unlock()
+		return "", ErrModelNotFound
+	}
+
+	mdl, ok := m.models[name]
+	if !ok {
+		// each download goes to a new directory, so that a model which is being deleted doesn't conflict with its next download
+		m.generations[name]++
+		mdl = &model{
+			name:   name,
+			dir:    filepath.Join(m.params.ModelsDir, name, strconv.Itoa(m.generations[name])),
+			loaded: make(chan struct{}),
+		}
+		m.models[name] = mdl
+		go m.load(mdl, s3Path)
+	}
+	mdl.inUse++
+	mdl.lastUsed = time.Now()
+	m.mu.Unlock()
+
+	select {
+	case <-mdl.loaded:
+	case <-ctx.Done():
+		m.release(mdl)
+		return "", ctx.Err()
+	}
+
+	if mdl.err != nil {
+		m.release(mdl)
+		return "", mdl.err
+	}
+
+	return mdl.dir, nil
+}
+
+// Release marks one use of the model as finished
+func (m *ModelManager) Release(name string) {
+	m.mu.Lock()
+	mdl, ok := m.models[name]
+	m.mu.Unlock()
+
+	if ok {
+		m.release(mdl)
+	}
+}
+
+func (m *ModelManager) release(mdl *model) {
+	m.mu.Lock()
+	if mdl.inUse > 0 {
+		mdl.inUse--
+	}
+	mdl.lastUsed = time.Now()
+	evicted := m.evict()
+	m.mu.Unlock()
+
+	m.deleteModels(evicted)
+}
+
+func (m *ModelManager) load(mdl *model, s3Path string) {
+	err := m.download(s3Path, mdl.dir)
+
+	m.mu.Lock()
+	mdl.err = err
+	if err != nil && m.models[mdl.name] == mdl {
+		// the download is retried by the next request for the model
+		delete(m.models, mdl.name)
+	}
+	close(mdl.loaded)
+	evicted := m.evict()
+	m.mu.Unlock()
+
+	if err != nil {
+		_ = os.RemoveAll(mdl.dir)
+	}
+	m.deleteModels(evicted)
+}
+
+// evict removes the least recently used models which aren't in use from the manager until at most MaxLoadedModels models are loaded,
+// and returns the removed models (whose files must be deleted once the lock is released); m.mu must be held
+func (m *ModelManager) evict() []*model {
+	var loaded []*model
+	for _, mdl := range m.models {
+		if isLoaded(mdl) {
+			loaded = append(loaded, mdl)
+		}
+	}
+	if len(loaded) <= m.params.MaxLoadedModels {
+		return nil
+	}
+
+	sort.Slice(loaded, func(i, j int) bool {
+		return loaded[i].lastUsed.Before(loaded[j].lastUsed)
+	})
+
+	var evicted []*model
+	numToEvict := len(loaded) - m.params.MaxLoadedModels
+	for _, mdl := range loaded {
+		if len(evicted) == numToEvict {
+			break
+		}
+		// models which are in use are evicted once they are released
+		if mdl.inUse > 0 {
+			continue
+		}
+		delete(m.models, mdl.name)
+		evicted = append(evicted, mdl)
+	}
+
+	return evicted
+}
+
+func (m *ModelManager) deleteModels(models []*model) {
+	for _, mdl := range models {
+		if m.unload != nil {
+			// the model's files are deleted even if the container couldn't be notified, since the disk would otherwise fill up
+			_ = m.unload(mdl.name, mdl.dir)
+		}
+		_ = os.RemoveAll(mdl.dir)
+	}
+}
+
+// Statuses returns the models which are downloaded or downloading, sorted by name
+func (m *ModelManager) Statuses() []ModelStatus {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	statuses := make([]ModelStatus, 0, len(m.models))
+	for _, mdl := range m.models {
+		statuses = append(statuses, ModelStatus{
+			Name:     mdl.name,
+			Path:     mdl.dir,
+			Loaded:   isLoaded(mdl),
+			InUse:    mdl.inUse,
+			LastUsed: mdl.lastUsed,
+		})
+	}
+
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Name < statuses[j].Name
+	})
+
+	return statuses
+}
+
+func isLoaded(mdl *model) bool {
+	select {
+	case <-mdl.loaded:
+		return mdl.err == nil
+	default:
+		return false
+	}
+}
@@ -0,0 +1,183 @@
+/*
+Copyright 2021 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package modelmanager_test
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/cortexlabs/cortex/pkg/modelmanager"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeS3 struct {
+	downloads int32
+	fail      bool
+	block     chan struct{}
+
+	mu       sync.Mutex
+	unloaded []string
+}
+
+func (f *fakeS3) download(s3Path string, localDir string) error {
+	atomic.AddInt32(&f.downloads, 1)
+	if f.block != nil {
+		<-f.block
+	}
+	if f.fail {
+		return errors.New("download failed")
+	}
+	if err := os.MkdirAll(localDir, os.ModePerm); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filepath.Join(localDir, "model.bin"), []byte(s3Path), 0644)
+}
+
+func (f *fakeS3) unload(name string, localDir string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.unloaded = append(f.unloaded, name)
+	return nil
+}
+
+func (f *fakeS3) unloadedModels() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string{}, f.unloaded...)
+}
+
+// models may be evicted by the download goroutine, after the model which was downloaded has been returned
+func requireUnloaded(t *testing.T, s3 *fakeS3, names ...string) {
+	require.Eventually(t, func() bool {
+		unloaded := s3.unloadedModels()
+		if len(unloaded) != len(names) {
+			return false
+		}
+		for i := range names {
+			if unloaded[i] != names[i] {
+				return false
+			}
+		}
+		return true
+	}, time.Second, 10*time.Millisecond)
+}
+
+func newManager(t *testing.T, s3 *fakeS3, maxLoadedModels int) *modelmanager.ModelManager {
+	return modelmanager.New(modelmanager.Params{
+		Models: map[string]string{
+			"a": "s3://bucket/models/a",
+			"b": "s3://bucket/models/b",
+		},
+		ModelsDir:       t.TempDir(),
+		MaxLoadedModels: maxLoadedModels,
+	}, s3.download, s3.unload)
+}
+
+func TestAcquireDownloadsOnce(t *testing.T) {
+	s3 := &fakeS3{block: make(chan struct{})}
+	manager := newManager(t, s3, 2)
+
+	var wg sync.WaitGroup
+	paths := make([]string, 5)
+	for i := range paths {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			path, err := manager.Acquire(context.Background(), "a")
+			require.NoError(t, err)
+			paths[i] = path
+		}(i)
+	}
+	close(s3.block)
+	wg.Wait()
+
+	require.Equal(t, int32(1), atomic.LoadInt32(&s3.downloads))
+	for _, path := range paths {
+		require.Equal(t, paths[0], path)
+	}
+	require.FileExists(t, filepath.Join(paths[0], "model.bin"))
+}
+
+func TestAcquireUnknownModel(t *testing.T) {
+	manager := newManager(t, &fakeS3{}, 2)
+
+	_, err := manager.Acquire(context.Background(), "c")
+	require.True(t, errors.Is(err, modelmanager.ErrModelNotFound))
+}
+
+func TestLeastRecentlyUsedModelIsEvicted(t *testing.T) {
+	s3 := &fakeS3{}
+	manager := newManager(t, s3, 1)
+
+	pathA, err := manager.Acquire(context.Background(), "a")
+	require.NoError(t, err)
+	manager.Release("a")
+
+	_, err = manager.Acquire(context.Background(), "b")
+	require.NoError(t, err)
+	manager.Release("b")
+
+	requireUnloaded(t, s3, "a")
+	require.Eventually(t, func() bool {
+		_, err := os.Stat(pathA)
+		return os.IsNotExist(err)
+	}, time.Second, 10*time.Millisecond)
+
+	statuses := manager.Statuses()
+	require.Len(t, statuses, 1)
+	require.Equal(t, "b", statuses[0].Name)
+}
+
+func TestModelInUseIsNotEvicted(t *testing.T) {
+	s3 := &fakeS3{}
+	manager := newManager(t, s3, 1)
+
+	pathA, err := manager.Acquire(context.Background(), "a")
+	require.NoError(t, err)
+
+	_, err = manager.Acquire(context.Background(), "b")
+	require.NoError(t, err)
+	manager.Release("b")
+
+	// b is evicted instead, since a is still in use
+	requireUnloaded(t, s3, "b")
+	require.DirExists(t, pathA)
+
+	manager.Release("a")
+	require.Len(t, manager.Statuses(), 1)
+}
+
+func TestFailedDownloadIsRetried(t *testing.T) {
+	s3 := &fakeS3{fail: true}
+	manager := newManager(t, s3, 1)
+
+	_, err := manager.Acquire(context.Background(), "a")
+	require.Error(t, err)
+	require.Empty(t, manager.Statuses())
+
+	s3.fail = false
+	_, err = manager.Acquire(context.Background(), "a")
+	require.NoError(t, err)
+	require.Equal(t, int32(2), atomic.LoadInt32(&s3.downloads))
+}
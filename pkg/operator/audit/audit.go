@@ -0,0 +1,171 @@
+/*
+Copyright 2021 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package audit
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cortexlabs/cortex/pkg/config"
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/lib/logging"
+	"github.com/cortexlabs/cortex/pkg/operator/schema"
+)
+
+var operatorLogger = logging.GetLogger()
+
+const (
+	DefaultListPeriod = 7 * 24 * time.Hour
+	MaxListPeriod     = 90 * 24 * time.Hour // List() lists one S3 prefix per day in the period
+	DefaultListLimit  = 100
+	_dayFormat        = "2006-01-02"
+)
+
+// Actor identifies who performed a control-plane action
+type Actor struct {
+	Principal string
+	ClientID  string
+	SourceIP  string
+}
+
+// actors for the changes which are made by the operator itself
+var (
+	GitOpsActor     = Actor{Principal: "cortex:gitops"}
+	CRDActor        = Actor{Principal: "cortex:crd-reconciler"}
+	ModelWatchActor = Actor{Principal: "cortex:model-watch"}
+)
+
+// events are stored in one directory per day, so that they can be listed by date without reading every event:
+// <cluster_uid>/audit/<yyyy-mm-dd>/<unix_nanos>-<action>-<api_name>.json
+func keysPrefix() string {
+	return filepath.Join(config.ClusterConfig.ClusterUID, "audit") + "/"
+}
+
+func dayPrefix(t time.Time) string {
+	return keysPrefix() + t.UTC().Format(_dayFormat) + "/"
+}
+
+func eventKey(event schema.AuditEvent) string {
+	return dayPrefix(event.Time) + fmt.Sprintf("%d-%s-%s.json", event.Time.UnixNano(), event.Action, event.APIName)
+}
+
+func parseEventKey(key string) (time.Time, string, bool) {
+	split := strings.SplitN(strings.TrimSuffix(filepath.Base(key), ".json"), "-", 3)
+	if len(split) != 3 {
+		return time.Time{}, "", false
+	}
+	nanos, err := strconv.ParseInt(split[0], 10, 64)
+	if err != nil {
+		return time.Time{}, "", false
+	}
+	return time.Unix(0, nanos).UTC(), split[2], true
+}
+
+// Record writes the event to the operator's logs (which are exported to cloudwatch) and to the cluster's bucket;
+// failing to record an event is logged, but doesn't fail the action which is being recorded
+func Record(actor Actor, event schema.AuditEvent) {
+	event.Time = time.Now().UTC()
+	event.Principal = actor.Principal
+	event.ClientID = actor.ClientID
+	event.SourceIP = actor.SourceIP
+
+	operatorLogger.Infow("audit", "audit_event", event)
+
+	if err := config.AWS.UploadJSONToS3(event, config.ClusterConfig.Bucket, eventKey(event)); err != nil {
+		operatorLogger.Error(errors.Wrap(err, "failed to record audit event", string(event.Action), event.APIName))
+	}
+}
+
+func RecordDeployResults(actor Actor, results []schema.DeployResult) {
+	for _, result := range results {
+		action := schema.AuditActionUpdate
+		if result.IsNew {
+			action = schema.AuditActionCreate
+		}
+
+		Record(actor, schema.AuditEvent{
+			Action:  action,
+			APIName: result.Resource.Name,
+			APIKind: result.Resource.Kind.String(),
+			Diffs:   result.Diffs,
+			Message: result.Message,
+			Error:   result.Error,
+		})
+	}
+}
+
+func RecordError(actor Actor, action schema.AuditAction, apiName string, err error) {
+	Record(actor, schema.AuditEvent{
+		Action:  action,
+		APIName: apiName,
+		Error:   errors.ErrorStr(err),
+	})
+}
+
+// List returns the events which were recorded since the given time (most recent first), optionally filtered by api name or api name prefix
+func List(apiName string, apiPrefix string, since time.Time, limit int) ([]schema.AuditEvent, error) {
+	events := []schema.AuditEvent{}
+
+	now := time.Now().UTC()
+	firstDay := since.UTC().Truncate(24 * time.Hour)
+
+	for day := now.Truncate(24 * time.Hour); !day.Before(firstDay); day = day.Add(-24 * time.Hour) {
+		objects, err := config.AWS.ListS3Prefix(config.ClusterConfig.Bucket, dayPrefix(day), false, nil, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		keys := make([]string, 0, len(objects))
+		for _, object := range objects {
+			if object.Key == nil {
+				continue
+			}
+			eventTime, eventAPIName, ok := parseEventKey(*object.Key)
+			if !ok || eventTime.Before(since) {
+				continue
+			}
+			if apiName != "" && eventAPIName != apiName {
+				continue
+			}
+			if !strings.HasPrefix(eventAPIName, apiPrefix) {
+				continue
+			}
+			keys = append(keys, *object.Key)
+		}
+
+		// the keys are prefixed with the event's timestamp, so they sort chronologically (within a day, the number of digits is constant)
+		sort.Sort(sort.Reverse(sort.StringSlice(keys)))
+
+		for _, key := range keys {
+			var event schema.AuditEvent
+			if err := config.AWS.ReadJSONFromS3(&event, config.ClusterConfig.Bucket, key); err != nil {
+				return nil, err
+			}
+			events = append(events, event)
+
+			if limit > 0 && len(events) >= limit {
+				return events, nil
+			}
+		}
+	}
+
+	return events, nil
+}
@@ -0,0 +1,174 @@
+/*
+Copyright 2021 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package crds
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/cortexlabs/cortex/pkg/config"
+	api "github.com/cortexlabs/cortex/pkg/crds/apis/api/v1alpha1"
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	libjson "github.com/cortexlabs/cortex/pkg/lib/json"
+	"github.com/cortexlabs/cortex/pkg/lib/logging"
+	"github.com/cortexlabs/cortex/pkg/lib/slices"
+	"github.com/cortexlabs/cortex/pkg/operator/audit"
+	"github.com/cortexlabs/cortex/pkg/operator/history"
+	"github.com/cortexlabs/cortex/pkg/operator/resources"
+	"github.com/cortexlabs/cortex/pkg/operator/schema"
+	"github.com/cortexlabs/cortex/pkg/types/userconfig"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var operatorLogger = logging.GetLogger()
+
+const ReconcileCronPeriod = 10 * time.Second
+
+// Reconcile deploys the cortex api resources (e.g. RealtimeAPI) which have been created or updated since they were last deployed,
+// and deletes the apis of resources which are being deleted
+func Reconcile() error {
+	ctx := context.Background()
+
+	var errs []error
+	for _, list := range api.NewObjectLists() {
+		if err := config.K8s.List(ctx, list, client.InNamespace(config.K8s.Namespace)); err != nil {
+			return err
+		}
+
+		for _, obj := range list.Objects() {
+			if err := reconcileObject(ctx, obj); err != nil {
+				errs = append(errs, errors.Wrap(err, obj.APIKind().String(), obj.GetName()))
+			}
+		}
+	}
+
+	if errors.HasError(errs) {
+		return errors.FirstError(errs...)
+	}
+	return nil
+}
+
+func reconcileObject(ctx context.Context, obj api.Object) error {
+	if !obj.GetDeletionTimestamp().IsZero() {
+		if !slices.HasString(obj.GetFinalizers(), api.Finalizer) {
+			return nil
+		}
+
+		if _, err := resources.DeleteAPI(obj.GetName(), false); err != nil && errors.GetKind(err) != resources.ErrAPINotDeployed {
+			audit.RecordError(audit.CRDActor, schema.AuditActionDelete, obj.GetName(), err)
+			return err
+		}
+		audit.Record(audit.CRDActor, schema.AuditEvent{
+			Action:  schema.AuditActionDelete,
+			APIName: obj.GetName(),
+			APIKind: obj.APIKind().String(),
+		})
+		operatorLogger.Infof("deleted %s (%s)", obj.GetName(), obj.APIKind().String())
+
+		obj.SetFinalizers(slices.RemoveString(obj.GetFinalizers(), api.Finalizer))
+		return config.K8s.Update(ctx, obj)
+	}
+
+	if !slices.HasString(obj.GetFinalizers(), api.Finalizer) {
+		obj.SetFinalizers(append(obj.GetFinalizers(), api.Finalizer))
+		if err := config.K8s.Update(ctx, obj); err != nil {
+			return err
+		}
+	}
+
+	status := obj.APIStatus()
+	if status.ObservedGeneration == obj.GetGeneration() {
+		// failed deployments are retried once the resource is updated
+		if status.Error != "" {
+			return nil
+		}
+
+		// the api is re-deployed if it was deleted outside of the resource (e.g. with `cortex delete`)
+		deployedResource, err := resources.GetDeployedResourceByNameOrNil(obj.GetName())
+		if err != nil {
+			return err
+		}
+		if deployedResource != nil {
+			return nil
+		}
+	}
+
+	newStatus := api.APIStatus{
+		ObservedGeneration: obj.GetGeneration(),
+	}
+
+	configBytes, err := apiConfigBytes(obj)
+	if err != nil {
+		newStatus.Error = errors.Message(err)
+	} else {
+		// the resource is the source of truth for the api, so in-progress updates are overridden (as with `cortex deploy --force`)
+		results, err := resources.Deploy(configFileName(obj), configBytes, true, false)
+		if err != nil {
+			newStatus.Error = errors.Message(err)
+		} else if len(results) > 0 {
+			audit.RecordDeployResults(audit.CRDActor, results)
+			history.RecordDeployResults(audit.CRDActor, results)
+
+			if results[0].Error != "" {
+				newStatus.Error = results[0].Error
+			} else {
+				newStatus.Message = results[0].Message
+				if results[0].API != nil {
+					newStatus.APIID = results[0].API.Spec.ID
+					newStatus.Endpoint = results[0].API.Endpoint
+				}
+			}
+		}
+	}
+
+	if newStatus.Error != "" {
+		operatorLogger.Infof("failed to deploy %s (%s): %s", obj.GetName(), obj.APIKind().String(), newStatus.Error)
+	} else {
+		operatorLogger.Infof("%s (%s): %s", obj.GetName(), obj.APIKind().String(), newStatus.Message)
+	}
+
+	*status = newStatus
+	return config.K8s.Status().Update(ctx, obj)
+}
+
+// used to identify the resource in error messages
+func configFileName(obj api.Object) string {
+	return strings.ToLower(obj.APIKind().String()) + "/" + obj.GetName()
+}
+
+// converts the resource into the format of a cortex api configuration file (a list of api configurations)
+func apiConfigBytes(obj api.Object) ([]byte, error) {
+	apiConfig := map[string]interface{}{}
+
+	if raw := obj.APISpec().Raw; len(raw) > 0 {
+		if err := libjson.Unmarshal(raw, &apiConfig); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, key := range []string{userconfig.NameKey, userconfig.KindKey} {
+		if _, ok := apiConfig[key]; ok {
+			return nil, errors.Wrap(ErrorFieldSetByResource(), key)
+		}
+	}
+
+	apiConfig[userconfig.NameKey] = obj.GetName()
+	apiConfig[userconfig.KindKey] = obj.APIKind().String()
+
+	return libjson.Marshal([]map[string]interface{}{apiConfig})
+}
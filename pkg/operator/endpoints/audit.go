@@ -0,0 +1,95 @@
+/*
+Copyright 2021 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoints
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cortexlabs/cortex/pkg/operator/audit"
+	"github.com/cortexlabs/cortex/pkg/operator/schema"
+)
+
+func GetAuditEvents(w http.ResponseWriter, r *http.Request) {
+	apiName := getOptionalQParam("apiName", r)
+	if apiName != "" {
+		if err := authorizeAPI(r, apiName); err != nil {
+			respondErrorCode(w, r, http.StatusForbidden, err)
+			return
+		}
+	}
+
+	apiPrefix := requestAccess(r).APIPrefix
+
+	since := time.Now().Add(-audit.DefaultListPeriod)
+	if sinceStr := getOptionalQParam("since", r); sinceStr != "" {
+		period, err := time.ParseDuration(sinceStr)
+		if err != nil || period <= 0 || period > audit.MaxListPeriod {
+			respondError(w, r, ErrorInvalidQueryParam("since", sinceStr, fmt.Sprintf("a positive duration no longer than %dh (e.g. 24h)", int(audit.MaxListPeriod.Hours()))))
+			return
+		}
+		since = time.Now().Add(-period)
+	}
+
+	limit := audit.DefaultListLimit
+	if limitStr := getOptionalQParam("limit", r); limitStr != "" {
+		var err error
+		limit, err = strconv.Atoi(limitStr)
+		if err != nil || limit <= 0 {
+			respondError(w, r, ErrorInvalidQueryParam("limit", limitStr, "a positive integer"))
+			return
+		}
+	}
+
+	events, err := audit.List(apiName, apiPrefix, since, limit)
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+
+	respondJSON(w, r, schema.AuditResponse{Events: events})
+}
+
+func auditActor(r *http.Request) audit.Actor {
+	actor := audit.Actor{
+		SourceIP: sourceIP(r),
+	}
+	if principal, ok := r.Context().Value(ctxKeyPrincipal).(string); ok {
+		actor.Principal = principal
+	}
+	if clientID, ok := r.Context().Value(ctxKeyClient).(string); ok {
+		actor.ClientID = clientID
+	}
+	return actor
+}
+
+// requests are proxied to the operator by the istio ingress gateway, which appends the address of its downstream peer to the X-Forwarded-For header;
+// earlier entries are set by the client and can't be trusted, so only the last one is used
+func sourceIP(r *http.Request) string {
+	if forwardedFor := r.Header.Get("X-Forwarded-For"); forwardedFor != "" {
+		hops := strings.Split(forwardedFor, ",")
+		return strings.TrimSpace(hops[len(hops)-1])
+	}
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		return host
+	}
+	return r.RemoteAddr
+}
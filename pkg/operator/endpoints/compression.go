@@ -0,0 +1,130 @@
+/*
+Copyright 2021 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoints
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gorilla/handlers"
+	"github.com/klauspost/compress/zstd"
+)
+
+var _zstdEncoders = sync.Pool{
+	New: func() interface{} {
+		encoder, _ := zstd.NewWriter(nil, zstd.WithEncoderConcurrency(1))
+		return encoder
+	},
+}
+
+// CompressionMiddleware compresses responses with zstd if the client accepts it, and with gzip or deflate otherwise (see handlers.CompressHandler);
+// websocket upgrades (e.g. for streaming logs) aren't compressed
+func CompressionMiddleware(next http.Handler) http.Handler {
+	compressHandler := handlers.CompressHandler(next)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Upgrade") != "" || !acceptsEncoding(r.Header, "zstd") {
+			compressHandler.ServeHTTP(w, r)
+			return
+		}
+
+		w.Header().Add("Vary", "Accept-Encoding")
+		zw := &zstdResponseWriter{ResponseWriter: w}
+		defer zw.close()
+		next.ServeHTTP(zw, r)
+	})
+}
+
+// acceptsEncoding returns whether the request's Accept-Encoding header allows the encoding (i.e. it's listed without q=0)
+func acceptsEncoding(header http.Header, encoding string) bool {
+	for _, value := range header.Values("Accept-Encoding") {
+		for _, part := range strings.Split(value, ",") {
+			params := strings.Split(part, ";")
+			if !strings.EqualFold(strings.TrimSpace(params[0]), encoding) {
+				continue
+			}
+			for _, param := range params[1:] {
+				param = strings.TrimSpace(param)
+				if strings.HasPrefix(param, "q=") {
+					if q, err := strconv.ParseFloat(strings.TrimPrefix(param, "q="), 64); err == nil && q == 0 {
+						return false
+					}
+				}
+			}
+			return true
+		}
+	}
+	return false
+}
+
+// zstdResponseWriter only compresses responses which have a body, so that e.g. 304 responses are sent as is
+type zstdResponseWriter struct {
+	http.ResponseWriter
+	encoder    *zstd.Encoder
+	statusCode int
+}
+
+func (zw *zstdResponseWriter) WriteHeader(statusCode int) {
+	if zw.encoder == nil && zw.statusCode == 0 {
+		zw.statusCode = statusCode
+	}
+}
+
+func (zw *zstdResponseWriter) Write(b []byte) (int, error) {
+	if zw.encoder == nil {
+		if len(b) == 0 {
+			return 0, nil
+		}
+		if zw.Header().Get("Content-Type") == "" {
+			zw.Header().Set("Content-Type", http.DetectContentType(b))
+		}
+		zw.Header().Del("Content-Length")
+		zw.Header().Set("Content-Encoding", "zstd")
+		zw.writeStatusCode()
+
+		zw.encoder = _zstdEncoders.Get().(*zstd.Encoder)
+		zw.encoder.Reset(zw.ResponseWriter)
+	}
+	return zw.encoder.Write(b)
+}
+
+func (zw *zstdResponseWriter) Flush() {
+	if zw.encoder != nil {
+		zw.encoder.Flush()
+	}
+	if flusher, ok := zw.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+func (zw *zstdResponseWriter) writeStatusCode() {
+	if zw.statusCode != 0 {
+		zw.ResponseWriter.WriteHeader(zw.statusCode)
+		zw.statusCode = 0
+	}
+}
+
+func (zw *zstdResponseWriter) close() {
+	if zw.encoder == nil {
+		zw.writeStatusCode()
+		return
+	}
+	zw.encoder.Close()
+	zw.encoder.Reset(nil)
+	_zstdEncoders.Put(zw.encoder)
+}
@@ -0,0 +1,454 @@
+/*
+Copyright 2021 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoints
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/cortexlabs/cortex/pkg/consts"
+)
+
+// the console is a static page which calls the operator's endpoints from the browser (with the operator token or OIDC id token which the user signs in with),
+// so the page itself doesn't require authentication
+func Console(w http.ResponseWriter, r *http.Request) {
+	setConsoleHeaders(w, "text/html; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(strings.Replace(_consoleHTML, "{{CORTEX_VERSION}}", consts.CortexVersion, 1)))
+}
+
+func ConsoleScript(w http.ResponseWriter, r *http.Request) {
+	setConsoleHeaders(w, "application/javascript; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(_consoleScript))
+}
+
+func setConsoleHeaders(w http.ResponseWriter, contentType string) {
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Content-Security-Policy", "default-src 'self'; style-src 'self' 'unsafe-inline'; frame-ancestors 'none'")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("X-Frame-Options", "DENY")
+	w.Header().Set("Referrer-Policy", "no-referrer")
+}
+
+const _consoleHTML = `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<meta name="cortex-api-version" content="{{CORTEX_VERSION}}">
+<title>cortex</title>
+<style>
+body { margin: 0; font-family: -apple-system, BlinkMacSystemFont, "Segoe UI", Helvetica, Arial, sans-serif; font-size: 14px; color: #1f2933; background: #f5f7fa; }
+header { display: flex; align-items: center; gap: 24px; padding: 12px 24px; background: #1f2933; color: #fff; }
+header a { color: #cbd2d9; text-decoration: none; }
+header a.active, header a:hover { color: #fff; }
+header .spacer { flex: 1; }
+main { padding: 24px; }
+h1 { font-size: 20px; margin: 0 0 16px; }
+h2 { font-size: 16px; margin: 24px 0 8px; }
+table { border-collapse: collapse; background: #fff; min-width: 480px; }
+th, td { text-align: left; padding: 6px 12px; border-bottom: 1px solid #e4e7eb; }
+th { font-weight: 600; background: #f0f4f8; }
+tr.link { cursor: pointer; }
+tr.link:hover { background: #f0f4f8; }
+dl { display: grid; grid-template-columns: max-content auto; gap: 6px 16px; margin: 0; }
+dt { font-weight: 600; }
+dd { margin: 0; }
+button, input { font: inherit; padding: 6px 12px; }
+.error { color: #b91c1c; margin: 8px 0; }
+.muted { color: #7b8794; }
+.charts { display: flex; flex-wrap: wrap; gap: 24px; }
+.chart { background: #fff; padding: 8px 12px; }
+.actions { display: flex; gap: 12px; margin: 16px 0; }
+</style>
+</head>
+<body>
+<header>
+<strong>cortex</strong>
+<a href="#/" id="nav-apis">apis</a>
+<a href="#/cluster" id="nav-cluster">cluster</a>
+<span class="spacer"></span>
+<a href="#" id="sign-out" hidden>sign out</a>
+</header>
+<main id="main"></main>
+<script src="console/console.js"></script>
+</body>
+</html>
+`
+
+const _consoleScript = `"use strict";
+
+var API_VERSION = document.querySelector('meta[name="cortex-api-version"]').content;
+var REFRESH_INTERVAL_MS = 5000;
+var MAX_CHART_POINTS = 120;
+
+var main = document.getElementById("main");
+var samples = {}; // api name -> [{time, latency, requests}] (collected while the console is open)
+var timer = null;
+
+function el(tag, attrs, children) {
+  var node = document.createElement(tag);
+  Object.keys(attrs || {}).forEach(function (key) {
+    if (key === "onclick") {
+      node.addEventListener("click", attrs[key]);
+    } else {
+      node.setAttribute(key, attrs[key]);
+    }
+  });
+  (children || []).forEach(function (child) {
+    if (child === null || child === undefined) {
+      return;
+    }
+    node.appendChild(typeof child === "string" || typeof child === "number" ? document.createTextNode(String(child)) : child);
+  });
+  return node;
+}
+
+function render(nodes) {
+  main.textContent = "";
+  nodes.forEach(function (node) {
+    if (node) {
+      main.appendChild(node);
+    }
+  });
+}
+
+function table(headers, rows, onclick) {
+  return el("table", {}, [
+    el("thead", {}, [el("tr", {}, headers.map(function (h) { return el("th", {}, [h]); }))]),
+    el("tbody", {}, rows.map(function (row, i) {
+      var attrs = onclick ? { "class": "link", onclick: function () { onclick(i); } } : {};
+      return el("tr", attrs, row.map(function (cell) { return el("td", {}, [cell]); }));
+    })),
+  ]);
+}
+
+function keyValues(pairs) {
+  var children = [];
+  pairs.forEach(function (pair) {
+    if (pair[1] === null || pair[1] === undefined || pair[1] === "") {
+      return;
+    }
+    children.push(el("dt", {}, [pair[0]]));
+    children.push(el("dd", {}, [pair[1]]));
+  });
+  return el("dl", {}, children);
+}
+
+function token() {
+  return sessionStorage.getItem("cortex-token");
+}
+
+function request(path) {
+  var headers = { "CortexAPIVersion": API_VERSION };
+  // OIDC id tokens are JWTs (three dot-separated segments); anything else is treated as an operator token
+  if (token().split(".").length === 3) {
+    headers["Authorization"] = "Bearer " + token();
+  } else {
+    headers["X-Cortex-Token"] = token();
+  }
+
+  return fetch(path, { headers: headers }).then(function (response) {
+    return response.json().catch(function () { return {}; }).then(function (body) {
+      if (response.status === 401) {
+        sessionStorage.removeItem("cortex-token");
+        showSignIn(body.message || "unauthorized");
+        throw null;
+      }
+      if (!response.ok) {
+        throw new Error(body.message || response.statusText);
+      }
+      return body;
+    });
+  });
+}
+
+function statusStr(code) {
+  return String(code || "unknown").replace(/^status_/, "").replace(/_/g, " ");
+}
+
+function timeStr(value) {
+  if (!value) {
+    return "-";
+  }
+  var date = typeof value === "number" ? new Date(value * 1000) : new Date(value);
+  return date.toLocaleString();
+}
+
+function showSignIn(message) {
+  clearInterval(timer);
+  document.getElementById("sign-out").hidden = true;
+  var input = el("input", { type: "password", placeholder: "operator token or OIDC id token", size: "48" });
+  var form = el("form", {}, [input, " ", el("button", { type: "submit" }, ["sign in"])]);
+  form.addEventListener("submit", function (event) {
+    event.preventDefault();
+    if (input.value.trim() !== "") {
+      sessionStorage.setItem("cortex-token", input.value.trim());
+      route();
+    }
+  });
+  render([
+    el("h1", {}, ["sign in"]),
+    el("p", { "class": "muted" }, ["create an operator token with ", el("code", {}, ["cortex auth token create"])]),
+    form,
+    message ? el("div", { "class": "error" }, [message]) : null,
+  ]);
+}
+
+// the window is opened before the request so that it isn't blocked as a pop-up
+function openLogs(apiName, jobID) {
+  var win = window.open("", "_blank");
+  win.opener = null;
+  var path = "logs/" + encodeURIComponent(apiName) + (jobID ? "?jobID=" + encodeURIComponent(jobID) : "");
+  request(path).then(function (body) {
+    win.location = body.log_url;
+  }).catch(function (err) {
+    win.close();
+    showError(err);
+  });
+}
+
+function showError(err) {
+  if (err) {
+    main.appendChild(el("div", { "class": "error" }, [err.message]));
+  }
+}
+
+function recordSample(api) {
+  var stats = api.metrics && api.metrics.network_stats;
+  if (!stats) {
+    return;
+  }
+  var points = samples[api.spec.name] = samples[api.spec.name] || [];
+  points.push({ time: Date.now(), latency: stats.latency, requests: stats.total });
+  if (points.length > MAX_CHART_POINTS) {
+    points.shift();
+  }
+}
+
+function chart(title, points, unit) {
+  var width = 360, height = 120;
+  var values = points.map(function (p) { return p === null || p === undefined ? 0 : p; });
+  var max = Math.max.apply(null, values.concat([1]));
+  var coords = values.map(function (v, i) {
+    var x = values.length === 1 ? 0 : (i / (values.length - 1)) * width;
+    var y = height - (v / max) * height;
+    return x.toFixed(1) + "," + y.toFixed(1);
+  }).join(" ");
+
+  var svg = document.createElementNS("http://www.w3.org/2000/svg", "svg");
+  svg.setAttribute("width", width);
+  svg.setAttribute("height", height);
+  var line = document.createElementNS("http://www.w3.org/2000/svg", "polyline");
+  line.setAttribute("points", coords);
+  line.setAttribute("fill", "none");
+  line.setAttribute("stroke", "#2563eb");
+  line.setAttribute("stroke-width", "2");
+  svg.appendChild(line);
+
+  var latest = values.length > 0 ? values[values.length - 1] : 0;
+  return el("div", { "class": "chart" }, [
+    el("div", {}, [el("strong", {}, [title]), " ", el("span", { "class": "muted" }, [Math.round(latest) + unit + " (max " + Math.round(max) + unit + ")"])]),
+    svg,
+  ]);
+}
+
+function showAPIs() {
+  return request("get").then(function (apis) {
+    apis.sort(function (a, b) { return a.spec.name < b.spec.name ? -1 : 1; });
+    apis.forEach(recordSample);
+    var rows = apis.map(function (api) {
+      var status = api.status;
+      var stats = api.metrics && api.metrics.network_stats;
+      return [
+        api.spec.name,
+        api.spec.kind,
+        api.spec.project || "-",
+        status ? statusStr(status.status_code) : "-",
+        status ? status.replica_counts.updated.ready + " / " + status.replica_counts.requested : "-",
+        stats && stats.latency ? Math.round(stats.latency) + " ms" : "-",
+        stats ? stats.code_2xx + " / " + stats.code_4xx + " / " + stats.code_5xx : "-",
+        timeStr(api.spec.last_updated),
+      ];
+    });
+    render([
+      el("h1", {}, ["apis"]),
+      apis.length === 0 ? el("p", { "class": "muted" }, ["no apis are deployed"]) :
+        table(["name", "kind", "project", "status", "ready / requested", "avg request", "2XX / 4XX / 5XX", "last update"], rows, function (i) {
+          location.hash = "#/apis/" + encodeURIComponent(apis[i].spec.name);
+        }),
+    ]);
+  });
+}
+
+function showAPI(apiName) {
+  return request("get/" + encodeURIComponent(apiName)).then(function (apis) {
+    if (apis.length === 0) {
+      throw new Error("api " + apiName + " was not found");
+    }
+    var api = apis[0];
+    recordSample(api);
+
+    var status = api.status;
+    var kind = api.spec.kind;
+    var nodes = [
+      el("h1", {}, [apiName]),
+      keyValues([
+        ["kind", kind],
+        ["project", api.spec.project],
+        ["status", status ? statusStr(status.status_code) : null],
+        ["up-to-date / requested", status ? status.replica_counts.updated.ready + " / " + status.replica_counts.requested : null],
+        ["stale", status && status.replica_counts.stale.ready > 0 ? status.replica_counts.stale.ready : null],
+        ["endpoint", api.endpoint],
+        ["labels", api.spec.labels ? Object.keys(api.spec.labels).map(function (k) { return k + "=" + api.spec.labels[k]; }).join(", ") : null],
+        ["last update", timeStr(api.spec.last_updated)],
+      ]),
+    ];
+
+    var actions = [];
+    if (kind === "RealtimeAPI" || kind === "AsyncAPI") {
+      actions.push(el("button", { onclick: function () { openLogs(apiName); } }, ["logs"]));
+    }
+    if (api.dashboard_url) {
+      actions.push(el("a", { href: api.dashboard_url, target: "_blank", rel: "noopener" }, ["metrics dashboard"]));
+    }
+    nodes.push(el("div", { "class": "actions" }, actions));
+
+    var points = samples[apiName] || [];
+    if (points.length > 0) {
+      nodes.push(el("h2", {}, ["metrics ", el("span", { "class": "muted" }, ["(sampled every " + REFRESH_INTERVAL_MS / 1000 + "s while this page is open)"])]));
+      nodes.push(el("div", { "class": "charts" }, [
+        chart("avg request", points.map(function (p) { return p.latency; }), " ms"),
+        chart("requests", points.map(function (p) { return p.requests; }), ""),
+      ]));
+    }
+
+    var jobs = api.batch_job_statuses || api.task_job_statuses;
+    if (kind === "BatchAPI" || kind === "TaskAPI") {
+      jobs = (jobs || []).slice().sort(function (a, b) { return a.start_time < b.start_time ? 1 : -1; });
+      nodes.push(el("h2", {}, ["jobs"]));
+      nodes.push(jobs.length === 0 ? el("p", { "class": "muted" }, ["no submitted jobs"]) :
+        table(["job id", "status", "start time", "end time"], jobs.map(function (job) {
+          return [job.job_id, statusStr(job.status), timeStr(job.start_time), timeStr(job.end_time)];
+        }), function (i) {
+          location.hash = "#/apis/" + encodeURIComponent(apiName) + "/jobs/" + encodeURIComponent(jobs[i].job_id);
+        }));
+    }
+
+    render(nodes);
+  });
+}
+
+function showJob(apiName, jobID) {
+  return request("get/" + encodeURIComponent(apiName)).then(function (apis) {
+    if (apis.length === 0) {
+      throw new Error("api " + apiName + " was not found");
+    }
+    var path = (apis[0].spec.kind === "BatchAPI" ? "batch/" : "tasks/") + encodeURIComponent(apiName) + "?jobID=" + encodeURIComponent(jobID);
+    return request(path);
+  }).then(function (res) {
+    var job = res.job_status;
+    var workers = job.worker_counts || {};
+    var metrics = job.batch_metrics;
+    render([
+      el("h1", {}, [el("a", { href: "#/apis/" + encodeURIComponent(apiName) }, [apiName]), " / ", jobID]),
+      keyValues([
+        ["status", statusStr(job.status)],
+        ["start time", timeStr(job.start_time)],
+        ["end time", timeStr(job.end_time)],
+        ["batches in queue", job.batches_in_queue],
+        ["succeeded batches", metrics ? metrics.succeeded : null],
+        ["failed batches", metrics ? metrics.failed : null],
+        ["workers", Object.keys(workers).map(function (k) { return workers[k] + " " + k; }).join(", ")],
+      ]),
+      el("div", { "class": "actions" }, [el("button", { onclick: function () { openLogs(apiName, jobID); } }, ["logs"])]),
+    ]);
+  });
+}
+
+function showCluster() {
+  return request("info").then(function (info) {
+    var nodes = info.node_infos || [];
+    render([
+      el("h1", {}, ["cluster"]),
+      keyValues([
+        ["cluster", info.cluster_config.cluster_name],
+        ["region", info.cluster_config.region],
+        ["version", info.cluster_config.api_version],
+        ["instances", nodes.length],
+        ["unscheduled replicas", info.num_pending_replicas],
+      ]),
+      el("h2", {}, ["instances"]),
+      table(["instance type", "lifecycle", "replicas", "CPU (requested / allocatable)", "memory (requested / allocatable)", "GPU (requested / allocatable)"], nodes.map(function (node) {
+        return [
+          node.instance_type,
+          node.is_spot ? "spot" : "on-demand",
+          node.num_replicas,
+          node.compute_user_requested.cpu + " / " + node.compute_user_capacity.cpu,
+          node.compute_user_requested.mem + " / " + node.compute_user_capacity.mem,
+          node.compute_user_requested.gpu + " / " + node.compute_user_capacity.gpu,
+        ];
+      })),
+    ]);
+  });
+}
+
+function route() {
+  clearInterval(timer);
+  if (!token()) {
+    showSignIn();
+    return;
+  }
+  document.getElementById("sign-out").hidden = false;
+
+  var parts = location.hash.replace(/^#\/?/, "").split("/").map(decodeURIComponent);
+  var show;
+  if (parts[0] === "cluster") {
+    show = showCluster;
+  } else if (parts[0] === "apis" && parts[1] && parts[2] === "jobs" && parts[3]) {
+    show = function () { return showJob(parts[1], parts[3]); };
+  } else if (parts[0] === "apis" && parts[1]) {
+    show = function () { return showAPI(parts[1]); };
+  } else {
+    show = showAPIs;
+  }
+
+  document.getElementById("nav-apis").className = parts[0] !== "cluster" ? "active" : "";
+  document.getElementById("nav-cluster").className = parts[0] === "cluster" ? "active" : "";
+
+  var refresh = function () {
+    show().catch(function (err) {
+      if (err) {
+        render([el("div", { "class": "error" }, [err.message])]);
+      }
+    });
+  };
+  refresh();
+  timer = setInterval(refresh, REFRESH_INTERVAL_MS);
+}
+
+document.getElementById("sign-out").addEventListener("click", function (event) {
+  event.preventDefault();
+  sessionStorage.removeItem("cortex-token");
+  showSignIn();
+});
+
+window.addEventListener("hashchange", route);
+route();
+`
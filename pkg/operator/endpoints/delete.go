@@ -19,7 +19,9 @@ package endpoints
 import (
 	"net/http"
 
+	"github.com/cortexlabs/cortex/pkg/operator/audit"
 	"github.com/cortexlabs/cortex/pkg/operator/resources"
+	"github.com/cortexlabs/cortex/pkg/operator/schema"
 	"github.com/gorilla/mux"
 )
 
@@ -29,8 +31,15 @@ func Delete(w http.ResponseWriter, r *http.Request) {
 
 	response, err := resources.DeleteAPI(apiName, keepCache)
 	if err != nil {
+		audit.RecordError(auditActor(r), schema.AuditActionDelete, apiName, err)
 		respondError(w, r, err)
 		return
 	}
+
+	audit.Record(auditActor(r), schema.AuditEvent{
+		Action:  schema.AuditActionDelete,
+		APIName: apiName,
+		Message: response.Message,
+	})
 	respondJSON(w, r, response)
 }
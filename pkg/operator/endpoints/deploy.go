@@ -21,11 +21,14 @@ import (
 
 	"github.com/cortexlabs/cortex/pkg/lib/errors"
 	"github.com/cortexlabs/cortex/pkg/lib/files"
+	"github.com/cortexlabs/cortex/pkg/operator/audit"
+	"github.com/cortexlabs/cortex/pkg/operator/history"
 	"github.com/cortexlabs/cortex/pkg/operator/resources"
 )
 
 func Deploy(w http.ResponseWriter, r *http.Request) {
 	force := getOptionalBoolQParam("force", false, r)
+	project := getOptionalBoolQParam("project", false, r)
 
 	configFileName, err := getRequiredQueryParam("configFileName", r)
 	if err != nil {
@@ -42,7 +45,45 @@ func Deploy(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	response, err := resources.Deploy(configFileName, configBytes, force)
+	if err := authorizeAPIConfigs(r, configFileName, configBytes); err != nil {
+		respondErrorCode(w, r, http.StatusForbidden, err)
+		return
+	}
+
+	response, err := resources.Deploy(configFileName, configBytes, force, project)
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+
+	audit.RecordDeployResults(auditActor(r), response)
+	history.RecordDeployResults(auditActor(r), response)
+
+	respondJSON(w, r, response)
+}
+
+func DeployDiff(w http.ResponseWriter, r *http.Request) {
+	configFileName, err := getRequiredQueryParam("configFileName", r)
+	if err != nil {
+		respondError(w, r, errors.WithStack(err))
+		return
+	}
+
+	configBytes, err := files.ReadReqFile(r, "config")
+	if err != nil {
+		respondError(w, r, errors.WithStack(err))
+		return
+	} else if len(configBytes) == 0 {
+		respondError(w, r, ErrorFormFileMustBeProvided("config"))
+		return
+	}
+
+	if err := authorizeAPIConfigs(r, configFileName, configBytes); err != nil {
+		respondErrorCode(w, r, http.StatusForbidden, err)
+		return
+	}
+
+	response, err := resources.DeployDiff(configFileName, configBytes)
 	if err != nil {
 		respondError(w, r, err)
 		return
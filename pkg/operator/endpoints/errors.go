@@ -22,6 +22,7 @@ import (
 	"github.com/cortexlabs/cortex/pkg/lib/errors"
 	s "github.com/cortexlabs/cortex/pkg/lib/strings"
 	"github.com/cortexlabs/cortex/pkg/operator/operator"
+	"github.com/cortexlabs/cortex/pkg/types/rbac"
 )
 
 const (
@@ -37,6 +38,14 @@ const (
 	ErrAnyQueryParamRequired  = "endpoints.any_query_param_required"
 	ErrAnyPathParamRequired   = "endpoints.any_path_param_required"
 	ErrLogsJobIDRequired      = "endpoints.logs_job_id_required"
+	ErrInvalidQueryParam      = "endpoints.invalid_query_param"
+	ErrForbiddenRole          = "endpoints.forbidden_role"
+	ErrForbiddenAPI           = "endpoints.forbidden_api"
+	ErrOIDCNotConfigured      = "endpoints.oidc_not_configured"
+	ErrOIDCNoRole             = "endpoints.oidc_no_role"
+	ErrNoRunningReplicas      = "endpoints.no_running_replicas"
+	ErrReplicaNotFound        = "endpoints.replica_not_found"
+	ErrContainerNotFound      = "endpoints.container_not_found"
 )
 
 func ErrorAPIVersionMismatch(operatorVersion string, clientVersion string) error {
@@ -123,3 +132,59 @@ func ErrorLogsJobIDRequired(resource operator.DeployedResource) error {
 		Message: fmt.Sprintf("job id is required for %s; you can get a list of latest job ids with `cortex get %s` and use `cortex logs %s JOB_ID` to get the logs", resource.UserString(), resource.Name, resource.Name),
 	})
 }
+
+func ErrorInvalidQueryParam(param string, value string, expected string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrInvalidQueryParam,
+		Message: fmt.Sprintf("invalid value for query param %s (%s); expected %s", param, value, expected),
+	})
+}
+
+func ErrorForbiddenRole(role rbac.Role, method string, path string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrForbiddenRole,
+		Message: fmt.Sprintf("your role (%s) is not allowed to perform this action (%s %s)", role.String(), method, path),
+	})
+}
+
+func ErrorForbiddenAPI(apiName string, apiPrefix string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrForbiddenAPI,
+		Message: fmt.Sprintf("you are not allowed to access api %s (your access is limited to apis whose names start with %s)", apiName, s.UserStr(apiPrefix)),
+	})
+}
+
+func ErrorOIDCNotConfigured() error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrOIDCNotConfigured,
+		Message: "OIDC authentication is not configured for this cluster; add an `oidc` section to your cluster configuration to enable it",
+	})
+}
+
+func ErrorOIDCNoRole(username string, rolesClaim string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrOIDCNoRole,
+		Message: fmt.Sprintf("%s is not granted a role by the cluster's OIDC role mappings (based on the %s claim); ask a cluster admin for access", username, s.UserStr(rolesClaim)),
+	})
+}
+
+func ErrorNoRunningReplicas(resource operator.DeployedResource) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrNoRunningReplicas,
+		Message: fmt.Sprintf("%s doesn't have any running replicas", resource.UserString()),
+	})
+}
+
+func ErrorReplicaNotFound(replica string, resource operator.DeployedResource, runningReplicas []string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrReplicaNotFound,
+		Message: fmt.Sprintf("%s is not a running replica of %s; running replicas: %s", replica, resource.UserString(), s.StrsAnd(runningReplicas)),
+	})
+}
+
+func ErrorContainerNotFound(container string, replica string, containers []string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrContainerNotFound,
+		Message: fmt.Sprintf("replica %s doesn't have a container named %s; containers: %s", replica, container, s.StrsAnd(containers)),
+	})
+}
@@ -0,0 +1,314 @@
+/*
+Copyright 2021 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoints
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+
+	"github.com/cortexlabs/cortex/pkg/config"
+	"github.com/cortexlabs/cortex/pkg/consts"
+	"github.com/cortexlabs/cortex/pkg/lib/k8s"
+	"github.com/cortexlabs/cortex/pkg/lib/slices"
+	"github.com/cortexlabs/cortex/pkg/operator/lib/routines"
+	"github.com/cortexlabs/cortex/pkg/operator/operator"
+	"github.com/cortexlabs/cortex/pkg/operator/resources"
+	"github.com/cortexlabs/cortex/pkg/types/userconfig"
+	"github.com/gorilla/mux"
+	"github.com/gorilla/websocket"
+	kcore "k8s.io/api/core/v1"
+	kremotecommand "k8s.io/client-go/tools/remotecommand"
+	kexec "k8s.io/client-go/util/exec"
+)
+
+// the exec and port-forward sockets carry binary messages in both directions (stdin, stdout, and the forwarded connection's bytes);
+// for exec, text messages carry stderr (from the operator) and terminal resizes (from the client), and an empty binary message from the client closes stdin.
+// When the command exits, the operator closes the socket with the command's exit code as the close reason
+
+const _istioProxyContainerName = "istio-proxy"
+
+// closing reasons are limited to 123 bytes
+const _maxCloseReasonLength = 123
+
+type terminalSize struct {
+	Width  uint16 `json:"width"`
+	Height uint16 `json:"height"`
+}
+
+type terminalSizeQueue chan kremotecommand.TerminalSize
+
+func (queue terminalSizeQueue) Next() *kremotecommand.TerminalSize {
+	size, ok := <-queue
+	if !ok {
+		return nil
+	}
+	return &size
+}
+
+// socketWriter writes binary or text messages to the socket, which doesn't support concurrent writes
+type socketWriter struct {
+	socket      *websocket.Conn
+	mutex       *sync.Mutex
+	messageType int
+}
+
+func (w socketWriter) Write(p []byte) (int, error) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	if err := w.socket.WriteMessage(w.messageType, p); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+func Exec(w http.ResponseWriter, r *http.Request) {
+	apiName := mux.Vars(r)["apiName"]
+	commandStr, err := getRequiredQueryParam("command", r)
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+	var command []string
+	if err := json.Unmarshal([]byte(commandStr), &command); err != nil || len(command) == 0 {
+		respondError(w, r, ErrorInvalidQueryParam("command", commandStr, "a json list of the command's arguments"))
+		return
+	}
+	tty := getOptionalBoolQParam("tty", false, r)
+
+	deployedResource, err := resources.GetDeployedResourceByName(apiName)
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+
+	pod, err := getReplicaPod(deployedResource, getOptionalQParam("replica", r))
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+
+	container, err := getReplicaContainer(pod, getOptionalQParam("container", r))
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+
+	upgrader := websocket.Upgrader{}
+	socket, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+	defer socket.Close()
+
+	stdinReader, stdinWriter := io.Pipe()
+	sizeQueue := make(terminalSizeQueue, 1)
+	routines.RunWithPanicHandler(func() {
+		defer stdinWriter.Close()
+		defer close(sizeQueue)
+		for {
+			messageType, message, err := socket.ReadMessage()
+			if err != nil {
+				return
+			}
+			if messageType == websocket.BinaryMessage {
+				if len(message) == 0 {
+					stdinWriter.Close()
+					continue
+				}
+				if _, err := stdinWriter.Write(message); err != nil {
+					return
+				}
+				continue
+			}
+			var size terminalSize
+			if json.Unmarshal(message, &size) == nil && size.Width > 0 && size.Height > 0 {
+				select {
+				case sizeQueue <- kremotecommand.TerminalSize{Width: size.Width, Height: size.Height}:
+				default:
+				}
+			}
+		}
+	})
+
+	mutex := &sync.Mutex{}
+	stdout := socketWriter{socket: socket, mutex: mutex, messageType: websocket.BinaryMessage}
+	stderr := socketWriter{socket: socket, mutex: mutex, messageType: websocket.TextMessage}
+
+	err = config.K8s.ExecStream(pod.Name, container, command, stdinReader, stdout, stderr, tty, sizeQueue)
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	if exitErr, ok := err.(kexec.ExitError); ok {
+		socket.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, strconv.Itoa(exitErr.ExitStatus())))
+	} else if err != nil {
+		reason := err.Error()
+		if len(reason) > _maxCloseReasonLength {
+			reason = reason[:_maxCloseReasonLength]
+		}
+		socket.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseInternalServerErr, reason))
+	} else {
+		socket.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, "0"))
+	}
+}
+
+// PortForward forwards one connection to a port of a replica (the client opens a socket for each connection which it accepts)
+func PortForward(w http.ResponseWriter, r *http.Request) {
+	apiName := mux.Vars(r)["apiName"]
+	portStr, err := getRequiredQueryParam("port", r)
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+	port, err := strconv.ParseInt(portStr, 10, 32)
+	if err != nil || port <= 0 || port > 65535 {
+		respondError(w, r, ErrorInvalidQueryParam("port", portStr, "a port number between 1 and 65535"))
+		return
+	}
+
+	deployedResource, err := resources.GetDeployedResourceByName(apiName)
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+
+	pod, err := getReplicaPod(deployedResource, getOptionalQParam("replica", r))
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+
+	podConn, err := config.K8s.DialPod(pod.Name, int32(port))
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+	defer podConn.Close()
+
+	upgrader := websocket.Upgrader{}
+	socket, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+	defer socket.Close()
+
+	done := make(chan struct{}, 2)
+	routines.RunWithPanicHandler(func() {
+		defer func() { done <- struct{}{} }()
+		for {
+			_, message, err := socket.ReadMessage()
+			if err != nil {
+				return
+			}
+			if _, err := podConn.Write(message); err != nil {
+				return
+			}
+		}
+	})
+	routines.RunWithPanicHandler(func() {
+		defer func() { done <- struct{}{} }()
+		buf := make([]byte, 32*1024)
+		for {
+			n, err := podConn.Read(buf)
+			if n > 0 {
+				if err := socket.WriteMessage(websocket.BinaryMessage, buf[:n]); err != nil {
+					return
+				}
+			}
+			if err != nil {
+				return
+			}
+		}
+	})
+	<-done
+
+	socket.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+}
+
+// getReplicaPod returns the running pod of the api whose name is replica, or the first running pod if replica is empty
+func getReplicaPod(deployedResource *operator.DeployedResource, replica string) (*kcore.Pod, error) {
+	labels := map[string]string{"apiName": deployedResource.Name}
+	switch deployedResource.Kind {
+	case userconfig.RealtimeAPIKind:
+		labels["deploymentID"] = deployedResource.VirtualService.Labels["deploymentID"]
+		labels["podID"] = deployedResource.VirtualService.Labels["podID"]
+	case userconfig.AsyncAPIKind:
+		labels["deploymentID"] = deployedResource.VirtualService.Labels["deploymentID"]
+		labels["podID"] = deployedResource.VirtualService.Labels["podID"]
+		labels["cortex.dev/async"] = "api"
+	case userconfig.BatchAPIKind:
+		labels["cortex.dev/batch"] = "worker"
+	}
+
+	pods, err := config.K8s.ListPodsByLabels(labels)
+	if err != nil {
+		return nil, err
+	}
+
+	var runningPods []kcore.Pod
+	var runningPodNames []string
+	for _, pod := range pods {
+		if k8s.GetPodStatus(&pod) == k8s.PodStatusRunning {
+			runningPods = append(runningPods, pod)
+			runningPodNames = append(runningPodNames, pod.Name)
+		}
+	}
+	if len(runningPods) == 0 {
+		return nil, ErrorNoRunningReplicas(*deployedResource)
+	}
+	sort.Slice(runningPods, func(i, j int) bool {
+		return runningPods[i].Name < runningPods[j].Name
+	})
+
+	if replica == "" {
+		return &runningPods[0], nil
+	}
+	for i := range runningPods {
+		if runningPods[i].Name == replica {
+			return &runningPods[i], nil
+		}
+	}
+	sort.Strings(runningPodNames)
+	return nil, ErrorReplicaNotFound(replica, *deployedResource, runningPodNames)
+}
+
+// getReplicaContainer returns the container if it's specified, or the first of the api's containers otherwise (rather than cortex's or istio's)
+func getReplicaContainer(pod *kcore.Pod, container string) (string, error) {
+	containerNames := make([]string, len(pod.Spec.Containers))
+	for i := range pod.Spec.Containers {
+		containerNames[i] = pod.Spec.Containers[i].Name
+	}
+
+	if container != "" {
+		if !slices.HasString(containerNames, container) {
+			return "", ErrorContainerNotFound(container, pod.Name, containerNames)
+		}
+		return container, nil
+	}
+
+	for _, containerName := range containerNames {
+		if !slices.HasString(consts.ReservedContainerNames, containerName) && containerName != _istioProxyContainerName {
+			return containerName, nil
+		}
+	}
+	return containerNames[0], nil
+}
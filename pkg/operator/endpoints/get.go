@@ -20,29 +20,83 @@ import (
 	"net/http"
 
 	"github.com/cortexlabs/cortex/pkg/operator/resources"
+	"github.com/cortexlabs/cortex/pkg/operator/schema"
 	"github.com/gorilla/mux"
+	klabels "k8s.io/apimachinery/pkg/labels"
 )
 
 func GetAPIs(w http.ResponseWriter, r *http.Request) {
+	var selector klabels.Selector
+	if selectorStr := getOptionalQParam("selector", r); selectorStr != "" {
+		var err error
+		selector, err = klabels.Parse(selectorStr)
+		if err != nil {
+			respondError(w, r, ErrorInvalidQueryParam("selector", selectorStr, "a label selector (e.g. team=nlp,stage!=dev)"))
+			return
+		}
+	}
+
+	options, err := getListOptions(r)
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+
 	response, err := resources.GetAPIs()
 	if err != nil {
 		respondError(w, r, err)
 		return
 	}
 
-	respondJSON(w, r, response)
+	// only the apis whose labels (in the api spec) match the selector are returned
+	if selector != nil {
+		matching := make([]schema.APIResponse, 0, len(response))
+		for _, api := range response {
+			if selector.Matches(klabels.Set(api.Spec.Labels)) {
+				matching = append(matching, api)
+			}
+		}
+		response = matching
+	}
+
+	// operator tokens which are scoped to an api prefix only see the apis in their scope
+	if access := requestAccess(r); access.APIPrefix != "" {
+		allowed := make([]schema.APIResponse, 0, len(response))
+		for _, api := range response {
+			if access.AllowsAPI(api.Spec.Name) {
+				allowed = append(allowed, api)
+			}
+		}
+		response = allowed
+	}
+
+	response, next := paginateAPIs(filterAPIs(response, options), options)
+
+	respondList(w, r, response, options.fields, next)
 }
 
 func GetAPI(w http.ResponseWriter, r *http.Request) {
 	apiName := mux.Vars(r)["apiName"]
 
+	options, err := getListOptions(r)
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+
 	response, err := resources.GetAPI(apiName)
 	if err != nil {
 		respondError(w, r, err)
 		return
 	}
 
-	respondJSON(w, r, response)
+	// the status filter and pagination apply to the api's jobs (or workflow runs)
+	var next string
+	if len(response) > 0 {
+		next = filterAndPaginateJobs(&response[0], options)
+	}
+
+	respondList(w, r, response, options.fields, next)
 }
 
 func GetAPIByID(w http.ResponseWriter, r *http.Request) {
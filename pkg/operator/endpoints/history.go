@@ -0,0 +1,111 @@
+/*
+Copyright 2021 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoints
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/cortexlabs/cortex/pkg/operator/audit"
+	"github.com/cortexlabs/cortex/pkg/operator/history"
+	"github.com/cortexlabs/cortex/pkg/operator/resources"
+	"github.com/cortexlabs/cortex/pkg/operator/schema"
+	"github.com/gorilla/mux"
+)
+
+func GetHistory(w http.ResponseWriter, r *http.Request) {
+	apiName := mux.Vars(r)["apiName"]
+
+	if err := authorizeAPI(r, apiName); err != nil {
+		respondErrorCode(w, r, http.StatusForbidden, err)
+		return
+	}
+
+	revisions, err := history.List(apiName)
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+
+	respondJSON(w, r, schema.HistoryResponse{Revisions: revisions})
+}
+
+func Rollback(w http.ResponseWriter, r *http.Request) {
+	apiName := mux.Vars(r)["apiName"]
+	force := getOptionalBoolQParam("force", false, r)
+
+	if err := authorizeAPI(r, apiName); err != nil {
+		respondErrorCode(w, r, http.StatusForbidden, err)
+		return
+	}
+
+	var revision *schema.DeploymentRevision
+	var err error
+	if revisionStr := getOptionalQParam("revision", r); revisionStr != "" {
+		revisionNum, parseErr := strconv.Atoi(revisionStr)
+		if parseErr != nil || revisionNum <= 0 {
+			respondError(w, r, ErrorInvalidQueryParam("revision", revisionStr, "a positive integer"))
+			return
+		}
+		revision, err = history.Get(apiName, revisionNum)
+	} else {
+		revision, err = history.Previous(apiName)
+	}
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+
+	configFileName := fmt.Sprintf("%s-revision-%d.yaml", apiName, revision.Revision)
+	configBytes, err := history.ConfigFileBytes(*revision)
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+
+	// the revision may be a traffic splitter which references apis that are outside of the caller's scope
+	if err := authorizeAPIConfigs(r, configFileName, configBytes); err != nil {
+		respondErrorCode(w, r, http.StatusForbidden, err)
+		return
+	}
+
+	results, err := resources.Deploy(configFileName, configBytes, force, false)
+	if err != nil {
+		audit.RecordError(auditActor(r), schema.AuditActionRollback, apiName, err)
+		respondError(w, r, err)
+		return
+	}
+	result := results[0]
+
+	audit.Record(auditActor(r), schema.AuditEvent{
+		Action:  schema.AuditActionRollback,
+		APIName: apiName,
+		APIKind: result.Resource.Kind.String(),
+		Diffs:   result.Diffs,
+		Message: fmt.Sprintf("rolled back to revision %d: %s", revision.Revision, result.Message),
+		Error:   result.Error,
+	})
+	if result.Error == "" && result.API != nil {
+		history.Record(auditActor(r), result.API.Spec, &revision.Revision)
+	}
+
+	respondJSON(w, r, schema.RollbackResponse{
+		Revision: revision.Revision,
+		Result:   result,
+	})
+}
@@ -19,24 +19,49 @@ package endpoints
 import (
 	"net/http"
 	"sort"
-	"strings"
+	"time"
 
 	"github.com/cortexlabs/cortex/pkg/config"
 	"github.com/cortexlabs/cortex/pkg/lib/aws"
 	"github.com/cortexlabs/cortex/pkg/lib/k8s"
+	"github.com/cortexlabs/cortex/pkg/operator/resources"
 	"github.com/cortexlabs/cortex/pkg/operator/schema"
 	"github.com/cortexlabs/cortex/pkg/types/clusterconfig"
 	"github.com/cortexlabs/cortex/pkg/types/userconfig"
+	"github.com/cortexlabs/cortex/pkg/workloads"
+	cache "github.com/patrickmn/go-cache"
 	kcore "k8s.io/api/core/v1"
 )
 
+// listing the nodes and pods (and the spot prices, quotas, and budget) is expensive, and dashboards can poll /info frequently
+const _infoCacheDuration = 5 * time.Second
+
+var _infoCache = cache.New(_infoCacheDuration, _infoCacheDuration)
+
 func Info(w http.ResponseWriter, r *http.Request) {
+	if cachedResponse, found := _infoCache.Get("info"); found {
+		respondJSON(w, r, cachedResponse)
+		return
+	}
+
 	nodeInfos, numPendingReplicas, err := getNodeInfos()
 	if err != nil {
 		respondError(w, r, err)
 		return
 	}
 
+	quotaUsages, err := resources.GetQuotaUsages()
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+
+	budgetUsage, err := resources.GetBudgetUsage()
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+
 	fullClusterConfig := clusterconfig.InternalConfig{
 		Config:            *config.ClusterConfig,
 		OperatorMetadata:  *config.OperatorMetadata,
@@ -47,7 +72,11 @@ func Info(w http.ResponseWriter, r *http.Request) {
 		ClusterConfig:      fullClusterConfig,
 		NodeInfos:          nodeInfos,
 		NumPendingReplicas: numPendingReplicas,
+		QuotaUsages:        quotaUsages,
+		BudgetUsage:        budgetUsage,
 	}
+	_infoCache.Set("info", response, _infoCacheDuration)
+
 	respondJSON(w, r, response)
 }
 
@@ -63,6 +92,7 @@ func getNodeInfos() ([]schema.NodeInfo, int, error) {
 	}
 
 	nodeInfoMap := make(map[string]*schema.NodeInfo, len(nodes)) // node name -> info
+	nodeMap := make(map[string]*kcore.Node, len(nodes))          // node name -> node
 	spotPriceCache := make(map[string]float64)                   // instance type -> spot price
 
 	for i := range nodes {
@@ -70,7 +100,7 @@ func getNodeInfos() ([]schema.NodeInfo, int, error) {
 
 		instanceType := node.Labels["beta.kubernetes.io/instance-type"]
 		nodeGroupName := node.Labels["alpha.eksctl.io/nodegroup-name"]
-		isSpot := strings.Contains(strings.ToLower(node.Labels["lifecycle"]), "spot")
+		isSpot := workloads.IsSpotNode(&node)
 
 		price := aws.InstanceMetadatas[config.ClusterConfig.Region][instanceType].Price
 		if isSpot {
@@ -87,6 +117,7 @@ func getNodeInfos() ([]schema.NodeInfo, int, error) {
 			}
 		}
 
+		nodeMap[node.Name] = &nodes[i]
 		nodeInfoMap[node.Name] = &schema.NodeInfo{
 			Name:                 node.Name,
 			NodeGroupName:        nodeGroupName,
@@ -126,7 +157,8 @@ func getNodeInfos() ([]schema.NodeInfo, int, error) {
 			}
 		}
 
-		cpu, mem, gpu, inf := k8s.TotalPodCompute(&pod.Spec)
+		cpu, mem, _, inf := k8s.TotalPodCompute(&pod.Spec)
+		gpu := podGPUs(&pod.Spec, nodeMap[pod.Spec.NodeName])
 
 		node.ComputeAvailable.CPU.SubQty(cpu)
 		node.ComputeAvailable.Mem.SubQty(mem)
@@ -161,14 +193,28 @@ func getNodeInfos() ([]schema.NodeInfo, int, error) {
 	return nodeInfos, numPendingReplicas, nil
 }
 
+// podGPUs returns the number of GPUs which the pod requests, counting the time-sliced replicas of shared GPUs as fractions of a GPU
+func podGPUs(podSpec *kcore.PodSpec, node *kcore.Node) float64 {
+	requests := kcore.ResourceList{}
+	for _, container := range podSpec.Containers {
+		for _, resourceName := range []kcore.ResourceName{"nvidia.com/gpu", clusterconfig.SharedGPUResourceName} {
+			if qty, ok := container.Resources.Requests[resourceName]; ok {
+				total := requests[resourceName]
+				total.Add(qty)
+				requests[resourceName] = total
+			}
+		}
+	}
+	return workloads.GPUsOnNode(node, requests)
+}
+
 func nodeComputeAllocatable(node *kcore.Node) userconfig.Compute {
-	gpuQty := node.Status.Allocatable["nvidia.com/gpu"]
 	infQty := node.Status.Allocatable["aws.amazon.com/neuron"]
 
 	return userconfig.Compute{
 		CPU: k8s.WrapQuantity(*node.Status.Allocatable.Cpu()),
 		Mem: k8s.WrapQuantity(*node.Status.Allocatable.Memory()),
-		GPU: gpuQty.Value(),
+		GPU: workloads.GPUsOnNode(node, node.Status.Allocatable),
 		Inf: infQty.Value(),
 	}
 }
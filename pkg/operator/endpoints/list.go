@@ -0,0 +1,255 @@
+/*
+Copyright 2021 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoints
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/cortexlabs/cortex/pkg/consts"
+	"github.com/cortexlabs/cortex/pkg/operator/schema"
+	"github.com/cortexlabs/cortex/pkg/types/status"
+	"github.com/cortexlabs/cortex/pkg/types/userconfig"
+)
+
+const _maxListLimit = 1000
+
+// listOptions are the query params which filter, paginate, and select the fields of the operator's list responses
+type listOptions struct {
+	limit      int    // 0 if the results aren't paginated
+	cursor     string // the results are returned from after this name or id
+	fields     []string
+	status     string
+	namePrefix string
+	kind       userconfig.Kind
+}
+
+func getListOptions(r *http.Request) (listOptions, error) {
+	options := listOptions{
+		cursor:     getOptionalQParam("continue", r),
+		status:     getOptionalQParam("status", r),
+		namePrefix: getOptionalQParam("name_prefix", r),
+	}
+
+	if limitStr := getOptionalQParam("limit", r); limitStr != "" {
+		limit, err := strconv.Atoi(limitStr)
+		if err != nil || limit < 1 || limit > _maxListLimit {
+			return listOptions{}, ErrorInvalidQueryParam("limit", limitStr, "an integer between 1 and "+strconv.Itoa(_maxListLimit))
+		}
+		options.limit = limit
+	}
+
+	if fieldsStr := getOptionalQParam("fields", r); fieldsStr != "" {
+		for _, field := range strings.Split(fieldsStr, ",") {
+			if field = strings.TrimSpace(field); field != "" {
+				options.fields = append(options.fields, field)
+			}
+		}
+	}
+
+	if kindStr := getOptionalQParam("kind", r); kindStr != "" {
+		options.kind = userconfig.KindFromString(kindStr)
+		if options.kind == userconfig.UnknownKind {
+			return listOptions{}, ErrorInvalidQueryParam("kind", kindStr, "one of "+strings.Join(userconfig.KindStrings(), ", "))
+		}
+	}
+
+	return options, nil
+}
+
+// filterAPIs returns the apis which match the kind, name prefix, and status filters
+func filterAPIs(apis []schema.APIResponse, options listOptions) []schema.APIResponse {
+	filtered := make([]schema.APIResponse, 0, len(apis))
+	for _, api := range apis {
+		if options.kind != userconfig.UnknownKind && api.Spec.Kind != options.kind {
+			continue
+		}
+		if !strings.HasPrefix(api.Spec.Name, options.namePrefix) {
+			continue
+		}
+		if options.status != "" && (api.Status == nil || !matchesStatus(api.Status.Code.String(), api.Status.Code.Message(), options.status)) {
+			continue
+		}
+		filtered = append(filtered, api)
+	}
+	return filtered
+}
+
+// paginateAPIs sorts the apis by name, and returns the page after the cursor (and the cursor of the next page, if there are more apis)
+func paginateAPIs(apis []schema.APIResponse, options listOptions) ([]schema.APIResponse, string) {
+	sort.Slice(apis, func(i, j int) bool {
+		return apis[i].Spec.Name < apis[j].Spec.Name
+	})
+
+	start := 0
+	if options.cursor != "" {
+		start = sort.Search(len(apis), func(i int) bool {
+			return apis[i].Spec.Name > options.cursor
+		})
+	}
+	apis = apis[start:]
+
+	if options.limit == 0 || len(apis) <= options.limit {
+		return apis, ""
+	}
+	return apis[:options.limit], apis[options.limit-1].Spec.Name
+}
+
+// filterAndPaginateJobs applies the status filter and pagination to the jobs (or workflow runs) of an api;
+// since job ids are monotonically decreasing, sorting by id returns the most recent jobs first
+func filterAndPaginateJobs(api *schema.APIResponse, options listOptions) string {
+	var ids []string
+	keep := map[string]bool{}
+
+	for _, job := range api.BatchJobStatuses {
+		if options.status == "" || matchesStatus(job.Status.String(), job.Status.Message(), options.status) {
+			ids = append(ids, job.ID)
+		}
+	}
+	for _, job := range api.TaskJobStatuses {
+		if options.status == "" || matchesStatus(job.Status.String(), job.Status.Message(), options.status) {
+			ids = append(ids, job.ID)
+		}
+	}
+	for _, run := range api.WorkflowRuns {
+		if options.status == "" || matchesStatus(run.Status.String(), run.Status.Message(), options.status) {
+			ids = append(ids, run.ID)
+		}
+	}
+
+	sort.Strings(ids)
+	start := 0
+	if options.cursor != "" {
+		start = sort.SearchStrings(ids, options.cursor)
+		if start < len(ids) && ids[start] == options.cursor {
+			start++
+		}
+	}
+	ids = ids[start:]
+
+	var next string
+	if options.limit != 0 && len(ids) > options.limit {
+		ids = ids[:options.limit]
+		next = ids[len(ids)-1]
+	}
+	for _, id := range ids {
+		keep[id] = true
+	}
+
+	batchJobs := make([]status.BatchJobStatus, 0, len(api.BatchJobStatuses))
+	for _, job := range api.BatchJobStatuses {
+		if keep[job.ID] {
+			batchJobs = append(batchJobs, job)
+		}
+	}
+	taskJobs := make([]status.TaskJobStatus, 0, len(api.TaskJobStatuses))
+	for _, job := range api.TaskJobStatuses {
+		if keep[job.ID] {
+			taskJobs = append(taskJobs, job)
+		}
+	}
+	runs := make([]status.WorkflowRun, 0, len(api.WorkflowRuns))
+	for _, run := range api.WorkflowRuns {
+		if keep[run.ID] {
+			runs = append(runs, run)
+		}
+	}
+
+	sort.Slice(batchJobs, func(i, j int) bool { return batchJobs[i].ID < batchJobs[j].ID })
+	sort.Slice(taskJobs, func(i, j int) bool { return taskJobs[i].ID < taskJobs[j].ID })
+	sort.Slice(runs, func(i, j int) bool { return runs[i].ID < runs[j].ID })
+
+	api.BatchJobStatuses = batchJobs
+	api.TaskJobStatuses = taskJobs
+	api.WorkflowRuns = runs
+
+	return next
+}
+
+// matchesStatus accepts either the status code (e.g. status_live) or its message (e.g. live)
+func matchesStatus(code string, message string, filter string) bool {
+	filter = strings.ToLower(filter)
+	return code == filter || message == filter || strings.TrimPrefix(code, "status_") == filter
+}
+
+// respondList responds with the list (with only the selected fields, if any), and sets the continue header if there are more results
+func respondList(w http.ResponseWriter, r *http.Request, list interface{}, fields []string, next string) {
+	if next != "" {
+		w.Header().Set(consts.ContinueHeader, next)
+	}
+
+	if len(fields) == 0 {
+		respondJSON(w, r, list)
+		return
+	}
+
+	jsonBytes, err := json.Marshal(list)
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+	var items []map[string]interface{}
+	if err := json.Unmarshal(jsonBytes, &items); err != nil {
+		respondError(w, r, err)
+		return
+	}
+
+	selected := make([]map[string]interface{}, len(items))
+	for i, item := range items {
+		selected[i] = selectFields(item, fields)
+	}
+
+	respondJSON(w, r, selected)
+}
+
+// selectFields returns the fields of the object (which can be nested, e.g. spec.name)
+func selectFields(obj map[string]interface{}, fields []string) map[string]interface{} {
+	selected := map[string]interface{}{}
+	for _, field := range fields {
+		path := strings.Split(field, ".")
+
+		value, ok := interface{}(obj), true
+		for _, key := range path {
+			m, isMap := value.(map[string]interface{})
+			if !isMap {
+				ok = false
+				break
+			}
+			if value, ok = m[key]; !ok {
+				break
+			}
+		}
+		if !ok {
+			continue
+		}
+
+		dest := selected
+		for _, key := range path[:len(path)-1] {
+			next, isMap := dest[key].(map[string]interface{})
+			if !isMap {
+				next = map[string]interface{}{}
+				dest[key] = next
+			}
+			dest = next
+		}
+		dest[path[len(path)-1]] = value
+	}
+	return selected
+}
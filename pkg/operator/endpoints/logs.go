@@ -18,17 +18,27 @@ package endpoints
 
 import (
 	"net/http"
+	"regexp"
+	"time"
 
 	"github.com/cortexlabs/cortex/pkg/operator/operator"
 	"github.com/cortexlabs/cortex/pkg/operator/resources"
 	"github.com/cortexlabs/cortex/pkg/operator/resources/asyncapi"
+	"github.com/cortexlabs/cortex/pkg/operator/resources/job/batchapi"
+	"github.com/cortexlabs/cortex/pkg/operator/resources/job/taskapi"
 	"github.com/cortexlabs/cortex/pkg/operator/resources/realtimeapi"
 	"github.com/cortexlabs/cortex/pkg/operator/schema"
+	"github.com/cortexlabs/cortex/pkg/types/spec"
 	"github.com/cortexlabs/cortex/pkg/types/userconfig"
 	"github.com/gorilla/mux"
 	"github.com/gorilla/websocket"
 )
 
+// the ids are interpolated into CloudWatch Insights queries
+var _logLinesIDRegex = regexp.MustCompile(`^[a-zA-Z0-9_.:-]+$`)
+
+const _defaultLogLinesSince = 24 * time.Hour
+
 func ReadLogs(w http.ResponseWriter, r *http.Request) {
 	apiName := mux.Vars(r)["apiName"]
 	jobID := getOptionalQParam("jobID", r)
@@ -124,3 +134,89 @@ func GetLogURL(w http.ResponseWriter, r *http.Request) {
 		respondError(w, r, resources.ErrorOperationIsOnlySupportedForKind(*deployedResource, userconfig.RealtimeAPIKind, userconfig.AsyncAPIKind))
 	}
 }
+
+// GetLogLines returns the log lines of a job (for batch and task apis) or of a request (for realtime and async apis)
+func GetLogLines(w http.ResponseWriter, r *http.Request) {
+	apiName := mux.Vars(r)["apiName"]
+	jobID := getOptionalQParam("jobID", r)
+	requestID := getOptionalQParam("requestID", r)
+
+	if (jobID == "") == (requestID == "") {
+		respondError(w, r, ErrorAnyQueryParamRequired("jobID", "requestID"))
+		return
+	}
+	for param, id := range map[string]string{"jobID": jobID, "requestID": requestID} {
+		if id != "" && !_logLinesIDRegex.MatchString(id) {
+			respondError(w, r, ErrorInvalidQueryParam(param, id, "an id consisting of alphanumeric characters, '-', '_', '.', or ':'"))
+			return
+		}
+	}
+
+	since := _defaultLogLinesSince
+	if sinceStr := getOptionalQParam("since", r); sinceStr != "" {
+		var err error
+		since, err = time.ParseDuration(sinceStr)
+		if err != nil || since <= 0 {
+			respondError(w, r, ErrorInvalidQueryParam("since", sinceStr, "a positive duration (e.g. 30m or 24h)"))
+			return
+		}
+	}
+	endTime := time.Now()
+	startTime := endTime.Add(-since)
+
+	deployedResource, err := resources.GetDeployedResourceByName(apiName)
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+
+	var logLines *schema.LogLinesResponse
+	switch deployedResource.Kind {
+	case userconfig.BatchAPIKind, userconfig.TaskAPIKind:
+		if jobID == "" {
+			respondError(w, r, ErrorLogsJobIDRequired(*deployedResource))
+			return
+		}
+
+		jobKey := spec.JobKey{ID: jobID, APIName: apiName, Kind: deployedResource.Kind}
+		var jobStartTime time.Time
+		var jobEndTime *time.Time
+		if deployedResource.Kind == userconfig.BatchAPIKind {
+			jobStatus, err := batchapi.GetJobStatus(jobKey)
+			if err != nil {
+				respondError(w, r, err)
+				return
+			}
+			jobStartTime, jobEndTime = jobStatus.StartTime, jobStatus.EndTime
+		} else {
+			jobStatus, err := taskapi.GetJobStatus(jobKey)
+			if err != nil {
+				respondError(w, r, err)
+				return
+			}
+			jobStartTime, jobEndTime = jobStatus.StartTime, jobStatus.EndTime
+		}
+
+		// the job's time range is used rather than since, with a margin for the enqueuer's and the pods' clocks
+		startTime = jobStartTime.Add(-time.Minute)
+		if jobEndTime != nil {
+			endTime = jobEndTime.Add(time.Minute)
+		}
+		logLines, err = operator.JobLogLines(apiName, jobID, startTime, endTime)
+	case userconfig.RealtimeAPIKind, userconfig.AsyncAPIKind:
+		if requestID == "" {
+			respondError(w, r, resources.ErrorOperationIsOnlySupportedForKind(*deployedResource, userconfig.BatchAPIKind, userconfig.TaskAPIKind))
+			return
+		}
+		logLines, err = operator.RequestLogLines(apiName, requestID, startTime, endTime)
+	default:
+		respondError(w, r, resources.ErrorOperationIsOnlySupportedForKind(*deployedResource, userconfig.RealtimeAPIKind, userconfig.AsyncAPIKind, userconfig.BatchAPIKind, userconfig.TaskAPIKind))
+		return
+	}
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+
+	respondJSON(w, r, logLines)
+}
@@ -19,12 +19,15 @@ package endpoints
 import (
 	"context"
 	"net/http"
+	"strings"
 
 	"github.com/cortexlabs/cortex/pkg/config"
 	"github.com/cortexlabs/cortex/pkg/consts"
 	"github.com/cortexlabs/cortex/pkg/lib/aws"
 	"github.com/cortexlabs/cortex/pkg/lib/sets/strset"
 	"github.com/cortexlabs/cortex/pkg/lib/telemetry"
+	"github.com/cortexlabs/cortex/pkg/operator/tokens"
+	"github.com/cortexlabs/cortex/pkg/types/rbac"
 )
 
 var _cachedClientIDs = strset.New()
@@ -34,6 +37,8 @@ type ctxKey int
 const (
 	ctxKeyUnknown ctxKey = iota
 	ctxKeyClient
+	ctxKeyPrincipal
+	ctxKeyAccess
 )
 
 func PanicMiddleware(next http.Handler) http.Handler {
@@ -59,8 +64,36 @@ func ClientIDMiddleware(next http.Handler) http.Handler {
 	})
 }
 
-func AWSAuthMiddleware(next http.Handler) http.Handler {
+// AuthMiddleware authenticates the caller with an operator token or an OIDC id token (if one is provided), or with AWS credentials;
+// callers who authenticate with AWS credentials from the cluster's account are cluster admins
+func AuthMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if tokenHeader := r.Header.Get(consts.OperatorTokenHeader); tokenHeader != "" {
+			token, err := tokens.Validate(tokenHeader)
+			if err != nil {
+				respondErrorCode(w, r, http.StatusUnauthorized, err)
+				return
+			}
+
+			ctx := context.WithValue(r.Context(), ctxKeyAccess, token.Access())
+			ctx = context.WithValue(ctx, ctxKeyPrincipal, "token:"+token.ID)
+			next.ServeHTTP(w, r.WithContext(ctx))
+			return
+		}
+
+		if authorization := r.Header.Get("Authorization"); strings.HasPrefix(authorization, "Bearer ") {
+			access, username, err := authenticateOIDC(strings.TrimPrefix(authorization, "Bearer "))
+			if err != nil {
+				respondErrorCode(w, r, http.StatusUnauthorized, err)
+				return
+			}
+
+			ctx := context.WithValue(r.Context(), ctxKeyAccess, access)
+			ctx = context.WithValue(ctx, ctxKeyPrincipal, "oidc:"+username)
+			next.ServeHTTP(w, r.WithContext(ctx))
+			return
+		}
+
 		authHeader := r.Header.Get(consts.AuthHeader)
 
 		if authHeader == "" {
@@ -68,7 +101,7 @@ func AWSAuthMiddleware(next http.Handler) http.Handler {
 			return
 		}
 
-		accountID, err := aws.ExecuteIdentityRequestFromHeader(authHeader)
+		accountID, principal, err := aws.ExecuteIdentityRequestFromHeader(authHeader)
 		if err != nil {
 			respondError(w, r, err)
 			return
@@ -85,7 +118,10 @@ func AWSAuthMiddleware(next http.Handler) http.Handler {
 			return
 		}
 
-		next.ServeHTTP(w, r)
+		// Add the caller's arn to context (for the audit log)
+		ctx := context.WithValue(r.Context(), ctxKeyAccess, rbac.Access{Role: rbac.AdminRole})
+		ctx = context.WithValue(ctx, ctxKeyPrincipal, principal)
+		next.ServeHTTP(w, r.WithContext(ctx))
 	})
 }
 
@@ -0,0 +1,106 @@
+/*
+Copyright 2021 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoints
+
+import (
+	"net/http"
+	"sync"
+
+	"github.com/cortexlabs/cortex/pkg/config"
+	"github.com/cortexlabs/cortex/pkg/lib/oidc"
+	"github.com/cortexlabs/cortex/pkg/operator/schema"
+	"github.com/cortexlabs/cortex/pkg/types/rbac"
+)
+
+var (
+	_oidcVerifier      *oidc.Verifier
+	_oidcVerifierMutex sync.Mutex
+)
+
+// the provider is discovered on the first request (rather than on startup), so that the operator can start if the provider is unavailable
+func getOIDCVerifier() (*oidc.Verifier, error) {
+	_oidcVerifierMutex.Lock()
+	defer _oidcVerifierMutex.Unlock()
+
+	if _oidcVerifier != nil {
+		return _oidcVerifier, nil
+	}
+
+	provider, err := oidc.Discover(config.ClusterConfig.OIDC.IssuerURL)
+	if err != nil {
+		return nil, err
+	}
+
+	_oidcVerifier = oidc.NewVerifier(provider, config.ClusterConfig.OIDC.ClientID)
+	return _oidcVerifier, nil
+}
+
+// authenticateOIDC verifies the id token, and maps its roles claim to an access level using the cluster's role mappings (the first matching mapping is used)
+func authenticateOIDC(idToken string) (rbac.Access, string, error) {
+	oidcConfig := config.ClusterConfig.OIDC
+	if oidcConfig == nil {
+		return rbac.Access{}, "", ErrorOIDCNotConfigured()
+	}
+
+	verifier, err := getOIDCVerifier()
+	if err != nil {
+		return rbac.Access{}, "", err
+	}
+
+	claims, err := verifier.Verify(idToken)
+	if err != nil {
+		return rbac.Access{}, "", err
+	}
+
+	username := claims.String(oidcConfig.UsernameClaim)
+	if username == "" {
+		username = claims.String("sub")
+	}
+
+	userRoles := claims.Strings(oidcConfig.RolesClaim)
+	for _, mapping := range oidcConfig.RoleMappings {
+		for _, userRole := range userRoles {
+			if userRole == mapping.Value {
+				return rbac.Access{
+					Role:      rbac.RoleFromString(mapping.Role),
+					APIPrefix: mapping.APIPrefix,
+				}, username, nil
+			}
+		}
+	}
+
+	if oidcConfig.DefaultRole != nil {
+		return rbac.Access{Role: rbac.RoleFromString(*oidcConfig.DefaultRole)}, username, nil
+	}
+
+	return rbac.Access{}, "", ErrorOIDCNoRole(username, oidcConfig.RolesClaim)
+}
+
+// GetOIDCConfig returns the settings which the cli needs to log in with the cluster's OIDC provider (it is not authenticated, so it must only return public settings)
+func GetOIDCConfig(w http.ResponseWriter, r *http.Request) {
+	oidcConfig := config.ClusterConfig.OIDC
+	if oidcConfig == nil {
+		respondError(w, r, ErrorOIDCNotConfigured())
+		return
+	}
+
+	respondJSON(w, r, schema.OIDCConfigResponse{
+		IssuerURL: oidcConfig.IssuerURL,
+		ClientID:  oidcConfig.ClientID,
+		Scopes:    oidcConfig.Scopes,
+	})
+}
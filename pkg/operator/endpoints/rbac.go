@@ -0,0 +1,110 @@
+/*
+Copyright 2021 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoints
+
+import (
+	"net/http"
+
+	"github.com/cortexlabs/cortex/pkg/types/rbac"
+	"github.com/cortexlabs/cortex/pkg/types/spec"
+	"github.com/cortexlabs/cortex/pkg/types/userconfig"
+	"github.com/gorilla/mux"
+)
+
+// requestAccess returns the access which was granted to the caller by AuthMiddleware; requests which weren't authenticated have no access
+func requestAccess(r *http.Request) rbac.Access {
+	if access, ok := r.Context().Value(ctxKeyAccess).(rbac.Access); ok {
+		return access
+	}
+	return rbac.Access{Role: rbac.UnknownRole}
+}
+
+func authorizeAPI(r *http.Request, apiName string) error {
+	access := requestAccess(r)
+	if !access.AllowsAPI(apiName) {
+		return ErrorForbiddenAPI(apiName, access.APIPrefix)
+	}
+	return nil
+}
+
+// authorizeAPIConfigs checks that all of the apis in a deployment (and the apis which are referenced by traffic splitters and workflows) are in the caller's scope
+func authorizeAPIConfigs(r *http.Request, configFileName string, configBytes []byte) error {
+	if requestAccess(r).APIPrefix == "" {
+		return nil
+	}
+
+	apiConfigs, err := spec.ExtractAPIConfigs(configBytes, configFileName)
+	if err != nil {
+		return err
+	}
+
+	for _, apiConfig := range apiConfigs {
+		if err := authorizeAPI(r, apiConfig.Name); err != nil {
+			return err
+		}
+		if apiConfig.Kind == userconfig.TrafficSplitterKind {
+			for _, trafficSplit := range apiConfig.APIs {
+				if err := authorizeAPI(r, trafficSplit.Name); err != nil {
+					return err
+				}
+			}
+		}
+		if apiConfig.Kind == userconfig.WorkflowKind {
+			for _, step := range apiConfig.Steps {
+				if err := authorizeAPI(r, step.API); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+func withRoleCheck(next http.HandlerFunc, isAllowed func(rbac.Role) bool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		role := requestAccess(r).Role
+		if !isAllowed(role) {
+			respondErrorCode(w, r, http.StatusForbidden, ErrorForbiddenRole(role, r.Method, r.URL.Path))
+			return
+		}
+
+		if apiName := mux.Vars(r)["apiName"]; apiName != "" {
+			if err := authorizeAPI(r, apiName); err != nil {
+				respondErrorCode(w, r, http.StatusForbidden, err)
+				return
+			}
+		}
+
+		next(w, r)
+	}
+}
+
+// ReadAccess allows the request if the caller's role can view apis
+func ReadAccess(next http.HandlerFunc) http.HandlerFunc {
+	return withRoleCheck(next, rbac.Role.CanRead)
+}
+
+// DeployAccess allows the request if the caller's role can modify apis
+func DeployAccess(next http.HandlerFunc) http.HandlerFunc {
+	return withRoleCheck(next, rbac.Role.CanDeploy)
+}
+
+// AdminAccess allows the request if the caller is a cluster admin
+func AdminAccess(next http.HandlerFunc) http.HandlerFunc {
+	return withRoleCheck(next, rbac.Role.CanManageTokens)
+}
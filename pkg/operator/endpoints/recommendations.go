@@ -0,0 +1,48 @@
+/*
+Copyright 2021 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoints
+
+import (
+	"net/http"
+	"time"
+
+	libtime "github.com/cortexlabs/cortex/pkg/lib/time"
+	"github.com/cortexlabs/cortex/pkg/operator/resources"
+	"github.com/gorilla/mux"
+)
+
+func GetRecommendations(w http.ResponseWriter, r *http.Request) {
+	apiName := mux.Vars(r)["apiName"]
+
+	window := resources.DefaultRecommendationsWindow
+	if windowStr := getOptionalQParam("window", r); windowStr != "" {
+		var err error
+		window, err = libtime.ParseDuration(windowStr)
+		if err != nil || window < time.Hour || window > resources.MaxRecommendationsWindow {
+			respondError(w, r, ErrorInvalidQueryParam("window", windowStr, "a duration between 1h and 14d (e.g. 7d)"))
+			return
+		}
+	}
+
+	response, err := resources.GetRecommendations(apiName, window)
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+
+	respondJSON(w, r, response)
+}
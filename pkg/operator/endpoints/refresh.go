@@ -19,6 +19,7 @@ package endpoints
 import (
 	"net/http"
 
+	"github.com/cortexlabs/cortex/pkg/operator/audit"
 	"github.com/cortexlabs/cortex/pkg/operator/resources"
 	"github.com/cortexlabs/cortex/pkg/operator/schema"
 	"github.com/gorilla/mux"
@@ -30,10 +31,17 @@ func Refresh(w http.ResponseWriter, r *http.Request) {
 
 	msg, err := resources.RefreshAPI(apiName, force)
 	if err != nil {
+		audit.RecordError(auditActor(r), schema.AuditActionRefresh, apiName, err)
 		respondError(w, r, err)
 		return
 	}
 
+	audit.Record(auditActor(r), schema.AuditEvent{
+		Action:  schema.AuditActionRefresh,
+		APIName: apiName,
+		Message: msg,
+	})
+
 	response := schema.RefreshResponse{
 		Message: msg,
 	}
@@ -19,8 +19,10 @@ package endpoints
 import (
 	"encoding/json"
 	"net/http"
+	"strings"
 
 	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/lib/hash"
 	libjson "github.com/cortexlabs/cortex/pkg/lib/json"
 	"github.com/cortexlabs/cortex/pkg/lib/logging"
 	"github.com/cortexlabs/cortex/pkg/lib/telemetry"
@@ -36,11 +38,30 @@ func respondJSON(w http.ResponseWriter, r *http.Request, response interface{}) {
 		return
 	}
 
+	// the etag is computed from the response, so a client which already has the same response (e.g. a dashboard which polls /info) doesn't download it again
+	etag := `"` + hash.Bytes(jsonBytes) + `"`
+	w.Header().Set("ETag", etag)
+	if r.Method == http.MethodGet && etagMatches(r.Header.Get("If-None-Match"), etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
 	w.Write(jsonBytes)
 }
 
+// etagMatches checks the If-None-Match header, which can contain a list of (possibly weak) etags
+func etagMatches(ifNoneMatch string, etag string) bool {
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == etag || candidate == "*" {
+			return true
+		}
+	}
+	return false
+}
+
 func respondError(w http.ResponseWriter, r *http.Request, err error, strs ...string) {
 	respondErrorCode(w, r, http.StatusBadRequest, err, strs...)
 }
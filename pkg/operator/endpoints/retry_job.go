@@ -0,0 +1,74 @@
+/*
+Copyright 2021 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoints
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/cortexlabs/cortex/pkg/operator/resources/job/batchapi"
+	"github.com/cortexlabs/cortex/pkg/operator/resources/job/taskapi"
+	"github.com/cortexlabs/cortex/pkg/operator/schema"
+	"github.com/cortexlabs/cortex/pkg/types/spec"
+	"github.com/cortexlabs/cortex/pkg/types/userconfig"
+	"github.com/gorilla/mux"
+)
+
+func RetryBatchJob(w http.ResponseWriter, r *http.Request) {
+	retryJob(w, r, userconfig.BatchAPIKind, func(jobKey spec.JobKey) (string, error) {
+		jobSpec, err := batchapi.RetryJob(jobKey)
+		if err != nil {
+			return "", err
+		}
+		return jobSpec.ID, nil
+	})
+}
+
+func RetryTaskJob(w http.ResponseWriter, r *http.Request) {
+	retryJob(w, r, userconfig.TaskAPIKind, func(jobKey spec.JobKey) (string, error) {
+		jobSpec, err := taskapi.RetryJob(jobKey)
+		if err != nil {
+			return "", err
+		}
+		return jobSpec.ID, nil
+	})
+}
+
+func retryJob(w http.ResponseWriter, r *http.Request, kind userconfig.Kind, retryFn func(spec.JobKey) (string, error)) {
+	vars := mux.Vars(r)
+	apiName := vars["apiName"]
+	jobID, err := getRequiredQueryParam("jobID", r)
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+
+	newJobID, err := retryFn(spec.JobKey{
+		APIName: apiName,
+		ID:      jobID,
+		Kind:    kind,
+	})
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+
+	respondJSON(w, r, schema.RetryJobResponse{
+		Message: fmt.Sprintf("retrying job %s as job %s", jobID, newJobID),
+		JobID:   newJobID,
+	})
+}
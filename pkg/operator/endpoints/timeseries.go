@@ -0,0 +1,67 @@
+/*
+Copyright 2021 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoints
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	libtime "github.com/cortexlabs/cortex/pkg/lib/time"
+	"github.com/cortexlabs/cortex/pkg/operator/resources"
+	"github.com/gorilla/mux"
+)
+
+func GetTimeSeries(w http.ResponseWriter, r *http.Request) {
+	apiName := mux.Vars(r)["apiName"]
+
+	window := resources.DefaultTimeSeriesWindow
+	if windowStr := getOptionalQParam("window", r); windowStr != "" {
+		var err error
+		window, err = libtime.ParseDuration(windowStr)
+		if err != nil || window < time.Minute || window > resources.MaxTimeSeriesWindow {
+			respondError(w, r, ErrorInvalidQueryParam("window", windowStr, "a duration between 1m and 14d (e.g. 6h)"))
+			return
+		}
+	}
+
+	// by default, the window is divided into 60 steps
+	step := (window / 60).Truncate(time.Second)
+	if step < resources.MinTimeSeriesStep {
+		step = resources.MinTimeSeriesStep
+	}
+	if stepStr := getOptionalQParam("step", r); stepStr != "" {
+		var err error
+		step, err = libtime.ParseDuration(stepStr)
+		if err != nil || step < resources.MinTimeSeriesStep || step > window {
+			respondError(w, r, ErrorInvalidQueryParam("step", stepStr, "a duration between 15s and the window (e.g. 1m)"))
+			return
+		}
+	}
+	if window/step > resources.MaxTimeSeriesPoints {
+		respondError(w, r, ErrorInvalidQueryParam("step", step.String(), fmt.Sprintf("a duration which divides the window into at most %d steps", resources.MaxTimeSeriesPoints)))
+		return
+	}
+
+	response, err := resources.GetTimeSeries(apiName, window, step)
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+
+	respondJSON(w, r, response)
+}
@@ -0,0 +1,96 @@
+/*
+Copyright 2021 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoints
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/operator/schema"
+	"github.com/cortexlabs/cortex/pkg/operator/tokens"
+	"github.com/cortexlabs/cortex/pkg/types/rbac"
+)
+
+func CreateOperatorToken(w http.ResponseWriter, r *http.Request) {
+	roleStr, err := getRequiredQueryParam("role", r)
+	if err != nil {
+		respondError(w, r, errors.WithStack(err))
+		return
+	}
+
+	role := rbac.RoleFromString(roleStr)
+	if role == rbac.UnknownRole {
+		respondError(w, r, ErrorInvalidQueryParam("role", roleStr, "one of "+strings.Join(rbac.RoleStrings(), ", ")))
+		return
+	}
+
+	var ttl *time.Duration
+	if ttlStr := getOptionalQParam("ttl", r); ttlStr != "" {
+		parsedTTL, err := time.ParseDuration(ttlStr)
+		if err != nil || parsedTTL <= 0 {
+			respondError(w, r, ErrorInvalidQueryParam("ttl", ttlStr, "a positive duration (e.g. 720h)"))
+			return
+		}
+		ttl = &parsedTTL
+	}
+
+	var createdBy string
+	if principal, ok := r.Context().Value(ctxKeyPrincipal).(string); ok {
+		createdBy = principal
+	}
+
+	token, operatorToken, err := tokens.Create(role, getOptionalQParam("apiPrefix", r), getOptionalQParam("description", r), ttl, createdBy)
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+
+	respondJSON(w, r, schema.CreateOperatorTokenResponse{
+		Token:         token,
+		OperatorToken: *operatorToken,
+	})
+}
+
+func ListOperatorTokens(w http.ResponseWriter, r *http.Request) {
+	operatorTokens, err := tokens.List()
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+
+	respondJSON(w, r, schema.OperatorTokensResponse{Tokens: operatorTokens})
+}
+
+func RevokeOperatorToken(w http.ResponseWriter, r *http.Request) {
+	tokenID, err := getRequiredPathParam("tokenID", r)
+	if err != nil {
+		respondError(w, r, errors.WithStack(err))
+		return
+	}
+
+	if err := tokens.Revoke(tokenID); err != nil {
+		respondError(w, r, err)
+		return
+	}
+
+	respondJSON(w, r, schema.RevokeOperatorTokenResponse{
+		Message: fmt.Sprintf("revoked operator token %s", tokenID),
+	})
+}
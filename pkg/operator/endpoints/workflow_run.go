@@ -0,0 +1,114 @@
+/*
+Copyright 2021 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoints
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/cortexlabs/cortex/pkg/consts"
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/operator/resources"
+	"github.com/cortexlabs/cortex/pkg/operator/resources/workflow"
+	"github.com/cortexlabs/cortex/pkg/operator/schema"
+	"github.com/cortexlabs/cortex/pkg/types/userconfig"
+	"github.com/gorilla/mux"
+)
+
+func SubmitWorkflowRun(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	apiName := vars["apiName"]
+
+	deployedResource, err := resources.GetDeployedResourceByName(apiName)
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+	if deployedResource.Kind != userconfig.WorkflowKind {
+		respondError(w, r, resources.ErrorOperationIsOnlySupportedForKind(*deployedResource, userconfig.WorkflowKind))
+		return
+	}
+
+	// max payload size, same as API Gateway
+	rw := http.MaxBytesReader(w, r.Body, 10<<20)
+
+	bodyBytes, err := ioutil.ReadAll(rw)
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+
+	submission := schema.WorkflowRunSubmission{}
+	if len(bodyBytes) > 0 {
+		err = json.Unmarshal(bodyBytes, &submission)
+		if err != nil {
+			respondError(w, r, errors.Append(err,
+				fmt.Sprintf("\n\nworkflow run submission schema can be found at https://docs.cortex.dev/v/%s/",
+					consts.CortexVersionMinor)),
+			)
+			return
+		}
+	}
+
+	run, err := workflow.SubmitRun(apiName, &submission)
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+
+	respondJSON(w, r, run)
+}
+
+func GetWorkflowRun(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	apiName := vars["apiName"]
+	runID, err := getRequiredQueryParam("runID", r)
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+
+	run, err := workflow.GetRun(apiName, runID)
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+
+	respondJSON(w, r, run)
+}
+
+func StopWorkflowRun(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	apiName := vars["apiName"]
+	runID, err := getRequiredQueryParam("runID", r)
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+
+	err = workflow.StopRun(apiName, runID)
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+
+	respondJSON(w, r, schema.DeleteResponse{
+		Message: fmt.Sprintf("stopped run %s", runID),
+	})
+}
@@ -0,0 +1,60 @@
+/*
+Copyright 2021 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gitops
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	s "github.com/cortexlabs/cortex/pkg/lib/strings"
+)
+
+const (
+	ErrGitCommandFailed     = "gitops.git_command_failed"
+	ErrNoAPIConfigsFound    = "gitops.no_api_configs_found"
+	ErrCommitStatusFailed   = "gitops.commit_status_failed"
+	ErrReconciliationFailed = "gitops.reconciliation_failed"
+)
+
+func ErrorGitCommandFailed(command string, output string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrGitCommandFailed,
+		Message: fmt.Sprintf("`git %s` failed:\n%s", command, output),
+	})
+}
+
+func ErrorNoAPIConfigsFound(source string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrNoAPIConfigsFound,
+		Message: fmt.Sprintf("no api configuration files (i.e. .yaml or .yml files) were found in %s; no apis will be updated or pruned", source),
+	})
+}
+
+func ErrorCommitStatusFailed(statusCode int, response string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrCommitStatusFailed,
+		Message: fmt.Sprintf("unable to set the commit status (status code %d): %s", statusCode, response),
+	})
+}
+
+func ErrorReconciliationFailed(revision string, errMessages []string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrReconciliationFailed,
+		Message: fmt.Sprintf("failed to reconcile %s %s at revision %s:\n\n%s", s.Int(len(errMessages)), s.PluralS("api configuration", len(errMessages)), revision, strings.Join(errMessages, "\n\n")),
+	})
+}
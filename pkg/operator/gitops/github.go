@@ -0,0 +1,94 @@
+/*
+Copyright 2021 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gitops
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	libjson "github.com/cortexlabs/cortex/pkg/lib/json"
+)
+
+const (
+	_commitStatusSuccess = "success"
+	_commitStatusFailure = "failure"
+
+	// github truncates longer descriptions
+	_maxCommitStatusDescriptionLength = 140
+)
+
+var _githubClient = &http.Client{
+	Timeout: 10 * time.Second,
+}
+
+type commitStatus struct {
+	State       string `json:"state"`
+	Description string `json:"description"`
+	Context     string `json:"context"`
+}
+
+// returns the owner and repository of a github repository url (e.g. https://github.com/my-org/my-apis.git), or false if it is not hosted on github
+func githubRepo(repoURL string) (string, string, bool) {
+	path := strings.TrimPrefix(repoURL, "https://github.com/")
+	if path == repoURL {
+		return "", "", false
+	}
+
+	parts := strings.Split(strings.TrimSuffix(strings.TrimSuffix(path, "/"), ".git"), "/")
+	if len(parts) != 2 {
+		return "", "", false
+	}
+
+	return parts[0], parts[1], true
+}
+
+func setGitHubCommitStatus(owner string, repo string, sha string, token string, status commitStatus) error {
+	if len(status.Description) > _maxCommitStatusDescriptionLength {
+		status.Description = status.Description[:_maxCommitStatusDescriptionLength-3] + "..."
+	}
+
+	body, err := libjson.Marshal(status)
+	if err != nil {
+		return err
+	}
+
+	url := fmt.Sprintf("https://api.github.com/repos/%s/%s/statuses/%s", owner, repo, sha)
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/vnd.github.v3+json")
+	req.Header.Set("Authorization", "token "+token)
+	req.Header.Set("Content-Type", "application/json")
+
+	response, err := _githubClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusCreated {
+		responseBytes, _ := ioutil.ReadAll(response.Body)
+		return ErrorCommitStatusFailed(response.StatusCode, string(responseBytes))
+	}
+
+	return nil
+}
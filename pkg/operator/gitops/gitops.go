@@ -0,0 +1,195 @@
+/*
+Copyright 2021 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gitops
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/cortexlabs/cortex/pkg/config"
+	"github.com/cortexlabs/cortex/pkg/lib/aws"
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/lib/logging"
+	"github.com/cortexlabs/cortex/pkg/lib/slices"
+	"github.com/cortexlabs/cortex/pkg/operator/audit"
+	"github.com/cortexlabs/cortex/pkg/operator/history"
+	"github.com/cortexlabs/cortex/pkg/operator/resources"
+	"github.com/cortexlabs/cortex/pkg/operator/schema"
+	"github.com/cortexlabs/cortex/pkg/types/spec"
+)
+
+var operatorLogger = logging.GetLogger()
+
+// the state of the previous reconciliation is persisted so that it survives operator restarts
+type state struct {
+	Revision string   `json:"revision"`
+	APINames []string `json:"api_names"` // the apis which are managed by the source
+	Status   string   `json:"status"`
+}
+
+func stateKey() string {
+	return filepath.Join(config.ClusterConfig.ClusterUID, "gitops", "state.json")
+}
+
+func readState() (state, error) {
+	var st state
+	if err := config.AWS.ReadJSONFromS3(&st, config.ClusterConfig.Bucket, stateKey()); err != nil {
+		if aws.IsNoSuchKeyErr(err) {
+			return state{}, nil
+		}
+		return state{}, err
+	}
+	return st, nil
+}
+
+// Reconcile updates the deployed apis to match the api configurations in the cluster's gitops source;
+// only the configuration files which differ from the deployed apis are deployed
+func Reconcile() error {
+	gitOps := config.ClusterConfig.GitOps
+
+	var token string
+	if gitOps.TokenSecret != nil {
+		secretValue, err := config.AWS.GetSecretValue(*gitOps.TokenSecret)
+		if err != nil {
+			return err
+		}
+		token = strings.TrimSpace(secretValue)
+	}
+
+	source, err := fetchSource(gitOps, token)
+	if err != nil {
+		return err
+	}
+
+	prevState, err := readState()
+	if err != nil {
+		return err
+	}
+
+	var errMessages []string
+	var apiNames []string
+
+	for _, fileName := range source.SortedFileNames() {
+		fileBytes := source.Files[fileName]
+
+		apiConfigs, err := spec.ExtractAPIConfigs(fileBytes, fileName)
+		if err != nil {
+			errMessages = append(errMessages, errors.Message(err))
+			continue
+		}
+		for _, apiConfig := range apiConfigs {
+			apiNames = append(apiNames, apiConfig.Name)
+		}
+
+		diffResults, err := resources.DeployDiff(fileName, fileBytes)
+		if err != nil {
+			errMessages = append(errMessages, errors.Message(err))
+			continue
+		}
+		if !hasChanges(diffResults) {
+			continue
+		}
+
+		deployResults, err := resources.Deploy(fileName, fileBytes, false, false)
+		if err != nil {
+			errMessages = append(errMessages, errors.Message(err))
+			continue
+		}
+		audit.RecordDeployResults(audit.GitOpsActor, deployResults)
+		history.RecordDeployResults(audit.GitOpsActor, deployResults)
+		for _, result := range deployResults {
+			if result.Error != "" {
+				errMessages = append(errMessages, result.Error)
+			} else {
+				operatorLogger.Infof("gitops (%s): %s", source.Revision, result.Message)
+			}
+		}
+	}
+
+	currentState := state{
+		Revision: source.Revision,
+		APINames: apiNames,
+	}
+
+	for _, apiName := range prevState.APINames {
+		if slices.HasString(apiNames, apiName) {
+			continue
+		}
+
+		if !gitOps.Prune {
+			continue
+		}
+
+		// apis are only pruned if all configurations were applied successfully, so that e.g. a malformed file doesn't cause its apis to be deleted
+		if len(errMessages) > 0 {
+			currentState.APINames = append(currentState.APINames, apiName)
+			continue
+		}
+
+		if _, err := resources.DeleteAPI(apiName, false); err != nil && errors.GetKind(err) != resources.ErrAPINotDeployed {
+			audit.RecordError(audit.GitOpsActor, schema.AuditActionDelete, apiName, err)
+			errMessages = append(errMessages, errors.Message(err))
+			currentState.APINames = append(currentState.APINames, apiName) // retry on the next sync
+			continue
+		}
+		audit.Record(audit.GitOpsActor, schema.AuditEvent{
+			Action:  schema.AuditActionDelete,
+			APIName: apiName,
+			Message: fmt.Sprintf("pruned (revision %s)", source.Revision),
+		})
+		operatorLogger.Infof("gitops (%s): deleted %s", source.Revision, apiName)
+	}
+
+	status := commitStatus{
+		State:       _commitStatusSuccess,
+		Description: "all apis are up to date",
+		Context:     "cortex/" + config.ClusterConfig.ClusterName,
+	}
+	if len(errMessages) > 0 {
+		status.State = _commitStatusFailure
+		status.Description = strings.Split(errMessages[0], "\n")[0]
+	}
+	currentState.Status = status.State
+
+	if token != "" && (currentState.Revision != prevState.Revision || currentState.Status != prevState.Status) {
+		if owner, repo, ok := githubRepo(gitOps.Source); ok {
+			if err := setGitHubCommitStatus(owner, repo, source.Revision, token, status); err != nil {
+				errMessages = append(errMessages, errors.Message(err))
+			}
+		}
+	}
+
+	if err := config.AWS.UploadJSONToS3(currentState, config.ClusterConfig.Bucket, stateKey()); err != nil {
+		return err
+	}
+
+	if len(errMessages) > 0 {
+		return ErrorReconciliationFailed(source.Revision, errMessages)
+	}
+
+	return nil
+}
+
+func hasChanges(diffResults []schema.DeployDiffResult) bool {
+	for _, result := range diffResults {
+		if result.IsNew || len(result.Diffs) > 0 || result.Error != "" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,213 @@
+/*
+Copyright 2021 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gitops
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/cortexlabs/cortex/pkg/config"
+	"github.com/cortexlabs/cortex/pkg/lib/aws"
+	"github.com/cortexlabs/cortex/pkg/lib/files"
+	"github.com/cortexlabs/cortex/pkg/lib/hash"
+	"github.com/cortexlabs/cortex/pkg/types/clusterconfig"
+)
+
+const (
+	_gitRepoDir     = "/tmp/gitops"
+	_gitAskPassPath = "/tmp/gitops-askpass.sh"
+	_gitTokenEnvVar = "CORTEX_GITOPS_TOKEN"
+)
+
+// the token is provided to git by this script (via GIT_ASKPASS) rather than in the repository's url, so that it is not written to .git/config
+var _gitAskPassScript = []byte(`#!/bin/sh
+case "$1" in
+  Username*) echo x-access-token ;;
+  *) echo "$` + _gitTokenEnvVar + `" ;;
+esac
+`)
+
+type sourceFiles struct {
+	Revision string            // the commit sha for git sources, or a hash of the objects' etags for s3 sources
+	Files    map[string][]byte // file name (relative to the source's root) -> contents
+}
+
+func (sf *sourceFiles) SortedFileNames() []string {
+	fileNames := make([]string, 0, len(sf.Files))
+	for fileName := range sf.Files {
+		fileNames = append(fileNames, fileName)
+	}
+	sort.Strings(fileNames)
+	return fileNames
+}
+
+func isAPIConfigFile(fileName string) bool {
+	return strings.HasSuffix(fileName, ".yaml") || strings.HasSuffix(fileName, ".yml")
+}
+
+func fetchSource(gitOps *clusterconfig.GitOps, token string) (*sourceFiles, error) {
+	var source *sourceFiles
+	var err error
+
+	if gitOps.IsS3Source() {
+		source, err = fetchS3Source(gitOps.Source)
+	} else {
+		source, err = fetchGitSource(gitOps, token)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if len(source.Files) == 0 {
+		return nil, ErrorNoAPIConfigsFound(gitOps.Source)
+	}
+
+	return source, nil
+}
+
+func fetchS3Source(s3Path string) (*sourceFiles, error) {
+	bucket, prefix, err := aws.SplitS3Path(s3Path)
+	if err != nil {
+		return nil, err
+	}
+
+	objects, err := config.AWS.ListS3Prefix(bucket, prefix, false, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	source := &sourceFiles{
+		Files: map[string][]byte{},
+	}
+
+	var revisionParts []string
+	for _, object := range objects {
+		if !isAPIConfigFile(*object.Key) {
+			continue
+		}
+
+		fileBytes, err := config.AWS.ReadBytesFromS3(bucket, *object.Key)
+		if err != nil {
+			return nil, err
+		}
+
+		source.Files[strings.TrimPrefix(*object.Key, prefix)] = fileBytes
+		revisionParts = append(revisionParts, *object.Key+"@"+*object.ETag)
+	}
+
+	sort.Strings(revisionParts)
+	source.Revision = hash.String(strings.Join(revisionParts, ","))[:12]
+
+	return source, nil
+}
+
+// the repository is cloned once and then fetched on each sync; only the latest commit of the branch is downloaded
+func fetchGitSource(gitOps *clusterconfig.GitOps, token string) (*sourceFiles, error) {
+	if token != "" {
+		if err := ioutil.WriteFile(_gitAskPassPath, _gitAskPassScript, 0700); err != nil {
+			return nil, err
+		}
+	}
+
+	if !files.IsDir(filepath.Join(_gitRepoDir, ".git")) {
+		if err := os.RemoveAll(_gitRepoDir); err != nil {
+			return nil, err
+		}
+		if _, err := runGit(token, "clone", "--depth", "1", "--branch", gitOps.Branch, "--", gitOps.Source, _gitRepoDir); err != nil {
+			return nil, err
+		}
+	} else {
+		if _, err := runGit(token, "-C", _gitRepoDir, "fetch", "--depth", "1", "--", gitOps.Source, gitOps.Branch); err != nil {
+			return nil, err
+		}
+		if _, err := runGit(token, "-C", _gitRepoDir, "reset", "--hard", "FETCH_HEAD"); err != nil {
+			return nil, err
+		}
+	}
+
+	revision, err := runGit(token, "-C", _gitRepoDir, "rev-parse", "HEAD")
+	if err != nil {
+		return nil, err
+	}
+
+	source := &sourceFiles{
+		Revision: revision,
+		Files:    map[string][]byte{},
+	}
+
+	rootDir := filepath.Join(_gitRepoDir, gitOps.Path)
+	err = filepath.Walk(rootDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			if info.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !isAPIConfigFile(path) {
+			return nil
+		}
+
+		fileBytes, err := files.ReadFileBytes(path)
+		if err != nil {
+			return err
+		}
+
+		fileName, err := filepath.Rel(_gitRepoDir, path)
+		if err != nil {
+			return err
+		}
+		source.Files[fileName] = fileBytes
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return source, nil
+}
+
+// the token is passed to git in an environment variable which is read by the askpass script; it is also removed from the command's output as a precaution
+func runGit(token string, args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+	if token != "" {
+		cmd.Env = append(cmd.Env, "GIT_ASKPASS="+_gitAskPassPath, _gitTokenEnvVar+"="+token)
+	}
+	output, err := cmd.CombinedOutput()
+
+	outputStr := strings.TrimSpace(string(output))
+	if token != "" {
+		outputStr = strings.ReplaceAll(outputStr, token, "***")
+	}
+
+	if err != nil {
+		command := args[0]
+		if command == "-C" && len(args) > 2 {
+			command = args[2]
+		}
+		return "", ErrorGitCommandFailed(command, outputStr)
+	}
+
+	return outputStr, nil
+}
@@ -0,0 +1,42 @@
+/*
+Copyright 2021 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package history
+
+import (
+	"fmt"
+
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+)
+
+const (
+	ErrRevisionNotFound   = "history.revision_not_found"
+	ErrNoPreviousRevision = "history.no_previous_revision"
+)
+
+func ErrorRevisionNotFound(apiName string, revision int) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrRevisionNotFound,
+		Message: fmt.Sprintf("revision %d of api %s does not exist; run `cortex get %s --history` to see its revisions", revision, apiName, apiName),
+	})
+}
+
+func ErrorNoPreviousRevision(apiName string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrNoPreviousRevision,
+		Message: fmt.Sprintf("api %s does not have a previous revision to roll back to", apiName),
+	})
+}
@@ -0,0 +1,254 @@
+/*
+Copyright 2021 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package history
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cortexlabs/cortex/pkg/config"
+	"github.com/cortexlabs/cortex/pkg/lib/cast"
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/lib/logging"
+	"github.com/cortexlabs/cortex/pkg/operator/audit"
+	"github.com/cortexlabs/cortex/pkg/operator/schema"
+	"github.com/cortexlabs/cortex/pkg/types/spec"
+	"github.com/cortexlabs/cortex/pkg/types/userconfig"
+	"github.com/cortexlabs/yaml"
+)
+
+var operatorLogger = logging.GetLogger()
+
+// revisions are stored outside of the api's directory, so that they are retained when the api is deleted:
+// <cluster_uid>/history/<api_name>/<revision>.json
+func keysPrefix(apiName string) string {
+	return filepath.Join(config.ClusterConfig.ClusterUID, "history", apiName) + "/"
+}
+
+func revisionKey(apiName string, revision int) string {
+	return keysPrefix(apiName) + fmt.Sprintf("%08d.json", revision)
+}
+
+func parseRevisionKey(key string) (int, bool) {
+	revision, err := strconv.Atoi(strings.TrimSuffix(filepath.Base(key), ".json"))
+	if err != nil || revision <= 0 {
+		return 0, false
+	}
+	return revision, true
+}
+
+// revisionNumbers returns the api's revision numbers in ascending order
+func revisionNumbers(apiName string) ([]int, error) {
+	objects, err := config.AWS.ListS3Prefix(config.ClusterConfig.Bucket, keysPrefix(apiName), false, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	revisions := make([]int, 0, len(objects))
+	for _, object := range objects {
+		if object.Key == nil {
+			continue
+		}
+		if revision, ok := parseRevisionKey(*object.Key); ok {
+			revisions = append(revisions, revision)
+		}
+	}
+	sort.Ints(revisions)
+
+	return revisions, nil
+}
+
+// Record stores the api's configuration as a new revision, unless it is the same as the api's latest revision;
+// failing to record a revision is logged, but doesn't fail the deployment which is being recorded
+func Record(actor audit.Actor, api spec.API, rollbackOf *int) {
+	if err := record(actor, api, rollbackOf); err != nil {
+		operatorLogger.Error(errors.Wrap(err, "failed to record deployment revision", api.Name))
+	}
+}
+
+func record(actor audit.Actor, api spec.API, rollbackOf *int) error {
+	revisions, err := revisionNumbers(api.Name)
+	if err != nil {
+		return err
+	}
+
+	nextRevision := 1
+	if len(revisions) > 0 {
+		latest, err := Get(api.Name, revisions[len(revisions)-1])
+		if err != nil {
+			return err
+		}
+		if latest.SpecID == api.SpecID {
+			return nil
+		}
+		nextRevision = latest.Revision + 1
+	}
+
+	configBytes, err := yaml.Marshal(api.SubmittedAPISpec)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	revision := schema.DeploymentRevision{
+		Revision:   nextRevision,
+		Time:       time.Now().UTC(),
+		APIName:    api.Name,
+		APIKind:    api.Kind.String(),
+		SpecID:     api.SpecID,
+		Principal:  actor.Principal,
+		ClientID:   actor.ClientID,
+		SourceIP:   actor.SourceIP,
+		RollbackOf: rollbackOf,
+		Config:     string(configBytes),
+
+		ModelVersions: api.ModelVersions(),
+	}
+
+	return config.AWS.UploadJSONToS3(revision, config.ClusterConfig.Bucket, revisionKey(api.Name, nextRevision))
+}
+
+func RecordDeployResults(actor audit.Actor, results []schema.DeployResult) {
+	for _, result := range results {
+		if result.Error != "" || result.API == nil {
+			continue
+		}
+		Record(actor, result.API.Spec, nil)
+	}
+}
+
+// List returns the api's revisions (most recent first), without their configurations
+func List(apiName string) ([]schema.DeploymentRevision, error) {
+	revisionNums, err := revisionNumbers(apiName)
+	if err != nil {
+		return nil, err
+	}
+
+	revisions := make([]schema.DeploymentRevision, 0, len(revisionNums))
+	for i := len(revisionNums) - 1; i >= 0; i-- {
+		revision, err := Get(apiName, revisionNums[i])
+		if err != nil {
+			return nil, err
+		}
+		revision.Config = ""
+		revisions = append(revisions, *revision)
+	}
+
+	return revisions, nil
+}
+
+func Get(apiName string, revision int) (*schema.DeploymentRevision, error) {
+	key := revisionKey(apiName, revision)
+
+	exists, err := config.AWS.IsS3File(config.ClusterConfig.Bucket, key)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, ErrorRevisionNotFound(apiName, revision)
+	}
+
+	var deploymentRevision schema.DeploymentRevision
+	if err := config.AWS.ReadJSONFromS3(&deploymentRevision, config.ClusterConfig.Bucket, key); err != nil {
+		return nil, err
+	}
+
+	return &deploymentRevision, nil
+}
+
+// Previous returns the revision which was deployed before the api's latest revision
+func Previous(apiName string) (*schema.DeploymentRevision, error) {
+	revisions, err := revisionNumbers(apiName)
+	if err != nil {
+		return nil, err
+	}
+	if len(revisions) < 2 {
+		return nil, ErrorNoPreviousRevision(apiName)
+	}
+
+	return Get(apiName, revisions[len(revisions)-2])
+}
+
+// ConfigFileBytes returns the revision's configuration in the format of an api configuration file (i.e. a list of apis)
+func ConfigFileBytes(revision schema.DeploymentRevision) ([]byte, error) {
+	var apiConfig interface{}
+	if err := yaml.Unmarshal([]byte(revision.Config), &apiConfig); err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	if len(revision.ModelVersions) > 0 {
+		apiConfig = pinModelVersions(apiConfig, revision.ModelVersions)
+	}
+
+	configBytes, err := yaml.Marshal([]interface{}{apiConfig})
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return configBytes, nil
+}
+
+// pinModelVersions sets the version of each of the api's model registry references to the version which was deployed in the revision,
+// so that rolling back to the revision restores its models (rather than using the versions which are currently in the registry's stages)
+func pinModelVersions(apiConfig interface{}, modelVersions map[string]string) interface{} {
+	casted, ok := cast.JSONMarshallable(apiConfig)
+	if !ok {
+		return apiConfig
+	}
+	configMap, ok := casted.(map[string]interface{})
+	if !ok {
+		return apiConfig
+	}
+
+	pinVersion := func(model interface{}, version string) {
+		modelMap, ok := model.(map[string]interface{})
+		if !ok {
+			return
+		}
+		modelRegistry, ok := modelMap[userconfig.ModelRegistryKey].(map[string]interface{})
+		if !ok {
+			return
+		}
+		modelRegistry[userconfig.ModelVersionKey] = version
+		delete(modelRegistry, userconfig.StageKey)
+	}
+
+	if version, ok := modelVersions[userconfig.ModelCacheKey]; ok {
+		if pod, ok := configMap[userconfig.PodKey].(map[string]interface{}); ok {
+			pinVersion(pod[userconfig.ModelCacheKey], version)
+		}
+	}
+
+	if models, ok := configMap[userconfig.ModelsKey].(map[string]interface{}); ok {
+		modelPaths, _ := models[userconfig.ModelPathsKey].([]interface{})
+		for _, modelPath := range modelPaths {
+			modelPathMap, ok := modelPath.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			modelName, _ := modelPathMap[userconfig.NameKey].(string)
+			if version, ok := modelVersions[modelName]; ok {
+				pinVersion(modelPathMap, version)
+			}
+		}
+	}
+
+	return configMap
+}
@@ -35,6 +35,28 @@ import (
 // the number of in-flight requests / messages
 type GetInFlightFunc func(apiName string, window time.Duration) (*float64, error)
 
+// QueueMetrics holds the metrics which the autoscaler reads directly from an api's queue
+type QueueMetrics struct {
+	QueueLength      float64  // the number of messages waiting to be picked up
+	OldestMessageAge *float64 // in seconds; nil if not available
+}
+
+// GetQueueMetricsFunc is the function signature used by the autoscaler to retrieve
+// the current queue metrics of queue-backed apis
+type GetQueueMetricsFunc func() (*QueueMetrics, error)
+
+// ServingReplicasFunc is the function signature used by the autoscaler to notify an api
+// of the number of replicas which should serve traffic (the remaining replicas are kept warm)
+type ServingReplicasFunc func(servingReplicas int32) error
+
+const (
+	// the age of the oldest message is read from cloudwatch, which lags behind the queue; when the queue latency is exceeded, a shorter
+	// upscale stabilization period is used (rather than none, so that a single stale datapoint doesn't trigger a scale up), and after a
+	// scale up which was triggered by the queue latency, the latency isn't used again until the new replicas could have affected it
+	_queueLatencyUpscaleStabilizationPeriod = 30 * time.Second
+	_queueLatencyUpscaleCooldown            = 3 * time.Minute
+)
+
 type recommendations map[time.Time]int32
 
 func (recs recommendations) add(rec int32) {
@@ -87,8 +109,10 @@ func (recs recommendations) minSince(period time.Duration) *int32 {
 	return &min
 }
 
-// AutoscaleFn returns the autoscaler function
-func AutoscaleFn(initialDeployment *kapps.Deployment, apiSpec *spec.API, getInFlightFn GetInFlightFunc) (func() error, error) {
+// AutoscaleFn returns the autoscaler function; getQueueMetricsFn is optional, and is only used
+// when the queue-based autoscaling targets are set; servingReplicasFn is optional, and is called
+// after every tick when the api keeps warm replicas
+func AutoscaleFn(initialDeployment *kapps.Deployment, apiSpec *spec.API, getInFlightFn GetInFlightFunc, getQueueMetricsFn GetQueueMetricsFunc, servingReplicasFn ServingReplicasFunc) (func() error, error) {
 	if initialDeployment == nil {
 		if apiSpec != nil {
 			return nil, errors.ErrorUnexpected("unable to find api deployment", apiSpec.Name)
@@ -107,7 +131,10 @@ func AutoscaleFn(initialDeployment *kapps.Deployment, apiSpec *spec.API, getInFl
 	}
 
 	apiName := apiSpec.Name
-	currentReplicas := *initialDeployment.Spec.Replicas
+
+	// warm replicas are part of the deployment, but are not counted as serving replicas by the autoscaler
+	warmReplicas := autoscalingSpec.WarmReplicas
+	currentReplicas := math2.MaxInt32(*initialDeployment.Spec.Replicas-warmReplicas, 0)
 
 	apiLogger, err := operator.GetRealtimeAPILoggerFromSpec(apiSpec)
 	if err != nil {
@@ -116,14 +143,9 @@ func AutoscaleFn(initialDeployment *kapps.Deployment, apiSpec *spec.API, getInFl
 
 	apiLogger.Infof("%s autoscaler init", apiName)
 
-	var startTime time.Time
-	recs := make(recommendations)
-
-	return func() error {
-		if startTime.IsZero() {
-			startTime = time.Now()
-		}
+	scaler := newScaler(autoscalingSpec)
 
+	autoscaleFn := func() error {
 		avgInFlight, err := getInFlightFn(apiName, autoscalingSpec.Window)
 		if err != nil {
 			return err
@@ -133,87 +155,16 @@ func AutoscaleFn(initialDeployment *kapps.Deployment, apiSpec *spec.API, getInFl
 			return nil
 		}
 
-		rawRecommendation := *avgInFlight / *autoscalingSpec.TargetInFlight
-		recommendation := int32(math.Ceil(rawRecommendation))
-
-		if rawRecommendation < float64(currentReplicas) && rawRecommendation > float64(currentReplicas)*(1-autoscalingSpec.DownscaleTolerance) {
-			recommendation = currentReplicas
-		}
-
-		if rawRecommendation > float64(currentReplicas) && rawRecommendation < float64(currentReplicas)*(1+autoscalingSpec.UpscaleTolerance) {
-			recommendation = currentReplicas
-		}
-
-		// always allow subtraction of 1
-		downscaleFactorFloor := math2.MinInt32(currentReplicas-1, int32(math.Ceil(float64(currentReplicas)*autoscalingSpec.MaxDownscaleFactor)))
-		if recommendation < downscaleFactorFloor {
-			recommendation = downscaleFactorFloor
-		}
-
-		// always allow addition of 1
-		upscaleFactorCeil := math2.MaxInt32(currentReplicas+1, int32(math.Ceil(float64(currentReplicas)*autoscalingSpec.MaxUpscaleFactor)))
-		if recommendation > upscaleFactorCeil {
-			recommendation = upscaleFactorCeil
-		}
-
-		if recommendation < autoscalingSpec.MinReplicas {
-			recommendation = autoscalingSpec.MinReplicas
-		}
-
-		if recommendation > autoscalingSpec.MaxReplicas {
-			recommendation = autoscalingSpec.MaxReplicas
-		}
-
-		// Rule of thumb: any modifications that don't consider historical recommendations should be performed before
-		// recording the recommendation, any modifications that use historical recommendations should be performed after
-		recs.add(recommendation)
-
-		// This is just for garbage collection
-		recs.deleteOlderThan(time2.MaxDuration(autoscalingSpec.DownscaleStabilizationPeriod, autoscalingSpec.UpscaleStabilizationPeriod))
-
-		request := recommendation
-		var downscaleStabilizationFloor *int32
-		var upscaleStabilizationCeil *int32
-
-		if request < currentReplicas {
-			downscaleStabilizationFloor = recs.maxSince(autoscalingSpec.DownscaleStabilizationPeriod)
-			if time.Since(startTime) < autoscalingSpec.DownscaleStabilizationPeriod {
-				request = currentReplicas
-			} else if downscaleStabilizationFloor != nil && request < *downscaleStabilizationFloor {
-				request = *downscaleStabilizationFloor
-			}
-		}
-		if request > currentReplicas {
-			upscaleStabilizationCeil = recs.minSince(autoscalingSpec.UpscaleStabilizationPeriod)
-			if time.Since(startTime) < autoscalingSpec.UpscaleStabilizationPeriod {
-				request = currentReplicas
-			} else if upscaleStabilizationCeil != nil && request > *upscaleStabilizationCeil {
-				request = *upscaleStabilizationCeil
+		var queueMetrics *QueueMetrics
+		if getQueueMetricsFn != nil && (autoscalingSpec.TargetQueueLengthPerReplica != nil || autoscalingSpec.MaxQueueLatency != nil) {
+			queueMetrics, err = getQueueMetricsFn()
+			if err != nil {
+				return err
 			}
 		}
 
-		apiLogger.Debugw(fmt.Sprintf("%s autoscaler tick", apiName),
-			"autoscaling", map[string]interface{}{
-				"avg_in_flight":                  *avgInFlight,
-				"target_in_flight":               *autoscalingSpec.TargetInFlight,
-				"raw_recommendation":             rawRecommendation,
-				"current_replicas":               currentReplicas,
-				"downscale_tolerance":            autoscalingSpec.DownscaleTolerance,
-				"upscale_tolerance":              autoscalingSpec.UpscaleTolerance,
-				"max_downscale_factor":           autoscalingSpec.MaxDownscaleFactor,
-				"downscale_factor_floor":         downscaleFactorFloor,
-				"max_upscale_factor":             autoscalingSpec.MaxUpscaleFactor,
-				"upscale_factor_ceil":            upscaleFactorCeil,
-				"min_replicas":                   autoscalingSpec.MinReplicas,
-				"max_replicas":                   autoscalingSpec.MaxReplicas,
-				"recommendation":                 recommendation,
-				"downscale_stabilization_period": autoscalingSpec.DownscaleStabilizationPeriod.Seconds(),
-				"downscale_stabilization_floor":  downscaleStabilizationFloor,
-				"upscale_stabilization_period":   autoscalingSpec.UpscaleStabilizationPeriod.Seconds(),
-				"upscale_stabilization_ceil":     upscaleStabilizationCeil,
-				"request":                        request,
-			},
-		)
+		request, autoscalingLog := scaler.recommend(currentReplicas, *avgInFlight, queueMetrics)
+		apiLogger.Debugw(fmt.Sprintf("%s autoscaler tick", apiName), "autoscaling", autoscalingLog)
 
 		if currentReplicas != request {
 			apiLogger.Infof("%s autoscaling event: %d -> %d", apiName, currentReplicas, request)
@@ -227,7 +178,8 @@ func AutoscaleFn(initialDeployment *kapps.Deployment, apiSpec *spec.API, getInFl
 				return errors.ErrorUnexpected("unable to find k8s deployment", apiName)
 			}
 
-			deployment.Spec.Replicas = &request
+			deploymentReplicas := request + warmReplicas
+			deployment.Spec.Replicas = &deploymentReplicas
 
 			if _, err := config.K8s.UpdateDeployment(deployment); err != nil {
 				return err
@@ -237,5 +189,156 @@ func AutoscaleFn(initialDeployment *kapps.Deployment, apiSpec *spec.API, getInFl
 		}
 
 		return nil
+	}
+
+	if servingReplicasFn == nil || warmReplicas == 0 {
+		return autoscaleFn, nil
+	}
+
+	return func() error {
+		if err := autoscaleFn(); err != nil {
+			return err
+		}
+		return servingReplicasFn(currentReplicas)
 	}, nil
 }
+
+// scaler holds the state of an api's autoscaler between ticks
+type scaler struct {
+	spec                    *userconfig.Autoscaling
+	startTime               time.Time
+	recs                    recommendations
+	lastQueueLatencyUpscale time.Time
+}
+
+func newScaler(autoscalingSpec *userconfig.Autoscaling) *scaler {
+	return &scaler{
+		spec: autoscalingSpec,
+		recs: make(recommendations),
+	}
+}
+
+// recommend returns the number of replicas which should be requested, and the values which the decision was based on (for logging)
+func (sc *scaler) recommend(currentReplicas int32, avgInFlight float64, queueMetrics *QueueMetrics) (int32, map[string]interface{}) {
+	autoscalingSpec := sc.spec
+
+	if sc.startTime.IsZero() {
+		sc.startTime = time.Now()
+	}
+
+	rawRecommendation := avgInFlight / *autoscalingSpec.TargetInFlight
+
+	queueLatencyExceeded := false
+	queueLatencyCooldown := time.Since(sc.lastQueueLatencyUpscale) < _queueLatencyUpscaleCooldown
+	if queueMetrics != nil {
+		if autoscalingSpec.TargetQueueLengthPerReplica != nil {
+			rawRecommendation = math.Max(rawRecommendation, queueMetrics.QueueLength / *autoscalingSpec.TargetQueueLengthPerReplica)
+		}
+
+		if autoscalingSpec.MaxQueueLatency != nil && queueMetrics.OldestMessageAge != nil && queueMetrics.QueueLength > 0 && !queueLatencyCooldown {
+			maxQueueLatency := autoscalingSpec.MaxQueueLatency.Seconds()
+			if *queueMetrics.OldestMessageAge > maxQueueLatency {
+				queueLatencyExceeded = true
+				// scale proportionally to how far behind the queue is
+				latencyRecommendation := math.Max(float64(currentReplicas), 1) * *queueMetrics.OldestMessageAge / maxQueueLatency
+				rawRecommendation = math.Max(rawRecommendation, latencyRecommendation)
+			}
+		}
+	}
+
+	recommendation := int32(math.Ceil(rawRecommendation))
+
+	if rawRecommendation < float64(currentReplicas) && rawRecommendation > float64(currentReplicas)*(1-autoscalingSpec.DownscaleTolerance) {
+		recommendation = currentReplicas
+	}
+
+	if rawRecommendation > float64(currentReplicas) && rawRecommendation < float64(currentReplicas)*(1+autoscalingSpec.UpscaleTolerance) {
+		recommendation = currentReplicas
+	}
+
+	// always allow subtraction of 1
+	downscaleFactorFloor := math2.MinInt32(currentReplicas-1, int32(math.Ceil(float64(currentReplicas)*autoscalingSpec.MaxDownscaleFactor)))
+	if recommendation < downscaleFactorFloor {
+		recommendation = downscaleFactorFloor
+	}
+
+	// always allow addition of 1
+	upscaleFactorCeil := math2.MaxInt32(currentReplicas+1, int32(math.Ceil(float64(currentReplicas)*autoscalingSpec.MaxUpscaleFactor)))
+	if recommendation > upscaleFactorCeil {
+		recommendation = upscaleFactorCeil
+	}
+
+	if recommendation < autoscalingSpec.MinReplicas {
+		recommendation = autoscalingSpec.MinReplicas
+	}
+
+	if recommendation > autoscalingSpec.MaxReplicas {
+		recommendation = autoscalingSpec.MaxReplicas
+	}
+
+	// Rule of thumb: any modifications that don't consider historical recommendations should be performed before
+	// recording the recommendation, any modifications that use historical recommendations should be performed after
+	sc.recs.add(recommendation)
+
+	// This is just for garbage collection
+	sc.recs.deleteOlderThan(time2.MaxDuration(autoscalingSpec.DownscaleStabilizationPeriod, autoscalingSpec.UpscaleStabilizationPeriod))
+
+	request := recommendation
+	var downscaleStabilizationFloor *int32
+	var upscaleStabilizationCeil *int32
+
+	upscaleStabilizationPeriod := autoscalingSpec.UpscaleStabilizationPeriod
+	if queueLatencyExceeded && upscaleStabilizationPeriod > _queueLatencyUpscaleStabilizationPeriod {
+		upscaleStabilizationPeriod = _queueLatencyUpscaleStabilizationPeriod
+	}
+
+	if request < currentReplicas {
+		downscaleStabilizationFloor = sc.recs.maxSince(autoscalingSpec.DownscaleStabilizationPeriod)
+		if time.Since(sc.startTime) < autoscalingSpec.DownscaleStabilizationPeriod {
+			request = currentReplicas
+		} else if downscaleStabilizationFloor != nil && request < *downscaleStabilizationFloor {
+			request = *downscaleStabilizationFloor
+		}
+	}
+	if request > currentReplicas {
+		upscaleStabilizationCeil = sc.recs.minSince(upscaleStabilizationPeriod)
+		if time.Since(sc.startTime) < upscaleStabilizationPeriod {
+			request = currentReplicas
+		} else if upscaleStabilizationCeil != nil && request > *upscaleStabilizationCeil {
+			request = *upscaleStabilizationCeil
+		}
+	}
+
+	if queueLatencyExceeded && request > currentReplicas {
+		sc.lastQueueLatencyUpscale = time.Now()
+	}
+
+	autoscalingLog := map[string]interface{}{
+		"avg_in_flight":                  avgInFlight,
+		"target_in_flight":               *autoscalingSpec.TargetInFlight,
+		"raw_recommendation":             rawRecommendation,
+		"current_replicas":               currentReplicas,
+		"downscale_tolerance":            autoscalingSpec.DownscaleTolerance,
+		"upscale_tolerance":              autoscalingSpec.UpscaleTolerance,
+		"max_downscale_factor":           autoscalingSpec.MaxDownscaleFactor,
+		"downscale_factor_floor":         downscaleFactorFloor,
+		"max_upscale_factor":             autoscalingSpec.MaxUpscaleFactor,
+		"upscale_factor_ceil":            upscaleFactorCeil,
+		"min_replicas":                   autoscalingSpec.MinReplicas,
+		"max_replicas":                   autoscalingSpec.MaxReplicas,
+		"recommendation":                 recommendation,
+		"downscale_stabilization_period": autoscalingSpec.DownscaleStabilizationPeriod.Seconds(),
+		"downscale_stabilization_floor":  downscaleStabilizationFloor,
+		"upscale_stabilization_period":   upscaleStabilizationPeriod.Seconds(),
+		"upscale_stabilization_ceil":     upscaleStabilizationCeil,
+		"request":                        request,
+	}
+	if queueMetrics != nil {
+		autoscalingLog["queue_length"] = queueMetrics.QueueLength
+		autoscalingLog["oldest_message_age"] = queueMetrics.OldestMessageAge
+		autoscalingLog["queue_latency_exceeded"] = queueLatencyExceeded
+		autoscalingLog["queue_latency_cooldown"] = queueLatencyCooldown
+	}
+
+	return request, autoscalingLog
+}
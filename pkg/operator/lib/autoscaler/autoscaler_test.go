@@ -0,0 +1,139 @@
+/*
+Copyright 2021 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package autoscaler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cortexlabs/cortex/pkg/lib/pointer"
+	"github.com/cortexlabs/cortex/pkg/types/userconfig"
+	"github.com/stretchr/testify/require"
+)
+
+func testAutoscalingSpec() *userconfig.Autoscaling {
+	return &userconfig.Autoscaling{
+		MinReplicas:                  1,
+		MaxReplicas:                  100,
+		TargetInFlight:               pointer.Float64(1),
+		DownscaleStabilizationPeriod: time.Hour,
+		UpscaleStabilizationPeriod:   time.Minute,
+		MaxDownscaleFactor:           0.75,
+		MaxUpscaleFactor:             1.5,
+		DownscaleTolerance:           0.05,
+		UpscaleTolerance:             0.05,
+	}
+}
+
+// newStartedScaler returns a scaler which has been running for longer than the stabilization periods
+func newStartedScaler(autoscalingSpec *userconfig.Autoscaling) *scaler {
+	sc := newScaler(autoscalingSpec)
+	sc.startTime = time.Now().Add(-2 * time.Hour)
+	return sc
+}
+
+func TestRecommendQueueLength(t *testing.T) {
+	autoscalingSpec := testAutoscalingSpec()
+	autoscalingSpec.TargetQueueLengthPerReplica = pointer.Float64(2)
+	autoscalingSpec.UpscaleStabilizationPeriod = 0
+
+	sc := newStartedScaler(autoscalingSpec)
+
+	// the queue length recommends more replicas than the in-flight requests
+	request, _ := sc.recommend(4, 4, &QueueMetrics{QueueLength: 10})
+	require.Equal(t, int32(5), request)
+
+	// the step is capped by max_upscale_factor
+	request, _ = sc.recommend(4, 4, &QueueMetrics{QueueLength: 100})
+	require.Equal(t, int32(6), request)
+
+	// the in-flight requests recommend more replicas than the queue length
+	request, _ = sc.recommend(4, 6, &QueueMetrics{QueueLength: 2})
+	require.Equal(t, int32(6), request)
+
+	// without queue metrics, only the in-flight requests are used
+	request, _ = sc.recommend(4, 4, nil)
+	require.Equal(t, int32(4), request)
+}
+
+func TestRecommendQueueLengthStabilization(t *testing.T) {
+	autoscalingSpec := testAutoscalingSpec()
+	autoscalingSpec.TargetQueueLengthPerReplica = pointer.Float64(2)
+
+	sc := newStartedScaler(autoscalingSpec)
+	sc.recs[time.Now().Add(-30*time.Second)] = 4
+
+	// a lower recommendation within the upscale stabilization period prevents the scale up
+	request, _ := sc.recommend(4, 4, &QueueMetrics{QueueLength: 100})
+	require.Equal(t, int32(4), request)
+}
+
+func TestRecommendQueueLatency(t *testing.T) {
+	autoscalingSpec := testAutoscalingSpec()
+	autoscalingSpec.MaxQueueLatency = pointer.Duration(5 * time.Minute)
+	autoscalingSpec.MaxUpscaleFactor = 10
+
+	sc := newStartedScaler(autoscalingSpec)
+	sc.recs[time.Now().Add(-45*time.Second)] = 4
+
+	// the oldest message is twice as old as allowed, so the replicas are doubled; the lower recommendation from 45 seconds ago
+	// is outside of the shortened upscale stabilization period
+	request, autoscalingLog := sc.recommend(4, 4, &QueueMetrics{QueueLength: 1, OldestMessageAge: pointer.Float64(600)})
+	require.Equal(t, int32(8), request)
+	require.Equal(t, true, autoscalingLog["queue_latency_exceeded"])
+
+	// the age of the oldest message lags behind the queue, so it's ignored until the new replicas could have affected it
+	request, autoscalingLog = sc.recommend(8, 8, &QueueMetrics{QueueLength: 1, OldestMessageAge: pointer.Float64(900)})
+	require.Equal(t, int32(8), request)
+	require.Equal(t, false, autoscalingLog["queue_latency_exceeded"])
+	require.Equal(t, true, autoscalingLog["queue_latency_cooldown"])
+
+	// once the cooldown has passed, the queue latency is used again
+	sc.lastQueueLatencyUpscale = time.Now().Add(-_queueLatencyUpscaleCooldown)
+	sc.recs = make(recommendations)
+	request, _ = sc.recommend(8, 8, &QueueMetrics{QueueLength: 1, OldestMessageAge: pointer.Float64(450)})
+	require.Equal(t, int32(12), request)
+}
+
+func TestRecommendQueueLatencyStabilization(t *testing.T) {
+	autoscalingSpec := testAutoscalingSpec()
+	autoscalingSpec.MaxQueueLatency = pointer.Duration(5 * time.Minute)
+	autoscalingSpec.MaxUpscaleFactor = 10
+
+	sc := newStartedScaler(autoscalingSpec)
+	sc.recs[time.Now().Add(-10*time.Second)] = 4
+
+	// a single datapoint doesn't trigger a scale up if there was a lower recommendation within the shortened stabilization period
+	request, _ := sc.recommend(4, 4, &QueueMetrics{QueueLength: 1, OldestMessageAge: pointer.Float64(600)})
+	require.Equal(t, int32(4), request)
+	require.True(t, sc.lastQueueLatencyUpscale.IsZero())
+
+	// the queue latency isn't used when the queue is empty
+	sc.recs = make(recommendations)
+	request, _ = sc.recommend(4, 4, &QueueMetrics{QueueLength: 0, OldestMessageAge: pointer.Float64(600)})
+	require.Equal(t, int32(4), request)
+}
+
+func TestRecommendQueueLatencyAfterStart(t *testing.T) {
+	autoscalingSpec := testAutoscalingSpec()
+	autoscalingSpec.MaxQueueLatency = pointer.Duration(5 * time.Minute)
+
+	// the shortened upscale stabilization period still applies right after the autoscaler starts
+	sc := newScaler(autoscalingSpec)
+	request, _ := sc.recommend(4, 4, &QueueMetrics{QueueLength: 1, OldestMessageAge: pointer.Float64(600)})
+	require.Equal(t, int32(4), request)
+}
@@ -0,0 +1,107 @@
+/*
+Copyright 2021 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package modelwatch
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/cortexlabs/cortex/pkg/config"
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/lib/logging"
+	"github.com/cortexlabs/cortex/pkg/lib/telemetry"
+	"github.com/cortexlabs/cortex/pkg/operator/audit"
+	"github.com/cortexlabs/cortex/pkg/operator/history"
+	"github.com/cortexlabs/cortex/pkg/operator/operator"
+	"github.com/cortexlabs/cortex/pkg/operator/resources"
+	"github.com/cortexlabs/cortex/pkg/types/spec"
+	"github.com/cortexlabs/cortex/pkg/types/userconfig"
+	"github.com/cortexlabs/yaml"
+)
+
+const CronPeriod = time.Minute
+
+var operatorLogger = logging.GetLogger()
+
+// Reconcile redeploys the apis whose watched model artifacts have changed (i.e. a new version was promoted in the model registry,
+// or the objects at the s3 path were modified); the apis are redeployed from their submitted configurations, which resolves the new
+// artifacts and triggers a rolling update, and the previous revisions are recorded in the apis' history so that they can be rolled back to
+func Reconcile() error {
+	deployments, err := config.K8s.ListDeploymentsWithLabelKeys("apiName")
+	if err != nil {
+		return err
+	}
+
+	for _, deployment := range deployments {
+		apiKind := userconfig.KindFromString(deployment.Labels["apiKind"])
+		if apiKind != userconfig.RealtimeAPIKind && apiKind != userconfig.AsyncAPIKind {
+			continue
+		}
+		if deployment.Labels["cortex.dev/async"] == "gateway" {
+			continue
+		}
+
+		// an api whose artifacts can't be checked shouldn't prevent the other apis from being updated
+		if err := reconcileAPI(deployment.Labels["apiName"], deployment.Labels["apiID"]); err != nil {
+			telemetry.Error(err)
+			operatorLogger.Error(err)
+		}
+	}
+
+	return nil
+}
+
+func reconcileAPI(apiName string, apiID string) error {
+	api, err := operator.DownloadAPISpec(apiName, apiID)
+	if err != nil {
+		return err
+	}
+	if !spec.HasWatchedModels(api.API) {
+		return nil
+	}
+
+	changed, err := spec.WatchedModelsChanged(api.API, config.AWS)
+	if err != nil {
+		return errors.Wrap(err, apiName)
+	}
+	if !changed {
+		return nil
+	}
+
+	configBytes, err := yaml.Marshal([]interface{}{api.SubmittedAPISpec})
+	if err != nil {
+		return errors.Wrap(errors.WithStack(err), apiName)
+	}
+
+	// the api isn't forced, so an api which is still being updated is redeployed during a later reconciliation
+	results, err := resources.Deploy(fmt.Sprintf("%s-model-watch.yaml", apiName), configBytes, false, false)
+	if err != nil {
+		return errors.Wrap(err, apiName)
+	}
+	audit.RecordDeployResults(audit.ModelWatchActor, results)
+	history.RecordDeployResults(audit.ModelWatchActor, results)
+
+	for _, result := range results {
+		if result.Error != "" {
+			operatorLogger.Warnf("model watch: the model artifacts of %s changed, but it could not be redeployed: %s", apiName, result.Error)
+			continue
+		}
+		operatorLogger.Infof("model watch: the model artifacts of %s changed; %s", apiName, result.Message)
+	}
+
+	return nil
+}
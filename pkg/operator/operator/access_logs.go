@@ -0,0 +1,76 @@
+/*
+Copyright 2021 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package operator
+
+import (
+	"github.com/cortexlabs/cortex/pkg/config"
+	"github.com/cortexlabs/cortex/pkg/lib/k8s"
+	"github.com/cortexlabs/cortex/pkg/lib/urls"
+	"github.com/cortexlabs/cortex/pkg/types/userconfig"
+	"github.com/cortexlabs/cortex/pkg/workloads"
+)
+
+// ApplyAPIAccessLogs makes the API gateway log (a sample of) the requests to the API's endpoint to its stdout,
+// from where they are forwarded to CloudWatch along with the rest of the cluster's logs
+func ApplyAPIAccessLogs(api *userconfig.API) error {
+	if api.Networking == nil || api.Networking.AccessLogs == nil {
+		return DeleteAPIAccessLogs(api.Name)
+	}
+
+	endpoint := urls.CanonicalizeEndpoint(*api.Networking.Endpoint)
+	paths := []string{"/*"}
+	if endpoint != "/" {
+		paths = []string{endpoint, endpoint + "/*", endpoint + "?*"}
+	}
+
+	_, err := config.K8sIstio.ApplyEnvoyFilter(k8s.AccessLogEnvoyFilter(&k8s.AccessLogEnvoyFilterSpec{
+		Name:             workloads.AccessLogsK8sName(api.Name),
+		WorkloadSelector: _apiGatewaySelector,
+		Paths:            paths,
+		SampleRate:       api.Networking.AccessLogs.SampleRate,
+		Fields: map[string]interface{}{
+			"timestamp":       "%START_TIME%",
+			"api_name":        api.Name,
+			"api_kind":        api.Kind.String(),
+			"request_id":      "%REQ(X-REQUEST-ID)%",
+			"client_ip":       "%DOWNSTREAM_REMOTE_ADDRESS_WITHOUT_PORT%",
+			"x_forwarded_for": "%REQ(X-FORWARDED-FOR)%",
+			"method":          "%REQ(:METHOD)%",
+			"path":            "%REQ(X-ENVOY-ORIGINAL-PATH?:PATH)%",
+			"protocol":        "%PROTOCOL%",
+			"status_code":     "%RESPONSE_CODE%",
+			"response_flags":  "%RESPONSE_FLAGS%",
+			"bytes_received":  "%BYTES_RECEIVED%",
+			"bytes_sent":      "%BYTES_SENT%",
+			"duration_ms":     "%DURATION%",
+			"upstream_host":   "%UPSTREAM_HOST%",
+			"user_agent":      "%REQ(USER-AGENT)%",
+			"authority":       "%REQ(:AUTHORITY)%",
+		},
+		Labels: map[string]string{
+			"apiName":        api.Name,
+			"apiKind":        api.Kind.String(),
+			"cortex.dev/api": "true",
+		},
+	}))
+	return err
+}
+
+func DeleteAPIAccessLogs(apiName string) error {
+	_, err := config.K8sIstio.DeleteEnvoyFilter(workloads.AccessLogsK8sName(apiName))
+	return err
+}
@@ -0,0 +1,345 @@
+/*
+Copyright 2021 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package operator
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/cortexlabs/cortex/pkg/config"
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/lib/k8s"
+	"github.com/cortexlabs/cortex/pkg/types/clusterconfig"
+	"github.com/cortexlabs/cortex/pkg/types/userconfig"
+	"github.com/cortexlabs/cortex/pkg/workloads"
+	"github.com/cortexlabs/yaml"
+)
+
+const (
+	_alertmanagerConfigSecretName = "alertmanager-main" // must match the name of the Alertmanager resource
+	_clusterAlertRulesName        = "cortex-cluster-alerts"
+	_alertReceiverLabel           = "cortex_receiver"
+)
+
+// the rules must be labeled this way to be selected by the cluster's Prometheus resource
+var _alertRuleLabels = map[string]string{
+	"prometheus":     "k8s",
+	"cortex.dev/api": "true",
+}
+
+// ApplyAlertmanagerConfig writes the cluster's alert receivers into the config secret which is loaded by alertmanager;
+// alerts are routed to the receiver named in their cortex_receiver label, and to the default receiver otherwise
+func ApplyAlertmanagerConfig() error {
+	alerting := config.ClusterConfig.Alerting
+	if alerting == nil {
+		return nil
+	}
+
+	routes := make([]map[string]interface{}, 0, len(alerting.Receivers))
+	receivers := make([]map[string]interface{}, 0, len(alerting.Receivers))
+	for _, receiver := range alerting.Receivers {
+		routes = append(routes, map[string]interface{}{
+			"receiver": receiver.Name,
+			"match": map[string]string{
+				_alertReceiverLabel: receiver.Name,
+			},
+		})
+		receivers = append(receivers, alertmanagerReceiver(receiver))
+	}
+
+	alertmanagerConfig := map[string]interface{}{
+		"route": map[string]interface{}{
+			"receiver":        alerting.DefaultReceiver,
+			"group_by":        []string{"alertname", "api_name"},
+			"group_wait":      "30s",
+			"group_interval":  "5m",
+			"repeat_interval": "4h",
+			"routes":          routes,
+		},
+		"receivers": receivers,
+	}
+
+	alertmanagerConfigBytes, err := yaml.Marshal(alertmanagerConfig)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	_, err = config.K8s.ApplySecret(k8s.Secret(&k8s.SecretSpec{
+		Name: _alertmanagerConfigSecretName,
+		Data: map[string][]byte{
+			"alertmanager.yaml": alertmanagerConfigBytes,
+		},
+	}))
+	return err
+}
+
+func alertmanagerReceiver(receiver *clusterconfig.AlertReceiver) map[string]interface{} {
+	alertmanagerReceiver := map[string]interface{}{
+		"name": receiver.Name,
+	}
+
+	switch {
+	case receiver.Slack != nil:
+		slackConfig := map[string]interface{}{
+			"api_url":       receiver.Slack.WebhookURL,
+			"send_resolved": true,
+			"title":         `[{{ .Status | toUpper }}] {{ .CommonLabels.alertname }}`,
+			"text":          `{{ range .Alerts }}{{ .Annotations.description }}` + "\n" + `{{ end }}`,
+		}
+		if receiver.Slack.Channel != nil {
+			slackConfig["channel"] = *receiver.Slack.Channel
+		}
+		alertmanagerReceiver["slack_configs"] = []map[string]interface{}{slackConfig}
+	case receiver.PagerDuty != nil:
+		alertmanagerReceiver["pagerduty_configs"] = []map[string]interface{}{
+			{
+				"routing_key":   receiver.PagerDuty.RoutingKey,
+				"send_resolved": true,
+			},
+		}
+	case receiver.Webhook != nil:
+		alertmanagerReceiver["webhook_configs"] = []map[string]interface{}{
+			{
+				"url":           receiver.Webhook.URL,
+				"send_resolved": true,
+			},
+		}
+	}
+
+	return alertmanagerReceiver
+}
+
+// ApplyClusterAlertRules creates the alert rules which aren't specific to an API
+func ApplyClusterAlertRules() error {
+	if config.ClusterConfig.Alerting == nil {
+		_, err := config.K8s.DeletePrometheusRule(_clusterAlertRulesName)
+		return err
+	}
+
+	defaultRules := config.ClusterConfig.Alerting.Rules
+	var rules []k8s.PrometheusAlertRule
+
+	if defaultRules.NodeNotReadyPeriod != nil {
+		period, err := promDuration(*defaultRules.NodeNotReadyPeriod)
+		if err != nil {
+			return err
+		}
+
+		rules = append(rules, k8s.PrometheusAlertRule{
+			Alert: "NodeNotReady",
+			Expr:  `kube_node_status_condition{condition="Ready", status="true"} == 0`,
+			For:   period,
+			Labels: map[string]string{
+				"severity": "critical",
+			},
+			Annotations: map[string]string{
+				"description": fmt.Sprintf("node {{ $labels.node }} has not been ready for more than %s", *defaultRules.NodeNotReadyPeriod),
+			},
+		})
+	}
+
+	if defaultRules.JobTimedOut != nil && *defaultRules.JobTimedOut {
+		rules = append(rules, k8s.PrometheusAlertRule{
+			Alert: "JobTimedOut",
+			Expr:  `time() - cortex_job_last_timed_out_timestamp_seconds < 300`,
+			Labels: map[string]string{
+				"severity": "warning",
+			},
+			Annotations: map[string]string{
+				"description": "a job of {{ $labels.api_name }} was terminated for exceeding its timeout",
+			},
+		})
+	}
+
+	if len(rules) == 0 {
+		_, err := config.K8s.DeletePrometheusRule(_clusterAlertRulesName)
+		return err
+	}
+
+	_, err := config.K8s.ApplyPrometheusRule(k8s.PrometheusRule(&k8s.PrometheusRuleSpec{
+		Name: _clusterAlertRulesName,
+		Groups: []k8s.PrometheusRuleGroup{
+			{
+				Name:  "cortex-cluster",
+				Rules: rules,
+			},
+		},
+		Labels: map[string]string{
+			"prometheus": "k8s",
+		},
+	}))
+	return err
+}
+
+// ApplyAPIAlertRules creates the API's alert rules by merging the API's alerting overrides with the cluster's default rules
+func ApplyAPIAlertRules(api *userconfig.API) error {
+	if config.ClusterConfig.Alerting == nil || (api.Alerting != nil && api.Alerting.Disabled) {
+		return DeleteAPIAlertRules(api.Name)
+	}
+
+	if api.Kind != userconfig.RealtimeAPIKind && api.Kind != userconfig.AsyncAPIKind {
+		return nil
+	}
+
+	rules, err := apiAlertRules(api)
+	if err != nil {
+		return err
+	}
+	if len(rules) == 0 {
+		return DeleteAPIAlertRules(api.Name)
+	}
+
+	labels := map[string]string{
+		"apiName": api.Name,
+		"apiKind": api.Kind.String(),
+	}
+	for key, value := range _alertRuleLabels {
+		labels[key] = value
+	}
+
+	_, err = config.K8s.ApplyPrometheusRule(k8s.PrometheusRule(&k8s.PrometheusRuleSpec{
+		Name: workloads.AlertRulesK8sName(api.Name),
+		Groups: []k8s.PrometheusRuleGroup{
+			{
+				Name:  workloads.K8sName(api.Name),
+				Rules: rules,
+			},
+		},
+		Labels: labels,
+	}))
+	return err
+}
+
+func DeleteAPIAlertRules(apiName string) error {
+	_, err := config.K8s.DeletePrometheusRule(workloads.AlertRulesK8sName(apiName))
+	return err
+}
+
+func apiAlertRules(api *userconfig.API) ([]k8s.PrometheusAlertRule, error) {
+	defaultRules := config.ClusterConfig.Alerting.Rules
+	overrides := api.Alerting
+	if overrides == nil {
+		overrides = &userconfig.Alerting{}
+	}
+
+	labels := map[string]string{
+		"api_name": api.Name,
+		"api_kind": api.Kind.String(),
+	}
+	if overrides.Receiver != nil {
+		labels[_alertReceiverLabel] = *overrides.Receiver
+	}
+
+	var rules []k8s.PrometheusAlertRule
+
+	errorRate := defaultRules.APIErrorRate
+	if overrides.ErrorRate != nil {
+		errorRate = overrides.ErrorRate
+	}
+	if errorRate != nil {
+		// ratio of 5xx responses over all responses in the last 5 minutes (async APIs are measured at their gateway)
+		rules = append(rules, k8s.PrometheusAlertRule{
+			Alert: "APIHighErrorRate",
+			Expr: fmt.Sprintf(
+				"sum(rate(istio_requests_total{destination_service_name=~\"api-%s.+\", response_code=~\"5.*\"}[5m])) / "+
+					"sum(rate(istio_requests_total{destination_service_name=~\"api-%s.+\"}[5m])) > %g",
+				api.Name, api.Name, *errorRate,
+			),
+			For:    "5m",
+			Labels: withSeverity(labels, "critical"),
+			Annotations: map[string]string{
+				"description": fmt.Sprintf("more than %g%% of the requests to %s have returned 5xx status codes in the last 5 minutes", *errorRate*100, api.Name),
+			},
+		})
+	}
+
+	pendingReplicasPeriod, err := durationOverride(defaultRules.PendingReplicasPeriod, overrides.PendingReplicasPeriod)
+	if err != nil {
+		return nil, err
+	}
+	if pendingReplicasPeriod != nil {
+		rules = append(rules, k8s.PrometheusAlertRule{
+			Alert: "APIPendingReplicas",
+			Expr: fmt.Sprintf(
+				"kube_deployment_spec_replicas{deployment=\"%s\"} - kube_deployment_status_replicas_available{deployment=\"%s\"} > 0",
+				workloads.K8sName(api.Name), workloads.K8sName(api.Name),
+			),
+			For:    promDurationFromDuration(*pendingReplicasPeriod),
+			Labels: withSeverity(labels, "warning"),
+			Annotations: map[string]string{
+				"description": fmt.Sprintf("%s has had replicas which weren't available for more than %s", api.Name, pendingReplicasPeriod.String()),
+			},
+		})
+	}
+
+	if api.Kind == userconfig.AsyncAPIKind {
+		queueAge, err := durationOverride(defaultRules.QueueAge, overrides.QueueAge)
+		if err != nil {
+			return nil, err
+		}
+		if queueAge != nil {
+			rules = append(rules, k8s.PrometheusAlertRule{
+				Alert: "APIQueueAgeTooHigh",
+				Expr: fmt.Sprintf(
+					"max(cortex_async_queue_oldest_message_age_seconds{api_name=\"%s\"}) > %d",
+					api.Name, int64(queueAge.Seconds()),
+				),
+				Labels: withSeverity(labels, "warning"),
+				Annotations: map[string]string{
+					"description": fmt.Sprintf("the oldest message in %s's queue is older than %s", api.Name, queueAge.String()),
+				},
+			})
+		}
+	}
+
+	return rules, nil
+}
+
+func durationOverride(defaultDuration *string, override *time.Duration) (*time.Duration, error) {
+	if override != nil {
+		return override, nil
+	}
+	if defaultDuration == nil {
+		return nil, nil
+	}
+	duration, err := time.ParseDuration(*defaultDuration)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return &duration, nil
+}
+
+func promDuration(durationStr string) (string, error) {
+	duration, err := time.ParseDuration(durationStr)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	return promDurationFromDuration(duration), nil
+}
+
+// prometheus doesn't accept fractional durations, so the duration is rounded to the second
+func promDurationFromDuration(duration time.Duration) string {
+	return fmt.Sprintf("%ds", int64(duration.Seconds()))
+}
+
+func withSeverity(labels map[string]string, severity string) map[string]string {
+	labelsWithSeverity := map[string]string{"severity": severity}
+	for key, value := range labels {
+		labelsWithSeverity[key] = value
+	}
+	return labelsWithSeverity
+}
@@ -0,0 +1,171 @@
+/*
+Copyright 2021 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package operator
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"time"
+
+	"github.com/cortexlabs/cortex/pkg/config"
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	libtime "github.com/cortexlabs/cortex/pkg/lib/time"
+	kcore "k8s.io/api/core/v1"
+)
+
+const (
+	CertificateRotationCronPeriod = 6 * time.Hour
+
+	_gatewayCertsSecretName    = "istio-customgateway-certs" // created by the cluster installer
+	_gatewayRestartedAtKey     = "cortex.dev/certificate-rotated-at"
+	_certificateRenewalDivisor = 4 // the certificate is renewed once a quarter of its validity remains
+)
+
+// the ingress gateways which serve the self-signed certificate
+var _gatewayDeploymentNames = []string{"ingressgateway-operator", "ingressgateway-apis"}
+
+// RotateGatewayCertificate replaces the self-signed certificate which is served by the load balancers
+// (when an ACM certificate isn't configured) before it expires, and restarts the ingress gateways to pick it up
+func RotateGatewayCertificate() error {
+	if config.ClusterConfig.SSLCertificateARN != nil && config.ClusterConfig.OperatorSSLCertificateARN != nil {
+		return nil
+	}
+
+	secret, err := config.K8sIstio.GetSecret(_gatewayCertsSecretName)
+	if err != nil {
+		return err
+	}
+	if secret == nil {
+		return nil
+	}
+
+	validity := libtime.MustParseDuration(config.ClusterConfig.SelfSignedCertificateValidity)
+
+	cert, err := parseCertificate(secret.Data[kcore.TLSCertKey])
+	if err == nil && !certificateNeedsRotation(cert, validity, time.Now()) {
+		return nil
+	}
+
+	certPEM, keyPEM, err := generateSelfSignedCertificate(validity, time.Now())
+	if err != nil {
+		return err
+	}
+
+	secret.Data = map[string][]byte{
+		kcore.TLSCertKey:       certPEM,
+		kcore.TLSPrivateKeyKey: keyPEM,
+	}
+	if _, err := config.K8sIstio.UpdateSecret(secret); err != nil {
+		return err
+	}
+
+	if err := restartGateways(); err != nil {
+		return err
+	}
+
+	operatorLogger.Infof("rotated the load balancers' self-signed certificate (valid for %s)", validity.String())
+	return nil
+}
+
+// a certificate is rotated once a quarter of the configured validity remains, or if it was issued for longer than the configured validity
+// (e.g. the certificate created by older versions of the cluster installer)
+func certificateNeedsRotation(cert *x509.Certificate, validity time.Duration, now time.Time) bool {
+	if cert.NotAfter.Sub(cert.NotBefore) > validity+time.Hour {
+		return true
+	}
+	return cert.NotAfter.Sub(now) < validity/_certificateRenewalDivisor
+}
+
+func parseCertificate(certPEM []byte) (*x509.Certificate, error) {
+	block, _ := pem.Decode(certPEM)
+	if block == nil {
+		return nil, ErrorInvalidGatewayCertificate()
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, errors.Wrap(ErrorInvalidGatewayCertificate(), err.Error())
+	}
+	return cert, nil
+}
+
+func generateSelfSignedCertificate(validity time.Duration, now time.Time) ([]byte, []byte, error) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return nil, nil, errors.WithStack(err)
+	}
+
+	serialNumber, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, nil, errors.WithStack(err)
+	}
+
+	template := x509.Certificate{
+		SerialNumber: serialNumber,
+		Subject: pkix.Name{
+			Country:    []string{"US"},
+			CommonName: "localhost",
+		},
+		NotBefore:             now,
+		NotAfter:              now.Add(validity),
+		KeyUsage:              x509.KeyUsageKeyEncipherment | x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+	}
+
+	certDER, err := x509.CreateCertificate(rand.Reader, &template, &template, &key.PublicKey, key)
+	if err != nil {
+		return nil, nil, errors.WithStack(err)
+	}
+
+	var certPEM, keyPEM bytes.Buffer
+	if err := pem.Encode(&certPEM, &pem.Block{Type: "CERTIFICATE", Bytes: certDER}); err != nil {
+		return nil, nil, errors.WithStack(err)
+	}
+	if err := pem.Encode(&keyPEM, &pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)}); err != nil {
+		return nil, nil, errors.WithStack(err)
+	}
+
+	return certPEM.Bytes(), keyPEM.Bytes(), nil
+}
+
+// the gateways load the certificate when they start, so they are restarted (with a rolling update) after it's rotated
+func restartGateways() error {
+	for _, deploymentName := range _gatewayDeploymentNames {
+		deployment, err := config.K8sIstio.GetDeployment(deploymentName)
+		if err != nil {
+			return err
+		}
+		if deployment == nil {
+			continue
+		}
+
+		if deployment.Spec.Template.Annotations == nil {
+			deployment.Spec.Template.Annotations = map[string]string{}
+		}
+		deployment.Spec.Template.Annotations[_gatewayRestartedAtKey] = time.Now().UTC().Format(time.RFC3339)
+
+		if _, err := config.K8sIstio.UpdateDeployment(deployment); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+/*
+Copyright 2021 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package operator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateSelfSignedCertificate(t *testing.T) {
+	now := time.Now()
+	certPEM, keyPEM, err := generateSelfSignedCertificate(90*24*time.Hour, now)
+	require.NoError(t, err)
+	require.NotEmpty(t, keyPEM)
+
+	cert, err := parseCertificate(certPEM)
+	require.NoError(t, err)
+	require.Equal(t, "localhost", cert.Subject.CommonName)
+	require.WithinDuration(t, now.Add(90*24*time.Hour), cert.NotAfter, time.Second)
+
+	_, err = parseCertificate([]byte("not a certificate"))
+	require.Error(t, err)
+}
+
+func TestCertificateNeedsRotation(t *testing.T) {
+	validity := 100 * 24 * time.Hour
+	now := time.Now()
+
+	certPEM, _, err := generateSelfSignedCertificate(validity, now)
+	require.NoError(t, err)
+	cert, err := parseCertificate(certPEM)
+	require.NoError(t, err)
+
+	require.False(t, certificateNeedsRotation(cert, validity, now))
+	require.False(t, certificateNeedsRotation(cert, validity, now.Add(74*24*time.Hour)))
+	require.True(t, certificateNeedsRotation(cert, validity, now.Add(76*24*time.Hour)))
+
+	// the certificate was issued for longer than the configured validity
+	require.True(t, certificateNeedsRotation(cert, validity/2, now))
+}
@@ -0,0 +1,61 @@
+/*
+Copyright 2021 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package operator
+
+import (
+	"github.com/cortexlabs/cortex/pkg/config"
+	"github.com/cortexlabs/cortex/pkg/lib/k8s"
+	"github.com/cortexlabs/cortex/pkg/lib/urls"
+	"github.com/cortexlabs/cortex/pkg/types/userconfig"
+	"github.com/cortexlabs/cortex/pkg/workloads"
+)
+
+var _apiGatewaySelector = map[string]string{
+	"istio": "ingressgateway-apis",
+}
+
+// ApplyAPICIDRWhiteList makes the API load balancer deny the requests to the API's endpoint which don't originate from the API's CIDR white list;
+// it is applied before the API's virtual service, so that the endpoint isn't reachable from other addresses in the meantime
+func ApplyAPICIDRWhiteList(api *userconfig.API) error {
+	if api.Networking == nil || len(api.Networking.CIDRWhiteList) == 0 {
+		return DeleteAPICIDRWhiteList(api.Name)
+	}
+
+	endpoint := urls.CanonicalizeEndpoint(*api.Networking.Endpoint)
+	paths := []string{"/*"}
+	if endpoint != "/" {
+		paths = []string{endpoint, endpoint + "/*", endpoint + "?*"}
+	}
+
+	_, err := config.K8sIstio.ApplyAuthorizationPolicy(k8s.IPWhiteListPolicy(&k8s.IPWhiteListPolicySpec{
+		Name:             workloads.CIDRWhiteListK8sName(api.Name),
+		WorkloadSelector: _apiGatewaySelector,
+		Paths:            paths,
+		IPBlocks:         api.Networking.CIDRWhiteList,
+		Labels: map[string]string{
+			"apiName":        api.Name,
+			"apiKind":        api.Kind.String(),
+			"cortex.dev/api": "true",
+		},
+	}))
+	return err
+}
+
+func DeleteAPICIDRWhiteList(apiName string) error {
+	_, err := config.K8sIstio.DeleteAuthorizationPolicy(workloads.CIDRWhiteListK8sName(apiName))
+	return err
+}
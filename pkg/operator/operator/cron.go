@@ -17,8 +17,6 @@ limitations under the License.
 package operator
 
 import (
-	"strings"
-
 	"github.com/cortexlabs/cortex/pkg/config"
 	"github.com/cortexlabs/cortex/pkg/lib/aws"
 	"github.com/cortexlabs/cortex/pkg/lib/errors"
@@ -26,8 +24,10 @@ import (
 	"github.com/cortexlabs/cortex/pkg/lib/logging"
 	libmath "github.com/cortexlabs/cortex/pkg/lib/math"
 	"github.com/cortexlabs/cortex/pkg/lib/sets/strset"
+	"github.com/cortexlabs/cortex/pkg/lib/slices"
 	"github.com/cortexlabs/cortex/pkg/lib/telemetry"
 	"github.com/cortexlabs/cortex/pkg/types/clusterconfig"
+	"github.com/cortexlabs/cortex/pkg/workloads"
 	kmeta "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -73,7 +73,7 @@ type instanceInfo struct {
 	Count         int32   `json:"count" yaml:"count"`
 	Memory        int64   `json:"memory" yaml:"memory"`
 	CPU           float64 `json:"cpu" yaml:"cpu"`
-	GPU           int64   `json:"gpu" yaml:"gpu"`
+	GPU           float64 `json:"gpu" yaml:"gpu"`
 	Inf           int64   `json:"inf" yaml:"inf"`
 }
 
@@ -112,10 +112,7 @@ func clusterTelemetryProperties() (map[string]interface{}, error) {
 			instanceType = "unknown"
 		}
 
-		isSpot := false
-		if strings.Contains(strings.ToLower(node.Labels["lifecycle"]), "spot") {
-			isSpot = true
-		}
+		isSpot := workloads.IsSpotNode(&node)
 
 		totalInstances++
 
@@ -143,7 +140,6 @@ func clusterTelemetryProperties() (map[string]interface{}, error) {
 		onDemandPrice += ebsPricePerVolume
 		price += ebsPricePerVolume
 
-		gpuQty := node.Status.Capacity["nvidia.com/gpu"]
 		infQty := node.Status.Capacity["aws.amazon.com/neuron"]
 
 		info := instanceInfo{
@@ -154,7 +150,7 @@ func clusterTelemetryProperties() (map[string]interface{}, error) {
 			Count:         1,
 			Memory:        node.Status.Capacity.Memory().Value(),
 			CPU:           float64(node.Status.Capacity.Cpu().MilliValue()) / 1000,
-			GPU:           gpuQty.Value(),
+			GPU:           workloads.GPUsOnNode(&node, node.Status.Capacity),
 			Inf:           infQty.Value(),
 		}
 
@@ -163,7 +159,7 @@ func clusterTelemetryProperties() (map[string]interface{}, error) {
 		totalInstancePriceIfOnDemand += info.OnDemandPrice
 	}
 
-	fixedPrice := clusterFixedPrice()
+	fixedPrice := ClusterFixedPrice()
 
 	return map[string]interface{}{
 		"region":                      config.ClusterConfig.Region,
@@ -178,30 +174,34 @@ func clusterTelemetryProperties() (map[string]interface{}, error) {
 }
 
 func getEBSPriceForNodeGroupInstance(ngs []*clusterconfig.NodeGroup, ngName string) float64 {
-	var ebsPrice float64
 	for _, ng := range ngs {
-		var ngNamePrefix string
-		if ng.Spot {
-			ngNamePrefix = "cx-ws-"
-		} else {
-			ngNamePrefix = "cx-wd-"
-		}
-		if ng.Name == ngNamePrefix+ngName {
-			ebsPrice = aws.EBSMetadatas[config.ClusterConfig.Region][ng.InstanceVolumeType.String()].PriceGB * float64(ng.InstanceVolumeSize) / 30 / 24
-			if ng.InstanceVolumeType == clusterconfig.IO1VolumeType && ng.InstanceVolumeIOPS != nil {
-				ebsPrice += aws.EBSMetadatas[config.ClusterConfig.Region][ng.InstanceVolumeType.String()].PriceIOPS * float64(*ng.InstanceVolumeIOPS) / 30 / 24
-			}
-			if ng.InstanceVolumeType == clusterconfig.GP3VolumeType && ng.InstanceVolumeIOPS != nil && ng.InstanceVolumeThroughput != nil {
-				ebsPrice += libmath.MaxFloat64(0, (aws.EBSMetadatas[config.ClusterConfig.Region][ng.InstanceVolumeType.String()].PriceIOPS-3000)*float64(*ng.InstanceVolumeIOPS)/30/24)
-				ebsPrice += libmath.MaxFloat64(0, (aws.EBSMetadatas[config.ClusterConfig.Region][ng.InstanceVolumeType.String()].PriceThroughput-125)*float64(*ng.InstanceVolumeThroughput)/30/24)
-			}
-			break
+		if slices.HasString(ng.EKSNames(), ngName) {
+			return ebsPriceForNodeGroupInstance(ng)
 		}
 	}
+	return 0
+}
+
+func ebsPriceForNodeGroupInstance(ng *clusterconfig.NodeGroup) float64 {
+	ebsPrice := aws.EBSMetadatas[config.ClusterConfig.Region][ng.InstanceVolumeType.String()].PriceGB * float64(ng.InstanceVolumeSize) / 30 / 24
+	if ng.InstanceVolumeType == clusterconfig.IO1VolumeType && ng.InstanceVolumeIOPS != nil {
+		ebsPrice += aws.EBSMetadatas[config.ClusterConfig.Region][ng.InstanceVolumeType.String()].PriceIOPS * float64(*ng.InstanceVolumeIOPS) / 30 / 24
+	}
+	if ng.InstanceVolumeType == clusterconfig.GP3VolumeType && ng.InstanceVolumeIOPS != nil && ng.InstanceVolumeThroughput != nil {
+		ebsPrice += libmath.MaxFloat64(0, (aws.EBSMetadatas[config.ClusterConfig.Region][ng.InstanceVolumeType.String()].PriceIOPS-3000)*float64(*ng.InstanceVolumeIOPS)/30/24)
+		ebsPrice += libmath.MaxFloat64(0, (aws.EBSMetadatas[config.ClusterConfig.Region][ng.InstanceVolumeType.String()].PriceThroughput-125)*float64(*ng.InstanceVolumeThroughput)/30/24)
+	}
 	return ebsPrice
 }
 
-func clusterFixedPrice() float64 {
+// NodeGroupInstancePrice returns the hourly on-demand price of an instance in the node group, including its EBS volume
+// (spot prices fluctuate, so the on-demand price is used as an upper bound)
+func NodeGroupInstancePrice(ng *clusterconfig.NodeGroup) float64 {
+	return aws.InstanceMetadatas[config.ClusterConfig.Region][ng.InstanceType].Price + ebsPriceForNodeGroupInstance(ng)
+}
+
+// ClusterFixedPrice returns the hourly price of the resources which don't depend on the workload (e.g. the eks control plane, the cortex system instances, and the load balancers)
+func ClusterFixedPrice() float64 {
 	eksPrice := aws.EKSPrices[config.ClusterConfig.Region]
 	operatorInstancePrice := aws.InstanceMetadatas[config.ClusterConfig.Region]["t3.medium"].Price
 	operatorEBSPrice := aws.EBSMetadatas[config.ClusterConfig.Region]["gp3"].PriceGB * 20 / 30 / 24
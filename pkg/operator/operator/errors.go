@@ -18,15 +18,25 @@ package operator
 
 import (
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/cortexlabs/cortex/pkg/lib/errors"
 	s "github.com/cortexlabs/cortex/pkg/lib/strings"
 )
 
 const (
-	ErrCortexInstallationBroken = "operator.cortex_installation_broken"
-	ErrLoadBalancerInitializing = "operator.load_balancer_initializing"
-	ErrInvalidOperatorLogLevel  = "operator.invalid_operator_log_level"
+	ErrCortexInstallationBroken  = "operator.cortex_installation_broken"
+	ErrLoadBalancerInitializing  = "operator.load_balancer_initializing"
+	ErrInvalidOperatorLogLevel   = "operator.invalid_operator_log_level"
+	ErrSecretIsNotKeyValue       = "operator.secret_is_not_key_value"
+	ErrSecretKeyNotFound         = "operator.secret_key_not_found"
+	ErrInvalidGatewayCertificate = "operator.invalid_gateway_certificate"
+	ErrHostNotResolved           = "operator.host_not_resolved"
+	ErrHookFailed                = "operator.hook_failed"
+	ErrHookHTTPStatus            = "operator.hook_http_status"
+	ErrHookJobFailed             = "operator.hook_job_failed"
+	ErrHookTimedOut              = "operator.hook_timed_out"
 )
 
 func ErrorCortexInstallationBroken() error {
@@ -49,3 +59,67 @@ func ErrorInvalidOperatorLogLevel(provided string, loglevels []string) error {
 		Message: fmt.Sprintf("invalid operator log level %s; must be one of %s", provided, s.StrsOr(loglevels)),
 	})
 }
+
+func ErrorSecretIsNotKeyValue(secretID string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrSecretIsNotKeyValue,
+		Message: fmt.Sprintf("secret %s is not a key/value secret (i.e. a json object), so the `key` field cannot be used", s.UserStr(secretID)),
+	})
+}
+
+func ErrorSecretKeyNotFound(key string, secretID string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrSecretKeyNotFound,
+		Message: fmt.Sprintf("key %s was not found in secret %s", s.UserStr(key), s.UserStr(secretID)),
+	})
+}
+
+func ErrorInvalidGatewayCertificate() error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrInvalidGatewayCertificate,
+		Message: "the load balancers' certificate is not a valid PEM-encoded x509 certificate",
+	})
+}
+
+func ErrorHostNotResolved(host string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrHostNotResolved,
+		Message: fmt.Sprintf("unable to resolve the ip addresses of %s", s.UserStr(host)),
+	})
+}
+
+func ErrorHookFailed(apiName string, hookName string, err error) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrHookFailed,
+		Message: fmt.Sprintf("%s hook for api %s failed: %s", hookName, apiName, errors.Message(err)),
+	})
+}
+
+func ErrorHookHTTPStatus(url string, statusCode int, body string) error {
+	message := fmt.Sprintf("%s returned status code %d", url, statusCode)
+	if body = strings.TrimSpace(body); body != "" {
+		message += fmt.Sprintf(" (%s)", body)
+	}
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrHookHTTPStatus,
+		Message: message,
+	})
+}
+
+func ErrorHookJobFailed(exitDescription string) error {
+	message := "the hook's container failed"
+	if exitDescription != "" {
+		message += fmt.Sprintf(" (%s)", exitDescription)
+	}
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrHookJobFailed,
+		Message: message,
+	})
+}
+
+func ErrorHookTimedOut(timeout time.Duration) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrHookTimedOut,
+		Message: fmt.Sprintf("the hook did not finish within its timeout (%s)", timeout.String()),
+	})
+}
@@ -0,0 +1,304 @@
+/*
+Copyright 2021 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package operator
+
+import (
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cortexlabs/cortex/pkg/config"
+	"github.com/cortexlabs/cortex/pkg/lib/aws"
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/lib/k8s"
+	"github.com/cortexlabs/cortex/pkg/lib/pointer"
+	s "github.com/cortexlabs/cortex/pkg/lib/strings"
+	"github.com/cortexlabs/cortex/pkg/operator/lib/routines"
+	"github.com/cortexlabs/cortex/pkg/types/spec"
+	"github.com/cortexlabs/cortex/pkg/types/userconfig"
+	"github.com/cortexlabs/cortex/pkg/workloads"
+	kbatch "k8s.io/api/batch/v1"
+	kcore "k8s.io/api/core/v1"
+)
+
+const (
+	PreDeployHook  = "pre-deploy"
+	PostDeployHook = "post-deploy"
+
+	PostDeployHooksCronPeriod = 10 * time.Second
+
+	// pre-deploy hooks run while the deploy request is being handled, and the cli waits up to 10 minutes for the response;
+	// this is the maximum total timeout of the pre-deploy hooks of the apis in one deployment
+	MaxPreDeployHooksTimeout = 8 * time.Minute
+
+	_hookJobPollPeriod = 2 * time.Second
+	_hookLabelKey      = "cortex.dev/hook"
+
+	// the api id of the rollout which the post-deploy hook ran for, and the hook's result; these annotations are not set by
+	// the api's deployment spec, so they are cleared whenever the api is rolled out again
+	_postDeployHookAPIIDAnnotationKey  = "hooks.cortex.dev/post-deploy-api-id"
+	_postDeployHookResultAnnotationKey = "hooks.cortex.dev/post-deploy-result"
+)
+
+// api ids of the rollouts which have post-deploy hooks that are running (or which finished but haven't been recorded in the deployment yet)
+var _postDeployHooks = map[string]bool{}
+var _postDeployHooksMutex = sync.Mutex{}
+
+// RunPreDeployHook runs the api's pre-deploy hook (if it has one), and returns an error if the hook failed
+func RunPreDeployHook(api *spec.API) error {
+	if api.Hooks == nil || api.Hooks.PreDeploy == nil {
+		return nil
+	}
+	return runHook(api, PreDeployHook, api.Hooks.PreDeploy)
+}
+
+// RunPostDeployHooks starts the post-deploy hooks of the apis which have finished rolling out
+func RunPostDeployHooks() error {
+	deployments, err := config.K8s.ListDeploymentsWithLabelKeys("apiName", "apiID")
+	if err != nil {
+		return err
+	}
+
+	_postDeployHooksMutex.Lock()
+	defer _postDeployHooksMutex.Unlock()
+
+	activeAPIIDs := map[string]bool{}
+	for i := range deployments {
+		deployment := &deployments[i]
+		if deployment.Annotations[userconfig.PostDeployHookAnnotationKey] != "true" {
+			continue
+		}
+
+		apiName := deployment.Labels["apiName"]
+		apiID := deployment.Labels["apiID"]
+		activeAPIIDs[apiID] = true
+
+		if deployment.Annotations[_postDeployHookAPIIDAnnotationKey] == apiID || _postDeployHooks[apiID] {
+			continue
+		}
+		if !k8s.IsDeploymentRolledOut(deployment) {
+			continue
+		}
+
+		_postDeployHooks[apiID] = true
+		routines.RunWithPanicHandler(func() {
+			runPostDeployHook(apiName, apiID)
+		})
+	}
+
+	for apiID := range _postDeployHooks {
+		if !activeAPIIDs[apiID] {
+			delete(_postDeployHooks, apiID)
+		}
+	}
+
+	return nil
+}
+
+func runPostDeployHook(apiName string, apiID string) {
+	api, err := DownloadAPISpec(apiName, apiID)
+	if err != nil {
+		operatorLogger.Error(errors.Wrap(err, "post-deploy hook", apiName))
+		return
+	}
+
+	result := "succeeded"
+	if err := runHook(api, PostDeployHook, api.Hooks.PostDeploy); err != nil {
+		operatorLogger.Error(err)
+		result = "failed: " + errors.Message(err)
+	} else {
+		operatorLogger.Infof("%s hook for api %s succeeded", PostDeployHook, apiName)
+	}
+
+	if err := recordPostDeployHook(api, result); err != nil {
+		operatorLogger.Error(errors.Wrap(err, "post-deploy hook", apiName))
+	}
+}
+
+func recordPostDeployHook(api *spec.API, result string) error {
+	deployment, err := config.K8s.GetDeployment(workloads.K8sName(api.Name))
+	if err != nil {
+		return err
+	}
+	// the api was deleted or rolled out again while the hook was running
+	if deployment == nil || deployment.Labels["apiID"] != api.ID {
+		return nil
+	}
+
+	if deployment.Annotations == nil {
+		deployment.Annotations = map[string]string{}
+	}
+	deployment.Annotations[_postDeployHookAPIIDAnnotationKey] = api.ID
+	deployment.Annotations[_postDeployHookResultAnnotationKey] = result
+
+	_, err = config.K8s.UpdateDeployment(deployment)
+	return err
+}
+
+func runHook(api *spec.API, hookName string, hook *userconfig.Hook) error {
+	var err error
+	if hook.HTTP != nil {
+		err = runHTTPHook(api, hookName, hook)
+	} else {
+		err = runJobHook(api, hookName, hook)
+	}
+
+	if err != nil {
+		return ErrorHookFailed(api.Name, hookName, err)
+	}
+	return nil
+}
+
+func runHTTPHook(api *spec.API, hookName string, hook *userconfig.Hook) error {
+	var body io.Reader
+	if hook.HTTP.Body != nil {
+		body = strings.NewReader(*hook.HTTP.Body)
+	}
+
+	req, err := http.NewRequest(hook.HTTP.Method, hook.HTTP.URL, body)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	for key, value := range hook.HTTP.Headers {
+		req.Header.Set(key, value)
+	}
+	req.Header.Set("X-Cortex-API-Name", api.Name)
+	req.Header.Set("X-Cortex-API-ID", api.ID)
+	req.Header.Set("X-Cortex-Hook", hookName)
+
+	client := &http.Client{Timeout: hook.Timeout}
+	res, err := client.Do(req)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		resBody, _ := ioutil.ReadAll(io.LimitReader(res.Body, 1024))
+		return ErrorHookHTTPStatus(hook.HTTP.URL, res.StatusCode, string(resBody))
+	}
+
+	return nil
+}
+
+func runJobHook(api *spec.API, hookName string, hook *userconfig.Hook) error {
+	job, err := config.K8s.CreateJob(hookJobSpec(api, hookName, hook))
+	if err != nil {
+		return err
+	}
+	defer config.K8s.DeleteJob(job.Name)
+
+	start := time.Now()
+	for {
+		k8sJob, err := config.K8s.GetJob(job.Name)
+		if err != nil {
+			return err
+		}
+		if k8sJob == nil {
+			return errors.ErrorUnexpected("hook job was deleted", api.Name, hookName)
+		}
+
+		if k8sJob.Status.Succeeded > 0 {
+			return nil
+		}
+		if k8sJob.Status.Failed > 0 {
+			return ErrorHookJobFailed(hookJobExitDescription(job.Name))
+		}
+		if time.Since(start) > hook.Timeout {
+			return ErrorHookTimedOut(hook.Timeout)
+		}
+
+		time.Sleep(_hookJobPollPeriod)
+	}
+}
+
+// hookJobExitDescription describes why the hook's container terminated (e.g. "exit code 1"), if it can be determined
+func hookJobExitDescription(jobName string) string {
+	pods, err := config.K8s.ListPodsByLabel("job-name", jobName)
+	if err != nil || len(pods) == 0 {
+		return ""
+	}
+
+	for _, containerStatus := range pods[0].Status.ContainerStatuses {
+		if terminated := containerStatus.State.Terminated; terminated != nil {
+			if terminated.Message != "" {
+				return terminated.Message
+			}
+			return "exit code " + s.Int32(terminated.ExitCode)
+		}
+	}
+
+	return ""
+}
+
+func hookJobSpec(api *spec.API, hookName string, hook *userconfig.Hook) *kbatch.Job {
+	env := []kcore.EnvVar{
+		{Name: "CORTEX_API_NAME", Value: api.Name},
+		{Name: "CORTEX_API_ID", Value: api.ID},
+		{Name: "CORTEX_HOOK", Value: hookName},
+	}
+	for key, value := range hook.Job.Env {
+		env = append(env, kcore.EnvVar{Name: key, Value: value})
+	}
+
+	job := k8s.Job(&k8s.JobSpec{
+		Name:         "hook-" + k8s.RandomName()[:20],
+		Parallelism:  1,
+		BackoffLimit: 0,
+		Labels: workloads.WithUserLabels(api.API, map[string]string{
+			"apiName":     api.Name,
+			"apiID":       api.ID,
+			"apiKind":     api.Kind.String(),
+			_hookLabelKey: hookName,
+		}),
+		PodSpec: k8s.PodSpec{
+			Labels: workloads.WithUserLabels(api.API, map[string]string{
+				_hookLabelKey: hookName,
+			}),
+			Annotations: map[string]string{
+				"cluster-autoscaler.kubernetes.io/safe-to-evict": "false",
+				"karpenter.sh/do-not-evict":                      "true",
+			},
+			K8sPodSpec: kcore.PodSpec{
+				RestartPolicy: "Never",
+				Containers: []kcore.Container{
+					{
+						Name:            "hook",
+						Image:           hook.Job.Image,
+						ImagePullPolicy: kcore.PullAlways,
+						Command:         hook.Job.Command,
+						Args:            hook.Job.Args,
+						Env:             env,
+					},
+				},
+				NodeSelector:       workloads.NodeSelectors(),
+				Tolerations:        workloads.GenerateResourceTolerations(),
+				Affinity:           workloads.GenerateNodeAffinities(api.NodeGroups, api.Arch, aws.OSLinux),
+				ServiceAccountName: workloads.APIServiceAccountName(api.API),
+				ImagePullSecrets:   workloads.ImagePullSecrets(api.API),
+			},
+		},
+	})
+
+	// kubernetes terminates the job if the operator restarts while waiting for it
+	job.Spec.ActiveDeadlineSeconds = pointer.Int64(int64(hook.Timeout.Seconds()))
+
+	return job
+}
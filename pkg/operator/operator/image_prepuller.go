@@ -0,0 +1,140 @@
+/*
+Copyright 2021 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package operator
+
+import (
+	"sort"
+	"time"
+
+	"github.com/cortexlabs/cortex/pkg/config"
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/lib/sets/strset"
+	"github.com/cortexlabs/cortex/pkg/types/spec"
+	"github.com/cortexlabs/cortex/pkg/types/userconfig"
+	"github.com/cortexlabs/cortex/pkg/workloads"
+	kcore "k8s.io/api/core/v1"
+)
+
+const ImagePrePullerCronPeriod = 60 * time.Second
+
+// UpdateImagePrePullers makes sure that every node group with prepull_images enabled has a daemonset
+// which pulls the images of all of the APIs that can be scheduled on that node group
+func UpdateImagePrePullers() error {
+	virtualServices, err := config.K8s.ListVirtualServicesWithLabelKeys("apiName")
+	if err != nil {
+		return err
+	}
+
+	var apiNames []string
+	var apiIDs []string
+	for _, vs := range virtualServices {
+		if vs.Labels["apiKind"] == userconfig.TrafficSplitterKind.String() || vs.Labels["apiKind"] == userconfig.WorkflowKind.String() {
+			continue
+		}
+		apiNames = append(apiNames, vs.Labels["apiName"])
+		apiIDs = append(apiIDs, vs.Labels["apiID"])
+	}
+
+	apis, err := DownloadAPISpecs(apiNames, apiIDs)
+	if err != nil {
+		return err
+	}
+
+	nodeGroupImages, nodeGroupPullSecrets := imagesByNodeGroup(apis)
+
+	var errs []error
+	activePrePullers := strset.New()
+	for _, nodeGroup := range config.ClusterConfig.NodeGroups {
+		images := nodeGroupImages[nodeGroup.Name]
+		if !nodeGroup.PrepullImages || len(images) == 0 {
+			continue
+		}
+
+		sortedImages := images.Slice()
+		sort.Strings(sortedImages)
+
+		var imagePullSecrets []kcore.LocalObjectReference
+		for _, secretName := range nodeGroupPullSecrets[nodeGroup.Name].SliceSorted() {
+			imagePullSecrets = append(imagePullSecrets, kcore.LocalObjectReference{Name: secretName})
+		}
+
+		daemonSet := workloads.ImagePrePullerDaemonSet(nodeGroup, sortedImages, imagePullSecrets)
+		if _, err := config.K8s.ApplyDaemonSet(daemonSet); err != nil {
+			errs = append(errs, errors.Wrap(err, nodeGroup.Name))
+			continue
+		}
+		activePrePullers.Add(daemonSet.Name)
+	}
+
+	daemonSets, err := config.K8s.ListDaemonSetsWithLabelKeys(workloads.ImagePrePullerLabelKey)
+	if err != nil {
+		return err
+	}
+	for _, daemonSet := range daemonSets {
+		if activePrePullers.Has(daemonSet.Name) {
+			continue
+		}
+		if _, err := config.K8s.DeleteDaemonSet(daemonSet.Name); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.FirstError(errs...)
+}
+
+// imagesByNodeGroup returns the images, and the names of the image pull secrets, of the APIs which can be scheduled on each node group
+func imagesByNodeGroup(apis []spec.API) (map[string]strset.Set, map[string]strset.Set) {
+	nodeGroupImages := map[string]strset.Set{}
+	nodeGroupPullSecrets := map[string]strset.Set{}
+	for _, nodeGroupName := range config.ClusterConfig.GetNodeGroupNames() {
+		nodeGroupImages[nodeGroupName] = strset.New()
+		nodeGroupPullSecrets[nodeGroupName] = strset.New()
+	}
+
+	for _, api := range apis {
+		if api.Pod == nil {
+			continue
+		}
+
+		nodeGroupNames := api.NodeGroups
+		if nodeGroupNames == nil {
+			nodeGroupNames = config.ClusterConfig.GetNodeGroupNames()
+		}
+
+		for _, nodeGroupName := range nodeGroupNames {
+			images, ok := nodeGroupImages[nodeGroupName]
+			if !ok {
+				continue
+			}
+			// the api's images can't be pulled on nodes with a different architecture or operating system
+			nodeGroup := config.ClusterConfig.GetNodeGroupByName(nodeGroupName)
+			if nodeGroup.Arch() != api.Arch || nodeGroup.OS() != api.OS {
+				continue
+			}
+			for _, container := range api.Pod.Containers {
+				if container != nil {
+					images.Add(workloads.ImageWithDigest(container.Image, container.ImageDigest))
+				}
+			}
+			if workloads.HasRegistryCredentials(api.API) {
+				nodeGroupPullSecrets[nodeGroupName].Add(workloads.RegistryCredentialsK8sName(api.Name))
+			}
+		}
+	}
+
+	return nodeGroupImages, nodeGroupPullSecrets
+}
@@ -21,11 +21,18 @@ import (
 
 	"github.com/cortexlabs/cortex/pkg/config"
 	"github.com/cortexlabs/cortex/pkg/lib/urls"
+	"github.com/cortexlabs/cortex/pkg/types/clusterconfig"
 	"github.com/cortexlabs/cortex/pkg/types/spec"
 )
 
-// APILoadBalancerURL returns the http endpoint of the ingress load balancer for deployed APIs
+// written by the manager, since the application load balancer and api gateway aren't managed by kubernetes
+const _apiLoadBalancerConfigMapName = "api-load-balancer"
+
+// APILoadBalancerURL returns the endpoint of the ingress load balancer for deployed APIs (or of the api gateway in front of it, if one is configured)
 func APILoadBalancerURL() (string, error) {
+	if config.ClusterConfig.APILoadBalancerType == clusterconfig.ALBLoadBalancerType {
+		return getApplicationLoadBalancerURL()
+	}
 	return getLoadBalancerURL("ingressgateway-apis")
 }
 
@@ -51,6 +58,20 @@ func getLoadBalancerURL(name string) (string, error) {
 	return "http://" + service.Status.LoadBalancer.Ingress[0].IP, nil
 }
 
+func getApplicationLoadBalancerURL() (string, error) {
+	data, _, err := config.K8s.GetConfigMapData(_apiLoadBalancerConfigMapName)
+	if err != nil {
+		return "", err
+	}
+	if data["api_gateway_endpoint"] != "" {
+		return data["api_gateway_endpoint"], nil
+	}
+	if data["load_balancer_endpoint"] == "" {
+		return "", ErrorLoadBalancerInitializing()
+	}
+	return "http://" + data["load_balancer_endpoint"], nil
+}
+
 func APIEndpoint(api *spec.API) (string, error) {
 	var err error
 	baseAPIEndpoint := ""
@@ -59,7 +80,10 @@ func APIEndpoint(api *spec.API) (string, error) {
 	if err != nil {
 		return "", err
 	}
-	baseAPIEndpoint = strings.Replace(baseAPIEndpoint, "https://", "http://", 1)
+	// api gateway http apis only accept https requests
+	if config.ClusterConfig.APIGateway == nil {
+		baseAPIEndpoint = strings.Replace(baseAPIEndpoint, "https://", "http://", 1)
+	}
 
 	return urls.Join(baseAPIEndpoint, *api.Networking.Endpoint), nil
 }
@@ -0,0 +1,71 @@
+/*
+Copyright 2021 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package operator
+
+import (
+	"github.com/cortexlabs/cortex/pkg/config"
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/lib/sets/strset"
+	"github.com/cortexlabs/cortex/pkg/workloads"
+)
+
+// ApplyKarpenterProvisioners makes sure that every node group has a karpenter provisioner and node template which reflect its configuration,
+// and deletes the provisioners and node templates of node groups which were removed from the cluster config
+// (karpenter terminates the instances of deleted provisioners)
+func ApplyKarpenterProvisioners() error {
+	var errs []error
+	activeNames := strset.New()
+	for idx, nodeGroup := range config.ClusterConfig.NodeGroups {
+		if _, err := config.K8s.ApplyKarpenterNodeTemplate(workloads.KarpenterNodeTemplate(nodeGroup)); err != nil {
+			errs = append(errs, errors.Wrap(err, nodeGroup.Name))
+			continue
+		}
+		if _, err := config.K8s.ApplyKarpenterProvisioner(workloads.KarpenterProvisioner(nodeGroup, idx)); err != nil {
+			errs = append(errs, errors.Wrap(err, nodeGroup.Name))
+			continue
+		}
+		activeNames.Add(workloads.KarpenterName(nodeGroup.Name))
+	}
+
+	provisioners, err := config.K8s.ListKarpenterProvisionersWithLabelKeys(workloads.KarpenterLabelKey)
+	if err != nil {
+		return err
+	}
+	for _, provisioner := range provisioners {
+		if activeNames.Has(provisioner.GetName()) {
+			continue
+		}
+		if _, err := config.K8s.DeleteKarpenterProvisioner(provisioner.GetName()); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	nodeTemplates, err := config.K8s.ListKarpenterNodeTemplatesWithLabelKeys(workloads.KarpenterLabelKey)
+	if err != nil {
+		return err
+	}
+	for _, nodeTemplate := range nodeTemplates {
+		if activeNames.Has(nodeTemplate.GetName()) {
+			continue
+		}
+		if _, err := config.K8s.DeleteKarpenterNodeTemplate(nodeTemplate.GetName()); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.FirstError(errs...)
+}
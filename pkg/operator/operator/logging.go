@@ -78,9 +78,10 @@ func getFromCacheOrNil(key string) *zap.SugaredLogger {
 func initializeLogger(key string, level userconfig.LogLevel, fields map[string]interface{}) (*zap.SugaredLogger, error) {
 	loggerConfig := logging.DefaultZapConfig(level, fields)
 
+	logFormat := strings.ToLower(os.Getenv(logging.LogFormatEnvVar))
 	disableJSONLogging := strings.ToLower(os.Getenv("CORTEX_DISABLE_JSON_LOGGING"))
-	if disableJSONLogging == "true" {
-		loggerConfig.Encoding = "console"
+	if logFormat == userconfig.ConsoleLogFormat.String() || disableJSONLogging == "true" {
+		loggerConfig.Encoding = userconfig.ConsoleLogFormat.String()
 	}
 
 	logger, err := loggerConfig.Build()
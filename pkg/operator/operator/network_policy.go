@@ -0,0 +1,155 @@
+/*
+Copyright 2021 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package operator
+
+import (
+	"net"
+	"time"
+
+	"github.com/cortexlabs/cortex/pkg/config"
+	"github.com/cortexlabs/cortex/pkg/lib/aws"
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/lib/sets/strset"
+	"github.com/cortexlabs/cortex/pkg/types/userconfig"
+	"github.com/cortexlabs/cortex/pkg/workloads"
+)
+
+const (
+	// the DNS names in the network policies are re-resolved on every run, since their addresses can change
+	NetworkPolicyCronPeriod = 60 * time.Second
+	// AWS updates its published ip address ranges a few times per week
+	_awsServiceCIDRsRefreshPeriod = 6 * time.Hour
+)
+
+var (
+	_awsServiceCIDRs          []string
+	_awsServiceCIDRsFetchedAt time.Time
+)
+
+// UpdateNetworkPolicies makes sure that every API with ingress or egress rules (or which belongs to a project) has a network policy
+// which reflects them, and deletes the network policies of APIs which no longer need one
+func UpdateNetworkPolicies() error {
+	virtualServices, err := config.K8s.ListVirtualServicesWithLabelKeys("apiName")
+	if err != nil {
+		return err
+	}
+
+	var apiNames []string
+	var apiIDs []string
+	for _, vs := range virtualServices {
+		if vs.Labels["apiKind"] == userconfig.TrafficSplitterKind.String() || vs.Labels["apiKind"] == userconfig.WorkflowKind.String() {
+			continue
+		}
+		apiNames = append(apiNames, vs.Labels["apiName"])
+		apiIDs = append(apiIDs, vs.Labels["apiID"])
+	}
+
+	apis, err := DownloadAPISpecs(apiNames, apiIDs)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	activeNetworkPolicies := strset.New()
+	for i := range apis {
+		api := &apis[i]
+		if !workloads.HasNetworkPolicy(api) {
+			continue
+		}
+
+		var hostIPs []string
+		var awsServiceCIDRs []string
+		if api.Networking.Egress != nil {
+			awsServiceCIDRs, err = getAWSServiceCIDRs()
+			if err != nil {
+				// without the AWS ranges, the api's pods would be unable to reach S3 and SQS; the previous policy (if any) is kept
+				errs = append(errs, errors.Wrap(err, api.Name))
+				activeNetworkPolicies.Add(workloads.NetworkPolicyName(api.Name))
+				continue
+			}
+
+			hostIPs, err = resolveHosts(workloads.NetworkPolicyHosts(api))
+			if err != nil {
+				// the policy is still applied with the addresses which were resolved
+				errs = append(errs, errors.Wrap(err, api.Name))
+			}
+		}
+
+		networkPolicy := workloads.NetworkPolicy(api, hostIPs, awsServiceCIDRs)
+		if _, err := config.K8s.ApplyNetworkPolicy(networkPolicy); err != nil {
+			errs = append(errs, errors.Wrap(err, api.Name))
+			continue
+		}
+		activeNetworkPolicies.Add(networkPolicy.Name)
+	}
+
+	networkPolicies, err := config.K8s.ListNetworkPoliciesWithLabelKeys(workloads.NetworkPolicyLabelKey)
+	if err != nil {
+		return err
+	}
+	for _, networkPolicy := range networkPolicies {
+		if activeNetworkPolicies.Has(networkPolicy.Name) {
+			continue
+		}
+		if _, err := config.K8s.DeleteNetworkPolicy(networkPolicy.Name); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.FirstError(errs...)
+}
+
+// the previously downloaded ranges are used if they can't be refreshed
+func getAWSServiceCIDRs() ([]string, error) {
+	if _awsServiceCIDRs != nil && time.Since(_awsServiceCIDRsFetchedAt) < _awsServiceCIDRsRefreshPeriod {
+		return _awsServiceCIDRs, nil
+	}
+
+	cidrs, err := aws.GetServiceIPRanges(config.ClusterConfig.Region)
+	if err != nil {
+		if _awsServiceCIDRs != nil {
+			operatorLogger.Warn(errors.Wrap(err, "using the previously downloaded AWS ip address ranges"))
+			return _awsServiceCIDRs, nil
+		}
+		return nil, err
+	}
+
+	_awsServiceCIDRs = cidrs
+	_awsServiceCIDRsFetchedAt = time.Now()
+	return _awsServiceCIDRs, nil
+}
+
+// resolveHosts returns the sorted IPv4 addresses of the hosts (so that the generated policy is stable);
+// the hosts which could not be resolved are skipped, and the first error is returned
+func resolveHosts(hosts []string) ([]string, error) {
+	var errs []error
+	ips := strset.New()
+	for _, host := range hosts {
+		addrs, err := net.LookupIP(host)
+		if err != nil {
+			errs = append(errs, errors.Wrap(ErrorHostNotResolved(host), err.Error()))
+			continue
+		}
+		for _, addr := range addrs {
+			if ipv4 := addr.To4(); ipv4 != nil {
+				ips.Add(ipv4.String())
+			}
+		}
+	}
+
+	return ips.SliceSorted(), errors.FirstError(errs...)
+}
@@ -0,0 +1,65 @@
+/*
+Copyright 2021 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package operator
+
+import (
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/lib/telemetry"
+	"github.com/cortexlabs/cortex/pkg/types/userconfig"
+	"github.com/cortexlabs/cortex/pkg/workloads"
+	kapps "k8s.io/api/apps/v1"
+)
+
+// ApplyAPIPodResources applies the k8s resources which are referenced by the API's pods (secrets, registry credentials, runtime config, and the
+// project's service account); it must only be called once the update has passed validation (e.g. the check for an in-progress update),
+// so that a rejected deploy doesn't modify them
+func ApplyAPIPodResources(api *userconfig.API) error {
+	if err := ApplyAPISecrets(api); err != nil {
+		return err
+	}
+	if err := ApplyAPIRegistryCredentials(api); err != nil {
+		return err
+	}
+	if err := ApplyAPIRuntimeConfig(api); err != nil {
+		return err
+	}
+	if err := ApplyProjectServiceAccount(api); err != nil {
+		return err
+	}
+	return nil
+}
+
+// DeleteStaleAPIPodResources deletes the runtime config maps of a Realtime or Async API which are no longer referenced by its
+// current or previous deployment (the previous deployment's replicas may still be running while the update is rolled out);
+// this is not done for Batch and Task APIs, since their running jobs may create pods which reference older config maps.
+// Errors are logged rather than returned, since the update has already been applied
+func DeleteStaleAPIPodResources(api *userconfig.API, prevDeployment *kapps.Deployment) {
+	var inUse []string
+	if api.Pod != nil && api.Pod.Config != nil && len(api.Pod.Config.Files) > 0 {
+		inUse = append(inUse, workloads.RuntimeConfigK8sName(api.Name, api.Pod.Config.Files))
+	}
+	if prevDeployment != nil {
+		if name := workloads.RuntimeConfigK8sNameFromPodSpec(prevDeployment.Spec.Template.Spec); name != "" {
+			inUse = append(inUse, name)
+		}
+	}
+	if err := DeleteStaleAPIRuntimeConfigs(api.Name, inUse...); err != nil {
+		err = errors.Wrap(err, api.Identify(), "delete stale runtime config")
+		telemetry.Error(err)
+		operatorLogger.Error(err)
+	}
+}
@@ -0,0 +1,82 @@
+/*
+Copyright 2021 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package operator
+
+import (
+	"time"
+
+	"github.com/cortexlabs/cortex/pkg/config"
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/lib/sets/strset"
+	"github.com/cortexlabs/cortex/pkg/types/userconfig"
+	"github.com/cortexlabs/cortex/pkg/workloads"
+)
+
+const ProjectServiceAccountsCronPeriod = 1 * time.Hour
+
+// ApplyProjectServiceAccount creates the service account of the API's project if it doesn't exist yet; existing service accounts
+// are not updated, so that annotations which were added by users (e.g. an IAM role for the project's pods) are preserved
+func ApplyProjectServiceAccount(api *userconfig.API) error {
+	if api.Project == nil {
+		return nil
+	}
+
+	serviceAccount, err := config.K8s.GetServiceAccount(workloads.ProjectServiceAccountName(*api.Project))
+	if err != nil {
+		return err
+	}
+	if serviceAccount != nil {
+		return nil
+	}
+
+	_, err = config.K8s.CreateServiceAccount(workloads.ProjectServiceAccount(*api.Project))
+	return err
+}
+
+// DeleteUnusedProjectServiceAccounts deletes the service accounts of projects which no longer have any APIs;
+// recently created service accounts are skipped, since their API's resources may not have been created yet
+func DeleteUnusedProjectServiceAccounts() error {
+	virtualServices, err := config.K8s.ListVirtualServicesWithLabelKeys(workloads.ProjectLabelKey)
+	if err != nil {
+		return err
+	}
+
+	activeProjects := strset.New()
+	for _, vs := range virtualServices {
+		activeProjects.Add(vs.Labels[workloads.ProjectLabelKey])
+	}
+
+	serviceAccounts, err := config.K8s.ListServiceAccountsWithLabelKeys(workloads.ProjectLabelKey)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, serviceAccount := range serviceAccounts {
+		if activeProjects.Has(serviceAccount.Labels[workloads.ProjectLabelKey]) {
+			continue
+		}
+		if time.Since(serviceAccount.CreationTimestamp.Time) < ProjectServiceAccountsCronPeriod {
+			continue
+		}
+		if _, err := config.K8s.DeleteServiceAccount(serviceAccount.Name); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.FirstError(errs...)
+}
@@ -0,0 +1,183 @@
+/*
+Copyright 2021 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package operator
+
+import (
+	"encoding/base64"
+	"time"
+
+	"github.com/cortexlabs/cortex/pkg/config"
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	libjson "github.com/cortexlabs/cortex/pkg/lib/json"
+	"github.com/cortexlabs/cortex/pkg/lib/k8s"
+	"github.com/cortexlabs/cortex/pkg/lib/sets/strset"
+	"github.com/cortexlabs/cortex/pkg/types/spec"
+	"github.com/cortexlabs/cortex/pkg/types/userconfig"
+	"github.com/cortexlabs/cortex/pkg/workloads"
+	kcore "k8s.io/api/core/v1"
+)
+
+const (
+	// ECR tokens are valid for 12 hours
+	RegistryCredentialsCronPeriod = 6 * time.Hour
+
+	RegistryCredentialsLabelKey = "cortex.dev/registry-credentials"
+
+	// the secret which was used to configure registry credentials before registry_credentials could be set in the api and cluster configs
+	_legacyRegistryCredentialsSecretName = "registry-credentials"
+)
+
+type dockerConfigJSON struct {
+	Auths map[string]dockerConfigAuth `json:"auths"`
+}
+
+type dockerConfigAuth struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+	Auth     string `json:"auth"`
+}
+
+// ApplyAPIRegistryCredentials resolves the registry credentials of the API and the cluster, and stores them in the API's image pull secret;
+// credentials in the API spec take precedence over the cluster's, which take precedence over the legacy registry-credentials secret
+// (which would otherwise be ignored, since the service account's image pull secrets don't apply to pods which set their own)
+func ApplyAPIRegistryCredentials(api *userconfig.API) error {
+	if !workloads.HasRegistryCredentials(api) {
+		return DeleteAPIRegistryCredentials(api.Name)
+	}
+
+	dockerConfig, err := legacyDockerConfig()
+	if err != nil {
+		return err
+	}
+
+	var registryCredentials []*userconfig.RegistryCredentials
+	registryCredentials = append(registryCredentials, config.ClusterConfig.RegistryCredentials...)
+	if api.Pod != nil {
+		registryCredentials = append(registryCredentials, api.Pod.RegistryCredentials...)
+	}
+
+	for _, credentials := range registryCredentials {
+		authConfig, err := spec.RegistryAuth(credentials, config.AWS)
+		if err != nil {
+			return errors.Wrap(err, api.Identify(), userconfig.RegistryCredentialsKey, credentials.Registry)
+		}
+		dockerConfig.Auths[authConfig.ServerAddress] = dockerConfigAuth{
+			Username: authConfig.Username,
+			Password: authConfig.Password,
+			Auth:     base64.StdEncoding.EncodeToString([]byte(authConfig.Username + ":" + authConfig.Password)),
+		}
+	}
+
+	dockerConfigBytes, err := libjson.Marshal(dockerConfig)
+	if err != nil {
+		return err
+	}
+
+	_, err = config.K8s.ApplySecret(k8s.Secret(&k8s.SecretSpec{
+		Name: workloads.RegistryCredentialsK8sName(api.Name),
+		Type: kcore.SecretTypeDockerConfigJson,
+		Data: map[string][]byte{
+			kcore.DockerConfigJsonKey: dockerConfigBytes,
+		},
+		Labels: map[string]string{
+			"apiName":                   api.Name,
+			"apiKind":                   api.Kind.String(),
+			"cortex.dev/api":            "true",
+			RegistryCredentialsLabelKey: "true",
+		},
+	}))
+	return err
+}
+
+func DeleteAPIRegistryCredentials(apiName string) error {
+	_, err := config.K8s.DeleteSecret(workloads.RegistryCredentialsK8sName(apiName))
+	return err
+}
+
+// RefreshRegistryCredentials periodically re-applies the image pull secrets of all APIs, since ECR tokens expire
+// (and the credentials in secrets manager may have been rotated); it also deletes image pull secrets of APIs which no longer exist
+func RefreshRegistryCredentials() error {
+	virtualServices, err := config.K8s.ListVirtualServicesWithLabelKeys("apiName")
+	if err != nil {
+		return err
+	}
+
+	var apiNames []string
+	var apiIDs []string
+	for _, vs := range virtualServices {
+		if vs.Labels["apiKind"] == userconfig.TrafficSplitterKind.String() || vs.Labels["apiKind"] == userconfig.WorkflowKind.String() {
+			continue
+		}
+		apiNames = append(apiNames, vs.Labels["apiName"])
+		apiIDs = append(apiIDs, vs.Labels["apiID"])
+	}
+
+	apis, err := DownloadAPISpecs(apiNames, apiIDs)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	activeSecrets := strset.New()
+	for i := range apis {
+		if !workloads.HasRegistryCredentials(apis[i].API) {
+			continue
+		}
+		if err := ApplyAPIRegistryCredentials(apis[i].API); err != nil {
+			errs = append(errs, err)
+		}
+		activeSecrets.Add(workloads.RegistryCredentialsK8sName(apis[i].Name))
+	}
+
+	secrets, err := config.K8s.ListSecretsWithLabelKeys(RegistryCredentialsLabelKey)
+	if err != nil {
+		return err
+	}
+	for _, secret := range secrets {
+		if activeSecrets.Has(secret.Name) {
+			continue
+		}
+		if _, err := config.K8s.DeleteSecret(secret.Name); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.FirstError(errs...)
+}
+
+func legacyDockerConfig() (dockerConfigJSON, error) {
+	dockerConfig := dockerConfigJSON{Auths: map[string]dockerConfigAuth{}}
+
+	secretData, err := config.K8s.GetSecretData(_legacyRegistryCredentialsSecretName)
+	if err != nil {
+		return dockerConfigJSON{}, err
+	}
+
+	authData, ok := secretData[kcore.DockerConfigJsonKey]
+	if !ok {
+		return dockerConfig, nil
+	}
+
+	if err := libjson.Unmarshal(authData, &dockerConfig); err != nil {
+		return dockerConfigJSON{}, errors.Wrap(err, _legacyRegistryCredentialsSecretName)
+	}
+	if dockerConfig.Auths == nil {
+		dockerConfig.Auths = map[string]dockerConfigAuth{}
+	}
+
+	return dockerConfig, nil
+}
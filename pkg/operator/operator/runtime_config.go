@@ -0,0 +1,90 @@
+/*
+Copyright 2021 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package operator
+
+import (
+	"github.com/cortexlabs/cortex/pkg/config"
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/lib/k8s"
+	"github.com/cortexlabs/cortex/pkg/lib/pointer"
+	"github.com/cortexlabs/cortex/pkg/lib/sets/strset"
+	"github.com/cortexlabs/cortex/pkg/types/userconfig"
+	"github.com/cortexlabs/cortex/pkg/workloads"
+)
+
+const RuntimeConfigLabelKey = "cortex.dev/runtime-config"
+
+// ApplyAPIRuntimeConfig stores the API's runtime config files in an immutable config map which is mounted in the API's containers;
+// the config map's name includes a hash of the files, so a config change results in a new pod spec (which is rolled out like any other change),
+// and running replicas keep the files which they were started with (rather than having the new files live-reloaded into them)
+func ApplyAPIRuntimeConfig(api *userconfig.API) error {
+	if api.Pod == nil || api.Pod.Config == nil || len(api.Pod.Config.Files) == 0 {
+		return nil
+	}
+
+	name := workloads.RuntimeConfigK8sName(api.Name, api.Pod.Config.Files)
+	existing, err := config.K8s.GetConfigMap(name)
+	if err != nil {
+		return err
+	}
+	if existing != nil {
+		return nil
+	}
+
+	configMap := k8s.ConfigMap(&k8s.ConfigMapSpec{
+		Name: name,
+		Data: api.Pod.Config.Files,
+		Labels: map[string]string{
+			"apiName":             api.Name,
+			"apiKind":             api.Kind.String(),
+			"cortex.dev/api":      "true",
+			RuntimeConfigLabelKey: "true",
+		},
+	})
+	configMap.Immutable = pointer.Bool(true)
+
+	_, err = config.K8s.CreateConfigMap(configMap)
+	return err
+}
+
+// DeleteStaleAPIRuntimeConfigs deletes the API's runtime config maps, except for the ones which are still in use
+func DeleteStaleAPIRuntimeConfigs(apiName string, inUse ...string) error {
+	configMaps, err := config.K8s.ListConfigMapsByLabels(map[string]string{
+		"apiName":             apiName,
+		RuntimeConfigLabelKey: "true",
+	})
+	if err != nil {
+		return err
+	}
+
+	inUseSet := strset.New(inUse...)
+	var errs []error
+	for _, configMap := range configMaps {
+		if inUseSet.Has(configMap.Name) {
+			continue
+		}
+		if _, err := config.K8s.DeleteConfigMap(configMap.Name); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.FirstError(errs...)
+}
+
+func DeleteAPIRuntimeConfig(apiName string) error {
+	return DeleteStaleAPIRuntimeConfigs(apiName)
+}
@@ -0,0 +1,90 @@
+/*
+Copyright 2021 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package operator
+
+import (
+	"github.com/cortexlabs/cortex/pkg/config"
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	libjson "github.com/cortexlabs/cortex/pkg/lib/json"
+	"github.com/cortexlabs/cortex/pkg/lib/k8s"
+	s "github.com/cortexlabs/cortex/pkg/lib/strings"
+	"github.com/cortexlabs/cortex/pkg/types/userconfig"
+	"github.com/cortexlabs/cortex/pkg/workloads"
+)
+
+// ApplyAPISecrets resolves the API's secrets and stores their values in the API's k8s secret, from which they are exposed to the API's containers;
+// the values are only resolved when the API is created, updated, or refreshed (a deploy of an unchanged API doesn't re-resolve them)
+func ApplyAPISecrets(api *userconfig.API) error {
+	if api.Pod == nil || len(api.Pod.Secrets) == 0 {
+		return DeleteAPISecrets(api.Name)
+	}
+
+	data := map[string][]byte{}
+	for i, secret := range api.Pod.Secrets {
+		value, err := resolveSecret(secret)
+		if err != nil {
+			return errors.Wrap(err, api.Identify(), userconfig.PodKey, userconfig.SecretsKey, s.Index(i))
+		}
+		data[secret.Name] = []byte(value)
+	}
+
+	_, err := config.K8s.ApplySecret(k8s.Secret(&k8s.SecretSpec{
+		Name: workloads.SecretsK8sName(api.Name),
+		Data: data,
+		Labels: map[string]string{
+			"apiName":        api.Name,
+			"apiKind":        api.Kind.String(),
+			"cortex.dev/api": "true",
+		},
+	}))
+	return err
+}
+
+func DeleteAPISecrets(apiName string) error {
+	_, err := config.K8s.DeleteSecret(workloads.SecretsK8sName(apiName))
+	return err
+}
+
+func resolveSecret(secret *userconfig.Secret) (string, error) {
+	if secret.SSMParameter != nil {
+		return config.AWS.GetSSMParameterValue(*secret.SSMParameter)
+	}
+
+	value, err := config.AWS.GetSecretValue(*secret.SecretsManager)
+	if err != nil {
+		return "", err
+	}
+
+	if secret.Key == nil {
+		return value, nil
+	}
+
+	var keyValues map[string]interface{}
+	if err := libjson.Unmarshal([]byte(value), &keyValues); err != nil {
+		return "", ErrorSecretIsNotKeyValue(*secret.SecretsManager)
+	}
+
+	keyValue, ok := keyValues[*secret.Key]
+	if !ok {
+		return "", ErrorSecretKeyNotFound(*secret.Key, *secret.SecretsManager)
+	}
+
+	if keyValueStr, ok := keyValue.(string); ok {
+		return keyValueStr, nil
+	}
+	return s.Obj(keyValue), nil
+}
@@ -33,6 +33,7 @@ import (
 	"github.com/cortexlabs/cortex/pkg/lib/k8s"
 	"github.com/cortexlabs/cortex/pkg/lib/telemetry"
 	"github.com/cortexlabs/cortex/pkg/operator/lib/routines"
+	"github.com/cortexlabs/cortex/pkg/operator/schema"
 	"github.com/cortexlabs/cortex/pkg/types/spec"
 	"github.com/cortexlabs/cortex/pkg/types/status"
 	"github.com/gorilla/websocket"
@@ -46,6 +47,9 @@ const (
 
 	_pendingPodCheckInterval = 1 * time.Second
 	_pollPeriod              = 250 * time.Millisecond
+
+	_logLinesLimit        = 1000
+	_logLinesQueryTimeout = 50 * time.Second
 )
 
 func timeString(t time.Time) string {
@@ -192,6 +196,45 @@ func TaskJobLogURL(apiName string, jobStatus status.TaskJobStatus) (string, erro
 	})
 }
 
+// JobLogLines returns the log lines of a job, which are the log lines of the job's pods and the log lines of cortex's containers whose job_id field is the job's id
+func JobLogLines(apiName string, jobID string, startTime time.Time, endTime time.Time) (*schema.LogLinesResponse, error) {
+	filter := fmt.Sprintf(`cortex.labels.apiName="%s" and (cortex.labels.jobID="%s" or job_id="%s")`, apiName, jobID, jobID)
+	return queryLogLines(filter, startTime, endTime)
+}
+
+// RequestLogLines returns the log lines of a request, which are the log lines of cortex's containers whose request_id field is the request's id,
+// and the log lines of the api's containers which contain the request's id
+func RequestLogLines(apiName string, requestID string, startTime time.Time, endTime time.Time) (*schema.LogLinesResponse, error) {
+	filter := fmt.Sprintf(`(api_name="%s" or cortex.labels.apiName="%s") and (request_id="%s" or @message like "%s")`, apiName, apiName, requestID, requestID)
+	return queryLogLines(filter, startTime, endTime)
+}
+
+func queryLogLines(filter string, startTime time.Time, endTime time.Time) (*schema.LogLinesResponse, error) {
+	query := fmt.Sprintf("fields @timestamp, message, @message\n| filter %s\n| sort @timestamp asc\n| limit %d", filter, _logLinesLimit)
+
+	results, err := config.AWS.QueryLogs(config.ClusterConfig.ClusterName, query, startTime, endTime, _logLinesLimit, _logLinesQueryTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	logLines := make([]schema.LogLine, len(results))
+	for i, result := range results {
+		message := result["message"]
+		if message == "" {
+			message = result["@message"]
+		}
+		logLines[i] = schema.LogLine{
+			Timestamp: result["@timestamp"],
+			Message:   message,
+		}
+	}
+
+	return &schema.LogLinesResponse{
+		Lines:     logLines,
+		Truncated: len(logLines) == _logLinesLimit,
+	}, nil
+}
+
 func waitForPodToBeNotPending(podName string, cancelListener chan struct{}, socket *websocket.Conn) bool {
 	wrotePending := false
 	timer := time.NewTimer(0)
@@ -0,0 +1,77 @@
+/*
+Copyright 2021 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"strings"
+	"time"
+
+	"github.com/cortexlabs/cortex/pkg/lib/integrity"
+	"github.com/cortexlabs/cortex/pkg/operator/schema"
+	"github.com/cortexlabs/cortex/pkg/types/spec"
+	"github.com/cortexlabs/cortex/pkg/workloads"
+	kcore "k8s.io/api/core/v1"
+)
+
+// getArtifactVerification summarizes the termination messages of the downloaders of the api's current replicas
+// (or returns nil if the api's model cache isn't verified)
+func getArtifactVerification(api spec.API, pods []kcore.Pod) *schema.ArtifactVerification {
+	if api.Pod == nil || api.Pod.ModelCache == nil || api.Pod.ModelCache.Integrity == nil {
+		return nil
+	}
+
+	verification := schema.ArtifactVerification{}
+	var lastFailedAt time.Time
+	for _, pod := range pods {
+		if pod.DeletionTimestamp != nil {
+			continue
+		}
+		// replicas of previous versions of the api may have downloaded different artifacts
+		if podID := pod.Labels["podID"]; podID != "" && podID != api.PodID {
+			continue
+		}
+
+		for _, containerStatus := range pod.Status.InitContainerStatuses {
+			if containerStatus.Name != workloads.DownloaderContainerName {
+				continue
+			}
+
+			terminated := containerStatus.State.Terminated
+			if terminated == nil {
+				terminated = containerStatus.LastTerminationState.Terminated
+			}
+			if terminated == nil {
+				continue
+			}
+
+			message := strings.TrimSpace(terminated.Message)
+			switch {
+			case strings.HasPrefix(message, integrity.VerifiedMessagePrefix):
+				verification.VerifiedReplicas++
+				verification.Checksum = strings.TrimPrefix(message, integrity.VerifiedMessagePrefix)
+			case strings.HasPrefix(message, integrity.FailedMessagePrefix):
+				verification.FailedReplicas++
+				if verification.Failure == "" || terminated.FinishedAt.Time.After(lastFailedAt) {
+					verification.Failure = strings.TrimPrefix(message, integrity.FailedMessagePrefix)
+					lastFailedAt = terminated.FinishedAt.Time
+				}
+			}
+		}
+	}
+
+	return &verification
+}
@@ -45,8 +45,9 @@ const (
 )
 
 var (
-	_autoscalerCrons = make(map[string]cron.Cron)
-	_metricsCrons    = make(map[string]cron.Cron)
+	_autoscalerCrons        = make(map[string]cron.Cron)
+	_gatewayAutoscalerCrons = make(map[string]cron.Cron)
+	_metricsCrons           = make(map[string]cron.Cron)
 )
 
 type resources struct {
@@ -81,6 +82,14 @@ func UpdateAPI(apiConfig userconfig.API, force bool) (*spec.API, string, error)
 
 	// resource creation
 	if prevK8sResources.apiDeployment == nil {
+		if err := operator.ApplyAPIPodResources(&apiConfig); err != nil {
+			return nil, "", err
+		}
+
+		if err := operator.RunPreDeployHook(api); err != nil {
+			return nil, "", err
+		}
+
 		if err := config.AWS.UploadJSONToS3(api, config.ClusterConfig.Bucket, api.Key); err != nil {
 			return nil, "", errors.Wrap(err, "upload api spec")
 		}
@@ -121,6 +130,14 @@ func UpdateAPI(apiConfig userconfig.API, force bool) (*spec.API, string, error)
 			return nil, "", ErrorAPIUpdating(api.Name)
 		}
 
+		if err := operator.ApplyAPIPodResources(&apiConfig); err != nil {
+			return nil, "", err
+		}
+
+		if err := operator.RunPreDeployHook(api); err != nil {
+			return nil, "", err
+		}
+
 		if err := config.AWS.UploadJSONToS3(api, config.ClusterConfig.Bucket, api.Key); err != nil {
 			return nil, "", errors.Wrap(err, "upload api spec")
 		}
@@ -134,6 +151,8 @@ func UpdateAPI(apiConfig userconfig.API, force bool) (*spec.API, string, error)
 			return nil, "", err
 		}
 
+		operator.DeleteStaleAPIPodResources(&apiConfig, prevK8sResources.apiDeployment)
+
 		return api, fmt.Sprintf("updating %s", api.Resource.UserString()), nil
 	}
 
@@ -277,7 +296,16 @@ func UpdateAutoscalerCron(deployment *kapps.Deployment, apiSpec spec.API) error
 		prevAutoscalerCron.Cancel()
 	}
 
-	autoscaler, err := autoscalerlib.AutoscaleFn(deployment, &apiSpec, getMessagesInQueue)
+	var queueMetricsFn autoscalerlib.GetQueueMetricsFunc
+	if apiSpec.Autoscaling != nil && (apiSpec.Autoscaling.TargetQueueLengthPerReplica != nil || apiSpec.Autoscaling.MaxQueueLatency != nil) {
+		queueURL, err := getQueueURL(apiName, deployment.Labels["deploymentID"])
+		if err != nil {
+			return err
+		}
+		queueMetricsFn = getQueueMetricsFn(queueURL, apiSpec.Autoscaling.MaxQueueLatency != nil)
+	}
+
+	autoscaler, err := autoscalerlib.AutoscaleFn(deployment, &apiSpec, getMessagesInQueue, queueMetricsFn, nil)
 	if err != nil {
 		return err
 	}
@@ -287,6 +315,30 @@ func UpdateAutoscalerCron(deployment *kapps.Deployment, apiSpec spec.API) error
 	return nil
 }
 
+// UpdateGatewayAutoscalerCron starts the cron which scales the gateway on the rate of submissions
+func UpdateGatewayAutoscalerCron(deployment *kapps.Deployment, apiSpec spec.API) error {
+	// skip api deployments
+	if deployment.Labels["cortex.dev/async"] != "gateway" {
+		return nil
+	}
+
+	apiName := deployment.Labels["apiName"]
+	if prevAutoscalerCron, ok := _gatewayAutoscalerCrons[apiName]; ok {
+		prevAutoscalerCron.Cancel()
+		delete(_gatewayAutoscalerCrons, apiName)
+	}
+
+	// apis which were deployed before the gateway section was added are only scaled by the HPA
+	if apiSpec.Gateway == nil || apiSpec.Autoscaling == nil {
+		return nil
+	}
+
+	autoscaler := gatewayAutoscaleFn(apiName, *apiSpec.Gateway, apiSpec.Autoscaling.MaxReplicas)
+	_gatewayAutoscalerCrons[apiName] = cron.Run(autoscaler, operator.ErrorHandler(apiName+" gateway autoscaler"), spec.AutoscalingTickInterval)
+
+	return nil
+}
+
 func getK8sResources(apiConfig userconfig.API) (resources, error) {
 	var deployment *kapps.Deployment
 	var apiConfigMap *kcore.ConfigMap
@@ -348,7 +400,7 @@ func applyK8sResources(api spec.API, prevK8sResources resources, queueURL string
 		return err
 	}
 	gatewayDeployment := gatewayDeploymentSpec(api, prevK8sResources.gatewayDeployment, queueURL)
-	gatewayHPA, err := gatewayHPASpec(api)
+	gatewayHPA, err := gatewayHPASpec(api, prevK8sResources.gatewayHPA)
 	if err != nil {
 		return err
 	}
@@ -376,10 +428,15 @@ func applyK8sResources(api spec.API, prevK8sResources resources, queueURL string
 			return nil
 		},
 		func() error {
-			return applyK8sDeployment(prevK8sResources.gatewayDeployment, &gatewayDeployment)
-		},
-		func() error {
-			return applyK8sHPA(prevK8sResources.gatewayHPA, &gatewayHPA)
+			if err := applyK8sDeployment(prevK8sResources.gatewayDeployment, &gatewayDeployment); err != nil {
+				return err
+			}
+
+			if err := applyK8sHPA(prevK8sResources.gatewayHPA, &gatewayHPA); err != nil {
+				return err
+			}
+
+			return UpdateGatewayAutoscalerCron(&gatewayDeployment, api)
 		},
 		func() error {
 			return applyK8sService(prevK8sResources.gatewayService, &gatewayService)
@@ -387,6 +444,9 @@ func applyK8sResources(api spec.API, prevK8sResources resources, queueURL string
 		func() error {
 			return applyK8sVirtualService(prevK8sResources.gatewayVirtualService, &gatewayVirtualService)
 		},
+		func() error {
+			return applyK8sPDB(api)
+		},
 	)
 }
 
@@ -460,6 +520,18 @@ func applyK8sVirtualService(prevVirtualService *istioclientnetworking.VirtualSer
 	return err
 }
 
+// the pod disruption budget is only created if max_disrupted_replicas is specified
+func applyK8sPDB(api spec.API) error {
+	if api.Availability == nil || api.Availability.MaxDisruptedReplicas == nil {
+		_, err := config.K8s.DeletePDB(workloads.K8sName(api.Name))
+		return err
+	}
+
+	pdb := pdbSpec(api)
+	_, err := config.K8s.ApplyPDB(&pdb)
+	return err
+}
+
 func deleteBucketResources(apiName string) error {
 	prefix := filepath.Join(config.ClusterConfig.ClusterUID, "apis", apiName)
 	return config.AWS.DeleteS3Dir(config.ClusterConfig.Bucket, prefix, true)
@@ -480,6 +552,11 @@ func deleteK8sResources(apiName string) error {
 				autoscalerCron.Cancel()
 				delete(_autoscalerCrons, apiName)
 			}
+
+			if gatewayAutoscalerCron, ok := _gatewayAutoscalerCrons[apiName]; ok {
+				gatewayAutoscalerCron.Cancel()
+				delete(_gatewayAutoscalerCrons, apiName)
+			}
 			_, err := config.K8s.DeleteDeployment(apiK8sName)
 			return err
 		},
@@ -503,6 +580,10 @@ func deleteK8sResources(apiName string) error {
 			_, err := config.K8s.DeleteVirtualService(apiK8sName)
 			return err
 		},
+		func() error {
+			_, err := config.K8s.DeletePDB(apiK8sName)
+			return err
+		},
 	)
 
 	return err
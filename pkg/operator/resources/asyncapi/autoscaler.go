@@ -19,6 +19,8 @@ package asyncapi
 import (
 	"context"
 	"fmt"
+	"math"
+	"path"
 	"strconv"
 	"time"
 
@@ -27,6 +29,7 @@ import (
 	"github.com/cortexlabs/cortex/pkg/config"
 	"github.com/cortexlabs/cortex/pkg/lib/errors"
 	"github.com/cortexlabs/cortex/pkg/lib/pointer"
+	autoscalerlib "github.com/cortexlabs/cortex/pkg/operator/lib/autoscaler"
 	"github.com/cortexlabs/cortex/pkg/types/userconfig"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
@@ -36,6 +39,10 @@ import (
 const (
 	_sqsQueryTimeoutSeconds        = 10
 	_prometheusQueryTimeoutSeconds = 10
+	_queueAgeUpdatePeriod          = 60 * time.Second // cloudwatch reports sqs metrics at most once per minute
+
+	_gatewaySubmissionRateWindow         = 60 * time.Second
+	_gatewayDownscaleStabilizationPeriod = 5 * time.Minute
 )
 
 var queueLengthGauge = promauto.NewGaugeVec(
@@ -46,44 +53,101 @@ var queueLengthGauge = promauto.NewGaugeVec(
 	}, []string{"api_name"},
 )
 
-func updateQueueLengthMetricsFn(apiName, queueURL string) func() error {
-	return func() error {
-		sqsClient := config.AWS.SQS()
+var queueAgeGauge = promauto.NewGaugeVec(
+	prometheus.GaugeOpts{
+		Name:        "cortex_async_queue_oldest_message_age_seconds",
+		Help:        "The age of the oldest in-queue message for a cortex AsyncAPI",
+		ConstLabels: map[string]string{"api_kind": userconfig.AsyncAPIKind.String()},
+	}, []string{"api_name"},
+)
 
-		ctx, cancel := context.WithTimeout(context.Background(), _sqsQueryTimeoutSeconds*time.Second)
-		defer cancel()
+func updateQueueLengthMetricsFn(apiName, queueURL string) func() error {
+	var lastQueueAgeUpdate time.Time
 
-		input := &sqs.GetQueueAttributesInput{
-			AttributeNames: []*string{
-				aws.String("ApproximateNumberOfMessages"),
-				aws.String("ApproximateNumberOfMessagesNotVisible"),
-			},
-			QueueUrl: aws.String(queueURL),
+	return func() error {
+		// the queue age is only used for alerting, and is fetched from cloudwatch (which is billed per request)
+		if config.ClusterConfig.Alerting != nil && time.Since(lastQueueAgeUpdate) >= _queueAgeUpdatePeriod {
+			queueAge, err := config.AWS.GetLatestSQSOldestMessageAge(path.Base(queueURL))
+			if err != nil {
+				return err
+			}
+			if queueAge != nil {
+				queueAgeGauge.WithLabelValues(apiName).Set(*queueAge)
+			}
+			lastQueueAgeUpdate = time.Now()
 		}
 
-		output, err := sqsClient.GetQueueAttributesWithContext(ctx, input)
+		visibleMessages, invisibleMessages, err := getQueueMessageCounts(queueURL)
 		if err != nil {
 			return err
 		}
 
-		visibleMessagesStr := output.Attributes["ApproximateNumberOfMessages"]
-		invisibleMessagesStr := output.Attributes["ApproximateNumberOfMessagesNotVisible"]
+		queueLength := visibleMessages + invisibleMessages
+		queueLengthGauge.WithLabelValues(apiName).Set(queueLength)
 
-		visibleMessages, err := strconv.ParseFloat(*visibleMessagesStr, 64)
+		return nil
+	}
+}
+
+// getQueueMetricsFn returns the function used by the autoscaler to read the queue metrics directly from sqs;
+// the oldest message age is only fetched (from cloudwatch) if includeOldestMessageAge is set
+func getQueueMetricsFn(queueURL string, includeOldestMessageAge bool) autoscalerlib.GetQueueMetricsFunc {
+	var oldestMessageAge *float64
+	var lastQueueAgeUpdate time.Time
+
+	return func() (*autoscalerlib.QueueMetrics, error) {
+		visibleMessages, _, err := getQueueMessageCounts(queueURL)
 		if err != nil {
-			return err
+			return nil, err
 		}
 
-		invisibleMessages, err := strconv.ParseFloat(*invisibleMessagesStr, 64)
-		if err != nil {
-			return err
+		if includeOldestMessageAge && time.Since(lastQueueAgeUpdate) >= _queueAgeUpdatePeriod {
+			oldestMessageAge, err = config.AWS.GetLatestSQSOldestMessageAge(path.Base(queueURL))
+			if err != nil {
+				return nil, err
+			}
+			lastQueueAgeUpdate = time.Now()
 		}
 
-		queueLength := visibleMessages + invisibleMessages
-		queueLengthGauge.WithLabelValues(apiName).Set(queueLength)
+		return &autoscalerlib.QueueMetrics{
+			QueueLength:      visibleMessages,
+			OldestMessageAge: oldestMessageAge,
+		}, nil
+	}
+}
 
-		return nil
+// getQueueMessageCounts returns the approximate number of visible (waiting) and not visible (in-flight) messages in the queue
+func getQueueMessageCounts(queueURL string) (float64, float64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), _sqsQueryTimeoutSeconds*time.Second)
+	defer cancel()
+
+	input := &sqs.GetQueueAttributesInput{
+		AttributeNames: []*string{
+			aws.String("ApproximateNumberOfMessages"),
+			aws.String("ApproximateNumberOfMessagesNotVisible"),
+		},
+		QueueUrl: aws.String(queueURL),
+	}
+
+	output, err := config.AWS.SQS().GetQueueAttributesWithContext(ctx, input)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	visibleMessagesStr := output.Attributes["ApproximateNumberOfMessages"]
+	invisibleMessagesStr := output.Attributes["ApproximateNumberOfMessagesNotVisible"]
+
+	visibleMessages, err := strconv.ParseFloat(*visibleMessagesStr, 64)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	invisibleMessages, err := strconv.ParseFloat(*invisibleMessagesStr, 64)
+	if err != nil {
+		return 0, 0, err
 	}
+
+	return visibleMessages, invisibleMessages, nil
 }
 
 func getMessagesInQueue(apiName string, window time.Duration) (*float64, error) {
@@ -117,3 +181,88 @@ func getMessagesInQueue(apiName string, window time.Duration) (*float64, error)
 	}
 	return nil, nil
 }
+
+// gatewayAutoscaleFn returns the function which sets the minimum replicas of the gateway's HPA based on the rate of submissions;
+// the HPA can still scale the gateway above that on cpu and memory utilization
+func gatewayAutoscaleFn(apiName string, gateway userconfig.AsyncGateway, maxReplicas int32) func() error {
+	type recommendation struct {
+		replicas  int32
+		timestamp time.Time
+	}
+	var recs []recommendation
+
+	return func() error {
+		submissionRate, err := getGatewaySubmissionRate(apiName, _gatewaySubmissionRateWindow)
+		if err != nil {
+			return err
+		}
+		if submissionRate == nil {
+			return nil
+		}
+
+		replicas := int32(math.Ceil(*submissionRate / gateway.TargetSubmissionRate))
+		if replicas < 1 {
+			replicas = 1
+		}
+		if replicas > maxReplicas {
+			replicas = maxReplicas
+		}
+
+		// only scale down once the submission rate has been low for the whole stabilization period
+		now := time.Now()
+		recs = append(recs, recommendation{replicas: replicas, timestamp: now})
+		for len(recs) > 0 && now.Sub(recs[0].timestamp) > _gatewayDownscaleStabilizationPeriod {
+			recs = recs[1:]
+		}
+		minReplicas := replicas
+		for _, rec := range recs {
+			if rec.replicas > minReplicas {
+				minReplicas = rec.replicas
+			}
+		}
+
+		hpa, err := config.K8s.GetHPA(getGatewayK8sName(apiName))
+		if err != nil {
+			return err
+		}
+		if hpa == nil || (hpa.Spec.MinReplicas != nil && *hpa.Spec.MinReplicas == minReplicas) {
+			return nil
+		}
+
+		hpa.Spec.MinReplicas = pointer.Int32(minReplicas)
+		if _, err := config.K8s.UpdateHPA(hpa); err != nil {
+			return err
+		}
+
+		return nil
+	}
+}
+
+func getGatewaySubmissionRate(apiName string, window time.Duration) (*float64, error) {
+	windowSeconds := int64(window.Seconds())
+
+	// PromQL query:
+	// 	sum(rate(cortex_async_gateway_submissions_total{api_name="<apiName>"}[60s]))
+	query := fmt.Sprintf(
+		"sum(rate(cortex_async_gateway_submissions_total{api_name=\"%s\"}[%ds]))",
+		apiName, windowSeconds,
+	)
+
+	ctx, cancel := context.WithTimeout(context.Background(), _prometheusQueryTimeoutSeconds*time.Second)
+	defer cancel()
+
+	valuesQuery, _, err := config.Prometheus.Query(ctx, query, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	values, ok := valuesQuery.(model.Vector)
+	if !ok {
+		return nil, errors.ErrorUnexpected("failed to convert prometheus metric to vector")
+	}
+
+	if values.Len() != 0 {
+		return pointer.Float64(float64(values[0].Value)), nil
+	}
+	return nil, nil
+}
@@ -20,10 +20,13 @@ import (
 	"fmt"
 
 	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/types/async"
 )
 
 const (
-	ErrAPIUpdating = "asyncapi.api_updating"
+	ErrAPIUpdating      = "asyncapi.api_updating"
+	ErrWorkloadNotFound = "asyncapi.workload_not_found"
+	ErrWorkloadFinished = "asyncapi.workload_finished"
 )
 
 func ErrorAPIUpdating(apiName string) error {
@@ -32,3 +35,17 @@ func ErrorAPIUpdating(apiName string) error {
 		Message: fmt.Sprintf("%s is updating (override with --force)", apiName),
 	})
 }
+
+func ErrorWorkloadNotFound(apiName string, requestID string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrWorkloadNotFound,
+		Message: fmt.Sprintf("request %s was not found for api %s", requestID, apiName),
+	})
+}
+
+func ErrorWorkloadFinished(requestID string, status async.Status) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrWorkloadFinished,
+		Message: fmt.Sprintf("request %s can't be cancelled because its status is %s", requestID, status),
+	})
+}
@@ -18,6 +18,7 @@ package asyncapi
 
 import (
 	"github.com/cortexlabs/cortex/pkg/consts"
+	"github.com/cortexlabs/cortex/pkg/lib/aws"
 	"github.com/cortexlabs/cortex/pkg/lib/k8s"
 	"github.com/cortexlabs/cortex/pkg/lib/pointer"
 	"github.com/cortexlabs/cortex/pkg/types/spec"
@@ -26,6 +27,7 @@ import (
 	kapps "k8s.io/api/apps/v1"
 	kautoscaling "k8s.io/api/autoscaling/v2beta2"
 	kcore "k8s.io/api/core/v1"
+	kpolicy "k8s.io/api/policy/v1beta1"
 )
 
 var _terminationGracePeriodSeconds int64 = 60  // seconds
@@ -63,7 +65,7 @@ func gatewayDeploymentSpec(api spec.API, prevDeployment *kapps.Deployment, queue
 			"apiKind":          api.Kind.String(),
 			"cortex.dev/async": "gateway",
 		},
-		Labels: map[string]string{
+		Labels: workloads.WithUserLabels(api.API, map[string]string{
 			"apiName":          api.Name,
 			"apiKind":          api.Kind.String(),
 			"apiID":            api.ID,
@@ -72,23 +74,24 @@ func gatewayDeploymentSpec(api spec.API, prevDeployment *kapps.Deployment, queue
 			"podID":            api.PodID,
 			"cortex.dev/api":   "true",
 			"cortex.dev/async": "gateway",
-		},
+		}),
 		PodSpec: k8s.PodSpec{
-			Labels: map[string]string{
+			Labels: workloads.WithUserLabels(api.API, map[string]string{
 				"apiName":          api.Name,
 				"apiKind":          api.Kind.String(),
 				"deploymentID":     api.DeploymentID,
 				"podID":            api.PodID,
 				"cortex.dev/api":   "true",
 				"cortex.dev/async": "gateway",
-			},
+			}),
+			Annotations: workloads.APIPodAnnotations(),
 			K8sPodSpec: kcore.PodSpec{
 				RestartPolicy:                 "Always",
 				TerminationGracePeriodSeconds: pointer.Int64(_terminationGracePeriodSeconds),
 				Containers:                    []kcore.Container{container},
 				NodeSelector:                  workloads.NodeSelectors(),
 				Tolerations:                   workloads.GenerateResourceTolerations(),
-				Affinity:                      workloads.GenerateNodeAffinities(api.NodeGroups),
+				Affinity:                      workloads.GenerateNodeAffinities(api.NodeGroups, api.Arch, aws.OSLinux),
 				Volumes:                       volumes,
 				ServiceAccountName:            workloads.ServiceAccountName,
 			},
@@ -96,14 +99,24 @@ func gatewayDeploymentSpec(api spec.API, prevDeployment *kapps.Deployment, queue
 	})
 }
 
-func gatewayHPASpec(api spec.API) (kautoscaling.HorizontalPodAutoscaler, error) {
+func gatewayHPASpec(api spec.API, prevHPA *kautoscaling.HorizontalPodAutoscaler) (kautoscaling.HorizontalPodAutoscaler, error) {
 	var maxReplicas int32 = 1
 	if api.Autoscaling != nil {
 		maxReplicas = api.Autoscaling.MaxReplicas
 	}
+
+	// keep the minimum replicas which were set by the gateway autoscaler, so that updating the api doesn't scale the gateway down
+	var minReplicas int32 = 1
+	if prevHPA != nil && prevHPA.Spec.MinReplicas != nil && api.Gateway != nil {
+		minReplicas = *prevHPA.Spec.MinReplicas
+		if minReplicas > maxReplicas {
+			minReplicas = maxReplicas
+		}
+	}
+
 	hpa, err := k8s.HPA(&k8s.HPASpec{
 		DeploymentName:       getGatewayK8sName(api.Name),
-		MinReplicas:          1,
+		MinReplicas:          minReplicas,
 		MaxReplicas:          maxReplicas,
 		TargetCPUUtilization: _gatewayHPATargetCPUUtilization,
 		TargetMemUtilization: _gatewayHPATargetMemUtilization,
@@ -158,7 +171,7 @@ func gatewayVirtualServiceSpec(api spec.API) v1beta1.VirtualService {
 		PrefixPath:  api.Networking.Endpoint,
 		Rewrite:     pointer.String("/"),
 		Annotations: api.ToK8sAnnotations(),
-		Labels: map[string]string{
+		Labels: workloads.WithUserLabels(api.API, map[string]string{
 			"apiName":          api.Name,
 			"apiKind":          api.Kind.String(),
 			"apiID":            api.ID,
@@ -167,7 +180,7 @@ func gatewayVirtualServiceSpec(api spec.API) v1beta1.VirtualService {
 			"podID":            api.PodID,
 			"cortex.dev/api":   "true",
 			"cortex.dev/async": "gateway",
-		},
+		}),
 	})
 }
 
@@ -203,12 +216,15 @@ func deploymentSpec(api spec.API, prevDeployment *kapps.Deployment, queueURL str
 
 	containers, volumes = workloads.AsyncContainers(api, queueURL)
 
+	annotations := api.ToK8sAnnotations()
+	workloads.ApplyVerticalAutoscaling(api.API, containers, prevDeployment, annotations)
+
 	return *k8s.Deployment(&k8s.DeploymentSpec{
 		Name:           workloads.K8sName(api.Name),
 		Replicas:       getRequestedReplicasFromDeployment(api, prevDeployment),
 		MaxSurge:       pointer.String(api.UpdateStrategy.MaxSurge),
 		MaxUnavailable: pointer.String(api.UpdateStrategy.MaxUnavailable),
-		Labels: map[string]string{
+		Labels: workloads.WithUserLabels(api.API, map[string]string{
 			"apiName":          api.Name,
 			"apiKind":          api.Kind.String(),
 			"apiID":            api.ID,
@@ -217,36 +233,63 @@ func deploymentSpec(api spec.API, prevDeployment *kapps.Deployment, queueURL str
 			"podID":            api.PodID,
 			"cortex.dev/api":   "true",
 			"cortex.dev/async": "api",
-		},
-		Annotations: api.ToK8sAnnotations(),
+		}),
+		Annotations: annotations,
 		Selector: map[string]string{
 			"apiName":          api.Name,
 			"apiKind":          api.Kind.String(),
 			"cortex.dev/async": "api",
 		},
 		PodSpec: k8s.PodSpec{
-			Labels: map[string]string{
+			Labels: workloads.WithUserLabels(api.API, map[string]string{
 				"apiName":          api.Name,
 				"apiKind":          api.Kind.String(),
 				"deploymentID":     api.DeploymentID,
 				"podID":            api.PodID,
 				"cortex.dev/api":   "true",
 				"cortex.dev/async": "api",
-			},
+			}),
+			Annotations: workloads.APIPodAnnotations(),
 			K8sPodSpec: kcore.PodSpec{
 				RestartPolicy:                 "Always",
 				TerminationGracePeriodSeconds: pointer.Int64(_terminationGracePeriodSeconds),
+				InitContainers:                workloads.UserPodInitContainers(api),
 				Containers:                    containers,
 				NodeSelector:                  workloads.NodeSelectors(),
 				Tolerations:                   workloads.GenerateResourceTolerations(),
-				Affinity:                      workloads.GenerateNodeAffinities(api.NodeGroups),
-				Volumes:                       volumes,
-				ServiceAccountName:            workloads.ServiceAccountName,
+				Affinity:                      workloads.GenerateNodeAffinities(api.NodeGroups, api.Arch, aws.OSLinux),
+				TopologySpreadConstraints: workloads.GenerateTopologySpreadConstraints(api.Availability, map[string]string{
+					"apiName":          api.Name,
+					"apiKind":          api.Kind.String(),
+					"cortex.dev/async": "api",
+				}),
+				Volumes:            volumes,
+				ServiceAccountName: workloads.APIServiceAccountName(api.API),
+				ImagePullSecrets:   workloads.ImagePullSecrets(api.API),
 			},
 		},
 	})
 }
 
+func pdbSpec(api spec.API) kpolicy.PodDisruptionBudget {
+	return *k8s.PDB(&k8s.PDBSpec{
+		Name:           workloads.K8sName(api.Name),
+		MaxUnavailable: *api.Availability.MaxDisruptedReplicas,
+		Annotations:    api.ToK8sAnnotations(),
+		Labels: map[string]string{
+			"apiName":          api.Name,
+			"apiKind":          api.Kind.String(),
+			"cortex.dev/api":   "true",
+			"cortex.dev/async": "api",
+		},
+		Selector: map[string]string{
+			"apiName":          api.Name,
+			"apiKind":          api.Kind.String(),
+			"cortex.dev/async": "api",
+		},
+	})
+}
+
 func getRequestedReplicasFromDeployment(api spec.API, deployment *kapps.Deployment) int32 {
 	requestedReplicas := api.Autoscaling.InitReplicas
 
@@ -0,0 +1,48 @@
+/*
+Copyright 2021 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package asyncapi
+
+import (
+	"path"
+
+	"github.com/cortexlabs/cortex/pkg/config"
+	"github.com/cortexlabs/cortex/pkg/lib/aws"
+	"github.com/cortexlabs/cortex/pkg/types/async"
+)
+
+// CancelWorkload marks a workload as cancelled; the dequeuer skips cancelled workloads which are still in the queue,
+// and aborts the request to the api's containers if the workload is being processed
+func CancelWorkload(apiName string, requestID string) error {
+	storagePath := async.StoragePath(config.ClusterConfig.ClusterUID, apiName)
+
+	statusObjects, err := config.AWS.ListS3Prefix(config.ClusterConfig.Bucket, async.StatusPrefixPath(storagePath, requestID)+"/", false, nil, nil)
+	if err != nil {
+		return err
+	}
+	if len(statusObjects) == 0 {
+		return ErrorWorkloadNotFound(apiName, requestID)
+	}
+
+	for _, key := range aws.ConvertS3ObjectsToKeys(statusObjects...) {
+		status := async.Status(path.Base(key))
+		if status.Finished() {
+			return ErrorWorkloadFinished(requestID, status)
+		}
+	}
+
+	return config.AWS.UploadStringToS3("", config.ClusterConfig.Bucket, async.StatusPath(storagePath, requestID, async.StatusCancelled))
+}
@@ -0,0 +1,236 @@
+/*
+Copyright 2021 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/cortexlabs/cortex/pkg/config"
+	"github.com/cortexlabs/cortex/pkg/lib/aws"
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	libmath "github.com/cortexlabs/cortex/pkg/lib/math"
+	"github.com/cortexlabs/cortex/pkg/lib/sets/strset"
+	"github.com/cortexlabs/cortex/pkg/lib/slices"
+	s "github.com/cortexlabs/cortex/pkg/lib/strings"
+	"github.com/cortexlabs/cortex/pkg/operator/operator"
+	"github.com/cortexlabs/cortex/pkg/operator/schema"
+	"github.com/cortexlabs/cortex/pkg/types/userconfig"
+	istioclientnetworking "istio.io/client-go/pkg/apis/networking/v1beta1"
+)
+
+// GetBudgetUsage returns the projected hourly cost of the cluster and of each deployed realtime and async api, or nil if no budget is configured
+func GetBudgetUsage() (*schema.BudgetUsage, error) {
+	if config.ClusterConfig.Budget == nil {
+		return nil, nil
+	}
+
+	virtualServices, err := config.K8s.ListVirtualServices(nil)
+	if err != nil {
+		return nil, err
+	}
+
+	apiCosts, err := getDeployedAPICosts(virtualServices, strset.New())
+	if err != nil {
+		return nil, err
+	}
+
+	clusterCost := operator.ClusterFixedPrice()
+	for _, apiCost := range apiCosts {
+		clusterCost += apiCost
+	}
+
+	return &schema.BudgetUsage{
+		Budget:             *config.ClusterConfig.Budget,
+		ClusterCostPerHour: clusterCost,
+		APICostsPerHour:    apiCosts,
+	}, nil
+}
+
+// getDeployedAPICosts returns the projected hourly cost of each deployed realtime and async api (by api name), ignoring the apis in excludedAPIs (e.g. because they are being redeployed)
+func getDeployedAPICosts(virtualServices []istioclientnetworking.VirtualService, excludedAPIs strset.Set) (map[string]float64, error) {
+	apiCosts := map[string]float64{}
+	for _, virtualService := range virtualServices {
+		apiKind := virtualService.Labels["apiKind"]
+		if apiKind != userconfig.RealtimeAPIKind.String() && apiKind != userconfig.AsyncAPIKind.String() {
+			continue
+		}
+
+		apiName := virtualService.Labels["apiName"]
+		if excludedAPIs.Has(apiName) {
+			continue
+		}
+
+		api, err := operator.DownloadAPISpec(apiName, virtualService.Labels["apiID"])
+		if err != nil {
+			return nil, err
+		}
+		apiCosts[apiName] = apiCostPerHour(api.API)
+	}
+	return apiCosts, nil
+}
+
+// apiCostPerHour returns the hourly cost of the api at its max replicas (including its warm replicas)
+func apiCostPerHour(api *userconfig.API) float64 {
+	return replicaCostPerHour(api) * float64(api.Autoscaling.MaxReplicas+api.Autoscaling.WarmReplicas)
+}
+
+// replicaCostPerHour returns the share of the instance price which one replica of the api requests,
+// on the most expensive of the api's node groups which the replica can be scheduled on
+func replicaCostPerHour(api *userconfig.API) float64 {
+	compute := userconfig.GetTotalComputeFromPod(api.Pod)
+
+	var cost float64
+	for _, ng := range config.ClusterConfig.NodeGroups {
+		if api.NodeGroups != nil && !slices.HasString(api.NodeGroups, ng.Name) {
+			continue
+		}
+		if ng.Arch() != api.Arch {
+			continue
+		}
+		if compute.GPU > 0 && ng.SharesGPUs() != api.Pod.RequestsFractionalGPUs() {
+			continue
+		}
+		instanceMetadata, ok := aws.InstanceMetadatas[config.ClusterConfig.Region][ng.InstanceType]
+		if !ok {
+			continue
+		}
+
+		share := instanceShare(compute, instanceMetadata)
+		if share > 1 {
+			continue
+		}
+		cost = libmath.MaxFloat64(cost, share*operator.NodeGroupInstancePrice(ng))
+	}
+	return cost
+}
+
+// instanceShare returns the largest fraction of the instance's capacity which is requested by the compute (greater than 1 if the compute doesn't fit on the instance)
+func instanceShare(compute userconfig.Compute, instanceMetadata aws.InstanceMetadata) float64 {
+	var share float64
+	if compute.CPU != nil && instanceMetadata.CPU.MilliValue() > 0 {
+		share = libmath.MaxFloat64(share, float64(compute.CPU.MilliValue())/float64(instanceMetadata.CPU.MilliValue()))
+	}
+	if compute.Mem != nil && instanceMetadata.Memory.Value() > 0 {
+		share = libmath.MaxFloat64(share, float64(compute.Mem.Value())/float64(instanceMetadata.Memory.Value()))
+	}
+	if compute.GPU > 0 {
+		if instanceMetadata.GPU == 0 {
+			return math.Inf(1)
+		}
+		share = libmath.MaxFloat64(share, compute.GPU/float64(instanceMetadata.GPU))
+	}
+	if compute.Inf > 0 {
+		if instanceMetadata.Inf == 0 {
+			return math.Inf(1)
+		}
+		share = libmath.MaxFloat64(share, float64(compute.Inf)/float64(instanceMetadata.Inf))
+	}
+	return share
+}
+
+// checkBudgets returns an error if deploying the realtime and async apis would exceed the budget, and otherwise returns the warnings (by api name) for the apis whose deployment reaches one of the budget's warning thresholds
+func checkBudgets(apis []userconfig.API, virtualServices []istioclientnetworking.VirtualService) (map[string][]string, error) {
+	budget := config.ClusterConfig.Budget
+	if budget == nil {
+		return nil, nil
+	}
+
+	apiNames := strset.New()
+	var budgetAPIs []*userconfig.API
+	for i := range apis {
+		api := &apis[i]
+		apiNames.Add(api.Name)
+		if api.Kind == userconfig.RealtimeAPIKind || api.Kind == userconfig.AsyncAPIKind {
+			budgetAPIs = append(budgetAPIs, api)
+		}
+	}
+	if len(budgetAPIs) == 0 {
+		return nil, nil
+	}
+
+	apiCosts, err := getDeployedAPICosts(virtualServices, apiNames)
+	if err != nil {
+		return nil, err
+	}
+
+	clusterCost := operator.ClusterFixedPrice()
+	for _, apiCost := range apiCosts {
+		clusterCost += apiCost
+	}
+
+	warnings := map[string][]string{}
+	for _, api := range budgetAPIs {
+		apiCost := apiCostPerHour(api)
+		clusterCost += apiCost
+
+		if budget.MaxAPICostPerHour != nil {
+			if apiCost > *budget.MaxAPICostPerHour {
+				return nil, errors.Wrap(ErrorAPIBudgetExceeded(apiCost, *budget.MaxAPICostPerHour), api.Identify())
+			}
+			if threshold, ok := reachedBudgetWarningThreshold(apiCost, *budget.MaxAPICostPerHour); ok {
+				warnings[api.Name] = append(warnings[api.Name], fmt.Sprintf("%s would cost up to %s per hour at its max replicas, which is %s of the budget of %s per hour for each api", api.Name, s.DollarsAndTenthsOfCents(apiCost), budgetPercent(threshold), s.DollarsAndCents(*budget.MaxAPICostPerHour)))
+			}
+		}
+
+		if budget.MaxClusterCostPerHour != nil {
+			if clusterCost > *budget.MaxClusterCostPerHour {
+				return nil, errors.Wrap(ErrorClusterBudgetExceeded(clusterCost, *budget.MaxClusterCostPerHour), api.Identify())
+			}
+			if threshold, ok := reachedBudgetWarningThreshold(clusterCost, *budget.MaxClusterCostPerHour); ok {
+				warnings[api.Name] = append(warnings[api.Name], fmt.Sprintf("the cluster's projected cost is %s per hour after deploying %s, which is %s of the cluster's budget of %s per hour", s.DollarsAndCents(clusterCost), api.Name, budgetPercent(threshold), s.DollarsAndCents(*budget.MaxClusterCostPerHour)))
+			}
+		}
+	}
+
+	return warnings, nil
+}
+
+// validateBudgets returns an error if deploying the realtime and async apis would exceed the budget
+func validateBudgets(apis []userconfig.API, virtualServices []istioclientnetworking.VirtualService) error {
+	_, err := checkBudgets(apis, virtualServices)
+	return err
+}
+
+// getBudgetWarnings returns the warnings (by api name) for the realtime and async apis whose deployment reaches one of the budget's warning thresholds
+func getBudgetWarnings(apis []userconfig.API) (map[string][]string, error) {
+	if config.ClusterConfig.Budget == nil {
+		return nil, nil
+	}
+
+	virtualServices, err := config.K8s.ListVirtualServices(nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return checkBudgets(apis, virtualServices)
+}
+
+// reachedBudgetWarningThreshold returns the highest warning threshold which the cost reaches, if any
+func reachedBudgetWarningThreshold(cost float64, budget float64) (float64, bool) {
+	var reachedThreshold float64
+	for _, threshold := range config.ClusterConfig.Budget.WarningThresholds {
+		if cost >= threshold*budget && threshold > reachedThreshold {
+			reachedThreshold = threshold
+		}
+	}
+	return reachedThreshold, reachedThreshold > 0
+}
+
+func budgetPercent(threshold float64) string {
+	return "at least " + s.Round(threshold*100, 0, 0) + "%"
+}
@@ -18,11 +18,13 @@ package resources
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/cortexlabs/cortex/pkg/lib/errors"
 	"github.com/cortexlabs/cortex/pkg/lib/strings"
 	s "github.com/cortexlabs/cortex/pkg/lib/strings"
 	"github.com/cortexlabs/cortex/pkg/operator/operator"
+	"github.com/cortexlabs/cortex/pkg/types/clusterconfig"
 	"github.com/cortexlabs/cortex/pkg/types/userconfig"
 )
 
@@ -36,6 +38,26 @@ const (
 	ErrRealtimeAPIUsedByTrafficSplitter = "resources.realtime_api_used_by_traffic_splitter"
 	ErrAPIsNotDeployed                  = "resources.apis_not_deployed"
 	ErrInvalidNodeGroupSelector         = "resources.invalid_node_group_selector"
+	ErrEFSNotConfigured                 = "resources.efs_not_configured"
+	ErrAlertingNotConfigured            = "resources.alerting_not_configured"
+	ErrInvalidAlertReceiver             = "resources.invalid_alert_receiver"
+	ErrDependenciesNotDeployed          = "resources.dependencies_not_deployed"
+	ErrProjectDeployFailed              = "resources.project_deploy_failed"
+	ErrPreDeployHooksTimeoutTooLong     = "resources.pre_deploy_hooks_timeout_too_long"
+	ErrTaskAPIsNotDeployed              = "resources.task_apis_not_deployed"
+	ErrAPIUsedByWorkflow                = "resources.api_used_by_workflow"
+	ErrQuotaExceeded                    = "resources.quota_exceeded"
+	ErrAPIBudgetExceeded                = "resources.api_budget_exceeded"
+	ErrClusterBudgetExceeded            = "resources.cluster_budget_exceeded"
+	ErrNoNodeGroupsWithArch             = "resources.no_node_groups_with_arch"
+	ErrARMNotSupportedWithMTLS          = "resources.arm_not_supported_with_mtls"
+	ErrNoWindowsNodeGroups              = "resources.no_windows_node_groups"
+	ErrWindowsNotSupportedWithMTLS      = "resources.windows_not_supported_with_mtls"
+	ErrMixedNeuronNodeGroups            = "resources.mixed_neuron_node_groups"
+	ErrNoNodeGroupsForGPURequest        = "resources.no_node_groups_for_gpu_request"
+	ErrMixedGPUSharingFactors           = "resources.mixed_gpu_sharing_factors"
+	ErrGPUNotMultipleOfSharingFactor    = "resources.gpu_not_multiple_of_sharing_factor"
+	ErrCIDRWhiteListNotSupportedWithALB = "resources.cidr_white_list_not_supported_with_alb"
 )
 
 func ErrorOperationIsOnlySupportedForKind(resource operator.DeployedResource, supportedKind userconfig.Kind, supportedKinds ...userconfig.Kind) error {
@@ -102,9 +124,182 @@ func ErrorAPIsNotDeployed(notDeployedAPIs []string) error {
 	})
 }
 
+func ErrorTaskAPIsNotDeployed(notDeployedAPIs []string) error {
+	message := fmt.Sprintf("apis %s were either not found or are not TaskAPIs", s.StrsAnd(notDeployedAPIs))
+	if len(notDeployedAPIs) == 1 {
+		message = fmt.Sprintf("api %s was either not found or is not a TaskAPI", notDeployedAPIs[0])
+	}
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrTaskAPIsNotDeployed,
+		Message: message,
+	})
+}
+
+func ErrorAPIUsedByWorkflow(workflows []string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrAPIUsedByWorkflow,
+		Message: fmt.Sprintf("cannot delete api because it is used by the following %s: %s", s.PluralS("Workflow", len(workflows)), s.StrsSentence(workflows, "")),
+	})
+}
+
 func ErrorInvalidNodeGroupSelector(selected string, availableNodeGroups []string) error {
 	return errors.WithStack(&errors.Error{
 		Kind:    ErrInvalidNodeGroupSelector,
 		Message: fmt.Sprintf("node group %s doesn't exist; remove the node group selector to let Cortex determine automatically where to place the API or specify a valid node group name (%s)", selected, s.StrsOr(availableNodeGroups)),
 	})
 }
+
+func ErrorEFSNotConfigured() error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrEFSNotConfigured,
+		Message: "this cluster was not created with an efs file system; to use efs, add the `efs` field to your cluster configuration file and create a new cluster",
+	})
+}
+
+func ErrorAlertingNotConfigured() error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrAlertingNotConfigured,
+		Message: "this cluster was not created with alerting enabled; to route alerts to a receiver, add the `alerting` field to your cluster configuration file and create a new cluster",
+	})
+}
+
+func ErrorInvalidAlertReceiver(receiver string, availableReceivers []string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrInvalidAlertReceiver,
+		Message: fmt.Sprintf("alert receiver %s doesn't exist; specify one of the receivers defined in the cluster configuration (%s)", receiver, s.StrsOr(availableReceivers)),
+	})
+}
+
+func ErrorDependenciesNotDeployed(missingAPIs []string) error {
+	message := fmt.Sprintf("apis %s are not deployed and are not defined in the same configuration file", s.StrsAnd(missingAPIs))
+	if len(missingAPIs) == 1 {
+		message = fmt.Sprintf("api %s is not deployed and is not defined in the same configuration file", missingAPIs[0])
+	}
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrDependenciesNotDeployed,
+		Message: message,
+	})
+}
+
+func ErrorProjectDeployFailed(failedAPIName string, rolledBack bool) error {
+	message := fmt.Sprintf("not deployed because %s failed to deploy", failedAPIName)
+	if rolledBack {
+		message = fmt.Sprintf("rolled back because %s failed to deploy", failedAPIName)
+	}
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrProjectDeployFailed,
+		Message: message,
+	})
+}
+
+func ErrorPreDeployHooksTimeoutTooLong(apiNames []string, total time.Duration, max time.Duration) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrPreDeployHooksTimeoutTooLong,
+		Message: fmt.Sprintf("the total timeout of the pre-deploy hooks of %s is %s, which exceeds the limit of %s per deployment; reduce the hooks' timeouts, or deploy the apis separately", s.StrsAnd(apiNames), total.String(), max.String()),
+	})
+}
+
+func ErrorQuotaExceeded(team string, resource string, requested string, quota string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrQuotaExceeded,
+		Message: fmt.Sprintf("deploying this api would exceed the %s quota of the %s team: the team's apis would request %s %s at their max replicas, but the quota is %s (run `cortex cluster info` to view the usage of each team)", resource, team, requested, resource, quota),
+	})
+}
+
+func ErrorAPIBudgetExceeded(cost float64, budget float64) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrAPIBudgetExceeded,
+		Message: fmt.Sprintf("this api would cost up to %s per hour at its max replicas, which exceeds the budget of %s per hour for each api (reduce max_replicas or the api's compute request, or increase budget.max_api_cost_per_hour in the cluster configuration)", s.DollarsAndTenthsOfCents(cost), s.DollarsAndCents(budget)),
+	})
+}
+
+func ErrorClusterBudgetExceeded(cost float64, budget float64) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrClusterBudgetExceeded,
+		Message: fmt.Sprintf("deploying this api would increase the cluster's projected cost to %s per hour, which exceeds the cluster's budget of %s per hour (run `cortex cluster info` to view the projected cost of each api)", s.DollarsAndCents(cost), s.DollarsAndCents(budget)),
+	})
+}
+
+func ErrorNoNodeGroupsWithArch(arch string, selectedNodeGroups []string) error {
+	message := fmt.Sprintf("there are no node groups with %s instances in this cluster; add a node group with %s instances to your cluster configuration, or set `%s` to the architecture of one of the existing node groups", arch, arch, userconfig.ArchKey)
+	if selectedNodeGroups != nil {
+		message = fmt.Sprintf("none of the selected node groups (%s) have %s instances; select a node group with %s instances, or set `%s` to the architecture of the selected node groups", s.StrsAnd(selectedNodeGroups), arch, arch, userconfig.ArchKey)
+	}
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrNoNodeGroupsWithArch,
+		Message: message,
+	})
+}
+
+func ErrorARMNotSupportedWithMTLS() error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrARMNotSupportedWithMTLS,
+		Message: "arm64 apis are not supported on clusters with mutual TLS enabled, since the istio sidecar which terminates mutual TLS is only available for amd64",
+	})
+}
+
+// the application load balancer terminates the clients' connections, so the istio gateway only sees the load balancer's addresses
+func ErrorCIDRWhiteListNotSupportedWithALB() error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrCIDRWhiteListNotSupportedWithALB,
+		Message: fmt.Sprintf("per-api cidr white lists are not supported on clusters with `%s: %s`; use the cluster's %s or %s instead", clusterconfig.APILoadBalancerTypeKey, clusterconfig.ALBLoadBalancerType.String(), clusterconfig.APILoadBalancerWAFWebACLARNKey, clusterconfig.APILoadBalancerCIDRWhiteListKey),
+	})
+}
+
+func ErrorNoWindowsNodeGroups(selectedNodeGroups []string) error {
+	message := fmt.Sprintf("there are no windows node groups in this cluster; add a node group with `%s: %s` to your cluster configuration", clusterconfig.AMIFamilyKey, clusterconfig.AMIFamilyWindows)
+	if selectedNodeGroups != nil {
+		message = fmt.Sprintf("none of the selected node groups (%s) are windows node groups; select a node group with `%s: %s`", s.StrsAnd(selectedNodeGroups), clusterconfig.AMIFamilyKey, clusterconfig.AMIFamilyWindows)
+	}
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrNoWindowsNodeGroups,
+		Message: message,
+	})
+}
+
+func ErrorWindowsNotSupportedWithMTLS() error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrWindowsNotSupportedWithMTLS,
+		Message: "windows apis are not supported on clusters with mutual TLS enabled, since the istio sidecar which terminates mutual TLS is only available for linux",
+	})
+}
+
+func ErrorMixedNeuronNodeGroups(inf1NodeGroups []string, neuronV2NodeGroups []string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrMixedNeuronNodeGroups,
+		Message: fmt.Sprintf("apis which request Inferentia/Trainium chips can't run on both inf1 node groups (%s) and inf2/trn1 node groups (%s); use the `%s` field to select node groups of only one of these generations", s.StrsAnd(inf1NodeGroups), s.StrsAnd(neuronV2NodeGroups), userconfig.NodeGroupsKey),
+	})
+}
+
+func ErrorNoNodeGroupsForGPURequest(fractionalGPUs bool, selectedNodeGroups []string) error {
+	var message string
+	if fractionalGPUs {
+		message = fmt.Sprintf("apis which request a fraction of a GPU can only run on node groups which share their GPUs, but there are no such node groups in this cluster; set `%s` for a GPU node group in your cluster configuration", clusterconfig.GPUSharingFactorKey)
+		if selectedNodeGroups != nil {
+			message = fmt.Sprintf("apis which request a fraction of a GPU can only run on node groups which share their GPUs, but none of the selected node groups (%s) have `%s` set", s.StrsAnd(selectedNodeGroups), clusterconfig.GPUSharingFactorKey)
+		}
+	} else {
+		message = fmt.Sprintf("apis which request whole GPUs can't run on node groups which share their GPUs, and all of the GPU node groups in this cluster have `%s` set", clusterconfig.GPUSharingFactorKey)
+		if selectedNodeGroups != nil {
+			message = fmt.Sprintf("apis which request whole GPUs can't run on node groups which share their GPUs, and all of the selected node groups (%s) have `%s` set", s.StrsAnd(selectedNodeGroups), clusterconfig.GPUSharingFactorKey)
+		}
+	}
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrNoNodeGroupsForGPURequest,
+		Message: message,
+	})
+}
+
+func ErrorMixedGPUSharingFactors(nodeGroup1 string, sharingFactor1 int64, nodeGroup2 string, sharingFactor2 int64) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrMixedGPUSharingFactors,
+		Message: fmt.Sprintf("apis which request a fraction of a GPU can't run on node groups with different values for `%s` (%s has %d, but %s has %d); use the `%s` field to select node groups with the same value", clusterconfig.GPUSharingFactorKey, nodeGroup1, sharingFactor1, nodeGroup2, sharingFactor2, userconfig.NodeGroupsKey),
+	})
+}
+
+func ErrorGPUNotMultipleOfSharingFactor(gpu float64, sharingFactor int64) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrGPUNotMultipleOfSharingFactor,
+		Message: fmt.Sprintf("%s GPU can't be requested from node groups which share each GPU between %d replicas; the requested GPU must be a multiple of 1/%d", s.Round(gpu, 4, 0), sharingFactor, sharingFactor),
+	})
+}
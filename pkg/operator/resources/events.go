@@ -0,0 +1,139 @@
+/*
+Copyright 2021 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/cortexlabs/cortex/pkg/config"
+	"github.com/cortexlabs/cortex/pkg/lib/k8s"
+	"github.com/cortexlabs/cortex/pkg/lib/sets/strset"
+	"github.com/cortexlabs/cortex/pkg/operator/schema"
+	"github.com/cortexlabs/cortex/pkg/workloads"
+	kcore "k8s.io/api/core/v1"
+)
+
+// kubernetes only retains events for an hour by default, so the timeline is usually short; this limits it when an api is flapping
+const _maxAPIEvents = 50
+
+// the suffixes which kubernetes generates for the names of replica sets and pods (vowels are excluded from these suffixes so that they don't spell words)
+var _generatedNameSuffixRegex = regexp.MustCompile(`^(-[bcdfghjklmnpqrstvwxz2456789]{5,10}){1,2}$`)
+
+// getAPIEvents returns the events of the api's kubernetes resources and the terminations of its containers, in chronological order
+func getAPIEvents(apiName string, pods []kcore.Pod) ([]schema.APIEvent, error) {
+	k8sEvents, err := config.K8s.ListEvents(nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// the names of the api's resources; the pods and replica sets of these resources have generated suffixes
+	ownerNames := strset.New(workloads.K8sName(apiName), "gateway-"+apiName)
+	podNames := strset.New()
+	for _, pod := range pods {
+		podNames.Add(pod.Name)
+		for _, ownerRef := range pod.OwnerReferences {
+			ownerNames.Add(ownerRef.Name)
+		}
+	}
+
+	var events []schema.APIEvent
+	for i := range k8sEvents {
+		k8sEvent := &k8sEvents[i]
+		if !podNames.Has(k8sEvent.InvolvedObject.Name) && !isOwnedByAny(k8sEvent.InvolvedObject.Name, ownerNames) {
+			continue
+		}
+
+		count := k8sEvent.Count
+		if count == 0 {
+			count = 1
+		}
+
+		events = append(events, schema.APIEvent{
+			Time:    k8s.EventTime(k8sEvent),
+			Type:    k8sEvent.Type,
+			Reason:  k8sEvent.Reason,
+			Object:  strings.ToLower(k8sEvent.InvolvedObject.Kind) + "/" + k8sEvent.InvolvedObject.Name,
+			Message: strings.TrimSpace(k8sEvent.Message),
+			Count:   count,
+		})
+	}
+
+	// kubernetes doesn't record events when containers are terminated, so they are read from the pods' container statuses
+	for _, pod := range pods {
+		for _, containerStatus := range pod.Status.ContainerStatuses {
+			for _, terminated := range []*kcore.ContainerStateTerminated{containerStatus.State.Terminated, containerStatus.LastTerminationState.Terminated} {
+				if event := terminationEvent(pod.Name, containerStatus.Name, terminated); event != nil {
+					events = append(events, *event)
+				}
+			}
+		}
+	}
+
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].Time.Before(events[j].Time)
+	})
+	if len(events) > _maxAPIEvents {
+		events = events[len(events)-_maxAPIEvents:]
+	}
+
+	return events, nil
+}
+
+func isOwnedByAny(objectName string, ownerNames strset.Set) bool {
+	if ownerNames.Has(objectName) {
+		return true
+	}
+	for ownerName := range ownerNames {
+		if strings.HasPrefix(objectName, ownerName) && _generatedNameSuffixRegex.MatchString(objectName[len(ownerName):]) {
+			return true
+		}
+	}
+	return false
+}
+
+// returns nil if the container wasn't terminated, or if it exited successfully
+func terminationEvent(podName string, containerName string, terminated *kcore.ContainerStateTerminated) *schema.APIEvent {
+	if terminated == nil || terminated.ExitCode == 0 {
+		return nil
+	}
+
+	event := schema.APIEvent{
+		Time:   terminated.FinishedAt.Time,
+		Type:   kcore.EventTypeWarning,
+		Reason: "Terminated",
+		Object: "pod/" + podName,
+		Count:  1,
+	}
+
+	if terminated.Reason == k8s.ReasonOOMKilled {
+		event.Reason = k8s.ReasonOOMKilled
+		event.Message = fmt.Sprintf("container %s was killed because it ran out of memory", containerName)
+		return &event
+	}
+
+	event.Message = fmt.Sprintf("container %s exited with code %d", containerName, terminated.ExitCode)
+	if terminated.Reason != "" && terminated.Reason != "Error" {
+		event.Message += fmt.Sprintf(" (%s)", terminated.Reason)
+	}
+	if message := strings.TrimSpace(terminated.Message); message != "" {
+		event.Message += ": " + message
+	}
+	return &event
+}
@@ -50,6 +50,10 @@ func UpdateAPI(apiConfig *userconfig.API) (*spec.API, string, error) {
 	api := spec.GetAPISpec(apiConfig, "", config.ClusterConfig.ClusterUID) // Deployment ID not needed for BatchAPI spec
 
 	if prevVirtualService == nil {
+		if err := operator.ApplyAPIPodResources(apiConfig); err != nil {
+			return nil, "", err
+		}
+
 		if err := config.AWS.UploadJSONToS3(api, config.ClusterConfig.Bucket, api.Key); err != nil {
 			return nil, "", errors.Wrap(err, "upload api spec")
 		}
@@ -66,6 +70,10 @@ func UpdateAPI(apiConfig *userconfig.API) (*spec.API, string, error) {
 	}
 
 	if prevVirtualService.Labels["specID"] != api.SpecID {
+		if err := operator.ApplyAPIPodResources(apiConfig); err != nil {
+			return nil, "", err
+		}
+
 		if err := config.AWS.UploadJSONToS3(api, config.ClusterConfig.Bucket, api.Key); err != nil {
 			return nil, "", errors.Wrap(err, "upload api spec")
 		}
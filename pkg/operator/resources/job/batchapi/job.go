@@ -25,6 +25,7 @@ import (
 	"github.com/cortexlabs/cortex/pkg/lib/errors"
 	"github.com/cortexlabs/cortex/pkg/lib/pointer"
 	"github.com/cortexlabs/cortex/pkg/operator/operator"
+	"github.com/cortexlabs/cortex/pkg/operator/resources/job"
 	"github.com/cortexlabs/cortex/pkg/operator/schema"
 	"github.com/cortexlabs/cortex/pkg/types/spec"
 	"github.com/cortexlabs/cortex/pkg/types/userconfig"
@@ -97,9 +98,8 @@ func SubmitJob(apiName string, submission *schema.BatchJobSubmission) (*spec.Bat
 		return nil, err
 	}
 
-	// upload job payload for enqueuer
-	payloadKey := spec.JobPayloadKey(config.ClusterConfig.ClusterUID, userconfig.BatchAPIKind, apiName, jobID)
-	if err = config.AWS.UploadJSONToS3(submission, config.ClusterConfig.Bucket, payloadKey); err != nil {
+	// upload job payload for enqueuer (it is kept so that the job can be retried)
+	if err = job.UploadSubmission(jobSpec.JobKey, submission); err != nil {
 		return nil, err
 	}
 
@@ -146,6 +146,7 @@ func SubmitJob(apiName string, submission *schema.BatchJobSubmission) (*spec.Bat
 			DeadLetterQueue: deadLetterQueue,
 			TTL:             &kmeta.Duration{Duration: _batchJobTTL},
 			NodeGroups:      apiSpec.NodeGroups,
+			Arch:            apiSpec.Arch,
 			Probes:          workloads.GetReadinessProbesFromContainers(apiSpec.Pod.Containers),
 		},
 	}
@@ -164,6 +165,20 @@ func StopJob(jobKey spec.JobKey) error {
 	})
 }
 
+func RetryJob(jobKey spec.JobKey) (*spec.BatchJob, error) {
+	jobStatus, err := GetJobStatus(jobKey)
+	if err != nil {
+		return nil, err
+	}
+
+	submission := schema.BatchJobSubmission{}
+	if err := job.ReadSubmissionForRetry(jobKey, jobStatus.Status, &submission); err != nil {
+		return nil, err
+	}
+
+	return SubmitJob(jobKey.APIName, &submission)
+}
+
 func uploadJobSpec(jobSpec *spec.BatchJob) error {
 	err := config.AWS.UploadJSONToS3(jobSpec, config.ClusterConfig.Bucket, jobSpec.SpecFilePath(config.ClusterConfig.ClusterUID))
 	if err != nil {
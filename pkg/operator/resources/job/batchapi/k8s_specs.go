@@ -46,14 +46,14 @@ func virtualServiceSpec(api *spec.API) *istioclientnetworking.VirtualService {
 		PrefixPath:  api.Networking.Endpoint,
 		Rewrite:     pointer.String(path.Join("batch", api.Name)),
 		Annotations: api.ToK8sAnnotations(),
-		Labels: map[string]string{
+		Labels: workloads.WithUserLabels(api.API, map[string]string{
 			"apiName":        api.Name,
 			"apiID":          api.ID,
 			"specID":         api.SpecID,
 			"podID":          api.PodID,
 			"apiKind":        api.Kind.String(),
 			"cortex.dev/api": "true",
-		},
+		}),
 	})
 }
 
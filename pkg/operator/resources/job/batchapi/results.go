@@ -0,0 +1,130 @@
+/*
+Copyright 2021 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package batchapi
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/cortexlabs/cortex/pkg/config"
+	"github.com/cortexlabs/cortex/pkg/lib/aws"
+	"github.com/cortexlabs/cortex/pkg/lib/pointer"
+	s "github.com/cortexlabs/cortex/pkg/lib/strings"
+	"github.com/cortexlabs/cortex/pkg/operator/resources/job"
+	"github.com/cortexlabs/cortex/pkg/types/spec"
+	"github.com/cortexlabs/cortex/pkg/types/status"
+	"github.com/cortexlabs/cortex/pkg/types/userconfig"
+)
+
+const (
+	_maxErrorSamples      = 10
+	_maxFailedBatchPaths  = 100
+	_failedBatchErrorFile = "error.json"
+	_failedBatchPayload   = "payload.json"
+)
+
+// GetJobResults aggregates the results which the workers of a completed job stored in s3
+func GetJobResults(jobKey spec.JobKey) (*status.BatchJobResults, error) {
+	jobStatus, err := GetJobStatus(jobKey)
+	if err != nil {
+		return nil, err
+	}
+
+	if !jobStatus.Status.IsCompleted() {
+		return nil, job.ErrorJobHasNotCompleted(jobKey, jobStatus.Status)
+	}
+
+	resultsPrefix := spec.JobResultsPrefix(config.ClusterConfig.ClusterUID, userconfig.BatchAPIKind, jobKey.APIName, jobKey.ID)
+	failedBatchesPrefix := s.EnsureSuffix(spec.JobFailedBatchesPrefix(resultsPrefix), "/")
+
+	results := status.BatchJobResults{
+		JobKey:            jobKey,
+		Status:            jobStatus.Status,
+		StartTime:         jobStatus.StartTime,
+		EndTime:           jobStatus.EndTime,
+		Workers:           []status.WorkerBatchResults{},
+		ErrorSamples:      []status.FailedBatch{},
+		FailedBatches:     []string{},
+		FailedBatchesPath: aws.S3Path(config.ClusterConfig.Bucket, failedBatchesPrefix),
+	}
+
+	workers, err := getWorkerResults(resultsPrefix)
+	if err != nil {
+		return nil, err
+	}
+	results.Workers = append(results.Workers, workers...)
+
+	for _, worker := range workers {
+		results.Succeeded += worker.Succeeded
+		results.Failed += worker.Failed
+	}
+
+	// the workers of jobs which were submitted before results were recorded didn't store their results
+	if len(workers) == 0 && jobStatus.BatchMetrics != nil {
+		results.Succeeded = jobStatus.BatchMetrics.Succeeded
+		results.Failed = jobStatus.BatchMetrics.Failed
+	}
+
+	failedBatchObjects, err := config.AWS.ListS3Prefix(config.ClusterConfig.Bucket, failedBatchesPrefix, false, pointer.Int64(2*_maxFailedBatchPaths), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, object := range failedBatchObjects {
+		key := *object.Key
+		batchID := strings.Split(strings.TrimPrefix(key, failedBatchesPrefix), "/")[0]
+
+		switch {
+		case strings.HasSuffix(key, "/"+_failedBatchPayload):
+			results.FailedBatches = append(results.FailedBatches, aws.S3Path(config.ClusterConfig.Bucket, key))
+
+		case strings.HasSuffix(key, "/"+_failedBatchErrorFile) && len(results.ErrorSamples) < _maxErrorSamples:
+			var failedBatch status.FailedBatch
+			if err := config.AWS.ReadJSONFromS3(&failedBatch, config.ClusterConfig.Bucket, key); err != nil {
+				return nil, err
+			}
+			failedBatch.PayloadPath = aws.S3Path(config.ClusterConfig.Bucket, spec.JobFailedBatchPayloadKey(resultsPrefix, batchID))
+			results.ErrorSamples = append(results.ErrorSamples, failedBatch)
+		}
+	}
+
+	return &results, nil
+}
+
+func getWorkerResults(resultsPrefix string) ([]status.WorkerBatchResults, error) {
+	workersPrefix := s.EnsureSuffix(spec.JobWorkerResultsPrefix(resultsPrefix), "/")
+
+	objects, err := config.AWS.ListS3Prefix(config.ClusterConfig.Bucket, workersPrefix, false, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	workers := make([]status.WorkerBatchResults, 0, len(objects))
+	for _, object := range objects {
+		var worker status.WorkerBatchResults
+		if err := config.AWS.ReadJSONFromS3(&worker, config.ClusterConfig.Bucket, *object.Key); err != nil {
+			return nil, err
+		}
+		workers = append(workers, worker)
+	}
+
+	sort.Slice(workers, func(i, j int) bool {
+		return workers[i].Worker < workers[j].Worker
+	})
+
+	return workers, nil
+}
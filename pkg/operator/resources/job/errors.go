@@ -22,6 +22,7 @@ import (
 	"github.com/cortexlabs/cortex/pkg/lib/errors"
 	s "github.com/cortexlabs/cortex/pkg/lib/strings"
 	"github.com/cortexlabs/cortex/pkg/types/spec"
+	"github.com/cortexlabs/cortex/pkg/types/status"
 	"github.com/cortexlabs/cortex/pkg/types/userconfig"
 )
 
@@ -30,6 +31,9 @@ const (
 	ErrJobNotFound              = "job.not_found"
 	ErrJobIsNotInProgress       = "job.job_is_not_in_progress"
 	ErrJobHasAlreadyBeenStopped = "job.job_has_already_been_stopped"
+	ErrJobHasNotFailed          = "job.job_has_not_failed"
+	ErrJobHasNotCompleted       = "job.job_has_not_completed"
+	ErrJobSubmissionNotFound    = "job.submission_not_found"
 	ErrConflictingFields        = "job.conflicting_fields"
 	ErrSpecifyExactlyOneKey     = "job.specify_exactly_one_key"
 )
@@ -62,6 +66,27 @@ func ErrorJobHasAlreadyBeenStopped(kind userconfig.Kind) error {
 	})
 }
 
+func ErrorJobHasNotFailed(jobKey spec.JobKey, jobStatus status.JobCode) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrJobHasNotFailed,
+		Message: fmt.Sprintf("cannot retry %s job %s because its status is %s; only failed jobs can be retried", jobKey.Kind.String(), jobKey.UserString(), jobStatus.Message()),
+	})
+}
+
+func ErrorJobHasNotCompleted(jobKey spec.JobKey, jobStatus status.JobCode) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrJobHasNotCompleted,
+		Message: fmt.Sprintf("the results of %s job %s are not available yet because its status is %s", jobKey.Kind.String(), jobKey.UserString(), jobStatus.Message()),
+	})
+}
+
+func ErrorJobSubmissionNotFound(jobKey spec.JobKey) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrJobSubmissionNotFound,
+		Message: fmt.Sprintf("unable to retry %s job %s because its original submission was not found", jobKey.Kind.String(), jobKey.UserString()),
+	})
+}
+
 func ErrorConflictingFields(key string, keys ...string) error {
 	allKeys := append([]string{key}, keys...)
 
@@ -0,0 +1,127 @@
+/*
+Copyright 2021 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package job
+
+import (
+	"context"
+	"time"
+
+	"github.com/cortexlabs/cortex/pkg/config"
+	batch "github.com/cortexlabs/cortex/pkg/crds/apis/batch/v1alpha1"
+	"github.com/cortexlabs/cortex/pkg/types/status"
+	"github.com/cortexlabs/cortex/pkg/types/userconfig"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	ReportTimedOutJobsCronPeriod = 60 * time.Second
+
+	// the number of most recently submitted jobs of each task api which are checked for timeouts
+	_timedOutTaskJobsLookback = 20
+)
+
+var lastTimedOutJobGauge = promauto.NewGaugeVec(
+	prometheus.GaugeOpts{
+		Name: "cortex_job_last_timed_out_timestamp_seconds",
+		Help: "The time at which the most recent job of the api was terminated for exceeding its timeout",
+	}, []string{"api_name", "api_kind"},
+)
+
+// ReportTimedOutJobs exports the time at which each api's most recent job timed out (it backs the JobTimedOut alert);
+// the times are derived from the jobs' statuses on every run, so they are not lost when the operator restarts
+func ReportTimedOutJobs() error {
+	batchTimes, err := getLastTimedOutBatchJobTimes()
+	if err != nil {
+		return err
+	}
+
+	taskTimes, err := getLastTimedOutTaskJobTimes()
+	if err != nil {
+		return err
+	}
+
+	// forget about apis which have been deleted, or whose timed out jobs have been garbage collected
+	lastTimedOutJobGauge.Reset()
+	for apiName, t := range batchTimes {
+		lastTimedOutJobGauge.WithLabelValues(apiName, userconfig.BatchAPIKind.String()).Set(float64(t.Unix()))
+	}
+	for apiName, t := range taskTimes {
+		lastTimedOutJobGauge.WithLabelValues(apiName, userconfig.TaskAPIKind.String()).Set(float64(t.Unix()))
+	}
+
+	return nil
+}
+
+func getLastTimedOutBatchJobTimes() (map[string]time.Time, error) {
+	batchJobList := batch.BatchJobList{}
+	if err := config.K8s.List(context.Background(), &batchJobList, client.InNamespace(config.K8s.Namespace)); err != nil {
+		return nil, err
+	}
+
+	lastTimes := map[string]time.Time{}
+	for _, batchJob := range batchJobList.Items {
+		if batchJob.Status.Status != status.JobTimedOut {
+			continue
+		}
+
+		// kubernetes doesn't set the completion time of jobs which exceeded their deadline, and the timeout is measured from the job's submission
+		var timedOutAt time.Time
+		if batchJob.Status.EndTime != nil {
+			timedOutAt = batchJob.Status.EndTime.Time
+		} else if batchJob.Spec.Timeout != nil {
+			timedOutAt = batchJob.CreationTimestamp.Add(batchJob.Spec.Timeout.Duration)
+		} else {
+			continue
+		}
+
+		if timedOutAt.After(lastTimes[batchJob.Spec.APIName]) {
+			lastTimes[batchJob.Spec.APIName] = timedOutAt
+		}
+	}
+
+	return lastTimes, nil
+}
+
+func getLastTimedOutTaskJobTimes() (map[string]time.Time, error) {
+	virtualServices, err := config.K8s.ListVirtualServicesByLabel("apiKind", userconfig.TaskAPIKind.String())
+	if err != nil {
+		return nil, err
+	}
+
+	lastTimes := map[string]time.Time{}
+	for _, virtualService := range virtualServices {
+		apiName := virtualService.Labels["apiName"]
+
+		jobStates, err := GetMostRecentlySubmittedJobStates(apiName, _timedOutTaskJobsLookback, userconfig.TaskAPIKind)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, jobState := range jobStates {
+			if jobState.Status != status.JobTimedOut || jobState.EndTime == nil {
+				continue
+			}
+			if jobState.EndTime.After(lastTimes[apiName]) {
+				lastTimes[apiName] = *jobState.EndTime
+			}
+		}
+	}
+
+	return lastTimes, nil
+}
@@ -18,13 +18,13 @@ package job
 
 import (
 	"path"
-	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/cortexlabs/cortex/pkg/config"
 	"github.com/cortexlabs/cortex/pkg/lib/errors"
 	"github.com/cortexlabs/cortex/pkg/lib/pointer"
-	"github.com/cortexlabs/cortex/pkg/lib/strings"
+	s "github.com/cortexlabs/cortex/pkg/lib/strings"
 	"github.com/cortexlabs/cortex/pkg/types/spec"
 	"github.com/cortexlabs/cortex/pkg/types/status"
 	"github.com/cortexlabs/cortex/pkg/types/userconfig"
@@ -172,9 +172,15 @@ func GetJobState(jobKey spec.JobKey) (*State, error) {
 		return nil, errors.Wrap(ErrorJobNotFound(jobKey), "failed to get job state")
 	}
 
+	jobPrefix := jobKey.Prefix(config.ClusterConfig.ClusterUID)
 	lastUpdatedMap := map[string]time.Time{}
 	for _, object := range s3Objects {
-		lastUpdatedMap[filepath.Base(*object.Key)] = *object.LastModified
+		// skip the files which are nested in the job's directory (e.g. checkpoints and results)
+		fileName := strings.TrimPrefix(*object.Key, jobPrefix)
+		if strings.Contains(fileName, "/") {
+			continue
+		}
+		lastUpdatedMap[fileName] = *object.LastModified
 	}
 
 	jobState := getJobStateFromFiles(jobKey, lastUpdatedMap)
@@ -207,7 +213,7 @@ func getJobStateFromFiles(jobKey spec.JobKey, lastUpdatedFileMap map[string]time
 
 func GetMostRecentlySubmittedJobStates(apiName string, count int, kind userconfig.Kind) ([]*State, error) {
 	// a single job state may include 5 files on average, overshoot the number of files needed
-	apiPrefix := strings.EnsureSuffix(spec.JobAPIPrefix(config.ClusterConfig.ClusterUID, kind, apiName), "/")
+	apiPrefix := s.EnsureSuffix(spec.JobAPIPrefix(config.ClusterConfig.ClusterUID, kind, apiName), "/")
 
 	s3Objects, err := config.AWS.ListS3Prefix(
 		config.ClusterConfig.Bucket,
@@ -228,8 +234,13 @@ func GetMostRecentlySubmittedJobStates(apiName string, count int, kind userconfi
 		if object == nil {
 			continue
 		}
-		fileName := filepath.Base(*object.Key)
-		jobID := filepath.Base(filepath.Dir(*object.Key))
+
+		// skip the files which are nested in a job's directory (e.g. checkpoints and results)
+		pathParts := strings.Split(strings.TrimPrefix(*object.Key, apiPrefix), "/")
+		if len(pathParts) != 2 {
+			continue
+		}
+		jobID, fileName := pathParts[0], pathParts[1]
 		if _, ok := lastUpdatedMaps[jobID]; !ok {
 			jobIDOrder = append(jobIDOrder, jobID)
 			lastUpdatedMaps[jobID] = map[string]time.Time{fileName: *object.LastModified}
@@ -0,0 +1,47 @@
+/*
+Copyright 2021 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package job
+
+import (
+	"github.com/cortexlabs/cortex/pkg/config"
+	"github.com/cortexlabs/cortex/pkg/types/spec"
+	"github.com/cortexlabs/cortex/pkg/types/status"
+)
+
+// UploadSubmission stores the job's submission (for batch jobs, it is also read by the enqueuer) so that the job can be retried
+func UploadSubmission(jobKey spec.JobKey, submission interface{}) error {
+	key := spec.JobPayloadKey(config.ClusterConfig.ClusterUID, jobKey.Kind, jobKey.APIName, jobKey.ID)
+	return config.AWS.UploadJSONToS3(submission, config.ClusterConfig.Bucket, key)
+}
+
+// ReadSubmissionForRetry reads the submission of a failed job into submissionPtr
+func ReadSubmissionForRetry(jobKey spec.JobKey, jobStatus status.JobCode, submissionPtr interface{}) error {
+	if !jobStatus.IsFailed() {
+		return ErrorJobHasNotFailed(jobKey, jobStatus)
+	}
+
+	key := spec.JobPayloadKey(config.ClusterConfig.ClusterUID, jobKey.Kind, jobKey.APIName, jobKey.ID)
+	exists, err := config.AWS.IsS3File(config.ClusterConfig.Bucket, key)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return ErrorJobSubmissionNotFound(jobKey)
+	}
+
+	return config.AWS.ReadJSONFromS3(submissionPtr, config.ClusterConfig.Bucket, key)
+}
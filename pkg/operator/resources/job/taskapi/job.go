@@ -69,11 +69,30 @@ func SubmitJob(apiName string, submission *schema.TaskJobSubmission) (*spec.Task
 		return nil, err
 	}
 
+	// kept so that the job can be retried
+	if err := job.UploadSubmission(jobKey, submission); err != nil {
+		return nil, err
+	}
+
 	deployJob(apiSpec, &jobSpec)
 
 	return &jobSpec, nil
 }
 
+func RetryJob(jobKey spec.JobKey) (*spec.TaskJob, error) {
+	jobStatus, err := GetJobStatus(jobKey)
+	if err != nil {
+		return nil, err
+	}
+
+	submission := schema.TaskJobSubmission{}
+	if err := job.ReadSubmissionForRetry(jobKey, jobStatus.Status, &submission); err != nil {
+		return nil, err
+	}
+
+	return SubmitJob(jobKey.APIName, &submission)
+}
+
 func uploadJobSpec(jobSpec *spec.TaskJob) error {
 	if err := config.AWS.UploadJSONToS3(
 		jobSpec, config.ClusterConfig.Bucket, jobSpec.SpecFilePath(config.ClusterConfig.ClusterUID),
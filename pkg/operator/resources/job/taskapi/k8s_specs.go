@@ -21,6 +21,7 @@ import (
 
 	"github.com/cortexlabs/cortex/pkg/config"
 	"github.com/cortexlabs/cortex/pkg/consts"
+	"github.com/cortexlabs/cortex/pkg/lib/aws"
 	"github.com/cortexlabs/cortex/pkg/lib/k8s"
 	"github.com/cortexlabs/cortex/pkg/lib/parallel"
 	"github.com/cortexlabs/cortex/pkg/lib/pointer"
@@ -48,14 +49,14 @@ func virtualServiceSpec(api *spec.API) *istioclientnetworking.VirtualService {
 		PrefixPath:  api.Networking.Endpoint,
 		Rewrite:     pointer.String(path.Join("tasks", api.Name)),
 		Annotations: api.ToK8sAnnotations(),
-		Labels: map[string]string{
+		Labels: workloads.WithUserLabels(api.API, map[string]string{
 			"apiName":        api.Name,
 			"apiID":          api.ID,
 			"specID":         api.SpecID,
 			"podID":          api.PodID,
 			"apiKind":        api.Kind.String(),
 			"cortex.dev/api": "true",
-		},
+		}),
 	})
 }
 
@@ -65,7 +66,7 @@ func k8sJobSpec(api *spec.API, job *spec.TaskJob) *kbatch.Job {
 	return k8s.Job(&k8s.JobSpec{
 		Name:        job.JobKey.K8sName(),
 		Parallelism: int32(job.Workers),
-		Labels: map[string]string{
+		Labels: workloads.WithUserLabels(api.API, map[string]string{
 			"apiName":        api.Name,
 			"apiID":          api.ID,
 			"specID":         api.SpecID,
@@ -73,30 +74,33 @@ func k8sJobSpec(api *spec.API, job *spec.TaskJob) *kbatch.Job {
 			"jobID":          job.ID,
 			"apiKind":        api.Kind.String(),
 			"cortex.dev/api": "true",
-		},
+		}),
 		PodSpec: k8s.PodSpec{
-			Labels: map[string]string{
+			Labels: workloads.WithUserLabels(api.API, map[string]string{
 				"apiName":        api.Name,
 				"podID":          api.PodID,
 				"jobID":          job.ID,
 				"apiKind":        api.Kind.String(),
 				"cortex.dev/api": "true",
-			},
+			}),
 			Annotations: map[string]string{
 				"traffic.sidecar.istio.io/excludeOutboundIPRanges": "0.0.0.0/0",
 				"cluster-autoscaler.kubernetes.io/safe-to-evict":   "false",
+				"karpenter.sh/do-not-evict":                        "true",
 			},
 			K8sPodSpec: kcore.PodSpec{
 				RestartPolicy: "Never",
-				InitContainers: []kcore.Container{
-					workloads.KubexitInitContainer(),
-				},
+				InitContainers: append(
+					[]kcore.Container{workloads.KubexitInitContainer()},
+					workloads.UserPodInitContainers(*api)...,
+				),
 				Containers:         containers,
 				NodeSelector:       workloads.NodeSelectors(),
 				Tolerations:        workloads.GenerateResourceTolerations(),
-				Affinity:           workloads.GenerateNodeAffinities(api.NodeGroups),
+				Affinity:           workloads.GenerateNodeAffinities(api.NodeGroups, api.Arch, aws.OSLinux),
 				Volumes:            volumes,
-				ServiceAccountName: workloads.ServiceAccountName,
+				ServiceAccountName: workloads.APIServiceAccountName(api.API),
+				ImagePullSecrets:   workloads.ImagePullSecrets(api.API),
 			},
 		},
 	})
@@ -0,0 +1,154 @@
+/*
+Copyright 2021 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"github.com/cortexlabs/cortex/pkg/config"
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/lib/k8s"
+	"github.com/cortexlabs/cortex/pkg/lib/sets/strset"
+	s "github.com/cortexlabs/cortex/pkg/lib/strings"
+	"github.com/cortexlabs/cortex/pkg/operator/operator"
+	"github.com/cortexlabs/cortex/pkg/operator/schema"
+	"github.com/cortexlabs/cortex/pkg/types/userconfig"
+	istioclientnetworking "istio.io/client-go/pkg/apis/networking/v1beta1"
+	kresource "k8s.io/apimachinery/pkg/api/resource"
+)
+
+// GetQuotaUsages returns the compute which is requested by the deployed realtime and async apis of each team (in the order of the quotas in the cluster config)
+func GetQuotaUsages() ([]schema.QuotaUsage, error) {
+	if len(config.ClusterConfig.Quotas) == 0 {
+		return nil, nil
+	}
+
+	virtualServices, err := config.K8s.ListVirtualServices(nil)
+	if err != nil {
+		return nil, err
+	}
+
+	usages, err := getQuotaUsages(virtualServices, strset.New())
+	if err != nil {
+		return nil, err
+	}
+
+	quotaUsages := make([]schema.QuotaUsage, 0, len(usages))
+	for _, quota := range config.ClusterConfig.Quotas {
+		quotaUsages = append(quotaUsages, *usages[quota.Team])
+	}
+	return quotaUsages, nil
+}
+
+// getQuotaUsages returns the usage of each team (by team name), ignoring the apis in excludedAPIs (e.g. because they are being redeployed)
+func getQuotaUsages(virtualServices []istioclientnetworking.VirtualService, excludedAPIs strset.Set) (map[string]*schema.QuotaUsage, error) {
+	usages := make(map[string]*schema.QuotaUsage, len(config.ClusterConfig.Quotas))
+	for _, quota := range config.ClusterConfig.Quotas {
+		usages[quota.Team] = &schema.QuotaUsage{
+			Quota: *quota,
+			Used: userconfig.Compute{
+				CPU: k8s.NewMilliQuantity(0),
+				Mem: k8s.NewQuantity(0),
+			},
+		}
+	}
+
+	for _, virtualService := range virtualServices {
+		apiKind := virtualService.Labels["apiKind"]
+		if apiKind != userconfig.RealtimeAPIKind.String() && apiKind != userconfig.AsyncAPIKind.String() {
+			continue
+		}
+
+		apiName := virtualService.Labels["apiName"]
+		if excludedAPIs.Has(apiName) {
+			continue
+		}
+
+		quota := config.ClusterConfig.QuotaForAPI(apiName)
+		if quota == nil {
+			continue
+		}
+
+		api, err := operator.DownloadAPISpec(apiName, virtualService.Labels["apiID"])
+		if err != nil {
+			return nil, err
+		}
+		addQuotaUsage(usages[quota.Team], api.API)
+	}
+
+	return usages, nil
+}
+
+// addQuotaUsage adds the compute which the api requests at its max replicas to the usage
+func addQuotaUsage(usage *schema.QuotaUsage, api *userconfig.API) {
+	replicas := int64(api.Autoscaling.MaxReplicas + api.Autoscaling.WarmReplicas)
+	compute := userconfig.GetTotalComputeFromPod(api.Pod)
+
+	if compute.CPU != nil {
+		usage.Used.CPU.Add(*kresource.NewMilliQuantity(compute.CPU.MilliValue()*replicas, kresource.DecimalSI))
+	}
+	if compute.Mem != nil {
+		usage.Used.Mem.Add(*kresource.NewQuantity(compute.Mem.Value()*replicas, kresource.BinarySI))
+	}
+	usage.Used.GPU += compute.GPU * float64(replicas)
+	usage.Replicas += replicas
+	usage.NumAPIs++
+}
+
+// validateQuotas returns an error if deploying the realtime and async apis would cause a team to exceed its quota
+func validateQuotas(apis []userconfig.API, virtualServices []istioclientnetworking.VirtualService) error {
+	if len(config.ClusterConfig.Quotas) == 0 {
+		return nil
+	}
+
+	apiNames := strset.New()
+	var quotaAPIs []*userconfig.API
+	for i := range apis {
+		api := &apis[i]
+		apiNames.Add(api.Name)
+		if (api.Kind == userconfig.RealtimeAPIKind || api.Kind == userconfig.AsyncAPIKind) && config.ClusterConfig.QuotaForAPI(api.Name) != nil {
+			quotaAPIs = append(quotaAPIs, api)
+		}
+	}
+	if len(quotaAPIs) == 0 {
+		return nil
+	}
+
+	usages, err := getQuotaUsages(virtualServices, apiNames)
+	if err != nil {
+		return err
+	}
+
+	for _, api := range quotaAPIs {
+		quota := config.ClusterConfig.QuotaForAPI(api.Name)
+		usage := usages[quota.Team]
+		addQuotaUsage(usage, api)
+
+		if quota.CPU != nil && usage.Used.CPU.Cmp(quota.CPU.Quantity) > 0 {
+			return errors.Wrap(ErrorQuotaExceeded(quota.Team, "cpu", usage.Used.CPU.String(), quota.CPU.String()), api.Identify())
+		}
+		if quota.Mem != nil && usage.Used.Mem.Cmp(quota.Mem.Quantity) > 0 {
+			return errors.Wrap(ErrorQuotaExceeded(quota.Team, "memory", usage.Used.Mem.String(), quota.Mem.String()), api.Identify())
+		}
+		if quota.GPU != nil && usage.Used.GPU > float64(*quota.GPU) {
+			return errors.Wrap(ErrorQuotaExceeded(quota.Team, "gpu", s.Round(usage.Used.GPU, 4, 0), s.Int64(*quota.GPU)), api.Identify())
+		}
+		if quota.Replicas != nil && usage.Replicas > *quota.Replicas {
+			return errors.Wrap(ErrorQuotaExceeded(quota.Team, "replicas", s.Int64(usage.Replicas), s.Int64(*quota.Replicas)), api.Identify())
+		}
+	}
+
+	return nil
+}
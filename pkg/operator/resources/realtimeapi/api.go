@@ -61,6 +61,14 @@ func UpdateAPI(apiConfig *userconfig.API, force bool) (*spec.API, string, error)
 	api := spec.GetAPISpec(apiConfig, deploymentID, config.ClusterConfig.ClusterUID)
 
 	if prevDeployment == nil {
+		if err := operator.ApplyAPIPodResources(apiConfig); err != nil {
+			return nil, "", err
+		}
+
+		if err := operator.RunPreDeployHook(api); err != nil {
+			return nil, "", err
+		}
+
 		if err := config.AWS.UploadJSONToS3(api, config.ClusterConfig.Bucket, api.Key); err != nil {
 			return nil, "", errors.Wrap(err, "upload api spec")
 		}
@@ -84,6 +92,14 @@ func UpdateAPI(apiConfig *userconfig.API, force bool) (*spec.API, string, error)
 			return nil, "", ErrorAPIUpdating(api.Name)
 		}
 
+		if err := operator.ApplyAPIPodResources(apiConfig); err != nil {
+			return nil, "", err
+		}
+
+		if err := operator.RunPreDeployHook(api); err != nil {
+			return nil, "", err
+		}
+
 		if err := config.AWS.UploadJSONToS3(api, config.ClusterConfig.Bucket, api.Key); err != nil {
 			return nil, "", errors.Wrap(err, "upload api spec")
 		}
@@ -91,6 +107,9 @@ func UpdateAPI(apiConfig *userconfig.API, force bool) (*spec.API, string, error)
 		if err := applyK8sResources(api, prevDeployment, prevService, prevVirtualService); err != nil {
 			return nil, "", err
 		}
+
+		operator.DeleteStaleAPIPodResources(apiConfig, prevDeployment)
+
 		return api, fmt.Sprintf("updating %s", api.Resource.UserString()), nil
 	}
 
@@ -136,8 +155,16 @@ func RefreshAPI(apiName string, force bool) (string, error) {
 		return "", err
 	}
 
+	if err := operator.ApplyAPIPodResources(api.API); err != nil {
+		return "", err
+	}
+
 	api = spec.GetAPISpec(api.API, deploymentID(), config.ClusterConfig.ClusterUID)
 
+	if err := operator.RunPreDeployHook(api); err != nil {
+		return "", err
+	}
+
 	if err := config.AWS.UploadJSONToS3(api, config.ClusterConfig.Bucket, api.Key); err != nil {
 		return "", errors.Wrap(err, "upload api spec")
 	}
@@ -146,6 +173,8 @@ func RefreshAPI(apiName string, force bool) (string, error) {
 		return "", err
 	}
 
+	operator.DeleteStaleAPIPodResources(api.API, prevDeployment)
+
 	return fmt.Sprintf("updating %s", api.Name), nil
 }
 
@@ -236,6 +265,11 @@ func GetAPIByName(deployedResource *operator.DeployedResource) ([]schema.APIResp
 		return nil, err
 	}
 
+	metrics.SLO, err = GetSLOStatus(api)
+	if err != nil {
+		return nil, err
+	}
+
 	apiEndpoint, err := operator.APIEndpoint(api)
 	if err != nil {
 		return nil, err
@@ -291,6 +325,12 @@ func applyK8sResources(api *spec.API, prevDeployment *kapps.Deployment, prevServ
 		func() error {
 			return applyK8sVirtualService(api, prevVirtualService)
 		},
+		func() error {
+			return applyK8sPDB(api)
+		},
+		func() error {
+			return applyK8sHedgingEnvoyFilter(api)
+		},
 	)
 }
 
@@ -330,7 +370,7 @@ func UpdateAutoscalerCron(deployment *kapps.Deployment, apiSpec *spec.API) error
 		prevAutoscalerCron.Cancel()
 	}
 
-	autoscaler, err := autoscalerlib.AutoscaleFn(deployment, apiSpec, getInflightRequests)
+	autoscaler, err := autoscalerlib.AutoscaleFn(deployment, apiSpec, getInflightRequests, nil, servingReplicasFn(apiName))
 	if err != nil {
 		return err
 	}
@@ -353,14 +393,41 @@ func applyK8sService(api *spec.API, prevService *kcore.Service) error {
 }
 
 func applyK8sVirtualService(api *spec.API, prevVirtualService *istioclientnetworking.VirtualService) error {
-	newVirtualService := virtualServiceSpec(api)
+	newVirtualService := virtualServiceSpec(api, prevVirtualService)
 
 	if prevVirtualService == nil {
-		_, err := config.K8s.CreateVirtualService(newVirtualService)
+		if _, err := config.K8s.CreateVirtualService(newVirtualService); err != nil {
+			return err
+		}
+	} else {
+		if _, err := config.K8s.UpdateVirtualService(prevVirtualService, newVirtualService); err != nil {
+			return err
+		}
+	}
+
+	UpdateHedgingCron(api)
+	return nil
+}
+
+// the envoy filter is only created if the api hedges requests
+func applyK8sHedgingEnvoyFilter(api *spec.API) error {
+	if !isHedgingEnabled(api) {
+		_, err := config.K8sIstio.DeleteEnvoyFilter(hedgingEnvoyFilterName(api.Name))
+		return err
+	}
+
+	_, err := config.K8sIstio.ApplyEnvoyFilter(hedgingEnvoyFilterSpec(api))
+	return err
+}
+
+// the pod disruption budget is only created if max_disrupted_replicas is specified
+func applyK8sPDB(api *spec.API) error {
+	if api.Availability == nil || api.Availability.MaxDisruptedReplicas == nil {
+		_, err := config.K8s.DeletePDB(workloads.K8sName(api.Name))
 		return err
 	}
 
-	_, err := config.K8s.UpdateVirtualService(prevVirtualService, newVirtualService)
+	_, err := config.K8s.ApplyPDB(pdbSpec(api))
 	return err
 }
 
@@ -380,9 +447,22 @@ func deleteK8sResources(apiName string) error {
 			return err
 		},
 		func() error {
+			if hedgingCron, ok := _hedgingCrons[apiName]; ok {
+				hedgingCron.Cancel()
+				delete(_hedgingCrons, apiName)
+			}
+
 			_, err := config.K8s.DeleteVirtualService(workloads.K8sName(apiName))
 			return err
 		},
+		func() error {
+			_, err := config.K8s.DeletePDB(workloads.K8sName(apiName))
+			return err
+		},
+		func() error {
+			_, err := config.K8sIstio.DeleteEnvoyFilter(hedgingEnvoyFilterName(apiName))
+			return err
+		},
 	)
 }
 
@@ -0,0 +1,158 @@
+/*
+Copyright 2021 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package realtimeapi
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/cortexlabs/cortex/pkg/config"
+	"github.com/cortexlabs/cortex/pkg/lib/cron"
+	"github.com/cortexlabs/cortex/pkg/lib/k8s"
+	"github.com/cortexlabs/cortex/pkg/operator/operator"
+	"github.com/cortexlabs/cortex/pkg/types/spec"
+	"github.com/cortexlabs/cortex/pkg/workloads"
+	"github.com/gogo/protobuf/types"
+	istioclientnetworkingv1alpha3 "istio.io/client-go/pkg/apis/networking/v1alpha3"
+	istioclientnetworking "istio.io/client-go/pkg/apis/networking/v1beta1"
+)
+
+const (
+	_hedgingDelayUpdateInterval = 5 * time.Minute
+	_hedgingDelayWindow         = time.Hour
+	_defaultHedgingDelay        = time.Second           // used until there's enough latency data
+	_minHedgingDelay            = 10 * time.Millisecond // so that a burst of fast requests doesn't cause every request to be hedged
+	_hedgingDelayTolerance      = 0.1                   // the virtual service is only updated if the p95 latency changes by more than this fraction
+)
+
+var _hedgingCrons = make(map[string]cron.Cron) // apiName -> cron
+
+func isHedgingEnabled(api *spec.API) bool {
+	return api.Retries != nil && api.Retries.Hedging != nil
+}
+
+// envoy hedges a request when its per-try timeout is reached (instead of cancelling the attempt), so the hedging delay is used as the per-try timeout
+func hedgingEnvoyFilterSpec(api *spec.API) *istioclientnetworkingv1alpha3.EnvoyFilter {
+	return k8s.RouteEnvoyFilter(&k8s.RouteEnvoyFilterSpec{
+		Name:             hedgingEnvoyFilterName(api.Name),
+		WorkloadSelector: map[string]string{"istio": "ingressgateway-apis"},
+		RouteName:        workloads.K8sName(api.Name),
+		RoutePatch: map[string]interface{}{
+			"route": map[string]interface{}{
+				"hedge_policy": map[string]interface{}{
+					"hedge_on_per_try_timeout": true,
+				},
+			},
+		},
+		Labels: map[string]string{
+			"apiName":        api.Name,
+			"apiKind":        api.Kind.String(),
+			"cortex.dev/api": "true",
+		},
+	})
+}
+
+func hedgingEnvoyFilterName(apiName string) string {
+	return workloads.K8sName(apiName) + "-hedging"
+}
+
+// returns the configured delay, or the delay which was most recently set by the hedging cron (so that redeploying the api doesn't reset it)
+func hedgingDelay(api *spec.API, prevVirtualService *istioclientnetworking.VirtualService) time.Duration {
+	if api.Retries.Hedging.Delay != nil {
+		return *api.Retries.Hedging.Delay
+	}
+	if prevDelay := virtualServicePerTryTimeout(prevVirtualService); prevDelay != nil {
+		return *prevDelay
+	}
+	return _defaultHedgingDelay
+}
+
+func virtualServicePerTryTimeout(virtualService *istioclientnetworking.VirtualService) *time.Duration {
+	if virtualService == nil {
+		return nil
+	}
+	for _, route := range virtualService.Spec.Http {
+		if route.Retries == nil || route.Retries.PerTryTimeout == nil {
+			continue
+		}
+		perTryTimeout, err := types.DurationFromProto(route.Retries.PerTryTimeout)
+		if err != nil {
+			continue
+		}
+		return &perTryTimeout
+	}
+	return nil
+}
+
+// UpdateHedgingCron starts a cron which periodically sets the api's hedging delay to its p95 latency, if the api hedges requests without a fixed delay
+func UpdateHedgingCron(api *spec.API) {
+	if prevHedgingCron, ok := _hedgingCrons[api.Name]; ok {
+		prevHedgingCron.Cancel()
+		delete(_hedgingCrons, api.Name)
+	}
+
+	if !isHedgingEnabled(api) || api.Retries.Hedging.Delay != nil {
+		return
+	}
+
+	apiName := api.Name
+	_hedgingCrons[apiName] = cron.Run(func() error {
+		return updateHedgingDelay(apiName)
+	}, operator.ErrorHandler(apiName+" hedging"), _hedgingDelayUpdateInterval)
+}
+
+func updateHedgingDelay(apiName string) error {
+	latencyP95, err := queryPrometheusScalar(config.Prometheus, fmt.Sprintf(
+		"histogram_quantile(0.95, sum(rate(istio_request_duration_milliseconds_bucket{destination_service_name=~\"api-%s.+\"}[%ds])) by (le))",
+		apiName, int64(_hedgingDelayWindow.Seconds()),
+	))
+	if err != nil {
+		return err
+	}
+	if latencyP95 == nil {
+		return nil
+	}
+
+	delay := time.Duration(*latencyP95 * float64(time.Millisecond)).Round(time.Millisecond)
+	if delay < _minHedgingDelay {
+		delay = _minHedgingDelay
+	}
+
+	virtualService, err := config.K8s.GetVirtualService(workloads.K8sName(apiName))
+	if err != nil {
+		return err
+	}
+	if virtualService == nil {
+		return nil
+	}
+
+	if prevDelay := virtualServicePerTryTimeout(virtualService); prevDelay != nil {
+		if math.Abs(float64(delay-*prevDelay)) <= _hedgingDelayTolerance*float64(*prevDelay) {
+			return nil
+		}
+	}
+
+	for _, route := range virtualService.Spec.Http {
+		if route.Retries != nil {
+			route.Retries.PerTryTimeout = types.DurationProto(delay)
+		}
+	}
+
+	_, err = config.K8s.UpdateVirtualService(virtualService, virtualService)
+	return err
+}
@@ -21,23 +21,43 @@ import (
 	"github.com/cortexlabs/cortex/pkg/lib/k8s"
 	"github.com/cortexlabs/cortex/pkg/lib/pointer"
 	"github.com/cortexlabs/cortex/pkg/types/spec"
+	"github.com/cortexlabs/cortex/pkg/types/userconfig"
 	"github.com/cortexlabs/cortex/pkg/workloads"
 	istioclientnetworking "istio.io/client-go/pkg/apis/networking/v1beta1"
 	kapps "k8s.io/api/apps/v1"
 	kcore "k8s.io/api/core/v1"
+	kpolicy "k8s.io/api/policy/v1beta1"
 )
 
 var _terminationGracePeriodSeconds int64 = 60 // seconds
 
+// the label which determines whether a pod receives traffic, for apis which keep warm replicas
+const _servingLabelKey = "cortex.dev/serving"
+
 func deploymentSpec(api *spec.API, prevDeployment *kapps.Deployment) *kapps.Deployment {
 	containers, volumes := workloads.RealtimeContainers(*api)
 
+	annotations := api.ToK8sAnnotations()
+	workloads.ApplyVerticalAutoscaling(api.API, containers, prevDeployment, annotations)
+
+	podLabels := workloads.WithUserLabels(api.API, map[string]string{
+		"apiName":        api.Name,
+		"apiKind":        api.Kind.String(),
+		"deploymentID":   api.DeploymentID,
+		"podID":          api.PodID,
+		"cortex.dev/api": "true",
+	})
+	if api.Autoscaling.WarmReplicas > 0 {
+		// new pods start out warm, and are promoted to serving by the autoscaler
+		podLabels[_servingLabelKey] = "false"
+	}
+
 	return k8s.Deployment(&k8s.DeploymentSpec{
 		Name:           workloads.K8sName(api.Name),
 		Replicas:       getRequestedReplicasFromDeployment(*api, prevDeployment),
 		MaxSurge:       pointer.String(api.UpdateStrategy.MaxSurge),
 		MaxUnavailable: pointer.String(api.UpdateStrategy.MaxUnavailable),
-		Labels: map[string]string{
+		Labels: workloads.WithUserLabels(api.API, map[string]string{
 			"apiName":        api.Name,
 			"apiKind":        api.Kind.String(),
 			"apiID":          api.ID,
@@ -45,41 +65,54 @@ func deploymentSpec(api *spec.API, prevDeployment *kapps.Deployment) *kapps.Depl
 			"deploymentID":   api.DeploymentID,
 			"podID":          api.PodID,
 			"cortex.dev/api": "true",
-		},
-		Annotations: api.ToK8sAnnotations(),
+		}),
+		Annotations: annotations,
 		Selector: map[string]string{
 			"apiName": api.Name,
 			"apiKind": api.Kind.String(),
 		},
 		PodSpec: k8s.PodSpec{
-			Labels: map[string]string{
-				"apiName":        api.Name,
-				"apiKind":        api.Kind.String(),
-				"deploymentID":   api.DeploymentID,
-				"podID":          api.PodID,
-				"cortex.dev/api": "true",
-			},
-			Annotations: map[string]string{
-				"traffic.sidecar.istio.io/excludeOutboundIPRanges": "0.0.0.0/0",
-			},
+			Labels:      podLabels,
+			Annotations: workloads.APIPodAnnotations(),
 			K8sPodSpec: kcore.PodSpec{
 				RestartPolicy:                 "Always",
 				TerminationGracePeriodSeconds: pointer.Int64(_terminationGracePeriodSeconds),
+				InitContainers:                workloads.UserPodInitContainers(*api),
 				Containers:                    containers,
 				NodeSelector:                  workloads.NodeSelectors(),
-				Tolerations:                   workloads.GenerateResourceTolerations(),
-				Affinity:                      workloads.GenerateNodeAffinities(api.NodeGroups),
-				Volumes:                       volumes,
-				ServiceAccountName:            workloads.ServiceAccountName,
+				Tolerations:                   append(workloads.GenerateResourceTolerations(), workloads.GenerateOSTolerations(api.OS)...),
+				Affinity:                      workloads.GenerateNodeAffinities(api.NodeGroups, api.Arch, api.OS),
+				TopologySpreadConstraints: workloads.GenerateTopologySpreadConstraints(api.Availability, map[string]string{
+					"apiName": api.Name,
+					"apiKind": api.Kind.String(),
+				}),
+				Volumes:            volumes,
+				ServiceAccountName: workloads.APIServiceAccountName(api.API),
+				ImagePullSecrets:   workloads.ImagePullSecrets(api.API),
 			},
 		},
 	})
 }
 
 func serviceSpec(api *spec.API) *kcore.Service {
+	selector := map[string]string{
+		"apiName": api.Name,
+		"apiKind": api.Kind.String(),
+	}
+	if api.Autoscaling.WarmReplicas > 0 {
+		// warm replicas are excluded from routing
+		selector[_servingLabelKey] = "true"
+	}
+
+	// the port's name determines the protocol which istio uses to forward requests to the proxy
+	portName := "http"
+	if api.Proxy != nil && api.Proxy.MaxConcurrentStreams != nil {
+		portName = "http2"
+	}
+
 	return k8s.Service(&k8s.ServiceSpec{
 		Name:        workloads.K8sName(api.Name),
-		PortName:    "http",
+		PortName:    portName,
 		Port:        consts.ProxyListeningPortInt32,
 		TargetPort:  consts.ProxyListeningPortInt32,
 		Annotations: api.ToK8sAnnotations(),
@@ -88,6 +121,20 @@ func serviceSpec(api *spec.API) *kcore.Service {
 			"apiKind":        api.Kind.String(),
 			"cortex.dev/api": "true",
 		},
+		Selector: selector,
+	})
+}
+
+func pdbSpec(api *spec.API) *kpolicy.PodDisruptionBudget {
+	return k8s.PDB(&k8s.PDBSpec{
+		Name:           workloads.K8sName(api.Name),
+		MaxUnavailable: *api.Availability.MaxDisruptedReplicas,
+		Annotations:    api.ToK8sAnnotations(),
+		Labels: map[string]string{
+			"apiName":        api.Name,
+			"apiKind":        api.Kind.String(),
+			"cortex.dev/api": "true",
+		},
 		Selector: map[string]string{
 			"apiName": api.Name,
 			"apiKind": api.Kind.String(),
@@ -95,7 +142,19 @@ func serviceSpec(api *spec.API) *kcore.Service {
 	})
 }
 
-func virtualServiceSpec(api *spec.API) *istioclientnetworking.VirtualService {
+func virtualServiceSpec(api *spec.API, prevVirtualService *istioclientnetworking.VirtualService) *istioclientnetworking.VirtualService {
+	var retries *k8s.Retries
+	if api.Retries != nil {
+		retries = &k8s.Retries{
+			Attempts:      api.Retries.Attempts,
+			PerTryTimeout: api.Retries.PerTryTimeout,
+			RetryOn:       api.Retries.RetryOn,
+		}
+		if isHedgingEnabled(api) {
+			retries.PerTryTimeout = pointer.Duration(hedgingDelay(api, prevVirtualService))
+		}
+	}
+
 	return k8s.VirtualService(&k8s.VirtualServiceSpec{
 		Name:     workloads.K8sName(api.Name),
 		Gateways: []string{"apis-gateway"},
@@ -106,8 +165,10 @@ func virtualServiceSpec(api *spec.API) *istioclientnetworking.VirtualService {
 		}},
 		PrefixPath:  api.Networking.Endpoint,
 		Rewrite:     pointer.String("/"),
+		RouteName:   workloads.K8sName(api.Name),
+		Retries:     retries,
 		Annotations: api.ToK8sAnnotations(),
-		Labels: map[string]string{
+		Labels: workloads.WithUserLabels(api.API, map[string]string{
 			"apiName":        api.Name,
 			"apiKind":        api.Kind.String(),
 			"apiID":          api.ID,
@@ -115,15 +176,19 @@ func virtualServiceSpec(api *spec.API) *istioclientnetworking.VirtualService {
 			"deploymentID":   api.DeploymentID,
 			"podID":          api.PodID,
 			"cortex.dev/api": "true",
-		},
+		}),
 	})
 }
 
+// returns the number of replicas of the deployment, which includes the warm replicas
 func getRequestedReplicasFromDeployment(api spec.API, deployment *kapps.Deployment) int32 {
 	requestedReplicas := api.Autoscaling.InitReplicas
 
 	if deployment != nil && deployment.Spec.Replicas != nil && *deployment.Spec.Replicas > 0 {
 		requestedReplicas = *deployment.Spec.Replicas
+		if prevAutoscaling, err := userconfig.AutoscalingFromAnnotations(deployment); err == nil {
+			requestedReplicas -= prevAutoscaling.WarmReplicas
+		}
 	}
 
 	if requestedReplicas < api.Autoscaling.MinReplicas {
@@ -134,5 +199,5 @@ func getRequestedReplicasFromDeployment(api spec.API, deployment *kapps.Deployme
 		requestedReplicas = api.Autoscaling.MaxReplicas
 	}
 
-	return requestedReplicas
+	return requestedReplicas + api.Autoscaling.WarmReplicas
 }
@@ -0,0 +1,146 @@
+/*
+Copyright 2021 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package realtimeapi
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/cortexlabs/cortex/pkg/config"
+	"github.com/cortexlabs/cortex/pkg/lib/parallel"
+	"github.com/cortexlabs/cortex/pkg/types/metrics"
+	"github.com/cortexlabs/cortex/pkg/types/spec"
+	promv1 "github.com/prometheus/client_golang/api/prometheus/v1"
+)
+
+// the window over which the burn rate is measured
+const _sloBurnRateWindow = time.Hour
+
+func GetSLOStatus(api *spec.API) (*metrics.SLOStatus, error) {
+	if api.SLO == nil {
+		return nil, nil
+	}
+
+	sloStatus := metrics.SLOStatus{
+		Window:  api.SLO.Window.String(),
+		Latency: make([]metrics.LatencySLOStatus, len(api.SLO.Latency)),
+	}
+
+	var fns []func() error
+
+	if api.SLO.Availability != nil {
+		sloStatus.Availability = &metrics.AvailabilitySLOStatus{
+			Target: *api.SLO.Availability,
+		}
+		fns = append(fns, func() error {
+			return updateAvailabilitySLOStatus(config.Prometheus, *api, sloStatus.Availability)
+		})
+	}
+
+	for i := range api.SLO.Latency {
+		localIdx := i
+		latencyTarget := api.SLO.Latency[i]
+		sloStatus.Latency[localIdx] = metrics.LatencySLOStatus{
+			Percentile: latencyTarget.Percentile,
+			Threshold:  float64(latencyTarget.Threshold) / float64(time.Millisecond),
+		}
+		fns = append(fns, func() error {
+			var err error
+			sloStatus.Latency[localIdx].Attained, err = getLatencyPercentileMetric(config.Prometheus, *api, latencyTarget.Percentile, api.SLO.Window)
+			return err
+		})
+	}
+
+	if len(fns) > 0 {
+		if err := parallel.RunFirstErr(fns[0], fns[1:]...); err != nil {
+			return nil, err
+		}
+	}
+
+	return &sloStatus, nil
+}
+
+func updateAvailabilitySLOStatus(promAPIv1 promv1.API, apiSpec spec.API, availabilitySLOStatus *metrics.AvailabilitySLOStatus) error {
+	var windowErrorRatio *float64
+	var recentErrorRatio *float64
+
+	err := parallel.RunFirstErr(
+		func() error {
+			var err error
+			windowErrorRatio, err = getErrorRatioMetric(promAPIv1, apiSpec, apiSpec.SLO.Window)
+			return err
+		},
+		func() error {
+			var err error
+			recentErrorRatio, err = getErrorRatioMetric(promAPIv1, apiSpec, _sloBurnRateWindow)
+			return err
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	if windowErrorRatio != nil {
+		attained := (1 - *windowErrorRatio) * 100
+		availabilitySLOStatus.Attained = &attained
+	}
+	availabilitySLOStatus.BurnRate, availabilitySLOStatus.RemainingBudget = metrics.ErrorBudgetStatus(availabilitySLOStatus.Target, windowErrorRatio, recentErrorRatio)
+
+	return nil
+}
+
+// returns the fraction of requests which returned 5xx status codes in the window, or nil if there weren't any requests
+func getErrorRatioMetric(promAPIv1 promv1.API, apiSpec spec.API, window time.Duration) (*float64, error) {
+	windowSeconds := int64(window.Seconds())
+
+	query := fmt.Sprintf(
+		"(sum(increase(istio_requests_total{destination_service_name=~\"api-%s.+\", response_code=~\"5.*\"}[%ds])) or vector(0)) "+
+			"/ sum(increase(istio_requests_total{destination_service_name=~\"api-%s.+\"}[%ds]))",
+		apiSpec.Name, windowSeconds,
+		apiSpec.Name, windowSeconds,
+	)
+
+	return queryPrometheusScalar(promAPIv1, query)
+}
+
+// returns the latency percentile (in milliseconds) over the window, or nil if there weren't any requests
+func getLatencyPercentileMetric(promAPIv1 promv1.API, apiSpec spec.API, percentile float64, window time.Duration) (*float64, error) {
+	query := fmt.Sprintf(
+		"histogram_quantile(%g, sum(rate(istio_request_duration_milliseconds_bucket{destination_service_name=~\"api-%s.+\"}[%ds])) by (le))",
+		percentile/100, apiSpec.Name, int64(window.Seconds()),
+	)
+
+	return queryPrometheusScalar(promAPIv1, query)
+}
+
+func queryPrometheusScalar(promAPIv1 promv1.API, query string) (*float64, error) {
+	values, err := queryPrometheusVec(promAPIv1, query)
+	if err != nil {
+		return nil, err
+	}
+
+	if values.Len() == 0 {
+		return nil, nil
+	}
+
+	value := float64(values[0].Value)
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		return nil, nil
+	}
+	return &value, nil
+}
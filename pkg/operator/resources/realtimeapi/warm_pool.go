@@ -0,0 +1,120 @@
+/*
+Copyright 2021 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package realtimeapi
+
+import (
+	"sort"
+
+	"github.com/cortexlabs/cortex/pkg/config"
+	"github.com/cortexlabs/cortex/pkg/lib/k8s"
+	"github.com/cortexlabs/cortex/pkg/workloads"
+	kcore "k8s.io/api/core/v1"
+)
+
+// pods with a higher deletion cost are removed last when the deployment is scaled down,
+// so that serving pods are kept and warm pods are removed first
+const (
+	_podDeletionCostAnnotationKey = "controller.kubernetes.io/pod-deletion-cost"
+	_servingPodDeletionCost       = "100"
+)
+
+// servingReplicasFn returns the function which is called by the autoscaler to promote warm pods to
+// serving pods (and demote the excess serving pods to warm) so that servingReplicas pods receive traffic
+func servingReplicasFn(apiName string) func(servingReplicas int32) error {
+	return func(servingReplicas int32) error {
+		deployment, err := config.K8s.GetDeployment(workloads.K8sName(apiName))
+		if err != nil {
+			return err
+		}
+		if deployment == nil {
+			return nil
+		}
+
+		pods, err := config.K8s.ListPodsByLabel("apiName", apiName)
+		if err != nil {
+			return err
+		}
+
+		serving := selectServingPods(pods, deployment.Spec.Template.Labels["podID"], servingReplicas)
+
+		for i := range pods {
+			pod := &pods[i]
+			if pod.DeletionTimestamp != nil {
+				continue
+			}
+
+			isServing := serving[pod.Name]
+			if isServing == (pod.Labels[_servingLabelKey] == "true") {
+				continue
+			}
+
+			if isServing {
+				pod.Labels[_servingLabelKey] = "true"
+				if pod.Annotations == nil {
+					pod.Annotations = map[string]string{}
+				}
+				pod.Annotations[_podDeletionCostAnnotationKey] = _servingPodDeletionCost
+			} else {
+				pod.Labels[_servingLabelKey] = "false"
+				delete(pod.Annotations, _podDeletionCostAnnotationKey)
+			}
+
+			if _, err := config.K8s.UpdatePod(pod); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+}
+
+// selectServingPods returns the names of the ready pods which should receive traffic; pods of the
+// current pod spec are preferred, followed by pods which are already serving, followed by the oldest pods
+func selectServingPods(pods []kcore.Pod, podID string, servingReplicas int32) map[string]bool {
+	var candidates []kcore.Pod
+	for i := range pods {
+		if pods[i].DeletionTimestamp == nil && k8s.IsPodReady(&pods[i]) {
+			candidates = append(candidates, pods[i])
+		}
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		iCurrent := candidates[i].Labels["podID"] == podID
+		jCurrent := candidates[j].Labels["podID"] == podID
+		if iCurrent != jCurrent {
+			return iCurrent
+		}
+
+		iServing := candidates[i].Labels[_servingLabelKey] == "true"
+		jServing := candidates[j].Labels[_servingLabelKey] == "true"
+		if iServing != jServing {
+			return iServing
+		}
+
+		return candidates[i].CreationTimestamp.Before(&candidates[j].CreationTimestamp)
+	})
+
+	serving := map[string]bool{}
+	for i := range candidates {
+		if int32(i) >= servingReplicas {
+			break
+		}
+		serving[candidates[i].Name] = true
+	}
+
+	return serving
+}
@@ -0,0 +1,284 @@
+/*
+Copyright 2021 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/cortexlabs/cortex/pkg/config"
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/lib/k8s"
+	"github.com/cortexlabs/cortex/pkg/lib/parallel"
+	"github.com/cortexlabs/cortex/pkg/lib/pointer"
+	"github.com/cortexlabs/cortex/pkg/operator/operator"
+	"github.com/cortexlabs/cortex/pkg/operator/schema"
+	"github.com/cortexlabs/cortex/pkg/types/userconfig"
+	"github.com/prometheus/common/model"
+	kcore "k8s.io/api/core/v1"
+	kresource "k8s.io/apimachinery/pkg/api/resource"
+)
+
+const (
+	DefaultRecommendationsWindow = 7 * 24 * time.Hour
+	MaxRecommendationsWindow     = 14 * 24 * time.Hour // prometheus' retention
+
+	_recommendationsStep           = 5 * time.Minute
+	_recommendationsRequestTimeout = 30 * time.Second
+	_recommendationHeadroom        = 1.2 // the usage is multiplied by this to leave headroom for spikes
+	_recommendationTolerance       = 0.2 // requests which are within this fraction of the recommendation are considered appropriate
+	_oomKilledMemIncrease          = 1.5 // the memory request is increased by at least this factor if the container ran out of memory
+	_minCPURecommendation          = 10  // millicores
+)
+
+// GetRecommendations compares the compute requests of the api's containers with their usage over the window (which is measured by prometheus),
+// and recommends requests for the containers which are over-provisioned or under-provisioned
+func GetRecommendations(apiName string, window time.Duration) (*schema.RecommendationsResponse, error) {
+	deployedResource, err := GetDeployedResourceByName(apiName)
+	if err != nil {
+		return nil, err
+	}
+	if deployedResource.Kind != userconfig.RealtimeAPIKind && deployedResource.Kind != userconfig.AsyncAPIKind {
+		return nil, ErrorOperationIsOnlySupportedForKind(*deployedResource, userconfig.RealtimeAPIKind, userconfig.AsyncAPIKind)
+	}
+
+	api, err := operator.DownloadAPISpec(deployedResource.Name, deployedResource.ID())
+	if err != nil {
+		return nil, err
+	}
+
+	pods, err := config.K8s.ListPodsByLabel("apiName", apiName)
+	if err != nil {
+		return nil, err
+	}
+
+	windowStr := window.String()
+	if window%(24*time.Hour) == 0 {
+		windowStr = fmt.Sprintf("%dd", window/(24*time.Hour))
+	}
+
+	response := schema.RecommendationsResponse{
+		APIName:    apiName,
+		Window:     windowStr,
+		Containers: make([]schema.ContainerRecommendation, len(api.Pod.Containers)),
+	}
+
+	fns := make([]func() error, len(api.Pod.Containers))
+	for i := range api.Pod.Containers {
+		localIdx := i
+		fns[i] = func() error {
+			recommendation, err := getContainerRecommendation(api.Name, api.Pod.Containers[localIdx], pods, window)
+			if err != nil {
+				return err
+			}
+			response.Containers[localIdx] = *recommendation
+			return nil
+		}
+	}
+	if err := parallel.RunFirstErr(fns[0], fns[1:]...); err != nil {
+		return nil, err
+	}
+
+	return &response, nil
+}
+
+func getContainerRecommendation(apiName string, container *userconfig.Container, pods []kcore.Pod, window time.Duration) (*schema.ContainerRecommendation, error) {
+	// the pods of the api's deployment (e.g. api-my-api-5d8f7c6b9-x2kqp), which excludes the pods of apis whose names begin with this api's name
+	selector := fmt.Sprintf(`pod=~"api-%s-[a-z0-9]+-[a-z0-9]+", container="%s"`, apiName, container.Name)
+	cpuUsage := fmt.Sprintf(`max(rate(container_cpu_usage_seconds_total{%s}[5m]))`, selector)
+	memUsage := fmt.Sprintf(`max(container_memory_working_set_bytes{%s})`, selector)
+
+	var cpuP95, cpuMax, memP95, memMax *float64
+	err := parallel.RunFirstErr(
+		func() error {
+			var err error
+			cpuP95, err = queryUsageOverWindow("quantile_over_time(0.95, ", cpuUsage, window)
+			return err
+		},
+		func() error {
+			var err error
+			cpuMax, err = queryUsageOverWindow("max_over_time(", cpuUsage, window)
+			return err
+		},
+		func() error {
+			var err error
+			memP95, err = queryUsageOverWindow("quantile_over_time(0.95, ", memUsage, window)
+			return err
+		},
+		func() error {
+			var err error
+			memMax, err = queryUsageOverWindow("max_over_time(", memUsage, window)
+			return err
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	recommendation := schema.ContainerRecommendation{
+		Container: container.Name,
+		OOMKilled: countOOMKilled(container.Name, pods),
+	}
+
+	var cpuRequest, memRequest *kresource.Quantity
+	if container.Compute != nil && container.Compute.CPU != nil {
+		cpuRequest = &container.Compute.CPU.Quantity
+	}
+	if container.Compute != nil && container.Compute.Mem != nil {
+		memRequest = &container.Compute.Mem.Quantity
+	}
+
+	recommendation.CPU = schema.ResourceRecommendation{
+		Requested: quantityStr(cpuRequest),
+		UsageP95:  cpuQuantityStr(cpuP95),
+		UsageMax:  cpuQuantityStr(cpuMax),
+	}
+	recommendation.Mem = schema.ResourceRecommendation{
+		Requested: quantityStr(memRequest),
+		UsageP95:  memQuantityStr(memP95),
+		UsageMax:  memQuantityStr(memMax),
+	}
+
+	// cpu is recommended based on the 95th percentile of usage, since cpu is throttled (rather than killed) when usage spikes above the request
+	if cpuP95 == nil {
+		recommendation.Suggestions = append(recommendation.Suggestions, fmt.Sprintf("there isn't enough cpu usage data for the %s container yet", container.Name))
+	} else {
+		recommendedCPU := kresource.NewMilliQuantity(roundUp(*cpuP95*1000*_recommendationHeadroom, _minCPURecommendation), kresource.DecimalSI)
+		if suggestion := compareRequest("cpu", container.Name, cpuRequest, recommendedCPU, *recommendation.CPU.UsageP95+" (95th percentile)"); suggestion != "" {
+			recommendation.CPU.Recommended = pointer.String(recommendedCPU.String())
+			recommendation.Suggestions = append(recommendation.Suggestions, suggestion)
+		}
+	}
+
+	// memory is recommended based on the maximum usage, since the container is killed if its node runs out of memory
+	if memMax == nil {
+		recommendation.Suggestions = append(recommendation.Suggestions, fmt.Sprintf("there isn't enough memory usage data for the %s container yet", container.Name))
+	} else {
+		recommendedMemMi := roundUp(*memMax*_recommendationHeadroom/(1024*1024), 1)
+		if recommendation.OOMKilled > 0 && memRequest != nil {
+			recommendedMemMi = int64(math.Max(float64(recommendedMemMi), math.Ceil(float64(memRequest.Value())*_oomKilledMemIncrease/(1024*1024))))
+		}
+		recommendedMem := kresource.NewQuantity(recommendedMemMi*1024*1024, kresource.BinarySI)
+		if suggestion := compareRequest("memory", container.Name, memRequest, recommendedMem, *recommendation.Mem.UsageMax+" (maximum)"); suggestion != "" {
+			recommendation.Mem.Recommended = pointer.String(recommendedMem.String())
+			recommendation.Suggestions = append(recommendation.Suggestions, suggestion)
+		}
+	}
+
+	if recommendation.OOMKilled > 0 {
+		recommendation.Suggestions = append(recommendation.Suggestions, fmt.Sprintf("the %s container ran out of memory in %d of the api's current replicas", container.Name, recommendation.OOMKilled))
+	}
+
+	return &recommendation, nil
+}
+
+// returns a suggestion if the request isn't within the tolerance of the recommendation, or an empty string if the request is appropriate
+func compareRequest(resourceName string, containerName string, request *kresource.Quantity, recommended *kresource.Quantity, usage string) string {
+	if request == nil {
+		return fmt.Sprintf("%s isn't requested for the %s container, so its replicas may be scheduled on nodes without enough %s (usage: %s); consider requesting %s", resourceName, containerName, resourceName, usage, recommended.String())
+	}
+
+	requestValue := float64(request.MilliValue())
+	recommendedValue := float64(recommended.MilliValue())
+	if math.Abs(requestValue-recommendedValue) <= requestValue*_recommendationTolerance {
+		return ""
+	}
+
+	provisioning := "over-provisioned"
+	if recommendedValue > requestValue {
+		provisioning = "under-provisioned"
+	}
+	return fmt.Sprintf("%s is %s for the %s container (requested: %s, usage: %s); consider requesting %s", resourceName, provisioning, containerName, request.String(), usage, recommended.String())
+}
+
+func countOOMKilled(containerName string, pods []kcore.Pod) int {
+	count := 0
+	for _, pod := range pods {
+		for _, containerStatus := range pod.Status.ContainerStatuses {
+			if containerStatus.Name != containerName {
+				continue
+			}
+			for _, terminated := range []*kcore.ContainerStateTerminated{containerStatus.State.Terminated, containerStatus.LastTerminationState.Terminated} {
+				if terminated != nil && terminated.Reason == k8s.ReasonOOMKilled {
+					count++
+					break
+				}
+			}
+		}
+	}
+	return count
+}
+
+// aggregates the usage over the window (e.g. with quantile_over_time), and returns nil if there isn't any usage data
+func queryUsageOverWindow(aggregation string, usageQuery string, window time.Duration) (*float64, error) {
+	query := fmt.Sprintf("%s%s[%ds:%ds])", aggregation, usageQuery, int64(window.Seconds()), int64(_recommendationsStep.Seconds()))
+
+	ctx, cancel := context.WithTimeout(context.Background(), _recommendationsRequestTimeout)
+	defer cancel()
+
+	valuesQuery, _, err := config.Prometheus.Query(ctx, query, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	values, ok := valuesQuery.(model.Vector)
+	if !ok {
+		return nil, errors.ErrorUnexpected("failed to convert metric to vector")
+	}
+
+	if values.Len() == 0 {
+		return nil, nil
+	}
+
+	value := float64(values[0].Value)
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		return nil, nil
+	}
+	return &value, nil
+}
+
+// rounds value up to a multiple of increment (and to at least increment)
+func roundUp(value float64, increment int64) int64 {
+	rounded := int64(math.Ceil(value/float64(increment))) * increment
+	if rounded < increment {
+		return increment
+	}
+	return rounded
+}
+
+func quantityStr(quantity *kresource.Quantity) *string {
+	if quantity == nil {
+		return nil
+	}
+	return pointer.String(quantity.String())
+}
+
+func cpuQuantityStr(cores *float64) *string {
+	if cores == nil {
+		return nil
+	}
+	return pointer.String(kresource.NewMilliQuantity(int64(math.Ceil(*cores*1000)), kresource.DecimalSI).String())
+}
+
+func memQuantityStr(bytes *float64) *string {
+	if bytes == nil {
+		return nil
+	}
+	return pointer.String(kresource.NewQuantity(int64(math.Ceil(*bytes/(1024*1024)))*1024*1024, kresource.BinarySI).String())
+}
@@ -0,0 +1,129 @@
+/*
+Copyright 2021 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/cortexlabs/cortex/pkg/config"
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/lib/k8s"
+	"github.com/cortexlabs/cortex/pkg/operator/schema"
+	kcore "k8s.io/api/core/v1"
+)
+
+const (
+	_maxReplicaFailures     = 3  // the logs of each failure are fetched from the kubelet, so only the most recent failures are described
+	_replicaFailureLogLines = 20 // the number of lines of the terminated container's logs to include
+)
+
+// getReplicaFailures describes the most recent failures of the api's replicas whose containers aren't running successfully
+func getReplicaFailures(pods []kcore.Pod) []schema.ReplicaFailure {
+	type podFailure struct {
+		failure  *schema.ReplicaFailure
+		previous bool // whether the logs of the container's previous instance should be fetched
+	}
+
+	var podFailures []podFailure
+	for i := range pods {
+		if pods[i].DeletionTimestamp != nil {
+			continue
+		}
+		if failure, previous := getReplicaFailure(&pods[i]); failure != nil {
+			podFailures = append(podFailures, podFailure{failure: failure, previous: previous})
+		}
+	}
+
+	sort.SliceStable(podFailures, func(i, j int) bool {
+		return finishedAt(podFailures[i].failure).After(finishedAt(podFailures[j].failure))
+	})
+	if len(podFailures) > _maxReplicaFailures {
+		podFailures = podFailures[:_maxReplicaFailures]
+	}
+
+	failures := make([]schema.ReplicaFailure, len(podFailures))
+	for i, podFailure := range podFailures {
+		failure := podFailure.failure
+		if failure.ExitCode != nil {
+			logs, err := config.K8s.GetPodLogs(failure.Replica, failure.Container, podFailure.previous, _replicaFailureLogLines)
+			if err != nil {
+				operatorLogger.Error(errors.Wrap(err, "failed to get the logs of a failed replica", failure.Replica, failure.Container))
+			} else if logs = strings.TrimRight(logs, "\n"); logs != "" {
+				failure.LogLines = strings.Split(logs, "\n")
+			}
+		}
+		failures[i] = *failure
+	}
+
+	return failures
+}
+
+// returns the failure of the pod's first failing container (or nil if all of its containers are healthy),
+// and whether the failure was a previous instance of the container (i.e. the container has since been restarted)
+func getReplicaFailure(pod *kcore.Pod) (*schema.ReplicaFailure, bool) {
+	for _, containerStatus := range pod.Status.ContainerStatuses {
+		failure := schema.ReplicaFailure{
+			Replica:   pod.Name,
+			Container: containerStatus.Name,
+			Restarts:  containerStatus.RestartCount,
+		}
+
+		if waiting := containerStatus.State.Waiting; waiting != nil && k8s.IsImagePullError(waiting.Reason) {
+			failure.Reason = waiting.Reason
+			failure.Message = strings.TrimSpace(waiting.Message)
+			return &failure, false
+		}
+
+		if terminated := containerStatus.State.Terminated; terminated != nil && terminated.ExitCode != 0 {
+			setTermination(&failure, terminated)
+			return &failure, false
+		}
+
+		if containerStatus.Ready {
+			continue
+		}
+
+		// the container is waiting to be restarted (e.g. CrashLoopBackOff), or it was restarted and isn't ready yet
+		if terminated := containerStatus.LastTerminationState.Terminated; terminated != nil && terminated.ExitCode != 0 {
+			setTermination(&failure, terminated)
+			return &failure, true
+		}
+	}
+
+	return nil, false
+}
+
+func setTermination(failure *schema.ReplicaFailure, terminated *kcore.ContainerStateTerminated) {
+	exitCode := terminated.ExitCode
+	failure.ExitCode = &exitCode
+	failure.Reason = terminated.Reason
+	failure.Message = strings.TrimSpace(terminated.Message)
+	if !terminated.FinishedAt.IsZero() {
+		finishedAt := terminated.FinishedAt.Time
+		failure.FinishedAt = &finishedAt
+	}
+}
+
+// failures without a termination time (e.g. image pull errors) are ongoing, so they are considered to be the most recent
+func finishedAt(failure *schema.ReplicaFailure) time.Time {
+	if failure.FinishedAt == nil {
+		return time.Now()
+	}
+	return *failure.FinishedAt
+}
@@ -36,6 +36,7 @@ import (
 	"github.com/cortexlabs/cortex/pkg/operator/resources/job/taskapi"
 	"github.com/cortexlabs/cortex/pkg/operator/resources/realtimeapi"
 	"github.com/cortexlabs/cortex/pkg/operator/resources/trafficsplitter"
+	"github.com/cortexlabs/cortex/pkg/operator/resources/workflow"
 	"github.com/cortexlabs/cortex/pkg/operator/schema"
 	"github.com/cortexlabs/cortex/pkg/types/spec"
 	"github.com/cortexlabs/cortex/pkg/types/userconfig"
@@ -83,7 +84,10 @@ func GetDeployedResourceByNameOrNil(resourceName string) (*operator.DeployedReso
 	}, nil
 }
 
-func Deploy(configFileName string, configBytes []byte, force bool) ([]schema.DeployResult, error) {
+// Deploy deploys the apis in dependency order; if project is true and an api fails to be submitted, the remaining apis are not deployed,
+// and the apis which were already changed are rolled back to their previous specs (or deleted if they are new). The rollout of the apis
+// is not tracked, so an api which is submitted successfully but whose replicas fail to become ready does not trigger a rollback
+func Deploy(configFileName string, configBytes []byte, force bool, project bool) ([]schema.DeployResult, error) {
 	apiConfigs, err := spec.ExtractAPIConfigs(configBytes, configFileName)
 	if err != nil {
 		return nil, err
@@ -95,30 +99,186 @@ func Deploy(configFileName string, configBytes []byte, force bool) ([]schema.Dep
 		return nil, err
 	}
 
-	// This is done if user specifies RealtimeAPIs in same file as TrafficSplitter
-	apiConfigs = append(ExclusiveFilterAPIsByKind(apiConfigs, userconfig.TrafficSplitterKind), InclusiveFilterAPIsByKind(apiConfigs, userconfig.TrafficSplitterKind)...)
+	budgetWarnings, err := getBudgetWarnings(apiConfigs)
+	if err != nil {
+		return nil, err
+	}
+
+	// e.g. traffic splitters are deployed after the apis in the same file which they route to
+	apiConfigs, err = spec.SortAPIsByDependencies(apiConfigs)
+	if err != nil {
+		return nil, err
+	}
+
+	var previousAPIs map[string]*spec.API
+	if project {
+		previousAPIs, err = getPreviousAPIs(apiConfigs)
+		if err != nil {
+			return nil, err
+		}
+	}
 
 	results := make([]schema.DeployResult, 0, len(apiConfigs))
 	for i := range apiConfigs {
 		apiConfig := apiConfigs[i]
 
+		// the diff is only informational (e.g. for the audit log), so it must not prevent the deployment
+		diffs, isNew, diffErr := diffAPI(&apiConfig)
+
 		api, msg, err := UpdateAPI(&apiConfig, force)
 
 		result := schema.DeployResult{
-			Message: msg,
-			API:     api,
+			Resource: apiConfig.Resource,
+			Message:  msg,
+			API:      api,
+		}
+		if diffErr == nil {
+			result.IsNew = isNew
+			result.Diffs = diffs
 		}
 
 		if err != nil {
 			result.Error = errors.ErrorStr(err)
+		} else if warnings := budgetWarnings[apiConfig.Name]; len(warnings) > 0 {
+			result.Warnings = warnings
+			for _, warning := range warnings {
+				operatorLogger.Warn(warning)
+			}
 		}
 
 		results = append(results, result)
+
+		if err != nil && project {
+			return rollbackProject(apiConfigs, results, previousAPIs), nil
+		}
 	}
 
 	return results, nil
 }
 
+// getPreviousAPIs downloads the specs of the apis which are currently deployed, so that they can be restored if the project fails to deploy
+func getPreviousAPIs(apiConfigs []userconfig.API) (map[string]*spec.API, error) {
+	previousAPIs := map[string]*spec.API{}
+	for i := range apiConfigs {
+		deployedResource, err := GetDeployedResourceByNameOrNil(apiConfigs[i].Name)
+		if err != nil {
+			return nil, err
+		}
+		if deployedResource == nil {
+			continue
+		}
+
+		previousAPI, err := operator.DownloadAPISpec(deployedResource.Name, deployedResource.ID())
+		if err != nil {
+			return nil, err
+		}
+		previousAPIs[deployedResource.Name] = previousAPI
+	}
+	return previousAPIs, nil
+}
+
+// rollbackProject is called after the last api in results failed to deploy; the apis which were changed before it are rolled back in reverse order
+// (so that e.g. traffic splitters are restored before the apis which they route to), and the apis after it are reported as not deployed;
+// apis whose specs were not changed by the deployment are left as they are
+func rollbackProject(apiConfigs []userconfig.API, results []schema.DeployResult, previousAPIs map[string]*spec.API) []schema.DeployResult {
+	failedAPIName := results[len(results)-1].Resource.Name
+
+	for i := len(results) - 2; i >= 0; i-- {
+		apiName := results[i].Resource.Name
+		previousAPI, wasDeployed := previousAPIs[apiName]
+
+		if wasDeployed && results[i].API != nil && results[i].API.Spec.ID == previousAPI.ID {
+			continue
+		}
+
+		var err error
+		if wasDeployed {
+			_, _, err = UpdateAPI(previousAPI.API, true)
+		} else {
+			_, err = DeleteAPI(apiName, false)
+		}
+
+		results[i].API = nil
+		results[i].Diffs = nil
+		if err != nil {
+			results[i].Error = errors.ErrorStr(errors.Wrap(err, "unable to roll back after "+failedAPIName+" failed to deploy"))
+		} else {
+			results[i].Error = errors.ErrorStr(ErrorProjectDeployFailed(failedAPIName, true))
+		}
+	}
+
+	for i := len(results); i < len(apiConfigs); i++ {
+		results = append(results, schema.DeployResult{
+			Resource: apiConfigs[i].Resource,
+			Error:    errors.ErrorStr(ErrorProjectDeployFailed(failedAPIName, false)),
+		})
+	}
+
+	return results
+}
+
+// DeployDiff validates the api configurations and compares them to the deployed apis, without applying any changes
+func DeployDiff(configFileName string, configBytes []byte) ([]schema.DeployDiffResult, error) {
+	apiConfigs, err := spec.ExtractAPIConfigs(configBytes, configFileName)
+	if err != nil {
+		return nil, err
+	}
+
+	err = ValidateClusterAPIs(apiConfigs)
+	if err != nil {
+		err = errors.Append(err, fmt.Sprintf("\n\napi configuration schema can be found at https://docs.cortex.dev/v/%s/", consts.CortexVersionMinor))
+		return nil, err
+	}
+
+	results := make([]schema.DeployDiffResult, 0, len(apiConfigs))
+	for i := range apiConfigs {
+		apiConfig := apiConfigs[i]
+
+		result := schema.DeployDiffResult{
+			Resource: apiConfig.Resource,
+		}
+
+		diffs, isNew, err := diffAPI(&apiConfig)
+		if err != nil {
+			result.Error = errors.ErrorStr(err)
+		} else {
+			result.Diffs = diffs
+			result.IsNew = isNew
+		}
+
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
+func diffAPI(apiConfig *userconfig.API) ([]spec.FieldDiff, bool, error) {
+	deployedResource, err := GetDeployedResourceByNameOrNil(apiConfig.Name)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if deployedResource == nil {
+		return nil, true, nil
+	}
+
+	if deployedResource.Kind != apiConfig.Kind {
+		return nil, false, ErrorCannotChangeKindOfDeployedAPI(apiConfig.Name, apiConfig.Kind, deployedResource.Kind)
+	}
+
+	deployedAPI, err := operator.DownloadAPISpec(deployedResource.Name, deployedResource.ID())
+	if err != nil {
+		return nil, false, err
+	}
+
+	diffs, err := spec.DiffAPIConfigs(deployedAPI.API, apiConfig)
+	if err != nil {
+		return nil, false, err
+	}
+
+	return diffs, false, nil
+}
+
 func UpdateAPI(apiConfig *userconfig.API, force bool) (*schema.APIResponse, string, error) {
 	deployedResource, err := GetDeployedResourceByNameOrNil(apiConfig.Name)
 	if err != nil {
@@ -131,6 +291,20 @@ func UpdateAPI(apiConfig *userconfig.API, force bool) (*schema.APIResponse, stri
 
 	telemetry.Event("operator.deploy", apiConfig.TelemetryEvent())
 
+	if err := operator.ApplyAPICIDRWhiteList(apiConfig); err != nil {
+		return nil, "", err
+	}
+
+	if err := operator.ApplyAPIAccessLogs(apiConfig); err != nil {
+		return nil, "", err
+	}
+
+	if apiConfig.Kind != userconfig.TrafficSplitterKind && apiConfig.Kind != userconfig.WorkflowKind {
+		if err := operator.ApplyAPIAlertRules(apiConfig); err != nil {
+			return nil, "", err
+		}
+	}
+
 	var api *spec.API
 	var msg string
 	switch apiConfig.Kind {
@@ -144,6 +318,8 @@ func UpdateAPI(apiConfig *userconfig.API, force bool) (*schema.APIResponse, stri
 		api, msg, err = asyncapi.UpdateAPI(*apiConfig, force)
 	case userconfig.TrafficSplitterKind:
 		api, msg, err = trafficsplitter.UpdateAPI(apiConfig)
+	case userconfig.WorkflowKind:
+		api, msg, err = workflow.UpdateAPI(apiConfig)
 	default:
 		return nil, "", ErrorOperationIsOnlySupportedForKind(
 			*deployedResource, userconfig.RealtimeAPIKind,
@@ -151,6 +327,7 @@ func UpdateAPI(apiConfig *userconfig.API, force bool) (*schema.APIResponse, stri
 			userconfig.BatchAPIKind,
 			userconfig.TrafficSplitterKind,
 			userconfig.TaskAPIKind,
+			userconfig.WorkflowKind,
 		) // unexpected
 	}
 
@@ -204,6 +381,27 @@ func DeleteAPI(apiName string, keepCache bool) (*schema.DeleteResponse, error) {
 				func() error {
 					return asyncapi.DeleteAPI(apiName, keepCache)
 				},
+				func() error {
+					return workflow.DeleteAPI(apiName, keepCache)
+				},
+				func() error {
+					return operator.DeleteAPISecrets(apiName)
+				},
+				func() error {
+					return operator.DeleteAPIRegistryCredentials(apiName)
+				},
+				func() error {
+					return operator.DeleteAPIRuntimeConfig(apiName)
+				},
+				func() error {
+					return operator.DeleteAPIAlertRules(apiName)
+				},
+				func() error {
+					return operator.DeleteAPICIDRWhiteList(apiName)
+				},
+				func() error {
+					return operator.DeleteAPIAccessLogs(apiName)
+				},
 			)
 			if err != nil {
 				telemetry.Error(err)
@@ -233,7 +431,11 @@ func DeleteAPI(apiName string, keepCache bool) (*schema.DeleteResponse, error) {
 			return nil, err
 		}
 	case userconfig.TaskAPIKind:
-		err := taskapi.DeleteAPI(apiName, keepCache)
+		err := checkIfUsedByWorkflow(apiName)
+		if err != nil {
+			return nil, err
+		}
+		err = taskapi.DeleteAPI(apiName, keepCache)
 		if err != nil {
 			return nil, err
 		}
@@ -242,8 +444,37 @@ func DeleteAPI(apiName string, keepCache bool) (*schema.DeleteResponse, error) {
 		if err != nil {
 			return nil, err
 		}
+	case userconfig.WorkflowKind:
+		err := workflow.DeleteAPI(apiName, keepCache)
+		if err != nil {
+			return nil, err
+		}
 	default:
-		return nil, ErrorOperationIsOnlySupportedForKind(*deployedResource, userconfig.RealtimeAPIKind, userconfig.AsyncAPIKind, userconfig.BatchAPIKind, userconfig.TrafficSplitterKind) // unexpected
+		return nil, ErrorOperationIsOnlySupportedForKind(*deployedResource, userconfig.RealtimeAPIKind, userconfig.AsyncAPIKind, userconfig.BatchAPIKind, userconfig.TrafficSplitterKind, userconfig.WorkflowKind) // unexpected
+	}
+
+	if err := operator.DeleteAPISecrets(apiName); err != nil {
+		return nil, err
+	}
+
+	if err := operator.DeleteAPIRegistryCredentials(apiName); err != nil {
+		return nil, err
+	}
+
+	if err := operator.DeleteAPIRuntimeConfig(apiName); err != nil {
+		return nil, err
+	}
+
+	if err := operator.DeleteAPIAlertRules(apiName); err != nil {
+		return nil, err
+	}
+
+	if err := operator.DeleteAPICIDRWhiteList(apiName); err != nil {
+		return nil, err
+	}
+
+	if err := operator.DeleteAPIAccessLogs(apiName); err != nil {
+		return nil, err
 	}
 
 	return &schema.DeleteResponse{
@@ -326,6 +557,7 @@ func GetAPIs() ([]schema.APIResponse, error) {
 	var batchAPIVirtualServices []istioclientnetworking.VirtualService
 	var taskAPIVirtualServices []istioclientnetworking.VirtualService
 	var trafficSplitterVirtualServices []istioclientnetworking.VirtualService
+	var workflowVirtualServices []istioclientnetworking.VirtualService
 
 	for _, vs := range virtualServices {
 		switch vs.Labels["apiKind"] {
@@ -335,6 +567,8 @@ func GetAPIs() ([]schema.APIResponse, error) {
 			trafficSplitterVirtualServices = append(trafficSplitterVirtualServices, vs)
 		case userconfig.TaskAPIKind.String():
 			taskAPIVirtualServices = append(taskAPIVirtualServices, vs)
+		case userconfig.WorkflowKind.String():
+			workflowVirtualServices = append(workflowVirtualServices, vs)
 		}
 	}
 
@@ -364,13 +598,19 @@ func GetAPIs() ([]schema.APIResponse, error) {
 		return nil, err
 	}
 
-	response := make([]schema.APIResponse, 0, len(realtimeAPIList)+len(batchAPIList)+len(trafficSplitterList))
+	workflowList, err := workflow.GetAllAPIs(workflowVirtualServices)
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]schema.APIResponse, 0, len(realtimeAPIList)+len(batchAPIList)+len(taskAPIList)+len(asyncAPIList)+len(trafficSplitterList)+len(workflowList))
 
 	response = append(response, realtimeAPIList...)
 	response = append(response, batchAPIList...)
 	response = append(response, taskAPIList...)
 	response = append(response, asyncAPIList...)
 	response = append(response, trafficSplitterList...)
+	response = append(response, workflowList...)
 
 	return response, nil
 }
@@ -409,12 +649,17 @@ func GetAPI(apiName string) ([]schema.APIResponse, error) {
 		if err != nil {
 			return nil, err
 		}
+	case userconfig.WorkflowKind:
+		apiResponse, err = workflow.GetAPIByName(deployedResource)
+		if err != nil {
+			return nil, err
+		}
 	default:
 		return nil, ErrorOperationIsOnlySupportedForKind(
 			*deployedResource,
 			userconfig.RealtimeAPIKind, userconfig.BatchAPIKind,
 			userconfig.TaskAPIKind, userconfig.TrafficSplitterKind,
-			userconfig.AsyncAPIKind,
+			userconfig.AsyncAPIKind, userconfig.WorkflowKind,
 		) // unexpected
 	}
 
@@ -424,6 +669,19 @@ func GetAPI(apiName string) ([]schema.APIResponse, error) {
 		if err != nil {
 			return nil, err
 		}
+
+		pods, err := config.K8s.ListPodsByLabel("apiName", deployedResource.Name)
+		if err != nil {
+			return nil, err
+		}
+
+		apiResponse[0].Events, err = getAPIEvents(deployedResource.Name, pods)
+		if err != nil {
+			return nil, err
+		}
+
+		apiResponse[0].ReplicaFailures = getReplicaFailures(pods)
+		apiResponse[0].Verification = getArtifactVerification(apiResponse[0].Spec, pods)
 	}
 
 	return apiResponse, nil
@@ -498,3 +756,15 @@ func checkIfUsedByTrafficSplitter(apiName string) error {
 	}
 	return nil
 }
+
+// checkIfUsedByWorkflow checks if the task api is used by a step of a deployed workflow
+func checkIfUsedByWorkflow(apiName string) error {
+	workflows, err := workflow.UsedByWorkflows(apiName)
+	if err != nil {
+		return err
+	}
+	if len(workflows) > 0 {
+		return ErrorAPIUsedByWorkflow(workflows)
+	}
+	return nil
+}
@@ -0,0 +1,273 @@
+/*
+Copyright 2021 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/cortexlabs/cortex/pkg/config"
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/lib/parallel"
+	"github.com/cortexlabs/cortex/pkg/operator/schema"
+	"github.com/cortexlabs/cortex/pkg/types/userconfig"
+	"github.com/cortexlabs/cortex/pkg/workloads"
+	promv1 "github.com/prometheus/client_golang/api/prometheus/v1"
+	"github.com/prometheus/common/model"
+)
+
+const (
+	DefaultTimeSeriesWindow = time.Hour
+	MaxTimeSeriesWindow     = 14 * 24 * time.Hour // prometheus' retention
+	MinTimeSeriesStep       = 15 * time.Second    // the shortest scrape interval
+	MaxTimeSeriesPoints     = 1000
+
+	_timeSeriesMinRateWindow  = time.Minute // rates are computed over at least this duration, so that they include a few scrapes
+	_timeSeriesRequestTimeout = 30 * time.Second
+)
+
+type timeSeriesQuery struct {
+	metric string
+	unit   string
+	query  string
+}
+
+// GetTimeSeries queries prometheus for the api's request rate, latency percentiles, error rate, in-flight requests, replica counts,
+// and (for async apis) queue depth over the window ending now, sampled at each step
+func GetTimeSeries(apiName string, window time.Duration, step time.Duration) (*schema.TimeSeriesResponse, error) {
+	deployedResource, err := GetDeployedResourceByName(apiName)
+	if err != nil {
+		return nil, err
+	}
+
+	var queries []timeSeriesQuery
+	switch deployedResource.Kind {
+	case userconfig.RealtimeAPIKind:
+		queries = realtimeTimeSeriesQueries(apiName, step)
+	case userconfig.AsyncAPIKind:
+		queries = asyncTimeSeriesQueries(apiName, step)
+	default:
+		return nil, ErrorOperationIsOnlySupportedForKind(*deployedResource, userconfig.RealtimeAPIKind, userconfig.AsyncAPIKind)
+	}
+
+	end := time.Now().Truncate(step)
+	queryRange := promv1.Range{
+		Start: end.Add(-window),
+		End:   end,
+		Step:  step,
+	}
+
+	response := schema.TimeSeriesResponse{
+		APIName: apiName,
+		Kind:    deployedResource.Kind.String(),
+		Start:   queryRange.Start,
+		End:     queryRange.End,
+		Step:    step.String(),
+		Series:  make([]schema.TimeSeries, len(queries)),
+	}
+
+	fns := make([]func() error, len(queries))
+	for i := range queries {
+		localIdx := i
+		fns[i] = func() error {
+			points, err := queryTimeSeries(queries[localIdx].query, queryRange)
+			if err != nil {
+				return err
+			}
+			response.Series[localIdx] = schema.TimeSeries{
+				Metric: queries[localIdx].metric,
+				Unit:   queries[localIdx].unit,
+				Points: points,
+			}
+			return nil
+		}
+	}
+	if err := parallel.RunFirstErr(fns[0], fns[1:]...); err != nil {
+		return nil, err
+	}
+
+	return &response, nil
+}
+
+// requests are measured by istio at the api's service
+func realtimeTimeSeriesQueries(apiName string, step time.Duration) []timeSeriesQuery {
+	rateWindow := timeSeriesRateWindowSeconds(step)
+
+	queries := []timeSeriesQuery{
+		{
+			metric: "requests_per_second",
+			unit:   "requests/s",
+			query: fmt.Sprintf(
+				"sum(rate(istio_requests_total{destination_service_name=~\"api-%s.+\"}[%ds]))",
+				apiName, rateWindow,
+			),
+		},
+		{
+			metric: "error_rate",
+			unit:   "ratio",
+			query: fmt.Sprintf(
+				"(sum(rate(istio_requests_total{destination_service_name=~\"api-%s.+\", response_code=~\"5.*\"}[%ds])) or vector(0)) "+
+					"/ sum(rate(istio_requests_total{destination_service_name=~\"api-%s.+\"}[%ds]))",
+				apiName, rateWindow, apiName, rateWindow,
+			),
+		},
+	}
+
+	for _, quantile := range []int{50, 90, 99} {
+		queries = append(queries, timeSeriesQuery{
+			metric: fmt.Sprintf("latency_p%d", quantile),
+			unit:   "ms",
+			query: fmt.Sprintf(
+				"histogram_quantile(%g, sum(rate(istio_request_duration_milliseconds_bucket{destination_service_name=~\"api-%s.+\"}[%ds])) by (le))",
+				float64(quantile)/100, apiName, rateWindow,
+			),
+		})
+	}
+
+	queries = append(queries, timeSeriesQuery{
+		metric: "in_flight_requests",
+		unit:   "requests",
+		query:  fmt.Sprintf("sum(cortex_in_flight_requests{api_name=\"%s\"})", apiName),
+	})
+
+	return append(queries, replicaTimeSeriesQueries(apiName)...)
+}
+
+// requests are measured by the dequeuer as it processes them (the api's service only receives submissions, which are measured by the gateway)
+func asyncTimeSeriesQueries(apiName string, step time.Duration) []timeSeriesQuery {
+	rateWindow := timeSeriesRateWindowSeconds(step)
+
+	queries := []timeSeriesQuery{
+		{
+			metric: "requests_per_second",
+			unit:   "requests/s",
+			query: fmt.Sprintf(
+				"sum(rate(cortex_dequeuer_messages_processed_total{api_name=\"%s\"}[%ds]))",
+				apiName, rateWindow,
+			),
+		},
+		{
+			metric: "submissions_per_second",
+			unit:   "requests/s",
+			query: fmt.Sprintf(
+				"sum(rate(cortex_async_gateway_submissions_total{api_name=\"%s\"}[%ds]))",
+				apiName, rateWindow,
+			),
+		},
+		{
+			metric: "error_rate",
+			unit:   "ratio",
+			query: fmt.Sprintf(
+				"(sum(rate(cortex_dequeuer_messages_failed_total{api_name=\"%s\"}[%ds])) or vector(0)) "+
+					"/ sum(rate(cortex_dequeuer_messages_processed_total{api_name=\"%s\"}[%ds]))",
+				apiName, rateWindow, apiName, rateWindow,
+			),
+		},
+	}
+
+	for _, quantile := range []int{50, 90, 99} {
+		queries = append(queries, timeSeriesQuery{
+			metric: fmt.Sprintf("latency_p%d", quantile),
+			unit:   "ms",
+			query: fmt.Sprintf(
+				"1000 * histogram_quantile(%g, sum(rate(cortex_dequeuer_processing_latency_seconds_bucket{api_name=\"%s\"}[%ds])) by (le))",
+				float64(quantile)/100, apiName, rateWindow,
+			),
+		})
+	}
+
+	queries = append(queries,
+		timeSeriesQuery{
+			metric: "in_flight_requests",
+			unit:   "requests",
+			query:  fmt.Sprintf("sum(cortex_dequeuer_messages_in_flight{api_name=\"%s\"})", apiName),
+		},
+		timeSeriesQuery{
+			metric: "queue_length",
+			unit:   "messages",
+			query:  fmt.Sprintf("sum(cortex_async_queue_length{api_name=\"%s\"})", apiName),
+		},
+		timeSeriesQuery{
+			metric: "queue_oldest_message_age",
+			unit:   "s",
+			query:  fmt.Sprintf("max(cortex_async_queue_oldest_message_age_seconds{api_name=\"%s\"})", apiName),
+		},
+	)
+
+	return append(queries, replicaTimeSeriesQueries(apiName)...)
+}
+
+// replica counts are measured by kube-state-metrics
+func replicaTimeSeriesQueries(apiName string) []timeSeriesQuery {
+	deploymentName := workloads.K8sName(apiName)
+
+	return []timeSeriesQuery{
+		{
+			metric: "replicas_requested",
+			unit:   "replicas",
+			query:  fmt.Sprintf("sum(kube_deployment_spec_replicas{deployment=\"%s\"})", deploymentName),
+		},
+		{
+			metric: "replicas_available",
+			unit:   "replicas",
+			query:  fmt.Sprintf("sum(kube_deployment_status_replicas_available{deployment=\"%s\"})", deploymentName),
+		},
+	}
+}
+
+func timeSeriesRateWindowSeconds(step time.Duration) int64 {
+	if step < _timeSeriesMinRateWindow {
+		return int64(_timeSeriesMinRateWindow.Seconds())
+	}
+	return int64(step.Seconds())
+}
+
+// returns the points of the query's (single) series, omitting steps without data (e.g. the error rate while there aren't any requests)
+func queryTimeSeries(query string, queryRange promv1.Range) ([]schema.TimeSeriesPoint, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), _timeSeriesRequestTimeout)
+	defer cancel()
+
+	valuesQuery, _, err := config.Prometheus.QueryRange(ctx, query, queryRange)
+	if err != nil {
+		return nil, err
+	}
+
+	values, ok := valuesQuery.(model.Matrix)
+	if !ok {
+		return nil, errors.ErrorUnexpected("failed to convert metric to matrix")
+	}
+
+	points := []schema.TimeSeriesPoint{}
+	if values.Len() == 0 {
+		return points, nil
+	}
+
+	for _, sample := range values[0].Values {
+		value := float64(sample.Value)
+		if math.IsNaN(value) || math.IsInf(value, 0) {
+			continue
+		}
+		points = append(points, schema.TimeSeriesPoint{
+			Time:  sample.Timestamp.Time(),
+			Value: value,
+		})
+	}
+
+	return points, nil
+}
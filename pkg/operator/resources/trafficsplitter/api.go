@@ -19,6 +19,8 @@ package trafficsplitter
 import (
 	"fmt"
 	"path/filepath"
+	"regexp"
+	"strings"
 
 	"github.com/cortexlabs/cortex/pkg/config"
 	"github.com/cortexlabs/cortex/pkg/consts"
@@ -110,16 +112,74 @@ func applyK8sVirtualService(trafficSplitter *spec.API, prevVirtualService *istio
 func getTrafficSplitterDestinations(trafficSplitter *spec.API) []k8s.Destination {
 	destinations := make([]k8s.Destination, len(trafficSplitter.APIs))
 	for i, api := range trafficSplitter.APIs {
-		destinations[i] = k8s.Destination{
-			ServiceName: workloads.K8sName(api.Name),
-			Weight:      api.Weight,
-			Port:        uint32(consts.ProxyListeningPortInt32),
-			Shadow:      api.Shadow,
-		}
+		destinations[i] = trafficSplitterDestination(trafficSplitter, api.Name, api.Weight, api.Shadow)
 	}
 	return destinations
 }
 
+// getSessionAffinityRoutes returns the routes which send the requests of clients that are pinned to an api (by the
+// session affinity cookie or header) to that api; all other requests are routed according to the weights
+func getSessionAffinityRoutes(trafficSplitter *spec.API) []k8s.HeaderRoute {
+	sessionAffinity := trafficSplitter.SessionAffinity
+	if sessionAffinity == nil {
+		return nil
+	}
+
+	var headerRoutes []k8s.HeaderRoute
+	for _, api := range trafficSplitter.APIs {
+		if api.Shadow {
+			continue
+		}
+
+		destination := trafficSplitterDestination(trafficSplitter, api.Name, 100, false)
+
+		if sessionAffinity.Cookie != nil {
+			headerRoutes = append(headerRoutes, k8s.HeaderRoute{
+				Headers: map[string]string{
+					"cookie": fmt.Sprintf(`^(.*;\s*)?%s=%s(;.*)?$`, regexp.QuoteMeta(*sessionAffinity.Cookie), regexp.QuoteMeta(api.Name)),
+				},
+				Destination: destination,
+			})
+		}
+
+		if sessionAffinity.Header != nil {
+			headerRoutes = append(headerRoutes, k8s.HeaderRoute{
+				Headers: map[string]string{
+					strings.ToLower(*sessionAffinity.Header): fmt.Sprintf(`^%s$`, regexp.QuoteMeta(api.Name)),
+				},
+				Destination: destination,
+			})
+		}
+	}
+
+	return headerRoutes
+}
+
+func trafficSplitterDestination(trafficSplitter *spec.API, apiName string, weight int32, shadow bool) k8s.Destination {
+	destination := k8s.Destination{
+		ServiceName: workloads.K8sName(apiName),
+		Weight:      weight,
+		Port:        uint32(consts.ProxyListeningPortInt32),
+		Shadow:      shadow,
+		RequestHeaders: map[string]string{
+			consts.TrafficSplitterHeader: trafficSplitter.Name,
+			consts.VariantHeader:         apiName,
+		},
+		ResponseHeaders: map[string]string{
+			consts.VariantHeader: apiName,
+		},
+	}
+
+	if trafficSplitter.SessionAffinity != nil && trafficSplitter.SessionAffinity.Cookie != nil {
+		// (re)sets the cookie on every response, so that the cookie expires after the ttl of inactivity
+		destination.AddedResponseHeaders = map[string]string{
+			"Set-Cookie": fmt.Sprintf("%s=%s; Path=/; Max-Age=%d", *trafficSplitter.SessionAffinity.Cookie, apiName, int64(trafficSplitter.SessionAffinity.TTL.Seconds())),
+		}
+	}
+
+	return destination
+}
+
 // GetAllAPIs returns a list of metadata, in the form of schema.APIResponse, about all the created traffic splitter APIs
 func GetAllAPIs(virtualServices []istioclientnetworking.VirtualService) ([]schema.APIResponse, error) {
 	var (
@@ -168,10 +228,16 @@ func GetAPIByName(deployedResource *operator.DeployedResource) ([]schema.APIResp
 		return nil, err
 	}
 
+	variantMetrics, err := GetVariantMetrics(api)
+	if err != nil {
+		return nil, err
+	}
+
 	return []schema.APIResponse{
 		{
-			Spec:     *api,
-			Endpoint: endpoint,
+			Spec:           *api,
+			Endpoint:       endpoint,
+			VariantMetrics: variantMetrics,
 		},
 	}, nil
 }
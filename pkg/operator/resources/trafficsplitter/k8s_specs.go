@@ -29,15 +29,16 @@ func virtualServiceSpec(trafficSplitter *spec.API) *istioclientnetworking.Virtua
 		Name:         workloads.K8sName(trafficSplitter.Name),
 		Gateways:     []string{"apis-gateway"},
 		Destinations: getTrafficSplitterDestinations(trafficSplitter),
+		HeaderRoutes: getSessionAffinityRoutes(trafficSplitter),
 		ExactPath:    trafficSplitter.Networking.Endpoint,
 		Rewrite:      pointer.String("/"),
 		Annotations:  trafficSplitter.ToK8sAnnotations(),
-		Labels: map[string]string{
+		Labels: workloads.WithUserLabels(trafficSplitter.API, map[string]string{
 			"apiName":        trafficSplitter.Name,
 			"apiKind":        trafficSplitter.Kind.String(),
 			"apiID":          trafficSplitter.ID,
 			"specID":         trafficSplitter.SpecID,
 			"cortex.dev/api": "true",
-		},
+		}),
 	})
 }
@@ -0,0 +1,129 @@
+/*
+Copyright 2021 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package trafficsplitter
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/cortexlabs/cortex/pkg/config"
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/lib/parallel"
+	"github.com/cortexlabs/cortex/pkg/types/metrics"
+	"github.com/cortexlabs/cortex/pkg/types/spec"
+	"github.com/prometheus/common/model"
+)
+
+const (
+	_metricsWindowHours    = 336 // 2 weeks
+	_metricsRequestTimeout = 10  // seconds
+)
+
+// GetVariantMetrics returns the metrics of the requests which were routed by the traffic splitter, for each of its (non-shadow) apis;
+// unlike the apis' own metrics, requests which were sent to the apis directly are not included
+func GetVariantMetrics(trafficSplitter *spec.API) ([]metrics.Metrics, error) {
+	var countValues model.Vector
+	var latencyValues model.Vector
+
+	err := parallel.RunFirstErr(
+		func() error {
+			var err error
+			countValues, err = queryPrometheusVec(fmt.Sprintf(
+				"sum(increase(cortex_variant_requests_total{traffic_splitter=\"%s\"}[%dh])) by (api_name, response_code)",
+				trafficSplitter.Name, _metricsWindowHours,
+			))
+			return err
+		},
+		func() error {
+			var err error
+			latencyValues, err = queryPrometheusVec(fmt.Sprintf(
+				"sum(rate(cortex_variant_request_duration_seconds_sum{traffic_splitter=\"%s\"}[%dh])) by (api_name) "+
+					"/ sum(rate(cortex_variant_request_duration_seconds_count{traffic_splitter=\"%s\"}[%dh])) by (api_name)",
+				trafficSplitter.Name, _metricsWindowHours,
+				trafficSplitter.Name, _metricsWindowHours,
+			))
+			return err
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	networkStats := map[string]*metrics.NetworkStats{}
+	var variantMetrics []metrics.Metrics
+	for _, api := range trafficSplitter.APIs {
+		if api.Shadow {
+			continue
+		}
+		networkStats[api.Name] = &metrics.NetworkStats{}
+		variantMetrics = append(variantMetrics, metrics.Metrics{
+			APIName:      api.Name,
+			NetworkStats: networkStats[api.Name],
+		})
+	}
+
+	for _, sample := range countValues {
+		stats, ok := networkStats[string(sample.Metric["api_name"])]
+		if !ok || math.IsNaN(float64(sample.Value)) {
+			continue
+		}
+
+		count := int(math.Round(float64(sample.Value)))
+		responseCode := string(sample.Metric["response_code"])
+		switch {
+		case strings.HasPrefix(responseCode, "2"):
+			stats.Code2XX += count
+		case strings.HasPrefix(responseCode, "4"):
+			stats.Code4XX += count
+		case strings.HasPrefix(responseCode, "5"):
+			stats.Code5XX += count
+		}
+		stats.Total += count
+	}
+
+	for _, sample := range latencyValues {
+		stats, ok := networkStats[string(sample.Metric["api_name"])]
+		if !ok || math.IsNaN(float64(sample.Value)) {
+			continue
+		}
+
+		latency := float64(sample.Value) * 1000 // milliseconds, to match the apis' metrics
+		stats.Latency = &latency
+	}
+
+	return variantMetrics, nil
+}
+
+func queryPrometheusVec(query string) (model.Vector, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), _metricsRequestTimeout*time.Second)
+	defer cancel()
+
+	valuesQuery, _, err := config.Prometheus.Query(ctx, query, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	values, ok := valuesQuery.(model.Vector)
+	if !ok {
+		return nil, errors.ErrorUnexpected("failed to convert metric to vector")
+	}
+
+	return values, nil
+}
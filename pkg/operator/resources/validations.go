@@ -18,13 +18,18 @@ package resources
 
 import (
 	"fmt"
+	"math"
+	"time"
 
 	"github.com/cortexlabs/cortex/pkg/config"
+	"github.com/cortexlabs/cortex/pkg/lib/aws"
 	"github.com/cortexlabs/cortex/pkg/lib/errors"
 	"github.com/cortexlabs/cortex/pkg/lib/k8s"
 	"github.com/cortexlabs/cortex/pkg/lib/sets/strset"
+	"github.com/cortexlabs/cortex/pkg/lib/slices"
 	s "github.com/cortexlabs/cortex/pkg/lib/strings"
 	"github.com/cortexlabs/cortex/pkg/operator/operator"
+	"github.com/cortexlabs/cortex/pkg/types/clusterconfig"
 	"github.com/cortexlabs/cortex/pkg/types/spec"
 	"github.com/cortexlabs/cortex/pkg/types/userconfig"
 	istioclientnetworking "istio.io/client-go/pkg/apis/networking/v1beta1"
@@ -41,28 +46,51 @@ func ValidateClusterAPIs(apis []userconfig.API) error {
 		return err
 	}
 	httpDeployedRealtimeAPIs := strset.New()
+	deployedTaskAPIs := strset.New()
+	deployedAPIs := strset.New()
 	for _, virtualService := range virtualServices {
-		if virtualService.Labels["apiKind"] == userconfig.RealtimeAPIKind.String() {
+		switch virtualService.Labels["apiKind"] {
+		case userconfig.RealtimeAPIKind.String():
 			httpDeployedRealtimeAPIs.Add(virtualService.Labels["apiName"])
+		case userconfig.TaskAPIKind.String():
+			deployedTaskAPIs.Add(virtualService.Labels["apiName"])
 		}
+		deployedAPIs.Add(virtualService.Labels["apiName"])
 	}
 
 	realtimeAPIs := InclusiveFilterAPIsByKind(apis, userconfig.RealtimeAPIKind)
+	taskAPIs := InclusiveFilterAPIsByKind(apis, userconfig.TaskAPIKind)
 
 	for i := range apis {
 		api := &apis[i]
+		if len(api.Networking.CIDRWhiteList) > 0 && config.ClusterConfig.APILoadBalancerType == clusterconfig.ALBLoadBalancerType {
+			return errors.Wrap(ErrorCIDRWhiteListNotSupportedWithALB(), api.Identify(), userconfig.NetworkingKey, userconfig.CIDRWhiteListKey)
+		}
+
 		if api.Kind == userconfig.RealtimeAPIKind || api.Kind == userconfig.BatchAPIKind ||
 			api.Kind == userconfig.TaskAPIKind || api.Kind == userconfig.AsyncAPIKind {
 
-			if err := spec.ValidateAPI(api, config.AWS, config.K8s); err != nil {
+			if err := spec.ValidateAPI(api, config.ClusterConfig.RegistryCredentials, config.AWS, config.K8s); err != nil {
 				return errors.Wrap(err, api.Identify())
 			}
 
+			if api.Pod.EFS != nil && config.ClusterConfig.EFSFileSystemID == "" {
+				return errors.Wrap(ErrorEFSNotConfigured(), api.Identify(), userconfig.PodKey, userconfig.EFSKey)
+			}
+
+			if err := validateAlertReceiver(api); err != nil {
+				return errors.Wrap(err, api.Identify(), userconfig.AlertingKey, userconfig.ReceiverKey)
+			}
+
 			if err := validateEndpointCollisions(api, virtualServices); err != nil {
 				return err
 			}
 		}
 
+		if err := checkIfDependenciesExist(api.DependsOn, apis, deployedAPIs); err != nil {
+			return errors.Wrap(err, api.Identify(), userconfig.DependsOnKey)
+		}
+
 		if api.Kind == userconfig.TrafficSplitterKind {
 			if err := spec.ValidateTrafficSplitter(api); err != nil {
 				return errors.Wrap(err, api.Identify())
@@ -74,6 +102,18 @@ func ValidateClusterAPIs(apis []userconfig.API) error {
 				return errors.Wrap(err, api.Identify())
 			}
 		}
+
+		if api.Kind == userconfig.WorkflowKind {
+			if err := spec.ValidateWorkflow(api); err != nil {
+				return errors.Wrap(err, api.Identify(), userconfig.StepsKey)
+			}
+			if err := checkIfWorkflowAPIsExist(api.Steps, taskAPIs, deployedTaskAPIs); err != nil {
+				return errors.Wrap(err, api.Identify(), userconfig.StepsKey)
+			}
+			if err := validateEndpointCollisions(api, virtualServices); err != nil {
+				return errors.Wrap(err, api.Identify())
+			}
+		}
 	}
 
 	maxMemMap, err := operator.UpdateMemoryCapacityConfigMap()
@@ -83,13 +123,21 @@ func ValidateClusterAPIs(apis []userconfig.API) error {
 
 	for i := range apis {
 		api := &apis[i]
-		if api.Kind != userconfig.TrafficSplitterKind {
+		if api.Kind != userconfig.TrafficSplitterKind && api.Kind != userconfig.WorkflowKind {
 			if err := validateK8sCompute(api, maxMemMap); err != nil {
 				return err
 			}
 		}
 	}
 
+	if _, err := spec.SortAPIsByDependencies(apis); err != nil {
+		return err
+	}
+
+	if err := validatePreDeployHooksTimeout(apis); err != nil {
+		return err
+	}
+
 	dups := spec.FindDuplicateNames(apis)
 	if len(dups) > 0 {
 		return spec.ErrorDuplicateName(dups)
@@ -99,6 +147,14 @@ func ValidateClusterAPIs(apis []userconfig.API) error {
 		return spec.ErrorDuplicateEndpointInOneDeploy(dups)
 	}
 
+	if err := validateQuotas(apis, virtualServices); err != nil {
+		return err
+	}
+
+	if err := validateBudgets(apis, virtualServices); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -148,19 +204,45 @@ func validateK8sCompute(api *userconfig.API, maxMemMap map[string]kresource.Quan
 		}
 	}
 
-	compute := userconfig.GetTotalComputeFromContainers(api.Pod.Containers)
+	if api.Arch == aws.ArchARM64 && config.ClusterConfig.MTLS != nil {
+		return errors.Wrap(ErrorARMNotSupportedWithMTLS(), api.Identify(), userconfig.ArchKey)
+	}
+	if api.OS == aws.OSWindows && config.ClusterConfig.MTLS != nil {
+		return errors.Wrap(ErrorWindowsNotSupportedWithMTLS(), api.Identify(), userconfig.OSKey)
+	}
+
+	nodeGroups := config.ClusterConfig.GetNodeGroupsForAPI(apiNodeGroupNames, api.Arch, api.OS)
+	if len(nodeGroups) == 0 {
+		if api.OS == aws.OSWindows {
+			return errors.Wrap(ErrorNoWindowsNodeGroups(apiNodeGroupNames), api.Identify(), userconfig.OSKey)
+		}
+		return errors.Wrap(ErrorNoNodeGroupsWithArch(api.Arch, apiNodeGroupNames), api.Identify(), userconfig.ArchKey)
+	}
+
+	compute := userconfig.GetTotalComputeFromPod(api.Pod)
+
+	if compute.Inf > 0 {
+		if err := validateNeuronNodeGroups(compute, nodeGroups); err != nil {
+			return errors.Wrap(err, api.Identify())
+		}
+	}
+
+	if compute.GPU > 0 {
+		gpuNodeGroups, err := validateGPUNodeGroups(api.Pod, nodeGroups, apiNodeGroupNames)
+		if err != nil {
+			return errors.Wrap(err, api.Identify())
+		}
+		nodeGroups = gpuNodeGroups
+	}
+
+	instanceTypes := strset.New()
+	for _, ng := range nodeGroups {
+		instanceTypes.Add(ng.InstanceType)
+	}
 
 	for _, instanceMetadata := range config.InstancesMetadata {
-		if apiNodeGroupNames != nil {
-			matchedNodeGroups := 0
-			for _, ngName := range apiNodeGroupNames {
-				if config.ClusterConfig.GetNodeGroupByName(ngName).InstanceType == instanceMetadata.Type {
-					matchedNodeGroups++
-				}
-			}
-			if matchedNodeGroups == 0 {
-				continue
-			}
+		if !instanceTypes.Has(instanceMetadata.Type) {
+			continue
 		}
 
 		maxMemLoop := maxMemMap[instanceMetadata.Type]
@@ -193,8 +275,8 @@ func validateK8sCompute(api *userconfig.API, maxMemMap map[string]kresource.Quan
 		if compute.Mem != nil && maxMemLoop.Cmp(compute.Mem.Quantity) < 0 {
 			loopErrors = append(loopErrors, ErrorNoAvailableNodeComputeLimit("memory", compute.Mem.String(), maxMemLoop.String()))
 		}
-		if compute.GPU > maxGPU {
-			loopErrors = append(loopErrors, ErrorNoAvailableNodeComputeLimit("GPU", fmt.Sprintf("%d", compute.GPU), fmt.Sprintf("%d", maxGPU)))
+		if compute.GPU > float64(maxGPU) {
+			loopErrors = append(loopErrors, ErrorNoAvailableNodeComputeLimit("GPU", s.Round(compute.GPU, 4, 0), fmt.Sprintf("%d", maxGPU)))
 		}
 		if compute.Inf > maxInf {
 			loopErrors = append(loopErrors, ErrorNoAvailableNodeComputeLimit("Inf", fmt.Sprintf("%d", compute.Inf), fmt.Sprintf("%d", maxInf)))
@@ -213,6 +295,80 @@ func validateK8sCompute(api *userconfig.API, maxMemMap map[string]kresource.Quan
 	return nil
 }
 
+// the neuron resources of the api's containers depend on the Inferentia generation (only inf1 requires hugepages),
+// so an api which requests Inferentia/Trainium chips must only be able to run on one generation
+func validateNeuronNodeGroups(compute userconfig.Compute, nodeGroups []*clusterconfig.NodeGroup) error {
+	var inf1NodeGroups, neuronV2NodeGroups []string
+	for _, ng := range nodeGroups {
+		neuronCores, err := aws.NeuronCoresPerInf(ng.InstanceType)
+		if err != nil {
+			return err
+		}
+		if neuronCores == 0 {
+			continue
+		}
+
+		requiresHugePages, err := aws.RequiresNeuronHugePages(ng.InstanceType)
+		if err != nil {
+			return err
+		}
+		if requiresHugePages {
+			inf1NodeGroups = append(inf1NodeGroups, ng.Name)
+		} else {
+			neuronV2NodeGroups = append(neuronV2NodeGroups, ng.Name)
+		}
+	}
+
+	if len(inf1NodeGroups) > 0 && len(neuronV2NodeGroups) > 0 {
+		return ErrorMixedNeuronNodeGroups(inf1NodeGroups, neuronV2NodeGroups)
+	}
+
+	if len(inf1NodeGroups) > 0 && compute.Inf > 1 {
+		return spec.ErrorInvalidNumberOfInfs(compute.Inf)
+	}
+
+	return nil
+}
+
+// apis which request a fraction of a GPU can only run on node groups which share their GPUs (and apis which request whole GPUs can't),
+// and the fraction must be a whole number of the GPU replicas which the nvidia device plugin advertises on those node groups
+func validateGPUNodeGroups(pod *userconfig.Pod, nodeGroups []*clusterconfig.NodeGroup, apiNodeGroupNames []string) ([]*clusterconfig.NodeGroup, error) {
+	requestsFractionalGPUs := pod.RequestsFractionalGPUs()
+
+	var gpuNodeGroups []*clusterconfig.NodeGroup
+	for _, ng := range nodeGroups {
+		if ng.SharesGPUs() == requestsFractionalGPUs {
+			gpuNodeGroups = append(gpuNodeGroups, ng)
+		}
+	}
+	if len(gpuNodeGroups) == 0 {
+		return nil, ErrorNoNodeGroupsForGPURequest(requestsFractionalGPUs, apiNodeGroupNames)
+	}
+
+	if !requestsFractionalGPUs {
+		return gpuNodeGroups, nil
+	}
+
+	sharingFactor := gpuNodeGroups[0].GPUSharingFactor
+	for _, ng := range gpuNodeGroups[1:] {
+		if ng.GPUSharingFactor != sharingFactor {
+			return nil, ErrorMixedGPUSharingFactors(gpuNodeGroups[0].Name, sharingFactor, ng.Name, ng.GPUSharingFactor)
+		}
+	}
+
+	for _, container := range pod.Containers {
+		if container == nil || container.Compute == nil || container.Compute.GPU == 0 {
+			continue
+		}
+		sharedGPUs := container.Compute.GPU * float64(sharingFactor)
+		if math.Abs(sharedGPUs-math.Round(sharedGPUs)) > 1e-6 {
+			return nil, errors.Wrap(ErrorGPUNotMultipleOfSharingFactor(container.Compute.GPU, sharingFactor), userconfig.ContainersKey, container.Name, userconfig.ComputeKey, userconfig.GPUKey)
+		}
+	}
+
+	return gpuNodeGroups, nil
+}
+
 func validateEndpointCollisions(api *userconfig.API, virtualServices []istioclientnetworking.VirtualService) error {
 	for i := range virtualServices {
 		virtualService := virtualServices[i]
@@ -301,3 +457,82 @@ func checkIfAPIExists(trafficSplitterAPIs []*userconfig.TrafficSplit, apis []use
 	return nil
 
 }
+
+// checkIfWorkflowAPIsExist checks that the apis which the workflow's steps run on are task apis which are either defined in the same yaml or already deployed
+func checkIfWorkflowAPIsExist(steps []*userconfig.WorkflowStep, taskAPIs []userconfig.API, deployedTaskAPIs strset.Set) error {
+	missingAPIs := strset.New()
+	for _, step := range steps {
+		if deployedTaskAPIs.Has(step.API) {
+			continue
+		}
+		found := false
+		for i := range taskAPIs {
+			if taskAPIs[i].Name == step.API {
+				found = true
+			}
+		}
+		if !found {
+			missingAPIs.Add(step.API)
+		}
+	}
+	if len(missingAPIs) != 0 {
+		return ErrorTaskAPIsNotDeployed(missingAPIs.SliceSorted())
+	}
+	return nil
+}
+
+func checkIfDependenciesExist(dependencies []string, apis []userconfig.API, deployedAPIs strset.Set) error {
+	var missingAPIs []string
+	for _, dependency := range dependencies {
+		if deployedAPIs.Has(dependency) {
+			continue
+		}
+		found := false
+		for i := range apis {
+			if apis[i].Name == dependency {
+				found = true
+			}
+		}
+		if !found {
+			missingAPIs = append(missingAPIs, dependency)
+		}
+	}
+	if len(missingAPIs) != 0 {
+		return ErrorDependenciesNotDeployed(missingAPIs)
+	}
+	return nil
+}
+
+func validateAlertReceiver(api *userconfig.API) error {
+	if api.Alerting == nil || api.Alerting.Receiver == nil {
+		return nil
+	}
+
+	if config.ClusterConfig.Alerting == nil {
+		return ErrorAlertingNotConfigured()
+	}
+
+	receiverNames := config.ClusterConfig.Alerting.GetReceiverNames()
+	if !slices.HasString(receiverNames, *api.Alerting.Receiver) {
+		return ErrorInvalidAlertReceiver(*api.Alerting.Receiver, receiverNames)
+	}
+
+	return nil
+}
+
+// the pre-deploy hooks of the apis in a deployment run one after the other, so their total timeout must be less than the time which the cli waits for the deployment
+func validatePreDeployHooksTimeout(apis []userconfig.API) error {
+	var total time.Duration
+	var apiNames []string
+	for i := range apis {
+		if apis[i].Hooks != nil && apis[i].Hooks.PreDeploy != nil {
+			total += apis[i].Hooks.PreDeploy.Timeout
+			apiNames = append(apiNames, apis[i].Name)
+		}
+	}
+
+	if total > operator.MaxPreDeployHooksTimeout {
+		return ErrorPreDeployHooksTimeoutTooLong(apiNames, total, operator.MaxPreDeployHooksTimeout)
+	}
+	return nil
+}
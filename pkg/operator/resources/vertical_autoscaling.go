@@ -0,0 +1,129 @@
+/*
+Copyright 2021 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"time"
+
+	"github.com/cortexlabs/cortex/pkg/config"
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/lib/telemetry"
+	"github.com/cortexlabs/cortex/pkg/operator/operator"
+	"github.com/cortexlabs/cortex/pkg/types/userconfig"
+	"github.com/cortexlabs/cortex/pkg/workloads"
+	kapps "k8s.io/api/apps/v1"
+	kresource "k8s.io/apimachinery/pkg/api/resource"
+)
+
+const VerticalAutoscalingCronPeriod = time.Hour
+
+// UpdateVerticalAutoscaling chooses the cpu and memory requests of the containers which have vertical autoscaling enabled based on their usage,
+// and stores them on the apis' deployments; only the deployments' metadata is updated, so the requests take effect during the apis' next rollouts
+// (rather than restarting the apis' replicas while they are serving traffic)
+func UpdateVerticalAutoscaling() error {
+	deployments, err := config.K8s.ListDeploymentsWithLabelKeys("apiName")
+	if err != nil {
+		return err
+	}
+
+	for i := range deployments {
+		deployment := deployments[i]
+		apiKind := userconfig.KindFromString(deployment.Labels["apiKind"])
+		if apiKind != userconfig.RealtimeAPIKind && apiKind != userconfig.AsyncAPIKind {
+			continue
+		}
+		if deployment.Labels["cortex.dev/async"] == "gateway" {
+			continue
+		}
+
+		// an api whose usage can't be queried shouldn't prevent the other apis from being updated
+		if err := updateVerticalAutoscalingRequests(&deployment); err != nil {
+			telemetry.Error(err)
+			operatorLogger.Error(err)
+		}
+	}
+
+	return nil
+}
+
+func updateVerticalAutoscalingRequests(deployment *kapps.Deployment) error {
+	apiName := deployment.Labels["apiName"]
+	api, err := operator.DownloadAPISpec(apiName, deployment.Labels["apiID"])
+	if err != nil {
+		return err
+	}
+
+	var containers []*userconfig.Container
+	for _, container := range api.Pod.Containers {
+		if container.VerticalAutoscaling != nil {
+			containers = append(containers, container)
+		}
+	}
+	if len(containers) == 0 {
+		return nil
+	}
+
+	pods, err := config.K8s.ListPodsByLabel("apiName", apiName)
+	if err != nil {
+		return err
+	}
+
+	prevRequests := workloads.VerticalAutoscalingRequestsFromDeployment(deployment)
+	requests := workloads.VerticalAutoscalingRequests{}
+	for _, container := range containers {
+		recommendation, err := getContainerRecommendation(apiName, container, pods, DefaultRecommendationsWindow)
+		if err != nil {
+			return err
+		}
+
+		cpu, err := verticalAutoscalingTarget(recommendation.CPU.Recommended, container.Compute.CPU.Quantity)
+		if err != nil {
+			return err
+		}
+		mem, err := verticalAutoscalingTarget(recommendation.Mem.Recommended, container.Compute.Mem.Quantity)
+		if err != nil {
+			return err
+		}
+		cpu = workloads.ClampQuantity(cpu, container.VerticalAutoscaling.MinCPU.Quantity, container.VerticalAutoscaling.MaxCPU.Quantity)
+		mem = workloads.ClampQuantity(mem, container.VerticalAutoscaling.MinMem.Quantity, container.VerticalAutoscaling.MaxMem.Quantity)
+
+		requests[container.Name] = workloads.ContainerRequests{CPU: cpu.String(), Mem: mem.String()}
+	}
+
+	if prevRequests != nil && prevRequests.String() == requests.String() {
+		return nil
+	}
+
+	if deployment.Annotations == nil {
+		deployment.Annotations = map[string]string{}
+	}
+	deployment.Annotations[userconfig.VerticalAutoscalingAnnotationKey] = requests.String()
+
+	if _, err := config.K8s.UpdateDeployment(deployment); err != nil {
+		return errors.Wrap(err, apiName)
+	}
+
+	return nil
+}
+
+// the recommended request is used if the container's usage doesn't match its configured request, otherwise the configured request is used
+func verticalAutoscalingTarget(recommended *string, requested kresource.Quantity) (kresource.Quantity, error) {
+	if recommended == nil {
+		return requested.DeepCopy(), nil
+	}
+	return kresource.ParseQuantity(*recommended)
+}
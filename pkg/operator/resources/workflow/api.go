@@ -0,0 +1,198 @@
+/*
+Copyright 2021 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workflow
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/cortexlabs/cortex/pkg/config"
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/lib/parallel"
+	"github.com/cortexlabs/cortex/pkg/operator/lib/routines"
+	"github.com/cortexlabs/cortex/pkg/operator/operator"
+	"github.com/cortexlabs/cortex/pkg/operator/schema"
+	"github.com/cortexlabs/cortex/pkg/types/spec"
+	"github.com/cortexlabs/cortex/pkg/types/userconfig"
+	"github.com/cortexlabs/cortex/pkg/workloads"
+	istioclientnetworking "istio.io/client-go/pkg/apis/networking/v1beta1"
+)
+
+// the number of runs which are included when getting a single workflow
+const _numRecentRuns = 10
+
+// UpdateAPI creates or updates a workflow API kind
+func UpdateAPI(apiConfig *userconfig.API) (*spec.API, string, error) {
+	prevVirtualService, err := config.K8s.GetVirtualService(workloads.K8sName(apiConfig.Name))
+	if err != nil {
+		return nil, "", err
+	}
+
+	api := spec.GetAPISpec(apiConfig, "", config.ClusterConfig.ClusterUID)
+	if prevVirtualService == nil {
+		if err := config.AWS.UploadJSONToS3(api, config.ClusterConfig.Bucket, api.Key); err != nil {
+			return nil, "", errors.Wrap(err, "failed to upload api spec")
+		}
+
+		if err := applyK8sVirtualService(api, prevVirtualService); err != nil {
+			routines.RunWithPanicHandler(func() {
+				_ = deleteK8sResources(api.Name)
+			})
+			return nil, "", err
+		}
+
+		return api, fmt.Sprintf("created %s", api.Resource.UserString()), nil
+	}
+
+	// runs which are in progress keep using the spec with which they were submitted
+	if prevVirtualService.Labels["specID"] != api.SpecID {
+		if err := config.AWS.UploadJSONToS3(api, config.ClusterConfig.Bucket, api.Key); err != nil {
+			return nil, "", errors.Wrap(err, "failed to upload api spec")
+		}
+
+		if err := applyK8sVirtualService(api, prevVirtualService); err != nil {
+			return nil, "", err
+		}
+
+		return api, fmt.Sprintf("updated %s", api.Resource.UserString()), nil
+	}
+
+	return api, fmt.Sprintf("%s is up to date", api.Resource.UserString()), nil
+}
+
+// DeleteAPI deletes a workflow; the task jobs of its runs which are in progress are stopped
+func DeleteAPI(apiName string, keepCache bool) error {
+	// best effort, so that the steps of in progress runs don't keep running
+	_ = stopAllRuns(apiName)
+
+	err := parallel.RunFirstErr(
+		func() error {
+			return deleteK8sResources(apiName)
+		},
+		func() error {
+			if keepCache {
+				return nil
+			}
+			// best effort deletion
+			_ = deleteS3Resources(apiName)
+			return nil
+		},
+	)
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// GetAllAPIs returns a list of metadata, in the form of schema.APIResponse, about all the created workflows
+func GetAllAPIs(virtualServices []istioclientnetworking.VirtualService) ([]schema.APIResponse, error) {
+	var (
+		apiNames  []string
+		apiIDs    []string
+		workflows []schema.APIResponse
+	)
+
+	for _, virtualService := range virtualServices {
+		if virtualService.Labels["apiKind"] == userconfig.WorkflowKind.String() {
+			apiNames = append(apiNames, virtualService.Labels["apiName"])
+			apiIDs = append(apiIDs, virtualService.Labels["apiID"])
+		}
+	}
+
+	apis, err := operator.DownloadAPISpecs(apiNames, apiIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range apis {
+		workflow := apis[i]
+		endpoint, err := operator.APIEndpoint(&workflow)
+		if err != nil {
+			return nil, err
+		}
+
+		workflows = append(workflows, schema.APIResponse{
+			Spec:     workflow,
+			Endpoint: endpoint,
+		})
+	}
+
+	return workflows, nil
+}
+
+// GetAPIByName retrieves the metadata, in the form of schema.APIResponse, of a single workflow, including its most recent runs
+func GetAPIByName(deployedResource *operator.DeployedResource) ([]schema.APIResponse, error) {
+	api, err := operator.DownloadAPISpec(deployedResource.Name, deployedResource.VirtualService.Labels["apiID"])
+	if err != nil {
+		return nil, err
+	}
+
+	endpoint, err := operator.APIEndpoint(api)
+	if err != nil {
+		return nil, err
+	}
+
+	runs, err := GetMostRecentRuns(api.Name, _numRecentRuns)
+	if err != nil {
+		return nil, err
+	}
+
+	return []schema.APIResponse{
+		{
+			Spec:         *api,
+			Endpoint:     endpoint,
+			WorkflowRuns: runs,
+		},
+	}, nil
+}
+
+// UsedByWorkflows returns the names of the deployed workflows which have steps that run on the api
+func UsedByWorkflows(apiName string) ([]string, error) {
+	virtualServices, err := config.K8s.ListVirtualServicesByLabel("apiKind", userconfig.WorkflowKind.String())
+	if err != nil {
+		return nil, err
+	}
+
+	var workflowNames []string
+	for _, vs := range virtualServices {
+		workflowSpec, err := operator.DownloadAPISpec(vs.Labels["apiName"], vs.Labels["apiID"])
+		if err != nil {
+			return nil, err
+		}
+		for _, step := range workflowSpec.Steps {
+			if step.API == apiName {
+				workflowNames = append(workflowNames, workflowSpec.Name)
+				break
+			}
+		}
+	}
+
+	return workflowNames, nil
+}
+
+func deleteS3Resources(apiName string) error {
+	return parallel.RunFirstErr(
+		func() error {
+			return config.AWS.DeleteS3Dir(config.ClusterConfig.Bucket, filepath.Join(config.ClusterConfig.ClusterUID, "apis", apiName), true)
+		},
+		func() error {
+			return config.AWS.DeleteS3Dir(config.ClusterConfig.Bucket, spec.WorkflowPrefix(config.ClusterConfig.ClusterUID, apiName), true)
+		},
+	)
+}
@@ -0,0 +1,76 @@
+/*
+Copyright 2021 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workflow
+
+import (
+	"time"
+
+	"github.com/cortexlabs/cortex/pkg/config"
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/lib/logging"
+	"github.com/cortexlabs/cortex/pkg/lib/telemetry"
+	"github.com/cortexlabs/cortex/pkg/operator/operator"
+	"github.com/cortexlabs/cortex/pkg/types/userconfig"
+)
+
+const ManageRunsCronPeriod = 15 * time.Second
+
+var operatorLogger = logging.GetLogger()
+
+// ManageRuns advances the in progress runs of all workflows
+func ManageRuns() error {
+	virtualServices, err := config.K8s.ListVirtualServicesByLabel("apiKind", userconfig.WorkflowKind.String())
+	if err != nil {
+		return err
+	}
+
+	_runsMutex.Lock()
+	defer _runsMutex.Unlock()
+
+	for _, vs := range virtualServices {
+		workflowName := vs.Labels["apiName"]
+
+		runIDs, err := listInProgressRunIDs(workflowName)
+		if err != nil {
+			return err
+		}
+
+		for _, runID := range runIDs {
+			if err := manageRun(workflowName, runID); err != nil {
+				telemetry.Error(err)
+				operatorLogger.Error(err)
+			}
+		}
+	}
+
+	return nil
+}
+
+func manageRun(workflowName string, runID string) error {
+	run, err := GetRun(workflowName, runID)
+	if err != nil {
+		return err
+	}
+
+	// runs continue with the spec that they were submitted with, even if the workflow has since been updated
+	apiSpec, err := operator.DownloadAPISpec(run.WorkflowName, run.WorkflowID)
+	if err != nil {
+		return errors.Wrap(err, workflowName, runID)
+	}
+
+	return advanceRun(apiSpec, run)
+}
@@ -0,0 +1,43 @@
+/*
+Copyright 2021 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workflow
+
+import (
+	"fmt"
+
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	s "github.com/cortexlabs/cortex/pkg/lib/strings"
+)
+
+const (
+	ErrRunNotFound        = "workflow.run_not_found"
+	ErrRunIsNotInProgress = "workflow.run_is_not_in_progress"
+)
+
+func ErrorRunNotFound(workflowName string, runID string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrRunNotFound,
+		Message: fmt.Sprintf("unable to find run %s of workflow %s", s.UserStr(runID), s.UserStr(workflowName)),
+	})
+}
+
+func ErrorRunIsNotInProgress(workflowName string, runID string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrRunIsNotInProgress,
+		Message: fmt.Sprintf("cannot stop run %s of workflow %s because it is not in progress", s.UserStr(runID), s.UserStr(workflowName)),
+	})
+}
@@ -0,0 +1,71 @@
+/*
+Copyright 2021 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workflow
+
+import (
+	"path"
+
+	"github.com/cortexlabs/cortex/pkg/config"
+	"github.com/cortexlabs/cortex/pkg/consts"
+	"github.com/cortexlabs/cortex/pkg/lib/k8s"
+	"github.com/cortexlabs/cortex/pkg/lib/pointer"
+	"github.com/cortexlabs/cortex/pkg/types/spec"
+	"github.com/cortexlabs/cortex/pkg/workloads"
+	istioclientnetworking "istio.io/client-go/pkg/apis/networking/v1beta1"
+)
+
+const _operatorService = "operator"
+
+// the workflow's endpoint is routed to the operator, which submits the runs
+func virtualServiceSpec(api *spec.API) *istioclientnetworking.VirtualService {
+	return k8s.VirtualService(&k8s.VirtualServiceSpec{
+		Name:     workloads.K8sName(api.Name),
+		Gateways: []string{"apis-gateway"},
+		Destinations: []k8s.Destination{{
+			ServiceName: _operatorService,
+			Weight:      100,
+			Port:        uint32(consts.ProxyListeningPortInt32),
+		}},
+		PrefixPath:  api.Networking.Endpoint,
+		Rewrite:     pointer.String(path.Join("workflows", api.Name)),
+		Annotations: api.ToK8sAnnotations(),
+		Labels: workloads.WithUserLabels(api.API, map[string]string{
+			"apiName":        api.Name,
+			"apiID":          api.ID,
+			"specID":         api.SpecID,
+			"apiKind":        api.Kind.String(),
+			"cortex.dev/api": "true",
+		}),
+	})
+}
+
+func applyK8sVirtualService(api *spec.API, prevVirtualService *istioclientnetworking.VirtualService) error {
+	newVirtualService := virtualServiceSpec(api)
+
+	if prevVirtualService == nil {
+		_, err := config.K8s.CreateVirtualService(newVirtualService)
+		return err
+	}
+
+	_, err := config.K8s.UpdateVirtualService(prevVirtualService, newVirtualService)
+	return err
+}
+
+func deleteK8sResources(apiName string) error {
+	_, err := config.K8s.DeleteVirtualService(workloads.K8sName(apiName))
+	return err
+}
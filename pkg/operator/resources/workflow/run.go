@@ -0,0 +1,340 @@
+/*
+Copyright 2021 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workflow
+
+import (
+	"path"
+	"sync"
+	"time"
+
+	"github.com/cortexlabs/cortex/pkg/config"
+	"github.com/cortexlabs/cortex/pkg/lib/aws"
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/lib/maps"
+	"github.com/cortexlabs/cortex/pkg/lib/pointer"
+	"github.com/cortexlabs/cortex/pkg/operator/operator"
+	"github.com/cortexlabs/cortex/pkg/operator/resources/job"
+	"github.com/cortexlabs/cortex/pkg/operator/resources/job/taskapi"
+	"github.com/cortexlabs/cortex/pkg/operator/schema"
+	"github.com/cortexlabs/cortex/pkg/types/spec"
+	"github.com/cortexlabs/cortex/pkg/types/status"
+	"github.com/cortexlabs/cortex/pkg/types/userconfig"
+	"github.com/cortexlabs/cortex/pkg/workloads"
+)
+
+// the key in each step's task job config under which the workflow's metadata (e.g. the artifacts paths) is passed
+const _workflowConfigKey = "workflow"
+
+// guards the read-modify-write of the runs' status files, which are updated by both the cron and the api
+var _runsMutex sync.Mutex
+
+func SubmitRun(workflowName string, submission *schema.WorkflowRunSubmission) (*status.WorkflowRun, error) {
+	virtualService, err := config.K8s.GetVirtualService(workloads.K8sName(workflowName))
+	if err != nil {
+		return nil, err
+	}
+
+	apiSpec, err := operator.DownloadAPISpec(workflowName, virtualService.Labels["apiID"])
+	if err != nil {
+		return nil, err
+	}
+
+	steps, err := spec.SortWorkflowSteps(apiSpec.Steps)
+	if err != nil {
+		return nil, err
+	}
+
+	runID := spec.MonotonicallyDecreasingID()
+	run := status.WorkflowRun{
+		ID:           runID,
+		WorkflowName: apiSpec.Name,
+		WorkflowID:   apiSpec.ID,
+		Status:       status.JobRunning,
+		Config:       submission.Config,
+		StartTime:    time.Now(),
+	}
+
+	for _, step := range steps {
+		run.Steps = append(run.Steps, status.WorkflowStepStatus{
+			Name:          step.Name,
+			API:           step.API,
+			DependsOn:     step.DependsOn,
+			Status:        status.JobPending,
+			ArtifactsPath: spec.WorkflowStepArtifactsPath(config.ClusterConfig.Bucket, config.ClusterConfig.ClusterUID, apiSpec.Name, runID, step.Name),
+		})
+	}
+
+	_runsMutex.Lock()
+	defer _runsMutex.Unlock()
+
+	if err := config.AWS.UploadStringToS3("", config.ClusterConfig.Bucket, inProgressKey(workflowName, runID)); err != nil {
+		return nil, err
+	}
+
+	// submits the steps which don't have dependencies
+	if err := advanceRun(apiSpec, &run); err != nil {
+		return nil, err
+	}
+
+	return &run, nil
+}
+
+func GetRun(workflowName string, runID string) (*status.WorkflowRun, error) {
+	var run status.WorkflowRun
+	err := config.AWS.ReadJSONFromS3(&run, config.ClusterConfig.Bucket, spec.WorkflowRunKey(config.ClusterConfig.ClusterUID, workflowName, runID))
+	if err != nil {
+		if aws.IsGenericNotFoundErr(err) {
+			return nil, ErrorRunNotFound(workflowName, runID)
+		}
+		return nil, err
+	}
+	return &run, nil
+}
+
+// GetMostRecentRuns returns the workflow's most recently submitted runs, the most recent first
+func GetMostRecentRuns(workflowName string, count int64) ([]status.WorkflowRun, error) {
+	// run ids are monotonically decreasing, so the most recent runs are listed first
+	s3Objects, err := config.AWS.ListS3Dir(config.ClusterConfig.Bucket, spec.WorkflowRunsPrefix(config.ClusterConfig.ClusterUID, workflowName), false, pointer.Int64(count), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	runs := make([]status.WorkflowRun, 0, len(s3Objects))
+	for _, obj := range s3Objects {
+		var run status.WorkflowRun
+		if err := config.AWS.ReadJSONFromS3(&run, config.ClusterConfig.Bucket, *obj.Key); err != nil {
+			return nil, err
+		}
+		runs = append(runs, run)
+	}
+
+	return runs, nil
+}
+
+func StopRun(workflowName string, runID string) error {
+	_runsMutex.Lock()
+	defer _runsMutex.Unlock()
+
+	run, err := GetRun(workflowName, runID)
+	if err != nil {
+		return err
+	}
+
+	if !run.Status.IsInProgress() {
+		return ErrorRunIsNotInProgress(workflowName, runID)
+	}
+
+	return stopRun(run)
+}
+
+func stopAllRuns(workflowName string) error {
+	_runsMutex.Lock()
+	defer _runsMutex.Unlock()
+
+	runIDs, err := listInProgressRunIDs(workflowName)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, runID := range runIDs {
+		run, err := GetRun(workflowName, runID)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		errs = append(errs, stopRun(run))
+	}
+
+	return errors.FirstError(errs...)
+}
+
+// stopRun stops the run's task jobs which are in progress, and marks the steps which haven't been submitted as stopped
+func stopRun(run *status.WorkflowRun) error {
+	var errs []error
+	for i := range run.Steps {
+		step := &run.Steps[i]
+		if step.Status.IsCompleted() {
+			continue
+		}
+		if step.JobID != "" {
+			jobKey := stepJobKey(step)
+			if err := taskapi.StopJob(jobKey); err != nil {
+				errs = append(errs, errors.Wrap(err, step.Name))
+			}
+		}
+		completeStep(step, status.JobStopped)
+	}
+
+	completeRun(run, status.JobStopped)
+	errs = append(errs, uploadRun(run))
+
+	return errors.FirstError(errs...)
+}
+
+// advanceRun refreshes the statuses of the run's task jobs, submits the steps whose dependencies have all succeeded,
+// and stops the steps which depend on a step which didn't succeed; the steps are expected to be sorted by their dependencies
+func advanceRun(apiSpec *spec.API, run *status.WorkflowRun) error {
+	for i := range run.Steps {
+		step := &run.Steps[i]
+		if step.JobID == "" || step.Status.IsCompleted() {
+			continue
+		}
+
+		jobState, err := job.GetJobState(stepJobKey(step))
+		if err != nil {
+			return errors.Wrap(err, run.WorkflowName, run.ID, step.Name)
+		}
+		step.Status = jobState.Status
+		if jobState.Status.IsCompleted() {
+			step.EndTime = jobState.EndTime
+			if step.EndTime == nil {
+				step.EndTime = pointer.Time(time.Now())
+			}
+		}
+	}
+
+	for i := range run.Steps {
+		step := &run.Steps[i]
+		if step.Status != status.JobPending || step.JobID != "" {
+			continue
+		}
+
+		ready := true
+		for _, dependencyName := range step.DependsOn {
+			dependency := run.GetStep(dependencyName)
+			if dependency.Status.IsCompleted() && dependency.Status != status.JobSucceeded {
+				completeStep(step, status.JobStopped)
+				ready = false
+				break
+			}
+			if dependency.Status != status.JobSucceeded {
+				ready = false
+			}
+		}
+
+		if ready {
+			submitStep(apiSpec, run, step)
+		}
+	}
+
+	runStatus := status.JobSucceeded
+	for _, step := range run.Steps {
+		if !step.Status.IsCompleted() {
+			runStatus = status.JobRunning
+			break
+		}
+		if step.Status.IsFailed() {
+			runStatus = status.JobCompletedWithFailures
+		} else if step.Status == status.JobStopped && runStatus == status.JobSucceeded {
+			runStatus = status.JobStopped
+		}
+	}
+
+	if runStatus != status.JobRunning {
+		completeRun(run, runStatus)
+	}
+
+	return uploadRun(run)
+}
+
+func submitStep(apiSpec *spec.API, run *status.WorkflowRun, step *status.WorkflowStepStatus) {
+	var stepConfig map[string]interface{}
+	for _, stepSpec := range apiSpec.Steps {
+		if stepSpec.Name == step.Name {
+			stepConfig = stepSpec.Config
+		}
+	}
+
+	dependencyArtifactsPaths := map[string]interface{}{}
+	for _, dependencyName := range step.DependsOn {
+		dependencyArtifactsPaths[dependencyName] = run.GetStep(dependencyName).ArtifactsPath
+	}
+
+	jobConfig := maps.MergeStrInterfaceMaps(run.Config, stepConfig)
+	jobConfig[_workflowConfigKey] = map[string]interface{}{
+		"name":                       run.WorkflowName,
+		"run_id":                     run.ID,
+		"step":                       step.Name,
+		"artifacts_path":             step.ArtifactsPath,
+		"dependency_artifacts_paths": dependencyArtifactsPaths,
+	}
+
+	step.StartTime = pointer.Time(time.Now())
+
+	jobSpec, err := taskapi.SubmitJob(step.API, &schema.TaskJobSubmission{
+		RuntimeTaskJobConfig: spec.RuntimeTaskJobConfig{
+			Workers: 1,
+			Config:  jobConfig,
+		},
+	})
+	if err != nil {
+		operatorLogger.Error(errors.Wrap(err, "failed to submit step", run.WorkflowName, run.ID, step.Name))
+		completeStep(step, status.JobUnexpectedError)
+		return
+	}
+
+	step.JobID = jobSpec.ID
+	step.Status = status.JobRunning
+}
+
+func completeStep(step *status.WorkflowStepStatus, code status.JobCode) {
+	step.Status = code
+	step.EndTime = pointer.Time(time.Now())
+}
+
+func completeRun(run *status.WorkflowRun, code status.JobCode) {
+	run.Status = code
+	run.EndTime = pointer.Time(time.Now())
+}
+
+func uploadRun(run *status.WorkflowRun) error {
+	if err := config.AWS.UploadJSONToS3(run, config.ClusterConfig.Bucket, spec.WorkflowRunKey(config.ClusterConfig.ClusterUID, run.WorkflowName, run.ID)); err != nil {
+		return err
+	}
+
+	if run.Status.IsCompleted() {
+		return config.AWS.DeleteS3File(config.ClusterConfig.Bucket, inProgressKey(run.WorkflowName, run.ID))
+	}
+
+	return nil
+}
+
+func listInProgressRunIDs(workflowName string) ([]string, error) {
+	s3Objects, err := config.AWS.ListS3Dir(config.ClusterConfig.Bucket, spec.WorkflowInProgressPrefix(config.ClusterConfig.ClusterUID, workflowName), false, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	runIDs := make([]string, 0, len(s3Objects))
+	for _, obj := range s3Objects {
+		runIDs = append(runIDs, path.Base(*obj.Key))
+	}
+	return runIDs, nil
+}
+
+func inProgressKey(workflowName string, runID string) string {
+	return path.Join(spec.WorkflowInProgressPrefix(config.ClusterConfig.ClusterUID, workflowName), runID)
+}
+
+func stepJobKey(step *status.WorkflowStepStatus) spec.JobKey {
+	return spec.JobKey{
+		APIName: step.API,
+		ID:      step.JobID,
+		Kind:    userconfig.TaskAPIKind,
+	}
+}
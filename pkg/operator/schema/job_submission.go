@@ -54,3 +54,7 @@ type BatchJobSubmission struct {
 type TaskJobSubmission struct {
 	spec.RuntimeTaskJobConfig
 }
+
+type WorkflowRunSubmission struct {
+	Config map[string]interface{} `json:"config"` // passed to all of the workflow's steps
+}
@@ -17,8 +17,11 @@ limitations under the License.
 package schema
 
 import (
+	"time"
+
 	"github.com/cortexlabs/cortex/pkg/types/clusterconfig"
 	"github.com/cortexlabs/cortex/pkg/types/metrics"
+	"github.com/cortexlabs/cortex/pkg/types/rbac"
 	"github.com/cortexlabs/cortex/pkg/types/spec"
 	"github.com/cortexlabs/cortex/pkg/types/status"
 	"github.com/cortexlabs/cortex/pkg/types/userconfig"
@@ -28,6 +31,23 @@ type InfoResponse struct {
 	ClusterConfig      clusterconfig.InternalConfig `json:"cluster_config"`
 	NodeInfos          []NodeInfo                   `json:"node_infos"`
 	NumPendingReplicas int                          `json:"num_pending_replicas"`
+	QuotaUsages        []QuotaUsage                 `json:"quota_usages,omitempty"`
+	BudgetUsage        *BudgetUsage                 `json:"budget_usage,omitempty"`
+}
+
+// QuotaUsage is the compute which the deployed realtime and async apis of a team request at their max replicas
+type QuotaUsage struct {
+	Quota    clusterconfig.Quota `json:"quota"`
+	Used     userconfig.Compute  `json:"used"`
+	Replicas int64               `json:"replicas"`
+	NumAPIs  int                 `json:"num_apis"`
+}
+
+// BudgetUsage is the projected hourly cost of the cluster and of each deployed realtime and async api (at their max replicas)
+type BudgetUsage struct {
+	Budget             clusterconfig.Budget `json:"budget"`
+	ClusterCostPerHour float64              `json:"cluster_cost_per_hour"`
+	APICostsPerHour    map[string]float64   `json:"api_costs_per_hour"` // by api name
 }
 
 type NodeInfo struct {
@@ -44,32 +64,137 @@ type NodeInfo struct {
 }
 
 type DeployResult struct {
-	API     *APIResponse `json:"api"`
-	Message string       `json:"message"`
-	Error   string       `json:"error"`
+	Resource userconfig.Resource `json:"resource"`
+	API      *APIResponse        `json:"api"`
+	Message  string              `json:"message"`
+	Error    string              `json:"error"`
+	IsNew    bool                `json:"is_new"`          // true if the api was not previously deployed
+	Diffs    []spec.FieldDiff    `json:"diffs,omitempty"` // the fields which were changed by the deployment
+	Warnings []string            `json:"warnings,omitempty"`
+}
+
+type DeployDiffResult struct {
+	Resource userconfig.Resource `json:"resource"`
+	IsNew    bool                `json:"is_new"` // true if the api is not currently deployed
+	Diffs    []spec.FieldDiff    `json:"diffs"`
+	Error    string              `json:"error"`
 }
 
 type APIResponse struct {
 	Spec             spec.API                `json:"spec"`
 	Status           *status.Status          `json:"status,omitempty"`
 	Metrics          *metrics.Metrics        `json:"metrics,omitempty"`
+	VariantMetrics   []metrics.Metrics       `json:"variant_metrics,omitempty"`
 	Endpoint         string                  `json:"endpoint"`
 	DashboardURL     *string                 `json:"dashboard_url,omitempty"`
 	BatchJobStatuses []status.BatchJobStatus `json:"batch_job_statuses,omitempty"`
 	TaskJobStatuses  []status.TaskJobStatus  `json:"task_job_statuses,omitempty"`
+	WorkflowRuns     []status.WorkflowRun    `json:"workflow_runs,omitempty"`
 	APIVersions      []APIVersion            `json:"api_versions,omitempty"`
+	Events           []APIEvent              `json:"events,omitempty"`
+	ReplicaFailures  []ReplicaFailure        `json:"replica_failures,omitempty"`
+	Verification     *ArtifactVerification   `json:"verification,omitempty"`
+}
+
+// ArtifactVerification is the verification status of the api's model cache, as reported by the replicas which downloaded it
+type ArtifactVerification struct {
+	Checksum         string `json:"checksum,omitempty"` // the sha256 checksum of the verified artifacts' manifest
+	VerifiedReplicas int32  `json:"verified_replicas"`
+	FailedReplicas   int32  `json:"failed_replicas"`
+	Failure          string `json:"failure,omitempty"` // the most recent verification failure
+}
+
+// ReplicaFailure describes why a replica of the api is failing (e.g. the most recent termination of its crashing container)
+type ReplicaFailure struct {
+	Replica    string     `json:"replica"`
+	Container  string     `json:"container"`
+	Reason     string     `json:"reason"` // e.g. Error, OOMKilled, or ImagePullBackOff
+	ExitCode   *int32     `json:"exit_code,omitempty"`
+	Message    string     `json:"message,omitempty"`
+	Restarts   int32      `json:"restarts"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+	LogLines   []string   `json:"log_lines,omitempty"` // the last lines of the terminated container's logs
+}
+
+// APIEvent is an event which occurred to one of the api's kubernetes resources (e.g. scaling, image pull failures, and failed probes),
+// or a termination of one of the api's containers (e.g. when it runs out of memory)
+type APIEvent struct {
+	Time    time.Time `json:"time"`
+	Type    string    `json:"type"`   // Normal or Warning
+	Reason  string    `json:"reason"` // e.g. ScalingReplicaSet, BackOff, Unhealthy, or OOMKilled
+	Object  string    `json:"object"` // e.g. pod/api-my-api-5d8f7c6b9-x2kqp
+	Message string    `json:"message"`
+	Count   int32     `json:"count"`
+}
+
+// RecommendationsResponse contains right-sizing recommendations for the compute requests of an api's containers, based on their usage
+type RecommendationsResponse struct {
+	APIName    string                    `json:"api_name"`
+	Window     string                    `json:"window"` // the duration over which the usage was measured
+	Containers []ContainerRecommendation `json:"containers"`
+}
+
+type ContainerRecommendation struct {
+	Container   string                 `json:"container"`
+	CPU         ResourceRecommendation `json:"cpu"`
+	Mem         ResourceRecommendation `json:"mem"`
+	OOMKilled   int                    `json:"oom_killed"` // the number of current replicas whose container was killed because it ran out of memory
+	Suggestions []string               `json:"suggestions,omitempty"`
+}
+
+// the quantities are formatted like the api configuration's compute fields (e.g. 500m or 2Gi)
+type ResourceRecommendation struct {
+	Requested   *string `json:"requested,omitempty"`
+	UsageP95    *string `json:"usage_p95,omitempty"`
+	UsageMax    *string `json:"usage_max,omitempty"`
+	Recommended *string `json:"recommended,omitempty"` // nil if the request is appropriate, or if there isn't enough usage data
+}
+
+// TimeSeriesResponse contains an api's metrics over a window, sampled at each step
+type TimeSeriesResponse struct {
+	APIName string       `json:"api_name"`
+	Kind    string       `json:"kind"`
+	Start   time.Time    `json:"start"`
+	End     time.Time    `json:"end"`
+	Step    string       `json:"step"`
+	Series  []TimeSeries `json:"series"`
+}
+
+type TimeSeries struct {
+	Metric string            `json:"metric"` // e.g. requests_per_second or latency_p99
+	Unit   string            `json:"unit"`   // e.g. requests/s, ms, ratio, or replicas
+	Points []TimeSeriesPoint `json:"points"` // steps without data are omitted
+}
+
+type TimeSeriesPoint struct {
+	Time  time.Time `json:"time"`
+	Value float64   `json:"value"`
 }
 
 type LogResponse struct {
 	LogURL string `json:"log_url"`
 }
 
+type LogLinesResponse struct {
+	Lines     []LogLine `json:"lines"`
+	Truncated bool      `json:"truncated"` // whether there are more log lines than the limit
+}
+
+type LogLine struct {
+	Timestamp string `json:"timestamp"`
+	Message   string `json:"message"`
+}
+
 type BatchJobResponse struct {
 	APISpec   spec.API              `json:"api_spec"`
 	JobStatus status.BatchJobStatus `json:"job_status"`
 	Endpoint  string                `json:"endpoint"`
 }
 
+type BatchJobResultsResponse struct {
+	JobResults status.BatchJobResults `json:"job_results"`
+}
+
 type TaskJobResponse struct {
 	APISpec   spec.API             `json:"api_spec"`
 	JobStatus status.TaskJobStatus `json:"job_status"`
@@ -80,6 +205,11 @@ type DeleteResponse struct {
 	Message string `json:"message"`
 }
 
+type RetryJobResponse struct {
+	Message string `json:"message"`
+	JobID   string `json:"job_id"` // the ID of the newly submitted job
+}
+
 type RefreshResponse struct {
 	Message string `json:"message"`
 }
@@ -105,3 +235,97 @@ func (ir InfoResponse) GetNodesWithNodeGroupName(ngName string) []NodeInfo {
 	}
 	return nodesInfo
 }
+
+type AuditEvent struct {
+	Time      time.Time        `json:"time"`
+	Action    AuditAction      `json:"action"`
+	APIName   string           `json:"api_name"`
+	APIKind   string           `json:"api_kind,omitempty"`
+	Principal string           `json:"principal"` // the caller's AWS arn, or the operator subsystem which performed the action (e.g. gitops)
+	ClientID  string           `json:"client_id,omitempty"`
+	SourceIP  string           `json:"source_ip,omitempty"`
+	Diffs     []spec.FieldDiff `json:"diffs,omitempty"`
+	Message   string           `json:"message,omitempty"`
+	Error     string           `json:"error,omitempty"`
+}
+
+type AuditAction string
+
+const (
+	AuditActionCreate   AuditAction = "create"
+	AuditActionUpdate   AuditAction = "update"
+	AuditActionDelete   AuditAction = "delete"
+	AuditActionRefresh  AuditAction = "refresh"
+	AuditActionRollback AuditAction = "rollback"
+)
+
+type AuditResponse struct {
+	Events []AuditEvent `json:"events"`
+}
+
+// DeploymentRevision is a version of an api's configuration which was deployed to the cluster
+type DeploymentRevision struct {
+	Revision   int       `json:"revision"`
+	Time       time.Time `json:"time"`
+	APIName    string    `json:"api_name"`
+	APIKind    string    `json:"api_kind"`
+	SpecID     string    `json:"spec_id"`
+	Principal  string    `json:"principal"` // the caller's AWS arn, or the operator subsystem which deployed the revision (e.g. gitops)
+	ClientID   string    `json:"client_id,omitempty"`
+	SourceIP   string    `json:"source_ip,omitempty"`
+	RollbackOf *int      `json:"rollback_of,omitempty"` // the revision which was redeployed, if this revision was created by a rollback
+	Config     string    `json:"config,omitempty"`      // the api's configuration (yaml), after values were applied
+
+	ModelVersions map[string]string `json:"model_versions,omitempty"` // the model registry versions which the api's models were resolved to
+}
+
+type HistoryResponse struct {
+	Revisions []DeploymentRevision `json:"revisions"`
+}
+
+type RollbackResponse struct {
+	Revision int          `json:"revision"` // the revision which was redeployed
+	Result   DeployResult `json:"result"`
+}
+
+// OperatorToken describes a token which was issued by a cluster admin; the token's secret is only returned when it is created
+type OperatorToken struct {
+	ID          string     `json:"id"`
+	Role        rbac.Role  `json:"role"`
+	APIPrefix   string     `json:"api_prefix,omitempty"` // if set, the token can only access apis whose names start with this prefix
+	Description string     `json:"description,omitempty"`
+	CreatedBy   string     `json:"created_by"`
+	CreatedAt   time.Time  `json:"created_at"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+}
+
+func (token OperatorToken) IsExpired() bool {
+	return token.ExpiresAt != nil && time.Now().After(*token.ExpiresAt)
+}
+
+func (token OperatorToken) Access() rbac.Access {
+	return rbac.Access{
+		Role:      token.Role,
+		APIPrefix: token.APIPrefix,
+	}
+}
+
+type CreateOperatorTokenResponse struct {
+	Token         string        `json:"token"`
+	OperatorToken OperatorToken `json:"operator_token"`
+}
+
+type OperatorTokensResponse struct {
+	Tokens []OperatorToken `json:"tokens"`
+}
+
+type RevokeOperatorTokenResponse struct {
+	Message string `json:"message"`
+}
+
+// OIDCConfigResponse contains the settings which clients use to log in with the cluster's OIDC provider
+type OIDCConfigResponse struct {
+	IssuerURL string   `json:"issuer_url"`
+	ClientID  string   `json:"client_id"`
+	Scopes    []string `json:"scopes"`
+}
@@ -0,0 +1,50 @@
+/*
+Copyright 2021 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tokens
+
+import (
+	"fmt"
+
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+)
+
+const (
+	ErrInvalidToken  = "tokens.invalid_token"
+	ErrTokenExpired  = "tokens.token_expired"
+	ErrTokenNotFound = "tokens.token_not_found"
+)
+
+func ErrorInvalidToken() error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrInvalidToken,
+		Message: "invalid operator token; ask a cluster admin for a new token (which can be created with `cortex auth token create`)",
+	})
+}
+
+func ErrorTokenExpired(id string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrTokenExpired,
+		Message: fmt.Sprintf("operator token %s has expired; ask a cluster admin for a new token (which can be created with `cortex auth token create`)", id),
+	})
+}
+
+func ErrorTokenNotFound(id string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrTokenNotFound,
+		Message: fmt.Sprintf("operator token %s does not exist; run `cortex auth token list` to see the existing tokens", id),
+	})
+}
@@ -0,0 +1,205 @@
+/*
+Copyright 2021 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tokens
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cortexlabs/cortex/pkg/config"
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/operator/schema"
+	"github.com/cortexlabs/cortex/pkg/types/rbac"
+)
+
+const (
+	_tokenPrefix = "cxt"
+	_idBytes     = 6
+	_secretBytes = 24
+)
+
+// only a hash of the token's secret is stored, so the token can't be recovered from the bucket
+type storedToken struct {
+	schema.OperatorToken
+	SecretHash string `json:"secret_hash"`
+}
+
+// tokens are immutable once created, so they are cached until they are revoked (there is a single operator replica)
+var (
+	_cachedTokens      = map[string]*storedToken{}
+	_cachedTokensMutex = sync.Mutex{}
+)
+
+func keysPrefix() string {
+	return filepath.Join(config.ClusterConfig.ClusterUID, "operator_tokens") + "/"
+}
+
+func tokenKey(id string) string {
+	return keysPrefix() + id + ".json"
+}
+
+func randomHex(numBytes int) (string, error) {
+	bytes := make([]byte, numBytes)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", errors.WithStack(err)
+	}
+	return hex.EncodeToString(bytes), nil
+}
+
+func hashSecret(secret string) string {
+	hash := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(hash[:])
+}
+
+// tokens have the form cxt_<id>_<secret>
+func parseToken(token string) (string, string, bool) {
+	split := strings.Split(token, "_")
+	if len(split) != 3 || split[0] != _tokenPrefix || split[1] == "" || split[2] == "" {
+		return "", "", false
+	}
+	return split[1], split[2], true
+}
+
+// Create issues a new token; the returned token string is not stored, so it can't be retrieved again
+func Create(role rbac.Role, apiPrefix string, description string, ttl *time.Duration, createdBy string) (string, *schema.OperatorToken, error) {
+	id, err := randomHex(_idBytes)
+	if err != nil {
+		return "", nil, err
+	}
+	secret, err := randomHex(_secretBytes)
+	if err != nil {
+		return "", nil, err
+	}
+
+	now := time.Now().UTC()
+	token := storedToken{
+		OperatorToken: schema.OperatorToken{
+			ID:          id,
+			Role:        role,
+			APIPrefix:   apiPrefix,
+			Description: description,
+			CreatedBy:   createdBy,
+			CreatedAt:   now,
+		},
+		SecretHash: hashSecret(secret),
+	}
+	if ttl != nil {
+		expiresAt := now.Add(*ttl)
+		token.ExpiresAt = &expiresAt
+	}
+
+	if err := config.AWS.UploadJSONToS3(token, config.ClusterConfig.Bucket, tokenKey(id)); err != nil {
+		return "", nil, err
+	}
+
+	return strings.Join([]string{_tokenPrefix, id, secret}, "_"), &token.OperatorToken, nil
+}
+
+// List returns all tokens which have not been revoked (including expired tokens)
+func List() ([]schema.OperatorToken, error) {
+	objects, err := config.AWS.ListS3Prefix(config.ClusterConfig.Bucket, keysPrefix(), false, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	tokens := make([]schema.OperatorToken, 0, len(objects))
+	for _, object := range objects {
+		if object.Key == nil {
+			continue
+		}
+		var token storedToken
+		if err := config.AWS.ReadJSONFromS3(&token, config.ClusterConfig.Bucket, *object.Key); err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, token.OperatorToken)
+	}
+
+	return tokens, nil
+}
+
+func Revoke(id string) error {
+	exists, err := config.AWS.IsS3File(config.ClusterConfig.Bucket, tokenKey(id))
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return ErrorTokenNotFound(id)
+	}
+
+	_cachedTokensMutex.Lock()
+	delete(_cachedTokens, id)
+	_cachedTokensMutex.Unlock()
+
+	return config.AWS.DeleteS3File(config.ClusterConfig.Bucket, tokenKey(id))
+}
+
+// Validate returns the token's metadata if the token is valid and hasn't expired
+func Validate(token string) (*schema.OperatorToken, error) {
+	id, secret, ok := parseToken(token)
+	if !ok {
+		return nil, ErrorInvalidToken()
+	}
+
+	stored, err := getStoredToken(id)
+	if err != nil {
+		return nil, err
+	}
+	if stored == nil {
+		return nil, ErrorInvalidToken()
+	}
+
+	if subtle.ConstantTimeCompare([]byte(hashSecret(secret)), []byte(stored.SecretHash)) != 1 {
+		return nil, ErrorInvalidToken()
+	}
+
+	if stored.IsExpired() {
+		return nil, ErrorTokenExpired(stored.ID)
+	}
+
+	return &stored.OperatorToken, nil
+}
+
+func getStoredToken(id string) (*storedToken, error) {
+	_cachedTokensMutex.Lock()
+	defer _cachedTokensMutex.Unlock()
+
+	if token, ok := _cachedTokens[id]; ok {
+		return token, nil
+	}
+
+	exists, err := config.AWS.IsS3File(config.ClusterConfig.Bucket, tokenKey(id))
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, nil
+	}
+
+	var token storedToken
+	if err := config.AWS.ReadJSONFromS3(&token, config.ClusterConfig.Bucket, tokenKey(id)); err != nil {
+		return nil, err
+	}
+
+	_cachedTokens[id] = &token
+	return &token, nil
+}
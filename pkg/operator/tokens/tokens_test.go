@@ -0,0 +1,41 @@
+/*
+Copyright 2021 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tokens
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseToken(t *testing.T) {
+	id, secret, ok := parseToken("cxt_0a1b2c_3d4e5f")
+	require.True(t, ok)
+	require.Equal(t, "0a1b2c", id)
+	require.Equal(t, "3d4e5f", secret)
+
+	for _, token := range []string{"", "cxt", "cxt_0a1b2c", "cxt__3d4e5f", "cxt_0a1b2c_", "abc_0a1b2c_3d4e5f", "cxt_0a1b2c_3d4e5f_7a"} {
+		_, _, ok := parseToken(token)
+		require.False(t, ok, token)
+	}
+}
+
+func TestHashSecret(t *testing.T) {
+	require.Equal(t, hashSecret("secret"), hashSecret("secret"))
+	require.NotEqual(t, hashSecret("secret"), hashSecret("secret2"))
+	require.Len(t, hashSecret("secret"), 64)
+}
@@ -0,0 +1,69 @@
+/*
+Copyright 2021 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proxy
+
+import (
+	"net"
+	"net/http"
+	"strings"
+)
+
+// RealIP sets the request's remote address to the address of the client which sent the request to the api load balancer.
+// The istio gateway appends the address of its downstream connection to the X-Forwarded-For header, so the client's address
+// is numTrustedProxies entries before the last one (entries before it are set by the client, and can't be trusted). The
+// entries which were appended by the trusted proxies are removed, so that the reverse proxy forwards the client's address to
+// the user container as the last entry of the X-Forwarded-For header (rather than the istio gateway's address).
+func RealIP(numTrustedProxies int, next http.Handler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		forwardedFor := splitForwardedFor(r.Header.Values("X-Forwarded-For"))
+
+		if len(forwardedFor) > numTrustedProxies {
+			clientIndex := len(forwardedFor) - 1 - numTrustedProxies
+			if net.ParseIP(forwardedFor[clientIndex]) != nil {
+				r.RemoteAddr = net.JoinHostPort(forwardedFor[clientIndex], "0")
+				if clientIndex > 0 {
+					r.Header.Set("X-Forwarded-For", strings.Join(forwardedFor[:clientIndex], ", "))
+				} else {
+					r.Header.Del("X-Forwarded-For")
+				}
+			}
+		}
+
+		next.ServeHTTP(w, r)
+	}
+}
+
+// ClientIP returns the address of the client which sent the request (without the port)
+func ClientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+func splitForwardedFor(values []string) []string {
+	var addresses []string
+	for _, value := range values {
+		for _, address := range strings.Split(value, ",") {
+			if address = strings.TrimSpace(address); address != "" {
+				addresses = append(addresses, address)
+			}
+		}
+	}
+	return addresses
+}
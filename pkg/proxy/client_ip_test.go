@@ -0,0 +1,107 @@
+/*
+Copyright 2021 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proxy_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/cortexlabs/cortex/pkg/proxy"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRealIP(t *testing.T) {
+	for _, tt := range []struct {
+		name                 string
+		numTrustedProxies    int
+		forwardedFor         []string
+		expectedClientIP     string
+		expectedForwardedFor string
+	}{
+		{
+			name:             "network load balancer",
+			forwardedFor:     []string{"203.0.113.7"},
+			expectedClientIP: "203.0.113.7",
+		},
+		{
+			name:                 "spoofed by the client",
+			forwardedFor:         []string{"198.51.100.1, 198.51.100.2", "203.0.113.7"},
+			expectedClientIP:     "203.0.113.7",
+			expectedForwardedFor: "198.51.100.1, 198.51.100.2",
+		},
+		{
+			name:                 "application load balancer",
+			numTrustedProxies:    1,
+			forwardedFor:         []string{"198.51.100.1, 203.0.113.7, 10.0.1.12"},
+			expectedClientIP:     "203.0.113.7",
+			expectedForwardedFor: "198.51.100.1",
+		},
+		{
+			name:                 "missing trusted proxy",
+			numTrustedProxies:    1,
+			forwardedFor:         []string{"203.0.113.7"},
+			expectedClientIP:     "192.0.2.1", // httptest's default remote address
+			expectedForwardedFor: "203.0.113.7",
+		},
+		{
+			name:             "no header",
+			expectedClientIP: "192.0.2.1",
+		},
+		{
+			name:                 "invalid address",
+			forwardedFor:         []string{"unknown"},
+			expectedClientIP:     "192.0.2.1",
+			expectedForwardedFor: "unknown",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			var clientIP, forwardedFor string
+			h := proxy.RealIP(tt.numTrustedProxies, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				clientIP = proxy.ClientIP(r)
+				forwardedFor = r.Header.Get("X-Forwarded-For")
+			}))
+
+			req := httptest.NewRequest(http.MethodGet, userContainerHost, nil)
+			for _, value := range tt.forwardedFor {
+				req.Header.Add("X-Forwarded-For", value)
+			}
+			h(httptest.NewRecorder(), req)
+
+			require.Equal(t, tt.expectedClientIP, clientIP)
+			require.Equal(t, tt.expectedForwardedFor, forwardedFor)
+		})
+	}
+}
+
+func TestRealIPForwardedToUserContainer(t *testing.T) {
+	var forwardedFor string
+	userContainer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		forwardedFor = r.Header.Get("X-Forwarded-For")
+	}))
+	defer userContainer.Close()
+
+	h := proxy.RealIP(0, proxy.NewReverseProxy(userContainer.URL, 1, 1))
+
+	req := httptest.NewRequest(http.MethodGet, userContainerHost, nil)
+	req.Header.Set("X-Forwarded-For", "198.51.100.1, 203.0.113.7")
+	rec := httptest.NewRecorder()
+	h(rec, req)
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, "198.51.100.1, 203.0.113.7", forwardedFor)
+}
@@ -0,0 +1,221 @@
+/*
+Copyright 2021 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proxy
+
+import (
+	"container/heap"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/cortexlabs/cortex/pkg/probe"
+)
+
+// FairQueueParams defines the parameters of the fair queue.
+type FairQueueParams struct {
+	QueueDepth     int
+	MaxConcurrency int
+	ClientHeader   string         // the request header which identifies the client (requests without it are treated as one client)
+	Weights        map[string]int // the weight of each client (keyed by the value of the client header)
+	DefaultWeight  int            // the weight of clients which aren't in Weights
+}
+
+// FairQueue enforces a concurrency limit like the Breaker, but while requests are queued, the free slots are shared
+// between clients in proportion to their weights (start-time fair queuing), rather than in arrival order,
+// so that a client which sends many requests (e.g. a backfill) can't starve the other clients
+type FairQueue struct {
+	params     FairQueueParams
+	totalSlots int
+
+	mu          sync.Mutex
+	inFlight    int // the number of requests which hold a slot
+	waiters     fairQueueWaiters
+	seq         uint64
+	virtualTime float64            // the start tag of the most recently dispatched request
+	lastFinish  map[string]float64 // the finish tag of each client's most recently queued request
+}
+
+type fairQueueWaiter struct {
+	start float64
+	seq   uint64 // breaks ties between equal start tags in arrival order
+	index int    // the waiter's index in the heap (-1 once it has been dispatched)
+	ready chan struct{}
+}
+
+// NewFairQueue creates a FairQueue with the desired queue depth, concurrency limit, and client weights.
+func NewFairQueue(params FairQueueParams) *FairQueue {
+	if params.QueueDepth <= 0 {
+		panic(fmt.Sprintf("Queue depth must be greater than 0. Got %v.", params.QueueDepth))
+	}
+	if params.MaxConcurrency <= 0 {
+		panic(fmt.Sprintf("Max concurrency must be greater than 0. Got %v.", params.MaxConcurrency))
+	}
+	if params.DefaultWeight <= 0 {
+		panic(fmt.Sprintf("Default weight must be greater than 0. Got %v.", params.DefaultWeight))
+	}
+
+	return &FairQueue{
+		params:     params,
+		totalSlots: params.QueueDepth + params.MaxConcurrency,
+		lastFinish: map[string]float64{},
+	}
+}
+
+func (q *FairQueue) weight(client string) int {
+	if weight, ok := q.params.Weights[client]; ok && weight > 0 {
+		return weight
+	}
+	return q.params.DefaultWeight
+}
+
+// Acquire waits for a free slot; requests from different clients are dispatched in proportion to the clients' weights.
+// On success, the caller must call release when it's done with the slot.
+func (q *FairQueue) Acquire(ctx context.Context, client string) (func(), error) {
+	q.mu.Lock()
+
+	if q.inFlight+q.waiters.Len() >= q.totalSlots {
+		q.mu.Unlock()
+		return nil, ErrRequestQueueFull
+	}
+
+	if q.inFlight < q.params.MaxConcurrency && q.waiters.Len() == 0 {
+		// fairness only matters while requests are queued
+		q.inFlight++
+		q.mu.Unlock()
+		return q.release, nil
+	}
+
+	start := q.virtualTime
+	if lastFinish := q.lastFinish[client]; lastFinish > start {
+		start = lastFinish
+	}
+	q.lastFinish[client] = start + 1/float64(q.weight(client))
+
+	waiter := &fairQueueWaiter{start: start, seq: q.seq, ready: make(chan struct{})}
+	q.seq++
+	heap.Push(&q.waiters, waiter)
+	q.mu.Unlock()
+
+	select {
+	case <-waiter.ready:
+		return q.release, nil
+	case <-ctx.Done():
+		q.mu.Lock()
+		if waiter.index >= 0 {
+			heap.Remove(&q.waiters, waiter.index)
+			if q.waiters.Len() == 0 {
+				q.virtualTime = 0
+				q.lastFinish = map[string]float64{}
+			}
+			q.mu.Unlock()
+			return nil, ctx.Err()
+		}
+		q.mu.Unlock()
+
+		// the slot was handed to this request concurrently with the cancellation
+		q.release()
+		return nil, ctx.Err()
+	}
+}
+
+// release hands the slot to the queued request with the smallest start tag, or frees it if no requests are queued
+func (q *FairQueue) release() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.waiters.Len() == 0 {
+		q.inFlight--
+		return
+	}
+
+	waiter := heap.Pop(&q.waiters).(*fairQueueWaiter)
+	q.virtualTime = waiter.start
+	if q.waiters.Len() == 0 {
+		// the queue has drained, so the clients' history is no longer needed
+		q.virtualTime = 0
+		q.lastFinish = map[string]float64{}
+	}
+	close(waiter.ready)
+}
+
+// InFlight returns the number of requests which are currently in flight or queued.
+func (q *FairQueue) InFlight() int64 {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return int64(q.inFlight + q.waiters.Len())
+}
+
+// Handler is used instead of the breaker's Handler when fair queuing is enabled
+func (q *FairQueue) Handler(next http.Handler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if probe.IsRequestKubeletProbe(r) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		release, err := q.Acquire(r.Context(), r.Header.Get(q.params.ClientHeader))
+		if err != nil {
+			if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, ErrRequestQueueFull) {
+				http.Error(w, err.Error(), http.StatusServiceUnavailable)
+			} else {
+				w.WriteHeader(http.StatusInternalServerError)
+			}
+			return
+		}
+		defer release()
+
+		next.ServeHTTP(w, r)
+	}
+}
+
+// fairQueueWaiters is a min-heap of waiters, ordered by start tag
+type fairQueueWaiters []*fairQueueWaiter
+
+func (w fairQueueWaiters) Len() int {
+	return len(w)
+}
+
+func (w fairQueueWaiters) Less(i, j int) bool {
+	if w[i].start == w[j].start {
+		return w[i].seq < w[j].seq
+	}
+	return w[i].start < w[j].start
+}
+
+func (w fairQueueWaiters) Swap(i, j int) {
+	w[i], w[j] = w[j], w[i]
+	w[i].index = i
+	w[j].index = j
+}
+
+func (w *fairQueueWaiters) Push(x interface{}) {
+	waiter := x.(*fairQueueWaiter)
+	waiter.index = len(*w)
+	*w = append(*w, waiter)
+}
+
+func (w *fairQueueWaiters) Pop() interface{} {
+	old := *w
+	n := len(old)
+	waiter := old[n-1]
+	old[n-1] = nil
+	waiter.index = -1
+	*w = old[:n-1]
+	return waiter
+}
@@ -0,0 +1,120 @@
+/*
+Copyright 2021 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proxy_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cortexlabs/cortex/pkg/proxy"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestFairQueue(queueDepth int) *proxy.FairQueue {
+	return proxy.NewFairQueue(proxy.FairQueueParams{
+		QueueDepth:     queueDepth,
+		MaxConcurrency: 1,
+		ClientHeader:   "X-Api-Key",
+		Weights:        map[string]int{"interactive": 4},
+		DefaultWeight:  1,
+	})
+}
+
+// waitForInFlight waits until the queue has the expected number of in-flight and queued requests
+func waitForInFlight(t *testing.T, queue *proxy.FairQueue, expected int64) {
+	require.Eventually(t, func() bool {
+		return queue.InFlight() == expected
+	}, time.Second, time.Millisecond)
+}
+
+func TestFairQueueSharesSlotsByWeight(t *testing.T) {
+	queue := newTestFairQueue(10)
+
+	release, err := queue.Acquire(context.Background(), "holder")
+	require.NoError(t, err)
+
+	var mu sync.Mutex
+	var order []string
+	var wg sync.WaitGroup
+
+	clients := []string{"backfill", "backfill", "backfill", "backfill", "interactive", "interactive"}
+	for i, client := range clients {
+		wg.Add(1)
+		go func(client string) {
+			defer wg.Done()
+			release, err := queue.Acquire(context.Background(), client)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+
+			mu.Lock()
+			order = append(order, client)
+			mu.Unlock()
+			release()
+		}(client)
+
+		// queue the requests one at a time, so that their arrival order is deterministic
+		waitForInFlight(t, queue, int64(i+2))
+	}
+
+	release()
+	wg.Wait()
+
+	require.Equal(t, []string{"backfill", "interactive", "interactive", "backfill", "backfill", "backfill"}, order)
+	require.Equal(t, int64(0), queue.InFlight())
+}
+
+func TestFairQueueFull(t *testing.T) {
+	queue := newTestFairQueue(1)
+
+	release, err := queue.Acquire(context.Background(), "")
+	require.NoError(t, err)
+
+	go func() {
+		release, err := queue.Acquire(context.Background(), "")
+		if err == nil {
+			release()
+		}
+	}()
+	waitForInFlight(t, queue, 2)
+
+	_, err = queue.Acquire(context.Background(), "")
+	require.Equal(t, proxy.ErrRequestQueueFull, err)
+
+	release()
+	waitForInFlight(t, queue, 0)
+}
+
+func TestFairQueueCancelled(t *testing.T) {
+	queue := newTestFairQueue(10)
+
+	release, err := queue.Acquire(context.Background(), "")
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err = queue.Acquire(ctx, "")
+	require.Equal(t, context.DeadlineExceeded, err)
+	require.Equal(t, int64(1), queue.InFlight())
+
+	release()
+	require.Equal(t, int64(0), queue.InFlight())
+}
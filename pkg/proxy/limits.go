@@ -0,0 +1,114 @@
+/*
+Copyright 2021 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proxy
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+)
+
+var (
+	// ErrRequestBodyTooLarge indicates the request body was larger than the api's max request body size.
+	ErrRequestBodyTooLarge = errors.New("request body too large")
+)
+
+// LimitRequestBody responds to requests whose content length is larger than maxBytes with status code 413;
+// requests without a content length (e.g. chunked requests) fail with ErrRequestBodyTooLarge once more than maxBytes have been read
+func LimitRequestBody(maxBytes int64, next http.Handler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.ContentLength > maxBytes {
+			http.Error(w, ErrRequestBodyTooLarge.Error(), http.StatusRequestEntityTooLarge)
+			return
+		}
+
+		if r.Body != nil && r.Body != http.NoBody {
+			r.Body = &limitedBody{ReadCloser: r.Body, remaining: maxBytes}
+		}
+		next.ServeHTTP(w, r)
+	}
+}
+
+type limitedBody struct {
+	io.ReadCloser
+	remaining int64
+}
+
+func (b *limitedBody) Read(p []byte) (int, error) {
+	// read one more byte than the limit, to detect whether the body is larger than the limit
+	if int64(len(p)) > b.remaining+1 {
+		p = p[:b.remaining+1]
+	}
+
+	n, err := b.ReadCloser.Read(p)
+	if int64(n) <= b.remaining {
+		b.remaining -= int64(n)
+		return n, err
+	}
+
+	n = int(b.remaining)
+	b.remaining = 0
+	return n, ErrRequestBodyTooLarge
+}
+
+// UpstreamTimeouts aborts requests which the user container doesn't finish responding to within timeout, or which it doesn't send
+// any part of its response for within idleTimeout (either can be 0 to disable it); the request's context is cancelled when it's aborted
+func UpstreamTimeouts(timeout time.Duration, idleTimeout time.Duration, next http.Handler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithCancel(r.Context())
+		defer cancel()
+
+		if timeout > 0 {
+			var cancelTimeout context.CancelFunc
+			ctx, cancelTimeout = context.WithTimeout(ctx, timeout)
+			defer cancelTimeout()
+		}
+
+		if idleTimeout > 0 {
+			idleTimer := time.AfterFunc(idleTimeout, cancel)
+			defer idleTimer.Stop()
+			w = &idleResponseWriter{ResponseWriter: w, idleTimer: idleTimer, idleTimeout: idleTimeout}
+		}
+
+		next.ServeHTTP(w, r.WithContext(ctx))
+	}
+}
+
+// idleResponseWriter restarts the idle timer whenever part of the response is written
+type idleResponseWriter struct {
+	http.ResponseWriter
+	idleTimer   *time.Timer
+	idleTimeout time.Duration
+}
+
+func (rw *idleResponseWriter) WriteHeader(statusCode int) {
+	rw.idleTimer.Reset(rw.idleTimeout)
+	rw.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (rw *idleResponseWriter) Write(b []byte) (int, error) {
+	rw.idleTimer.Reset(rw.idleTimeout)
+	return rw.ResponseWriter.Write(b)
+}
+
+func (rw *idleResponseWriter) Flush() {
+	if flusher, ok := rw.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
@@ -0,0 +1,85 @@
+/*
+Copyright 2021 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proxy_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cortexlabs/cortex/pkg/proxy"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLimitRequestBody(t *testing.T) {
+	var body []byte
+	var readErr error
+	h := proxy.LimitRequestBody(5, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, readErr = ioutil.ReadAll(r.Body)
+	}))
+
+	rec := httptest.NewRecorder()
+	h(rec, httptest.NewRequest(http.MethodPost, userContainerHost, strings.NewReader("hello")))
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.NoError(t, readErr)
+	require.Equal(t, "hello", string(body))
+
+	rec = httptest.NewRecorder()
+	h(rec, httptest.NewRequest(http.MethodPost, userContainerHost, strings.NewReader("hello world")))
+	require.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+
+	// without a content length, the body can't be rejected before it's read
+	req := httptest.NewRequest(http.MethodPost, userContainerHost, strings.NewReader("hello world"))
+	req.ContentLength = -1
+	h(httptest.NewRecorder(), req)
+	require.Equal(t, proxy.ErrRequestBodyTooLarge, readErr)
+	require.Equal(t, "hello", string(body))
+}
+
+func TestUpstreamTimeouts(t *testing.T) {
+	// the upstream sends part of its response every 20ms, so the idle timeout isn't reached
+	streaming := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for i := 0; i < 5; i++ {
+			select {
+			case <-r.Context().Done():
+				return
+			case <-time.After(20 * time.Millisecond):
+				_, _ = w.Write([]byte("."))
+			}
+		}
+	})
+
+	rec := httptest.NewRecorder()
+	proxy.UpstreamTimeouts(0, 50*time.Millisecond, streaming)(rec, httptest.NewRequest(http.MethodGet, userContainerHost, nil))
+	require.Equal(t, ".....", rec.Body.String())
+
+	rec = httptest.NewRecorder()
+	proxy.UpstreamTimeouts(50*time.Millisecond, 0, streaming)(rec, httptest.NewRequest(http.MethodGet, userContainerHost, nil))
+	require.True(t, len(rec.Body.String()) < 5)
+
+	// the upstream doesn't respond
+	var ctxErr error
+	hanging := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+		ctxErr = r.Context().Err()
+	})
+	proxy.UpstreamTimeouts(0, 10*time.Millisecond, hanging)(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, userContainerHost, nil))
+	require.Error(t, ctxErr)
+}
@@ -0,0 +1,121 @@
+/*
+Copyright 2021 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proxy
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/cortexlabs/cortex/pkg/consts"
+	"github.com/cortexlabs/cortex/pkg/modelmanager"
+)
+
+const _modelReleaseTimeout = 5 * time.Second
+
+// ModelRouter acquires the model which is named in a request's model header from the model manager sidecar
+// (which downloads the model if necessary), and adds the local path of the model's files to the request before passing it on
+type ModelRouter struct {
+	modelManagerURL string
+	client          *http.Client
+}
+
+func NewModelRouter(modelManagerURL string) *ModelRouter {
+	return &ModelRouter{
+		modelManagerURL: strings.TrimSuffix(modelManagerURL, "/"),
+		client:          &http.Client{},
+	}
+}
+
+// Handler routes the requests which have the model header, and passes all other requests to next unchanged
+func (r *ModelRouter) Handler(next http.Handler) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		modelName := req.Header.Get(consts.ModelHeader)
+		if modelName == "" {
+			next.ServeHTTP(w, req)
+			return
+		}
+
+		modelPath, statusCode, err := r.acquire(req.Context(), modelName)
+		if err != nil {
+			http.Error(w, err.Error(), statusCode)
+			return
+		}
+		defer r.release(modelName)
+
+		req.Header.Set(consts.ModelPathHeader, modelPath)
+		next.ServeHTTP(w, req)
+	}
+}
+
+func (r *ModelRouter) acquire(ctx context.Context, modelName string) (string, int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, r.modelURL(modelName, "acquire"), nil)
+	if err != nil {
+		return "", http.StatusInternalServerError, err
+	}
+
+	res, err := r.client.Do(req)
+	if err != nil {
+		return "", http.StatusServiceUnavailable, fmt.Errorf("failed to load model %s: %w", modelName, err)
+	}
+	defer res.Body.Close()
+
+	body, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return "", http.StatusServiceUnavailable, fmt.Errorf("failed to load model %s: %w", modelName, err)
+	}
+
+	switch {
+	case res.StatusCode == http.StatusNotFound:
+		return "", http.StatusNotFound, fmt.Errorf("model %s was not found", modelName)
+	case res.StatusCode != http.StatusOK:
+		return "", http.StatusServiceUnavailable, fmt.Errorf("failed to load model %s: %s", modelName, strings.TrimSpace(string(body)))
+	}
+
+	var acquireResponse modelmanager.AcquireResponse
+	if err := json.Unmarshal(body, &acquireResponse); err != nil {
+		return "", http.StatusInternalServerError, fmt.Errorf("failed to load model %s: %w", modelName, err)
+	}
+
+	return acquireResponse.Path, http.StatusOK, nil
+}
+
+// the model is released even if the request was cancelled, so that it can be evicted
+func (r *ModelRouter) release(modelName string) {
+	ctx, cancel := context.WithTimeout(context.Background(), _modelReleaseTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, r.modelURL(modelName, "release"), nil)
+	if err != nil {
+		return
+	}
+
+	res, err := r.client.Do(req)
+	if err != nil {
+		return
+	}
+	_ = res.Body.Close()
+}
+
+func (r *ModelRouter) modelURL(modelName string, action string) string {
+	return r.modelManagerURL + "/models/" + url.PathEscape(modelName) + "/" + action
+}
@@ -0,0 +1,111 @@
+/*
+Copyright 2021 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proxy_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/cortexlabs/cortex/pkg/consts"
+	"github.com/cortexlabs/cortex/pkg/modelmanager"
+	"github.com/cortexlabs/cortex/pkg/proxy"
+	"github.com/stretchr/testify/require"
+)
+
+func newModelManagerServer(t *testing.T, download modelmanager.DownloadFunc) (*modelmanager.ModelManager, *httptest.Server) {
+	manager := modelmanager.New(modelmanager.Params{
+		Models:          map[string]string{"iris": "s3://bucket/models/iris"},
+		ModelsDir:       "/models",
+		MaxLoadedModels: 1,
+	}, download, nil)
+
+	server := httptest.NewServer(modelmanager.Handler(manager))
+	t.Cleanup(server.Close)
+
+	return manager, server
+}
+
+func TestModelRouterAddsModelPath(t *testing.T) {
+	manager, server := newModelManagerServer(t, func(s3Path string, localDir string) error {
+		return nil
+	})
+
+	var modelPath string
+	var inUse int
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		modelPath = r.Header.Get(consts.ModelPathHeader)
+		inUse = manager.Statuses()[0].InUse
+	})
+
+	req := httptest.NewRequest(http.MethodPost, userContainerHost, nil)
+	req.Header.Set(consts.ModelHeader, "iris")
+	rec := httptest.NewRecorder()
+	proxy.NewModelRouter(server.URL).Handler(next)(rec, req)
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, "/models/iris/1", modelPath)
+	require.Equal(t, 1, inUse)
+	require.Equal(t, 0, manager.Statuses()[0].InUse)
+}
+
+func TestModelRouterUnknownModel(t *testing.T) {
+	_, server := newModelManagerServer(t, nil)
+
+	var isHandlerCalled bool
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		isHandlerCalled = true
+	})
+
+	req := httptest.NewRequest(http.MethodPost, userContainerHost, nil)
+	req.Header.Set(consts.ModelHeader, "mnist")
+	rec := httptest.NewRecorder()
+	proxy.NewModelRouter(server.URL).Handler(next)(rec, req)
+
+	require.Equal(t, http.StatusNotFound, rec.Code)
+	require.False(t, isHandlerCalled)
+}
+
+func TestModelRouterFailedDownload(t *testing.T) {
+	_, server := newModelManagerServer(t, func(s3Path string, localDir string) error {
+		return errors.New("access denied")
+	})
+
+	req := httptest.NewRequest(http.MethodPost, userContainerHost, nil)
+	req.Header.Set(consts.ModelHeader, "iris")
+	rec := httptest.NewRecorder()
+	proxy.NewModelRouter(server.URL).Handler(http.NotFoundHandler())(rec, req)
+
+	require.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	require.Contains(t, rec.Body.String(), "access denied")
+}
+
+func TestModelRouterWithoutModelHeader(t *testing.T) {
+	var isHandlerCalled bool
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		isHandlerCalled = true
+		require.Empty(t, r.Header.Get(consts.ModelPathHeader))
+	})
+
+	// the model manager isn't called for requests without the model header
+	rec := httptest.NewRecorder()
+	proxy.NewModelRouter("http://localhost:0").Handler(next)(rec, httptest.NewRequest(http.MethodPost, userContainerHost, nil))
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.True(t, isHandlerCalled)
+}
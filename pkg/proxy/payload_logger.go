@@ -0,0 +1,219 @@
+/*
+Copyright 2021 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proxy
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"path"
+	"sync"
+	"time"
+
+	"github.com/cortexlabs/cortex/pkg/consts"
+	"github.com/cortexlabs/cortex/pkg/probe"
+)
+
+// the maximum number of records which are buffered between flushes; records are dropped once the buffer is full
+const _maxBufferedPayloadRecords = 10000
+
+// UploadFunc is the function signature used by the payload logger to write a batch of records
+type UploadFunc func(data []byte, bucket string, key string) error
+
+type PayloadLoggerParams struct {
+	APIName     string
+	PodName     string
+	Bucket      string
+	Prefix      string
+	SampleRate  float64
+	MaxBodySize int64
+}
+
+// PayloadRecord is a sampled request and its response; records are written to S3 as JSON lines
+type PayloadRecord struct {
+	Timestamp             time.Time `json:"timestamp"`
+	APIName               string    `json:"api_name"`
+	TrafficSplitter       string    `json:"traffic_splitter,omitempty"` // set if the request was routed by a traffic splitter
+	ClientIP              string    `json:"client_ip"`
+	Method                string    `json:"method"`
+	Path                  string    `json:"path"`
+	Query                 string    `json:"query,omitempty"`
+	RequestContentType    string    `json:"request_content_type,omitempty"`
+	RequestBody           string    `json:"request_body"`
+	RequestBodyTruncated  bool      `json:"request_body_truncated"`
+	StatusCode            int       `json:"status_code"`
+	ResponseContentType   string    `json:"response_content_type,omitempty"`
+	ResponseBody          string    `json:"response_body"`
+	ResponseBodyTruncated bool      `json:"response_body_truncated"`
+	Latency               float64   `json:"latency"` // in seconds
+}
+
+type PayloadLogger struct {
+	params   PayloadLoggerParams
+	uploadFn UploadFunc
+
+	mux     sync.Mutex
+	records []PayloadRecord
+	dropped int
+}
+
+func NewPayloadLogger(params PayloadLoggerParams, uploadFn UploadFunc) *PayloadLogger {
+	return &PayloadLogger{
+		params:   params,
+		uploadFn: uploadFn,
+	}
+}
+
+// Handler logs a sample of the requests which are handled by next
+func (pl *PayloadLogger) Handler(next http.Handler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if probe.IsRequestKubeletProbe(r) || rand.Float64() >= pl.params.SampleRate {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		startTime := time.Now()
+
+		var requestBody []byte
+		if r.Body != nil {
+			// read up to one byte past the limit to detect truncation, and forward the full body
+			requestBody, _ = ioutil.ReadAll(io.LimitReader(r.Body, pl.params.MaxBodySize+1))
+			r.Body = &replayedBody{
+				Reader: io.MultiReader(bytes.NewReader(requestBody), r.Body),
+				Closer: r.Body,
+			}
+		}
+
+		rw := &payloadResponseWriter{
+			ResponseWriter: w,
+			statusCode:     http.StatusOK,
+			maxBodySize:    pl.params.MaxBodySize,
+		}
+		next.ServeHTTP(rw, r)
+
+		record := PayloadRecord{
+			Timestamp:             startTime.UTC(),
+			APIName:               pl.params.APIName,
+			TrafficSplitter:       r.Header.Get(consts.TrafficSplitterHeader),
+			ClientIP:              ClientIP(r),
+			Method:                r.Method,
+			Path:                  r.URL.Path,
+			Query:                 r.URL.RawQuery,
+			RequestContentType:    r.Header.Get("Content-Type"),
+			StatusCode:            rw.statusCode,
+			ResponseContentType:   rw.Header().Get("Content-Type"),
+			ResponseBody:          rw.body.String(),
+			ResponseBodyTruncated: rw.truncated,
+			Latency:               time.Since(startTime).Seconds(),
+		}
+		if int64(len(requestBody)) > pl.params.MaxBodySize {
+			record.RequestBody = string(requestBody[:pl.params.MaxBodySize])
+			record.RequestBodyTruncated = true
+		} else {
+			record.RequestBody = string(requestBody)
+		}
+
+		pl.add(record)
+	}
+}
+
+func (pl *PayloadLogger) add(record PayloadRecord) {
+	pl.mux.Lock()
+	defer pl.mux.Unlock()
+
+	if len(pl.records) >= _maxBufferedPayloadRecords {
+		pl.dropped++
+		return
+	}
+	pl.records = append(pl.records, record)
+}
+
+// Flush writes the buffered records to S3, and returns the number of records which were dropped since the last flush
+func (pl *PayloadLogger) Flush() (int, error) {
+	pl.mux.Lock()
+	records := pl.records
+	dropped := pl.dropped
+	pl.records = nil
+	pl.dropped = 0
+	pl.mux.Unlock()
+
+	if len(records) == 0 {
+		return dropped, nil
+	}
+
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	for i := range records {
+		if err := encoder.Encode(records[i]); err != nil {
+			return dropped, err
+		}
+	}
+
+	if err := pl.uploadFn(buf.Bytes(), pl.params.Bucket, pl.objectKey(time.Now().UTC())); err != nil {
+		return dropped + len(records), err
+	}
+
+	return dropped, nil
+}
+
+// objects are partitioned by date, so that they can be queried by date (e.g. with Athena)
+func (pl *PayloadLogger) objectKey(now time.Time) string {
+	fileName := fmt.Sprintf("%d-%s.json", now.UnixNano(), pl.params.PodName)
+	return path.Join(pl.params.Prefix, pl.params.APIName, now.Format("2006/01/02"), fileName)
+}
+
+type replayedBody struct {
+	io.Reader
+	io.Closer
+}
+
+type payloadResponseWriter struct {
+	http.ResponseWriter
+	statusCode  int
+	body        bytes.Buffer
+	maxBodySize int64
+	truncated   bool
+}
+
+func (rw *payloadResponseWriter) WriteHeader(statusCode int) {
+	rw.statusCode = statusCode
+	rw.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (rw *payloadResponseWriter) Write(b []byte) (int, error) {
+	if remaining := rw.maxBodySize - int64(rw.body.Len()); remaining > 0 {
+		if int64(len(b)) > remaining {
+			rw.body.Write(b[:remaining])
+			rw.truncated = true
+		} else {
+			rw.body.Write(b)
+		}
+	} else if len(b) > 0 {
+		rw.truncated = true
+	}
+	return rw.ResponseWriter.Write(b)
+}
+
+func (rw *payloadResponseWriter) Flush() {
+	if flusher, ok := rw.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
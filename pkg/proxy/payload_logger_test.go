@@ -0,0 +1,96 @@
+/*
+Copyright 2021 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proxy_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/cortexlabs/cortex/pkg/proxy"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPayloadLogger(t *testing.T) {
+	var uploadedBucket string
+	var uploadedKey string
+	var uploaded []byte
+	uploadFn := func(data []byte, bucket string, key string) error {
+		uploadedBucket = bucket
+		uploadedKey = key
+		uploaded = data
+		return nil
+	}
+
+	payloadLogger := proxy.NewPayloadLogger(proxy.PayloadLoggerParams{
+		APIName:     "my-api",
+		PodName:     "my-pod",
+		Bucket:      "my-bucket",
+		Prefix:      "payloads",
+		SampleRate:  1,
+		MaxBodySize: 5,
+	}, uploadFn)
+
+	var receivedBody []byte
+	var handler http.HandlerFunc = func(w http.ResponseWriter, r *http.Request) {
+		receivedBody, _ = ioutil.ReadAll(r.Body)
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte("ok"))
+	}
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "http://user-container.cortex.dev/predict?id=1", strings.NewReader("hello world"))
+	payloadLogger.Handler(handler).ServeHTTP(resp, req)
+
+	// the request is forwarded and responded to unchanged
+	require.Equal(t, "hello world", string(receivedBody))
+	require.Equal(t, http.StatusCreated, resp.Code)
+	require.Equal(t, "ok", resp.Body.String())
+
+	dropped, err := payloadLogger.Flush()
+	require.NoError(t, err)
+	require.Equal(t, 0, dropped)
+
+	require.Equal(t, "my-bucket", uploadedBucket)
+	require.True(t, strings.HasPrefix(uploadedKey, "payloads/my-api/"))
+	require.True(t, strings.HasSuffix(uploadedKey, "-my-pod.json"))
+
+	lines := bytes.Split(bytes.TrimSpace(uploaded), []byte("\n"))
+	require.Len(t, lines, 1)
+
+	var record proxy.PayloadRecord
+	require.NoError(t, json.Unmarshal(lines[0], &record))
+	require.Equal(t, "my-api", record.APIName)
+	require.Equal(t, "/predict", record.Path)
+	require.Equal(t, "id=1", record.Query)
+	require.Equal(t, "192.0.2.1", record.ClientIP) // httptest's default remote address
+	require.Equal(t, "hello", record.RequestBody)
+	require.True(t, record.RequestBodyTruncated)
+	require.Equal(t, http.StatusCreated, record.StatusCode)
+	require.Equal(t, "ok", record.ResponseBody)
+	require.False(t, record.ResponseBodyTruncated)
+
+	// nothing is uploaded when there are no new records
+	uploaded = nil
+	_, err = payloadLogger.Flush()
+	require.NoError(t, err)
+	require.Nil(t, uploaded)
+}
@@ -0,0 +1,136 @@
+/*
+Copyright 2021 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proxy
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/cortexlabs/cortex/pkg/consts"
+	"github.com/cortexlabs/cortex/pkg/probe"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+type timingCtxKey struct{}
+
+type requestTiming struct {
+	received   time.Time
+	dispatched time.Time // zero if the request wasn't forwarded to the user container (e.g. if the queue was full)
+}
+
+// TimingReporter measures how long each request waits in the proxy (for a free slot in the api's concurrency, and for its model to be loaded)
+// and how long the user container takes to respond, and adds the times to the response's headers, so that clients can distinguish queuing delay from model latency
+type TimingReporter struct {
+	replicaID        string
+	queueDuration    prometheus.Histogram
+	upstreamDuration prometheus.Histogram
+}
+
+func NewTimingReporter(replicaID string) *TimingReporter {
+	buckets := prometheus.ExponentialBuckets(0.001, 2, 16) // 1ms to ~33s
+
+	return &TimingReporter{
+		replicaID: replicaID,
+		queueDuration: promauto.NewHistogram(prometheus.HistogramOpts{
+			Name:    "cortex_proxy_queue_duration_seconds",
+			Help:    "The time which requests to a cortex API spent in the proxy before they were forwarded to the user container",
+			Buckets: buckets,
+		}),
+		upstreamDuration: promauto.NewHistogram(prometheus.HistogramOpts{
+			Name:    "cortex_proxy_upstream_duration_seconds",
+			Help:    "The time which the user container of a cortex API took to process requests",
+			Buckets: buckets,
+		}),
+	}
+}
+
+// Handler must wrap the breaker, so that the time which requests spend in the queue is measured
+func (r *TimingReporter) Handler(next http.Handler) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		if probe.IsRequestKubeletProbe(req) {
+			next.ServeHTTP(w, req)
+			return
+		}
+
+		timing := &requestTiming{received: time.Now()}
+		rw := &timingResponseWriter{ResponseWriter: w, reporter: r, timing: timing}
+		next.ServeHTTP(rw, req.WithContext(context.WithValue(req.Context(), timingCtxKey{}, timing)))
+
+		if !timing.dispatched.IsZero() {
+			r.queueDuration.Observe(timing.dispatched.Sub(timing.received).Seconds())
+			r.upstreamDuration.Observe(time.Since(timing.dispatched).Seconds())
+		}
+	}
+}
+
+// UpstreamHandler must wrap the handler which forwards requests to the user container, to record when they were forwarded
+func (r *TimingReporter) UpstreamHandler(next http.Handler) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		if timing, ok := req.Context().Value(timingCtxKey{}).(*requestTiming); ok {
+			timing.dispatched = time.Now()
+		}
+		next.ServeHTTP(w, req)
+	}
+}
+
+// timingResponseWriter adds the headers when the response's headers are written, since the response may be streamed
+type timingResponseWriter struct {
+	http.ResponseWriter
+	reporter      *TimingReporter
+	timing        *requestTiming
+	headerWritten bool
+}
+
+func (rw *timingResponseWriter) WriteHeader(statusCode int) {
+	if !rw.headerWritten {
+		rw.headerWritten = true
+
+		now := time.Now()
+		header := rw.ResponseWriter.Header()
+		header.Set(consts.ReplicaIDHeader, rw.reporter.replicaID)
+		if rw.timing.dispatched.IsZero() {
+			header.Set(consts.QueueTimeHeader, millisecondsStr(now.Sub(rw.timing.received)))
+		} else {
+			header.Set(consts.QueueTimeHeader, millisecondsStr(rw.timing.dispatched.Sub(rw.timing.received)))
+			header.Set(consts.UpstreamTimeHeader, millisecondsStr(now.Sub(rw.timing.dispatched)))
+		}
+	}
+	rw.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (rw *timingResponseWriter) Write(b []byte) (int, error) {
+	if !rw.headerWritten {
+		rw.WriteHeader(http.StatusOK)
+	}
+	return rw.ResponseWriter.Write(b)
+}
+
+func (rw *timingResponseWriter) Flush() {
+	if !rw.headerWritten {
+		rw.WriteHeader(http.StatusOK)
+	}
+	if flusher, ok := rw.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+func millisecondsStr(duration time.Duration) string {
+	return strconv.FormatFloat(float64(duration)/float64(time.Millisecond), 'f', 3, 64)
+}
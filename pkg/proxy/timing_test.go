@@ -0,0 +1,73 @@
+/*
+Copyright 2021 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proxy_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/cortexlabs/cortex/pkg/consts"
+	"github.com/cortexlabs/cortex/pkg/proxy"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTimingReporter(t *testing.T) {
+	// the metrics are registered globally, so the reporter can only be created once
+	reporter := proxy.NewTimingReporter("api-my-api-5d8f7c6b9-x2kqp")
+
+	upstream := reporter.UpstreamHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(20 * time.Millisecond)
+		_, _ = w.Write([]byte("ok"))
+	}))
+
+	t.Run("forwarded", func(t *testing.T) {
+		h := reporter.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			time.Sleep(10 * time.Millisecond) // waiting in the queue
+			upstream(w, r)
+		}))
+
+		rec := httptest.NewRecorder()
+		h(rec, httptest.NewRequest(http.MethodGet, userContainerHost, nil))
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Equal(t, "api-my-api-5d8f7c6b9-x2kqp", rec.Header().Get(consts.ReplicaIDHeader))
+
+		queueTime, err := strconv.ParseFloat(rec.Header().Get(consts.QueueTimeHeader), 64)
+		require.NoError(t, err)
+		require.GreaterOrEqual(t, queueTime, 10.0)
+
+		upstreamTime, err := strconv.ParseFloat(rec.Header().Get(consts.UpstreamTimeHeader), 64)
+		require.NoError(t, err)
+		require.GreaterOrEqual(t, upstreamTime, 20.0)
+	})
+
+	t.Run("rejected", func(t *testing.T) {
+		h := reporter.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, proxy.ErrRequestQueueFull.Error(), http.StatusServiceUnavailable)
+		}))
+
+		rec := httptest.NewRecorder()
+		h(rec, httptest.NewRequest(http.MethodGet, userContainerHost, nil))
+
+		require.Equal(t, http.StatusServiceUnavailable, rec.Code)
+		require.NotEmpty(t, rec.Header().Get(consts.QueueTimeHeader))
+		require.Empty(t, rec.Header().Get(consts.UpstreamTimeHeader))
+	})
+}
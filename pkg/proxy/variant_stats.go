@@ -0,0 +1,81 @@
+/*
+Copyright 2021 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proxy
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/cortexlabs/cortex/pkg/consts"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// VariantStatsReporter records the requests which are routed to the api by a traffic splitter, so that
+// the traffic splitter's variants can be compared (the api_name label is added when the metrics are scraped)
+type VariantStatsReporter struct {
+	requests        *prometheus.CounterVec
+	requestDuration *prometheus.HistogramVec
+}
+
+func NewVariantStatsReporter() *VariantStatsReporter {
+	return &VariantStatsReporter{
+		requests: promauto.NewCounterVec(prometheus.CounterOpts{
+			Name: "cortex_variant_requests_total",
+			Help: "The number of requests routed to a cortex API by a traffic splitter",
+		}, []string{"traffic_splitter", "response_code"}),
+		requestDuration: promauto.NewHistogramVec(prometheus.HistogramOpts{
+			Name: "cortex_variant_request_duration_seconds",
+			Help: "The duration of requests routed to a cortex API by a traffic splitter",
+		}, []string{"traffic_splitter"}),
+	}
+}
+
+// Handler records the requests which have the traffic splitter header, and passes all requests to next
+func (r *VariantStatsReporter) Handler(next http.Handler) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		trafficSplitter := req.Header.Get(consts.TrafficSplitterHeader)
+		if trafficSplitter == "" {
+			next.ServeHTTP(w, req)
+			return
+		}
+
+		startTime := time.Now()
+		rw := &statusResponseWriter{ResponseWriter: w, statusCode: http.StatusOK}
+		next.ServeHTTP(rw, req)
+
+		r.requests.WithLabelValues(trafficSplitter, strconv.Itoa(rw.statusCode)).Inc()
+		r.requestDuration.WithLabelValues(trafficSplitter).Observe(time.Since(startTime).Seconds())
+	}
+}
+
+type statusResponseWriter struct {
+	http.ResponseWriter
+	statusCode int
+}
+
+func (rw *statusResponseWriter) WriteHeader(statusCode int) {
+	rw.statusCode = statusCode
+	rw.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (rw *statusResponseWriter) Flush() {
+	if flusher, ok := rw.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
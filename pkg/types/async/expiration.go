@@ -0,0 +1,70 @@
+/*
+Copyright 2021 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package async
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/sqs"
+)
+
+// ExpirationHeader sets the expiration of a single workload, either as a duration (e.g. "5m") or as a number of seconds;
+// it takes precedence over the api's expiration
+const ExpirationHeader = "X-Cortex-Expiration"
+
+// ExpiresAtMessageAttribute is the sqs message attribute which holds the unix time (in seconds) after which a workload expires
+const ExpiresAtMessageAttribute = "cortex.expires_at"
+
+// ParseExpiration parses the value of the ExpirationHeader
+func ParseExpiration(value string) (time.Duration, bool) {
+	if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.Duration(seconds) * time.Second, seconds > 0
+	}
+	expiration, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, false
+	}
+	return expiration, expiration > 0
+}
+
+// ExpiresAtAttributes returns the sqs message attributes which hold the time after which a workload expires
+func ExpiresAtAttributes(expiresAt time.Time) map[string]*sqs.MessageAttributeValue {
+	return map[string]*sqs.MessageAttributeValue{
+		ExpiresAtMessageAttribute: {
+			DataType:    aws.String("Number"),
+			StringValue: aws.String(strconv.FormatInt(expiresAt.Unix(), 10)),
+		},
+	}
+}
+
+// ExpiresAt returns the time after which the message's workload expires, or nil if it doesn't expire
+func ExpiresAt(message *sqs.Message) *time.Time {
+	attribute, ok := message.MessageAttributes[ExpiresAtMessageAttribute]
+	if !ok || attribute == nil || attribute.StringValue == nil {
+		return nil
+	}
+
+	seconds, err := strconv.ParseInt(*attribute.StringValue, 10, 64)
+	if err != nil {
+		return nil
+	}
+
+	expiresAt := time.Unix(seconds, 0)
+	return &expiresAt
+}
@@ -0,0 +1,50 @@
+/*
+Copyright 2021 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package async
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/service/sqs"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseExpiration(t *testing.T) {
+	for value, expected := range map[string]time.Duration{
+		"30":  30 * time.Second,
+		"5m":  5 * time.Minute,
+		"1h":  time.Hour,
+		"0":   0,
+		"-1":  0,
+		"-5m": 0,
+		"abc": 0,
+	} {
+		expiration, ok := ParseExpiration(value)
+		require.Equal(t, expected > 0, ok, value)
+		if ok {
+			require.Equal(t, expected, expiration, value)
+		}
+	}
+}
+
+func TestExpiresAt(t *testing.T) {
+	expiresAt := time.Unix(time.Now().Add(time.Minute).Unix(), 0)
+
+	require.Equal(t, &expiresAt, ExpiresAt(&sqs.Message{MessageAttributes: ExpiresAtAttributes(expiresAt)}))
+	require.Nil(t, ExpiresAt(&sqs.Message{}))
+}
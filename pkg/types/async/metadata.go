@@ -0,0 +1,66 @@
+/*
+Copyright 2021 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package async
+
+import (
+	"net/http"
+	"strings"
+)
+
+// MetadataHeaderPrefix is the prefix of the headers which attach metadata to a workload,
+// e.g. "X-Cortex-Metadata-Customer: acme" sets the workload's "customer" metadata key to "acme"
+const MetadataHeaderPrefix = "X-Cortex-Metadata-"
+
+const (
+	MaxMetadataKeys = 32
+	MaxMetadataSize = 8192 // the total size of the keys and values in bytes
+)
+
+// MetadataFromHeaders returns the metadata in the headers (with lower-cased keys), or nil if there is none
+func MetadataFromHeaders(header http.Header) map[string]string {
+	var metadata map[string]string
+	for name, values := range header {
+		if len(values) == 0 || !strings.HasPrefix(name, MetadataHeaderPrefix) {
+			continue
+		}
+		key := strings.ToLower(strings.TrimPrefix(name, MetadataHeaderPrefix))
+		if key == "" {
+			continue
+		}
+		if metadata == nil {
+			metadata = map[string]string{}
+		}
+		metadata[key] = values[0]
+	}
+	return metadata
+}
+
+// SetMetadataHeaders sets the headers which carry the metadata to the user's container
+func SetMetadataHeaders(header http.Header, metadata map[string]string) {
+	for key, value := range metadata {
+		header.Set(MetadataHeaderPrefix+key, value)
+	}
+}
+
+// MetadataSize returns the total size of the metadata's keys and values in bytes
+func MetadataSize(metadata map[string]string) int {
+	size := 0
+	for key, value := range metadata {
+		size += len(key) + len(value)
+	}
+	return size
+}
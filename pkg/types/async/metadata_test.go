@@ -0,0 +1,46 @@
+/*
+Copyright 2021 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package async
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetadataFromHeaders(t *testing.T) {
+	header := http.Header{}
+	header.Set("Content-Type", "application/json")
+	header.Set("X-Cortex-Metadata-Customer", "acme")
+	header.Set("x-cortex-metadata-batch-id", "42")
+	header.Set("X-Cortex-Metadata-", "ignored")
+
+	require.Equal(t, map[string]string{"customer": "acme", "batch-id": "42"}, MetadataFromHeaders(header))
+	require.Nil(t, MetadataFromHeaders(http.Header{"Content-Type": []string{"text/plain"}}))
+}
+
+func TestSetMetadataHeaders(t *testing.T) {
+	metadata := map[string]string{"customer": "acme", "batch-id": "42"}
+
+	header := http.Header{}
+	SetMetadataHeaders(header, metadata)
+
+	require.Equal(t, "acme", header.Get("X-Cortex-Metadata-Customer"))
+	require.Equal(t, metadata, MetadataFromHeaders(header))
+	require.Equal(t, 22, MetadataSize(metadata))
+}
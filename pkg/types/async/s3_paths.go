@@ -32,6 +32,10 @@ func ResultPath(storagePath string, requestID string) string {
 	return fmt.Sprintf("%s/%s/result.json", storagePath, requestID)
 }
 
+func MetadataPath(storagePath string, requestID string) string {
+	return fmt.Sprintf("%s/%s/metadata.json", storagePath, requestID)
+}
+
 func StatusPrefixPath(storagePath string, requestID string) string {
 	return fmt.Sprintf("%s/%s/status", storagePath, requestID)
 }
@@ -26,17 +26,52 @@ const (
 	StatusInProgress Status = "in_progress"
 	StatusInQueue    Status = "in_queue"
 	StatusCompleted  Status = "completed"
+	StatusExpired    Status = "expired"
+	StatusCancelled  Status = "cancelled"
 )
 
 func (status Status) String() string {
 	return string(status)
 }
 
+// Finished returns whether the workload will not be processed any further
+func (status Status) Finished() bool {
+	switch status {
+	case StatusCompleted, StatusFailed, StatusExpired, StatusCancelled:
+		return true
+	default:
+		return false
+	}
+}
+
 func (status Status) Valid() bool {
 	switch status {
-	case StatusNotFound, StatusFailed, StatusInProgress, StatusInQueue, StatusCompleted:
+	case StatusNotFound, StatusFailed, StatusInProgress, StatusInQueue, StatusCompleted, StatusExpired, StatusCancelled:
 		return true
 	default:
 		return false
 	}
 }
+
+// the order in which finished statuses take precedence over each other; a workload can be cancelled while the dequeuer is
+// finishing it, in which case the status which was written by the dequeuer is the workload's actual outcome
+var _finishedStatusPrecedence = []Status{StatusCompleted, StatusFailed, StatusExpired, StatusCancelled}
+
+// ResolveStatus returns a workload's status given all of the status files which were written for it
+func ResolveStatus(statuses []Status) Status {
+	for _, finished := range _finishedStatusPrecedence {
+		for _, status := range statuses {
+			if status == finished {
+				return status
+			}
+		}
+	}
+
+	for _, status := range statuses {
+		if status == StatusInProgress {
+			return StatusInProgress
+		}
+	}
+
+	return StatusInQueue
+}
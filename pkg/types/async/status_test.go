@@ -0,0 +1,34 @@
+/*
+Copyright 2021 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package async
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveStatus(t *testing.T) {
+	require.Equal(t, StatusInQueue, ResolveStatus([]Status{StatusInQueue}))
+	require.Equal(t, StatusInProgress, ResolveStatus([]Status{StatusInProgress, StatusInQueue}))
+	require.Equal(t, StatusCancelled, ResolveStatus([]Status{StatusCancelled, StatusInQueue}))
+	require.Equal(t, StatusCancelled, ResolveStatus([]Status{StatusCancelled, StatusInProgress, StatusInQueue}))
+
+	// the workload was cancelled after it completed (status files are listed in lexicographic order)
+	require.Equal(t, StatusCompleted, ResolveStatus([]Status{StatusCancelled, StatusCompleted, StatusInProgress, StatusInQueue}))
+	require.Equal(t, StatusFailed, ResolveStatus([]Status{StatusCancelled, StatusFailed, StatusInProgress, StatusInQueue}))
+}
@@ -17,10 +17,9 @@ limitations under the License.
 package clusterconfig
 
 import (
-	"bytes"
 	"encoding/json"
 	"fmt"
-	"text/template"
+	"strings"
 
 	"github.com/aws/aws-sdk-go/service/iam"
 	"github.com/cortexlabs/cortex/pkg/lib/aws"
@@ -36,90 +35,208 @@ func DefaultPolicyARN(accountID string, clusterName string, region string) strin
 	return fmt.Sprintf("arn:%s:iam::%s:policy/%s", aws.PartitionFromRegion(region), accountID, DefaultPolicyName(clusterName, region))
 }
 
-var _cortexPolicy = `
-{
-	"Version": "2012-10-17",
-	"Statement": [
+type CortexPolicyArgs struct {
+	ClusterName string
+	LogGroup    string
+	Region      string
+	Bucket      string
+	QueuePrefix string
+	AccountID   string
+	Karpenter   bool // whether the karpenter controller (which runs on the operator nodes) needs to launch and terminate instances
+}
+
+type policyDocument struct {
+	Version   string            `json:"Version"`
+	Statement []policyStatement `json:"Statement"`
+}
+
+type policyStatement struct {
+	Sid         string                            `json:"Sid"`
+	Effect      string                            `json:"Effect"`
+	Action      []string                          `json:"Action"`
+	Resource    []string                          `json:"Resource"`
+	Condition   map[string]map[string]interface{} `json:"Condition,omitempty"`
+	Description string                            `json:"-"` // used in the human-readable summary of the policy
+}
+
+// cortexPolicyStatements returns the statements of the least-privilege policy which is attached to the cluster's nodes;
+// the resources are scoped to the cluster's bucket, log group, and queue prefix wherever aws supports resource-level permissions
+func cortexPolicyStatements(args CortexPolicyArgs) []policyStatement {
+	partition := aws.PartitionFromRegion(args.Region)
+	bucketARN := fmt.Sprintf("arn:%s:s3:::%s", partition, args.Bucket)
+	logGroupARN := fmt.Sprintf("arn:%s:logs:%s:%s:log-group:%s", partition, args.Region, args.AccountID, args.LogGroup)
+
+	statements := []policyStatement{
 		{
-			"Action": [
-				"sts:GetCallerIdentity",
-				"ecr:GetAuthorizationToken",
-				"ecr:BatchGetImage",
-				"sqs:ListQueues",
-				"ec2:DescribeSpotPriceHistory"
-			],
-			"Effect": "Allow",
-			"Resource": "*"
+			Sid:         "AccountIdentity",
+			Action:      []string{"sts:GetCallerIdentity"},
+			Resource:    []string{"*"},
+			Description: "look up the cluster's account id",
 		},
 		{
-			"Effect": "Allow",
-			"Action": "sqs:*",
-			"Resource": "arn:*:sqs:{{ .Region }}:{{ .AccountID }}:cx_*"
+			Sid:         "PullImages",
+			Action:      []string{"ecr:GetAuthorizationToken", "ecr:BatchGetImage"},
+			Resource:    []string{"*"},
+			Description: "pull container images from ecr repositories",
 		},
 		{
-			"Effect": "Allow",
-			"Action": "s3:*",
-			"Resource": "arn:*:s3:::{{ .Bucket }}"
+			Sid:         "ReadMetrics",
+			Action:      []string{"sqs:ListQueues", "cloudwatch:GetMetricStatistics", "ec2:DescribeSpotPriceHistory"},
+			Resource:    []string{"*"},
+			Description: "list queues, read queue metrics, and read spot prices",
 		},
 		{
-			"Effect": "Allow",
-			"Action": "s3:*",
-			"Resource": "arn:*:s3:::{{ .Bucket }}/*"
+			Sid: "ManageQueues",
+			Action: []string{
+				"sqs:CreateQueue",
+				"sqs:DeleteQueue",
+				"sqs:GetQueueUrl",
+				"sqs:GetQueueAttributes",
+				"sqs:SetQueueAttributes",
+				"sqs:TagQueue",
+				"sqs:ListQueueTags",
+				"sqs:PurgeQueue",
+				"sqs:SendMessage",
+				"sqs:ReceiveMessage",
+				"sqs:DeleteMessage",
+				"sqs:ChangeMessageVisibility",
+			},
+			Resource:    []string{fmt.Sprintf("arn:%s:sqs:%s:%s:%s*", partition, args.Region, args.AccountID, args.QueuePrefix)},
+			Description: fmt.Sprintf("manage the queues of async and batch apis (queues whose names start with %s)", args.QueuePrefix),
 		},
 		{
-			"Effect": "Allow",
-			"Action": [
-				"logs:CreateLogStream",
-				"logs:DescribeLogStreams",
-				"logs:PutLogEvents",
-				"logs:CreateLogGroup"
-			],
-			"Resource": "arn:*:logs:{{ .Region }}:{{ .AccountID }}:log-group:{{ .LogGroup }}:*"
+			Sid:         "ListBucket",
+			Action:      []string{"s3:ListBucket", "s3:GetBucketLocation"},
+			Resource:    []string{bucketARN},
+			Description: fmt.Sprintf("list the objects in the %s bucket", args.Bucket),
 		},
 		{
-			"Effect": "Allow",
-			"Action": "logs:CreateLogGroup",
-			"Resource": "arn:*:logs:{{ .Region }}:{{ .AccountID }}:log-group:{{ .LogGroup }}"
-		}
-	]
-}
-`
+			Sid:         "ReadWriteBucket",
+			Action:      []string{"s3:GetObject", "s3:PutObject", "s3:DeleteObject", "s3:AbortMultipartUpload"},
+			Resource:    []string{bucketARN + "/*"},
+			Description: fmt.Sprintf("read, write, and delete the objects in the %s bucket", args.Bucket),
+		},
+		{
+			Sid:         "CreateLogGroup",
+			Action:      []string{"logs:CreateLogGroup"},
+			Resource:    []string{logGroupARN, logGroupARN + ":*"},
+			Description: fmt.Sprintf("create the %s log group", args.LogGroup),
+		},
+		{
+			Sid:         "WriteLogs",
+			Action:      []string{"logs:CreateLogStream", "logs:DescribeLogStreams", "logs:PutLogEvents"},
+			Resource:    []string{logGroupARN + ":*"},
+			Description: fmt.Sprintf("write logs to the %s log group", args.LogGroup),
+		},
+		{
+			Sid:         "QueryLogs",
+			Action:      []string{"logs:StartQuery"},
+			Resource:    []string{logGroupARN, logGroupARN + ":*"},
+			Description: fmt.Sprintf("query the %s log group with CloudWatch Insights (e.g. for `cortex logs --job` and `cortex logs --request`)", args.LogGroup),
+		},
+		{
+			Sid:         "ReadLogQueryResults",
+			Action:      []string{"logs:GetQueryResults", "logs:StopQuery"},
+			Resource:    []string{"*"},
+			Description: "read the results of CloudWatch Insights queries",
+		},
+	}
 
-type CortexPolicyTemplateArgs struct {
-	ClusterName string
-	LogGroup    string
-	Region      string
-	Bucket      string
-	AccountID   string
+	if args.Karpenter {
+		statements = append(statements, karpenterPolicyStatements(args)...)
+	}
+
+	return statements
 }
 
-func CreateDefaultPolicy(awsClient *aws.Client, args CortexPolicyTemplateArgs) error {
-	policyName := DefaultPolicyName(args.ClusterName, args.Region)
-	accountID, _, err := awsClient.GetCachedAccountID()
-	if err != nil {
-		return err
+// karpenterPolicyStatements returns the statements which allow karpenter to launch worker instances with the role of the cluster's nodes,
+// and to terminate the instances (and delete the launch templates) which it created
+func karpenterPolicyStatements(args CortexPolicyArgs) []policyStatement {
+	partition := aws.PartitionFromRegion(args.Region)
+	karpenterResourceCondition := map[string]map[string]interface{}{
+		"StringLike": {"ec2:ResourceTag/karpenter.sh/provisioner-name": "*"},
 	}
 
-	policyARN := DefaultPolicyARN(accountID, args.ClusterName, args.Region)
-	policyTemplate, err := template.New("policy").Parse(_cortexPolicy)
-	if err != nil {
-		return errors.Wrap(err, "failed to parse aws policy template")
+	return []policyStatement{
+		{
+			Sid: "KarpenterDescribe",
+			Action: []string{
+				"ec2:DescribeAvailabilityZones",
+				"ec2:DescribeImages",
+				"ec2:DescribeInstances",
+				"ec2:DescribeInstanceTypeOfferings",
+				"ec2:DescribeInstanceTypes",
+				"ec2:DescribeLaunchTemplates",
+				"ec2:DescribeSecurityGroups",
+				"ec2:DescribeSubnets",
+				"ssm:GetParameter",
+				"pricing:GetProducts",
+				"eks:DescribeCluster",
+			},
+			Resource:    []string{"*"},
+			Description: "look up instance types, prices, amis, subnets, and security groups for karpenter",
+		},
+		{
+			Sid:         "KarpenterLaunchInstances",
+			Action:      []string{"ec2:CreateFleet", "ec2:RunInstances", "ec2:CreateLaunchTemplate", "ec2:CreateTags"},
+			Resource:    []string{"*"},
+			Description: "launch worker instances with karpenter",
+		},
+		{
+			Sid:         "KarpenterTerminateInstances",
+			Action:      []string{"ec2:TerminateInstances", "ec2:DeleteLaunchTemplate"},
+			Resource:    []string{"*"},
+			Condition:   karpenterResourceCondition,
+			Description: "terminate the instances and delete the launch templates which were created by karpenter",
+		},
+		{
+			Sid:         "KarpenterPassNodeRole",
+			Action:      []string{"iam:PassRole"},
+			Resource:    []string{fmt.Sprintf("arn:%s:iam::%s:role/eksctl-%s-nodegroup-*", partition, args.AccountID, args.ClusterName)},
+			Description: "launch worker instances with the role of the cluster's nodes",
+		},
+	}
+}
+
+// CortexPolicyDocument returns the compact json document of the policy which is attached to the cluster's nodes
+func CortexPolicyDocument(args CortexPolicyArgs) (string, error) {
+	statements := cortexPolicyStatements(args)
+	for i := range statements {
+		statements[i].Effect = "Allow"
 	}
 
-	buf := &bytes.Buffer{}
-	err = policyTemplate.Execute(buf, args)
+	policyBytes, err := json.Marshal(policyDocument{
+		Version:   "2012-10-17",
+		Statement: statements,
+	})
 	if err != nil {
-		return errors.Wrap(err, "failed to execute aws policy template")
+		return "", errors.Wrap(err, "failed to generate aws policy json")
 	}
 
-	compactBuf := &bytes.Buffer{}
+	return string(policyBytes), nil
+}
+
+// CortexPolicySummary returns a human-readable summary of the policy which is attached to the cluster's nodes
+func CortexPolicySummary(args CortexPolicyArgs) string {
+	summary := fmt.Sprintf("the %s iam policy allows the cluster to:\n", DefaultPolicyName(args.ClusterName, args.Region))
+	for _, statement := range cortexPolicyStatements(args) {
+		summary += fmt.Sprintf("  - %s (%s)\n", statement.Description, strings.Join(statement.Action, ", "))
+	}
+	return summary
+}
 
-	err = json.Compact(compactBuf, buf.Bytes())
+func CreateDefaultPolicy(awsClient *aws.Client, args CortexPolicyArgs) error {
+	policyName := DefaultPolicyName(args.ClusterName, args.Region)
+	accountID, _, err := awsClient.GetCachedAccountID()
 	if err != nil {
-		return errors.Wrap(err, "failed to parse and remove whitespace from aws policy json")
+		return err
 	}
 
-	policyDocument := compactBuf.String()
+	policyARN := DefaultPolicyARN(accountID, args.ClusterName, args.Region)
+	policyDocument, err := CortexPolicyDocument(args)
+	if err != nil {
+		return err
+	}
 
 	_, err = awsClient.IAM().CreatePolicy(&iam.CreatePolicyInput{
 		PolicyDocument: &policyDocument,
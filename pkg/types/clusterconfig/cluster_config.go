@@ -34,10 +34,15 @@ import (
 	cr "github.com/cortexlabs/cortex/pkg/lib/configreader"
 	"github.com/cortexlabs/cortex/pkg/lib/errors"
 	"github.com/cortexlabs/cortex/pkg/lib/hash"
+	"github.com/cortexlabs/cortex/pkg/lib/k8s"
 	libmath "github.com/cortexlabs/cortex/pkg/lib/math"
 	"github.com/cortexlabs/cortex/pkg/lib/pointer"
 	"github.com/cortexlabs/cortex/pkg/lib/sets/strset"
 	"github.com/cortexlabs/cortex/pkg/lib/slices"
+	"github.com/cortexlabs/cortex/pkg/lib/urls"
+	"github.com/cortexlabs/cortex/pkg/types/rbac"
+	"github.com/cortexlabs/cortex/pkg/types/spec"
+	"github.com/cortexlabs/cortex/pkg/types/userconfig"
 	"github.com/cortexlabs/yaml"
 )
 
@@ -50,12 +55,45 @@ const (
 	// In this case, _ was chosen to simplify the retrieval of information for the queue's name,
 	// since the api naming scheme does not allow this character.
 	SQSQueueDelimiter = "_"
+	// NodeGroupNameLabelKey is the node label which eksctl sets to the name of the node's EKS node group (e.g. cx-wd-<node_group_name>)
+	NodeGroupNameLabelKey = "alpha.eksctl.io/nodegroup-name"
+	// GPUSharingFactorLabelKey is the node label which is set to the gpu_sharing_factor of node groups which share their GPUs
+	GPUSharingFactorLabelKey = "cortex.dev/gpu-sharing-factor"
+	// SharedGPUResourceName is the resource which the nvidia device plugin advertises on nodes which share their GPUs (one unit per replica which can share a GPU)
+	SharedGPUResourceName = "nvidia.com/gpu.shared"
+	// OnDemandNodeGroupPrefix and SpotNodeGroupPrefix are prepended to node group names to form the EKS node group names
+	OnDemandNodeGroupPrefix = "cx-wd-"
+	SpotNodeGroupPrefix     = "cx-ws-"
+
+	// NodeProvisionerClusterAutoscaler and NodeProvisionerKarpenter are the supported values of node_provisioner
+	NodeProvisionerClusterAutoscaler = "cluster-autoscaler"
+	NodeProvisionerKarpenter         = "karpenter"
+
+	// AMIFamilyAL2, AMIFamilyBottlerocket and AMIFamilyWindows are the supported values of a node group's ami_family
+	AMIFamilyAL2          = "al2"
+	AMIFamilyBottlerocket = "bottlerocket"
+	AMIFamilyWindows      = "windows"
+
+	// WindowsTaintKey is the key of the taint which is applied to the instances of windows node groups (so that only windows apis are scheduled on them)
+	WindowsTaintKey = "os"
+
+	MTLSModeStrict     = "strict"
+	MTLSModePermissive = "permissive"
+
+	KubernetesAccessRoleAdmin    = "admin"
+	KubernetesAccessRoleReadOnly = "read-only"
+
+	SpotAllocationStrategyLowestPrice                  = "lowest-price"
+	SpotAllocationStrategyCapacityOptimized            = "capacity-optimized"
+	SpotAllocationStrategyCapacityOptimizedPrioritized = "capacity-optimized-prioritized"
 )
 
 var (
 	_maxNodeGroupLengthWithPrefix = 32
-	_maxNodeGroupLength           = _maxNodeGroupLengthWithPrefix - len("cx-wd-") // or cx-ws-
+	_maxNodeGroupLength           = _maxNodeGroupLengthWithPrefix - len(OnDemandNodeGroupPrefix) // or SpotNodeGroupPrefix
 	_maxInstancePools             = 20
+	_maxGPUSharingFactor          = int64(16)
+	_maxPreBootstrapCommandsBytes = 10 * 1024 // EC2 user data is limited to 16KB, and the rest is reserved for the bootstrap script
 	_defaultIAMPolicies           = []string{"arn:aws:iam::aws:policy/AmazonS3FullAccess"}
 	_invalidTagPrefixes           = []string{"kubernetes.io/", "k8s.io/", "eksctl.", "alpha.eksctl.", "beta.eksctl.", "aws:", "Aws:", "aWs:", "awS:", "aWS:", "AwS:", "aWS:", "AWS:"}
 
@@ -70,6 +108,8 @@ var (
 
 	// This regex is stricter than the actual S3 rules
 	_strictS3BucketRegex = regexp.MustCompile(`^([a-z0-9])+(-[a-z0-9]+)*$`)
+
+	_elasticIPAllocationIDRegex = regexp.MustCompile(`^eipalloc-[0-9a-f]+$`)
 )
 
 type CoreConfig struct {
@@ -81,8 +121,9 @@ type CoreConfig struct {
 	IstioNamespace string `json:"istio_namespace" yaml:"istio_namespace"`
 
 	// User-specifiable fields
-	ClusterName string `json:"cluster_name" yaml:"cluster_name"`
-	Region      string `json:"region" yaml:"region"`
+	ClusterName string      `json:"cluster_name" yaml:"cluster_name"`
+	Region      string      `json:"region" yaml:"region"`
+	AssumeRole  *AssumeRole `json:"assume_role,omitempty" yaml:"assume_role,omitempty"`
 
 	// User-specifiable fields
 	ImageOperator                   string `json:"image_operator" yaml:"image_operator"`
@@ -93,7 +134,10 @@ type CoreConfig struct {
 	ImageAsyncGateway               string `json:"image_async_gateway" yaml:"image_async_gateway"`
 	ImageEnqueuer                   string `json:"image_enqueuer" yaml:"image_enqueuer"`
 	ImageDequeuer                   string `json:"image_dequeuer" yaml:"image_dequeuer"`
+	ImageDownloader                 string `json:"image_downloader" yaml:"image_downloader"`
+	ImageModelManager               string `json:"image_model_manager" yaml:"image_model_manager"`
 	ImageClusterAutoscaler          string `json:"image_cluster_autoscaler" yaml:"image_cluster_autoscaler"`
+	ImageKarpenter                  string `json:"image_karpenter" yaml:"image_karpenter"`
 	ImageMetricsServer              string `json:"image_metrics_server" yaml:"image_metrics_server"`
 	ImageInferentia                 string `json:"image_inferentia" yaml:"image_inferentia"`
 	ImageNvidia                     string `json:"image_nvidia" yaml:"image_nvidia"`
@@ -109,25 +153,289 @@ type CoreConfig struct {
 	ImagePrometheusNodeExporter     string `json:"image_prometheus_node_exporter" yaml:"image_prometheus_node_exporter"`
 	ImageKubeRBACProxy              string `json:"image_kube_rbac_proxy" yaml:"image_kube_rbac_proxy"`
 	ImageGrafana                    string `json:"image_grafana" yaml:"image_grafana"`
+	ImageAlertmanager               string `json:"image_alertmanager" yaml:"image_alertmanager"`
 	ImageEventExporter              string `json:"image_event_exporter" yaml:"image_event_exporter"`
 }
 
 type ManagedConfig struct {
-	NodeGroups                        []*NodeGroup       `json:"node_groups" yaml:"node_groups"`
-	Tags                              map[string]string  `json:"tags" yaml:"tags"`
-	AvailabilityZones                 []string           `json:"availability_zones" yaml:"availability_zones"`
-	SSLCertificateARN                 *string            `json:"ssl_certificate_arn,omitempty" yaml:"ssl_certificate_arn,omitempty"`
-	IAMPolicyARNs                     []string           `json:"iam_policy_arns" yaml:"iam_policy_arns"`
-	SubnetVisibility                  SubnetVisibility   `json:"subnet_visibility" yaml:"subnet_visibility"`
-	Subnets                           []*Subnet          `json:"subnets,omitempty" yaml:"subnets,omitempty"`
-	NATGateway                        NATGateway         `json:"nat_gateway" yaml:"nat_gateway"`
-	APILoadBalancerScheme             LoadBalancerScheme `json:"api_load_balancer_scheme" yaml:"api_load_balancer_scheme"`
-	OperatorLoadBalancerScheme        LoadBalancerScheme `json:"operator_load_balancer_scheme" yaml:"operator_load_balancer_scheme"`
-	APILoadBalancerCIDRWhiteList      []string           `json:"api_load_balancer_cidr_white_list,omitempty" yaml:"api_load_balancer_cidr_white_list,omitempty"`
-	OperatorLoadBalancerCIDRWhiteList []string           `json:"operator_load_balancer_cidr_white_list,omitempty" yaml:"operator_load_balancer_cidr_white_list,omitempty"`
-	VPCCIDR                           *string            `json:"vpc_cidr,omitempty" yaml:"vpc_cidr,omitempty"`
-	CortexPolicyARN                   string             `json:"cortex_policy_arn" yaml:"cortex_policy_arn"` // this field is not user facing
-	AccountID                         string             `json:"account_id" yaml:"account_id"`               // this field is not user facing
+	NodeGroups                        []*NodeGroup                      `json:"node_groups" yaml:"node_groups"`
+	NodeProvisioner                   string                            `json:"node_provisioner" yaml:"node_provisioner"`
+	Tags                              map[string]string                 `json:"tags" yaml:"tags"`
+	AvailabilityZones                 []string                          `json:"availability_zones" yaml:"availability_zones"`
+	SSLCertificateARN                 *string                           `json:"ssl_certificate_arn,omitempty" yaml:"ssl_certificate_arn,omitempty"`
+	IAMPolicyARNs                     []string                          `json:"iam_policy_arns" yaml:"iam_policy_arns"`
+	SubnetVisibility                  SubnetVisibility                  `json:"subnet_visibility" yaml:"subnet_visibility"`
+	Subnets                           []*Subnet                         `json:"subnets,omitempty" yaml:"subnets,omitempty"`
+	NATGateway                        NATGateway                        `json:"nat_gateway" yaml:"nat_gateway"`
+	NATGatewayElasticIPs              []string                          `json:"nat_gateway_elastic_ips,omitempty" yaml:"nat_gateway_elastic_ips,omitempty"`
+	APILoadBalancerScheme             LoadBalancerScheme                `json:"api_load_balancer_scheme" yaml:"api_load_balancer_scheme"`
+	APILoadBalancerType               LoadBalancerType                  `json:"api_load_balancer_type" yaml:"api_load_balancer_type"`
+	APILoadBalancerProxyProtocol      bool                              `json:"api_load_balancer_proxy_protocol" yaml:"api_load_balancer_proxy_protocol"`
+	APILoadBalancerAccessLogsS3Path   *string                           `json:"api_load_balancer_access_logs_s3_path,omitempty" yaml:"api_load_balancer_access_logs_s3_path,omitempty"`
+	APILoadBalancerWAFWebACLARN       *string                           `json:"api_load_balancer_waf_web_acl_arn,omitempty" yaml:"api_load_balancer_waf_web_acl_arn,omitempty"`
+	APIGateway                        *APIGateway                       `json:"api_gateway,omitempty" yaml:"api_gateway,omitempty"`
+	OperatorLoadBalancerScheme        LoadBalancerScheme                `json:"operator_load_balancer_scheme" yaml:"operator_load_balancer_scheme"`
+	APILoadBalancerCIDRWhiteList      []string                          `json:"api_load_balancer_cidr_white_list,omitempty" yaml:"api_load_balancer_cidr_white_list,omitempty"`
+	OperatorLoadBalancerCIDRWhiteList []string                          `json:"operator_load_balancer_cidr_white_list,omitempty" yaml:"operator_load_balancer_cidr_white_list,omitempty"`
+	VPCCIDR                           *string                           `json:"vpc_cidr,omitempty" yaml:"vpc_cidr,omitempty"`
+	RequireIMDSv2                     bool                              `json:"require_imdsv2" yaml:"require_imdsv2"`
+	SSMAccess                         bool                              `json:"ssm_access" yaml:"ssm_access"`
+	KubernetesAccess                  []*KubernetesAccess               `json:"kubernetes_access,omitempty" yaml:"kubernetes_access,omitempty"`
+	WebConsole                        bool                              `json:"web_console" yaml:"web_console"`
+	EFS                               *EFS                              `json:"efs,omitempty" yaml:"efs,omitempty"`
+	EFSFileSystemID                   string                            `json:"efs_file_system_id" yaml:"efs_file_system_id"` // this field is not user facing
+	GitOps                            *GitOps                           `json:"gitops,omitempty" yaml:"gitops,omitempty"`
+	Alerting                          *Alerting                         `json:"alerting,omitempty" yaml:"alerting,omitempty"`
+	OIDC                              *OIDC                             `json:"oidc,omitempty" yaml:"oidc,omitempty"`
+	MTLS                              *MTLS                             `json:"mtls,omitempty" yaml:"mtls,omitempty"`
+	Quotas                            []*Quota                          `json:"quotas,omitempty" yaml:"quotas,omitempty"`
+	Budget                            *Budget                           `json:"budget,omitempty" yaml:"budget,omitempty"`
+	Logging                           *Logging                          `json:"logging" yaml:"logging"`
+	OperatorSSLCertificateARN         *string                           `json:"operator_ssl_certificate_arn,omitempty" yaml:"operator_ssl_certificate_arn,omitempty"`
+	SelfSignedCertificateValidity     string                            `json:"self_signed_certificate_validity" yaml:"self_signed_certificate_validity"`
+	RegistryCredentials               []*userconfig.RegistryCredentials `json:"registry_credentials" yaml:"registry_credentials"` // used by all apis
+	CortexPolicyARN                   string                            `json:"cortex_policy_arn" yaml:"cortex_policy_arn"`       // this field is not user facing
+	AccountID                         string                            `json:"account_id" yaml:"account_id"`                     // this field is not user facing
+}
+
+// AssumeRole configures the role which the cli assumes to manage the cluster (e.g. in organizations which prohibit long-lived IAM user keys)
+type AssumeRole struct {
+	RoleARN        string            `json:"role_arn" yaml:"role_arn"`
+	SourceRoleARNs []string          `json:"source_role_arns" yaml:"source_role_arns"` // assumed in order before role_arn (role chaining)
+	ExternalID     *string           `json:"external_id" yaml:"external_id"`
+	SessionTags    map[string]string `json:"session_tags" yaml:"session_tags"`
+	MFASerial      *string           `json:"mfa_serial" yaml:"mfa_serial"`
+}
+
+// CredentialsConfig returns the aws credentials config for the role (the MFA token provider must be set by the caller)
+func (assumeRole *AssumeRole) CredentialsConfig() aws.CredentialsConfig {
+	credentialsConfig := aws.CredentialsConfig{
+		RoleARN:        assumeRole.RoleARN,
+		SourceRoleARNs: assumeRole.SourceRoleARNs,
+		SessionTags:    assumeRole.SessionTags,
+	}
+	if assumeRole.ExternalID != nil {
+		credentialsConfig.ExternalID = *assumeRole.ExternalID
+	}
+	if assumeRole.MFASerial != nil {
+		credentialsConfig.MFASerial = *assumeRole.MFASerial
+	}
+	return credentialsConfig
+}
+
+type EFS struct {
+	PerformanceMode string `json:"performance_mode" yaml:"performance_mode"`
+}
+
+type GitOps struct {
+	Source      string  `json:"source" yaml:"source"`
+	Branch      string  `json:"branch" yaml:"branch"`
+	Path        string  `json:"path" yaml:"path"`
+	TokenSecret *string `json:"token_secret" yaml:"token_secret"`
+	SyncPeriod  string  `json:"sync_period" yaml:"sync_period"`
+	Prune       bool    `json:"prune" yaml:"prune"`
+}
+
+func (gitOps *GitOps) IsS3Source() bool {
+	return aws.IsValidS3Path(gitOps.Source)
+}
+
+// APIGateway configures an api gateway (http api) in front of the api load balancer (requires an internal application load balancer)
+type APIGateway struct {
+	ThrottlingRateLimit  *float64              `json:"throttling_rate_limit" yaml:"throttling_rate_limit"`
+	ThrottlingBurstLimit *int64                `json:"throttling_burst_limit" yaml:"throttling_burst_limit"`
+	Authorizer           *APIGatewayAuthorizer `json:"authorizer" yaml:"authorizer"`
+}
+
+type APIGatewayAuthorizer struct {
+	JWT    *JWTAuthorizer    `json:"jwt" yaml:"jwt"`
+	Lambda *LambdaAuthorizer `json:"lambda" yaml:"lambda"`
+}
+
+type JWTAuthorizer struct {
+	Issuer         string   `json:"issuer" yaml:"issuer"`
+	Audience       []string `json:"audience" yaml:"audience"`
+	IdentitySource string   `json:"identity_source" yaml:"identity_source"`
+}
+
+type LambdaAuthorizer struct {
+	FunctionARN    string   `json:"function_arn" yaml:"function_arn"`
+	IdentitySource []string `json:"identity_source" yaml:"identity_source"`
+	ResultTTL      string   `json:"result_ttl" yaml:"result_ttl"`
+}
+
+type Alerting struct {
+	Receivers       []*AlertReceiver `json:"receivers" yaml:"receivers"`
+	DefaultReceiver string           `json:"default_receiver" yaml:"default_receiver"`
+	Rules           *AlertRules      `json:"rules" yaml:"rules"`
+}
+
+type AlertReceiver struct {
+	Name      string                  `json:"name" yaml:"name"`
+	Slack     *SlackAlertReceiver     `json:"slack" yaml:"slack"`
+	PagerDuty *PagerDutyAlertReceiver `json:"pagerduty" yaml:"pagerduty"`
+	Webhook   *WebhookAlertReceiver   `json:"webhook" yaml:"webhook"`
+}
+
+type SlackAlertReceiver struct {
+	WebhookURL string  `json:"webhook_url" yaml:"webhook_url"`
+	Channel    *string `json:"channel" yaml:"channel"`
+}
+
+type PagerDutyAlertReceiver struct {
+	RoutingKey string `json:"routing_key" yaml:"routing_key"`
+}
+
+type WebhookAlertReceiver struct {
+	URL string `json:"url" yaml:"url"`
+}
+
+// a nil threshold disables the rule
+type AlertRules struct {
+	APIErrorRate          *float64 `json:"api_error_rate" yaml:"api_error_rate"`
+	PendingReplicasPeriod *string  `json:"pending_replicas_period" yaml:"pending_replicas_period"`
+	QueueAge              *string  `json:"queue_age" yaml:"queue_age"`
+	NodeNotReadyPeriod    *string  `json:"node_not_ready_period" yaml:"node_not_ready_period"`
+	JobTimedOut           *bool    `json:"job_timed_out" yaml:"job_timed_out"`
+}
+
+func (alerting *Alerting) GetReceiverNames() []string {
+	names := make([]string, len(alerting.Receivers))
+	for i, receiver := range alerting.Receivers {
+		names[i] = receiver.Name
+	}
+	return names
+}
+
+type OIDC struct {
+	IssuerURL     string             `json:"issuer_url" yaml:"issuer_url"`
+	ClientID      string             `json:"client_id" yaml:"client_id"`
+	Scopes        []string           `json:"scopes" yaml:"scopes"`
+	UsernameClaim string             `json:"username_claim" yaml:"username_claim"`
+	RolesClaim    string             `json:"roles_claim" yaml:"roles_claim"`
+	RoleMappings  []*OIDCRoleMapping `json:"role_mappings" yaml:"role_mappings"`
+	DefaultRole   *string            `json:"default_role" yaml:"default_role"` // if nil, users who don't match a role mapping are denied
+}
+
+// OIDCRoleMapping grants a role to users whose roles claim contains the value
+type OIDCRoleMapping struct {
+	Value     string `json:"value" yaml:"value"`
+	Role      string `json:"role" yaml:"role"`
+	APIPrefix string `json:"api_prefix" yaml:"api_prefix"`
+}
+
+// KubernetesAccess grants an IAM role or user access to the cluster's kubernetes api (e.g. to use kubectl), by mapping it to a kubernetes group in the aws-auth config map
+type KubernetesAccess struct {
+	ARN  string `json:"arn" yaml:"arn"`
+	Role string `json:"role" yaml:"role"` // admin (the cluster-admin cluster role) or read-only (the view cluster role, and read access to nodes)
+}
+
+// MTLS enables istio mutual TLS for the traffic which is sent to the pods of realtime and async apis
+type MTLS struct {
+	Mode string `json:"mode" yaml:"mode"` // strict only accepts mutual TLS traffic; permissive also accepts plaintext traffic (e.g. during a migration)
+}
+
+// Quota limits the compute which the realtime and async apis of a team can request (at their max replicas); unset limits are unlimited
+type Quota struct {
+	Team      string        `json:"team" yaml:"team"`
+	APIPrefix string        `json:"api_prefix" yaml:"api_prefix"` // apis whose names start with the prefix belong to the team
+	CPU       *k8s.Quantity `json:"cpu" yaml:"cpu"`
+	Mem       *k8s.Quantity `json:"mem" yaml:"mem"`
+	GPU       *int64        `json:"gpu" yaml:"gpu"`
+	Replicas  *int64        `json:"replicas" yaml:"replicas"`
+}
+
+// QuotaForAPI returns the quota of the team which the api belongs to (the quota with the longest matching api prefix), or nil if the api doesn't belong to a team
+func (mc *ManagedConfig) QuotaForAPI(apiName string) *Quota {
+	var apiQuota *Quota
+	for _, quota := range mc.Quotas {
+		if strings.HasPrefix(apiName, quota.APIPrefix) && (apiQuota == nil || len(quota.APIPrefix) > len(apiQuota.APIPrefix)) {
+			apiQuota = quota
+		}
+	}
+	return apiQuota
+}
+
+// Budget limits the projected hourly cost of the cluster and of each api (with the realtime and async apis at their max replicas); unset limits are unlimited
+type Budget struct {
+	MaxClusterCostPerHour *float64  `json:"max_cluster_cost_per_hour" yaml:"max_cluster_cost_per_hour"`
+	MaxAPICostPerHour     *float64  `json:"max_api_cost_per_hour" yaml:"max_api_cost_per_hour"`
+	WarningThresholds     []float64 `json:"warning_thresholds" yaml:"warning_thresholds"` // fractions of the limits at which deployments succeed with a warning
+}
+
+// Logging configures the logs of the cortex containers (e.g. the proxy, gateway, enqueuer, and dequeuer) of all apis; apis can override it
+type Logging struct {
+	Level  userconfig.LogLevel  `json:"level" yaml:"level"`
+	Format userconfig.LogFormat `json:"format" yaml:"format"`
+	Sinks  []*LogSink           `json:"sinks" yaml:"sinks"`
+
+	RetentionDays *int64      `json:"retention_days" yaml:"retention_days"` // retention of the cluster's CloudWatch log group; if nil, the log group's retention isn't changed (new log groups never expire)
+	Filters       *LogFilters `json:"filters" yaml:"filters"`
+}
+
+// LogFilters drop log lines before they're shipped to CloudWatch (and to the log sinks)
+type LogFilters struct {
+	MinLevel            userconfig.LogLevel `json:"min_level" yaml:"min_level"`
+	APIMinLevels        []*APIMinLogLevel   `json:"api_min_levels" yaml:"api_min_levels"`
+	AccessLogSampleRate float64             `json:"access_log_sample_rate" yaml:"access_log_sample_rate"` // fraction of access log lines (e.g. "GET /predict HTTP/1.1" 200) which are shipped
+}
+
+// APIMinLogLevel overrides the minimum level of the logs of the apis whose names start with the prefix (the longest matching prefix wins)
+type APIMinLogLevel struct {
+	APIPrefix string              `json:"api_prefix" yaml:"api_prefix"`
+	MinLevel  userconfig.LogLevel `json:"min_level" yaml:"min_level"`
+}
+
+// LogRetentionDays returns the retention of the cluster's CloudWatch log group, or nil if it shouldn't be changed
+func (mc *ManagedConfig) LogRetentionDays() *int64 {
+	if mc.Logging == nil {
+		return nil
+	}
+	return mc.Logging.RetentionDays
+}
+
+// the retention periods which CloudWatch supports, in days
+var _logRetentionDays = []int64{1, 3, 5, 7, 14, 30, 60, 90, 120, 150, 180, 365, 400, 545, 731, 1096, 1827, 2192, 2557, 2922, 3288, 3653}
+
+// LogSink forwards logs to a third-party destination (in addition to CloudWatch)
+type LogSink struct {
+	Name          string                `json:"name" yaml:"name"`
+	APIs          []string              `json:"apis" yaml:"apis"` // names of the apis whose logs are forwarded; if empty, all of the cluster's logs are forwarded
+	Datadog       *DatadogLogSink       `json:"datadog" yaml:"datadog"`
+	Loki          *LokiLogSink          `json:"loki" yaml:"loki"`
+	Elasticsearch *ElasticsearchLogSink `json:"elasticsearch" yaml:"elasticsearch"`
+	Splunk        *SplunkLogSink        `json:"splunk" yaml:"splunk"`
+}
+
+type DatadogLogSink struct {
+	APIKey string `json:"api_key" yaml:"api_key"`
+	Site   string `json:"site" yaml:"site"`
+}
+
+type LokiLogSink struct {
+	Host     string  `json:"host" yaml:"host"`
+	Port     int64   `json:"port" yaml:"port"`
+	TLS      bool    `json:"tls" yaml:"tls"`
+	TenantID *string `json:"tenant_id" yaml:"tenant_id"`
+	Username *string `json:"username" yaml:"username"`
+	Password *string `json:"password" yaml:"password"`
+}
+
+type ElasticsearchLogSink struct {
+	Host     string  `json:"host" yaml:"host"`
+	Port     int64   `json:"port" yaml:"port"`
+	TLS      bool    `json:"tls" yaml:"tls"`
+	Index    string  `json:"index" yaml:"index"`
+	Username *string `json:"username" yaml:"username"`
+	Password *string `json:"password" yaml:"password"`
+}
+
+type SplunkLogSink struct {
+	Host  string  `json:"host" yaml:"host"`
+	Port  int64   `json:"port" yaml:"port"`
+	TLS   bool    `json:"tls" yaml:"tls"`
+	Token string  `json:"token" yaml:"token"` // HTTP Event Collector token
+	Index *string `json:"index" yaml:"index"`
 }
 
 type NodeGroup struct {
@@ -141,6 +449,11 @@ type NodeGroup struct {
 	InstanceVolumeThroughput *int64      `json:"instance_volume_throughput" yaml:"instance_volume_throughput"`
 	Spot                     bool        `json:"spot" yaml:"spot"`
 	SpotConfig               *SpotConfig `json:"spot_config" yaml:"spot_config"`
+	PrepullImages            bool        `json:"prepull_images" yaml:"prepull_images"`
+	GPUSharingFactor         int64       `json:"gpu_sharing_factor" yaml:"gpu_sharing_factor"` // number of replicas which can share each GPU via time-slicing
+	Managed                  bool        `json:"managed" yaml:"managed"`                       // whether the node group is an EKS managed node group (rather than a self-managed auto scaling group)
+	AMIFamily                string      `json:"ami_family" yaml:"ami_family"`
+	PreBootstrapCommands     []string    `json:"pre_bootstrap_commands" yaml:"pre_bootstrap_commands"` // shell commands (powershell on windows) which run on each instance before it joins the cluster
 }
 
 type SpotConfig struct {
@@ -148,7 +461,9 @@ type SpotConfig struct {
 	OnDemandBaseCapacity                *int64   `json:"on_demand_base_capacity" yaml:"on_demand_base_capacity"`
 	OnDemandPercentageAboveBaseCapacity *int64   `json:"on_demand_percentage_above_base_capacity" yaml:"on_demand_percentage_above_base_capacity"`
 	MaxPrice                            *float64 `json:"max_price" yaml:"max_price"`
-	InstancePools                       *int64   `json:"instance_pools" yaml:"instance_pools"`
+	AllocationStrategy                  string   `json:"allocation_strategy" yaml:"allocation_strategy"`
+	InstancePools                       *int64   `json:"instance_pools" yaml:"instance_pools"`     // only applicable to the lowest-price allocation strategy
+	OnDemandBackup                      bool     `json:"on_demand_backup" yaml:"on_demand_backup"` // create an on-demand node group which the cluster autoscaler falls back to when spot instances can't be provisioned
 }
 
 type Subnet struct {
@@ -179,9 +494,10 @@ type InternalConfig struct {
 
 // The bare minimum to identify a cluster
 type AccessConfig struct {
-	ClusterName  string `json:"cluster_name" yaml:"cluster_name"`
-	Region       string `json:"region" yaml:"region"`
-	ImageManager string `json:"image_manager" yaml:"image_manager"`
+	ClusterName  string      `json:"cluster_name" yaml:"cluster_name"`
+	Region       string      `json:"region" yaml:"region"`
+	AssumeRole   *AssumeRole `json:"assume_role,omitempty" yaml:"assume_role,omitempty"`
+	ImageManager string      `json:"image_manager" yaml:"image_manager"`
 }
 
 // NewForFile initializes and validates the cluster config from the YAML config file
@@ -244,6 +560,70 @@ func (cc *Config) Hash() (string, error) {
 	return hex.EncodeToString(configHash.Sum(nil)), nil
 }
 
+// shared by the core config and the access config, since the role is needed to access the cluster
+var _assumeRoleFieldValidation = &cr.StructFieldValidation{
+	StructField: "AssumeRole",
+	StructValidation: &cr.StructValidation{
+		DefaultNil:        true,
+		AllowExplicitNull: true,
+		StructFieldValidations: []*cr.StructFieldValidation{
+			{
+				StructField: "RoleARN",
+				StringValidation: &cr.StringValidation{
+					Required:  true,
+					Validator: validateRoleARN,
+				},
+			},
+			{
+				StructField: "SourceRoleARNs",
+				StringListValidation: &cr.StringListValidation{
+					AllowEmpty:        true,
+					AllowExplicitNull: true,
+					Validator: func(roleARNs []string) ([]string, error) {
+						for _, roleARN := range roleARNs {
+							if _, err := validateRoleARN(roleARN); err != nil {
+								return nil, err
+							}
+						}
+						return roleARNs, nil
+					},
+				},
+			},
+			{
+				StructField: "ExternalID",
+				StringPtrValidation: &cr.StringPtrValidation{
+					AllowExplicitNull: true,
+					MinLength:         2,
+					MaxLength:         1224,
+				},
+			},
+			{
+				StructField: "SessionTags",
+				StringMapValidation: &cr.StringMapValidation{
+					AllowExplicitNull:  true,
+					AllowEmpty:         true,
+					ConvertNullToEmpty: true,
+					KeyStringValidator: &cr.StringValidation{
+						MinLength: 1,
+						MaxLength: 128,
+					},
+					ValueStringValidator: &cr.StringValidation{
+						AllowEmpty: true,
+						MaxLength:  256,
+					},
+				},
+			},
+			{
+				StructField: "MFASerial",
+				StringPtrValidation: &cr.StringPtrValidation{
+					AllowExplicitNull: true,
+					Validator:         validateMFASerial,
+				},
+			},
+		},
+	},
+}
+
 var CoreConfigStructFieldValidations = []*cr.StructFieldValidation{
 	{
 		Key: "provider",
@@ -307,6 +687,7 @@ var CoreConfigStructFieldValidations = []*cr.StructFieldValidation{
 			Validator: RegionValidator,
 		},
 	},
+	_assumeRoleFieldValidation,
 	{
 		StructField: "Telemetry",
 		BoolValidation: &cr.BoolValidation{
@@ -369,6 +750,20 @@ var CoreConfigStructFieldValidations = []*cr.StructFieldValidation{
 			Validator: validateImageVersion,
 		},
 	},
+	{
+		StructField: "ImageDownloader",
+		StringValidation: &cr.StringValidation{
+			Default:   consts.DefaultRegistry() + "/downloader:" + consts.CortexVersion,
+			Validator: validateImageVersion,
+		},
+	},
+	{
+		StructField: "ImageModelManager",
+		StringValidation: &cr.StringValidation{
+			Default:   consts.DefaultRegistry() + "/model-manager:" + consts.CortexVersion,
+			Validator: validateImageVersion,
+		},
+	},
 	{
 		StructField: "ImageClusterAutoscaler",
 		StringValidation: &cr.StringValidation{
@@ -376,6 +771,13 @@ var CoreConfigStructFieldValidations = []*cr.StructFieldValidation{
 			Validator: validateImageVersion,
 		},
 	},
+	{
+		StructField: "ImageKarpenter",
+		StringValidation: &cr.StringValidation{
+			Default:   consts.DefaultRegistry() + "/karpenter:" + consts.CortexVersion,
+			Validator: validateImageVersion,
+		},
+	},
 	{
 		StructField: "ImageMetricsServer",
 		StringValidation: &cr.StringValidation{
@@ -481,6 +883,13 @@ var CoreConfigStructFieldValidations = []*cr.StructFieldValidation{
 			Validator: validateImageVersion,
 		},
 	},
+	{
+		StructField: "ImageAlertmanager",
+		StringValidation: &cr.StringValidation{
+			Default:   consts.DefaultRegistry() + "/alertmanager:" + consts.CortexVersion,
+			Validator: validateImageVersion,
+		},
+	},
 	{
 		StructField: "ImageEventExporter",
 		StringValidation: &cr.StringValidation{
@@ -601,6 +1010,13 @@ var ManagedConfigStructFieldValidations = []*cr.StructFieldValidation{
 										AllowExplicitNull: true,
 									},
 								},
+								{
+									StructField: "AllocationStrategy",
+									StringValidation: &cr.StringValidation{
+										Default:       SpotAllocationStrategyLowestPrice,
+										AllowedValues: []string{SpotAllocationStrategyLowestPrice, SpotAllocationStrategyCapacityOptimized, SpotAllocationStrategyCapacityOptimizedPrioritized},
+									},
+								},
 								{
 									StructField: "InstancePools",
 									Int64PtrValidation: &cr.Int64PtrValidation{
@@ -609,6 +1025,49 @@ var ManagedConfigStructFieldValidations = []*cr.StructFieldValidation{
 										AllowExplicitNull:    true,
 									},
 								},
+								{
+									StructField: "OnDemandBackup",
+									BoolValidation: &cr.BoolValidation{
+										Default: false,
+									},
+								},
+							},
+						},
+					},
+					{
+						StructField: "PrepullImages",
+						BoolValidation: &cr.BoolValidation{
+							Default: false,
+						},
+					},
+					{
+						StructField: "GPUSharingFactor",
+						Int64Validation: &cr.Int64Validation{
+							Default:              1,
+							GreaterThanOrEqualTo: pointer.Int64(1),
+							LessThanOrEqualTo:    pointer.Int64(_maxGPUSharingFactor),
+						},
+					},
+					{
+						StructField: "Managed",
+						BoolValidation: &cr.BoolValidation{
+							Default: false,
+						},
+					},
+					{
+						StructField: "AMIFamily",
+						StringValidation: &cr.StringValidation{
+							Default:       AMIFamilyAL2,
+							AllowedValues: []string{AMIFamilyAL2, AMIFamilyBottlerocket, AMIFamilyWindows},
+						},
+					},
+					{
+						StructField: "PreBootstrapCommands",
+						StringListValidation: &cr.StringListValidation{
+							AllowExplicitNull: true,
+							AllowEmpty:        true,
+							ElementStringValidation: &cr.StringValidation{
+								MinLength: 1,
 							},
 						},
 					},
@@ -616,6 +1075,13 @@ var ManagedConfigStructFieldValidations = []*cr.StructFieldValidation{
 			},
 		},
 	},
+	{
+		StructField: "NodeProvisioner",
+		StringValidation: &cr.StringValidation{
+			Default:       NodeProvisionerClusterAutoscaler,
+			AllowedValues: []string{NodeProvisionerClusterAutoscaler, NodeProvisionerKarpenter},
+		},
+	},
 	{
 		StructField: "Tags",
 		StringMapValidation: &cr.StringMapValidation{
@@ -714,6 +1180,21 @@ var ManagedConfigStructFieldValidations = []*cr.StructFieldValidation{
 			return SingleNATGateway.String()
 		},
 	},
+	{
+		StructField: "NATGatewayElasticIPs",
+		StringListValidation: &cr.StringListValidation{
+			AllowExplicitNull: true,
+			DisallowDups:      true,
+			Validator: func(allocationIDs []string) ([]string, error) {
+				for i, allocationID := range allocationIDs {
+					if _, err := validateElasticIPAllocationID(allocationID); err != nil {
+						return nil, errors.Wrap(err, fmt.Sprintf("index %d", i))
+					}
+				}
+				return allocationIDs, nil
+			},
+		},
+	},
 	{
 		StructField: "APILoadBalancerScheme",
 		StringValidation: &cr.StringValidation{
@@ -724,6 +1205,130 @@ var ManagedConfigStructFieldValidations = []*cr.StructFieldValidation{
 			return LoadBalancerSchemeFromString(str), nil
 		},
 	},
+	{
+		StructField: "APILoadBalancerType",
+		StringValidation: &cr.StringValidation{
+			AllowedValues: LoadBalancerTypeStrings(),
+			Default:       NLBLoadBalancerType.String(),
+		},
+		Parser: func(str string) (interface{}, error) {
+			return LoadBalancerTypeFromString(str), nil
+		},
+	},
+	{
+		StructField: "APILoadBalancerProxyProtocol",
+		BoolValidation: &cr.BoolValidation{
+			Default: false,
+		},
+	},
+	{
+		StructField: "APILoadBalancerAccessLogsS3Path",
+		StringPtrValidation: &cr.StringPtrValidation{
+			AllowExplicitNull: true,
+			Validator:         cr.S3PathValidator,
+		},
+	},
+	{
+		StructField: "APILoadBalancerWAFWebACLARN",
+		StringPtrValidation: &cr.StringPtrValidation{
+			AllowExplicitNull: true,
+			Validator:         validateWAFWebACLARN,
+		},
+	},
+	{
+		StructField: "APIGateway",
+		StructValidation: &cr.StructValidation{
+			DefaultNil:        true,
+			AllowExplicitNull: true,
+			StructFieldValidations: []*cr.StructFieldValidation{
+				{
+					StructField: "ThrottlingRateLimit",
+					Float64PtrValidation: &cr.Float64PtrValidation{
+						AllowExplicitNull: true,
+						GreaterThan:       pointer.Float64(0),
+					},
+				},
+				{
+					StructField: "ThrottlingBurstLimit",
+					Int64PtrValidation: &cr.Int64PtrValidation{
+						AllowExplicitNull: true,
+						GreaterThan:       pointer.Int64(0),
+					},
+				},
+				{
+					StructField: "Authorizer",
+					StructValidation: &cr.StructValidation{
+						DefaultNil:        true,
+						AllowExplicitNull: true,
+						StructFieldValidations: []*cr.StructFieldValidation{
+							{
+								StructField: "JWT",
+								StructValidation: &cr.StructValidation{
+									DefaultNil:        true,
+									AllowExplicitNull: true,
+									StructFieldValidations: []*cr.StructFieldValidation{
+										{
+											StructField: "Issuer",
+											StringValidation: &cr.StringValidation{
+												Required:  true,
+												Validator: validateOIDCIssuerURL,
+											},
+										},
+										{
+											StructField: "Audience",
+											StringListValidation: &cr.StringListValidation{
+												Required:     true,
+												MinLength:    1,
+												DisallowDups: true,
+											},
+										},
+										{
+											StructField: "IdentitySource",
+											StringValidation: &cr.StringValidation{
+												Default: "$request.header.Authorization",
+												Prefix:  "$request.header.",
+											},
+										},
+									},
+								},
+							},
+							{
+								StructField: "Lambda",
+								StructValidation: &cr.StructValidation{
+									DefaultNil:        true,
+									AllowExplicitNull: true,
+									StructFieldValidations: []*cr.StructFieldValidation{
+										{
+											StructField: "FunctionARN",
+											StringValidation: &cr.StringValidation{
+												Required:  true,
+												Validator: validateLambdaFunctionARN,
+											},
+										},
+										{
+											StructField: "IdentitySource",
+											StringListValidation: &cr.StringListValidation{
+												Default:      []string{"$request.header.Authorization"},
+												MinLength:    1,
+												DisallowDups: true,
+											},
+										},
+										{
+											StructField: "ResultTTL",
+											StringValidation: &cr.StringValidation{
+												Default:   "5m",
+												Validator: validateAuthorizerResultTTL,
+											},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	},
 	{
 		StructField: "APILoadBalancerCIDRWhiteList",
 		StringListValidation: &cr.StringListValidation{
@@ -769,64 +1374,730 @@ var ManagedConfigStructFieldValidations = []*cr.StructFieldValidation{
 		},
 	},
 	{
-		StructField: "CortexPolicyARN",
-		StringValidation: &cr.StringValidation{
-			Required:         false,
-			AllowEmpty:       true,
-			TreatNullAsEmpty: true,
+		StructField: "RequireIMDSv2",
+		BoolValidation: &cr.BoolValidation{
+			Default: false,
 		},
 	},
 	{
-		StructField: "AccountID",
-		StringValidation: &cr.StringValidation{
-			Required:         false,
-			AllowEmpty:       true,
-			TreatNullAsEmpty: true,
+		StructField: "SSMAccess",
+		BoolValidation: &cr.BoolValidation{
+			Default: false,
 		},
 	},
-}
-
-func CoreConfigValidations(allowExtraFields bool) *cr.StructValidation {
-	return &cr.StructValidation{
-		Required:               true,
-		StructFieldValidations: CoreConfigStructFieldValidations,
-		AllowExtraFields:       allowExtraFields,
-	}
-}
-
-func ManagedConfigValidations(allowExtraFields bool) *cr.StructValidation {
-	return &cr.StructValidation{
-		Required:               true,
-		StructFieldValidations: ManagedConfigStructFieldValidations,
-		AllowExtraFields:       allowExtraFields,
-	}
-}
-
-var FullManagedValidation = &cr.StructValidation{
-	Required:               true,
-	StructFieldValidations: append([]*cr.StructFieldValidation{}, append(CoreConfigStructFieldValidations, ManagedConfigStructFieldValidations...)...),
-}
-
-var AccessValidation = &cr.StructValidation{
-	AllowExtraFields: true,
-	StructFieldValidations: []*cr.StructFieldValidation{
-		{
-			StructField: "ClusterName",
-			StringValidation: &cr.StringValidation{
-				Default:   "cortex",
-				MaxLength: 54, // leaves room for 8 char uniqueness string (and "-") for bucket name (63 chars max)
-				MinLength: 3,
-				Validator: validateClusterName,
+	{
+		StructField: "KubernetesAccess",
+		StructListValidation: &cr.StructListValidation{
+			AllowExplicitNull: true,
+			StructValidation: &cr.StructValidation{
+				StructFieldValidations: []*cr.StructFieldValidation{
+					{
+						StructField: "ARN",
+						StringValidation: &cr.StringValidation{
+							Required:  true,
+							Validator: validateIAMPrincipalARN,
+						},
+					},
+					{
+						StructField: "Role",
+						StringValidation: &cr.StringValidation{
+							Required:      true,
+							AllowedValues: []string{KubernetesAccessRoleAdmin, KubernetesAccessRoleReadOnly},
+						},
+					},
+				},
 			},
 		},
-		{
-			StructField: "Region",
-			StringValidation: &cr.StringValidation{
-				Required:  true,
-				MinLength: 1,
+	},
+	{
+		StructField: "WebConsole",
+		BoolValidation: &cr.BoolValidation{
+			Default: false,
+		},
+	},
+	{
+		StructField: "EFS",
+		StructValidation: &cr.StructValidation{
+			DefaultNil:        true,
+			AllowExplicitNull: true,
+			StructFieldValidations: []*cr.StructFieldValidation{
+				{
+					StructField: "PerformanceMode",
+					StringValidation: &cr.StringValidation{
+						Default:       "generalPurpose",
+						AllowedValues: []string{"generalPurpose", "maxIO"},
+					},
+				},
+			},
+		},
+	},
+	{
+		StructField: "GitOps",
+		StructValidation: &cr.StructValidation{
+			DefaultNil:        true,
+			AllowExplicitNull: true,
+			StructFieldValidations: []*cr.StructFieldValidation{
+				{
+					StructField: "Source",
+					StringValidation: &cr.StringValidation{
+						Required:  true,
+						Validator: validateGitOpsSource,
+					},
+				},
+				{
+					StructField: "Branch",
+					StringValidation: &cr.StringValidation{
+						Default:   "main",
+						Validator: validateGitOpsBranch,
+					},
+				},
+				{
+					StructField: "Path",
+					StringValidation: &cr.StringValidation{
+						Default:    "",
+						AllowEmpty: true,
+					},
+				},
+				{
+					StructField:         "TokenSecret",
+					StringPtrValidation: &cr.StringPtrValidation{},
+				},
+				{
+					StructField: "SyncPeriod",
+					StringValidation: &cr.StringValidation{
+						Default:   "1m",
+						Validator: validateGitOpsSyncPeriod,
+					},
+				},
+				{
+					StructField: "Prune",
+					BoolValidation: &cr.BoolValidation{
+						Default: false,
+					},
+				},
+			},
+		},
+	},
+	{
+		StructField: "Alerting",
+		StructValidation: &cr.StructValidation{
+			DefaultNil:        true,
+			AllowExplicitNull: true,
+			StructFieldValidations: []*cr.StructFieldValidation{
+				{
+					StructField: "Receivers",
+					StructListValidation: &cr.StructListValidation{
+						Required:  true,
+						MinLength: 1,
+						StructValidation: &cr.StructValidation{
+							StructFieldValidations: []*cr.StructFieldValidation{
+								{
+									StructField: "Name",
+									StringValidation: &cr.StringValidation{
+										Required: true,
+										DNS1035:  true,
+									},
+								},
+								{
+									StructField: "Slack",
+									StructValidation: &cr.StructValidation{
+										DefaultNil:        true,
+										AllowExplicitNull: true,
+										StructFieldValidations: []*cr.StructFieldValidation{
+											{
+												StructField: "WebhookURL",
+												StringValidation: &cr.StringValidation{
+													Required:  true,
+													Validator: validateAlertURL,
+												},
+											},
+											{
+												StructField:         "Channel",
+												StringPtrValidation: &cr.StringPtrValidation{},
+											},
+										},
+									},
+								},
+								{
+									StructField: "PagerDuty",
+									StructValidation: &cr.StructValidation{
+										DefaultNil:        true,
+										AllowExplicitNull: true,
+										StructFieldValidations: []*cr.StructFieldValidation{
+											{
+												StructField: "RoutingKey",
+												StringValidation: &cr.StringValidation{
+													Required: true,
+												},
+											},
+										},
+									},
+								},
+								{
+									StructField: "Webhook",
+									StructValidation: &cr.StructValidation{
+										DefaultNil:        true,
+										AllowExplicitNull: true,
+										StructFieldValidations: []*cr.StructFieldValidation{
+											{
+												StructField: "URL",
+												StringValidation: &cr.StringValidation{
+													Required:  true,
+													Validator: validateAlertURL,
+												},
+											},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+				{
+					StructField: "DefaultReceiver",
+					StringValidation: &cr.StringValidation{
+						Default:    "",
+						AllowEmpty: true,
+					},
+				},
+				{
+					StructField: "Rules",
+					StructValidation: &cr.StructValidation{
+						StructFieldValidations: []*cr.StructFieldValidation{
+							{
+								StructField: "APIErrorRate",
+								Float64PtrValidation: &cr.Float64PtrValidation{
+									Default:           pointer.Float64(0.05),
+									AllowExplicitNull: true,
+									GreaterThan:       pointer.Float64(0),
+									LessThanOrEqualTo: pointer.Float64(1),
+								},
+							},
+							{
+								StructField: "PendingReplicasPeriod",
+								StringPtrValidation: &cr.StringPtrValidation{
+									Default:           pointer.String("10m"),
+									AllowExplicitNull: true,
+									Validator:         validateAlertPeriod,
+								},
+							},
+							{
+								StructField: "QueueAge",
+								StringPtrValidation: &cr.StringPtrValidation{
+									Default:           pointer.String("10m"),
+									AllowExplicitNull: true,
+									Validator:         validateAlertPeriod,
+								},
+							},
+							{
+								StructField: "NodeNotReadyPeriod",
+								StringPtrValidation: &cr.StringPtrValidation{
+									Default:           pointer.String("5m"),
+									AllowExplicitNull: true,
+									Validator:         validateAlertPeriod,
+								},
+							},
+							{
+								StructField: "JobTimedOut",
+								BoolPtrValidation: &cr.BoolPtrValidation{
+									Default:           pointer.Bool(true),
+									AllowExplicitNull: true,
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	},
+	{
+		StructField: "OIDC",
+		StructValidation: &cr.StructValidation{
+			DefaultNil:        true,
+			AllowExplicitNull: true,
+			StructFieldValidations: []*cr.StructFieldValidation{
+				{
+					StructField: "IssuerURL",
+					StringValidation: &cr.StringValidation{
+						Required:  true,
+						Validator: validateOIDCIssuerURL,
+					},
+				},
+				{
+					StructField: "ClientID",
+					StringValidation: &cr.StringValidation{
+						Required: true,
+					},
+				},
+				{
+					StructField: "Scopes",
+					StringListValidation: &cr.StringListValidation{
+						Default:      []string{"openid", "email", "profile", "offline_access"},
+						DisallowDups: true,
+					},
+				},
+				{
+					StructField: "UsernameClaim",
+					StringValidation: &cr.StringValidation{
+						Default: "email",
+					},
+				},
+				{
+					StructField: "RolesClaim",
+					StringValidation: &cr.StringValidation{
+						Default: "groups",
+					},
+				},
+				{
+					StructField: "RoleMappings",
+					StructListValidation: &cr.StructListValidation{
+						AllowExplicitNull: true,
+						StructValidation: &cr.StructValidation{
+							StructFieldValidations: []*cr.StructFieldValidation{
+								{
+									StructField: "Value",
+									StringValidation: &cr.StringValidation{
+										Required: true,
+									},
+								},
+								{
+									StructField: "Role",
+									StringValidation: &cr.StringValidation{
+										Required:      true,
+										AllowedValues: rbac.RoleStrings(),
+									},
+								},
+								{
+									StructField: "APIPrefix",
+									StringValidation: &cr.StringValidation{
+										AllowEmpty: true,
+									},
+								},
+							},
+						},
+					},
+				},
+				{
+					StructField: "DefaultRole",
+					StringPtrValidation: &cr.StringPtrValidation{
+						AllowedValues: rbac.RoleStrings(),
+					},
+				},
+			},
+		},
+	},
+	{
+		StructField: "Quotas",
+		StructListValidation: &cr.StructListValidation{
+			AllowExplicitNull: true,
+			StructValidation: &cr.StructValidation{
+				StructFieldValidations: []*cr.StructFieldValidation{
+					{
+						StructField: "Team",
+						StringValidation: &cr.StringValidation{
+							Required:                   true,
+							AlphaNumericDashUnderscore: true,
+						},
+					},
+					{
+						StructField: "APIPrefix",
+						StringValidation: &cr.StringValidation{
+							Required: true,
+						},
+					},
+					{
+						StructField: "CPU",
+						StringPtrValidation: &cr.StringPtrValidation{
+							AllowExplicitNull: true,
+							CastNumeric:       true,
+						},
+						Parser: k8s.QuantityParser(&k8s.QuantityValidation{}),
+					},
+					{
+						StructField: "Mem",
+						StringPtrValidation: &cr.StringPtrValidation{
+							AllowExplicitNull: true,
+						},
+						Parser: k8s.QuantityParser(&k8s.QuantityValidation{}),
+					},
+					{
+						StructField: "GPU",
+						Int64PtrValidation: &cr.Int64PtrValidation{
+							AllowExplicitNull:    true,
+							GreaterThanOrEqualTo: pointer.Int64(0),
+						},
+					},
+					{
+						StructField: "Replicas",
+						Int64PtrValidation: &cr.Int64PtrValidation{
+							AllowExplicitNull:    true,
+							GreaterThanOrEqualTo: pointer.Int64(0),
+						},
+					},
+				},
+			},
+		},
+	},
+	{
+		StructField: "MTLS",
+		StructValidation: &cr.StructValidation{
+			DefaultNil:        true,
+			AllowExplicitNull: true,
+			StructFieldValidations: []*cr.StructFieldValidation{
+				{
+					StructField: "Mode",
+					StringValidation: &cr.StringValidation{
+						Default:       MTLSModeStrict,
+						AllowedValues: []string{MTLSModeStrict, MTLSModePermissive},
+					},
+				},
+			},
+		},
+	},
+	{
+		StructField: "Budget",
+		StructValidation: &cr.StructValidation{
+			DefaultNil:        true,
+			AllowExplicitNull: true,
+			StructFieldValidations: []*cr.StructFieldValidation{
+				{
+					StructField: "MaxClusterCostPerHour",
+					Float64PtrValidation: &cr.Float64PtrValidation{
+						AllowExplicitNull: true,
+						GreaterThan:       pointer.Float64(0),
+					},
+				},
+				{
+					StructField: "MaxAPICostPerHour",
+					Float64PtrValidation: &cr.Float64PtrValidation{
+						AllowExplicitNull: true,
+						GreaterThan:       pointer.Float64(0),
+					},
+				},
+				{
+					StructField: "WarningThresholds",
+					Float64ListValidation: &cr.Float64ListValidation{
+						Default:    []float64{0.8},
+						AllowEmpty: true,
+						Validator:  validateBudgetWarningThresholds,
+					},
+				},
+			},
+		},
+	},
+	{
+		StructField: "Logging",
+		StructValidation: &cr.StructValidation{
+			StructFieldValidations: []*cr.StructFieldValidation{
+				{
+					StructField: "Level",
+					StringValidation: &cr.StringValidation{
+						AllowedValues: userconfig.LogLevelTypes(),
+						Default:       userconfig.InfoLogLevel.String(),
+					},
+					Parser: func(str string) (interface{}, error) {
+						return userconfig.LogLevelFromString(str), nil
+					},
+				},
+				{
+					StructField: "Format",
+					StringValidation: &cr.StringValidation{
+						AllowedValues: userconfig.LogFormatTypes(),
+						Default:       userconfig.JSONLogFormat.String(),
+					},
+					Parser: func(str string) (interface{}, error) {
+						return userconfig.LogFormatFromString(str), nil
+					},
+				},
+				{
+					StructField: "Sinks",
+					StructListValidation: &cr.StructListValidation{
+						AllowExplicitNull: true,
+						StructValidation: &cr.StructValidation{
+							StructFieldValidations: []*cr.StructFieldValidation{
+								{
+									StructField: "Name",
+									StringValidation: &cr.StringValidation{
+										Required: true,
+										DNS1035:  true,
+									},
+								},
+								{
+									StructField: "APIs",
+									StringListValidation: &cr.StringListValidation{
+										AllowExplicitNull: true,
+										AllowEmpty:        true,
+										DisallowDups:      true,
+										ElementStringValidation: &cr.StringValidation{
+											DNS1035: true,
+										},
+									},
+								},
+								{
+									StructField: "Datadog",
+									StructValidation: &cr.StructValidation{
+										DefaultNil:        true,
+										AllowExplicitNull: true,
+										StructFieldValidations: []*cr.StructFieldValidation{
+											{
+												StructField: "APIKey",
+												StringValidation: &cr.StringValidation{
+													Required: true,
+												},
+											},
+											{
+												StructField: "Site",
+												StringValidation: &cr.StringValidation{
+													Default:   "datadoghq.com",
+													Validator: validateLogSinkHost,
+												},
+											},
+										},
+									},
+								},
+								{
+									StructField: "Loki",
+									StructValidation: &cr.StructValidation{
+										DefaultNil:        true,
+										AllowExplicitNull: true,
+										StructFieldValidations: []*cr.StructFieldValidation{
+											logSinkHostValidation(),
+											logSinkPortValidation(443),
+											logSinkTLSValidation(),
+											{
+												StructField:         "TenantID",
+												StringPtrValidation: &cr.StringPtrValidation{},
+											},
+											{
+												StructField:         "Username",
+												StringPtrValidation: &cr.StringPtrValidation{},
+											},
+											{
+												StructField:         "Password",
+												StringPtrValidation: &cr.StringPtrValidation{},
+											},
+										},
+									},
+								},
+								{
+									StructField: "Elasticsearch",
+									StructValidation: &cr.StructValidation{
+										DefaultNil:        true,
+										AllowExplicitNull: true,
+										StructFieldValidations: []*cr.StructFieldValidation{
+											logSinkHostValidation(),
+											logSinkPortValidation(443),
+											logSinkTLSValidation(),
+											{
+												StructField: "Index",
+												StringValidation: &cr.StringValidation{
+													Default: "cortex",
+												},
+											},
+											{
+												StructField:         "Username",
+												StringPtrValidation: &cr.StringPtrValidation{},
+											},
+											{
+												StructField:         "Password",
+												StringPtrValidation: &cr.StringPtrValidation{},
+											},
+										},
+									},
+								},
+								{
+									StructField: "Splunk",
+									StructValidation: &cr.StructValidation{
+										DefaultNil:        true,
+										AllowExplicitNull: true,
+										StructFieldValidations: []*cr.StructFieldValidation{
+											logSinkHostValidation(),
+											logSinkPortValidation(8088),
+											logSinkTLSValidation(),
+											{
+												StructField: "Token",
+												StringValidation: &cr.StringValidation{
+													Required: true,
+												},
+											},
+											{
+												StructField:         "Index",
+												StringPtrValidation: &cr.StringPtrValidation{},
+											},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+				{
+					StructField: "RetentionDays",
+					Int64PtrValidation: &cr.Int64PtrValidation{
+						AllowExplicitNull: true,
+						AllowedValues:     _logRetentionDays,
+					},
+				},
+				{
+					StructField: "Filters",
+					StructValidation: &cr.StructValidation{
+						DefaultNil:        true,
+						AllowExplicitNull: true,
+						StructFieldValidations: []*cr.StructFieldValidation{
+							{
+								StructField: "MinLevel",
+								StringValidation: &cr.StringValidation{
+									AllowedValues: userconfig.LogLevelTypes(),
+									Default:       userconfig.DebugLogLevel.String(),
+								},
+								Parser: func(str string) (interface{}, error) {
+									return userconfig.LogLevelFromString(str), nil
+								},
+							},
+							{
+								StructField: "APIMinLevels",
+								StructListValidation: &cr.StructListValidation{
+									AllowExplicitNull: true,
+									StructValidation: &cr.StructValidation{
+										StructFieldValidations: []*cr.StructFieldValidation{
+											{
+												StructField: "APIPrefix",
+												StringValidation: &cr.StringValidation{
+													Required: true,
+												},
+											},
+											{
+												StructField: "MinLevel",
+												StringValidation: &cr.StringValidation{
+													Required:      true,
+													AllowedValues: userconfig.LogLevelTypes(),
+												},
+												Parser: func(str string) (interface{}, error) {
+													return userconfig.LogLevelFromString(str), nil
+												},
+											},
+										},
+									},
+								},
+							},
+							{
+								StructField: "AccessLogSampleRate",
+								Float64Validation: &cr.Float64Validation{
+									Default:              1,
+									GreaterThanOrEqualTo: pointer.Float64(0),
+									LessThanOrEqualTo:    pointer.Float64(1),
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	},
+	{
+		StructField: "OperatorSSLCertificateARN",
+		StringPtrValidation: &cr.StringPtrValidation{
+			AllowExplicitNull: true,
+		},
+	},
+	{
+		StructField: "SelfSignedCertificateValidity",
+		StringValidation: &cr.StringValidation{
+			Default:   "8760h",
+			Validator: validateSelfSignedCertificateValidity,
+		},
+	},
+	{
+		StructField: "RegistryCredentials",
+		StructListValidation: &cr.StructListValidation{
+			TreatNullAsEmpty:  true,
+			AllowExplicitNull: true,
+			StructValidation: &cr.StructValidation{
+				StructFieldValidations: []*cr.StructFieldValidation{
+					{
+						StructField: "Registry",
+						StringValidation: &cr.StringValidation{
+							Required: true,
+						},
+					},
+					{
+						StructField: "SecretsManager",
+						StringPtrValidation: &cr.StringPtrValidation{
+							AllowExplicitNull: true,
+						},
+					},
+				},
+			},
+		},
+	},
+	{
+		StructField: "EFSFileSystemID",
+		StringValidation: &cr.StringValidation{
+			Required:         false,
+			AllowEmpty:       true,
+			TreatNullAsEmpty: true,
+		},
+	},
+	{
+		StructField: "CortexPolicyARN",
+		StringValidation: &cr.StringValidation{
+			Required:         false,
+			AllowEmpty:       true,
+			TreatNullAsEmpty: true,
+		},
+	},
+	{
+		StructField: "AccountID",
+		StringValidation: &cr.StringValidation{
+			Required:         false,
+			AllowEmpty:       true,
+			TreatNullAsEmpty: true,
+		},
+	},
+}
+
+func CoreConfigValidations(allowExtraFields bool) *cr.StructValidation {
+	return &cr.StructValidation{
+		Required:               true,
+		StructFieldValidations: CoreConfigStructFieldValidations,
+		AllowExtraFields:       allowExtraFields,
+	}
+}
+
+func ManagedConfigValidations(allowExtraFields bool) *cr.StructValidation {
+	return &cr.StructValidation{
+		Required:               true,
+		StructFieldValidations: ManagedConfigStructFieldValidations,
+		AllowExtraFields:       allowExtraFields,
+	}
+}
+
+var FullManagedValidation = &cr.StructValidation{
+	Required:               true,
+	StructFieldValidations: append([]*cr.StructFieldValidation{}, append(CoreConfigStructFieldValidations, ManagedConfigStructFieldValidations...)...),
+}
+
+var AccessValidation = &cr.StructValidation{
+	AllowExtraFields: true,
+	StructFieldValidations: []*cr.StructFieldValidation{
+		{
+			StructField: "ClusterName",
+			StringValidation: &cr.StringValidation{
+				Default:   "cortex",
+				MaxLength: 54, // leaves room for 8 char uniqueness string (and "-") for bucket name (63 chars max)
+				MinLength: 3,
+				Validator: validateClusterName,
+			},
+		},
+		{
+			StructField: "Region",
+			StringValidation: &cr.StringValidation{
+				Required:  true,
+				MinLength: 1,
 				Validator: RegionValidator,
 			},
 		},
+		_assumeRoleFieldValidation,
 		{
 			StructField: "ImageManager",
 			StringValidation: &cr.StringValidation{
@@ -841,10 +2112,16 @@ func (cc *Config) ToAccessConfig() AccessConfig {
 	return AccessConfig{
 		ClusterName:  cc.ClusterName,
 		Region:       cc.Region,
+		AssumeRole:   cc.AssumeRole,
 		ImageManager: cc.ImageManager,
 	}
 }
 
+// used as the creation token of the cluster's efs file system, which makes its creation idempotent
+func EFSCreationToken(clusterName string, region string) string {
+	return "cortex-" + clusterName + "-" + region
+}
+
 func SQSNamePrefix(clusterName string) string {
 	// 8 was chosen to make sure that other identifiers can be added to the full queue name before reaching the 80 char SQS name limit
 	return "cx" + SQSQueueDelimiter + hash.String(clusterName)[:8] + SQSQueueDelimiter
@@ -868,7 +2145,6 @@ func (cc *Config) Validate(awsClient *aws.Client) error {
 	}
 
 	ngNames := []string{}
-	instances := []aws.InstanceTypeRequests{}
 	for _, nodeGroup := range cc.NodeGroups {
 		// setting max_instances to 0 during cluster creation is not permitted (but scaling max_instances to 0 afterwards is allowed)
 		if nodeGroup.MaxInstances == 0 {
@@ -880,19 +2156,24 @@ func (cc *Config) Validate(awsClient *aws.Client) error {
 			return errors.Wrap(ErrorDuplicateNodeGroupName(nodeGroup.Name), NodeGroupsKey)
 		}
 
+		if cc.UsesKarpenter() {
+			if err := nodeGroup.validateKarpenterNodeGroup(); err != nil {
+				return errors.Wrap(err, NodeGroupsKey, nodeGroup.Name)
+			}
+		}
+		if nodeGroup.Managed {
+			if err := nodeGroup.validateManagedNodeGroup(); err != nil {
+				return errors.Wrap(err, NodeGroupsKey, nodeGroup.Name)
+			}
+		}
+
 		err := nodeGroup.validateNodeGroup(awsClient, cc.Region)
 		if err != nil {
 			return errors.Wrap(err, NodeGroupsKey, nodeGroup.Name)
 		}
-
-		instances = append(instances, aws.InstanceTypeRequests{
-			InstanceType:              nodeGroup.InstanceType,
-			RequiredOnDemandInstances: nodeGroup.MaxPossibleOnDemandInstances(),
-			RequiredSpotInstances:     nodeGroup.MaxPossibleSpotInstances(),
-		})
 	}
 
-	if err := awsClient.VerifyInstanceQuota(instances); err != nil {
+	if err := awsClient.VerifyInstanceQuota(cc.InstanceTypeRequests()); err != nil {
 		// Skip AWS errors, since some regions (e.g. eu-north-1) do not support this API
 		if !aws.IsAWSError(err) {
 			return errors.Wrap(err, NodeGroupsKey)
@@ -903,6 +2184,72 @@ func (cc *Config) Validate(awsClient *aws.Client) error {
 		return ErrorSpecifyOneOrNone(AvailabilityZonesKey, SubnetsKey)
 	}
 
+	if cc.Alerting != nil {
+		if err := cc.Alerting.validate(); err != nil {
+			return errors.Wrap(err, AlertingKey)
+		}
+	}
+
+	if err := spec.ValidateRegistryCredentials(cc.RegistryCredentials); err != nil {
+		return errors.Wrap(err, RegistryCredentialsKey)
+	}
+
+	if err := validateQuotas(cc.Quotas); err != nil {
+		return errors.Wrap(err, QuotasKey)
+	}
+
+	if cc.Logging != nil {
+		if err := validateLogSinks(cc.Logging.Sinks); err != nil {
+			return errors.Wrap(err, LoggingKey, SinksKey)
+		}
+		if cc.Logging.Filters != nil {
+			if err := validateAPIMinLogLevels(cc.Logging.Filters.APIMinLevels); err != nil {
+				return errors.Wrap(err, LoggingKey, FiltersKey, APIMinLevelsKey)
+			}
+		}
+	}
+
+	if cc.OIDC != nil && !slices.HasString(cc.OIDC.Scopes, "openid") {
+		return errors.Wrap(ErrorOIDCScopeRequired("openid"), OIDCKey, ScopesKey)
+	}
+
+	// the application load balancer appends the client's address to the X-Forwarded-For header instead
+	if cc.APILoadBalancerType == ALBLoadBalancerType && cc.APILoadBalancerProxyProtocol {
+		return errors.Wrap(ErrorProxyProtocolRequiresNLB(), APILoadBalancerProxyProtocolKey)
+	}
+
+	// network load balancers only write access logs for their TLS listeners
+	if cc.APILoadBalancerType == NLBLoadBalancerType && cc.APILoadBalancerAccessLogsS3Path != nil && cc.SSLCertificateARN == nil {
+		return errors.Wrap(ErrorAccessLogsRequireTLS(), APILoadBalancerAccessLogsS3PathKey)
+	}
+
+	if cc.APILoadBalancerType != ALBLoadBalancerType {
+		if cc.APILoadBalancerWAFWebACLARN != nil {
+			return errors.Wrap(ErrorFieldRequiresALB(APILoadBalancerWAFWebACLARNKey), APILoadBalancerWAFWebACLARNKey)
+		}
+		if cc.APIGateway != nil {
+			return errors.Wrap(ErrorFieldRequiresALB(APIGatewayKey), APIGatewayKey)
+		}
+	}
+
+	if cc.APIGateway != nil {
+		// otherwise clients could bypass the api gateway's authorizer and throttling by sending requests to the load balancer directly
+		if cc.APILoadBalancerScheme != InternalLoadBalancerScheme {
+			return errors.Wrap(ErrorAPIGatewayRequiresInternalLoadBalancer(), APIGatewayKey)
+		}
+		if authorizer := cc.APIGateway.Authorizer; authorizer != nil {
+			numTypes := 0
+			for _, isDefined := range []bool{authorizer.JWT != nil, authorizer.Lambda != nil} {
+				if isDefined {
+					numTypes++
+				}
+			}
+			if numTypes != 1 {
+				return errors.Wrap(ErrorSpecifyExactlyOneField(numTypes, JWTKey, LambdaKey), APIGatewayKey, AuthorizerKey)
+			}
+		}
+	}
+
 	if len(cc.Subnets) > 0 && cc.NATGateway != NoneNATGateway {
 		return ErrorNoNATGatewayWithSubnets()
 	}
@@ -911,6 +2258,17 @@ func (cc *Config) Validate(awsClient *aws.Client) error {
 		return ErrorNATRequiredWithPrivateSubnetVisibility()
 	}
 
+	if len(cc.NATGatewayElasticIPs) > 0 {
+		// the nat gateways of existing subnets aren't managed by cortex
+		if len(cc.Subnets) > 0 {
+			return ErrorSpecifyOneOrNone(SubnetsKey, NATGatewayElasticIPsKey)
+		}
+		// nodes in public subnets send requests from their own (dynamic) public ip addresses rather than through the nat gateways
+		if cc.SubnetVisibility != PrivateSubnetVisibility {
+			return errors.Wrap(ErrorNATElasticIPsRequirePrivateSubnets(), NATGatewayElasticIPsKey)
+		}
+	}
+
 	accountID, _, err := awsClient.GetCachedAccountID()
 	if err != nil {
 		return err
@@ -941,6 +2299,11 @@ func (cc *Config) Validate(awsClient *aws.Client) error {
 	}
 	cc.CortexPolicyARN = DefaultPolicyARN(accountID, cc.ClusterName, cc.Region)
 
+	// the file system is created during `cortex cluster up`
+	if cc.EFSFileSystemID != "" {
+		return ErrorDisallowedField(EFSFileSystemIDKey)
+	}
+
 	defaultPoliciesSet := strset.New(_defaultIAMPolicies...)
 	for i := range cc.IAMPolicyARNs {
 		policyARN := cc.IAMPolicyARNs[i]
@@ -973,6 +2336,29 @@ func (cc *Config) Validate(awsClient *aws.Client) error {
 		}
 	}
 
+	// load balancers can only deliver their access logs to buckets in the same region
+	if cc.APILoadBalancerAccessLogsS3Path != nil {
+		bucketRegion, err := aws.GetBucketRegionFromS3Path(*cc.APILoadBalancerAccessLogsS3Path)
+		if err != nil {
+			return errors.Wrap(err, APILoadBalancerAccessLogsS3PathKey)
+		}
+		if bucketRegion != cc.Region {
+			bucket, _, _ := aws.SplitS3Path(*cc.APILoadBalancerAccessLogsS3Path)
+			return errors.Wrap(ErrorAccessLogsBucketRegion(bucket, bucketRegion, cc.Region), APILoadBalancerAccessLogsS3PathKey)
+		}
+	}
+
+	if cc.OperatorSSLCertificateARN != nil {
+		exists, err := awsClient.DoesCertificateExist(*cc.OperatorSSLCertificateARN)
+		if err != nil {
+			return errors.Wrap(err, OperatorSSLCertificateARNKey)
+		}
+
+		if !exists {
+			return errors.Wrap(ErrorSSLCertificateARNNotFound(*cc.OperatorSSLCertificateARN, cc.Region), OperatorSSLCertificateARNKey)
+		}
+	}
+
 	for tagName, tagValue := range cc.Tags {
 		if strings.HasPrefix(tagName, "cortex.dev/") {
 			if tagName != ClusterNameTag {
@@ -995,6 +2381,12 @@ func (cc *Config) Validate(awsClient *aws.Client) error {
 		}
 	}
 
+	if len(cc.NATGatewayElasticIPs) > 0 {
+		if err := cc.validateNATGatewayElasticIPs(awsClient); err != nil {
+			return errors.Wrap(err, NATGatewayElasticIPsKey)
+		}
+	}
+
 	var requiredVPCs int
 	if len(cc.Subnets) == 0 {
 		requiredVPCs = 1
@@ -1072,7 +2464,46 @@ func (ng *NodeGroup) validateNodeGroup(awsClient *aws.Client, region string) err
 		ng.InstanceVolumeIOPS = pointer.Int64(libmath.MinInt64(ng.InstanceVolumeSize*_maxIOPSToVolumeSizeRatioForIO1, 3000))
 	}
 
+	if ng.SharesGPUs() && aws.InstanceMetadatas[region][primaryInstanceType].GPU == 0 {
+		return errors.Wrap(ErrorGPUSharingRequiresGPUInstance(primaryInstanceType), GPUSharingFactorKey)
+	}
+
+	if ng.AMIFamily == AMIFamilyBottlerocket {
+		primaryInstance := aws.InstanceMetadatas[region][primaryInstanceType]
+		if primaryInstance.GPU > 0 || primaryInstance.Inf > 0 {
+			return errors.Wrap(ErrorBottlerocketInstanceType(primaryInstanceType), AMIFamilyKey)
+		}
+	}
+
+	if len(ng.PreBootstrapCommands) > 0 {
+		// bottlerocket's user data is a TOML document of its settings rather than a script
+		if ng.AMIFamily == AMIFamilyBottlerocket {
+			return errors.Wrap(ErrorBottlerocketBootstrapCommands(), PreBootstrapCommandsKey)
+		}
+		numBytes := 0
+		for _, command := range ng.PreBootstrapCommands {
+			numBytes += len(command) + 1
+		}
+		if numBytes > _maxPreBootstrapCommandsBytes {
+			return errors.Wrap(ErrorPreBootstrapCommandsTooLarge(numBytes, _maxPreBootstrapCommandsBytes), PreBootstrapCommandsKey)
+		}
+	}
+
+	if ng.AMIFamily == AMIFamilyWindows {
+		primaryInstance := aws.InstanceMetadatas[region][primaryInstanceType]
+		if primaryInstance.GPU > 0 || primaryInstance.Inf > 0 || ng.Arch() != aws.ArchAMD64 {
+			return errors.Wrap(ErrorWindowsInstanceType(primaryInstanceType), AMIFamilyKey)
+		}
+		if ng.PrepullImages {
+			return errors.Wrap(ErrorFieldNotSupportedByWindowsNodeGroups(PrepullImagesKey), PrepullImagesKey)
+		}
+	}
+
 	if ng.Spot {
+		if ng.SpotConfig != nil && ng.SpotConfig.InstancePools != nil && ng.SpotConfig.AllocationStrategy != SpotAllocationStrategyLowestPrice {
+			return errors.Wrap(ErrorInstancePoolsRequireLowestPrice(ng.SpotConfig.AllocationStrategy), SpotConfigKey, InstancePoolsKey)
+		}
+
 		ng.FillEmptySpotFields(region)
 
 		primaryInstance := aws.InstanceMetadatas[region][primaryInstanceType]
@@ -1116,11 +2547,108 @@ func (ng *NodeGroup) validateNodeGroup(awsClient *aws.Client, region string) err
 	return nil
 }
 
+// karpenter launches instances for pending pods rather than maintaining auto scaling groups, so the node group's fields which configure
+// the auto scaling group can't be applied (this must be validated before the empty spot config fields are filled in)
+func (ng *NodeGroup) validateKarpenterNodeGroup() error {
+	if ng.MinInstances > 0 {
+		return errors.Wrap(ErrorMinInstancesNotSupportedByKarpenter(), MinInstancesKey)
+	}
+	if ng.Managed {
+		return ErrorFieldNotSupportedByKarpenter(ManagedKey)
+	}
+	if ng.AMIFamily == AMIFamilyWindows {
+		return errors.Wrap(ErrorWindowsNotSupportedByKarpenter(), AMIFamilyKey)
+	}
+
+	if ng.SpotConfig == nil {
+		return nil
+	}
+	if ng.SpotConfig.OnDemandBaseCapacity != nil {
+		return errors.Wrap(ErrorFieldNotSupportedByKarpenter(OnDemandBaseCapacityKey), SpotConfigKey)
+	}
+	if ng.SpotConfig.OnDemandPercentageAboveBaseCapacity != nil {
+		return errors.Wrap(ErrorFieldNotSupportedByKarpenter(OnDemandPercentageAboveBaseCapacityKey), SpotConfigKey)
+	}
+	if ng.SpotConfig.MaxPrice != nil {
+		return errors.Wrap(ErrorFieldNotSupportedByKarpenter(MaxPriceKey), SpotConfigKey)
+	}
+	if ng.SpotConfig.InstancePools != nil {
+		return errors.Wrap(ErrorFieldNotSupportedByKarpenter(InstancePoolsKey), SpotConfigKey)
+	}
+
+	return nil
+}
+
+// the spot instances of EKS managed node groups are always launched with the capacity-optimized allocation strategy, and eksctl doesn't support
+// the kubelet configuration or node template tags of managed node groups (this must be validated before the empty spot config fields are filled in)
+func (ng *NodeGroup) validateManagedNodeGroup() error {
+	// without the node template tags, the cluster autoscaler doesn't know the labels of the node group's instances
+	if ng.MinInstances == 0 {
+		return errors.Wrap(ErrorManagedNodeGroupMinInstances(), MinInstancesKey)
+	}
+
+	if ng.AMIFamily == AMIFamilyBottlerocket {
+		return errors.Wrap(ErrorBottlerocketManagedNodeGroup(), AMIFamilyKey)
+	}
+	if ng.AMIFamily == AMIFamilyWindows {
+		return errors.Wrap(ErrorFieldNotSupportedByWindowsNodeGroups(ManagedKey), ManagedKey)
+	}
+
+	if !ng.Spot || ng.SpotConfig == nil {
+		return nil
+	}
+	if ng.SpotConfig.OnDemandBaseCapacity != nil {
+		return errors.Wrap(ErrorFieldNotSupportedByManagedNodeGroups(OnDemandBaseCapacityKey), SpotConfigKey)
+	}
+	if ng.SpotConfig.OnDemandPercentageAboveBaseCapacity != nil {
+		return errors.Wrap(ErrorFieldNotSupportedByManagedNodeGroups(OnDemandPercentageAboveBaseCapacityKey), SpotConfigKey)
+	}
+	if ng.SpotConfig.MaxPrice != nil {
+		return errors.Wrap(ErrorFieldNotSupportedByManagedNodeGroups(MaxPriceKey), SpotConfigKey)
+	}
+	if ng.SpotConfig.InstancePools != nil {
+		return errors.Wrap(ErrorFieldNotSupportedByManagedNodeGroups(InstancePoolsKey), SpotConfigKey)
+	}
+	// the on-demand backup would have to be scaled up from zero instances
+	if ng.SpotConfig.OnDemandBackup {
+		return errors.Wrap(ErrorFieldNotSupportedByManagedNodeGroups(OnDemandBackupKey), SpotConfigKey)
+	}
+	ng.SpotConfig.AllocationStrategy = SpotAllocationStrategyCapacityOptimized
+
+	return nil
+}
+
 func CheckSpotInstanceCompatibility(target aws.InstanceMetadata, suggested aws.InstanceMetadata) error {
+	targetArch, err := aws.InstanceArch(target.Type)
+	if err != nil {
+		return err
+	}
+	suggestedArch, err := aws.InstanceArch(suggested.Type)
+	if err != nil {
+		return err
+	}
+	if targetArch != suggestedArch {
+		return ErrorIncompatibleSpotInstanceTypeArch(target, suggested, targetArch, suggestedArch)
+	}
+
 	if target.Inf > 0 && suggested.Inf == 0 {
 		return ErrorIncompatibleSpotInstanceTypeInf(suggested)
 	}
 
+	if target.Inf > 0 {
+		targetRequiresHugePages, err := aws.RequiresNeuronHugePages(target.Type)
+		if err != nil {
+			return err
+		}
+		suggestedRequiresHugePages, err := aws.RequiresNeuronHugePages(suggested.Type)
+		if err != nil {
+			return err
+		}
+		if targetRequiresHugePages != suggestedRequiresHugePages {
+			return ErrorIncompatibleSpotInstanceTypeNeuron(target, suggested)
+		}
+	}
+
 	if target.GPU > suggested.GPU {
 		return ErrorIncompatibleSpotInstanceTypeGPU(target, suggested)
 	}
@@ -1149,13 +2677,16 @@ func CheckSpotInstancePriceCompatibility(target aws.InstanceMetadata, suggested
 
 func AutoGenerateSpotConfig(spotConfig *SpotConfig, region string, instanceType string) {
 	primaryInstance := aws.InstanceMetadatas[region][instanceType]
-	cleanedDistribution := []string{instanceType}
-	for _, spotInstance := range spotConfig.InstanceDistribution {
-		if spotInstance != instanceType {
-			cleanedDistribution = append(cleanedDistribution, spotInstance)
-		}
+
+	// the order of the instance distribution is the priority of the instance types for the capacity-optimized-prioritized allocation strategy,
+	// so the primary instance type is only prepended if it wasn't listed
+	if !slices.HasString(spotConfig.InstanceDistribution, instanceType) {
+		spotConfig.InstanceDistribution = append([]string{instanceType}, spotConfig.InstanceDistribution...)
+	}
+
+	if spotConfig.AllocationStrategy == "" {
+		spotConfig.AllocationStrategy = SpotAllocationStrategyLowestPrice
 	}
-	spotConfig.InstanceDistribution = cleanedDistribution
 
 	if spotConfig.MaxPrice == nil {
 		spotConfig.MaxPrice = &primaryInstance.Price
@@ -1169,7 +2700,7 @@ func AutoGenerateSpotConfig(spotConfig *SpotConfig, region string, instanceType
 		spotConfig.OnDemandPercentageAboveBaseCapacity = pointer.Int64(0)
 	}
 
-	if spotConfig.InstancePools == nil {
+	if spotConfig.InstancePools == nil && spotConfig.AllocationStrategy == SpotAllocationStrategyLowestPrice {
 		if len(spotConfig.InstanceDistribution) < _maxInstancePools {
 			spotConfig.InstancePools = pointer.Int64(int64(len(spotConfig.InstanceDistribution)))
 		} else {
@@ -1194,6 +2725,305 @@ func validateCIDR(cidr string) (string, error) {
 	return cidr, nil
 }
 
+func validateGitOpsSource(source string) (string, error) {
+	if aws.IsValidS3Path(source) {
+		if !strings.HasSuffix(source, "/") {
+			source += "/"
+		}
+		return source, nil
+	}
+	if strings.HasPrefix(source, "https://") {
+		return source, nil
+	}
+	return "", ErrorInvalidGitOpsSource(source)
+}
+
+// the branch is passed to git as an argument, so it must not be interpreted as an option
+func validateGitOpsBranch(branch string) (string, error) {
+	if strings.HasPrefix(branch, "-") || strings.ContainsAny(branch, " \t\n") {
+		return "", ErrorInvalidGitOpsBranch(branch)
+	}
+	return branch, nil
+}
+
+func validateGitOpsSyncPeriod(syncPeriod string) (string, error) {
+	_, err := cr.DurationParser(&cr.DurationValidation{
+		GreaterThanOrEqualTo: pointer.Duration(10 * time.Second),
+	})(syncPeriod)
+	if err != nil {
+		return "", err
+	}
+	return syncPeriod, nil
+}
+
+func validateAlertPeriod(period string) (string, error) {
+	_, err := cr.DurationParser(&cr.DurationValidation{
+		GreaterThanOrEqualTo: pointer.Duration(time.Minute),
+	})(period)
+	if err != nil {
+		return "", err
+	}
+	return period, nil
+}
+
+func validateSelfSignedCertificateValidity(validity string) (string, error) {
+	_, err := cr.DurationParser(&cr.DurationValidation{
+		GreaterThanOrEqualTo: pointer.Duration(7 * 24 * time.Hour),
+	})(validity)
+	if err != nil {
+		return "", err
+	}
+	return validity, nil
+}
+
+func validateAlertURL(alertURL string) (string, error) {
+	u, err := urls.Parse(alertURL)
+	if err != nil {
+		return "", err
+	}
+	if u.Scheme != "https" && u.Scheme != "http" || u.Host == "" {
+		return "", urls.ErrorInvalidURL(alertURL)
+	}
+	return alertURL, nil
+}
+
+// the issuer url must match the "iss" claim of the provider's tokens, and is used to discover the provider's endpoints and signing keys
+func validateRoleARN(roleARN string) (string, error) {
+	if !strings.HasPrefix(roleARN, "arn:") || !strings.Contains(roleARN, ":role/") {
+		return "", ErrorInvalidRoleARN(roleARN)
+	}
+	return roleARN, nil
+}
+
+func validateIAMPrincipalARN(arn string) (string, error) {
+	if !strings.HasPrefix(arn, "arn:") || !(strings.Contains(arn, ":role/") || strings.Contains(arn, ":user/")) {
+		return "", ErrorInvalidIAMPrincipalARN(arn)
+	}
+	return arn, nil
+}
+
+// the serial number is either the ARN of a virtual MFA device or the serial number of a hardware device
+func validateMFASerial(mfaSerial string) (string, error) {
+	if len(mfaSerial) < 9 || len(mfaSerial) > 256 {
+		return "", ErrorInvalidMFASerial(mfaSerial)
+	}
+	return mfaSerial, nil
+}
+
+func validateOIDCIssuerURL(issuerURL string) (string, error) {
+	u, err := urls.Parse(issuerURL)
+	if err != nil {
+		return "", err
+	}
+	if u.Scheme != "https" || u.Host == "" {
+		return "", ErrorOIDCIssuerMustBeHTTPS(issuerURL)
+	}
+	return strings.TrimSuffix(issuerURL, "/"), nil
+}
+
+func validateElasticIPAllocationID(allocationID string) (string, error) {
+	if !_elasticIPAllocationIDRegex.MatchString(allocationID) {
+		return "", ErrorInvalidElasticIPAllocationID(allocationID)
+	}
+	return allocationID, nil
+}
+
+// one elastic ip is assigned to each nat gateway, and there is a nat gateway in each availability zone when nat_gateway is highly_available
+func (cc *Config) validateNATGatewayElasticIPs(awsClient *aws.Client) error {
+	numNATGateways := 1
+	if cc.NATGateway == HighlyAvailableNATGateway {
+		numNATGateways = len(cc.AvailabilityZones)
+	}
+	if len(cc.NATGatewayElasticIPs) != numNATGateways {
+		return ErrorNATGatewayElasticIPsCount(len(cc.NATGatewayElasticIPs), numNATGateways, cc.NATGateway)
+	}
+
+	for _, allocationID := range cc.NATGatewayElasticIPs {
+		address, err := awsClient.DescribeElasticIP(allocationID)
+		if err != nil {
+			return err
+		}
+		if address == nil {
+			return ErrorElasticIPNotFound(allocationID, cc.Region)
+		}
+		if address.AssociationId != nil {
+			return ErrorElasticIPInUse(allocationID, *address.PublicIp)
+		}
+	}
+
+	return nil
+}
+
+// only regional web acls can be associated with application load balancers (global web acls are for cloudfront distributions)
+func validateWAFWebACLARN(webACLARN string) (string, error) {
+	if !strings.HasPrefix(webACLARN, "arn:") || !strings.Contains(webACLARN, ":wafv2:") || !strings.Contains(webACLARN, ":regional/webacl/") {
+		return "", ErrorInvalidWAFWebACLARN(webACLARN)
+	}
+	return webACLARN, nil
+}
+
+func validateLambdaFunctionARN(functionARN string) (string, error) {
+	if !strings.HasPrefix(functionARN, "arn:") || !strings.Contains(functionARN, ":lambda:") || !strings.Contains(functionARN, ":function:") {
+		return "", ErrorInvalidLambdaFunctionARN(functionARN)
+	}
+	return functionARN, nil
+}
+
+// api gateway caches authorizer results for up to an hour, in whole seconds
+func validateAuthorizerResultTTL(ttl string) (string, error) {
+	_, err := cr.DurationParser(&cr.DurationValidation{
+		GreaterThanOrEqualTo: pointer.Duration(0),
+		LessThanOrEqualTo:    pointer.Duration(time.Hour),
+		MultipleOf:           pointer.Duration(time.Second),
+	})(ttl)
+	if err != nil {
+		return "", err
+	}
+	return ttl, nil
+}
+
+func validateQuotas(quotas []*Quota) error {
+	teams := strset.New()
+	apiPrefixes := strset.New()
+	for _, quota := range quotas {
+		if teams.Has(quota.Team) {
+			return ErrorDuplicateQuotaTeam(quota.Team)
+		}
+		teams.Add(quota.Team)
+
+		if apiPrefixes.Has(quota.APIPrefix) {
+			return errors.Wrap(ErrorDuplicateQuotaAPIPrefix(quota.APIPrefix), quota.Team, APIPrefixKey)
+		}
+		apiPrefixes.Add(quota.APIPrefix)
+	}
+	return nil
+}
+
+func validateLogSinks(sinks []*LogSink) error {
+	names := strset.New()
+	for _, sink := range sinks {
+		if names.Has(sink.Name) {
+			return ErrorDuplicateLogSinkName(sink.Name)
+		}
+		names.Add(sink.Name)
+
+		numTypes := 0
+		for _, isDefined := range []bool{sink.Datadog != nil, sink.Loki != nil, sink.Elasticsearch != nil, sink.Splunk != nil} {
+			if isDefined {
+				numTypes++
+			}
+		}
+		if numTypes != 1 {
+			return errors.Wrap(ErrorSpecifyExactlyOneField(numTypes, DatadogKey, LokiKey, ElasticsearchKey, SplunkKey), sink.Name)
+		}
+
+		if sink.Loki != nil && (sink.Loki.Username == nil) != (sink.Loki.Password == nil) {
+			return errors.Wrap(spec.ErrorSpecifyAllOrNone(UsernameKey, PasswordKey), sink.Name, LokiKey)
+		}
+		if sink.Elasticsearch != nil && (sink.Elasticsearch.Username == nil) != (sink.Elasticsearch.Password == nil) {
+			return errors.Wrap(spec.ErrorSpecifyAllOrNone(UsernameKey, PasswordKey), sink.Name, ElasticsearchKey)
+		}
+	}
+	return nil
+}
+
+func validateAPIMinLogLevels(apiMinLevels []*APIMinLogLevel) error {
+	apiPrefixes := strset.New()
+	for _, apiMinLevel := range apiMinLevels {
+		if apiPrefixes.Has(apiMinLevel.APIPrefix) {
+			return ErrorDuplicateAPIMinLogLevelPrefix(apiMinLevel.APIPrefix)
+		}
+		apiPrefixes.Add(apiMinLevel.APIPrefix)
+	}
+	return nil
+}
+
+func logSinkHostValidation() *cr.StructFieldValidation {
+	return &cr.StructFieldValidation{
+		StructField: "Host",
+		StringValidation: &cr.StringValidation{
+			Required:  true,
+			Validator: validateLogSinkHost,
+		},
+	}
+}
+
+func logSinkPortValidation(defaultPort int64) *cr.StructFieldValidation {
+	return &cr.StructFieldValidation{
+		StructField: "Port",
+		Int64Validation: &cr.Int64Validation{
+			Default:           defaultPort,
+			GreaterThan:       pointer.Int64(0),
+			LessThanOrEqualTo: pointer.Int64(65535),
+		},
+	}
+}
+
+func logSinkTLSValidation() *cr.StructFieldValidation {
+	return &cr.StructFieldValidation{
+		StructField: "TLS",
+		BoolValidation: &cr.BoolValidation{
+			Default: true,
+		},
+	}
+}
+
+// fluent-bit's outputs are configured with a host and a port rather than a url
+func validateLogSinkHost(host string) (string, error) {
+	if strings.Contains(host, "://") || strings.Contains(host, "/") || strings.Contains(host, ":") {
+		return "", ErrorInvalidLogSinkHost(host)
+	}
+	return host, nil
+}
+
+func validateBudgetWarningThresholds(thresholds []float64) ([]float64, error) {
+	for _, threshold := range thresholds {
+		if threshold <= 0 || threshold > 1 {
+			return nil, ErrorInvalidBudgetWarningThreshold(threshold)
+		}
+	}
+	return thresholds, nil
+}
+
+func (alerting *Alerting) validate() error {
+	receiverNames := strset.New()
+	for _, receiver := range alerting.Receivers {
+		if receiverNames.Has(receiver.Name) {
+			return errors.Wrap(ErrorDuplicateAlertReceiverName(receiver.Name), ReceiversKey)
+		}
+		receiverNames.Add(receiver.Name)
+
+		numTypes := 0
+		for _, isDefined := range []bool{receiver.Slack != nil, receiver.PagerDuty != nil, receiver.Webhook != nil} {
+			if isDefined {
+				numTypes++
+			}
+		}
+		if numTypes != 1 {
+			return errors.Wrap(ErrorSpecifyExactlyOneField(numTypes, SlackKey, PagerDutyKey, WebhookKey), ReceiversKey, receiver.Name)
+		}
+	}
+
+	if alerting.DefaultReceiver == "" {
+		alerting.DefaultReceiver = alerting.Receivers[0].Name
+	} else if !receiverNames.Has(alerting.DefaultReceiver) {
+		return errors.Wrap(ErrorAlertReceiverNotFound(alerting.DefaultReceiver, alerting.GetReceiverNames()), DefaultReceiverKey)
+	}
+
+	return nil
+}
+
+// instance types which are supported by the VPC CNI, but which were released after the version of its networking limits table that cortex depends on
+var _instanceTypesMissingNetworkingLimits = strset.New(
+	"inf2.xlarge",
+	"inf2.8xlarge",
+	"inf2.24xlarge",
+	"inf2.48xlarge",
+	"trn1.2xlarge",
+	"trn1.32xlarge",
+	"trn1n.32xlarge",
+)
+
 func validateInstanceType(instanceType string) (string, error) {
 	if err := aws.CheckValidInstanceType(instanceType); err != nil {
 		return "", err
@@ -1220,8 +3050,12 @@ func validateInstanceType(instanceType string) (string, error) {
 	if err != nil {
 		return "", err
 	}
-	if isARM {
-		return "", ErrorARMInstancesNotSupported(instanceType)
+	isGPU, err := aws.IsGPUInstance(instanceType)
+	if err != nil {
+		return "", err
+	}
+	if isARM && isGPU {
+		return "", ErrorARMGPUInstancesNotSupported(instanceType)
 	}
 
 	isAMDGPU, err := aws.IsAMDGPUInstance(instanceType)
@@ -1232,7 +3066,7 @@ func validateInstanceType(instanceType string) (string, error) {
 		return "", ErrorAMDGPUInstancesNotSupported(instanceType)
 	}
 
-	if _, ok := awsutils.InstanceNetworkingLimits[instanceType]; !ok {
+	if _, ok := awsutils.InstanceNetworkingLimits[instanceType]; !ok && !_instanceTypesMissingNetworkingLimits.Has(instanceType) {
 		return "", ErrorInstanceTypeNotSupportedByCortex(instanceType)
 	}
 
@@ -1249,8 +3083,86 @@ func validateInstanceDistribution(instances []string) ([]string, error) {
 	return instances, nil
 }
 
+// EKSName returns the name of the EKS node group which was created for the node group
+func (ng *NodeGroup) EKSName() string {
+	if ng.Spot {
+		return SpotNodeGroupPrefix + ng.Name
+	}
+	return OnDemandNodeGroupPrefix + ng.Name
+}
+
+// Arch returns the CPU architecture of the node group's instances (as used in the kubernetes.io/arch node label)
+func (ng *NodeGroup) Arch() string {
+	arch, _ := aws.InstanceArch(ng.InstanceType) // the instance type has already been validated
+	return arch
+}
+
+// OS returns the operating system of the node group's instances (as used in the kubernetes.io/os node label)
+func (ng *NodeGroup) OS() string {
+	if ng.AMIFamily == AMIFamilyWindows {
+		return aws.OSWindows
+	}
+	return aws.OSLinux
+}
+
+// SharesGPUs returns true if each of the node group's GPUs is time-sliced between multiple replicas
+func (ng *NodeGroup) SharesGPUs() bool {
+	return ng.GPUSharingFactor > 1
+}
+
+// AMIType returns the type of the AMI which the node group's instances run, using the naming of the EKS AMI types (e.g. AL2_x86_64_GPU or BOTTLEROCKET_ARM_64)
+func (ng *NodeGroup) AMIType() string {
+	arch := "x86_64"
+	if ng.Arch() == aws.ArchARM64 {
+		arch = "ARM_64"
+	}
+
+	if ng.AMIFamily == AMIFamilyBottlerocket {
+		return "BOTTLEROCKET_" + arch
+	}
+	if ng.AMIFamily == AMIFamilyWindows {
+		return "WINDOWS_CORE_2019_x86_64"
+	}
+
+	isGPU, _ := aws.IsGPUInstance(ng.InstanceType)
+	neuronCores, _ := aws.NeuronCoresPerInf(ng.InstanceType)
+	if isGPU || neuronCores > 0 {
+		return "AL2_" + arch + "_GPU"
+	}
+	return "AL2_" + arch
+}
+
+// HasOnDemandBackup returns true if an on-demand EKS node group was created as a fallback for the spot node group
+func (ng *NodeGroup) HasOnDemandBackup() bool {
+	return ng.Spot && ng.SpotConfig != nil && ng.SpotConfig.OnDemandBackup
+}
+
+// EKSNames returns the names of all of the EKS node groups which were created for the node group (i.e. including the on-demand backup, if any)
+func (ng *NodeGroup) EKSNames() []string {
+	if ng.HasOnDemandBackup() {
+		return []string{ng.EKSName(), OnDemandNodeGroupPrefix + ng.Name}
+	}
+	return []string{ng.EKSName()}
+}
+
+// the number of instances of each node group's instance type which the cluster may require if all node groups are scaled to their max instances
+func (cc *Config) InstanceTypeRequests() []aws.InstanceTypeRequests {
+	instances := []aws.InstanceTypeRequests{}
+	for _, ng := range cc.NodeGroups {
+		if ng == nil {
+			continue
+		}
+		instances = append(instances, aws.InstanceTypeRequests{
+			InstanceType:              ng.InstanceType,
+			RequiredOnDemandInstances: ng.MaxPossibleOnDemandInstances(),
+			RequiredSpotInstances:     ng.MaxPossibleSpotInstances(),
+		})
+	}
+	return instances
+}
+
 func (ng *NodeGroup) MaxPossibleOnDemandInstances() int64 {
-	if !ng.Spot || ng.SpotConfig == nil {
+	if !ng.Spot || ng.SpotConfig == nil || ng.SpotConfig.OnDemandBackup {
 		return ng.MaxInstances
 	}
 
@@ -1303,6 +3215,18 @@ func (cc *CoreConfig) TelemetryEvent() map[string]interface{} {
 
 	event["region"] = cc.Region
 
+	if cc.AssumeRole != nil {
+		event["assume_role._is_defined"] = true
+		event["assume_role.source_role_arns._len"] = len(cc.AssumeRole.SourceRoleARNs)
+		event["assume_role.session_tags._len"] = len(cc.AssumeRole.SessionTags)
+		if cc.AssumeRole.ExternalID != nil {
+			event["assume_role.external_id._is_defined"] = true
+		}
+		if cc.AssumeRole.MFASerial != nil {
+			event["assume_role.mfa_serial._is_defined"] = true
+		}
+	}
+
 	if !strings.HasPrefix(cc.ImageOperator, "cortexlabs/") {
 		event["image_operator._is_custom"] = true
 	}
@@ -1327,9 +3251,18 @@ func (cc *CoreConfig) TelemetryEvent() map[string]interface{} {
 	if !strings.HasPrefix(cc.ImageDequeuer, "cortexlabs/") {
 		event["image_dequeuer._is_custom"] = true
 	}
+	if !strings.HasPrefix(cc.ImageDownloader, "cortexlabs/") {
+		event["image_downloader._is_custom"] = true
+	}
+	if !strings.HasPrefix(cc.ImageModelManager, "cortexlabs/") {
+		event["image_model_manager._is_custom"] = true
+	}
 	if !strings.HasPrefix(cc.ImageClusterAutoscaler, "cortexlabs/") {
 		event["image_cluster_autoscaler._is_custom"] = true
 	}
+	if !strings.HasPrefix(cc.ImageKarpenter, "cortexlabs/") {
+		event["image_karpenter._is_custom"] = true
+	}
 	if !strings.HasPrefix(cc.ImageMetricsServer, "cortexlabs/") {
 		event["image_metrics_server._is_custom"] = true
 	}
@@ -1375,6 +3308,9 @@ func (cc *CoreConfig) TelemetryEvent() map[string]interface{} {
 	if strings.HasPrefix(cc.ImageGrafana, "cortexlabs/") {
 		event["image_grafana._is_custom"] = true
 	}
+	if strings.HasPrefix(cc.ImageAlertmanager, "cortexlabs/") {
+		event["image_alertmanager._is_custom"] = true
+	}
 	if strings.HasPrefix(cc.ImageEventExporter, "cortexlabs/") {
 		event["image_event_exporter._is_custom"] = true
 	}
@@ -1408,13 +3344,161 @@ func (mc *ManagedConfig) TelemetryEvent() map[string]interface{} {
 	}
 	event["iam_policy_arns._len"] = len(mc.IAMPolicyARNs)
 
+	event["node_provisioner"] = mc.NodeProvisioner
 	event["subnet_visibility"] = mc.SubnetVisibility
 	event["nat_gateway"] = mc.NATGateway
+	if len(mc.NATGatewayElasticIPs) > 0 {
+		event["nat_gateway_elastic_ips._is_defined"] = true
+		event["nat_gateway_elastic_ips._len"] = len(mc.NATGatewayElasticIPs)
+	}
 	event["api_load_balancer_scheme"] = mc.APILoadBalancerScheme
+	event["api_load_balancer_type"] = mc.APILoadBalancerType
+	event["api_load_balancer_proxy_protocol"] = mc.APILoadBalancerProxyProtocol
+	if mc.APILoadBalancerAccessLogsS3Path != nil {
+		event["api_load_balancer_access_logs_s3_path._is_defined"] = true
+	}
+	if mc.APILoadBalancerWAFWebACLARN != nil {
+		event["api_load_balancer_waf_web_acl_arn._is_defined"] = true
+	}
+	if mc.APIGateway != nil {
+		event["api_gateway._is_defined"] = true
+		if mc.APIGateway.ThrottlingRateLimit != nil {
+			event["api_gateway.throttling_rate_limit"] = *mc.APIGateway.ThrottlingRateLimit
+		}
+		if mc.APIGateway.ThrottlingBurstLimit != nil {
+			event["api_gateway.throttling_burst_limit"] = *mc.APIGateway.ThrottlingBurstLimit
+		}
+		if mc.APIGateway.Authorizer != nil {
+			if mc.APIGateway.Authorizer.JWT != nil {
+				event["api_gateway.authorizer.jwt._is_defined"] = true
+			}
+			if mc.APIGateway.Authorizer.Lambda != nil {
+				event["api_gateway.authorizer.lambda._is_defined"] = true
+				event["api_gateway.authorizer.lambda.result_ttl"] = mc.APIGateway.Authorizer.Lambda.ResultTTL
+			}
+		}
+	}
 	event["operator_load_balancer_scheme"] = mc.OperatorLoadBalancerScheme
 	if mc.VPCCIDR != nil {
 		event["vpc_cidr._is_defined"] = true
 	}
+	event["require_imdsv2"] = mc.RequireIMDSv2
+	event["ssm_access"] = mc.SSMAccess
+	event["kubernetes_access._len"] = len(mc.KubernetesAccess)
+	event["web_console"] = mc.WebConsole
+	if mc.EFS != nil {
+		event["efs._is_defined"] = true
+		event["efs.performance_mode"] = mc.EFS.PerformanceMode
+	}
+	if mc.GitOps != nil {
+		event["gitops._is_defined"] = true
+		event["gitops._is_s3_source"] = mc.GitOps.IsS3Source()
+		event["gitops.sync_period"] = mc.GitOps.SyncPeriod
+		event["gitops.prune"] = mc.GitOps.Prune
+		if mc.GitOps.TokenSecret != nil {
+			event["gitops.token_secret._is_defined"] = true
+		}
+	}
+
+	if mc.Alerting != nil {
+		event["alerting._is_defined"] = true
+		event["alerting.receivers._len"] = len(mc.Alerting.Receivers)
+		for _, receiver := range mc.Alerting.Receivers {
+			if receiver.Slack != nil {
+				event["alerting.receivers._has_slack"] = true
+			}
+			if receiver.PagerDuty != nil {
+				event["alerting.receivers._has_pagerduty"] = true
+			}
+			if receiver.Webhook != nil {
+				event["alerting.receivers._has_webhook"] = true
+			}
+		}
+		if mc.Alerting.Rules != nil {
+			if mc.Alerting.Rules.APIErrorRate != nil {
+				event["alerting.rules.api_error_rate"] = *mc.Alerting.Rules.APIErrorRate
+			}
+			if mc.Alerting.Rules.PendingReplicasPeriod != nil {
+				event["alerting.rules.pending_replicas_period"] = *mc.Alerting.Rules.PendingReplicasPeriod
+			}
+			if mc.Alerting.Rules.QueueAge != nil {
+				event["alerting.rules.queue_age"] = *mc.Alerting.Rules.QueueAge
+			}
+			if mc.Alerting.Rules.NodeNotReadyPeriod != nil {
+				event["alerting.rules.node_not_ready_period"] = *mc.Alerting.Rules.NodeNotReadyPeriod
+			}
+			if mc.Alerting.Rules.JobTimedOut != nil {
+				event["alerting.rules.job_timed_out"] = *mc.Alerting.Rules.JobTimedOut
+			}
+		}
+	}
+
+	if mc.OIDC != nil {
+		event["oidc._is_defined"] = true
+		event["oidc.issuer_url"] = mc.OIDC.IssuerURL
+		event["oidc.scopes"] = mc.OIDC.Scopes
+		event["oidc.username_claim"] = mc.OIDC.UsernameClaim
+		event["oidc.roles_claim"] = mc.OIDC.RolesClaim
+		event["oidc.role_mappings._len"] = len(mc.OIDC.RoleMappings)
+		if mc.OIDC.DefaultRole != nil {
+			event["oidc.default_role"] = *mc.OIDC.DefaultRole
+		}
+	}
+
+	if mc.MTLS != nil {
+		event["mtls._is_defined"] = true
+		event["mtls.mode"] = mc.MTLS.Mode
+	}
+	if len(mc.Quotas) > 0 {
+		event["quotas._is_defined"] = true
+		event["quotas._len"] = len(mc.Quotas)
+	}
+	if mc.Budget != nil {
+		event["budget._is_defined"] = true
+		if mc.Budget.MaxClusterCostPerHour != nil {
+			event["budget.max_cluster_cost_per_hour"] = *mc.Budget.MaxClusterCostPerHour
+		}
+		if mc.Budget.MaxAPICostPerHour != nil {
+			event["budget.max_api_cost_per_hour"] = *mc.Budget.MaxAPICostPerHour
+		}
+		event["budget.warning_thresholds._len"] = len(mc.Budget.WarningThresholds)
+	}
+	if mc.Logging != nil {
+		event["logging.level"] = mc.Logging.Level.String()
+		event["logging.format"] = mc.Logging.Format.String()
+		event["logging.sinks._len"] = len(mc.Logging.Sinks)
+		for _, sink := range mc.Logging.Sinks {
+			if sink.Datadog != nil {
+				event["logging.sinks._has_datadog"] = true
+			}
+			if sink.Loki != nil {
+				event["logging.sinks._has_loki"] = true
+			}
+			if sink.Elasticsearch != nil {
+				event["logging.sinks._has_elasticsearch"] = true
+			}
+			if sink.Splunk != nil {
+				event["logging.sinks._has_splunk"] = true
+			}
+			if len(sink.APIs) > 0 {
+				event["logging.sinks._has_apis"] = true
+			}
+		}
+		if mc.Logging.RetentionDays != nil {
+			event["logging.retention_days"] = *mc.Logging.RetentionDays
+		}
+		if mc.Logging.Filters != nil {
+			event["logging.filters._is_defined"] = true
+			event["logging.filters.min_level"] = mc.Logging.Filters.MinLevel.String()
+			event["logging.filters.api_min_levels._len"] = len(mc.Logging.Filters.APIMinLevels)
+			event["logging.filters.access_log_sample_rate"] = mc.Logging.Filters.AccessLogSampleRate
+		}
+	}
+	if mc.OperatorSSLCertificateARN != nil {
+		event["operator_ssl_certificate_arn._is_defined"] = true
+	}
+	event["self_signed_certificate_validity"] = mc.SelfSignedCertificateValidity
+	event["registry_credentials._len"] = len(mc.RegistryCredentials)
 
 	onDemandInstanceTypes := strset.New()
 	spotInstanceTypes := strset.New()
@@ -1475,8 +3559,16 @@ func (mc *ManagedConfig) TelemetryEvent() map[string]interface{} {
 				event[nodeGroupKey("spot_config.instance_pools._is_defined")] = true
 				event[nodeGroupKey("spot_config.instance_pools")] = *ng.SpotConfig.InstancePools
 			}
+			event[nodeGroupKey("spot_config.allocation_strategy")] = ng.SpotConfig.AllocationStrategy
+			event[nodeGroupKey("spot_config.on_demand_backup")] = ng.SpotConfig.OnDemandBackup
 		}
 
+		event[nodeGroupKey("prepull_images")] = ng.PrepullImages
+		event[nodeGroupKey("gpu_sharing_factor")] = ng.GPUSharingFactor
+		event[nodeGroupKey("managed")] = ng.Managed
+		event[nodeGroupKey("ami_family")] = ng.AMIFamily
+		event[nodeGroupKey("pre_bootstrap_commands._len")] = len(ng.PreBootstrapCommands)
+
 		totalMinSize += int(ng.MinInstances)
 		totalMaxSize += int(ng.MaxInstances)
 	}
@@ -1499,6 +3591,38 @@ func (mc *ManagedConfig) GetAllInstanceTypes() []string {
 	return allInstanceTypes.Slice()
 }
 
+// NumTrustedProxies returns the number of proxies in front of the apis' istio gateway which append the address of their client to
+// the X-Forwarded-For header (the network load balancer passes the client's address through instead, in the connection itself or with the proxy protocol)
+func (mc *ManagedConfig) NumTrustedProxies() int {
+	if mc.APILoadBalancerType != ALBLoadBalancerType {
+		return 0
+	}
+	if mc.APIGateway != nil {
+		return 2
+	}
+	return 1
+}
+
+// UsesKarpenter returns true if the cluster's worker instances are provisioned by karpenter (rather than by the cluster autoscaler)
+func (mc *ManagedConfig) UsesKarpenter() bool {
+	return mc.NodeProvisioner == NodeProvisionerKarpenter
+}
+
+// GetNodeGroupsForAPI returns the node groups which an api with the given node group selector (nil to select all node groups), CPU architecture and operating system can run on
+func (mc *ManagedConfig) GetNodeGroupsForAPI(apiNodeGroups []string, arch string, os string) []*NodeGroup {
+	var nodeGroups []*NodeGroup
+	for _, ng := range mc.NodeGroups {
+		if ng.Arch() != arch || ng.OS() != os {
+			continue
+		}
+		if apiNodeGroups != nil && !slices.HasString(apiNodeGroups, ng.Name) {
+			continue
+		}
+		nodeGroups = append(nodeGroups, ng)
+	}
+	return nodeGroups
+}
+
 func (mc *ManagedConfig) GetNodeGroupByName(name string) *NodeGroup {
 	for _, ng := range mc.NodeGroups {
 		if ng.Name == name {
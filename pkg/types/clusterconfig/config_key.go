@@ -23,6 +23,7 @@ const (
 	ClusterNameKey                         = "cluster_name"
 	RegionKey                              = "region"
 	NodeGroupsKey                          = "node_groups"
+	NodeProvisionerKey                     = "node_provisioner"
 	NodePoolsKey                           = "node_pools"
 	InstanceTypeKey                        = "instance_type"
 	AcceleratorTypeKey                     = "accelerator_type"
@@ -39,7 +40,14 @@ const (
 	InstanceVolumeIOPSKey                  = "instance_volume_iops"
 	InstanceVolumeThroughputKey            = "instance_volume_throughput"
 	InstancePoolsKey                       = "instance_pools"
+	AllocationStrategyKey                  = "allocation_strategy"
+	OnDemandBackupKey                      = "on_demand_backup"
 	MaxPriceKey                            = "max_price"
+	PrepullImagesKey                       = "prepull_images"
+	GPUSharingFactorKey                    = "gpu_sharing_factor"
+	ManagedKey                             = "managed"
+	AMIFamilyKey                           = "ami_family"
+	PreBootstrapCommandsKey                = "pre_bootstrap_commands"
 	NetworkKey                             = "network"
 	SubnetKey                              = "subnet"
 	TagsKey                                = "tags"
@@ -52,9 +60,69 @@ const (
 	IAMPolicyARNsKey                       = "iam_policy_arns"
 	SubnetVisibilityKey                    = "subnet_visibility"
 	NATGatewayKey                          = "nat_gateway"
+	NATGatewayElasticIPsKey                = "nat_gateway_elastic_ips"
 	APILoadBalancerSchemeKey               = "api_load_balancer_scheme"
+	APILoadBalancerTypeKey                 = "api_load_balancer_type"
+	APILoadBalancerProxyProtocolKey        = "api_load_balancer_proxy_protocol"
+	APILoadBalancerAccessLogsS3PathKey     = "api_load_balancer_access_logs_s3_path"
+	APILoadBalancerCIDRWhiteListKey        = "api_load_balancer_cidr_white_list"
+	APILoadBalancerWAFWebACLARNKey         = "api_load_balancer_waf_web_acl_arn"
+	APIGatewayKey                          = "api_gateway"
+	AuthorizerKey                          = "authorizer"
+	JWTKey                                 = "jwt"
+	LambdaKey                              = "lambda"
 	OperatorLoadBalancerSchemeKey          = "operator_load_balancer_scheme"
 	VPCCIDRKey                             = "vpc_cidr"
+	RequireIMDSv2Key                       = "require_imdsv2"
+	SSMAccessKey                           = "ssm_access"
+	KubernetesAccessKey                    = "kubernetes_access"
+	ARNKey                                 = "arn"
+	RoleKey                                = "role"
+	WebConsoleKey                          = "web_console"
+	EFSKey                                 = "efs"
+	EFSFileSystemIDKey                     = "efs_file_system_id"
+	PerformanceModeKey                     = "performance_mode"
+	GitOpsKey                              = "gitops"
+	SourceKey                              = "source"
+	BranchKey                              = "branch"
+	PathKey                                = "path"
+	TokenSecretKey                         = "token_secret"
+	SyncPeriodKey                          = "sync_period"
+	PruneKey                               = "prune"
+	AlertingKey                            = "alerting"
+	ReceiversKey                           = "receivers"
+	DefaultReceiverKey                     = "default_receiver"
+	SlackKey                               = "slack"
+	PagerDutyKey                           = "pagerduty"
+	WebhookKey                             = "webhook"
+	OIDCKey                                = "oidc"
+	ScopesKey                              = "scopes"
+	MTLSKey                                = "mtls"
+	QuotasKey                              = "quotas"
+	APIPrefixKey                           = "api_prefix"
+	BudgetKey                              = "budget"
+	MaxClusterCostPerHourKey               = "max_cluster_cost_per_hour"
+	MaxAPICostPerHourKey                   = "max_api_cost_per_hour"
+	WarningThresholdsKey                   = "warning_thresholds"
+	LoggingKey                             = "logging"
+	LogLevelKey                            = "level"
+	LogFormatKey                           = "format"
+	SinksKey                               = "sinks"
+	DatadogKey                             = "datadog"
+	LokiKey                                = "loki"
+	ElasticsearchKey                       = "elasticsearch"
+	SplunkKey                              = "splunk"
+	UsernameKey                            = "username"
+	PasswordKey                            = "password"
+	RetentionDaysKey                       = "retention_days"
+	FiltersKey                             = "filters"
+	MinLevelKey                            = "min_level"
+	APIMinLevelsKey                        = "api_min_levels"
+	AccessLogSampleRateKey                 = "access_log_sample_rate"
+	ModeKey                                = "mode"
+	OperatorSSLCertificateARNKey           = "operator_ssl_certificate_arn"
+	SelfSignedCertificateValidityKey       = "self_signed_certificate_validity"
+	RegistryCredentialsKey                 = "registry_credentials"
 	AccountIDKey                           = "account_id"
 	TelemetryKey                           = "telemetry"
 )
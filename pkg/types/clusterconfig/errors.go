@@ -43,14 +43,29 @@ const (
 	ErrIncompatibleSpotInstanceTypeCPU        = "clusterconfig.incompatible_spot_instance_type_cpu"
 	ErrIncompatibleSpotInstanceTypeGPU        = "clusterconfig.incompatible_spot_instance_type_gpu"
 	ErrIncompatibleSpotInstanceTypeInf        = "clusterconfig.incompatible_spot_instance_type_inf"
+	ErrIncompatibleSpotInstanceTypeArch       = "clusterconfig.incompatible_spot_instance_type_arch"
+	ErrIncompatibleSpotInstanceTypeNeuron     = "clusterconfig.incompatible_spot_instance_type_neuron"
 	ErrSpotPriceGreaterThanTargetOnDemand     = "clusterconfig.spot_price_greater_than_target_on_demand"
 	ErrSpotPriceGreaterThanMaxPrice           = "clusterconfig.spot_price_greater_than_max_price"
 	ErrInstanceTypeNotSupportedByCortex       = "clusterconfig.instance_type_not_supported_by_cortex"
-	ErrARMInstancesNotSupported               = "clusterconfig.arm_instances_not_supported"
+	ErrARMGPUInstancesNotSupported            = "clusterconfig.arm_gpu_instances_not_supported"
 	ErrAMDGPUInstancesNotSupported            = "clusterconfig.amd_gpu_instances_not_supported"
+	ErrGPUSharingRequiresGPUInstance          = "clusterconfig.gpu_sharing_requires_gpu_instance"
 	ErrAtLeastOneInstanceDistribution         = "clusterconfig.at_least_one_instance_distribution"
 	ErrNoCompatibleSpotInstanceFound          = "clusterconfig.no_compatible_spot_instance_found"
 	ErrConfiguredWhenSpotIsNotEnabled         = "clusterconfig.configured_when_spot_is_not_enabled"
+	ErrMinInstancesNotSupportedByKarpenter    = "clusterconfig.min_instances_not_supported_by_karpenter"
+	ErrFieldNotSupportedByKarpenter           = "clusterconfig.field_not_supported_by_karpenter"
+	ErrManagedNodeGroupMinInstances           = "clusterconfig.managed_node_group_min_instances"
+	ErrFieldNotSupportedByManagedNodeGroups   = "clusterconfig.field_not_supported_by_managed_node_groups"
+	ErrBottlerocketManagedNodeGroup           = "clusterconfig.bottlerocket_managed_node_group"
+	ErrBottlerocketInstanceType               = "clusterconfig.bottlerocket_instance_type"
+	ErrWindowsInstanceType                    = "clusterconfig.windows_instance_type"
+	ErrWindowsNotSupportedByKarpenter         = "clusterconfig.windows_not_supported_by_karpenter"
+	ErrFieldNotSupportedByWindowsNodeGroups   = "clusterconfig.field_not_supported_by_windows_node_groups"
+	ErrBottlerocketBootstrapCommands          = "clusterconfig.bottlerocket_bootstrap_commands"
+	ErrPreBootstrapCommandsTooLarge           = "clusterconfig.pre_bootstrap_commands_too_large"
+	ErrInstancePoolsRequireLowestPrice        = "clusterconfig.instance_pools_require_lowest_price"
 	ErrOnDemandBaseCapacityGreaterThanMax     = "clusterconfig.on_demand_base_capacity_greater_than_max"
 	ErrInvalidAvailabilityZone                = "clusterconfig.invalid_availability_zone"
 	ErrAvailabilityZoneSpecifiedTwice         = "clusterconfig.availability_zone_specified_twice"
@@ -73,6 +88,34 @@ const (
 	ErrCantOverrideDefaultTag                 = "clusterconfig.cant_override_default_tag"
 	ErrSSLCertificateARNNotFound              = "clusterconfig.ssl_certificate_arn_not_found"
 	ErrIAMPolicyARNNotFound                   = "clusterconfig.iam_policy_arn_not_found"
+	ErrInvalidGitOpsSource                    = "clusterconfig.invalid_gitops_source"
+	ErrInvalidGitOpsBranch                    = "clusterconfig.invalid_gitops_branch"
+	ErrSpecifyExactlyOneField                 = "clusterconfig.specify_exactly_one_field"
+	ErrDuplicateAlertReceiverName             = "clusterconfig.duplicate_alert_receiver_name"
+	ErrDuplicateQuotaTeam                     = "clusterconfig.duplicate_quota_team"
+	ErrDuplicateQuotaAPIPrefix                = "clusterconfig.duplicate_quota_api_prefix"
+	ErrInvalidBudgetWarningThreshold          = "clusterconfig.invalid_budget_warning_threshold"
+	ErrAlertReceiverNotFound                  = "clusterconfig.alert_receiver_not_found"
+	ErrOIDCIssuerMustBeHTTPS                  = "clusterconfig.oidc_issuer_must_be_https"
+	ErrInvalidRoleARN                         = "clusterconfig.invalid_role_arn"
+	ErrInvalidIAMPrincipalARN                 = "clusterconfig.invalid_iam_principal_arn"
+	ErrInvalidMFASerial                       = "clusterconfig.invalid_mfa_serial"
+	ErrOIDCScopeRequired                      = "clusterconfig.oidc_scope_required"
+	ErrDuplicateLogSinkName                   = "clusterconfig.duplicate_log_sink_name"
+	ErrInvalidLogSinkHost                     = "clusterconfig.invalid_log_sink_host"
+	ErrDuplicateAPIMinLogLevelPrefix          = "clusterconfig.duplicate_api_min_log_level_prefix"
+	ErrFieldRequiresALB                       = "clusterconfig.field_requires_alb"
+	ErrAPIGatewayRequiresInternalLoadBalancer = "clusterconfig.api_gateway_requires_internal_load_balancer"
+	ErrInvalidWAFWebACLARN                    = "clusterconfig.invalid_waf_web_acl_arn"
+	ErrInvalidLambdaFunctionARN               = "clusterconfig.invalid_lambda_function_arn"
+	ErrInvalidElasticIPAllocationID           = "clusterconfig.invalid_elastic_ip_allocation_id"
+	ErrNATElasticIPsRequirePrivateSubnets     = "clusterconfig.nat_gateway_elastic_ips_require_private_subnets"
+	ErrNATGatewayElasticIPsCount              = "clusterconfig.nat_gateway_elastic_ips_count"
+	ErrElasticIPNotFound                      = "clusterconfig.elastic_ip_not_found"
+	ErrElasticIPInUse                         = "clusterconfig.elastic_ip_in_use"
+	ErrProxyProtocolRequiresNLB               = "clusterconfig.proxy_protocol_requires_nlb"
+	ErrAccessLogsRequireTLS                   = "clusterconfig.access_logs_require_tls"
+	ErrAccessLogsBucketRegion                 = "clusterconfig.access_logs_bucket_region"
 )
 
 func ErrorInvalidProvider(providerStr string) error {
@@ -180,6 +223,20 @@ func ErrorIncompatibleSpotInstanceTypeInf(suggested aws.InstanceMetadata) error
 	})
 }
 
+func ErrorIncompatibleSpotInstanceTypeNeuron(target aws.InstanceMetadata, suggested aws.InstanceMetadata) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrIncompatibleSpotInstanceTypeNeuron,
+		Message: fmt.Sprintf("first generation Inferentia instances (inf1) can't be mixed with inf2 or trn1 instances, but %s and %s were both specified", target.Type, suggested.Type),
+	})
+}
+
+func ErrorIncompatibleSpotInstanceTypeArch(target aws.InstanceMetadata, suggested aws.InstanceMetadata, targetArch string, suggestedArch string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrIncompatibleSpotInstanceTypeArch,
+		Message: fmt.Sprintf("all instances must have the same CPU architecture as %s (%s), but %s is %s", target.Type, targetArch, suggested.Type, suggestedArch),
+	})
+}
+
 func ErrorSpotPriceGreaterThanTargetOnDemand(spotPrice float64, target aws.InstanceMetadata, suggested aws.InstanceMetadata) error {
 	return errors.WithStack(&errors.Error{
 		Kind:    ErrSpotPriceGreaterThanTargetOnDemand,
@@ -201,10 +258,17 @@ func ErrorInstanceTypeNotSupportedByCortex(instanceType string) error {
 	})
 }
 
-func ErrorARMInstancesNotSupported(instanceType string) error {
+func ErrorARMGPUInstancesNotSupported(instanceType string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrARMGPUInstancesNotSupported,
+		Message: fmt.Sprintf("ARM-based GPU instances (including %s) are not supported by cortex", instanceType),
+	})
+}
+
+func ErrorGPUSharingRequiresGPUInstance(instanceType string) error {
 	return errors.WithStack(&errors.Error{
-		Kind:    ErrARMInstancesNotSupported,
-		Message: fmt.Sprintf("ARM-based instances (including %s) are not supported by cortex", instanceType),
+		Kind:    ErrGPUSharingRequiresGPUInstance,
+		Message: fmt.Sprintf("%s can only be set to a value greater than 1 for node groups with GPU instances (%s does not have GPUs)", GPUSharingFactorKey, instanceType),
 	})
 }
 
@@ -215,6 +279,83 @@ func ErrorAMDGPUInstancesNotSupported(instanceType string) error {
 	})
 }
 
+func ErrorMinInstancesNotSupportedByKarpenter() error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrMinInstancesNotSupportedByKarpenter,
+		Message: fmt.Sprintf("%s must be 0 when `%s: %s` is set, since karpenter only launches instances for replicas which are pending", MinInstancesKey, NodeProvisionerKey, NodeProvisionerKarpenter),
+	})
+}
+
+func ErrorFieldNotSupportedByKarpenter(configKey string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrFieldNotSupportedByKarpenter,
+		Message: fmt.Sprintf("%s cannot be specified when `%s: %s` is set, since it configures the node group's auto scaling group", configKey, NodeProvisionerKey, NodeProvisionerKarpenter),
+	})
+}
+
+func ErrorManagedNodeGroupMinInstances() error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrManagedNodeGroupMinInstances,
+		Message: fmt.Sprintf("%s must be at least 1 for managed node groups (`%s: true`), since the cluster autoscaler can't scale managed node groups up from zero instances", MinInstancesKey, ManagedKey),
+	})
+}
+
+func ErrorFieldNotSupportedByManagedNodeGroups(configKey string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrFieldNotSupportedByManagedNodeGroups,
+		Message: fmt.Sprintf("%s cannot be specified for managed node groups (`%s: true`)", configKey, ManagedKey),
+	})
+}
+
+func ErrorBottlerocketManagedNodeGroup() error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrBottlerocketManagedNodeGroup,
+		Message: fmt.Sprintf("`%s: %s` is not supported for managed node groups (`%s: true`)", AMIFamilyKey, AMIFamilyBottlerocket, ManagedKey),
+	})
+}
+
+func ErrorBottlerocketInstanceType(instanceType string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrBottlerocketInstanceType,
+		Message: fmt.Sprintf("`%s: %s` is not supported for GPU and Inferentia instances (%s)", AMIFamilyKey, AMIFamilyBottlerocket, instanceType),
+	})
+}
+
+func ErrorWindowsInstanceType(instanceType string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrWindowsInstanceType,
+		Message: fmt.Sprintf("`%s: %s` is only supported for x86 instances without GPUs or Inferentia chips (%s)", AMIFamilyKey, AMIFamilyWindows, instanceType),
+	})
+}
+
+func ErrorWindowsNotSupportedByKarpenter() error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrWindowsNotSupportedByKarpenter,
+		Message: fmt.Sprintf("`%s: %s` is not supported when `%s: %s` is set", AMIFamilyKey, AMIFamilyWindows, NodeProvisionerKey, NodeProvisionerKarpenter),
+	})
+}
+
+func ErrorFieldNotSupportedByWindowsNodeGroups(configKey string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrFieldNotSupportedByWindowsNodeGroups,
+		Message: fmt.Sprintf("%s is not supported for windows node groups (`%s: %s`)", configKey, AMIFamilyKey, AMIFamilyWindows),
+	})
+}
+
+func ErrorBottlerocketBootstrapCommands() error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrBottlerocketBootstrapCommands,
+		Message: fmt.Sprintf("%s is not supported for `%s: %s`, since bottlerocket instances are configured with bottlerocket's settings rather than a script", PreBootstrapCommandsKey, AMIFamilyKey, AMIFamilyBottlerocket),
+	})
+}
+
+func ErrorPreBootstrapCommandsTooLarge(numBytes int, maxBytes int) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrPreBootstrapCommandsTooLarge,
+		Message: fmt.Sprintf("%s are too large (%d bytes); the combined size of the commands can't exceed %d bytes, since they are included in the user data of the node group's instances", PreBootstrapCommandsKey, numBytes, maxBytes),
+	})
+}
+
 func ErrorConfiguredWhenSpotIsNotEnabled(configKey string) error {
 	return errors.WithStack(&errors.Error{
 		Kind:    ErrConfiguredWhenSpotIsNotEnabled,
@@ -222,6 +363,13 @@ func ErrorConfiguredWhenSpotIsNotEnabled(configKey string) error {
 	})
 }
 
+func ErrorInstancePoolsRequireLowestPrice(allocationStrategy string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrInstancePoolsRequireLowestPrice,
+		Message: fmt.Sprintf("%s can only be specified when %s is %s (the %s allocation strategy chooses the spot instance pools based on their available capacity)", InstancePoolsKey, AllocationStrategyKey, SpotAllocationStrategyLowestPrice, allocationStrategy),
+	})
+}
+
 func ErrorOnDemandBaseCapacityGreaterThanMax(onDemandBaseCapacity int64, max int64) error {
 	return errors.WithStack(&errors.Error{
 		Kind:    ErrOnDemandBaseCapacityGreaterThanMax,
@@ -402,3 +550,205 @@ func ErrorIAMPolicyARNNotFound(policyARN string) error {
 		Message: fmt.Sprintf("unable to find iam policy %s", policyARN),
 	})
 }
+
+func ErrorInvalidGitOpsSource(source string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrInvalidGitOpsSource,
+		Message: fmt.Sprintf("%s is not a valid source; it must either be an S3 path (e.g. s3://my-bucket/apis/) or the https url of a git repository (e.g. https://github.com/my-org/my-apis.git)", source),
+	})
+}
+
+func ErrorInvalidGitOpsBranch(branch string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrInvalidGitOpsBranch,
+		Message: fmt.Sprintf("\"%s\" is not a valid branch name; branch names cannot start with \"-\" or contain whitespace", branch),
+	})
+}
+
+func ErrorSpecifyExactlyOneField(numSpecified int, fields ...string) error {
+	var msg string
+	if numSpecified == 0 {
+		msg = fmt.Sprintf("please specify one of the following fields: %s", s.StrsOr(fields))
+	} else {
+		msg = fmt.Sprintf("please specify only one of the following fields: %s", s.StrsOr(fields))
+	}
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrSpecifyExactlyOneField,
+		Message: msg,
+	})
+}
+
+func ErrorDuplicateAlertReceiverName(name string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrDuplicateAlertReceiverName,
+		Message: fmt.Sprintf("cannot have multiple alert receivers with the same name (%s)", name),
+	})
+}
+
+func ErrorDuplicateQuotaTeam(team string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrDuplicateQuotaTeam,
+		Message: fmt.Sprintf("cannot have multiple quotas for the same team (%s)", team),
+	})
+}
+
+func ErrorDuplicateQuotaAPIPrefix(apiPrefix string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrDuplicateQuotaAPIPrefix,
+		Message: fmt.Sprintf("cannot have multiple quotas with the same api prefix (%s)", apiPrefix),
+	})
+}
+
+func ErrorInvalidBudgetWarningThreshold(threshold float64) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrInvalidBudgetWarningThreshold,
+		Message: fmt.Sprintf("budget warning thresholds must be fractions of the budget greater than 0 and less than or equal to 1 (got %s)", s.Float64(threshold)),
+	})
+}
+
+func ErrorAlertReceiverNotFound(name string, available []string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrAlertReceiverNotFound,
+		Message: fmt.Sprintf("alert receiver %s is not defined in the cluster configuration; available receivers: %s", name, s.StrsAnd(available)),
+	})
+}
+
+func ErrorOIDCIssuerMustBeHTTPS(issuerURL string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrOIDCIssuerMustBeHTTPS,
+		Message: fmt.Sprintf("%s is not a valid OIDC issuer url; the issuer url must use https (e.g. https://accounts.google.com)", issuerURL),
+	})
+}
+
+func ErrorInvalidRoleARN(roleARN string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrInvalidRoleARN,
+		Message: fmt.Sprintf("%s is not a valid IAM role ARN (e.g. arn:aws:iam::123456789012:role/cortex-admin)", roleARN),
+	})
+}
+
+func ErrorInvalidIAMPrincipalARN(arn string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrInvalidIAMPrincipalARN,
+		Message: fmt.Sprintf("%s is not a valid IAM role or user ARN (e.g. arn:aws:iam::123456789012:role/developers or arn:aws:iam::123456789012:user/alice)", arn),
+	})
+}
+
+func ErrorInvalidMFASerial(mfaSerial string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrInvalidMFASerial,
+		Message: fmt.Sprintf("%s is not a valid MFA device serial number; it must be the ARN of a virtual MFA device (e.g. arn:aws:iam::123456789012:mfa/user) or the serial number of a hardware MFA device", mfaSerial),
+	})
+}
+
+func ErrorOIDCScopeRequired(scope string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrOIDCScopeRequired,
+		Message: fmt.Sprintf("the %s scope is required", s.UserStr(scope)),
+	})
+}
+
+func ErrorDuplicateLogSinkName(name string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrDuplicateLogSinkName,
+		Message: fmt.Sprintf("cannot have multiple log sinks with the same name (%s)", name),
+	})
+}
+
+func ErrorInvalidLogSinkHost(host string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrInvalidLogSinkHost,
+		Message: fmt.Sprintf("%s is not a valid host; specify the host name without a scheme, port, or path (e.g. logs.example.com), and set the port separately", host),
+	})
+}
+
+func ErrorDuplicateAPIMinLogLevelPrefix(apiPrefix string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrDuplicateAPIMinLogLevelPrefix,
+		Message: fmt.Sprintf("cannot have multiple minimum log levels for the same api prefix (%s)", apiPrefix),
+	})
+}
+
+func ErrorFieldRequiresALB(fieldName string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrFieldRequiresALB,
+		Message: fmt.Sprintf("%s can only be configured when %s is set to %s", fieldName, APILoadBalancerTypeKey, ALBLoadBalancerType.String()),
+	})
+}
+
+func ErrorAPIGatewayRequiresInternalLoadBalancer() error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrAPIGatewayRequiresInternalLoadBalancer,
+		Message: fmt.Sprintf("%s requires %s to be set to %s, since clients could otherwise bypass the api gateway by sending requests to the api load balancer directly", APIGatewayKey, APILoadBalancerSchemeKey, InternalLoadBalancerScheme.String()),
+	})
+}
+
+func ErrorInvalidWAFWebACLARN(webACLARN string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrInvalidWAFWebACLARN,
+		Message: fmt.Sprintf("%s is not a valid regional WAF web ACL ARN (e.g. arn:aws:wafv2:us-east-1:123456789012:regional/webacl/cortex/a1b2c3d4-5678-90ab-cdef-EXAMPLE11111)", webACLARN),
+	})
+}
+
+func ErrorInvalidLambdaFunctionARN(functionARN string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrInvalidLambdaFunctionARN,
+		Message: fmt.Sprintf("%s is not a valid lambda function ARN (e.g. arn:aws:lambda:us-east-1:123456789012:function:authorizer)", functionARN),
+	})
+}
+
+func ErrorInvalidElasticIPAllocationID(allocationID string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrInvalidElasticIPAllocationID,
+		Message: fmt.Sprintf("%s is not a valid elastic ip allocation id (e.g. eipalloc-0123456789abcdef0)", allocationID),
+	})
+}
+
+func ErrorNATElasticIPsRequirePrivateSubnets() error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrNATElasticIPsRequirePrivateSubnets,
+		Message: fmt.Sprintf("%s can only be configured when %s is set to %s, since nodes in public subnets send requests from their own public ip addresses rather than through the nat gateways", NATGatewayElasticIPsKey, SubnetVisibilityKey, PrivateSubnetVisibility.String()),
+	})
+}
+
+func ErrorNATGatewayElasticIPsCount(numElasticIPs int, numNATGateways int, natGateway NATGateway) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrNATGatewayElasticIPsCount,
+		Message: fmt.Sprintf("%d elastic ips were specified, but %d %s required (one for each nat gateway when %s is %s)", numElasticIPs, numNATGateways, s.PluralIs(numNATGateways), NATGatewayKey, natGateway.String()),
+	})
+}
+
+func ErrorElasticIPNotFound(allocationID string, region string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrElasticIPNotFound,
+		Message: fmt.Sprintf("elastic ip %s does not exist in %s", allocationID, region),
+	})
+}
+
+func ErrorElasticIPInUse(allocationID string, publicIP string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrElasticIPInUse,
+		Message: fmt.Sprintf("elastic ip %s (%s) is already associated with another resource", allocationID, publicIP),
+	})
+}
+
+func ErrorProxyProtocolRequiresNLB() error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrProxyProtocolRequiresNLB,
+		Message: fmt.Sprintf("%s can only be enabled when %s is set to %s, since the application load balancer adds the client's ip address to the X-Forwarded-For header instead", APILoadBalancerProxyProtocolKey, APILoadBalancerTypeKey, NLBLoadBalancerType.String()),
+	})
+}
+
+func ErrorAccessLogsRequireTLS() error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrAccessLogsRequireTLS,
+		Message: fmt.Sprintf("%s requires %s to be set when %s is %s, since network load balancers only write access logs for tls connections", APILoadBalancerAccessLogsS3PathKey, SSLCertificateARNKey, APILoadBalancerTypeKey, NLBLoadBalancerType.String()),
+	})
+}
+
+func ErrorAccessLogsBucketRegion(bucketName string, bucketRegion string, clusterRegion string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrAccessLogsBucketRegion,
+		Message: fmt.Sprintf("the %s bucket is in %s, but load balancers can only write access logs to buckets in the same region as the cluster (%s)", bucketName, bucketRegion, clusterRegion),
+	})
+}
@@ -131,6 +131,13 @@ func getStatus(statusMap map[string]string, controlPlane string, clusterName str
 		return StatusCreateInProgress, nil
 	}
 
+	// the control plane was created, but some of the nodegroups could not be (e.g. due to insufficient capacity); `cluster up` can be resumed in this state
+	if controlPlaneStatus == cloudformation.StackStatusCreateComplete &&
+		any(nodeGroupStatuses, cloudformation.StackStatusCreateFailed, cloudformation.StackStatusRollbackComplete, cloudformation.StackStatusRollbackFailed) &&
+		all(nodeGroupStatuses, cloudformation.StackStatusCreateComplete, cloudformation.StackStatusCreateFailed, cloudformation.StackStatusRollbackComplete, cloudformation.StackStatusRollbackFailed, cloudformation.StackStatusDeleteComplete) {
+		return StatusNodeGroupCreateFailed, nil
+	}
+
 	if any(allStatuses, cloudformation.StackStatusCreateFailed) {
 		return StatusCreateFailed, nil
 	}
@@ -197,6 +204,10 @@ func GetClusterState(awsClient *aws.Client, accessConfig *clusterconfig.AccessCo
 	var controlPlaneCreationTime time.Time
 
 	for _, stackSummary := range stackSummaries {
+		// stacks are listed from newest to oldest, and a nodegroup's stack may have been deleted and recreated (e.g. when `cluster up` is resumed)
+		if _, ok := statusMap[*stackSummary.StackName]; ok {
+			continue
+		}
 		statusMap[*stackSummary.StackName] = *stackSummary.StackStatus
 		if *stackSummary.StackName == controlPlaneStackName {
 			controlPlaneCreationTime = *stackSummary.CreationTime
@@ -27,6 +27,7 @@ const (
 	ErrClusterUpInProgress            = "clusterstatus.cluster_up_in_progress"
 	ErrClusterCreateFailed            = "clusterstatus.cluster_create_failed"
 	ErrClusterCreateFailedTimeout     = "clusterstatus.cluster_create_failed_timeout"
+	ErrNodeGroupCreateFailed          = "clusterstatus.nodegroup_create_failed"
 	ErrClusterAlreadyCreated          = "clusterstatus.cluster_already_created"
 	ErrClusterAlreadyUpdated          = "clusterstatus.cluster_already_updated"
 	ErrClusterDownInProgress          = "clusterstatus.cluster_down_in_progress"
@@ -56,6 +57,13 @@ func ErrorClusterCreateFailed(clusterName string, region string) error {
 	})
 }
 
+func ErrorNodeGroupCreateFailed(clusterName string, region string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrNodeGroupCreateFailed,
+		Message: fmt.Sprintf("some of the node groups of cluster \"%s\" in %s could not be created; please view error information in your AWS console (%s), and then run `cortex cluster up` to resume creating the cluster or `cortex cluster down` to delete it", clusterName, region, CloudFormationURL(clusterName, region)),
+	})
+}
+
 func ErrorClusterCreateFailedTimeout(clusterName string, region string) error {
 	return errors.WithStack(&errors.Error{
 		Kind:    ErrClusterCreateFailedTimeout,
@@ -23,6 +23,7 @@ const (
 	StatusCreateInProgress       Status = "create_in_progress"
 	StatusCreateFailed           Status = "create_failed"
 	StatusCreateFailedTimedOut   Status = "create_failed_timed_out"
+	StatusNodeGroupCreateFailed  Status = "nodegroup_create_failed"
 	StatusCreateComplete         Status = "create_complete"
 	StatusUpdateComplete         Status = "update_complete"
 	StatusUpdateRollbackComplete Status = "update_rollback_complete"
@@ -47,6 +48,8 @@ func AssertClusterStatus(clusterName string, region string, status Status, allow
 		return ErrorClusterCreateFailed(clusterName, region)
 	case StatusCreateFailedTimedOut:
 		return ErrorClusterCreateFailedTimeout(clusterName, region)
+	case StatusNodeGroupCreateFailed:
+		return ErrorNodeGroupCreateFailed(clusterName, region)
 	case StatusCreateComplete:
 		return ErrorClusterAlreadyCreated(clusterName, region)
 	case StatusUpdateComplete:
@@ -24,6 +24,7 @@ import (
 type Metrics struct {
 	APIName      string        `json:"api_name"`
 	NetworkStats *NetworkStats `json:"network_stats"`
+	SLO          *SLOStatus    `json:"slo,omitempty"`
 }
 
 func (left Metrics) Merge(right Metrics) Metrics {
@@ -0,0 +1,59 @@
+/*
+Copyright 2021 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+type SLOStatus struct {
+	Window       string                 `json:"window"`
+	Availability *AvailabilitySLOStatus `json:"availability,omitempty"`
+	Latency      []LatencySLOStatus     `json:"latency,omitempty"`
+}
+
+type AvailabilitySLOStatus struct {
+	Target          float64  `json:"target"`           // percentage
+	Attained        *float64 `json:"attained"`         // percentage over the SLO window (nil if the API hasn't received requests)
+	BurnRate        *float64 `json:"burn_rate"`        // rate at which the error budget was consumed over the last hour (1 means the budget would be used up exactly at the end of the window)
+	RemainingBudget *float64 `json:"remaining_budget"` // fraction of the error budget which remains for the window (negative if the SLO was missed)
+}
+
+type LatencySLOStatus struct {
+	Percentile float64  `json:"percentile"`
+	Threshold  float64  `json:"threshold"` // milliseconds
+	Attained   *float64 `json:"attained"`  // milliseconds over the SLO window (nil if the API hasn't received requests)
+}
+
+// ErrorBudgetStatus returns the burn rate and the remaining budget, given the target availability (as a percentage) and the observed error ratios
+func ErrorBudgetStatus(target float64, windowErrorRatio *float64, recentErrorRatio *float64) (burnRate *float64, remainingBudget *float64) {
+	budget := 1 - target/100
+	if budget <= 0 {
+		return nil, nil
+	}
+
+	if recentErrorRatio != nil {
+		rate := *recentErrorRatio / budget
+		burnRate = &rate
+	}
+	if windowErrorRatio != nil {
+		remaining := 1 - *windowErrorRatio/budget
+		remainingBudget = &remaining
+	}
+
+	return burnRate, remainingBudget
+}
+
+func (latencySLOStatus LatencySLOStatus) IsMet() bool {
+	return latencySLOStatus.Attained == nil || *latencySLOStatus.Attained <= latencySLOStatus.Threshold
+}
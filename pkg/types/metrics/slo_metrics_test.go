@@ -0,0 +1,43 @@
+/*
+Copyright 2021 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"testing"
+
+	"github.com/cortexlabs/cortex/pkg/lib/pointer"
+	"github.com/stretchr/testify/require"
+)
+
+func TestErrorBudgetStatus(t *testing.T) {
+	burnRate, remainingBudget := ErrorBudgetStatus(99, nil, nil)
+	require.Nil(t, burnRate)
+	require.Nil(t, remainingBudget)
+
+	burnRate, remainingBudget = ErrorBudgetStatus(99, pointer.Float64(0.0025), pointer.Float64(0.02))
+	require.InDelta(t, 2, *burnRate, 1e-9)
+	require.InDelta(t, 0.75, *remainingBudget, 1e-9)
+
+	_, remainingBudget = ErrorBudgetStatus(99.9, pointer.Float64(0.002), nil)
+	require.InDelta(t, -1, *remainingBudget, 1e-9)
+}
+
+func TestLatencySLOStatusIsMet(t *testing.T) {
+	require.True(t, LatencySLOStatus{Percentile: 99, Threshold: 100}.IsMet())
+	require.True(t, LatencySLOStatus{Percentile: 99, Threshold: 100, Attained: pointer.Float64(100)}.IsMet())
+	require.False(t, LatencySLOStatus{Percentile: 99, Threshold: 100, Attained: pointer.Float64(100.5)}.IsMet())
+}
@@ -0,0 +1,95 @@
+/*
+Copyright 2021 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rbac
+
+type Role int
+
+const (
+	UnknownRole Role = iota
+	AdminRole
+	DeployRole
+	ReadOnlyRole
+)
+
+var _roles = []string{
+	"unknown",
+	"admin",
+	"deploy",
+	"read-only",
+}
+
+func RoleFromString(s string) Role {
+	for i := 0; i < len(_roles); i++ {
+		if s == _roles[i] {
+			return Role(i)
+		}
+	}
+	return UnknownRole
+}
+
+func RoleStrings() []string {
+	return _roles[1:]
+}
+
+func (t Role) String() string {
+	return _roles[t]
+}
+
+// CanRead returns true if the role is allowed to view apis, jobs, logs, and the audit log
+func (t Role) CanRead() bool {
+	return t == AdminRole || t == DeployRole || t == ReadOnlyRole
+}
+
+// CanDeploy returns true if the role is allowed to deploy, refresh, and delete apis
+func (t Role) CanDeploy() bool {
+	return t == AdminRole || t == DeployRole
+}
+
+// CanManageTokens returns true if the role is allowed to create, list, and revoke operator tokens
+func (t Role) CanManageTokens() bool {
+	return t == AdminRole
+}
+
+// MarshalText satisfies TextMarshaler
+func (t Role) MarshalText() ([]byte, error) {
+	return []byte(t.String()), nil
+}
+
+// UnmarshalText satisfies TextUnmarshaler
+func (t *Role) UnmarshalText(text []byte) error {
+	enum := string(text)
+	for i := 0; i < len(_roles); i++ {
+		if enum == _roles[i] {
+			*t = Role(i)
+			return nil
+		}
+	}
+
+	*t = UnknownRole
+	return nil
+}
+
+// UnmarshalBinary satisfies BinaryUnmarshaler
+// Needed for msgpack
+func (t *Role) UnmarshalBinary(data []byte) error {
+	return t.UnmarshalText(data)
+}
+
+// MarshalBinary satisfies BinaryMarshaler
+func (t Role) MarshalBinary() ([]byte, error) {
+	return []byte(t.String()), nil
+}
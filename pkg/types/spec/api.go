@@ -53,10 +53,21 @@ APIID (uniquely identifies an api configuration for a given deployment)
 				* Containers
 				* Compute
 			* Pod
+			* Project (determines the pods' service account and labels)
+			* Labels
+			* PayloadLogging (configures the proxy container)
+			* Proxy (configures the proxy container)
+			* Models (configures the proxy and model manager containers)
+			* Logging (configures the cortex containers)
 		* Deployment Strategy
 		* Autoscaling
 		* Networking
+		* Gateway (AsyncAPI only)
+		* Expiration (AsyncAPI only)
+		* Retries (RealtimeAPI only)
 		* APIs
+		* SessionAffinity
+		* Steps
 	* DeploymentID (used for refreshing a deployment)
 */
 func GetAPISpec(apiConfig *userconfig.API, deploymentID string, clusterUID string) *API {
@@ -64,6 +75,30 @@ func GetAPISpec(apiConfig *userconfig.API, deploymentID string, clusterUID strin
 
 	buf.WriteString(s.Obj(apiConfig.Resource))
 	buf.WriteString(s.Obj(apiConfig.Pod))
+	if apiConfig.Project != nil {
+		// only hashed when set, so that the pod ids of apis without a project are unchanged
+		buf.WriteString(*apiConfig.Project)
+	}
+	if len(apiConfig.Labels) > 0 {
+		// only hashed when set, so that the pod ids of apis without labels are unchanged
+		buf.WriteString(s.Obj(apiConfig.Labels))
+	}
+	if apiConfig.PayloadLogging != nil {
+		// only hashed when set, so that the pod ids of apis without payload logging are unchanged
+		buf.WriteString(s.Obj(apiConfig.PayloadLogging))
+	}
+	if apiConfig.Proxy != nil {
+		// only hashed when set, so that the pod ids of apis without a proxy configuration are unchanged
+		buf.WriteString(s.Obj(apiConfig.Proxy))
+	}
+	if apiConfig.Models != nil {
+		// only hashed when set, so that the pod ids of apis without models are unchanged
+		buf.WriteString(s.Obj(apiConfig.Models))
+	}
+	if apiConfig.Logging != nil {
+		// only hashed when set, so that the pod ids of apis without a logging configuration are unchanged
+		buf.WriteString(s.Obj(apiConfig.Logging))
+	}
 	podID := hash.Bytes(buf.Bytes())
 
 	buf.Reset()
@@ -72,6 +107,27 @@ func GetAPISpec(apiConfig *userconfig.API, deploymentID string, clusterUID strin
 	buf.WriteString(s.Obj(apiConfig.Networking))
 	buf.WriteString(s.Obj(apiConfig.Autoscaling))
 	buf.WriteString(s.Obj(apiConfig.UpdateStrategy))
+	buf.WriteString(s.Obj(apiConfig.Availability))
+	if apiConfig.SessionAffinity != nil {
+		// only hashed when set, so that the spec ids of traffic splitters without session affinity are unchanged
+		buf.WriteString(s.Obj(apiConfig.SessionAffinity))
+	}
+	if len(apiConfig.Steps) > 0 {
+		// only hashed when set, so that the spec ids of the other kinds are unchanged
+		buf.WriteString(s.Obj(apiConfig.Steps))
+	}
+	if apiConfig.Gateway != nil {
+		// only hashed when set, so that the spec ids of the other kinds are unchanged
+		buf.WriteString(s.Obj(apiConfig.Gateway))
+	}
+	if apiConfig.Expiration != nil {
+		// only hashed when set, so that the spec ids of apis without an expiration are unchanged
+		buf.WriteString(apiConfig.Expiration.String())
+	}
+	if apiConfig.Retries != nil {
+		// only hashed when set, so that the spec ids of apis without retries are unchanged
+		buf.WriteString(s.Obj(apiConfig.Retries))
+	}
 	specID := hash.Bytes(buf.Bytes())[:32]
 
 	apiID := fmt.Sprintf("%s-%s-%s", MonotonicallyDecreasingID(), deploymentID, specID) // should be up to 60 characters long
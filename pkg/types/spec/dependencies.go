@@ -0,0 +1,93 @@
+/*
+Copyright 2021 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spec
+
+import (
+	"github.com/cortexlabs/cortex/pkg/types/userconfig"
+)
+
+// APIDependencies returns the names of the apis which the api depends on; traffic splitters implicitly depend on the apis which they route to,
+// and workflows on the task apis which their steps run on
+func APIDependencies(api *userconfig.API) []string {
+	dependencies := append([]string{}, api.DependsOn...)
+	if api.Kind == userconfig.TrafficSplitterKind {
+		for _, trafficSplit := range api.APIs {
+			dependencies = append(dependencies, trafficSplit.Name)
+		}
+	}
+	if api.Kind == userconfig.WorkflowKind {
+		for _, step := range api.Steps {
+			dependencies = append(dependencies, step.API)
+		}
+	}
+	return dependencies
+}
+
+// SortAPIsByDependencies orders the apis so that each api comes after the apis in the list which it depends on (dependencies on apis which
+// aren't in the list are ignored); apart from that, the order of the apis in the configuration file is preserved
+func SortAPIsByDependencies(apis []userconfig.API) ([]userconfig.API, error) {
+	indexes := make(map[string]int, len(apis))
+	for i := range apis {
+		indexes[apis[i].Name] = i
+	}
+
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	states := make([]int, len(apis))
+	sorted := make([]userconfig.API, 0, len(apis))
+
+	var visit func(i int, path []string) error
+	visit = func(i int, path []string) error {
+		switch states[i] {
+		case visited:
+			return nil
+		case visiting:
+			// the path starts at the first api which was visited, which isn't necessarily part of the cycle
+			for start, name := range path {
+				if name == apis[i].Name {
+					path = path[start:]
+					break
+				}
+			}
+			return ErrorDependencyCycle(append(path, apis[i].Name))
+		}
+
+		states[i] = visiting
+		for _, dependency := range APIDependencies(&apis[i]) {
+			if j, ok := indexes[dependency]; ok {
+				if err := visit(j, append(path, apis[i].Name)); err != nil {
+					return err
+				}
+			}
+		}
+		states[i] = visited
+
+		sorted = append(sorted, apis[i])
+		return nil
+	}
+
+	for i := range apis {
+		if err := visit(i, nil); err != nil {
+			return nil, err
+		}
+	}
+
+	return sorted, nil
+}
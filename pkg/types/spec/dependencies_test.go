@@ -0,0 +1,76 @@
+/*
+Copyright 2021 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spec
+
+import (
+	"testing"
+
+	"github.com/cortexlabs/cortex/pkg/types/userconfig"
+	"github.com/stretchr/testify/require"
+)
+
+func testDependencyAPI(name string, kind userconfig.Kind, dependsOn ...string) userconfig.API {
+	return userconfig.API{
+		Resource:  userconfig.Resource{Name: name, Kind: kind},
+		DependsOn: dependsOn,
+	}
+}
+
+func apiNames(apis []userconfig.API) []string {
+	names := make([]string, len(apis))
+	for i := range apis {
+		names[i] = apis[i].Name
+	}
+	return names
+}
+
+func TestSortAPIsByDependencies(t *testing.T) {
+	splitter := testDependencyAPI("splitter", userconfig.TrafficSplitterKind)
+	splitter.APIs = []*userconfig.TrafficSplit{{Name: "a"}, {Name: "b"}}
+
+	sorted, err := SortAPIsByDependencies([]userconfig.API{
+		splitter,
+		testDependencyAPI("a", userconfig.RealtimeAPIKind, "c"),
+		testDependencyAPI("b", userconfig.RealtimeAPIKind),
+		testDependencyAPI("c", userconfig.AsyncAPIKind, "deployed-elsewhere"),
+		testDependencyAPI("d", userconfig.BatchAPIKind),
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{"c", "a", "b", "splitter", "d"}, apiNames(sorted))
+
+	sorted, err = SortAPIsByDependencies([]userconfig.API{
+		testDependencyAPI("a", userconfig.RealtimeAPIKind),
+		testDependencyAPI("b", userconfig.RealtimeAPIKind),
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{"a", "b"}, apiNames(sorted))
+}
+
+func TestSortAPIsByDependenciesCycle(t *testing.T) {
+	_, err := SortAPIsByDependencies([]userconfig.API{
+		testDependencyAPI("x", userconfig.RealtimeAPIKind, "a"),
+		testDependencyAPI("a", userconfig.RealtimeAPIKind, "b"),
+		testDependencyAPI("b", userconfig.RealtimeAPIKind, "a"),
+	})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "a -> b -> a")
+
+	_, err = SortAPIsByDependencies([]userconfig.API{
+		testDependencyAPI("a", userconfig.RealtimeAPIKind, "a"),
+	})
+	require.Error(t, err)
+}
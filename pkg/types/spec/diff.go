@@ -0,0 +1,129 @@
+/*
+Copyright 2021 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spec
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	libjson "github.com/cortexlabs/cortex/pkg/lib/json"
+	s "github.com/cortexlabs/cortex/pkg/lib/strings"
+	"github.com/cortexlabs/cortex/pkg/types/userconfig"
+)
+
+// these fields describe where the api was declared rather than how it is configured
+var _diffIgnoredFields = []string{"index", "file_name", "submitted_api_spec"}
+
+type FieldDiff struct {
+	Field string  `json:"field"`
+	Old   *string `json:"old"` // nil if the field was not set in the deployed api
+	New   *string `json:"new"` // nil if the field is not set in the submitted api
+}
+
+// DiffAPIConfigs returns the fields which differ between the deployed and the submitted api configurations (sorted by field path);
+// both configurations are expected to have been validated, so that their defaults are populated
+func DiffAPIConfigs(deployed *userconfig.API, submitted *userconfig.API) ([]FieldDiff, error) {
+	deployedFields, err := flattenAPIConfig(deployed)
+	if err != nil {
+		return nil, err
+	}
+
+	submittedFields, err := flattenAPIConfig(submitted)
+	if err != nil {
+		return nil, err
+	}
+
+	var diffs []FieldDiff
+
+	for field, oldVal := range deployedFields {
+		oldVal := oldVal
+		newVal, ok := submittedFields[field]
+		if !ok {
+			diffs = append(diffs, FieldDiff{Field: field, Old: &oldVal})
+		} else if newVal != oldVal {
+			diffs = append(diffs, FieldDiff{Field: field, Old: &oldVal, New: &newVal})
+		}
+	}
+
+	for field, newVal := range submittedFields {
+		newVal := newVal
+		if _, ok := deployedFields[field]; !ok {
+			diffs = append(diffs, FieldDiff{Field: field, New: &newVal})
+		}
+	}
+
+	sort.Slice(diffs, func(i, j int) bool {
+		return diffs[i].Field < diffs[j].Field
+	})
+
+	return diffs, nil
+}
+
+// returns a map of each leaf field's path (e.g. pod.containers[0].compute.cpu) to its value
+func flattenAPIConfig(api *userconfig.API) (map[string]string, error) {
+	jsonBytes, err := libjson.Marshal(api)
+	if err != nil {
+		return nil, err
+	}
+
+	var obj map[string]interface{}
+	if err := libjson.DecodeWithNumber(jsonBytes, &obj); err != nil {
+		return nil, err
+	}
+
+	for _, field := range _diffIgnoredFields {
+		delete(obj, field)
+	}
+
+	fields := map[string]string{}
+	flattenValue("", obj, fields)
+	return fields, nil
+}
+
+func flattenValue(path string, val interface{}, fields map[string]string) {
+	switch typedVal := val.(type) {
+	case map[string]interface{}:
+		if len(typedVal) == 0 {
+			fields[path] = "{}"
+			return
+		}
+		for key, subVal := range typedVal {
+			subPath := key
+			if path != "" {
+				subPath = path + "." + key
+			}
+			flattenValue(subPath, subVal, fields)
+		}
+	case []interface{}:
+		if len(typedVal) == 0 {
+			fields[path] = "[]"
+			return
+		}
+		for i, subVal := range typedVal {
+			flattenValue(fmt.Sprintf("%s[%d]", path, i), subVal, fields)
+		}
+	case nil:
+		fields[path] = "null"
+	case string:
+		fields[path] = typedVal
+	case json.Number:
+		fields[path] = typedVal.String()
+	default:
+		fields[path] = s.Obj(typedVal)
+	}
+}
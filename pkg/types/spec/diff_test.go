@@ -0,0 +1,80 @@
+/*
+Copyright 2021 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spec
+
+import (
+	"testing"
+
+	"github.com/cortexlabs/cortex/pkg/lib/pointer"
+	"github.com/cortexlabs/cortex/pkg/types/userconfig"
+	"github.com/stretchr/testify/require"
+)
+
+func testAPIConfig(image string, env map[string]string, maxReplicas int32) *userconfig.API {
+	return &userconfig.API{
+		Resource: userconfig.Resource{
+			Name: "test",
+			Kind: userconfig.RealtimeAPIKind,
+		},
+		Pod: &userconfig.Pod{
+			Containers: []*userconfig.Container{
+				{
+					Name:  "api",
+					Image: image,
+					Env:   env,
+				},
+			},
+		},
+		Autoscaling: &userconfig.Autoscaling{
+			MaxReplicas: maxReplicas,
+		},
+	}
+}
+
+func TestDiffAPIConfigs(t *testing.T) {
+	deployed := testAPIConfig("image:1", map[string]string{"A": "1", "B": "2"}, 10)
+
+	diffs, err := DiffAPIConfigs(deployed, testAPIConfig("image:1", map[string]string{"A": "1", "B": "2"}, 10))
+	require.NoError(t, err)
+	require.Empty(t, diffs)
+
+	// the location of the api's declaration is not part of the diff
+	submitted := testAPIConfig("image:1", map[string]string{"A": "1", "B": "2"}, 10)
+	submitted.FileName = "other.yaml"
+	submitted.Index = 3
+	diffs, err = DiffAPIConfigs(deployed, submitted)
+	require.NoError(t, err)
+	require.Empty(t, diffs)
+
+	diffs, err = DiffAPIConfigs(deployed, testAPIConfig("image:2", map[string]string{"A": "3", "C": "4"}, 20))
+	require.NoError(t, err)
+	require.Equal(t, []FieldDiff{
+		{Field: "autoscaling.max_replicas", Old: pointer.String("10"), New: pointer.String("20")},
+		{Field: "pod.containers[0].env.A", Old: pointer.String("1"), New: pointer.String("3")},
+		{Field: "pod.containers[0].env.B", Old: pointer.String("2")},
+		{Field: "pod.containers[0].env.C", New: pointer.String("4")},
+		{Field: "pod.containers[0].image", Old: pointer.String("image:1"), New: pointer.String("image:2")},
+	}, diffs)
+
+	diffs, err = DiffAPIConfigs(deployed, testAPIConfig("image:1", map[string]string{}, 10))
+	require.NoError(t, err)
+	require.Equal(t, []FieldDiff{
+		{Field: "pod.containers[0].env", New: pointer.String("{}")},
+		{Field: "pod.containers[0].env.A", Old: pointer.String("1")},
+		{Field: "pod.containers[0].env.B", Old: pointer.String("2")},
+	}, diffs)
+}
@@ -19,8 +19,10 @@ package spec
 import (
 	"fmt"
 	"regexp"
+	"strings"
 
 	"github.com/cortexlabs/cortex/pkg/consts"
+	"github.com/cortexlabs/cortex/pkg/lib/aws"
 	"github.com/cortexlabs/cortex/pkg/lib/errors"
 	"github.com/cortexlabs/cortex/pkg/lib/k8s"
 	"github.com/cortexlabs/cortex/pkg/lib/sets/strset"
@@ -35,7 +37,13 @@ const (
 	ErrDuplicateEndpointInOneDeploy = "spec.duplicate_endpoint_in_one_deploy"
 	ErrDuplicateEndpoint            = "spec.duplicate_endpoint"
 	ErrDuplicateContainerName       = "spec.duplicate_container_name"
+	ErrDuplicateSecretName          = "spec.duplicate_secret_name"
+	ErrDuplicateModelName           = "spec.duplicate_model_name"
+	ErrDuplicateClient              = "spec.duplicate_client"
+	ErrSecretConflictsWithEnvVar    = "spec.secret_conflicts_with_env_var"
+	ErrRuntimeConfigTooLarge        = "spec.runtime_config_too_large"
 	ErrSpecifyExactlyOneField       = "spec.specify_exactly_one_field"
+	ErrInvalidProbeSuccessThreshold = "spec.invalid_probe_success_threshold"
 	ErrSpecifyAllOrNone             = "spec.specify_all_or_none"
 	ErrOneOfPrerequisitesNotDefined = "spec.one_of_prerequisites_not_defined"
 	ErrConfigGreaterThanOtherConfig = "spec.config_greater_than_other_config"
@@ -47,6 +55,10 @@ const (
 
 	ErrInvalidSurgeOrUnavailable   = "spec.invalid_surge_or_unavailable"
 	ErrSurgeAndUnavailableBothZero = "spec.surge_and_unavailable_both_zero"
+	ErrMaxDisruptedReplicasZero    = "spec.max_disrupted_replicas_zero"
+	ErrMaxDisruptedReplicasTooHigh = "spec.max_disrupted_replicas_too_high"
+	ErrSLOMissingTarget            = "spec.slo_missing_target"
+	ErrDuplicateLatencyPercentile  = "spec.duplicate_latency_percentile"
 
 	ErrShmCannotExceedMem = "spec.shm_cannot_exceed_mem"
 
@@ -56,10 +68,40 @@ const (
 	ErrDisallowedEnvVars              = "spec.disallowed_env_vars"
 	ErrComputeResourceConflict        = "spec.compute_resource_conflict"
 	ErrInvalidNumberOfInfs            = "spec.invalid_number_of_infs"
+	ErrInvalidFractionalGPU           = "spec.invalid_fractional_gpu"
 	ErrIncorrectTrafficSplitterWeight = "spec.incorrect_traffic_splitter_weight"
 	ErrTrafficSplitterAPIsNotUnique   = "spec.traffic_splitter_apis_not_unique"
 	ErrOneShadowPerTrafficSplitter    = "spec.one_shadow_per_traffic_splitter"
+	ErrSessionAffinityMissingKey      = "spec.session_affinity_missing_key"
 	ErrUnexpectedDockerSecretData     = "spec.unexpected_docker_secret_data"
+	ErrS3PathNotFound                 = "spec.s3_path_not_found"
+	ErrInvalidHost                    = "spec.invalid_host"
+	ErrInvalidLabelValue              = "spec.invalid_label_value"
+	ErrWindowsAPIRequiresAMD64        = "spec.windows_api_requires_amd64"
+	ErrNotSupportedForWindowsAPIs     = "spec.not_supported_for_windows_apis"
+
+	ErrDuplicateRegistryCredentials                = "spec.duplicate_registry_credentials"
+	ErrRegistryCredentialsSecretRequired           = "spec.registry_credentials_secret_required"
+	ErrRegistryCredentialsSecretNotSupportedForECR = "spec.registry_credentials_secret_not_supported_for_ecr"
+	ErrInvalidRegistryCredentialsSecret            = "spec.invalid_registry_credentials_secret"
+	ErrMalformedValues                             = "spec.malformed_values"
+	ErrOverlayAPINotFound                          = "spec.overlay_api_not_found"
+	ErrUndefinedVariable                           = "spec.undefined_variable"
+	ErrUndefinedEnvVar                             = "spec.undefined_env_var"
+	ErrDependencyCycle                             = "spec.dependency_cycle"
+	ErrRegistryLoginFailed                         = "spec.registry_login_failed"
+	ErrDuplicateWorkflowStepName                   = "spec.duplicate_workflow_step_name"
+	ErrWorkflowStepNotFound                        = "spec.workflow_step_not_found"
+	ErrWorkflowStepCycle                           = "spec.workflow_step_cycle"
+	ErrConflictingFields                           = "spec.conflicting_fields"
+	ErrFieldRequiredForModelRegistry               = "spec.field_required_for_model_registry"
+	ErrFieldNotSupportedForModelRegistry           = "spec.field_not_supported_for_model_registry"
+	ErrModelArtifactsNotInS3                       = "spec.model_artifacts_not_in_s3"
+	ErrInvalidImageDigest                          = "spec.invalid_image_digest"
+	ErrImageDigestMismatch                         = "spec.image_digest_mismatch"
+	ErrInvalidSHA256Checksum                       = "spec.invalid_sha256_checksum"
+	ErrInvalidBase64                               = "spec.invalid_base64"
+	ErrInvalidRetryCondition                       = "spec.invalid_retry_condition"
 )
 
 func ErrorMalformedConfig() error {
@@ -114,6 +156,41 @@ func ErrorDuplicateContainerName(containerName string) error {
 	})
 }
 
+func ErrorDuplicateSecretName(secretName string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrDuplicateSecretName,
+		Message: fmt.Sprintf("secret name %s must be unique", s.UserStr(secretName)),
+	})
+}
+
+func ErrorDuplicateModelName(modelName string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrDuplicateModelName,
+		Message: fmt.Sprintf("model name %s must be unique", s.UserStr(modelName)),
+	})
+}
+
+func ErrorDuplicateClient(client string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrDuplicateClient,
+		Message: fmt.Sprintf("client %s is listed multiple times", s.UserStr(client)),
+	})
+}
+
+func ErrorSecretConflictsWithEnvVar(secretName string, containerName string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrSecretConflictsWithEnvVar,
+		Message: fmt.Sprintf("secret %s conflicts with the environment variable of the same name in container %s", s.UserStr(secretName), s.UserStr(containerName)),
+	})
+}
+
+func ErrorRuntimeConfigTooLarge(maxSize int) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrRuntimeConfigTooLarge,
+		Message: fmt.Sprintf("the total size of the config files cannot exceed %s; larger files can be downloaded from S3 using the %s field", s.IntToBase2Byte(maxSize), userconfig.ModelCacheKey),
+	})
+}
+
 func ErrorSpecifyExactlyOneField(numSpecified int, fields ...string) error {
 	var msg string
 
@@ -136,6 +213,13 @@ func ErrorSpecifyExactlyOneField(numSpecified int, fields ...string) error {
 	})
 }
 
+func ErrorInvalidProbeSuccessThreshold(probeKey string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrInvalidProbeSuccessThreshold,
+		Message: fmt.Sprintf("%s must be 1 for %s (only %s may require more than one consecutive success)", userconfig.SuccessThresholdKey, probeKey, userconfig.ReadinessProbeKey),
+	})
+}
+
 func ErrorSpecifyAllOrNone(val string, vals ...string) error {
 	allVals := append([]string{val}, vals...)
 	message := fmt.Sprintf("please specify all or none of %s", s.UserStrsAnd(allVals))
@@ -208,6 +292,34 @@ func ErrorSurgeAndUnavailableBothZero() error {
 	})
 }
 
+func ErrorSLOMissingTarget() error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrSLOMissingTarget,
+		Message: fmt.Sprintf("at least one of %s or %s must be specified", userconfig.SLOAvailabilityKey, userconfig.LatencyKey),
+	})
+}
+
+func ErrorDuplicateLatencyPercentile(percentile float64) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrDuplicateLatencyPercentile,
+		Message: fmt.Sprintf("multiple latency targets were specified for percentile %s", s.Float64(percentile)),
+	})
+}
+
+func ErrorMaxDisruptedReplicasZero() error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrMaxDisruptedReplicasZero,
+		Message: fmt.Sprintf("%s cannot be zero, since that would prevent nodes from being drained (e.g. when the cluster scales down); to disable the pod disruption budget, set %s to null", userconfig.MaxDisruptedReplicasKey, userconfig.MaxDisruptedReplicasKey),
+	})
+}
+
+func ErrorMaxDisruptedReplicasTooHigh(maxDisruptedReplicas string, disruptedReplicas int32, minReplicas int32) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrMaxDisruptedReplicasTooHigh,
+		Message: fmt.Sprintf("%s (%s) would allow %d of the api's %s %d replicas to be disrupted at the same time; %s must leave at least one replica running when the api is at %s (to disable the pod disruption budget, set %s to null)", userconfig.MaxDisruptedReplicasKey, maxDisruptedReplicas, disruptedReplicas, userconfig.MinReplicasKey, minReplicas, userconfig.MaxDisruptedReplicasKey, userconfig.MinReplicasKey, userconfig.MaxDisruptedReplicasKey),
+	})
+}
+
 func ErrorShmCannotExceedMem(shm k8s.Quantity, mem k8s.Quantity) error {
 	return errors.WithStack(&errors.Error{
 		Kind:    ErrShmCannotExceedMem,
@@ -229,6 +341,20 @@ func ErrorFieldIsNotSupportedForKind(field string, kind userconfig.Kind) error {
 	})
 }
 
+func ErrorWindowsAPIRequiresAMD64() error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrWindowsAPIRequiresAMD64,
+		Message: fmt.Sprintf("`%s: %s` requires `%s: %s`, since windows node groups only support x86 instances", userconfig.OSKey, aws.OSWindows, userconfig.ArchKey, aws.ArchAMD64),
+	})
+}
+
+func ErrorNotSupportedForWindowsAPIs(field string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrNotSupportedForWindowsAPIs,
+		Message: fmt.Sprintf("%s is not supported for windows apis (`%s: %s`)", field, userconfig.OSKey, aws.OSWindows),
+	})
+}
+
 func ErrorCortexPrefixedEnvVarNotAllowed(prefixes ...string) error {
 	return errors.WithStack(&errors.Error{
 		Kind:    ErrCortexPrefixedEnvVarNotAllowed,
@@ -253,7 +379,14 @@ func ErrorComputeResourceConflict(resourceA, resourceB string) error {
 func ErrorInvalidNumberOfInfs(requestedInfs int64) error {
 	return errors.WithStack(&errors.Error{
 		Kind:    ErrInvalidNumberOfInfs,
-		Message: fmt.Sprintf("cannot request %d Infs (currently only 1 Inf can be used per API replica, due to AWS's bug: https://github.com/aws/aws-neuron-sdk/issues/110)", requestedInfs),
+		Message: fmt.Sprintf("cannot request %d Infs on inf1 instances (currently only 1 Inf can be used per API replica on inf1 instances, due to AWS's bug: https://github.com/aws/aws-neuron-sdk/issues/110)", requestedInfs),
+	})
+}
+
+func ErrorInvalidFractionalGPU(gpu float64) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrInvalidFractionalGPU,
+		Message: fmt.Sprintf("cannot request %s GPUs (fractional GPUs can only be requested if less than 1 GPU is requested)", s.Round(gpu, 4, 0)),
 	})
 }
 
@@ -278,6 +411,20 @@ func ErrorOneShadowPerTrafficSplitter() error {
 	})
 }
 
+func ErrorSessionAffinityMissingKey() error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrSessionAffinityMissingKey,
+		Message: fmt.Sprintf("at least one of %s or %s must be specified", userconfig.CookieKey, userconfig.HeaderKey),
+	})
+}
+
+func ErrorS3PathNotFound(path string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrS3PathNotFound,
+		Message: fmt.Sprintf("%s does not exist or is not accessible by the cluster", path),
+	})
+}
+
 var _pwRegex = regexp.MustCompile(`"password":"[^"]+"`)
 var _authRegex = regexp.MustCompile(`"auth":"[^"]+"`)
 
@@ -296,3 +443,171 @@ func ErrorUnexpectedDockerSecretData(reason string, secretData map[string][]byte
 		Message: fmt.Sprintf("docker registry secret named \"%s\" was found, but contains unexpected data (%s); got: %s", _dockerPullSecretName, reason, s.UserStr(secretDataStrMap)),
 	})
 }
+
+func ErrorInvalidHost(host string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrInvalidHost,
+		Message: fmt.Sprintf("%s is not a valid DNS name (e.g. api.example.com)", s.UserStr(host)),
+	})
+}
+
+func ErrorInvalidLabelValue(value string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrInvalidLabelValue,
+		Message: fmt.Sprintf("%s is not a valid label value (label values can be up to 63 characters long, must consist of alphanumeric characters, '-', '_', or '.', and must start and end with an alphanumeric character)", s.UserStr(value)),
+	})
+}
+
+func ErrorDuplicateRegistryCredentials(registry string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrDuplicateRegistryCredentials,
+		Message: fmt.Sprintf("credentials for registry %s are specified more than once", s.UserStr(registry)),
+	})
+}
+
+func ErrorRegistryCredentialsSecretRequired(registry string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrRegistryCredentialsSecretRequired,
+		Message: fmt.Sprintf("%s must be specified for registry %s (it should reference a secret with \"username\" and \"password\" keys)", userconfig.SecretsManagerKey, s.UserStr(registry)),
+	})
+}
+
+func ErrorRegistryCredentialsSecretNotSupportedForECR(registry string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrRegistryCredentialsSecretNotSupportedForECR,
+		Message: fmt.Sprintf("%s is not supported for ECR registry %s, since ECR registries are accessed with the cluster's AWS credentials; grant the cluster access via the repository policy instead", userconfig.SecretsManagerKey, s.UserStr(registry)),
+	})
+}
+
+func ErrorInvalidRegistryCredentialsSecret(secretID string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrInvalidRegistryCredentialsSecret,
+		Message: fmt.Sprintf("secret %s must be a json object with non-empty \"username\" and \"password\" keys", s.UserStr(secretID)),
+	})
+}
+
+func ErrorRegistryLoginFailed(registry string, reason string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrRegistryLoginFailed,
+		Message: fmt.Sprintf("unable to log in to registry %s with the provided credentials: %s", s.UserStr(registry), reason),
+	})
+}
+
+func ErrorMalformedValues() error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrMalformedValues,
+		Message: fmt.Sprintf("values files must be a map which contains %s (a map of variable names to values) and/or %s (a list of api overlays, each of which must specify the %s of the api to update)", ValuesVariablesKey, ValuesAPIsKey, userconfig.NameKey),
+	})
+}
+
+func ErrorOverlayAPINotFound(apiName string, configFileName string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrOverlayAPINotFound,
+		Message: fmt.Sprintf("api %s was not found in %s", s.UserStr(apiName), configFileName),
+	})
+}
+
+func ErrorUndefinedVariable(name string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrUndefinedVariable,
+		Message: fmt.Sprintf("variable %s is not defined in the %s section of the values files", s.UserStr(name), ValuesVariablesKey),
+	})
+}
+
+func ErrorUndefinedEnvVar(name string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrUndefinedEnvVar,
+		Message: fmt.Sprintf("environment variable %s is not set", s.UserStr(name)),
+	})
+}
+
+func ErrorDependencyCycle(cycle []string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrDependencyCycle,
+		Message: fmt.Sprintf("the apis' dependencies contain a cycle (%s)", strings.Join(cycle, " -> ")),
+	})
+}
+
+func ErrorDuplicateWorkflowStepName(stepName string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrDuplicateWorkflowStepName,
+		Message: fmt.Sprintf("multiple steps are named %s; each step must have a unique name", s.UserStr(stepName)),
+	})
+}
+
+func ErrorWorkflowStepNotFound(stepName string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrWorkflowStepNotFound,
+		Message: fmt.Sprintf("step %s is not defined in the workflow", s.UserStr(stepName)),
+	})
+}
+
+func ErrorWorkflowStepCycle(cycle []string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrWorkflowStepCycle,
+		Message: fmt.Sprintf("the workflow's steps contain a cycle (%s)", strings.Join(cycle, " -> ")),
+	})
+}
+
+func ErrorConflictingFields(fieldA string, fieldB string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrConflictingFields,
+		Message: fmt.Sprintf("please specify either %s or %s (both cannot be specified at the same time)", s.UserStr(fieldA), s.UserStr(fieldB)),
+	})
+}
+
+func ErrorFieldRequiredForModelRegistry(field string, provider string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrFieldRequiredForModelRegistry,
+		Message: fmt.Sprintf("%s must be specified for %s model registries", s.UserStr(field), provider),
+	})
+}
+
+func ErrorFieldNotSupportedForModelRegistry(field string, provider string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrFieldNotSupportedForModelRegistry,
+		Message: fmt.Sprintf("%s is not supported for %s model registries", s.UserStr(field), provider),
+	})
+}
+
+func ErrorModelArtifactsNotInS3(artifactURI string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrModelArtifactsNotInS3,
+		Message: fmt.Sprintf("the model version's artifacts are stored at %s, but only artifacts stored in s3 are supported", artifactURI),
+	})
+}
+
+func ErrorInvalidImageDigest(digest string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrInvalidImageDigest,
+		Message: fmt.Sprintf("%s is not a valid image digest; image digests must be in the format sha256:<64 hexadecimal characters>", s.UserStr(digest)),
+	})
+}
+
+func ErrorImageDigestMismatch(image string, expectedDigest string, actualDigest string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrImageDigestMismatch,
+		Message: fmt.Sprintf("the digest of %s in its registry is %s, but %s was expected", image, actualDigest, expectedDigest),
+	})
+}
+
+func ErrorInvalidSHA256Checksum(checksum string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrInvalidSHA256Checksum,
+		Message: fmt.Sprintf("%s is not a valid sha256 checksum; sha256 checksums must be 64 hexadecimal characters", s.UserStr(checksum)),
+	})
+}
+
+func ErrorInvalidBase64() error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrInvalidBase64,
+		Message: "the value is not base64-encoded",
+	})
+}
+
+func ErrorInvalidRetryCondition(condition string, validConditions []string) error {
+	return errors.WithStack(&errors.Error{
+		Kind:    ErrInvalidRetryCondition,
+		Message: fmt.Sprintf("%s is not a valid retry condition; valid conditions are %s, or a 4xx or 5xx status code (e.g. 503)", s.UserStr(condition), s.StrsOr(validConditions)),
+	})
+}
@@ -0,0 +1,94 @@
+/*
+Copyright 2021 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spec
+
+import (
+	"encoding/base64"
+	"regexp"
+	"strings"
+
+	"github.com/cortexlabs/cortex/pkg/lib/integrity"
+	"github.com/cortexlabs/cortex/pkg/types/userconfig"
+)
+
+var (
+	_sha256Regex      = regexp.MustCompile(`^[a-f0-9]{64}$`)
+	_imageDigestRegex = regexp.MustCompile(`^sha256:[a-f0-9]{64}$`)
+)
+
+func validateImageDigest(digest string) (string, error) {
+	digest = strings.ToLower(digest)
+	if !_imageDigestRegex.MatchString(digest) {
+		return "", ErrorInvalidImageDigest(digest)
+	}
+	return digest, nil
+}
+
+func validateSHA256Checksum(checksum string) (string, error) {
+	checksum = strings.ToLower(checksum)
+	if !_sha256Regex.MatchString(checksum) {
+		return "", ErrorInvalidSHA256Checksum(checksum)
+	}
+	return checksum, nil
+}
+
+func validateBase64(str string) (string, error) {
+	str = strings.TrimSpace(str)
+	if _, err := base64.StdEncoding.DecodeString(str); err != nil {
+		return "", ErrorInvalidBase64()
+	}
+	return str, nil
+}
+
+func validatePublicKey(publicKey string) (string, error) {
+	if _, err := integrity.ParsePublicKey(publicKey); err != nil {
+		return "", err
+	}
+	return publicKey, nil
+}
+
+// a checksum can't be used with a watched model, since the model's artifacts are expected to change (but they can be signed)
+func validateIntegrity(modelIntegrity *userconfig.Integrity, watch bool) error {
+	if modelIntegrity.SHA256 == nil && modelIntegrity.Signature == nil {
+		return ErrorOneOfPrerequisitesNotDefined(userconfig.IntegrityKey, userconfig.SHA256Key, userconfig.SignatureKey)
+	}
+	if (modelIntegrity.Signature == nil) != (modelIntegrity.PublicKey == nil) {
+		return ErrorSpecifyAllOrNone(userconfig.SignatureKey, userconfig.PublicKeyKey)
+	}
+	if modelIntegrity.SHA256 != nil && watch {
+		return ErrorConflictingFields(userconfig.SHA256Key, userconfig.WatchKey)
+	}
+	return nil
+}
+
+// IntegritySpec converts the model's integrity configuration to the spec which the verifier checks the downloaded artifacts against
+func IntegritySpec(modelIntegrity *userconfig.Integrity) integrity.Spec {
+	var integritySpec integrity.Spec
+	if modelIntegrity == nil {
+		return integritySpec
+	}
+	if modelIntegrity.SHA256 != nil {
+		integritySpec.SHA256 = *modelIntegrity.SHA256
+	}
+	if modelIntegrity.Signature != nil {
+		integritySpec.Signature = *modelIntegrity.Signature
+	}
+	if modelIntegrity.PublicKey != nil {
+		integritySpec.PublicKey = *modelIntegrity.PublicKey
+	}
+	return integritySpec
+}
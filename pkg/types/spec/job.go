@@ -107,3 +107,38 @@ func JobBatchCountKey(clusterUID string, kind userconfig.Kind, apiName string, j
 func JobMetricsKey(clusterUID string, kind userconfig.Kind, apiName string, jobID string) string {
 	return filepath.Join(JobAPIPrefix(clusterUID, kind, apiName), jobID, MetricsFileKey)
 }
+
+// e.g. /<cluster UID>/jobs/<job_api_kind>/<cortex version>/<api_name>/<job_id>/checkpoints
+func JobCheckpointsPrefix(clusterUID string, kind userconfig.Kind, apiName string, jobID string) string {
+	return filepath.Join(JobAPIPrefix(clusterUID, kind, apiName), jobID, "checkpoints")
+}
+
+// e.g. /<cluster UID>/jobs/<job_api_kind>/<cortex version>/<api_name>/<job_id>/results
+func JobResultsPrefix(clusterUID string, kind userconfig.Kind, apiName string, jobID string) string {
+	return filepath.Join(JobAPIPrefix(clusterUID, kind, apiName), jobID, "results")
+}
+
+// e.g. /<cluster UID>/jobs/<job_api_kind>/<cortex version>/<api_name>/<job_id>/results/workers
+func JobWorkerResultsPrefix(resultsPrefix string) string {
+	return filepath.Join(resultsPrefix, "workers")
+}
+
+// e.g. /<cluster UID>/jobs/<job_api_kind>/<cortex version>/<api_name>/<job_id>/results/workers/<worker>.json
+func JobWorkerResultsKey(resultsPrefix string, worker string) string {
+	return filepath.Join(JobWorkerResultsPrefix(resultsPrefix), worker+".json")
+}
+
+// e.g. /<cluster UID>/jobs/<job_api_kind>/<cortex version>/<api_name>/<job_id>/results/failed
+func JobFailedBatchesPrefix(resultsPrefix string) string {
+	return filepath.Join(resultsPrefix, "failed")
+}
+
+// e.g. /<cluster UID>/jobs/<job_api_kind>/<cortex version>/<api_name>/<job_id>/results/failed/<batch_id>/error.json
+func JobFailedBatchErrorKey(resultsPrefix string, batchID string) string {
+	return filepath.Join(JobFailedBatchesPrefix(resultsPrefix), batchID, "error.json")
+}
+
+// e.g. /<cluster UID>/jobs/<job_api_kind>/<cortex version>/<api_name>/<job_id>/results/failed/<batch_id>/payload.json
+func JobFailedBatchPayloadKey(resultsPrefix string, batchID string) string {
+	return filepath.Join(JobFailedBatchesPrefix(resultsPrefix), batchID, "payload.json")
+}
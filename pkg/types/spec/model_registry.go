@@ -0,0 +1,151 @@
+/*
+Copyright 2021 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spec
+
+import (
+	"path/filepath"
+	"strconv"
+
+	"github.com/cortexlabs/cortex/pkg/lib/aws"
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/lib/mlflow"
+	"github.com/cortexlabs/cortex/pkg/types/userconfig"
+)
+
+const (
+	_defaultMLflowStage             = "Production"
+	_defaultSageMakerApprovalStatus = "Approved"
+)
+
+// resolveModelPath returns the s3 path of the model, resolving (and recording the version of) the model registry reference if one is
+// specified; since this happens before the API's spec is hashed, a new version of the model results in a new deployment
+func resolveModelPath(path string, modelRegistry *userconfig.ModelRegistry, awsClient *aws.Client) (string, error) {
+	if path != "" && modelRegistry != nil {
+		return "", ErrorSpecifyExactlyOneField(2, userconfig.PathKey, userconfig.ModelRegistryKey)
+	}
+	if path == "" && modelRegistry == nil {
+		return "", ErrorSpecifyExactlyOneField(0, userconfig.PathKey, userconfig.ModelRegistryKey)
+	}
+	if modelRegistry == nil {
+		return path, nil
+	}
+
+	resolvedPath, err := resolveModelRegistry(modelRegistry, awsClient)
+	if err != nil {
+		return "", errors.Wrap(err, userconfig.ModelRegistryKey)
+	}
+	return resolvedPath, nil
+}
+
+func resolveModelRegistry(modelRegistry *userconfig.ModelRegistry, awsClient *aws.Client) (string, error) {
+	if modelRegistry.Stage != nil && modelRegistry.Version != nil {
+		return "", ErrorConflictingFields(userconfig.StageKey, userconfig.ModelVersionKey)
+	}
+
+	switch modelRegistry.Provider {
+	case userconfig.MLflowModelRegistry:
+		return resolveMLflowModel(modelRegistry, awsClient)
+	case userconfig.SageMakerModelRegistry:
+		return resolveSageMakerModel(modelRegistry, awsClient)
+	}
+
+	return "", nil
+}
+
+func resolveMLflowModel(modelRegistry *userconfig.ModelRegistry, awsClient *aws.Client) (string, error) {
+	if modelRegistry.TrackingURI == nil {
+		return "", ErrorFieldRequiredForModelRegistry(userconfig.TrackingURIKey, modelRegistry.Provider)
+	}
+
+	var token string
+	if modelRegistry.TokenSecret != nil {
+		var err error
+		token, err = awsClient.GetSecretValue(*modelRegistry.TokenSecret)
+		if err != nil {
+			return "", errors.Wrap(err, userconfig.TokenSecretKey)
+		}
+	}
+
+	client := mlflow.NewClient(*modelRegistry.TrackingURI, token)
+
+	var modelVersion *mlflow.ModelVersion
+	var err error
+	if modelRegistry.Version != nil {
+		modelVersion, err = client.GetModelVersion(modelRegistry.Name, *modelRegistry.Version)
+	} else {
+		stage := _defaultMLflowStage
+		if modelRegistry.Stage != nil {
+			stage = *modelRegistry.Stage
+		}
+		modelVersion, err = client.GetLatestVersion(modelRegistry.Name, stage)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	artifactURI, err := client.GetDownloadURI(modelRegistry.Name, modelVersion.Version)
+	if err != nil {
+		return "", err
+	}
+	if !aws.IsValidS3Path(artifactURI) {
+		return "", ErrorModelArtifactsNotInS3(artifactURI)
+	}
+
+	modelRegistry.ResolvedVersion = modelVersion.Version
+	return artifactURI, nil
+}
+
+func resolveSageMakerModel(modelRegistry *userconfig.ModelRegistry, awsClient *aws.Client) (string, error) {
+	if modelRegistry.TrackingURI != nil {
+		return "", ErrorFieldNotSupportedForModelRegistry(userconfig.TrackingURIKey, modelRegistry.Provider)
+	}
+	if modelRegistry.TokenSecret != nil {
+		return "", ErrorFieldNotSupportedForModelRegistry(userconfig.TokenSecretKey, modelRegistry.Provider)
+	}
+
+	var version *int64
+	if modelRegistry.Version != nil {
+		parsedVersion, err := strconv.ParseInt(*modelRegistry.Version, 10, 64)
+		if err != nil {
+			return "", errors.Wrap(errors.WithStack(err), userconfig.ModelVersionKey)
+		}
+		version = &parsedVersion
+	}
+
+	approvalStatus := _defaultSageMakerApprovalStatus
+	if modelRegistry.Stage != nil {
+		approvalStatus = *modelRegistry.Stage
+	}
+
+	modelPackage, err := awsClient.GetModelPackage(modelRegistry.Name, version, approvalStatus)
+	if err != nil {
+		return "", err
+	}
+	bucket, key, err := aws.SplitS3Path(modelPackage.ModelDataURL)
+	if err != nil {
+		return "", ErrorModelArtifactsNotInS3(modelPackage.ModelDataURL)
+	}
+
+	// model data is usually an archive (e.g. model.tar.gz), in which case its directory is used as the model's path
+	artifactURI := modelPackage.ModelDataURL
+	if filepath.Ext(key) != "" {
+		artifactURI = aws.S3Path(bucket, filepath.Dir(key))
+	}
+
+	modelRegistry.ResolvedVersion = strconv.FormatInt(modelPackage.Version, 10)
+	return artifactURI, nil
+}
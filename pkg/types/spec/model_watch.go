@@ -0,0 +1,86 @@
+/*
+Copyright 2021 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spec
+
+import (
+	"github.com/cortexlabs/cortex/pkg/lib/aws"
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/types/userconfig"
+)
+
+// HasWatchedModels returns whether the api should be redeployed when its model artifacts change
+func HasWatchedModels(api *userconfig.API) bool {
+	if api.Pod != nil && api.Pod.ModelCache != nil && api.Pod.ModelCache.Watch {
+		return true
+	}
+	if api.Models != nil {
+		for _, modelPath := range api.Models.Paths {
+			if modelPath.Watch {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// WatchedModelsChanged returns whether the artifacts of any of the api's watched models have changed since the api was deployed,
+// i.e. the model registry has a newer version of the model in the configured stage, or the objects at the model's s3 path were modified
+func WatchedModelsChanged(api *userconfig.API, awsClient *aws.Client) (bool, error) {
+	if api.Pod != nil && api.Pod.ModelCache != nil && api.Pod.ModelCache.Watch {
+		modelCache := api.Pod.ModelCache
+		changed, err := watchedModelChanged(modelCache.Path, modelCache.ModelRegistry, modelCache.ArtifactVersion, awsClient)
+		if err != nil {
+			return false, errors.Wrap(err, userconfig.ModelCacheKey)
+		}
+		if changed {
+			return true, nil
+		}
+	}
+
+	if api.Models != nil {
+		for _, modelPath := range api.Models.Paths {
+			if !modelPath.Watch {
+				continue
+			}
+			changed, err := watchedModelChanged(modelPath.Path, modelPath.ModelRegistry, modelPath.ArtifactVersion, awsClient)
+			if err != nil {
+				return false, errors.Wrap(err, userconfig.ModelsKey, modelPath.Name)
+			}
+			if changed {
+				return true, nil
+			}
+		}
+	}
+
+	return false, nil
+}
+
+func watchedModelChanged(path string, modelRegistry *userconfig.ModelRegistry, artifactVersion string, awsClient *aws.Client) (bool, error) {
+	if modelRegistry != nil {
+		current := *modelRegistry
+		if _, err := resolveModelRegistry(&current, awsClient); err != nil {
+			return false, errors.Wrap(err, userconfig.ModelRegistryKey)
+		}
+		return current.ResolvedVersion != modelRegistry.ResolvedVersion, nil
+	}
+
+	fingerprint, err := awsClient.GetS3PathFingerprint(path)
+	if err != nil {
+		return false, errors.Wrap(err, userconfig.PathKey)
+	}
+	return fingerprint != artifactVersion, nil
+}
@@ -0,0 +1,99 @@
+/*
+Copyright 2021 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spec
+
+import (
+	"github.com/cortexlabs/cortex/pkg/lib/aws"
+	"github.com/cortexlabs/cortex/pkg/lib/docker"
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	libjson "github.com/cortexlabs/cortex/pkg/lib/json"
+	"github.com/cortexlabs/cortex/pkg/lib/regex"
+	"github.com/cortexlabs/cortex/pkg/lib/sets/strset"
+	s "github.com/cortexlabs/cortex/pkg/lib/strings"
+	"github.com/cortexlabs/cortex/pkg/types/userconfig"
+	dockertypes "github.com/docker/docker/api/types"
+)
+
+// ValidateRegistryCredentials checks that ECR registries don't reference a secret (since they are accessed with the cluster's AWS credentials),
+// that all other registries do, and that each registry is only listed once
+func ValidateRegistryCredentials(registryCredentials []*userconfig.RegistryCredentials) error {
+	registries := strset.New()
+	for i, credentials := range registryCredentials {
+		registry := docker.NormalizeRegistry(credentials.Registry)
+		if registries.Has(registry) {
+			return errors.Wrap(ErrorDuplicateRegistryCredentials(registry), s.Index(i), userconfig.RegistryKey)
+		}
+		registries.Add(registry)
+
+		if regex.IsValidECRURL(registry) {
+			if credentials.SecretsManager != nil {
+				return errors.Wrap(ErrorRegistryCredentialsSecretNotSupportedForECR(registry), s.Index(i), userconfig.SecretsManagerKey)
+			}
+		} else if credentials.SecretsManager == nil {
+			return errors.Wrap(ErrorRegistryCredentialsSecretRequired(registry), s.Index(i), userconfig.SecretsManagerKey)
+		}
+	}
+	return nil
+}
+
+// FindRegistryCredentials returns the first credentials for the image's registry, or nil if there are none
+func FindRegistryCredentials(image string, registryCredentials []*userconfig.RegistryCredentials) *userconfig.RegistryCredentials {
+	registry := docker.ExtractImageRegistry(image)
+	for _, credentials := range registryCredentials {
+		if docker.NormalizeRegistry(credentials.Registry) == registry {
+			return credentials
+		}
+	}
+	return nil
+}
+
+// RegistryAuth resolves the username and password for the registry; ECR registries (which can be in other accounts or regions)
+// use a token which is retrieved with the AWS credentials, and is valid for 12 hours
+func RegistryAuth(credentials *userconfig.RegistryCredentials, awsClient *aws.Client) (dockertypes.AuthConfig, error) {
+	registry := docker.NormalizeRegistry(credentials.Registry)
+
+	if regex.IsValidECRURL(registry) {
+		ecrAuthConfig, err := awsClient.GetECRAuthConfigForRegistry(registry)
+		if err != nil {
+			return dockertypes.AuthConfig{}, err
+		}
+		return dockertypes.AuthConfig{
+			Username:      ecrAuthConfig.Username,
+			Password:      ecrAuthConfig.AccessToken,
+			ServerAddress: registry,
+		}, nil
+	}
+
+	value, err := awsClient.GetSecretValue(*credentials.SecretsManager)
+	if err != nil {
+		return dockertypes.AuthConfig{}, err
+	}
+
+	var usernamePassword struct {
+		Username string `json:"username"`
+		Password string `json:"password"`
+	}
+	if err := libjson.Unmarshal([]byte(value), &usernamePassword); err != nil || usernamePassword.Username == "" || usernamePassword.Password == "" {
+		return dockertypes.AuthConfig{}, ErrorInvalidRegistryCredentialsSecret(*credentials.SecretsManager)
+	}
+
+	return dockertypes.AuthConfig{
+		Username:      usernamePassword.Username,
+		Password:      usernamePassword.Password,
+		ServerAddress: docker.RegistryAuthAddress(registry),
+	}, nil
+}
@@ -17,6 +17,7 @@ limitations under the License.
 package spec
 
 import (
+	"math"
 	"strings"
 
 	"github.com/cortexlabs/cortex/pkg/lib/errors"
@@ -62,6 +63,24 @@ func surgeOrUnavailableValidator(str string) (string, error) {
 	return str, nil
 }
 
+// disruptedReplicasAt returns the number of replicas which the pod disruption budget allows to be disrupted when the api has the given number of replicas
+// (percentages are rounded up, like kubernetes does for a pod disruption budget's maxUnavailable)
+func disruptedReplicasAt(maxDisruptedReplicas string, replicas int32) (int32, error) {
+	if strings.HasSuffix(maxDisruptedReplicas, "%") {
+		pct, ok := s.ParseInt32(strings.TrimSuffix(maxDisruptedReplicas, "%"))
+		if !ok {
+			return 0, ErrorInvalidSurgeOrUnavailable(maxDisruptedReplicas)
+		}
+		return int32(math.Ceil(float64(pct) * float64(replicas) / 100)), nil
+	}
+
+	count, ok := s.ParseInt32(maxDisruptedReplicas)
+	if !ok {
+		return 0, ErrorInvalidSurgeOrUnavailable(maxDisruptedReplicas)
+	}
+	return count, nil
+}
+
 func verifyTotalWeight(apis []*userconfig.TrafficSplit) error {
 	totalWeight := int32(0)
 	for _, api := range apis {
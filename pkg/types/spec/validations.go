@@ -19,6 +19,9 @@ package spec
 import (
 	"context"
 	"fmt"
+	"math"
+	"net"
+	"strconv"
 	"strings"
 	"time"
 
@@ -39,48 +42,100 @@ import (
 	"github.com/cortexlabs/cortex/pkg/types/userconfig"
 	dockertypes "github.com/docker/docker/api/types"
 	kresource "k8s.io/apimachinery/pkg/api/resource"
+	kvalidation "k8s.io/apimachinery/pkg/util/validation"
 )
 
 var AutoscalingTickInterval = 10 * time.Second
 
 const _dockerPullSecretName = "registry-credentials"
 
+// the files are stored in a config map, which is limited to 1MiB (including its metadata)
+const _maxRuntimeConfigFilesSize = 1000000
+
 func apiValidation(resource userconfig.Resource) *cr.StructValidation {
 	var structFieldValidations []*cr.StructFieldValidation
 
 	switch resource.Kind {
 	case userconfig.RealtimeAPIKind:
 		structFieldValidations = append(resourceStructValidations,
+			projectValidation(),
+			labelsValidation(),
 			podValidation(userconfig.RealtimeAPIKind),
 			nodegroupsValidation(),
-			networkingValidation(),
+			archValidation(),
+			osValidation(resource.Kind),
+			networkingValidation(resource.Kind),
 			autoscalingValidation(resource.Kind),
 			updateStrategyValidation(),
+			availabilityValidation(),
+			alertingValidation(resource.Kind),
+			sloValidation(),
+			payloadLoggingValidation(),
+			proxyValidation(),
+			retriesValidation(),
+			modelsValidation(),
+			loggingValidation(),
+			hooksValidation(),
+			dependsOnValidation(),
 		)
 	case userconfig.AsyncAPIKind:
 		structFieldValidations = append(resourceStructValidations,
+			projectValidation(),
+			labelsValidation(),
 			podValidation(userconfig.AsyncAPIKind),
 			nodegroupsValidation(),
-			networkingValidation(),
+			archValidation(),
+			osValidation(resource.Kind),
+			networkingValidation(resource.Kind),
 			autoscalingValidation(resource.Kind),
 			updateStrategyValidation(),
+			availabilityValidation(),
+			alertingValidation(resource.Kind),
+			gatewayValidation(),
+			expirationValidation(),
+			loggingValidation(),
+			hooksValidation(),
+			dependsOnValidation(),
 		)
 	case userconfig.BatchAPIKind:
 		structFieldValidations = append(resourceStructValidations,
+			projectValidation(),
+			labelsValidation(),
 			podValidation(userconfig.BatchAPIKind),
 			nodegroupsValidation(),
-			networkingValidation(),
+			archValidation(),
+			osValidation(resource.Kind),
+			networkingValidation(resource.Kind),
+			loggingValidation(),
+			dependsOnValidation(),
 		)
 	case userconfig.TaskAPIKind:
 		structFieldValidations = append(resourceStructValidations,
+			projectValidation(),
+			labelsValidation(),
 			podValidation(userconfig.TaskAPIKind),
 			nodegroupsValidation(),
-			networkingValidation(),
+			archValidation(),
+			osValidation(resource.Kind),
+			networkingValidation(resource.Kind),
+			dependsOnValidation(),
 		)
 	case userconfig.TrafficSplitterKind:
 		structFieldValidations = append(resourceStructValidations,
+			projectValidation(),
+			labelsValidation(),
 			multiAPIsValidation(),
-			networkingValidation(),
+			sessionAffinityValidation(),
+			networkingValidation(resource.Kind),
+			dependsOnValidation(),
+		)
+	case userconfig.WorkflowKind:
+		structFieldValidations = append(resourceStructValidations,
+			projectValidation(),
+			labelsValidation(),
+			stepsValidation(),
+			networkingValidation(resource.Kind),
+			dependsOnValidation(),
 		)
 	}
 	return &cr.StructValidation{
@@ -143,6 +198,85 @@ func multiAPIsValidation() *cr.StructFieldValidation {
 	}
 }
 
+func sessionAffinityValidation() *cr.StructFieldValidation {
+	return &cr.StructFieldValidation{
+		StructField: "SessionAffinity",
+		StructValidation: &cr.StructValidation{
+			DefaultNil:        true,
+			AllowExplicitNull: true,
+			StructFieldValidations: []*cr.StructFieldValidation{
+				{
+					StructField: "Cookie",
+					StringPtrValidation: &cr.StringPtrValidation{
+						AllowExplicitNull:          true,
+						AlphaNumericDashUnderscore: true,
+					},
+				},
+				{
+					StructField: "Header",
+					StringPtrValidation: &cr.StringPtrValidation{
+						AllowExplicitNull:          true,
+						AlphaNumericDashUnderscore: true,
+					},
+				},
+				{
+					StructField: "TTL",
+					StringValidation: &cr.StringValidation{
+						Default: "24h",
+					},
+					Parser: cr.DurationParser(&cr.DurationValidation{
+						GreaterThan: pointer.Duration(0),
+					}),
+				},
+			},
+		},
+	}
+}
+
+func stepsValidation() *cr.StructFieldValidation {
+	return &cr.StructFieldValidation{
+		StructField: "Steps",
+		StructListValidation: &cr.StructListValidation{
+			Required:         true,
+			TreatNullAsEmpty: true,
+			MinLength:        1,
+			StructValidation: &cr.StructValidation{
+				StructFieldValidations: []*cr.StructFieldValidation{
+					{
+						StructField: "Name",
+						StringValidation: &cr.StringValidation{
+							Required: true,
+							DNS1035:  true,
+						},
+					},
+					{
+						StructField: "API",
+						StringValidation: &cr.StringValidation{
+							Required: true,
+						},
+					},
+					{
+						StructField: "DependsOn",
+						StringListValidation: &cr.StringListValidation{
+							Default:      []string{},
+							AllowEmpty:   true,
+							DisallowDups: true,
+						},
+					},
+					{
+						StructField: "Config",
+						InterfaceMapValidation: &cr.InterfaceMapValidation{
+							StringKeysOnly:    true,
+							AllowEmpty:        true,
+							AllowExplicitNull: true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
 func podValidation(kind userconfig.Kind) *cr.StructFieldValidation {
 	validation := &cr.StructFieldValidation{
 		StructField: "Pod",
@@ -159,7 +293,65 @@ func podValidation(kind userconfig.Kind) *cr.StructFieldValidation {
 						DisallowedValues:  consts.ReservedContainerPorts,
 					},
 				},
+				initContainersValidation(),
 				containersValidation(kind),
+				secretsValidation(),
+				registryCredentialsValidation(),
+				runtimeConfigValidation(),
+				{
+					StructField: "ModelCache",
+					StructValidation: &cr.StructValidation{
+						Required:          false,
+						DefaultNil:        true,
+						AllowExplicitNull: true,
+						StructFieldValidations: []*cr.StructFieldValidation{
+							{
+								StructField: "Path",
+								StringValidation: &cr.StringValidation{
+									AllowEmpty: true, // if model_registry is specified
+									Validator:  optionalS3PathValidator,
+								},
+							},
+							modelRegistryValidation(),
+							{
+								StructField:    "Watch",
+								BoolValidation: &cr.BoolValidation{},
+							},
+							integrityValidation(),
+						},
+					},
+				},
+				{
+					StructField: "EFS",
+					StructValidation: &cr.StructValidation{
+						Required:          false,
+						DefaultNil:        true,
+						AllowExplicitNull: true,
+						StructFieldValidations: []*cr.StructFieldValidation{
+							{
+								StructField: "Path",
+								StringValidation: &cr.StringValidation{
+									Default: "/",
+									Prefix:  "/",
+								},
+							},
+							{
+								StructField: "MountPath",
+								StringValidation: &cr.StringValidation{
+									Default:          "/efs",
+									Prefix:           "/",
+									DisallowedValues: []string{"/", "/mnt", "/cortex", "/dev/shm"},
+								},
+							},
+							{
+								StructField: "ReadOnly",
+								BoolValidation: &cr.BoolValidation{
+									Default: false,
+								},
+							},
+						},
+					},
+				},
 			},
 		},
 	}
@@ -192,6 +384,207 @@ func podValidation(kind userconfig.Kind) *cr.StructFieldValidation {
 	return validation
 }
 
+func runtimeConfigValidation() *cr.StructFieldValidation {
+	return &cr.StructFieldValidation{
+		StructField: "Config",
+		StructValidation: &cr.StructValidation{
+			Required:          false,
+			DefaultNil:        true,
+			AllowExplicitNull: true,
+			StructFieldValidations: []*cr.StructFieldValidation{
+				{
+					StructField: "Files",
+					StringMapValidation: &cr.StringMapValidation{
+						Required:   false,
+						Default:    map[string]string{},
+						AllowEmpty: true,
+						KeyStringValidator: &cr.StringValidation{
+							AlphaNumericDashDotUnderscore: true,
+						},
+					},
+				},
+				{
+					StructField: "Env",
+					StringMapValidation: &cr.StringMapValidation{
+						Required:   false,
+						Default:    map[string]string{},
+						AllowEmpty: true,
+						KeyStringValidator: &cr.StringValidation{
+							AlphaNumericDotUnderscore: true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func registryCredentialsValidation() *cr.StructFieldValidation {
+	return &cr.StructFieldValidation{
+		StructField: "RegistryCredentials",
+		StructListValidation: &cr.StructListValidation{
+			Required:          false,
+			TreatNullAsEmpty:  true,
+			AllowExplicitNull: true,
+			StructValidation: &cr.StructValidation{
+				StructFieldValidations: []*cr.StructFieldValidation{
+					{
+						StructField: "Registry",
+						StringValidation: &cr.StringValidation{
+							Required:   true,
+							AllowEmpty: false,
+						},
+					},
+					{
+						StructField: "SecretsManager",
+						StringPtrValidation: &cr.StringPtrValidation{
+							Required:          false,
+							AllowExplicitNull: true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func secretsValidation() *cr.StructFieldValidation {
+	return &cr.StructFieldValidation{
+		StructField: "Secrets",
+		StructListValidation: &cr.StructListValidation{
+			Required:          false,
+			TreatNullAsEmpty:  true,
+			AllowExplicitNull: true,
+			StructValidation: &cr.StructValidation{
+				StructFieldValidations: []*cr.StructFieldValidation{
+					{
+						StructField: "Name",
+						StringValidation: &cr.StringValidation{
+							Required:                  true,
+							AllowEmpty:                false,
+							AlphaNumericDotUnderscore: true,
+						},
+					},
+					{
+						StructField: "SecretsManager",
+						StringPtrValidation: &cr.StringPtrValidation{
+							Required:          false,
+							AllowExplicitNull: true,
+						},
+					},
+					{
+						StructField: "SSMParameter",
+						StringPtrValidation: &cr.StringPtrValidation{
+							Required:          false,
+							AllowExplicitNull: true,
+						},
+					},
+					{
+						StructField: "Key",
+						StringPtrValidation: &cr.StringPtrValidation{
+							Required:          false,
+							AllowExplicitNull: true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func initContainersValidation() *cr.StructFieldValidation {
+	return &cr.StructFieldValidation{
+		StructField: "InitContainers",
+		StructListValidation: &cr.StructListValidation{
+			Required:          false,
+			TreatNullAsEmpty:  true,
+			AllowExplicitNull: true,
+			StructValidation: &cr.StructValidation{
+				StructFieldValidations: []*cr.StructFieldValidation{
+					{
+						StructField: "Name",
+						StringValidation: &cr.StringValidation{
+							Required:         true,
+							AllowEmpty:       false,
+							DNS1035:          true,
+							MaxLength:        63,
+							DisallowedValues: consts.ReservedContainerNames,
+						},
+					},
+					{
+						StructField: "Image",
+						StringValidation: &cr.StringValidation{
+							Required:    true,
+							AllowEmpty:  false,
+							DockerImage: true,
+						},
+					},
+					{
+						StructField: "ImageDigest",
+						StringPtrValidation: &cr.StringPtrValidation{
+							Required:          false,
+							AllowExplicitNull: true,
+							Validator:         validateImageDigest,
+						},
+					},
+					{
+						StructField: "Env",
+						StringMapValidation: &cr.StringMapValidation{
+							Required:   false,
+							Default:    map[string]string{},
+							AllowEmpty: true,
+						},
+					},
+					{
+						StructField: "Command",
+						StringListValidation: &cr.StringListValidation{
+							Required:          false,
+							AllowExplicitNull: true,
+							AllowEmpty:        true,
+						},
+					},
+					{
+						StructField: "Args",
+						StringListValidation: &cr.StringListValidation{
+							Required:          false,
+							AllowExplicitNull: true,
+							AllowEmpty:        true,
+						},
+					},
+					{
+						StructField: "Compute",
+						StructValidation: &cr.StructValidation{
+							StructFieldValidations: []*cr.StructFieldValidation{
+								{
+									StructField: "CPU",
+									StringPtrValidation: &cr.StringPtrValidation{
+										Default:           nil,
+										AllowExplicitNull: true,
+										CastNumeric:       true,
+									},
+									Parser: k8s.QuantityParser(&k8s.QuantityValidation{
+										GreaterThanOrEqualTo: k8s.QuantityPtr(kresource.MustParse("20m")),
+									}),
+								},
+								{
+									StructField: "Mem",
+									StringPtrValidation: &cr.StringPtrValidation{
+										Default:           nil,
+										AllowExplicitNull: true,
+									},
+									Parser: k8s.QuantityParser(&k8s.QuantityValidation{
+										GreaterThanOrEqualTo: k8s.QuantityPtr(kresource.MustParse("20Mi")),
+									}),
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
 func containersValidation(kind userconfig.Kind) *cr.StructFieldValidation {
 	validations := []*cr.StructFieldValidation{
 		{
@@ -212,6 +605,14 @@ func containersValidation(kind userconfig.Kind) *cr.StructFieldValidation {
 				DockerImage: true,
 			},
 		},
+		{
+			StructField: "ImageDigest",
+			StringPtrValidation: &cr.StringPtrValidation{
+				Required:          false,
+				AllowExplicitNull: true,
+				Validator:         validateImageDigest,
+			},
+		},
 		{
 			StructField: "Env",
 			StringMapValidation: &cr.StringMapValidation{
@@ -237,7 +638,9 @@ func containersValidation(kind userconfig.Kind) *cr.StructFieldValidation {
 			},
 		},
 		computeValidation(),
+		verticalAutoscalingValidation(),
 		probeValidation("LivenessProbe", true),
+		probeValidation("StartupProbe", true),
 	}
 
 	if kind == userconfig.RealtimeAPIKind {
@@ -259,6 +662,55 @@ func containersValidation(kind userconfig.Kind) *cr.StructFieldValidation {
 	}
 }
 
+func projectValidation() *cr.StructFieldValidation {
+	return &cr.StructFieldValidation{
+		StructField: "Project",
+		StringPtrValidation: &cr.StringPtrValidation{
+			Required:          false,
+			Default:           nil,
+			AllowExplicitNull: true,
+			DNS1035:           true,
+			MaxLength:         48, // the project's service account is named cortex-project-<project>, and label values are limited to 63 characters
+		},
+	}
+}
+
+func labelsValidation() *cr.StructFieldValidation {
+	return &cr.StructFieldValidation{
+		StructField: "Labels",
+		StringMapValidation: &cr.StringMapValidation{
+			Required:          false,
+			Default:           nil,
+			AllowExplicitNull: true,
+			AllowEmpty:        true,
+			KeyStringValidator: &cr.StringValidation{
+				DNS1123:   true,
+				MaxLength: 63,
+			},
+			ValueStringValidator: &cr.StringValidation{
+				AllowEmpty: true,
+				Validator:  validateLabelValue,
+			},
+		},
+	}
+}
+
+func dependsOnValidation() *cr.StructFieldValidation {
+	return &cr.StructFieldValidation{
+		StructField: "DependsOn",
+		StringListValidation: &cr.StringListValidation{
+			Required:          false,
+			Default:           nil,
+			AllowExplicitNull: true,
+			AllowEmpty:        true,
+			DisallowDups:      true,
+			ElementStringValidation: &cr.StringValidation{
+				DNS1035: true,
+			},
+		},
+	}
+}
+
 func nodegroupsValidation() *cr.StructFieldValidation {
 	return &cr.StructFieldValidation{
 		StructField: "NodeGroups",
@@ -274,59 +726,181 @@ func nodegroupsValidation() *cr.StructFieldValidation {
 	}
 }
 
-func networkingValidation() *cr.StructFieldValidation {
+func archValidation() *cr.StructFieldValidation {
 	return &cr.StructFieldValidation{
-		StructField: "Networking",
-		StructValidation: &cr.StructValidation{
-			StructFieldValidations: []*cr.StructFieldValidation{
-				{
-					StructField: "Endpoint",
-					StringPtrValidation: &cr.StringPtrValidation{
-						Validator: urls.ValidateEndpoint,
-						MaxLength: 1000, // no particular reason other than it works
-					},
-				},
-			},
+		StructField: "Arch",
+		StringValidation: &cr.StringValidation{
+			Default:       aws.ArchAMD64,
+			AllowedValues: []string{aws.ArchAMD64, aws.ArchARM64},
 		},
 	}
 }
 
-func probeValidation(structFieldName string, hasExecProbe bool) *cr.StructFieldValidation {
-	validations := []*cr.StructFieldValidation{
-		httpGetProbeValidation(),
-		tcpSocketProbeValidation(),
-		{
-			StructField: "InitialDelaySeconds",
-			Int32Validation: &cr.Int32Validation{
-				Default:              0,
-				GreaterThanOrEqualTo: pointer.Int32(0),
-			},
+// only realtime apis can run on windows node groups, since the sidecars of the other kinds (e.g. the dequeuer) are only built for linux
+func osValidation(kind userconfig.Kind) *cr.StructFieldValidation {
+	allowedValues := []string{aws.OSLinux}
+	if kind == userconfig.RealtimeAPIKind {
+		allowedValues = append(allowedValues, aws.OSWindows)
+	}
+
+	return &cr.StructFieldValidation{
+		StructField: "OS",
+		StringValidation: &cr.StringValidation{
+			Default:       aws.OSLinux,
+			AllowedValues: allowedValues,
 		},
+	}
+}
+
+func networkingValidation(kind userconfig.Kind) *cr.StructFieldValidation {
+	structFieldValidations := []*cr.StructFieldValidation{
 		{
-			StructField: "TimeoutSeconds",
-			Int32Validation: &cr.Int32Validation{
-				Default:              1,
-				GreaterThanOrEqualTo: pointer.Int32(0),
+			StructField: "Endpoint",
+			StringPtrValidation: &cr.StringPtrValidation{
+				Validator: urls.ValidateEndpoint,
+				MaxLength: 1000, // no particular reason other than it works
 			},
 		},
 		{
-			StructField: "PeriodSeconds",
-			Int32Validation: &cr.Int32Validation{
-				Default:              10,
-				GreaterThanOrEqualTo: pointer.Int32(0),
+			StructField: "CIDRWhiteList",
+			StringListValidation: &cr.StringListValidation{
+				AllowEmpty:   true,
+				DisallowDups: true,
+				Validator: func(cidrs []string) ([]string, error) {
+					for i, cidr := range cidrs {
+						if _, _, err := net.ParseCIDR(cidr); err != nil {
+							return nil, errors.Wrap(errors.WithStack(err), fmt.Sprintf("index %d", i))
+						}
+					}
+					return cidrs, nil
+				},
 			},
 		},
 		{
-			StructField: "SuccessThreshold",
-			Int32Validation: &cr.Int32Validation{
-				Default:              1,
-				GreaterThanOrEqualTo: pointer.Int32(0),
+			StructField: "AccessLogs",
+			StructValidation: &cr.StructValidation{
+				DefaultNil:        true,
+				AllowExplicitNull: true,
+				StructFieldValidations: []*cr.StructFieldValidation{
+					{
+						StructField: "SampleRate",
+						Float64Validation: &cr.Float64Validation{
+							Default:           1,
+							GreaterThan:       pointer.Float64(0),
+							LessThanOrEqualTo: pointer.Float64(1),
+						},
+					},
+				},
 			},
 		},
-		{
-			StructField: "FailureThreshold",
-			Int32Validation: &cr.Int32Validation{
-				Default:              3,
+	}
+
+	// traffic splitters and workflows don't have pods
+	if kind != userconfig.TrafficSplitterKind && kind != userconfig.WorkflowKind {
+		structFieldValidations = append(structFieldValidations,
+			networkPolicyRulesValidation("Ingress", false),
+			networkPolicyRulesValidation("Egress", true),
+		)
+	}
+
+	return &cr.StructFieldValidation{
+		StructField: "Networking",
+		StructValidation: &cr.StructValidation{
+			StructFieldValidations: structFieldValidations,
+		},
+	}
+}
+
+func networkPolicyRulesValidation(structFieldName string, allowHosts bool) *cr.StructFieldValidation {
+	structFieldValidations := []*cr.StructFieldValidation{
+		{
+			StructField: "CIDRs",
+			StringListValidation: &cr.StringListValidation{
+				AllowEmpty:   true,
+				DisallowDups: true,
+				Validator: func(cidrs []string) ([]string, error) {
+					for i, cidr := range cidrs {
+						if _, _, err := net.ParseCIDR(cidr); err != nil {
+							return nil, errors.Wrap(errors.WithStack(err), fmt.Sprintf("index %d", i))
+						}
+					}
+					return cidrs, nil
+				},
+			},
+		},
+		{
+			StructField: "APIs",
+			StringListValidation: &cr.StringListValidation{
+				AllowEmpty:   true,
+				DisallowDups: true,
+			},
+		},
+	}
+
+	if allowHosts {
+		structFieldValidations = append(structFieldValidations, &cr.StructFieldValidation{
+			StructField: "Hosts",
+			StringListValidation: &cr.StringListValidation{
+				AllowEmpty:   true,
+				DisallowDups: true,
+				Validator: func(hosts []string) ([]string, error) {
+					for i, host := range hosts {
+						if err := validateHost(host); err != nil {
+							return nil, errors.Wrap(err, fmt.Sprintf("index %d", i))
+						}
+					}
+					return hosts, nil
+				},
+			},
+		})
+	}
+
+	return &cr.StructFieldValidation{
+		StructField: structFieldName,
+		StructValidation: &cr.StructValidation{
+			DefaultNil:             true,
+			AllowExplicitNull:      true,
+			StructFieldValidations: structFieldValidations,
+		},
+	}
+}
+
+func probeValidation(structFieldName string, hasExecProbe bool) *cr.StructFieldValidation {
+	validations := []*cr.StructFieldValidation{
+		httpGetProbeValidation(),
+		tcpSocketProbeValidation(),
+		{
+			StructField: "InitialDelaySeconds",
+			Int32Validation: &cr.Int32Validation{
+				Default:              0,
+				GreaterThanOrEqualTo: pointer.Int32(0),
+			},
+		},
+		{
+			StructField: "TimeoutSeconds",
+			Int32Validation: &cr.Int32Validation{
+				Default:              1,
+				GreaterThanOrEqualTo: pointer.Int32(0),
+			},
+		},
+		{
+			StructField: "PeriodSeconds",
+			Int32Validation: &cr.Int32Validation{
+				Default:              10,
+				GreaterThanOrEqualTo: pointer.Int32(0),
+			},
+		},
+		{
+			StructField: "SuccessThreshold",
+			Int32Validation: &cr.Int32Validation{
+				Default:              1,
+				GreaterThanOrEqualTo: pointer.Int32(0),
+			},
+		},
+		{
+			StructField: "FailureThreshold",
+			Int32Validation: &cr.Int32Validation{
+				Default:              3,
 				GreaterThanOrEqualTo: pointer.Int32(0),
 			},
 		},
@@ -446,9 +1020,10 @@ func computeValidation() *cr.StructFieldValidation {
 				},
 				{
 					StructField: "GPU",
-					Int64Validation: &cr.Int64Validation{
+					Float64Validation: &cr.Float64Validation{
 						Default:              0,
-						GreaterThanOrEqualTo: pointer.Int64(0),
+						GreaterThanOrEqualTo: pointer.Float64(0),
+						Validator:            validateGPU,
 					},
 				},
 				{
@@ -471,127 +1046,943 @@ func computeValidation() *cr.StructFieldValidation {
 	}
 }
 
+func verticalAutoscalingValidation() *cr.StructFieldValidation {
+	cpuValidation := func(structField string) *cr.StructFieldValidation {
+		return &cr.StructFieldValidation{
+			StructField: structField,
+			StringPtrValidation: &cr.StringPtrValidation{
+				Required:    true,
+				CastNumeric: true,
+			},
+			Parser: k8s.QuantityParser(&k8s.QuantityValidation{
+				GreaterThanOrEqualTo: k8s.QuantityPtr(kresource.MustParse("20m")),
+			}),
+		}
+	}
+	memValidation := func(structField string) *cr.StructFieldValidation {
+		return &cr.StructFieldValidation{
+			StructField: structField,
+			StringPtrValidation: &cr.StringPtrValidation{
+				Required: true,
+			},
+			Parser: k8s.QuantityParser(&k8s.QuantityValidation{
+				GreaterThanOrEqualTo: k8s.QuantityPtr(kresource.MustParse("20Mi")),
+			}),
+		}
+	}
+
+	return &cr.StructFieldValidation{
+		StructField: "VerticalAutoscaling",
+		StructValidation: &cr.StructValidation{
+			DefaultNil:        true,
+			AllowExplicitNull: true,
+			StructFieldValidations: []*cr.StructFieldValidation{
+				cpuValidation("MinCPU"),
+				cpuValidation("MaxCPU"),
+				memValidation("MinMem"),
+				memValidation("MaxMem"),
+			},
+		},
+	}
+}
+
 func autoscalingValidation(kind userconfig.Kind) *cr.StructFieldValidation {
 	minReplicas := int32(1)
 	if kind == userconfig.AsyncAPIKind {
 		minReplicas = int32(0)
 	}
 
+	structFieldValidations := []*cr.StructFieldValidation{
+		{
+			StructField: "MinReplicas",
+			Int32Validation: &cr.Int32Validation{
+				Default:              1,
+				GreaterThanOrEqualTo: pointer.Int32(minReplicas),
+			},
+		},
+		{
+			StructField: "MaxReplicas",
+			Int32Validation: &cr.Int32Validation{
+				Default:     100,
+				GreaterThan: pointer.Int32(0),
+			},
+		},
+		{
+			StructField:  "InitReplicas",
+			DefaultField: "MinReplicas",
+			Int32Validation: &cr.Int32Validation{
+				GreaterThanOrEqualTo: pointer.Int32(minReplicas),
+			},
+		},
+		{
+			StructField: "TargetInFlight",
+			Float64PtrValidation: &cr.Float64PtrValidation{
+				Default:     nil,
+				GreaterThan: pointer.Float64(0),
+			},
+		},
+		{
+			StructField: "Window",
+			StringValidation: &cr.StringValidation{
+				Default: "60s",
+			},
+			Parser: cr.DurationParser(&cr.DurationValidation{
+				GreaterThanOrEqualTo: &AutoscalingTickInterval,
+				MultipleOf:           &AutoscalingTickInterval,
+			}),
+		},
+		{
+			StructField: "DownscaleStabilizationPeriod",
+			StringValidation: &cr.StringValidation{
+				Default: "5m",
+			},
+			Parser: cr.DurationParser(&cr.DurationValidation{
+				GreaterThanOrEqualTo: pointer.Duration(libtime.MustParseDuration("0s")),
+			}),
+		},
+		{
+			StructField: "UpscaleStabilizationPeriod",
+			StringValidation: &cr.StringValidation{
+				Default: "1m",
+			},
+			Parser: cr.DurationParser(&cr.DurationValidation{
+				GreaterThanOrEqualTo: pointer.Duration(libtime.MustParseDuration("0s")),
+			}),
+		},
+		{
+			StructField: "MaxDownscaleFactor",
+			Float64Validation: &cr.Float64Validation{
+				Default:              0.75,
+				GreaterThanOrEqualTo: pointer.Float64(0),
+				LessThan:             pointer.Float64(1),
+			},
+		},
+		{
+			StructField: "MaxUpscaleFactor",
+			Float64Validation: &cr.Float64Validation{
+				Default:     1.5,
+				GreaterThan: pointer.Float64(1),
+			},
+		},
+		{
+			StructField: "DownscaleTolerance",
+			Float64Validation: &cr.Float64Validation{
+				Default:              0.05,
+				GreaterThanOrEqualTo: pointer.Float64(0),
+				LessThan:             pointer.Float64(1),
+			},
+		},
+		{
+			StructField: "UpscaleTolerance",
+			Float64Validation: &cr.Float64Validation{
+				Default:              0.05,
+				GreaterThanOrEqualTo: pointer.Float64(0),
+			},
+		},
+	}
+
+	if kind == userconfig.RealtimeAPIKind {
+		structFieldValidations = append(structFieldValidations,
+			&cr.StructFieldValidation{
+				StructField: "WarmReplicas",
+				Int32Validation: &cr.Int32Validation{
+					Default:              0,
+					GreaterThanOrEqualTo: pointer.Int32(0),
+				},
+			},
+		)
+	}
+
+	if kind == userconfig.AsyncAPIKind {
+		structFieldValidations = append(structFieldValidations,
+			&cr.StructFieldValidation{
+				StructField: "TargetQueueLengthPerReplica",
+				Float64PtrValidation: &cr.Float64PtrValidation{
+					Default:           nil,
+					AllowExplicitNull: true,
+					GreaterThan:       pointer.Float64(0),
+				},
+			},
+			&cr.StructFieldValidation{
+				StructField: "MaxQueueLatency",
+				StringPtrValidation: &cr.StringPtrValidation{
+					Default:           nil,
+					AllowExplicitNull: true,
+				},
+				Parser: cr.DurationParser(&cr.DurationValidation{
+					GreaterThanOrEqualTo: &AutoscalingTickInterval,
+				}),
+			},
+		)
+	}
+
 	return &cr.StructFieldValidation{
 		StructField: "Autoscaling",
+		StructValidation: &cr.StructValidation{
+			StructFieldValidations: structFieldValidations,
+		},
+	}
+}
+
+func updateStrategyValidation() *cr.StructFieldValidation {
+	return &cr.StructFieldValidation{
+		StructField: "UpdateStrategy",
 		StructValidation: &cr.StructValidation{
 			StructFieldValidations: []*cr.StructFieldValidation{
 				{
-					StructField: "MinReplicas",
-					Int32Validation: &cr.Int32Validation{
-						Default:              1,
-						GreaterThanOrEqualTo: pointer.Int32(minReplicas),
-					},
-				},
-				{
-					StructField: "MaxReplicas",
-					Int32Validation: &cr.Int32Validation{
-						Default:     100,
-						GreaterThan: pointer.Int32(0),
+					StructField: "MaxSurge",
+					StringValidation: &cr.StringValidation{
+						Default:   "25%",
+						CastInt:   true,
+						Validator: surgeOrUnavailableValidator,
 					},
 				},
 				{
-					StructField:  "InitReplicas",
-					DefaultField: "MinReplicas",
-					Int32Validation: &cr.Int32Validation{
-						GreaterThanOrEqualTo: pointer.Int32(minReplicas),
+					StructField: "MaxUnavailable",
+					StringValidation: &cr.StringValidation{
+						Default:   "25%",
+						CastInt:   true,
+						Validator: surgeOrUnavailableValidator,
 					},
 				},
+			},
+		},
+	}
+}
+
+func availabilityValidation() *cr.StructFieldValidation {
+	return &cr.StructFieldValidation{
+		StructField: "Availability",
+		StructValidation: &cr.StructValidation{
+			StructFieldValidations: []*cr.StructFieldValidation{
 				{
-					StructField: "TargetInFlight",
-					Float64PtrValidation: &cr.Float64PtrValidation{
-						Default:     nil,
-						GreaterThan: pointer.Float64(0),
+					StructField: "MaxDisruptedReplicas",
+					StringPtrValidation: &cr.StringPtrValidation{
+						Default:           nil,
+						AllowExplicitNull: true,
+						CastInt:           true,
+						Validator:         surgeOrUnavailableValidator,
 					},
 				},
 				{
-					StructField: "Window",
+					StructField: "ZoneSpread",
 					StringValidation: &cr.StringValidation{
-						Default: "60s",
+						AllowedValues: userconfig.TopologySpreadStrings(),
+						Default:       userconfig.PreferredTopologySpread.String(),
+					},
+					Parser: func(str string) (interface{}, error) {
+						return userconfig.TopologySpreadFromString(str), nil
 					},
-					Parser: cr.DurationParser(&cr.DurationValidation{
-						GreaterThanOrEqualTo: &AutoscalingTickInterval,
-						MultipleOf:           &AutoscalingTickInterval,
-					}),
 				},
 				{
-					StructField: "DownscaleStabilizationPeriod",
+					StructField: "NodeSpread",
 					StringValidation: &cr.StringValidation{
-						Default: "5m",
+						AllowedValues: userconfig.TopologySpreadStrings(),
+						Default:       userconfig.NoneTopologySpread.String(),
+					},
+					Parser: func(str string) (interface{}, error) {
+						return userconfig.TopologySpreadFromString(str), nil
 					},
-					Parser: cr.DurationParser(&cr.DurationValidation{
-						GreaterThanOrEqualTo: pointer.Duration(libtime.MustParseDuration("0s")),
-					}),
 				},
+			},
+		},
+	}
+}
+
+func alertingValidation(kind userconfig.Kind) *cr.StructFieldValidation {
+	structFieldValidations := []*cr.StructFieldValidation{
+		{
+			StructField: "Disabled",
+			BoolValidation: &cr.BoolValidation{
+				Default: false,
+			},
+		},
+		{
+			StructField: "Receiver",
+			StringPtrValidation: &cr.StringPtrValidation{
+				Default:           nil,
+				AllowExplicitNull: true,
+			},
+		},
+		{
+			StructField: "ErrorRate",
+			Float64PtrValidation: &cr.Float64PtrValidation{
+				Default:           nil,
+				AllowExplicitNull: true,
+				GreaterThan:       pointer.Float64(0),
+				LessThanOrEqualTo: pointer.Float64(1),
+			},
+		},
+		{
+			StructField: "PendingReplicasPeriod",
+			StringPtrValidation: &cr.StringPtrValidation{
+				Default:           nil,
+				AllowExplicitNull: true,
+			},
+			Parser: cr.DurationParser(&cr.DurationValidation{
+				GreaterThanOrEqualTo: pointer.Duration(libtime.MustParseDuration("1m")),
+			}),
+		},
+	}
+
+	if kind == userconfig.AsyncAPIKind {
+		structFieldValidations = append(structFieldValidations, &cr.StructFieldValidation{
+			StructField: "QueueAge",
+			StringPtrValidation: &cr.StringPtrValidation{
+				Default:           nil,
+				AllowExplicitNull: true,
+			},
+			Parser: cr.DurationParser(&cr.DurationValidation{
+				GreaterThanOrEqualTo: pointer.Duration(libtime.MustParseDuration("1m")),
+			}),
+		})
+	}
+
+	return &cr.StructFieldValidation{
+		StructField: "Alerting",
+		StructValidation: &cr.StructValidation{
+			DefaultNil:             true,
+			AllowExplicitNull:      true,
+			StructFieldValidations: structFieldValidations,
+		},
+	}
+}
+
+func payloadLoggingValidation() *cr.StructFieldValidation {
+	return &cr.StructFieldValidation{
+		StructField: "PayloadLogging",
+		StructValidation: &cr.StructValidation{
+			DefaultNil:        true,
+			AllowExplicitNull: true,
+			StructFieldValidations: []*cr.StructFieldValidation{
 				{
-					StructField: "UpscaleStabilizationPeriod",
+					StructField: "S3Path",
 					StringValidation: &cr.StringValidation{
-						Default: "1m",
+						Required:  true,
+						Validator: cr.S3PathValidator,
 					},
-					Parser: cr.DurationParser(&cr.DurationValidation{
-						GreaterThanOrEqualTo: pointer.Duration(libtime.MustParseDuration("0s")),
-					}),
 				},
 				{
-					StructField: "MaxDownscaleFactor",
+					StructField: "SampleRate",
 					Float64Validation: &cr.Float64Validation{
-						Default:              0.75,
-						GreaterThanOrEqualTo: pointer.Float64(0),
-						LessThan:             pointer.Float64(1),
+						Default:           0.01,
+						GreaterThan:       pointer.Float64(0),
+						LessThanOrEqualTo: pointer.Float64(1),
 					},
 				},
 				{
-					StructField: "MaxUpscaleFactor",
-					Float64Validation: &cr.Float64Validation{
-						Default:     1.5,
-						GreaterThan: pointer.Float64(1),
+					StructField: "MaxBodySize",
+					Int64Validation: &cr.Int64Validation{
+						Default:              65536,
+						GreaterThanOrEqualTo: pointer.Int64(0),
+						LessThanOrEqualTo:    pointer.Int64(1048576),
 					},
 				},
 				{
-					StructField: "DownscaleTolerance",
-					Float64Validation: &cr.Float64Validation{
-						Default:              0.05,
-						GreaterThanOrEqualTo: pointer.Float64(0),
-						LessThan:             pointer.Float64(1),
+					StructField: "FlushInterval",
+					StringValidation: &cr.StringValidation{
+						Default: "60s",
 					},
+					Parser: cr.DurationParser(&cr.DurationValidation{
+						GreaterThanOrEqualTo: pointer.Duration(libtime.MustParseDuration("10s")),
+						LessThanOrEqualTo:    pointer.Duration(libtime.MustParseDuration("1h")),
+					}),
 				},
-				{
-					StructField: "UpscaleTolerance",
-					Float64Validation: &cr.Float64Validation{
-						Default:              0.05,
-						GreaterThanOrEqualTo: pointer.Float64(0),
+			},
+		},
+	}
+}
+
+func proxyValidation() *cr.StructFieldValidation {
+	return &cr.StructFieldValidation{
+		StructField: "Proxy",
+		StructValidation: &cr.StructValidation{
+			DefaultNil:        true,
+			AllowExplicitNull: true,
+			StructFieldValidations: []*cr.StructFieldValidation{
+				{
+					StructField: "UpstreamTimeout",
+					StringPtrValidation: &cr.StringPtrValidation{
+						Default:           nil,
+						AllowExplicitNull: true,
 					},
+					Parser: cr.DurationParser(&cr.DurationValidation{
+						GreaterThanOrEqualTo: pointer.Duration(libtime.MustParseDuration("1s")),
+					}),
 				},
+				{
+					StructField: "IdleTimeout",
+					StringPtrValidation: &cr.StringPtrValidation{
+						Default:           nil,
+						AllowExplicitNull: true,
+					},
+					Parser: cr.DurationParser(&cr.DurationValidation{
+						GreaterThanOrEqualTo: pointer.Duration(libtime.MustParseDuration("1s")),
+					}),
+				},
+				{
+					StructField: "MaxRequestBodySize",
+					Int64PtrValidation: &cr.Int64PtrValidation{
+						Default:           nil,
+						AllowExplicitNull: true,
+						GreaterThan:       pointer.Int64(0),
+					},
+				},
+				{
+					StructField: "MaxConcurrentStreams",
+					Int32PtrValidation: &cr.Int32PtrValidation{
+						Default:              nil,
+						AllowExplicitNull:    true,
+						GreaterThanOrEqualTo: pointer.Int32(1),
+						LessThanOrEqualTo:    pointer.Int32(10000),
+					},
+				},
+				fairQueuingValidation(),
 			},
 		},
 	}
 }
 
-func updateStrategyValidation() *cr.StructFieldValidation {
+func fairQueuingValidation() *cr.StructFieldValidation {
+	return &cr.StructFieldValidation{
+		StructField: "FairQueuing",
+		StructValidation: &cr.StructValidation{
+			DefaultNil:        true,
+			AllowExplicitNull: true,
+			StructFieldValidations: []*cr.StructFieldValidation{
+				{
+					StructField: "Header",
+					StringValidation: &cr.StringValidation{
+						Default:                    "X-Api-Key",
+						AllowEmpty:                 false,
+						AlphaNumericDashUnderscore: true,
+					},
+				},
+				{
+					StructField: "DefaultWeight",
+					Int32Validation: &cr.Int32Validation{
+						Default:              1,
+						GreaterThanOrEqualTo: pointer.Int32(1),
+						LessThanOrEqualTo:    pointer.Int32(100),
+					},
+				},
+				{
+					StructField: "Clients",
+					StructListValidation: &cr.StructListValidation{
+						AllowExplicitNull: true,
+						TreatNullAsEmpty:  true,
+						StructValidation: &cr.StructValidation{
+							StructFieldValidations: []*cr.StructFieldValidation{
+								{
+									StructField: "Client",
+									StringValidation: &cr.StringValidation{
+										Required:   true,
+										AllowEmpty: false,
+									},
+								},
+								{
+									StructField: "Weight",
+									Int32Validation: &cr.Int32Validation{
+										Required:             true,
+										GreaterThanOrEqualTo: pointer.Int32(1),
+										LessThanOrEqualTo:    pointer.Int32(100),
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// the conditions which envoy supports for retrying requests (status codes are also supported)
+var _retryConditions = []string{
+	"5xx", "gateway-error", "reset", "connect-failure", "retriable-4xx", "refused-stream", "retriable-status-codes",
+	"retriable-headers", "cancelled", "deadline-exceeded", "internal", "resource-exhausted", "unavailable",
+}
+
+func retriesValidation() *cr.StructFieldValidation {
+	return &cr.StructFieldValidation{
+		StructField: "Retries",
+		StructValidation: &cr.StructValidation{
+			DefaultNil:        true,
+			AllowExplicitNull: true,
+			StructFieldValidations: []*cr.StructFieldValidation{
+				{
+					StructField: "Attempts",
+					Int32Validation: &cr.Int32Validation{
+						Default:              2,
+						GreaterThanOrEqualTo: pointer.Int32(1),
+						LessThanOrEqualTo:    pointer.Int32(10),
+					},
+				},
+				{
+					StructField: "RetryOn",
+					StringListValidation: &cr.StringListValidation{
+						Default:        []string{"connect-failure", "refused-stream", "unavailable", "cancelled", "503"},
+						CastSingleItem: true,
+						DisallowDups:   true,
+						MinLength:      1,
+						Validator:      validateRetryConditions,
+					},
+				},
+				{
+					StructField: "PerTryTimeout",
+					StringPtrValidation: &cr.StringPtrValidation{
+						Default:           nil,
+						AllowExplicitNull: true,
+					},
+					Parser: cr.DurationParser(&cr.DurationValidation{
+						GreaterThanOrEqualTo: pointer.Duration(libtime.MustParseDuration("1ms")),
+					}),
+				},
+				{
+					StructField: "Hedging",
+					StructValidation: &cr.StructValidation{
+						DefaultNil:        true,
+						AllowExplicitNull: true,
+						StructFieldValidations: []*cr.StructFieldValidation{
+							{
+								StructField: "Delay",
+								StringPtrValidation: &cr.StringPtrValidation{
+									Default:           nil,
+									AllowExplicitNull: true,
+								},
+								Parser: cr.DurationParser(&cr.DurationValidation{
+									GreaterThanOrEqualTo: pointer.Duration(libtime.MustParseDuration("1ms")),
+								}),
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func validateRetryConditions(conditions []string) ([]string, error) {
+	for _, condition := range conditions {
+		if slices.HasString(_retryConditions, condition) {
+			continue
+		}
+		if statusCode, err := strconv.Atoi(condition); err == nil && statusCode >= 400 && statusCode <= 599 {
+			continue
+		}
+		return nil, ErrorInvalidRetryCondition(condition, _retryConditions)
+	}
+	return conditions, nil
+}
+
+func modelsValidation() *cr.StructFieldValidation {
+	return &cr.StructFieldValidation{
+		StructField: "Models",
+		StructValidation: &cr.StructValidation{
+			DefaultNil:        true,
+			AllowExplicitNull: true,
+			StructFieldValidations: []*cr.StructFieldValidation{
+				{
+					StructField: "Paths",
+					StructListValidation: &cr.StructListValidation{
+						Required:  true,
+						MinLength: 1,
+						StructValidation: &cr.StructValidation{
+							StructFieldValidations: []*cr.StructFieldValidation{
+								{
+									StructField: "Name",
+									StringValidation: &cr.StringValidation{
+										Required:                      true,
+										AlphaNumericDashDotUnderscore: true,
+										MaxLength:                     128,
+									},
+								},
+								{
+									StructField: "Path",
+									StringValidation: &cr.StringValidation{
+										AllowEmpty: true, // if model_registry is specified
+										Validator:  optionalS3PathValidator,
+									},
+								},
+								modelRegistryValidation(),
+								{
+									StructField:    "Watch",
+									BoolValidation: &cr.BoolValidation{},
+								},
+								integrityValidation(),
+							},
+						},
+					},
+				},
+				{
+					StructField: "MaxLoadedModels",
+					Int32Validation: &cr.Int32Validation{
+						Default:     10,
+						GreaterThan: pointer.Int32(0),
+					},
+				},
+				{
+					StructField: "UnloadPath",
+					StringPtrValidation: &cr.StringPtrValidation{
+						Required:          false,
+						AllowExplicitNull: true,
+						Validator:         urls.ValidateEndpoint,
+					},
+				},
+			},
+		},
+	}
+}
+
+func modelRegistryValidation() *cr.StructFieldValidation {
+	return &cr.StructFieldValidation{
+		StructField: "ModelRegistry",
+		StructValidation: &cr.StructValidation{
+			Required:          false,
+			DefaultNil:        true,
+			AllowExplicitNull: true,
+			StructFieldValidations: []*cr.StructFieldValidation{
+				{
+					StructField: "Provider",
+					StringValidation: &cr.StringValidation{
+						Required:      true,
+						AllowedValues: []string{userconfig.MLflowModelRegistry, userconfig.SageMakerModelRegistry},
+					},
+				},
+				{
+					StructField: "Name",
+					StringValidation: &cr.StringValidation{
+						Required: true,
+					},
+				},
+				{
+					StructField: "Stage",
+					StringPtrValidation: &cr.StringPtrValidation{
+						Required:          false,
+						AllowExplicitNull: true,
+					},
+				},
+				{
+					StructField: "Version",
+					StringPtrValidation: &cr.StringPtrValidation{
+						Required:          false,
+						AllowExplicitNull: true,
+						CastInt:           true,
+					},
+				},
+				{
+					StructField: "TrackingURI",
+					StringPtrValidation: &cr.StringPtrValidation{
+						Required:          false,
+						AllowExplicitNull: true,
+						Validator:         validateHTTPURL,
+					},
+				},
+				{
+					StructField: "TokenSecret",
+					StringPtrValidation: &cr.StringPtrValidation{
+						Required:          false,
+						AllowExplicitNull: true,
+					},
+				},
+			},
+		},
+	}
+}
+
+func integrityValidation() *cr.StructFieldValidation {
+	return &cr.StructFieldValidation{
+		StructField: "Integrity",
+		StructValidation: &cr.StructValidation{
+			Required:          false,
+			DefaultNil:        true,
+			AllowExplicitNull: true,
+			StructFieldValidations: []*cr.StructFieldValidation{
+				{
+					StructField: "SHA256",
+					StringPtrValidation: &cr.StringPtrValidation{
+						Required:          false,
+						AllowExplicitNull: true,
+						Validator:         validateSHA256Checksum,
+					},
+				},
+				{
+					StructField: "Signature",
+					StringPtrValidation: &cr.StringPtrValidation{
+						Required:          false,
+						AllowExplicitNull: true,
+						Validator:         validateBase64,
+					},
+				},
+				{
+					StructField: "PublicKey",
+					StringPtrValidation: &cr.StringPtrValidation{
+						Required:          false,
+						AllowExplicitNull: true,
+						Validator:         validatePublicKey,
+					},
+				},
+			},
+		},
+	}
+}
+
+func optionalS3PathValidator(val string) (string, error) {
+	if val == "" {
+		return val, nil
+	}
+	return cr.S3PathValidator(val)
+}
+
+func gatewayValidation() *cr.StructFieldValidation {
+	return &cr.StructFieldValidation{
+		StructField: "Gateway",
+		StructValidation: &cr.StructValidation{
+			StructFieldValidations: []*cr.StructFieldValidation{
+				{
+					StructField: "TargetSubmissionRate",
+					Float64Validation: &cr.Float64Validation{
+						Default:     100,
+						GreaterThan: pointer.Float64(0),
+					},
+				},
+				{
+					StructField: "MaxWriteLatency",
+					StringValidation: &cr.StringValidation{
+						Default: "5s",
+					},
+					Parser: cr.DurationParser(&cr.DurationValidation{
+						GreaterThanOrEqualTo: pointer.Duration(libtime.MustParseDuration("100ms")),
+						LessThanOrEqualTo:    pointer.Duration(libtime.MustParseDuration("1m")),
+					}),
+				},
+				{
+					StructField: "RetryAfter",
+					StringValidation: &cr.StringValidation{
+						Default: "10s",
+					},
+					Parser: cr.DurationParser(&cr.DurationValidation{
+						GreaterThanOrEqualTo: pointer.Duration(libtime.MustParseDuration("1s")),
+						LessThanOrEqualTo:    pointer.Duration(libtime.MustParseDuration("10m")),
+					}),
+				},
+			},
+		},
+	}
+}
+
+func expirationValidation() *cr.StructFieldValidation {
+	return &cr.StructFieldValidation{
+		StructField: "Expiration",
+		StringPtrValidation: &cr.StringPtrValidation{
+			Default:           nil,
+			AllowExplicitNull: true,
+		},
+		Parser: cr.DurationParser(&cr.DurationValidation{
+			GreaterThanOrEqualTo: pointer.Duration(libtime.MustParseDuration("1s")),
+			LessThanOrEqualTo:    pointer.Duration(libtime.MustParseDuration("96h")), // the retention period of the api's queue
+		}),
+	}
+}
+
+func loggingValidation() *cr.StructFieldValidation {
+	return &cr.StructFieldValidation{
+		StructField: "Logging",
+		StructValidation: &cr.StructValidation{
+			DefaultNil:        true,
+			AllowExplicitNull: true,
+			StructFieldValidations: []*cr.StructFieldValidation{
+				{
+					StructField: "Level",
+					StringPtrValidation: &cr.StringPtrValidation{
+						AllowExplicitNull: true,
+						AllowedValues:     userconfig.LogLevelTypes(),
+					},
+					Parser: func(str string) (interface{}, error) {
+						return userconfig.LogLevelFromString(str), nil
+					},
+				},
+				{
+					StructField: "Format",
+					StringPtrValidation: &cr.StringPtrValidation{
+						AllowExplicitNull: true,
+						AllowedValues:     userconfig.LogFormatTypes(),
+					},
+					Parser: func(str string) (interface{}, error) {
+						return userconfig.LogFormatFromString(str), nil
+					},
+				},
+			},
+		},
+	}
+}
+
+func sloValidation() *cr.StructFieldValidation {
+	return &cr.StructFieldValidation{
+		StructField: "SLO",
+		StructValidation: &cr.StructValidation{
+			DefaultNil:        true,
+			AllowExplicitNull: true,
+			StructFieldValidations: []*cr.StructFieldValidation{
+				{
+					StructField: "Window",
+					StringValidation: &cr.StringValidation{
+						Default: "168h",
+					},
+					// the window can't exceed prometheus' retention period
+					Parser: cr.DurationParser(&cr.DurationValidation{
+						GreaterThanOrEqualTo: pointer.Duration(libtime.MustParseDuration("1h")),
+						LessThanOrEqualTo:    pointer.Duration(libtime.MustParseDuration("336h")),
+					}),
+				},
+				{
+					StructField: "Availability",
+					Float64PtrValidation: &cr.Float64PtrValidation{
+						Default:           nil,
+						AllowExplicitNull: true,
+						GreaterThan:       pointer.Float64(0),
+						LessThan:          pointer.Float64(100),
+					},
+				},
+				{
+					StructField: "Latency",
+					StructListValidation: &cr.StructListValidation{
+						AllowExplicitNull: true,
+						StructValidation: &cr.StructValidation{
+							StructFieldValidations: []*cr.StructFieldValidation{
+								{
+									StructField: "Percentile",
+									Float64Validation: &cr.Float64Validation{
+										Required:    true,
+										GreaterThan: pointer.Float64(0),
+										LessThan:    pointer.Float64(100),
+									},
+								},
+								{
+									StructField: "Threshold",
+									StringValidation: &cr.StringValidation{
+										Required: true,
+									},
+									Parser: cr.DurationParser(&cr.DurationValidation{
+										GreaterThan: pointer.Duration(0),
+									}),
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func hooksValidation() *cr.StructFieldValidation {
+	return &cr.StructFieldValidation{
+		StructField: "Hooks",
+		StructValidation: &cr.StructValidation{
+			DefaultNil:        true,
+			AllowExplicitNull: true,
+			StructFieldValidations: []*cr.StructFieldValidation{
+				// pre-deploy hooks block the deployment, so they must finish before the cli's request times out
+				hookValidation("PreDeploy", libtime.MustParseDuration("5m")),
+				hookValidation("PostDeploy", libtime.MustParseDuration("1h")),
+			},
+		},
+	}
+}
+
+func hookValidation(structFieldName string, maxTimeout time.Duration) *cr.StructFieldValidation {
 	return &cr.StructFieldValidation{
-		StructField: "UpdateStrategy",
+		StructField: structFieldName,
 		StructValidation: &cr.StructValidation{
+			DefaultNil:        true,
+			AllowExplicitNull: true,
 			StructFieldValidations: []*cr.StructFieldValidation{
 				{
-					StructField: "MaxSurge",
-					StringValidation: &cr.StringValidation{
-						Default:   "25%",
-						CastInt:   true,
-						Validator: surgeOrUnavailableValidator,
+					StructField: "HTTP",
+					StructValidation: &cr.StructValidation{
+						DefaultNil:        true,
+						AllowExplicitNull: true,
+						StructFieldValidations: []*cr.StructFieldValidation{
+							{
+								StructField: "URL",
+								StringValidation: &cr.StringValidation{
+									Required:  true,
+									Validator: validateHTTPURL,
+								},
+							},
+							{
+								StructField: "Method",
+								StringValidation: &cr.StringValidation{
+									Default:       "POST",
+									AllowedValues: []string{"GET", "POST", "PUT"},
+								},
+							},
+							{
+								StructField: "Headers",
+								StringMapValidation: &cr.StringMapValidation{
+									Default:    map[string]string{},
+									AllowEmpty: true,
+								},
+							},
+							{
+								StructField: "Body",
+								StringPtrValidation: &cr.StringPtrValidation{
+									Default:           nil,
+									AllowExplicitNull: true,
+									AllowEmpty:        true,
+								},
+							},
+						},
 					},
 				},
 				{
-					StructField: "MaxUnavailable",
+					StructField: "Job",
+					StructValidation: &cr.StructValidation{
+						DefaultNil:        true,
+						AllowExplicitNull: true,
+						StructFieldValidations: []*cr.StructFieldValidation{
+							{
+								StructField: "Image",
+								StringValidation: &cr.StringValidation{
+									Required:    true,
+									AllowEmpty:  false,
+									DockerImage: true,
+								},
+							},
+							{
+								StructField: "Env",
+								StringMapValidation: &cr.StringMapValidation{
+									Default:    map[string]string{},
+									AllowEmpty: true,
+								},
+							},
+							{
+								StructField: "Command",
+								StringListValidation: &cr.StringListValidation{
+									AllowExplicitNull: true,
+									AllowEmpty:        true,
+								},
+							},
+							{
+								StructField: "Args",
+								StringListValidation: &cr.StringListValidation{
+									AllowExplicitNull: true,
+									AllowEmpty:        true,
+								},
+							},
+						},
+					},
+				},
+				{
+					StructField: "Timeout",
 					StringValidation: &cr.StringValidation{
-						Default:   "25%",
-						CastInt:   true,
-						Validator: surgeOrUnavailableValidator,
+						Default: "2m",
 					},
+					Parser: cr.DurationParser(&cr.DurationValidation{
+						GreaterThan:       pointer.Duration(0),
+						LessThanOrEqualTo: pointer.Duration(maxTimeout),
+					}),
 				},
 			},
 		},
@@ -651,8 +2042,10 @@ func ExtractAPIConfigs(configBytes []byte, configFileName string) ([]userconfig.
 	return apis, nil
 }
 
+// clusterRegistryCredentials are the registry credentials in the cluster configuration, which apply to all APIs
 func ValidateAPI(
 	api *userconfig.API,
+	clusterRegistryCredentials []*userconfig.RegistryCredentials,
 	awsClient *aws.Client,
 	k8sClient *k8s.Client,
 ) error {
@@ -661,8 +2054,14 @@ func ValidateAPI(
 		api.Networking.Endpoint = pointer.String("/" + api.Name)
 	}
 
+	if api.OS == aws.OSWindows {
+		if err := validateWindowsAPI(api); err != nil {
+			return err
+		}
+	}
+
 	if api.Pod != nil {
-		if err := validatePod(api, awsClient, k8sClient); err != nil {
+		if err := validatePod(api, clusterRegistryCredentials, awsClient, k8sClient); err != nil {
 			return errors.Wrap(err, userconfig.PodKey)
 		}
 	}
@@ -679,6 +2078,83 @@ func ValidateAPI(
 		}
 	}
 
+	if api.Availability != nil {
+		if err := validateAvailability(api.Availability, api.Autoscaling); err != nil {
+			return errors.Wrap(err, userconfig.AvailabilityKey)
+		}
+	}
+
+	if api.SLO != nil {
+		if err := validateSLO(api.SLO); err != nil {
+			return errors.Wrap(err, userconfig.SLOKey)
+		}
+	}
+
+	if api.Proxy != nil && api.Proxy.FairQueuing != nil {
+		clients := []string{}
+		for i, clientWeight := range api.Proxy.FairQueuing.Clients {
+			if slices.HasString(clients, clientWeight.Client) {
+				return errors.Wrap(ErrorDuplicateClient(clientWeight.Client), userconfig.ProxyKey, userconfig.FairQueuingKey, userconfig.ClientsKey, s.Index(i), userconfig.ClientKey)
+			}
+			clients = append(clients, clientWeight.Client)
+		}
+	}
+
+	if api.Retries != nil && api.Retries.Hedging != nil && api.Retries.PerTryTimeout != nil {
+		// the hedging delay is used as the per-try timeout
+		return errors.Wrap(ErrorConflictingFields(userconfig.PerTryTimeoutKey, userconfig.HedgingKey), userconfig.RetriesKey)
+	}
+
+	if api.Models != nil {
+		if err := validateModels(api.Models, awsClient); err != nil {
+			return errors.Wrap(err, userconfig.ModelsKey)
+		}
+	}
+
+	if api.Hooks != nil {
+		var registryCredentials []*userconfig.RegistryCredentials
+		if api.Pod != nil {
+			registryCredentials = append(registryCredentials, api.Pod.RegistryCredentials...)
+		}
+		registryCredentials = append(registryCredentials, clusterRegistryCredentials...)
+
+		if err := validateHooks(api.Hooks, registryCredentials, awsClient, k8sClient); err != nil {
+			return errors.Wrap(err, userconfig.HooksKey)
+		}
+	}
+
+	return nil
+}
+
+// windows apis can't use the features which rely on cortex's linux images (e.g. the model cache downloader and hook jobs) or on linux kernel features
+func validateWindowsAPI(api *userconfig.API) error {
+	if api.Arch != aws.ArchAMD64 {
+		return errors.Wrap(ErrorWindowsAPIRequiresAMD64(), userconfig.ArchKey)
+	}
+
+	if api.Hooks != nil {
+		return errors.Wrap(ErrorNotSupportedForWindowsAPIs(userconfig.HooksKey), userconfig.HooksKey)
+	}
+
+	if api.Models != nil {
+		return errors.Wrap(ErrorNotSupportedForWindowsAPIs(userconfig.ModelsKey), userconfig.ModelsKey)
+	}
+
+	if api.Pod == nil {
+		return nil
+	}
+	if api.Pod.ModelCache != nil {
+		return errors.Wrap(ErrorNotSupportedForWindowsAPIs(userconfig.ModelCacheKey), userconfig.PodKey, userconfig.ModelCacheKey)
+	}
+	if api.Pod.EFS != nil {
+		return errors.Wrap(ErrorNotSupportedForWindowsAPIs(userconfig.EFSKey), userconfig.PodKey, userconfig.EFSKey)
+	}
+	for i, container := range api.Pod.Containers {
+		if container.Compute != nil && container.Compute.Shm != nil {
+			return errors.Wrap(ErrorNotSupportedForWindowsAPIs(userconfig.ShmKey), userconfig.PodKey, userconfig.ContainersKey, s.Index(i), userconfig.ComputeKey, userconfig.ShmKey)
+		}
+	}
+
 	return nil
 }
 
@@ -703,11 +2179,16 @@ func ValidateTrafficSplitter(api *userconfig.API) error {
 		}
 	}
 
+	if api.SessionAffinity != nil && api.SessionAffinity.Cookie == nil && api.SessionAffinity.Header == nil {
+		return errors.Wrap(ErrorSessionAffinityMissingKey(), userconfig.SessionAffinityKey)
+	}
+
 	return nil
 }
 
 func validatePod(
 	api *userconfig.API,
+	clusterRegistryCredentials []*userconfig.RegistryCredentials,
 	awsClient *aws.Client,
 	k8sClient *k8s.Client,
 ) error {
@@ -725,16 +2206,207 @@ func validatePod(
 		return errors.Wrap(err, userconfig.ComputeKey)
 	}
 
-	if err := validateContainers(containers, api.Kind, awsClient, k8sClient); err != nil {
+	if api.Pod.Config != nil {
+		if err := validateRuntimeConfig(api.Pod.Config); err != nil {
+			return errors.Wrap(err, userconfig.RuntimeConfigKey)
+		}
+	}
+
+	if err := validateSecrets(api.Pod.Secrets, containers); err != nil {
+		return errors.Wrap(err, userconfig.SecretsKey)
+	}
+
+	if err := ValidateRegistryCredentials(api.Pod.RegistryCredentials); err != nil {
+		return errors.Wrap(err, userconfig.RegistryCredentialsKey)
+	}
+
+	// the API's credentials take precedence over the cluster's
+	registryCredentials := append(append([]*userconfig.RegistryCredentials{}, api.Pod.RegistryCredentials...), clusterRegistryCredentials...)
+
+	if err := validateInitContainers(api.Pod.InitContainers, containers, api.OS, registryCredentials, awsClient, k8sClient); err != nil {
+		return errors.Wrap(err, userconfig.InitContainersKey)
+	}
+
+	if err := validateContainers(containers, api.Kind, api.OS, registryCredentials, awsClient, k8sClient); err != nil {
 		return errors.Wrap(err, userconfig.ContainersKey)
 	}
 
+	if api.Pod.ModelCache != nil {
+		// the apis of the other kinds don't have long-running deployments which could be updated
+		if api.Pod.ModelCache.Watch && api.Kind != userconfig.RealtimeAPIKind && api.Kind != userconfig.AsyncAPIKind {
+			return errors.Wrap(ErrorFieldIsNotSupportedForKind(userconfig.WatchKey, api.Kind), userconfig.ModelCacheKey)
+		}
+		if err := validateModelCache(api.Pod.ModelCache, awsClient); err != nil {
+			return errors.Wrap(err, userconfig.ModelCacheKey)
+		}
+	}
+
+	return nil
+}
+
+func validateModelCache(modelCache *userconfig.ModelCache, awsClient *aws.Client) error {
+	if modelCache.Integrity != nil {
+		if err := validateIntegrity(modelCache.Integrity, modelCache.Watch); err != nil {
+			return errors.Wrap(err, userconfig.IntegrityKey)
+		}
+	}
+
+	path, err := resolveModelPath(modelCache.Path, modelCache.ModelRegistry, awsClient)
+	if err != nil {
+		return err
+	}
+	modelCache.Path = path
+
+	isPrefix, err := awsClient.IsS3PathPrefix(modelCache.Path)
+	if err != nil {
+		return errors.Wrap(err, userconfig.PathKey)
+	}
+	if !isPrefix {
+		return errors.Wrap(ErrorS3PathNotFound(modelCache.Path), userconfig.PathKey)
+	}
+
+	if modelCache.Watch && modelCache.ModelRegistry == nil {
+		modelCache.ArtifactVersion, err = awsClient.GetS3PathFingerprint(modelCache.Path)
+		if err != nil {
+			return errors.Wrap(err, userconfig.PathKey)
+		}
+	}
+
+	return nil
+}
+
+func validateModels(models *userconfig.Models, awsClient *aws.Client) error {
+	modelNames := []string{}
+
+	for i, modelPath := range models.Paths {
+		if slices.HasString(modelNames, modelPath.Name) {
+			return errors.Wrap(ErrorDuplicateModelName(modelPath.Name), userconfig.ModelPathsKey, s.Index(i), userconfig.NameKey)
+		}
+		modelNames = append(modelNames, modelPath.Name)
+
+		if modelPath.Integrity != nil {
+			if err := validateIntegrity(modelPath.Integrity, modelPath.Watch); err != nil {
+				return errors.Wrap(err, userconfig.ModelPathsKey, s.Index(i), userconfig.IntegrityKey)
+			}
+		}
+
+		path, err := resolveModelPath(modelPath.Path, modelPath.ModelRegistry, awsClient)
+		if err != nil {
+			return errors.Wrap(err, userconfig.ModelPathsKey, s.Index(i))
+		}
+		modelPath.Path = path
+
+		isPrefix, err := awsClient.IsS3PathPrefix(modelPath.Path)
+		if err != nil {
+			return errors.Wrap(err, userconfig.ModelPathsKey, s.Index(i), userconfig.PathKey)
+		}
+		if !isPrefix {
+			return errors.Wrap(ErrorS3PathNotFound(modelPath.Path), userconfig.ModelPathsKey, s.Index(i), userconfig.PathKey)
+		}
+
+		if modelPath.Watch && modelPath.ModelRegistry == nil {
+			modelPath.ArtifactVersion, err = awsClient.GetS3PathFingerprint(modelPath.Path)
+			if err != nil {
+				return errors.Wrap(err, userconfig.ModelPathsKey, s.Index(i), userconfig.PathKey)
+			}
+		}
+	}
+
+	return nil
+}
+
+func validateRuntimeConfig(runtimeConfig *userconfig.RuntimeConfig) error {
+	totalSize := 0
+	for fileName, contents := range runtimeConfig.Files {
+		totalSize += len(fileName) + len(contents)
+	}
+	if totalSize > _maxRuntimeConfigFilesSize {
+		return errors.Wrap(ErrorRuntimeConfigTooLarge(_maxRuntimeConfigFilesSize), userconfig.FilesKey)
+	}
+
+	for key := range runtimeConfig.Env {
+		if strings.HasPrefix(key, "CORTEX_") || strings.HasPrefix(key, "KUBEXIT_") {
+			return errors.Wrap(ErrorCortexPrefixedEnvVarNotAllowed("CORTEX_", "KUBEXIT_"), userconfig.EnvKey, key)
+		}
+	}
+
+	return nil
+}
+
+func validateSecrets(secrets []*userconfig.Secret, containers []*userconfig.Container) error {
+	secretNames := []string{}
+
+	for i, secret := range secrets {
+		if slices.HasString(secretNames, secret.Name) {
+			return errors.Wrap(ErrorDuplicateSecretName(secret.Name), s.Index(i), userconfig.SecretNameKey)
+		}
+		secretNames = append(secretNames, secret.Name)
+
+		if strings.HasPrefix(secret.Name, "CORTEX_") || strings.HasPrefix(secret.Name, "KUBEXIT_") {
+			return errors.Wrap(ErrorCortexPrefixedEnvVarNotAllowed("CORTEX_", "KUBEXIT_"), s.Index(i), userconfig.SecretNameKey)
+		}
+
+		for _, container := range containers {
+			if _, ok := container.Env[secret.Name]; ok {
+				return errors.Wrap(ErrorSecretConflictsWithEnvVar(secret.Name, container.Name), s.Index(i), userconfig.SecretNameKey)
+			}
+		}
+
+		numSpecified := 0
+		if secret.SecretsManager != nil {
+			numSpecified++
+		}
+		if secret.SSMParameter != nil {
+			numSpecified++
+		}
+		if numSpecified != 1 {
+			return errors.Wrap(ErrorSpecifyExactlyOneField(numSpecified, userconfig.SecretsManagerKey, userconfig.SSMParameterKey), s.Index(i))
+		}
+
+		if secret.Key != nil && secret.SecretsManager == nil {
+			return errors.Wrap(ErrorOneOfPrerequisitesNotDefined(userconfig.SecretKeyKey, userconfig.SecretsManagerKey), s.Index(i))
+		}
+	}
+
+	return nil
+}
+
+func validateInitContainers(
+	initContainers []*userconfig.InitContainer,
+	containers []*userconfig.Container,
+	os string,
+	registryCredentials []*userconfig.RegistryCredentials,
+	awsClient *aws.Client,
+	k8sClient *k8s.Client,
+) error {
+	// init containers share the pod's container name space
+	containerNames := userconfig.GetContainerNames(containers)
+
+	for i, initContainer := range initContainers {
+		if containerNames.Has(initContainer.Name) {
+			return errors.Wrap(ErrorDuplicateContainerName(initContainer.Name), s.Index(i), userconfig.ContainerNameKey)
+		}
+		containerNames.Add(initContainer.Name)
+
+		if err := validateDockerImagePath(initContainer.Image, initContainer.ImageDigest, os, registryCredentials, awsClient, k8sClient); err != nil {
+			return errors.Wrap(err, s.Index(i), userconfig.ImageKey)
+		}
+
+		for key := range initContainer.Env {
+			if strings.HasPrefix(key, "CORTEX_") || strings.HasPrefix(key, "KUBEXIT_") {
+				return errors.Wrap(ErrorCortexPrefixedEnvVarNotAllowed("CORTEX_", "KUBEXIT_"), s.Index(i), userconfig.EnvKey, key)
+			}
+		}
+	}
+
 	return nil
 }
 
 func validateContainers(
 	containers []*userconfig.Container,
 	kind userconfig.Kind,
+	os string,
+	registryCredentials []*userconfig.RegistryCredentials,
 	awsClient *aws.Client,
 	k8sClient *k8s.Client,
 ) error {
@@ -750,7 +2422,7 @@ func validateContainers(
 			return errors.Wrap(ErrorFieldMustBeSpecifiedForKind(userconfig.CommandKey, kind), s.Index(i), userconfig.CommandKey)
 		}
 
-		if err := validateDockerImagePath(container.Image, awsClient, k8sClient); err != nil {
+		if err := validateDockerImagePath(container.Image, container.ImageDigest, os, registryCredentials, awsClient, k8sClient); err != nil {
 			return errors.Wrap(err, s.Index(i), userconfig.ImageKey)
 		}
 
@@ -775,6 +2447,18 @@ func validateContainers(
 			if err := validateProbe(*container.LivenessProbe, true); err != nil {
 				return errors.Wrap(err, s.Index(i), userconfig.LivenessProbeKey)
 			}
+			if container.LivenessProbe.SuccessThreshold != 1 {
+				return errors.Wrap(ErrorInvalidProbeSuccessThreshold(userconfig.LivenessProbeKey), s.Index(i), userconfig.LivenessProbeKey, userconfig.SuccessThresholdKey)
+			}
+		}
+
+		if container.StartupProbe != nil {
+			if err := validateProbe(*container.StartupProbe, true); err != nil {
+				return errors.Wrap(err, s.Index(i), userconfig.StartupProbeKey)
+			}
+			if container.StartupProbe.SuccessThreshold != 1 {
+				return errors.Wrap(ErrorInvalidProbeSuccessThreshold(userconfig.StartupProbeKey), s.Index(i), userconfig.StartupProbeKey, userconfig.SuccessThresholdKey)
+			}
 		}
 
 		compute := container.Compute
@@ -782,6 +2466,44 @@ func validateContainers(
 			return errors.Wrap(ErrorShmCannotExceedMem(*compute.Shm, *compute.Mem), s.Index(i), userconfig.ComputeKey)
 		}
 
+		if container.VerticalAutoscaling != nil {
+			if err := validateVerticalAutoscaling(*container.VerticalAutoscaling, *compute, kind); err != nil {
+				return errors.Wrap(err, s.Index(i), userconfig.VerticalAutoscalingKey)
+			}
+		}
+	}
+
+	return nil
+}
+
+// the container's compute requests are used until the operator has measured its usage, so they must be within the bounds
+func validateVerticalAutoscaling(verticalAutoscaling userconfig.VerticalAutoscaling, compute userconfig.Compute, kind userconfig.Kind) error {
+	if kind != userconfig.RealtimeAPIKind && kind != userconfig.AsyncAPIKind {
+		return ErrorFieldIsNotSupportedForKind(userconfig.VerticalAutoscalingKey, kind)
+	}
+
+	if compute.CPU == nil {
+		return ErrorOneOfPrerequisitesNotDefined(userconfig.VerticalAutoscalingKey, userconfig.ComputeKey+"."+userconfig.CPUKey)
+	}
+	if compute.Mem == nil {
+		return ErrorOneOfPrerequisitesNotDefined(userconfig.VerticalAutoscalingKey, userconfig.ComputeKey+"."+userconfig.MemKey)
+	}
+
+	bounds := []struct {
+		smallerKey string
+		smaller    *k8s.Quantity
+		biggerKey  string
+		bigger     *k8s.Quantity
+	}{
+		{userconfig.MinCPUKey, verticalAutoscaling.MinCPU, userconfig.ComputeKey + "." + userconfig.CPUKey, compute.CPU},
+		{userconfig.ComputeKey + "." + userconfig.CPUKey, compute.CPU, userconfig.MaxCPUKey, verticalAutoscaling.MaxCPU},
+		{userconfig.MinMemKey, verticalAutoscaling.MinMem, userconfig.ComputeKey + "." + userconfig.MemKey, compute.Mem},
+		{userconfig.ComputeKey + "." + userconfig.MemKey, compute.Mem, userconfig.MaxMemKey, verticalAutoscaling.MaxMem},
+	}
+	for _, bound := range bounds {
+		if bound.smaller.Cmp(bound.bigger.Quantity) > 0 {
+			return ErrorConfigGreaterThanOtherConfig(bound.smallerKey, bound.smaller.UserString, bound.biggerKey, bound.bigger.UserString)
+		}
 	}
 
 	return nil
@@ -844,15 +2566,19 @@ func validateAutoscaling(api *userconfig.API) error {
 	return nil
 }
 
+// a fraction of a GPU can be requested (e.g. 0.25), but more than one GPU must be requested in whole GPUs
+func validateGPU(gpu float64) (float64, error) {
+	if gpu > 1 && gpu != math.Trunc(gpu) {
+		return 0, ErrorInvalidFractionalGPU(gpu)
+	}
+	return gpu, nil
+}
+
 func validateCompute(compute userconfig.Compute) error {
 	if compute.GPU > 0 && compute.Inf > 0 {
 		return ErrorComputeResourceConflict(userconfig.GPUKey, userconfig.InfKey)
 	}
 
-	if compute.Inf > 1 {
-		return ErrorInvalidNumberOfInfs(compute.Inf)
-	}
-
 	return nil
 }
 
@@ -864,8 +2590,116 @@ func validateUpdateStrategy(updateStrategy *userconfig.UpdateStrategy) error {
 	return nil
 }
 
+func validateAvailability(availability *userconfig.Availability, autoscaling *userconfig.Autoscaling) error {
+	if availability.MaxDisruptedReplicas == nil {
+		return nil
+	}
+	maxDisruptedReplicas := *availability.MaxDisruptedReplicas
+
+	if maxDisruptedReplicas == "0" || maxDisruptedReplicas == "0%" {
+		return ErrorMaxDisruptedReplicasZero()
+	}
+
+	// the pod disruption budget only bounds disruption if it leaves at least one replica running when the api is at its smallest size
+	if autoscaling != nil {
+		disruptedReplicas, err := disruptedReplicasAt(maxDisruptedReplicas, autoscaling.MinReplicas)
+		if err != nil {
+			return errors.Wrap(err, userconfig.MaxDisruptedReplicasKey)
+		}
+		if disruptedReplicas >= autoscaling.MinReplicas {
+			return ErrorMaxDisruptedReplicasTooHigh(maxDisruptedReplicas, disruptedReplicas, autoscaling.MinReplicas)
+		}
+	}
+
+	return nil
+}
+
+func validateSLO(slo *userconfig.SLO) error {
+	if slo.Availability == nil && len(slo.Latency) == 0 {
+		return ErrorSLOMissingTarget()
+	}
+
+	percentiles := map[float64]bool{}
+	for _, latencyTarget := range slo.Latency {
+		if percentiles[latencyTarget.Percentile] {
+			return errors.Wrap(ErrorDuplicateLatencyPercentile(latencyTarget.Percentile), userconfig.LatencyKey)
+		}
+		percentiles[latencyTarget.Percentile] = true
+	}
+
+	return nil
+}
+
+func validateHooks(
+	hooks *userconfig.Hooks,
+	registryCredentials []*userconfig.RegistryCredentials,
+	awsClient *aws.Client,
+	k8sClient *k8s.Client,
+) error {
+	if hooks.PreDeploy != nil {
+		if err := validateHook(hooks.PreDeploy, registryCredentials, awsClient, k8sClient); err != nil {
+			return errors.Wrap(err, userconfig.PreDeployKey)
+		}
+	}
+	if hooks.PostDeploy != nil {
+		if err := validateHook(hooks.PostDeploy, registryCredentials, awsClient, k8sClient); err != nil {
+			return errors.Wrap(err, userconfig.PostDeployKey)
+		}
+	}
+	return nil
+}
+
+func validateHook(
+	hook *userconfig.Hook,
+	registryCredentials []*userconfig.RegistryCredentials,
+	awsClient *aws.Client,
+	k8sClient *k8s.Client,
+) error {
+	numSpecifiedHooks := 0
+	if hook.HTTP != nil {
+		numSpecifiedHooks++
+	}
+	if hook.Job != nil {
+		numSpecifiedHooks++
+	}
+
+	if numSpecifiedHooks != 1 {
+		return ErrorSpecifyExactlyOneField(numSpecifiedHooks, userconfig.HTTPKey, userconfig.JobKey)
+	}
+
+	if hook.Job != nil {
+		if err := validateDockerImagePath(hook.Job.Image, nil, aws.OSLinux, registryCredentials, awsClient, k8sClient); err != nil {
+			return errors.Wrap(err, userconfig.JobKey, userconfig.ImageKey)
+		}
+
+		for key := range hook.Job.Env {
+			if strings.HasPrefix(key, "CORTEX_") {
+				return errors.Wrap(ErrorCortexPrefixedEnvVarNotAllowed("CORTEX_"), userconfig.JobKey, userconfig.EnvKey, key)
+			}
+		}
+	}
+
+	return nil
+}
+
+func validateHTTPURL(httpURL string) (string, error) {
+	u, err := urls.Parse(httpURL)
+	if err != nil {
+		return "", err
+	}
+	if u.Scheme != "https" && u.Scheme != "http" || u.Host == "" {
+		return "", urls.ErrorInvalidURL(httpURL)
+	}
+	return httpURL, nil
+}
+
+// os is the operating system which the image must be available for (e.g. "linux"); if expectedDigest is not nil,
+// the image's digest in the registry must match it
 func validateDockerImagePath(
 	image string,
+	expectedDigest *string,
+	os string,
+	registryCredentials []*userconfig.RegistryCredentials,
 	awsClient *aws.Client,
 	k8sClient *k8s.Client,
 ) error {
@@ -876,7 +2710,19 @@ func validateDockerImagePath(
 
 	dockerAuthStr := docker.NoAuth
 
-	if regex.IsValidECRURL(image) {
+	if credentials := FindRegistryCredentials(image, registryCredentials); credentials != nil {
+		dockerAuth, err := RegistryAuth(credentials, awsClient)
+		if err != nil {
+			return err
+		}
+		if _, err := dockerClient.RegistryLogin(context.Background(), dockerAuth); err != nil {
+			return ErrorRegistryLoginFailed(credentials.Registry, errors.Message(err))
+		}
+		dockerAuthStr, err = docker.EncodeAuthConfig(dockerAuth)
+		if err != nil {
+			return err
+		}
+	} else if regex.IsValidECRURL(image) {
 		dockerAuthStr, err = docker.AWSAuthConfig(awsClient)
 		if err != nil {
 			return err
@@ -888,10 +2734,15 @@ func validateDockerImagePath(
 		}
 	}
 
-	if err := docker.CheckImageAccessible(dockerClient, image, dockerAuthStr); err != nil {
+	digest, err := docker.CheckImageAccessibleForOS(dockerClient, image, dockerAuthStr, os)
+	if err != nil {
 		return err
 	}
 
+	if expectedDigest != nil && *expectedDigest != digest {
+		return ErrorImageDigestMismatch(image, *expectedDigest, digest)
+	}
+
 	return nil
 }
 
@@ -956,3 +2807,19 @@ func getDockerAuthStrFromK8s(dockerClient *docker.Client, k8sClient *k8s.Client)
 
 	return dockerAuthStr, nil
 }
+
+func validateLabelValue(value string) (string, error) {
+	if len(kvalidation.IsValidLabelValue(value)) > 0 {
+		return "", ErrorInvalidLabelValue(value)
+	}
+	return value, nil
+}
+
+func validateHost(host string) error {
+	for _, label := range strings.Split(host, ".") {
+		if urls.CheckDNS1123(label) != nil {
+			return ErrorInvalidHost(host)
+		}
+	}
+	return nil
+}
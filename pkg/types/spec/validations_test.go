@@ -0,0 +1,70 @@
+/*
+Copyright 2021 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spec
+
+import (
+	"testing"
+
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	"github.com/cortexlabs/cortex/pkg/lib/pointer"
+	"github.com/cortexlabs/cortex/pkg/types/userconfig"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateAvailability(t *testing.T) {
+	availability := func(maxDisruptedReplicas string) *userconfig.Availability {
+		return &userconfig.Availability{MaxDisruptedReplicas: pointer.String(maxDisruptedReplicas)}
+	}
+	autoscaling := func(minReplicas int32) *userconfig.Autoscaling {
+		return &userconfig.Autoscaling{MinReplicas: minReplicas, MaxReplicas: 10}
+	}
+
+	require.NoError(t, validateAvailability(&userconfig.Availability{}, autoscaling(1)))
+	require.NoError(t, validateAvailability(availability("1"), autoscaling(2)))
+	require.NoError(t, validateAvailability(availability("50%"), autoscaling(3)))
+	require.NoError(t, validateAvailability(availability("2"), nil))
+
+	err := validateAvailability(availability("0%"), autoscaling(2))
+	require.Equal(t, ErrMaxDisruptedReplicasZero, errors.GetKind(err))
+
+	// a single replica can always be disrupted
+	err = validateAvailability(availability("1"), autoscaling(1))
+	require.Equal(t, ErrMaxDisruptedReplicasTooHigh, errors.GetKind(err))
+
+	err = validateAvailability(availability("100%"), autoscaling(4))
+	require.Equal(t, ErrMaxDisruptedReplicasTooHigh, errors.GetKind(err))
+
+	// percentages are rounded up (ceil(50% of 1) = 1)
+	err = validateAvailability(availability("50%"), autoscaling(1))
+	require.Equal(t, ErrMaxDisruptedReplicasTooHigh, errors.GetKind(err))
+
+	err = validateAvailability(availability("3"), autoscaling(3))
+	require.Equal(t, ErrMaxDisruptedReplicasTooHigh, errors.GetKind(err))
+}
+
+func TestDisruptedReplicasAt(t *testing.T) {
+	disrupted, err := disruptedReplicasAt("2", 10)
+	require.NoError(t, err)
+	require.Equal(t, int32(2), disrupted)
+
+	disrupted, err = disruptedReplicasAt("10%", 15)
+	require.NoError(t, err)
+	require.Equal(t, int32(2), disrupted)
+
+	_, err = disruptedReplicasAt("abc", 10)
+	require.Error(t, err)
+}
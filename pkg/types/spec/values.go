@@ -0,0 +1,372 @@
+/*
+Copyright 2021 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spec
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/cortexlabs/cortex/pkg/lib/cast"
+	cr "github.com/cortexlabs/cortex/pkg/lib/configreader"
+	"github.com/cortexlabs/cortex/pkg/lib/errors"
+	libjson "github.com/cortexlabs/cortex/pkg/lib/json"
+	s "github.com/cortexlabs/cortex/pkg/lib/strings"
+	"github.com/cortexlabs/cortex/pkg/types/userconfig"
+	"github.com/cortexlabs/yaml"
+)
+
+const (
+	ValuesVariablesKey = "variables"
+	ValuesAPIsKey      = "apis"
+
+	_valuesEnvPrefix = "env."
+)
+
+// matches ${{ name }}, ${{ nested.name }}, and ${{ env.NAME }}
+var _variableRegex = regexp.MustCompile(`\$\{\{\s*([A-Za-z0-9_\-]+(?:\.[A-Za-z0-9_\-]+)*)\s*\}\}`)
+
+// Values holds the variables and overlays of a values file, which allows the same api configuration file to be deployed to multiple environments
+type Values struct {
+	FileName  string
+	Variables map[string]interface{}
+	APIs      []map[string]interface{} // overlays, which are merged into the apis with matching names
+}
+
+func ReadValuesBytes(valuesBytes []byte, valuesFileName string) (*Values, error) {
+	valuesData, err := cr.ReadYAMLBytes(valuesBytes)
+	if err != nil {
+		return nil, errors.Wrap(err, valuesFileName)
+	}
+
+	casted, ok := cast.JSONMarshallable(valuesData)
+	if !ok {
+		return nil, errors.Wrap(ErrorMalformedValues(), valuesFileName)
+	}
+	valuesMap, ok := cast.InterfaceToStrInterfaceMap(casted)
+	if !ok {
+		return nil, errors.Wrap(ErrorMalformedValues(), valuesFileName)
+	}
+
+	values := &Values{
+		FileName:  valuesFileName,
+		Variables: map[string]interface{}{},
+	}
+
+	for key, value := range valuesMap {
+		switch key {
+		case ValuesVariablesKey:
+			variables, ok := cast.InterfaceToStrInterfaceMap(value)
+			if !ok {
+				return nil, errors.Wrap(ErrorMalformedValues(), valuesFileName, ValuesVariablesKey)
+			}
+			if variables != nil {
+				values.Variables = variables
+			}
+		case ValuesAPIsKey:
+			overlays, ok := cast.InterfaceToStrInterfaceMapSlice(value)
+			if !ok {
+				return nil, errors.Wrap(ErrorMalformedValues(), valuesFileName, ValuesAPIsKey)
+			}
+			for i, overlay := range overlays {
+				if name, ok := overlay[userconfig.NameKey].(string); !ok || name == "" {
+					return nil, errors.Wrap(cr.ErrorMustBeDefined(), valuesFileName, ValuesAPIsKey, s.Index(i), userconfig.NameKey)
+				}
+			}
+			values.APIs = overlays
+		default:
+			return nil, errors.Wrap(cr.ErrorUnsupportedKey(key), valuesFileName)
+		}
+	}
+
+	return values, nil
+}
+
+// ApplyValues substitutes the variables in the api configuration, and then merges the overlays into the apis with matching names;
+// the variables and overlays of later values files take precedence. Variables are substituted into the parsed configuration
+// (rather than its text), so values can't change the structure of the configuration, and placeholders in comments are ignored
+func ApplyValues(configBytes []byte, configFileName string, valuesList ...*Values) ([]byte, error) {
+	variables := map[string]interface{}{}
+	for _, values := range valuesList {
+		mergeOverlay(variables, values.Variables)
+	}
+
+	configData, err := cr.ReadYAMLBytes(configBytes)
+	if err != nil {
+		return nil, errors.Wrap(err, configFileName)
+	}
+	casted, ok := cast.JSONMarshallable(configData)
+	if !ok {
+		return nil, errors.Wrap(ErrorMalformedConfig(), configFileName)
+	}
+
+	substituted, numSubstitutions, err := substituteVariables(casted, variables)
+	if err != nil {
+		return nil, errors.Wrap(err, configFileName)
+	}
+
+	hasOverlays := false
+	for _, values := range valuesList {
+		if len(values.APIs) > 0 {
+			hasOverlays = true
+		}
+	}
+	if !hasOverlays {
+		if numSubstitutions == 0 {
+			return configBytes, nil
+		}
+		return yaml.Marshal(substituted)
+	}
+
+	apis, ok := cast.InterfaceToStrInterfaceMapSlice(substituted)
+	if !ok {
+		return nil, errors.Wrap(ErrorMalformedConfig(), configFileName)
+	}
+
+	for _, values := range valuesList {
+		for i, overlay := range values.APIs {
+			name := overlay[userconfig.NameKey].(string)
+			found := false
+			for _, api := range apis {
+				if api[userconfig.NameKey] == name {
+					mergeOverlay(api, overlay)
+					found = true
+				}
+			}
+			if !found {
+				return nil, errors.Wrap(ErrorOverlayAPINotFound(name, configFileName), values.FileName, ValuesAPIsKey, s.Index(i))
+			}
+		}
+	}
+
+	return yaml.Marshal(apis)
+}
+
+// substituteVariables replaces the placeholders in the keys and string values of the parsed configuration, and returns the number of placeholders which were replaced;
+// a value which consists of a single placeholder is replaced by the variable's value (which may be a number, list, map, etc), and other placeholders are replaced with the variable's value as a string
+func substituteVariables(data interface{}, variables map[string]interface{}) (interface{}, int, error) {
+	switch typed := data.(type) {
+	case map[string]interface{}:
+		substituted := make(map[string]interface{}, len(typed))
+		numSubstitutions := 0
+		for key, value := range typed {
+			substitutedKey, n, err := substituteVariablesInStr(key, variables)
+			if err != nil {
+				return nil, 0, errors.Wrap(err, key)
+			}
+			numSubstitutions += n
+
+			substitutedValue, n, err := substituteVariables(value, variables)
+			if err != nil {
+				return nil, 0, errors.Wrap(err, key)
+			}
+			numSubstitutions += n
+
+			substituted[substitutedKey] = substitutedValue
+		}
+		return substituted, numSubstitutions, nil
+
+	case []interface{}:
+		substituted := make([]interface{}, len(typed))
+		numSubstitutions := 0
+		for i, item := range typed {
+			substitutedItem, n, err := substituteVariables(item, variables)
+			if err != nil {
+				return nil, 0, errors.Wrap(err, s.Index(i))
+			}
+			numSubstitutions += n
+			substituted[i] = substitutedItem
+		}
+		return substituted, numSubstitutions, nil
+
+	case string:
+		if match := _variableRegex.FindStringSubmatchIndex(typed); match != nil && match[0] == 0 && match[1] == len(typed) {
+			value, err := variableValue(typed[match[2]:match[3]], variables)
+			if err != nil {
+				return nil, 0, err
+			}
+			return value, 1, nil
+		}
+		return substituteVariablesInStr(typed, variables)
+	}
+
+	return data, 0, nil
+}
+
+func substituteVariablesInStr(str string, variables map[string]interface{}) (string, int, error) {
+	var substitutionErr error
+	numSubstitutions := 0
+
+	substituted := _variableRegex.ReplaceAllStringFunc(str, func(match string) string {
+		numSubstitutions++
+		name := _variableRegex.FindStringSubmatch(match)[1]
+		value, err := variableValue(name, variables)
+		if err != nil {
+			if substitutionErr == nil {
+				substitutionErr = err
+			}
+			return ""
+		}
+		valueStr, err := variableValueStr(value)
+		if err != nil && substitutionErr == nil {
+			substitutionErr = err
+		}
+		return valueStr
+	})
+	if substitutionErr != nil {
+		return "", 0, substitutionErr
+	}
+
+	return substituted, numSubstitutions, nil
+}
+
+// environment variables are parsed as yaml scalars (e.g. so that they can be used as numbers); values which aren't scalars are used as strings
+func variableValue(name string, variables map[string]interface{}) (interface{}, error) {
+	if strings.HasPrefix(name, _valuesEnvPrefix) {
+		envVarName := strings.TrimPrefix(name, _valuesEnvPrefix)
+		value, ok := os.LookupEnv(envVarName)
+		if !ok {
+			return nil, ErrorUndefinedEnvVar(envVarName)
+		}
+		var parsed interface{}
+		if err := yaml.Unmarshal([]byte(value), &parsed); err == nil {
+			switch parsed.(type) {
+			case string, int, int64, float64, bool:
+				return parsed, nil
+			}
+		}
+		return value, nil
+	}
+
+	var value interface{} = variables
+	for _, key := range strings.Split(name, ".") {
+		valueMap, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, ErrorUndefinedVariable(name)
+		}
+		value, ok = valueMap[key]
+		if !ok {
+			return nil, ErrorUndefinedVariable(name)
+		}
+	}
+
+	return deepCopy(value), nil
+}
+
+// variableValueStr returns the value as it is inserted into a string which contains other text
+func variableValueStr(value interface{}) (string, error) {
+	switch value.(type) {
+	case map[string]interface{}, []interface{}:
+		jsonBytes, err := libjson.Marshal(value)
+		if err != nil {
+			return "", err
+		}
+		return string(jsonBytes), nil
+	case nil:
+		return "null", nil
+	default:
+		return fmt.Sprint(value), nil
+	}
+}
+
+// mergeOverlay recursively merges the overlay into the base map; lists of maps with names (e.g. containers) are merged by name,
+// other values are replaced, and null values remove the key from the base. The values which are added to the base are copied,
+// so that later merges into the base don't modify the overlay
+func mergeOverlay(base map[string]interface{}, overlay map[string]interface{}) {
+	for key, overlayValue := range overlay {
+		if overlayValue == nil {
+			delete(base, key)
+			continue
+		}
+
+		baseValue, ok := base[key]
+		if !ok {
+			base[key] = deepCopy(overlayValue)
+			continue
+		}
+
+		if baseMap, ok := baseValue.(map[string]interface{}); ok {
+			if overlayMap, ok := overlayValue.(map[string]interface{}); ok {
+				mergeOverlay(baseMap, overlayMap)
+				continue
+			}
+		}
+
+		if baseList, ok := cast.InterfaceToStrInterfaceMapSlice(baseValue); ok && isNamedList(baseList) {
+			if overlayList, ok := cast.InterfaceToStrInterfaceMapSlice(overlayValue); ok && isNamedList(overlayList) {
+				base[key] = mergeNamedLists(baseList, overlayList)
+				continue
+			}
+		}
+
+		base[key] = deepCopy(overlayValue)
+	}
+}
+
+func mergeNamedLists(baseList []map[string]interface{}, overlayList []map[string]interface{}) []interface{} {
+	merged := make([]interface{}, 0, len(baseList)+len(overlayList))
+	for _, item := range baseList {
+		merged = append(merged, item)
+	}
+
+	for _, overlayItem := range overlayList {
+		found := false
+		for _, baseItem := range baseList {
+			if baseItem[userconfig.NameKey] == overlayItem[userconfig.NameKey] {
+				mergeOverlay(baseItem, overlayItem)
+				found = true
+				break
+			}
+		}
+		if !found {
+			merged = append(merged, deepCopy(overlayItem))
+		}
+	}
+
+	return merged
+}
+
+func isNamedList(list []map[string]interface{}) bool {
+	if len(list) == 0 {
+		return false
+	}
+	for _, item := range list {
+		if _, ok := item[userconfig.NameKey].(string); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func deepCopy(value interface{}) interface{} {
+	switch typed := value.(type) {
+	case map[string]interface{}:
+		copied := make(map[string]interface{}, len(typed))
+		for key, item := range typed {
+			copied[key] = deepCopy(item)
+		}
+		return copied
+	case []interface{}:
+		copied := make([]interface{}, len(typed))
+		for i, item := range typed {
+			copied[i] = deepCopy(item)
+		}
+		return copied
+	}
+	return value
+}
@@ -0,0 +1,195 @@
+/*
+Copyright 2021 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spec
+
+import (
+	"os"
+	"testing"
+
+	"github.com/cortexlabs/yaml"
+	"github.com/stretchr/testify/require"
+)
+
+const _testValuesConfig = `
+- name: text-generator
+  kind: RealtimeAPI
+  pod:
+    containers:
+      - name: api
+        image: ${{ registry }}/text-generator:${{ tag }}
+        env:
+          STAGE: ${{ stage }}
+          LOG_LEVEL: info
+      - name: sidecar
+        image: sidecar:latest
+  autoscaling:
+    min_replicas: ${{ replicas.min }}
+    max_replicas: 10
+`
+
+func TestApplyValuesSubstitution(t *testing.T) {
+	values, err := ReadValuesBytes([]byte(`
+variables:
+  registry: docker.io/org
+  tag: v1
+  stage: dev
+  replicas:
+    min: 1
+`), "dev.yaml")
+	require.NoError(t, err)
+
+	configBytes, err := ApplyValues([]byte(_testValuesConfig), "cortex.yaml", values)
+	require.NoError(t, err)
+
+	apis := unmarshalTestAPIs(t, configBytes)
+	container := apis[0]["pod"].(map[interface{}]interface{})["containers"].([]interface{})[0].(map[interface{}]interface{})
+	require.Equal(t, "docker.io/org/text-generator:v1", container["image"])
+	require.Equal(t, "dev", container["env"].(map[interface{}]interface{})["STAGE"])
+	require.Equal(t, int64(1), apis[0]["autoscaling"].(map[interface{}]interface{})["min_replicas"])
+}
+
+func TestApplyValuesLaterFilesTakePrecedence(t *testing.T) {
+	base, err := ReadValuesBytes([]byte("variables: {registry: r, tag: v1, stage: dev, replicas: {min: 1}}"), "base.yaml")
+	require.NoError(t, err)
+	prod, err := ReadValuesBytes([]byte("variables: {stage: prod, replicas: {min: 3}}"), "prod.yaml")
+	require.NoError(t, err)
+
+	configBytes, err := ApplyValues([]byte(_testValuesConfig), "cortex.yaml", base, prod)
+	require.NoError(t, err)
+
+	apis := unmarshalTestAPIs(t, configBytes)
+	container := apis[0]["pod"].(map[interface{}]interface{})["containers"].([]interface{})[0].(map[interface{}]interface{})
+	require.Equal(t, "r/text-generator:v1", container["image"])
+	require.Equal(t, "prod", container["env"].(map[interface{}]interface{})["STAGE"])
+	require.Equal(t, int64(3), apis[0]["autoscaling"].(map[interface{}]interface{})["min_replicas"])
+}
+
+func TestApplyValuesOverlays(t *testing.T) {
+	values, err := ReadValuesBytes([]byte(`
+variables:
+  registry: r
+  tag: v1
+  stage: prod
+  replicas:
+    min: 2
+apis:
+  - name: text-generator
+    pod:
+      containers:
+        - name: api
+          env:
+            LOG_LEVEL: warning
+          compute:
+            gpu: 1
+        - name: metrics
+          image: metrics:latest
+    autoscaling:
+      max_replicas: 50
+`), "prod.yaml")
+	require.NoError(t, err)
+
+	configBytes, err := ApplyValues([]byte(_testValuesConfig), "cortex.yaml", values)
+	require.NoError(t, err)
+
+	apis := unmarshalTestAPIs(t, configBytes)
+	containers := apis[0]["pod"].(map[interface{}]interface{})["containers"].([]interface{})
+	require.Len(t, containers, 3)
+
+	api := containers[0].(map[interface{}]interface{})
+	require.Equal(t, "r/text-generator:v1", api["image"])
+	require.Equal(t, map[interface{}]interface{}{"STAGE": "prod", "LOG_LEVEL": "warning"}, api["env"])
+	require.Equal(t, map[interface{}]interface{}{"gpu": int64(1)}, api["compute"])
+	require.Equal(t, "sidecar", containers[1].(map[interface{}]interface{})["name"])
+	require.Equal(t, "metrics", containers[2].(map[interface{}]interface{})["name"])
+
+	autoscaling := apis[0]["autoscaling"].(map[interface{}]interface{})
+	require.Equal(t, int64(2), autoscaling["min_replicas"])
+	require.Equal(t, int64(50), autoscaling["max_replicas"])
+}
+
+func TestApplyValuesEnvVars(t *testing.T) {
+	os.Setenv("CORTEX_TEST_VALUES_TAG", "abc123")
+	defer os.Unsetenv("CORTEX_TEST_VALUES_TAG")
+
+	values, err := ReadValuesBytes([]byte("variables: {}"), "values.yaml")
+	require.NoError(t, err)
+
+	configBytes, err := ApplyValues([]byte("- name: a\n  image: img:${{ env.CORTEX_TEST_VALUES_TAG }}\n"), "cortex.yaml", values)
+	require.NoError(t, err)
+	require.Equal(t, "img:abc123", unmarshalTestAPIs(t, configBytes)[0]["image"])
+
+	_, err = ApplyValues([]byte("- name: a\n  image: img:${{ env.CORTEX_TEST_VALUES_UNSET }}\n"), "cortex.yaml", values)
+	require.Error(t, err)
+}
+
+func TestApplyValuesCantChangeStructure(t *testing.T) {
+	values, err := ReadValuesBytes([]byte(`
+variables:
+  registry: "r\nkind: TaskAPI"
+  tag: "v1 # comment"
+  stage: "dev: true"
+  replicas:
+    min: 1
+`), "values.yaml")
+	require.NoError(t, err)
+
+	// placeholders in comments don't need to be defined
+	configBytes, err := ApplyValues([]byte(_testValuesConfig+"# ${{ undefined }}\n"), "cortex.yaml", values)
+	require.NoError(t, err)
+
+	apis := unmarshalTestAPIs(t, configBytes)
+	require.Equal(t, "RealtimeAPI", apis[0]["kind"])
+	container := apis[0]["pod"].(map[interface{}]interface{})["containers"].([]interface{})[0].(map[interface{}]interface{})
+	require.Equal(t, "r\nkind: TaskAPI/text-generator:v1 # comment", container["image"])
+	require.Equal(t, "dev: true", container["env"].(map[interface{}]interface{})["STAGE"])
+}
+
+func TestApplyValuesDoesntModifyValues(t *testing.T) {
+	base, err := ReadValuesBytes([]byte("variables: {registry: r, tag: v1, stage: dev, replicas: {min: 1}}"), "base.yaml")
+	require.NoError(t, err)
+	prod, err := ReadValuesBytes([]byte("variables: {replicas: {min: 3}}"), "prod.yaml")
+	require.NoError(t, err)
+
+	_, err = ApplyValues([]byte(_testValuesConfig), "cortex.yaml", base, prod)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), base.Variables["replicas"].(map[string]interface{})["min"])
+}
+
+func TestApplyValuesErrors(t *testing.T) {
+	values, err := ReadValuesBytes([]byte("variables: {registry: r}"), "values.yaml")
+	require.NoError(t, err)
+	_, err = ApplyValues([]byte(_testValuesConfig), "cortex.yaml", values)
+	require.Error(t, err)
+
+	values, err = ReadValuesBytes([]byte("variables: {registry: r, tag: v1, stage: dev, replicas: {min: 1}}\napis: [{name: other}]"), "values.yaml")
+	require.NoError(t, err)
+	_, err = ApplyValues([]byte(_testValuesConfig), "cortex.yaml", values)
+	require.Error(t, err)
+
+	_, err = ReadValuesBytes([]byte("apis: [{kind: RealtimeAPI}]"), "values.yaml")
+	require.Error(t, err)
+
+	_, err = ReadValuesBytes([]byte("unknown: true"), "values.yaml")
+	require.Error(t, err)
+}
+
+func unmarshalTestAPIs(t *testing.T, configBytes []byte) []map[string]interface{} {
+	t.Helper()
+	var apis []map[string]interface{}
+	require.NoError(t, yaml.Unmarshal(configBytes, &apis))
+	return apis
+}
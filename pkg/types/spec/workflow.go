@@ -0,0 +1,135 @@
+/*
+Copyright 2021 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spec
+
+import (
+	"path"
+
+	"github.com/cortexlabs/cortex/pkg/consts"
+	"github.com/cortexlabs/cortex/pkg/lib/pointer"
+	"github.com/cortexlabs/cortex/pkg/lib/sets/strset"
+	s "github.com/cortexlabs/cortex/pkg/lib/strings"
+	"github.com/cortexlabs/cortex/pkg/types/userconfig"
+)
+
+// e.g. /<cluster UID>/workflows/<cortex version>/<workflow_name>/
+func WorkflowPrefix(clusterUID string, workflowName string) string {
+	return s.EnsureSuffix(path.Join(clusterUID, "workflows", consts.CortexVersion, workflowName), "/")
+}
+
+// e.g. /<cluster UID>/workflows/<cortex version>/<workflow_name>/runs/
+func WorkflowRunsPrefix(clusterUID string, workflowName string) string {
+	return path.Join(WorkflowPrefix(clusterUID, workflowName), "runs") + "/"
+}
+
+// e.g. /<cluster UID>/workflows/<cortex version>/<workflow_name>/runs/<run_id>.json
+func WorkflowRunKey(clusterUID string, workflowName string, runID string) string {
+	return path.Join(WorkflowRunsPrefix(clusterUID, workflowName), runID+".json")
+}
+
+// e.g. /<cluster UID>/workflows/<cortex version>/<workflow_name>/in_progress/
+func WorkflowInProgressPrefix(clusterUID string, workflowName string) string {
+	return path.Join(WorkflowPrefix(clusterUID, workflowName), "in_progress") + "/"
+}
+
+// WorkflowStepArtifactsPath is the s3 path under which a step of a workflow run is expected to write its artifacts,
+// e.g. s3://<bucket>/<cluster UID>/workflows/<cortex version>/<workflow_name>/artifacts/<run_id>/<step_name>/
+func WorkflowStepArtifactsPath(bucket string, clusterUID string, workflowName string, runID string, stepName string) string {
+	return "s3://" + path.Join(bucket, WorkflowPrefix(clusterUID, workflowName), "artifacts", runID, stepName) + "/"
+}
+
+func ValidateWorkflow(api *userconfig.API) error {
+	if api.Networking.Endpoint == nil {
+		api.Networking.Endpoint = pointer.String("/" + api.Name)
+	}
+
+	stepNames := strset.New()
+	for _, step := range api.Steps {
+		if stepNames.Has(step.Name) {
+			return ErrorDuplicateWorkflowStepName(step.Name)
+		}
+		stepNames.Add(step.Name)
+	}
+
+	for _, step := range api.Steps {
+		for _, dependency := range step.DependsOn {
+			if !stepNames.Has(dependency) {
+				return ErrorWorkflowStepNotFound(dependency)
+			}
+		}
+	}
+
+	if _, err := SortWorkflowSteps(api.Steps); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// SortWorkflowSteps orders the steps so that each step comes after the steps which it depends on; apart from that,
+// the order of the steps in the configuration is preserved
+func SortWorkflowSteps(steps []*userconfig.WorkflowStep) ([]*userconfig.WorkflowStep, error) {
+	indexes := make(map[string]int, len(steps))
+	for i, step := range steps {
+		indexes[step.Name] = i
+	}
+
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	states := make([]int, len(steps))
+	sorted := make([]*userconfig.WorkflowStep, 0, len(steps))
+
+	var visit func(i int, path []string) error
+	visit = func(i int, path []string) error {
+		switch states[i] {
+		case visited:
+			return nil
+		case visiting:
+			for start, name := range path {
+				if name == steps[i].Name {
+					path = path[start:]
+					break
+				}
+			}
+			return ErrorWorkflowStepCycle(append(path, steps[i].Name))
+		}
+
+		states[i] = visiting
+		for _, dependency := range steps[i].DependsOn {
+			if j, ok := indexes[dependency]; ok {
+				if err := visit(j, append(path, steps[i].Name)); err != nil {
+					return err
+				}
+			}
+		}
+		states[i] = visited
+
+		sorted = append(sorted, steps[i])
+		return nil
+	}
+
+	for i := range steps {
+		if err := visit(i, nil); err != nil {
+			return nil, err
+		}
+	}
+
+	return sorted, nil
+}
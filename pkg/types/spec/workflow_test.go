@@ -0,0 +1,72 @@
+/*
+Copyright 2021 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spec
+
+import (
+	"testing"
+
+	"github.com/cortexlabs/cortex/pkg/types/userconfig"
+	"github.com/stretchr/testify/require"
+)
+
+func testWorkflow(steps ...*userconfig.WorkflowStep) *userconfig.API {
+	return &userconfig.API{
+		Resource:   userconfig.Resource{Name: "workflow", Kind: userconfig.WorkflowKind},
+		Steps:      steps,
+		Networking: &userconfig.Networking{},
+	}
+}
+
+func testWorkflowStep(name string, dependsOn ...string) *userconfig.WorkflowStep {
+	return &userconfig.WorkflowStep{Name: name, API: "task", DependsOn: dependsOn}
+}
+
+func TestValidateWorkflow(t *testing.T) {
+	// fan-out from "extract", fan-in at "report"
+	api := testWorkflow(
+		testWorkflowStep("report", "train-a", "train-b"),
+		testWorkflowStep("train-a", "extract"),
+		testWorkflowStep("train-b", "extract"),
+		testWorkflowStep("extract"),
+	)
+	require.NoError(t, ValidateWorkflow(api))
+	require.Equal(t, "/workflow", *api.Networking.Endpoint)
+
+	sorted, err := SortWorkflowSteps(api.Steps)
+	require.NoError(t, err)
+	var names []string
+	for _, step := range sorted {
+		names = append(names, step.Name)
+	}
+	require.Equal(t, []string{"extract", "train-a", "train-b", "report"}, names)
+
+	err = ValidateWorkflow(testWorkflow(testWorkflowStep("a"), testWorkflowStep("a")))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "unique")
+
+	err = ValidateWorkflow(testWorkflow(testWorkflowStep("a", "missing")))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "missing")
+
+	err = ValidateWorkflow(testWorkflow(
+		testWorkflowStep("a", "c"),
+		testWorkflowStep("b", "a"),
+		testWorkflowStep("c", "b"),
+	))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "a -> c -> b -> a")
+}
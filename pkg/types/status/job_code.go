@@ -85,6 +85,13 @@ func (code JobCode) IsCompleted() bool {
 		code == JobStopped || code == JobTimedOut
 }
 
+// IsFailed returns true if the job completed unsuccessfully (stopped jobs are not considered to have failed)
+func (code JobCode) IsFailed() bool {
+	return code == JobEnqueueFailed || code == JobCompletedWithFailures ||
+		code == JobUnexpectedError || code == JobWorkerError ||
+		code == JobWorkerOOM || code == JobTimedOut
+}
+
 func (code JobCode) String() string {
 	if int(code) < 0 || int(code) >= len(_jobCodes) {
 		return _jobCodes[JobUnknown]
@@ -0,0 +1,64 @@
+/*
+Copyright 2021 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package status
+
+import (
+	"time"
+
+	"github.com/cortexlabs/cortex/pkg/types/spec"
+)
+
+// WorkerBatchResults summarizes the batches which were processed by one worker of a batch job
+type WorkerBatchResults struct {
+	Worker         string    `json:"worker"`
+	Succeeded      int       `json:"succeeded"`
+	Failed         int       `json:"failed"`
+	ProcessingTime float64   `json:"processing_time"` // seconds spent processing batches which succeeded
+	StartTime      time.Time `json:"start_time"`
+	LastUpdated    time.Time `json:"last_updated"`
+}
+
+// FailedBatch describes a batch which a worker of a batch job failed to process
+type FailedBatch struct {
+	BatchID     string    `json:"batch_id"`
+	Worker      string    `json:"worker"`
+	Error       string    `json:"error"`
+	FailedAt    time.Time `json:"failed_at"`
+	PayloadPath string    `json:"payload_path,omitempty"` // the s3 path of the batch's payload
+}
+
+type BatchJobResults struct {
+	spec.JobKey
+	Status            JobCode              `json:"status"`
+	StartTime         time.Time            `json:"start_time"`
+	EndTime           *time.Time           `json:"end_time,omitempty"`
+	Succeeded         int                  `json:"succeeded"`
+	Failed            int                  `json:"failed"`
+	Workers           []WorkerBatchResults `json:"workers"`
+	ErrorSamples      []FailedBatch        `json:"error_samples"`
+	FailedBatches     []string             `json:"failed_batches"`      // the s3 paths of the payloads of the failed batches (capped)
+	FailedBatchesPath string               `json:"failed_batches_path"` // the s3 path under which all failed batches are stored
+}
+
+// Duration returns the time between the job's submission and its completion
+func (results BatchJobResults) Duration() *time.Duration {
+	if results.EndTime == nil {
+		return nil
+	}
+	duration := results.EndTime.Sub(results.StartTime)
+	return &duration
+}
@@ -0,0 +1,54 @@
+/*
+Copyright 2021 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package status
+
+import (
+	"time"
+)
+
+// WorkflowStepStatus is the status of one step of a workflow run; steps which can no longer run because a step which they depend on
+// did not succeed are marked as stopped
+type WorkflowStepStatus struct {
+	Name          string     `json:"name"`
+	API           string     `json:"api"`
+	DependsOn     []string   `json:"depends_on"`
+	JobID         string     `json:"job_id,omitempty"` // set once the step's task job has been submitted
+	Status        JobCode    `json:"status"`
+	ArtifactsPath string     `json:"artifacts_path"`
+	StartTime     *time.Time `json:"start_time,omitempty"`
+	EndTime       *time.Time `json:"end_time,omitempty"`
+}
+
+type WorkflowRun struct {
+	ID           string                 `json:"run_id"`
+	WorkflowName string                 `json:"workflow_name"`
+	WorkflowID   string                 `json:"workflow_id"` // the id of the workflow's spec when the run was submitted
+	Status       JobCode                `json:"status"`
+	Config       map[string]interface{} `json:"config,omitempty"` // passed to all of the steps (the steps' own config takes precedence)
+	StartTime    time.Time              `json:"start_time"`
+	EndTime      *time.Time             `json:"end_time,omitempty"`
+	Steps        []WorkflowStepStatus   `json:"steps"`
+}
+
+func (run *WorkflowRun) GetStep(stepName string) *WorkflowStepStatus {
+	for i := range run.Steps {
+		if run.Steps[i].Name == stepName {
+			return &run.Steps[i]
+		}
+	}
+	return nil
+}
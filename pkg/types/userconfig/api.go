@@ -18,10 +18,13 @@ package userconfig
 
 import (
 	"fmt"
+	"math"
+	"sort"
 	"strings"
 	"time"
 
 	"github.com/cortexlabs/cortex/pkg/lib/k8s"
+	"github.com/cortexlabs/cortex/pkg/lib/maps"
 	"github.com/cortexlabs/cortex/pkg/lib/pointer"
 	"github.com/cortexlabs/cortex/pkg/lib/sets/strset"
 	s "github.com/cortexlabs/cortex/pkg/lib/strings"
@@ -33,46 +36,190 @@ import (
 type API struct {
 	Resource
 
-	Pod              *Pod            `json:"pod" yaml:"pod"`
-	NodeGroups       []string        `json:"node_groups" yaml:"node_groups"`
-	APIs             []*TrafficSplit `json:"apis" yaml:"apis"`
-	Networking       *Networking     `json:"networking" yaml:"networking"`
-	Autoscaling      *Autoscaling    `json:"autoscaling" yaml:"autoscaling"`
-	UpdateStrategy   *UpdateStrategy `json:"update_strategy" yaml:"update_strategy"`
-	Index            int             `json:"index" yaml:"-"`
-	FileName         string          `json:"file_name" yaml:"-"`
-	SubmittedAPISpec interface{}     `json:"submitted_api_spec" yaml:"submitted_api_spec"`
+	Project          *string           `json:"project" yaml:"project"`
+	Labels           map[string]string `json:"labels" yaml:"labels"`
+	Pod              *Pod              `json:"pod" yaml:"pod"`
+	NodeGroups       []string          `json:"node_groups" yaml:"node_groups"`
+	Arch             string            `json:"arch" yaml:"arch"`
+	OS               string            `json:"os" yaml:"os"`
+	APIs             []*TrafficSplit   `json:"apis" yaml:"apis"`
+	SessionAffinity  *SessionAffinity  `json:"session_affinity" yaml:"session_affinity"`
+	Steps            []*WorkflowStep   `json:"steps" yaml:"steps"`
+	Networking       *Networking       `json:"networking" yaml:"networking"`
+	Autoscaling      *Autoscaling      `json:"autoscaling" yaml:"autoscaling"`
+	UpdateStrategy   *UpdateStrategy   `json:"update_strategy" yaml:"update_strategy"`
+	Availability     *Availability     `json:"availability" yaml:"availability"`
+	Alerting         *Alerting         `json:"alerting" yaml:"alerting"`
+	SLO              *SLO              `json:"slo" yaml:"slo"`
+	PayloadLogging   *PayloadLogging   `json:"payload_logging" yaml:"payload_logging"`
+	Proxy            *Proxy            `json:"proxy" yaml:"proxy"`
+	Retries          *Retries          `json:"retries" yaml:"retries"`
+	Models           *Models           `json:"models" yaml:"models"`
+	Gateway          *AsyncGateway     `json:"gateway" yaml:"gateway"`
+	Expiration       *time.Duration    `json:"expiration" yaml:"expiration"` // queued async workloads which are older than this are not processed
+	Logging          *Logging          `json:"logging" yaml:"logging"`
+	Hooks            *Hooks            `json:"hooks" yaml:"hooks"`
+	DependsOn        []string          `json:"depends_on" yaml:"depends_on"`
+	Index            int               `json:"index" yaml:"-"`
+	FileName         string            `json:"file_name" yaml:"-"`
+	SubmittedAPISpec interface{}       `json:"submitted_api_spec" yaml:"submitted_api_spec"`
 }
 
 type Pod struct {
-	Port           *int32       `json:"port" yaml:"port"`
-	MaxQueueLength int64        `json:"max_queue_length" yaml:"max_queue_length"`
-	MaxConcurrency int64        `json:"max_concurrency" yaml:"max_concurrency"`
-	Containers     []*Container `json:"containers" yaml:"containers"`
+	Port           *int32           `json:"port" yaml:"port"`
+	MaxQueueLength int64            `json:"max_queue_length" yaml:"max_queue_length"`
+	MaxConcurrency int64            `json:"max_concurrency" yaml:"max_concurrency"`
+	InitContainers []*InitContainer `json:"init_containers" yaml:"init_containers"`
+	Containers     []*Container     `json:"containers" yaml:"containers"`
+	ModelCache     *ModelCache      `json:"model_cache" yaml:"model_cache"`
+	EFS            *EFSMount        `json:"efs" yaml:"efs"`
+	Secrets        []*Secret        `json:"secrets" yaml:"secrets"`
+	Config         *RuntimeConfig   `json:"config" yaml:"config"`
+
+	RegistryCredentials []*RegistryCredentials `json:"registry_credentials" yaml:"registry_credentials"`
 }
 
-type Container struct {
-	Name  string            `json:"name" yaml:"name"`
-	Image string            `json:"image" yaml:"image"`
+// RuntimeConfig is configuration which can be changed without rebuilding the API's images
+type RuntimeConfig struct {
+	Files map[string]string `json:"files" yaml:"files"`
 	Env   map[string]string `json:"env" yaml:"env"`
+}
+
+type ModelCache struct {
+	Path          string         `json:"path" yaml:"path"`
+	ModelRegistry *ModelRegistry `json:"model_registry" yaml:"model_registry"`
+	Watch         bool           `json:"watch" yaml:"watch"` // redeploy the api when the artifacts change
+	Integrity     *Integrity     `json:"integrity" yaml:"integrity"`
+
+	ArtifactVersion string `json:"artifact_version" yaml:"-"` // set by the operator if watch is enabled for an s3 path
+}
+
+// Integrity is the expected checksum and/or signature of a model's artifacts, which are verified after they are downloaded
+// (the checksum and signature are of the manifest which lists the sha256 checksum of each of the artifacts' files)
+type Integrity struct {
+	SHA256    *string `json:"sha256" yaml:"sha256"`
+	Signature *string `json:"signature" yaml:"signature"`   // base64-encoded
+	PublicKey *string `json:"public_key" yaml:"public_key"` // PEM-encoded
+}
+
+const (
+	MLflowModelRegistry    = "mlflow"
+	SageMakerModelRegistry = "sagemaker"
+)
+
+// ModelRegistry references a version of a model in a model registry; the operator resolves it to the S3 path of the version's artifacts
+// (which is used as the path of the model) when the API is deployed
+type ModelRegistry struct {
+	Provider    string  `json:"provider" yaml:"provider"`
+	Name        string  `json:"name" yaml:"name"`                 // the registered model (mlflow) or the model package group (sagemaker)
+	Stage       *string `json:"stage" yaml:"stage"`               // the stage (mlflow) or approval status (sagemaker) whose latest version is used
+	Version     *string `json:"version" yaml:"version"`           // a specific version, instead of the latest version in the stage
+	TrackingURI *string `json:"tracking_uri" yaml:"tracking_uri"` // mlflow only
+	TokenSecret *string `json:"token_secret" yaml:"token_secret"` // mlflow only; the Secrets Manager secret which contains the tracking server's token
+
+	ResolvedVersion string `json:"resolved_version" yaml:"-"` // set by the operator, for lineage
+}
+
+type EFSMount struct {
+	Path      string `json:"path" yaml:"path"`
+	MountPath string `json:"mount_path" yaml:"mount_path"`
+	ReadOnly  bool   `json:"read_only" yaml:"read_only"`
+}
+
+type Container struct {
+	Name        string            `json:"name" yaml:"name"`
+	Image       string            `json:"image" yaml:"image"`
+	ImageDigest *string           `json:"image_digest" yaml:"image_digest"` // the image's expected digest (sha256:...), which is verified against the registry
+	Env         map[string]string `json:"env" yaml:"env"`
 
 	Command []string `json:"command" yaml:"command"`
 	Args    []string `json:"args" yaml:"args"`
 
 	ReadinessProbe *Probe `json:"readiness_probe" yaml:"readiness_probe"`
 	LivenessProbe  *Probe `json:"liveness_probe" yaml:"liveness_probe"`
+	StartupProbe   *Probe `json:"startup_probe" yaml:"startup_probe"`
+
+	Compute             *Compute             `json:"compute" yaml:"compute"`
+	VerticalAutoscaling *VerticalAutoscaling `json:"vertical_autoscaling" yaml:"vertical_autoscaling"`
+}
+
+// VerticalAutoscaling bounds the cpu and memory requests which the operator sets for a container based on its usage
+type VerticalAutoscaling struct {
+	MinCPU *k8s.Quantity `json:"min_cpu" yaml:"min_cpu"`
+	MaxCPU *k8s.Quantity `json:"max_cpu" yaml:"max_cpu"`
+	MinMem *k8s.Quantity `json:"min_mem" yaml:"min_mem"`
+	MaxMem *k8s.Quantity `json:"max_mem" yaml:"max_mem"`
+}
+
+// Secret is resolved by the operator from AWS Secrets Manager or SSM Parameter Store,
+// and is exposed to all of the pod's containers as an environment variable
+type Secret struct {
+	Name           string  `json:"name" yaml:"name"`
+	SecretsManager *string `json:"secrets_manager" yaml:"secrets_manager"`
+	SSMParameter   *string `json:"ssm_parameter" yaml:"ssm_parameter"`
+	Key            *string `json:"key" yaml:"key"`
+}
+
+// RegistryCredentials are used to pull images from a private docker registry; they are resolved by the operator
+// and attached to the API's pods as an image pull secret
+type RegistryCredentials struct {
+	Registry       string  `json:"registry" yaml:"registry"`               // e.g. ghcr.io, docker.io, or 123456789012.dkr.ecr.us-west-2.amazonaws.com
+	SecretsManager *string `json:"secrets_manager" yaml:"secrets_manager"` // a secret with "username" and "password" keys; ECR registries are accessed with the cluster's AWS credentials instead
+}
+
+type InitContainer struct {
+	Name        string            `json:"name" yaml:"name"`
+	Image       string            `json:"image" yaml:"image"`
+	ImageDigest *string           `json:"image_digest" yaml:"image_digest"`
+	Env         map[string]string `json:"env" yaml:"env"`
+
+	Command []string `json:"command" yaml:"command"`
+	Args    []string `json:"args" yaml:"args"`
 
 	Compute *Compute `json:"compute" yaml:"compute"`
 }
 
+// SessionAffinity pins clients of a traffic splitter to the api which served their first request, so that
+// they consistently hit the same api (e.g. for the duration of an experiment)
+type SessionAffinity struct {
+	Cookie *string       `json:"cookie" yaml:"cookie"` // the cookie which records the chosen api (it is set on responses)
+	Header *string       `json:"header" yaml:"header"` // the request header which clients can set to the chosen api
+	TTL    time.Duration `json:"ttl" yaml:"ttl"`       // the lifetime of the cookie
+}
+
 type TrafficSplit struct {
 	Name   string `json:"name" yaml:"name"`
 	Weight int32  `json:"weight" yaml:"weight"`
 	Shadow bool   `json:"shadow" yaml:"shadow"`
 }
 
+// WorkflowStep is a task job which is submitted to a Task API as part of a workflow run, once all of the steps which it depends on have succeeded
+type WorkflowStep struct {
+	Name      string                 `json:"name" yaml:"name"`
+	API       string                 `json:"api" yaml:"api"`
+	DependsOn []string               `json:"depends_on" yaml:"depends_on"`
+	Config    map[string]interface{} `json:"config" yaml:"config"`
+}
+
 type Networking struct {
-	Endpoint *string `json:"endpoint" yaml:"endpoint"`
+	Endpoint      *string             `json:"endpoint" yaml:"endpoint"`
+	CIDRWhiteList []string            `json:"cidr_white_list" yaml:"cidr_white_list"` // if set, requests to the endpoint which don't originate from these CIDR blocks are denied by the API load balancer
+	AccessLogs    *AccessLogs         `json:"access_logs" yaml:"access_logs"`         // if set, the apis' istio gateway logs a sample of the requests to the endpoint
+	Ingress       *NetworkPolicyRules `json:"ingress" yaml:"ingress"`
+	Egress        *NetworkPolicyRules `json:"egress" yaml:"egress"`
+}
+
+// AccessLogs configures the access logs which the apis' istio gateway writes (as JSON lines) for the requests to an api's endpoint
+type AccessLogs struct {
+	SampleRate float64 `json:"sample_rate" yaml:"sample_rate"` // the fraction of requests which are logged
+}
+
+// NetworkPolicyRules lists the sources (for ingress) or destinations (for egress) which are allowed to reach/be reached by an api's pods;
+// when set, all other traffic in that direction is denied (except for the traffic which is required by cortex)
+type NetworkPolicyRules struct {
+	CIDRs []string `json:"cidrs" yaml:"cidrs"`
+	Hosts []string `json:"hosts" yaml:"hosts"` // only supported for egress
+	APIs  []string `json:"apis" yaml:"apis"`
 }
 
 type Probe struct {
@@ -102,23 +249,26 @@ type ExecProbe struct {
 type Compute struct {
 	CPU *k8s.Quantity `json:"cpu" yaml:"cpu"`
 	Mem *k8s.Quantity `json:"mem" yaml:"mem"`
-	GPU int64         `json:"gpu" yaml:"gpu"`
+	GPU float64       `json:"gpu" yaml:"gpu"` // a fraction of a GPU can be requested from node groups which share their GPUs
 	Inf int64         `json:"inf" yaml:"inf"`
 	Shm *k8s.Quantity `json:"shm" yaml:"shm"`
 }
 
 type Autoscaling struct {
-	MinReplicas                  int32         `json:"min_replicas" yaml:"min_replicas"`
-	MaxReplicas                  int32         `json:"max_replicas" yaml:"max_replicas"`
-	InitReplicas                 int32         `json:"init_replicas" yaml:"init_replicas"`
-	TargetInFlight               *float64      `json:"target_in_flight" yaml:"target_in_flight"`
-	Window                       time.Duration `json:"window" yaml:"window"`
-	DownscaleStabilizationPeriod time.Duration `json:"downscale_stabilization_period" yaml:"downscale_stabilization_period"`
-	UpscaleStabilizationPeriod   time.Duration `json:"upscale_stabilization_period" yaml:"upscale_stabilization_period"`
-	MaxDownscaleFactor           float64       `json:"max_downscale_factor" yaml:"max_downscale_factor"`
-	MaxUpscaleFactor             float64       `json:"max_upscale_factor" yaml:"max_upscale_factor"`
-	DownscaleTolerance           float64       `json:"downscale_tolerance" yaml:"downscale_tolerance"`
-	UpscaleTolerance             float64       `json:"upscale_tolerance" yaml:"upscale_tolerance"`
+	MinReplicas                  int32          `json:"min_replicas" yaml:"min_replicas"`
+	MaxReplicas                  int32          `json:"max_replicas" yaml:"max_replicas"`
+	InitReplicas                 int32          `json:"init_replicas" yaml:"init_replicas"`
+	TargetInFlight               *float64       `json:"target_in_flight" yaml:"target_in_flight"`
+	Window                       time.Duration  `json:"window" yaml:"window"`
+	DownscaleStabilizationPeriod time.Duration  `json:"downscale_stabilization_period" yaml:"downscale_stabilization_period"`
+	UpscaleStabilizationPeriod   time.Duration  `json:"upscale_stabilization_period" yaml:"upscale_stabilization_period"`
+	MaxDownscaleFactor           float64        `json:"max_downscale_factor" yaml:"max_downscale_factor"`
+	MaxUpscaleFactor             float64        `json:"max_upscale_factor" yaml:"max_upscale_factor"`
+	DownscaleTolerance           float64        `json:"downscale_tolerance" yaml:"downscale_tolerance"`
+	UpscaleTolerance             float64        `json:"upscale_tolerance" yaml:"upscale_tolerance"`
+	TargetQueueLengthPerReplica  *float64       `json:"target_queue_length_per_replica" yaml:"target_queue_length_per_replica"`
+	MaxQueueLatency              *time.Duration `json:"max_queue_latency" yaml:"max_queue_latency"`
+	WarmReplicas                 int32          `json:"warm_replicas" yaml:"warm_replicas"`
 }
 
 type UpdateStrategy struct {
@@ -126,6 +276,135 @@ type UpdateStrategy struct {
 	MaxUnavailable string `json:"max_unavailable" yaml:"max_unavailable"`
 }
 
+type Availability struct {
+	MaxDisruptedReplicas *string        `json:"max_disrupted_replicas" yaml:"max_disrupted_replicas"`
+	ZoneSpread           TopologySpread `json:"zone_spread" yaml:"zone_spread"`
+	NodeSpread           TopologySpread `json:"node_spread" yaml:"node_spread"`
+}
+
+// Alerting overrides the cluster's default alert rules for a single API (nil fields inherit the cluster defaults)
+type Alerting struct {
+	Disabled              bool           `json:"disabled" yaml:"disabled"`
+	Receiver              *string        `json:"receiver" yaml:"receiver"`
+	ErrorRate             *float64       `json:"error_rate" yaml:"error_rate"`
+	PendingReplicasPeriod *time.Duration `json:"pending_replicas_period" yaml:"pending_replicas_period"`
+	QueueAge              *time.Duration `json:"queue_age" yaml:"queue_age"`
+}
+
+type SLO struct {
+	Window       time.Duration    `json:"window" yaml:"window"`
+	Availability *float64         `json:"availability" yaml:"availability"` // percentage of requests which must not return 5xx status codes
+	Latency      []*LatencyTarget `json:"latency" yaml:"latency"`
+}
+
+type LatencyTarget struct {
+	Percentile float64       `json:"percentile" yaml:"percentile"`
+	Threshold  time.Duration `json:"threshold" yaml:"threshold"`
+}
+
+// PayloadLogging configures the proxy to write a sample of the API's requests and responses to S3
+type PayloadLogging struct {
+	S3Path        string        `json:"s3_path" yaml:"s3_path"`
+	SampleRate    float64       `json:"sample_rate" yaml:"sample_rate"`     // the fraction of requests which are logged
+	MaxBodySize   int64         `json:"max_body_size" yaml:"max_body_size"` // in bytes; larger request and response bodies are truncated
+	FlushInterval time.Duration `json:"flush_interval" yaml:"flush_interval"`
+}
+
+// Proxy configures the limits which the proxy container applies to the API's requests (nil fields are unlimited)
+type Proxy struct {
+	UpstreamTimeout      *time.Duration `json:"upstream_timeout" yaml:"upstream_timeout"`             // requests which the container doesn't finish responding to within this duration are aborted
+	IdleTimeout          *time.Duration `json:"idle_timeout" yaml:"idle_timeout"`                     // requests are aborted if the container doesn't send any part of its response for this duration
+	MaxRequestBodySize   *int64         `json:"max_request_body_size" yaml:"max_request_body_size"`   // in bytes
+	MaxConcurrentStreams *int32         `json:"max_concurrent_streams" yaml:"max_concurrent_streams"` // per HTTP/2 connection; HTTP/2 is only enabled if this is set
+	FairQueuing          *FairQueuing   `json:"fair_queuing" yaml:"fair_queuing"`
+}
+
+// FairQueuing shares the API's concurrency between clients (identified by a request header) in proportion to their weights while requests are queued
+type FairQueuing struct {
+	Header        string          `json:"header" yaml:"header"`
+	DefaultWeight int32           `json:"default_weight" yaml:"default_weight"` // the weight of clients which aren't listed (and of requests without the header)
+	Clients       []*ClientWeight `json:"clients" yaml:"clients"`
+}
+
+type ClientWeight struct {
+	Client string `json:"client" yaml:"client"` // the value of the header
+	Weight int32  `json:"weight" yaml:"weight"`
+}
+
+// Retries configures how the API's load balancer retries failed requests on other replicas, and whether it hedges slow requests
+type Retries struct {
+	Attempts      int32          `json:"attempts" yaml:"attempts"` // the max number of retries (including hedged attempts)
+	RetryOn       []string       `json:"retry_on" yaml:"retry_on"` // envoy retry conditions (e.g. connect-failure) or status codes
+	PerTryTimeout *time.Duration `json:"per_try_timeout" yaml:"per_try_timeout"`
+	Hedging       *Hedging       `json:"hedging" yaml:"hedging"`
+}
+
+// Hedging sends another attempt of a request to a different replica if the first attempt hasn't responded after the delay, and uses the first response
+type Hedging struct {
+	Delay *time.Duration `json:"delay" yaml:"delay"` // if nil, the API's p95 latency is used
+}
+
+// Models configures the model manager sidecar, which downloads the API's models when they are requested
+// (and deletes the least recently used models), so that many models can be served by one API
+type Models struct {
+	Paths           []*ModelPath `json:"paths" yaml:"paths"`
+	MaxLoadedModels int32        `json:"max_loaded_models" yaml:"max_loaded_models"`
+	UnloadPath      *string      `json:"unload_path" yaml:"unload_path"` // the container is notified at this path before a model's files are deleted
+}
+
+type ModelPath struct {
+	Name          string         `json:"name" yaml:"name"`
+	Path          string         `json:"path" yaml:"path"`
+	ModelRegistry *ModelRegistry `json:"model_registry" yaml:"model_registry"`
+	Watch         bool           `json:"watch" yaml:"watch"` // redeploy the api when the model's files change
+	Integrity     *Integrity     `json:"integrity" yaml:"integrity"`
+
+	ArtifactVersion string `json:"artifact_version" yaml:"-"` // set by the operator if watch is enabled for an s3 path
+}
+
+// AsyncGateway configures the autoscaling and load-shedding of an AsyncAPI's gateway
+type AsyncGateway struct {
+	TargetSubmissionRate float64       `json:"target_submission_rate" yaml:"target_submission_rate"` // submissions per second per gateway replica
+	MaxWriteLatency      time.Duration `json:"max_write_latency" yaml:"max_write_latency"`           // submissions are rejected while the s3/sqs write latency exceeds this
+	RetryAfter           time.Duration `json:"retry_after" yaml:"retry_after"`
+}
+
+// Logging configures the logs of the cortex containers of the API (e.g. the proxy, gateway, and dequeuer);
+// unset fields default to the cluster's logging configuration
+type Logging struct {
+	Level  *LogLevel  `json:"level" yaml:"level"`
+	Format *LogFormat `json:"format" yaml:"format"`
+}
+
+// Hooks are run by the operator whenever the API is rolled out (i.e. when it is created, updated, or refreshed)
+type Hooks struct {
+	PreDeploy  *Hook `json:"pre_deploy" yaml:"pre_deploy"`   // runs before the new replicas are created; the rollout is aborted if the hook fails
+	PostDeploy *Hook `json:"post_deploy" yaml:"post_deploy"` // runs once all of the new replicas are ready
+}
+
+// Hook is either an HTTP request or a job which runs a container to completion
+type Hook struct {
+	HTTP    *HTTPHook     `json:"http" yaml:"http"`
+	Job     *JobHook      `json:"job" yaml:"job"`
+	Timeout time.Duration `json:"timeout" yaml:"timeout"`
+}
+
+// HTTPHook succeeds if the request returns a 2XX status code
+type HTTPHook struct {
+	URL     string            `json:"url" yaml:"url"`
+	Method  string            `json:"method" yaml:"method"`
+	Headers map[string]string `json:"headers" yaml:"headers"`
+	Body    *string           `json:"body" yaml:"body"`
+}
+
+// JobHook succeeds if the container exits with status code 0
+type JobHook struct {
+	Image   string            `json:"image" yaml:"image"`
+	Env     map[string]string `json:"env" yaml:"env"`
+	Command []string          `json:"command" yaml:"command"`
+	Args    []string          `json:"args" yaml:"args"`
+}
+
 func (api *API) Identify() string {
 	return IdentifyAPI(api.FileName, api.Name, api.Kind, api.Index)
 }
@@ -149,6 +428,36 @@ func IdentifyAPI(filePath string, name string, kind Kind, index int) string {
 	return str + "resource"
 }
 
+// ModelVersions returns the model registry versions which the api's models were resolved to (or the artifact versions of watched s3 paths),
+// keyed by model name (the model cache's key is "model_cache"), or nil if the api doesn't have any versioned models
+func (api *API) ModelVersions() map[string]string {
+	var versions map[string]string
+	addVersion := func(modelName string, modelRegistry *ModelRegistry, artifactVersion string) {
+		version := artifactVersion
+		if modelRegistry != nil {
+			version = modelRegistry.ResolvedVersion
+		}
+		if version == "" {
+			return
+		}
+		if versions == nil {
+			versions = map[string]string{}
+		}
+		versions[modelName] = version
+	}
+
+	if api.Pod != nil && api.Pod.ModelCache != nil {
+		addVersion(ModelCacheKey, api.Pod.ModelCache.ModelRegistry, api.Pod.ModelCache.ArtifactVersion)
+	}
+	if api.Models != nil {
+		for _, modelPath := range api.Models.Paths {
+			addVersion(modelPath.Name, modelPath.ModelRegistry, modelPath.ArtifactVersion)
+		}
+	}
+
+	return versions
+}
+
 // InitReplicas was left out deliberately
 func (api *API) ToK8sAnnotations() map[string]string {
 	annotations := map[string]string{}
@@ -173,6 +482,19 @@ func (api *API) ToK8sAnnotations() map[string]string {
 		annotations[MaxUpscaleFactorAnnotationKey] = s.Float64(api.Autoscaling.MaxUpscaleFactor)
 		annotations[DownscaleToleranceAnnotationKey] = s.Float64(api.Autoscaling.DownscaleTolerance)
 		annotations[UpscaleToleranceAnnotationKey] = s.Float64(api.Autoscaling.UpscaleTolerance)
+		if api.Autoscaling.TargetQueueLengthPerReplica != nil {
+			annotations[TargetQueueLengthPerReplicaAnnotationKey] = s.Float64(*api.Autoscaling.TargetQueueLengthPerReplica)
+		}
+		if api.Autoscaling.MaxQueueLatency != nil {
+			annotations[MaxQueueLatencyAnnotationKey] = api.Autoscaling.MaxQueueLatency.String()
+		}
+		if api.Autoscaling.WarmReplicas > 0 {
+			annotations[WarmReplicasAnnotationKey] = s.Int32(api.Autoscaling.WarmReplicas)
+		}
+	}
+
+	if api.Hooks != nil && api.Hooks.PostDeploy != nil {
+		annotations[PostDeployHookAnnotationKey] = "true"
 	}
 	return annotations
 }
@@ -240,6 +562,31 @@ func AutoscalingFromAnnotations(k8sObj kmeta.Object) (*Autoscaling, error) {
 	}
 	a.UpscaleTolerance = upscaleTolerance
 
+	// the queue-based targets are optional
+	if _, ok := k8sObj.GetAnnotations()[TargetQueueLengthPerReplicaAnnotationKey]; ok {
+		targetQueueLengthPerReplica, err := k8s.ParseFloat64Annotation(k8sObj, TargetQueueLengthPerReplicaAnnotationKey)
+		if err != nil {
+			return nil, err
+		}
+		a.TargetQueueLengthPerReplica = pointer.Float64(targetQueueLengthPerReplica)
+	}
+
+	if _, ok := k8sObj.GetAnnotations()[MaxQueueLatencyAnnotationKey]; ok {
+		maxQueueLatency, err := k8s.ParseDurationAnnotation(k8sObj, MaxQueueLatencyAnnotationKey)
+		if err != nil {
+			return nil, err
+		}
+		a.MaxQueueLatency = &maxQueueLatency
+	}
+
+	if _, ok := k8sObj.GetAnnotations()[WarmReplicasAnnotationKey]; ok {
+		warmReplicas, err := k8s.ParseInt32Annotation(k8sObj, WarmReplicasAnnotationKey)
+		if err != nil {
+			return nil, err
+		}
+		a.WarmReplicas = warmReplicas
+	}
+
 	return &a, nil
 }
 
@@ -247,12 +594,31 @@ func (api *API) UserStr() string {
 	var sb strings.Builder
 	sb.WriteString(fmt.Sprintf("%s: %s\n", NameKey, api.Name))
 	sb.WriteString(fmt.Sprintf("%s: %s\n", KindKey, api.Kind.String()))
+	if api.Project != nil {
+		sb.WriteString(fmt.Sprintf("%s: %s\n", ProjectKey, *api.Project))
+	}
+	if len(api.Labels) > 0 {
+		sb.WriteString(fmt.Sprintf("%s:\n", LabelsKey))
+		d, _ := yaml.Marshal(&api.Labels)
+		sb.WriteString(s.Indent(string(d), "  "))
+	}
 
 	if api.Kind == TrafficSplitterKind {
 		sb.WriteString(fmt.Sprintf("%s:\n", APIsKey))
 		for _, api := range api.APIs {
 			sb.WriteString(s.Indent(api.UserStr(), "  "))
 		}
+		if api.SessionAffinity != nil {
+			sb.WriteString(fmt.Sprintf("%s:\n", SessionAffinityKey))
+			sb.WriteString(s.Indent(api.SessionAffinity.UserStr(), "  "))
+		}
+	}
+
+	if api.Kind == WorkflowKind {
+		sb.WriteString(fmt.Sprintf("%s:\n", StepsKey))
+		for _, step := range api.Steps {
+			sb.WriteString(s.Indent(step.UserStr(), "  "))
+		}
 	}
 
 	if api.Pod != nil {
@@ -276,11 +642,92 @@ func (api *API) UserStr() string {
 		sb.WriteString(fmt.Sprintf("%s: %s\n", NodeGroupsKey, s.ObjFlatNoQuotes(api.NodeGroups)))
 	}
 
+	if api.Arch != "" {
+		sb.WriteString(fmt.Sprintf("%s: %s\n", ArchKey, api.Arch))
+	}
+
+	if api.OS != "" {
+		sb.WriteString(fmt.Sprintf("%s: %s\n", OSKey, api.OS))
+	}
+
 	if api.UpdateStrategy != nil {
 		sb.WriteString(fmt.Sprintf("%s:\n", UpdateStrategyKey))
 		sb.WriteString(s.Indent(api.UpdateStrategy.UserStr(), "  "))
 	}
 
+	if api.Availability != nil {
+		sb.WriteString(fmt.Sprintf("%s:\n", AvailabilityKey))
+		sb.WriteString(s.Indent(api.Availability.UserStr(), "  "))
+	}
+
+	if api.Alerting != nil {
+		sb.WriteString(fmt.Sprintf("%s:\n", AlertingKey))
+		sb.WriteString(s.Indent(api.Alerting.UserStr(api.Kind), "  "))
+	}
+
+	if api.SLO != nil {
+		sb.WriteString(fmt.Sprintf("%s:\n", SLOKey))
+		sb.WriteString(s.Indent(api.SLO.UserStr(), "  "))
+	}
+
+	if api.PayloadLogging != nil {
+		sb.WriteString(fmt.Sprintf("%s:\n", PayloadLoggingKey))
+		sb.WriteString(s.Indent(api.PayloadLogging.UserStr(), "  "))
+	}
+
+	if api.Proxy != nil {
+		sb.WriteString(fmt.Sprintf("%s:\n", ProxyKey))
+		sb.WriteString(s.Indent(api.Proxy.UserStr(), "  "))
+	}
+
+	if api.Retries != nil {
+		sb.WriteString(fmt.Sprintf("%s:\n", RetriesKey))
+		sb.WriteString(s.Indent(api.Retries.UserStr(), "  "))
+	}
+
+	if api.Models != nil {
+		sb.WriteString(fmt.Sprintf("%s:\n", ModelsKey))
+		sb.WriteString(s.Indent(api.Models.UserStr(), "  "))
+	}
+
+	if api.Gateway != nil {
+		sb.WriteString(fmt.Sprintf("%s:\n", GatewayKey))
+		sb.WriteString(s.Indent(api.Gateway.UserStr(), "  "))
+	}
+
+	if api.Expiration != nil {
+		sb.WriteString(fmt.Sprintf("%s: %s\n", ExpirationKey, api.Expiration.String()))
+	}
+
+	if api.Logging != nil {
+		sb.WriteString(fmt.Sprintf("%s:\n", LoggingKey))
+		sb.WriteString(s.Indent(api.Logging.UserStr(), "  "))
+	}
+
+	if api.Hooks != nil {
+		sb.WriteString(fmt.Sprintf("%s:\n", HooksKey))
+		sb.WriteString(s.Indent(api.Hooks.UserStr(), "  "))
+	}
+
+	if len(api.DependsOn) > 0 {
+		sb.WriteString(fmt.Sprintf("%s: %s\n", DependsOnKey, s.ObjFlatNoQuotes(api.DependsOn)))
+	}
+
+	return sb.String()
+}
+
+func (step *WorkflowStep) UserStr() string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("%s: %s\n", StepNameKey, step.Name))
+	sb.WriteString(fmt.Sprintf("%s: %s\n", StepAPIKey, step.API))
+	if len(step.DependsOn) > 0 {
+		sb.WriteString(fmt.Sprintf("%s: %s\n", DependsOnKey, s.ObjFlatNoQuotes(step.DependsOn)))
+	}
+	if len(step.Config) > 0 {
+		sb.WriteString(fmt.Sprintf("%s:\n", RuntimeConfigKey))
+		d, _ := yaml.Marshal(&step.Config)
+		sb.WriteString(s.Indent(string(d), "  "))
+	}
 	return sb.String()
 }
 
@@ -303,6 +750,15 @@ func (pod *Pod) UserStr(kind Kind) string {
 		sb.WriteString(fmt.Sprintf("%s: %s\n", MaxQueueLengthKey, s.Int64(pod.MaxQueueLength)))
 	}
 
+	if len(pod.InitContainers) > 0 {
+		sb.WriteString(fmt.Sprintf("%s:\n", InitContainersKey))
+		for _, initContainer := range pod.InitContainers {
+			initContainerUserStr := s.Indent(initContainer.UserStr(), "    ")
+			initContainerUserStr = initContainerUserStr[:2] + "-" + initContainerUserStr[3:]
+			sb.WriteString(initContainerUserStr)
+		}
+	}
+
 	sb.WriteString(fmt.Sprintf("%s:\n", ContainersKey))
 	for _, container := range pod.Containers {
 		containerUserStr := s.Indent(container.UserStr(), "    ")
@@ -310,6 +766,170 @@ func (pod *Pod) UserStr(kind Kind) string {
 		sb.WriteString(containerUserStr)
 	}
 
+	if pod.ModelCache != nil {
+		sb.WriteString(fmt.Sprintf("%s:\n", ModelCacheKey))
+		sb.WriteString(s.Indent(pod.ModelCache.UserStr(), "  "))
+	}
+
+	if pod.EFS != nil {
+		sb.WriteString(fmt.Sprintf("%s:\n", EFSKey))
+		sb.WriteString(s.Indent(pod.EFS.UserStr(), "  "))
+	}
+
+	if pod.Config != nil {
+		sb.WriteString(fmt.Sprintf("%s:\n", RuntimeConfigKey))
+		sb.WriteString(s.Indent(pod.Config.UserStr(), "  "))
+	}
+
+	if len(pod.Secrets) > 0 {
+		sb.WriteString(fmt.Sprintf("%s:\n", SecretsKey))
+		for _, secret := range pod.Secrets {
+			secretUserStr := s.Indent(secret.UserStr(), "    ")
+			secretUserStr = secretUserStr[:2] + "-" + secretUserStr[3:]
+			sb.WriteString(secretUserStr)
+		}
+	}
+
+	if len(pod.RegistryCredentials) > 0 {
+		sb.WriteString(fmt.Sprintf("%s:\n", RegistryCredentialsKey))
+		for _, registryCredentials := range pod.RegistryCredentials {
+			registryCredentialsUserStr := s.Indent(registryCredentials.UserStr(), "    ")
+			registryCredentialsUserStr = registryCredentialsUserStr[:2] + "-" + registryCredentialsUserStr[3:]
+			sb.WriteString(registryCredentialsUserStr)
+		}
+	}
+
+	return sb.String()
+}
+
+func (modelCache *ModelCache) UserStr() string {
+	var sb strings.Builder
+	if modelCache.Path != "" {
+		sb.WriteString(fmt.Sprintf("%s: %s\n", PathKey, modelCache.Path))
+	}
+	if modelCache.ModelRegistry != nil {
+		sb.WriteString(fmt.Sprintf("%s:\n", ModelRegistryKey))
+		sb.WriteString(s.Indent(modelCache.ModelRegistry.UserStr(), "  "))
+	}
+	if modelCache.Watch {
+		sb.WriteString(fmt.Sprintf("%s: %s\n", WatchKey, s.Bool(modelCache.Watch)))
+	}
+	if modelCache.Integrity != nil {
+		sb.WriteString(fmt.Sprintf("%s:\n", IntegrityKey))
+		sb.WriteString(s.Indent(modelCache.Integrity.UserStr(), "  "))
+	}
+	return sb.String()
+}
+
+func (integrity *Integrity) UserStr() string {
+	var sb strings.Builder
+	if integrity.SHA256 != nil {
+		sb.WriteString(fmt.Sprintf("%s: %s\n", SHA256Key, *integrity.SHA256))
+	}
+	if integrity.Signature != nil {
+		sb.WriteString(fmt.Sprintf("%s: %s\n", SignatureKey, *integrity.Signature))
+	}
+	if integrity.PublicKey != nil {
+		sb.WriteString(fmt.Sprintf("%s: |\n", PublicKeyKey))
+		sb.WriteString(s.Indent(strings.TrimSpace(*integrity.PublicKey)+"\n", "  "))
+	}
+	return sb.String()
+}
+
+func (modelRegistry *ModelRegistry) UserStr() string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("%s: %s\n", ModelRegistryProviderKey, modelRegistry.Provider))
+	sb.WriteString(fmt.Sprintf("%s: %s\n", RegisteredModelNameKey, modelRegistry.Name))
+	if modelRegistry.Stage != nil {
+		sb.WriteString(fmt.Sprintf("%s: %s\n", StageKey, *modelRegistry.Stage))
+	}
+	if modelRegistry.Version != nil {
+		sb.WriteString(fmt.Sprintf("%s: %s\n", ModelVersionKey, *modelRegistry.Version))
+	}
+	if modelRegistry.TrackingURI != nil {
+		sb.WriteString(fmt.Sprintf("%s: %s\n", TrackingURIKey, *modelRegistry.TrackingURI))
+	}
+	if modelRegistry.TokenSecret != nil {
+		sb.WriteString(fmt.Sprintf("%s: %s\n", TokenSecretKey, *modelRegistry.TokenSecret))
+	}
+	return sb.String()
+}
+
+func (efsMount *EFSMount) UserStr() string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("%s: %s\n", PathKey, efsMount.Path))
+	sb.WriteString(fmt.Sprintf("%s: %s\n", MountPathKey, efsMount.MountPath))
+	sb.WriteString(fmt.Sprintf("%s: %s\n", ReadOnlyKey, s.Bool(efsMount.ReadOnly)))
+	return sb.String()
+}
+
+func (runtimeConfig *RuntimeConfig) UserStr() string {
+	var sb strings.Builder
+	if len(runtimeConfig.Files) > 0 {
+		fileNames := maps.StrMapKeysString(runtimeConfig.Files)
+		sort.Strings(fileNames)
+		sb.WriteString(fmt.Sprintf("%s: %s  # file contents omitted\n", FilesKey, s.ObjFlatNoQuotes(fileNames)))
+	}
+	if len(runtimeConfig.Env) > 0 {
+		sb.WriteString(fmt.Sprintf("%s:\n", EnvKey))
+		d, _ := yaml.Marshal(&runtimeConfig.Env)
+		sb.WriteString(s.Indent(string(d), "  "))
+	}
+	return sb.String()
+}
+
+func (secret *Secret) UserStr() string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("%s: %s\n", SecretNameKey, secret.Name))
+	if secret.SecretsManager != nil {
+		sb.WriteString(fmt.Sprintf("%s: %s\n", SecretsManagerKey, *secret.SecretsManager))
+	}
+	if secret.SSMParameter != nil {
+		sb.WriteString(fmt.Sprintf("%s: %s\n", SSMParameterKey, *secret.SSMParameter))
+	}
+	if secret.Key != nil {
+		sb.WriteString(fmt.Sprintf("%s: %s\n", SecretKeyKey, *secret.Key))
+	}
+	return sb.String()
+}
+
+func (registryCredentials *RegistryCredentials) UserStr() string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("%s: %s\n", RegistryKey, registryCredentials.Registry))
+	if registryCredentials.SecretsManager != nil {
+		sb.WriteString(fmt.Sprintf("%s: %s\n", SecretsManagerKey, *registryCredentials.SecretsManager))
+	}
+	return sb.String()
+}
+
+func (initContainer *InitContainer) UserStr() string {
+	var sb strings.Builder
+
+	sb.WriteString(fmt.Sprintf("%s: %s\n", ContainerNameKey, initContainer.Name))
+	sb.WriteString(fmt.Sprintf("%s: %s\n", ImageKey, initContainer.Image))
+	if initContainer.ImageDigest != nil {
+		sb.WriteString(fmt.Sprintf("%s: %s\n", ImageDigestKey, *initContainer.ImageDigest))
+	}
+
+	if len(initContainer.Env) > 0 {
+		sb.WriteString(fmt.Sprintf("%s:\n", EnvKey))
+		d, _ := yaml.Marshal(&initContainer.Env)
+		sb.WriteString(s.Indent(string(d), "  "))
+	}
+
+	if initContainer.Command != nil {
+		sb.WriteString(fmt.Sprintf("%s: %s\n", CommandKey, s.ObjFlatNoQuotes(initContainer.Command)))
+	}
+
+	if initContainer.Args != nil {
+		sb.WriteString(fmt.Sprintf("%s: %s\n", ArgsKey, s.ObjFlatNoQuotes(initContainer.Args)))
+	}
+
+	if initContainer.Compute != nil {
+		sb.WriteString(fmt.Sprintf("%s:\n", ComputeKey))
+		sb.WriteString(s.Indent(initContainer.Compute.UserStr(), "  "))
+	}
+
 	return sb.String()
 }
 
@@ -318,6 +938,9 @@ func (container *Container) UserStr() string {
 
 	sb.WriteString(fmt.Sprintf("%s: %s\n", ContainerNameKey, container.Name))
 	sb.WriteString(fmt.Sprintf("%s: %s\n", ImageKey, container.Image))
+	if container.ImageDigest != nil {
+		sb.WriteString(fmt.Sprintf("%s: %s\n", ImageDigestKey, *container.ImageDigest))
+	}
 
 	if len(container.Env) > 0 {
 		sb.WriteString(fmt.Sprintf("%s:\n", EnvKey))
@@ -343,11 +966,21 @@ func (container *Container) UserStr() string {
 		sb.WriteString(s.Indent(container.LivenessProbe.UserStr(), "  "))
 	}
 
+	if container.StartupProbe != nil {
+		sb.WriteString(fmt.Sprintf("%s:\n", StartupProbeKey))
+		sb.WriteString(s.Indent(container.StartupProbe.UserStr(), "  "))
+	}
+
 	if container.Compute != nil {
 		sb.WriteString(fmt.Sprintf("%s:\n", ComputeKey))
 		sb.WriteString(s.Indent(container.Compute.UserStr(), "  "))
 	}
 
+	if container.VerticalAutoscaling != nil {
+		sb.WriteString(fmt.Sprintf("%s:\n", VerticalAutoscalingKey))
+		sb.WriteString(s.Indent(container.VerticalAutoscaling.UserStr(), "  "))
+	}
+
 	return sb.String()
 }
 
@@ -356,6 +989,35 @@ func (networking *Networking) UserStr() string {
 	if networking.Endpoint != nil {
 		sb.WriteString(fmt.Sprintf("%s: %s\n", EndpointKey, *networking.Endpoint))
 	}
+	if len(networking.CIDRWhiteList) > 0 {
+		sb.WriteString(fmt.Sprintf("%s: %s\n", CIDRWhiteListKey, s.ObjFlatNoQuotes(networking.CIDRWhiteList)))
+	}
+	if networking.AccessLogs != nil {
+		sb.WriteString(fmt.Sprintf("%s:\n", AccessLogsKey))
+		sb.WriteString(s.Indent(fmt.Sprintf("%s: %s\n", SampleRateKey, s.Float64(networking.AccessLogs.SampleRate)), "  "))
+	}
+	if networking.Ingress != nil {
+		sb.WriteString(fmt.Sprintf("%s:\n", IngressKey))
+		sb.WriteString(s.Indent(networking.Ingress.UserStr(), "  "))
+	}
+	if networking.Egress != nil {
+		sb.WriteString(fmt.Sprintf("%s:\n", EgressKey))
+		sb.WriteString(s.Indent(networking.Egress.UserStr(), "  "))
+	}
+	return sb.String()
+}
+
+func (rules *NetworkPolicyRules) UserStr() string {
+	var sb strings.Builder
+	if len(rules.CIDRs) > 0 {
+		sb.WriteString(fmt.Sprintf("%s: %s\n", CIDRsKey, s.ObjFlatNoQuotes(rules.CIDRs)))
+	}
+	if len(rules.Hosts) > 0 {
+		sb.WriteString(fmt.Sprintf("%s: %s\n", HostsKey, s.ObjFlatNoQuotes(rules.Hosts)))
+	}
+	if len(rules.APIs) > 0 {
+		sb.WriteString(fmt.Sprintf("%s: %s\n", APIsKey, s.ObjFlatNoQuotes(rules.APIs)))
+	}
 	return sb.String()
 }
 
@@ -413,7 +1075,7 @@ func (compute *Compute) UserStr() string {
 		sb.WriteString(fmt.Sprintf("%s: %s\n", CPUKey, compute.CPU.UserString))
 	}
 	if compute.GPU > 0 {
-		sb.WriteString(fmt.Sprintf("%s: %s\n", GPUKey, s.Int64(compute.GPU)))
+		sb.WriteString(fmt.Sprintf("%s: %s\n", GPUKey, s.Round(compute.GPU, 4, 0)))
 	}
 	if compute.Inf > 0 {
 		sb.WriteString(fmt.Sprintf("%s: %s\n", InfKey, s.Int64(compute.Inf)))
@@ -431,6 +1093,15 @@ func (compute *Compute) UserStr() string {
 	return sb.String()
 }
 
+func (verticalAutoscaling *VerticalAutoscaling) UserStr() string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("%s: %s\n", MinCPUKey, verticalAutoscaling.MinCPU.UserString))
+	sb.WriteString(fmt.Sprintf("%s: %s\n", MaxCPUKey, verticalAutoscaling.MaxCPU.UserString))
+	sb.WriteString(fmt.Sprintf("%s: %s\n", MinMemKey, verticalAutoscaling.MinMem.UserString))
+	sb.WriteString(fmt.Sprintf("%s: %s\n", MaxMemKey, verticalAutoscaling.MaxMem.UserString))
+	return sb.String()
+}
+
 func (autoscaling *Autoscaling) UserStr() string {
 	var sb strings.Builder
 	sb.WriteString(fmt.Sprintf("%s: %s\n", MinReplicasKey, s.Int32(autoscaling.MinReplicas)))
@@ -444,6 +1115,15 @@ func (autoscaling *Autoscaling) UserStr() string {
 	sb.WriteString(fmt.Sprintf("%s: %s\n", MaxUpscaleFactorKey, s.Float64(autoscaling.MaxUpscaleFactor)))
 	sb.WriteString(fmt.Sprintf("%s: %s\n", DownscaleToleranceKey, s.Float64(autoscaling.DownscaleTolerance)))
 	sb.WriteString(fmt.Sprintf("%s: %s\n", UpscaleToleranceKey, s.Float64(autoscaling.UpscaleTolerance)))
+	if autoscaling.TargetQueueLengthPerReplica != nil {
+		sb.WriteString(fmt.Sprintf("%s: %s\n", TargetQueueLengthPerReplicaKey, s.Float64(*autoscaling.TargetQueueLengthPerReplica)))
+	}
+	if autoscaling.MaxQueueLatency != nil {
+		sb.WriteString(fmt.Sprintf("%s: %s\n", MaxQueueLatencyKey, autoscaling.MaxQueueLatency.String()))
+	}
+	if autoscaling.WarmReplicas > 0 {
+		sb.WriteString(fmt.Sprintf("%s: %s\n", WarmReplicasKey, s.Int32(autoscaling.WarmReplicas)))
+	}
 
 	return sb.String()
 }
@@ -455,6 +1135,265 @@ func (updateStrategy *UpdateStrategy) UserStr() string {
 	return sb.String()
 }
 
+func (availability *Availability) UserStr() string {
+	var sb strings.Builder
+	if availability.MaxDisruptedReplicas == nil {
+		sb.WriteString(fmt.Sprintf("%s: null\n", MaxDisruptedReplicasKey))
+	} else {
+		sb.WriteString(fmt.Sprintf("%s: %s\n", MaxDisruptedReplicasKey, *availability.MaxDisruptedReplicas))
+	}
+	sb.WriteString(fmt.Sprintf("%s: %s\n", ZoneSpreadKey, availability.ZoneSpread.String()))
+	sb.WriteString(fmt.Sprintf("%s: %s\n", NodeSpreadKey, availability.NodeSpread.String()))
+	return sb.String()
+}
+
+func (alerting *Alerting) UserStr(kind Kind) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("%s: %s\n", DisabledKey, s.Bool(alerting.Disabled)))
+	if alerting.Receiver != nil {
+		sb.WriteString(fmt.Sprintf("%s: %s\n", ReceiverKey, *alerting.Receiver))
+	}
+	if alerting.ErrorRate != nil {
+		sb.WriteString(fmt.Sprintf("%s: %s\n", ErrorRateKey, s.Float64(*alerting.ErrorRate)))
+	}
+	if alerting.PendingReplicasPeriod != nil {
+		sb.WriteString(fmt.Sprintf("%s: %s\n", PendingReplicasPeriodKey, alerting.PendingReplicasPeriod.String()))
+	}
+	if kind == AsyncAPIKind && alerting.QueueAge != nil {
+		sb.WriteString(fmt.Sprintf("%s: %s\n", QueueAgeKey, alerting.QueueAge.String()))
+	}
+	return sb.String()
+}
+
+func (slo *SLO) UserStr() string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("%s: %s\n", WindowKey, slo.Window.String()))
+	if slo.Availability != nil {
+		sb.WriteString(fmt.Sprintf("%s: %s\n", SLOAvailabilityKey, s.Float64(*slo.Availability)))
+	}
+	if len(slo.Latency) > 0 {
+		sb.WriteString(fmt.Sprintf("%s:\n", LatencyKey))
+		for _, latencyTarget := range slo.Latency {
+			latencyTargetUserStr := s.Indent(latencyTarget.UserStr(), "    ")
+			latencyTargetUserStr = latencyTargetUserStr[:2] + "-" + latencyTargetUserStr[3:]
+			sb.WriteString(latencyTargetUserStr)
+		}
+	}
+	return sb.String()
+}
+
+func (sessionAffinity *SessionAffinity) UserStr() string {
+	var sb strings.Builder
+	if sessionAffinity.Cookie != nil {
+		sb.WriteString(fmt.Sprintf("%s: %s\n", CookieKey, *sessionAffinity.Cookie))
+		sb.WriteString(fmt.Sprintf("%s: %s\n", TTLKey, sessionAffinity.TTL.String()))
+	}
+	if sessionAffinity.Header != nil {
+		sb.WriteString(fmt.Sprintf("%s: %s\n", HeaderKey, *sessionAffinity.Header))
+	}
+	return sb.String()
+}
+
+func (payloadLogging *PayloadLogging) UserStr() string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("%s: %s\n", S3PathKey, payloadLogging.S3Path))
+	sb.WriteString(fmt.Sprintf("%s: %s\n", SampleRateKey, s.Float64(payloadLogging.SampleRate)))
+	sb.WriteString(fmt.Sprintf("%s: %s\n", MaxBodySizeKey, s.Int64(payloadLogging.MaxBodySize)))
+	sb.WriteString(fmt.Sprintf("%s: %s\n", FlushIntervalKey, payloadLogging.FlushInterval.String()))
+	return sb.String()
+}
+
+func (proxy *Proxy) UserStr() string {
+	var sb strings.Builder
+	if proxy.UpstreamTimeout == nil {
+		sb.WriteString(fmt.Sprintf("%s: null\n", UpstreamTimeoutKey))
+	} else {
+		sb.WriteString(fmt.Sprintf("%s: %s\n", UpstreamTimeoutKey, proxy.UpstreamTimeout.String()))
+	}
+	if proxy.IdleTimeout == nil {
+		sb.WriteString(fmt.Sprintf("%s: null\n", IdleTimeoutKey))
+	} else {
+		sb.WriteString(fmt.Sprintf("%s: %s\n", IdleTimeoutKey, proxy.IdleTimeout.String()))
+	}
+	if proxy.MaxRequestBodySize == nil {
+		sb.WriteString(fmt.Sprintf("%s: null\n", MaxRequestBodySizeKey))
+	} else {
+		sb.WriteString(fmt.Sprintf("%s: %s\n", MaxRequestBodySizeKey, s.Int64(*proxy.MaxRequestBodySize)))
+	}
+	if proxy.MaxConcurrentStreams == nil {
+		sb.WriteString(fmt.Sprintf("%s: null\n", MaxConcurrentStreamsKey))
+	} else {
+		sb.WriteString(fmt.Sprintf("%s: %s\n", MaxConcurrentStreamsKey, s.Int32(*proxy.MaxConcurrentStreams)))
+	}
+	if proxy.FairQueuing != nil {
+		sb.WriteString(fmt.Sprintf("%s:\n", FairQueuingKey))
+		sb.WriteString(s.Indent(proxy.FairQueuing.UserStr(), "  "))
+	}
+	return sb.String()
+}
+
+func (fairQueuing *FairQueuing) UserStr() string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("%s: %s\n", HeaderKey, fairQueuing.Header))
+	sb.WriteString(fmt.Sprintf("%s: %s\n", DefaultWeightKey, s.Int32(fairQueuing.DefaultWeight)))
+	if len(fairQueuing.Clients) > 0 {
+		sb.WriteString(fmt.Sprintf("%s:\n", ClientsKey))
+		for _, clientWeight := range fairQueuing.Clients {
+			clientWeightUserStr := s.Indent(clientWeight.UserStr(), "  ")
+			clientWeightUserStr = clientWeightUserStr[:2] + "-" + clientWeightUserStr[3:]
+			sb.WriteString(clientWeightUserStr)
+		}
+	}
+	return sb.String()
+}
+
+func (clientWeight *ClientWeight) UserStr() string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("%s: %s\n", ClientKey, clientWeight.Client))
+	sb.WriteString(fmt.Sprintf("%s: %s\n", WeightKey, s.Int32(clientWeight.Weight)))
+	return sb.String()
+}
+
+func (retries *Retries) UserStr() string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("%s: %s\n", AttemptsKey, s.Int32(retries.Attempts)))
+	sb.WriteString(fmt.Sprintf("%s: %s\n", RetryOnKey, s.ObjFlatNoQuotes(retries.RetryOn)))
+	if retries.PerTryTimeout == nil {
+		sb.WriteString(fmt.Sprintf("%s: null\n", PerTryTimeoutKey))
+	} else {
+		sb.WriteString(fmt.Sprintf("%s: %s\n", PerTryTimeoutKey, retries.PerTryTimeout.String()))
+	}
+	if retries.Hedging != nil {
+		sb.WriteString(fmt.Sprintf("%s:\n", HedgingKey))
+		if retries.Hedging.Delay == nil {
+			sb.WriteString(fmt.Sprintf("  %s: null\n", DelayKey))
+		} else {
+			sb.WriteString(fmt.Sprintf("  %s: %s\n", DelayKey, retries.Hedging.Delay.String()))
+		}
+	}
+	return sb.String()
+}
+
+func (models *Models) UserStr() string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("%s:\n", ModelPathsKey))
+	for _, modelPath := range models.Paths {
+		modelPathUserStr := s.Indent(modelPath.UserStr(), "  ")
+		modelPathUserStr = modelPathUserStr[:2] + "-" + modelPathUserStr[3:]
+		sb.WriteString(modelPathUserStr)
+	}
+	sb.WriteString(fmt.Sprintf("%s: %s\n", MaxLoadedModelsKey, s.Int32(models.MaxLoadedModels)))
+	if models.UnloadPath != nil {
+		sb.WriteString(fmt.Sprintf("%s: %s\n", UnloadPathKey, *models.UnloadPath))
+	}
+	return sb.String()
+}
+
+func (modelPath *ModelPath) UserStr() string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("%s: %s\n", NameKey, modelPath.Name))
+	if modelPath.Path != "" {
+		sb.WriteString(fmt.Sprintf("%s: %s\n", PathKey, modelPath.Path))
+	}
+	if modelPath.ModelRegistry != nil {
+		sb.WriteString(fmt.Sprintf("%s:\n", ModelRegistryKey))
+		sb.WriteString(s.Indent(modelPath.ModelRegistry.UserStr(), "  "))
+	}
+	if modelPath.Watch {
+		sb.WriteString(fmt.Sprintf("%s: %s\n", WatchKey, s.Bool(modelPath.Watch)))
+	}
+	if modelPath.Integrity != nil {
+		sb.WriteString(fmt.Sprintf("%s:\n", IntegrityKey))
+		sb.WriteString(s.Indent(modelPath.Integrity.UserStr(), "  "))
+	}
+	return sb.String()
+}
+
+func (gateway *AsyncGateway) UserStr() string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("%s: %s\n", TargetSubmissionRateKey, s.Float64(gateway.TargetSubmissionRate)))
+	sb.WriteString(fmt.Sprintf("%s: %s\n", MaxWriteLatencyKey, gateway.MaxWriteLatency.String()))
+	sb.WriteString(fmt.Sprintf("%s: %s\n", RetryAfterKey, gateway.RetryAfter.String()))
+	return sb.String()
+}
+
+func (logging *Logging) UserStr() string {
+	var sb strings.Builder
+	if logging.Level != nil {
+		sb.WriteString(fmt.Sprintf("%s: %s\n", LogLevelKey, logging.Level.String()))
+	}
+	if logging.Format != nil {
+		sb.WriteString(fmt.Sprintf("%s: %s\n", LogFormatKey, logging.Format.String()))
+	}
+	return sb.String()
+}
+
+func (latencyTarget *LatencyTarget) UserStr() string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("%s: %s\n", PercentileKey, s.Float64(latencyTarget.Percentile)))
+	sb.WriteString(fmt.Sprintf("%s: %s\n", ThresholdKey, latencyTarget.Threshold.String()))
+	return sb.String()
+}
+
+func (hooks *Hooks) UserStr() string {
+	var sb strings.Builder
+	if hooks.PreDeploy != nil {
+		sb.WriteString(fmt.Sprintf("%s:\n", PreDeployKey))
+		sb.WriteString(s.Indent(hooks.PreDeploy.UserStr(), "  "))
+	}
+	if hooks.PostDeploy != nil {
+		sb.WriteString(fmt.Sprintf("%s:\n", PostDeployKey))
+		sb.WriteString(s.Indent(hooks.PostDeploy.UserStr(), "  "))
+	}
+	return sb.String()
+}
+
+func (hook *Hook) UserStr() string {
+	var sb strings.Builder
+	if hook.HTTP != nil {
+		sb.WriteString(fmt.Sprintf("%s:\n", HTTPKey))
+		sb.WriteString(s.Indent(hook.HTTP.UserStr(), "  "))
+	}
+	if hook.Job != nil {
+		sb.WriteString(fmt.Sprintf("%s:\n", JobKey))
+		sb.WriteString(s.Indent(hook.Job.UserStr(), "  "))
+	}
+	sb.WriteString(fmt.Sprintf("%s: %s\n", HookTimeoutKey, hook.Timeout.String()))
+	return sb.String()
+}
+
+func (httpHook *HTTPHook) UserStr() string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("%s: %s\n", URLKey, httpHook.URL))
+	sb.WriteString(fmt.Sprintf("%s: %s\n", MethodKey, httpHook.Method))
+	if len(httpHook.Headers) > 0 {
+		sb.WriteString(fmt.Sprintf("%s:\n", HeadersKey))
+		d, _ := yaml.Marshal(&httpHook.Headers)
+		sb.WriteString(s.Indent(string(d), "  "))
+	}
+	if httpHook.Body != nil {
+		sb.WriteString(fmt.Sprintf("%s: %s\n", BodyKey, s.UserStr(*httpHook.Body)))
+	}
+	return sb.String()
+}
+
+func (jobHook *JobHook) UserStr() string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("%s: %s\n", ImageKey, jobHook.Image))
+	if len(jobHook.Env) > 0 {
+		sb.WriteString(fmt.Sprintf("%s:\n", EnvKey))
+		d, _ := yaml.Marshal(&jobHook.Env)
+		sb.WriteString(s.Indent(string(d), "  "))
+	}
+	if jobHook.Command != nil {
+		sb.WriteString(fmt.Sprintf("%s: %s\n", CommandKey, s.ObjFlatNoQuotes(jobHook.Command)))
+	}
+	if jobHook.Args != nil {
+		sb.WriteString(fmt.Sprintf("%s: %s\n", ArgsKey, s.ObjFlatNoQuotes(jobHook.Args)))
+	}
+	return sb.String()
+}
+
 func ZeroCompute() Compute {
 	return Compute{
 		CPU: &k8s.Quantity{},
@@ -505,6 +1444,38 @@ func GetTotalComputeFromContainers(containers []*Container) Compute {
 	return compute
 }
 
+// init containers run one at a time before the other containers start, so the pod needs enough resources for
+// the sum of its containers' requests or for its largest init container's request, whichever is greater
+func GetTotalComputeFromPod(pod *Pod) Compute {
+	compute := GetTotalComputeFromContainers(pod.Containers)
+
+	for _, initContainer := range pod.InitContainers {
+		if initContainer == nil || initContainer.Compute == nil {
+			continue
+		}
+
+		if initContainer.Compute.CPU != nil && (compute.CPU == nil || initContainer.Compute.CPU.Cmp(compute.CPU.Quantity) > 0) {
+			compute.CPU = k8s.NewMilliQuantity(initContainer.Compute.CPU.ToDec().MilliValue())
+		}
+
+		if initContainer.Compute.Mem != nil && (compute.Mem == nil || initContainer.Compute.Mem.Cmp(compute.Mem.Quantity) > 0) {
+			compute.Mem = k8s.NewMilliQuantity(initContainer.Compute.Mem.ToDec().MilliValue())
+		}
+	}
+
+	return compute
+}
+
+// RequestsFractionalGPUs returns true if any of the pod's containers request a fraction of a GPU
+func (pod *Pod) RequestsFractionalGPUs() bool {
+	for _, container := range pod.Containers {
+		if container != nil && container.Compute != nil && container.Compute.GPU != math.Trunc(container.Compute.GPU) {
+			return true
+		}
+	}
+	return false
+}
+
 func GetContainerNames(containers []*Container) strset.Set {
 	containerNames := strset.New()
 	for _, container := range containers {
@@ -518,11 +1489,34 @@ func GetContainerNames(containers []*Container) strset.Set {
 func (api *API) TelemetryEvent() map[string]interface{} {
 	event := map[string]interface{}{"kind": api.Kind}
 
+	if api.Project != nil {
+		event["project._is_defined"] = true
+	}
+
+	if len(api.Labels) > 0 {
+		event["labels._len"] = len(api.Labels)
+	}
+
 	if len(api.APIs) > 0 {
 		event["apis._is_defined"] = true
 		event["apis._len"] = len(api.APIs)
 	}
 
+	if api.SessionAffinity != nil {
+		event["session_affinity._is_defined"] = true
+		event["session_affinity.cookie._is_defined"] = api.SessionAffinity.Cookie != nil
+		event["session_affinity.header._is_defined"] = api.SessionAffinity.Header != nil
+	}
+
+	if len(api.Steps) > 0 {
+		event["steps._is_defined"] = true
+		event["steps._len"] = len(api.Steps)
+	}
+
+	if len(api.DependsOn) > 0 {
+		event["depends_on._len"] = len(api.DependsOn)
+	}
+
 	if api.Networking != nil {
 		event["networking._is_defined"] = true
 		if api.Networking.Endpoint != nil {
@@ -531,6 +1525,24 @@ func (api *API) TelemetryEvent() map[string]interface{} {
 				event["networking.endpoint._is_custom"] = true
 			}
 		}
+		if len(api.Networking.CIDRWhiteList) > 0 {
+			event["networking.cidr_white_list._len"] = len(api.Networking.CIDRWhiteList)
+		}
+		if api.Networking.AccessLogs != nil {
+			event["networking.access_logs._is_defined"] = true
+			event["networking.access_logs.sample_rate"] = api.Networking.AccessLogs.SampleRate
+		}
+		if api.Networking.Ingress != nil {
+			event["networking.ingress._is_defined"] = true
+			event["networking.ingress.cidrs._len"] = len(api.Networking.Ingress.CIDRs)
+			event["networking.ingress.apis._len"] = len(api.Networking.Ingress.APIs)
+		}
+		if api.Networking.Egress != nil {
+			event["networking.egress._is_defined"] = true
+			event["networking.egress.cidrs._len"] = len(api.Networking.Egress.CIDRs)
+			event["networking.egress.hosts._len"] = len(api.Networking.Egress.Hosts)
+			event["networking.egress.apis._len"] = len(api.Networking.Egress.APIs)
+		}
 	}
 
 	if api.Pod != nil {
@@ -543,9 +1555,18 @@ func (api *API) TelemetryEvent() map[string]interface{} {
 		event["pod.max_queue_length"] = api.Pod.MaxQueueLength
 
 		event["pod.containers._len"] = len(api.Pod.Containers)
+		event["pod.init_containers._len"] = len(api.Pod.InitContainers)
+		event["pod.secrets._len"] = len(api.Pod.Secrets)
+		event["pod.registry_credentials._len"] = len(api.Pod.RegistryCredentials)
+		if api.Pod.Config != nil {
+			event["pod.config._is_defined"] = true
+			event["pod.config.files._len"] = len(api.Pod.Config.Files)
+			event["pod.config.env._len"] = len(api.Pod.Config.Env)
+		}
 
 		var numReadinessProbes int
 		var numLivenessProbes int
+		var numStartupProbes int
 		for _, container := range api.Pod.Containers {
 			if container.ReadinessProbe != nil {
 				numReadinessProbes++
@@ -553,10 +1574,14 @@ func (api *API) TelemetryEvent() map[string]interface{} {
 			if container.LivenessProbe != nil {
 				numLivenessProbes++
 			}
+			if container.StartupProbe != nil {
+				numStartupProbes++
+			}
 		}
 
 		event["pod.containers._num_readiness_probes"] = numReadinessProbes
 		event["pod.containers._num_liveness_probes"] = numLivenessProbes
+		event["pod.containers._num_startup_probes"] = numStartupProbes
 
 		totalCompute := GetTotalComputeFromContainers(api.Pod.Containers)
 		if totalCompute.CPU != nil {
@@ -573,9 +1598,28 @@ func (api *API) TelemetryEvent() map[string]interface{} {
 		}
 		event["pod.containers.compute.gpu"] = totalCompute.GPU
 		event["pod.containers.compute.inf"] = totalCompute.Inf
+
+		if api.Pod.ModelCache != nil {
+			event["pod.model_cache._is_defined"] = true
+			if api.Pod.ModelCache.ModelRegistry != nil {
+				event["pod.model_cache.model_registry.provider"] = api.Pod.ModelCache.ModelRegistry.Provider
+			}
+			event["pod.model_cache.watch"] = api.Pod.ModelCache.Watch
+			event["pod.model_cache.integrity._is_defined"] = api.Pod.ModelCache.Integrity != nil
+		}
+		if api.Pod.EFS != nil {
+			event["pod.efs._is_defined"] = true
+			event["pod.efs.read_only"] = api.Pod.EFS.ReadOnly
+		}
 	}
 
 	event["node_groups._len"] = len(api.NodeGroups)
+	if api.Arch != "" {
+		event["arch"] = api.Arch
+	}
+	if api.OS != "" {
+		event["os"] = api.OS
+	}
 
 	if api.UpdateStrategy != nil {
 		event["update_strategy._is_defined"] = true
@@ -583,6 +1627,127 @@ func (api *API) TelemetryEvent() map[string]interface{} {
 		event["update_strategy.max_unavailable"] = api.UpdateStrategy.MaxUnavailable
 	}
 
+	if api.Availability != nil {
+		event["availability._is_defined"] = true
+		if api.Availability.MaxDisruptedReplicas != nil {
+			event["availability.max_disrupted_replicas._is_defined"] = true
+			event["availability.max_disrupted_replicas"] = *api.Availability.MaxDisruptedReplicas
+		}
+		event["availability.zone_spread"] = api.Availability.ZoneSpread.String()
+		event["availability.node_spread"] = api.Availability.NodeSpread.String()
+	}
+
+	if api.Alerting != nil {
+		event["alerting._is_defined"] = true
+		event["alerting.disabled"] = api.Alerting.Disabled
+		if api.Alerting.Receiver != nil {
+			event["alerting.receiver._is_defined"] = true
+		}
+		if api.Alerting.ErrorRate != nil {
+			event["alerting.error_rate"] = *api.Alerting.ErrorRate
+		}
+		if api.Alerting.PendingReplicasPeriod != nil {
+			event["alerting.pending_replicas_period"] = api.Alerting.PendingReplicasPeriod.Seconds()
+		}
+		if api.Alerting.QueueAge != nil {
+			event["alerting.queue_age"] = api.Alerting.QueueAge.Seconds()
+		}
+	}
+
+	if api.SLO != nil {
+		event["slo._is_defined"] = true
+		event["slo.window"] = api.SLO.Window.Seconds()
+		if api.SLO.Availability != nil {
+			event["slo.availability"] = *api.SLO.Availability
+		}
+		event["slo.latency._len"] = len(api.SLO.Latency)
+	}
+
+	if api.PayloadLogging != nil {
+		event["payload_logging._is_defined"] = true
+		event["payload_logging.sample_rate"] = api.PayloadLogging.SampleRate
+		event["payload_logging.max_body_size"] = api.PayloadLogging.MaxBodySize
+		event["payload_logging.flush_interval"] = api.PayloadLogging.FlushInterval.Seconds()
+	}
+
+	if api.Proxy != nil {
+		event["proxy._is_defined"] = true
+		if api.Proxy.UpstreamTimeout != nil {
+			event["proxy.upstream_timeout"] = api.Proxy.UpstreamTimeout.Seconds()
+		}
+		if api.Proxy.IdleTimeout != nil {
+			event["proxy.idle_timeout"] = api.Proxy.IdleTimeout.Seconds()
+		}
+		if api.Proxy.MaxRequestBodySize != nil {
+			event["proxy.max_request_body_size"] = *api.Proxy.MaxRequestBodySize
+		}
+		if api.Proxy.MaxConcurrentStreams != nil {
+			event["proxy.max_concurrent_streams"] = *api.Proxy.MaxConcurrentStreams
+		}
+		if api.Proxy.FairQueuing != nil {
+			event["proxy.fair_queuing._is_defined"] = true
+			event["proxy.fair_queuing.default_weight"] = api.Proxy.FairQueuing.DefaultWeight
+			event["proxy.fair_queuing.clients._len"] = len(api.Proxy.FairQueuing.Clients)
+		}
+	}
+
+	if api.Retries != nil {
+		event["retries._is_defined"] = true
+		event["retries.attempts"] = api.Retries.Attempts
+		event["retries.retry_on._len"] = len(api.Retries.RetryOn)
+		if api.Retries.PerTryTimeout != nil {
+			event["retries.per_try_timeout"] = api.Retries.PerTryTimeout.Seconds()
+		}
+		if api.Retries.Hedging != nil {
+			event["retries.hedging._is_defined"] = true
+			if api.Retries.Hedging.Delay != nil {
+				event["retries.hedging.delay"] = api.Retries.Hedging.Delay.Seconds()
+			}
+		}
+	}
+
+	if api.Models != nil {
+		event["models._is_defined"] = true
+		event["models.paths._len"] = len(api.Models.Paths)
+		event["models.max_loaded_models"] = api.Models.MaxLoadedModels
+		event["models.unload_path._is_defined"] = api.Models.UnloadPath != nil
+	}
+
+	if api.Gateway != nil {
+		event["gateway._is_defined"] = true
+		event["gateway.target_submission_rate"] = api.Gateway.TargetSubmissionRate
+		event["gateway.max_write_latency"] = api.Gateway.MaxWriteLatency.Seconds()
+		event["gateway.retry_after"] = api.Gateway.RetryAfter.Seconds()
+	}
+
+	if api.Expiration != nil {
+		event["expiration"] = api.Expiration.Seconds()
+	}
+
+	if api.Logging != nil {
+		event["logging._is_defined"] = true
+		if api.Logging.Level != nil {
+			event["logging.level"] = api.Logging.Level.String()
+		}
+		if api.Logging.Format != nil {
+			event["logging.format"] = api.Logging.Format.String()
+		}
+	}
+
+	if api.Hooks != nil {
+		event["hooks._is_defined"] = true
+		if api.Hooks.PreDeploy != nil {
+			event["hooks.pre_deploy._is_defined"] = true
+			event["hooks.pre_deploy.http._is_defined"] = api.Hooks.PreDeploy.HTTP != nil
+			event["hooks.pre_deploy.job._is_defined"] = api.Hooks.PreDeploy.Job != nil
+		}
+		if api.Hooks.PostDeploy != nil {
+			event["hooks.post_deploy._is_defined"] = true
+			event["hooks.post_deploy.http._is_defined"] = api.Hooks.PostDeploy.HTTP != nil
+			event["hooks.post_deploy.job._is_defined"] = api.Hooks.PostDeploy.Job != nil
+		}
+	}
+
 	if api.Autoscaling != nil {
 		event["autoscaling._is_defined"] = true
 		event["autoscaling.min_replicas"] = api.Autoscaling.MinReplicas
@@ -599,6 +1764,15 @@ func (api *API) TelemetryEvent() map[string]interface{} {
 		event["autoscaling.max_upscale_factor"] = api.Autoscaling.MaxUpscaleFactor
 		event["autoscaling.downscale_tolerance"] = api.Autoscaling.DownscaleTolerance
 		event["autoscaling.upscale_tolerance"] = api.Autoscaling.UpscaleTolerance
+		if api.Autoscaling.TargetQueueLengthPerReplica != nil {
+			event["autoscaling.target_queue_length_per_replica._is_defined"] = true
+			event["autoscaling.target_queue_length_per_replica"] = *api.Autoscaling.TargetQueueLengthPerReplica
+		}
+		if api.Autoscaling.MaxQueueLatency != nil {
+			event["autoscaling.max_queue_latency._is_defined"] = true
+			event["autoscaling.max_queue_latency"] = api.Autoscaling.MaxQueueLatency.Seconds()
+		}
+		event["autoscaling.warm_replicas"] = api.Autoscaling.WarmReplicas
 	}
 
 	return event
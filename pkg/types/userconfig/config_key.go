@@ -20,32 +20,78 @@ const (
 	// API
 	NameKey           = "name"
 	KindKey           = "kind"
+	ProjectKey        = "project"
+	LabelsKey         = "labels"
 	NetworkingKey     = "networking"
 	ComputeKey        = "compute"
 	AutoscalingKey    = "autoscaling"
 	UpdateStrategyKey = "update_strategy"
+	AvailabilityKey   = "availability"
+	AlertingKey       = "alerting"
+	SLOKey            = "slo"
+	PayloadLoggingKey = "payload_logging"
+	ProxyKey          = "proxy"
+	RetriesKey        = "retries"
+	ModelsKey         = "models"
+	GatewayKey        = "gateway"
+	ExpirationKey     = "expiration"
+	LoggingKey        = "logging"
+	HooksKey          = "hooks"
+	DependsOnKey      = "depends_on"
 
 	// TrafficSplitter
-	APIsKey   = "apis"
-	WeightKey = "weight"
-	ShadowKey = "shadow"
+	APIsKey            = "apis"
+	WeightKey          = "weight"
+	ShadowKey          = "shadow"
+	SessionAffinityKey = "session_affinity"
+	CookieKey          = "cookie"
+	HeaderKey          = "header"
+	TTLKey             = "ttl"
+
+	// Workflow
+	StepsKey    = "steps"
+	StepAPIKey  = "api"
+	StepNameKey = "name"
 
 	// Pod
 	PodKey            = "pod"
 	NodeGroupsKey     = "node_groups"
+	ArchKey           = "arch"
+	OSKey             = "os"
 	PortKey           = "port"
 	MaxConcurrencyKey = "max_concurrency"
 	MaxQueueLengthKey = "max_queue_length"
+	InitContainersKey = "init_containers"
 	ContainersKey     = "containers"
+	ModelCacheKey     = "model_cache"
+	ModelRegistryKey  = "model_registry"
+	EFSKey            = "efs"
+	MountPathKey      = "mount_path"
+	ReadOnlyKey       = "read_only"
+	SecretsKey        = "secrets"
+	RuntimeConfigKey  = "config"
+	FilesKey          = "files"
+
+	RegistryCredentialsKey = "registry_credentials"
+	RegistryKey            = "registry"
 
 	// Containers
 	ContainerNameKey  = "name"
 	ImageKey          = "image"
+	ImageDigestKey    = "image_digest"
 	EnvKey            = "env"
 	CommandKey        = "command"
 	ArgsKey           = "args"
 	ReadinessProbeKey = "readiness_probe"
 	LivenessProbeKey  = "liveness_probe"
+	StartupProbeKey   = "startup_probe"
+
+	// VerticalAutoscaling
+	VerticalAutoscalingKey = "vertical_autoscaling"
+	MinCPUKey              = "min_cpu"
+	MaxCPUKey              = "max_cpu"
+	MinMemKey              = "min_mem"
+	MaxMemKey              = "max_mem"
 
 	// Probe
 	HTTPGetKey             = "http_get"
@@ -68,7 +114,13 @@ const (
 	ShmKey = "shm"
 
 	// Networking
-	EndpointKey = "endpoint"
+	EndpointKey      = "endpoint"
+	CIDRWhiteListKey = "cidr_white_list"
+	AccessLogsKey    = "access_logs"
+	IngressKey       = "ingress"
+	EgressKey        = "egress"
+	CIDRsKey         = "cidrs"
+	HostsKey         = "hosts"
 
 	// Autoscaling
 	MinReplicasKey                  = "min_replicas"
@@ -82,11 +134,101 @@ const (
 	MaxUpscaleFactorKey             = "max_upscale_factor"
 	DownscaleToleranceKey           = "downscale_tolerance"
 	UpscaleToleranceKey             = "upscale_tolerance"
+	TargetQueueLengthPerReplicaKey  = "target_queue_length_per_replica"
+	MaxQueueLatencyKey              = "max_queue_latency"
+	WarmReplicasKey                 = "warm_replicas"
 
 	// UpdateStrategy
 	MaxSurgeKey       = "max_surge"
 	MaxUnavailableKey = "max_unavailable"
 
+	// Secret
+	SecretNameKey     = "name"
+	SecretsManagerKey = "secrets_manager"
+	SSMParameterKey   = "ssm_parameter"
+	SecretKeyKey      = "key"
+
+	// Availability
+	MaxDisruptedReplicasKey = "max_disrupted_replicas"
+	ZoneSpreadKey           = "zone_spread"
+	NodeSpreadKey           = "node_spread"
+
+	// Alerting
+	DisabledKey              = "disabled"
+	ReceiverKey              = "receiver"
+	ErrorRateKey             = "error_rate"
+	PendingReplicasPeriodKey = "pending_replicas_period"
+	QueueAgeKey              = "queue_age"
+
+	// SLO
+	SLOAvailabilityKey = "availability"
+	LatencyKey         = "latency"
+	PercentileKey      = "percentile"
+	ThresholdKey       = "threshold"
+
+	// PayloadLogging
+	S3PathKey        = "s3_path"
+	SampleRateKey    = "sample_rate"
+	MaxBodySizeKey   = "max_body_size"
+	FlushIntervalKey = "flush_interval"
+
+	// Proxy
+	UpstreamTimeoutKey      = "upstream_timeout"
+	IdleTimeoutKey          = "idle_timeout"
+	MaxRequestBodySizeKey   = "max_request_body_size"
+	MaxConcurrentStreamsKey = "max_concurrent_streams"
+	FairQueuingKey          = "fair_queuing"
+	DefaultWeightKey        = "default_weight"
+	ClientsKey              = "clients"
+	ClientKey               = "client"
+
+	// Retries
+	AttemptsKey      = "attempts"
+	RetryOnKey       = "retry_on"
+	PerTryTimeoutKey = "per_try_timeout"
+	HedgingKey       = "hedging"
+	DelayKey         = "delay"
+
+	// Models
+	ModelPathsKey      = "paths"
+	MaxLoadedModelsKey = "max_loaded_models"
+	UnloadPathKey      = "unload_path"
+
+	// ModelRegistry
+	ModelRegistryProviderKey = "provider"
+	RegisteredModelNameKey   = "name"
+	StageKey                 = "stage"
+	ModelVersionKey          = "version"
+	TrackingURIKey           = "tracking_uri"
+	TokenSecretKey           = "token_secret"
+	WatchKey                 = "watch"
+
+	// ArtifactIntegrity
+	IntegrityKey = "integrity"
+	SHA256Key    = "sha256"
+	SignatureKey = "signature"
+	PublicKeyKey = "public_key"
+
+	// AsyncGateway
+	TargetSubmissionRateKey = "target_submission_rate"
+	MaxWriteLatencyKey      = "max_write_latency"
+	RetryAfterKey           = "retry_after"
+
+	// Logging
+	LogLevelKey  = "level"
+	LogFormatKey = "format"
+
+	// Hooks
+	PreDeployKey   = "pre_deploy"
+	PostDeployKey  = "post_deploy"
+	HTTPKey        = "http"
+	JobKey         = "job"
+	HookTimeoutKey = "timeout"
+	URLKey         = "url"
+	MethodKey      = "method"
+	HeadersKey     = "headers"
+	BodyKey        = "body"
+
 	// K8s annotation
 	EndpointAnnotationKey                     = "networking.cortex.dev/endpoint"
 	MaxConcurrencyAnnotationKey               = "pod.cortex.dev/max-concurrency"
@@ -101,4 +243,9 @@ const (
 	MaxUpscaleFactorAnnotationKey             = "autoscaling.cortex.dev/max-upscale-factor"
 	DownscaleToleranceAnnotationKey           = "autoscaling.cortex.dev/downscale-tolerance"
 	UpscaleToleranceAnnotationKey             = "autoscaling.cortex.dev/upscale-tolerance"
+	TargetQueueLengthPerReplicaAnnotationKey  = "autoscaling.cortex.dev/target-queue-length-per-replica"
+	MaxQueueLatencyAnnotationKey              = "autoscaling.cortex.dev/max-queue-latency"
+	WarmReplicasAnnotationKey                 = "autoscaling.cortex.dev/warm-replicas"
+	PostDeployHookAnnotationKey               = "hooks.cortex.dev/post-deploy"
+	VerticalAutoscalingAnnotationKey          = "autoscaling.cortex.dev/vertical-requests"
 )
@@ -25,6 +25,7 @@ const (
 	TrafficSplitterKind
 	TaskAPIKind
 	AsyncAPIKind
+	WorkflowKind
 )
 
 var _kinds = []string{
@@ -34,6 +35,7 @@ var _kinds = []string{
 	"TrafficSplitter",
 	"TaskAPI",
 	"AsyncAPI",
+	"Workflow",
 }
 
 func KindFromString(s string) Kind {
@@ -0,0 +1,78 @@
+/*
+Copyright 2021 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package userconfig
+
+type LogFormat int
+
+const (
+	UnknownLogFormat LogFormat = iota
+	JSONLogFormat
+	ConsoleLogFormat
+)
+
+var _logFormats = []string{
+	"unknown",
+	"json",
+	"console",
+}
+
+func LogFormatFromString(s string) LogFormat {
+	for i := 0; i < len(_logFormats); i++ {
+		if s == _logFormats[i] {
+			return LogFormat(i)
+		}
+	}
+	return UnknownLogFormat
+}
+
+func LogFormatTypes() []string {
+	return _logFormats[1:]
+}
+
+func (t LogFormat) String() string {
+	return _logFormats[t]
+}
+
+// MarshalText satisfies TextMarshaler
+func (t LogFormat) MarshalText() ([]byte, error) {
+	return []byte(t.String()), nil
+}
+
+// UnmarshalText satisfies TextUnmarshaler
+func (t *LogFormat) UnmarshalText(text []byte) error {
+	enum := string(text)
+	for i := 0; i < len(_logFormats); i++ {
+		if enum == _logFormats[i] {
+			*t = LogFormat(i)
+			return nil
+		}
+	}
+
+	*t = UnknownLogFormat
+	return nil
+}
+
+// UnmarshalBinary satisfies BinaryUnmarshaler
+// Needed for msgpack
+func (t *LogFormat) UnmarshalBinary(data []byte) error {
+	return t.UnmarshalText(data)
+}
+
+// MarshalBinary satisfies BinaryMarshaler
+func (t LogFormat) MarshalBinary() ([]byte, error) {
+	return []byte(t.String()), nil
+}
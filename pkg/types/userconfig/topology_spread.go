@@ -0,0 +1,80 @@
+/*
+Copyright 2021 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package userconfig
+
+type TopologySpread int
+
+const (
+	UnknownTopologySpread TopologySpread = iota
+	NoneTopologySpread
+	PreferredTopologySpread
+	RequiredTopologySpread
+)
+
+var _topologySpreads = []string{
+	"unknown",
+	"none",
+	"preferred",
+	"required",
+}
+
+func TopologySpreadFromString(s string) TopologySpread {
+	for i := 0; i < len(_topologySpreads); i++ {
+		if s == _topologySpreads[i] {
+			return TopologySpread(i)
+		}
+	}
+	return UnknownTopologySpread
+}
+
+func TopologySpreadStrings() []string {
+	return _topologySpreads[1:]
+}
+
+func (t TopologySpread) String() string {
+	return _topologySpreads[t]
+}
+
+// MarshalText satisfies TextMarshaler
+func (t TopologySpread) MarshalText() ([]byte, error) {
+	return []byte(t.String()), nil
+}
+
+// UnmarshalText satisfies TextUnmarshaler
+func (t *TopologySpread) UnmarshalText(text []byte) error {
+	enum := string(text)
+	for i := 0; i < len(_topologySpreads); i++ {
+		if enum == _topologySpreads[i] {
+			*t = TopologySpread(i)
+			return nil
+		}
+	}
+
+	*t = UnknownTopologySpread
+	return nil
+}
+
+// UnmarshalBinary satisfies BinaryUnmarshaler
+// Needed for msgpack
+func (t *TopologySpread) UnmarshalBinary(data []byte) error {
+	return t.UnmarshalText(data)
+}
+
+// MarshalBinary satisfies BinaryMarshaler
+func (t TopologySpread) MarshalBinary() ([]byte, error) {
+	return []byte(t.String()), nil
+}
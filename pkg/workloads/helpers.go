@@ -20,6 +20,9 @@ import (
 	"path"
 	"strings"
 
+	"github.com/cortexlabs/cortex/pkg/config"
+	"github.com/cortexlabs/cortex/pkg/lib/aws"
+	"github.com/cortexlabs/cortex/pkg/lib/hash"
 	"github.com/cortexlabs/cortex/pkg/lib/k8s"
 	"github.com/cortexlabs/cortex/pkg/types/userconfig"
 	kcore "k8s.io/api/core/v1"
@@ -31,6 +34,72 @@ func K8sName(apiName string) string {
 	return "api-" + apiName
 }
 
+// the k8s secret which holds the resolved values of the API's secrets
+func SecretsK8sName(apiName string) string {
+	return K8sName(apiName) + "-secrets"
+}
+
+// the k8s secret which holds the API's docker registry credentials
+func RegistryCredentialsK8sName(apiName string) string {
+	return K8sName(apiName) + "-registry-credentials"
+}
+
+// HasRegistryCredentials returns true if registry credentials are configured for the API, either in the API spec or in the cluster config
+func HasRegistryCredentials(api *userconfig.API) bool {
+	if len(config.ClusterConfig.RegistryCredentials) > 0 {
+		return true
+	}
+	return api.Pod != nil && len(api.Pod.RegistryCredentials) > 0
+}
+
+// ImageWithDigest returns the image reference which is used for a container; if the image's digest was verified when the API was deployed,
+// the image is pulled by its digest, so that the replicas run the verified image even if the image's tag has since been pushed to
+func ImageWithDigest(image string, digest *string) string {
+	if digest == nil {
+		return image
+	}
+	if i := strings.Index(image, "@"); i >= 0 {
+		image = image[:i]
+	}
+	return image + "@" + *digest
+}
+
+// ImagePullSecrets returns the API's image pull secret (which is kept up to date by the operator), or nil if the API has no registry credentials
+func ImagePullSecrets(api *userconfig.API) []kcore.LocalObjectReference {
+	if !HasRegistryCredentials(api) {
+		return nil
+	}
+	return []kcore.LocalObjectReference{{Name: RegistryCredentialsK8sName(api.Name)}}
+}
+
+// the k8s config map which holds the API's runtime config files; the config maps are immutable, so the name includes a hash of the files
+func RuntimeConfigK8sName(apiName string, files map[string]string) string {
+	return K8sName(apiName) + "-config-" + hash.Any(files)[:10]
+}
+
+// RuntimeConfigK8sNameFromPodSpec returns the name of the runtime config map which is mounted by the pod spec, or "" if there is none
+func RuntimeConfigK8sNameFromPodSpec(podSpec kcore.PodSpec) string {
+	for _, volume := range podSpec.Volumes {
+		if volume.Name == _runtimeConfigVolumeName && volume.ConfigMap != nil {
+			return volume.ConfigMap.Name
+		}
+	}
+	return ""
+}
+
+// the prometheus rule which holds the API's alert rules
+func AlertRulesK8sName(apiName string) string {
+	return K8sName(apiName) + "-alerts"
+}
+
+func CIDRWhiteListK8sName(apiName string) string {
+	return K8sName(apiName) + "-cidr-white-list"
+}
+
+func AccessLogsK8sName(apiName string) string {
+	return K8sName(apiName) + "-access-logs"
+}
+
 func GetProbeSpec(probe *userconfig.Probe) *kcore.Probe {
 	if probe == nil {
 		return nil
@@ -206,6 +275,32 @@ func ShmVolume(q resource.Quantity, volumeName string) kcore.Volume {
 	}
 }
 
+// the file system is mounted over nfs, so that the efs csi driver doesn't need to be installed on the cluster
+func EFSVolume() kcore.Volume {
+	return kcore.Volume{
+		Name: _efsVolumeName,
+		VolumeSource: kcore.VolumeSource{
+			NFS: &kcore.NFSVolumeSource{
+				Server: aws.EFSFileSystemDNSName(config.ClusterConfig.EFSFileSystemID, config.ClusterConfig.Region),
+				Path:   "/",
+			},
+		},
+	}
+}
+
+func RuntimeConfigVolume(apiName string, files map[string]string) kcore.Volume {
+	return kcore.Volume{
+		Name: _runtimeConfigVolumeName,
+		VolumeSource: kcore.VolumeSource{
+			ConfigMap: &kcore.ConfigMapVolumeSource{
+				LocalObjectReference: kcore.LocalObjectReference{
+					Name: RuntimeConfigK8sName(apiName, files),
+				},
+			},
+		},
+	}
+}
+
 func KubexitVolume() kcore.Volume {
 	return k8s.EmptyDirVolume(_kubexitGraveyardName)
 }
@@ -245,6 +340,24 @@ func ShmMount(volumeName string) kcore.VolumeMount {
 	return k8s.EmptyDirVolumeMount(volumeName, _shmDirMountPath)
 }
 
+func EFSMount(efsMount userconfig.EFSMount) kcore.VolumeMount {
+	return kcore.VolumeMount{
+		Name:      _efsVolumeName,
+		MountPath: efsMount.MountPath,
+		SubPath:   strings.TrimPrefix(efsMount.Path, "/"),
+		ReadOnly:  efsMount.ReadOnly,
+	}
+}
+
+// SubPath is not used so that the files can be read at a consistent path regardless of the number of files
+func RuntimeConfigMount() kcore.VolumeMount {
+	return kcore.VolumeMount{
+		Name:      _runtimeConfigVolumeName,
+		MountPath: _runtimeConfigMountPath,
+		ReadOnly:  true,
+	}
+}
+
 func KubexitMount() kcore.VolumeMount {
 	return k8s.EmptyDirVolumeMount(_kubexitGraveyardName, _kubexitGraveyardMountPath)
 }
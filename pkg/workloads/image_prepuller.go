@@ -0,0 +1,135 @@
+/*
+Copyright 2021 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workloads
+
+import (
+	"fmt"
+
+	"github.com/cortexlabs/cortex/pkg/config"
+	"github.com/cortexlabs/cortex/pkg/lib/k8s"
+	"github.com/cortexlabs/cortex/pkg/lib/pointer"
+	"github.com/cortexlabs/cortex/pkg/types/clusterconfig"
+	kapps "k8s.io/api/apps/v1"
+	kcore "k8s.io/api/core/v1"
+	kresource "k8s.io/apimachinery/pkg/api/resource"
+)
+
+const (
+	ImagePrePullerLabelKey = "cortex.dev/image-prepuller"
+
+	_imagePrePullerContainerName = "pause"
+	_imagePrePullerNoopName      = "noop"
+	_imagePrePullerNoopDir       = "/cortex-prepuller"
+	_imagePrePullerNoopPath      = _imagePrePullerNoopDir + "/noop"
+)
+
+var (
+	_imagePrePullerCPURequest = kresource.MustParse("1m")
+	_imagePrePullerMemRequest = kresource.MustParse("4Mi")
+)
+
+func ImagePrePullerName(nodeGroupName string) string {
+	return "image-prepuller-" + nodeGroupName
+}
+
+// ImagePrePullerDaemonSet generates a daemonset which runs on every instance of the node group;
+// each image is pulled by an init container (which exits immediately), so that the image is already cached
+// on the instance by the time an API replica gets scheduled onto it (images in private registries are pulled with the APIs' image pull secrets).
+// The init containers run a static no-op binary which is copied from the kubexit image, since the images may not contain a shell (e.g. distroless images)
+func ImagePrePullerDaemonSet(nodeGroup *clusterconfig.NodeGroup, images []string, imagePullSecrets []kcore.LocalObjectReference) *kapps.DaemonSet {
+	noopMount := k8s.EmptyDirVolumeMount(_imagePrePullerNoopName, _imagePrePullerNoopDir)
+
+	initContainers := make([]kcore.Container, 0, len(images)+1)
+	initContainers = append(initContainers, kcore.Container{
+		Name:            _imagePrePullerNoopName,
+		Image:           config.ClusterConfig.ImageKubexit,
+		ImagePullPolicy: kcore.PullIfNotPresent,
+		Command:         []string{"cp", "/bin/noop", _imagePrePullerNoopPath},
+		Resources:       imagePrePullerResources(),
+		VolumeMounts:    []kcore.VolumeMount{noopMount},
+	})
+	for i, image := range images {
+		initContainers = append(initContainers, kcore.Container{
+			Name:            fmt.Sprintf("image-%d", i),
+			Image:           image,
+			ImagePullPolicy: kcore.PullIfNotPresent,
+			Command:         []string{_imagePrePullerNoopPath},
+			Resources:       imagePrePullerResources(),
+			VolumeMounts:    []kcore.VolumeMount{noopMount},
+		})
+	}
+
+	name := ImagePrePullerName(nodeGroup.Name)
+
+	return k8s.DaemonSet(&k8s.DaemonSetSpec{
+		Name: name,
+		Labels: map[string]string{
+			ImagePrePullerLabelKey: "true",
+			"nodeGroup":            nodeGroup.Name,
+		},
+		PodSpec: k8s.PodSpec{
+			Labels: map[string]string{
+				ImagePrePullerLabelKey: "true",
+				"nodeGroup":            nodeGroup.Name,
+			},
+			K8sPodSpec: kcore.PodSpec{
+				InitContainers: initContainers,
+				Containers: []kcore.Container{
+					{
+						Name:            _imagePrePullerContainerName,
+						Image:           config.ClusterConfig.ImageKubexit,
+						ImagePullPolicy: kcore.PullIfNotPresent,
+						Command:         []string{"/bin/sh", "-c", "while true; do sleep 3600; done"},
+						Resources:       imagePrePullerResources(),
+					},
+				},
+				Volumes:                       []kcore.Volume{k8s.EmptyDirVolume(_imagePrePullerNoopName)},
+				TerminationGracePeriodSeconds: pointer.Int64(1),
+				NodeSelector:                  NodeSelectors(),
+				Tolerations:                   GenerateResourceTolerations(),
+				ImagePullSecrets:              imagePullSecrets,
+				Affinity: &kcore.Affinity{
+					NodeAffinity: &kcore.NodeAffinity{
+						RequiredDuringSchedulingIgnoredDuringExecution: &kcore.NodeSelector{
+							NodeSelectorTerms: []kcore.NodeSelectorTerm{
+								{
+									MatchExpressions: []kcore.NodeSelectorRequirement{
+										{
+											Key:      clusterconfig.NodeGroupNameLabelKey,
+											Operator: kcore.NodeSelectorOpIn,
+											Values:   nodeGroup.EKSNames(),
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+				ServiceAccountName: ServiceAccountName,
+			},
+		},
+	})
+}
+
+func imagePrePullerResources() kcore.ResourceRequirements {
+	return kcore.ResourceRequirements{
+		Requests: kcore.ResourceList{
+			kcore.ResourceCPU:    _imagePrePullerCPURequest,
+			kcore.ResourceMemory: _imagePrePullerMemRequest,
+		},
+	}
+}
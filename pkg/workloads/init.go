@@ -18,6 +18,8 @@ package workloads
 
 import (
 	"github.com/cortexlabs/cortex/pkg/config"
+	"github.com/cortexlabs/cortex/pkg/lib/k8s"
+	"github.com/cortexlabs/cortex/pkg/types/spec"
 	kcore "k8s.io/api/core/v1"
 )
 
@@ -36,3 +38,44 @@ func KubexitInitContainer() kcore.Container {
 		},
 	}
 }
+
+// UserPodInitContainers returns the init containers which must run before the user's containers start;
+// the model cache downloader (if any) runs first, so the user's init containers can use the cached artifacts
+func UserPodInitContainers(api spec.API) []kcore.Container {
+	var initContainers []kcore.Container
+	if api.Pod == nil {
+		return initContainers
+	}
+
+	if api.Pod.ModelCache != nil {
+		initContainers = append(initContainers, modelCacheDownloaderContainer(api))
+	}
+
+	// the volumes are added to the pod by userPodContainers()
+	_, containerMounts := userPodVolumes(api)
+
+	for _, initContainer := range api.Pod.InitContainers {
+		containerResourceList := kcore.ResourceList{}
+		if initContainer.Compute != nil && initContainer.Compute.CPU != nil {
+			containerResourceList[kcore.ResourceCPU] = *k8s.QuantityPtr(initContainer.Compute.CPU.Quantity.DeepCopy())
+		}
+		if initContainer.Compute != nil && initContainer.Compute.Mem != nil {
+			containerResourceList[kcore.ResourceMemory] = *k8s.QuantityPtr(initContainer.Compute.Mem.Quantity.DeepCopy())
+		}
+
+		initContainers = append(initContainers, kcore.Container{
+			Name:         initContainer.Name,
+			Image:        ImageWithDigest(initContainer.Image, initContainer.ImageDigest),
+			Command:      initContainer.Command,
+			Args:         initContainer.Args,
+			Env:          userPodEnvVars(api, initContainer.Env),
+			VolumeMounts: containerMounts,
+			Resources: kcore.ResourceRequirements{
+				Requests: containerResourceList,
+			},
+			ImagePullPolicy: kcore.PullAlways,
+		})
+	}
+
+	return initContainers
+}
@@ -17,12 +17,18 @@ limitations under the License.
 package workloads
 
 import (
+	"math"
 	"path"
+	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/cortexlabs/cortex/pkg/config"
 	"github.com/cortexlabs/cortex/pkg/consts"
+	"github.com/cortexlabs/cortex/pkg/lib/aws"
 	"github.com/cortexlabs/cortex/pkg/lib/k8s"
+	"github.com/cortexlabs/cortex/pkg/lib/logging"
+	"github.com/cortexlabs/cortex/pkg/lib/maps"
 	"github.com/cortexlabs/cortex/pkg/lib/pointer"
 	s "github.com/cortexlabs/cortex/pkg/lib/strings"
 	"github.com/cortexlabs/cortex/pkg/types/clusterconfig"
@@ -30,11 +36,14 @@ import (
 	"github.com/cortexlabs/cortex/pkg/types/userconfig"
 	kcore "k8s.io/api/core/v1"
 	kresource "k8s.io/apimachinery/pkg/api/resource"
+	kmeta "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
 const (
 	ServiceAccountName = "default"
+
+	RuntimeConfigDirEnvVar = "CORTEX_CONFIG_DIR"
 )
 
 const (
@@ -56,6 +65,11 @@ const (
 
 	_shmDirMountPath = "/dev/shm"
 
+	_efsVolumeName = "efs"
+
+	_runtimeConfigVolumeName = "runtime-config"
+	_runtimeConfigMountPath  = "/cortex/config"
+
 	_clientConfigDirVolume = "client-config"
 	_clientConfigConfigMap = "client-config"
 
@@ -76,20 +90,34 @@ var (
 )
 
 func AsyncGatewayContainer(api spec.API, queueURL string, volumeMounts []kcore.VolumeMount) kcore.Container {
+	args := []string{
+		"--cluster-config", consts.DefaultInClusterConfigPath,
+		"--port", s.Int32(consts.ProxyListeningPortInt32),
+		"--admin-port", consts.AdminPortStr,
+		"--queue", queueURL,
+	}
+
+	if api.Gateway != nil {
+		args = append(args,
+			"--max-write-latency", api.Gateway.MaxWriteLatency.String(),
+			"--retry-after", api.Gateway.RetryAfter.String(),
+		)
+	}
+
+	if api.Expiration != nil {
+		args = append(args, "--expiration", api.Expiration.String())
+	}
+
 	return kcore.Container{
 		Name:            _gatewayContainerName,
 		Image:           config.ClusterConfig.ImageAsyncGateway,
 		ImagePullPolicy: kcore.PullAlways,
-		Args: []string{
-			"--cluster-config", consts.DefaultInClusterConfigPath,
-			"--port", s.Int32(consts.ProxyListeningPortInt32),
-			"--queue", queueURL,
-			api.Name,
-		},
+		Args:            append(args, api.Name),
 		Ports: []kcore.ContainerPort{
+			{Name: "admin", ContainerPort: consts.AdminPortInt32},
 			{ContainerPort: consts.ProxyListeningPortInt32},
 		},
-		Env: baseEnvVars,
+		Env: baseEnvVars(api),
 		Resources: kcore.ResourceRequirements{
 			Requests: kcore.ResourceList{
 				kcore.ResourceCPU:    _asyncGatewayCPURequest,
@@ -135,7 +163,11 @@ func asyncDequeuerProxyContainer(api spec.API, queueURL string) (kcore.Container
 			"--statsd-port", consts.StatsDPortStr,
 			"--admin-port", consts.AdminPortStr,
 		},
-		Env: append(baseEnvVars, kcore.EnvVar{
+		// the dequeuer's metrics are scraped from the admin port
+		Ports: []kcore.ContainerPort{
+			{Name: "admin", ContainerPort: consts.AdminPortInt32},
+		},
+		Env: append(baseEnvVars(api), kcore.EnvVar{
 			Name: "HOST_IP",
 			ValueFrom: &kcore.EnvVarSource{
 				FieldRef: &kcore.ObjectFieldSelector{
@@ -182,7 +214,11 @@ func batchDequeuerProxyContainer(api spec.API, jobID, queueURL string) (kcore.Co
 			"--statsd-port", consts.StatsDPortStr,
 			"--admin-port", consts.AdminPortStr,
 		},
-		Env: append(baseEnvVars, kcore.EnvVar{
+		// the dequeuer's metrics are scraped from the admin port
+		Ports: []kcore.ContainerPort{
+			{Name: "admin", ContainerPort: consts.AdminPortInt32},
+		},
+		Env: append(baseEnvVars(api), kcore.EnvVar{
 			Name: "HOST_IP",
 			ValueFrom: &kcore.EnvVarSource{
 				FieldRef: &kcore.ObjectFieldSelector{
@@ -211,29 +247,79 @@ func batchDequeuerProxyContainer(api spec.API, jobID, queueURL string) (kcore.Co
 }
 
 func realtimeProxyContainer(api spec.API) (kcore.Container, kcore.Volume) {
+	args := []string{
+		"--cluster-config",
+		consts.DefaultInClusterConfigPath,
+		"--port",
+		consts.ProxyListeningPortStr,
+		"--admin-port",
+		consts.AdminPortStr,
+		"--user-port",
+		s.Int32(*api.Pod.Port),
+		"--max-concurrency",
+		s.Int32(int32(api.Pod.MaxConcurrency)),
+		"--max-queue-length",
+		s.Int32(int32(api.Pod.MaxQueueLength)),
+		"--api-name",
+		api.Name,
+	}
+
+	if api.PayloadLogging != nil {
+		args = append(args,
+			"--payload-logging-s3-path",
+			api.PayloadLogging.S3Path,
+			"--payload-logging-sample-rate",
+			s.Float64(api.PayloadLogging.SampleRate),
+			"--payload-logging-max-body-size",
+			s.Int64(api.PayloadLogging.MaxBodySize),
+			"--payload-logging-flush-interval",
+			api.PayloadLogging.FlushInterval.String(),
+		)
+	}
+
+	if api.Proxy != nil {
+		if api.Proxy.UpstreamTimeout != nil {
+			args = append(args, "--upstream-timeout", api.Proxy.UpstreamTimeout.String())
+		}
+		if api.Proxy.IdleTimeout != nil {
+			args = append(args, "--idle-timeout", api.Proxy.IdleTimeout.String())
+		}
+		if api.Proxy.MaxRequestBodySize != nil {
+			args = append(args, "--max-request-body-size", s.Int64(*api.Proxy.MaxRequestBodySize))
+		}
+		if api.Proxy.MaxConcurrentStreams != nil {
+			args = append(args, "--max-concurrent-streams", s.Int32(*api.Proxy.MaxConcurrentStreams))
+		}
+		if api.Proxy.FairQueuing != nil {
+			args = append(args,
+				"--fair-queuing-header",
+				api.Proxy.FairQueuing.Header,
+				"--fair-queuing-default-weight",
+				s.Int32(api.Proxy.FairQueuing.DefaultWeight),
+			)
+			for _, clientWeight := range api.Proxy.FairQueuing.Clients {
+				args = append(args, "--fair-queuing-weight", clientWeight.Client+"="+s.Int32(clientWeight.Weight))
+			}
+		}
+	}
+
+	if api.Models != nil {
+		args = append(args,
+			"--model-manager-port",
+			consts.ModelManagerPortStr,
+		)
+	}
+
 	return kcore.Container{
 		Name:            _proxyContainerName,
 		Image:           config.ClusterConfig.ImageProxy,
 		ImagePullPolicy: kcore.PullAlways,
-		Args: []string{
-			"--cluster-config",
-			consts.DefaultInClusterConfigPath,
-			"--port",
-			consts.ProxyListeningPortStr,
-			"--admin-port",
-			consts.AdminPortStr,
-			"--user-port",
-			s.Int32(*api.Pod.Port),
-			"--max-concurrency",
-			s.Int32(int32(api.Pod.MaxConcurrency)),
-			"--max-queue-length",
-			s.Int32(int32(api.Pod.MaxQueueLength)),
-		},
+		Args:            args,
 		Ports: []kcore.ContainerPort{
 			{Name: "admin", ContainerPort: consts.AdminPortInt32},
 			{ContainerPort: consts.ProxyListeningPortInt32},
 		},
-		Env:     baseEnvVars,
+		Env:     baseEnvVars(api),
 		EnvFrom: baseClusterEnvVars(),
 		VolumeMounts: []kcore.VolumeMount{
 			ClusterConfigMount(),
@@ -261,6 +347,12 @@ func RealtimeContainers(api spec.API) ([]kcore.Container, []kcore.Volume) {
 	containers = append(containers, proxyContainer)
 	volumes = append(volumes, proxyVolume)
 
+	if api.Models != nil {
+		modelManagerContainer, modelsVolume := modelManagerContainer(api)
+		containers = append(containers, modelManagerContainer)
+		volumes = append(volumes, modelsVolume)
+	}
+
 	return containers, volumes
 }
 
@@ -343,7 +435,8 @@ func BatchContainers(api spec.API, job *spec.BatchJob) ([]kcore.Container, []kco
 	return containers, volumes
 }
 
-func userPodContainers(api spec.API) ([]kcore.Container, []kcore.Volume) {
+// returns the volumes which are mounted into all of the user's containers (including init containers)
+func userPodVolumes(api spec.API) ([]kcore.Volume, []kcore.VolumeMount) {
 	volumes := []kcore.Volume{
 		MntVolume(),
 		CortexVolume(),
@@ -355,6 +448,114 @@ func userPodContainers(api spec.API) ([]kcore.Container, []kcore.Volume) {
 		ClientConfigMount(),
 	}
 
+	if api.Pod.ModelCache != nil {
+		volumes = append(volumes, ModelCacheVolume(api.Pod.ModelCache))
+		containerMounts = append(containerMounts, ModelCacheMount())
+	}
+
+	// the volume is added with the model manager's container
+	if api.Models != nil {
+		containerMounts = append(containerMounts, ModelsMount())
+	}
+
+	if api.Pod.EFS != nil {
+		volumes = append(volumes, EFSVolume())
+		containerMounts = append(containerMounts, EFSMount(*api.Pod.EFS))
+	}
+
+	if api.Pod.Config != nil && len(api.Pod.Config.Files) > 0 {
+		volumes = append(volumes, RuntimeConfigVolume(api.Name, api.Pod.Config.Files))
+		containerMounts = append(containerMounts, RuntimeConfigMount())
+	}
+
+	return volumes, containerMounts
+}
+
+// returns the environment variables which are set in all of the user's containers (including init containers)
+func userPodEnvVars(api spec.API, containerEnv map[string]string) []kcore.EnvVar {
+	envVars := baseEnvVars(api)
+
+	envVars = append(envVars, kcore.EnvVar{
+		Name:  "CORTEX_CLI_CONFIG_DIR",
+		Value: _clientConfigDir,
+	})
+
+	if api.Pod.ModelCache != nil {
+		envVars = append(envVars, kcore.EnvVar{
+			Name:  ModelCacheDirEnvVar,
+			Value: _modelCacheMountPath,
+		})
+	}
+
+	if api.Models != nil {
+		envVars = append(envVars, kcore.EnvVar{
+			Name:  ModelsDirEnvVar,
+			Value: _modelsMountPath,
+		})
+	}
+
+	if api.Kind != userconfig.TaskAPIKind {
+		envVars = append(envVars, kcore.EnvVar{
+			Name:  "CORTEX_PORT",
+			Value: s.Int32(*api.Pod.Port),
+		})
+	}
+
+	if api.Kind == userconfig.BatchAPIKind {
+		// served by the dequeuer sidecar
+		envVars = append(envVars, kcore.EnvVar{
+			Name:  "CORTEX_CHECKPOINT_URL",
+			Value: "http://localhost:" + consts.AdminPortStr + "/checkpoints",
+		})
+	}
+
+	if api.Pod.Config != nil {
+		if len(api.Pod.Config.Files) > 0 {
+			envVars = append(envVars, kcore.EnvVar{
+				Name:  RuntimeConfigDirEnvVar,
+				Value: _runtimeConfigMountPath,
+			})
+		}
+
+		// the keys are sorted so that the pod spec is deterministic
+		envKeys := maps.StrMapKeysString(api.Pod.Config.Env)
+		sort.Strings(envKeys)
+		for _, k := range envKeys {
+			envVars = append(envVars, kcore.EnvVar{
+				Name:  k,
+				Value: api.Pod.Config.Env[k],
+			})
+		}
+	}
+
+	// the container's env is added after the runtime config's env so that it takes precedence
+	for k, v := range containerEnv {
+		envVars = append(envVars, kcore.EnvVar{
+			Name:  k,
+			Value: v,
+		})
+	}
+
+	for _, secret := range api.Pod.Secrets {
+		envVars = append(envVars, kcore.EnvVar{
+			Name: secret.Name,
+			ValueFrom: &kcore.EnvVarSource{
+				SecretKeyRef: &kcore.SecretKeySelector{
+					LocalObjectReference: kcore.LocalObjectReference{
+						Name: SecretsK8sName(api.Name),
+					},
+					Key: secret.Name,
+				},
+			},
+		})
+	}
+
+	return envVars
+}
+
+func userPodContainers(api spec.API) ([]kcore.Container, []kcore.Volume) {
+	volumes, containerMounts := userPodVolumes(api)
+
 	var containers []kcore.Container
 	for _, container := range api.Pod.Containers {
 		containerResourceList := kcore.ResourceList{}
@@ -362,6 +563,10 @@ func userPodContainers(api spec.API) ([]kcore.Container, []kcore.Volume) {
 		securityContext := kcore.SecurityContext{
 			Privileged: pointer.Bool(true),
 		}
+		if api.OS == aws.OSWindows {
+			// windows containers can't be privileged
+			securityContext.Privileged = nil
+		}
 
 		var readinessProbe *kcore.Probe
 		if api.Kind == userconfig.RealtimeAPIKind {
@@ -377,16 +582,25 @@ func userPodContainers(api spec.API) ([]kcore.Container, []kcore.Volume) {
 		}
 
 		if container.Compute.GPU > 0 {
-			containerResourceList["nvidia.com/gpu"] = *kresource.NewQuantity(container.Compute.GPU, kresource.DecimalSI)
-			containerResourceLimitsList["nvidia.com/gpu"] = *kresource.NewQuantity(container.Compute.GPU, kresource.DecimalSI)
+			if sharingFactor := gpuSharingFactor(api); sharingFactor > 1 {
+				// each time-sliced replica of a shared GPU is advertised as one unit of the shared resource
+				sharedGPUs := int64(math.Round(container.Compute.GPU * float64(sharingFactor)))
+				containerResourceList[clusterconfig.SharedGPUResourceName] = *kresource.NewQuantity(sharedGPUs, kresource.DecimalSI)
+				containerResourceLimitsList[clusterconfig.SharedGPUResourceName] = *kresource.NewQuantity(sharedGPUs, kresource.DecimalSI)
+			} else {
+				containerResourceList["nvidia.com/gpu"] = *kresource.NewQuantity(int64(container.Compute.GPU), kresource.DecimalSI)
+				containerResourceLimitsList["nvidia.com/gpu"] = *kresource.NewQuantity(int64(container.Compute.GPU), kresource.DecimalSI)
+			}
 		}
 
 		if container.Compute.Inf > 0 {
-			totalHugePages := container.Compute.Inf * _hugePagesMemPerInf
-			containerResourceList["nvidia.com/gpu"] = *kresource.NewQuantity(container.Compute.Inf, kresource.DecimalSI)
-			containerResourceList["hugepages-2Mi"] = *kresource.NewQuantity(totalHugePages, kresource.BinarySI)
-			containerResourceLimitsList["nvidia.com/gpu"] = *kresource.NewQuantity(container.Compute.Inf, kresource.DecimalSI)
-			containerResourceLimitsList["hugepages-2Mi"] = *kresource.NewQuantity(totalHugePages, kresource.BinarySI)
+			containerResourceList["aws.amazon.com/neuron"] = *kresource.NewQuantity(container.Compute.Inf, kresource.DecimalSI)
+			containerResourceLimitsList["aws.amazon.com/neuron"] = *kresource.NewQuantity(container.Compute.Inf, kresource.DecimalSI)
+			if requiresNeuronHugePages(api) {
+				totalHugePages := container.Compute.Inf * _hugePagesMemPerInf
+				containerResourceList["hugepages-2Mi"] = *kresource.NewQuantity(totalHugePages, kresource.BinarySI)
+				containerResourceLimitsList["hugepages-2Mi"] = *kresource.NewQuantity(totalHugePages, kresource.BinarySI)
+			}
 
 			securityContext.Capabilities = &kcore.Capabilities{
 				Add: []kcore.Capability{
@@ -401,36 +615,18 @@ func userPodContainers(api spec.API) ([]kcore.Container, []kcore.Volume) {
 			containerMounts = append(containerMounts, ShmMount("dshm-"+container.Name))
 		}
 
-		containerEnvVars := baseEnvVars
-
-		containerEnvVars = append(containerEnvVars, kcore.EnvVar{
-			Name:  "CORTEX_CLI_CONFIG_DIR",
-			Value: _clientConfigDir,
-		})
-
-		if api.Kind != userconfig.TaskAPIKind {
-			containerEnvVars = append(containerEnvVars, kcore.EnvVar{
-				Name:  "CORTEX_PORT",
-				Value: s.Int32(*api.Pod.Port),
-			})
-		}
-
-		for k, v := range container.Env {
-			containerEnvVars = append(containerEnvVars, kcore.EnvVar{
-				Name:  k,
-				Value: v,
-			})
-		}
+		containerEnvVars := userPodEnvVars(api, container.Env)
 
 		containers = append(containers, kcore.Container{
 			Name:           container.Name,
-			Image:          container.Image,
+			Image:          ImageWithDigest(container.Image, container.ImageDigest),
 			Command:        container.Command,
 			Args:           container.Args,
 			Env:            containerEnvVars,
 			VolumeMounts:   containerMounts,
 			LivenessProbe:  GetProbeSpec(container.LivenessProbe),
 			ReadinessProbe: readinessProbe,
+			StartupProbe:   GetProbeSpec(container.StartupProbe),
 			Resources: kcore.ResourceRequirements{
 				Requests: containerResourceList,
 				Limits:   containerResourceLimitsList,
@@ -443,6 +639,65 @@ func userPodContainers(api spec.API) ([]kcore.Container, []kcore.Volume) {
 	return containers, volumes
 }
 
+// requiresNeuronHugePages returns true if the api's Inferentia containers will run on inf1 instances, which require hugepages
+// (the operator doesn't allow apis to run on both inf1 and inf2/trn1 node groups)
+func requiresNeuronHugePages(api spec.API) bool {
+	for _, ng := range config.ClusterConfig.GetNodeGroupsForAPI(api.NodeGroups, api.Arch, api.OS) {
+		if requiresHugePages, _ := aws.RequiresNeuronHugePages(ng.InstanceType); requiresHugePages {
+			return true
+		}
+	}
+	return false
+}
+
+// gpuSharingFactor returns the number of replicas which share each GPU on the node groups which the api's fractional GPU requests can be scheduled on
+// (all of these node groups are validated to have the same sharing factor when the api is deployed), or 1 if the api requests whole GPUs
+func gpuSharingFactor(api spec.API) int64 {
+	if !api.Pod.RequestsFractionalGPUs() {
+		return 1
+	}
+	for _, ng := range config.ClusterConfig.GetNodeGroupsForAPI(api.NodeGroups, api.Arch, api.OS) {
+		if ng.SharesGPUs() {
+			return ng.GPUSharingFactor
+		}
+	}
+	return 1
+}
+
+// GPUsOnNode returns the number of physical GPUs which the resources (e.g. a node's allocatable resources or a pod's requests) represent on the node,
+// counting the time-sliced replicas of shared GPUs as fractions of a GPU
+func GPUsOnNode(node *kcore.Node, resources kcore.ResourceList) float64 {
+	gpuQty := resources["nvidia.com/gpu"]
+	gpus := float64(gpuQty.Value())
+
+	if sharedGPUQty, ok := resources[clusterconfig.SharedGPUResourceName]; ok {
+		sharingFactor, err := strconv.ParseInt(node.Labels[clusterconfig.GPUSharingFactorLabelKey], 10, 64)
+		if err == nil && sharingFactor > 0 {
+			gpus += float64(sharedGPUQty.Value()) / float64(sharingFactor)
+		}
+	}
+
+	return gpus
+}
+
+// IsSpotNode returns true if the node is a spot instance (the nodes of spot EKS node groups are labeled by eksctl, and the nodes which are launched by karpenter are labeled with their capacity type)
+func IsSpotNode(node *kcore.Node) bool {
+	return strings.Contains(strings.ToLower(node.Labels["lifecycle"]), "spot") || node.Labels[KarpenterCapacityTypeLabelKey] == "spot"
+}
+
+// APIPodAnnotations returns the annotations for the pods of long-running api workloads;
+// when mtls is enabled, an istio sidecar is injected to terminate mutual TLS in front of the proxy/gateway container
+// (it is not injected into job pods, since the sidecar would keep them from completing)
+func APIPodAnnotations() map[string]string {
+	annotations := map[string]string{
+		"traffic.sidecar.istio.io/excludeOutboundIPRanges": "0.0.0.0/0",
+	}
+	if config.ClusterConfig.MTLS != nil {
+		annotations["sidecar.istio.io/inject"] = "true"
+	}
+	return annotations
+}
+
 func NodeSelectors() map[string]string {
 	return map[string]string{
 		"workload": "true",
@@ -473,7 +728,24 @@ func GenerateResourceTolerations() []kcore.Toleration {
 	return tolerations
 }
 
-func GenerateNodeAffinities(apiNodeGroups []string) *kcore.Affinity {
+// GenerateOSTolerations returns the tolerations which allow the pods of an api with the given operating system to run on its node groups
+// (windows node groups are tainted so that linux pods aren't scheduled on them)
+func GenerateOSTolerations(os string) []kcore.Toleration {
+	if os != aws.OSWindows {
+		return nil
+	}
+
+	return []kcore.Toleration{
+		{
+			Key:      clusterconfig.WindowsTaintKey,
+			Operator: kcore.TolerationOpEqual,
+			Value:    aws.OSWindows,
+			Effect:   kcore.TaintEffectNoSchedule,
+		},
+	}
+}
+
+func GenerateNodeAffinities(apiNodeGroups []string, arch string, os string) *kcore.Affinity {
 	// node groups are ordered according to how the cluster config node groups are ordered
 	var nodeGroups []*clusterconfig.NodeGroup
 	for _, clusterNodeGroup := range config.ClusterConfig.NodeGroups {
@@ -494,60 +766,134 @@ func GenerateNodeAffinities(apiNodeGroups []string) *kcore.Affinity {
 	var preferredAffinities []kcore.PreferredSchedulingTerm
 
 	for idx, nodeGroup := range nodeGroups {
-		var nodeGroupPrefix string
-		if nodeGroup.Spot {
-			nodeGroupPrefix = "cx-ws-"
-		} else {
-			nodeGroupPrefix = "cx-wd-"
-		}
-
 		preferredAffinities = append(preferredAffinities, kcore.PreferredSchedulingTerm{
 			Weight: int32(100 * (1 - float64(idx)/float64(numNodeGroups))),
 			Preference: kcore.NodeSelectorTerm{
 				MatchExpressions: []kcore.NodeSelectorRequirement{
 					{
-						Key:      "alpha.eksctl.io/nodegroup-name",
+						Key:      clusterconfig.NodeGroupNameLabelKey,
 						Operator: kcore.NodeSelectorOpIn,
-						Values:   []string{nodeGroupPrefix + nodeGroup.Name},
+						Values:   nodeGroup.EKSNames(),
 					},
 				},
 			},
 		})
-		requiredNodeGroups = append(requiredNodeGroups, nodeGroupPrefix+nodeGroup.Name)
+		requiredNodeGroups = append(requiredNodeGroups, nodeGroup.EKSNames()...)
 	}
 
-	var requiredNodeSelector *kcore.NodeSelector
+	// the pods can only run on nodes with the API's CPU architecture and operating system, since the containers' images may not be multi-platform
+	requiredExpressions := []kcore.NodeSelectorRequirement{
+		{
+			Key:      kcore.LabelArchStable,
+			Operator: kcore.NodeSelectorOpIn,
+			Values:   []string{arch},
+		},
+		{
+			Key:      kcore.LabelOSStable,
+			Operator: kcore.NodeSelectorOpIn,
+			Values:   []string{os},
+		},
+	}
 	if apiNodeGroups != nil {
-		requiredNodeSelector = &kcore.NodeSelector{
-			NodeSelectorTerms: []kcore.NodeSelectorTerm{
-				{
-					MatchExpressions: []kcore.NodeSelectorRequirement{
-						{
-							Key:      "alpha.eksctl.io/nodegroup-name",
-							Operator: kcore.NodeSelectorOpIn,
-							Values:   requiredNodeGroups,
-						},
-					},
-				},
-			},
-		}
+		requiredExpressions = append(requiredExpressions, kcore.NodeSelectorRequirement{
+			Key:      clusterconfig.NodeGroupNameLabelKey,
+			Operator: kcore.NodeSelectorOpIn,
+			Values:   requiredNodeGroups,
+		})
 	}
 
 	return &kcore.Affinity{
 		NodeAffinity: &kcore.NodeAffinity{
 			PreferredDuringSchedulingIgnoredDuringExecution: preferredAffinities,
-			RequiredDuringSchedulingIgnoredDuringExecution:  requiredNodeSelector,
+			RequiredDuringSchedulingIgnoredDuringExecution: &kcore.NodeSelector{
+				NodeSelectorTerms: []kcore.NodeSelectorTerm{
+					{
+						MatchExpressions: requiredExpressions,
+					},
+				},
+			},
 		},
 	}
 }
 
-var baseEnvVars = []kcore.EnvVar{
-	{
-		Name:  "CORTEX_VERSION",
-		Value: consts.CortexVersion,
-	},
-	{
-		Name:  "CORTEX_LOG_LEVEL",
-		Value: strings.ToUpper(userconfig.InfoLogLevel.String()),
-	},
+// spreads the API's replicas evenly across availability zones and/or nodes; selector must match the labels of the API's pods
+func GenerateTopologySpreadConstraints(availability *userconfig.Availability, selector map[string]string) []kcore.TopologySpreadConstraint {
+	if availability == nil {
+		return nil
+	}
+
+	// the order must be deterministic, otherwise the pod template would change on every update
+	topologies := []struct {
+		key    string
+		spread userconfig.TopologySpread
+	}{
+		{key: "topology.kubernetes.io/zone", spread: availability.ZoneSpread},
+		{key: "kubernetes.io/hostname", spread: availability.NodeSpread},
+	}
+
+	var constraints []kcore.TopologySpreadConstraint
+	for _, topology := range topologies {
+		var whenUnsatisfiable kcore.UnsatisfiableConstraintAction
+		switch topology.spread {
+		case userconfig.PreferredTopologySpread:
+			whenUnsatisfiable = kcore.ScheduleAnyway
+		case userconfig.RequiredTopologySpread:
+			whenUnsatisfiable = kcore.DoNotSchedule
+		default:
+			continue
+		}
+
+		constraints = append(constraints, kcore.TopologySpreadConstraint{
+			MaxSkew:           1,
+			TopologyKey:       topology.key,
+			WhenUnsatisfiable: whenUnsatisfiable,
+			LabelSelector:     &kmeta.LabelSelector{MatchLabels: selector},
+		})
+	}
+
+	return constraints
+}
+
+// returns the environment variables which are set in all of the api's containers
+func baseEnvVars(api spec.API) []kcore.EnvVar {
+	return append([]kcore.EnvVar{
+		{
+			Name:  "CORTEX_VERSION",
+			Value: consts.CortexVersion,
+		},
+	}, LogEnvVars(api)...)
+}
+
+// LogEnvVars returns the environment variables which configure the logs of the cortex containers of the api (see logging.ZapConfigFromEnv());
+// the api's logging configuration takes precedence over the cluster's
+func LogEnvVars(api spec.API) []kcore.EnvVar {
+	logLevel, logFormat := userconfig.InfoLogLevel, userconfig.JSONLogFormat
+	if config.ClusterConfig.Logging != nil {
+		logLevel, logFormat = config.ClusterConfig.Logging.Level, config.ClusterConfig.Logging.Format
+	}
+	if api.Logging != nil && api.Logging.Level != nil {
+		logLevel = *api.Logging.Level
+	}
+	if api.Logging != nil && api.Logging.Format != nil {
+		logFormat = *api.Logging.Format
+	}
+
+	return []kcore.EnvVar{
+		{
+			Name:  logging.LogLevelEnvVar,
+			Value: strings.ToUpper(logLevel.String()),
+		},
+		{
+			Name:  logging.LogFormatEnvVar,
+			Value: logFormat.String(),
+		},
+		{
+			Name:  logging.APINameEnvVar,
+			Value: api.Name,
+		},
+		{
+			Name:  logging.ClusterUIDEnvVar,
+			Value: config.ClusterConfig.ClusterUID,
+		},
+	}
 }
@@ -0,0 +1,225 @@
+/*
+Copyright 2021 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workloads
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/cortexlabs/cortex/pkg/config"
+	"github.com/cortexlabs/cortex/pkg/lib/aws"
+	"github.com/cortexlabs/cortex/pkg/lib/k8s"
+	"github.com/cortexlabs/cortex/pkg/types/clusterconfig"
+	kunstructured "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const (
+	KarpenterLabelKey = "cortex.dev/karpenter"
+
+	// the label which karpenter sets to the capacity type of the instances which it launches ("spot" or "on-demand")
+	KarpenterCapacityTypeLabelKey = "karpenter.sh/capacity-type"
+)
+
+// KarpenterName returns the name of the provisioner and node template which are generated for the node group
+func KarpenterName(nodeGroupName string) string {
+	return "cx-" + nodeGroupName
+}
+
+// KarpenterProvisioner generates the provisioner which launches the instances of the node group; idx is the node group's position in the cluster config,
+// which determines the provisioner's weight (so that karpenter prefers the node groups in the order in which they are listed, like the pods' node affinities do).
+// The nodes are labeled and tainted like the nodes of the corresponding EKS node group, so the api pods' node affinities and tolerations apply to them unchanged
+func KarpenterProvisioner(nodeGroup *clusterconfig.NodeGroup, idx int) *kunstructured.Unstructured {
+	numNodeGroups := len(config.ClusterConfig.NodeGroups)
+
+	capacityTypes := []interface{}{"on-demand"}
+	if nodeGroup.Spot {
+		capacityTypes = []interface{}{"spot"}
+		if nodeGroup.HasOnDemandBackup() {
+			// karpenter prefers spot instances, and falls back to on-demand instances when no spot capacity is available
+			capacityTypes = []interface{}{"spot", "on-demand"}
+		}
+	}
+
+	instanceTypes := []interface{}{nodeGroup.InstanceType}
+	if nodeGroup.Spot && nodeGroup.SpotConfig != nil {
+		for _, instanceType := range nodeGroup.SpotConfig.InstanceDistribution {
+			if instanceType != nodeGroup.InstanceType {
+				instanceTypes = append(instanceTypes, instanceType)
+			}
+		}
+	}
+
+	requirements := []interface{}{
+		map[string]interface{}{
+			"key":      "node.kubernetes.io/instance-type",
+			"operator": "In",
+			"values":   instanceTypes,
+		},
+		map[string]interface{}{
+			"key":      KarpenterCapacityTypeLabelKey,
+			"operator": "In",
+			"values":   capacityTypes,
+		},
+	}
+	if len(config.ClusterConfig.AvailabilityZones) > 0 {
+		zones := make([]interface{}, 0, len(config.ClusterConfig.AvailabilityZones))
+		for _, zone := range config.ClusterConfig.AvailabilityZones {
+			zones = append(zones, zone)
+		}
+		requirements = append(requirements, map[string]interface{}{
+			"key":      "topology.kubernetes.io/zone",
+			"operator": "In",
+			"values":   zones,
+		})
+	}
+
+	nodeLabels := map[string]interface{}{
+		"workload":                          "true",
+		clusterconfig.NodeGroupNameLabelKey: nodeGroup.EKSName(),
+	}
+	taints := []interface{}{
+		map[string]interface{}{"key": "workload", "value": "true", "effect": "NoSchedule"},
+	}
+	if isGPU, _ := aws.IsGPUInstance(nodeGroup.InstanceType); isGPU {
+		nodeLabels["nvidia.com/gpu"] = "true"
+		nodeLabels["k8s.amazonaws.com/accelerator"] = "true"
+		taints = append(taints, map[string]interface{}{"key": "nvidia.com/gpu", "value": "true", "effect": "NoSchedule"})
+		if nodeGroup.SharesGPUs() {
+			nodeLabels[clusterconfig.GPUSharingFactorLabelKey] = strconv.FormatInt(nodeGroup.GPUSharingFactor, 10)
+		}
+	}
+	if neuronCores, _ := aws.NeuronCoresPerInf(nodeGroup.InstanceType); neuronCores > 0 {
+		nodeLabels["aws.amazon.com/neuron"] = "true"
+		taints = append(taints, map[string]interface{}{"key": "aws.amazon.com/neuron", "value": "true", "effect": "NoSchedule"})
+	}
+
+	spec := map[string]interface{}{
+		"weight":       int64(100 * (1 - float64(idx)/float64(numNodeGroups))),
+		"requirements": requirements,
+		"labels":       nodeLabels,
+		"taints":       taints,
+		"consolidation": map[string]interface{}{
+			"enabled": true,
+		},
+		// matches the kubelet configuration of the EKS node groups (see manager/generate_eks.py)
+		"kubeletConfiguration": map[string]interface{}{
+			"kubeReserved":   map[string]interface{}{"cpu": "150m", "memory": "300Mi", "ephemeral-storage": "1Gi"},
+			"systemReserved": map[string]interface{}{"cpu": "150m", "memory": "300Mi", "ephemeral-storage": "1Gi"},
+			"evictionHard":   map[string]interface{}{"memory.available": "200Mi", "nodefs.available": "5%"},
+		},
+		"providerRef": map[string]interface{}{
+			"name": KarpenterName(nodeGroup.Name),
+		},
+	}
+
+	// max_instances is enforced as a limit on the total number of vCPUs of the provisioner's instances
+	if instanceMetadata, ok := aws.InstanceMetadatas[config.ClusterConfig.Region][nodeGroup.InstanceType]; ok {
+		spec["limits"] = map[string]interface{}{
+			"resources": map[string]interface{}{
+				"cpu": strconv.FormatInt(nodeGroup.MaxInstances*instanceMetadata.CPU.Value(), 10),
+			},
+		}
+	}
+
+	return k8s.KarpenterProvisioner(KarpenterName(nodeGroup.Name), karpenterLabels(nodeGroup), spec)
+}
+
+// KarpenterNodeTemplate generates the node template which configures the AWS resources of the node group's instances
+// (karpenter launches them with its default instance profile, which is the instance profile of the operator node group)
+func KarpenterNodeTemplate(nodeGroup *clusterconfig.NodeGroup) *kunstructured.Unstructured {
+	subnetSelector := map[string]interface{}{
+		clusterconfig.ClusterNameTag: config.ClusterConfig.ClusterName,
+	}
+	if len(config.ClusterConfig.Subnets) > 0 {
+		subnetIDs := make([]string, 0, len(config.ClusterConfig.Subnets))
+		for _, subnet := range config.ClusterConfig.Subnets {
+			subnetIDs = append(subnetIDs, subnet.SubnetID)
+		}
+		subnetSelector = map[string]interface{}{
+			"aws-ids": strings.Join(subnetIDs, ","),
+		}
+	} else if config.ClusterConfig.SubnetVisibility == clusterconfig.PrivateSubnetVisibility {
+		subnetSelector["kubernetes.io/role/internal-elb"] = "1"
+	} else {
+		subnetSelector["kubernetes.io/role/elb"] = "1"
+	}
+
+	blockDevice := map[string]interface{}{
+		"volumeSize": strconv.FormatInt(nodeGroup.InstanceVolumeSize, 10) + "Gi",
+		"volumeType": nodeGroup.InstanceVolumeType.String(),
+		"encrypted":  true,
+	}
+	if nodeGroup.InstanceVolumeIOPS != nil {
+		blockDevice["iops"] = *nodeGroup.InstanceVolumeIOPS
+	}
+	if nodeGroup.InstanceVolumeThroughput != nil {
+		blockDevice["throughput"] = *nodeGroup.InstanceVolumeThroughput
+	}
+
+	httpTokens := "optional"
+	if config.ClusterConfig.RequireIMDSv2 {
+		httpTokens = "required"
+	}
+
+	tags := make(map[string]interface{}, len(config.ClusterConfig.Tags))
+	for key, value := range config.ClusterConfig.Tags {
+		tags[key] = value
+	}
+
+	// the first volume of bottlerocket instances only contains the OS, and the container images and logs are stored on the second one
+	amiFamily := "AL2"
+	deviceName := "/dev/xvda"
+	if nodeGroup.AMIFamily == clusterconfig.AMIFamilyBottlerocket {
+		amiFamily = "Bottlerocket"
+		deviceName = "/dev/xvdb"
+	}
+
+	spec := map[string]interface{}{
+		"amiFamily":      amiFamily,
+		"subnetSelector": subnetSelector,
+		// the security group which EKS creates for the cluster allows all traffic between the nodes and the control plane
+		"securityGroupSelector": map[string]interface{}{
+			"aws:eks:cluster-name": config.ClusterConfig.ClusterName,
+		},
+		"blockDeviceMappings": []interface{}{
+			map[string]interface{}{
+				"deviceName": deviceName,
+				"ebs":        blockDevice,
+			},
+		},
+		"metadataOptions": map[string]interface{}{
+			"httpEndpoint":            "enabled",
+			"httpTokens":              httpTokens,
+			"httpPutResponseHopLimit": int64(2),
+		},
+		"tags": tags,
+	}
+
+	// karpenter merges the script into the user data which bootstraps the instance (before the bootstrap script runs)
+	if len(nodeGroup.PreBootstrapCommands) > 0 {
+		spec["userData"] = "#!/bin/bash\n" + strings.Join(nodeGroup.PreBootstrapCommands, "\n") + "\n"
+	}
+
+	return k8s.KarpenterNodeTemplate(KarpenterName(nodeGroup.Name), karpenterLabels(nodeGroup), spec)
+}
+
+func karpenterLabels(nodeGroup *clusterconfig.NodeGroup) map[string]string {
+	return map[string]string{
+		KarpenterLabelKey: "true",
+		"nodeGroup":       nodeGroup.Name,
+	}
+}
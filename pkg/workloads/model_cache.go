@@ -0,0 +1,100 @@
+/*
+Copyright 2021 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workloads
+
+import (
+	"path"
+
+	"github.com/cortexlabs/cortex/pkg/config"
+	"github.com/cortexlabs/cortex/pkg/lib/hash"
+	libjson "github.com/cortexlabs/cortex/pkg/lib/json"
+	"github.com/cortexlabs/cortex/pkg/lib/pointer"
+	"github.com/cortexlabs/cortex/pkg/types/spec"
+	"github.com/cortexlabs/cortex/pkg/types/userconfig"
+	kcore "k8s.io/api/core/v1"
+)
+
+const (
+	ModelCacheDirEnvVar     = "CORTEX_MODEL_CACHE_DIR"
+	DownloaderContainerName = "downloader"
+
+	_modelCacheVolumeName = "model-cache"
+	_modelCacheMountPath  = "/model-cache"
+
+	// the cache directories which aren't used by any of the node's pods are deleted by the model-cache-gc daemonset (see cmd/downloader/gc.go)
+	_modelCacheHostPathRoot = "/var/lib/cortex/model-cache"
+)
+
+// the downloader holds a lock on the node's cache directory while downloading,
+// so the artifacts are only fetched from S3 once per node (rather than once per replica)
+func modelCacheDownloaderContainer(api spec.API) kcore.Container {
+	args := []string{
+		"--region", config.ClusterConfig.Region,
+		"--s3-path", api.Pod.ModelCache.Path,
+		"--cache-dir", _modelCacheMountPath,
+	}
+	// the downloader also verifies the artifacts (including artifacts which were already cached on the node) before the user's containers start
+	if api.Pod.ModelCache.Integrity != nil {
+		integrityJSON, _ := libjson.MarshalJSONStr(spec.IntegritySpec(api.Pod.ModelCache.Integrity))
+		args = append(args, "--integrity", integrityJSON)
+	}
+
+	return kcore.Container{
+		Name:            DownloaderContainerName,
+		Image:           config.ClusterConfig.ImageDownloader,
+		ImagePullPolicy: kcore.PullAlways,
+		Args:            args,
+		Env:             baseEnvVars(api),
+		VolumeMounts: []kcore.VolumeMount{
+			{
+				Name:      _modelCacheVolumeName,
+				MountPath: _modelCacheMountPath,
+			},
+		},
+		SecurityContext: &kcore.SecurityContext{
+			RunAsUser: pointer.Int64(0),
+		},
+	}
+}
+
+// the cache directory on the node is keyed by the S3 path, so that all APIs which use the same artifacts share it; if the path is watched,
+// the key also includes the version of the artifacts, so that the replicas of a rolling update don't use the previous version's cache
+func ModelCacheVolume(modelCache *userconfig.ModelCache) kcore.Volume {
+	cacheKey := modelCache.Path
+	if modelCache.ArtifactVersion != "" {
+		cacheKey += "@" + modelCache.ArtifactVersion
+	}
+
+	hostPathType := kcore.HostPathDirectoryOrCreate
+	return kcore.Volume{
+		Name: _modelCacheVolumeName,
+		VolumeSource: kcore.VolumeSource{
+			HostPath: &kcore.HostPathVolumeSource{
+				Path: path.Join(_modelCacheHostPathRoot, hash.String(cacheKey)),
+				Type: &hostPathType,
+			},
+		},
+	}
+}
+
+func ModelCacheMount() kcore.VolumeMount {
+	return kcore.VolumeMount{
+		Name:      _modelCacheVolumeName,
+		MountPath: _modelCacheMountPath,
+		ReadOnly:  true,
+	}
+}
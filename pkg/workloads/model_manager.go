@@ -0,0 +1,103 @@
+/*
+Copyright 2021 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workloads
+
+import (
+	"github.com/cortexlabs/cortex/pkg/config"
+	"github.com/cortexlabs/cortex/pkg/consts"
+	"github.com/cortexlabs/cortex/pkg/lib/integrity"
+	libjson "github.com/cortexlabs/cortex/pkg/lib/json"
+	"github.com/cortexlabs/cortex/pkg/lib/k8s"
+	s "github.com/cortexlabs/cortex/pkg/lib/strings"
+	"github.com/cortexlabs/cortex/pkg/types/spec"
+	kcore "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+const (
+	ModelsDirEnvVar = "CORTEX_MODELS_DIR"
+
+	_modelsVolumeName          = "models"
+	_modelsMountPath           = "/models"
+	_modelManagerContainerName = "model-manager"
+)
+
+// the model manager downloads the api's models into a directory which is shared with the user's containers when they are requested
+// (via the proxy), and deletes the least recently used models once more than max_loaded_models are on disk
+func modelManagerContainer(api spec.API) (kcore.Container, kcore.Volume) {
+	models := make(map[string]string, len(api.Models.Paths))
+	modelsIntegrity := map[string]integrity.Spec{}
+	for _, modelPath := range api.Models.Paths {
+		models[modelPath.Name] = modelPath.Path
+		if modelPath.Integrity != nil {
+			modelsIntegrity[modelPath.Name] = spec.IntegritySpec(modelPath.Integrity)
+		}
+	}
+	modelsJSON, _ := libjson.MarshalJSONStr(models)
+
+	args := []string{
+		"--region", config.ClusterConfig.Region,
+		"--port", consts.ModelManagerPortStr,
+		"--user-port", s.Int32(*api.Pod.Port),
+		"--models", modelsJSON,
+		"--models-dir", _modelsMountPath,
+		"--max-loaded-models", s.Int32(api.Models.MaxLoadedModels),
+	}
+	if api.Models.UnloadPath != nil {
+		args = append(args, "--unload-path", *api.Models.UnloadPath)
+	}
+	if len(modelsIntegrity) > 0 {
+		integrityJSON, _ := libjson.MarshalJSONStr(modelsIntegrity)
+		args = append(args, "--integrity", integrityJSON)
+	}
+
+	return kcore.Container{
+		Name:            _modelManagerContainerName,
+		Image:           config.ClusterConfig.ImageModelManager,
+		ImagePullPolicy: kcore.PullAlways,
+		Args:            args,
+		Ports: []kcore.ContainerPort{
+			{Name: "model-manager", ContainerPort: consts.ModelManagerPortInt32},
+		},
+		Env:     baseEnvVars(api),
+		EnvFrom: baseClusterEnvVars(),
+		VolumeMounts: []kcore.VolumeMount{
+			k8s.EmptyDirVolumeMount(_modelsVolumeName, _modelsMountPath),
+		},
+		ReadinessProbe: &kcore.Probe{
+			Handler: kcore.Handler{
+				HTTPGet: &kcore.HTTPGetAction{
+					Path: "/healthz",
+					Port: intstr.FromInt(int(consts.ModelManagerPortInt32)),
+				},
+			},
+			InitialDelaySeconds: 1,
+			TimeoutSeconds:      1,
+			PeriodSeconds:       10,
+			SuccessThreshold:    1,
+			FailureThreshold:    1,
+		},
+	}, k8s.EmptyDirVolume(_modelsVolumeName)
+}
+
+func ModelsMount() kcore.VolumeMount {
+	return kcore.VolumeMount{
+		Name:      _modelsVolumeName,
+		MountPath: _modelsMountPath,
+		ReadOnly:  true,
+	}
+}
@@ -0,0 +1,174 @@
+/*
+Copyright 2021 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workloads
+
+import (
+	"github.com/cortexlabs/cortex/pkg/consts"
+	"github.com/cortexlabs/cortex/pkg/lib/k8s"
+	"github.com/cortexlabs/cortex/pkg/types/spec"
+	kcore "k8s.io/api/core/v1"
+	knetworking "k8s.io/api/networking/v1"
+	kmeta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+const (
+	NetworkPolicyLabelKey = "cortex.dev/network-policy"
+
+	_instanceMetadataCIDR = "169.254.169.254/32" // used by the cortex containers to retrieve AWS credentials
+)
+
+// the namespace label which is set in manager/manifests/istio-namespace.yaml
+var _istioNamespaceSelector = &kmeta.LabelSelector{
+	MatchLabels: map[string]string{"name": "istio-system"},
+}
+
+// cortex's own pods (e.g. the operator and the metrics exporters) in the cluster namespace
+var _controlPlanePodSelector = &kmeta.LabelSelector{
+	MatchExpressions: []kmeta.LabelSelectorRequirement{
+		{Key: "cortex.dev/api", Operator: kmeta.LabelSelectorOpDoesNotExist},
+	},
+}
+
+func NetworkPolicyName(apiName string) string {
+	return "api-" + apiName
+}
+
+// HasNetworkPolicy returns whether the traffic of the api's pods is limited by a network policy, which is the case for apis
+// with ingress or egress rules, and for apis which belong to a project
+func HasNetworkPolicy(api *spec.API) bool {
+	if api.Networking == nil {
+		return false
+	}
+	return api.Networking.Ingress != nil || api.Networking.Egress != nil || api.Project != nil
+}
+
+// NetworkPolicyHosts returns the DNS names which the pods of the api are allowed to reach
+func NetworkPolicyHosts(api *spec.API) []string {
+	if api.Networking != nil && api.Networking.Egress != nil {
+		return api.Networking.Egress.Hosts
+	}
+	return nil
+}
+
+// NetworkPolicy generates the policy which limits the traffic of the api's pods to the sources/destinations in the api's
+// networking configuration (and to the traffic which is required by cortex); hostIPs are the resolved addresses of NetworkPolicyHosts(),
+// and awsServiceCIDRs are the published address ranges of the AWS services in the cluster's region (which are used by the cortex containers,
+// e.g. to reach S3 and SQS; their DNS names resolve to many frequently changing addresses, so they can't be allowed by resolving them).
+// The pods of an api which belongs to a project and has no ingress rules only accept traffic from the gateway and from the project's other pods
+func NetworkPolicy(api *spec.API, hostIPs []string, awsServiceCIDRs []string) *knetworking.NetworkPolicy {
+	var policyTypes []knetworking.PolicyType
+	var ingress []knetworking.NetworkPolicyIngressRule
+	var egress []knetworking.NetworkPolicyEgressRule
+
+	if api.Networking.Ingress != nil || api.Project != nil {
+		policyTypes = append(policyTypes, knetworking.PolicyTypeIngress)
+
+		peers := []knetworking.NetworkPolicyPeer{
+			{NamespaceSelector: _istioNamespaceSelector},
+		}
+		if api.Networking.Ingress != nil {
+			peers = append(peers, networkPolicyPeers(api.Networking.Ingress.CIDRs, api.Networking.Ingress.APIs)...)
+		} else {
+			peers = append(peers, knetworking.NetworkPolicyPeer{
+				PodSelector: &kmeta.LabelSelector{
+					MatchLabels: map[string]string{ProjectLabelKey: *api.Project},
+				},
+			})
+		}
+
+		ingress = []knetworking.NetworkPolicyIngressRule{
+			{From: peers},
+			{
+				// metrics are scraped by prometheus
+				From:  []knetworking.NetworkPolicyPeer{{PodSelector: _controlPlanePodSelector}},
+				Ports: []knetworking.NetworkPolicyPort{networkPolicyPort(kcore.ProtocolTCP, consts.AdminPortInt32)},
+			},
+		}
+	}
+
+	if api.Networking.Egress != nil {
+		policyTypes = append(policyTypes, knetworking.PolicyTypeEgress)
+
+		cidrs := append([]string{_instanceMetadataCIDR}, api.Networking.Egress.CIDRs...)
+		cidrs = append(cidrs, awsServiceCIDRs...)
+		for _, ip := range hostIPs {
+			cidrs = append(cidrs, ip+"/32")
+		}
+
+		peers := []knetworking.NetworkPolicyPeer{
+			{NamespaceSelector: _istioNamespaceSelector},
+			{PodSelector: _controlPlanePodSelector},
+		}
+		peers = append(peers, networkPolicyPeers(cidrs, api.Networking.Egress.APIs)...)
+
+		egress = []knetworking.NetworkPolicyEgressRule{
+			{To: peers},
+			{
+				// dns, and metrics which are sent to the statsd exporter on the node's ip address
+				Ports: []knetworking.NetworkPolicyPort{
+					networkPolicyPort(kcore.ProtocolUDP, 53),
+					networkPolicyPort(kcore.ProtocolTCP, 53),
+					networkPolicyPort(kcore.ProtocolUDP, consts.StatsDPortInt32),
+				},
+			},
+		}
+	}
+
+	return k8s.NetworkPolicy(&k8s.NetworkPolicySpec{
+		Name: NetworkPolicyName(api.Name),
+		PodSelector: map[string]string{
+			"apiName": api.Name,
+			"apiKind": api.Kind.String(),
+		},
+		Ingress:     ingress,
+		Egress:      egress,
+		PolicyTypes: policyTypes,
+		Labels: WithUserLabels(api.API, map[string]string{
+			"apiName":             api.Name,
+			"apiKind":             api.Kind.String(),
+			NetworkPolicyLabelKey: "true",
+		}),
+	})
+}
+
+func networkPolicyPeers(cidrs []string, apiNames []string) []knetworking.NetworkPolicyPeer {
+	var peers []knetworking.NetworkPolicyPeer
+	for _, cidr := range cidrs {
+		peers = append(peers, knetworking.NetworkPolicyPeer{
+			IPBlock: &knetworking.IPBlock{CIDR: cidr},
+		})
+	}
+	if len(apiNames) > 0 {
+		peers = append(peers, knetworking.NetworkPolicyPeer{
+			PodSelector: &kmeta.LabelSelector{
+				MatchExpressions: []kmeta.LabelSelectorRequirement{
+					{Key: "apiName", Operator: kmeta.LabelSelectorOpIn, Values: apiNames},
+				},
+			},
+		})
+	}
+	return peers
+}
+
+func networkPolicyPort(protocol kcore.Protocol, port int32) knetworking.NetworkPolicyPort {
+	portIntStr := intstr.FromInt(int(port))
+	return knetworking.NetworkPolicyPort{
+		Protocol: &protocol,
+		Port:     &portIntStr,
+	}
+}
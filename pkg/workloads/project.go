@@ -0,0 +1,65 @@
+/*
+Copyright 2021 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workloads
+
+import (
+	"github.com/cortexlabs/cortex/pkg/lib/k8s"
+	"github.com/cortexlabs/cortex/pkg/types/userconfig"
+	kcore "k8s.io/api/core/v1"
+)
+
+const (
+	ProjectLabelKey = "cortex.dev/project"
+
+	// the labels in the api spec are prefixed, so that they can't conflict with the labels which are set by cortex
+	UserLabelKeyPrefix = "label.cortex.dev/"
+)
+
+func ProjectServiceAccountName(project string) string {
+	return "cortex-project-" + project
+}
+
+// APIServiceAccountName returns the service account of the api's pods; the apis of a project share the project's service account,
+// and the other apis use the namespace's default service account
+func APIServiceAccountName(api *userconfig.API) string {
+	if api.Project != nil {
+		return ProjectServiceAccountName(*api.Project)
+	}
+	return ServiceAccountName
+}
+
+// WithUserLabels adds the api's project (if it has one) and the labels in the api spec to the labels, and returns them
+func WithUserLabels(api *userconfig.API, labels map[string]string) map[string]string {
+	if api.Project != nil {
+		labels[ProjectLabelKey] = *api.Project
+	}
+	for key, value := range api.Labels {
+		labels[UserLabelKeyPrefix+key] = value
+	}
+	return labels
+}
+
+// ProjectServiceAccount is the service account which is shared by the pods of the project's apis; it has no role bindings
+// (like the namespace's default service account), so that users can grant permissions to the apis of a single project
+func ProjectServiceAccount(project string) *kcore.ServiceAccount {
+	return k8s.ServiceAccount(&k8s.ServiceAccountSpec{
+		Name: ProjectServiceAccountName(project),
+		Labels: map[string]string{
+			ProjectLabelKey: project,
+		},
+	})
+}
@@ -0,0 +1,100 @@
+/*
+Copyright 2021 Cortex Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workloads
+
+import (
+	libjson "github.com/cortexlabs/cortex/pkg/lib/json"
+	"github.com/cortexlabs/cortex/pkg/types/userconfig"
+	kapps "k8s.io/api/apps/v1"
+	kcore "k8s.io/api/core/v1"
+	kresource "k8s.io/apimachinery/pkg/api/resource"
+)
+
+// VerticalAutoscalingRequests maps the names of an api's containers to the requests which the operator chose for them based on their usage;
+// the requests are stored in an annotation on the api's deployment, and are applied to the pods during the api's next rollout
+type VerticalAutoscalingRequests map[string]ContainerRequests
+
+type ContainerRequests struct {
+	CPU string `json:"cpu"`
+	Mem string `json:"mem"`
+}
+
+// VerticalAutoscalingRequestsFromDeployment returns the requests which are stored on the deployment (or nil if there aren't any)
+func VerticalAutoscalingRequestsFromDeployment(deployment *kapps.Deployment) VerticalAutoscalingRequests {
+	if deployment == nil || deployment.Annotations[userconfig.VerticalAutoscalingAnnotationKey] == "" {
+		return nil
+	}
+
+	var requests VerticalAutoscalingRequests
+	if err := libjson.Unmarshal([]byte(deployment.Annotations[userconfig.VerticalAutoscalingAnnotationKey]), &requests); err != nil {
+		return nil
+	}
+	return requests
+}
+
+func (requests VerticalAutoscalingRequests) String() string {
+	bytes, _ := libjson.Marshal(requests)
+	return string(bytes)
+}
+
+// ApplyVerticalAutoscaling sets the requests of the api's containers which have vertical autoscaling to the requests which are stored on
+// the previous deployment (within the containers' current bounds), and stores them in the new deployment's annotations so that they are retained
+func ApplyVerticalAutoscaling(api *userconfig.API, containers []kcore.Container, prevDeployment *kapps.Deployment, annotations map[string]string) {
+	prevRequests := VerticalAutoscalingRequestsFromDeployment(prevDeployment)
+	if len(prevRequests) == 0 {
+		return
+	}
+
+	requests := VerticalAutoscalingRequests{}
+	for _, container := range api.Pod.Containers {
+		containerRequests, ok := prevRequests[container.Name]
+		if !ok || container.VerticalAutoscaling == nil {
+			continue
+		}
+
+		cpu, cpuErr := kresource.ParseQuantity(containerRequests.CPU)
+		mem, memErr := kresource.ParseQuantity(containerRequests.Mem)
+		if cpuErr != nil || memErr != nil {
+			continue
+		}
+		cpu = ClampQuantity(cpu, container.VerticalAutoscaling.MinCPU.Quantity, container.VerticalAutoscaling.MaxCPU.Quantity)
+		mem = ClampQuantity(mem, container.VerticalAutoscaling.MinMem.Quantity, container.VerticalAutoscaling.MaxMem.Quantity)
+
+		for i := range containers {
+			if containers[i].Name != container.Name {
+				continue
+			}
+			containers[i].Resources.Requests[kcore.ResourceCPU] = cpu
+			containers[i].Resources.Requests[kcore.ResourceMemory] = mem
+		}
+		requests[container.Name] = ContainerRequests{CPU: cpu.String(), Mem: mem.String()}
+	}
+
+	if len(requests) > 0 {
+		annotations[userconfig.VerticalAutoscalingAnnotationKey] = requests.String()
+	}
+}
+
+func ClampQuantity(quantity kresource.Quantity, min kresource.Quantity, max kresource.Quantity) kresource.Quantity {
+	if quantity.Cmp(min) < 0 {
+		return min.DeepCopy()
+	}
+	if quantity.Cmp(max) > 0 {
+		return max.DeepCopy()
+	}
+	return quantity
+}